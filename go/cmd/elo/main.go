@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"nof0-api/pkg/journal"
+	"nof0-api/pkg/rating"
+)
+
+// ratingsFile is the document served by GET /api/ratings.
+type ratingsFile struct {
+	Ratings     []rating.Rating `json:"ratings"`
+	Rounds      int             `json:"rounds"`
+	Matches     int             `json:"matches"`
+	Unresolved  int             `json:"unresolved"`
+	Bucket      string          `json:"bucket"`
+	Horizon     string          `json:"horizon"`
+	GeneratedAt int64           `json:"generated_at"`
+}
+
+func main() {
+	var (
+		journalDirs = flag.String("journal-dirs", "journal", "Comma-separated trader journal directories")
+		out         = flag.String("out", "../mcp/data/elo-ratings.json", "Where to write the ratings JSON")
+		k           = flag.Float64("k", rating.DefaultK, "Elo K-factor")
+		bucket      = flag.Duration("bucket", rating.DefaultBucket, "Window within which cycles count as the same market data")
+		horizon     = flag.Duration("horizon", rating.DefaultHorizon, "How far ahead a decision is judged")
+		minMove     = flag.Float64("min-move", rating.DefaultMinMove, "Absolute return below which disagreements are draws")
+	)
+	flag.Parse()
+
+	var records []*journal.CycleRecord
+	for _, dir := range strings.Split(*journalDirs, ",") {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			continue
+		}
+		recs, err := journal.NewReader(dir).Latest(0)
+		if err != nil {
+			log.Fatalf("load journal %s: %v", dir, err)
+		}
+		records = append(records, recs...)
+	}
+	if len(records) == 0 {
+		log.Println("no journal cycles found")
+		return
+	}
+
+	summary := rating.Score(rating.ObservationsFromJournal(records), rating.Config{
+		K:       *k,
+		Bucket:  *bucket,
+		Horizon: *horizon,
+		MinMove: *minMove,
+	})
+	for i, r := range summary.Ratings {
+		log.Printf("%2d. %-24s %7.1f  W%d L%d D%d", i+1, r.TraderID, r.Rating, r.Wins, r.Losses, r.Draws)
+	}
+	log.Printf("scored %d cycles: %d rounds, %d matches, %d awaiting outcome", len(records), summary.Rounds, summary.Matches, summary.Unresolved)
+
+	body, err := json.MarshalIndent(ratingsFile{
+		Ratings:     summary.Ratings,
+		Rounds:      summary.Rounds,
+		Matches:     summary.Matches,
+		Unresolved:  summary.Unresolved,
+		Bucket:      bucket.String(),
+		Horizon:     horizon.String(),
+		GeneratedAt: time.Now().UnixMilli(),
+	}, "", "  ")
+	if err != nil {
+		log.Fatalf("encode ratings: %v", err)
+	}
+	tmp := *out + ".tmp"
+	if err := os.WriteFile(tmp, body, 0o644); err != nil {
+		log.Fatalf("write ratings: %v", err)
+	}
+	if err := os.Rename(tmp, *out); err != nil {
+		log.Fatalf("write ratings: %v", err)
+	}
+	log.Printf("ratings written to %s", filepath.Clean(*out))
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"nof0-api/pkg/dataset"
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/journal"
+	"nof0-api/pkg/rating"
+)
+
+func runPromptDataset(args []string) error {
+	fsFlags := flag.NewFlagSet("prompt-dataset", flag.ContinueOnError)
+	var (
+		journalDirs  = fsFlags.String("journal-dirs", "journal", "Comma-separated trader journal directories")
+		executorPath = fsFlags.String("executor-config", "etc/executor.yaml", "Executor config used to rebuild prompts")
+		templatePath = fsFlags.String("template", "etc/prompts/executor/default_prompt.tmpl", "Executor prompt template")
+		models       = fsFlags.String("model", "", "Comma-separated trader ids to export (default all)")
+		from         = fsFlags.String("from", "", "Earliest cycle time, RFC3339 or YYYY-MM-DD (inclusive)")
+		to           = fsFlags.String("to", "", "Latest cycle time, RFC3339 or YYYY-MM-DD (exclusive)")
+		outcomes     = fsFlags.String("outcome", "", "Comma-separated outcomes to keep: "+strings.Join(dataset.Outcomes, ", "))
+		horizon      = fsFlags.Duration("horizon", rating.DefaultHorizon, "How far ahead a decision's outcome is judged")
+		minMove      = fsFlags.Float64("min-move", rating.DefaultMinMove, "Absolute return below which an outcome is flat")
+		out          = fsFlags.String("out", "-", "Output JSONL file, - for stdout")
+	)
+	if err := fsFlags.Parse(args); err != nil {
+		return err
+	}
+
+	opts := dataset.Options{
+		Models:   splitList(*models),
+		Outcomes: splitList(*outcomes),
+		Horizon:  *horizon,
+		MinMove:  *minMove,
+	}
+	for _, o := range opts.Outcomes {
+		if !slices.Contains(dataset.Outcomes, strings.ToLower(o)) {
+			return fmt.Errorf("unknown outcome %q (want %s)", o, strings.Join(dataset.Outcomes, ", "))
+		}
+	}
+	var err error
+	if opts.From, err = parseTime(*from); err != nil {
+		return fmt.Errorf("-from: %w", err)
+	}
+	if opts.To, err = parseTime(*to); err != nil {
+		return fmt.Errorf("-to: %w", err)
+	}
+
+	var records []*journal.CycleRecord
+	for _, dir := range splitList(*journalDirs) {
+		recs, err := journal.NewReader(dir).Latest(0)
+		if err != nil {
+			return err
+		}
+		records = append(records, recs...)
+	}
+
+	execCfg, err := executorpkg.LoadConfig(*executorPath)
+	if err != nil {
+		return fmt.Errorf("load executor config: %w", err)
+	}
+	renderer, err := executorpkg.NewPromptRenderer(execCfg, *templatePath)
+	if err != nil {
+		return fmt.Errorf("load template: %w", err)
+	}
+	examples, err := dataset.Build(records, func(rec *journal.CycleRecord) (string, error) {
+		execCtx := journal.BuildExecutorContext(execCfg, rec)
+		return renderer.Render(executorpkg.BuildPromptInputs(execCfg, &execCtx))
+	}, opts)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeJSONL(w, examples); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "exported %d of %d cycles\n", len(examples), len(records))
+	return nil
+}
+
+func writeJSONL(w io.Writer, examples []dataset.Example) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, ex := range examples {
+		if err := enc.Encode(ex); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// parseTime accepts RFC3339 timestamps and bare UTC dates; empty is zero.
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
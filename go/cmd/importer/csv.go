@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"nof0-api/internal/data"
+)
+
+// importCSV stores the round trips in an exchange trade export as a
+// comparison account in the MCP data directory, where the API ranks it on the
+// leaderboard next to the models.
+func importCSV(dataPath, csvPath, account string, startingEquity float64) error {
+	if startingEquity <= 0 {
+		return errors.New("-starting-equity must be positive")
+	}
+	f, err := os.Open(csvPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	res, err := data.ParseTradeCSV(f, account)
+	if err != nil {
+		return err
+	}
+	if len(res.Open) > 0 {
+		log.Printf("skipping positions still open at end of export: %v", res.Open)
+	}
+	dl := data.NewDataLoader(dataPath)
+	err = dl.SaveComparisonAccount(data.ComparisonAccount{
+		Id:             account,
+		Source:         filepath.Base(csvPath),
+		StartingEquity: startingEquity,
+		ImportedAt:     time.Now().UnixMilli(),
+		Trades:         res.Trades,
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("imported %d fills as %d trades into comparison account %q", res.Fills, len(res.Trades), account)
+	return nil
+}
@@ -2,16 +2,21 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"nof0-api/etc"
+	"nof0-api/internal/accounting"
 	"nof0-api/internal/cache"
 	"nof0-api/internal/cli"
 	appconfig "nof0-api/internal/config"
@@ -24,10 +29,16 @@ import (
 	_ "nof0-api/pkg/exchange/hyperliquid"
 	_ "nof0-api/pkg/exchange/sim"
 	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/flags"
+	"nof0-api/pkg/leader"
 	llmpkg "nof0-api/pkg/llm"
 	managerpkg "nof0-api/pkg/manager"
 	marketpkg "nof0-api/pkg/market"
+	_ "nof0-api/pkg/market/exchanges/binance"
 	_ "nof0-api/pkg/market/exchanges/hyperliquid"
+	"nof0-api/pkg/market/indicators"
+	promptpkg "nof0-api/pkg/prompt"
+	"nof0-api/pkg/telegram"
 )
 
 type filteredMarket struct {
@@ -190,12 +201,20 @@ func applyExecutorPromptProfile(cfg *managerpkg.Config, profile string) error {
 		return fmt.Errorf("unknown executor prompt profile %q", profile)
 	}
 
-	path, err := confkit.ProjectPath(rel)
-	if err != nil {
-		return fmt.Errorf("resolve prompt profile path: %w", err)
-	}
-	if _, err := os.Stat(path); err != nil {
-		return fmt.Errorf("prompt profile %q missing template %s: %w", profile, path, err)
+	var path string
+	if cfg.TemplateFS != nil {
+		path = strings.TrimPrefix(rel, "etc/")
+		if _, err := fs.Stat(cfg.TemplateFS, path); err != nil {
+			return fmt.Errorf("prompt profile %q missing embedded template %s: %w", profile, path, err)
+		}
+	} else {
+		var err error
+		if path, err = confkit.ProjectPath(rel); err != nil {
+			return fmt.Errorf("resolve prompt profile path: %w", err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("prompt profile %q missing template %s: %w", profile, path, err)
+		}
 	}
 	for i := range cfg.Traders {
 		cfg.Traders[i].ExecutorTemplate = path
@@ -242,6 +261,154 @@ func applyPaperMarketOverride(cfg *managerpkg.Config, providerName string) error
 	return nil
 }
 
+// newAccountSnapshotter builds the account snapshot scheduler over the
+// manager returned by mgr, which may be constructed after it.
+func newAccountSnapshotter(cfg appconfig.AccountsConf, svcCtx *svc.ServiceContext, mgr func() *managerpkg.Manager) (*accounting.Snapshotter, error) {
+	var store accounting.SnapshotStore
+	if svcCtx != nil && svcCtx.AccountSnapshotsModel != nil {
+		store = svcCtx.AccountSnapshotsModel
+	} else {
+		logx.Slowf("account snapshotter: postgres not configured; history kept in memory only")
+	}
+	source := accounting.AccountSourceFunc(func(ctx context.Context) []managerpkg.TraderAccount {
+		m := mgr()
+		if m == nil {
+			return nil
+		}
+		return m.TraderAccounts(ctx)
+	})
+	return accounting.NewSnapshotter(source, store, accounting.SnapshotterConfig{
+		Interval: cfg.Interval,
+		Lookback: cfg.Lookback,
+	})
+}
+
+// newLeaderElector builds the elector deciding which instance drives each
+// trader.
+func newLeaderElector(cfg appconfig.LeaderConf, svcCtx *svc.ServiceContext) (*leader.Elector, error) {
+	var backend leader.Backend
+	switch cfg.Backend {
+	case leader.BackendMemory:
+		backend = leader.NewMemoryBackend()
+	case leader.BackendRedis, "":
+		if svcCtx == nil || svcCtx.Redis == nil {
+			return nil, errors.New("redis backend requires Cache to be configured")
+		}
+		backend = leader.NewRedisBackend(svcCtx.Redis)
+	default:
+		return nil, fmt.Errorf("unknown backend %q", cfg.Backend)
+	}
+	return leader.NewElector(backend, cfg.Owner, cfg.TTL)
+}
+
+// newFeatureFlags loads the feature flags for cfg.Env from the configured
+// source.
+func newFeatureFlags(cfg *appconfig.Config, svcCtx *svc.ServiceContext) (*flags.Store, error) {
+	var source flags.Source
+	switch cfg.FeatureFlags.Source {
+	case flags.SourcePostgres:
+		if svcCtx == nil || svcCtx.DBConn == nil {
+			return nil, errors.New("postgres source requires Postgres to be configured")
+		}
+		source = flags.NewSQLSource(svcCtx.DBConn)
+	case flags.SourceFile, "":
+		path := cfg.FeatureFlags.File
+		if !filepath.IsAbs(path) {
+			resolved, err := confkit.ProjectPath(path)
+			if err != nil {
+				return nil, fmt.Errorf("resolve %s: %w", path, err)
+			}
+			path = resolved
+		}
+		source = flags.FileSource{Path: path}
+	default:
+		return nil, fmt.Errorf("unknown source %q", cfg.FeatureFlags.Source)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return flags.NewStore(ctx, source, cfg.Env)
+}
+
+// newMetricsCollector builds the market_metrics collector over the configured
+// provider and feeds its rolling averages back into that provider's snapshots.
+func newMetricsCollector(cfg appconfig.MetricsConf, providers map[string]marketpkg.Provider, svcCtx *svc.ServiceContext, symbols []string) (*ingest.MetricsCollector, error) {
+	provider, ok := providers[cfg.Provider]
+	if !ok {
+		return nil, fmt.Errorf("market provider %s not found", cfg.Provider)
+	}
+	source, ok := provider.(marketpkg.MetricsProvider)
+	if !ok {
+		return nil, fmt.Errorf("market provider %s does not report metrics", cfg.Provider)
+	}
+	if len(cfg.Symbols) > 0 {
+		symbols = cfg.Symbols
+	}
+	var store ingest.MetricsStore
+	if svcCtx != nil && svcCtx.MarketSeries != nil {
+		store = svcCtx.MarketSeries
+	} else {
+		logx.Slowf("metrics collector: postgres not configured; averages kept in memory only")
+	}
+	collector, err := ingest.NewMetricsCollector(source, store, ingest.MetricsCollectorConfig{
+		Provider:      cfg.Provider,
+		Symbols:       symbols,
+		Interval:      cfg.Interval,
+		AverageWindow: cfg.AverageWindow,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if aware, ok := provider.(marketpkg.MetricsAverageAware); ok {
+		aware.SetMetricsAverager(collector)
+	}
+	return collector, nil
+}
+
+// promptIntradayInterval and promptLongTermInterval are the candle
+// intervals of the prompt's rolling windows; KlineIngest must store both
+// for the windows to advance.
+const (
+	promptIntradayInterval = "3m"
+	promptLongTermInterval = "4h"
+)
+
+// newKlineIngestors builds one ingestor per configured candle feed. A feed
+// also rolls its candles into the windows of the market provider with the
+// same name.
+func newKlineIngestors(cfg appconfig.KlineIngestConf, store ingest.KlineStore, windows map[string]*marketpkg.WindowCache, symbols []string) ([]*ingest.KlineIngestor, error) {
+	out := make([]*ingest.KlineIngestor, 0, len(cfg.Feeds))
+	for _, feedCfg := range cfg.Feeds {
+		feed, err := marketpkg.NewKlineFeed(feedCfg.Type, marketpkg.KlineFeedConfig{
+			Testnet: feedCfg.Testnet,
+			URL:     feedCfg.URL,
+		})
+		if err != nil {
+			return nil, err
+		}
+		provider := strings.TrimSpace(feedCfg.Provider)
+		if provider == "" {
+			provider = feedCfg.Type
+		}
+		kcfg := ingest.KlineIngestorConfig{
+			Provider:      provider,
+			Symbols:       symbols,
+			Intervals:     cfg.Intervals,
+			Backfill:      cfg.Backfill,
+			FlushInterval: cfg.FlushInterval,
+			Trades:        feedCfg.Trades,
+		}
+		if w, ok := windows[provider]; ok {
+			kcfg.Windows = w
+		}
+		k, err := ingest.NewKlineIngestor(feed, store, kcfg)
+		if err != nil {
+			return nil, fmt.Errorf("kline feed %s: %w", feedCfg.Type, err)
+		}
+		out = append(out, k)
+	}
+	return out, nil
+}
+
 func main() {
 	var (
 		exchangePath  = flag.String("exchange-config", "etc/exchange.yaml", "path to exchange provider configuration")
@@ -254,8 +421,13 @@ func main() {
 		promptProfile = flag.String("executor-prompt-profile", "default", "executor prompt profile (default|fast)")
 		paperTrading  = flag.Bool("paper-trading", false, "route trades to the in-memory simulator instead of live exchanges")
 		paperExchange = flag.String("paper-exchange-provider", "paper_trading", "exchange provider id to use when --paper-trading is enabled")
+		embedPrompts  = flag.Bool("embedded-prompts", false, "load prompt templates compiled into the binary instead of etc/prompts on disk")
+		watchPrompts  = flag.Bool("watch-prompts", false, "reload executor prompt templates when their files change")
 	)
 	flag.Parse()
+	if *embedPrompts && *watchPrompts {
+		fatalf("--watch-prompts cannot be combined with --embedded-prompts")
+	}
 	logx.MustSetup(logx.LogConf{})
 	logx.DisableStat()
 
@@ -306,15 +478,12 @@ func main() {
 	if err != nil {
 		fatalf("load llm config: %v", err)
 	}
-	llmClient, err := llmpkg.NewClient(llmCfg)
-	if err != nil {
-		fatalf("initialise llm client: %v", err)
+	var managerCfg *managerpkg.Config
+	if *embedPrompts {
+		managerCfg, err = managerpkg.LoadConfigWithTemplateFS(*managerPath, etc.Prompts)
+	} else {
+		managerCfg, err = managerpkg.LoadConfig(*managerPath)
 	}
-	defer func() {
-		_ = llmClient.Close()
-	}()
-
-	managerCfg, err := managerpkg.LoadConfig(*managerPath)
 	if err != nil {
 		fatalf("load manager config: %v", err)
 	}
@@ -377,6 +546,8 @@ func main() {
 			TradesModel:               svcCtx.TradesModel,
 			SnapshotsModel:            svcCtx.AccountEquitySnapshotsModel,
 			DecisionModel:             svcCtx.DecisionCyclesModel,
+			CycleEventsModel:          svcCtx.CycleEventsModel,
+			TradeJournalModel:         svcCtx.TradeJournalModel,
 			Cache:                     svcCtx.Cache,
 			Redis:                     svcCtx.Redis,
 			TTL:                       ttlSet,
@@ -384,12 +555,18 @@ func main() {
 			ConversationMessagesModel: svcCtx.ConversationMessagesModel,
 		})
 		marketPersist = marketpersist.NewService(marketpersist.Config{
-			SQLConn:         svcCtx.DBConn,
-			AssetsModel:     svcCtx.MarketAssetsModel,
-			PriceTicksModel: svcCtx.PriceTicksModel,
-			Cache:           svcCtx.Cache,
-			Redis:           svcCtx.Redis,
-			TTL:             ttlSet,
+			SQLConn:            svcCtx.BulkConn,
+			AssetsModel:        svcCtx.MarketAssetsModel,
+			PriceTicksModel:    svcCtx.PriceTicksModel,
+			KlinesModel:        svcCtx.KlinesModel,
+			MarketMetricsModel: svcCtx.MarketMetricsModel,
+			Cache:              svcCtx.Cache,
+			Redis:              svcCtx.Redis,
+			TTL:                ttlSet,
+			SeriesBackend:      runtimeCfg.MarketStorage.Backend,
+			SeriesTable:        runtimeCfg.MarketStorage.Table,
+			KlinesTable:        runtimeCfg.MarketStorage.KlinesTable,
+			MetricsTable:       runtimeCfg.MarketStorage.MetricsTable,
 		})
 		if persistService == nil {
 			logx.Slowf("manager persistence disabled: postgres/cache not configured in %s", *appConfig)
@@ -407,12 +584,73 @@ func main() {
 			}
 		}
 	}
+	// Serve prompt assembly from rolling windows: the kline ingestor rolls
+	// in each closed candle and the market ingestor keeps prices fresh.
+	windowCaches := make(map[string]*marketpkg.WindowCache, len(filteredMarkets))
+	for name, provider := range filteredMarkets {
+		windows := marketpkg.NewWindowCache(name, 0, func() marketpkg.SymbolWindows {
+			return indicators.NewWindows(promptIntradayInterval, promptLongTermInterval, marketpkg.DefaultIntradayWindow, marketpkg.DefaultLongTermWindow)
+		})
+		if sink, ok := marketPersist.(marketpkg.WindowSink); ok {
+			windows.SetSink(sink)
+		}
+		windowCaches[name] = windows
+		filteredMarkets[name] = windows.Wrap(provider)
+	}
+	var metricsCollector *ingest.MetricsCollector
+	if runtimeCfg != nil && runtimeCfg.Metrics.Enabled {
+		metricsCollector, err = newMetricsCollector(runtimeCfg.Metrics, marketProviders, svcCtx, allowedSymbols)
+		if err != nil {
+			fatalf("build metrics collector: %v", err)
+		}
+	}
 	ingestor := ingest.NewMarketIngestor(filteredMarkets, allowedSymbols, 45*time.Second, 30*time.Minute, 150*time.Millisecond)
+	var klineIngestors []*ingest.KlineIngestor
+	if runtimeCfg != nil && runtimeCfg.KlineIngest.Enabled {
+		if svcCtx.MarketSeries == nil {
+			logx.Slowf("kline ingest disabled: postgres not configured in %s", *appConfig)
+		} else if klineIngestors, err = newKlineIngestors(runtimeCfg.KlineIngest, svcCtx.MarketSeries, windowCaches, allowedSymbols); err != nil {
+			fatalf("build kline ingestors: %v", err)
+		}
+	}
 	var conversationRecorder executorpkg.ConversationRecorder
 	if rec, ok := persistService.(executorpkg.ConversationRecorder); ok {
 		conversationRecorder = rec
 	}
+	var llmOpts []llmpkg.ClientOption
+	if llmCfg.Cache.UsesRedis() && svcCtx != nil && svcCtx.Redis != nil {
+		llmOpts = append(llmOpts, llmpkg.WithResponseCache(llmpkg.NewRedisResponseCache(svcCtx.Redis)))
+	}
+	llmClient, err := llmpkg.NewClient(llmCfg, llmOpts...)
+	if err != nil {
+		fatalf("initialise llm client: %v", err)
+	}
+	defer func() {
+		_ = llmClient.Close()
+	}()
 	execFactory := managerpkg.NewBasicExecutorFactory(llmClient, conversationRecorder)
+	execFactory.SetTemplateFS(managerCfg.TemplateFS)
+	var promptRegistry *promptpkg.Registry
+	if managerCfg.PromptVersions != nil {
+		promptRegistry, err = managerCfg.PromptRegistry()
+		if err != nil {
+			fatalf("load prompt versions: %v", err)
+		}
+		execFactory.SetPromptRegistry(promptRegistry)
+		logx.Infof("prompt versions loaded versions=%d rollouts=%d", len(promptRegistry.Versions()), len(managerCfg.PromptVersions.Rollouts))
+	}
+	var promptWatcher *llmpkg.TemplateWatcher
+	if *watchPrompts {
+		promptWatcher, err = llmpkg.NewTemplateWatcher()
+		if err != nil {
+			fatalf("start prompt watcher: %v", err)
+		}
+		defer promptWatcher.Close()
+		promptWatcher.OnReload(func(name, digest string) {
+			logx.Infof("prompt template %s reloaded, digest=%s", name, digest)
+		})
+		execFactory.SetTemplateWatcher(promptWatcher)
+	}
 
 	traderSources := managerCfg.Traders
 	if svcCtx != nil && svcCtx.TraderConfigRepo != nil && len(managerCfg.Traders) > 0 {
@@ -445,7 +683,11 @@ func main() {
 		}
 	}
 
-	var managerOpts []managerpkg.Option
+	managerOpts := []managerpkg.Option{managerpkg.WithEmbedders(llmClient)}
+	if promptRegistry != nil {
+		// Rollouts observe cycle outcomes on the registry executors assign from.
+		managerOpts = append(managerOpts, managerpkg.WithPromptRegistry(promptRegistry))
+	}
 	if svcCtx != nil {
 		if svcCtx.TraderConfigRepo != nil {
 			managerOpts = append(managerOpts, managerpkg.WithConfigRepo(svcCtx.TraderConfigRepo))
@@ -455,7 +697,42 @@ func main() {
 		}
 	}
 
-	mgr := managerpkg.NewManager(managerCfg, execFactory, exchangeProviders, filteredMarkets, persistService, managerOpts...)
+	var mgr *managerpkg.Manager
+	var snapshotter *accounting.Snapshotter
+	if runtimeCfg != nil && runtimeCfg.Accounts.Enabled {
+		snapshotter, err = newAccountSnapshotter(runtimeCfg.Accounts, svcCtx, func() *managerpkg.Manager { return mgr })
+		if err != nil {
+			fatalf("build account snapshotter: %v", err)
+		}
+		managerOpts = append(managerOpts, managerpkg.WithAccountMetrics(snapshotter))
+	}
+
+	var featureFlags *flags.Store
+	if runtimeCfg != nil {
+		featureFlags, err = newFeatureFlags(runtimeCfg, svcCtx)
+		if err != nil {
+			fatalf("load feature flags: %v", err)
+		}
+		logx.Infof("feature flags loaded source=%s env=%s configured=%d", runtimeCfg.FeatureFlags.Source, runtimeCfg.Env, len(featureFlags.Snapshot().Flags()))
+		managerOpts = append(managerOpts, managerpkg.WithFeatureFlags(featureFlags))
+	}
+
+	if runtimeCfg != nil && runtimeCfg.Leader.Enabled {
+		elector, err := newLeaderElector(runtimeCfg.Leader, svcCtx)
+		if err != nil {
+			fatalf("build leader elector: %v", err)
+		}
+		logx.Infof("leader election enabled backend=%s owner=%s ttl=%s", runtimeCfg.Leader.Backend, elector.Owner(), runtimeCfg.Leader.TTL)
+		managerOpts = append(managerOpts, managerpkg.WithLeaderElection(elector))
+	}
+
+	var bot *telegram.Bot
+	if managerCfg.Monitoring.Telegram.Enabled {
+		bot = telegram.NewBot(managerCfg.Monitoring.Telegram, nil)
+		managerOpts = append(managerOpts, managerpkg.WithAlerter(bot))
+	}
+
+	mgr = managerpkg.NewManager(managerCfg, execFactory, exchangeProviders, filteredMarkets, persistService, managerOpts...)
 
 	traderIDs := make([]string, 0, len(traderSources))
 	for _, traderCfg := range traderSources {
@@ -481,6 +758,24 @@ func main() {
 	if ingestor != nil {
 		go ingestor.Run(ctx)
 	}
+	if metricsCollector != nil {
+		go metricsCollector.Run(ctx)
+	}
+	for _, k := range klineIngestors {
+		go k.Run(ctx)
+	}
+	if snapshotter != nil {
+		go snapshotter.Run(ctx)
+	}
+	if promptWatcher != nil {
+		go promptWatcher.Run(ctx)
+	}
+	if featureFlags != nil {
+		go featureFlags.Run(ctx, runtimeCfg.FeatureFlags.Refresh)
+	}
+	if bot != nil {
+		go bot.Run(ctx, mgr)
+	}
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
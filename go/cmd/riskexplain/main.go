@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"nof0-api/internal/logic"
+	"nof0-api/internal/types"
+	executorpkg "nof0-api/pkg/executor"
+	managerpkg "nof0-api/pkg/manager"
+)
+
+// riskexplain dry-runs the risk engine on one decision and prints every rule
+// evaluated, so a rejected trade can be understood without reading the
+// validator. The input is the body accepted by POST /api/risk/explain:
+//
+//	{"trader_id": "...", "decision": {...}, "portfolio": {...}}
+func main() {
+	var (
+		input        = flag.String("input", "-", "Explain request JSON file (- for stdin)")
+		executorPath = flag.String("executor-config", "etc/executor.yaml", "Executor config file (used without a trader)")
+		managerPath  = flag.String("manager-config", "etc/manager.yaml", "Manager config file (used with a trader)")
+		traderID     = flag.String("trader", "", "Trader id whose risk params apply; overrides trader_id in the input")
+		jsonOut      = flag.Bool("json", false, "Print the explanation as JSON")
+		showSkipped  = flag.Bool("skipped", false, "Also list rules that did not apply")
+		failOnReject = flag.Bool("exit-code", true, "Exit 1 when the decision is rejected")
+	)
+	flag.Parse()
+
+	req, err := readRequest(*input)
+	if err != nil {
+		log.Fatalf("read input: %v", err)
+	}
+	if id := strings.TrimSpace(*traderID); id != "" {
+		req.TraderId = id
+	}
+
+	var (
+		execCfg *executorpkg.Config
+		mgrCfg  *managerpkg.Config
+	)
+	if strings.TrimSpace(req.TraderId) != "" {
+		if mgrCfg, err = managerpkg.LoadConfig(*managerPath); err != nil {
+			log.Fatalf("load manager config: %v", err)
+		}
+	} else if execCfg, err = executorpkg.LoadConfig(*executorPath); err != nil {
+		log.Fatalf("load executor config: %v", err)
+	}
+
+	resp, err := logic.ExplainRisk(execCfg, mgrCfg, req)
+	if err != nil {
+		log.Fatalf("explain: %v", err)
+	}
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(resp); err != nil {
+			log.Fatalf("encode: %v", err)
+		}
+	} else {
+		printExplanation(os.Stdout, resp, *showSkipped)
+	}
+	if *failOnReject && !resp.Allowed {
+		os.Exit(1)
+	}
+}
+
+func readRequest(path string) (*types.RiskExplainRequest, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var req types.RiskExplainRequest
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		return nil, fmt.Errorf("decode explain request: %w", err)
+	}
+	return &req, nil
+}
+
+func printExplanation(w io.Writer, resp *types.RiskExplainResponse, showSkipped bool) {
+	verdict := "ALLOWED"
+	if !resp.Allowed {
+		verdict = "REJECTED"
+	}
+	scope := "executor config"
+	if resp.TraderId != "" {
+		scope = "trader " + resp.TraderId
+	}
+	fmt.Fprintf(w, "%s %s: %s (%s)\n\n", resp.Action, resp.Symbol, verdict, scope)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tRULE\tINPUTS\tTHRESHOLD\tDETAIL")
+	for _, r := range resp.Rules {
+		if r.Status == string(executorpkg.RuleSkip) && !showSkipped {
+			continue
+		}
+		threshold := ""
+		if r.Threshold != nil {
+			threshold = fmt.Sprint(r.Threshold)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", strings.ToUpper(r.Status), r.Rule, formatInputs(r.Inputs), threshold, r.Detail)
+	}
+	_ = tw.Flush()
+}
+
+func formatInputs(in map[string]interface{}) string {
+	if len(in) == 0 {
+		return ""
+	}
+	raw, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Sprint(in)
+	}
+	return string(raw)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+func runDiff(args []string) error {
+	fsFlags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	var (
+		dataPath = fsFlags.String("data", "", "JSON data file rendered by both templates, or a fixture directory searched for <new>.json, then default.json")
+		typeName = fsFlags.String("type", "", "Decode the data into this type (see template schema --list) instead of a generic map")
+		context  = fsFlags.Int("context", 3, "Lines of context around each change")
+	)
+	paths, err := parseInterspersed(fsFlags, args)
+	if err != nil {
+		return err
+	}
+	if len(paths) != 2 {
+		return fmt.Errorf("usage: template diff OLD NEW [--data data.json | --data DIR] [--type NAME] [--context N]")
+	}
+	var typ reflect.Type
+	if strings.TrimSpace(*typeName) != "" {
+		if _, typ, err = lookupDataType(promptDataTypes(), *typeName); err != nil {
+			return err
+		}
+	}
+	fixture := *dataPath
+	if info, statErr := os.Stat(fixture); statErr == nil && info.IsDir() {
+		fixture = resolveFixture(fixture, paths[1])
+	}
+	diff, oldRes, newRes, err := diffTemplates(paths[0], paths[1], fixture, typ, *context)
+	if err != nil {
+		return err
+	}
+	fmt.Print(diff)
+	if diff == "" {
+		fmt.Fprintf(os.Stderr, "rendered outputs are identical (~%d tokens)\n", newRes.Tokens)
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "~%d -> ~%d tokens (%+d)\n", oldRes.Tokens, newRes.Tokens, newRes.Tokens-oldRes.Tokens)
+	return nil
+}
+
+// diffTemplates renders both templates against the same fixture and returns
+// a unified diff of the outputs, empty when they are identical.
+func diffTemplates(oldPath, newPath, fixture string, typ reflect.Type, context int) (string, renderResult, renderResult, error) {
+	render := func(path string) (renderResult, error) {
+		var res renderResult
+		if typ != nil {
+			res = renderTyped(path, fixture, typ)
+		} else {
+			res = renderWithFixture(path, fixture)
+		}
+		for _, w := range res.Warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s: %s\n", path, w)
+		}
+		if res.Error != "" {
+			return res, fmt.Errorf("%s: %s", path, res.Error)
+		}
+		return res, nil
+	}
+	oldRes, err := render(oldPath)
+	if err != nil {
+		return "", oldRes, renderResult{}, err
+	}
+	newRes, err := render(newPath)
+	if err != nil {
+		return "", oldRes, newRes, err
+	}
+	if oldRes.Output == newRes.Output {
+		return "", oldRes, newRes, nil
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(oldRes.Output),
+		B:        diffLines(newRes.Output),
+		FromFile: oldPath,
+		ToFile:   newPath,
+		Context:  context,
+	})
+	return diff, oldRes, newRes, err
+}
+
+// diffLines splits text into newline-terminated lines, terminating the last
+// one if needed.
+func diffLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// parseInterspersed parses fs from args allowing flags after positional
+// arguments, as in `diff old.tmpl new.tmpl --data x.json`, and returns the
+// positional ones.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTemplatesRendersBothWithSameData(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.tmpl")
+	newPath := filepath.Join(dir, "new.tmpl")
+	fixture := filepath.Join(dir, "data.json")
+	require.NoError(t, os.WriteFile(oldPath, []byte("Equity {{ .Equity }}\nRules:\n- be careful\n"), 0o644))
+	require.NoError(t, os.WriteFile(newPath, []byte("Equity {{ .Equity }} USD\nRules:\n- be careful\n"), 0o644))
+	require.NoError(t, os.WriteFile(fixture, []byte(`{"Equity": 1000}`), 0o644))
+
+	diff, oldRes, newRes, err := diffTemplates(oldPath, newPath, fixture, nil, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "--- "+oldPath+"\n+++ "+newPath+"\n@@ -1,3 +1,3 @@\n-Equity 1000\n+Equity 1000 USD\n Rules:\n - be careful\n", diff)
+	assert.Greater(t, newRes.Tokens, oldRes.Tokens)
+
+	diff, _, _, err = diffTemplates(oldPath, oldPath, fixture, nil, 3)
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+
+	require.NoError(t, os.WriteFile(newPath, []byte("{{ .Equity "), 0o644))
+	_, _, _, err = diffTemplates(oldPath, newPath, fixture, nil, 3)
+	assert.ErrorContains(t, err, newPath)
+}
+
+func TestParseInterspersed(t *testing.T) {
+	fsFlags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	data := fsFlags.String("data", "", "")
+	args, err := parseInterspersed(fsFlags, []string{"old.tmpl", "new.tmpl", "--data", "x.json"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old.tmpl", "new.tmpl"}, args)
+	assert.Equal(t, "x.json", *data)
+}
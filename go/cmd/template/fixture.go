@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/journal"
+)
+
+// fixtureMetaKey holds capture metadata inside a fixture. Templates cannot
+// reference it (leading underscore), so it never leaks into rendered prompts.
+const fixtureMetaKey = "_fixture"
+
+var fixtureNameRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// fixtureMeta records where a fixture was captured from.
+type fixtureMeta struct {
+	Name       string    `json:"name"`
+	Source     string    `json:"source"`
+	CapturedAt time.Time `json:"captured_at"`
+	CycleTime  time.Time `json:"cycle_time,omitempty"`
+	Redacted   bool      `json:"redacted"`
+	Note       string    `json:"note,omitempty"`
+}
+
+func runFixture(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: template fixture <save|list|apply> [flags]")
+	}
+	switch args[0] {
+	case "save":
+		return runFixtureSave(args[1:])
+	case "list":
+		return runFixtureList(args[1:])
+	case "apply":
+		return runFixtureApply(args[1:])
+	default:
+		return fmt.Errorf("unknown fixture command %q (want save, list or apply)", args[0])
+	}
+}
+
+func runFixtureSave(args []string) error {
+	fsFlags := flag.NewFlagSet("fixture save", flag.ContinueOnError)
+	var (
+		name         = fsFlags.String("name", "", "Fixture name (required)")
+		dataDir      = fsFlags.String("data", "fixtures", "Fixture directory")
+		journalDir   = fsFlags.String("journal-dir", "journal", "Journal directory to capture from")
+		cycle        = fsFlags.String("cycle", "", "Journal cycle file to capture (defaults to the latest)")
+		executorPath = fsFlags.String("executor-config", "etc/executor.yaml", "Executor config used to build prompt inputs")
+		note         = fsFlags.String("note", "", "Optional free-form note stored with the fixture")
+		force        = fsFlags.Bool("force", false, "Overwrite an existing fixture")
+	)
+	if err := fsFlags.Parse(args); err != nil {
+		return err
+	}
+	if !fixtureNameRegexp.MatchString(*name) {
+		return fmt.Errorf("invalid fixture name %q", *name)
+	}
+	path := filepath.Join(*dataDir, *name+fixtureExt)
+	if _, err := os.Stat(path); err == nil && !*force {
+		return fmt.Errorf("fixture %s already exists (use --force to overwrite)", path)
+	}
+
+	reader := journal.NewReader(*journalDir)
+	source := strings.TrimSpace(*cycle)
+	if source == "" {
+		files, err := reader.List(1)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no journal cycles found in %s", *journalDir)
+		}
+		source = files[0]
+	}
+	rec, err := reader.Load(source)
+	if err != nil {
+		return err
+	}
+	execCfg, err := executorpkg.LoadConfig(*executorPath)
+	if err != nil {
+		return fmt.Errorf("load executor config: %w", err)
+	}
+
+	meta := fixtureMeta{
+		Name:       *name,
+		Source:     filepath.Base(source),
+		CapturedAt: time.Now().UTC(),
+		CycleTime:  rec.Timestamp.UTC(),
+		Redacted:   true,
+		Note:       *note,
+	}
+	data, err := json.MarshalIndent(buildFixture(execCfg, redactCycle(rec), meta), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*dataDir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("saved fixture %s from %s\n", path, meta.Source)
+	return nil
+}
+
+// redactCycle strips identifying and free-form fields from a journal record
+// before it is captured. Market and account numbers are kept because they are
+// what prompt edits need to be tested against.
+func redactCycle(rec *journal.CycleRecord) *journal.CycleRecord {
+	out := *rec
+	out.TraderID = ""
+	out.ConfigVersion = 0
+	out.PromptDigest = ""
+	out.CoTTrace = ""
+	out.DecisionsJSON = ""
+	out.ErrorMessage = ""
+	out.Actions = nil
+	out.Extra = nil
+	return &out
+}
+
+// buildFixture flattens prompt inputs and the prompt-relevant executor
+// settings into the data shape the executor template renders against.
+// Secrets such as the signing key are never copied.
+func buildFixture(cfg *executorpkg.Config, rec *journal.CycleRecord, meta fixtureMeta) map[string]any {
+	execCtx := journal.BuildExecutorContext(cfg, rec)
+	inputs := executorpkg.BuildPromptInputs(cfg, &execCtx)
+	return map[string]any{
+		fixtureMetaKey: meta,
+		"Config": map[string]any{
+			"MajorCoinLeverage": cfg.MajorCoinLeverage,
+			"AltcoinLeverage":   cfg.AltcoinLeverage,
+			"MinConfidence":     cfg.MinConfidence,
+			"MinRiskReward":     cfg.MinRiskReward,
+			"MaxPositions":      cfg.MaxPositions,
+		},
+		"CurrentTime":        inputs.CurrentTime,
+		"RuntimeMinutes":     inputs.RuntimeMinutes,
+		"SharpeRatio":        inputs.SharpeRatio,
+		"AccountOverview":    inputs.AccountOverview,
+		"OpenPositions":      inputs.OpenPositions,
+		"RiskBudget":         inputs.RiskBudget,
+		"PerformanceView":    inputs.PerformanceView,
+		"CandidateCoins":     inputs.CandidateCoins,
+		"MarketSnapshots":    inputs.MarketSnapshots,
+		"MarketSummary":      inputs.MarketSummary,
+		"MarketSeries":       inputs.MarketSeries,
+		"DataUnavailable":    inputs.DataUnavailable,
+		"DecisionCadence":    inputs.DecisionCadence,
+		"MarketRegime":       inputs.MarketRegime,
+		"TriggerReasons":     orEmpty(inputs.TriggerReasons),
+		"AdvisorNotes":       orEmpty(inputs.AdvisorNotes),
+		"ObservedSlippage":   inputs.ObservedSlippage,
+		"DataQuality":        inputs.DataQuality,
+		"Blacklisted":        inputs.Blacklisted,
+		"WarmingUp":          inputs.WarmingUp,
+		"LossStreak":         inputs.LossStreak,
+		"EntriesPausedUntil": inputs.EntriesPausedUntil,
+	}
+}
+
+// orEmpty keeps an unset list an empty JSON array rather than null, which
+// the prompt data schema rejects.
+func orEmpty(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
+
+func runFixtureList(args []string) error {
+	fsFlags := flag.NewFlagSet("fixture list", flag.ContinueOnError)
+	dataDir := fsFlags.String("data", "fixtures", "Fixture directory")
+	if err := fsFlags.Parse(args); err != nil {
+		return err
+	}
+	names, err := listFixtures(*dataDir)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSOURCE\tCYCLE TIME\tNOTE")
+	for _, file := range names {
+		name := strings.TrimSuffix(file, fixtureExt)
+		meta, err := readFixtureMeta(filepath.Join(*dataDir, file))
+		if err != nil {
+			fmt.Fprintf(tw, "%s\t(unreadable: %v)\t\t\n", name, err)
+			continue
+		}
+		source, cycleTime, note := "(hand-written)", "", ""
+		if meta != nil {
+			source, note = meta.Source, meta.Note
+			if !meta.CycleTime.IsZero() {
+				cycleTime = meta.CycleTime.Format(time.RFC3339)
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, source, cycleTime, note)
+	}
+	return tw.Flush()
+}
+
+// listFixtures returns the fixture file names in dir in lexical order.
+func listFixtures(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("list fixtures in %s: %w", dir, err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, ent := range entries {
+		if !ent.IsDir() && strings.HasSuffix(ent.Name(), fixtureExt) {
+			names = append(names, ent.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func readFixtureMeta(path string) (*fixtureMeta, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Meta *fixtureMeta `json:"_fixture"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return doc.Meta, nil
+}
+
+func runFixtureApply(args []string) error {
+	fsFlags := flag.NewFlagSet("fixture apply", flag.ContinueOnError)
+	var (
+		name         = fsFlags.String("name", "", "Fixture name (required)")
+		dataDir      = fsFlags.String("data", "fixtures", "Fixture directory")
+		templatePath = fsFlags.String("template", "etc/prompts/executor/default_prompt.tmpl", "Template to render")
+	)
+	if err := fsFlags.Parse(args); err != nil {
+		return err
+	}
+	if !fixtureNameRegexp.MatchString(*name) {
+		return fmt.Errorf("invalid fixture name %q", *name)
+	}
+	fixturePath := filepath.Join(*dataDir, *name+fixtureExt)
+	res := renderWithFixture(*templatePath, fixturePath)
+	for _, w := range res.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	if res.Error != "" {
+		return fmt.Errorf("%s", res.Error)
+	}
+	fmt.Print(res.Output)
+	fmt.Fprintf(os.Stderr, "rendered %s with %s (~%d tokens)\n", res.Template, fixturePath, res.Tokens)
+	return nil
+}
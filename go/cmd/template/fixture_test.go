@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/journal"
+)
+
+func TestBuildFixtureRedactsCycle(t *testing.T) {
+	cfg := &executorpkg.Config{MajorCoinLeverage: 10, AltcoinLeverage: 5, MinConfidence: 70, MinRiskReward: 2, MaxPositions: 3, SigningKey: "secret"}
+	rec := &journal.CycleRecord{
+		Timestamp:    time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		TraderID:     "trader-alpha",
+		CoTTrace:     "private reasoning",
+		ErrorMessage: "boom",
+		Account:      map[string]any{"equity": 1000.0, "available": 800.0},
+		Candidates:   []string{"btc"},
+		MarketDigest: map[string]any{"BTC": map[string]any{"price": 50000.0}},
+	}
+
+	fixture := buildFixture(cfg, redactCycle(rec), fixtureMeta{Name: "sample"})
+	raw, err := json.Marshal(fixture)
+	require.NoError(t, err)
+
+	body := string(raw)
+	assert.NotContains(t, body, "secret")
+	assert.NotContains(t, body, "trader-alpha")
+	assert.NotContains(t, body, "private reasoning")
+	assert.Equal(t, "2025-01-02T03:04:05Z", fixture["CurrentTime"])
+	assert.Contains(t, fixture["AccountOverview"], "equity=1000.00")
+	assert.Contains(t, fixture["CandidateCoins"], "BTC")
+	// The original record must be left untouched.
+	assert.Equal(t, "trader-alpha", rec.TraderID)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"nof0-api/pkg/llm"
+)
+
+// lintResult is the outcome of linting one template.
+type lintResult struct {
+	Template string
+	Issues   []llm.TemplateIssue
+}
+
+func runLint(args []string) error {
+	fsFlags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	typeName := fsFlags.String("type", "", "Check field references against this data type (see template schema --list); defaults to the template's front-matter data_type")
+	if err := fsFlags.Parse(args); err != nil {
+		return err
+	}
+	if fsFlags.NArg() == 0 {
+		return fmt.Errorf("usage: template lint [--type NAME] <file|dir>...")
+	}
+	var typ reflect.Type
+	if strings.TrimSpace(*typeName) != "" {
+		var err error
+		if _, typ, err = lookupDataType(promptDataTypes(), *typeName); err != nil {
+			return err
+		}
+	}
+	var paths []string
+	for _, arg := range fsFlags.Args() {
+		found, err := lintTargets(arg)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, found...)
+	}
+	results, err := lintTemplates(paths, typ)
+	if err != nil {
+		return err
+	}
+	if n := writeLintResults(os.Stdout, results); n > 0 {
+		return fmt.Errorf("%d problem(s) in %d template(s)", n, len(results))
+	}
+	fmt.Fprintf(os.Stderr, "%d template(s) ok\n", len(paths))
+	return nil
+}
+
+// lintTargets expands a file or directory argument into template paths.
+func lintTargets(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	found, err := discoverTemplates(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("%s: %w", path, errNoTemplates)
+	}
+	return found, nil
+}
+
+// lintTemplates lints each template, checking fields against typ or, when
+// typ is nil, the front-matter data_type. Only templates with issues are
+// returned.
+func lintTemplates(paths []string, typ reflect.Type) ([]lintResult, error) {
+	types := promptDataTypes()
+	var results []lintResult
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read template %s: %w", path, err)
+		}
+		dataType := typ
+		var issues []llm.TemplateIssue
+		if dataType == nil {
+			// A malformed front-matter is reported by LintTemplate itself.
+			if meta, err := llm.LoadTemplateMeta(path); err == nil && meta.DataType != "" {
+				if dataType = types[meta.DataType]; dataType == nil {
+					issues = append(issues, llm.TemplateIssue{
+						Line:    1,
+						Kind:    llm.IssueFrontMatter,
+						Message: fmt.Sprintf("unknown data_type %q (see template schema --list)", meta.DataType),
+					})
+				}
+			}
+		}
+		issues = append(issues, llm.LintTemplate(path, src, nil, dataType)...)
+		if len(issues) > 0 {
+			results = append(results, lintResult{Template: path, Issues: issues})
+		}
+	}
+	return results, nil
+}
+
+// writeLintResults prints one "path:line:col: message" line per issue and
+// returns how many were printed.
+func writeLintResults(w io.Writer, results []lintResult) int {
+	n := 0
+	for _, r := range results {
+		for _, issue := range r.Issues {
+			fmt.Fprintf(w, "%s:%s\n", r.Template, issue)
+			n++
+		}
+	}
+	return n
+}
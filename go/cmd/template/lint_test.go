@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintTemplatesReportsPositions(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.tmpl")
+	bad := filepath.Join(dir, "nested", "bad.tmpl")
+	require.NoError(t, os.MkdirAll(filepath.Dir(bad), 0o755))
+	require.NoError(t, os.WriteFile(good, []byte("hello {{ .Name }}"), 0o644))
+	require.NoError(t, os.WriteFile(bad, []byte("---\ndata_type: nope.Missing\n---\nhello {{ upper .Nmae }}"), 0o644))
+
+	paths, err := lintTargets(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{good, bad}, paths)
+
+	type greeting struct{ Name string }
+	results, err := lintTemplates(paths, reflect.TypeOf(greeting{}))
+	require.NoError(t, err)
+	require.Len(t, results, 1, "good.tmpl has no issues")
+	var out bytes.Buffer
+	assert.Equal(t, 2, writeLintResults(&out, results))
+	assert.Equal(t, bad+`:4:10: function "upper" not defined`+"\n"+
+		bad+`:4:16: main.greeting has no field or method "Nmae"`+"\n", out.String())
+
+	results, err = lintTemplates([]string{bad}, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Contains(t, results[0].Issues[0].Message, `unknown data_type "nope.Missing"`)
+	assert.Len(t, results[0].Issues, 2, "fields are unchecked without a known type")
+}
+
+func TestLintTargetsRejectsEmptyDir(t *testing.T) {
+	_, err := lintTargets(t.TempDir())
+	assert.ErrorIs(t, err, errNoTemplates)
+}
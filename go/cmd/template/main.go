@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// command is a single `template` subcommand.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{name: "render", summary: "Render one template against a fixture, optionally decoded into its data type", run: runRender},
+	{name: "diff", summary: "Render two templates with the same data and print a unified diff of the outputs", run: runDiff},
+	{name: "lint", summary: "Check templates for syntax errors, unknown functions and unknown fields", run: runLint},
+	{name: "watch", summary: "Serve a live preview of rendered prompt templates", run: runWatch},
+	{name: "fixture", summary: "Save, list and apply prompt data fixtures", run: runFixture},
+	{name: "pack", summary: "Export and import shareable template packs", run: runPack},
+	{name: "schema", summary: "Describe or export the data types templates render against", run: runSchema},
+	{name: "test", summary: "Run YAML behaviour specs against rendered templates", run: runTest},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	if name == "-h" || name == "--help" || name == "help" {
+		usage()
+		return
+	}
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "template %s: %v\n", name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: template <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+}
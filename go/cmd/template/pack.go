@@ -0,0 +1,456 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"nof0-api/pkg/llm"
+)
+
+const (
+	packManifestName = "manifest.json"
+	packFormat       = 1
+	// packMaxFileSize bounds each archive entry so a hostile pack cannot
+	// exhaust memory on import.
+	packMaxFileSize = 4 << 20
+)
+
+// packKind classifies pack contents; each kind has its own archive root and
+// install directory.
+type packKind string
+
+const (
+	packTemplate packKind = "template"
+	packFixture  packKind = "fixture"
+	packSchema   packKind = "schema"
+)
+
+var packRoots = map[packKind]string{
+	packTemplate: "templates",
+	packFixture:  "fixtures",
+	packSchema:   "schema",
+}
+
+// packManifest describes a template pack. Every archive entry besides the
+// manifest must be listed with its digest.
+type packManifest struct {
+	Format      int        `json:"format"`
+	Name        string     `json:"name"`
+	Version     string     `json:"version,omitempty"`
+	Author      string     `json:"author,omitempty"`
+	Description string     `json:"description,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Files       []packFile `json:"files"`
+}
+
+// packFile is one manifest entry. Path is slash-separated and relative to
+// the kind's root, e.g. "executor/default_prompt.tmpl".
+type packFile struct {
+	Path            string   `json:"path"`
+	Kind            packKind `json:"kind"`
+	SHA256          string   `json:"sha256"`
+	Size            int64    `json:"size"`
+	TemplateVersion string   `json:"template_version,omitempty"`
+}
+
+func (f packFile) archivePath() string { return packRoots[f.Kind] + "/" + f.Path }
+
+// packSources are the directories a pack is exported from or imported into.
+type packSources struct {
+	TemplateDir string
+	FixtureDir  string
+	// SchemaPath is the output schema file on export and its directory on
+	// import. Empty leaves the schema out.
+	SchemaPath string
+}
+
+func runPack(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: template pack <export|import> [flags]")
+	}
+	switch args[0] {
+	case "export":
+		return runPackExport(args[1:])
+	case "import":
+		return runPackImport(args[1:])
+	default:
+		return fmt.Errorf("unknown pack command %q (want export or import)", args[0])
+	}
+}
+
+func runPackExport(args []string) error {
+	fsFlags := flag.NewFlagSet("pack export", flag.ContinueOnError)
+	var (
+		name        = fsFlags.String("name", "", "Pack name (required)")
+		version     = fsFlags.String("version", "", "Pack version")
+		author      = fsFlags.String("author", "", "Pack author")
+		description = fsFlags.String("description", "", "Short description of the pack")
+		dir         = fsFlags.String("dir", "etc/prompts", "Directory containing prompt templates")
+		dataDir     = fsFlags.String("data", "fixtures", "Fixture directory (skipped when absent)")
+		schemaPath  = fsFlags.String("schema", "schemas/decision_output.json", "Output schema baseline (empty to omit)")
+		out         = fsFlags.String("o", "", "Output archive (defaults to <name>.tar.gz)")
+	)
+	if err := fsFlags.Parse(args); err != nil {
+		return err
+	}
+	if !fixtureNameRegexp.MatchString(*name) {
+		return fmt.Errorf("invalid pack name %q", *name)
+	}
+	if *out == "" {
+		*out = *name + ".tar.gz"
+	}
+	m := packManifest{
+		Format:      packFormat,
+		Name:        *name,
+		Version:     *version,
+		Author:      *author,
+		Description: *description,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+	contents, err := collectPack(&m, packSources{TemplateDir: *dir, FixtureDir: *dataDir, SchemaPath: *schemaPath})
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := writePack(f, m, contents); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("exported %s: %s\n", *out, packSummary(m))
+	return nil
+}
+
+// collectPack reads the pack files from src, appends them to m and returns
+// their contents keyed by archive path.
+func collectPack(m *packManifest, src packSources) (map[string][]byte, error) {
+	contents := make(map[string][]byte)
+	add := func(kind packKind, rel, file string) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		pf := packFile{Path: filepath.ToSlash(rel), Kind: kind, SHA256: sha256Hex(data), Size: int64(len(data))}
+		if kind == packTemplate {
+			pf.TemplateVersion, _ = llm.ExtractTemplateVersion(file, 0)
+		}
+		m.Files = append(m.Files, pf)
+		contents[pf.archivePath()] = data
+		return nil
+	}
+
+	templates, err := discoverTemplates(src.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("%w in %s", errNoTemplates, src.TemplateDir)
+	}
+	for _, file := range templates {
+		rel, err := filepath.Rel(src.TemplateDir, file)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(packTemplate, rel, file); err != nil {
+			return nil, err
+		}
+	}
+
+	if entries, err := os.ReadDir(src.FixtureDir); err == nil {
+		for _, ent := range entries {
+			if ent.IsDir() || !strings.HasSuffix(ent.Name(), fixtureExt) {
+				continue
+			}
+			if err := add(packFixture, ent.Name(), filepath.Join(src.FixtureDir, ent.Name())); err != nil {
+				return nil, err
+			}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("list fixtures in %s: %w", src.FixtureDir, err)
+	}
+
+	if src.SchemaPath != "" {
+		if err := add(packSchema, filepath.Base(src.SchemaPath), src.SchemaPath); err != nil {
+			return nil, fmt.Errorf("schema baseline: %w", err)
+		}
+	}
+	return contents, nil
+}
+
+// writePack writes a gzipped tarball holding the manifest followed by the
+// files in manifest order.
+func writePack(w io.Writer, m packManifest, contents map[string][]byte) error {
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	write := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: m.CreatedAt, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := write(packManifestName, manifest); err != nil {
+		return err
+	}
+	for _, f := range m.Files {
+		if err := write(f.archivePath(), contents[f.archivePath()]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// readPack reads and verifies a pack: every entry must be a regular file
+// under a known root, listed in the manifest with a matching digest.
+func readPack(r io.Reader) (*packManifest, map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open pack: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	var manifest []byte
+	contents := make(map[string][]byte)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("read pack: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, nil, fmt.Errorf("pack entry %s: only regular files are allowed", hdr.Name)
+		}
+		if !validPackPath(hdr.Name) {
+			return nil, nil, fmt.Errorf("pack entry %s: unsafe path", hdr.Name)
+		}
+		if hdr.Size > packMaxFileSize {
+			return nil, nil, fmt.Errorf("pack entry %s: %d bytes exceeds the %d byte limit", hdr.Name, hdr.Size, packMaxFileSize)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, packMaxFileSize+1))
+		if err != nil {
+			return nil, nil, fmt.Errorf("read pack entry %s: %w", hdr.Name, err)
+		}
+		if hdr.Name == packManifestName {
+			manifest = data
+			continue
+		}
+		if _, dup := contents[hdr.Name]; dup {
+			return nil, nil, fmt.Errorf("pack entry %s: duplicate", hdr.Name)
+		}
+		contents[hdr.Name] = data
+	}
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("pack has no %s", packManifestName)
+	}
+	var m packManifest
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		return nil, nil, fmt.Errorf("decode %s: %w", packManifestName, err)
+	}
+	if m.Format != packFormat {
+		return nil, nil, fmt.Errorf("unsupported pack format %d (want %d)", m.Format, packFormat)
+	}
+
+	listed := make(map[string]struct{}, len(m.Files))
+	for _, f := range m.Files {
+		if _, ok := packRoots[f.Kind]; !ok {
+			return nil, nil, fmt.Errorf("manifest entry %s: unknown kind %q", f.Path, f.Kind)
+		}
+		name := f.archivePath()
+		if !validPackPath(f.Path) || !validPackPath(name) {
+			return nil, nil, fmt.Errorf("manifest entry %s: unsafe path", f.Path)
+		}
+		data, ok := contents[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("manifest entry %s: missing from pack", name)
+		}
+		if got := sha256Hex(data); got != f.SHA256 {
+			return nil, nil, fmt.Errorf("manifest entry %s: digest %s does not match manifest %s", name, got, f.SHA256)
+		}
+		listed[name] = struct{}{}
+	}
+	for name := range contents {
+		if _, ok := listed[name]; !ok {
+			return nil, nil, fmt.Errorf("pack entry %s: not listed in manifest", name)
+		}
+	}
+	return &m, contents, nil
+}
+
+func runPackImport(args []string) error {
+	fsFlags := flag.NewFlagSet("pack import", flag.ContinueOnError)
+	var (
+		in        = fsFlags.String("in", "", "Pack archive to import (required)")
+		dir       = fsFlags.String("dir", "etc/prompts", "Directory to install templates into")
+		dataDir   = fsFlags.String("data", "fixtures", "Directory to install fixtures into")
+		schemaDir = fsFlags.String("schema-dir", "schemas", "Directory to install the schema baseline into")
+		force     = fsFlags.Bool("force", false, "Overwrite existing files that differ from the pack")
+		dryRun    = fsFlags.Bool("dry-run", false, "Verify the pack and print the plan without writing")
+	)
+	if err := fsFlags.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" {
+		return fmt.Errorf("--in is required")
+	}
+	f, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	m, contents, err := readPack(f)
+	if err != nil {
+		return err
+	}
+	if err := checkPackContents(m, contents); err != nil {
+		return err
+	}
+
+	plan, err := planPackImport(m, contents, packSources{TemplateDir: *dir, FixtureDir: *dataDir, SchemaPath: *schemaDir})
+	if err != nil {
+		return err
+	}
+	var conflicts []string
+	for _, step := range plan {
+		if step.action == "overwrite" {
+			conflicts = append(conflicts, step.dest)
+		}
+	}
+	if len(conflicts) > 0 && !*force && !*dryRun {
+		return fmt.Errorf("pack would overwrite %d modified file(s): %s (use --force)", len(conflicts), strings.Join(conflicts, ", "))
+	}
+	for _, step := range plan {
+		fmt.Printf("%-9s %s\n", step.action, step.dest)
+		if *dryRun || step.action == "unchanged" {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(step.dest), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(step.dest, step.data, 0o644); err != nil {
+			return err
+		}
+	}
+	verb := "imported"
+	if *dryRun {
+		verb = "verified"
+	}
+	fmt.Printf("%s %s: %s\n", verb, *in, packSummary(*m))
+	return nil
+}
+
+// checkPackContents parses every template and checks that fixtures and the
+// schema are JSON, so a broken pack is rejected before anything is written.
+// Templates are staged together so tier layouts resolve as they would once
+// installed.
+func checkPackContents(m *packManifest, contents map[string][]byte) error {
+	staging, err := os.MkdirTemp("", "template-pack-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+	for _, f := range m.Files {
+		data := contents[f.archivePath()]
+		if f.Kind != packTemplate {
+			if !json.Valid(data) {
+				return fmt.Errorf("pack %s %s: invalid JSON", f.Kind, f.Path)
+			}
+			continue
+		}
+		dest := filepath.Join(staging, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dest, data, 0o600); err != nil {
+			return err
+		}
+		if _, err := llm.NewPromptTemplate(dest, nil); err != nil {
+			return fmt.Errorf("pack template %s: %w", f.Path, err)
+		}
+	}
+	return nil
+}
+
+type packStep struct {
+	action string // create, overwrite or unchanged
+	dest   string
+	data   []byte
+}
+
+func planPackImport(m *packManifest, contents map[string][]byte, dst packSources) ([]packStep, error) {
+	roots := map[packKind]string{packTemplate: dst.TemplateDir, packFixture: dst.FixtureDir, packSchema: dst.SchemaPath}
+	plan := make([]packStep, 0, len(m.Files))
+	for _, f := range m.Files {
+		step := packStep{action: "create", dest: filepath.Join(roots[f.Kind], filepath.FromSlash(f.Path)), data: contents[f.archivePath()]}
+		existing, err := os.ReadFile(step.dest)
+		switch {
+		case err == nil && bytes.Equal(existing, step.data):
+			step.action = "unchanged"
+		case err == nil:
+			step.action = "overwrite"
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+		plan = append(plan, step)
+	}
+	sort.SliceStable(plan, func(i, j int) bool { return plan[i].dest < plan[j].dest })
+	return plan, nil
+}
+
+// validPackPath accepts clean, relative, slash-separated paths that stay
+// inside the directory they are joined to.
+func validPackPath(p string) bool {
+	if p == "" || strings.HasPrefix(p, "/") || strings.Contains(p, `\`) {
+		return false
+	}
+	return path.Clean(p) == p && p != ".." && !strings.HasPrefix(p, "../")
+}
+
+func packSummary(m packManifest) string {
+	counts := make(map[packKind]int)
+	for _, f := range m.Files {
+		counts[f.Kind]++
+	}
+	label := m.Name
+	if m.Version != "" {
+		label += "@" + m.Version
+	}
+	return fmt.Sprintf("%s (%d template(s), %d fixture(s), %d schema)", label, counts[packTemplate], counts[packFixture], counts[packSchema])
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestPackRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	writeFile(t, filepath.Join(src, "prompts/executor/default_prompt.tmpl"), "{{/* Version: v1.0.0 */}}\nhello {{ .Name }}")
+	writeFile(t, filepath.Join(src, "fixtures/default.json"), `{"Name": "pack"}`)
+	writeFile(t, filepath.Join(src, "schemas/decision_output.json"), `{"type": "object"}`)
+
+	m := packManifest{Format: packFormat, Name: "demo"}
+	contents, err := collectPack(&m, packSources{
+		TemplateDir: filepath.Join(src, "prompts"),
+		FixtureDir:  filepath.Join(src, "fixtures"),
+		SchemaPath:  filepath.Join(src, "schemas/decision_output.json"),
+	})
+	require.NoError(t, err)
+	require.Len(t, m.Files, 3)
+	assert.Equal(t, "executor/default_prompt.tmpl", m.Files[0].Path)
+	assert.Equal(t, "v1.0.0", m.Files[0].TemplateVersion)
+
+	var buf bytes.Buffer
+	require.NoError(t, writePack(&buf, m, contents))
+	got, gotContents, err := readPack(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, m.Files, got.Files)
+	require.NoError(t, checkPackContents(got, gotContents))
+
+	dst := t.TempDir()
+	plan, err := planPackImport(got, gotContents, packSources{
+		TemplateDir: filepath.Join(dst, "prompts"),
+		FixtureDir:  filepath.Join(dst, "fixtures"),
+		SchemaPath:  filepath.Join(dst, "schemas"),
+	})
+	require.NoError(t, err)
+	for _, step := range plan {
+		assert.Equal(t, "create", step.action, step.dest)
+	}
+	assert.Equal(t, filepath.Join(dst, "prompts/executor/default_prompt.tmpl"), plan[1].dest, "steps are sorted by destination")
+}
+
+func TestReadPackRejectsTampering(t *testing.T) {
+	m := packManifest{Format: packFormat, Name: "demo", Files: []packFile{
+		{Path: "a.tmpl", Kind: packTemplate, SHA256: sha256Hex([]byte("a")), Size: 1},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, writePack(&buf, m, map[string][]byte{"templates/a.tmpl": []byte("b")}))
+	_, _, err := readPack(&buf)
+	assert.ErrorContains(t, err, "does not match manifest")
+
+	buf.Reset()
+	require.NoError(t, writePack(&buf, packManifest{Format: packFormat, Name: "demo"}, nil))
+	_, _, err = readPack(appendEntry(t, buf.Bytes(), "templates/extra.tmpl", "x"))
+	assert.ErrorContains(t, err, "not listed in manifest")
+
+	_, _, err = readPack(appendEntry(t, buf.Bytes(), "../escape.tmpl", "x"))
+	assert.ErrorContains(t, err, "unsafe path")
+}
+
+func TestCheckPackContentsRejectsBrokenTemplate(t *testing.T) {
+	m := &packManifest{Files: []packFile{{Path: "bad.tmpl", Kind: packTemplate}}}
+	err := checkPackContents(m, map[string][]byte{"templates/bad.tmpl": []byte("{{ .Name ")})
+	assert.ErrorContains(t, err, "pack template bad.tmpl")
+}
+
+func TestValidPackPath(t *testing.T) {
+	for p, want := range map[string]bool{
+		"executor/a.tmpl": true,
+		"":                false,
+		"/etc/passwd":     false,
+		"../a.tmpl":       false,
+		"a/../../b":       false,
+		"a//b":            false,
+		`a\b`:             false,
+	} {
+		assert.Equal(t, want, validPackPath(p), p)
+	}
+}
+
+// appendEntry rebuilds a pack with one more entry after the existing ones.
+func appendEntry(t *testing.T, pack []byte, name, content string) *bytes.Buffer {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(pack))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err = tw.Write(mustReadAll(t, tr))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+	_, err = tw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return &out
+}
+
+func mustReadAll(t *testing.T, r *tar.Reader) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	_, err := buf.ReadFrom(r)
+	require.NoError(t, err)
+	return buf.Bytes()
+}
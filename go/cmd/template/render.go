@@ -0,0 +1,376 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"nof0-api/pkg/llm"
+)
+
+const (
+	templateExt        = ".tmpl"
+	fixtureExt         = ".json"
+	defaultFixtureName = "default"
+)
+
+// errNoTemplates is returned when a directory contains no templates.
+var errNoTemplates = errors.New("no templates found")
+
+// renderResult captures the outcome of rendering one template against its fixture.
+type renderResult struct {
+	Template string            `json:"template"`
+	Fixture  string            `json:"fixture,omitempty"`
+	Version  string            `json:"version,omitempty"`
+	Meta     *llm.TemplateMeta `json:"meta,omitempty"`
+	Output   string            `json:"output"`
+	Spans    []llm.Span        `json:"spans,omitempty"`
+	Tokens   int               `json:"tokens"`
+	Warnings []string          `json:"warnings,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// discoverTemplates returns every template file below dir in lexical order.
+func discoverTemplates(dir string) ([]string, error) {
+	var out []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), templateExt) {
+			out = append(out, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan templates in %s: %w", dir, err)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// resolveFixture picks the fixture for a template: <data>/<name>.json first,
+// then <data>/default.json. An empty path means no fixture was found.
+func resolveFixture(dataDir, templatePath string) string {
+	if strings.TrimSpace(dataDir) == "" {
+		return ""
+	}
+	base := strings.TrimSuffix(filepath.Base(templatePath), templateExt)
+	for _, name := range []string{base, defaultFixtureName} {
+		candidate := filepath.Join(dataDir, name+fixtureExt)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// loadFixture decodes a JSON fixture into generic template data.
+func loadFixture(path string) (map[string]any, error) {
+	data := map[string]any{}
+	if path == "" {
+		return data, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fixture %s: %w", path, err)
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("decode fixture %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// loadTypedFixture decodes a JSON fixture into a new value of typ so the
+// template sees the real struct and its methods. Fields typ does not declare
+// are rejected rather than silently dropped.
+func loadTypedFixture(path string, typ reflect.Type) (any, error) {
+	ptr := reflect.New(typ)
+	if path == "" {
+		return ptr.Interface(), nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fixture %s: %w", path, err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("decode fixture %s: %w", path, err)
+	}
+	// Saved fixtures carry their provenance, which is not template data.
+	delete(doc, "_fixture")
+	if raw, err = json.Marshal(doc); err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(ptr.Interface()); err != nil {
+		return nil, fmt.Errorf("decode fixture %s as %s: %w", path, typ, err)
+	}
+	return ptr.Interface(), nil
+}
+
+// lookupDataType finds a registered data type by its full name or, when
+// unambiguous, by the name after the package prefix.
+func lookupDataType(types map[string]reflect.Type, name string) (string, reflect.Type, error) {
+	name = strings.TrimSpace(name)
+	if typ, ok := types[name]; ok {
+		return name, typ, nil
+	}
+	var matches []string
+	for full := range types {
+		if i := strings.LastIndex(full, "."); i >= 0 && strings.EqualFold(full[i+1:], name) {
+			matches = append(matches, full)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], types[matches[0]], nil
+	case 0:
+		return "", nil, fmt.Errorf("unknown data type %q (see template schema --list)", name)
+	default:
+		sort.Strings(matches)
+		return "", nil, fmt.Errorf("data type %q is ambiguous: %s", name, strings.Join(matches, ", "))
+	}
+}
+
+func runRender(args []string) error {
+	fsFlags := flag.NewFlagSet("render", flag.ContinueOnError)
+	var (
+		templatePath = fsFlags.String("template", "", "Template to render (required)")
+		fixturePath  = fsFlags.String("fixture", "", "JSON data file; defaults to the fixture resolved from --data")
+		dataDir      = fsFlags.String("data", "fixtures", "Fixture directory searched for <template>.json, then default.json")
+		typeName     = fsFlags.String("type", "", "Decode the data into this type (see template schema --list) instead of a generic map")
+		batchDir     = fsFlags.String("data-dir", "", "Render against every JSON file in this directory instead of one fixture; requires --out-dir")
+		outDir       = fsFlags.String("out-dir", "", "Directory receiving one <fixture>.txt per --data-dir fixture")
+	)
+	if err := fsFlags.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*templatePath) == "" {
+		return fmt.Errorf("usage: template render --template PATH [--fixture data.json | --data DIR | --data-dir DIR --out-dir DIR] [--type NAME]")
+	}
+	var typ reflect.Type
+	if strings.TrimSpace(*typeName) != "" {
+		var err error
+		if _, typ, err = lookupDataType(promptDataTypes(), *typeName); err != nil {
+			return err
+		}
+	}
+	if *batchDir != "" || *outDir != "" {
+		if *batchDir == "" || *outDir == "" {
+			return fmt.Errorf("--data-dir and --out-dir must be used together")
+		}
+		if *fixturePath != "" {
+			return fmt.Errorf("--fixture cannot be combined with --data-dir")
+		}
+		return runRenderBatch(*templatePath, *batchDir, *outDir, typ)
+	}
+	fixture := *fixturePath
+	if fixture == "" {
+		fixture = resolveFixture(*dataDir, *templatePath)
+	}
+	var res renderResult
+	if typ == nil {
+		res = renderWithFixture(*templatePath, fixture)
+	} else {
+		res = renderTyped(*templatePath, fixture, typ)
+	}
+	for _, w := range res.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	if res.Error != "" {
+		return fmt.Errorf("%s", res.Error)
+	}
+	fmt.Print(res.Output)
+	fmt.Fprintf(os.Stderr, "rendered %s (~%d tokens)\n", res.Template, res.Tokens)
+	return nil
+}
+
+func runRenderBatch(templatePath, dataDir, outDir string, typ reflect.Type) error {
+	results, err := renderBatch(templatePath, dataDir, outDir, typ)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, res := range results {
+		for _, w := range res.Warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s: %s\n", res.Fixture, w)
+		}
+		if res.Error != "" {
+			failed++
+			fmt.Fprintf(os.Stderr, "error: %s: %s\n", res.Fixture, res.Error)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "rendered %s -> %s (~%d tokens)\n", res.Fixture, batchOutputPath(outDir, res.Fixture), res.Tokens)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d fixture(s) failed to render", failed, len(results))
+	}
+	return nil
+}
+
+// renderBatch renders templatePath against every fixture in dataDir, in
+// lexical order, and writes each output to outDir as <fixture>.txt. Render
+// failures are reported on the results and write no file; only listing or
+// writing failures are returned.
+func renderBatch(templatePath, dataDir, outDir string, typ reflect.Type) ([]renderResult, error) {
+	names, err := listFixtures(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no %s fixtures in %s", fixtureExt, dataDir)
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create %s: %w", outDir, err)
+	}
+	results := make([]renderResult, 0, len(names))
+	for _, name := range names {
+		fixture := filepath.Join(dataDir, name)
+		var res renderResult
+		if typ == nil {
+			res = renderWithFixture(templatePath, fixture)
+		} else {
+			res = renderTyped(templatePath, fixture, typ)
+		}
+		if res.Error == "" {
+			if err := os.WriteFile(batchOutputPath(outDir, fixture), []byte(res.Output), 0o644); err != nil {
+				return nil, fmt.Errorf("write rendered %s: %w", name, err)
+			}
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func batchOutputPath(outDir, fixture string) string {
+	return filepath.Join(outDir, strings.TrimSuffix(filepath.Base(fixture), fixtureExt)+".txt")
+}
+
+// renderTemplate renders templatePath with the fixture resolved from dataDir.
+// Failures are reported on the result rather than returned so callers can
+// present every template in one pass.
+func renderTemplate(templatePath, dataDir string) renderResult {
+	return renderWithFixture(templatePath, resolveFixture(dataDir, templatePath))
+}
+
+// renderWithFixture renders templatePath against an explicit fixture file.
+func renderWithFixture(templatePath, fixturePath string) renderResult {
+	res := renderResult{Template: templatePath, Fixture: fixturePath}
+	if res.Fixture == "" {
+		res.Warnings = append(res.Warnings, "no fixture found; rendering with empty data")
+	}
+	data, err := loadFixture(res.Fixture)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	return renderData(res, data)
+}
+
+// renderTyped renders templatePath against a fixture decoded into typ.
+func renderTyped(templatePath, fixturePath string, typ reflect.Type) renderResult {
+	res := renderResult{Template: templatePath, Fixture: fixturePath}
+	if res.Fixture == "" {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("no fixture found; rendering with a zero %s", typ))
+	}
+	data, err := loadTypedFixture(res.Fixture, typ)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	return renderData(res, data)
+}
+
+// renderData renders res.Template against data, filling in the rest of res.
+func renderData(res renderResult, data any) renderResult {
+	version, err := llm.ExtractTemplateVersion(res.Template, 0)
+	if err != nil {
+		res.Warnings = append(res.Warnings, "missing {{/* Version: ... */}} header")
+	}
+	res.Version = version
+
+	tmpl, err := llm.NewPromptTemplate(res.Template, nil)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if meta := tmpl.Meta(); meta.DataType != "" || meta.Author != "" || meta.Version != "" || len(meta.Models) > 0 || len(meta.Funcs) > 0 {
+		res.Meta = &meta
+	}
+	out, spans, err := tmpl.RenderSpans(data)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Output = out
+	res.Spans = spans
+	res.Tokens = estimateTokens(out)
+	res.Warnings = append(res.Warnings, lintOutput(out)...)
+	return res
+}
+
+// outputSegment is a run of rendered output; Field is set when an action
+// printed it.
+type outputSegment struct {
+	Text   string
+	Field  string
+	Source string
+}
+
+// Segments splits Output at its spans so the preview can highlight values
+// that came from fixture data.
+func (r renderResult) Segments() []outputSegment {
+	var segs []outputSegment
+	last := 0
+	for _, s := range r.Spans {
+		if s.Start < last || s.End > len(r.Output) || s.Start >= s.End {
+			continue
+		}
+		if s.Start > last {
+			segs = append(segs, outputSegment{Text: r.Output[last:s.Start]})
+		}
+		segs = append(segs, outputSegment{Text: r.Output[s.Start:s.End], Field: s.Field, Source: s.Source})
+		last = s.End
+	}
+	if last < len(r.Output) {
+		segs = append(segs, outputSegment{Text: r.Output[last:]})
+	}
+	return segs
+}
+
+// lintOutput flags common rendering mistakes visible in the final prompt.
+func lintOutput(out string) []string {
+	var warnings []string
+	if strings.TrimSpace(out) == "" {
+		warnings = append(warnings, "rendered output is empty")
+	}
+	if n := strings.Count(out, "<no value>"); n > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d field(s) rendered as <no value>", n))
+	}
+	trailing := 0
+	for _, line := range strings.Split(out, "\n") {
+		if line != strings.TrimRight(line, " \t") {
+			trailing++
+		}
+	}
+	if trailing > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d line(s) with trailing whitespace", trailing))
+	}
+	return warnings
+}
+
+// estimateTokens approximates the token count of text for preview purposes,
+// using the same heuristic as the executor's prompt budget check.
+func estimateTokens(text string) int {
+	return llm.EstimateTokens(text)
+}
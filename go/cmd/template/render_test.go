@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTemplateWithFixture(t *testing.T) {
+	dir := t.TempDir()
+	dataDir := t.TempDir()
+	tmplPath := filepath.Join(dir, "greet.tmpl")
+	require.NoError(t, os.WriteFile(tmplPath, []byte("{{/* Version: v1.0.0 */}}\nhello {{ .Name }}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "greet.json"), []byte(`{"Name":"nof0"}`), 0o644))
+
+	res := renderTemplate(tmplPath, dataDir)
+	assert.Empty(t, res.Error)
+	assert.Equal(t, "v1.0.0", res.Version)
+	assert.Equal(t, "\nhello nof0", res.Output)
+	assert.Equal(t, estimateTokens(res.Output), res.Tokens)
+	assert.Empty(t, res.Warnings)
+}
+
+func TestRenderTemplateFallsBackToDefaultFixture(t *testing.T) {
+	dir := t.TempDir()
+	dataDir := t.TempDir()
+	tmplPath := filepath.Join(dir, "greet.tmpl")
+	require.NoError(t, os.WriteFile(tmplPath, []byte("hello {{ .Name }} "), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "default.json"), []byte(`{"Name":"x"}`), 0o644))
+
+	res := renderTemplate(tmplPath, dataDir)
+	assert.Equal(t, filepath.Join(dataDir, "default.json"), res.Fixture)
+	assert.Contains(t, res.Warnings, "missing {{/* Version: ... */}} header")
+	assert.Contains(t, res.Warnings, "1 line(s) with trailing whitespace")
+}
+
+func TestRenderTemplateReportsMissingKeys(t *testing.T) {
+	dir := t.TempDir()
+	tmplPath := filepath.Join(dir, "greet.tmpl")
+	require.NoError(t, os.WriteFile(tmplPath, []byte("hello {{ .Name }}"), 0o644))
+
+	res := renderTemplate(tmplPath, "")
+	assert.NotEmpty(t, res.Error)
+	assert.Contains(t, res.Warnings, "no fixture found; rendering with empty data")
+}
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, estimateTokens(""))
+	assert.Equal(t, 1, estimateTokens("abc"))
+	assert.Equal(t, 2, estimateTokens("abcde"))
+}
+
+func TestRenderTemplateFrontMatter(t *testing.T) {
+	dir := t.TempDir()
+	tmplPath := filepath.Join(dir, "meta.tmpl")
+	require.NoError(t, os.WriteFile(tmplPath, []byte("---\nauthor: quant-desk\nversion: v2.0.0\n---\nhello"), 0o644))
+
+	res := renderTemplate(tmplPath, "")
+	require.Empty(t, res.Error)
+	assert.Equal(t, "hello", res.Output)
+	assert.Equal(t, "v2.0.0", res.Version)
+	require.NotNil(t, res.Meta)
+	assert.Equal(t, "quant-desk", res.Meta.Author)
+}
+
+type pct float64
+
+func (p pct) String() string { return fmt.Sprintf("%.1f%%", float64(p)*100) }
+
+type typedData struct {
+	Name string
+	Risk pct
+}
+
+func TestRenderTypedFixture(t *testing.T) {
+	dir := t.TempDir()
+	tmplPath := filepath.Join(dir, "typed.tmpl")
+	require.NoError(t, os.WriteFile(tmplPath, []byte("{{/* Version: v1.0.0 */}}\n{{ .Name }} risks {{ .Risk }}"), 0o644))
+	fixture := filepath.Join(dir, "typed.json")
+	require.NoError(t, os.WriteFile(fixture, []byte(`{"_fixture":{"source":"journal"},"Name":"nof0","Risk":0.025}`), 0o644))
+
+	res := renderTyped(tmplPath, fixture, reflect.TypeOf(typedData{}))
+	require.Empty(t, res.Error)
+	assert.Equal(t, "\nnof0 risks 2.5%", res.Output, "String() formats typed fields")
+
+	// The same fixture as a map prints the raw float.
+	res = renderWithFixture(tmplPath, fixture)
+	require.Empty(t, res.Error)
+	assert.Equal(t, "\nnof0 risks 0.025", res.Output)
+
+	require.NoError(t, os.WriteFile(fixture, []byte(`{"Name":"nof0","Rsik":0.025}`), 0o644))
+	res = renderTyped(tmplPath, fixture, reflect.TypeOf(typedData{}))
+	assert.Contains(t, res.Error, `unknown field "Rsik"`)
+}
+
+func TestRenderBatchWritesOnePromptPerFixture(t *testing.T) {
+	dir := t.TempDir()
+	dataDir := filepath.Join(dir, "fixtures")
+	outDir := filepath.Join(dir, "rendered", "typed")
+	require.NoError(t, os.MkdirAll(dataDir, 0o755))
+	tmplPath := filepath.Join(dir, "typed.tmpl")
+	require.NoError(t, os.WriteFile(tmplPath, []byte("{{ .Name }} risks {{ .Risk }}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "calm.json"), []byte(`{"Name":"calm","Risk":0.01}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "crash.json"), []byte(`{"Name":"crash","Risk":0.05}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "typo.json"), []byte(`{"Nmae":"x"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "notes.md"), []byte("ignored"), 0o644))
+
+	results, err := renderBatch(tmplPath, dataDir, outDir, reflect.TypeOf(typedData{}))
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Empty(t, results[0].Error)
+	assert.Contains(t, results[2].Error, `unknown field "Nmae"`)
+
+	calm, err := os.ReadFile(filepath.Join(outDir, "calm.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "calm risks 1.0%", string(calm))
+	crash, err := os.ReadFile(filepath.Join(outDir, "crash.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "crash risks 5.0%", string(crash))
+	assert.NoFileExists(t, filepath.Join(outDir, "typo.txt"), "failed renders write nothing")
+
+	_, err = renderBatch(tmplPath, outDir, outDir, nil)
+	assert.ErrorContains(t, err, "no .json fixtures")
+}
+
+func TestLookupDataType(t *testing.T) {
+	types := map[string]reflect.Type{
+		"executor.PromptInputs":       reflect.TypeOf(typedData{}),
+		"manager.ManagerPromptInputs": reflect.TypeOf(typedData{}),
+		"alert.PromptInputs":          reflect.TypeOf(typedData{}),
+	}
+	name, _, err := lookupDataType(types, "managerpromptinputs")
+	require.NoError(t, err)
+	assert.Equal(t, "manager.ManagerPromptInputs", name)
+	name, _, err = lookupDataType(types, "executor.PromptInputs")
+	require.NoError(t, err)
+	assert.Equal(t, "executor.PromptInputs", name)
+	_, _, err = lookupDataType(types, "PromptInputs")
+	assert.ErrorContains(t, err, "ambiguous")
+	_, _, err = lookupDataType(types, "SystemPromptData")
+	assert.ErrorContains(t, err, "unknown data type")
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	// Imported for the data types their init functions register.
+	_ "nof0-api/pkg/executor"
+	"nof0-api/pkg/llm"
+	_ "nof0-api/pkg/manager"
+)
+
+const (
+	schemaFormatText       = "text"
+	schemaFormatJSONSchema = "jsonschema"
+)
+
+// promptDataTypes maps the data_type names templates declare in front-matter
+// to the Go types they render against, as registered by the init functions
+// of the imported packages.
+func promptDataTypes() map[string]reflect.Type {
+	return llm.DataTypes().Types()
+}
+
+func runSchema(args []string) error {
+	fsFlags := flag.NewFlagSet("schema", flag.ContinueOnError)
+	var (
+		typeName     = fsFlags.String("type", "", "Data type name (see --list)")
+		templatePath = fsFlags.String("template", "", "Template whose front-matter data_type selects the type")
+		format       = fsFlags.String("format", schemaFormatText, "Output format: text or jsonschema")
+		check        = fsFlags.String("check", "", "Validate this JSON data file against the schema instead of printing it")
+		list         = fsFlags.Bool("list", false, "List the known data types")
+		src          = fsFlags.String("src", "", "Comma-separated Go package dirs whose doc comments describe undocumented fields, e.g. pkg/executor,pkg/market")
+	)
+	if err := fsFlags.Parse(args); err != nil {
+		return err
+	}
+	types := promptDataTypes()
+	if *list {
+		names := make([]string, 0, len(types))
+		for name := range types {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	name, err := schemaTypeName(*typeName, *templatePath)
+	if err != nil {
+		return err
+	}
+	typ, ok := types[name]
+	if !ok {
+		return fmt.Errorf("unknown data type %q (see --list)", name)
+	}
+	docs, err := loadSourceDocs(*src)
+	if err != nil {
+		return err
+	}
+	if *check != "" {
+		return checkDataFile(os.Stdout, name, typ, *check)
+	}
+	switch *format {
+	case schemaFormatText:
+		return writeSchemaText(os.Stdout, typ, docs)
+	case schemaFormatJSONSchema:
+		schema, err := dataTypeSchema(name, typ, llm.WithSourceDocs(docs))
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(schema)
+	default:
+		return fmt.Errorf("unknown format %q (want %s or %s)", *format, schemaFormatText, schemaFormatJSONSchema)
+	}
+}
+
+// schemaTypeName resolves the data type from --type or the template's
+// front-matter.
+func schemaTypeName(typeName, templatePath string) (string, error) {
+	if strings.TrimSpace(typeName) != "" {
+		return strings.TrimSpace(typeName), nil
+	}
+	if strings.TrimSpace(templatePath) == "" {
+		return "", fmt.Errorf("usage: template schema --type NAME | --template PATH [--format text|jsonschema] [--check data.json]")
+	}
+	tmpl, err := llm.NewPromptTemplate(templatePath, nil)
+	if err != nil {
+		return "", err
+	}
+	if tmpl.Meta().DataType == "" {
+		return "", fmt.Errorf("template %s declares no data_type; pass --type", templatePath)
+	}
+	return tmpl.Meta().DataType, nil
+}
+
+// loadSourceDocs reads the doc comments of the --src package dirs.
+func loadSourceDocs(src string) (llm.SourceDocs, error) {
+	var dirs []string
+	for _, dir := range strings.Split(src, ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 {
+		return nil, nil
+	}
+	return llm.LoadSourceDocs(dirs...)
+}
+
+// dataTypeSchema is the JSON Schema of typ titled with its registered name.
+func dataTypeSchema(name string, typ reflect.Type, opts ...llm.SchemaOption) (map[string]any, error) {
+	schema, err := llm.ExportJSONSchema(typ, opts...)
+	if err != nil {
+		return nil, err
+	}
+	schema["title"] = name
+	return schema, nil
+}
+
+// checkDataFile validates a template data file, e.g. a fixture, against the
+// schema of typ and lists every violation.
+func checkDataFile(w io.Writer, name string, typ reflect.Type, path string) error {
+	schema, err := dataTypeSchema(name, typ)
+	if err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read data file %s: %w", path, err)
+	}
+	result, err := gojsonschema.Validate(gojsonschema.NewGoLoader(schema), gojsonschema.NewBytesLoader(raw))
+	if err != nil {
+		return fmt.Errorf("validate %s: %w", path, err)
+	}
+	if result.Valid() {
+		fmt.Fprintf(w, "%s matches %s\n", path, name)
+		return nil
+	}
+	for _, e := range result.Errors() {
+		fmt.Fprintf(w, "%s: %s\n", e.Field(), e.Description())
+	}
+	return fmt.Errorf("%s does not match %s (%d error(s))", path, name, len(result.Errors()))
+}
+
+// writeSchemaText lists every field path templates can reference with its
+// Go type and description.
+func writeSchemaText(w io.Writer, typ reflect.Type, docs llm.SourceDocs) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tTYPE\tDESCRIPTION")
+	writeSchemaFields(tw, typ, docs, "", map[reflect.Type]bool{})
+	return tw.Flush()
+}
+
+func writeSchemaFields(w io.Writer, typ reflect.Type, docs llm.SourceDocs, prefix string, visiting map[reflect.Type]bool) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || typ == reflect.TypeOf(time.Time{}) || visiting[typ] {
+		return
+	}
+	visiting[typ] = true
+	defer delete(visiting, typ)
+	for _, f := range reflect.VisibleFields(typ) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		owner := typ
+		if len(f.Index) > 1 {
+			// Promoted fields are documented on the embedded type.
+			owner = typ.FieldByIndex(f.Index[:len(f.Index)-1]).Type
+		}
+		path := prefix + "." + f.Name
+		fmt.Fprintf(w, "%s\t%s\t%s\n", path, f.Type, docs.FieldDescription(owner, f))
+		writeSchemaFields(w, f.Type, docs, path, visiting)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/journal"
+)
+
+func TestCheckDataFileAcceptsSavedFixtures(t *testing.T) {
+	cfg := &executorpkg.Config{MajorCoinLeverage: 10, AltcoinLeverage: 5, MinConfidence: 70, MinRiskReward: 2, MaxPositions: 3}
+	rec := &journal.CycleRecord{Timestamp: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), Candidates: []string{"btc"}}
+	raw, err := json.Marshal(buildFixture(cfg, rec, fixtureMeta{Name: "sample"}))
+	require.NoError(t, err)
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	require.NoError(t, os.WriteFile(good, raw, 0o644))
+
+	typ := executorpkg.PromptDataType()
+	var out bytes.Buffer
+	require.NoError(t, checkDataFile(&out, "executor.PromptInputs", typ, good))
+	assert.Contains(t, out.String(), "matches executor.PromptInputs")
+
+	bad := filepath.Join(dir, "bad.json")
+	require.NoError(t, os.WriteFile(bad, []byte(`{"CurrentTime":"now","RuntimeMinutes":"ten","Config":{"MinConfidence":1.5}}`), 0o644))
+	out.Reset()
+	err = checkDataFile(&out, "executor.PromptInputs", typ, bad)
+	require.Error(t, err)
+	assert.Contains(t, out.String(), "RuntimeMinutes")
+	assert.Contains(t, out.String(), "Config.MinConfidence")
+	assert.Contains(t, out.String(), "AccountOverview is required")
+}
+
+func TestPromptDataTypesComeFromPackageRegistrations(t *testing.T) {
+	types := promptDataTypes()
+	assert.Equal(t, executorpkg.PromptDataType(), types["executor.PromptInputs"])
+	assert.Contains(t, types, "manager.ManagerPromptInputs")
+	assert.Contains(t, types, "alert.trade_executed")
+	assert.Contains(t, types, "manager.TraderConfig", "nested types are registered too")
+
+	name, _, err := lookupDataType(types, "TraderConfig")
+	require.NoError(t, err)
+	assert.Equal(t, "manager.TraderConfig", name)
+}
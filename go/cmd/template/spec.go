@@ -0,0 +1,226 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templateSpec is a YAML behaviour test for one template. Paths are relative
+// to the spec file.
+type templateSpec struct {
+	Template string `yaml:"template"`
+	// Fixture is the default fixture for cases that do not name their own.
+	Fixture string     `yaml:"fixture"`
+	Cases   []specCase `yaml:"cases"`
+}
+
+// specCase renders the template once and checks the output. Data is merged
+// over the fixture's top-level keys, so a case can tweak a captured fixture
+// or supply all data inline.
+type specCase struct {
+	Name        string         `yaml:"name"`
+	Fixture     string         `yaml:"fixture"`
+	Data        map[string]any `yaml:"data"`
+	Contains    []string       `yaml:"contains"`
+	NotContains []string       `yaml:"not_contains"`
+	MaxTokens   int            `yaml:"max_tokens"`
+}
+
+// caseResult is the outcome of one spec case; it passed when Failures is empty.
+type caseResult struct {
+	Spec     string
+	Name     string
+	Tokens   int
+	Failures []string
+}
+
+func runTest(args []string) error {
+	fsFlags := flag.NewFlagSet("test", flag.ContinueOnError)
+	var (
+		run     = fsFlags.String("run", "", "Only run cases whose name matches this regular expression")
+		verbose = fsFlags.Bool("v", false, "Report passing cases as well as failures")
+	)
+	fsFlags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: template test [flags] [spec.yaml|dir ...]  (default: etc/prompt-tests)")
+		fsFlags.PrintDefaults()
+	}
+	if err := fsFlags.Parse(args); err != nil {
+		return err
+	}
+	var filter *regexp.Regexp
+	if *run != "" {
+		re, err := regexp.Compile(*run)
+		if err != nil {
+			return fmt.Errorf("invalid -run pattern: %w", err)
+		}
+		filter = re
+	}
+	targets := fsFlags.Args()
+	if len(targets) == 0 {
+		targets = []string{"etc/prompt-tests"}
+	}
+	specs, err := discoverSpecs(targets)
+	if err != nil {
+		return err
+	}
+
+	var passed, failed int
+	for _, path := range specs {
+		results, err := runSpecFile(path, filter)
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			if len(r.Failures) == 0 {
+				passed++
+				if *verbose {
+					fmt.Printf("PASS  %s: %s (~%d tokens)\n", r.Spec, r.Name, r.Tokens)
+				}
+				continue
+			}
+			failed++
+			fmt.Printf("FAIL  %s: %s\n", r.Spec, r.Name)
+			for _, f := range r.Failures {
+				fmt.Printf("      %s\n", f)
+			}
+		}
+	}
+	fmt.Printf("%d passed, %d failed\n", passed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d case(s) failed", failed)
+	}
+	return nil
+}
+
+// discoverSpecs expands directories to the YAML specs below them in lexical
+// order; files are taken as given.
+func discoverSpecs(targets []string) ([]string, error) {
+	var out []string
+	for _, target := range targets {
+		info, err := os.Stat(target)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			out = append(out, target)
+			continue
+		}
+		var found []string
+		err = filepath.WalkDir(target, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && (strings.HasSuffix(d.Name(), ".yaml") || strings.HasSuffix(d.Name(), ".yml")) {
+				found = append(found, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("scan specs in %s: %w", target, err)
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no specs found in %s", target)
+		}
+		sort.Strings(found)
+		out = append(out, found...)
+	}
+	return out, nil
+}
+
+// loadSpec reads a spec and resolves its paths against the spec's directory.
+func loadSpec(path string) (*templateSpec, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read spec %s: %w", path, err)
+	}
+	var spec templateSpec
+	if err := yaml.Unmarshal(raw, &spec); err != nil {
+		return nil, fmt.Errorf("decode spec %s: %w", path, err)
+	}
+	if strings.TrimSpace(spec.Template) == "" {
+		return nil, fmt.Errorf("spec %s: template is required", path)
+	}
+	if len(spec.Cases) == 0 {
+		return nil, fmt.Errorf("spec %s: no cases", path)
+	}
+	base := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(base, p)
+	}
+	spec.Template = resolve(spec.Template)
+	spec.Fixture = resolve(spec.Fixture)
+	for i := range spec.Cases {
+		c := &spec.Cases[i]
+		if strings.TrimSpace(c.Name) == "" {
+			c.Name = fmt.Sprintf("case %d", i+1)
+		}
+		c.Fixture = resolve(c.Fixture)
+		if c.MaxTokens < 0 {
+			return nil, fmt.Errorf("spec %s: case %q: max_tokens must not be negative", path, c.Name)
+		}
+	}
+	return &spec, nil
+}
+
+// runSpecFile runs the cases of one spec that match filter (nil runs all).
+func runSpecFile(path string, filter *regexp.Regexp) ([]caseResult, error) {
+	spec, err := loadSpec(path)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]caseResult, 0, len(spec.Cases))
+	for _, c := range spec.Cases {
+		if filter != nil && !filter.MatchString(c.Name) {
+			continue
+		}
+		results = append(results, runSpecCase(path, spec, c))
+	}
+	return results, nil
+}
+
+func runSpecCase(specPath string, spec *templateSpec, c specCase) caseResult {
+	res := caseResult{Spec: specPath, Name: c.Name}
+	fixture := c.Fixture
+	if fixture == "" {
+		fixture = spec.Fixture
+	}
+	data, err := loadFixture(fixture)
+	if err != nil {
+		res.Failures = append(res.Failures, err.Error())
+		return res
+	}
+	for k, v := range c.Data {
+		data[k] = v
+	}
+	out := renderData(renderResult{Template: spec.Template, Fixture: fixture}, data)
+	if out.Error != "" {
+		res.Failures = append(res.Failures, "render: "+out.Error)
+		return res
+	}
+	res.Tokens = out.Tokens
+	for _, want := range c.Contains {
+		if !strings.Contains(out.Output, want) {
+			res.Failures = append(res.Failures, fmt.Sprintf("missing %q", want))
+		}
+	}
+	for _, banned := range c.NotContains {
+		if strings.Contains(out.Output, banned) {
+			res.Failures = append(res.Failures, fmt.Sprintf("contains forbidden %q", banned))
+		}
+	}
+	if c.MaxTokens > 0 && out.Tokens > c.MaxTokens {
+		res.Failures = append(res.Failures, fmt.Sprintf("~%d tokens exceeds max_tokens %d", out.Tokens, c.MaxTokens))
+	}
+	return res
+}
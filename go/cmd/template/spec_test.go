@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSpecFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "prompts"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "prompts", "greet.tmpl"), []byte("hello {{ .Name }} from {{ .Desk }}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "desk.json"), []byte(`{"Name":"fixture","Desk":"quant"}`), 0o644))
+	specPath := filepath.Join(dir, "greet.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(`
+template: prompts/greet.tmpl
+fixture: desk.json
+cases:
+  - name: uses fixture
+    contains: ["hello fixture", "quant"]
+  - name: inline data overrides fixture keys
+    data: {Name: inline}
+    contains: ["hello inline from quant"]
+    not_contains: ["fixture"]
+  - name: every check reports
+    contains: ["goodbye"]
+    not_contains: ["hello"]
+    max_tokens: 2
+  - name: render errors fail the case
+    fixture: missing.json
+`), 0o644))
+
+	results, err := runSpecFile(specPath, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.Empty(t, results[0].Failures)
+	assert.Empty(t, results[1].Failures)
+	assert.Equal(t, []string{`missing "goodbye"`, `contains forbidden "hello"`, "~6 tokens exceeds max_tokens 2"}, results[2].Failures)
+	require.Len(t, results[3].Failures, 1)
+	assert.Contains(t, results[3].Failures[0], "missing.json")
+
+	results, err = runSpecFile(specPath, regexp.MustCompile("inline"))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "inline data overrides fixture keys", results[0].Name)
+}
+
+func TestLoadSpecValidates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bad.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("cases: [{name: x}]\n"), 0o644))
+	_, err := loadSpec(path)
+	assert.ErrorContains(t, err, "template is required")
+
+	require.NoError(t, os.WriteFile(path, []byte("template: x.tmpl\n"), 0o644))
+	_, err = loadSpec(path)
+	assert.ErrorContains(t, err, "no cases")
+}
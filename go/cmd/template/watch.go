@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// watchState holds the latest render results and the fingerprint of the
+// watched files they were produced from.
+type watchState struct {
+	dir     string
+	dataDir string
+
+	mu          sync.RWMutex
+	fingerprint uint64
+	generation  int
+	renderedAt  time.Time
+	results     []renderResult
+}
+
+func runWatch(args []string) error {
+	fsFlags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	var (
+		dir      = fsFlags.String("dir", "etc/prompts", "Directory containing prompt templates")
+		dataDir  = fsFlags.String("data", "fixtures", "Directory containing JSON fixtures")
+		addr     = fsFlags.String("addr", "127.0.0.1:7070", "Listen address for the preview server")
+		interval = fsFlags.Duration("interval", 500*time.Millisecond, "Polling interval for file changes")
+	)
+	if err := fsFlags.Parse(args); err != nil {
+		return err
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+
+	st := &watchState{dir: *dir, dataDir: *dataDir}
+	if err := st.refresh(true); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go st.poll(ctx, *interval)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", st.handleIndex)
+	mux.HandleFunc("/state", st.handleState)
+	srv := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("watching %s (fixtures: %s), preview at http://%s/", *dir, *dataDir, *addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (s *watchState) poll(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.refresh(false); err != nil {
+				log.Printf("refresh failed: %v", err)
+			}
+		}
+	}
+}
+
+// refresh re-renders all templates when the watched files changed, or always when force is set.
+func (s *watchState) refresh(force bool) error {
+	fp, err := fingerprint(s.dir, s.dataDir)
+	if err != nil {
+		return err
+	}
+	s.mu.RLock()
+	unchanged := fp == s.fingerprint && s.generation > 0
+	s.mu.RUnlock()
+	if unchanged && !force {
+		return nil
+	}
+
+	paths, err := discoverTemplates(s.dir)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("%w in %s", errNoTemplates, s.dir)
+	}
+	results := make([]renderResult, 0, len(paths))
+	for _, path := range paths {
+		results = append(results, renderTemplate(path, s.dataDir))
+	}
+
+	s.mu.Lock()
+	s.fingerprint = fp
+	s.generation++
+	s.renderedAt = time.Now().UTC()
+	s.results = results
+	gen := s.generation
+	s.mu.Unlock()
+
+	if gen > 1 {
+		log.Printf("change detected, re-rendered %d template(s)", len(results))
+	}
+	return nil
+}
+
+// fingerprint hashes path, size and mtime of every file under the given roots.
+// Missing roots are ignored so that an absent fixtures directory is not fatal.
+func fingerprint(roots ...string) (uint64, error) {
+	type entry struct {
+		path string
+		size int64
+		mod  int64
+	}
+	var entries []entry
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry{path: path, size: info.Size(), mod: info.ModTime().UnixNano()})
+			return nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("scan %s: %w", root, err)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].path < entries[j].path })
+	h := fnv.New64a()
+	for _, e := range entries {
+		fmt.Fprintf(h, "%s|%d|%d\n", e.path, e.size, e.mod)
+	}
+	return h.Sum64(), nil
+}
+
+type watchSnapshot struct {
+	Generation int            `json:"generation"`
+	RenderedAt time.Time      `json:"renderedAt"`
+	Results    []renderResult `json:"results"`
+}
+
+func (s *watchState) snapshot() watchSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return watchSnapshot{Generation: s.generation, RenderedAt: s.renderedAt, Results: s.results}
+}
+
+func (s *watchState) handleState(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(s.snapshot())
+}
+
+func (s *watchState) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := previewPage.Execute(w, s.snapshot()); err != nil {
+		log.Printf("render preview page: %v", err)
+	}
+}
+
+// previewPage polls /state and reloads itself when the generation changes.
+var previewPage = template.Must(template.New("preview").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>nof0 template preview</title>
+<style>
+body { font-family: sans-serif; margin: 1.5rem; }
+section { border: 1px solid #ccc; border-radius: 4px; margin-bottom: 1.5rem; padding: 0.75rem; }
+h2 { font-size: 1rem; margin: 0 0 0.5rem; }
+.meta { color: #555; font-size: 0.85rem; }
+.warn { color: #a15c00; }
+.err { color: #b00020; font-weight: bold; }
+mark { background: #fff3b0; }
+pre { background: #f6f6f6; padding: 0.75rem; overflow-x: auto; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<p class="meta">generation {{.Generation}} &middot; rendered {{.RenderedAt.Format "15:04:05"}} UTC</p>
+{{range .Results}}
+<section>
+<h2>{{.Template}}</h2>
+<p class="meta">fixture: {{if .Fixture}}{{.Fixture}}{{else}}(none){{end}} &middot; version: {{if .Version}}{{.Version}}{{else}}(none){{end}} &middot; ~{{.Tokens}} tokens</p>
+{{if .Error}}<p class="err">{{.Error}}</p>{{end}}
+{{range .Warnings}}<p class="warn">&#9888; {{.}}</p>{{end}}
+{{if .Output}}<pre>{{range .Segments}}{{if .Field}}<mark title="{{.Field}} ({{.Source}})">{{.Text}}</mark>{{else}}{{.Text}}{{end}}{{end}}</pre>{{end}}
+</section>
+{{end}}
+<script>
+const generation = {{.Generation}};
+setInterval(async () => {
+  try {
+    const res = await fetch("/state", { cache: "no-store" });
+    const body = await res.json();
+    if (body.generation !== generation) { location.reload(); }
+  } catch (e) {}
+}, 1000);
+</script>
+</body>
+</html>
+`))
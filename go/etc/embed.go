@@ -0,0 +1,11 @@
+// Package etc embeds the prompt templates so binaries can run without the
+// etc directory alongside them.
+package etc
+
+import "embed"
+
+// Prompts holds etc/prompts; paths are relative to etc, e.g.
+// "prompts/executor/default_prompt.tmpl", matching manager.yaml.
+//
+//go:embed prompts
+var Prompts embed.FS
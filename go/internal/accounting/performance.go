@@ -0,0 +1,66 @@
+// Package accounting snapshots trader accounts on a schedule and derives the
+// performance figures decision prompts report from the snapshot history.
+package accounting
+
+import (
+	"math"
+	"time"
+)
+
+const year = 365 * 24 * time.Hour
+
+// Performance is derived from an equity series sampled at a fixed interval.
+type Performance struct {
+	ReturnPct      float64 // change from the initial equity, percent
+	SharpeRatio    float64 // annualised, zero risk-free rate
+	MaxDrawdownPct float64 // largest peak-to-trough fall, percent
+}
+
+// ComputePerformance measures equity, oldest first, against initial. The
+// Sharpe ratio annualises per-interval returns and stays 0 until there are
+// at least two returns with non-zero spread.
+func ComputePerformance(equity []float64, initial float64, interval time.Duration) Performance {
+	var perf Performance
+	if len(equity) == 0 {
+		return perf
+	}
+	if initial > 0 {
+		perf.ReturnPct = 100 * (equity[len(equity)-1]/initial - 1)
+	}
+
+	peak := 0.0
+	for _, v := range equity {
+		if v > peak {
+			peak = v
+		}
+		if peak > 0 {
+			if dd := 100 * (peak - v) / peak; dd > perf.MaxDrawdownPct {
+				perf.MaxDrawdownPct = dd
+			}
+		}
+	}
+
+	rets := make([]float64, 0, len(equity)-1)
+	for i := 1; i < len(equity); i++ {
+		if equity[i-1] > 0 {
+			rets = append(rets, equity[i]/equity[i-1]-1)
+		}
+	}
+	if len(rets) < 2 || interval <= 0 {
+		return perf
+	}
+	mean := 0.0
+	for _, r := range rets {
+		mean += r
+	}
+	mean /= float64(len(rets))
+	variance := 0.0
+	for _, r := range rets {
+		variance += (r - mean) * (r - mean)
+	}
+	sd := math.Sqrt(variance / float64(len(rets)-1))
+	if sd > 0 {
+		perf.SharpeRatio = mean / sd * math.Sqrt(float64(year)/float64(interval))
+	}
+	return perf
+}
@@ -0,0 +1,37 @@
+package accounting
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputePerformance(t *testing.T) {
+	perf := ComputePerformance([]float64{100, 110, 99, 121}, 100, 24*time.Hour)
+	assert.InDelta(t, 21, perf.ReturnPct, 1e-9)
+	assert.InDelta(t, 10, perf.MaxDrawdownPct, 1e-9)
+
+	// Returns 0.1, -0.1, 0.2222: mean 0.0741, sample sd 0.1611.
+	rets := []float64{0.1, -0.1, 121.0/99 - 1}
+	mean := (rets[0] + rets[1] + rets[2]) / 3
+	var variance float64
+	for _, r := range rets {
+		variance += (r - mean) * (r - mean)
+	}
+	want := mean / math.Sqrt(variance/2) * math.Sqrt(365)
+	assert.InDelta(t, want, perf.SharpeRatio, 1e-9)
+}
+
+func TestComputePerformanceDegenerate(t *testing.T) {
+	assert.Equal(t, Performance{}, ComputePerformance(nil, 100, time.Hour))
+
+	perf := ComputePerformance([]float64{100, 105}, 0, time.Hour)
+	assert.Zero(t, perf.ReturnPct, "no initial equity")
+	assert.Zero(t, perf.SharpeRatio, "a single return has no spread")
+
+	perf = ComputePerformance([]float64{100, 100, 100}, 100, time.Hour)
+	assert.Zero(t, perf.SharpeRatio)
+	assert.Zero(t, perf.MaxDrawdownPct)
+}
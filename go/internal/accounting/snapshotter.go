@@ -0,0 +1,354 @@
+package accounting
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/internal/model"
+	executorpkg "nof0-api/pkg/executor"
+	managerpkg "nof0-api/pkg/manager"
+)
+
+// AccountSource reads trader accounts; *manager.Manager implements it.
+type AccountSource interface {
+	TraderAccounts(ctx context.Context) []managerpkg.TraderAccount
+}
+
+// AccountSourceFunc adapts a function to AccountSource, e.g. to bind a
+// manager constructed after the snapshotter.
+type AccountSourceFunc func(ctx context.Context) []managerpkg.TraderAccount
+
+// TraderAccounts implements AccountSource.
+func (f AccountSourceFunc) TraderAccounts(ctx context.Context) []managerpkg.TraderAccount {
+	return f(ctx)
+}
+
+// SnapshotStore persists account snapshots. model.AccountSnapshotsModel
+// implements it.
+type SnapshotStore interface {
+	Upsert(ctx context.Context, row *model.AccountSnapshots) error
+	ListSince(ctx context.Context, provider string, since time.Time) ([]*model.AccountSnapshots, error)
+	Latest(ctx context.Context, provider string) (*model.AccountSnapshots, error)
+}
+
+// SnapshotterConfig configures a Snapshotter.
+type SnapshotterConfig struct {
+	// Interval is how often accounts are snapshotted; defaults to 5m.
+	Interval time.Duration
+	// Lookback is the history Sharpe ratio and max drawdown are computed
+	// over; defaults to 30 days.
+	Lookback time.Duration
+}
+
+const (
+	defaultSnapshotInterval = 5 * time.Minute
+	defaultSnapshotLookback = 30 * 24 * time.Hour
+	snapshotTimeout         = 30 * time.Second
+)
+
+// SnapshotDetail is the detail document of a trader's account_snapshots row.
+type SnapshotDetail struct {
+	EquityUSD        float64                      `json:"equity_usd"`
+	CashUSD          float64                      `json:"cash_usd"`
+	MarginUsedUSD    float64                      `json:"margin_used_usd"`
+	UnrealizedPnLUSD float64                      `json:"unrealized_pnl_usd"`
+	InitialEquityUSD float64                      `json:"initial_equity_usd"`
+	Positions        []managerpkg.AccountPosition `json:"positions"`
+	ReturnPct        float64                      `json:"return_pct"`
+	SharpeRatio      float64                      `json:"sharpe_ratio"`
+	MaxDrawdownPct   float64                      `json:"max_drawdown_pct"`
+	// Samples is the number of snapshots the metrics were computed from.
+	Samples int `json:"samples"`
+}
+
+type equityPoint struct {
+	at     time.Time
+	equity float64
+}
+
+// traderHistory is the in-memory snapshot history of one trader.
+type traderHistory struct {
+	seeded  bool
+	initial float64
+	points  []equityPoint // oldest first, within Lookback
+	latest  *managerpkg.AccountMetrics
+}
+
+// Snapshotter periodically records each trader's account value, cash and
+// positions into account_snapshots together with its return, Sharpe ratio
+// and max drawdown over the snapshot history. It implements
+// manager.AccountMetricsSource so decision cycles read those figures
+// instead of recomputing them.
+type Snapshotter struct {
+	source   AccountSource
+	store    SnapshotStore
+	interval time.Duration
+	lookback time.Duration
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	traders map[string]*traderHistory
+}
+
+var _ managerpkg.AccountMetricsSource = (*Snapshotter)(nil)
+
+// NewSnapshotter builds a snapshotter reading source. store may be nil to
+// keep the history in memory only.
+func NewSnapshotter(source AccountSource, store SnapshotStore, cfg SnapshotterConfig) (*Snapshotter, error) {
+	if source == nil {
+		return nil, fmt.Errorf("account snapshotter: source is required")
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultSnapshotInterval
+	}
+	lookback := cfg.Lookback
+	if lookback <= 0 {
+		lookback = defaultSnapshotLookback
+	}
+	if lookback < 2*interval {
+		return nil, fmt.Errorf("account snapshotter: lookback %s must cover at least two intervals of %s", lookback, interval)
+	}
+	return &Snapshotter{
+		source:   source,
+		store:    store,
+		interval: interval,
+		lookback: lookback,
+		now:      time.Now,
+		traders:  make(map[string]*traderHistory),
+	}, nil
+}
+
+// Run snapshots immediately and then every Interval until ctx is cancelled.
+func (s *Snapshotter) Run(ctx context.Context) {
+	s.snapshot(ctx)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.snapshot(ctx)
+		}
+	}
+}
+
+// LatestAccountMetrics implements manager.AccountMetricsSource. Before the
+// first snapshot of this process it falls back to the newest stored row.
+func (s *Snapshotter) LatestAccountMetrics(ctx context.Context, traderID string) (*managerpkg.AccountMetrics, error) {
+	s.mu.RLock()
+	h := s.traders[traderID]
+	var latest *managerpkg.AccountMetrics
+	if h != nil && h.latest != nil {
+		copied := *h.latest
+		latest = &copied
+	}
+	s.mu.RUnlock()
+	if latest != nil || s.store == nil {
+		return latest, nil
+	}
+	row, err := s.store.Latest(ctx, traderID)
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	detail, err := DecodeDetail(row)
+	if err != nil {
+		return nil, err
+	}
+	return metricsFromDetail(detail, row.EventAt), nil
+}
+
+func (s *Snapshotter) snapshot(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, snapshotTimeout)
+	defer cancel()
+	// Snapshots of one tick share an event time aligned to the interval, so
+	// a restart within the same interval replaces rather than adds a row.
+	eventAt := s.now().UTC().Truncate(s.interval)
+	for _, acct := range s.source.TraderAccounts(ctx) {
+		s.seed(ctx, acct.TraderID)
+		detail := s.record(acct, eventAt)
+		if s.store == nil {
+			continue
+		}
+		raw, err := json.Marshal(detail)
+		if err != nil {
+			logx.WithContext(ctx).Errorf("account snapshotter: encode trader=%s err=%v", acct.TraderID, err)
+			continue
+		}
+		row := &model.AccountSnapshots{
+			Provider: sql.NullString{String: acct.TraderID, Valid: true},
+			IsTrader: true,
+			Detail:   string(raw),
+			EventAt:  eventAt,
+		}
+		if err := s.store.Upsert(ctx, row); err != nil {
+			logx.WithContext(ctx).Errorf("account snapshotter: store trader=%s err=%v", acct.TraderID, err)
+		}
+	}
+}
+
+// seed loads the stored history of a trader within Lookback once.
+func (s *Snapshotter) seed(ctx context.Context, traderID string) {
+	s.mu.RLock()
+	h := s.traders[traderID]
+	done := h != nil && h.seeded
+	s.mu.RUnlock()
+	if done {
+		return
+	}
+	var (
+		points  []equityPoint
+		initial float64
+	)
+	if s.store != nil {
+		rows, err := s.store.ListSince(ctx, traderID, s.now().Add(-s.lookback))
+		if err != nil {
+			// Retried on the next tick; metrics start from live snapshots meanwhile.
+			logx.WithContext(ctx).Errorf("account snapshotter: seed trader=%s err=%v", traderID, err)
+			return
+		}
+		for _, row := range rows {
+			detail, err := DecodeDetail(row)
+			if err != nil {
+				continue
+			}
+			points = append(points, equityPoint{at: row.EventAt, equity: detail.EquityUSD})
+			initial = detail.InitialEquityUSD
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h = s.history(traderID)
+	h.seeded = true
+	if initial > 0 && h.initial == 0 {
+		h.initial = initial
+	}
+	if len(points) == 0 {
+		return
+	}
+	newest := points[len(points)-1].at
+	for _, p := range h.points {
+		if p.at.After(newest) {
+			points = append(points, p)
+		}
+	}
+	h.points = points
+}
+
+// record appends acct to its trader's history and returns the snapshot
+// detail with metrics over the history.
+func (s *Snapshotter) record(acct managerpkg.TraderAccount, eventAt time.Time) SnapshotDetail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := s.history(acct.TraderID)
+	switch {
+	case acct.InitialEquityUSD > 0:
+		h.initial = acct.InitialEquityUSD
+	case h.initial == 0 && len(h.points) > 0:
+		h.initial = h.points[0].equity
+	case h.initial == 0:
+		h.initial = acct.EquityUSD
+	}
+
+	// A repeated event time replaces the reading taken earlier in the interval.
+	if n := len(h.points); n > 0 && !h.points[n-1].at.Before(eventAt) {
+		h.points = h.points[:n-1]
+	}
+	h.points = append(h.points, equityPoint{at: eventAt, equity: acct.EquityUSD})
+	cutoff := eventAt.Add(-s.lookback)
+	drop := 0
+	for drop < len(h.points) && h.points[drop].at.Before(cutoff) {
+		drop++
+	}
+	h.points = h.points[drop:]
+
+	equity := make([]float64, len(h.points))
+	for i, p := range h.points {
+		equity[i] = p.equity
+	}
+	perf := ComputePerformance(equity, h.initial, s.interval)
+	detail := SnapshotDetail{
+		EquityUSD:        acct.EquityUSD,
+		CashUSD:          acct.CashUSD,
+		MarginUsedUSD:    acct.MarginUsedUSD,
+		UnrealizedPnLUSD: acct.UnrealizedPnLUSD,
+		InitialEquityUSD: h.initial,
+		Positions:        acct.Positions,
+		ReturnPct:        perf.ReturnPct,
+		SharpeRatio:      perf.SharpeRatio,
+		MaxDrawdownPct:   perf.MaxDrawdownPct,
+		Samples:          len(equity),
+	}
+	h.latest = metricsFromDetail(detail, eventAt)
+	return detail
+}
+
+// history returns the trader's history, creating it; s.mu must be held.
+func (s *Snapshotter) history(traderID string) *traderHistory {
+	h := s.traders[traderID]
+	if h == nil {
+		h = &traderHistory{}
+		s.traders[traderID] = h
+	}
+	return h
+}
+
+func metricsFromDetail(d SnapshotDetail, at time.Time) *managerpkg.AccountMetrics {
+	m := &managerpkg.AccountMetrics{
+		ReturnPct:      d.ReturnPct,
+		SharpeRatio:    d.SharpeRatio,
+		MaxDrawdownPct: d.MaxDrawdownPct,
+		AsOf:           at,
+	}
+	if d.InitialEquityUSD > 0 {
+		m.TotalPnLUSD = d.EquityUSD - d.InitialEquityUSD
+	}
+	return m
+}
+
+// DecodeDetail parses the detail document of an account snapshot row.
+func DecodeDetail(row *model.AccountSnapshots) (SnapshotDetail, error) {
+	var detail SnapshotDetail
+	if row == nil {
+		return detail, fmt.Errorf("account snapshot: nil row")
+	}
+	if err := json.Unmarshal([]byte(row.Detail), &detail); err != nil {
+		return detail, fmt.Errorf("account snapshot %d: decode detail: %w", row.Id, err)
+	}
+	return detail, nil
+}
+
+// AccountInfo builds the prompt's account summary from a snapshot row, so
+// callers holding the latest rows need not re-read the exchange. TotalPnL
+// is measured against the trader's initial equity.
+func AccountInfo(row *model.AccountSnapshots) (executorpkg.AccountInfo, error) {
+	detail, err := DecodeDetail(row)
+	if err != nil {
+		return executorpkg.AccountInfo{}, err
+	}
+	info := executorpkg.AccountInfo{
+		TotalEquity:      detail.EquityUSD,
+		AvailableBalance: detail.CashUSD,
+		TotalPnLPct:      detail.ReturnPct,
+		MarginUsed:       detail.MarginUsedUSD,
+		PositionCount:    len(detail.Positions),
+	}
+	if detail.InitialEquityUSD > 0 {
+		info.TotalPnL = detail.EquityUSD - detail.InitialEquityUSD
+	}
+	if detail.EquityUSD != 0 {
+		info.MarginUsedPct = 100 * detail.MarginUsedUSD / detail.EquityUSD
+	}
+	return info, nil
+}
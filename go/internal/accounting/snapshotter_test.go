@@ -0,0 +1,147 @@
+package accounting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/internal/model"
+	managerpkg "nof0-api/pkg/manager"
+)
+
+type memorySnapshotStore struct {
+	rows []*model.AccountSnapshots
+}
+
+func (m *memorySnapshotStore) Upsert(ctx context.Context, row *model.AccountSnapshots) error {
+	for i, r := range m.rows {
+		if r.Provider == row.Provider && r.EventAt.Equal(row.EventAt) {
+			m.rows[i] = row
+			return nil
+		}
+	}
+	m.rows = append(m.rows, row)
+	return nil
+}
+
+func (m *memorySnapshotStore) ListSince(ctx context.Context, provider string, since time.Time) ([]*model.AccountSnapshots, error) {
+	var out []*model.AccountSnapshots
+	for _, r := range m.rows {
+		if r.Provider.String == provider && !r.EventAt.Before(since) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (m *memorySnapshotStore) Latest(ctx context.Context, provider string) (*model.AccountSnapshots, error) {
+	var latest *model.AccountSnapshots
+	for _, r := range m.rows {
+		if r.Provider.String == provider && (latest == nil || r.EventAt.After(latest.EventAt)) {
+			latest = r
+		}
+	}
+	if latest == nil {
+		return nil, model.ErrNotFound
+	}
+	return latest, nil
+}
+
+func TestNewSnapshotterValidates(t *testing.T) {
+	_, err := NewSnapshotter(nil, nil, SnapshotterConfig{})
+	assert.Error(t, err)
+	src := AccountSourceFunc(func(context.Context) []managerpkg.TraderAccount { return nil })
+	_, err = NewSnapshotter(src, nil, SnapshotterConfig{Interval: time.Hour, Lookback: time.Hour})
+	assert.ErrorContains(t, err, "at least two intervals")
+}
+
+func TestSnapshotterRecordsMetrics(t *testing.T) {
+	ctx := context.Background()
+	equity := 1000.0
+	src := AccountSourceFunc(func(context.Context) []managerpkg.TraderAccount {
+		return []managerpkg.TraderAccount{{
+			TraderID:         "t1",
+			EquityUSD:        equity,
+			MarginUsedUSD:    200,
+			CashUSD:          equity - 200,
+			InitialEquityUSD: 1000,
+			Positions:        []managerpkg.AccountPosition{{Symbol: "BTC", Side: "long", Quantity: 0.1}},
+		}}
+	})
+	store := &memorySnapshotStore{}
+	s, err := NewSnapshotter(src, store, SnapshotterConfig{Interval: time.Hour, Lookback: 24 * time.Hour})
+	require.NoError(t, err)
+	now := time.Date(2025, 1, 1, 0, 10, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	for _, v := range []float64{1000, 1200, 900, 1100} {
+		equity = v
+		s.snapshot(ctx)
+		now = now.Add(time.Hour)
+	}
+	// A second reading within the interval replaces the first.
+	now = now.Add(-30 * time.Minute)
+	equity = 1050
+	s.snapshot(ctx)
+
+	require.Len(t, store.rows, 4)
+	assert.Equal(t, time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC), store.rows[3].EventAt)
+	detail, err := DecodeDetail(store.rows[3])
+	require.NoError(t, err)
+	assert.Equal(t, 4, detail.Samples)
+	assert.InDelta(t, 5, detail.ReturnPct, 1e-9)
+	assert.InDelta(t, 25, detail.MaxDrawdownPct, 1e-9)
+	assert.Len(t, detail.Positions, 1)
+
+	metrics, err := s.LatestAccountMetrics(ctx, "t1")
+	require.NoError(t, err)
+	require.NotNil(t, metrics)
+	assert.InDelta(t, 50, metrics.TotalPnLUSD, 1e-9)
+	assert.InDelta(t, detail.SharpeRatio, metrics.SharpeRatio, 1e-9)
+
+	info, err := AccountInfo(store.rows[3])
+	require.NoError(t, err)
+	assert.InDelta(t, 1050, info.TotalEquity, 1e-9)
+	assert.InDelta(t, 850, info.AvailableBalance, 1e-9)
+	assert.InDelta(t, 50, info.TotalPnL, 1e-9)
+	assert.InDelta(t, 5, info.TotalPnLPct, 1e-9)
+	assert.InDelta(t, 100*200/1050.0, info.MarginUsedPct, 1e-9)
+	assert.Equal(t, 1, info.PositionCount)
+}
+
+func TestSnapshotterSeedsFromStore(t *testing.T) {
+	ctx := context.Background()
+	store := &memorySnapshotStore{}
+	first, err := NewSnapshotter(AccountSourceFunc(func(context.Context) []managerpkg.TraderAccount {
+		return []managerpkg.TraderAccount{{TraderID: "t1", EquityUSD: 1000}}
+	}), store, SnapshotterConfig{Interval: time.Hour, Lookback: 24 * time.Hour})
+	require.NoError(t, err)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	first.now = func() time.Time { return now }
+	first.snapshot(ctx)
+
+	// A restarted snapshotter reports stored metrics until it snapshots,
+	// then measures against the stored baseline.
+	restarted, err := NewSnapshotter(AccountSourceFunc(func(context.Context) []managerpkg.TraderAccount {
+		return []managerpkg.TraderAccount{{TraderID: "t1", EquityUSD: 800}}
+	}), store, SnapshotterConfig{Interval: time.Hour, Lookback: 24 * time.Hour})
+	require.NoError(t, err)
+	restarted.now = func() time.Time { return now.Add(time.Hour) }
+	metrics, err := restarted.LatestAccountMetrics(ctx, "t1")
+	require.NoError(t, err)
+	require.NotNil(t, metrics)
+	assert.Equal(t, now, metrics.AsOf)
+
+	restarted.snapshot(ctx)
+	metrics, err = restarted.LatestAccountMetrics(ctx, "t1")
+	require.NoError(t, err)
+	assert.InDelta(t, -20, metrics.ReturnPct, 1e-9)
+	assert.InDelta(t, 20, metrics.MaxDrawdownPct, 1e-9)
+
+	metrics, err = restarted.LatestAccountMetrics(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Nil(t, metrics)
+}
@@ -0,0 +1,252 @@
+// Package archive moves old decision cycles and LLM conversation messages
+// out of the hot tables: rows are exported to an object store as gzip JSONL,
+// soft-deleted, and purged after a grace period, while every exported
+// object is recorded so a range can be restored.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultBatchSize = 5000
+
+// Table describes an archivable table. Rows need a BIGINT id primary key and
+// a nullable deleted_at column.
+type Table struct {
+	Name string
+	// TimeColumn ages rows for archival.
+	TimeColumn string
+}
+
+var tables = map[string]Table{
+	"decision_cycles":       {Name: "decision_cycles", TimeColumn: "executed_at"},
+	"conversation_messages": {Name: "conversation_messages", TimeColumn: "created_at"},
+}
+
+// LookupTable returns the archivable table called name.
+func LookupTable(name string) (Table, error) {
+	t, ok := tables[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Table{}, fmt.Errorf("archive: table %q is not archivable (want %s)", name, strings.Join(TableNames(), ", "))
+	}
+	return t, nil
+}
+
+// TableNames lists the archivable tables, sorted.
+func TableNames() []string {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Row is one table row as a JSON document.
+type Row struct {
+	ID  int64
+	At  time.Time
+	Doc json.RawMessage
+}
+
+// Batch records one exported object.
+type Batch struct {
+	Table      string
+	Key        string
+	FirstID    int64
+	LastID     int64
+	Rows       int
+	From       time.Time
+	To         time.Time
+	ArchivedAt time.Time
+	RestoredAt time.Time
+}
+
+// Store is the database side of archival; NewSQLStore implements it over
+// Postgres.
+type Store interface {
+	// Candidates returns up to limit live rows older than before, by id.
+	Candidates(ctx context.Context, t Table, before time.Time, limit int) ([]Row, error)
+	// MarkArchived soft-deletes ids and records b in one transaction.
+	MarkArchived(ctx context.Context, t Table, ids []int64, b Batch) error
+	// Purge hard-deletes rows soft-deleted before cutoff.
+	Purge(ctx context.Context, t Table, cutoff time.Time) (int64, error)
+	// Restore upserts docs and clears their deleted_at, then marks the batch
+	// restored.
+	Restore(ctx context.Context, t Table, key string, docs []json.RawMessage) (int64, error)
+	// Batches lists the recorded batches of table overlapping [from, to);
+	// zero bounds do not filter.
+	Batches(ctx context.Context, table string, from, to time.Time) ([]Batch, error)
+}
+
+// Archiver exports rows from Store to Objects.
+type Archiver struct {
+	Store   Store
+	Objects ObjectStore
+	// BatchSize caps rows per object; defaults to 5000.
+	BatchSize int
+	Now       func() time.Time
+}
+
+func (a *Archiver) batchSize() int {
+	if a.BatchSize > 0 {
+		return a.BatchSize
+	}
+	return defaultBatchSize
+}
+
+func (a *Archiver) now() time.Time {
+	if a.Now != nil {
+		return a.Now()
+	}
+	return time.Now()
+}
+
+// Archive exports and soft-deletes the live rows of t older than before, one
+// object per batch, and returns the batches written. An object is uploaded
+// before its rows are marked, so a failed run leaves rows live and a rerun
+// overwrites the same key.
+func (a *Archiver) Archive(ctx context.Context, t Table, before time.Time) ([]Batch, error) {
+	var out []Batch
+	for {
+		rows, err := a.Store.Candidates(ctx, t, before, a.batchSize())
+		if err != nil {
+			return out, fmt.Errorf("archive %s: select: %w", t.Name, err)
+		}
+		if len(rows) == 0 {
+			return out, nil
+		}
+		b := batchOf(t, rows)
+		var buf bytes.Buffer
+		if err := writeRows(&buf, rows); err != nil {
+			return out, fmt.Errorf("archive %s: encode: %w", t.Name, err)
+		}
+		if err := a.Objects.Put(ctx, b.Key, &buf); err != nil {
+			return out, fmt.Errorf("archive %s: upload %s: %w", t.Name, b.Key, err)
+		}
+		ids := make([]int64, len(rows))
+		for i, r := range rows {
+			ids[i] = r.ID
+		}
+		b.ArchivedAt = a.now().UTC()
+		if err := a.Store.MarkArchived(ctx, t, ids, b); err != nil {
+			return out, fmt.Errorf("archive %s: mark %s: %w", t.Name, b.Key, err)
+		}
+		out = append(out, b)
+		if len(rows) < a.batchSize() {
+			return out, nil
+		}
+	}
+}
+
+// Purge hard-deletes rows of t archived longer than grace ago.
+func (a *Archiver) Purge(ctx context.Context, t Table, grace time.Duration) (int64, error) {
+	n, err := a.Store.Purge(ctx, t, a.now().Add(-grace))
+	if err != nil {
+		return 0, fmt.Errorf("purge %s: %w", t.Name, err)
+	}
+	return n, nil
+}
+
+// Restore reloads the object under key into t and returns the rows written.
+// Restoring rows that were only soft-deleted makes them live again.
+func (a *Archiver) Restore(ctx context.Context, t Table, key string) (int64, error) {
+	rc, err := a.Objects.Get(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("restore %s: %w", key, err)
+	}
+	defer rc.Close()
+	docs, err := readRows(rc)
+	if err != nil {
+		return 0, fmt.Errorf("restore %s: decode: %w", key, err)
+	}
+	n, err := a.Store.Restore(ctx, t, key, docs)
+	if err != nil {
+		return 0, fmt.Errorf("restore %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// RestoreRange restores every recorded batch of t overlapping [from, to).
+func (a *Archiver) RestoreRange(ctx context.Context, t Table, from, to time.Time) (int64, error) {
+	batches, err := a.Store.Batches(ctx, t.Name, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("restore %s: list batches: %w", t.Name, err)
+	}
+	var total int64
+	for _, b := range batches {
+		n, err := a.Restore(ctx, t, b.Key)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// batchOf names the object of rows, ordered by id, as
+// <table>/<yyyy>/<mm>/<dd>/<first>-<last>.jsonl.gz keyed by the oldest row.
+func batchOf(t Table, rows []Row) Batch {
+	b := Batch{
+		Table:   t.Name,
+		FirstID: rows[0].ID,
+		LastID:  rows[len(rows)-1].ID,
+		Rows:    len(rows),
+		From:    rows[0].At,
+		To:      rows[0].At,
+	}
+	for _, r := range rows {
+		if r.At.Before(b.From) {
+			b.From = r.At
+		}
+		if r.At.After(b.To) {
+			b.To = r.At
+		}
+	}
+	b.Key = fmt.Sprintf("%s/%s/%d-%d.jsonl.gz", t.Name, b.From.UTC().Format("2006/01/02"), b.FirstID, b.LastID)
+	return b
+}
+
+func writeRows(w io.Writer, rows []Row) error {
+	zw := gzip.NewWriter(w)
+	for _, r := range rows {
+		if _, err := zw.Write(r.Doc); err != nil {
+			return err
+		}
+		if _, err := zw.Write([]byte{'\n'}); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func readRows(r io.Reader) ([]json.RawMessage, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	var docs []json.RawMessage
+	sc := bufio.NewScanner(zr)
+	sc.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if !json.Valid([]byte(line)) {
+			return nil, fmt.Errorf("line %d is not JSON", len(docs)+1)
+		}
+		docs = append(docs, json.RawMessage(line))
+	}
+	return docs, sc.Err()
+}
@@ -0,0 +1,157 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memRow struct {
+	at      time.Time
+	doc     string
+	deleted time.Time
+}
+
+// memoryStore keeps one table's rows by id.
+type memoryStore struct {
+	rows    map[int64]*memRow
+	batches []Batch
+}
+
+func newMemoryStore(base time.Time, n int) *memoryStore {
+	s := &memoryStore{rows: map[int64]*memRow{}}
+	for i := 1; i <= n; i++ {
+		at := base.Add(time.Duration(i) * time.Hour)
+		s.rows[int64(i)] = &memRow{at: at, doc: fmt.Sprintf(`{"id":%d,"executed_at":%q}`, i, at.Format(time.RFC3339))}
+	}
+	return s
+}
+
+func (s *memoryStore) ids() []int64 {
+	ids := make([]int64, 0, len(s.rows))
+	for id := range s.rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func (s *memoryStore) live() []int64 {
+	var out []int64
+	for _, id := range s.ids() {
+		if s.rows[id].deleted.IsZero() {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func (s *memoryStore) Candidates(ctx context.Context, t Table, before time.Time, limit int) ([]Row, error) {
+	var out []Row
+	for _, id := range s.live() {
+		r := s.rows[id]
+		if r.at.Before(before) && len(out) < limit {
+			out = append(out, Row{ID: id, At: r.at, Doc: json.RawMessage(r.doc)})
+		}
+	}
+	return out, nil
+}
+
+func (s *memoryStore) MarkArchived(ctx context.Context, t Table, ids []int64, b Batch) error {
+	for _, id := range ids {
+		s.rows[id].deleted = b.ArchivedAt
+	}
+	s.batches = append(s.batches, b)
+	return nil
+}
+
+func (s *memoryStore) Purge(ctx context.Context, t Table, cutoff time.Time) (int64, error) {
+	var n int64
+	for id, r := range s.rows {
+		if !r.deleted.IsZero() && r.deleted.Before(cutoff) {
+			delete(s.rows, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *memoryStore) Restore(ctx context.Context, t Table, key string, docs []json.RawMessage) (int64, error) {
+	for _, doc := range docs {
+		var row struct {
+			ID         int64     `json:"id"`
+			ExecutedAt time.Time `json:"executed_at"`
+		}
+		if err := json.Unmarshal(doc, &row); err != nil {
+			return 0, err
+		}
+		s.rows[row.ID] = &memRow{at: row.ExecutedAt, doc: string(doc)}
+	}
+	return int64(len(docs)), nil
+}
+
+func (s *memoryStore) Batches(ctx context.Context, table string, from, to time.Time) ([]Batch, error) {
+	var out []Batch
+	for _, b := range s.batches {
+		if (from.IsZero() || !b.To.Before(from)) && (to.IsZero() || b.From.Before(to)) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func TestArchivePurgeRestore(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	store := newMemoryStore(base, 5)
+	objects, err := OpenObjectStore(t.TempDir())
+	require.NoError(t, err)
+	now := base.Add(10 * 24 * time.Hour)
+	a := &Archiver{Store: store, Objects: objects, BatchSize: 2, Now: func() time.Time { return now }}
+	table, err := LookupTable("decision_cycles")
+	require.NoError(t, err)
+
+	// Rows 1-4 are older than the cutoff; 5 stays hot.
+	batches, err := a.Archive(ctx, table, base.Add(4*time.Hour+time.Minute))
+	require.NoError(t, err)
+	require.Len(t, batches, 2)
+	assert.Equal(t, "decision_cycles/2025/03/01/1-2.jsonl.gz", batches[0].Key)
+	assert.Equal(t, "decision_cycles/2025/03/01/3-4.jsonl.gz", batches[1].Key)
+	assert.Equal(t, base.Add(3*time.Hour), batches[1].From)
+	assert.Equal(t, []int64{5}, store.live())
+
+	// Nothing is purged inside the grace period.
+	n, err := a.Purge(ctx, table, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	now = now.Add(2 * 24 * time.Hour)
+	n, err = a.Purge(ctx, table, 24*time.Hour)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, n)
+	assert.Equal(t, []int64{5}, store.ids())
+
+	n, err = a.Restore(ctx, table, batches[1].Key)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	assert.Equal(t, []int64{3, 4, 5}, store.live())
+	assert.JSONEq(t, `{"id":3,"executed_at":"2025-03-01T03:00:00Z"}`, store.rows[3].doc)
+
+	n, err = a.RestoreRange(ctx, table, base, base.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, store.live())
+}
+
+func TestLookupTable(t *testing.T) {
+	table, err := LookupTable(" Conversation_Messages ")
+	require.NoError(t, err)
+	assert.Equal(t, "created_at", table.TimeColumn)
+	_, err = LookupTable("trades")
+	assert.ErrorContains(t, err, "not archivable")
+}
@@ -0,0 +1,130 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrObjectNotFound is returned by ObjectStore.Get for a missing key.
+var ErrObjectNotFound = errors.New("archive: object not found")
+
+// ObjectStore holds archived batches by key.
+type ObjectStore interface {
+	// Put stores the content of r under key, replacing any existing object.
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Get opens the object under key; callers close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// ObjectStoreBuilder constructs an ObjectStore from its location URL.
+type ObjectStoreBuilder func(u *url.URL) (ObjectStore, error)
+
+var (
+	objectStoreMu       sync.RWMutex
+	objectStoreBuilders = map[string]ObjectStoreBuilder{
+		"file": newFileObjectStore,
+		"s3":   newS3ObjectStore,
+	}
+)
+
+// RegisterObjectStore registers an object store backend for a URL scheme,
+// allowing out-of-tree implementations (e.g. GCS behind a build tag).
+func RegisterObjectStore(scheme string, builder ObjectStoreBuilder) {
+	objectStoreMu.Lock()
+	defer objectStoreMu.Unlock()
+	objectStoreBuilders[strings.ToLower(strings.TrimSpace(scheme))] = builder
+}
+
+// OpenObjectStore resolves location, e.g. file:///var/lib/nof0/archive or
+// s3://bucket/prefix. A bare path is treated as a file location.
+func OpenObjectStore(location string) (ObjectStore, error) {
+	location = strings.TrimSpace(location)
+	if location == "" {
+		return nil, fmt.Errorf("archive: object store location is required")
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("archive: parse object store location: %w", err)
+	}
+	if u.Scheme == "" {
+		u = &url.URL{Scheme: "file", Path: location}
+	}
+	objectStoreMu.RLock()
+	builder, ok := objectStoreBuilders[strings.ToLower(u.Scheme)]
+	objectStoreMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("archive: unknown object store scheme %q", u.Scheme)
+	}
+	return builder(u)
+}
+
+// fileObjectStore keeps objects under a local directory, e.g. a mounted
+// bucket or a staging disk synced elsewhere.
+type fileObjectStore struct {
+	root string
+}
+
+func newFileObjectStore(u *url.URL) (ObjectStore, error) {
+	root := u.Path
+	if u.Host != "" {
+		// file://relative/dir parses the first segment as host.
+		root = filepath.Join(u.Host, u.Path)
+	}
+	if root == "" {
+		return nil, fmt.Errorf("archive: file object store needs a directory")
+	}
+	return &fileObjectStore{root: filepath.Clean(root)}, nil
+}
+
+func (s *fileObjectStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if clean == "." || filepath.IsAbs(clean) || strings.HasPrefix(clean, ".."+string(filepath.Separator)) || clean == ".." {
+		return "", fmt.Errorf("archive: invalid object key %q", key)
+	}
+	return filepath.Join(s.root, clean), nil
+}
+
+func (s *fileObjectStore) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// Write to a temp file first so a failed upload never leaves a partial
+	// object under the final key.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *fileObjectStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	return f, err
+}
@@ -0,0 +1,86 @@
+package archive
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileObjectStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := OpenObjectStore("file://" + dir)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(ctx, "a/b.jsonl.gz", strings.NewReader("one")))
+	require.NoError(t, store.Put(ctx, "a/b.jsonl.gz", strings.NewReader("two")))
+	rc, err := store.Get(ctx, "a/b.jsonl.gz")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(rc)
+	rc.Close()
+	assert.Equal(t, "two", string(body))
+
+	_, err = store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+	assert.Error(t, store.Put(ctx, "../escape", strings.NewReader("x")))
+
+	_, err = OpenObjectStore("gcs://bucket")
+	assert.ErrorContains(t, err, "unknown object store scheme")
+}
+
+func TestS3ObjectStore(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		objects = map[string]string{}
+		auths   []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		auths = append(auths, r.Header.Get("Authorization"))
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = string(body)
+		case http.MethodGet:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				http.Error(w, "NoSuchKey", http.StatusNotFound)
+				return
+			}
+			io.WriteString(w, body)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	ctx := context.Background()
+	store, err := OpenObjectStore("s3://archive/nof0?region=eu-west-1&endpoint=" + srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, store.Put(ctx, "decision_cycles/1-2.jsonl.gz", strings.NewReader("payload")))
+	assert.Equal(t, "payload", objects["/archive/nof0/decision_cycles/1-2.jsonl.gz"])
+
+	rc, err := store.Get(ctx, "decision_cycles/1-2.jsonl.gz")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(rc)
+	rc.Close()
+	assert.Equal(t, "payload", string(body))
+
+	_, err = store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	require.NotEmpty(t, auths)
+	assert.Regexp(t, `^AWS4-HMAC-SHA256 Credential=AKID/\d{8}/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=[0-9a-f]{64}$`, auths[0])
+
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	_, err = OpenObjectStore("s3://archive")
+	assert.ErrorContains(t, err, "AWS_SECRET_ACCESS_KEY")
+}
@@ -0,0 +1,152 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+// sqlStore implements Store over Postgres. Rows travel as to_jsonb documents
+// and are restored with jsonb_populate_recordset, so archival needs no
+// per-table column lists.
+type sqlStore struct {
+	conn sqlx.SqlConn
+}
+
+// NewSQLStore returns a Store over conn.
+func NewSQLStore(conn sqlx.SqlConn) Store {
+	return &sqlStore{conn: conn}
+}
+
+func (s *sqlStore) Candidates(ctx context.Context, t Table, before time.Time, limit int) ([]Row, error) {
+	query := fmt.Sprintf(`
+SELECT t.id, t.%[2]s AS at, to_jsonb(t)::text AS doc
+FROM public.%[1]s t
+WHERE t.deleted_at IS NULL AND t.%[2]s < $1
+ORDER BY t.id
+LIMIT $2`, t.Name, t.TimeColumn)
+	var rows []struct {
+		ID  int64     `db:"id"`
+		At  time.Time `db:"at"`
+		Doc string    `db:"doc"`
+	}
+	if err := s.conn.QueryRowsCtx(ctx, &rows, query, before, limit); err != nil {
+		return nil, err
+	}
+	out := make([]Row, len(rows))
+	for i, r := range rows {
+		out[i] = Row{ID: r.ID, At: r.At, Doc: json.RawMessage(r.Doc)}
+	}
+	return out, nil
+}
+
+func (s *sqlStore) MarkArchived(ctx context.Context, t Table, ids []int64, b Batch) error {
+	return s.conn.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		update := fmt.Sprintf(`UPDATE public.%s SET deleted_at = $2 WHERE id = ANY($1) AND deleted_at IS NULL`, t.Name)
+		if _, err := session.ExecCtx(ctx, update, pq.Array(ids), b.ArchivedAt); err != nil {
+			return err
+		}
+		const insert = `
+INSERT INTO public.archive_batches (table_name, object_key, first_id, last_id, row_count, from_at, to_at, archived_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (object_key) DO UPDATE
+SET row_count = EXCLUDED.row_count,
+    from_at = EXCLUDED.from_at,
+    to_at = EXCLUDED.to_at,
+    archived_at = EXCLUDED.archived_at,
+    restored_at = NULL`
+		_, err := session.ExecCtx(ctx, insert, b.Table, b.Key, b.FirstID, b.LastID, b.Rows, b.From, b.To, b.ArchivedAt)
+		return err
+	})
+}
+
+func (s *sqlStore) Purge(ctx context.Context, t Table, cutoff time.Time) (int64, error) {
+	query := fmt.Sprintf(`DELETE FROM public.%s WHERE deleted_at IS NOT NULL AND deleted_at < $1`, t.Name)
+	res, err := s.conn.ExecCtx(ctx, query, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *sqlStore) Restore(ctx context.Context, t Table, key string, docs []json.RawMessage) (int64, error) {
+	if len(docs) == 0 {
+		return 0, nil
+	}
+	payload := append([]byte{'['}, bytes.Join(rawBytes(docs), []byte{','})...)
+	payload = append(payload, ']')
+	var n int64
+	err := s.conn.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		query := fmt.Sprintf(`
+INSERT INTO public.%[1]s
+SELECT * FROM jsonb_populate_recordset(NULL::public.%[1]s, $1::jsonb)
+ON CONFLICT (id) DO UPDATE SET deleted_at = NULL`, t.Name)
+		res, err := session.ExecCtx(ctx, query, string(payload))
+		if err != nil {
+			return err
+		}
+		if n, err = res.RowsAffected(); err != nil {
+			return err
+		}
+		_, err = session.ExecCtx(ctx, `UPDATE public.archive_batches SET restored_at = NOW() WHERE object_key = $1`, key)
+		return err
+	})
+	return n, err
+}
+
+func (s *sqlStore) Batches(ctx context.Context, table string, from, to time.Time) ([]Batch, error) {
+	const query = `
+SELECT table_name, object_key, first_id, last_id, row_count, from_at, to_at, archived_at, restored_at
+FROM public.archive_batches
+WHERE table_name = $1
+  AND ($2::timestamptz IS NULL OR to_at >= $2)
+  AND ($3::timestamptz IS NULL OR from_at < $3)
+ORDER BY from_at, first_id`
+	var rows []struct {
+		Table      string       `db:"table_name"`
+		Key        string       `db:"object_key"`
+		FirstID    int64        `db:"first_id"`
+		LastID     int64        `db:"last_id"`
+		Rows       int          `db:"row_count"`
+		From       time.Time    `db:"from_at"`
+		To         time.Time    `db:"to_at"`
+		ArchivedAt time.Time    `db:"archived_at"`
+		RestoredAt sql.NullTime `db:"restored_at"`
+	}
+	if err := s.conn.QueryRowsCtx(ctx, &rows, query, table, nullTime(from), nullTime(to)); err != nil {
+		return nil, err
+	}
+	out := make([]Batch, len(rows))
+	for i, r := range rows {
+		out[i] = Batch{
+			Table:      r.Table,
+			Key:        r.Key,
+			FirstID:    r.FirstID,
+			LastID:     r.LastID,
+			Rows:       r.Rows,
+			From:       r.From,
+			To:         r.To,
+			ArchivedAt: r.ArchivedAt,
+			RestoredAt: r.RestoredAt.Time,
+		}
+	}
+	return out, nil
+}
+
+func rawBytes(docs []json.RawMessage) [][]byte {
+	out := make([][]byte, len(docs))
+	for i, d := range docs {
+		out[i] = d
+	}
+	return out
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
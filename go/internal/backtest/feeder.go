@@ -0,0 +1,162 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"nof0-api/pkg/market"
+	"nof0-api/pkg/market/indicators"
+)
+
+// Lookbacks match the live Hyperliquid provider so replayed prompts carry the
+// same warmed-up indicators.
+const (
+	intradayLookback = 80  // MACD warm-up plus the shown series
+	longTermLookback = 120 // EMA50 warm-up plus the shown series
+	seriesLength     = 10
+)
+
+// Feeder steps through intraday bars and builds the snapshot a live cycle
+// would have seen at each bar's close. It implements backtest.Feeder.
+type Feeder struct {
+	symbol           string
+	intraday         []market.Candle
+	longTerm         []market.Candle
+	metrics          []Metric
+	intradayInterval time.Duration
+	longInterval     time.Duration
+
+	idx int
+	now time.Time
+}
+
+// NewFeeder replays intraday bars opening at or after start. Earlier bars,
+// and long-term bars, only warm up indicators. metrics may be empty.
+func NewFeeder(symbol string, intraday []market.Candle, intradayInterval time.Duration, longTerm []market.Candle, longInterval time.Duration, metrics []Metric, start time.Time) (*Feeder, error) {
+	if intradayInterval <= 0 || longInterval <= 0 {
+		return nil, fmt.Errorf("backtest: kline intervals must be positive")
+	}
+	startMs := start.UnixMilli()
+	idx := sort.Search(len(intraday), func(i int) bool { return intraday[i].OpenTime >= startMs })
+	return &Feeder{
+		symbol:           strings.ToUpper(strings.TrimSpace(symbol)),
+		intraday:         intraday,
+		longTerm:         longTerm,
+		metrics:          metrics,
+		intradayInterval: intradayInterval,
+		longInterval:     longInterval,
+		idx:              idx,
+	}, nil
+}
+
+// SnapshotAt loads history for symbol from src and returns the snapshot a
+// live cycle would have seen at asOf, which must be the close of a stored
+// intraday bar. Empty interval names default as in Config.
+func SnapshotAt(ctx context.Context, src Source, symbol string, asOf time.Time, intradayName, longName string) (*market.Snapshot, error) {
+	intradayName, longName, intradayInterval, longInterval, err := parseIntervals(intradayName, longName)
+	if err != nil {
+		return nil, err
+	}
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	intraday, err := src.Klines(ctx, symbol, intradayName, asOf.Add(-(intradayLookback+1)*intradayInterval), asOf)
+	if err != nil {
+		return nil, err
+	}
+	longTerm, err := src.Klines(ctx, symbol, longName, asOf.Add(-(longTermLookback+1)*longInterval), asOf)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := src.Metrics(ctx, symbol, asOf.Add(-longInterval), asOf)
+	if err != nil {
+		return nil, err
+	}
+	feeder, err := NewFeeder(symbol, intraday, intradayInterval, longTerm, longInterval, metrics, asOf.Add(-intradayInterval))
+	if err != nil {
+		return nil, err
+	}
+	snap, ok, err := feeder.Next(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || !feeder.Now().Equal(asOf.UTC()) {
+		return nil, fmt.Errorf("backtest: no stored %s %s bar closing at %s", symbol, intradayName, asOf.UTC().Format(time.RFC3339))
+	}
+	return snap, nil
+}
+
+// Now returns the close time of the bar last emitted.
+func (f *Feeder) Now() time.Time { return f.now }
+
+// Next returns the snapshot at the close of the next intraday bar.
+func (f *Feeder) Next(ctx context.Context, symbol string) (*market.Snapshot, bool, error) {
+	if !strings.EqualFold(symbol, f.symbol) {
+		return nil, false, fmt.Errorf("backtest: feeder symbol mismatch %s != %s", symbol, f.symbol)
+	}
+	if f.idx >= len(f.intraday) {
+		return nil, false, nil
+	}
+	i := f.idx
+	f.idx++
+	asOf := time.UnixMilli(f.intraday[i].OpenTime).Add(f.intradayInterval).UTC()
+	f.now = asOf
+	return f.snapshotAt(i, asOf), true, nil
+}
+
+func (f *Feeder) snapshotAt(i int, asOf time.Time) *market.Snapshot {
+	intraday := tail(f.intraday[:i+1], intradayLookback)
+	longTerm := tail(market.ClosedCandles(f.longTerm, f.longInterval, asOf), longTermLookback)
+	last := intraday[len(intraday)-1].Close
+
+	intradaySeries, intradaySignals := indicators.BuildSeries(intraday, indicators.FrameIntraday, seriesLength)
+	longSeries, longSignals := indicators.BuildSeries(longTerm, indicators.FrameLongTerm, seriesLength)
+
+	snap := &market.Snapshot{
+		Symbol:     f.symbol,
+		Price:      market.PriceInfo{Last: last},
+		Indicators: indicators.Summary(intradaySignals, longSignals),
+		Intraday:   intradaySeries,
+		LongTerm:   longSeries,
+	}
+	if back := int(time.Hour / f.intradayInterval); back > 0 && len(intraday) > back {
+		snap.Change.OneHour = change(last, intraday[len(intraday)-1-back].Close)
+	}
+	if len(longTerm) > 1 {
+		snap.Change.FourHour = change(last, longTerm[len(longTerm)-2].Close)
+	}
+	if m, ok := f.metricAt(asOf); ok {
+		if m.FundingRate != 0 {
+			snap.Funding = &market.FundingInfo{Rate: m.FundingRate}
+		}
+		if m.OpenInterest != 0 {
+			snap.OpenInterest = &market.OpenInterestInfo{Latest: m.OpenInterest, Average: m.OpenInterest}
+		}
+	}
+	return snap
+}
+
+// metricAt returns the latest metric recorded at or before asOf.
+func (f *Feeder) metricAt(asOf time.Time) (Metric, bool) {
+	n := sort.Search(len(f.metrics), func(i int) bool { return f.metrics[i].At.After(asOf) })
+	if n == 0 {
+		return Metric{}, false
+	}
+	return f.metrics[n-1], true
+}
+
+func tail(candles []market.Candle, n int) []market.Candle {
+	if len(candles) > n {
+		return candles[len(candles)-n:]
+	}
+	return candles
+}
+
+// change returns the fractional change (0.01 == +1%).
+func change(current, previous float64) float64 {
+	if previous == 0 {
+		return 0
+	}
+	return (current - previous) / previous
+}
@@ -0,0 +1,176 @@
+package backtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"nof0-api/pkg/decision"
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/llm"
+	"nof0-api/pkg/market"
+)
+
+var (
+	_ llm.LLMClient   = (*RulesLLM)(nil)
+	_ ContextObserver = (*RulesLLM)(nil)
+)
+
+// RulesLLM stands in for a model with a fixed trend-following rule so a
+// backtest can exercise the whole loop without API calls: enter in the
+// direction of price against the intraday EMA20 when MACD agrees and RSI7 is
+// not stretched, and exit when both turn. Stops sit StopATR long-term ATR14
+// from entry (2% without ATR) with the target RewardRisk times further.
+type RulesLLM struct {
+	Symbol     string
+	Leverage   int     // defaults to 3
+	MarginPct  float64 // margin per entry as a percentage of equity; defaults to 10
+	StopATR    float64 // defaults to 2
+	RewardRisk float64 // defaults to 2
+
+	mu    sync.Mutex
+	input *executorpkg.Context
+}
+
+// Observe implements ContextObserver.
+func (r *RulesLLM) Observe(input *executorpkg.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.input = input
+}
+
+// Chat answers with the rule's decision for the last observed input; the
+// prompt itself is ignored.
+func (r *RulesLLM) Chat(ctx context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	r.mu.Lock()
+	input := r.input
+	r.mu.Unlock()
+	if input == nil {
+		return nil, errors.New("backtest: rules stub has no observed input")
+	}
+	data, err := json.Marshal(r.decide(input))
+	if err != nil {
+		return nil, err
+	}
+	return &llm.ChatResponse{
+		Model:   "rules",
+		Choices: []llm.Choice{{Message: llm.Message{Role: "assistant", Content: string(data)}, FinishReason: "stop"}},
+	}, nil
+}
+
+// ChatStructured answers like Chat and decodes the decision into target.
+func (r *RulesLLM) ChatStructured(ctx context.Context, req *llm.ChatRequest, target interface{}) (*llm.ChatResponse, error) {
+	resp, err := r.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if target != nil {
+		if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), target); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// ChatStream is not supported by the rules stub.
+func (r *RulesLLM) ChatStream(ctx context.Context, req *llm.ChatRequest) (<-chan llm.StreamResponse, error) {
+	return nil, errors.New("backtest: rules stub does not stream")
+}
+
+// GetConfig returns nil; the stub has no model settings.
+func (r *RulesLLM) GetConfig() *llm.Config { return nil }
+
+// Close is a no-op.
+func (r *RulesLLM) Close() error { return nil }
+
+func (r *RulesLLM) decide(input *executorpkg.Context) decision.Contract {
+	symbol := strings.ToUpper(r.Symbol)
+	hold := decision.Contract{Signal: string(decision.SignalHold), Symbol: symbol}
+	snap := input.MarketDataMap[symbol]
+	if snap == nil || snap.Price.Last <= 0 {
+		return hold
+	}
+	price := snap.Price.Last
+	ema, okEMA := snap.Indicators.EMA["EMA20"]
+	rsi, okRSI := snap.Indicators.RSI["RSI7"]
+	if !okEMA || !okRSI {
+		return hold
+	}
+	up := price > ema && snap.Indicators.MACD > 0
+	down := price < ema && snap.Indicators.MACD < 0
+
+	for _, p := range input.Positions {
+		if p.Symbol != symbol {
+			continue
+		}
+		if (p.Side == "long" && down) || (p.Side == "short" && up) {
+			return decision.Contract{
+				Signal:     string(decision.SignalClose),
+				Symbol:     symbol,
+				Confidence: 70,
+				Reasoning:  fmt.Sprintf("trend turned against %s: price %.4g vs EMA20 %.4g, MACD %.4g", p.Side, price, ema, snap.Indicators.MACD),
+			}
+		}
+		return hold
+	}
+
+	long := up && rsi < 70
+	short := down && rsi > 30
+	if !long && !short {
+		return hold
+	}
+	lev, marginPct, stopATR, rr := r.params()
+	stopDist := price * 0.02
+	if atr := latestATR(snap); atr > 0 {
+		stopDist = stopATR * atr
+	}
+	c := decision.Contract{
+		Signal:                string(decision.SignalBuyToEnter),
+		Symbol:                symbol,
+		Leverage:              lev,
+		PositionSizeUSD:       input.Account.TotalEquity * marginPct / 100 * float64(lev),
+		EntryPrice:            price,
+		StopLoss:              price - stopDist,
+		TakeProfit:            price + rr*stopDist,
+		Confidence:            70,
+		InvalidationCondition: "price closes back through EMA20 with MACD flipping sign",
+		Reasoning:             fmt.Sprintf("price %.4g vs EMA20 %.4g, MACD %.4g, RSI7 %.1f", price, ema, snap.Indicators.MACD, rsi),
+	}
+	if short {
+		c.Signal = string(decision.SignalSellToEnter)
+		c.StopLoss, c.TakeProfit = price+stopDist, price-rr*stopDist
+	}
+	c.RiskUSD = c.PositionSizeUSD * stopDist / price
+	return c
+}
+
+func (r *RulesLLM) params() (leverage int, marginPct, stopATR, rewardRisk float64) {
+	leverage, marginPct, stopATR, rewardRisk = r.Leverage, r.MarginPct, r.StopATR, r.RewardRisk
+	if leverage <= 0 {
+		leverage = 3
+	}
+	if marginPct <= 0 {
+		marginPct = 10
+	}
+	if stopATR <= 0 {
+		stopATR = 2
+	}
+	if rewardRisk <= 0 {
+		rewardRisk = 2
+	}
+	return leverage, marginPct, stopATR, rewardRisk
+}
+
+func latestATR(snap *market.Snapshot) float64 {
+	if snap.LongTerm == nil {
+		return 0
+	}
+	atr := snap.LongTerm.ATR["ATR14"]
+	if len(atr) == 0 {
+		return 0
+	}
+	return atr[len(atr)-1]
+}
@@ -0,0 +1,222 @@
+package backtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	pkgbacktest "nof0-api/pkg/backtest"
+	"nof0-api/pkg/exchange/sim"
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/llm"
+	"nof0-api/pkg/market"
+)
+
+// Config describes one backtest run.
+type Config struct {
+	Symbol string
+	// From and To bound the replayed intraday bars by open time.
+	From time.Time
+	To   time.Time
+	// IntradayInterval and LongTermInterval name the stored kline intervals;
+	// they default to the live provider's "3m" and "4h".
+	IntradayInterval string
+	LongTermInterval string
+
+	// Executor, TemplatePath and ModelAlias configure the executor exactly as
+	// for a live trader.
+	Executor     *executorpkg.Config
+	TemplatePath string
+	ModelAlias   string
+	// LLM answers the rendered prompts: a real client, a ReplayLLM, or a
+	// RulesLLM.
+	LLM llm.LLMClient
+
+	InitialEquity float64 // defaults to 100000
+	FeeBps        float64
+	SlippageBps   float64
+	// OutputPath, when set, receives the report as JSON.
+	OutputPath string
+}
+
+// Trade is one fill in the per-trade log.
+type Trade struct {
+	Time      time.Time `json:"time"`
+	Action    string    `json:"action"`
+	Side      string    `json:"side"`
+	Price     float64   `json:"price"`
+	Qty       float64   `json:"qty"`
+	Fee       float64   `json:"fee"`
+	Realized  float64   `json:"realized"`
+	Position  float64   `json:"position"`
+	Reasoning string    `json:"reasoning,omitempty"`
+}
+
+// Report is the outcome of a run. Times align with Result.EquityCurve and
+// Drawdown.
+type Report struct {
+	Symbol string              `json:"symbol"`
+	Result *pkgbacktest.Result `json:"result"`
+	Times  []time.Time         `json:"times"`
+	// Drawdown is the percentage below the running equity peak at each bar.
+	Drawdown []float64 `json:"drawdown"`
+	Trades   []Trade   `json:"trades"`
+	// Decisions counts executor calls; Rejected those that failed
+	// validation and held.
+	Decisions int `json:"decisions"`
+	Rejected  int `json:"rejected"`
+}
+
+// Run loads history for cfg.Symbol from src and replays it through the
+// executor and the paper exchange.
+func Run(ctx context.Context, src Source, cfg Config) (*Report, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(cfg.Symbol))
+	switch {
+	case src == nil:
+		return nil, errors.New("backtest: source is required")
+	case symbol == "":
+		return nil, errors.New("backtest: symbol is required")
+	case !cfg.To.After(cfg.From):
+		return nil, errors.New("backtest: to must be after from")
+	case cfg.Executor == nil || cfg.LLM == nil:
+		return nil, errors.New("backtest: executor config and llm are required")
+	}
+	intradayName, longName, intradayInterval, longInterval, err := parseIntervals(cfg.IntradayInterval, cfg.LongTermInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load enough bars before From to warm up indicators on the first cycle.
+	intraday, err := src.Klines(ctx, symbol, intradayName, cfg.From.Add(-intradayLookback*intradayInterval), cfg.To)
+	if err != nil {
+		return nil, err
+	}
+	longTerm, err := src.Klines(ctx, symbol, longName, cfg.From.Add(-(longTermLookback+1)*longInterval), cfg.To)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := src.Metrics(ctx, symbol, cfg.From.Add(-longInterval), cfg.To)
+	if err != nil {
+		return nil, err
+	}
+	feeder, err := NewFeeder(symbol, intraday, intradayInterval, longTerm, longInterval, metrics, cfg.From)
+	if err != nil {
+		return nil, err
+	}
+
+	exec, err := executorpkg.NewExecutor(cfg.Executor, cfg.LLM, cfg.TemplatePath, cfg.ModelAlias)
+	if err != nil {
+		return nil, fmt.Errorf("backtest: init executor: %w", err)
+	}
+	equity := cfg.InitialEquity
+	if equity <= 0 {
+		equity = 100000
+	}
+	exch := sim.New(sim.WithInitialEquity(equity))
+	strategy := &decisionStrategy{
+		exec:   exec,
+		exch:   exch,
+		feeder: feeder,
+		symbol: symbol,
+		start:  cfg.From,
+	}
+	if obs, ok := cfg.LLM.(ContextObserver); ok {
+		strategy.observer = obs
+	}
+	var times []time.Time
+	engine := &pkgbacktest.Engine{
+		Feeder:        timedFeeder{Feeder: feeder, times: &times},
+		Strategy:      strategy,
+		Exch:          exch,
+		Symbol:        symbol,
+		InitialEquity: equity,
+		FeeBps:        cfg.FeeBps,
+		SlippageBps:   cfg.SlippageBps,
+	}
+	res, err := engine.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Symbol:    symbol,
+		Result:    res,
+		Times:     times,
+		Drawdown:  drawdownCurve(equity, res.EquityCurve),
+		Decisions: strategy.calls,
+		Rejected:  strategy.rejected,
+	}
+	// The engine records one detail per order, in the order they were sent.
+	for i, d := range res.Details {
+		t := Trade{Side: d.Side, Price: d.Price, Qty: d.Qty, Fee: d.Fee, Realized: d.Realized, Position: d.Position}
+		if i < len(strategy.orders) {
+			o := strategy.orders[i]
+			t.Time, t.Action, t.Reasoning = o.At, o.Action, o.Reasoning
+		}
+		report.Trades = append(report.Trades, t)
+	}
+	if cfg.OutputPath != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(cfg.OutputPath, data, 0o600); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// parseIntervals applies the "3m" and "4h" defaults to the stored kline
+// interval names and parses them.
+func parseIntervals(intradayName, longName string) (string, string, time.Duration, time.Duration, error) {
+	if intradayName == "" {
+		intradayName = "3m"
+	}
+	if longName == "" {
+		longName = "4h"
+	}
+	intradayInterval, err := time.ParseDuration(intradayName)
+	if err != nil {
+		return "", "", 0, 0, fmt.Errorf("backtest: intraday interval %q: %w", intradayName, err)
+	}
+	longInterval, err := time.ParseDuration(longName)
+	if err != nil {
+		return "", "", 0, 0, fmt.Errorf("backtest: long-term interval %q: %w", longName, err)
+	}
+	return intradayName, longName, intradayInterval, longInterval, nil
+}
+
+// timedFeeder records the bar time of every snapshot the engine consumes.
+type timedFeeder struct {
+	*Feeder
+	times *[]time.Time
+}
+
+func (f timedFeeder) Next(ctx context.Context, symbol string) (*market.Snapshot, bool, error) {
+	snap, ok, err := f.Feeder.Next(ctx, symbol)
+	if ok {
+		*f.times = append(*f.times, f.Now())
+	}
+	return snap, ok, err
+}
+
+// drawdownCurve returns the percentage below the running peak, starting from
+// the initial equity, at each point of curve.
+func drawdownCurve(initial float64, curve []float64) []float64 {
+	out := make([]float64, len(curve))
+	peak := initial
+	for i, v := range curve {
+		if v > peak {
+			peak = v
+		}
+		if peak > 0 {
+			out[i] = (peak - v) / peak * 100
+		}
+	}
+	return out
+}
@@ -0,0 +1,116 @@
+package backtest
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+)
+
+// fakeSource serves generated bars: a steady rally followed by a selloff.
+type fakeSource struct{}
+
+func (fakeSource) Klines(ctx context.Context, symbol, interval string, from, to time.Time) ([]market.Candle, error) {
+	step, err := time.ParseDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+	turn := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	var out []market.Candle
+	for t := from.Truncate(step); t.Before(to); t = t.Add(step) {
+		hours := t.Sub(turn).Hours()
+		px := 1000 + 2*hours + 5*math.Sin(hours/3)
+		if hours > 0 {
+			px = 1000 - 6*hours + 5*math.Sin(hours/3)
+		}
+		out = append(out, market.Candle{OpenTime: t.UnixMilli(), Open: px, High: px * 1.002, Low: px * 0.998, Close: px, Volume: 10})
+	}
+	return out, nil
+}
+
+func (fakeSource) Metrics(ctx context.Context, symbol string, from, to time.Time) ([]Metric, error) {
+	return []Metric{{At: from, FundingRate: 0.0001, OpenInterest: 5000}}, nil
+}
+
+func testConfig() Config {
+	return Config{
+		Symbol:           "btc",
+		From:             time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+		To:               time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC),
+		IntradayInterval: "1h",
+		LongTermInterval: "4h",
+		Executor: &executorpkg.Config{
+			MajorCoinLeverage:      10,
+			AltcoinLeverage:        5,
+			MinConfidence:          50,
+			MinRiskReward:          1.5,
+			MaxPositions:           1,
+			DecisionIntervalRaw:    "1h",
+			DecisionTimeoutRaw:     "10s",
+			MaxConcurrentDecisions: 1,
+		},
+		TemplatePath:  filepath.Join("..", "..", "etc", "prompts", "executor", "default_prompt.tmpl"),
+		LLM:           &RulesLLM{Symbol: "BTC"},
+		InitialEquity: 10000,
+	}
+}
+
+func TestRunReplaysHistoryThroughExecutor(t *testing.T) {
+	report, err := Run(context.Background(), fakeSource{}, testConfig())
+	require.NoError(t, err)
+
+	res := report.Result
+	assert.Equal(t, 48, res.Steps)
+	assert.Equal(t, res.Steps, report.Decisions)
+	assert.Len(t, res.EquityCurve, res.Steps)
+	assert.Len(t, report.Times, res.Steps)
+	assert.Len(t, report.Drawdown, res.Steps)
+	assert.Equal(t, time.Date(2025, 1, 2, 1, 0, 0, 0, time.UTC), report.Times[0], "first cycle runs at the first bar's close")
+
+	require.NotEmpty(t, report.Trades)
+	assert.Equal(t, "open_long", report.Trades[0].Action, "the rally is bought")
+	assert.NotEmpty(t, report.Trades[0].Reasoning)
+	var closed bool
+	for _, tr := range report.Trades {
+		closed = closed || tr.Action == "close_long"
+	}
+	assert.True(t, closed, "the selloff closes the long")
+	assert.Greater(t, res.MaxDDPct, 0.0)
+}
+
+func TestFeederSnapshotsUseOnlyClosedBars(t *testing.T) {
+	cfg := testConfig()
+	src := fakeSource{}
+	intraday, err := src.Klines(context.Background(), "BTC", "1h", cfg.From.Add(-80*time.Hour), cfg.To)
+	require.NoError(t, err)
+	longTerm, err := src.Klines(context.Background(), "BTC", "4h", cfg.From.Add(-121*4*time.Hour), cfg.To)
+	require.NoError(t, err)
+	metrics, _ := src.Metrics(context.Background(), "BTC", cfg.From, cfg.To)
+
+	f, err := NewFeeder("BTC", intraday, time.Hour, longTerm, 4*time.Hour, metrics, cfg.From)
+	require.NoError(t, err)
+	snap, ok, err := f.Next(context.Background(), "BTC")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	asOf := f.Now()
+	last := snap.LongTerm.Candles[len(snap.LongTerm.Candles)-1]
+	assert.LessOrEqual(t, time.UnixMilli(last.OpenTime).Add(4*time.Hour), asOf, "long-term frame ends on a closed bar")
+	assert.Contains(t, snap.Indicators.EMA, "EMA50")
+	require.NotNil(t, snap.Funding)
+	assert.Equal(t, 0.0001, snap.Funding.Rate)
+
+	_, _, err = f.Next(context.Background(), "ETH")
+	assert.Error(t, err)
+}
+
+func TestDrawdownCurve(t *testing.T) {
+	assert.Equal(t, []float64{0, 10, 0, 25}, drawdownCurve(100, []float64{100, 90, 120, 90}))
+}
@@ -0,0 +1,186 @@
+package backtest
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/sim"
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+)
+
+// ContextObserver is implemented by LLM clients that decide from structured
+// inputs rather than the rendered prompt, such as RulesLLM. The strategy
+// hands them each cycle's executor input before the executor runs.
+type ContextObserver interface {
+	Observe(input *executorpkg.Context)
+}
+
+// order is an order the strategy sent, kept to annotate the engine's fills.
+type order struct {
+	At        time.Time
+	Action    string
+	Reasoning string
+}
+
+// decisionStrategy asks the executor for a decision at every snapshot and
+// turns it into orders on the paper exchange. It implements
+// backtest.Strategy.
+type decisionStrategy struct {
+	exec     executorpkg.Executor
+	observer ContextObserver
+	exch     *sim.Provider
+	feeder   *Feeder
+	symbol   string
+	start    time.Time
+
+	calls    int
+	rejected int
+	orders   []order
+}
+
+func (s *decisionStrategy) Decide(ctx context.Context, snap *market.Snapshot) ([]exchange.Order, error) {
+	price := snap.Price.Last
+	if price <= 0 {
+		return nil, nil
+	}
+	if err := s.exch.SetMarkPrice(ctx, s.symbol, price); err != nil {
+		return nil, err
+	}
+	input, pos, err := s.context(ctx, snap)
+	if err != nil {
+		return nil, err
+	}
+	if s.observer != nil {
+		s.observer.Observe(input)
+	}
+	s.calls++
+	full, err := s.exec.GetFullDecision(input)
+	if err != nil {
+		// Invalid or rejected decisions hold, as they do live.
+		s.rejected++
+		logx.WithContext(ctx).Infof("backtest: %s decision rejected at %s: %v", s.symbol, s.feeder.Now().Format(time.RFC3339), err)
+		return nil, nil
+	}
+	var out []exchange.Order
+	for _, d := range full.Decisions {
+		if !strings.EqualFold(d.Symbol, s.symbol) {
+			continue
+		}
+		ord, ok, err := s.toOrder(ctx, d, price, pos)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, ord)
+			s.orders = append(s.orders, order{At: s.feeder.Now(), Action: d.Action, Reasoning: d.Reasoning})
+		}
+	}
+	return out, nil
+}
+
+// context builds the executor input from the paper account at the current
+// bar, returning the open position on the replayed symbol, if any.
+func (s *decisionStrategy) context(ctx context.Context, snap *market.Snapshot) (*executorpkg.Context, *executorpkg.PositionInfo, error) {
+	state, err := s.exch.GetAccountState(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	equity := parseDecimal(state.MarginSummary.AccountValue)
+	marginUsed := parseDecimal(state.MarginSummary.TotalMarginUsed)
+	now := s.feeder.Now()
+	input := &executorpkg.Context{
+		CurrentTime:    now.Format(time.RFC3339),
+		RuntimeMinutes: int(now.Sub(s.start).Minutes()),
+		CallCount:      s.calls + 1,
+		Account: executorpkg.AccountInfo{
+			TotalEquity:      equity,
+			AvailableBalance: equity - marginUsed,
+			MarginUsed:       marginUsed,
+			PositionCount:    len(state.AssetPositions),
+		},
+		CandidateCoins: []executorpkg.CandidateCoin{{Symbol: s.symbol, Sources: []string{"backtest"}}},
+		MarketDataMap:  map[string]*market.Snapshot{s.symbol: snap},
+	}
+	if equity > 0 {
+		input.Account.MarginUsedPct = marginUsed / equity * 100
+	}
+	var current *executorpkg.PositionInfo
+	for _, p := range state.AssetPositions {
+		qty := parseDecimal(p.Szi)
+		if qty == 0 {
+			continue
+		}
+		info := executorpkg.PositionInfo{
+			Symbol:        strings.ToUpper(p.Coin),
+			Side:          "long",
+			MarkPrice:     snap.Price.Last,
+			Quantity:      math.Abs(qty),
+			Leverage:      p.Leverage.Value,
+			MarginMode:    p.Leverage.Type,
+			UnrealizedPnL: parseDecimal(p.UnrealizedPnl),
+		}
+		if qty < 0 {
+			info.Side = "short"
+		}
+		if p.EntryPx != nil {
+			info.EntryPrice = parseDecimal(*p.EntryPx)
+		}
+		if p.LiquidationPx != nil {
+			info.LiquidationPrice = parseDecimal(*p.LiquidationPx)
+		}
+		input.Positions = append(input.Positions, info)
+		if info.Symbol == s.symbol {
+			current = &input.Positions[len(input.Positions)-1]
+		}
+	}
+	return input, current, nil
+}
+
+// toOrder maps a decision onto an IOC order at price. Closes flatten the
+// open position; opens are skipped while one is held.
+func (s *decisionStrategy) toOrder(ctx context.Context, d executorpkg.Decision, price float64, pos *executorpkg.PositionInfo) (exchange.Order, bool, error) {
+	asset, err := s.exch.GetAssetIndex(ctx, s.symbol)
+	if err != nil {
+		return exchange.Order{}, false, err
+	}
+	ord := exchange.Order{
+		Asset:     asset,
+		LimitPx:   strconv.FormatFloat(price, 'f', -1, 64),
+		OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Ioc"}},
+	}
+	switch strings.ToLower(strings.TrimSpace(d.Action)) {
+	case "open_long", "open_short":
+		if pos != nil || d.PositionSizeUSD <= 0 {
+			return exchange.Order{}, false, nil
+		}
+		if d.Leverage > 0 {
+			if err := s.exch.UpdateLeverage(ctx, asset, true, d.Leverage); err != nil {
+				return exchange.Order{}, false, err
+			}
+		}
+		ord.IsBuy = d.Action == "open_long"
+		ord.Sz = strconv.FormatFloat(d.PositionSizeUSD/price, 'f', -1, 64)
+	case "close_long", "close_short":
+		if pos == nil {
+			return exchange.Order{}, false, nil
+		}
+		ord.IsBuy = pos.Side == "short"
+		ord.ReduceOnly = true
+		ord.Sz = strconv.FormatFloat(pos.Quantity, 'f', -1, 64)
+	default:
+		return exchange.Order{}, false, nil
+	}
+	return ord, true, nil
+}
+
+func parseDecimal(s string) float64 {
+	v, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return v
+}
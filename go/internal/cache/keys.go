@@ -111,6 +111,11 @@ func MarketAssetCtxKey(provider, symbol string) string {
 	return formatKey("market", "ctx", provider, symbol)
 }
 
+// MarketWindowKey stores rolling indicator input windows for prompt assembly.
+func MarketWindowKey(provider, symbol string) string {
+	return formatKey("market", "window", provider, symbol)
+}
+
 // --- Positions Keys ---------------------------------------------------------
 
 func PositionsHashKey(modelID string) string {
@@ -142,6 +147,29 @@ func TraderDecisionLastHashKey() string {
 	return formatKey("trader", "decision_last")
 }
 
+// TraderCycleLatestHashKey stores the latest cycle breakdown per trader.
+func TraderCycleLatestHashKey() string {
+	return formatKey("trader", "cycle_latest")
+}
+
+// PipelineLogStreamKey is the Redis stream of live pipeline events read by
+// /api/logs/stream.
+func PipelineLogStreamKey() string {
+	return formatKey("pipeline", "logs")
+}
+
+// PipelineLogMaxLen caps the pipeline log stream (approximate trimming).
+const PipelineLogMaxLen = 5000
+
+// LiveEventStreamKey is the Redis stream of decision, position and account
+// updates pushed to dashboards over /ws.
+func LiveEventStreamKey() string {
+	return formatKey("live", "events")
+}
+
+// LiveEventMaxLen caps the live event stream (approximate trimming).
+const LiveEventMaxLen = 2000
+
 // TraderHashField normalizes trader ids for hash access.
 func TraderHashField(traderID string) string {
 	return strings.ToLower(strings.TrimSpace(traderID))
@@ -241,6 +269,11 @@ func MarketAssetCtxTTL(ttl TTLSet) time.Duration {
 	return ttl.Duration(TTLMedium)
 }
 
+// MarketWindowTTL returns the TTL for rolling market window payloads.
+func MarketWindowTTL(ttl TTLSet) time.Duration {
+	return ttl.Duration(TTLMedium)
+}
+
 // PositionsTTL returns the TTL for positions hash payloads.
 func PositionsTTL(ttl TTLSet) time.Duration {
 	return ttl.Scaled(TTLMedium, 0.5) // target ~30s when medium=60s
@@ -286,6 +319,12 @@ func DecisionLastTTL(ttl TTLSet) time.Duration {
 	return ttl.Duration(TTLMedium)
 }
 
+// CycleLatestTTL returns the TTL for cycle breakdowns. It spans several
+// decision intervals so slow traders still report their last cycle.
+func CycleLatestTTL(ttl TTLSet) time.Duration {
+	return ttl.Scaled(TTLLong, 12) // target ~1h when long=300s
+}
+
 // TraderStateTTL returns the TTL for cached trader state.
 func TraderStateTTL(ttl TTLSet) time.Duration {
 	return ttl.Duration(TTLMedium)
@@ -33,6 +33,33 @@ type PostgresConf struct {
 	MaxOpen     int           `json:",default=10"`
 	MaxIdle     int           `json:",default=5"`
 	MaxLifetime time.Duration `json:",default=5m"`
+	MaxIdleTime time.Duration `json:",optional"`
+	// StatementTimeout makes Postgres cancel statements running longer than
+	// this; 0 keeps the server default.
+	StatementTimeout time.Duration `json:",optional"`
+	// SlowQuery logs statements taking at least this long with a fingerprint
+	// shared by statements differing only in literals; 0 disables it.
+	SlowQuery time.Duration `json:",optional"`
+	// Bulk gives the ingestion writers (klines, market metrics, price ticks)
+	// their own pool so bulk inserts do not starve API reads such as the
+	// leaderboard. MaxOpen 0 shares the main pool.
+	Bulk PostgresPoolConf `json:",optional"`
+}
+
+// PostgresPoolConf sizes a secondary Postgres pool; unset timeouts inherit
+// from PostgresConf.
+type PostgresPoolConf struct {
+	MaxOpen          int           `json:",optional"`
+	MaxIdle          int           `json:",optional"`
+	StatementTimeout time.Duration `json:",optional"`
+}
+
+// MarketStorageConf selects where historical market series are stored.
+type MarketStorageConf struct {
+	Backend      string `json:",default=sql,options=sql|timescale"`
+	Table        string `json:",optional"` // overrides the tick series table for non-sql backends
+	KlinesTable  string `json:",optional"` // overrides the kline series table for non-sql backends
+	MetricsTable string `json:",optional"` // overrides the metrics series table for non-sql backends
 }
 
 // LoggingConf configures logging behavior and thresholds
@@ -48,6 +75,149 @@ type SlowThresholdConf struct {
 	Redis int `json:",default=500"`  // milliseconds
 }
 
+// LogStreamConf configures the authenticated pipeline log stream.
+type LogStreamConf struct {
+	// Token is the shared bearer token; empty disables /api/logs/stream.
+	Token string `json:",optional"`
+}
+
+// LiveConf configures the /ws live update stream. It needs Redis.
+type LiveConf struct {
+	// Heartbeat is how often idle connections are sent a heartbeat.
+	Heartbeat time.Duration `json:",default=15s"`
+	// MaxClients caps concurrent connections per process; 0 is unlimited.
+	MaxClients int `json:",default=1000"`
+}
+
+// ShareConf configures public read-only share links.
+type ShareConf struct {
+	// Secret signs share tokens; empty disables share links.
+	Secret string `json:",optional"`
+	// IssuerToken is the bearer token required to create links.
+	IssuerToken string `json:",optional"`
+	// BaseURL is the public page links point at; the token is appended.
+	// Empty links straight to /api/share/<token>.
+	BaseURL    string        `json:",optional"`
+	DefaultTTL time.Duration `json:",default=168h"`
+	MaxTTL     time.Duration `json:",default=720h"`
+}
+
+// TemplatesConf configures remote prompt template rendering.
+type TemplatesConf struct {
+	// Token is the bearer token for /api/templates/render; empty disables it.
+	Token string `json:",optional"`
+	// Dir is the root template names are resolved against, relative to the
+	// config file; empty uses the config directory, so names match the
+	// prompt_template paths in manager.yaml.
+	Dir string `json:",optional"`
+	// AllowedFuncs, when set, are the only functions (text/template builtins
+	// included) templates rendered from Dir may call; others are rejected.
+	AllowedFuncs []string `json:",optional"`
+}
+
+// WidgetConf configures the embeddable widget endpoints.
+type WidgetConf struct {
+	// MaxAge is the Cache-Control max-age sent with widget responses.
+	MaxAge time.Duration `json:",default=60s"`
+	// TrustForwardedFor keys rate limits by the first X-Forwarded-For hop.
+	// Enable it only behind a reverse proxy that overwrites the header.
+	TrustForwardedFor bool          `json:",default=false"`
+	Equity            RateLimitConf `json:",optional"`
+	Leaderboard       RateLimitConf `json:",optional"`
+}
+
+// KlineIngestConf configures candle ingestion into the klines table. It
+// needs Postgres and runs inside cmd/llm for the traded symbols.
+type KlineIngestConf struct {
+	Enabled bool            `json:",default=false"`
+	Feeds   []KlineFeedConf `json:",optional"`
+	// Intervals are the stored intervals; the smallest is streamed and the
+	// rest are aggregated from it. Empty uses 1m, 3m and 4h.
+	Intervals     []string      `json:",optional"`
+	Backfill      time.Duration `json:",default=72h"`
+	FlushInterval time.Duration `json:",default=5s"`
+}
+
+// KlinesConf configures the /api/klines chart endpoint.
+type KlinesConf struct {
+	// Provider is the exchange_provider served when a request names none; it
+	// should match the KlineIngest feed the models trade on.
+	Provider string `json:",default=hyperliquid"`
+	// MaxCandles caps the candles of one response, keeping the newest.
+	MaxCandles int `json:",default=1500"`
+	// MaxAge is the Cache-Control max-age of ranges that include the open
+	// candle; ranges ending before it never change and are cached for a day.
+	MaxAge time.Duration `json:",default=15s"`
+}
+
+// KlineFeedConf selects one websocket candle feed.
+type KlineFeedConf struct {
+	// Type is a registered feed: hyperliquid or binance.
+	Type string `json:",options=hyperliquid|binance"`
+	// Provider is the exchange_provider rows are stored under; defaults to Type.
+	Provider string `json:",optional"`
+	Testnet  bool   `json:",default=false"`
+	// URL overrides the websocket endpoint.
+	URL string `json:",optional"`
+	// Trades also subscribes to the feed's trade stream, so a trade in the
+	// next interval closes the open candle without waiting for its successor.
+	Trades bool `json:",default=false"`
+}
+
+// MetricsConf configures the market_metrics collector run by cmd/llm.
+// Without Postgres the rolling averages are kept in memory only.
+type MetricsConf struct {
+	Enabled bool `json:",default=false"`
+	// Provider names the market.yaml provider metrics are read from.
+	Provider string `json:",default=hyperliquid"`
+	// Symbols defaults to the traded --symbols.
+	Symbols  []string      `json:",optional"`
+	Interval time.Duration `json:",default=1m"`
+	// AverageWindow is the span of the open interest and volume averages
+	// reported in market snapshots.
+	AverageWindow time.Duration `json:",default=24h"`
+}
+
+// AccountsConf configures the account snapshot scheduler run by cmd/llm.
+// Without Postgres the snapshot history is kept in memory only.
+type AccountsConf struct {
+	Enabled  bool          `json:",default=false"`
+	Interval time.Duration `json:",default=5m"`
+	// Lookback is the history Sharpe ratio and max drawdown cover.
+	Lookback time.Duration `json:",default=720h"`
+}
+
+// LeaderConf enables leader election between cmd/llm instances sharing one
+// trader set: each trader is driven by exactly one instance, and another
+// takes over within TTL when it dies. The redis backend needs Cache.
+type LeaderConf struct {
+	Enabled bool `json:",default=false"`
+	// Backend is redis, or memory for a single process.
+	Backend string        `json:",default=redis,options=redis|memory"`
+	TTL     time.Duration `json:",default=15s"`
+	// Owner identifies this instance; empty uses hostname and pid.
+	Owner string `json:",optional"`
+}
+
+// FeatureFlagsConf selects where cmd/llm loads feature flags from and how
+// often it reloads them. Flags are evaluated for Env, so one file or table can
+// serve every environment.
+type FeatureFlagsConf struct {
+	// Source is file, or postgres for the feature_flags table.
+	Source  string        `json:",default=file,options=file|postgres"`
+	File    string        `json:",default=etc/feature_flags.yaml"`
+	Refresh time.Duration `json:",default=30s"`
+}
+
+// RateLimitConf caps requests per client IP within a fixed window.
+type RateLimitConf struct {
+	Quota  int           `json:",default=60"`
+	Period time.Duration `json:",default=1m"`
+	// MaxClients bounds the client windows kept in memory; the oldest is
+	// dropped when a new client arrives at the cap.
+	MaxClients int `json:",default=10000"`
+}
+
 type Config struct {
 	rest.RestConf
 	// Env indicates the running environment: test | dev | prod
@@ -59,6 +229,19 @@ type Config struct {
 	TTL      CacheTTL        `json:",optional"`
 	Logging  LoggingConf     `json:",optional"`
 
+	MarketStorage MarketStorageConf `json:",optional"`
+	LogStream     LogStreamConf     `json:",optional"`
+	Live          LiveConf          `json:",optional"`
+	Share         ShareConf         `json:",optional"`
+	Widget        WidgetConf        `json:",optional"`
+	Templates     TemplatesConf     `json:",optional"`
+	KlineIngest   KlineIngestConf   `json:",optional"`
+	Klines        KlinesConf        `json:",optional"`
+	Metrics       MetricsConf       `json:",optional"`
+	Accounts      AccountsConf      `json:",optional"`
+	Leader        LeaderConf        `json:",optional"`
+	FeatureFlags  FeatureFlagsConf  `json:",optional"`
+
 	LLM      confkit.Section[llmpkg.Config]      `json:",optional"`
 	Executor confkit.Section[executorpkg.Config] `json:",optional"`
 	Manager  confkit.Section[managerpkg.Config]  `json:",optional"`
@@ -0,0 +1,129 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"nof0-api/internal/types"
+)
+
+const comparisonAccountsFile = "comparison-accounts.json"
+
+// ComparisonAccount is a user's own trade history, imported from an exchange
+// export so the leaderboard can rank it against the models. Accounts are kept
+// next to the MCP data files and merged into LoadTrades and LoadLeaderboard.
+type ComparisonAccount struct {
+	Id             string        `json:"id"`
+	Source         string        `json:"source"`
+	StartingEquity float64       `json:"starting_equity"`
+	ImportedAt     int64         `json:"imported_at"`
+	Trades         []types.Trade `json:"trades"`
+}
+
+// LoadComparisonAccounts returns the imported comparison accounts; a data
+// directory without any is not an error.
+func (dl *DataLoader) LoadComparisonAccounts() ([]ComparisonAccount, error) {
+	var data struct {
+		Accounts []ComparisonAccount `json:"accounts"`
+	}
+	err := dl.loadJSONFile(comparisonAccountsFile, &data)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data.Accounts, err
+}
+
+// SaveComparisonAccount adds acct, replacing any account with the same id.
+func (dl *DataLoader) SaveComparisonAccount(acct ComparisonAccount) error {
+	accounts, err := dl.LoadComparisonAccounts()
+	if err != nil {
+		return err
+	}
+	out := []ComparisonAccount{acct}
+	for _, existing := range accounts {
+		if existing.Id != acct.Id {
+			out = append(out, existing)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Id < out[j].Id })
+	body, err := json.MarshalIndent(map[string]any{"accounts": out}, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dl.dataPath, comparisonAccountsFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, body, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LeaderboardEntry summarises the account with the leaderboard's fields.
+// Sharpe is the mean over the standard deviation of per-trade returns on
+// starting equity, unannualised.
+func (a ComparisonAccount) LeaderboardEntry() types.LeaderboardEntry {
+	entry := types.LeaderboardEntry{
+		Id:        a.Id,
+		NumTrades: len(a.Trades),
+		Equity:    a.StartingEquity,
+	}
+	returns := make([]float64, 0, len(a.Trades))
+	for _, t := range a.Trades {
+		pnl := t.RealizedNetPnl
+		entry.Equity += pnl
+		if pnl >= 0 {
+			entry.NumWins++
+			entry.WinDollars += pnl
+		} else {
+			entry.NumLosses++
+			entry.LoseDollars += pnl
+		}
+		if a.StartingEquity > 0 {
+			returns = append(returns, pnl/a.StartingEquity)
+		}
+	}
+	if a.StartingEquity > 0 {
+		entry.ReturnPct = (entry.Equity - a.StartingEquity) / a.StartingEquity * 100
+	}
+	if len(returns) > 1 {
+		var mean float64
+		for _, r := range returns {
+			mean += r
+		}
+		mean /= float64(len(returns))
+		var variance float64
+		for _, r := range returns {
+			variance += (r - mean) * (r - mean)
+		}
+		if std := math.Sqrt(variance / float64(len(returns)-1)); std > 0 {
+			entry.Sharpe = mean / std
+		}
+	}
+	return entry
+}
+
+// mergeComparisonLeaderboard appends comparison accounts, replacing model
+// rows that share an id.
+func mergeComparisonLeaderboard(board []types.LeaderboardEntry, accounts []ComparisonAccount) []types.LeaderboardEntry {
+	if len(accounts) == 0 {
+		return board
+	}
+	imported := make(map[string]struct{}, len(accounts))
+	for _, a := range accounts {
+		imported[a.Id] = struct{}{}
+	}
+	out := make([]types.LeaderboardEntry, 0, len(board)+len(accounts))
+	for _, e := range board {
+		if _, ok := imported[e.Id]; !ok {
+			out = append(out, e)
+		}
+	}
+	for _, a := range accounts {
+		out = append(out, a.LeaderboardEntry())
+	}
+	return out
+}
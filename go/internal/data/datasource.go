@@ -13,6 +13,7 @@ type DataSource interface {
 	LoadModelAnalytics(modelId string) (*types.ModelAnalyticsResponse, error)
 	LoadPositions() (*types.PositionsResponse, error)
 	LoadConversations() (*types.ConversationsResponse, error)
+	LoadRatings() (*types.RatingsResponse, error)
 }
 
 // Ensure DataLoader implements DataSource
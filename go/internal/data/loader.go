@@ -2,6 +2,7 @@ package data
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"time"
@@ -38,7 +39,8 @@ func (dl *DataLoader) LoadAccountTotals() (*types.AccountTotalsResponse, error)
 	return &response, nil
 }
 
-// LoadTrades loads trades from JSON file
+// LoadTrades loads trades from JSON file, followed by the trades of any
+// imported comparison accounts.
 func (dl *DataLoader) LoadTrades() (*types.TradesResponse, error) {
 	var data struct {
 		Trades []types.Trade `json:"trades"`
@@ -47,6 +49,13 @@ func (dl *DataLoader) LoadTrades() (*types.TradesResponse, error) {
 	if err != nil {
 		return nil, err
 	}
+	accounts, err := dl.LoadComparisonAccounts()
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range accounts {
+		data.Trades = append(data.Trades, a.Trades...)
+	}
 
 	return &types.TradesResponse{
 		Trades:     data.Trades,
@@ -65,11 +74,19 @@ func (dl *DataLoader) LoadSinceInception() (*types.SinceInceptionResponse, error
 	return &response, nil
 }
 
-// LoadLeaderboard loads leaderboard from JSON file
+// LoadLeaderboard loads leaderboard from JSON file and ranks imported
+// comparison accounts alongside the models.
 func (dl *DataLoader) LoadLeaderboard() (*types.LeaderboardResponse, error) {
 	var response types.LeaderboardResponse
-	err := dl.loadJSONFile("leaderboard.json", &response)
-	return &response, err
+	if err := dl.loadJSONFile("leaderboard.json", &response); err != nil {
+		return &response, err
+	}
+	accounts, err := dl.LoadComparisonAccounts()
+	if err != nil {
+		return &response, err
+	}
+	response.Leaderboard = mergeComparisonLeaderboard(response.Leaderboard, accounts)
+	return &response, nil
 }
 
 // LoadAnalytics loads all analytics from JSON file
@@ -127,6 +144,17 @@ func (dl *DataLoader) LoadModelAnalytics(modelId string) (*types.ModelAnalyticsR
 	}, nil
 }
 
+// LoadRatings loads the head-to-head Elo ratings written by cmd/elo. Before
+// the first scoring run the response is empty.
+func (dl *DataLoader) LoadRatings() (*types.RatingsResponse, error) {
+	response := types.RatingsResponse{Ratings: []types.ModelRating{}}
+	if err := dl.loadJSONFile("elo-ratings.json", &response); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	response.ServerTime = getCurrentTimestamp()
+	return &response, nil
+}
+
 // Helper function to load JSON file
 func (dl *DataLoader) loadJSONFile(filename string, v interface{}) error {
 	filePath := filepath.Join(dl.dataPath, filename)
@@ -1,6 +1,8 @@
 package data
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -355,3 +357,22 @@ func BenchmarkLoadConversations(b *testing.B) {
 		_, _ = loader.LoadConversations()
 	}
 }
+
+func TestLoadRatings(t *testing.T) {
+	dir := t.TempDir()
+	loader := NewDataLoader(dir)
+
+	resp, err := loader.LoadRatings()
+	require.NoError(t, err)
+	assert.Empty(t, resp.Ratings)
+	assert.NotZero(t, resp.ServerTime)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "elo-ratings.json"),
+		[]byte(`{"ratings":[{"trader_id":"gpt-5","rating":1532.5,"matches":4,"wins":3,"losses":1}],"rounds":2,"matches":4,"horizon":"1h0m0s"}`), 0o644))
+	resp, err = loader.LoadRatings()
+	require.NoError(t, err)
+	require.Len(t, resp.Ratings, 1)
+	assert.Equal(t, "gpt-5", resp.Ratings[0].TraderId)
+	assert.InDelta(t, 1532.5, resp.Ratings[0].Rating, 1e-9)
+	assert.Equal(t, "1h0m0s", resp.Horizon)
+}
@@ -0,0 +1,332 @@
+package data
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"nof0-api/internal/types"
+)
+
+// csvColumnAliases maps the fields the importer needs to the header names
+// used by common exchange exports, compared case-insensitively.
+var csvColumnAliases = map[string][]string{
+	"time":     {"time", "timestamp", "date", "date(utc)", "datetime", "time(utc)"},
+	"symbol":   {"symbol", "coin", "pair", "market", "contract"},
+	"side":     {"side", "dir", "direction"},
+	"price":    {"price", "px", "avg price", "fill price"},
+	"quantity": {"quantity", "qty", "sz", "size", "filled", "executed"},
+	"fee":      {"fee", "fees", "commission"},
+}
+
+var csvRequiredColumns = []string{"time", "symbol", "side", "price", "quantity"}
+
+var csvTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.000000",
+	"2006-01-02 15:04:05.000",
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+	"01/02/2006 - 15:04:05",
+	"01/02/2006 15:04:05",
+}
+
+// symbolQuoteSuffixes are stripped so exchange pairs (BTCUSDT, ETH-PERP)
+// match the base-asset symbols the models trade.
+var symbolQuoteSuffixes = []string{"PERP", "USDT", "USDC", "BUSD", "USD"}
+
+// CSVImport is the result of parsing an exchange trade export.
+type CSVImport struct {
+	// Trades are the round trips closed within the export.
+	Trades []types.Trade
+	// Fills is the number of fill rows read.
+	Fills int
+	// Open lists symbols whose position was still open at the end of the
+	// export; those fills are not part of Trades.
+	Open []string
+}
+
+type csvFill struct {
+	at     time.Time
+	symbol string
+	qty    float64 // signed: positive buys, negative sells
+	price  float64
+	fee    float64
+}
+
+// ParseTradeCSV reads fill-level trade history exported from an exchange and
+// nets the fills per symbol into round-trip trades owned by accountID, in the
+// same shape as the models' trades. A trade opens when the position leaves
+// zero and closes when it returns there; a fill that flips the position is
+// split between the closing and the new trade. PnL is recomputed from fill
+// prices rather than read from the export, because exchanges disagree on
+// whether their realized pnl column already includes fees.
+func ParseTradeCSV(r io.Reader, accountID string) (*CSVImport, error) {
+	accountID = strings.TrimSpace(accountID)
+	if accountID == "" {
+		return nil, errors.New("account id is required")
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	cols, err := mapCSVColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var fills []csvFill
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv line %d: %w", line, err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		fill, err := parseCSVFill(record, cols)
+		if err != nil {
+			return nil, fmt.Errorf("csv line %d: %w", line, err)
+		}
+		fills = append(fills, fill)
+	}
+	if len(fills) == 0 {
+		return nil, errors.New("csv contains no fills")
+	}
+	trades, open := netFills(fills, accountID)
+	return &CSVImport{Trades: trades, Fills: len(fills), Open: open}, nil
+}
+
+func mapCSVColumns(header []string) (map[string]int, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	cols := make(map[string]int, len(csvColumnAliases))
+	for field, aliases := range csvColumnAliases {
+		for _, alias := range aliases {
+			if i, ok := index[alias]; ok {
+				cols[field] = i
+				break
+			}
+		}
+	}
+	var missing []string
+	for _, field := range csvRequiredColumns {
+		if _, ok := cols[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("csv header lacks columns for %s", strings.Join(missing, ", "))
+	}
+	return cols, nil
+}
+
+func parseCSVFill(record []string, cols map[string]int) (csvFill, error) {
+	field := func(name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	var fill csvFill
+	var err error
+	if fill.at, err = parseCSVTime(field("time")); err != nil {
+		return fill, err
+	}
+	if fill.symbol = normalizeCSVSymbol(field("symbol")); fill.symbol == "" {
+		return fill, errors.New("empty symbol")
+	}
+	sign, err := parseCSVSide(field("side"))
+	if err != nil {
+		return fill, err
+	}
+	if fill.price, err = parseCSVNumber(field("price")); err != nil || fill.price <= 0 {
+		return fill, fmt.Errorf("invalid price %q", field("price"))
+	}
+	qty, err := parseCSVNumber(field("quantity"))
+	if err != nil || qty == 0 {
+		return fill, fmt.Errorf("invalid quantity %q", field("quantity"))
+	}
+	fill.qty = sign * math.Abs(qty)
+	if raw := field("fee"); raw != "" {
+		if fill.fee, err = parseCSVNumber(raw); err != nil {
+			return fill, fmt.Errorf("invalid fee %q", raw)
+		}
+		fill.fee = math.Abs(fill.fee)
+	}
+	return fill, nil
+}
+
+func parseCSVTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, errors.New("empty time")
+	}
+	if v, err := strconv.ParseFloat(raw, 64); err == nil {
+		// Exports use either epoch seconds or milliseconds.
+		if v >= 1e12 {
+			return time.UnixMilli(int64(v)).UTC(), nil
+		}
+		return time.UnixMilli(int64(v * 1000)).UTC(), nil
+	}
+	for _, layout := range csvTimeLayouts {
+		if t, err := time.ParseInLocation(layout, raw, time.UTC); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised time %q", raw)
+}
+
+// parseCSVSide returns +1 for fills that buy and -1 for fills that sell.
+func parseCSVSide(raw string) (float64, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "buy", "b", "bid", "open long", "close short":
+		return 1, nil
+	case "sell", "s", "a", "ask", "open short", "close long":
+		return -1, nil
+	}
+	return 0, fmt.Errorf("unrecognised side %q", raw)
+}
+
+// parseCSVNumber accepts thousands separators and a trailing unit such as
+// "0.12 USDT".
+func parseCSVNumber(raw string) (float64, error) {
+	if fields := strings.Fields(raw); len(fields) > 0 {
+		raw = fields[0]
+	}
+	return strconv.ParseFloat(strings.ReplaceAll(raw, ",", ""), 64)
+}
+
+func normalizeCSVSymbol(raw string) string {
+	s := strings.ToUpper(strings.TrimSpace(raw))
+	if base, _, ok := strings.Cut(s, ":"); ok {
+		s = base
+	}
+	s = strings.NewReplacer("-", "", "/", "", "_", "").Replace(s)
+	for _, suffix := range symbolQuoteSuffixes {
+		if trimmed := strings.TrimSuffix(s, suffix); trimmed != "" && trimmed != s {
+			s = trimmed
+		}
+	}
+	return s
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// roundTrip accumulates the fills of one position from open to flat.
+type roundTrip struct {
+	symbol        string
+	dir           float64
+	entryAt       time.Time
+	exitAt        time.Time
+	entryQty      float64
+	entryNotional float64
+	exitQty       float64
+	exitNotional  float64
+	entryFees     float64
+	exitFees      float64
+}
+
+func (rt *roundTrip) open() float64 { return rt.entryQty - rt.exitQty }
+
+func netFills(fills []csvFill, accountID string) ([]types.Trade, []string) {
+	sort.SliceStable(fills, func(i, j int) bool { return fills[i].at.Before(fills[j].at) })
+	positions := make(map[string]*roundTrip)
+	var trades []types.Trade
+	for _, f := range fills {
+		remaining := f.qty
+		size := math.Abs(f.qty)
+		for remaining != 0 {
+			dir := math.Copysign(1, remaining)
+			qty := math.Abs(remaining)
+			share := qty / size
+			rt := positions[f.symbol]
+			if rt == nil {
+				rt = &roundTrip{symbol: f.symbol, dir: dir, entryAt: f.at}
+				positions[f.symbol] = rt
+			}
+			if dir == rt.dir {
+				rt.entryQty += qty
+				rt.entryNotional += qty * f.price
+				rt.entryFees += f.fee * share
+				break
+			}
+			closeQty := math.Min(qty, rt.open())
+			part := closeQty / size
+			rt.exitQty += closeQty
+			rt.exitNotional += closeQty * f.price
+			rt.exitFees += f.fee * part
+			rt.exitAt = f.at
+			remaining -= dir * closeQty
+			if rt.open() <= rt.entryQty*1e-9 {
+				trades = append(trades, rt.trade(accountID))
+				delete(positions, f.symbol)
+			}
+			if math.Abs(remaining) <= size*1e-9 {
+				break
+			}
+		}
+	}
+	open := make([]string, 0, len(positions))
+	for symbol := range positions {
+		open = append(open, symbol)
+	}
+	sort.Strings(open)
+	return trades, open
+}
+
+func (rt *roundTrip) trade(accountID string) types.Trade {
+	entryPrice := rt.entryNotional / rt.entryQty
+	exitPrice := rt.exitNotional / rt.exitQty
+	gross := rt.dir * (exitPrice - entryPrice) * rt.exitQty
+	fees := rt.entryFees + rt.exitFees
+	side := "long"
+	if rt.dir < 0 {
+		side = "short"
+	}
+	entryTime := float64(rt.entryAt.UnixMilli()) / 1000
+	exitTime := float64(rt.exitAt.UnixMilli()) / 1000
+	return types.Trade{
+		Id:                     fmt.Sprintf("%s_%s_%d", accountID, rt.symbol, rt.entryAt.UnixMilli()),
+		ModelId:                accountID,
+		Symbol:                 rt.symbol,
+		Side:                   side,
+		TradeType:              side,
+		TradeId:                fmt.Sprintf("%.3f_%s_%.3f_%s", entryTime, rt.symbol, exitTime, accountID),
+		Quantity:               rt.entryQty,
+		EntryPrice:             entryPrice,
+		EntryTime:              entryTime,
+		EntryHumanTime:         rt.entryAt.Format("2006-01-02 15:04:05.000000"),
+		EntrySz:                rt.entryQty,
+		EntryCommissionDollars: rt.entryFees,
+		ExitPrice:              exitPrice,
+		ExitTime:               exitTime,
+		ExitHumanTime:          rt.exitAt.Format("2006-01-02 15:04:05.000000"),
+		ExitSz:                 rt.exitQty,
+		ExitCommissionDollars:  rt.exitFees,
+		RealizedGrossPnl:       gross,
+		RealizedNetPnl:         gross - fees,
+		TotalCommissionDollars: fees,
+	}
+}
@@ -0,0 +1,122 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTradeCSVBinanceExport(t *testing.T) {
+	const export = `Date(UTC),Symbol,Side,Price,Quantity,Amount,Fee,Realized Profit
+2025-10-24 10:00:00,BTCUSDT,BUY,100000,0.02,2000,0.8 USDT,0
+2025-10-24 11:00:00,BTCUSDT,BUY,102000,0.02,2040,0.8 USDT,0
+2025-10-24 12:00:00,ETHUSDT,SELL,4000,1,"4,000",1.6 USDT,0
+2025-10-24 13:00:00,BTCUSDT,SELL,103000,0.04,4120,1.6 USDT,100
+2025-10-24 14:00:00,ETHUSDT,BUY,3900,0.5,1950,0.8 USDT,50
+`
+	res, err := ParseTradeCSV(strings.NewReader(export), "you")
+	require.NoError(t, err)
+	assert.Equal(t, 5, res.Fills)
+	assert.Equal(t, []string{"ETH"}, res.Open)
+	require.Len(t, res.Trades, 1)
+
+	tr := res.Trades[0]
+	assert.Equal(t, "you", tr.ModelId)
+	assert.Equal(t, "BTC", tr.Symbol)
+	assert.Equal(t, "long", tr.Side)
+	assert.InDelta(t, 0.04, tr.Quantity, 1e-12)
+	assert.InDelta(t, 101000, tr.EntryPrice, 1e-6)
+	assert.InDelta(t, 103000, tr.ExitPrice, 1e-6)
+	assert.InDelta(t, 80, tr.RealizedGrossPnl, 1e-6)
+	assert.InDelta(t, 3.2, tr.TotalCommissionDollars, 1e-9)
+	assert.InDelta(t, 76.8, tr.RealizedNetPnl, 1e-6)
+	assert.Equal(t, "2025-10-24 10:00:00.000000", tr.EntryHumanTime)
+}
+
+func TestParseTradeCSVFlipSplitsFill(t *testing.T) {
+	const export = `time,coin,dir,px,sz,fee
+1761314029249,ETH,Open Long,4000,1,2
+1761314089249,ETH,Sell,4100,3,6
+1761314149249,ETH,Close Short,4050,2,4
+`
+	res, err := ParseTradeCSV(strings.NewReader(export), "you")
+	require.NoError(t, err)
+	assert.Empty(t, res.Open)
+	require.Len(t, res.Trades, 2)
+
+	long, short := res.Trades[0], res.Trades[1]
+	assert.Equal(t, "long", long.Side)
+	assert.InDelta(t, 100, long.RealizedGrossPnl, 1e-9)
+	// One third of the flipping fill's fee closes the long.
+	assert.InDelta(t, 4, long.TotalCommissionDollars, 1e-9)
+
+	assert.Equal(t, "short", short.Side)
+	assert.InDelta(t, 2, short.Quantity, 1e-12)
+	assert.InDelta(t, 100, short.RealizedGrossPnl, 1e-9)
+	assert.InDelta(t, 8, short.TotalCommissionDollars, 1e-9)
+	assert.InDelta(t, 1761314089.249, short.EntryTime, 1e-6)
+}
+
+func TestParseTradeCSVErrors(t *testing.T) {
+	_, err := ParseTradeCSV(strings.NewReader("time,symbol,price\n"), "you")
+	assert.ErrorContains(t, err, "side, quantity")
+
+	_, err = ParseTradeCSV(strings.NewReader("time,symbol,side,price,qty\n2025-10-24 10:00:00,BTC,hold,1,1\n"), "you")
+	assert.ErrorContains(t, err, "line 2")
+
+	_, err = ParseTradeCSV(strings.NewReader("time,symbol,side,price,qty\n"), "you")
+	assert.Error(t, err)
+
+	_, err = ParseTradeCSV(strings.NewReader("time,symbol,side,price,qty\n"), " ")
+	assert.ErrorContains(t, err, "account id")
+}
+
+func TestComparisonAccountsMergeIntoLeaderboard(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "leaderboard.json"),
+		[]byte(`{"leaderboard":[{"id":"gpt-5","return_pct":3.5,"num_trades":4}]}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "trades.json"), []byte(`{"trades":[{"id":"t1","model_id":"gpt-5"}]}`), 0o644))
+	loader := NewDataLoader(dir)
+
+	accounts, err := loader.LoadComparisonAccounts()
+	require.NoError(t, err)
+	assert.Empty(t, accounts)
+
+	const export = `time,symbol,side,price,qty
+2025-10-24 10:00:00,SOL,buy,200,10
+2025-10-24 11:00:00,SOL,sell,210,10
+2025-10-24 12:00:00,SOL,buy,210,10
+2025-10-24 13:00:00,SOL,sell,205,10
+`
+	res, err := ParseTradeCSV(strings.NewReader(export), "you")
+	require.NoError(t, err)
+	require.NoError(t, loader.SaveComparisonAccount(ComparisonAccount{Id: "you", StartingEquity: 1000, Trades: res.Trades}))
+
+	board, err := loader.LoadLeaderboard()
+	require.NoError(t, err)
+	require.Len(t, board.Leaderboard, 2)
+	you := board.Leaderboard[1]
+	assert.Equal(t, "you", you.Id)
+	assert.Equal(t, 2, you.NumTrades)
+	assert.Equal(t, 1, you.NumWins)
+	assert.Equal(t, 1, you.NumLosses)
+	assert.InDelta(t, 100, you.WinDollars, 1e-9)
+	assert.InDelta(t, -50, you.LoseDollars, 1e-9)
+	assert.InDelta(t, 1050, you.Equity, 1e-9)
+	assert.InDelta(t, 5, you.ReturnPct, 1e-9)
+
+	trades, err := loader.LoadTrades()
+	require.NoError(t, err)
+	assert.Len(t, trades.Trades, 3)
+
+	// Re-importing replaces the account instead of duplicating it.
+	require.NoError(t, loader.SaveComparisonAccount(ComparisonAccount{Id: "you", StartingEquity: 1000, Trades: res.Trades[:1]}))
+	board, err = loader.LoadLeaderboard()
+	require.NoError(t, err)
+	require.Len(t, board.Leaderboard, 2)
+	assert.Equal(t, 1, board.Leaderboard[1].NumTrades)
+}
@@ -0,0 +1,240 @@
+// Package engine runs the trading decision cycle: it gathers market data and
+// account state into the prompt context, has the executor render the prompts,
+// call the model and parse its decisions, puts every decision through risk
+// review and submits the survivors to the exchange.
+//
+// Each stage is an interface so callers can swap in their own: the manager
+// plugs in its per-trader context builder, risk vetoes and execution path,
+// while MarketGatherer and ExchangeSubmitter drive a bare exchange.Exchange
+// and market.Provider directly.
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+// Gatherer assembles the prompt context for one cycle from market data and
+// account state.
+type Gatherer interface {
+	Gather(ctx context.Context) (executorpkg.Context, error)
+}
+
+// GatherFunc adapts a function to Gatherer.
+type GatherFunc func(ctx context.Context) (executorpkg.Context, error)
+
+// Gather calls f.
+func (f GatherFunc) Gather(ctx context.Context) (executorpkg.Context, error) { return f(ctx) }
+
+// Decider renders the prompts for a context, calls the model and parses its
+// decisions. executor.Executor satisfies it.
+type Decider interface {
+	GetFullDecision(input *executorpkg.Context) (*executorpkg.FullDecision, error)
+}
+
+// Planner orders the parsed decisions and drops those that must not run this
+// cycle, e.g. opens beyond the free position slots.
+type Planner interface {
+	Plan(ctx context.Context, ectx *executorpkg.Context, out *executorpkg.FullDecision) []executorpkg.Decision
+}
+
+// PlanFunc adapts a function to Planner.
+type PlanFunc func(ctx context.Context, ectx *executorpkg.Context, out *executorpkg.FullDecision) []executorpkg.Decision
+
+// Plan calls f.
+func (f PlanFunc) Plan(ctx context.Context, ectx *executorpkg.Context, out *executorpkg.FullDecision) []executorpkg.Decision {
+	return f(ctx, ectx, out)
+}
+
+// Reviewer applies risk checks to a decision before it is submitted. A
+// non-nil error vetoes the decision; a reviewer may also resize it in place.
+type Reviewer interface {
+	Review(ctx context.Context, ectx *executorpkg.Context, d *executorpkg.Decision) error
+}
+
+// ReviewFunc adapts a function to Reviewer.
+type ReviewFunc func(ctx context.Context, ectx *executorpkg.Context, d *executorpkg.Decision) error
+
+// Review calls f.
+func (f ReviewFunc) Review(ctx context.Context, ectx *executorpkg.Context, d *executorpkg.Decision) error {
+	return f(ctx, ectx, d)
+}
+
+// Submitter sends one reviewed decision to the exchange.
+type Submitter interface {
+	Submit(ctx context.Context, d *executorpkg.Decision) error
+}
+
+// SubmitFunc adapts a function to Submitter.
+type SubmitFunc func(ctx context.Context, d *executorpkg.Decision) error
+
+// Submit calls f.
+func (f SubmitFunc) Submit(ctx context.Context, d *executorpkg.Decision) error { return f(ctx, d) }
+
+// Hooks observe a cycle as it moves through the stages. All are optional and
+// run on the cycle's goroutine.
+type Hooks struct {
+	// Gathered runs once the prompt context is assembled, with the gather
+	// error; the cycle stops after it when err is non-nil. It may still
+	// amend ectx before the prompt is rendered.
+	Gathered func(ctx context.Context, ectx *executorpkg.Context, err error)
+	// Decided runs after the model call with whatever the executor returned.
+	Decided func(ctx context.Context, ectx *executorpkg.Context, out *executorpkg.FullDecision, err error)
+	// Executed runs after each planned decision was reviewed and submitted;
+	// err is the veto or submission error.
+	Executed func(ctx context.Context, d executorpkg.Decision, err error)
+}
+
+// Engine runs decision cycles. Gatherer, Decider and Submitter are required;
+// without a Planner decisions run closes first, and without a Reviewer every
+// decision goes straight to the Submitter.
+type Engine struct {
+	Gatherer  Gatherer
+	Decider   Decider
+	Planner   Planner
+	Reviewer  Reviewer
+	Submitter Submitter
+	Hooks     Hooks
+
+	// Interval is how often Run starts a cycle.
+	Interval time.Duration
+}
+
+// Execution is the outcome of one planned decision.
+type Execution struct {
+	Decision executorpkg.Decision
+	Err      error
+}
+
+// Result is what one cycle produced.
+type Result struct {
+	Context executorpkg.Context
+	// GatherErr aborted the cycle before the model was called.
+	GatherErr error
+	Output    *executorpkg.FullDecision
+	// DecisionErr is the executor's error. The executor can return parsed
+	// decisions alongside a validation error; those are never submitted.
+	DecisionErr error
+	Executions  []Execution
+}
+
+// OK reports whether the cycle reached a valid decision and every submitted
+// decision succeeded.
+func (r *Result) OK() bool {
+	if r == nil || r.GatherErr != nil || r.Output == nil || r.DecisionErr != nil {
+		return false
+	}
+	for _, ex := range r.Executions {
+		if ex.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+var errNotConfigured = errors.New("engine: gatherer, decider and submitter are required")
+
+// Step runs one decision cycle. It returns an error only when the engine is
+// misconfigured; stage failures are reported on the Result.
+func (e *Engine) Step(ctx context.Context) (*Result, error) {
+	if e == nil || e.Gatherer == nil || e.Decider == nil || e.Submitter == nil {
+		return nil, errNotConfigured
+	}
+	res := &Result{}
+	res.Context, res.GatherErr = e.Gatherer.Gather(ctx)
+	if e.Hooks.Gathered != nil {
+		e.Hooks.Gathered(ctx, &res.Context, res.GatherErr)
+	}
+	if res.GatherErr != nil {
+		return res, nil
+	}
+
+	res.Output, res.DecisionErr = e.Decider.GetFullDecision(&res.Context)
+	if e.Hooks.Decided != nil {
+		e.Hooks.Decided(ctx, &res.Context, res.Output, res.DecisionErr)
+	}
+	if res.Output == nil || res.DecisionErr != nil {
+		return res, nil
+	}
+
+	var planned []executorpkg.Decision
+	if e.Planner != nil {
+		planned = e.Planner.Plan(ctx, &res.Context, res.Output)
+	} else {
+		planned = CloseFirst(res.Output.Decisions)
+	}
+	for i := range planned {
+		d := planned[i]
+		var err error
+		if e.Reviewer != nil {
+			err = e.Reviewer.Review(ctx, &res.Context, &d)
+		}
+		if err == nil {
+			err = e.Submitter.Submit(ctx, &d)
+		}
+		res.Executions = append(res.Executions, Execution{Decision: d, Err: err})
+		if e.Hooks.Executed != nil {
+			e.Hooks.Executed(ctx, d, err)
+		}
+	}
+	return res, nil
+}
+
+// Run starts a cycle every Interval until ctx ends. Cycles do not overlap: a
+// cycle that overruns the interval delays the next tick.
+func (e *Engine) Run(ctx context.Context) error {
+	if e == nil || e.Interval <= 0 {
+		return fmt.Errorf("engine: interval must be positive")
+	}
+	ticker := time.NewTicker(e.Interval)
+	defer ticker.Stop()
+	for {
+		res, err := e.Step(ctx)
+		if err != nil {
+			return err
+		}
+		if !res.OK() {
+			logx.WithContext(ctx).Slowf("engine: cycle incomplete gather_err=%v decision_err=%v executions=%d", res.GatherErr, res.DecisionErr, len(res.Executions))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// CloseFirst returns ds with closes ahead of opens and holds last, by symbol
+// within each group, so a close frees margin and a position slot before an
+// open needs them.
+func CloseFirst(ds []executorpkg.Decision) []executorpkg.Decision {
+	out := make([]executorpkg.Decision, len(ds))
+	copy(out, ds)
+	sort.SliceStable(out, func(i, j int) bool {
+		pri := priority(out[i].Action)
+		prj := priority(out[j].Action)
+		if pri != prj {
+			return pri < prj
+		}
+		return out[i].Symbol < out[j].Symbol
+	})
+	return out
+}
+
+func priority(action string) int {
+	switch action {
+	case "close_long", "close_short":
+		return 0
+	case "open_long", "open_short":
+		return 1
+	default:
+		return 2
+	}
+}
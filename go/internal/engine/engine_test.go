@@ -0,0 +1,231 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange/sim"
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/llm"
+	"nof0-api/pkg/market"
+	"nof0-api/pkg/risk"
+)
+
+// fakeLLM answers every prompt with one fixed decision.
+type fakeLLM struct {
+	payload string
+	prompts int
+}
+
+func (f *fakeLLM) Chat(context.Context, *llm.ChatRequest) (*llm.ChatResponse, error) {
+	return nil, nil
+}
+
+func (f *fakeLLM) ChatStream(context.Context, *llm.ChatRequest) (<-chan llm.StreamResponse, error) {
+	return nil, nil
+}
+
+func (f *fakeLLM) ChatStructured(_ context.Context, _ *llm.ChatRequest, target interface{}) (*llm.ChatResponse, error) {
+	f.prompts++
+	if err := llm.ParseStructured(f.payload, target); err != nil {
+		return nil, err
+	}
+	return &llm.ChatResponse{
+		Model:   "test-model",
+		Choices: []llm.Choice{{Message: llm.Message{Role: "assistant", Content: f.payload}}},
+	}, nil
+}
+
+func (f *fakeLLM) GetConfig() *llm.Config { return &llm.Config{} }
+func (f *fakeLLM) Close() error           { return nil }
+
+// fakeMarket serves fixed prices; symbols without one fail.
+type fakeMarket map[string]float64
+
+func (m fakeMarket) Snapshot(_ context.Context, symbol string) (*market.Snapshot, error) {
+	px, ok := m[symbol]
+	if !ok {
+		return nil, errors.New("no data")
+	}
+	return &market.Snapshot{Symbol: symbol, Price: market.PriceInfo{Last: px}}, nil
+}
+
+func (m fakeMarket) ListAssets(context.Context) ([]market.Asset, error) { return nil, nil }
+
+const buyBTC = `{
+  "signal":"buy_to_enter",
+  "symbol":"BTC",
+  "leverage":5,
+  "position_size_usd":200,
+  "entry_price":100,
+  "stop_loss":95,
+  "take_profit":115,
+  "risk_usd":10,
+  "confidence":90,
+  "invalidation_condition":"below EMA20",
+  "reasoning":"clear uptrend"
+}`
+
+func newExecutor(t *testing.T, client llm.LLMClient) executorpkg.Executor {
+	t.Helper()
+	cfg := &executorpkg.Config{
+		MajorCoinLeverage:      10,
+		AltcoinLeverage:        5,
+		MinConfidence:          70,
+		MinRiskReward:          2,
+		MaxPositions:           2,
+		DecisionIntervalRaw:    "3m",
+		DecisionTimeoutRaw:     "60s",
+		MaxConcurrentDecisions: 1,
+	}
+	exec, err := executorpkg.NewExecutor(cfg, client, filepath.Join("..", "..", "etc", "prompts", "executor", "default_prompt.tmpl"), "")
+	require.NoError(t, err)
+	return exec
+}
+
+func newEngine(t *testing.T, client llm.LLMClient, mkt fakeMarket) (*Engine, *sim.Provider) {
+	t.Helper()
+	ex := sim.New()
+	for sym, px := range mkt {
+		require.NoError(t, ex.SetMarkPrice(context.Background(), sym, px))
+	}
+	return &Engine{
+		Gatherer: &MarketGatherer{Exchange: ex, Market: mkt, Symbols: []string{"BTC"}, MajorCoinLeverage: 10, AltcoinLeverage: 5},
+		Decider:  newExecutor(t, client),
+		// A 200 USD entry stopped at 95 loses 10 USD; the guard halves it.
+		Reviewer:  GuardReviewer{Guard: risk.NewGuard(risk.Config{MaxLossPerTradeUSD: 5, Resize: true})},
+		Submitter: &ExchangeSubmitter{Exchange: ex, Market: mkt},
+		Interval:  time.Minute,
+	}, ex
+}
+
+func TestStepTradesThroughExchange(t *testing.T) {
+	ctx := context.Background()
+	model := &fakeLLM{payload: buyBTC}
+	eng, ex := newEngine(t, model, fakeMarket{"BTC": 100})
+
+	res, err := eng.Step(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, model.prompts)
+	assert.True(t, res.OK(), "gather=%v decision=%v", res.GatherErr, res.DecisionErr)
+	require.Len(t, res.Executions, 1)
+	assert.InDelta(t, 100, res.Executions[0].Decision.PositionSizeUSD, 1e-9, "resized by the guard")
+	positions, err := ex.GetPositions(ctx)
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	qty, err := strconv.ParseFloat(positions[0].Szi, 64)
+	require.NoError(t, err)
+	assert.InDelta(t, 1, qty, 1e-9)
+}
+
+func TestStepStopsWithoutMarketData(t *testing.T) {
+	model := &fakeLLM{payload: buyBTC}
+	eng, _ := newEngine(t, model, fakeMarket{"ETH": 10})
+	var gathered error
+	eng.Hooks.Gathered = func(_ context.Context, _ *executorpkg.Context, err error) { gathered = err }
+
+	res, err := eng.Step(context.Background())
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, res.GatherErr, "market data unavailable: BTC")
+	assert.Equal(t, res.GatherErr, gathered)
+	assert.Zero(t, model.prompts, "no prompt on a partial view")
+	assert.False(t, res.OK())
+}
+
+// stubDecider returns a fixed output and error.
+type stubDecider struct {
+	out *executorpkg.FullDecision
+	err error
+}
+
+func (s stubDecider) GetFullDecision(*executorpkg.Context) (*executorpkg.FullDecision, error) {
+	return s.out, s.err
+}
+
+func TestStepSkipsDecisionsThatFailedValidation(t *testing.T) {
+	submitted := 0
+	eng := &Engine{
+		Gatherer: GatherFunc(func(context.Context) (executorpkg.Context, error) { return executorpkg.Context{}, nil }),
+		Decider: stubDecider{
+			out: &executorpkg.FullDecision{Decisions: []executorpkg.Decision{{Symbol: "BTC", Action: "open_long"}}},
+			err: errors.New("confidence below minimum"),
+		},
+		Submitter: SubmitFunc(func(context.Context, *executorpkg.Decision) error { submitted++; return nil }),
+	}
+
+	res, err := eng.Step(context.Background())
+	require.NoError(t, err)
+
+	assert.Zero(t, submitted)
+	assert.Empty(t, res.Executions)
+	assert.False(t, res.OK())
+}
+
+func TestStepReviewsAndSubmitsClosesFirst(t *testing.T) {
+	var order []string
+	var executed []string
+	eng := &Engine{
+		Gatherer: GatherFunc(func(context.Context) (executorpkg.Context, error) { return executorpkg.Context{}, nil }),
+		Decider: stubDecider{out: &executorpkg.FullDecision{Decisions: []executorpkg.Decision{
+			{Symbol: "SOL", Action: "hold"},
+			{Symbol: "ETH", Action: "open_short"},
+			{Symbol: "BTC", Action: "close_long"},
+		}}},
+		Reviewer: ReviewFunc(func(_ context.Context, _ *executorpkg.Context, d *executorpkg.Decision) error {
+			if d.Symbol == "ETH" {
+				return errors.New("vetoed")
+			}
+			return nil
+		}),
+		Submitter: SubmitFunc(func(_ context.Context, d *executorpkg.Decision) error {
+			order = append(order, d.Symbol)
+			return nil
+		}),
+		Hooks: Hooks{Executed: func(_ context.Context, d executorpkg.Decision, err error) {
+			executed = append(executed, d.Symbol+":"+strconv.FormatBool(err == nil))
+		}},
+	}
+
+	res, err := eng.Step(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"BTC", "SOL"}, order, "the veto keeps ETH from the exchange")
+	assert.Equal(t, []string{"BTC:true", "ETH:false", "SOL:true"}, executed)
+	assert.False(t, res.OK())
+}
+
+func TestStepRequiresStages(t *testing.T) {
+	_, err := (&Engine{}).Step(context.Background())
+	assert.ErrorIs(t, err, errNotConfigured)
+}
+
+func TestRunStepsEveryInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	steps := 0
+	eng := &Engine{
+		Gatherer: GatherFunc(func(context.Context) (executorpkg.Context, error) {
+			steps++
+			if steps == 3 {
+				cancel()
+			}
+			return executorpkg.Context{}, nil
+		}),
+		Decider:   stubDecider{out: &executorpkg.FullDecision{}},
+		Submitter: SubmitFunc(func(context.Context, *executorpkg.Decision) error { return nil }),
+		Interval:  time.Millisecond,
+	}
+
+	err := eng.Run(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 3, steps)
+}
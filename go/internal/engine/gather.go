@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"nof0-api/pkg/exchange"
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+)
+
+// MarketGatherer builds the prompt context from one read of the exchange
+// account and a market snapshot for every configured symbol and open
+// position. A symbol without a snapshot fails the gather, so the model never
+// decides on a partial view.
+type MarketGatherer struct {
+	Exchange exchange.Exchange
+	Market   market.Provider
+	// Symbols are the candidates offered to the model besides open positions.
+	Symbols []string
+	// MajorCoinLeverage and AltcoinLeverage are the leverage caps stated in
+	// the prompt.
+	MajorCoinLeverage int
+	AltcoinLeverage   int
+
+	// Now stamps the context; time.Now when nil.
+	Now func() time.Time
+
+	calls int
+}
+
+// Gather implements Gatherer.
+func (g *MarketGatherer) Gather(ctx context.Context) (executorpkg.Context, error) {
+	if g.Exchange == nil || g.Market == nil {
+		return executorpkg.Context{}, fmt.Errorf("engine: gatherer needs an exchange and a market provider")
+	}
+	state, err := g.Exchange.GetAccountState(ctx)
+	if err != nil {
+		return executorpkg.Context{}, fmt.Errorf("engine: account state: %w", err)
+	}
+	account := executorpkg.AccountInfo{
+		TotalEquity: parseFloat(state.MarginSummary.AccountValue),
+		MarginUsed:  parseFloat(state.MarginSummary.TotalMarginUsed),
+	}
+	account.AvailableBalance = account.TotalEquity - account.MarginUsed
+	positions := make([]executorpkg.PositionInfo, 0, len(state.AssetPositions))
+	for _, p := range state.AssetPositions {
+		qty := parseFloat(p.Szi)
+		if qty == 0 {
+			continue
+		}
+		side := "long"
+		if qty < 0 {
+			side, qty = "short", -qty
+		}
+		var entry float64
+		if p.EntryPx != nil {
+			entry = parseFloat(*p.EntryPx)
+		}
+		pnl := parseFloat(p.UnrealizedPnl)
+		account.TotalPnL += pnl
+		positions = append(positions, executorpkg.PositionInfo{
+			Symbol:        p.Coin,
+			Side:          side,
+			EntryPrice:    entry,
+			Quantity:      qty,
+			Leverage:      p.Leverage.Value,
+			MarginMode:    p.Leverage.Type,
+			UnrealizedPnL: pnl,
+		})
+	}
+	account.PositionCount = len(positions)
+	if account.TotalEquity != 0 {
+		account.MarginUsedPct = 100 * account.MarginUsed / account.TotalEquity
+		account.TotalPnLPct = 100 * account.TotalPnL / account.TotalEquity
+	}
+
+	candidates := make([]executorpkg.CandidateCoin, 0, len(g.Symbols))
+	for _, sym := range g.Symbols {
+		candidates = append(candidates, executorpkg.CandidateCoin{Symbol: sym, Sources: []string{"configured"}})
+	}
+	now := time.Now
+	if g.Now != nil {
+		now = g.Now
+	}
+	g.calls++
+	base := executorpkg.Context{
+		CurrentTime:       now().UTC().Format(time.RFC3339),
+		CallCount:         g.calls,
+		Account:           account,
+		Positions:         positions,
+		CandidateCoins:    candidates,
+		MajorCoinLeverage: g.MajorCoinLeverage,
+		AltcoinLeverage:   g.AltcoinLeverage,
+	}
+	ectx, err := executorpkg.BuildContext(ctx, &base, g.Market)
+	if err != nil {
+		return executorpkg.Context{}, err
+	}
+	for i := range ectx.Positions {
+		if snap := ectx.MarketDataMap[ectx.Positions[i].Symbol]; snap != nil {
+			ectx.Positions[i].MarkPrice = snap.Price.Last
+		}
+	}
+	var missing []string
+	for _, sym := range wanted(ectx) {
+		if ectx.MarketDataMap[sym] == nil {
+			missing = append(missing, sym)
+		}
+	}
+	if len(missing) > 0 {
+		return executorpkg.Context{}, fmt.Errorf("engine: market data unavailable: %s", strings.Join(missing, ","))
+	}
+	return *ectx, nil
+}
+
+// wanted lists the symbols the context needs market data for.
+func wanted(ectx *executorpkg.Context) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, c := range ectx.CandidateCoins {
+		if !seen[c.Symbol] {
+			seen[c.Symbol] = true
+			out = append(out, c.Symbol)
+		}
+	}
+	for _, p := range ectx.Positions {
+		if !seen[p.Symbol] {
+			seen[p.Symbol] = true
+			out = append(out, p.Symbol)
+		}
+	}
+	return out
+}
+
+func parseFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return v
+}
@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"nof0-api/pkg/exchange"
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+	"nof0-api/pkg/risk"
+)
+
+// GuardReviewer puts every open through a risk.Guard, priced at the
+// decision's entry and checked against the equity and exposure in the prompt
+// context. When the guard resizes an order the decision is resized to match.
+// Closes and holds pass unchecked.
+type GuardReviewer struct {
+	Guard *risk.Guard
+}
+
+// Review implements Reviewer.
+func (r GuardReviewer) Review(_ context.Context, ectx *executorpkg.Context, d *executorpkg.Decision) error {
+	if !isOpen(d.Action) {
+		return nil
+	}
+	entry := d.EntryPrice
+	if snap := ectx.MarketDataMap[d.Symbol]; entry <= 0 && snap != nil {
+		entry = snap.Price.Last
+	}
+	exposure := make(map[string]float64, len(ectx.Positions))
+	for _, p := range ectx.Positions {
+		exposure[p.Symbol] += p.Quantity * p.MarkPrice
+	}
+	verdict, err := r.Guard.Check(risk.Order{
+		Symbol:     d.Symbol,
+		Long:       d.Action == "open_long",
+		SizeUSD:    d.PositionSizeUSD,
+		EntryPrice: entry,
+		StopLoss:   d.StopLoss,
+		TakeProfit: d.TakeProfit,
+		Leverage:   d.Leverage,
+	}, risk.Portfolio{EquityUSD: ectx.Account.TotalEquity, ExposureUSD: exposure})
+	if err != nil {
+		return err
+	}
+	d.PositionSizeUSD = verdict.Order.SizeUSD
+	d.Leverage = verdict.Order.Leverage
+	return nil
+}
+
+// ExchangeSubmitter sends decisions to an exchange.Exchange: closes flatten
+// the symbol, opens go out as immediate-or-cancel limit orders sized from the
+// decision's notional at the market's last price. Holds are not sent.
+type ExchangeSubmitter struct {
+	Exchange exchange.Exchange
+	// Market prices opens; the decision's entry price is used without it.
+	Market market.Provider
+	// SlippageBps moves the limit price away from the reference price so an
+	// IOC order still crosses the spread.
+	SlippageBps float64
+}
+
+// Submit implements Submitter.
+func (s *ExchangeSubmitter) Submit(ctx context.Context, d *executorpkg.Decision) error {
+	switch d.Action {
+	case "close_long", "close_short":
+		resp, err := s.Exchange.ClosePosition(ctx, d.Symbol)
+		if err != nil {
+			return fmt.Errorf("engine: close %s: %w", d.Symbol, err)
+		}
+		return orderError(resp)
+	case "open_long", "open_short":
+		return s.open(ctx, d)
+	default:
+		return nil
+	}
+}
+
+func (s *ExchangeSubmitter) open(ctx context.Context, d *executorpkg.Decision) error {
+	price := d.EntryPrice
+	if s.Market != nil {
+		if snap, err := s.Market.Snapshot(ctx, d.Symbol); err == nil && snap != nil && snap.Price.Last > 0 {
+			price = snap.Price.Last
+		}
+	}
+	if price <= 0 || d.PositionSizeUSD <= 0 {
+		return fmt.Errorf("engine: open %s needs a positive price and size", d.Symbol)
+	}
+	asset, err := s.Exchange.GetAssetIndex(ctx, d.Symbol)
+	if err != nil {
+		return fmt.Errorf("engine: asset index %s: %w", d.Symbol, err)
+	}
+	if d.Leverage > 0 {
+		if err := s.Exchange.UpdateLeverage(ctx, asset, true, d.Leverage); err != nil {
+			return fmt.Errorf("engine: leverage %s: %w", d.Symbol, err)
+		}
+	}
+	isBuy := d.Action == "open_long"
+	limit := price * (1 + s.SlippageBps/10000)
+	if !isBuy {
+		limit = price * (1 - s.SlippageBps/10000)
+	}
+	resp, err := s.Exchange.PlaceOrder(ctx, exchange.Order{
+		Asset:     asset,
+		IsBuy:     isBuy,
+		LimitPx:   strconv.FormatFloat(limit, 'f', -1, 64),
+		Sz:        strconv.FormatFloat(d.PositionSizeUSD/price, 'f', -1, 64),
+		OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Ioc"}},
+	})
+	if err != nil {
+		return fmt.Errorf("engine: open %s: %w", d.Symbol, err)
+	}
+	return orderError(resp)
+}
+
+// orderError surfaces a rejection the exchange reported in an otherwise
+// successful response.
+func orderError(resp *exchange.OrderResponse) error {
+	if resp == nil {
+		return nil
+	}
+	if resp.Status == "err" {
+		return errors.New("engine: order rejected")
+	}
+	for _, st := range resp.Response.Data.Statuses {
+		if st.Error != "" {
+			return fmt.Errorf("engine: order rejected: %s", st.Error)
+		}
+	}
+	return nil
+}
+
+func isOpen(action string) bool {
+	return action == "open_long" || action == "open_short"
+}
@@ -0,0 +1,242 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"nof0-api/internal/backtest"
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/llm"
+	"nof0-api/pkg/market"
+)
+
+const defaultEquity = 10000
+
+// Config selects the models to evaluate and how their executors are built.
+type Config struct {
+	// Executor and TemplatePath configure every model's executor exactly as
+	// for a live trader.
+	Executor     *executorpkg.Config
+	TemplatePath string
+	// LLM answers for every alias in Models; it receives the alias as the
+	// request model.
+	LLM    llm.LLMClient
+	Models []string
+}
+
+// Result is one model's answer to one scenario.
+type Result struct {
+	Scenario   string   `json:"scenario"`
+	Kind       string   `json:"kind"`
+	Symbol     string   `json:"symbol"`
+	Action     string   `json:"action,omitempty"`
+	Reasonable []string `json:"reasonable"`
+	Pass       bool     `json:"pass"`
+	// Error is set when the decision failed validation or the call failed;
+	// such answers never pass.
+	Error     string `json:"error,omitempty"`
+	Reasoning string `json:"reasoning,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// KindScore aggregates one scenario kind.
+type KindScore struct {
+	Total  int     `json:"total"`
+	Passed int     `json:"passed"`
+	Score  float64 `json:"score"`
+}
+
+// ModelReport scores one model across the suite. Score is the percentage of
+// scenarios answered with a reasonable action.
+type ModelReport struct {
+	Model        string               `json:"model"`
+	Total        int                  `json:"total"`
+	Passed       int                  `json:"passed"`
+	Errors       int                  `json:"errors"`
+	Score        float64              `json:"score"`
+	ByKind       map[string]KindScore `json:"by_kind"`
+	AvgLatencyMs int64                `json:"avg_latency_ms"`
+	Results      []Result             `json:"results"`
+}
+
+// Report compares models on one suite, best score first.
+type Report struct {
+	Suite       string        `json:"suite"`
+	Scenarios   int           `json:"scenarios"`
+	Models      []ModelReport `json:"models"`
+	GeneratedAt time.Time     `json:"generated_at"`
+}
+
+// Run builds every scenario's market snapshot from src and asks each model
+// for a decision on it.
+func Run(ctx context.Context, src backtest.Source, suite *Suite, cfg Config) (*Report, error) {
+	switch {
+	case src == nil:
+		return nil, errors.New("eval: source is required")
+	case suite == nil || len(suite.Scenarios) == 0:
+		return nil, errors.New("eval: suite has no scenarios")
+	case cfg.Executor == nil || cfg.LLM == nil:
+		return nil, errors.New("eval: executor config and llm are required")
+	case len(cfg.Models) == 0:
+		return nil, errors.New("eval: no models to evaluate")
+	}
+	snaps := make([]*market.Snapshot, len(suite.Scenarios))
+	for i, sc := range suite.Scenarios {
+		snap, err := backtest.SnapshotAt(ctx, src, sc.Symbol, sc.AsOf, suite.IntradayInterval, suite.LongTermInterval)
+		if err != nil {
+			return nil, fmt.Errorf("eval: scenario %q: %w", sc.Name, err)
+		}
+		snaps[i] = snap
+	}
+	observer, _ := cfg.LLM.(backtest.ContextObserver)
+
+	report := &Report{Suite: suite.Name, Scenarios: len(suite.Scenarios), GeneratedAt: time.Now().UTC()}
+	for _, model := range cfg.Models {
+		exec, err := executorpkg.NewExecutor(cfg.Executor, cfg.LLM, cfg.TemplatePath, model)
+		if err != nil {
+			return nil, fmt.Errorf("eval: init executor for %s: %w", model, err)
+		}
+		mr := ModelReport{Model: model, ByKind: make(map[string]KindScore)}
+		var latency time.Duration
+		for i, sc := range suite.Scenarios {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			input := scenarioContext(suite, sc, snaps[i])
+			if observer != nil {
+				observer.Observe(input)
+			}
+			start := time.Now()
+			full, err := exec.GetFullDecision(input)
+			took := time.Since(start)
+			latency += took
+
+			res := Result{Scenario: sc.Name, Kind: sc.Kind, Symbol: sc.Symbol, Reasonable: sc.Reasonable, LatencyMs: took.Milliseconds()}
+			if err != nil {
+				res.Error = err.Error()
+				mr.Errors++
+			} else {
+				res.Action, res.Reasoning = chosenAction(full, sc.Symbol)
+				res.Pass = contains(sc.Reasonable, res.Action)
+			}
+			k := mr.ByKind[sc.Kind]
+			k.Total++
+			mr.Total++
+			if res.Pass {
+				k.Passed++
+				mr.Passed++
+			}
+			mr.ByKind[sc.Kind] = k
+			mr.Results = append(mr.Results, res)
+		}
+		for kind, k := range mr.ByKind {
+			k.Score = pct(k.Passed, k.Total)
+			mr.ByKind[kind] = k
+		}
+		mr.Score = pct(mr.Passed, mr.Total)
+		mr.AvgLatencyMs = (latency / time.Duration(mr.Total)).Milliseconds()
+		report.Models = append(report.Models, mr)
+	}
+	sort.SliceStable(report.Models, func(i, j int) bool { return report.Models[i].Score > report.Models[j].Score })
+	return report, nil
+}
+
+// scenarioContext builds the executor input for a scenario, seeding its
+// position at the snapshot's mark price.
+func scenarioContext(suite *Suite, sc Scenario, snap *market.Snapshot) *executorpkg.Context {
+	equity := suite.Equity
+	if equity <= 0 {
+		equity = defaultEquity
+	}
+	input := &executorpkg.Context{
+		CurrentTime:    sc.AsOf.Format(time.RFC3339),
+		CallCount:      1,
+		CandidateCoins: []executorpkg.CandidateCoin{{Symbol: sc.Symbol, Sources: []string{"eval"}}},
+		MarketDataMap:  map[string]*market.Snapshot{sc.Symbol: snap},
+		Account: executorpkg.AccountInfo{
+			TotalEquity:      equity,
+			AvailableBalance: equity,
+		},
+	}
+	if p := sc.Position; p != nil {
+		mark := snap.Price.Last
+		qty := p.SizeUSD / p.EntryPrice
+		pnl := (mark - p.EntryPrice) * qty
+		if p.Side == "short" {
+			pnl = -pnl
+		}
+		margin := p.SizeUSD / float64(p.Leverage)
+		input.Positions = []executorpkg.PositionInfo{{
+			Symbol:           sc.Symbol,
+			Side:             p.Side,
+			EntryPrice:       p.EntryPrice,
+			MarkPrice:        mark,
+			Quantity:         qty,
+			Leverage:         p.Leverage,
+			UnrealizedPnL:    pnl,
+			UnrealizedPnLPct: pnl / margin * 100,
+			MarginUsed:       margin,
+		}}
+		input.Account.TotalEquity = equity + pnl
+		input.Account.MarginUsed = margin
+		input.Account.AvailableBalance = input.Account.TotalEquity - margin
+		input.Account.MarginUsedPct = margin / input.Account.TotalEquity * 100
+		input.Account.PositionCount = 1
+	}
+	return input
+}
+
+// chosenAction returns the first non-hold action on symbol, or "hold" when
+// the model left it alone.
+func chosenAction(full *executorpkg.FullDecision, symbol string) (string, string) {
+	action, reasoning := "hold", ""
+	for _, d := range full.Decisions {
+		if !strings.EqualFold(d.Symbol, symbol) {
+			continue
+		}
+		a := strings.ToLower(strings.TrimSpace(d.Action))
+		if a == "" || a == "hold" || a == "wait" {
+			if reasoning == "" {
+				reasoning = d.Reasoning
+			}
+			continue
+		}
+		return a, d.Reasoning
+	}
+	return action, reasoning
+}
+
+func pct(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total) * 100
+}
+
+// WriteTable prints one row per model with its overall and per-kind scores.
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "MODEL\tSCORE\tPASSED\tERRORS")
+	for _, kind := range Kinds {
+		fmt.Fprintf(tw, "\t%s", strings.ToUpper(kind))
+	}
+	fmt.Fprintln(tw, "\tAVG LATENCY")
+	for _, m := range r.Models {
+		fmt.Fprintf(tw, "%s\t%.1f\t%d/%d\t%d", m.Model, m.Score, m.Passed, m.Total, m.Errors)
+		for _, kind := range Kinds {
+			if k, ok := m.ByKind[kind]; ok {
+				fmt.Fprintf(tw, "\t%d/%d", k.Passed, k.Total)
+			} else {
+				fmt.Fprint(tw, "\t-")
+			}
+		}
+		fmt.Fprintf(tw, "\t%s\n", time.Duration(m.AvgLatencyMs)*time.Millisecond)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,165 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/internal/backtest"
+	"nof0-api/pkg/decision"
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/llm"
+	"nof0-api/pkg/market"
+)
+
+// rallySource serves a rally with pullbacks into 2025-01-03 followed by a selloff.
+type rallySource struct{}
+
+func (rallySource) Klines(ctx context.Context, symbol, interval string, from, to time.Time) ([]market.Candle, error) {
+	step, err := time.ParseDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+	turn := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	var out []market.Candle
+	for t := from.Truncate(step); t.Before(to); t = t.Add(step) {
+		if t.Before(from) {
+			continue
+		}
+		hours := t.Sub(turn).Hours()
+		px := 1000 + 2*hours + 10*math.Sin(hours/2)
+		if hours > 0 {
+			px = 1000 - 6*hours + 5*math.Sin(hours/3)
+		}
+		out = append(out, market.Candle{OpenTime: t.UnixMilli(), Open: px, High: px * 1.002, Low: px * 0.998, Close: px, Volume: 10})
+	}
+	return out, nil
+}
+
+func (rallySource) Metrics(ctx context.Context, symbol string, from, to time.Time) ([]backtest.Metric, error) {
+	return nil, nil
+}
+
+// modelsLLM answers as the trend rule for "rules" and always holds for any
+// other model.
+type modelsLLM struct {
+	backtest.RulesLLM
+}
+
+func (m *modelsLLM) ChatStructured(ctx context.Context, req *llm.ChatRequest, target interface{}) (*llm.ChatResponse, error) {
+	if req.Model == "rules" {
+		return m.RulesLLM.ChatStructured(ctx, req, target)
+	}
+	hold := decision.Contract{Signal: string(decision.SignalHold), Symbol: "BTC"}
+	data, err := json.Marshal(hold)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return nil, err
+	}
+	return &llm.ChatResponse{
+		Model:   req.Model,
+		Choices: []llm.Choice{{Message: llm.Message{Role: "assistant", Content: string(data)}, FinishReason: "stop"}},
+	}, nil
+}
+
+const testSuite = `
+name: unit
+intraday_interval: 1h
+long_term_interval: 4h
+scenarios:
+  - name: rally
+    kind: trend
+    symbol: btc
+    as_of: 2025-01-02T10:00:00Z
+    reasonable: [open_long]
+  - name: selloff-flat
+    kind: crash
+    symbol: BTC
+    as_of: 2025-01-03T12:00:00Z
+    reasonable: [open_short, hold]
+  - name: selloff-long
+    kind: crash
+    symbol: BTC
+    as_of: 2025-01-03T12:00:00Z
+    position: {side: long, entry_price: 1000, size_usd: 2000, leverage: 2}
+    reasonable: [CLOSE_LONG]
+`
+
+func writeSuite(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "suite.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+	return path
+}
+
+func TestLoadSuiteValidates(t *testing.T) {
+	suite, err := LoadSuite(writeSuite(t, testSuite))
+	require.NoError(t, err)
+	require.Len(t, suite.Scenarios, 3)
+	assert.Equal(t, "BTC", suite.Scenarios[0].Symbol)
+	assert.Equal(t, []string{"close_long"}, suite.Scenarios[2].Reasonable)
+
+	for _, bad := range []string{
+		"scenarios: [{name: a, kind: sideways, symbol: BTC, as_of: 2025-01-02T00:00:00Z, reasonable: [hold]}]",
+		"scenarios: [{name: a, kind: chop, symbol: BTC, as_of: 2025-01-02T00:00:00Z, reasonable: [buy]}]",
+		"scenarios: [{name: a, kind: chop, symbol: BTC, reasonable: [hold]}]",
+		"scenarios: [{name: a, kind: chop, symbol: BTC, as_of: 2025-01-02T00:00:00Z, reasonable: [hold], position: {side: up, entry_price: 1, size_usd: 1}}]",
+	} {
+		_, err := LoadSuite(writeSuite(t, bad))
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRunScoresModelsAgainstLabels(t *testing.T) {
+	suite, err := LoadSuite(writeSuite(t, testSuite))
+	require.NoError(t, err)
+	cfg := Config{
+		Executor: &executorpkg.Config{
+			MajorCoinLeverage:      10,
+			AltcoinLeverage:        5,
+			MinConfidence:          50,
+			MinRiskReward:          1.5,
+			MaxPositions:           1,
+			DecisionIntervalRaw:    "1h",
+			DecisionTimeoutRaw:     "10s",
+			MaxConcurrentDecisions: 1,
+		},
+		TemplatePath: filepath.Join("..", "..", "etc", "prompts", "executor", "default_prompt.tmpl"),
+		LLM:          &modelsLLM{RulesLLM: backtest.RulesLLM{Symbol: "BTC"}},
+		Models:       []string{"holder", "rules"},
+	}
+	report, err := Run(context.Background(), rallySource{}, suite, cfg)
+	require.NoError(t, err)
+	require.Len(t, report.Models, 2)
+
+	best, worst := report.Models[0], report.Models[1]
+	assert.Equal(t, "rules", best.Model, "best score first")
+	assert.Equal(t, 3, best.Passed, "%+v", best.Results)
+	assert.Equal(t, 100.0, best.Score)
+	assert.Equal(t, "open_long", best.Results[0].Action)
+	assert.Equal(t, "close_long", best.Results[2].Action)
+
+	assert.Equal(t, "holder", worst.Model)
+	assert.Equal(t, 1, worst.Passed)
+	assert.Equal(t, KindScore{Total: 1, Passed: 0, Score: 0}, worst.ByKind[KindTrend])
+	assert.Equal(t, KindScore{Total: 2, Passed: 1, Score: 50}, worst.ByKind[KindCrash])
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteTable(&buf))
+	assert.Contains(t, buf.String(), "rules")
+	assert.Contains(t, buf.String(), "CRASH")
+
+	suite.Scenarios[0].AsOf = time.Date(2025, 1, 2, 10, 30, 0, 0, time.UTC)
+	_, err = Run(context.Background(), rallySource{}, suite, cfg)
+	assert.ErrorContains(t, err, "rally", "an as_of off the bar grid has no snapshot")
+}
@@ -0,0 +1,139 @@
+// Package eval scores models offline against a fixed suite of historical
+// market scenarios before they are allowed to trade.
+package eval
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario kinds. Every suite is expected to cover each of them so reports
+// stay comparable across models and suite revisions.
+const (
+	KindTrend   = "trend"
+	KindChop    = "chop"
+	KindCrash   = "crash"
+	KindSqueeze = "squeeze"
+)
+
+// Kinds lists the scenario kinds in report order.
+var Kinds = []string{KindTrend, KindChop, KindCrash, KindSqueeze}
+
+// Actions are the executor actions a scenario may label as reasonable.
+var Actions = []string{"open_long", "open_short", "close_long", "close_short", "hold"}
+
+// Suite is a fixed set of labeled scenarios.
+type Suite struct {
+	Name string `yaml:"name"`
+	// IntradayInterval and LongTermInterval name the stored kline intervals;
+	// they default to the live provider's "3m" and "4h".
+	IntradayInterval string `yaml:"intraday_interval"`
+	LongTermInterval string `yaml:"long_term_interval"`
+	// Equity is the account value shown to the model; defaults to 10000.
+	Equity    float64    `yaml:"equity"`
+	Scenarios []Scenario `yaml:"scenarios"`
+}
+
+// Scenario is one decision point: the market at AsOf, optionally with a
+// position already open, and the actions a reasonable trader might take.
+type Scenario struct {
+	Name   string    `yaml:"name"`
+	Kind   string    `yaml:"kind"`
+	Symbol string    `yaml:"symbol"`
+	AsOf   time.Time `yaml:"as_of"`
+	// Position, when set, is held on Symbol going into the decision.
+	Position   *Position `yaml:"position,omitempty"`
+	Reasonable []string  `yaml:"reasonable"`
+	Note       string    `yaml:"note,omitempty"`
+}
+
+// Position is an open position seeded into a scenario.
+type Position struct {
+	Side       string  `yaml:"side"`
+	EntryPrice float64 `yaml:"entry_price"`
+	SizeUSD    float64 `yaml:"size_usd"`
+	Leverage   int     `yaml:"leverage"`
+}
+
+// LoadSuite reads and validates a suite file.
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("open eval suite: %w", err)
+	}
+	var s Suite
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("unmarshal eval suite: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Validate normalises names, symbols and actions and rejects incomplete
+// scenarios.
+func (s *Suite) Validate() error {
+	if len(s.Scenarios) == 0 {
+		return fmt.Errorf("eval suite: no scenarios")
+	}
+	if s.Equity < 0 {
+		return fmt.Errorf("eval suite: equity cannot be negative")
+	}
+	seen := make(map[string]bool, len(s.Scenarios))
+	for i := range s.Scenarios {
+		sc := &s.Scenarios[i]
+		sc.Name = strings.TrimSpace(sc.Name)
+		sc.Kind = strings.ToLower(strings.TrimSpace(sc.Kind))
+		sc.Symbol = strings.ToUpper(strings.TrimSpace(sc.Symbol))
+		switch {
+		case sc.Name == "":
+			return fmt.Errorf("eval suite: scenarios[%d] has no name", i)
+		case seen[sc.Name]:
+			return fmt.Errorf("eval suite: duplicate scenario %q", sc.Name)
+		case !contains(Kinds, sc.Kind):
+			return fmt.Errorf("eval suite: scenario %q kind %q (want %s)", sc.Name, sc.Kind, strings.Join(Kinds, ", "))
+		case sc.Symbol == "":
+			return fmt.Errorf("eval suite: scenario %q has no symbol", sc.Name)
+		case sc.AsOf.IsZero():
+			return fmt.Errorf("eval suite: scenario %q has no as_of", sc.Name)
+		case len(sc.Reasonable) == 0:
+			return fmt.Errorf("eval suite: scenario %q labels no reasonable actions", sc.Name)
+		}
+		seen[sc.Name] = true
+		sc.AsOf = sc.AsOf.UTC()
+		for j, a := range sc.Reasonable {
+			a = strings.ToLower(strings.TrimSpace(a))
+			if !contains(Actions, a) {
+				return fmt.Errorf("eval suite: scenario %q action %q (want %s)", sc.Name, a, strings.Join(Actions, ", "))
+			}
+			sc.Reasonable[j] = a
+		}
+		if p := sc.Position; p != nil {
+			p.Side = strings.ToLower(strings.TrimSpace(p.Side))
+			if p.Side != "long" && p.Side != "short" {
+				return fmt.Errorf("eval suite: scenario %q position side %q (want long or short)", sc.Name, p.Side)
+			}
+			if p.EntryPrice <= 0 || p.SizeUSD <= 0 {
+				return fmt.Errorf("eval suite: scenario %q position needs entry_price and size_usd", sc.Name)
+			}
+			if p.Leverage <= 0 {
+				p.Leverage = 1
+			}
+		}
+	}
+	return nil
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
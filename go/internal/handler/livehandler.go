@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/core/logc"
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"golang.org/x/net/websocket"
+	"nof0-api/internal/logic"
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+)
+
+// liveMaxMessageBytes bounds client messages, which are only small commands.
+const liveMaxMessageBytes = 4 << 10
+
+func LiveHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.LiveRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := logic.NewLiveLogic(r.Context(), svcCtx)
+		if err := l.Validate(&req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		// The public dashboard is served from other origins, so unlike
+		// websocket.Handler no Origin check is made.
+		websocket.Server{Handler: func(ws *websocket.Conn) {
+			ws.MaxPayloadBytes = liveMaxMessageBytes
+			if err := l.Live(&req, liveConn{ws: ws}); err != nil {
+				logc.Errorw(r.Context(), "LiveHandler", logc.Field("error", err))
+			}
+		}}.ServeHTTP(w, r)
+	}
+}
+
+// liveConn adapts a websocket connection to logic.LiveConn.
+type liveConn struct {
+	ws *websocket.Conn
+}
+
+func (c liveConn) Receive() ([]byte, error) {
+	var msg []byte
+	err := websocket.Message.Receive(c.ws, &msg)
+	return msg, err
+}
+
+func (c liveConn) Send(msg *types.LiveMessage) error {
+	return websocket.JSON.Send(c.ws, msg)
+}
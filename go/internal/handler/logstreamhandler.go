@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logc"
+	"github.com/zeromicro/go-zero/core/threading"
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"nof0-api/internal/logic"
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+)
+
+// logStreamKeepAlive keeps idle connections open through proxies.
+const logStreamKeepAlive = 15 * time.Second
+
+func LogStreamHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.LogStreamRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := logic.NewLogStreamLogic(r.Context(), svcCtx)
+		if err := l.Validate(&req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		client := make(chan *types.PipelineEvent, 16)
+		threading.GoSafeCtx(r.Context(), func() {
+			defer close(client)
+			if err := l.LogStream(&req, client); err != nil {
+				logc.Errorw(r.Context(), "LogStreamHandler", logc.Field("error", err))
+			}
+		})
+
+		flusher, _ := w.(http.Flusher)
+		flush := func() {
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		// Let EventSource retry quickly after a disconnect.
+		fmt.Fprint(w, "retry: 3000\n\n")
+		flush()
+
+		keepAlive := time.NewTicker(logStreamKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case ev, ok := <-client:
+				if !ok {
+					return
+				}
+				output, err := json.Marshal(ev)
+				if err != nil {
+					logc.Errorw(r.Context(), "LogStreamHandler", logc.Field("error", err))
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "id: %s\ndata: %s\n\n", ev.Id, output); err != nil {
+					return
+				}
+				flush()
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+					return
+				}
+				flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
@@ -5,6 +5,7 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"nof0-api/internal/svc"
 
@@ -59,7 +60,154 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/conversations",
 				Handler: ConversationsHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/cycles/latest",
+				Handler: CyclesLatestHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/status",
+				Handler: StatusHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/timeline/:modelId",
+				Handler: TimelineHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/account/snapshot",
+				Handler: AccountSnapshotHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/state/:modelId",
+				Handler: StateAsOfHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/klines",
+				Handler: KlinesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/klines/markers",
+				Handler: KlineMarkersHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/decisions",
+				Handler: DecisionsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/journal/:modelId",
+				Handler: TradeJournalHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/journal/:modelId/:id",
+				Handler: TradeJournalEntryHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/share/:token",
+				Handler: SharedPerformanceHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/ratings",
+				Handler: RatingsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/risk/explain",
+				Handler: RiskExplainHandler(serverCtx),
+			},
 		},
 		rest.WithPrefix("/api"),
 	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.LogStreamAuth},
+			[]rest.Route{
+				{
+					Method:  http.MethodGet,
+					Path:    "/logs/stream",
+					Handler: LogStreamHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithPrefix("/api"),
+		rest.WithSSE(),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.ShareIssuerAuth},
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/share-links",
+					Handler: ShareLinkHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithPrefix("/api"),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.TemplatesAuth},
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/templates/render",
+					Handler: TemplateRenderHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithPrefix("/api"),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.WidgetEquityLimit},
+			[]rest.Route{
+				{
+					Method:  http.MethodGet,
+					Path:    "/widget/equity.svg",
+					Handler: WidgetEquityHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithPrefix("/api"),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.WidgetLeaderboardLimit},
+			[]rest.Route{
+				{
+					Method:  http.MethodGet,
+					Path:    "/widget/leaderboard.json",
+					Handler: WidgetLeaderboardHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithPrefix("/api"),
+	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/ws",
+				Handler: LiveHandler(serverCtx),
+			},
+		},
+		rest.WithTimeout(0*time.Millisecond),
+	)
 }
@@ -9,12 +9,19 @@ import (
 	"github.com/zeromicro/go-zero/rest/httpx"
 	"nof0-api/internal/logic"
 	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
 )
 
 func TradesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.TradesRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
 		l := logic.NewTradesLogic(r.Context(), svcCtx)
-		resp, err := l.Trades()
+		resp, err := l.Trades(&req)
 		if err != nil {
 			httpx.ErrorCtx(r.Context(), w, err)
 		} else {
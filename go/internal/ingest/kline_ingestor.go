@@ -0,0 +1,579 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/internal/model"
+	marketpkg "nof0-api/pkg/market"
+)
+
+// KlineStore persists candles. marketpersist.SeriesStore implements it.
+type KlineStore interface {
+	UpsertKlines(ctx context.Context, rows []*model.Klines) error
+	LatestKlineOpenTime(ctx context.Context, provider, symbol, interval string) (time.Time, bool, error)
+}
+
+// KlineWindows receive closed candles as they are ingested, such as the
+// rolling windows prompts are read from. market.WindowCache implements it.
+type KlineWindows interface {
+	// WindowIntervals maps each windowed interval to the closed candles the
+	// windows are seeded with on start.
+	WindowIntervals() map[string]int
+	AppendKline(symbol, interval string, c marketpkg.Candle)
+}
+
+// KlineIngestorConfig configures a KlineIngestor.
+type KlineIngestorConfig struct {
+	// Provider is the exchange_provider rows are stored under.
+	Provider string
+	Symbols  []string
+	// Intervals lists the stored intervals. The smallest is streamed and the
+	// others, which must be multiples of it, are aggregated from it.
+	// Defaults to 1m, 3m and 4h.
+	Intervals []string
+	// Backfill bounds how far back missing history is fetched on start;
+	// defaults to 72h.
+	Backfill time.Duration
+	// FlushInterval is how often closed candles are written; defaults to 5s.
+	FlushInterval time.Duration
+	// Windows, when set, are seeded over REST on start and then receive
+	// every closed candle of a stored interval.
+	Windows KlineWindows
+	// Trades subscribes to the feed's trade stream when it has one
+	// (market.TradeFeed). A trade in the next base interval closes the open
+	// candle at once, and trades start the candle when no update has.
+	Trades bool
+}
+
+const (
+	defaultKlineBackfill      = 72 * time.Hour
+	defaultKlineFlushInterval = 5 * time.Second
+	// klineCloseGrace is how long after its close an open candle with no
+	// successor is taken as final, for quiet markets without trades.
+	klineCloseGrace   = 10 * time.Second
+	klineUpdateBuffer = 1024
+	klineUpsertBatch  = 500
+	// klineMaxPending caps rows held back by a failing store; beyond it
+	// candles are dropped and counted until a flush succeeds.
+	klineMaxPending   = 50000
+	klineFetchTimeout = 20 * time.Second
+)
+
+var defaultKlineIntervals = []string{"1m", "3m", "4h"}
+
+type klineInterval struct {
+	name string
+	step time.Duration
+}
+
+func (iv klineInterval) stepMs() int64 { return iv.step.Milliseconds() }
+
+// klineBucket accumulates base candles into one higher-interval candle.
+type klineBucket struct {
+	candle  marketpkg.Candle
+	candles int
+}
+
+// klineSeries is the streaming state of one symbol.
+type klineSeries struct {
+	open       *marketpkg.Candle // newest base candle not yet known to be final
+	lastClosed int64             // open time of the newest final base candle
+	buckets    map[string]*klineBucket
+	// openFromTrades marks an open candle built from trades alone, whose
+	// volume is the sum of their sizes rather than the feed's running total.
+	openFromTrades bool
+}
+
+type klineKey struct {
+	symbol   string
+	interval string
+	open     int64
+}
+
+// KlineIngestor fills the klines table from an exchange websocket feed. On
+// start it backfills every interval over REST from the newest stored candle
+// (bounded by Backfill); afterwards it streams the base interval, fills gaps
+// in it over REST, and rolls closed base candles up into the higher
+// intervals. A higher-interval candle whose base candles were not all seen,
+// such as the one in progress at start, is fetched over REST when it closes.
+// Only closed candles are written. When the store keeps failing, at most
+// klineMaxPending candles are held; later ones are dropped, logged and
+// counted until a flush succeeds.
+type KlineIngestor struct {
+	provider  string
+	feed      marketpkg.KlineFeed
+	store     KlineStore
+	symbols   []string
+	base      klineInterval
+	higher    []klineInterval
+	backfill  time.Duration
+	flushTick time.Duration
+	windows   KlineWindows
+	trades    bool
+	now       func() time.Time
+
+	series     map[string]*klineSeries
+	pending    map[klineKey]*model.Klines
+	maxPending int
+	// dropped counts candles not staged since pending filled up.
+	dropped int
+}
+
+// NewKlineIngestor validates cfg and builds an ingestor writing feed's
+// candles to store.
+func NewKlineIngestor(feed marketpkg.KlineFeed, store KlineStore, cfg KlineIngestorConfig) (*KlineIngestor, error) {
+	if feed == nil || store == nil {
+		return nil, fmt.Errorf("kline ingest: feed and store are required")
+	}
+	provider := strings.TrimSpace(cfg.Provider)
+	if provider == "" {
+		return nil, fmt.Errorf("kline ingest: provider is required")
+	}
+	names := cfg.Intervals
+	if len(names) == 0 {
+		names = defaultKlineIntervals
+	}
+	intervals := make([]klineInterval, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		step, err := time.ParseDuration(name)
+		if err != nil || step <= 0 {
+			return nil, fmt.Errorf("kline ingest: invalid interval %q", name)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		intervals = append(intervals, klineInterval{name: name, step: step})
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].step < intervals[j].step })
+	base := intervals[0]
+	for _, iv := range intervals[1:] {
+		if iv.step%base.step != 0 {
+			return nil, fmt.Errorf("kline ingest: interval %s is not a multiple of %s", iv.name, base.name)
+		}
+	}
+	var symbols []string
+	uniq := make(map[string]bool)
+	for _, sym := range cfg.Symbols {
+		sym = strings.ToUpper(strings.TrimSpace(sym))
+		if sym != "" && !uniq[sym] {
+			uniq[sym] = true
+			symbols = append(symbols, sym)
+		}
+	}
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("kline ingest: no symbols")
+	}
+	backfill := cfg.Backfill
+	if backfill <= 0 {
+		backfill = defaultKlineBackfill
+	}
+	flush := cfg.FlushInterval
+	if flush <= 0 {
+		flush = defaultKlineFlushInterval
+	}
+	return &KlineIngestor{
+		provider:   provider,
+		feed:       feed,
+		store:      store,
+		symbols:    symbols,
+		base:       base,
+		higher:     intervals[1:],
+		backfill:   backfill,
+		flushTick:  flush,
+		windows:    cfg.Windows,
+		trades:     cfg.Trades,
+		now:        time.Now,
+		series:     make(map[string]*klineSeries, len(symbols)),
+		pending:    make(map[klineKey]*model.Klines),
+		maxPending: klineMaxPending,
+	}, nil
+}
+
+// Run backfills, then streams until ctx is cancelled.
+func (k *KlineIngestor) Run(ctx context.Context) {
+	for _, sym := range k.symbols {
+		if err := k.feed.Subscribe(sym, k.base.name); err != nil {
+			logx.WithContext(ctx).Errorf("kline ingest: subscribe provider=%s symbol=%s err=%v", k.provider, sym, err)
+			return
+		}
+		k.series[sym] = &klineSeries{buckets: make(map[string]*klineBucket)}
+	}
+	trades := k.subscribeTrades(ctx)
+	k.seedWindows(ctx)
+	k.backfillAll(ctx)
+	// Backfilled candles are newer than anything stored, so no reader has
+	// them cached; skip the per-batch cache deletes.
+	k.flush(model.WithCacheBypass(ctx))
+
+	updates := make(chan marketpkg.KlineUpdate, klineUpdateBuffer)
+	go func() {
+		err := k.feed.Run(ctx, func(u marketpkg.KlineUpdate) {
+			select {
+			case updates <- u:
+			case <-ctx.Done():
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			logx.WithContext(ctx).Errorf("kline ingest: feed provider=%s stopped err=%v", k.provider, err)
+		}
+	}()
+
+	ticker := time.NewTicker(k.flushTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Write what is already closed with a fresh context.
+			flushCtx, cancel := context.WithTimeout(context.Background(), klineFetchTimeout)
+			k.flush(flushCtx)
+			cancel()
+			return
+		case u := <-updates:
+			k.handle(ctx, u)
+		case t := <-trades:
+			k.handleTrade(ctx, t)
+		case <-ticker.C:
+			k.closeStale(ctx)
+			k.flush(ctx)
+		}
+	}
+}
+
+// subscribeTrades subscribes every symbol to the feed's trade stream when
+// Trades is set and returns the channel trades arrive on; it is nil, and
+// never ready, otherwise.
+func (k *KlineIngestor) subscribeTrades(ctx context.Context) chan marketpkg.TradeUpdate {
+	if !k.trades {
+		return nil
+	}
+	feed, ok := k.feed.(marketpkg.TradeFeed)
+	if !ok {
+		logx.WithContext(ctx).Slowf("kline ingest: feed provider=%s has no trade stream; candles close on the next candle update", k.provider)
+		return nil
+	}
+	for _, sym := range k.symbols {
+		if err := feed.SubscribeTrades(sym); err != nil {
+			logx.WithContext(ctx).Errorf("kline ingest: subscribe trades provider=%s symbol=%s err=%v", k.provider, sym, err)
+			return nil
+		}
+	}
+	trades := make(chan marketpkg.TradeUpdate, klineUpdateBuffer)
+	feed.OnTrade(func(t marketpkg.TradeUpdate) {
+		select {
+		case trades <- t:
+		case <-ctx.Done():
+		}
+	})
+	return trades
+}
+
+// backfillAll fetches every closed candle newer than the stored ones.
+func (k *KlineIngestor) backfillAll(ctx context.Context) {
+	now := k.now().UTC()
+	for _, sym := range k.symbols {
+		for _, iv := range append([]klineInterval{k.base}, k.higher...) {
+			if ctx.Err() != nil {
+				return
+			}
+			from := now.Add(-k.backfill).Truncate(iv.step)
+			latest, ok, err := k.store.LatestKlineOpenTime(ctx, k.provider, sym, iv.name)
+			if err != nil {
+				logx.WithContext(ctx).Errorf("kline ingest: latest provider=%s symbol=%s interval=%s err=%v", k.provider, sym, iv.name, err)
+			} else if ok && !latest.Before(from) {
+				from = latest.Add(iv.step)
+			}
+			if iv == k.base && ok {
+				k.series[sym].lastClosed = latest.UnixMilli()
+			}
+			to := now.Truncate(iv.step) // every bar opened before this has closed
+			if !from.Before(to) {
+				continue
+			}
+			candles, err := k.fetch(ctx, sym, iv, from, to)
+			if err != nil {
+				logx.WithContext(ctx).Errorf("kline ingest: backfill provider=%s symbol=%s interval=%s err=%v", k.provider, sym, iv.name, err)
+				continue
+			}
+			for _, c := range candles {
+				k.queue(ctx, sym, iv, c, "rest")
+				if iv == k.base && c.OpenTime > k.series[sym].lastClosed {
+					k.series[sym].lastClosed = c.OpenTime
+				}
+			}
+			logx.WithContext(ctx).Infof("kline ingest: backfilled provider=%s symbol=%s interval=%s candles=%d", k.provider, sym, iv.name, len(candles))
+		}
+	}
+}
+
+// seedWindows fills the windows with the newest closed candles of each
+// windowed interval, so they serve warmed series before the stream has
+// delivered as many candles.
+func (k *KlineIngestor) seedWindows(ctx context.Context) {
+	if k.windows == nil {
+		return
+	}
+	sizes := k.windows.WindowIntervals()
+	names := make([]string, 0, len(sizes))
+	for name := range sizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	now := k.now().UTC()
+	for _, name := range names {
+		iv, ok := k.interval(name)
+		if !ok {
+			logx.WithContext(ctx).Slowf("kline ingest: window interval %s is not ingested provider=%s; it will not advance", name, k.provider)
+			continue
+		}
+		to := now.Truncate(iv.step)
+		from := to.Add(-time.Duration(sizes[name]) * iv.step)
+		for _, sym := range k.symbols {
+			if ctx.Err() != nil {
+				return
+			}
+			candles, err := k.fetch(ctx, sym, iv, from, to)
+			if err != nil {
+				logx.WithContext(ctx).Errorf("kline ingest: seed window provider=%s symbol=%s interval=%s err=%v", k.provider, sym, name, err)
+				continue
+			}
+			for _, c := range candles {
+				k.windows.AppendKline(sym, name, c)
+			}
+		}
+	}
+}
+
+// interval returns the stored interval called name.
+func (k *KlineIngestor) interval(name string) (klineInterval, bool) {
+	for _, iv := range append([]klineInterval{k.base}, k.higher...) {
+		if iv.name == name {
+			return iv, true
+		}
+	}
+	return klineInterval{}, false
+}
+
+func (k *KlineIngestor) handle(ctx context.Context, u marketpkg.KlineUpdate) {
+	sym := strings.ToUpper(u.Symbol)
+	s, ok := k.series[sym]
+	if !ok || u.Interval != k.base.name {
+		return
+	}
+	c := u.Candle
+	if c.OpenTime <= s.lastClosed {
+		return
+	}
+	k.advance(ctx, sym, s, c.OpenTime)
+	s.openFromTrades = false
+	if u.Closed {
+		k.closeBase(ctx, sym, s, c)
+		s.open = nil
+		return
+	}
+	s.open = &c
+}
+
+// handleTrade folds a trade into the open base candle, first closing an
+// older one. The feed's own candle updates replace what trades built.
+func (k *KlineIngestor) handleTrade(ctx context.Context, t marketpkg.TradeUpdate) {
+	s, ok := k.series[strings.ToUpper(t.Symbol)]
+	if !ok || t.Price <= 0 {
+		return
+	}
+	open := t.Time - t.Time%k.base.stepMs()
+	if open <= s.lastClosed || (s.open != nil && open < s.open.OpenTime) {
+		return
+	}
+	k.advance(ctx, strings.ToUpper(t.Symbol), s, open)
+	if s.open == nil {
+		s.open = &marketpkg.Candle{OpenTime: open, Open: t.Price, High: t.Price, Low: t.Price}
+		s.openFromTrades = true
+	}
+	s.open.High = max(s.open.High, t.Price)
+	s.open.Low = min(s.open.Low, t.Price)
+	s.open.Close = t.Price
+	if s.openFromTrades {
+		s.open.Volume += t.Size
+	}
+}
+
+// advance closes the open base candle once one opening at openMs is seen
+// and fills any base candles missing in between.
+func (k *KlineIngestor) advance(ctx context.Context, sym string, s *klineSeries, openMs int64) {
+	if s.open != nil && openMs > s.open.OpenTime {
+		k.closeBase(ctx, sym, s, *s.open)
+		s.open = nil
+	}
+	if s.lastClosed > 0 && openMs > s.lastClosed+k.base.stepMs() {
+		k.fillGap(ctx, sym, s, openMs)
+	}
+}
+
+// fillGap fetches the base candles missing before the one opening at
+// untilMs, going back at most Backfill.
+func (k *KlineIngestor) fillGap(ctx context.Context, sym string, s *klineSeries, untilMs int64) {
+	from := time.UnixMilli(s.lastClosed + k.base.stepMs()).UTC()
+	if oldest := k.now().Add(-k.backfill).Truncate(k.base.step); from.Before(oldest) {
+		from = oldest
+	}
+	candles, err := k.fetch(ctx, sym, k.base, from, time.UnixMilli(untilMs).UTC())
+	if err != nil {
+		logx.WithContext(ctx).Errorf("kline ingest: gap provider=%s symbol=%s from=%s err=%v", k.provider, sym, from.Format(time.RFC3339), err)
+		return
+	}
+	for _, c := range candles {
+		k.closeBase(ctx, sym, s, c)
+	}
+}
+
+// closeStale closes open candles that should have been followed by now.
+func (k *KlineIngestor) closeStale(ctx context.Context) {
+	now := k.now().UnixMilli()
+	for _, sym := range k.symbols {
+		s := k.series[sym]
+		if s.open != nil && s.open.OpenTime+k.base.stepMs()+klineCloseGrace.Milliseconds() < now {
+			k.closeBase(ctx, sym, s, *s.open)
+			s.open = nil
+		}
+	}
+}
+
+// closeBase records a final base candle and rolls it into the higher
+// intervals.
+func (k *KlineIngestor) closeBase(ctx context.Context, sym string, s *klineSeries, c marketpkg.Candle) {
+	if c.OpenTime <= s.lastClosed {
+		return
+	}
+	s.lastClosed = c.OpenTime
+	k.queue(ctx, sym, k.base, c, "ws")
+	for _, iv := range k.higher {
+		perBucket := int(iv.step / k.base.step)
+		open := c.OpenTime - c.OpenTime%iv.stepMs()
+		b := s.buckets[iv.name]
+		if b != nil && b.candle.OpenTime != open {
+			// The bucket's last base candle never arrived.
+			k.fetchBucket(ctx, sym, iv, b.candle.OpenTime)
+			b = nil
+		}
+		if b == nil {
+			b = &klineBucket{candle: marketpkg.Candle{OpenTime: open, Open: c.Open, High: c.High, Low: c.Low}}
+			s.buckets[iv.name] = b
+		}
+		b.candle.High = max(b.candle.High, c.High)
+		b.candle.Low = min(b.candle.Low, c.Low)
+		b.candle.Close = c.Close
+		b.candle.Volume += c.Volume
+		b.candles++
+		if c.OpenTime+k.base.stepMs() < open+iv.stepMs() {
+			continue
+		}
+		delete(s.buckets, iv.name)
+		if b.candles == perBucket {
+			k.queue(ctx, sym, iv, b.candle, "aggregate")
+		} else {
+			k.fetchBucket(ctx, sym, iv, open)
+		}
+	}
+}
+
+// fetchBucket fetches one higher-interval candle that could not be built
+// from streamed candles.
+func (k *KlineIngestor) fetchBucket(ctx context.Context, sym string, iv klineInterval, openMs int64) {
+	from := time.UnixMilli(openMs).UTC()
+	if from.Add(iv.step).After(k.now()) {
+		return
+	}
+	candles, err := k.fetch(ctx, sym, iv, from, from.Add(iv.step))
+	if err != nil {
+		logx.WithContext(ctx).Errorf("kline ingest: fetch provider=%s symbol=%s interval=%s open=%s err=%v", k.provider, sym, iv.name, from.Format(time.RFC3339), err)
+		return
+	}
+	for _, c := range candles {
+		k.queue(ctx, sym, iv, c, "rest")
+	}
+}
+
+func (k *KlineIngestor) fetch(ctx context.Context, sym string, iv klineInterval, from, to time.Time) ([]marketpkg.Candle, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, klineFetchTimeout)
+	defer cancel()
+	return k.feed.History(fetchCtx, sym, iv.name, from, to)
+}
+
+// queue stages a closed candle for the next flush, where a later write of
+// the same candle replaces an earlier one, and rolls it into the windows.
+// A full backlog is flushed at once; if the store still fails, the candle is
+// dropped and counted.
+func (k *KlineIngestor) queue(ctx context.Context, sym string, iv klineInterval, c marketpkg.Candle, source string) {
+	if k.windows != nil {
+		k.windows.AppendKline(sym, iv.name, c)
+	}
+	key := klineKey{symbol: sym, interval: iv.name, open: c.OpenTime}
+	if _, staged := k.pending[key]; !staged && len(k.pending) >= k.maxPending {
+		if k.dropped == 0 {
+			k.flush(ctx)
+		}
+		if len(k.pending) >= k.maxPending {
+			if k.dropped == 0 {
+				logx.WithContext(ctx).Errorf("kline ingest: pending full provider=%s pending=%d; dropping candles until the store recovers", k.provider, len(k.pending))
+			}
+			k.dropped++
+			return
+		}
+	}
+	open := time.UnixMilli(c.OpenTime).UTC()
+	k.pending[key] = &model.Klines{
+		SymbolId:         k.provider + "/" + sym,
+		ExchangeProvider: k.provider,
+		Symbol:           sym,
+		Interval:         iv.name,
+		OpenTime:         open,
+		CloseTime:        open.Add(iv.step - time.Millisecond),
+		OpenPrice:        c.Open,
+		HighPrice:        c.High,
+		LowPrice:         c.Low,
+		ClosePrice:       c.Close,
+		Volume:           sql.NullFloat64{Float64: c.Volume, Valid: true},
+		Detail:           fmt.Sprintf(`{"source":%q}`, source),
+	}
+}
+
+// flush writes staged candles in batches, keeping them for the next flush
+// when the store fails.
+func (k *KlineIngestor) flush(ctx context.Context) {
+	if len(k.pending) == 0 {
+		return
+	}
+	keys := make([]klineKey, 0, len(k.pending))
+	for key := range k.pending {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].open < keys[j].open })
+	for start := 0; start < len(keys); start += klineUpsertBatch {
+		end := min(start+klineUpsertBatch, len(keys))
+		rows := make([]*model.Klines, 0, end-start)
+		for _, key := range keys[start:end] {
+			rows = append(rows, k.pending[key])
+		}
+		if err := k.store.UpsertKlines(ctx, rows); err != nil {
+			logx.WithContext(ctx).Errorf("kline ingest: upsert provider=%s rows=%d pending=%d err=%v", k.provider, len(rows), len(k.pending), err)
+			return
+		}
+		for _, key := range keys[start:end] {
+			delete(k.pending, key)
+		}
+	}
+	if k.dropped > 0 {
+		logx.WithContext(ctx).Errorf("kline ingest: store recovered provider=%s after dropping %d candles; they are missing from the klines table", k.provider, k.dropped)
+		k.dropped = 0
+	}
+}
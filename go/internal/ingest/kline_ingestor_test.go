@@ -0,0 +1,285 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/internal/model"
+	marketpkg "nof0-api/pkg/market"
+)
+
+var klineT0 = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+// klineAt is the candle the fake exchange reports for an interval's bar.
+func klineAt(open time.Time, step time.Duration) marketpkg.Candle {
+	px := float64(open.Sub(klineT0) / step)
+	return marketpkg.Candle{OpenTime: open.UnixMilli(), Open: px, High: px + 1, Low: px - 1, Close: px + 0.5, Volume: 1}
+}
+
+type historyCall struct {
+	interval string
+	from, to time.Time
+}
+
+type fakeKlineFeed struct {
+	subs  []string
+	calls []historyCall
+}
+
+func (f *fakeKlineFeed) Subscribe(symbol, interval string) error {
+	f.subs = append(f.subs, symbol+"@"+interval)
+	return nil
+}
+
+func (f *fakeKlineFeed) Run(ctx context.Context, handle func(marketpkg.KlineUpdate)) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (f *fakeKlineFeed) History(ctx context.Context, symbol, interval string, from, to time.Time) ([]marketpkg.Candle, error) {
+	f.calls = append(f.calls, historyCall{interval: interval, from: from, to: to})
+	step, _ := time.ParseDuration(interval)
+	var out []marketpkg.Candle
+	for t := from; t.Before(to); t = t.Add(step) {
+		out = append(out, klineAt(t, step))
+	}
+	return out, nil
+}
+
+type memoryKlineStore struct {
+	rows    map[string]*model.Klines
+	fail    bool
+	upserts int
+}
+
+func (m *memoryKlineStore) UpsertKlines(ctx context.Context, rows []*model.Klines) error {
+	m.upserts++
+	if m.fail {
+		return errors.New("database is down")
+	}
+	for _, r := range rows {
+		m.rows[r.Interval+" "+r.OpenTime.Format("15:04")] = r
+	}
+	return nil
+}
+
+func (m *memoryKlineStore) LatestKlineOpenTime(ctx context.Context, provider, symbol, interval string) (time.Time, bool, error) {
+	var latest time.Time
+	for _, r := range m.rows {
+		if r.Interval == interval && r.OpenTime.After(latest) {
+			latest = r.OpenTime
+		}
+	}
+	return latest, !latest.IsZero(), nil
+}
+
+func newTestKlineIngestor(t *testing.T, now time.Time) (*KlineIngestor, *fakeKlineFeed, *memoryKlineStore) {
+	t.Helper()
+	feed := &fakeKlineFeed{}
+	store := &memoryKlineStore{rows: make(map[string]*model.Klines)}
+	k, err := NewKlineIngestor(feed, store, KlineIngestorConfig{
+		Provider:  "hyperliquid",
+		Symbols:   []string{"btc"},
+		Intervals: []string{"3m", "1m"},
+		Backfill:  time.Hour,
+	})
+	require.NoError(t, err)
+	k.now = func() time.Time { return now }
+	k.series["BTC"] = &klineSeries{buckets: make(map[string]*klineBucket)}
+	return k, feed, store
+}
+
+func TestNewKlineIngestorValidatesIntervals(t *testing.T) {
+	_, err := NewKlineIngestor(&fakeKlineFeed{}, &memoryKlineStore{}, KlineIngestorConfig{Provider: "hl", Symbols: []string{"BTC"}, Intervals: []string{"2m", "3m"}})
+	assert.ErrorContains(t, err, "not a multiple")
+	_, err = NewKlineIngestor(&fakeKlineFeed{}, &memoryKlineStore{}, KlineIngestorConfig{Provider: "hl"})
+	assert.Error(t, err)
+}
+
+func TestKlineIngestorBackfillsFromLatestStored(t *testing.T) {
+	k, feed, store := newTestKlineIngestor(t, klineT0.Add(7*time.Minute+30*time.Second))
+	store.rows["1m 09:50"] = &model.Klines{Interval: "1m", OpenTime: klineT0.Add(-10 * time.Minute)}
+
+	k.backfillAll(context.Background())
+	k.flush(context.Background())
+
+	assert.Equal(t, []historyCall{
+		{interval: "1m", from: klineT0.Add(-9 * time.Minute), to: klineT0.Add(7 * time.Minute)},
+		{interval: "3m", from: klineT0.Add(-54 * time.Minute), to: klineT0.Add(6 * time.Minute)},
+	}, feed.calls, "1m resumes after the stored candle; 3m starts Backfill ago; both stop at the open bar")
+	assert.Len(t, store.rows, 1+16+20)
+	assert.Equal(t, klineT0.Add(6*time.Minute).UnixMilli(), k.series["BTC"].lastClosed)
+	row := store.rows["1m 10:06"]
+	require.NotNil(t, row)
+	assert.Equal(t, "hyperliquid/BTC", row.SymbolId)
+	assert.Equal(t, klineT0.Add(7*time.Minute-time.Millisecond), row.CloseTime)
+}
+
+func TestKlineIngestorAggregatesAndFillsGaps(t *testing.T) {
+	k, feed, store := newTestKlineIngestor(t, klineT0.Add(6*time.Minute+20*time.Second))
+	ctx := context.Background()
+	s := k.series["BTC"]
+	s.lastClosed = klineT0.UnixMilli()
+	minute := func(i int) marketpkg.Candle { return klineAt(klineT0.Add(time.Duration(i)*time.Minute), time.Minute) }
+	update := func(c marketpkg.Candle, closed bool) {
+		k.handle(ctx, marketpkg.KlineUpdate{Symbol: "BTC", Interval: "1m", Candle: c, Closed: closed})
+	}
+
+	partial := minute(1)
+	partial.Close = 0
+	update(partial, false)
+	update(minute(1), false)
+	update(minute(2), true) // closes 10:01 (superseded) and 10:02 (final)
+	update(minute(5), false)
+	k.closeStale(ctx) // 10:05 closed over 10s ago with no successor
+	k.flush(ctx)
+
+	assert.Equal(t, []historyCall{
+		{interval: "3m", from: klineT0, to: klineT0.Add(3 * time.Minute)},
+		{interval: "1m", from: klineT0.Add(3 * time.Minute), to: klineT0.Add(5 * time.Minute)},
+	}, feed.calls, "the 10:00 3m bar missed its first minute; 10:03-10:04 were never streamed")
+
+	for _, key := range []string{"1m 10:01", "1m 10:02", "1m 10:03", "1m 10:04", "1m 10:05", "3m 10:00", "3m 10:03"} {
+		assert.Contains(t, store.rows, key)
+	}
+	assert.Equal(t, 1.5, store.rows["1m 10:01"].ClosePrice, "the final update wins")
+	agg := store.rows["3m 10:03"]
+	assert.Equal(t, `{"source":"aggregate"}`, agg.Detail)
+	assert.Equal(t, 3.0, agg.OpenPrice)
+	assert.Equal(t, 6.0, agg.HighPrice)
+	assert.Equal(t, 2.0, agg.LowPrice)
+	assert.Equal(t, 5.5, agg.ClosePrice)
+	assert.Equal(t, 3.0, agg.Volume.Float64)
+	assert.Equal(t, `{"source":"rest"}`, store.rows["3m 10:00"].Detail)
+}
+
+// recordingWindows records the candles appended per interval.
+type recordingWindows struct {
+	sizes    map[string]int
+	appended map[string][]int64
+}
+
+func (w *recordingWindows) WindowIntervals() map[string]int { return w.sizes }
+
+func (w *recordingWindows) AppendKline(symbol, interval string, c marketpkg.Candle) {
+	w.appended[symbol+" "+interval] = append(w.appended[symbol+" "+interval], c.OpenTime)
+}
+
+func TestKlineIngestorFeedsWindows(t *testing.T) {
+	k, feed, _ := newTestKlineIngestor(t, klineT0.Add(2*time.Minute+20*time.Second))
+	windows := &recordingWindows{sizes: map[string]int{"3m": 2, "4h": 5}, appended: map[string][]int64{}}
+	k.windows = windows
+	ctx := context.Background()
+	ms := func(d time.Duration) int64 { return klineT0.Add(d).UnixMilli() }
+
+	k.seedWindows(ctx)
+	assert.Equal(t, []historyCall{
+		{interval: "3m", from: klineT0.Add(-6 * time.Minute), to: klineT0},
+	}, feed.calls, "3m is seeded with its two newest closed bars; 4h is not ingested")
+	assert.Equal(t, []int64{ms(-6 * time.Minute), ms(-3 * time.Minute)}, windows.appended["BTC 3m"])
+
+	s := k.series["BTC"]
+	s.lastClosed = ms(time.Minute)
+	s.buckets["3m"] = &klineBucket{candle: klineAt(klineT0, 3*time.Minute), candles: 2}
+	k.handle(ctx, marketpkg.KlineUpdate{Symbol: "BTC", Interval: "1m", Candle: klineAt(klineT0.Add(2*time.Minute), time.Minute), Closed: true})
+
+	assert.Equal(t, []int64{ms(2 * time.Minute)}, windows.appended["BTC 1m"])
+	assert.Equal(t, []int64{ms(-6 * time.Minute), ms(-3 * time.Minute), ms(0)}, windows.appended["BTC 3m"], "the completed 3m bar rolls in")
+}
+
+// tradeFeed is a fakeKlineFeed with a trade stream.
+type tradeFeed struct {
+	fakeKlineFeed
+	tradeSubs []string
+	onTrade   func(marketpkg.TradeUpdate)
+}
+
+func (f *tradeFeed) SubscribeTrades(symbol string) error {
+	f.tradeSubs = append(f.tradeSubs, symbol)
+	return nil
+}
+
+func (f *tradeFeed) OnTrade(handle func(marketpkg.TradeUpdate)) { f.onTrade = handle }
+
+func TestKlineIngestorSubscribesTrades(t *testing.T) {
+	k, _, _ := newTestKlineIngestor(t, klineT0)
+	ctx := context.Background()
+	assert.Nil(t, k.subscribeTrades(ctx), "trades are off by default")
+	k.trades = true
+	assert.Nil(t, k.subscribeTrades(ctx), "the feed has no trade stream")
+
+	feed := &tradeFeed{}
+	k.feed = feed
+	trades := k.subscribeTrades(ctx)
+	require.NotNil(t, trades)
+	assert.Equal(t, []string{"BTC"}, feed.tradeSubs)
+	feed.onTrade(marketpkg.TradeUpdate{Symbol: "BTC", Price: 1})
+	assert.Equal(t, marketpkg.TradeUpdate{Symbol: "BTC", Price: 1}, <-trades)
+}
+
+func TestKlineIngestorTradesCloseTheOpenCandle(t *testing.T) {
+	k, feed, store := newTestKlineIngestor(t, klineT0.Add(2*time.Minute+40*time.Second))
+	ctx := context.Background()
+	s := k.series["BTC"]
+	s.lastClosed = klineT0.UnixMilli()
+	trade := func(at time.Duration, px, sz float64) {
+		k.handleTrade(ctx, marketpkg.TradeUpdate{Symbol: "btc", Price: px, Size: sz, Time: klineT0.Add(at).UnixMilli()})
+	}
+
+	trade(30*time.Second, 50, 1) // already closed
+	trade(65*time.Second, 10, 1)
+	trade(90*time.Second, 12, 2)
+	trade(110*time.Second, 9, 1)
+	trade(121*time.Second, 11, 1) // closes 10:01
+	k.handle(ctx, marketpkg.KlineUpdate{Symbol: "BTC", Interval: "1m", Candle: klineAt(klineT0.Add(2*time.Minute), time.Minute)})
+	trade(150*time.Second, 100, 5)
+	trade(100*time.Second, 1, 1) // older than the open candle
+	k.flush(ctx)
+
+	assert.Empty(t, feed.calls)
+	row := store.rows["1m 10:01"]
+	require.NotNil(t, row, "the first trade of 10:02 closes 10:01")
+	assert.Equal(t, []float64{10, 12, 9, 9, 4}, []float64{row.OpenPrice, row.HighPrice, row.LowPrice, row.ClosePrice, row.Volume.Float64})
+	assert.Equal(t, klineT0.Add(time.Minute).UnixMilli(), s.lastClosed)
+	require.NotNil(t, s.open)
+	assert.Equal(t, marketpkg.Candle{OpenTime: klineT0.Add(2 * time.Minute).UnixMilli(), Open: 2, High: 100, Low: 1, Close: 100, Volume: 1}, *s.open,
+		"trades move the feed's candle but leave its volume alone")
+}
+
+func TestKlineIngestorFlushesAndCountsAFullBacklog(t *testing.T) {
+	k, _, store := newTestKlineIngestor(t, klineT0.Add(time.Hour))
+	k.maxPending = 2
+	ctx := context.Background()
+	minute := func(i int) marketpkg.Candle { return klineAt(klineT0.Add(time.Duration(i)*time.Minute), time.Minute) }
+
+	store.fail = true
+	k.queue(ctx, "BTC", k.base, minute(0), "ws")
+	k.queue(ctx, "BTC", k.base, minute(1), "ws")
+	k.queue(ctx, "BTC", k.base, minute(2), "ws")
+	assert.Equal(t, 1, store.upserts, "a full backlog is flushed at once")
+	k.queue(ctx, "BTC", k.base, minute(3), "ws")
+	k.queue(ctx, "BTC", k.base, minute(1), "ws") // already staged, replaced
+	assert.Equal(t, 1, store.upserts, "no retry per candle while the store is down")
+	assert.Equal(t, 2, k.dropped)
+	assert.Len(t, k.pending, 2)
+
+	store.fail = false
+	k.flush(ctx)
+	assert.Zero(t, k.dropped, "the count resets once the store recovers")
+	assert.Len(t, store.rows, 2)
+
+	k.queue(ctx, "BTC", k.base, minute(4), "ws")
+	k.queue(ctx, "BTC", k.base, minute(5), "ws")
+	k.queue(ctx, "BTC", k.base, minute(6), "ws")
+	k.flush(ctx)
+	assert.Zero(t, k.dropped, "the synchronous flush made room")
+	for _, key := range []string{"1m 10:00", "1m 10:01", "1m 10:04", "1m 10:05", "1m 10:06"} {
+		assert.Contains(t, store.rows, key)
+	}
+	assert.NotContains(t, store.rows, "1m 10:02")
+}
@@ -132,7 +132,12 @@ func (m *MarketIngestor) refreshSnapshots(ctx context.Context) {
 				return
 			}
 			reqCtx, cancel := context.WithTimeout(ctx, defaultSnapshotTimeout)
-			if _, err := prov.Snapshot(reqCtx, symbol); err != nil && reqCtx.Err() == nil {
+			fetch := prov.Snapshot
+			if refresher, ok := prov.(marketpkg.Refresher); ok {
+				// Bypass read caches so rolling windows are updated every tick.
+				fetch = refresher.Refresh
+			}
+			if _, err := fetch(reqCtx, symbol); err != nil && reqCtx.Err() == nil {
 				logx.WithContext(ctx).Errorf("market ingest: snapshot provider=%s symbol=%s err=%v", name, symbol, err)
 			}
 			cancel()
@@ -0,0 +1,273 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/internal/model"
+	marketpkg "nof0-api/pkg/market"
+)
+
+// MetricsStore persists market metrics. marketpersist.SeriesStore implements it.
+type MetricsStore interface {
+	WriteMetrics(ctx context.Context, rows []*model.MarketMetrics) error
+	QueryMetrics(ctx context.Context, provider, symbol string, since time.Time) ([]*model.MarketMetrics, error)
+}
+
+// MetricsCollectorConfig configures a MetricsCollector.
+type MetricsCollectorConfig struct {
+	// Provider is the exchange_provider rows are stored under.
+	Provider string
+	Symbols  []string
+	// Interval is how often metrics are collected; defaults to 1m.
+	Interval time.Duration
+	// AverageWindow is the span rolling averages cover; defaults to 24h.
+	AverageWindow time.Duration
+}
+
+const (
+	defaultMetricsInterval      = time.Minute
+	defaultMetricsAverageWindow = 24 * time.Hour
+	metricsFetchTimeout         = 20 * time.Second
+)
+
+type metricSample struct {
+	at           time.Time
+	openInterest float64
+	dayVolume    float64
+	fundingRate  float64
+}
+
+// MetricsCollector periodically records funding, open interest and 24h volume
+// per symbol into market_metrics and keeps rolling averages over the recent
+// readings. It implements market.MetricsAverager so providers can report
+// OpenInterest.Average and Volume.Average from them. On the first reading
+// of a symbol the window is seeded from the store, so averages survive
+// restarts.
+type MetricsCollector struct {
+	provider string
+	source   marketpkg.MetricsProvider
+	store    MetricsStore
+	symbols  []string
+	interval time.Duration
+	window   time.Duration
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	samples map[string][]metricSample // uppercased symbol -> oldest first
+	seeded  map[string]bool
+}
+
+var _ marketpkg.MetricsAverager = (*MetricsCollector)(nil)
+
+// NewMetricsCollector validates cfg and builds a collector reading source.
+// store may be nil to keep averages in memory only.
+func NewMetricsCollector(source marketpkg.MetricsProvider, store MetricsStore, cfg MetricsCollectorConfig) (*MetricsCollector, error) {
+	if source == nil {
+		return nil, fmt.Errorf("metrics collector: source is required")
+	}
+	provider := strings.TrimSpace(cfg.Provider)
+	if provider == "" {
+		return nil, fmt.Errorf("metrics collector: provider is required")
+	}
+	symbols := make([]string, 0, len(cfg.Symbols))
+	seen := make(map[string]bool, len(cfg.Symbols))
+	for _, sym := range cfg.Symbols {
+		sym = strings.ToUpper(strings.TrimSpace(sym))
+		if sym == "" || seen[sym] {
+			continue
+		}
+		seen[sym] = true
+		symbols = append(symbols, sym)
+	}
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("metrics collector: at least one symbol is required")
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultMetricsInterval
+	}
+	window := cfg.AverageWindow
+	if window <= 0 {
+		window = defaultMetricsAverageWindow
+	}
+	if window < interval {
+		return nil, fmt.Errorf("metrics collector: average window %s is shorter than interval %s", window, interval)
+	}
+	return &MetricsCollector{
+		provider: provider,
+		source:   source,
+		store:    store,
+		symbols:  symbols,
+		interval: interval,
+		window:   window,
+		now:      time.Now,
+		samples:  make(map[string][]metricSample, len(symbols)),
+		seeded:   make(map[string]bool, len(symbols)),
+	}, nil
+}
+
+// Run collects immediately and then every Interval until ctx is cancelled.
+func (c *MetricsCollector) Run(ctx context.Context) {
+	c.collect(ctx)
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.collect(ctx)
+		}
+	}
+}
+
+// MetricAverages implements market.MetricsAverager.
+func (c *MetricsCollector) MetricAverages(symbol string) (marketpkg.MetricAverages, bool) {
+	key := strings.ToUpper(strings.TrimSpace(symbol))
+	cutoff := c.now().Add(-c.window)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var avg marketpkg.MetricAverages
+	for _, s := range c.samples[key] {
+		if s.at.Before(cutoff) {
+			continue
+		}
+		avg.OpenInterest += s.openInterest
+		avg.DayVolume += s.dayVolume
+		avg.FundingRate += s.fundingRate
+		avg.Samples++
+	}
+	if avg.Samples == 0 {
+		return marketpkg.MetricAverages{}, false
+	}
+	n := float64(avg.Samples)
+	avg.OpenInterest /= n
+	avg.DayVolume /= n
+	avg.FundingRate /= n
+	return avg, true
+}
+
+func (c *MetricsCollector) collect(ctx context.Context) {
+	fetchCtx, cancel := context.WithTimeout(ctx, metricsFetchTimeout)
+	defer cancel()
+	readings, err := c.source.Metrics(fetchCtx, c.symbols...)
+	if err != nil {
+		if ctx.Err() == nil {
+			logx.WithContext(ctx).Errorf("metrics collector: fetch provider=%s err=%v", c.provider, err)
+		}
+		return
+	}
+	// Readings of one tick share an event time aligned to the interval, so
+	// a restart within the same interval does not store a second row.
+	eventAt := c.now().UTC().Truncate(c.interval)
+	rows := make([]*model.MarketMetrics, 0, len(readings))
+	found := make(map[string]bool, len(readings))
+	for _, m := range readings {
+		key := strings.ToUpper(m.Symbol)
+		found[key] = true
+		c.seed(fetchCtx, m.Symbol)
+		c.record(key, metricSample{at: eventAt, openInterest: m.OpenInterest, dayVolume: m.DayVolume, fundingRate: m.FundingRate})
+		rows = append(rows, c.row(m, eventAt))
+	}
+	for _, sym := range c.symbols {
+		if !found[sym] {
+			logx.WithContext(ctx).Slowf("metrics collector: provider=%s returned no metrics for %s", c.provider, sym)
+		}
+	}
+	if c.store == nil || len(rows) == 0 {
+		return
+	}
+	if err := c.store.WriteMetrics(fetchCtx, rows); err != nil {
+		logx.WithContext(ctx).Errorf("metrics collector: store provider=%s rows=%d err=%v", c.provider, len(rows), err)
+	}
+}
+
+// seed loads the stored readings of symbol inside the average window once.
+func (c *MetricsCollector) seed(ctx context.Context, symbol string) {
+	key := strings.ToUpper(symbol)
+	c.mu.RLock()
+	done := c.seeded[key]
+	c.mu.RUnlock()
+	if done || c.store == nil {
+		return
+	}
+	rows, err := c.store.QueryMetrics(ctx, c.provider, symbol, c.now().Add(-c.window))
+	if err != nil {
+		// Retried on the next tick; averages start from live readings meanwhile.
+		logx.WithContext(ctx).Errorf("metrics collector: seed provider=%s symbol=%s err=%v", c.provider, symbol, err)
+		return
+	}
+	stored := make([]metricSample, 0, len(rows))
+	for _, r := range rows {
+		stored = append(stored, metricSample{
+			at:           r.EventAt,
+			openInterest: r.OpenInterest.Float64,
+			dayVolume:    r.DayVolume.Float64,
+			fundingRate:  r.FundingRate.Float64,
+		})
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seeded[key] = true
+	if len(stored) == 0 {
+		return
+	}
+	// Keep live readings newer than the newest stored one.
+	newest := stored[len(stored)-1].at
+	for _, s := range c.samples[key] {
+		if s.at.After(newest) {
+			stored = append(stored, s)
+		}
+	}
+	c.samples[key] = stored
+}
+
+func (c *MetricsCollector) record(key string, s metricSample) {
+	cutoff := s.at.Add(-c.window)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	kept := c.samples[key]
+	if n := len(kept); n > 0 && !kept[n-1].at.Before(s.at) {
+		return
+	}
+	drop := 0
+	for drop < len(kept) && kept[drop].at.Before(cutoff) {
+		drop++
+	}
+	c.samples[key] = append(kept[drop:], s)
+}
+
+func (c *MetricsCollector) row(m marketpkg.Metrics, eventAt time.Time) *model.MarketMetrics {
+	var change24h sql.NullFloat64
+	if m.PrevDayPrice > 0 && m.MarkPrice > 0 {
+		change24h = validFloat(m.MarkPrice/m.PrevDayPrice - 1)
+	}
+	return &model.MarketMetrics{
+		SymbolId:          c.provider + "/" + m.Symbol,
+		ExchangeProvider:  c.provider,
+		Symbol:            m.Symbol,
+		MarkPrice:         validFloat(m.MarkPrice),
+		MidPrice:          validFloat(m.MidPrice),
+		OraclePrice:       validFloat(m.OraclePrice),
+		FundingRate:       validFloat(m.FundingRate),
+		OpenInterest:      validFloat(m.OpenInterest),
+		DayVolume:         validFloat(m.DayVolume),
+		DayNotionalVolume: validFloat(m.DayNotionalVolume),
+		Change24h:         change24h,
+		Premium:           validFloat(m.Premium),
+		PrevDayPrice:      validFloat(m.PrevDayPrice),
+		Detail:            "{}",
+		EventAt:           eventAt,
+	}
+}
+
+func validFloat(v float64) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: v, Valid: true}
+}
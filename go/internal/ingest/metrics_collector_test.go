@@ -0,0 +1,110 @@
+package ingest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/internal/model"
+	marketpkg "nof0-api/pkg/market"
+)
+
+type fakeMetricsSource struct {
+	oi    float64
+	calls [][]string
+}
+
+func (f *fakeMetricsSource) Metrics(ctx context.Context, symbols ...string) ([]marketpkg.Metrics, error) {
+	f.calls = append(f.calls, symbols)
+	// Only BTC is listed; the exchange spells it in its own case.
+	return []marketpkg.Metrics{{
+		Symbol:       "BTC",
+		MarkPrice:    110,
+		PrevDayPrice: 100,
+		FundingRate:  0.0001,
+		OpenInterest: f.oi,
+		DayVolume:    2 * f.oi,
+	}}, nil
+}
+
+type memoryMetricsStore struct {
+	rows     []*model.MarketMetrics
+	listings int
+}
+
+func (m *memoryMetricsStore) WriteMetrics(ctx context.Context, rows []*model.MarketMetrics) error {
+	m.rows = append(m.rows, rows...)
+	return nil
+}
+
+func (m *memoryMetricsStore) QueryMetrics(ctx context.Context, provider, symbol string, since time.Time) ([]*model.MarketMetrics, error) {
+	m.listings++
+	var out []*model.MarketMetrics
+	for _, r := range m.rows {
+		if r.ExchangeProvider == provider && r.Symbol == symbol && !r.EventAt.Before(since) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func TestNewMetricsCollectorValidates(t *testing.T) {
+	_, err := NewMetricsCollector(&fakeMetricsSource{}, nil, MetricsCollectorConfig{Provider: "hl"})
+	assert.Error(t, err)
+	_, err = NewMetricsCollector(&fakeMetricsSource{}, nil, MetricsCollectorConfig{Provider: "hl", Symbols: []string{"BTC"}, Interval: time.Hour, AverageWindow: time.Minute})
+	assert.ErrorContains(t, err, "shorter than interval")
+}
+
+func TestMetricsCollectorStoresAndAverages(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	store := &memoryMetricsStore{}
+	// An hour-old reading from before a restart seeds the window.
+	store.rows = append(store.rows, &model.MarketMetrics{
+		ExchangeProvider: "hyperliquid",
+		Symbol:           "BTC",
+		OpenInterest:     sql.NullFloat64{Float64: 400, Valid: true},
+		DayVolume:        sql.NullFloat64{Float64: 800, Valid: true},
+		EventAt:          t0.Add(-time.Hour),
+	})
+	source := &fakeMetricsSource{oi: 100}
+	c, err := NewMetricsCollector(source, store, MetricsCollectorConfig{
+		Provider:      "hyperliquid",
+		Symbols:       []string{"btc", "eth", "BTC"},
+		AverageWindow: 2 * time.Hour,
+	})
+	require.NoError(t, err)
+	now := t0.Add(30 * time.Second)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	c.collect(ctx)
+	c.collect(ctx) // same interval: neither stored nor counted twice
+	now = t0.Add(time.Minute + 10*time.Second)
+	source.oi = 200
+	c.collect(ctx)
+
+	assert.Equal(t, [][]string{{"BTC", "ETH"}, {"BTC", "ETH"}, {"BTC", "ETH"}}, source.calls)
+	assert.Equal(t, 1, store.listings, "the window is seeded once")
+	require.Len(t, store.rows, 4, "WriteMetrics skips duplicates in SQL, the memory store does not")
+	row := store.rows[1]
+	assert.Equal(t, "hyperliquid/BTC", row.SymbolId)
+	assert.Equal(t, t0, row.EventAt)
+	assert.InDelta(t, 0.1, row.Change24h.Float64, 1e-9)
+
+	avg, ok := c.MetricAverages("btc")
+	require.True(t, ok)
+	assert.Equal(t, marketpkg.MetricAverages{OpenInterest: 700.0 / 3, DayVolume: 1400.0 / 3, FundingRate: 0.0002 / 3, Samples: 3}, avg)
+	_, ok = c.MetricAverages("ETH")
+	assert.False(t, ok)
+
+	// Once the seeded reading leaves the window only live readings count.
+	now = t0.Add(time.Hour + 30*time.Second)
+	avg, ok = c.MetricAverages("BTC")
+	require.True(t, ok)
+	assert.Equal(t, 2, avg.Samples)
+	assert.Equal(t, 150.0, avg.OpenInterest)
+}
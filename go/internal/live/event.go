@@ -0,0 +1,62 @@
+// Package live fans decision, position and account updates published by the
+// trading engine out to dashboard connections. The engine appends events to a
+// Redis stream; each API process tails it once and shares the result between
+// all of its connections.
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/redis"
+
+	cachekeys "nof0-api/internal/cache"
+	"nof0-api/internal/redisstream"
+)
+
+// Topics a connection can subscribe to.
+const (
+	TopicDecisions = "decisions"
+	TopicPositions = "positions"
+	TopicAccount   = "account"
+)
+
+// Topics lists every topic; connections that name none receive all of them.
+var Topics = []string{TopicDecisions, TopicPositions, TopicAccount}
+
+// ValidTopic reports whether topic is one of Topics.
+func ValidTopic(topic string) bool {
+	for _, t := range Topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is one state change. ID is the stream entry id, assigned on publish.
+type Event struct {
+	ID       string          `json:"id,omitempty"`
+	Topic    string          `json:"topic"`
+	TraderID string          `json:"trader_id"`
+	Data     json.RawMessage `json:"data"`
+	At       time.Time       `json:"at"`
+}
+
+// Publish appends an event carrying payload to the live stream.
+func Publish(ctx context.Context, rds *redis.Redis, topic, traderID string, payload any) error {
+	if rds == nil || strings.TrimSpace(traderID) == "" {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(Event{Topic: topic, TraderID: traderID, Data: data, At: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	return redisstream.Append(ctx, rds, cachekeys.LiveEventStreamKey(), cachekeys.LiveEventMaxLen, string(body))
+}
@@ -0,0 +1,235 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	hubPollInterval = time.Second
+	hubReadBatch    = 200
+	// subscriptionBuffer is how many events a connection may fall behind
+	// before it is dropped.
+	subscriptionBuffer = 64
+)
+
+// ErrTooManyClients is returned by Subscribe once the hub is full.
+var ErrTooManyClients = errors.New("live: too many clients")
+
+// Hub tails a Source while at least one subscription is open and delivers
+// each event to the subscriptions that want it. A subscription that cannot
+// keep up is closed rather than allowed to stall the others.
+type Hub struct {
+	source     Source
+	maxClients int
+	poll       time.Duration
+
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	cancel context.CancelFunc
+}
+
+// NewHub returns a hub over source. maxClients <= 0 means unlimited.
+func NewHub(source Source, maxClients int) *Hub {
+	return &Hub{
+		source:     source,
+		maxClients: maxClients,
+		poll:       hubPollInterval,
+		subs:       make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe opens a subscription to topics, all of them when empty, limited
+// to modelID unless it is empty. Only events published afterwards are
+// delivered.
+func (h *Hub) Subscribe(modelID string, topics []string) (*Subscription, error) {
+	sub := &Subscription{
+		hub:     h,
+		modelID: strings.ToLower(strings.TrimSpace(modelID)),
+		ch:      make(chan Event, subscriptionBuffer),
+		topics:  make(map[string]bool),
+	}
+	if _, err := sub.Subscribe(topics...); err != nil {
+		return nil, err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.maxClients > 0 && len(h.subs) >= h.maxClients {
+		return nil, ErrTooManyClients
+	}
+	h.subs[sub] = struct{}{}
+	if h.cancel == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		h.cancel = cancel
+		go h.run(ctx)
+	}
+	return sub, nil
+}
+
+// Clients returns the number of open subscriptions.
+func (h *Hub) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	close(sub.ch)
+	if len(h.subs) == 0 && h.cancel != nil {
+		h.cancel()
+		h.cancel = nil
+	}
+}
+
+// run starts at the stream's current tail and polls for newer events until
+// ctx is cancelled by the last subscription closing.
+func (h *Hub) run(ctx context.Context) {
+	ticker := time.NewTicker(h.poll)
+	defer ticker.Stop()
+	last := ""
+	for {
+		if last == "" {
+			id, err := h.source.Tail(ctx)
+			if err == nil {
+				last = id
+			} else if ctx.Err() == nil {
+				logx.Errorf("live: read stream tail: %v", err)
+			}
+		} else {
+			events, err := h.source.Read(ctx, last, hubReadBatch)
+			if err != nil && ctx.Err() == nil {
+				logx.Errorf("live: read stream after %s: %v", last, err)
+			}
+			for _, ev := range events {
+				last = ev.ID
+				h.dispatch(ev)
+			}
+			if len(events) == hubReadBatch {
+				continue
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Hub) dispatch(ev Event) {
+	h.mu.Lock()
+	var slow []*Subscription
+	for sub := range h.subs {
+		if !sub.wants(ev) {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	h.mu.Unlock()
+	for _, sub := range slow {
+		logx.Infof("live: dropping slow subscriber model=%q", sub.modelID)
+		sub.Close()
+	}
+}
+
+// Subscription receives the events of its topics until closed.
+type Subscription struct {
+	hub     *Hub
+	modelID string
+	ch      chan Event
+
+	mu     sync.Mutex
+	topics map[string]bool
+}
+
+// Events delivers matching events; it is closed when the subscription is,
+// including when the hub drops it for falling behind.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Subscribe adds topics, all of them when none are given, and returns the
+// resulting set.
+func (s *Subscription) Subscribe(topics ...string) ([]string, error) {
+	if len(topics) == 0 {
+		topics = Topics
+	}
+	if err := validateTopics(topics); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range topics {
+		s.topics[t] = true
+	}
+	return s.topicsLocked(), nil
+}
+
+// Unsubscribe removes topics and returns the remaining set.
+func (s *Subscription) Unsubscribe(topics ...string) ([]string, error) {
+	if err := validateTopics(topics); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range topics {
+		delete(s.topics, t)
+	}
+	return s.topicsLocked(), nil
+}
+
+// Topics returns the subscribed topics.
+func (s *Subscription) Topics() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.topicsLocked()
+}
+
+// Close ends the subscription; it is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.remove(s)
+}
+
+func (s *Subscription) wants(ev Event) bool {
+	if s.modelID != "" && strings.ToLower(ev.TraderID) != s.modelID {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.topics[ev.Topic]
+}
+
+func (s *Subscription) topicsLocked() []string {
+	out := make([]string, 0, len(s.topics))
+	for t := range s.topics {
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func validateTopics(topics []string) error {
+	for _, t := range topics {
+		if !ValidTopic(t) {
+			return fmt.Errorf("live: unknown topic %q (want %s)", t, strings.Join(Topics, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySource is an in-memory stream with ids "0-1", "0-2", ...
+type memorySource struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (m *memorySource) publish(topic, traderID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, Event{ID: fmt.Sprintf("0-%d", len(m.events)+1), Topic: topic, TraderID: traderID})
+}
+
+func (m *memorySource) Tail(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fmt.Sprintf("0-%d", len(m.events)), nil
+}
+
+func (m *memorySource) Read(ctx context.Context, after string, count int) ([]Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int
+	fmt.Sscanf(after, "0-%d", &n)
+	out := append([]Event(nil), m.events[n:]...)
+	if len(out) > count {
+		out = out[:count]
+	}
+	return out, nil
+}
+
+func newTestHub(src Source, maxClients int) *Hub {
+	h := NewHub(src, maxClients)
+	h.poll = 5 * time.Millisecond
+	return h
+}
+
+func receive(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case ev, ok := <-sub.Events():
+		require.True(t, ok, "subscription closed")
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("no event delivered")
+		return Event{}
+	}
+}
+
+func TestHubFiltersByTopicAndModel(t *testing.T) {
+	src := &memorySource{}
+	src.publish(TopicDecisions, "old") // before subscribing: never delivered
+	hub := newTestHub(src, 0)
+
+	all, err := hub.Subscribe("", nil)
+	require.NoError(t, err)
+	defer all.Close()
+	account, err := hub.Subscribe("GPT-5", []string{TopicAccount})
+	require.NoError(t, err)
+	defer account.Close()
+	time.Sleep(20 * time.Millisecond) // let the hub read the tail
+
+	src.publish(TopicPositions, "claude")
+	src.publish(TopicAccount, "claude")
+	src.publish(TopicAccount, "gpt-5")
+
+	assert.Equal(t, "0-2", receive(t, all).ID)
+	assert.Equal(t, "0-3", receive(t, all).ID)
+	assert.Equal(t, "0-4", receive(t, all).ID)
+	assert.Equal(t, "0-4", receive(t, account).ID)
+
+	topics, err := all.Unsubscribe(TopicPositions)
+	require.NoError(t, err)
+	assert.Equal(t, []string{TopicAccount, TopicDecisions}, topics)
+	src.publish(TopicPositions, "claude")
+	src.publish(TopicDecisions, "claude")
+	assert.Equal(t, "0-6", receive(t, all).ID)
+
+	_, err = all.Subscribe("trades")
+	assert.Error(t, err)
+}
+
+func TestHubLimitsAndDropsSlowClients(t *testing.T) {
+	src := &memorySource{}
+	hub := newTestHub(src, 1)
+	sub, err := hub.Subscribe("", nil)
+	require.NoError(t, err)
+	_, err = hub.Subscribe("", nil)
+	assert.ErrorIs(t, err, ErrTooManyClients)
+	time.Sleep(20 * time.Millisecond)
+
+	for i := 0; i <= subscriptionBuffer; i++ {
+		src.publish(TopicDecisions, "m")
+	}
+	require.Eventually(t, func() bool { return hub.Clients() == 0 }, time.Second, 5*time.Millisecond)
+	n := 0
+	for range sub.Events() {
+		n++
+	}
+	assert.Equal(t, subscriptionBuffer, n)
+	sub.Close() // already dropped; must not panic
+}
@@ -0,0 +1,64 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+
+	cachekeys "nof0-api/internal/cache"
+	"nof0-api/internal/redisstream"
+)
+
+// Source reads published events in order.
+type Source interface {
+	// Tail returns the id of the newest event, or "0-0" when there is none.
+	Tail(ctx context.Context) (string, error)
+	// Read returns up to count events after id, oldest first.
+	Read(ctx context.Context, after string, count int) ([]Event, error)
+}
+
+// RedisSource reads the stream written by Publish.
+type RedisSource struct {
+	rds *redis.Redis
+}
+
+// NewRedisSource returns a Source over rds.
+func NewRedisSource(rds *redis.Redis) *RedisSource {
+	return &RedisSource{rds: rds}
+}
+
+func (s *RedisSource) Tail(ctx context.Context) (string, error) {
+	entries, err := redisstream.Latest(ctx, s.rds, cachekeys.LiveEventStreamKey(), 1)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) > 0 {
+		return entries[0].ID, nil
+	}
+	return "0-0", nil
+}
+
+func (s *RedisSource) Read(ctx context.Context, after string, count int) ([]Event, error) {
+	entries, err := redisstream.After(ctx, s.rds, cachekeys.LiveEventStreamKey(), after, count)
+	if err != nil {
+		return nil, err
+	}
+	return decodeEvents(entries), nil
+}
+
+// decodeEvents decodes stream entries, skipping those that are not an Event.
+func decodeEvents(entries []redisstream.Entry) []Event {
+	out := make([]Event, 0, len(entries))
+	for _, e := range entries {
+		var ev Event
+		if err := json.Unmarshal([]byte(e.Data), &ev); err != nil {
+			logx.Errorf("live: decode entry %s: %v", e.ID, err)
+			continue
+		}
+		ev.ID = e.ID
+		out = append(out, ev)
+	}
+	return out
+}
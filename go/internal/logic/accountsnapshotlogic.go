@@ -0,0 +1,150 @@
+package logic
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"nof0-api/internal/model"
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type AccountSnapshotLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAccountSnapshotLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AccountSnapshotLogic {
+	return &AccountSnapshotLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AccountSnapshot returns equity snapshots newest first, optionally for one
+// model and time range. With Latest set it returns only the newest snapshot
+// of each model and ignores the range and cursor. It reads the equity
+// snapshots table when a database is configured and the data files otherwise.
+func (l *AccountSnapshotLogic) AccountSnapshot(req *types.AccountSnapshotRequest) (resp *types.AccountSnapshotResponse, err error) {
+	page, err := parsePageRequest(req.ModelId, req.From, req.To, req.Limit, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if req.Latest {
+		page.From, page.To, page.Cursor = 0, 0, nil
+	}
+	resp = &types.AccountSnapshotResponse{Snapshots: []types.AccountSnapshot{}, ServerTime: time.Now().UnixMilli()}
+	if l.svcCtx.AccountEquitySnapshotsModel != nil {
+		err = l.snapshotsFromModel(page, req.Latest, resp)
+		return resp, err
+	}
+	totals, err := l.svcCtx.DataLoader.LoadAccountTotals()
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]types.AccountSnapshot, 0, len(totals.AccountTotals))
+	for _, t := range totals.AccountTotals {
+		snapshots = append(snapshots, types.AccountSnapshot{
+			ModelId:            t.ModelId,
+			Timestamp:          secondsToMillis(t.Timestamp),
+			DollarEquity:       t.DollarEquity,
+			RealizedPnl:        t.RealizedPnl,
+			TotalUnrealizedPnl: t.TotalUnrealizedPnl,
+			CumPnlPct:          t.CumPnlPct,
+			SharpeRatio:        t.SharpeRatio,
+		})
+	}
+	sortSnapshots(snapshots)
+	if req.Latest {
+		resp.Snapshots = latestPerModel(snapshots, page.ModelID)
+		return resp, nil
+	}
+	resp.Snapshots, resp.NextCursor = pageInMemory(snapshots, page, func(s types.AccountSnapshot) (string, int64, string) {
+		return s.ModelId, s.Timestamp, s.ModelId
+	})
+	return resp, nil
+}
+
+func (l *AccountSnapshotLogic) snapshotsFromModel(page pageRequest, latest bool, resp *types.AccountSnapshotResponse) error {
+	if latest {
+		var ids []string
+		if page.ModelID != "" {
+			ids = []string{page.ModelID}
+		}
+		byModel, err := l.svcCtx.AccountEquitySnapshotsModel.LatestSnapshots(l.ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, s := range byModel {
+			resp.Snapshots = append(resp.Snapshots, snapshotFromModel(s))
+		}
+		sortSnapshots(resp.Snapshots)
+		return nil
+	}
+	q := model.AccountSnapshotPageQuery{
+		ModelID:  page.ModelID,
+		FromTsMs: page.From,
+		ToTsMs:   page.To,
+		// Fetch one extra row to learn whether an older page exists.
+		Limit: page.Limit + 1,
+	}
+	if page.Cursor != nil {
+		q.BeforeTsMs, q.BeforeModelID = page.Cursor.Ts, page.Cursor.ID
+	}
+	rows, err := l.svcCtx.AccountEquitySnapshotsModel.History(l.ctx, q)
+	if err != nil {
+		return err
+	}
+	if len(rows) > page.Limit {
+		rows = rows[:page.Limit]
+		last := rows[len(rows)-1]
+		resp.NextCursor = encodePageCursor(pageCursor{Ts: last.TimestampMs, ID: last.ModelID})
+	}
+	for _, s := range rows {
+		resp.Snapshots = append(resp.Snapshots, snapshotFromModel(s))
+	}
+	return nil
+}
+
+func snapshotFromModel(s model.AccountSnapshot) types.AccountSnapshot {
+	return types.AccountSnapshot{
+		ModelId:            s.ModelID,
+		Timestamp:          s.TimestampMs,
+		DollarEquity:       s.DollarEquity,
+		RealizedPnl:        s.RealizedPnl,
+		TotalUnrealizedPnl: s.TotalUnrealizedPnl,
+		CumPnlPct:          floatValue(s.CumPnlPct),
+		SharpeRatio:        floatValue(s.SharpeRatio),
+	}
+}
+
+// sortSnapshots orders snapshots newest first, then by model id descending,
+// matching the keyset order of the history query.
+func sortSnapshots(snapshots []types.AccountSnapshot) {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if snapshots[i].Timestamp != snapshots[j].Timestamp {
+			return snapshots[i].Timestamp > snapshots[j].Timestamp
+		}
+		return snapshots[i].ModelId > snapshots[j].ModelId
+	})
+}
+
+// latestPerModel keeps the first snapshot of each model from snapshots
+// sorted newest first.
+func latestPerModel(snapshots []types.AccountSnapshot, modelID string) []types.AccountSnapshot {
+	seen := map[string]bool{}
+	out := []types.AccountSnapshot{}
+	for _, s := range snapshots {
+		if seen[s.ModelId] || (modelID != "" && s.ModelId != modelID) {
+			continue
+		}
+		seen[s.ModelId] = true
+		out = append(out, s)
+	}
+	return out
+}
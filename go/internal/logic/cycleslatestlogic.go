@@ -0,0 +1,80 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	cachekeys "nof0-api/internal/cache"
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+type CyclesLatestLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCyclesLatestLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CyclesLatestLogic {
+	return &CyclesLatestLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// CyclesLatest returns the most recent cycle breakdown per model as published
+// by the trading engine. Without Redis there is nothing to read, so the list
+// is empty rather than an error.
+func (l *CyclesLatestLogic) CyclesLatest(req *types.CyclesLatestRequest) (resp *types.CyclesLatestResponse, err error) {
+	resp = &types.CyclesLatestResponse{
+		Cycles:     []types.CycleBreakdown{},
+		ServerTime: time.Now().UnixMilli(),
+	}
+	if l.svcCtx.Redis == nil {
+		return resp, nil
+	}
+	key := cachekeys.TraderCycleLatestHashKey()
+	var raw map[string]string
+	if req != nil && strings.TrimSpace(req.ModelId) != "" {
+		field := cachekeys.TraderHashField(req.ModelId)
+		val, err := l.svcCtx.Redis.HgetCtx(l.ctx, key, field)
+		if errors.Is(err, redis.Nil) {
+			return resp, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		raw = map[string]string{field: val}
+	} else {
+		raw, err = l.svcCtx.Redis.HgetallCtx(l.ctx, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	resp.Cycles = decodeCycleBreakdowns(raw, l.Logger)
+	return resp, nil
+}
+
+// decodeCycleBreakdowns parses hash values, skipping malformed entries, and
+// orders the result by model id for stable output.
+func decodeCycleBreakdowns(raw map[string]string, logger logx.Logger) []types.CycleBreakdown {
+	out := make([]types.CycleBreakdown, 0, len(raw))
+	for field, val := range raw {
+		var cycle types.CycleBreakdown
+		if err := json.Unmarshal([]byte(val), &cycle); err != nil {
+			logger.Errorf("cycles latest: decode field=%s err=%v", field, err)
+			continue
+		}
+		out = append(out, cycle)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TraderId < out[j].TraderId })
+	return out
+}
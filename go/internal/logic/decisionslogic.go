@@ -0,0 +1,91 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"nof0-api/internal/model"
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DecisionsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDecisionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DecisionsLogic {
+	return &DecisionsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Decisions returns decision cycles newest first, optionally for one model
+// and execution-time range. The list is empty without a database, since the
+// data files hold no decision cycles.
+func (l *DecisionsLogic) Decisions(req *types.DecisionsRequest) (resp *types.DecisionsResponse, err error) {
+	page, err := parsePageRequest(req.ModelId, req.From, req.To, req.Limit, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	resp = &types.DecisionsResponse{Decisions: []types.DecisionCycle{}, ServerTime: time.Now().UnixMilli()}
+	if l.svcCtx.DecisionCyclesModel == nil {
+		return resp, nil
+	}
+	q := model.DecisionCyclePageQuery{
+		ModelID: page.ModelID,
+		// Fetch one extra row to learn whether an older page exists.
+		Limit: page.Limit + 1,
+	}
+	if page.From > 0 {
+		q.From = time.UnixMilli(page.From)
+	}
+	if page.To > 0 {
+		q.To = time.UnixMilli(page.To)
+	}
+	if page.Cursor != nil {
+		// Decision cursors carry microseconds so cycles within one
+		// millisecond are neither repeated nor skipped.
+		id, err := strconv.ParseInt(page.Cursor.ID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %w", err)
+		}
+		q.BeforeAt, q.BeforeID = time.UnixMicro(page.Cursor.Ts), id
+	}
+	rows, err := l.svcCtx.DecisionCyclesModel.Page(l.ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > page.Limit {
+		rows = rows[:page.Limit]
+		last := rows[len(rows)-1]
+		resp.NextCursor = encodePageCursor(pageCursor{Ts: last.ExecutedAt.UnixMicro(), ID: strconv.FormatInt(last.Id, 10)})
+	}
+	for _, row := range rows {
+		cycle := types.DecisionCycle{
+			Id:           row.Id,
+			ModelId:      row.ModelId,
+			CycleNumber:  row.CycleNumber.Int64,
+			Success:      row.Success,
+			PromptDigest: row.PromptDigest.String,
+			CotTrace:     row.CotTrace.String,
+			ErrorMessage: row.ErrorMessage.String,
+			ExecutedAt:   row.ExecutedAt.UnixMilli(),
+		}
+		if row.Decisions.Valid && row.Decisions.String != "" {
+			if err := json.Unmarshal([]byte(row.Decisions.String), &cycle.Decisions); err != nil {
+				l.Errorf("decisions: decode decisions id=%d err=%v", row.Id, err)
+			}
+		}
+		resp.Decisions = append(resp.Decisions, cycle)
+	}
+	return resp, nil
+}
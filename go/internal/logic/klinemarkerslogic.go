@@ -0,0 +1,223 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxKlineMarkers caps the markers of one response, keeping the newest.
+const maxKlineMarkers = 2000
+
+// Kline marker kinds.
+const (
+	markerDecision = "decision"
+	markerEntry    = "entry"
+	markerExit     = "exit"
+	markerStop     = "stop"
+)
+
+type KlineMarkersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewKlineMarkersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *KlineMarkersLogic {
+	return &KlineMarkersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// KlineMarkers returns a model's decisions, entries, exits and exchange-side
+// stops on one symbol in [From, To), oldest first, each with Time set to the
+// open time of the candle of Interval it falls in so charts can draw them on
+// the bars /api/klines serves. Holds are left out. It reads the decision
+// timeline when a database is configured and the entries and exits of the
+// trades data file otherwise.
+func (l *KlineMarkersLogic) KlineMarkers(req *types.KlineMarkersRequest) (*types.KlineMarkersResponse, error) {
+	modelID := strings.TrimSpace(req.ModelId)
+	if modelID == "" {
+		return nil, errors.New("modelId is required")
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if symbol == "" {
+		return nil, errors.New("symbol is required")
+	}
+	step, ok := intervalDuration(req.Interval)
+	if !ok {
+		return nil, fmt.Errorf("unknown interval %q", req.Interval)
+	}
+	page, err := parsePageRequest(modelID, req.From, req.To, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	var markers []types.KlineMarker
+	if l.svcCtx.DBConn != nil {
+		markers, err = l.markersFromTimeline(modelID, symbol, page)
+	} else {
+		markers, err = l.markersFromTrades(modelID, symbol, page)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(markers, func(i, j int) bool { return markers[i].At < markers[j].At })
+	if len(markers) > maxKlineMarkers {
+		markers = markers[len(markers)-maxKlineMarkers:]
+	}
+	for i := range markers {
+		markers[i].Time = alignToCandle(markers[i].At, step)
+	}
+	return &types.KlineMarkersResponse{
+		ModelId:    modelID,
+		Symbol:     symbol,
+		Interval:   strings.TrimSpace(req.Interval),
+		Markers:    append([]types.KlineMarker{}, markers...),
+		ServerTime: time.Now().UnixMilli(),
+	}, nil
+}
+
+func (l *KlineMarkersLogic) markersFromTimeline(modelID, symbol string, page pageRequest) ([]types.KlineMarker, error) {
+	from, to := time.UnixMilli(0), timelineStart.At
+	if page.From > 0 {
+		from = time.UnixMilli(page.From)
+	}
+	if page.To > 0 {
+		to = time.UnixMilli(page.To)
+	}
+	const query = `SELECT id, trace_id, kind, symbol, summary, detail::text AS detail, occurred_at
+FROM public.decision_timeline
+WHERE trader_id = $1
+  AND symbol = $2
+  AND kind IN ('decision', 'fill', 'exit')
+  AND occurred_at >= $3
+  AND occurred_at < $4
+ORDER BY occurred_at DESC, id DESC
+LIMIT $5`
+	var rows []timelineRow
+	if err := l.svcCtx.DBConn.QueryRowsPartialCtx(l.ctx, &rows, query, modelID, symbol, from.UTC(), to.UTC(), maxKlineMarkers); err != nil {
+		return nil, err
+	}
+	markers := make([]types.KlineMarker, 0, len(rows))
+	for _, row := range rows {
+		var detail map[string]any
+		if err := json.Unmarshal([]byte(row.Detail), &detail); err != nil {
+			l.Errorf("kline markers: decode timeline detail id=%d err=%v", row.ID, err)
+		}
+		if m, ok := timelineMarker(row, detail); ok {
+			markers = append(markers, m)
+		}
+	}
+	return markers, nil
+}
+
+// timelineMarker maps a decision, fill or exit timeline entry to a marker.
+// Exits the manager found already closed on the exchange are stops.
+func timelineMarker(row timelineRow, detail map[string]any) (types.KlineMarker, bool) {
+	action, _ := detail["action"].(string)
+	m := types.KlineMarker{
+		At:      row.OccurredAt.UnixMilli(),
+		Action:  action,
+		Side:    actionSide(action),
+		Text:    row.Summary,
+		TraceId: row.TraceID,
+	}
+	switch row.Kind {
+	case "decision":
+		if action == "" || action == "hold" || action == "wait" {
+			return m, false
+		}
+		m.Kind = markerDecision
+	case "fill":
+		m.Kind = markerEntry
+		m.Price, m.Size = detailFloat(detail, "fill_price"), detailFloat(detail, "fill_size")
+	case "exit":
+		if reason, _ := detail["reason"].(string); reason == "exchange_close" {
+			m.Kind = markerStop
+			m.Side, _ = detail["side"].(string)
+			m.Size = detailFloat(detail, "quantity")
+			return m, true
+		}
+		m.Kind = markerExit
+		m.Price, m.Size = detailFloat(detail, "fill_price"), detailFloat(detail, "fill_size")
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+func (l *KlineMarkersLogic) markersFromTrades(modelID, symbol string, page pageRequest) ([]types.KlineMarker, error) {
+	resp, err := l.svcCtx.DataLoader.LoadTrades()
+	if err != nil {
+		return nil, err
+	}
+	var markers []types.KlineMarker
+	for _, t := range resp.Trades {
+		if t.ModelId != modelID || !strings.EqualFold(t.Symbol, symbol) {
+			continue
+		}
+		size := math.Abs(t.Quantity)
+		entry := types.KlineMarker{
+			At:     secondsToMillis(t.EntryTime),
+			Kind:   markerEntry,
+			Side:   t.Side,
+			Action: "open_" + t.Side,
+			Price:  t.EntryPrice,
+			Size:   size,
+			Text:   fmt.Sprintf("open_%s %s filled %.6f @ %.4f", t.Side, symbol, size, t.EntryPrice),
+		}
+		exit := types.KlineMarker{
+			At:     secondsToMillis(t.ExitTime),
+			Kind:   markerExit,
+			Side:   t.Side,
+			Action: "close_" + t.Side,
+			Price:  t.ExitPrice,
+			Size:   size,
+			Text:   fmt.Sprintf("close_%s %s closed %.6f @ %.4f", t.Side, symbol, size, t.ExitPrice),
+		}
+		for _, m := range []types.KlineMarker{entry, exit} {
+			if m.At > 0 && page.inRange(m.At) {
+				markers = append(markers, m)
+			}
+		}
+	}
+	return markers, nil
+}
+
+// alignToCandle is the open time of the candle of length step holding ms.
+func alignToCandle(ms int64, step time.Duration) int64 {
+	size := step.Milliseconds()
+	if size <= 0 {
+		return ms
+	}
+	return ms - ((ms%size)+size)%size
+}
+
+// actionSide is the position side an open or close action refers to.
+func actionSide(action string) string {
+	switch {
+	case strings.HasSuffix(action, "_long"):
+		return "long"
+	case strings.HasSuffix(action, "_short"):
+		return "short"
+	default:
+		return ""
+	}
+}
+
+func detailFloat(detail map[string]any, key string) float64 {
+	v, _ := detail[key].(float64)
+	return v
+}
@@ -0,0 +1,94 @@
+package logic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/internal/types"
+)
+
+func TestKlineMarkersFromTrades(t *testing.T) {
+	l := NewKlineMarkersLogic(context.Background(), createTestServiceContext(t))
+
+	all, err := l.KlineMarkers(&types.KlineMarkersRequest{ModelId: "gpt-5", Symbol: "doge", Interval: "3m"})
+	require.NoError(t, err)
+	assert.Equal(t, "DOGE", all.Symbol)
+	require.NotEmpty(t, all.Markers)
+	step := int64(3 * time.Minute / time.Millisecond)
+	for i, m := range all.Markers {
+		assert.Zero(t, m.Time%step, "aligned to a 3m candle open")
+		assert.True(t, m.Time <= m.At && m.At < m.Time+step)
+		if i > 0 {
+			assert.GreaterOrEqual(t, m.At, all.Markers[i-1].At, "oldest first")
+		}
+	}
+
+	// The first DOGE short opened at 1760747130.185 and closed at 1760748644.383.
+	ranged, err := l.KlineMarkers(&types.KlineMarkersRequest{ModelId: "gpt-5", Symbol: "DOGE", Interval: "1h", From: 1760747000000, To: 1760748700000})
+	require.NoError(t, err)
+	require.Len(t, ranged.Markers, 2)
+	entry, exit := ranged.Markers[0], ranged.Markers[1]
+	assert.Equal(t, markerEntry, entry.Kind)
+	assert.Equal(t, "short", entry.Side)
+	assert.Equal(t, int64(1760747130185), entry.At)
+	assert.Equal(t, int64(1760745600000), entry.Time)
+	assert.Greater(t, entry.Size, 0.0)
+	assert.Equal(t, markerExit, exit.Kind)
+	assert.Equal(t, "close_short", exit.Action)
+
+	_, err = l.KlineMarkers(&types.KlineMarkersRequest{ModelId: "gpt-5", Symbol: "DOGE", Interval: "3x"})
+	assert.ErrorContains(t, err, "unknown interval")
+	_, err = l.KlineMarkers(&types.KlineMarkersRequest{Symbol: "DOGE", Interval: "3m"})
+	assert.ErrorContains(t, err, "modelId is required")
+}
+
+func TestTimelineMarker(t *testing.T) {
+	at := time.UnixMilli(1735689700000)
+	cases := []struct {
+		name   string
+		row    timelineRow
+		detail map[string]any
+		want   types.KlineMarker
+		ok     bool
+	}{
+		{
+			name:   "hold decisions are skipped",
+			row:    timelineRow{Kind: "decision", OccurredAt: at},
+			detail: map[string]any{"action": "hold"},
+		},
+		{
+			name:   "fill is an entry",
+			row:    timelineRow{Kind: "fill", Summary: "filled", TraceID: "tr", OccurredAt: at},
+			detail: map[string]any{"action": "open_long", "fill_price": 100.5, "fill_size": 2.0},
+			want:   types.KlineMarker{At: at.UnixMilli(), Kind: markerEntry, Side: "long", Action: "open_long", Price: 100.5, Size: 2, Text: "filled", TraceId: "tr"},
+			ok:     true,
+		},
+		{
+			name:   "exchange close is a stop",
+			row:    timelineRow{Kind: "exit", Summary: "closed on exchange", OccurredAt: at},
+			detail: map[string]any{"side": "short", "quantity": 3.0, "reason": "exchange_close"},
+			want:   types.KlineMarker{At: at.UnixMilli(), Kind: markerStop, Side: "short", Size: 3, Text: "closed on exchange"},
+			ok:     true,
+		},
+		{
+			name:   "manager close is an exit",
+			row:    timelineRow{Kind: "exit", OccurredAt: at},
+			detail: map[string]any{"action": "close_short", "fill_price": 99.0, "fill_size": 1.0, "reason": "target reached"},
+			want:   types.KlineMarker{At: at.UnixMilli(), Kind: markerExit, Side: "short", Action: "close_short", Price: 99, Size: 1},
+			ok:     true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := timelineMarker(tc.row, tc.detail)
+			assert.Equal(t, tc.ok, ok)
+			if tc.ok {
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package logic
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"nof0-api/internal/model"
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// klinesMagic opens every binary klines body; the trailing digit is the
+// layout version.
+const klinesMagic = "NK01"
+
+// klineBinarySize is the encoded size of one candle: the open time and five
+// float64 fields.
+const klineBinarySize = 6 * 8
+
+// closedKlinesMaxAge is the Cache-Control max-age of ranges that end before
+// the open candle and therefore never change.
+const closedKlinesMaxAge = 24 * time.Hour
+
+type KlinesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewKlinesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *KlinesLogic {
+	return &KlinesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Klines returns the stored candles of one symbol and interval opening in
+// [From, To), oldest first, so charts draw the bars the models were shown.
+// At most Klines.MaxCandles are returned, the newest of the range. Without a
+// database the list is empty. The response carries no server time so its
+// body, and therefore its ETag, only changes with the candles.
+func (l *KlinesLogic) Klines(req *types.KlinesRequest) (*types.KlinesResponse, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if symbol == "" {
+		return nil, errors.New("symbol is required")
+	}
+	interval := strings.TrimSpace(req.Interval)
+	if interval == "" {
+		return nil, errors.New("interval is required")
+	}
+	page, err := parsePageRequest("", req.From, req.To, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	cfg := l.svcCtx.Config.Klines
+	provider := strings.TrimSpace(req.Provider)
+	if provider == "" {
+		provider = cfg.Provider
+	}
+	resp := &types.KlinesResponse{Symbol: symbol, Interval: interval, Provider: provider, Klines: []types.Kline{}}
+	if l.svcCtx.MarketSeries == nil {
+		return resp, nil
+	}
+	q := model.KlineRangeQuery{Provider: provider, Symbol: symbol, Interval: interval, Limit: cfg.MaxCandles}
+	if page.From > 0 {
+		q.From = time.UnixMilli(page.From)
+	}
+	if page.To > 0 {
+		q.To = time.UnixMilli(page.To)
+	}
+	rows, err := l.svcCtx.MarketSeries.QueryKlines(l.ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		resp.Klines = append(resp.Klines, types.Kline{
+			OpenTime: row.OpenTime.UnixMilli(),
+			Open:     row.OpenPrice,
+			High:     row.HighPrice,
+			Low:      row.LowPrice,
+			Close:    row.ClosePrice,
+			Volume:   row.Volume.Float64,
+		})
+	}
+	return resp, nil
+}
+
+// Encode serializes resp in the requested format and returns the body with
+// its content type.
+func (l *KlinesLogic) Encode(req *types.KlinesRequest, resp *types.KlinesResponse) ([]byte, string, error) {
+	if req.Format == "binary" {
+		return encodeKlinesBinary(resp.Klines), "application/octet-stream", nil
+	}
+	body, err := json.Marshal(resp)
+	return body, "application/json; charset=utf-8", err
+}
+
+// MaxAge is how long clients may cache the response to req: a day for
+// ranges ending at least one interval ago, Klines.MaxAge otherwise.
+func (l *KlinesLogic) MaxAge(req *types.KlinesRequest) time.Duration {
+	return klinesMaxAge(req, l.svcCtx.Config.Klines.MaxAge, time.Now())
+}
+
+func klinesMaxAge(req *types.KlinesRequest, openMaxAge time.Duration, now time.Time) time.Duration {
+	step, ok := intervalDuration(req.Interval)
+	if !ok || req.To <= 0 || time.UnixMilli(req.To).After(now.Add(-step)) {
+		return openMaxAge
+	}
+	return closedKlinesMaxAge
+}
+
+// intervalDuration parses candle intervals such as 1m, 4h and 1d.
+func intervalDuration(interval string) (time.Duration, bool) {
+	interval = strings.TrimSpace(interval)
+	if n, ok := strings.CutSuffix(interval, "d"); ok {
+		days, err := strconv.Atoi(n)
+		return time.Duration(days) * 24 * time.Hour, err == nil && days > 0
+	}
+	d, err := time.ParseDuration(interval)
+	return d, err == nil && d > 0
+}
+
+// encodeKlinesBinary lays candles out for typed-array decoding: the magic
+// "NK01", a little-endian uint32 count, then per candle the open time as an
+// int64 of unix milliseconds followed by open, high, low, close and volume
+// as float64, all little-endian.
+func encodeKlinesBinary(klines []types.Kline) []byte {
+	buf := make([]byte, 0, len(klinesMagic)+4+len(klines)*klineBinarySize)
+	buf = append(buf, klinesMagic...)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(klines)))
+	for _, k := range klines {
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(k.OpenTime))
+		for _, v := range []float64{k.Open, k.High, k.Low, k.Close, k.Volume} {
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(v))
+		}
+	}
+	return buf
+}
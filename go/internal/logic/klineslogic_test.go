@@ -0,0 +1,76 @@
+package logic
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/internal/types"
+)
+
+func TestKlinesValidatesAndEncodes(t *testing.T) {
+	svcCtx := createTestServiceContext(t)
+	svcCtx.Config.Klines.Provider = "hyperliquid"
+	l := NewKlinesLogic(context.Background(), svcCtx)
+
+	_, err := l.Klines(&types.KlinesRequest{Interval: "3m"})
+	assert.ErrorContains(t, err, "symbol is required")
+	_, err = l.Klines(&types.KlinesRequest{Symbol: "BTC", Interval: "3m", From: 2, To: 1})
+	assert.ErrorContains(t, err, "from must be before to")
+
+	// Without a database there are no stored candles.
+	resp, err := l.Klines(&types.KlinesRequest{Symbol: " btc ", Interval: "3m"})
+	require.NoError(t, err)
+	assert.Equal(t, "BTC", resp.Symbol)
+	assert.Equal(t, "hyperliquid", resp.Provider)
+	assert.Empty(t, resp.Klines)
+
+	resp.Klines = []types.Kline{
+		{OpenTime: 1735689600000, Open: 1, High: 2, Low: 0.5, Close: 1.5, Volume: 10},
+		{OpenTime: 1735689780000, Open: 1.5, High: 3, Low: 1.25, Close: 2.75, Volume: 20},
+	}
+	body, contentType, err := l.Encode(&types.KlinesRequest{Format: "json"}, resp)
+	require.NoError(t, err)
+	assert.Contains(t, contentType, "application/json")
+	var decoded types.KlinesResponse
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	assert.Equal(t, *resp, decoded)
+
+	body, contentType, err = l.Encode(&types.KlinesRequest{Format: "binary"}, resp)
+	require.NoError(t, err)
+	assert.Equal(t, "application/octet-stream", contentType)
+	require.Len(t, body, 8+2*klineBinarySize)
+	assert.Equal(t, klinesMagic, string(body[:4]))
+	assert.Equal(t, uint32(2), binary.LittleEndian.Uint32(body[4:8]))
+	second := body[8+klineBinarySize:]
+	assert.Equal(t, int64(1735689780000), int64(binary.LittleEndian.Uint64(second)))
+	assert.Equal(t, 2.75, math.Float64frombits(binary.LittleEndian.Uint64(second[32:])), "close is the fourth price")
+	assert.Equal(t, 20.0, math.Float64frombits(binary.LittleEndian.Uint64(second[40:])))
+}
+
+func TestKlinesMaxAge(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	open := 15 * time.Second
+	cases := []struct {
+		name string
+		req  types.KlinesRequest
+		want time.Duration
+	}{
+		{"open ended", types.KlinesRequest{Interval: "3m"}, open},
+		{"ends inside the open candle", types.KlinesRequest{Interval: "3m", To: now.Add(-time.Minute).UnixMilli()}, open},
+		{"ends before the open candle", types.KlinesRequest{Interval: "3m", To: now.Add(-time.Hour).UnixMilli()}, closedKlinesMaxAge},
+		{"day interval", types.KlinesRequest{Interval: "1d", To: now.Add(-12 * time.Hour).UnixMilli()}, open},
+		{"unknown interval", types.KlinesRequest{Interval: "1M", To: now.Add(-time.Hour).UnixMilli()}, open},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, klinesMaxAge(&tc.req, open, now))
+		})
+	}
+}
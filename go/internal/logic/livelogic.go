@@ -0,0 +1,179 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"nof0-api/internal/live"
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const defaultLiveHeartbeat = 15 * time.Second
+
+// Live message types sent to clients.
+const (
+	liveMessageEvent      = "event"
+	liveMessageSubscribed = "subscribed"
+	liveMessageHeartbeat  = "heartbeat"
+	liveMessagePong       = "pong"
+	liveMessageError      = "error"
+)
+
+// LiveConn is one dashboard connection. Receive returns the next client
+// message; Send is only ever called from the goroutine running Live.
+type LiveConn interface {
+	Receive() ([]byte, error)
+	Send(msg *types.LiveMessage) error
+}
+
+type LiveLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewLiveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LiveLogic {
+	return &LiveLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Validate rejects requests that cannot be served, before the handler
+// upgrades the connection.
+func (l *LiveLogic) Validate(req *types.LiveRequest) error {
+	if l.svcCtx.LiveHub == nil {
+		return errors.New("live updates require redis")
+	}
+	for _, t := range splitTopics(req.Topics) {
+		if !live.ValidTopic(t) {
+			return fmt.Errorf("unknown topic %q (want %s)", t, strings.Join(live.Topics, ", "))
+		}
+	}
+	return nil
+}
+
+// Live pushes the events of the subscribed topics to conn until the client
+// disconnects, falls too far behind or the request ends. Clients change
+// topics with {"op":"subscribe"|"unsubscribe","topics":[...]} and may send
+// {"op":"ping"}; idle connections receive a heartbeat.
+func (l *LiveLogic) Live(req *types.LiveRequest, conn LiveConn) error {
+	if err := l.Validate(req); err != nil {
+		return err
+	}
+	sub, err := l.svcCtx.LiveHub.Subscribe(req.ModelId, splitTopics(req.Topics))
+	if errors.Is(err, live.ErrTooManyClients) {
+		return conn.Send(liveError("too many clients, retry later"))
+	}
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	commands := make(chan []byte)
+	go func() {
+		defer close(commands)
+		for {
+			raw, err := conn.Receive()
+			if err != nil {
+				return
+			}
+			select {
+			case commands <- raw:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	if err := conn.Send(&types.LiveMessage{Type: liveMessageSubscribed, Topics: sub.Topics(), At: time.Now().UnixMilli()}); err != nil {
+		return nil
+	}
+	interval := l.svcCtx.Config.Live.Heartbeat
+	if interval <= 0 {
+		interval = defaultLiveHeartbeat
+	}
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+	for {
+		var msg *types.LiveMessage
+		select {
+		case ev, ok := <-sub.Events():
+			if !ok {
+				_ = conn.Send(liveError("connection fell behind, reconnect"))
+				return nil
+			}
+			msg = &types.LiveMessage{
+				Type:    liveMessageEvent,
+				Id:      ev.ID,
+				Topic:   ev.Topic,
+				ModelId: ev.TraderID,
+				Data:    ev.Data,
+				At:      ev.At.UnixMilli(),
+			}
+		case raw, ok := <-commands:
+			if !ok {
+				return nil
+			}
+			msg = applyLiveCommand(sub, raw)
+		case <-heartbeat.C:
+			msg = &types.LiveMessage{Type: liveMessageHeartbeat, At: time.Now().UnixMilli()}
+		case <-l.ctx.Done():
+			return nil
+		}
+		if err := conn.Send(msg); err != nil {
+			return nil
+		}
+		heartbeat.Reset(interval)
+	}
+}
+
+// applyLiveCommand handles one client message and returns the reply.
+func applyLiveCommand(sub *live.Subscription, raw []byte) *types.LiveMessage {
+	var cmd types.LiveCommand
+	if err := json.Unmarshal(raw, &cmd); err != nil {
+		return liveError("invalid message: " + err.Error())
+	}
+	var (
+		topics []string
+		err    error
+	)
+	switch strings.ToLower(strings.TrimSpace(cmd.Op)) {
+	case "subscribe":
+		topics, err = sub.Subscribe(cmd.Topics...)
+	case "unsubscribe":
+		topics, err = sub.Unsubscribe(cmd.Topics...)
+	case "ping":
+		return &types.LiveMessage{Type: liveMessagePong, At: time.Now().UnixMilli()}
+	default:
+		return liveError(fmt.Sprintf("unknown op %q (want subscribe, unsubscribe or ping)", cmd.Op))
+	}
+	if err != nil {
+		return liveError(err.Error())
+	}
+	return &types.LiveMessage{Type: liveMessageSubscribed, Topics: topics, At: time.Now().UnixMilli()}
+}
+
+func liveError(message string) *types.LiveMessage {
+	return &types.LiveMessage{Type: liveMessageError, Message: message, At: time.Now().UnixMilli()}
+}
+
+func splitTopics(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
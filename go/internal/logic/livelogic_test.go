@@ -0,0 +1,105 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/internal/config"
+	"nof0-api/internal/live"
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+)
+
+// onceSource holds one account event that is newer than its reported tail.
+type onceSource struct{}
+
+func (onceSource) Tail(ctx context.Context) (string, error) { return "0-0", nil }
+
+func (onceSource) Read(ctx context.Context, after string, count int) ([]live.Event, error) {
+	if after != "0-0" {
+		return nil, nil
+	}
+	return []live.Event{{ID: "0-1", Topic: live.TopicAccount, TraderID: "m1", Data: json.RawMessage(`{"equity_usd":100}`), At: time.UnixMilli(1_700_000_000_000)}}, nil
+}
+
+type fakeLiveConn struct {
+	in  chan []byte
+	out chan *types.LiveMessage
+}
+
+func (c *fakeLiveConn) Receive() ([]byte, error) {
+	raw, ok := <-c.in
+	if !ok {
+		return nil, io.EOF
+	}
+	return raw, nil
+}
+
+func (c *fakeLiveConn) Send(msg *types.LiveMessage) error {
+	c.out <- msg
+	return nil
+}
+
+// next returns the next message of type typ, skipping heartbeats.
+func (c *fakeLiveConn) next(t *testing.T, typ string) *types.LiveMessage {
+	t.Helper()
+	deadline := time.After(3 * time.Second)
+	for {
+		select {
+		case msg := <-c.out:
+			if msg.Type == "heartbeat" && typ != "heartbeat" {
+				continue
+			}
+			require.Equal(t, typ, msg.Type, msg.Message)
+			return msg
+		case <-deadline:
+			t.Fatalf("no %s message", typ)
+		}
+	}
+}
+
+func TestLiveStreamsEventsAndCommands(t *testing.T) {
+	svcCtx := &svc.ServiceContext{
+		Config:  config.Config{Live: config.LiveConf{Heartbeat: 20 * time.Millisecond}},
+		LiveHub: live.NewHub(onceSource{}, 0),
+	}
+	l := NewLiveLogic(context.Background(), svcCtx)
+	require.Error(t, l.Validate(&types.LiveRequest{Topics: "trades"}))
+
+	conn := &fakeLiveConn{in: make(chan []byte), out: make(chan *types.LiveMessage, 16)}
+	done := make(chan error, 1)
+	go func() { done <- l.Live(&types.LiveRequest{Topics: "account"}, conn) }()
+
+	assert.Equal(t, []string{live.TopicAccount}, conn.next(t, "subscribed").Topics)
+	conn.next(t, "heartbeat")
+
+	conn.in <- []byte(`{"op":"subscribe","topics":["decisions"]}`)
+	assert.Equal(t, []string{live.TopicAccount, live.TopicDecisions}, conn.next(t, "subscribed").Topics)
+	conn.in <- []byte(`{"op":"ping"}`)
+	conn.next(t, "pong")
+	conn.in <- []byte(`{"op":"unsubscribe","topics":["trades"]}`)
+	assert.Contains(t, conn.next(t, "error").Message, "unknown topic")
+
+	ev := conn.next(t, "event")
+	assert.Equal(t, "0-1", ev.Id)
+	assert.Equal(t, live.TopicAccount, ev.Topic)
+	assert.Equal(t, "m1", ev.ModelId)
+	body, err := json.Marshal(ev)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"data":{"equity_usd":100}`)
+
+	close(conn.in)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Live did not return after the client went away")
+	}
+	assert.Zero(t, svcCtx.LiveHub.Clients())
+}
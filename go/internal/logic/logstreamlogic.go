@@ -0,0 +1,152 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	cachekeys "nof0-api/internal/cache"
+	"nof0-api/internal/redisstream"
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	logStreamPollInterval    = time.Second
+	logStreamBatch           = 200
+	logStreamDefaultBackfill = 50
+	logStreamMaxBackfill     = 500
+)
+
+var streamIDPattern = regexp.MustCompile(`^\d+-\d+$`)
+
+type LogStreamLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewLogStreamLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LogStreamLogic {
+	return &LogStreamLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Validate rejects requests that cannot be served, before the handler
+// commits to an event stream.
+func (l *LogStreamLogic) Validate(req *types.LogStreamRequest) error {
+	if l.svcCtx.Redis == nil {
+		return errors.New("log stream requires redis")
+	}
+	if req.LastEventId != "" && !streamIDPattern.MatchString(req.LastEventId) {
+		return fmt.Errorf("invalid Last-Event-ID %q", req.LastEventId)
+	}
+	return nil
+}
+
+// LogStream tails the pipeline event stream published by the trading engine
+// and sends matching events to client until the request context ends. A
+// reconnecting client resumes after Last-Event-ID; otherwise the most recent
+// Backfill events are replayed first.
+func (l *LogStreamLogic) LogStream(req *types.LogStreamRequest, client chan<- *types.PipelineEvent) error {
+	if err := l.Validate(req); err != nil {
+		return err
+	}
+	key := cachekeys.PipelineLogStreamKey()
+	filter := logStreamFilter{
+		modelID: strings.ToLower(strings.TrimSpace(req.ModelId)),
+		traceID: strings.TrimSpace(req.TraceId),
+	}
+
+	lastID := req.LastEventId
+	if lastID == "" {
+		backfill := req.Backfill
+		if backfill <= 0 {
+			backfill = logStreamDefaultBackfill
+		}
+		if backfill > logStreamMaxBackfill {
+			backfill = logStreamMaxBackfill
+		}
+		entries, err := redisstream.Latest(l.ctx, l.svcCtx.Redis, key, backfill)
+		if err != nil {
+			return err
+		}
+		lastID = "0-0"
+		if len(entries) > 0 {
+			lastID = entries[len(entries)-1].ID
+		}
+		if !l.send(entries, filter, client) {
+			return nil
+		}
+	}
+
+	ticker := time.NewTicker(logStreamPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		entries, err := redisstream.After(l.ctx, l.svcCtx.Redis, key, lastID, logStreamBatch)
+		if err != nil {
+			if l.ctx.Err() != nil {
+				return nil
+			}
+			l.Errorf("log stream: read %s: %v", key, err)
+			continue
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		lastID = entries[len(entries)-1].ID
+		if !l.send(entries, filter, client) {
+			return nil
+		}
+	}
+}
+
+// send decodes and forwards matching entries; it reports false once the
+// client has gone away.
+func (l *LogStreamLogic) send(entries []redisstream.Entry, filter logStreamFilter, client chan<- *types.PipelineEvent) bool {
+	for _, e := range entries {
+		var ev types.PipelineEvent
+		if err := json.Unmarshal([]byte(e.Data), &ev); err != nil {
+			l.Errorf("log stream: decode entry %s: %v", e.ID, err)
+			continue
+		}
+		if !filter.match(&ev) {
+			continue
+		}
+		ev.Id = e.ID
+		select {
+		case client <- &ev:
+		case <-l.ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+type logStreamFilter struct {
+	modelID string
+	traceID string
+}
+
+func (f logStreamFilter) match(ev *types.PipelineEvent) bool {
+	if f.modelID != "" && strings.ToLower(ev.TraderId) != f.modelID {
+		return false
+	}
+	if f.traceID != "" && ev.TraceId != f.traceID {
+		return false
+	}
+	return true
+}
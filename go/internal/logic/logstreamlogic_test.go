@@ -0,0 +1,18 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"nof0-api/internal/types"
+)
+
+func TestLogStreamFilter(t *testing.T) {
+	ev := &types.PipelineEvent{TraderId: "Alpha", TraceId: "alpha-1"}
+	assert.True(t, logStreamFilter{}.match(ev))
+	assert.True(t, logStreamFilter{modelID: "alpha"}.match(ev))
+	assert.False(t, logStreamFilter{modelID: "beta"}.match(ev))
+	assert.True(t, logStreamFilter{modelID: "alpha", traceID: "alpha-1"}.match(ev))
+	assert.False(t, logStreamFilter{traceID: "alpha-2"}.match(ev))
+}
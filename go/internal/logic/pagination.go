@@ -0,0 +1,130 @@
+package logic
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxPageLimit caps the page size of the paginated list endpoints and is
+// the size used when a request leaves limit unset.
+const maxPageLimit = 500
+
+// pageCursor is the keyset position of the last row on a page of a list
+// ordered newest first: the row's sort timestamp, in the unit of the column
+// it sorts by, and its id as the tie-breaker.
+type pageCursor struct {
+	Ts int64
+	ID string
+}
+
+func encodePageCursor(c pageCursor) string {
+	raw := strconv.FormatInt(c.Ts, 10) + ":" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodePageCursor(s string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	tsStr, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return pageCursor{}, errors.New("invalid cursor")
+	}
+	ts, err := strconv.ParseInt(tsStr, 10, 64)
+	if err != nil {
+		return pageCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return pageCursor{Ts: ts, ID: id}, nil
+}
+
+// after reports whether a row keyed (ts, id) sorts after the cursor, i.e.
+// belongs to a later page.
+func (c pageCursor) after(ts int64, id string) bool {
+	return ts < c.Ts || (ts == c.Ts && id < c.ID)
+}
+
+// pageRequest is the parsed form of the modelId, from, to, limit and cursor
+// parameters shared by the list endpoints. From and To are unix milliseconds
+// bounding [From, To); zero leaves that side open.
+type pageRequest struct {
+	ModelID string
+	From    int64
+	To      int64
+	Limit   int
+	Cursor  *pageCursor
+}
+
+func parsePageRequest(modelID string, from, to int64, limit int, cursor string) (pageRequest, error) {
+	if from < 0 || to < 0 {
+		return pageRequest{}, errors.New("from and to must be unix milliseconds")
+	}
+	if from > 0 && to > 0 && from >= to {
+		return pageRequest{}, errors.New("from must be before to")
+	}
+	if limit <= 0 || limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	req := pageRequest{ModelID: strings.TrimSpace(modelID), From: from, To: to, Limit: limit}
+	if cursor != "" {
+		c, err := decodePageCursor(cursor)
+		if err != nil {
+			return pageRequest{}, err
+		}
+		req.Cursor = &c
+	}
+	return req, nil
+}
+
+// inRange reports whether tsMs falls in [From, To).
+func (p pageRequest) inRange(tsMs int64) bool {
+	return (p.From == 0 || tsMs >= p.From) && (p.To == 0 || tsMs < p.To)
+}
+
+// pageInMemory filters rows already sorted newest first by key to the
+// request's model, range and cursor, and cuts one page. It serves the file
+// backed data when no database is configured. key returns a row's model id,
+// timestamp in milliseconds and id.
+func pageInMemory[T any](rows []T, p pageRequest, key func(T) (modelID string, tsMs int64, id string)) (page []T, next string) {
+	page = []T{}
+	for _, row := range rows {
+		modelID, ts, id := key(row)
+		if p.ModelID != "" && modelID != p.ModelID || !p.inRange(ts) {
+			continue
+		}
+		if p.Cursor != nil && !p.Cursor.after(ts, id) {
+			continue
+		}
+		if len(page) == p.Limit {
+			last := page[len(page)-1]
+			_, lastTs, lastID := key(last)
+			return page, encodePageCursor(pageCursor{Ts: lastTs, ID: lastID})
+		}
+		page = append(page, row)
+	}
+	return page, ""
+}
+
+// secondsToMillis converts the fractional unix seconds of the nof1 data
+// files to milliseconds.
+func secondsToMillis(sec float64) int64 {
+	return int64(math.Round(sec * 1000))
+}
+
+func floatValue(p *float64) float64 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+func int64Value(p *int64) int64 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
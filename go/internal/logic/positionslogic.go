@@ -5,7 +5,10 @@ package logic
 
 import (
 	"context"
+	"sort"
+	"time"
 
+	"nof0-api/internal/model"
 	"nof0-api/internal/svc"
 	"nof0-api/internal/types"
 
@@ -26,6 +29,98 @@ func NewPositionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Positio
 	}
 }
 
+// Positions returns open positions grouped by model, optionally for one
+// model and entry-time range, with at most Limit positions in total. It reads
+// the positions table when a database is configured and the data files
+// otherwise.
 func (l *PositionsLogic) Positions(req *types.PositionsRequest) (resp *types.PositionsResponse, err error) {
-	return l.svcCtx.DataLoader.LoadPositions()
+	page, err := parsePageRequest(req.ModelId, req.From, req.To, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	if l.svcCtx.PositionsModel != nil {
+		resp, err = l.positionsFromModel(page.ModelID)
+	} else {
+		resp, err = l.svcCtx.DataLoader.LoadPositions()
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp.AccountTotals = filterPositions(resp.AccountTotals, page, req.Limit)
+	return resp, nil
+}
+
+func (l *PositionsLogic) positionsFromModel(modelID string) (*types.PositionsResponse, error) {
+	var ids []string
+	if modelID != "" {
+		ids = []string{modelID}
+	}
+	byModel, err := l.svcCtx.PositionsModel.ActiveByModels(l.ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	resp := &types.PositionsResponse{AccountTotals: []types.PositionsByModel{}, ServerTime: time.Now().UnixMilli()}
+	for id, records := range byModel {
+		group := types.PositionsByModel{ModelId: id, Positions: make(map[string]types.Position, len(records))}
+		for _, rec := range records {
+			group.Positions[rec.Symbol] = positionFromRecord(rec)
+		}
+		resp.AccountTotals = append(resp.AccountTotals, group)
+	}
+	return resp, nil
+}
+
+// positionFromRecord maps a positions row onto the nof1 position shape:
+// entry time in unix seconds and shorts as negative quantities.
+func positionFromRecord(rec model.PositionRecord) types.Position {
+	qty := rec.Quantity
+	if rec.Side == "short" && qty > 0 {
+		qty = -qty
+	}
+	return types.Position{
+		Symbol:        rec.Symbol,
+		EntryPrice:    rec.EntryPrice,
+		EntryTime:     float64(rec.EntryTimeMs) / 1000,
+		Quantity:      qty,
+		Leverage:      floatValue(rec.Leverage),
+		Confidence:    floatValue(rec.Confidence),
+		RiskUsd:       floatValue(rec.RiskUsd),
+		UnrealizedPnl: floatValue(rec.UnrealizedPnl),
+	}
+}
+
+// filterPositions keeps the positions of the requested model and entry-time
+// range, ordered by model id, and stops after limit positions when positive.
+func filterPositions(groups []types.PositionsByModel, page pageRequest, limit int) []types.PositionsByModel {
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].ModelId < groups[j].ModelId })
+	out := make([]types.PositionsByModel, 0, len(groups))
+	kept := 0
+	for _, g := range groups {
+		if page.ModelID != "" && g.ModelId != page.ModelID {
+			continue
+		}
+		symbols := make([]string, 0, len(g.Positions))
+		for sym := range g.Positions {
+			symbols = append(symbols, sym)
+		}
+		sort.Strings(symbols)
+		filtered := types.PositionsByModel{ModelId: g.ModelId, Positions: map[string]types.Position{}}
+		for _, sym := range symbols {
+			if limit > 0 && kept == limit {
+				break
+			}
+			pos := g.Positions[sym]
+			if !page.inRange(secondsToMillis(pos.EntryTime)) {
+				continue
+			}
+			filtered.Positions[sym] = pos
+			kept++
+		}
+		// Models without positions stay listed; models whose positions were
+		// all filtered out do not.
+		if len(filtered.Positions) > 0 || len(g.Positions) == 0 {
+			out = append(out, filtered)
+		}
+	}
+	return out
 }
@@ -0,0 +1,135 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+	executorpkg "nof0-api/pkg/executor"
+	managerpkg "nof0-api/pkg/manager"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RiskExplainLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRiskExplainLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RiskExplainLogic {
+	return &RiskExplainLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// RiskExplain dry-runs the risk engine on a decision and reports every rule
+// with its inputs, threshold and outcome.
+func (l *RiskExplainLogic) RiskExplain(req *types.RiskExplainRequest) (resp *types.RiskExplainResponse, err error) {
+	return ExplainRisk(l.svcCtx.ExecutorConfig, l.svcCtx.ManagerConfig, req)
+}
+
+// ExplainRisk evaluates req against the risk engine. With a trader id the
+// trader's risk params and exec guards from managerCfg apply; otherwise
+// executorCfg does. Without a portfolio, rules needing account state are
+// reported as skipped.
+func ExplainRisk(executorCfg *executorpkg.Config, managerCfg *managerpkg.Config, req *types.RiskExplainRequest) (*types.RiskExplainResponse, error) {
+	d := toExecutorDecision(req.Decision)
+	traderID := strings.TrimSpace(req.TraderId)
+
+	var exp executorpkg.Explanation
+	if traderID != "" {
+		if managerCfg == nil {
+			return nil, errors.New("risk explain unavailable: manager config not loaded")
+		}
+		trader, ok := findTraderConfig(managerCfg, traderID)
+		if !ok {
+			return nil, fmt.Errorf("trader %s not found", traderID)
+		}
+		var base executorpkg.Context
+		if p := toExecutorPortfolio(req.Portfolio); p != nil {
+			base = *p
+		}
+		exp = managerpkg.ExplainDecision(trader, base, d)
+	} else {
+		if executorCfg == nil {
+			return nil, errors.New("risk explain unavailable: executor config not loaded")
+		}
+		exp = executorpkg.Explain(executorCfg, toExecutorPortfolio(req.Portfolio), d)
+	}
+	return toRiskExplainResponse(traderID, exp), nil
+}
+
+func findTraderConfig(cfg *managerpkg.Config, id string) (managerpkg.TraderConfig, bool) {
+	for _, t := range cfg.Traders {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return managerpkg.TraderConfig{}, false
+}
+
+func toExecutorDecision(d types.RiskDecision) executorpkg.Decision {
+	return executorpkg.Decision{
+		Symbol:          strings.ToUpper(strings.TrimSpace(d.Symbol)),
+		Action:          d.Action,
+		Leverage:        d.Leverage,
+		PositionSizeUSD: d.PositionSizeUsd,
+		EntryPrice:      d.EntryPrice,
+		StopLoss:        d.StopLoss,
+		TakeProfit:      d.TakeProfit,
+		Confidence:      d.Confidence,
+		RiskUSD:         d.RiskUsd,
+	}
+}
+
+func toExecutorPortfolio(p *types.RiskPortfolio) *executorpkg.Context {
+	if p == nil {
+		return nil
+	}
+	ctx := &executorpkg.Context{
+		Account: executorpkg.AccountInfo{
+			TotalEquity:      p.TotalEquity,
+			AvailableBalance: p.AvailableBalance,
+			MarginUsed:       p.MarginUsed,
+			PositionCount:    len(p.Positions),
+		},
+		Positions: make([]executorpkg.PositionInfo, 0, len(p.Positions)),
+	}
+	if p.TotalEquity != 0 {
+		ctx.Account.MarginUsedPct = 100 * p.MarginUsed / p.TotalEquity
+	}
+	for _, pos := range p.Positions {
+		ctx.Positions = append(ctx.Positions, executorpkg.PositionInfo{
+			Symbol:   strings.ToUpper(strings.TrimSpace(pos.Symbol)),
+			Side:     strings.ToLower(strings.TrimSpace(pos.Side)),
+			Quantity: pos.Quantity,
+		})
+	}
+	return ctx
+}
+
+func toRiskExplainResponse(traderID string, exp executorpkg.Explanation) *types.RiskExplainResponse {
+	resp := &types.RiskExplainResponse{
+		TraderId: traderID,
+		Symbol:   exp.Symbol,
+		Action:   exp.Action,
+		Allowed:  exp.Allowed,
+		Rules:    make([]types.RiskRule, 0, len(exp.Rules)),
+	}
+	for _, r := range exp.Rules {
+		resp.Rules = append(resp.Rules, types.RiskRule{
+			Rule:      r.Rule,
+			Status:    string(r.Status),
+			Inputs:    r.Inputs,
+			Threshold: r.Threshold,
+			Detail:    r.Detail,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,96 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"nof0-api/internal/share"
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SharedPerformanceLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSharedPerformanceLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SharedPerformanceLogic {
+	return &SharedPerformanceLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SharedPerformance serves the public slice of a model's performance behind
+// a share token: the equity curve and trades, without model reasoning, exit
+// plans or open positions.
+func (l *SharedPerformanceLogic) SharedPerformance(req *types.SharedPerformanceRequest) (resp *types.SharedPerformanceResponse, err error) {
+	secret := l.svcCtx.Config.Share.Secret
+	if secret == "" {
+		return nil, errors.New("share links disabled")
+	}
+	claims, err := share.Verify([]byte(secret), req.Token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	totals, err := l.svcCtx.DataLoader.LoadAccountTotals()
+	if err != nil {
+		return nil, err
+	}
+	trades, err := l.svcCtx.DataLoader.LoadTrades()
+	if err != nil {
+		return nil, err
+	}
+	return &types.SharedPerformanceResponse{
+		ModelId:     claims.ModelID,
+		ExpiresAt:   claims.ExpiresAt.Unix(),
+		EquityCurve: sharedEquityCurve(totals.AccountTotals, claims.ModelID),
+		Trades:      sharedTrades(trades.Trades, claims.ModelID),
+		ServerTime:  time.Now().UnixMilli(),
+	}, nil
+}
+
+func sharedEquityCurve(totals []types.AccountTotal, modelID string) []types.SharedEquityPoint {
+	out := []types.SharedEquityPoint{}
+	for _, t := range totals {
+		if t.ModelId != modelID {
+			continue
+		}
+		out = append(out, types.SharedEquityPoint{
+			Timestamp:    t.Timestamp,
+			DollarEquity: t.DollarEquity,
+			CumPnlPct:    t.CumPnlPct,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Timestamp < out[j].Timestamp })
+	return out
+}
+
+// sharedTrades copies only execution facts; confidence and exit plans stay private.
+func sharedTrades(trades []types.Trade, modelID string) []types.SharedTrade {
+	out := []types.SharedTrade{}
+	for _, t := range trades {
+		if t.ModelId != modelID {
+			continue
+		}
+		out = append(out, types.SharedTrade{
+			Symbol:         t.Symbol,
+			Side:           t.Side,
+			Quantity:       t.Quantity,
+			Leverage:       t.Leverage,
+			EntryPrice:     t.EntryPrice,
+			EntryTime:      t.EntryTime,
+			ExitPrice:      t.ExitPrice,
+			ExitTime:       t.ExitTime,
+			RealizedNetPnl: t.RealizedNetPnl,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].EntryTime > out[j].EntryTime })
+	return out
+}
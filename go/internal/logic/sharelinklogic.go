@@ -0,0 +1,60 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"nof0-api/internal/share"
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ShareLinkLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewShareLinkLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ShareLinkLogic {
+	return &ShareLinkLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ShareLink signs a public read-only link to a model's performance.
+func (l *ShareLinkLogic) ShareLink(req *types.ShareLinkRequest) (resp *types.ShareLinkResponse, err error) {
+	cfg := l.svcCtx.Config.Share
+	if cfg.Secret == "" {
+		return nil, errors.New("share links disabled: Share.Secret is not configured")
+	}
+	modelID := strings.TrimSpace(req.ModelId)
+	if modelID == "" {
+		return nil, errors.New("model_id is required")
+	}
+	ttl := cfg.DefaultTTL
+	if req.TtlSeconds > 0 {
+		ttl = time.Duration(req.TtlSeconds) * time.Second
+	}
+	if cfg.MaxTTL > 0 && ttl > cfg.MaxTTL {
+		return nil, fmt.Errorf("ttl_seconds exceeds the maximum of %d", int64(cfg.MaxTTL/time.Second))
+	}
+	expiresAt := time.Now().Add(ttl)
+	token := share.Sign([]byte(cfg.Secret), modelID, expiresAt)
+	url := "/api/share/" + token
+	if cfg.BaseURL != "" {
+		url = strings.TrimRight(cfg.BaseURL, "/") + "/" + token
+	}
+	l.Infof("share link issued model=%s expires=%s", modelID, expiresAt.UTC().Format(time.RFC3339))
+	return &types.ShareLinkResponse{
+		Token:     token,
+		Url:       url,
+		ExpiresAt: expiresAt.Unix(),
+	}, nil
+}
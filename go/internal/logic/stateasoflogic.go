@@ -0,0 +1,198 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type StateAsOfLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewStateAsOfLogic(ctx context.Context, svcCtx *svc.ServiceContext) *StateAsOfLogic {
+	return &StateAsOfLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// StateAsOf reconstructs a model's account and positions as they were at
+// At, in unix milliseconds. The account starts from the newest equity
+// snapshot at or before At; trades closed between that snapshot and At are
+// listed and added to its realized PnL. Positions are the trades open at At
+// plus today's open positions entered by then. Mark-dependent fields such as
+// unrealized PnL are not known for past instants and are left zero.
+func (l *StateAsOfLogic) StateAsOf(req *types.StateAsOfRequest) (resp *types.StateAsOfResponse, err error) {
+	modelID := strings.TrimSpace(req.ModelId)
+	if modelID == "" {
+		return nil, errors.New("modelId is required")
+	}
+	if req.At <= 0 {
+		return nil, errors.New("at must be unix milliseconds")
+	}
+	var (
+		snapshot *types.AccountSnapshot
+		trades   []types.Trade
+		open     map[string]types.Position
+	)
+	if l.svcCtx.AccountEquitySnapshotsModel != nil && l.svcCtx.TradesModel != nil && l.svcCtx.PositionsModel != nil {
+		snapshot, trades, open, err = l.stateFromModels(modelID, req.At)
+	} else {
+		snapshot, trades, open, err = l.stateFromFiles(modelID, req.At)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp = buildStateAsOf(modelID, req.At, snapshot, trades, open)
+	resp.ServerTime = time.Now().UnixMilli()
+	return resp, nil
+}
+
+func (l *StateAsOfLogic) stateFromModels(modelID string, at int64) (*types.AccountSnapshot, []types.Trade, map[string]types.Position, error) {
+	var snapshot *types.AccountSnapshot
+	row, err := l.svcCtx.AccountEquitySnapshotsModel.AsOf(l.ctx, modelID, at)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var since int64
+	if row != nil {
+		s := snapshotFromModel(*row)
+		snapshot, since = &s, s.Timestamp
+	}
+	records, err := l.svcCtx.TradesModel.Overlapping(l.ctx, modelID, since, at)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	trades := make([]types.Trade, 0, len(records))
+	for _, rec := range records {
+		trades = append(trades, tradeFromRecord(rec))
+	}
+	byModel, err := l.svcCtx.PositionsModel.ActiveByModels(l.ctx, []string{modelID})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	open := make(map[string]types.Position, len(byModel[modelID]))
+	for _, rec := range byModel[modelID] {
+		open[rec.Symbol] = positionFromRecord(rec)
+	}
+	return snapshot, trades, open, nil
+}
+
+func (l *StateAsOfLogic) stateFromFiles(modelID string, at int64) (*types.AccountSnapshot, []types.Trade, map[string]types.Position, error) {
+	totals, err := l.svcCtx.DataLoader.LoadAccountTotals()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var snapshot *types.AccountSnapshot
+	for _, t := range totals.AccountTotals {
+		ts := secondsToMillis(t.Timestamp)
+		if t.ModelId != modelID || ts > at || (snapshot != nil && ts <= snapshot.Timestamp) {
+			continue
+		}
+		snapshot = &types.AccountSnapshot{
+			ModelId:            t.ModelId,
+			Timestamp:          ts,
+			DollarEquity:       t.DollarEquity,
+			RealizedPnl:        t.RealizedPnl,
+			TotalUnrealizedPnl: t.TotalUnrealizedPnl,
+			CumPnlPct:          t.CumPnlPct,
+			SharpeRatio:        t.SharpeRatio,
+		}
+	}
+	all, err := l.svcCtx.DataLoader.LoadTrades()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var trades []types.Trade
+	for _, t := range all.Trades {
+		if t.ModelId == modelID {
+			trades = append(trades, t)
+		}
+	}
+	positions, err := l.svcCtx.DataLoader.LoadPositions()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var open map[string]types.Position
+	for _, g := range positions.AccountTotals {
+		if g.ModelId == modelID {
+			open = g.Positions
+		}
+	}
+	return snapshot, trades, open, nil
+}
+
+// buildStateAsOf replays trades over snapshot up to at. Trades entered after
+// at or closed at or before the snapshot do not affect the result.
+func buildStateAsOf(modelID string, at int64, snapshot *types.AccountSnapshot, trades []types.Trade, open map[string]types.Position) *types.StateAsOfResponse {
+	resp := &types.StateAsOfResponse{
+		ModelId:      modelID,
+		At:           at,
+		Snapshot:     snapshot,
+		Positions:    map[string]types.Position{},
+		ClosedTrades: []types.Trade{},
+	}
+	var since int64
+	if snapshot != nil {
+		since = snapshot.Timestamp
+		resp.RealizedPnl = snapshot.RealizedPnl
+	}
+	sort.SliceStable(trades, func(i, j int) bool {
+		if trades[i].ExitTime != trades[j].ExitTime {
+			return trades[i].ExitTime > trades[j].ExitTime
+		}
+		return trades[i].Id > trades[j].Id
+	})
+	for _, t := range trades {
+		entry, exit := secondsToMillis(t.EntryTime), secondsToMillis(t.ExitTime)
+		if entry > at || exit <= since {
+			continue
+		}
+		if exit > at {
+			if _, ok := resp.Positions[t.Symbol]; !ok {
+				resp.Positions[t.Symbol] = positionFromTrade(t)
+			}
+			continue
+		}
+		resp.ClosedTrades = append(resp.ClosedTrades, t)
+		resp.RealizedPnl += t.RealizedNetPnl
+	}
+	for sym, p := range open {
+		if _, ok := resp.Positions[sym]; ok || secondsToMillis(p.EntryTime) > at {
+			continue
+		}
+		p.CurrentPrice, p.UnrealizedPnl, p.LiquidationPrice = 0, 0, 0
+		resp.Positions[sym] = p
+	}
+	return resp
+}
+
+// positionFromTrade is the position a trade held while open, with shorts as
+// negative quantities.
+func positionFromTrade(t types.Trade) types.Position {
+	qty := math.Abs(t.Quantity)
+	if t.Side == "short" {
+		qty = -qty
+	}
+	return types.Position{
+		Symbol:     t.Symbol,
+		EntryPrice: t.EntryPrice,
+		EntryTime:  t.EntryTime,
+		EntryOid:   t.EntryOid,
+		Quantity:   qty,
+		Leverage:   t.Leverage,
+		Confidence: t.Confidence,
+	}
+}
@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/internal/types"
+)
+
+func TestStateAsOfReplaysTradesOverSnapshot(t *testing.T) {
+	l := NewStateAsOfLogic(context.Background(), createTestServiceContext(t))
+
+	// Between the DOGE short closing (1760748644.383) and the next snapshot.
+	at := int64(1760749000000)
+	resp, err := l.StateAsOf(&types.StateAsOfRequest{ModelId: "gpt-5", At: at})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Snapshot)
+	assert.LessOrEqual(t, resp.Snapshot.Timestamp, at)
+	assert.Greater(t, resp.Snapshot.Timestamp, int64(1760745000000), "the newest snapshot before at is used")
+
+	require.Len(t, resp.ClosedTrades, 1)
+	closed := resp.ClosedTrades[0]
+	assert.Equal(t, "DOGE", closed.Symbol)
+	assert.InDelta(t, resp.Snapshot.RealizedPnl+closed.RealizedNetPnl, resp.RealizedPnl, 1e-9)
+
+	require.Contains(t, resp.Positions, "BNB", "a trade open across at is a position")
+	assert.Less(t, resp.Positions["BNB"].Quantity, 0.0, "shorts are negative")
+	assert.NotContains(t, resp.Positions, "DOGE", "closed before at and reopened after it")
+	for _, p := range resp.Positions {
+		assert.LessOrEqual(t, secondsToMillis(p.EntryTime), at)
+		assert.Zero(t, p.UnrealizedPnl)
+	}
+
+	// Before the first snapshot there is no account baseline.
+	early, err := l.StateAsOf(&types.StateAsOfRequest{ModelId: "gpt-5", At: 1700000000000})
+	require.NoError(t, err)
+	assert.Nil(t, early.Snapshot)
+	assert.Empty(t, early.Positions)
+	assert.Empty(t, early.ClosedTrades)
+
+	_, err = l.StateAsOf(&types.StateAsOfRequest{ModelId: "gpt-5"})
+	assert.ErrorContains(t, err, "at must be")
+	_, err = l.StateAsOf(&types.StateAsOfRequest{At: at})
+	assert.ErrorContains(t, err, "modelId is required")
+}
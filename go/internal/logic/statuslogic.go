@@ -0,0 +1,130 @@
+package logic
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Runner states reported by /api/status.
+const (
+	runnerStatusOK      = "ok"
+	runnerStatusStalled = "stalled"
+	runnerStatusOffline = "offline"
+)
+
+// skippedCycleWindow is the lookback for the skipped-cycle counts.
+const skippedCycleWindow = 24 * time.Hour
+
+type StatusLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewStatusLogic(ctx context.Context, svcCtx *svc.ServiceContext) *StatusLogic {
+	return &StatusLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+type heartbeatRow struct {
+	TraderID           string       `db:"trader_id"`
+	LastCycleAt        sql.NullTime `db:"last_cycle_at"`
+	LastLLMSuccessAt   sql.NullTime `db:"last_llm_success_at"`
+	LastOrderAt        sql.NullTime `db:"last_order_at"`
+	DecisionIntervalMs int64        `db:"decision_interval_ms"`
+	StallAfterMs       int64        `db:"stall_after_ms"`
+	Stalled            bool         `db:"stalled"`
+	ReportedAt         time.Time    `db:"reported_at"`
+}
+
+// Status reports runner heartbeats. Without a database there are no
+// heartbeats to read, so the list is empty rather than an error.
+func (l *StatusLogic) Status() (resp *types.StatusResponse, err error) {
+	now := time.Now()
+	resp = &types.StatusResponse{
+		Runners:    []types.RunnerStatus{},
+		ServerTime: now.UnixMilli(),
+	}
+	if l.svcCtx.DBConn == nil {
+		return resp, nil
+	}
+	const query = `SELECT trader_id, last_cycle_at, last_llm_success_at, last_order_at, decision_interval_ms, stall_after_ms, stalled, reported_at
+FROM public.trader_heartbeats ORDER BY trader_id`
+	var rows []heartbeatRow
+	if err := l.svcCtx.DBConn.QueryRowsPartialCtx(l.ctx, &rows, query); err != nil {
+		return nil, err
+	}
+	skipped, err := l.skippedCycles(now)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		runner := runnerStatus(row, now)
+		runner.SkippedCycles = skipped[row.TraderID]
+		if runner.SkippedCycles == nil {
+			runner.SkippedCycles = []types.SkippedCycleCount{}
+		}
+		resp.Runners = append(resp.Runners, runner)
+	}
+	return resp, nil
+}
+
+// skippedCycles groups recent cycle-ledger counts by trader.
+func (l *StatusLogic) skippedCycles(now time.Time) (map[string][]types.SkippedCycleCount, error) {
+	if l.svcCtx.CycleEventsModel == nil {
+		return nil, nil
+	}
+	counts, err := l.svcCtx.CycleEventsModel.CountsSince(l.ctx, now.Add(-skippedCycleWindow))
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string][]types.SkippedCycleCount)
+	for _, c := range counts {
+		out[c.TraderId] = append(out[c.TraderId], types.SkippedCycleCount{
+			Kind:   c.Kind,
+			Count:  c.Count,
+			LastAt: c.LastAt.UnixMilli(),
+		})
+	}
+	return out, nil
+}
+
+// runnerStatus classifies a heartbeat. A runner that stopped reporting for
+// longer than its stall threshold is offline (process gone); one still
+// reporting but not completing cycles is stalled (loop hung).
+func runnerStatus(row heartbeatRow, now time.Time) types.RunnerStatus {
+	out := types.RunnerStatus{
+		ModelId:            row.TraderID,
+		Status:             runnerStatusOK,
+		LastCycleAt:        nullTimeMillis(row.LastCycleAt),
+		LastLlmSuccessAt:   nullTimeMillis(row.LastLLMSuccessAt),
+		LastOrderAt:        nullTimeMillis(row.LastOrderAt),
+		DecisionIntervalMs: row.DecisionIntervalMs,
+		StallAfterMs:       row.StallAfterMs,
+		ReportedAt:         row.ReportedAt.UnixMilli(),
+	}
+	stallAfter := time.Duration(row.StallAfterMs) * time.Millisecond
+	switch {
+	case stallAfter > 0 && now.Sub(row.ReportedAt) > stallAfter:
+		out.Status = runnerStatusOffline
+	case row.Stalled:
+		out.Status = runnerStatusStalled
+	}
+	return out
+}
+
+func nullTimeMillis(t sql.NullTime) int64 {
+	if !t.Valid {
+		return 0
+	}
+	return t.Time.UnixMilli()
+}
@@ -0,0 +1,130 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+	"nof0-api/pkg/confkit"
+	"nof0-api/pkg/llm"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const templateExt = ".tmpl"
+
+type TemplateRenderLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewTemplateRenderLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TemplateRenderLogic {
+	return &TemplateRenderLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// TemplateRender renders a prompt template from the server's template root
+// with caller-supplied data, so editors preview exactly what would be sent.
+func (l *TemplateRenderLogic) TemplateRender(req *types.TemplateRenderRequest) (resp *types.TemplateRenderResponse, err error) {
+	cfg := l.svcCtx.Config
+	root := cfg.BaseDir()
+	if dir := strings.TrimSpace(cfg.Templates.Dir); dir != "" {
+		root = confkit.ResolvePath(cfg.BaseDir(), dir)
+	}
+	if root == "" {
+		return nil, errors.New("template rendering unavailable: template root is not resolved")
+	}
+	resp, err = renderNamedTemplate(os.DirFS(root), req, cfg.Templates.AllowedFuncs)
+	if err != nil {
+		return nil, err
+	}
+	l.Infof("template rendered name=%s version=%s tokens=%d", resp.Name, resp.Version, resp.Tokens)
+	return resp, nil
+}
+
+// renderNamedTemplate resolves req.Name inside fsys and renders it. Version
+// may be the template's Version header or a sha256 digest; either must match
+// the file on disk. With req.Spans set, the response also maps each
+// substituted value back to the template action that printed it. A
+// non-empty allowed restricts the functions the template may call.
+func renderNamedTemplate(fsys fs.FS, req *types.TemplateRenderRequest, allowed []string) (*types.TemplateRenderResponse, error) {
+	name := path.Clean(strings.TrimPrefix(strings.TrimSpace(req.Name), "/"))
+	if name == "." || name == "" {
+		return nil, errors.New("name is required")
+	}
+	if !strings.HasSuffix(name, templateExt) {
+		name += templateExt
+	}
+	if !fs.ValidPath(name) {
+		return nil, fmt.Errorf("invalid template name %q", req.Name)
+	}
+	if _, err := fs.Stat(fsys, name); err != nil {
+		return nil, fmt.Errorf("template %s not found", name)
+	}
+
+	var opts []llm.PromptTemplateOption
+	want := strings.TrimSpace(req.Version)
+	isDigest := isTemplateDigest(want)
+	if isDigest {
+		opts = append(opts, llm.WithPinnedDigest(want))
+	}
+	if len(allowed) > 0 {
+		opts = append(opts, llm.WithAllowedFuncs(allowed...))
+	}
+	version, _ := llm.ExtractTemplateVersionFS(fsys, name, 0)
+	if want != "" && !isDigest && want != version {
+		return nil, fmt.Errorf("template %s is version %q, not %q", name, version, want)
+	}
+
+	tmpl, err := llm.NewPromptTemplate(name, nil, append(opts, llm.WithFS(fsys))...)
+	if err != nil {
+		return nil, err
+	}
+	data := req.Data
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	resp := &types.TemplateRenderResponse{Name: name, Version: version, Digest: tmpl.Digest()}
+	if req.Spans {
+		out, spans, err := tmpl.RenderSpans(data)
+		if err != nil {
+			return nil, err
+		}
+		resp.Prompt = out
+		resp.Spans = make([]types.TemplateSpan, 0, len(spans))
+		for _, s := range spans {
+			resp.Spans = append(resp.Spans, types.TemplateSpan{Start: s.Start, End: s.End, Field: s.Field, Source: s.Source})
+		}
+	} else {
+		out, err := tmpl.Render(data)
+		if err != nil {
+			return nil, err
+		}
+		resp.Prompt = out
+	}
+	resp.Tokens = llm.EstimateTokens(resp.Prompt)
+	return resp, nil
+}
+
+// isTemplateDigest reports whether v names a sha256 digest rather than a
+// Version header.
+func isTemplateDigest(v string) bool {
+	d := llm.NormalizeDigest(v)
+	if strings.HasPrefix(strings.ToLower(v), "sha256:") {
+		return true
+	}
+	if len(d) != 64 {
+		return false
+	}
+	return strings.Trim(d, "0123456789abcdef") == ""
+}
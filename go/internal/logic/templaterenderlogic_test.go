@@ -0,0 +1,69 @@
+package logic
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/internal/types"
+	"nof0-api/pkg/llm"
+)
+
+func TestRenderNamedTemplate(t *testing.T) {
+	src := "{{/* Version: 1.2.0 */}}Trade {{ .symbol }} with {{ .equity }} USD"
+	fsys := fstest.MapFS{"prompts/trader.tmpl": {Data: []byte(src)}}
+
+	resp, err := renderNamedTemplate(fsys, &types.TemplateRenderRequest{
+		Name: "prompts/trader",
+		Data: map[string]interface{}{"symbol": "BTC", "equity": 1000},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "prompts/trader.tmpl", resp.Name)
+	assert.Equal(t, "1.2.0", resp.Version)
+	assert.Equal(t, "Trade BTC with 1000 USD", resp.Prompt)
+	assert.Equal(t, llm.DigestString(src), resp.Digest)
+	assert.Positive(t, resp.Tokens)
+	assert.Empty(t, resp.Spans)
+
+	traced, err := renderNamedTemplate(fsys, &types.TemplateRenderRequest{
+		Name:  "prompts/trader",
+		Data:  map[string]interface{}{"symbol": "BTC", "equity": 1000},
+		Spans: true,
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, resp.Prompt, traced.Prompt)
+	require.Len(t, traced.Spans, 2)
+	assert.Equal(t, types.TemplateSpan{Start: 6, End: 9, Field: ".symbol", Source: traced.Spans[0].Source}, traced.Spans[0])
+	assert.Equal(t, "1000", traced.Prompt[traced.Spans[1].Start:traced.Spans[1].End])
+
+	_, err = renderNamedTemplate(fsys, &types.TemplateRenderRequest{Name: "prompts/trader.tmpl", Version: "sha256:" + resp.Digest}, nil)
+	assert.NoError(t, err, "digest pins are accepted as versions")
+
+	_, err = renderNamedTemplate(fsys, &types.TemplateRenderRequest{Name: "prompts/trader", Version: "2.0.0"}, nil)
+	assert.ErrorContains(t, err, `is version "1.2.0", not "2.0.0"`)
+
+	_, err = renderNamedTemplate(fsys, &types.TemplateRenderRequest{Name: "../nof0.yaml"}, nil)
+	assert.ErrorContains(t, err, "invalid template name")
+
+	_, err = renderNamedTemplate(fsys, &types.TemplateRenderRequest{Name: "prompts/missing"}, nil)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestRenderNamedTemplateAllowedFuncs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"ok.tmpl":   {Data: []byte(`{{ if gt (len .items) 1 }}{{ printf "%d items" (len .items) }}{{ end }}`)},
+		"call.tmpl": {Data: []byte(`{{ call .fn }}`)},
+	}
+	data := map[string]interface{}{"items": []int{1, 2}}
+
+	resp, err := renderNamedTemplate(fsys, &types.TemplateRenderRequest{Name: "ok", Data: data}, llm.SafeBuiltinFuncs)
+	require.NoError(t, err)
+	assert.Equal(t, "2 items", resp.Prompt)
+
+	_, err = renderNamedTemplate(fsys, &types.TemplateRenderRequest{Name: "call"}, llm.SafeBuiltinFuncs)
+	assert.ErrorContains(t, err, `function "call" is not allowed`)
+	_, err = renderNamedTemplate(fsys, &types.TemplateRenderRequest{Name: "ok", Data: data}, []string{"len", "gt"})
+	assert.ErrorContains(t, err, `function "printf" is not allowed`)
+}
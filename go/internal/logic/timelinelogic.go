@@ -0,0 +1,142 @@
+package logic
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const maxTimelineLimit = 200
+
+// timelineStart is the keyset position before the newest possible entry.
+var timelineStart = timelineCursor{At: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC), ID: math.MaxInt64}
+
+type TimelineLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewTimelineLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TimelineLogic {
+	return &TimelineLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+type timelineRow struct {
+	ID         int64     `db:"id"`
+	TraceID    string    `db:"trace_id"`
+	Kind       string    `db:"kind"`
+	Symbol     string    `db:"symbol"`
+	Summary    string    `db:"summary"`
+	Detail     string    `db:"detail"`
+	OccurredAt time.Time `db:"occurred_at"`
+}
+
+// Timeline returns a model's prompt, decision, risk, order, fill and exit
+// entries newest first. NextCursor pages towards older entries and is empty
+// on the last page.
+func (l *TimelineLogic) Timeline(req *types.TimelineRequest) (resp *types.TimelineResponse, err error) {
+	modelID := strings.TrimSpace(req.ModelId)
+	if modelID == "" {
+		return nil, errors.New("modelId is required")
+	}
+	limit := req.Limit
+	if limit <= 0 || limit > maxTimelineLimit {
+		limit = maxTimelineLimit
+	}
+	cursor := timelineStart
+	if req.Cursor != "" {
+		if cursor, err = decodeTimelineCursor(req.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	resp = &types.TimelineResponse{
+		ModelId:    modelID,
+		Entries:    []types.TimelineEntry{},
+		ServerTime: time.Now().UnixMilli(),
+	}
+	if l.svcCtx.DBConn == nil {
+		return resp, nil
+	}
+	const query = `SELECT id, trace_id, kind, symbol, summary, detail::text AS detail, occurred_at
+FROM public.decision_timeline
+WHERE trader_id = $1
+  AND ($2 = '' OR symbol = $2)
+  AND ($3 = '' OR trace_id = $3)
+  AND (occurred_at, id) < ($4, $5)
+ORDER BY occurred_at DESC, id DESC
+LIMIT $6`
+	var rows []timelineRow
+	// Fetch one extra row to learn whether an older page exists.
+	err = l.svcCtx.DBConn.QueryRowsPartialCtx(l.ctx, &rows, query,
+		modelID, strings.ToUpper(strings.TrimSpace(req.Symbol)), strings.TrimSpace(req.TraceId),
+		cursor.At, cursor.ID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		resp.NextCursor = encodeTimelineCursor(timelineCursor{At: last.OccurredAt, ID: last.ID})
+	}
+	for _, row := range rows {
+		entry := types.TimelineEntry{
+			Id:         row.ID,
+			TraceId:    row.TraceID,
+			Kind:       row.Kind,
+			Symbol:     row.Symbol,
+			Summary:    row.Summary,
+			OccurredAt: row.OccurredAt.UnixMilli(),
+		}
+		if err := json.Unmarshal([]byte(row.Detail), &entry.Detail); err != nil {
+			l.Errorf("timeline: decode detail id=%d err=%v", row.ID, err)
+		}
+		resp.Entries = append(resp.Entries, entry)
+	}
+	return resp, nil
+}
+
+// timelineCursor is the keyset position of the last entry on a page.
+type timelineCursor struct {
+	At time.Time
+	ID int64
+}
+
+func encodeTimelineCursor(c timelineCursor) string {
+	raw := strconv.FormatInt(c.At.UnixMicro(), 10) + ":" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeTimelineCursor(s string) (timelineCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return timelineCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	atStr, idStr, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return timelineCursor{}, errors.New("invalid cursor")
+	}
+	micros, err := strconv.ParseInt(atStr, 10, 64)
+	if err != nil {
+		return timelineCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return timelineCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return timelineCursor{At: time.UnixMicro(micros).UTC(), ID: id}, nil
+}
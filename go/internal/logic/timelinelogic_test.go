@@ -0,0 +1,20 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimelineCursorRoundTrip(t *testing.T) {
+	c := timelineCursor{At: time.Date(2025, 3, 1, 12, 0, 0, 123456000, time.UTC), ID: 42}
+	got, err := decodeTimelineCursor(encodeTimelineCursor(c))
+	require.NoError(t, err)
+	assert.True(t, c.At.Equal(got.At))
+	assert.Equal(t, c.ID, got.ID)
+
+	_, err = decodeTimelineCursor("not-a-cursor")
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"nof0-api/internal/model"
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type TradeJournalEntryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewTradeJournalEntryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TradeJournalEntryLogic {
+	return &TradeJournalEntryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// TradeJournalEntry returns one journaled trade of a model including the raw
+// model answer that led to it.
+func (l *TradeJournalEntryLogic) TradeJournalEntry(req *types.TradeJournalEntryRequest) (resp *types.TradeJournalEntryResponse, err error) {
+	modelID := strings.TrimSpace(req.ModelId)
+	if modelID == "" {
+		return nil, errors.New("modelId is required")
+	}
+	if l.svcCtx.TradeJournalModel == nil {
+		return nil, errors.New("trade journal unavailable: database not configured")
+	}
+	row, err := l.svcCtx.TradeJournalModel.FindOne(l.ctx, req.Id)
+	if errors.Is(err, model.ErrNotFound) || (err == nil && row.TraderId != modelID) {
+		return nil, fmt.Errorf("journal entry %d not found for model %s", req.Id, modelID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &types.TradeJournalEntryResponse{
+		Entry:      toTradeJournalEntry(row, l.Logger),
+		ServerTime: time.Now().UnixMilli(),
+	}, nil
+}
@@ -0,0 +1,107 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"nof0-api/internal/model"
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const maxTradeJournalLimit = 200
+
+type TradeJournalLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewTradeJournalLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TradeJournalLogic {
+	return &TradeJournalLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// TradeJournal returns a model's journaled trades newest first without the
+// raw model answers, which the entry endpoint serves. NextBeforeId pages
+// towards older entries and is 0 on the last page.
+func (l *TradeJournalLogic) TradeJournal(req *types.TradeJournalRequest) (resp *types.TradeJournalResponse, err error) {
+	modelID := strings.TrimSpace(req.ModelId)
+	if modelID == "" {
+		return nil, errors.New("modelId is required")
+	}
+	limit := req.Limit
+	if limit <= 0 || limit > maxTradeJournalLimit {
+		limit = maxTradeJournalLimit
+	}
+	resp = &types.TradeJournalResponse{
+		ModelId:    modelID,
+		Entries:    []types.TradeJournalEntry{},
+		ServerTime: time.Now().UnixMilli(),
+	}
+	if l.svcCtx.TradeJournalModel == nil {
+		return resp, nil
+	}
+	// Fetch one extra row to learn whether an older page exists.
+	rows, err := l.svcCtx.TradeJournalModel.ListByTrader(l.ctx, modelID, strings.ToUpper(strings.TrimSpace(req.Symbol)), req.BeforeId, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		resp.NextBeforeId = rows[len(rows)-1].Id
+	}
+	for i := range rows {
+		entry := toTradeJournalEntry(&rows[i], l.Logger)
+		entry.RawResponse = ""
+		resp.Entries = append(resp.Entries, entry)
+	}
+	return resp, nil
+}
+
+// toTradeJournalEntry converts a journal row, logging JSON columns that fail
+// to decode instead of failing the request.
+func toTradeJournalEntry(row *model.TradeJournal, logger logx.Logger) types.TradeJournalEntry {
+	entry := types.TradeJournalEntry{
+		Id:               row.Id,
+		ModelId:          row.TraderId,
+		TraceId:          row.TraceId,
+		Symbol:           row.Symbol,
+		Side:             row.Side,
+		Status:           row.Status,
+		PromptDigest:     row.PromptDigest,
+		PromptVersion:    row.PromptVersion,
+		RawResponse:      row.RawResponse,
+		OpenOrderIds:     []string{},
+		EntryPrice:       row.EntryPrice,
+		EntrySize:        row.EntrySize,
+		EntrySlippageBps: row.EntrySlippageBps,
+		OpenedAt:         row.OpenedAt.UnixMilli(),
+		CloseOrderIds:    []string{},
+		CloseReason:      row.CloseReason,
+		ExitPrice:        row.ExitPrice.Float64,
+		ExitSlippageBps:  row.ExitSlippageBps.Float64,
+		RealizedPnl:      row.RealizedPnl.Float64,
+	}
+	if row.ClosedAt.Valid {
+		entry.ClosedAt = row.ClosedAt.Time.UnixMilli()
+	}
+	if err := json.Unmarshal([]byte(row.Decision), &entry.Decision); err != nil {
+		logger.Errorf("trade journal: decode decision id=%d err=%v", row.Id, err)
+	}
+	if err := json.Unmarshal([]byte(row.OpenOrderIds), &entry.OpenOrderIds); err != nil {
+		logger.Errorf("trade journal: decode open_order_ids id=%d err=%v", row.Id, err)
+	}
+	if err := json.Unmarshal([]byte(row.CloseOrderIds), &entry.CloseOrderIds); err != nil {
+		logger.Errorf("trade journal: decode close_order_ids id=%d err=%v", row.Id, err)
+	}
+	return entry
+}
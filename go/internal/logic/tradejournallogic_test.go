@@ -0,0 +1,84 @@
+package logic
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/internal/model"
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+)
+
+// fakeTradeJournalModel serves ListByTrader from rows held newest first.
+type fakeTradeJournalModel struct {
+	model.TradeJournalModel
+	rows []model.TradeJournal
+}
+
+func (f *fakeTradeJournalModel) ListByTrader(ctx context.Context, traderID, symbol string, beforeID int64, limit int) ([]model.TradeJournal, error) {
+	var out []model.TradeJournal
+	for _, row := range f.rows {
+		if row.TraderId != traderID || (symbol != "" && row.Symbol != symbol) || (beforeID > 0 && row.Id >= beforeID) {
+			continue
+		}
+		if len(out) == limit {
+			break
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+func journalRow(id int64, symbol string) model.TradeJournal {
+	return model.TradeJournal{
+		Id:            id,
+		TraderId:      "m1",
+		Symbol:        symbol,
+		Side:          "long",
+		Status:        "closed",
+		PromptDigest:  "abc",
+		RawResponse:   `{"decisions":[]}`,
+		Decision:      `{"symbol":"` + symbol + `","action":"open_long","reasoning":"breakout"}`,
+		OpenOrderIds:  `["101"]`,
+		CloseOrderIds: `["202"]`,
+		EntryPrice:    100,
+		OpenedAt:      time.UnixMilli(1_700_000_000_000),
+		RealizedPnl:   sql.NullFloat64{Float64: 12.5, Valid: true},
+		ClosedAt:      sql.NullTime{Time: time.UnixMilli(1_700_000_600_000), Valid: true},
+	}
+}
+
+func TestTradeJournalPagesNewestFirst(t *testing.T) {
+	fake := &fakeTradeJournalModel{rows: []model.TradeJournal{journalRow(3, "BTC"), journalRow(2, "ETH"), journalRow(1, "BTC")}}
+	l := NewTradeJournalLogic(context.Background(), &svc.ServiceContext{TradeJournalModel: fake})
+
+	resp, err := l.TradeJournal(&types.TradeJournalRequest{ModelId: "m1", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, resp.Entries, 2)
+	assert.Equal(t, int64(2), resp.NextBeforeId)
+	first := resp.Entries[0]
+	assert.Equal(t, "breakout", first.Decision["reasoning"])
+	assert.Equal(t, []string{"101"}, first.OpenOrderIds)
+	assert.Equal(t, []string{"202"}, first.CloseOrderIds)
+	assert.Equal(t, 12.5, first.RealizedPnl)
+	assert.Equal(t, int64(1_700_000_600_000), first.ClosedAt)
+	assert.Empty(t, first.RawResponse, "raw answers are served by the entry endpoint")
+
+	resp, err = l.TradeJournal(&types.TradeJournalRequest{ModelId: "m1", BeforeId: resp.NextBeforeId, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, resp.Entries, 1)
+	assert.Zero(t, resp.NextBeforeId)
+
+	resp, err = l.TradeJournal(&types.TradeJournalRequest{ModelId: "m1", Symbol: "eth"})
+	require.NoError(t, err)
+	require.Len(t, resp.Entries, 1)
+	assert.Equal(t, "ETH", resp.Entries[0].Symbol)
+
+	_, err = l.TradeJournal(&types.TradeJournalRequest{})
+	assert.Error(t, err)
+}
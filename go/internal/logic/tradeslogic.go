@@ -5,7 +5,10 @@ package logic
 
 import (
 	"context"
+	"sort"
+	"time"
 
+	"nof0-api/internal/model"
 	"nof0-api/internal/svc"
 	"nof0-api/internal/types"
 
@@ -26,6 +29,79 @@ func NewTradesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TradesLogi
 	}
 }
 
-func (l *TradesLogic) Trades() (resp *types.TradesResponse, err error) {
-	return l.svcCtx.DataLoader.LoadTrades()
+// Trades returns closed trades newest first by exit time, optionally for one
+// model and exit-time range. It reads the trades table when a database is
+// configured and the data files otherwise.
+func (l *TradesLogic) Trades(req *types.TradesRequest) (resp *types.TradesResponse, err error) {
+	page, err := parsePageRequest(req.ModelId, req.From, req.To, req.Limit, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if l.svcCtx.TradesModel != nil {
+		return l.tradesFromModel(page)
+	}
+	resp, err = l.svcCtx.DataLoader.LoadTrades()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(resp.Trades, func(i, j int) bool {
+		a, b := resp.Trades[i], resp.Trades[j]
+		if a.ExitTime != b.ExitTime {
+			return a.ExitTime > b.ExitTime
+		}
+		return a.Id > b.Id
+	})
+	resp.Trades, resp.NextCursor = pageInMemory(resp.Trades, page, func(t types.Trade) (string, int64, string) {
+		return t.ModelId, secondsToMillis(t.ExitTime), t.Id
+	})
+	return resp, nil
+}
+
+func (l *TradesLogic) tradesFromModel(page pageRequest) (*types.TradesResponse, error) {
+	q := model.TradePageQuery{
+		ModelID:  page.ModelID,
+		FromTsMs: page.From,
+		ToTsMs:   page.To,
+		// Fetch one extra row to learn whether an older page exists.
+		Limit: page.Limit + 1,
+	}
+	if page.Cursor != nil {
+		q.BeforeTsMs, q.BeforeID = page.Cursor.Ts, page.Cursor.ID
+	}
+	records, err := l.svcCtx.TradesModel.Page(l.ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	resp := &types.TradesResponse{Trades: make([]types.Trade, 0, len(records)), ServerTime: time.Now().UnixMilli()}
+	if len(records) > page.Limit {
+		records = records[:page.Limit]
+		last := records[len(records)-1]
+		resp.NextCursor = encodePageCursor(pageCursor{Ts: int64Value(last.ExitTsMs), ID: last.ID})
+	}
+	for _, rec := range records {
+		resp.Trades = append(resp.Trades, tradeFromRecord(rec))
+	}
+	return resp, nil
+}
+
+// tradeFromRecord maps a trades row onto the nof1 trade shape, whose times
+// are unix seconds.
+func tradeFromRecord(rec model.TradeRecord) types.Trade {
+	return types.Trade{
+		Id:             rec.ID,
+		ModelId:        rec.ModelID,
+		Symbol:         rec.Symbol,
+		Side:           rec.Side,
+		TradeId:        rec.ID,
+		Quantity:       floatValue(rec.Quantity),
+		Leverage:       floatValue(rec.Leverage),
+		Confidence:     floatValue(rec.Confidence),
+		EntryPrice:     floatValue(rec.EntryPrice),
+		EntryTime:      float64(rec.EntryTsMs) / 1000,
+		EntrySz:        floatValue(rec.EntrySz),
+		ExitPrice:      floatValue(rec.ExitPrice),
+		ExitTime:       float64(int64Value(rec.ExitTsMs)) / 1000,
+		ExitSz:         floatValue(rec.Quantity),
+		RealizedNetPnl: floatValue(rec.RealizedNetPnl),
+	}
 }
@@ -0,0 +1,68 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/internal/types"
+)
+
+func TestTradesPagesNewestFirst(t *testing.T) {
+	l := NewTradesLogic(context.Background(), createTestServiceContext(t))
+
+	all, err := l.Trades(&types.TradesRequest{ModelId: "gpt-5"})
+	require.NoError(t, err)
+	require.Greater(t, len(all.Trades), 3)
+	assert.Empty(t, all.NextCursor)
+
+	first, err := l.Trades(&types.TradesRequest{ModelId: "gpt-5", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first.Trades, 2)
+	require.NotEmpty(t, first.NextCursor)
+	second, err := l.Trades(&types.TradesRequest{ModelId: "gpt-5", Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, second.Trades, 2)
+	assert.Equal(t, all.Trades[:4], append(first.Trades, second.Trades...), "pages continue where the last one ended")
+	for i := 1; i < len(all.Trades); i++ {
+		assert.GreaterOrEqual(t, all.Trades[i-1].ExitTime, all.Trades[i].ExitTime)
+	}
+
+	from := secondsToMillis(all.Trades[2].ExitTime)
+	ranged, err := l.Trades(&types.TradesRequest{ModelId: "gpt-5", From: from})
+	require.NoError(t, err)
+	for _, tr := range ranged.Trades {
+		assert.Equal(t, "gpt-5", tr.ModelId)
+		assert.GreaterOrEqual(t, secondsToMillis(tr.ExitTime), from)
+	}
+
+	_, err = l.Trades(&types.TradesRequest{From: 2, To: 1})
+	assert.ErrorContains(t, err, "from must be before to")
+	_, err = l.Trades(&types.TradesRequest{Cursor: "%%"})
+	assert.Error(t, err)
+}
+
+func TestAccountSnapshotLatestAndHistory(t *testing.T) {
+	l := NewAccountSnapshotLogic(context.Background(), createTestServiceContext(t))
+
+	latest, err := l.AccountSnapshot(&types.AccountSnapshotRequest{Latest: true})
+	require.NoError(t, err)
+	seen := map[string]bool{}
+	for _, s := range latest.Snapshots {
+		assert.False(t, seen[s.ModelId], "one snapshot per model")
+		seen[s.ModelId] = true
+	}
+	assert.Contains(t, seen, "gpt-5")
+
+	history, err := l.AccountSnapshot(&types.AccountSnapshotRequest{ModelId: "gpt-5", Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, history.Snapshots, 5)
+	assert.NotEmpty(t, history.NextCursor)
+	for _, s := range latest.Snapshots {
+		if s.ModelId == "gpt-5" {
+			assert.Equal(t, s, history.Snapshots[0], "the newest history entry is the latest snapshot")
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	widgetMinSize  = 80
+	widgetMaxSize  = 1200
+	widgetPadding  = 8
+	widgetHeaderPx = 18
+)
+
+type widgetTheme struct {
+	background, text, up, down string
+}
+
+var widgetThemes = map[string]widgetTheme{
+	"light": {background: "#ffffff", text: "#1f2937", up: "#16a34a", down: "#dc2626"},
+	"dark":  {background: "#111827", text: "#e5e7eb", up: "#22c55e", down: "#f87171"},
+}
+
+type WidgetEquityLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewWidgetEquityLogic(ctx context.Context, svcCtx *svc.ServiceContext) *WidgetEquityLogic {
+	return &WidgetEquityLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// WidgetEquity renders a model's equity curve as a standalone SVG sparkline.
+// The output depends only on the data, so identical curves share an ETag.
+func (l *WidgetEquityLogic) WidgetEquity(req *types.WidgetEquityRequest) ([]byte, error) {
+	modelID := strings.TrimSpace(req.ModelId)
+	if modelID == "" {
+		return nil, errors.New("modelId is required")
+	}
+	totals, err := l.svcCtx.DataLoader.LoadAccountTotals()
+	if err != nil {
+		return nil, err
+	}
+	curve := sharedEquityCurve(totals.AccountTotals, modelID)
+	return renderEquitySVG(modelID, curve, clampWidgetSize(req.Width), clampWidgetSize(req.Height), req.Theme), nil
+}
+
+func clampWidgetSize(v int) int {
+	if v < widgetMinSize {
+		return widgetMinSize
+	}
+	if v > widgetMaxSize {
+		return widgetMaxSize
+	}
+	return v
+}
+
+func renderEquitySVG(modelID string, curve []types.SharedEquityPoint, width, height int, theme string) []byte {
+	th, ok := widgetThemes[theme]
+	if !ok {
+		th = widgetThemes["light"]
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" role="img">`, width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" rx="6" fill="%s"/>`, th.background)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-family="sans-serif" font-size="12" fill="%s">%s</text>`,
+		widgetPadding, widgetPadding+11, th.text, html.EscapeString(modelID))
+
+	if len(curve) == 0 {
+		fmt.Fprintf(&b, `<text x="50%%" y="55%%" text-anchor="middle" font-family="sans-serif" font-size="11" fill="%s">no data</text></svg>`, th.text)
+		return b.Bytes()
+	}
+
+	last := curve[len(curve)-1]
+	color := th.up
+	if last.DollarEquity < curve[0].DollarEquity {
+		color = th.down
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end" font-family="sans-serif" font-size="12" fill="%s">%+.2f%%</text>`,
+		width-widgetPadding, widgetPadding+11, color, last.CumPnlPct)
+
+	lo, hi := curve[0].DollarEquity, curve[0].DollarEquity
+	for _, p := range curve {
+		lo = min(lo, p.DollarEquity)
+		hi = max(hi, p.DollarEquity)
+	}
+	top := float64(widgetPadding + widgetHeaderPx)
+	plotW := float64(width - 2*widgetPadding)
+	plotH := float64(height-widgetPadding) - top
+	points := make([]string, 0, len(curve))
+	for i, p := range curve {
+		x := float64(widgetPadding) + plotW/2
+		if len(curve) > 1 {
+			x = float64(widgetPadding) + plotW*float64(i)/float64(len(curve)-1)
+		}
+		y := top + plotH/2
+		if hi > lo {
+			y = top + plotH*(hi-p.DollarEquity)/(hi-lo)
+		}
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="1.5" stroke-linejoin="round" points="%s"/></svg>`,
+		color, strings.Join(points, " "))
+	return b.Bytes()
+}
@@ -0,0 +1,66 @@
+package logic
+
+import (
+	"context"
+	"sort"
+
+	"nof0-api/internal/svc"
+	"nof0-api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const maxWidgetLeaderboard = 50
+
+type WidgetLeaderboardLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewWidgetLeaderboardLogic(ctx context.Context, svcCtx *svc.ServiceContext) *WidgetLeaderboardLogic {
+	return &WidgetLeaderboardLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// WidgetLeaderboard returns the top models by return. It carries no server
+// timestamp so the body, and therefore its ETag, only changes with the data.
+func (l *WidgetLeaderboardLogic) WidgetLeaderboard(req *types.WidgetLeaderboardRequest) (*types.WidgetLeaderboardResponse, error) {
+	board, err := l.svcCtx.DataLoader.LoadLeaderboard()
+	if err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit <= 0 || limit > maxWidgetLeaderboard {
+		limit = maxWidgetLeaderboard
+	}
+	return &types.WidgetLeaderboardResponse{Entries: rankLeaderboard(board.Leaderboard, limit)}, nil
+}
+
+func rankLeaderboard(board []types.LeaderboardEntry, limit int) []types.WidgetLeaderboardEntry {
+	sorted := append([]types.LeaderboardEntry(nil), board...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].ReturnPct != sorted[j].ReturnPct {
+			return sorted[i].ReturnPct > sorted[j].ReturnPct
+		}
+		return sorted[i].Id < sorted[j].Id
+	})
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	out := make([]types.WidgetLeaderboardEntry, 0, len(sorted))
+	for i, e := range sorted {
+		out = append(out, types.WidgetLeaderboardEntry{
+			Rank:      i + 1,
+			ModelId:   e.Id,
+			ReturnPct: e.ReturnPct,
+			Equity:    e.Equity,
+			Sharpe:    e.Sharpe,
+			NumTrades: e.NumTrades,
+		})
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"nof0-api/internal/types"
+)
+
+func TestRenderEquitySVG(t *testing.T) {
+	curve := []types.SharedEquityPoint{
+		{Timestamp: 1, DollarEquity: 10000, CumPnlPct: 0},
+		{Timestamp: 2, DollarEquity: 10500, CumPnlPct: 5},
+		{Timestamp: 3, DollarEquity: 9800, CumPnlPct: -2},
+	}
+	svg := string(renderEquitySVG("gpt<5>", curve, 200, 100, "dark"))
+	assert.Contains(t, svg, `width="200" height="100"`)
+	assert.Contains(t, svg, "gpt&lt;5&gt;")
+	assert.Contains(t, svg, "-2.00%")
+	assert.Contains(t, svg, `stroke="#f87171"`)
+	assert.Contains(t, svg, `points="8.0,73.1 100.0,26.0 192.0,92.0"`)
+	assert.Equal(t, svg, string(renderEquitySVG("gpt<5>", curve, 200, 100, "dark")))
+
+	empty := string(renderEquitySVG("m", nil, 200, 100, "bogus"))
+	assert.Contains(t, empty, "no data")
+	assert.Contains(t, empty, `fill="#ffffff"`)
+}
+
+func TestRankLeaderboard(t *testing.T) {
+	board := []types.LeaderboardEntry{
+		{Id: "b", ReturnPct: 3},
+		{Id: "a", ReturnPct: 12},
+		{Id: "c", ReturnPct: 3},
+	}
+	got := rankLeaderboard(board, 2)
+	assert.Equal(t, []types.WidgetLeaderboardEntry{
+		{Rank: 1, ModelId: "a", ReturnPct: 12},
+		{Rank: 2, ModelId: "b", ReturnPct: 3},
+	}, got)
+	assert.Equal(t, "b", board[0].Id, "input order is preserved")
+}
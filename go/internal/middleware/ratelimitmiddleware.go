@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"container/list"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitMiddleware caps requests per client IP in fixed windows. Counters
+// live in process memory, so each API instance enforces its own quota.
+type RateLimitMiddleware struct {
+	quota      int
+	period     time.Duration
+	trustProxy bool
+	maxClients int
+	now        func() time.Time
+	mu         sync.Mutex
+	windows    map[string]*rateWindow
+	// order holds the windows oldest first: expired windows and eviction
+	// candidates sit at the front.
+	order *list.List
+}
+
+type rateWindow struct {
+	client string
+	start  time.Time
+	count  int
+}
+
+// defaultRateLimitClients bounds the windows kept when RateLimitOptions
+// leaves MaxClients unset.
+const defaultRateLimitClients = 10000
+
+// RateLimitOptions tunes how clients are identified and tracked.
+type RateLimitOptions struct {
+	// TrustForwardedFor keys clients by the first X-Forwarded-For hop instead
+	// of the connection's address. Enable it only behind a reverse proxy that
+	// overwrites the header; otherwise every client can pick its own key.
+	TrustForwardedFor bool
+	// MaxClients caps the windows kept in memory; when full, the oldest
+	// window is dropped for a new client. Non-positive uses 10000.
+	MaxClients int
+}
+
+// NewRateLimitMiddleware allows quota requests per client per period. A
+// non-positive quota or period disables limiting.
+func NewRateLimitMiddleware(quota int, period time.Duration, opts RateLimitOptions) *RateLimitMiddleware {
+	maxClients := opts.MaxClients
+	if maxClients <= 0 {
+		maxClients = defaultRateLimitClients
+	}
+	return &RateLimitMiddleware{
+		quota:      quota,
+		period:     period,
+		trustProxy: opts.TrustForwardedFor,
+		maxClients: maxClients,
+		now:        time.Now,
+		windows:    make(map[string]*rateWindow),
+		order:      list.New(),
+	}
+}
+
+func (m *RateLimitMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if m.quota <= 0 || m.period <= 0 {
+			next(w, r)
+			return
+		}
+		if retry, ok := m.allow(clientIP(r, m.trustProxy)); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// allow counts a request from client and, when over quota, reports how long
+// until its window resets.
+func (m *RateLimitMiddleware) allow(client string) (time.Duration, bool) {
+	now := m.now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for e := m.order.Front(); e != nil; e = m.order.Front() {
+		if now.Sub(e.Value.(*rateWindow).start) < m.period {
+			break
+		}
+		m.drop(e)
+	}
+	win, ok := m.windows[client]
+	if !ok {
+		if len(m.windows) >= m.maxClients {
+			m.drop(m.order.Front())
+		}
+		win = &rateWindow{client: client, start: now}
+		m.order.PushBack(win)
+		m.windows[client] = win
+	}
+	if win.count >= m.quota {
+		return win.start.Add(m.period).Sub(now), false
+	}
+	win.count++
+	return 0, true
+}
+
+func (m *RateLimitMiddleware) drop(e *list.Element) {
+	m.order.Remove(e)
+	delete(m.windows, e.Value.(*rateWindow).client)
+}
+
+// clientIP is the connection's address, or with trustProxy the first
+// X-Forwarded-For hop set by the reverse proxy.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if fwd := r.Header.Get("X-Forwarded-For"); trustProxy && fwd != "" {
+		first, _, _ := strings.Cut(fwd, ",")
+		if ip := strings.TrimSpace(first); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := NewRateLimitMiddleware(2, time.Minute, RateLimitOptions{})
+	m.now = func() time.Time { return now }
+	serve := func(m *RateLimitMiddleware, remote, forwarded string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/widget/equity.svg", nil)
+		req.RemoteAddr = remote
+		if forwarded != "" {
+			req.Header.Set("X-Forwarded-For", forwarded)
+		}
+		rec := httptest.NewRecorder()
+		m.Handle(noContent)(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve(m, "10.0.0.1:1234", "").Code)
+	assert.Equal(t, http.StatusNoContent, serve(m, "10.0.0.1:5678", "").Code)
+	limited := serve(m, "10.0.0.1:1234", "")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, "60", limited.Header().Get("Retry-After"))
+
+	// Without a trusted proxy a client cannot pick its own window.
+	assert.Equal(t, http.StatusTooManyRequests, serve(m, "10.0.0.1:1234", "203.0.113.7").Code)
+	assert.Equal(t, http.StatusNoContent, serve(m, "10.0.0.2:1234", "").Code)
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, http.StatusNoContent, serve(m, "10.0.0.1:1234", "").Code)
+
+	// Behind a trusted proxy clients are keyed by the forwarded address.
+	proxied := NewRateLimitMiddleware(1, time.Minute, RateLimitOptions{TrustForwardedFor: true})
+	assert.Equal(t, http.StatusNoContent, serve(proxied, "10.0.0.9:80", "203.0.113.7, 10.0.0.9").Code)
+	assert.Equal(t, http.StatusNoContent, serve(proxied, "10.0.0.9:80", "203.0.113.8").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(proxied, "10.0.0.9:80", "203.0.113.7").Code)
+
+	unlimited := NewRateLimitMiddleware(0, time.Minute, RateLimitOptions{})
+	for i := 0; i < 5; i++ {
+		rec := httptest.NewRecorder()
+		unlimited.Handle(noContent)(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	}
+}
+
+func TestRateLimitMiddlewareBoundsClients(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := NewRateLimitMiddleware(1, time.Minute, RateLimitOptions{MaxClients: 2})
+	m.now = func() time.Time { return now }
+
+	_, ok := m.allow("a")
+	assert.True(t, ok)
+	now = now.Add(time.Second)
+	_, ok = m.allow("b")
+	assert.True(t, ok)
+	_, ok = m.allow("a")
+	assert.False(t, ok)
+
+	// A third client evicts the oldest window, which resets a.
+	now = now.Add(time.Second)
+	_, ok = m.allow("c")
+	assert.True(t, ok)
+	assert.Len(t, m.windows, 2)
+	assert.Equal(t, 2, m.order.Len())
+	_, ok = m.allow("b")
+	assert.False(t, ok, "b keeps its window")
+
+	// Expired windows are dropped before anything is evicted.
+	now = now.Add(time.Minute)
+	_, ok = m.allow("d")
+	assert.True(t, ok)
+	assert.Len(t, m.windows, 1)
+}
+
+func noContent(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) }
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// TokenAuthMiddleware guards routes with a shared bearer token. Browsers'
+// EventSource cannot set headers, so the token may also be passed as the
+// "token" query parameter.
+type TokenAuthMiddleware struct {
+	token   string
+	setting string
+}
+
+// NewTokenAuthMiddleware accepts requests bearing token. setting names the
+// config key in the error returned while the token is unset.
+func NewTokenAuthMiddleware(token, setting string) *TokenAuthMiddleware {
+	return &TokenAuthMiddleware{token: strings.TrimSpace(token), setting: setting}
+}
+
+func (m *TokenAuthMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if m.token == "" {
+			http.Error(w, "disabled: "+m.setting+" is not configured", http.StatusServiceUnavailable)
+			return
+		}
+		got := r.URL.Query().Get("token")
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			got = strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+		}
+		if subtle.ConstantTimeCompare([]byte(got), []byte(m.token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenAuthMiddleware(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	serve := func(m *TokenAuthMiddleware, target, auth string) int {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		m.Handle(ok)(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, serve(NewTokenAuthMiddleware("", "LogStream.Token"), "/api/logs/stream", "Bearer x"))
+
+	m := NewTokenAuthMiddleware("s3cret", "LogStream.Token")
+	assert.Equal(t, http.StatusUnauthorized, serve(m, "/api/logs/stream", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve(m, "/api/logs/stream", "Bearer wrong"))
+	assert.Equal(t, http.StatusNoContent, serve(m, "/api/logs/stream", "Bearer s3cret"))
+	assert.Equal(t, http.StatusNoContent, serve(m, "/api/logs/stream?token=s3cret", ""))
+	// A header, when present, takes precedence over the query parameter.
+	assert.Equal(t, http.StatusUnauthorized, serve(m, "/api/logs/stream?token=s3cret", "Bearer wrong"))
+}
@@ -26,12 +26,26 @@ type AccountSnapshot struct {
 	Metadata                   string
 }
 
+// AccountSnapshotPageQuery selects equity snapshots newest first. Zero fields
+// do not filter; BeforeTsMs and BeforeModelID are the timestamp and model of
+// the previous page's last snapshot.
+type AccountSnapshotPageQuery struct {
+	ModelID       string
+	FromTsMs      int64
+	ToTsMs        int64
+	BeforeTsMs    int64
+	BeforeModelID string
+	Limit         int
+}
+
 type (
 	// AccountEquitySnapshotsModel is an interface to be customized, add more methods here,
 	// and implement the added methods in customAccountEquitySnapshotsModel.
 	AccountEquitySnapshotsModel interface {
 		accountEquitySnapshotsModel
 		LatestSnapshots(ctx context.Context, modelIDs []string) (map[string]AccountSnapshot, error)
+		History(ctx context.Context, q AccountSnapshotPageQuery) ([]AccountSnapshot, error)
+		AsOf(ctx context.Context, modelID string, tsMs int64) (*AccountSnapshot, error)
 	}
 
 	customAccountEquitySnapshotsModel struct {
@@ -88,6 +102,78 @@ ORDER BY model_id, ts_ms DESC`
 	return result, nil
 }
 
+// History returns one page of snapshots taken in [FromTsMs, ToTsMs),
+// ordered by timestamp then model id descending. Limit defaults to 500 when
+// non-positive.
+func (m *customAccountEquitySnapshotsModel) History(ctx context.Context, q AccountSnapshotPageQuery) ([]AccountSnapshot, error) {
+	if q.Limit <= 0 {
+		q.Limit = 500
+	}
+
+	const query = `
+SELECT
+    model_id,
+    ts_ms,
+    dollar_equity,
+    realized_pnl,
+    total_unrealized_pnl,
+    cum_pnl_pct,
+    sharpe_ratio,
+    since_inception_hourly_marker,
+    since_inception_minute_marker,
+    metadata
+FROM public.account_equity_snapshots
+WHERE ($1 = '' OR model_id = $1)
+  AND ($2::bigint = 0 OR ts_ms >= $2)
+  AND ($3::bigint = 0 OR ts_ms < $3)
+  AND ($4::bigint = 0 OR (ts_ms, model_id) < ($4, $5))
+ORDER BY ts_ms DESC, model_id DESC
+LIMIT $6`
+
+	var rows []AccountEquitySnapshots
+	if err := m.QueryRowsNoCacheCtx(ctx, &rows, query, q.ModelID, q.FromTsMs, q.ToTsMs, q.BeforeTsMs, q.BeforeModelID, q.Limit); err != nil {
+		return nil, fmt.Errorf("accountEquitySnapshots.History query: %w", err)
+	}
+
+	result := make([]AccountSnapshot, 0, len(rows))
+	for i := range rows {
+		result = append(result, buildAccountSnapshot(&rows[i]))
+	}
+	return result, nil
+}
+
+// AsOf returns the model's newest snapshot taken at or before tsMs, or nil
+// when there is none.
+func (m *customAccountEquitySnapshotsModel) AsOf(ctx context.Context, modelID string, tsMs int64) (*AccountSnapshot, error) {
+	const query = `
+SELECT
+    model_id,
+    ts_ms,
+    dollar_equity,
+    realized_pnl,
+    total_unrealized_pnl,
+    cum_pnl_pct,
+    sharpe_ratio,
+    since_inception_hourly_marker,
+    since_inception_minute_marker,
+    metadata
+FROM public.account_equity_snapshots
+WHERE model_id = $1
+  AND ts_ms <= $2
+ORDER BY ts_ms DESC
+LIMIT 1`
+
+	var rows []AccountEquitySnapshots
+	if err := m.QueryRowsNoCacheCtx(ctx, &rows, query, modelID, tsMs); err != nil {
+		return nil, fmt.Errorf("accountEquitySnapshots.AsOf query: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	snapshot := buildAccountSnapshot(&rows[0])
+	return &snapshot, nil
+}
+
 func buildAccountSnapshot(row *AccountEquitySnapshots) AccountSnapshot {
 	snapshot := AccountSnapshot{
 		ModelID:            row.ModelId,
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+var _ AccountSnapshotsModel = (*customAccountSnapshotsModel)(nil)
+
+type (
+	// AccountSnapshotsModel is an interface to be customized, add more methods here,
+	// and implement the added methods in customAccountSnapshotsModel.
+	AccountSnapshotsModel interface {
+		accountSnapshotsModel
+		Upsert(ctx context.Context, row *AccountSnapshots) error
+		ListSince(ctx context.Context, provider string, since time.Time) ([]*AccountSnapshots, error)
+		Latest(ctx context.Context, provider string) (*AccountSnapshots, error)
+	}
+
+	customAccountSnapshotsModel struct {
+		*defaultAccountSnapshotsModel
+	}
+)
+
+// NewAccountSnapshotsModel returns a model for the database table.
+func NewAccountSnapshotsModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Option) AccountSnapshotsModel {
+	return &customAccountSnapshotsModel{
+		defaultAccountSnapshotsModel: newAccountSnapshotsModel(conn, c, opts...),
+	}
+}
+
+// Upsert writes row, replacing the detail of a snapshot already stored for
+// the same provider and event time.
+func (m *customAccountSnapshotsModel) Upsert(ctx context.Context, row *AccountSnapshots) error {
+	query := fmt.Sprintf(`
+INSERT INTO %s (provider, is_trader, detail, event_at)
+VALUES ($1, $2, $3::jsonb, $4)
+ON CONFLICT (provider, event_at) DO UPDATE
+SET is_trader = EXCLUDED.is_trader,
+    detail = EXCLUDED.detail,
+    updated_at = NOW()`, m.tableName())
+	_, err := m.ExecNoCacheCtx(ctx, query, row.Provider, row.IsTrader, jsonOrDefault(row.Detail, "{}"), row.EventAt)
+	return err
+}
+
+// ListSince returns the snapshots of provider taken at or after since,
+// oldest first.
+func (m *customAccountSnapshotsModel) ListSince(ctx context.Context, provider string, since time.Time) ([]*AccountSnapshots, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE provider = $1 AND event_at >= $2 ORDER BY event_at`, accountSnapshotsRows, m.tableName())
+	var rows []*AccountSnapshots
+	if err := m.QueryRowsNoCacheCtx(ctx, &rows, query, provider, since); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Latest returns the newest snapshot of provider, or ErrNotFound.
+func (m *customAccountSnapshotsModel) Latest(ctx context.Context, provider string) (*AccountSnapshots, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE provider = $1 ORDER BY event_at DESC LIMIT 1`, accountSnapshotsRows, m.tableName())
+	var row AccountSnapshots
+	if err := m.QueryRowNoCacheCtx(ctx, &row, query, provider); err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"context"
+	"fmt"
+)
+
+// bulkCacheChunk bounds the keys removed per cache round trip by bulk writes.
+const bulkCacheChunk = 500
+
+type cacheBypassKey struct{}
+
+// WithCacheBypass marks ctx so bulk writes under it skip row cache
+// invalidation. Backfills write rows nobody has looked up by key yet, so the
+// deletes would cost Redis round trips for nothing; a not-found placeholder
+// cached for one of those keys expires on its own.
+func WithCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+// CacheBypassed reports whether ctx was marked by WithCacheBypass.
+func CacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
+
+// cacheKeys collects the distinct row cache keys touched by a bulk write.
+type cacheKeys struct {
+	seen map[string]struct{}
+	keys []string
+}
+
+func (c *cacheKeys) add(prefix string, parts ...any) {
+	key := prefix
+	for i, p := range parts {
+		if i > 0 {
+			key += ":"
+		}
+		key += fmt.Sprint(p)
+	}
+	if c.seen == nil {
+		c.seen = make(map[string]struct{})
+	}
+	if _, ok := c.seen[key]; ok {
+		return
+	}
+	c.seen[key] = struct{}{}
+	c.keys = append(c.keys, key)
+}
+
+// invalidate removes the collected keys with one delete per chunk instead of
+// one per row, unless ctx bypasses the cache.
+func (c *cacheKeys) invalidate(ctx context.Context, del func(context.Context, ...string) error) error {
+	if CacheBypassed(ctx) {
+		return nil
+	}
+	for start := 0; start < len(c.keys); start += bulkCacheChunk {
+		end := min(start+bulkCacheChunk, len(c.keys))
+		if err := del(ctx, c.keys[start:end]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheKeysInvalidateInChunks(t *testing.T) {
+	openTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var keys cacheKeys
+	keys.add("cache:klines:symbolId:interval:openTime:", "hl/BTC", "1m", openTime)
+	keys.add("cache:klines:symbolId:interval:openTime:", "hl/BTC", "1m", openTime)
+	for id := 0; id < bulkCacheChunk+10; id++ {
+		keys.add("cache:klines:id:", id)
+	}
+	require.Len(t, keys.keys, bulkCacheChunk+11, "duplicates are dropped")
+	assert.Equal(t, fmt.Sprintf("cache:klines:symbolId:interval:openTime:hl/BTC:1m:%v", openTime), keys.keys[0])
+
+	var calls [][]string
+	del := func(ctx context.Context, ks ...string) error {
+		calls = append(calls, ks)
+		return nil
+	}
+	require.NoError(t, keys.invalidate(context.Background(), del))
+	require.Len(t, calls, 2)
+	assert.Len(t, calls[0], bulkCacheChunk)
+	assert.Len(t, calls[1], 11)
+
+	calls = nil
+	ctx := WithCacheBypass(context.Background())
+	assert.True(t, CacheBypassed(ctx))
+	require.NoError(t, keys.invalidate(ctx, del))
+	assert.Empty(t, calls)
+}
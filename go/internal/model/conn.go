@@ -0,0 +1,260 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+// PoolConf tunes one Postgres connection pool behind the models.
+type PoolConf struct {
+	// Name labels the pool in slow query logs.
+	Name        string
+	MaxOpen     int
+	MaxIdle     int
+	MaxLifetime time.Duration
+	MaxIdleTime time.Duration
+	// StatementTimeout makes the server cancel statements running longer
+	// than this; 0 leaves the server default.
+	StatementTimeout time.Duration
+	// SlowQuery logs statements taking at least this long with their
+	// fingerprint; 0 disables the log.
+	SlowQuery time.Duration
+}
+
+// NewPostgresConn opens a pgx pool for dsn tuned by c and returns it as a
+// SqlConn for the models together with the underlying *sql.DB.
+func NewPostgresConn(dsn string, c PoolConf) (sqlx.SqlConn, *sql.DB, error) {
+	cfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse postgres dsn: %w", err)
+	}
+	if c.StatementTimeout > 0 {
+		cfg.RuntimeParams["statement_timeout"] = strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10)
+	}
+	var connector driver.Connector = stdlib.GetConnector(*cfg)
+	if c.SlowQuery > 0 {
+		connector = &slowQueryConnector{Connector: connector, pool: c.Name, threshold: c.SlowQuery}
+	}
+	db := sql.OpenDB(connector)
+	if c.MaxOpen > 0 {
+		db.SetMaxOpenConns(c.MaxOpen)
+	}
+	if c.MaxIdle > 0 {
+		db.SetMaxIdleConns(c.MaxIdle)
+	}
+	if c.MaxLifetime > 0 {
+		db.SetConnMaxLifetime(c.MaxLifetime)
+	} else {
+		db.SetConnMaxLifetime(5 * time.Minute)
+	}
+	if c.MaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(c.MaxIdleTime)
+	}
+	return sqlx.NewSqlConnFromDB(db), db, nil
+}
+
+// QueryFingerprint normalises query so statements differing only in
+// literals, placeholders, IN-list length or whitespace share a fingerprint.
+// It returns the fingerprint and the normalised text.
+func QueryFingerprint(query string) (string, string) {
+	var b strings.Builder
+	b.Grow(len(query))
+	rs := []rune(query)
+	space := false
+	emit := func(s string) {
+		if space && b.Len() > 0 {
+			if last := b.String()[b.Len()-1]; last != '(' && last != ',' {
+				b.WriteByte(' ')
+			}
+		}
+		space = false
+		b.WriteString(s)
+	}
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+		case r == '-' && i+1 < len(rs) && rs[i+1] == '-':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+			space = true
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			i += 2
+			for i+1 < len(rs) && !(rs[i] == '*' && rs[i+1] == '/') {
+				i++
+			}
+			i++
+			space = true
+		case r == '\'':
+			for i++; i < len(rs); i++ {
+				if rs[i] == '\'' {
+					if i+1 < len(rs) && rs[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			emit("?")
+		case r == '$' && i+1 < len(rs) && unicode.IsDigit(rs[i+1]):
+			for i+1 < len(rs) && unicode.IsDigit(rs[i+1]) {
+				i++
+			}
+			emit("?")
+		case unicode.IsDigit(r) && !identChar(prevRune(rs, i)):
+			for i+1 < len(rs) && (unicode.IsDigit(rs[i+1]) || rs[i+1] == '.') {
+				i++
+			}
+			emit("?")
+		case r == '(':
+			emit("(")
+		case r == ')' || r == ',':
+			// List punctuation binds to its neighbours so lists normalise alike.
+			space = false
+			b.WriteRune(r)
+		default:
+			start := i
+			for i+1 < len(rs) && !unicode.IsSpace(rs[i+1]) && !strings.ContainsRune("(),'", rs[i+1]) &&
+				!(rs[i+1] == '$' && i+2 < len(rs) && unicode.IsDigit(rs[i+2])) {
+				i++
+			}
+			emit(strings.ToLower(string(rs[start : i+1])))
+		}
+	}
+	normalized := collapseLists(b.String())
+	h := fnv.New64a()
+	h.Write([]byte(normalized))
+	return fmt.Sprintf("%016x", h.Sum64()), normalized
+}
+
+// collapseLists rewrites runs like (?,?,?) to (?+).
+func collapseLists(s string) string {
+	for {
+		i := strings.Index(s, "?,?")
+		if i < 0 {
+			return s
+		}
+		j := i + 3
+		for strings.HasPrefix(s[j:], ",?") {
+			j += 2
+		}
+		s = s[:i] + "?+" + s[j:]
+	}
+}
+
+func prevRune(rs []rune, i int) rune {
+	if i == 0 {
+		return ' '
+	}
+	return rs[i-1]
+}
+
+func identChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// slowQueryConnector times statements run directly on pooled connections,
+// which covers every model query and transaction statement.
+type slowQueryConnector struct {
+	driver.Connector
+	pool      string
+	threshold time.Duration
+}
+
+func (c *slowQueryConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &slowQueryConn{Conn: conn, c: c}, nil
+}
+
+func (c *slowQueryConnector) observe(ctx context.Context, query string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	if elapsed < c.threshold {
+		return
+	}
+	fingerprint, normalized := QueryFingerprint(query)
+	status := "ok"
+	if err != nil {
+		status = err.Error()
+	}
+	logx.WithContext(ctx).WithDuration(elapsed).Slowf("[SQL] slow query pool=%s fingerprint=%s status=%s - %s",
+		c.pool, fingerprint, status, normalized)
+}
+
+type slowQueryConn struct {
+	driver.Conn
+	c *slowQueryConnector
+}
+
+func (s *slowQueryConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := s.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, query, args)
+	s.c.observe(ctx, query, start, err)
+	return rows, err
+}
+
+func (s *slowQueryConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := s.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	res, err := e.ExecContext(ctx, query, args)
+	s.c.observe(ctx, query, start, err)
+	return res, err
+}
+
+func (s *slowQueryConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := s.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return s.Conn.Prepare(query)
+}
+
+func (s *slowQueryConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := s.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return s.Conn.Begin() //nolint:staticcheck // fallback for drivers without BeginTx
+}
+
+func (s *slowQueryConn) Ping(ctx context.Context) error {
+	if p, ok := s.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (s *slowQueryConn) CheckNamedValue(v *driver.NamedValue) error {
+	if c, ok := s.Conn.(driver.NamedValueChecker); ok {
+		return c.CheckNamedValue(v)
+	}
+	return driver.ErrSkip
+}
+
+func (s *slowQueryConn) ResetSession(ctx context.Context) error {
+	if r, ok := s.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
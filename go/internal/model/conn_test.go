@@ -0,0 +1,67 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+func TestQueryFingerprint(t *testing.T) {
+	fp1, norm := QueryFingerprint(`
+SELECT id, model_id FROM decision_cycles -- newest first
+WHERE model_id = $1 AND id IN ($2, $3, $4) AND note = 'it''s'
+LIMIT 200`)
+	assert.Equal(t, "select id,model_id from decision_cycles where model_id = ? and id in (?+) and note = ? limit ?", norm)
+
+	fp2, _ := QueryFingerprint(`select id, model_id /* hint */ from decision_cycles where model_id = 'gpt' and id in (7) and note = 'x' limit 50`)
+	assert.NotEqual(t, fp1, fp2, "a single-element IN list differs from a longer one")
+	fp3, _ := QueryFingerprint(`SELECT id,model_id FROM decision_cycles WHERE model_id = $9 AND id IN ($1,$2) AND note = $3 LIMIT $4`)
+	assert.Equal(t, fp1, fp3)
+	assert.Len(t, fp1, 16)
+
+	_, norm = QueryFingerprint(`INSERT INTO klines_1m (symbol, ts) VALUES ($1, $2), ($3, $4)`)
+	assert.Equal(t, "insert into klines_1m (symbol,ts) values (?+),(?+)", norm)
+}
+
+type fakeConnector struct{ execs []string }
+
+func (f *fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{f: f}, nil }
+func (f *fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ f *fakeConnector }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.f.execs = append(c.f.execs, query)
+	return driver.RowsAffected(1), nil
+}
+
+func TestSlowQueryConnectorLogsFingerprint(t *testing.T) {
+	var buf strings.Builder
+	logx.SetWriter(logx.NewWriter(&buf))
+	defer logx.Reset()
+
+	inner := &fakeConnector{}
+	db := sql.OpenDB(&slowQueryConnector{Connector: inner, pool: "bulk", threshold: time.Nanosecond})
+	defer db.Close()
+	res, err := db.ExecContext(context.Background(), "DELETE FROM klines WHERE ts < $1", time.Now())
+	require.NoError(t, err)
+	n, _ := res.RowsAffected()
+	assert.EqualValues(t, 1, n)
+	assert.Equal(t, []string{"DELETE FROM klines WHERE ts < $1"}, inner.execs)
+
+	fingerprint, _ := QueryFingerprint("DELETE FROM klines WHERE ts < $1")
+	assert.Contains(t, buf.String(), "pool=bulk fingerprint="+fingerprint)
+	assert.Contains(t, buf.String(), "delete from klines where ts < ?")
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+var _ CycleEventsModel = (*customCycleEventsModel)(nil)
+
+// CycleEventCount aggregates skipped or aborted cycles per trader and kind.
+type CycleEventCount struct {
+	TraderId string    `db:"trader_id"`
+	Kind     string    `db:"kind"`
+	Count    int64     `db:"count"`
+	LastAt   time.Time `db:"last_at"`
+}
+
+type (
+	// CycleEventsModel is an interface to be customized, add more methods here,
+	// and implement the added methods in customCycleEventsModel.
+	CycleEventsModel interface {
+		cycleEventsModel
+		Append(ctx context.Context, data *CycleEvents) error
+		CountsSince(ctx context.Context, since time.Time) ([]CycleEventCount, error)
+	}
+
+	customCycleEventsModel struct {
+		*defaultCycleEventsModel
+	}
+)
+
+// NewCycleEventsModel returns a model for the database table.
+func NewCycleEventsModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Option) CycleEventsModel {
+	return &customCycleEventsModel{
+		defaultCycleEventsModel: newCycleEventsModel(conn, c, opts...),
+	}
+}
+
+// Append inserts one ledger row. The ledger is append-only and never read
+// by id, so it bypasses the row cache.
+func (m *customCycleEventsModel) Append(ctx context.Context, data *CycleEvents) error {
+	if data == nil {
+		return fmt.Errorf("cycle_events: nil data")
+	}
+	query := fmt.Sprintf(`
+INSERT INTO %s (trader_id, trace_id, kind, reason, occurred_at)
+VALUES ($1, $2, $3, $4, $5)`, m.tableName())
+	_, err := m.ExecNoCacheCtx(ctx, query, data.TraderId, data.TraceId, data.Kind, data.Reason, data.OccurredAt)
+	return err
+}
+
+// CountsSince counts events per trader and kind that occurred at or after
+// since, ordered by trader and kind.
+func (m *customCycleEventsModel) CountsSince(ctx context.Context, since time.Time) ([]CycleEventCount, error) {
+	query := fmt.Sprintf(`
+SELECT trader_id, kind, COUNT(*) AS count, MAX(occurred_at) AS last_at
+FROM %s
+WHERE occurred_at >= $1
+GROUP BY trader_id, kind
+ORDER BY trader_id, kind`, m.tableName())
+	var rows []CycleEventCount
+	if err := m.QueryRowsNoCacheCtx(ctx, &rows, query, since); err != nil {
+		return nil, fmt.Errorf("cycle_events.CountsSince query: %w", err)
+	}
+	return rows, nil
+}
@@ -1,17 +1,49 @@
 package model
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
 	"github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
 )
 
 var _ DecisionCyclesModel = (*customDecisionCyclesModel)(nil)
 
+// DecisionCycleRow is the subset of a decision_cycles row served by the
+// decisions API.
+type DecisionCycleRow struct {
+	Id           int64          `db:"id"`
+	ModelId      string         `db:"model_id"`
+	CycleNumber  sql.NullInt64  `db:"cycle_number"`
+	Success      bool           `db:"success"`
+	PromptDigest sql.NullString `db:"prompt_digest"`
+	CotTrace     sql.NullString `db:"cot_trace"`
+	Decisions    sql.NullString `db:"decisions"`
+	ErrorMessage sql.NullString `db:"error_message"`
+	ExecutedAt   time.Time      `db:"executed_at"`
+}
+
+// DecisionCyclePageQuery selects decision cycles newest first. Zero fields do
+// not filter; BeforeAt and BeforeID are the execution time and id of the
+// previous page's last cycle.
+type DecisionCyclePageQuery struct {
+	ModelID  string
+	From     time.Time
+	To       time.Time
+	BeforeAt time.Time
+	BeforeID int64
+	Limit    int
+}
+
 type (
 	// DecisionCyclesModel is an interface to be customized, add more methods here,
 	// and implement the added methods in customDecisionCyclesModel.
 	DecisionCyclesModel interface {
 		decisionCyclesModel
+		Page(ctx context.Context, q DecisionCyclePageQuery) ([]DecisionCycleRow, error)
 	}
 
 	customDecisionCyclesModel struct {
@@ -25,3 +57,31 @@ func NewDecisionCyclesModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.
 		defaultDecisionCyclesModel: newDecisionCyclesModel(conn, c, opts...),
 	}
 }
+
+// Page returns one page of live (not archived) cycles executed in [From, To),
+// ordered by execution time then id descending. Limit defaults to 200 when non-positive.
+func (m *customDecisionCyclesModel) Page(ctx context.Context, q DecisionCyclePageQuery) ([]DecisionCycleRow, error) {
+	if q.Limit <= 0 {
+		q.Limit = 200
+	}
+	query := fmt.Sprintf(`
+SELECT id, model_id, cycle_number, success, prompt_digest, cot_trace, decisions, error_message, executed_at
+FROM %s
+WHERE deleted_at IS NULL
+  AND ($1 = '' OR model_id = $1)
+  AND ($2::timestamptz IS NULL OR executed_at >= $2)
+  AND ($3::timestamptz IS NULL OR executed_at < $3)
+  AND ($4::timestamptz IS NULL OR (executed_at, id) < ($4, $5))
+ORDER BY executed_at DESC, id DESC
+LIMIT $6`, m.tableName())
+	var rows []DecisionCycleRow
+	if err := m.QueryRowsNoCacheCtx(ctx, &rows, query,
+		q.ModelID, nullableTime(q.From), nullableTime(q.To), nullableTime(q.BeforeAt), q.BeforeID, q.Limit); err != nil {
+		return nil, fmt.Errorf("decision_cycles.Page query: %w", err)
+	}
+	return rows, nil
+}
+
+func nullableTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,145 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+var _ KlinesModel = (*customKlinesModel)(nil)
+
+// klinesUpsertColumns is the number of bound parameters per upserted row.
+const klinesUpsertColumns = 12
+
+// KlineRangeQuery selects candles of one symbol and interval opening in
+// [From, To). Zero times do not filter; with more than Limit candles in the
+// range the newest Limit are returned.
+type KlineRangeQuery struct {
+	Provider string
+	Symbol   string
+	Interval string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+type (
+	// KlinesModel is an interface to be customized, add more methods here,
+	// and implement the added methods in customKlinesModel.
+	KlinesModel interface {
+		klinesModel
+		UpsertBatch(ctx context.Context, rows []*Klines) error
+		LatestOpenTime(ctx context.Context, provider, symbol, interval string) (time.Time, bool, error)
+		Range(ctx context.Context, q KlineRangeQuery) ([]Klines, error)
+	}
+
+	customKlinesModel struct {
+		*defaultKlinesModel
+	}
+)
+
+// NewKlinesModel returns a model for the database table.
+func NewKlinesModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Option) KlinesModel {
+	return &customKlinesModel{
+		defaultKlinesModel: newKlinesModel(conn, c, opts...),
+	}
+}
+
+// UpsertBatch writes rows in one statement, replacing the prices and volume
+// of candles already stored for the same symbol, interval and open time.
+// The row cache entries of every written candle are then removed in batched
+// deletes, or left to expire when ctx is marked by WithCacheBypass.
+func (m *customKlinesModel) UpsertBatch(ctx context.Context, rows []*Klines) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	placeholders := make([]string, 0, len(rows))
+	args := make([]any, 0, len(rows)*klinesUpsertColumns)
+	var keys cacheKeys
+	for i, r := range rows {
+		if r == nil {
+			return fmt.Errorf("klines: nil row at %d", i)
+		}
+		base := i * klinesUpsertColumns
+		ph := make([]string, klinesUpsertColumns)
+		for j := range ph {
+			ph[j] = fmt.Sprintf("$%d", base+j+1)
+		}
+		ph[klinesUpsertColumns-1] += "::jsonb"
+		placeholders = append(placeholders, "("+strings.Join(ph, ", ")+")")
+		args = append(args,
+			r.SymbolId, r.ExchangeProvider, r.Symbol, r.Interval, r.OpenTime, r.CloseTime,
+			r.OpenPrice, r.HighPrice, r.LowPrice, r.ClosePrice, r.Volume, jsonOrDefault(r.Detail, "{}"))
+		keys.add(cacheKlinesSymbolIdIntervalOpenTimePrefix, r.SymbolId, r.Interval, r.OpenTime)
+	}
+	query := fmt.Sprintf(`
+INSERT INTO %s (
+    symbol_id, exchange_provider, symbol, interval, open_time, close_time,
+    open_price, high_price, low_price, close_price, volume, detail
+) VALUES %s
+ON CONFLICT (symbol_id, interval, open_time) DO UPDATE
+SET close_time = EXCLUDED.close_time,
+    open_price = EXCLUDED.open_price,
+    high_price = EXCLUDED.high_price,
+    low_price = EXCLUDED.low_price,
+    close_price = EXCLUDED.close_price,
+    volume = EXCLUDED.volume,
+    detail = EXCLUDED.detail,
+    updated_at = NOW()
+RETURNING id`, m.tableName(), strings.Join(placeholders, ", "))
+	var ids []int64
+	if err := m.QueryRowsNoCacheCtx(ctx, &ids, query, args...); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		keys.add(cacheKlinesIdPrefix, id)
+	}
+	return keys.invalidate(ctx, m.DelCacheCtx)
+}
+
+// LatestOpenTime returns the open time of the newest stored candle.
+func (m *customKlinesModel) LatestOpenTime(ctx context.Context, provider, symbol, interval string) (time.Time, bool, error) {
+	query := fmt.Sprintf(`SELECT MAX(open_time) FROM %s WHERE exchange_provider = $1 AND symbol = $2 AND interval = $3`, m.tableName())
+	var latest sql.NullTime
+	if err := m.QueryRowNoCacheCtx(ctx, &latest, query, provider, symbol, interval); err != nil {
+		return time.Time{}, false, err
+	}
+	if !latest.Valid {
+		return time.Time{}, false, nil
+	}
+	return latest.Time.UTC(), true, nil
+}
+
+// Range returns the candles selected by q in ascending open time. Limit
+// defaults to 1500 when non-positive.
+func (m *customKlinesModel) Range(ctx context.Context, q KlineRangeQuery) ([]Klines, error) {
+	if q.Limit <= 0 {
+		q.Limit = 1500
+	}
+	clauses := []string{"exchange_provider = $1", "symbol = $2", "interval = $3"}
+	args := []any{q.Provider, q.Symbol, q.Interval}
+	if !q.From.IsZero() {
+		args = append(args, q.From.UTC())
+		clauses = append(clauses, fmt.Sprintf("open_time >= $%d", len(args)))
+	}
+	if !q.To.IsZero() {
+		args = append(args, q.To.UTC())
+		clauses = append(clauses, fmt.Sprintf("open_time < $%d", len(args)))
+	}
+	args = append(args, q.Limit)
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY open_time DESC LIMIT $%d`,
+		klinesRows, m.tableName(), strings.Join(clauses, " AND "), len(args))
+	var rows []Klines
+	if err := m.QueryRowsNoCacheCtx(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("klines.Range query: %w", err)
+	}
+	for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+		rows[i], rows[j] = rows[j], rows[i]
+	}
+	return rows, nil
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+var _ MarketMetricsModel = (*customMarketMetricsModel)(nil)
+
+// marketMetricsInsertColumns is the number of bound parameters per inserted row.
+const marketMetricsInsertColumns = 15
+
+type (
+	// MarketMetricsModel is an interface to be customized, add more methods here,
+	// and implement the added methods in customMarketMetricsModel.
+	MarketMetricsModel interface {
+		marketMetricsModel
+		InsertBatch(ctx context.Context, rows []*MarketMetrics) error
+		ListSince(ctx context.Context, provider, symbol string, since time.Time) ([]*MarketMetrics, error)
+	}
+
+	customMarketMetricsModel struct {
+		*defaultMarketMetricsModel
+	}
+)
+
+// NewMarketMetricsModel returns a model for the database table.
+func NewMarketMetricsModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Option) MarketMetricsModel {
+	return &customMarketMetricsModel{
+		defaultMarketMetricsModel: newMarketMetricsModel(conn, c, opts...),
+	}
+}
+
+// InsertBatch writes rows in one statement. A row whose symbol already has a
+// reading at the same event_at is skipped, so re-collecting a tick is a no-op.
+// Not-found entries cached for the written keys are removed in batched
+// deletes unless ctx is marked by WithCacheBypass.
+func (m *customMarketMetricsModel) InsertBatch(ctx context.Context, rows []*MarketMetrics) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	placeholders := make([]string, 0, len(rows))
+	args := make([]any, 0, len(rows)*marketMetricsInsertColumns)
+	var keys cacheKeys
+	for i, r := range rows {
+		if r == nil {
+			return fmt.Errorf("market metrics: nil row at %d", i)
+		}
+		base := i * marketMetricsInsertColumns
+		ph := make([]string, marketMetricsInsertColumns)
+		for j := range ph {
+			ph[j] = fmt.Sprintf("$%d", base+j+1)
+		}
+		ph[marketMetricsInsertColumns-2] += "::jsonb"
+		placeholders = append(placeholders, "("+strings.Join(ph, ", ")+")")
+		args = append(args,
+			r.SymbolId, r.ExchangeProvider, r.Symbol,
+			r.MarkPrice, r.MidPrice, r.OraclePrice, r.FundingRate, r.OpenInterest,
+			r.DayVolume, r.DayNotionalVolume, r.Change24h, r.Premium, r.PrevDayPrice,
+			jsonOrDefault(r.Detail, "{}"), r.EventAt)
+		keys.add(cacheMarketMetricsSymbolIdEventAtPrefix, r.SymbolId, r.EventAt)
+	}
+	query := fmt.Sprintf(`
+INSERT INTO %s (
+    symbol_id, exchange_provider, symbol,
+    mark_price, mid_price, oracle_price, funding_rate, open_interest,
+    day_volume, day_notional_volume, change_24h, premium, prev_day_price,
+    detail, event_at
+) VALUES %s
+ON CONFLICT (symbol_id, event_at) DO NOTHING`, m.tableName(), strings.Join(placeholders, ", "))
+	if _, err := m.ExecNoCacheCtx(ctx, query, args...); err != nil {
+		return err
+	}
+	return keys.invalidate(ctx, m.DelCacheCtx)
+}
+
+// ListSince returns the readings of a symbol at or after since, oldest first.
+func (m *customMarketMetricsModel) ListSince(ctx context.Context, provider, symbol string, since time.Time) ([]*MarketMetrics, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE exchange_provider = $1 AND symbol = $2 AND event_at >= $3 ORDER BY event_at`, marketMetricsRows, m.tableName())
+	var rows []*MarketMetrics
+	if err := m.QueryRowsNoCacheCtx(ctx, &rows, query, provider, symbol, since); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -1,17 +1,25 @@
 package model
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
 	"github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
 )
 
 var _ PriceTicksModel = (*customPriceTicksModel)(nil)
 
+// priceTicksInsertColumns is the number of bound parameters per inserted row.
+const priceTicksInsertColumns = 6
+
 type (
 	// PriceTicksModel is an interface to be customized, add more methods here,
 	// and implement the added methods in customPriceTicksModel.
 	PriceTicksModel interface {
 		priceTicksModel
+		InsertBatch(ctx context.Context, rows []*PriceTicks) error
 	}
 
 	customPriceTicksModel struct {
@@ -25,3 +33,37 @@ func NewPriceTicksModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Opti
 		defaultPriceTicksModel: newPriceTicksModel(conn, c, opts...),
 	}
 }
+
+// InsertBatch writes rows in one statement, skipping ticks already stored
+// for the same provider, symbol and timestamp. Unlike Insert, which clears
+// the cache per row, not-found entries cached for the written keys are
+// removed in batched deletes, or left to expire when ctx is marked by
+// WithCacheBypass.
+func (m *customPriceTicksModel) InsertBatch(ctx context.Context, rows []*PriceTicks) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	placeholders := make([]string, 0, len(rows))
+	args := make([]any, 0, len(rows)*priceTicksInsertColumns)
+	var keys cacheKeys
+	for i, r := range rows {
+		if r == nil {
+			return fmt.Errorf("price_ticks: nil row at %d", i)
+		}
+		base := i * priceTicksInsertColumns
+		ph := make([]string, priceTicksInsertColumns)
+		for j := range ph {
+			ph[j] = fmt.Sprintf("$%d", base+j+1)
+		}
+		placeholders = append(placeholders, "("+strings.Join(ph, ", ")+")")
+		args = append(args, r.Provider, r.Symbol, r.Price, r.Volume, r.TsMs, r.Raw)
+		keys.add(cachePriceTicksProviderSymbolTsMsPrefix, r.Provider, r.Symbol, r.TsMs)
+	}
+	query := fmt.Sprintf(`
+INSERT INTO %s (provider, symbol, price, volume, ts_ms, raw) VALUES %s
+ON CONFLICT (provider, symbol, ts_ms) DO NOTHING`, m.tableName(), strings.Join(placeholders, ", "))
+	if _, err := m.ExecNoCacheCtx(ctx, query, args...); err != nil {
+		return err
+	}
+	return keys.invalidate(ctx, m.DelCacheCtx)
+}
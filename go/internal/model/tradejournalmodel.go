@@ -0,0 +1,119 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+var _ TradeJournalModel = (*customTradeJournalModel)(nil)
+
+// TradeJournalClose settles a trader's open journal entries for one symbol
+// and side. OrderIds is a JSON array of exchange order ids.
+type TradeJournalClose struct {
+	TraderId    string
+	Symbol      string
+	Side        string
+	OrderIds    string
+	Reason      string
+	ExitPrice   float64
+	SlippageBps float64
+	RealizedPnl sql.NullFloat64
+	ClosedAt    time.Time
+}
+
+type (
+	// TradeJournalModel is an interface to be customized, add more methods here,
+	// and implement the added methods in customTradeJournalModel.
+	TradeJournalModel interface {
+		tradeJournalModel
+		Append(ctx context.Context, data *TradeJournal) error
+		Settle(ctx context.Context, c TradeJournalClose) (int64, error)
+		ListByTrader(ctx context.Context, traderID, symbol string, beforeID int64, limit int) ([]TradeJournal, error)
+	}
+
+	customTradeJournalModel struct {
+		*defaultTradeJournalModel
+	}
+)
+
+// NewTradeJournalModel returns a model for the database table.
+func NewTradeJournalModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Option) TradeJournalModel {
+	return &customTradeJournalModel{
+		defaultTradeJournalModel: newTradeJournalModel(conn, c, opts...),
+	}
+}
+
+// Append inserts an open entry. Rows are updated in place by Settle, which
+// matches on trader and symbol rather than id, so writes bypass the row cache.
+func (m *customTradeJournalModel) Append(ctx context.Context, data *TradeJournal) error {
+	if data == nil {
+		return fmt.Errorf("trade_journal: nil data")
+	}
+	query := fmt.Sprintf(`
+INSERT INTO %s (
+    trader_id, trace_id, symbol, side, status, prompt_digest, prompt_version, raw_response, decision,
+    open_order_ids, entry_price, entry_size, entry_slippage_bps, opened_at
+) VALUES ($1, $2, $3, $4, 'open', $5, $6, $7, $8::jsonb, $9::jsonb, $10, $11, $12, $13)`, m.tableName())
+	_, err := m.ExecNoCacheCtx(ctx, query,
+		data.TraderId, data.TraceId, data.Symbol, data.Side, data.PromptDigest, data.PromptVersion, data.RawResponse,
+		jsonOrDefault(data.Decision, "{}"), jsonOrDefault(data.OpenOrderIds, "[]"),
+		data.EntryPrice, data.EntrySize, data.EntrySlippageBps, data.OpenedAt)
+	return err
+}
+
+// Settle closes every open entry of the trader for c.Symbol and c.Side and
+// returns how many were closed. Entries that scaled into the same position
+// share its exit and realized PnL.
+func (m *customTradeJournalModel) Settle(ctx context.Context, c TradeJournalClose) (int64, error) {
+	query := fmt.Sprintf(`
+UPDATE %s
+SET status = 'closed',
+    close_order_ids = $4::jsonb,
+    close_reason = $5,
+    exit_price = $6,
+    exit_slippage_bps = $7,
+    realized_pnl = $8,
+    closed_at = $9,
+    updated_at = NOW()
+WHERE trader_id = $1 AND symbol = $2 AND side = $3 AND status = 'open'`, m.tableName())
+	res, err := m.ExecNoCacheCtx(ctx, query,
+		c.TraderId, c.Symbol, c.Side, jsonOrDefault(c.OrderIds, "[]"), c.Reason,
+		c.ExitPrice, c.SlippageBps, c.RealizedPnl, c.ClosedAt)
+	if err != nil {
+		return 0, fmt.Errorf("trade_journal.Settle exec: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// ListByTrader returns a trader's entries with id below beforeID, newest
+// first, optionally restricted to symbol. beforeID <= 0 starts at the newest
+// entry; limit defaults to 50 when non-positive.
+func (m *customTradeJournalModel) ListByTrader(ctx context.Context, traderID, symbol string, beforeID int64, limit int) ([]TradeJournal, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	query := fmt.Sprintf(`
+SELECT %s FROM %s
+WHERE trader_id = $1
+  AND ($2 = '' OR symbol = $2)
+  AND ($3 <= 0 OR id < $3)
+ORDER BY id DESC
+LIMIT $4`, tradeJournalRows, m.tableName())
+	var rows []TradeJournal
+	if err := m.QueryRowsNoCacheCtx(ctx, &rows, query, traderID, symbol, beforeID, limit); err != nil {
+		return nil, fmt.Errorf("trade_journal.ListByTrader query: %w", err)
+	}
+	return rows, nil
+}
+
+func jsonOrDefault(raw, fallback string) string {
+	if raw == "" {
+		return fallback
+	}
+	return raw
+}
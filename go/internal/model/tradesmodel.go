@@ -30,12 +30,26 @@ type TradeRecord struct {
 	RealizedNetPnl   *float64
 }
 
+// TradePageQuery selects closed trades newest first by close time. Zero
+// fields do not filter; BeforeTsMs and BeforeID are the close time and id of
+// the previous page's last trade.
+type TradePageQuery struct {
+	ModelID    string
+	FromTsMs   int64
+	ToTsMs     int64
+	BeforeTsMs int64
+	BeforeID   string
+	Limit      int
+}
+
 type (
 	// TradesModel is an interface to be customized, add more methods here,
 	// and implement the added methods in customTradesModel.
 	TradesModel interface {
 		tradesModel
 		RecentByModel(ctx context.Context, modelID string, limit int) ([]TradeRecord, error)
+		Page(ctx context.Context, q TradePageQuery) ([]TradeRecord, error)
+		Overlapping(ctx context.Context, modelID string, fromTsMs, toTsMs int64) ([]TradeRecord, error)
 	}
 
 	customTradesModel struct {
@@ -82,6 +96,71 @@ LIMIT $2`
 	return result, nil
 }
 
+// Page returns one page of trades closed in [FromTsMs, ToTsMs), ordered by
+// close time then id descending. Limit defaults to 200 when non-positive.
+func (m *customTradesModel) Page(ctx context.Context, q TradePageQuery) ([]TradeRecord, error) {
+	if q.Limit <= 0 {
+		q.Limit = 200
+	}
+
+	const query = `
+SELECT
+    id,
+    trader_id,
+    symbol,
+    side,
+    close_ts_ms,
+    detail
+FROM public.trades
+WHERE ($1 = '' OR trader_id = $1)
+  AND ($2::bigint = 0 OR close_ts_ms >= $2)
+  AND ($3::bigint = 0 OR close_ts_ms < $3)
+  AND ($4::bigint = 0 OR (close_ts_ms, id) < ($4, $5))
+ORDER BY close_ts_ms DESC, id DESC
+LIMIT $6`
+
+	var rows []Trades
+	if err := m.QueryRowsNoCacheCtx(ctx, &rows, query, q.ModelID, q.FromTsMs, q.ToTsMs, q.BeforeTsMs, q.BeforeID, q.Limit); err != nil {
+		return nil, fmt.Errorf("trades.Page query: %w", err)
+	}
+
+	result := make([]TradeRecord, 0, len(rows))
+	for i := range rows {
+		result = append(result, buildTradeRecord(&rows[i]))
+	}
+	return result, nil
+}
+
+// Overlapping returns the model's trades closed after fromTsMs and opened at
+// or before toTsMs, ordered by close time then id descending: the trades
+// closed in (fromTsMs, toTsMs] and those still open at toTsMs.
+func (m *customTradesModel) Overlapping(ctx context.Context, modelID string, fromTsMs, toTsMs int64) ([]TradeRecord, error) {
+	const query = `
+SELECT
+    id,
+    trader_id,
+    symbol,
+    side,
+    close_ts_ms,
+    detail
+FROM public.trades
+WHERE trader_id = $1
+  AND close_ts_ms > $2
+  AND COALESCE((detail->'time'->>'open_ts_ms')::bigint, 0) <= $3
+ORDER BY close_ts_ms DESC, id DESC`
+
+	var rows []Trades
+	if err := m.QueryRowsNoCacheCtx(ctx, &rows, query, modelID, fromTsMs, toTsMs); err != nil {
+		return nil, fmt.Errorf("trades.Overlapping query: %w", err)
+	}
+
+	result := make([]TradeRecord, 0, len(rows))
+	for i := range rows {
+		result = append(result, buildTradeRecord(&rows[i]))
+	}
+	return result, nil
+}
+
 func buildTradeRecord(row *Trades) TradeRecord {
 	rec := TradeRecord{
 		ID:        row.Id,
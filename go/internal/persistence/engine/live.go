@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/internal/live"
+	"nof0-api/pkg/journal"
+	managerpkg "nof0-api/pkg/manager"
+)
+
+// publishLive pushes a dashboard update over /ws. Failures are logged and
+// never returned so that Redis trouble cannot fail the write it accompanies.
+func (s *Service) publishLive(ctx context.Context, topic, traderID string, payload any) {
+	if s.redis == nil {
+		return
+	}
+	if err := live.Publish(ctx, s.redis, topic, traderID, payload); err != nil {
+		logx.WithContext(ctx).Errorf("enginepersist: publish live %s trader=%s err=%v", topic, traderID, err)
+	}
+}
+
+type livePosition struct {
+	Symbol      string  `json:"symbol"`
+	Event       string  `json:"event"`
+	Action      string  `json:"action"`
+	Price       float64 `json:"price"`
+	Size        float64 `json:"size"`
+	Leverage    int     `json:"leverage,omitempty"`
+	SlippageBps float64 `json:"slippage_bps,omitempty"`
+	TraceID     string  `json:"trace_id,omitempty"`
+	OccurredAt  int64   `json:"occurred_at"`
+}
+
+func livePositionPayload(symbol string, event managerpkg.PositionEvent) livePosition {
+	price := effectivePrice(event)
+	at := event.OccurredAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return livePosition{
+		Symbol:      symbol,
+		Event:       string(event.Event),
+		Action:      event.Decision.Action,
+		Price:       price,
+		Size:        effectiveQuantity(event, price),
+		Leverage:    event.Decision.Leverage,
+		SlippageBps: event.SlippageBps,
+		TraceID:     event.TraceID,
+		OccurredAt:  at.UTC().UnixMilli(),
+	}
+}
+
+type liveDecision struct {
+	Cycle        int             `json:"cycle"`
+	Success      bool            `json:"success"`
+	ErrorMessage string          `json:"error_message,omitempty"`
+	PromptDigest string          `json:"prompt_digest,omitempty"`
+	Decisions    json.RawMessage `json:"decisions"`
+	ExecutedAt   int64           `json:"executed_at"`
+}
+
+// liveDecisionPayload summarises a cycle without its prompt inputs or chain
+// of thought, which stay behind the authenticated endpoints.
+func liveDecisionPayload(cycle *journal.CycleRecord) liveDecision {
+	decisions := json.RawMessage("[]")
+	if raw := strings.TrimSpace(cycle.DecisionsJSON); raw != "" && json.Valid([]byte(raw)) {
+		decisions = json.RawMessage(raw)
+	}
+	at := cycle.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return liveDecision{
+		Cycle:        cycle.CycleNumber,
+		Success:      cycle.Success,
+		ErrorMessage: cycle.ErrorMessage,
+		PromptDigest: cycle.PromptDigest,
+		Decisions:    decisions,
+		ExecutedAt:   at.UTC().UnixMilli(),
+	}
+}
+
+type liveAccount struct {
+	EquityUSD           float64 `json:"equity_usd"`
+	MarginUsedUSD       float64 `json:"margin_used_usd"`
+	AvailableBalanceUSD float64 `json:"available_balance_usd"`
+	UnrealizedPnLUSD    float64 `json:"unrealized_pnl_usd"`
+	SyncedAt            int64   `json:"synced_at"`
+}
@@ -20,7 +20,9 @@ import (
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
 
 	cachekeys "nof0-api/internal/cache"
+	"nof0-api/internal/live"
 	"nof0-api/internal/model"
+	"nof0-api/internal/redisstream"
 	"nof0-api/pkg/exchange"
 	executorpkg "nof0-api/pkg/executor"
 	journal "nof0-api/pkg/journal"
@@ -28,8 +30,13 @@ import (
 )
 
 var (
-	_ managerpkg.PersistenceService    = (*Service)(nil)
-	_ executorpkg.ConversationRecorder = (*Service)(nil)
+	_ managerpkg.PersistenceService     = (*Service)(nil)
+	_ managerpkg.CycleBreakdownRecorder = (*Service)(nil)
+	_ managerpkg.CycleEventRecorder     = (*Service)(nil)
+	_ managerpkg.HeartbeatRecorder      = (*Service)(nil)
+	_ managerpkg.PipelineLogRecorder    = (*Service)(nil)
+	_ managerpkg.TimelineRecorder       = (*Service)(nil)
+	_ executorpkg.ConversationRecorder  = (*Service)(nil)
 )
 
 // Service wires Postgres + Redis collaborators required by manager persistence hooks.
@@ -39,6 +46,8 @@ type Service struct {
 	tradesModel               model.TradesModel
 	snapshotsModel            model.AccountEquitySnapshotsModel
 	decisionModel             model.DecisionCyclesModel
+	cycleEventsModel          model.CycleEventsModel
+	tradeJournalModel         model.TradeJournalModel
 	cache                     gocache.Cache
 	redis                     *redis.Redis
 	ttl                       cachekeys.TTLSet
@@ -53,6 +62,8 @@ type Config struct {
 	TradesModel               model.TradesModel
 	SnapshotsModel            model.AccountEquitySnapshotsModel
 	DecisionModel             model.DecisionCyclesModel
+	CycleEventsModel          model.CycleEventsModel
+	TradeJournalModel         model.TradeJournalModel
 	Cache                     gocache.Cache
 	Redis                     *redis.Redis
 	TTL                       cachekeys.TTLSet
@@ -71,6 +82,8 @@ func NewService(cfg Config) managerpkg.PersistenceService {
 		tradesModel:               cfg.TradesModel,
 		snapshotsModel:            cfg.SnapshotsModel,
 		decisionModel:             cfg.DecisionModel,
+		cycleEventsModel:          cfg.CycleEventsModel,
+		tradeJournalModel:         cfg.TradeJournalModel,
 		cache:                     cfg.Cache,
 		redis:                     cfg.Redis,
 		ttl:                       cfg.TTL,
@@ -81,7 +94,7 @@ func NewService(cfg Config) managerpkg.PersistenceService {
 
 // RecordPositionEvent persists basic position lifecycle information.
 func (s *Service) RecordPositionEvent(ctx context.Context, event managerpkg.PositionEvent) error {
-	if s == nil || s.sqlConn == nil {
+	if s == nil {
 		return nil
 	}
 	modelID := normalizedModelID(event)
@@ -89,6 +102,10 @@ func (s *Service) RecordPositionEvent(ctx context.Context, event managerpkg.Posi
 	if modelID == "" || symbol == "" {
 		return nil
 	}
+	s.publishLive(ctx, live.TopicPositions, modelID, livePositionPayload(symbol, event))
+	if s.sqlConn == nil {
+		return nil
+	}
 	switch event.Event {
 	case managerpkg.PositionEventOpen:
 		return s.handleOpenPosition(ctx, modelID, symbol, event)
@@ -101,7 +118,7 @@ func (s *Service) RecordPositionEvent(ctx context.Context, event managerpkg.Posi
 
 // RecordDecisionCycle mirrors journal cycles to Postgres.
 func (s *Service) RecordDecisionCycle(ctx context.Context, record managerpkg.DecisionCycleRecord) error {
-	if s == nil || s.decisionModel == nil || record.Cycle == nil {
+	if s == nil || record.Cycle == nil {
 		return nil
 	}
 	mID := record.TraderID
@@ -111,6 +128,10 @@ func (s *Service) RecordDecisionCycle(ctx context.Context, record managerpkg.Dec
 	if mID == "" {
 		return nil
 	}
+	s.publishLive(ctx, live.TopicDecisions, mID, liveDecisionPayload(record.Cycle))
+	if s.decisionModel == nil {
+		return nil
+	}
 	row := &model.DecisionCycles{
 		ModelId: mID,
 		Success: record.Cycle.Success,
@@ -156,15 +177,155 @@ func (s *Service) RecordDecisionCycle(ctx context.Context, record managerpkg.Dec
 	return nil
 }
 
+// RecordCycleBreakdown publishes the latest cycle breakdown for /api/cycles/latest.
+func (s *Service) RecordCycleBreakdown(ctx context.Context, breakdown managerpkg.CycleBreakdown) error {
+	if s == nil || s.redis == nil || strings.TrimSpace(breakdown.TraderID) == "" {
+		return nil
+	}
+	ttl := s.ttlDuration(cachekeys.CycleLatestTTL(s.ttl))
+	return s.hashSetJSON(ctx, cachekeys.TraderCycleLatestHashKey(), cachekeys.TraderHashField(breakdown.TraderID), ttl, breakdown)
+}
+
+// RecordPipelineEvent appends a pipeline event to the live log stream.
+func (s *Service) RecordPipelineEvent(ctx context.Context, event managerpkg.PipelineEvent) error {
+	if s == nil || s.redis == nil {
+		return nil
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return redisstream.Append(ctx, s.redis, cachekeys.PipelineLogStreamKey(), cachekeys.PipelineLogMaxLen, string(data))
+}
+
+// RecordTimeline appends decision timeline entries in one statement.
+func (s *Service) RecordTimeline(ctx context.Context, entries []managerpkg.TimelineEntry) error {
+	if s == nil || s.sqlConn == nil || len(entries) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO public.decision_timeline (trader_id, trace_id, kind, symbol, summary, detail, occurred_at) VALUES ")
+	args := make([]any, 0, len(entries)*7)
+	for i, e := range entries {
+		detail := "{}"
+		if len(e.Detail) > 0 {
+			raw, err := json.Marshal(e.Detail)
+			if err != nil {
+				return err
+			}
+			detail = string(raw)
+		}
+		at := e.At
+		if at.IsZero() {
+			at = time.Now()
+		}
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		n := len(args)
+		fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d, $%d, $%d::jsonb, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7)
+		args = append(args, e.TraderID, e.TraceID, e.Kind, strings.ToUpper(e.Symbol), e.Summary, detail, at.UTC())
+	}
+	_, err := s.sqlConn.ExecCtx(ctx, sb.String(), args...)
+	return err
+}
+
+// RecordDataQuality appends one cycle's per-symbol data quality scores in
+// one statement.
+func (s *Service) RecordDataQuality(ctx context.Context, samples []managerpkg.DataQualitySample) error {
+	if s == nil || s.sqlConn == nil || len(samples) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO public.data_quality (trader_id, trace_id, symbol, score, gaps, stale_ms, outliers, occurred_at) VALUES ")
+	args := make([]any, 0, len(samples)*8)
+	for i, q := range samples {
+		at := q.At
+		if at.IsZero() {
+			at = time.Now()
+		}
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		n := len(args)
+		fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8)
+		args = append(args, q.TraderID, q.TraceID, strings.ToUpper(q.Symbol), q.Quality.Score, q.Quality.Gaps, q.Quality.StaleMs, q.Quality.Outliers, at.UTC())
+	}
+	_, err := s.sqlConn.ExecCtx(ctx, sb.String(), args...)
+	return err
+}
+
+// RecordCycleEvent appends a skipped or aborted cycle to the ledger counted
+// by /api/status.
+func (s *Service) RecordCycleEvent(ctx context.Context, event managerpkg.CycleEvent) error {
+	if s == nil || s.cycleEventsModel == nil || strings.TrimSpace(event.TraderID) == "" {
+		return nil
+	}
+	at := event.OccurredAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return s.cycleEventsModel.Append(ctx, &model.CycleEvents{
+		TraderId:   event.TraderID,
+		TraceId:    event.TraceID,
+		Kind:       event.Kind,
+		Reason:     event.Reason,
+		OccurredAt: at.UTC(),
+	})
+}
+
+// RecordHeartbeat upserts the runner heartbeat read by /api/status.
+func (s *Service) RecordHeartbeat(ctx context.Context, hb managerpkg.Heartbeat) error {
+	if s == nil || s.sqlConn == nil || strings.TrimSpace(hb.TraderID) == "" {
+		return nil
+	}
+	const stmt = `INSERT INTO public.trader_heartbeats
+    (trader_id, last_cycle_at, last_llm_success_at, last_order_at, decision_interval_ms, stall_after_ms, stalled, reported_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (trader_id) DO UPDATE SET
+    last_cycle_at = EXCLUDED.last_cycle_at,
+    last_llm_success_at = EXCLUDED.last_llm_success_at,
+    last_order_at = EXCLUDED.last_order_at,
+    decision_interval_ms = EXCLUDED.decision_interval_ms,
+    stall_after_ms = EXCLUDED.stall_after_ms,
+    stalled = EXCLUDED.stalled,
+    reported_at = EXCLUDED.reported_at`
+	reportedAt := hb.ReportedAt
+	if reportedAt.IsZero() {
+		reportedAt = time.Now()
+	}
+	_, err := s.sqlConn.ExecCtx(ctx, stmt,
+		hb.TraderID,
+		nullTime(hb.LastCycleAt),
+		nullTime(hb.LastLLMSuccessAt),
+		nullTime(hb.LastOrderAt),
+		hb.DecisionInterval.Milliseconds(),
+		hb.StallAfter.Milliseconds(),
+		hb.Stalled,
+		reportedAt.UTC(),
+	)
+	return err
+}
+
 // RecordAccountSnapshot captures periodic equity metrics.
 func (s *Service) RecordAccountSnapshot(ctx context.Context, snapshot managerpkg.AccountSyncSnapshot) error {
-	if s == nil || s.snapshotsModel == nil || snapshot.TraderID == "" {
+	if s == nil || snapshot.TraderID == "" {
 		return nil
 	}
 	ts := snapshot.SyncedAt
 	if ts.IsZero() {
 		ts = time.Now()
 	}
+	s.publishLive(ctx, live.TopicAccount, snapshot.TraderID, liveAccount{
+		EquityUSD:           snapshot.EquityUSD,
+		MarginUsedUSD:       snapshot.MarginUsedUSD,
+		AvailableBalanceUSD: snapshot.AvailableBalanceUSD,
+		UnrealizedPnLUSD:    snapshot.UnrealizedPnLUSD,
+		SyncedAt:            ts.UTC().UnixMilli(),
+	})
+	if s.snapshotsModel == nil {
+		return nil
+	}
 	metaPayload := map[string]any{
 		"available_balance_usd": snapshot.AvailableBalanceUSD,
 		"unrealized_pnl_usd":    snapshot.UnrealizedPnLUSD,
@@ -340,7 +501,7 @@ func (s *Service) hydrateDecisionCycles(ctx context.Context, traderIDs []string)
 	if s.sqlConn == nil {
 		return nil
 	}
-	const query = `SELECT success, error_message, decisions, executed_at, config_version FROM public.decision_cycles WHERE model_id = $1 ORDER BY executed_at DESC LIMIT 1`
+	const query = `SELECT success, error_message, decisions, executed_at, config_version FROM public.decision_cycles WHERE model_id = $1 AND deleted_at IS NULL ORDER BY executed_at DESC LIMIT 1`
 	for _, modelID := range traderIDs {
 		var row struct {
 			Success       bool           `db:"success"`
@@ -514,6 +675,7 @@ RETURNING id`
 			"model":          rec.ModelName,
 			"prompt_tokens":  rec.PromptTokens,
 			"total_tokens":   rec.TotalTokens,
+			"sampling":       rec.Sampling,
 			"conversationId": conversationID,
 		}); err != nil {
 			return err
@@ -545,14 +707,16 @@ func (s *Service) handleOpenPosition(ctx context.Context, modelID, symbol string
 		entryTime = time.Now()
 	}
 	detail, err := buildPositionDetail(positionDetailInput{
-		Price:       price,
-		Quantity:    qty,
-		TimeMs:      entryTime.UTC().UnixMilli(),
-		Leverage:    float64(event.Decision.Leverage),
-		Confidence:  float64(event.Decision.Confidence),
-		RiskUSD:     event.Decision.RiskUSD,
-		Exchange:    traderExchange(event),
-		Orientation: side,
+		Price:        price,
+		Quantity:     qty,
+		TimeMs:       entryTime.UTC().UnixMilli(),
+		Leverage:     float64(event.Decision.Leverage),
+		Confidence:   float64(event.Decision.Confidence),
+		RiskUSD:      event.Decision.RiskUSD,
+		Exchange:     traderExchange(event),
+		Orientation:  side,
+		ArrivalPrice: event.ArrivalPrice,
+		SlippageBps:  event.SlippageBps,
 	})
 	if err != nil {
 		return err
@@ -581,6 +745,9 @@ ON CONFLICT (id) DO UPDATE SET
 	if err != nil {
 		return err
 	}
+	if err := s.journalOpen(ctx, modelID, symbol, side, price, qty, entryTime, event); err != nil {
+		logx.WithContext(ctx).Errorf("enginepersist: trade journal open trader=%s symbol=%s err=%v", modelID, symbol, err)
+	}
 	s.cacheOpenPosition(ctx, modelID, symbol, &positionCacheEntry{
 		Symbol:      symbol,
 		Side:        side,
@@ -636,9 +803,11 @@ func (s *Service) handleClosePosition(ctx context.Context, modelID, symbol strin
 	var detailErr error
 	if existing != nil {
 		detailJSON, detailErr = updatePositionDetailOnClose(existing.Detail, positionCloseDetail{
-			Price:    closePrice,
-			TimeMs:   closeTime.UTC().UnixMilli(),
-			Quantity: qty,
+			Price:        closePrice,
+			TimeMs:       closeTime.UTC().UnixMilli(),
+			Quantity:     qty,
+			ArrivalPrice: event.ArrivalPrice,
+			SlippageBps:  event.SlippageBps,
 			PnL: func() float64 {
 				if pnl.Valid {
 					return pnl.Float64
@@ -648,9 +817,11 @@ func (s *Service) handleClosePosition(ctx context.Context, modelID, symbol strin
 		})
 	} else {
 		detailJSON, detailErr = updatePositionDetailOnClose("", positionCloseDetail{
-			Price:    closePrice,
-			TimeMs:   closeTime.UTC().UnixMilli(),
-			Quantity: qty,
+			Price:        closePrice,
+			TimeMs:       closeTime.UTC().UnixMilli(),
+			Quantity:     qty,
+			ArrivalPrice: event.ArrivalPrice,
+			SlippageBps:  event.SlippageBps,
 			PnL: func() float64 {
 				if pnl.Valid {
 					return pnl.Float64
@@ -676,6 +847,9 @@ WHERE id = $1;
 	if err != nil {
 		return err
 	}
+	if err := s.journalClose(ctx, modelID, symbol, closePrice, pnl, closeTime, event); err != nil {
+		logx.WithContext(ctx).Errorf("enginepersist: trade journal close trader=%s symbol=%s err=%v", modelID, symbol, err)
+	}
 	s.cacheOpenPosition(ctx, modelID, symbol, nil)
 	if summary != nil {
 		s.appendRecentTrade(ctx, modelID, *summary)
@@ -749,6 +923,81 @@ func (s *Service) insertTrade(ctx context.Context, pos *model.Positions, modelID
 	return summary, nil
 }
 
+// journalOpen records the open decision with the prompt digest, prompt
+// version and raw model answer of the cycle that produced it.
+func (s *Service) journalOpen(ctx context.Context, modelID, symbol, side string, price, qty float64, openedAt time.Time, event managerpkg.PositionEvent) error {
+	if s.tradeJournalModel == nil {
+		return nil
+	}
+	decision, err := json.Marshal(event.Decision)
+	if err != nil {
+		return err
+	}
+	orderIDs, err := json.Marshal(exchangeOrderIDs(event.ExchangeResponse))
+	if err != nil {
+		return err
+	}
+	return s.tradeJournalModel.Append(ctx, &model.TradeJournal{
+		TraderId:         modelID,
+		TraceId:          event.TraceID,
+		Symbol:           symbol,
+		Side:             side,
+		PromptDigest:     event.PromptDigest,
+		PromptVersion:    event.PromptVersion,
+		RawResponse:      event.RawResponse,
+		Decision:         string(decision),
+		OpenOrderIds:     string(orderIDs),
+		EntryPrice:       price,
+		EntrySize:        qty,
+		EntrySlippageBps: event.SlippageBps,
+		OpenedAt:         openedAt.UTC(),
+	})
+}
+
+// journalClose settles the open journal entries of the closed position.
+func (s *Service) journalClose(ctx context.Context, modelID, symbol string, closePrice float64, pnl sql.NullFloat64, closeTime time.Time, event managerpkg.PositionEvent) error {
+	if s.tradeJournalModel == nil {
+		return nil
+	}
+	side := "long"
+	if strings.EqualFold(event.Decision.Action, "close_short") {
+		side = "short"
+	}
+	orderIDs, err := json.Marshal(exchangeOrderIDs(event.ExchangeResponse))
+	if err != nil {
+		return err
+	}
+	_, err = s.tradeJournalModel.Settle(ctx, model.TradeJournalClose{
+		TraderId:    modelID,
+		Symbol:      symbol,
+		Side:        side,
+		OrderIds:    string(orderIDs),
+		Reason:      event.Decision.Reasoning,
+		ExitPrice:   closePrice,
+		SlippageBps: event.SlippageBps,
+		RealizedPnl: pnl,
+		ClosedAt:    closeTime.UTC(),
+	})
+	return err
+}
+
+// exchangeOrderIDs lists the ids of resting and filled orders in resp.
+func exchangeOrderIDs(resp *exchange.OrderResponse) []string {
+	ids := []string{}
+	if resp == nil {
+		return ids
+	}
+	for _, st := range resp.Response.Data.Statuses {
+		switch {
+		case st.Filled != nil:
+			ids = append(ids, strconv.FormatInt(st.Filled.Oid, 10))
+		case st.Resting != nil:
+			ids = append(ids, strconv.FormatInt(st.Resting.Oid, 10))
+		}
+	}
+	return ids
+}
+
 func normalizedModelID(event managerpkg.PositionEvent) string {
 	if strings.TrimSpace(event.TraderID) != "" {
 		return event.TraderID
@@ -1259,14 +1508,16 @@ func durationToSeconds(ttl time.Duration) int {
 }
 
 type positionDetailInput struct {
-	Price       float64
-	Quantity    float64
-	TimeMs      int64
-	Leverage    float64
-	Confidence  float64
-	RiskUSD     float64
-	Exchange    string
-	Orientation string
+	Price        float64
+	Quantity     float64
+	TimeMs       int64
+	Leverage     float64
+	Confidence   float64
+	RiskUSD      float64
+	Exchange     string
+	Orientation  string
+	ArrivalPrice float64
+	SlippageBps  float64
 }
 
 type positionDetail struct {
@@ -1278,10 +1529,12 @@ type positionDetail struct {
 }
 
 type positionEntryDetail struct {
-	Price    float64 `json:"price"`
-	Quantity float64 `json:"quantity"`
-	TimeMs   int64   `json:"time_ms"`
-	Leverage float64 `json:"leverage"`
+	Price        float64 `json:"price"`
+	Quantity     float64 `json:"quantity"`
+	TimeMs       int64   `json:"time_ms"`
+	Leverage     float64 `json:"leverage"`
+	ArrivalPrice float64 `json:"arrival_price,omitempty"`
+	SlippageBps  float64 `json:"slippage_bps,omitempty"`
 }
 
 type positionExchangeDetail struct {
@@ -1294,10 +1547,12 @@ type positionRiskDetail struct {
 }
 
 type positionCloseDetail struct {
-	Price    float64 `json:"price,omitempty"`
-	TimeMs   int64   `json:"time_ms,omitempty"`
-	Quantity float64 `json:"quantity,omitempty"`
-	PnL      float64 `json:"pnl,omitempty"`
+	Price        float64 `json:"price,omitempty"`
+	TimeMs       int64   `json:"time_ms,omitempty"`
+	Quantity     float64 `json:"quantity,omitempty"`
+	PnL          float64 `json:"pnl,omitempty"`
+	ArrivalPrice float64 `json:"arrival_price,omitempty"`
+	SlippageBps  float64 `json:"slippage_bps,omitempty"`
 }
 
 type positionMetricsDetail struct {
@@ -1307,10 +1562,12 @@ type positionMetricsDetail struct {
 func buildPositionDetail(input positionDetailInput) (string, error) {
 	detail := positionDetail{
 		Entry: positionEntryDetail{
-			Price:    input.Price,
-			Quantity: input.Quantity,
-			TimeMs:   input.TimeMs,
-			Leverage: input.Leverage,
+			Price:        input.Price,
+			Quantity:     input.Quantity,
+			TimeMs:       input.TimeMs,
+			Leverage:     input.Leverage,
+			ArrivalPrice: input.ArrivalPrice,
+			SlippageBps:  input.SlippageBps,
 		},
 		Exchange: positionExchangeDetail{
 			Provider: strings.TrimSpace(input.Exchange),
@@ -1473,3 +1730,10 @@ func (s *Service) cacheLeaderboardScore(ctx context.Context, modelID string, sco
 		logx.WithContext(ctx).Errorf("enginepersist: set leaderboard cache key=%s err=%v", key, err)
 	}
 }
+
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
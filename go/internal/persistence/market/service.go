@@ -10,7 +10,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/lib/pq"
 	"github.com/zeromicro/go-zero/core/logx"
 	gocache "github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/core/stores/redis"
@@ -32,6 +31,7 @@ type Service struct {
 	sqlConn         sqlx.SqlConn
 	assetsModel     model.MarketAssetsModel
 	priceTicksModel model.PriceTicksModel
+	series          SeriesStore
 	cache           gocache.Cache
 	redis           *redis.Redis
 	ttl             cachekeys.TTLSet
@@ -45,6 +45,17 @@ type Config struct {
 	Cache           gocache.Cache
 	Redis           *redis.Redis
 	TTL             cachekeys.TTLSet
+	// KlinesModel and MarketMetricsModel back the kline and metrics series
+	// of the sql backend.
+	KlinesModel        model.KlinesModel
+	MarketMetricsModel model.MarketMetricsModel
+	// SeriesBackend selects the series store (sql | timescale). Defaults to sql.
+	SeriesBackend string
+	// SeriesTable, KlinesTable and MetricsTable override the tick, kline and
+	// metrics tables used by non-default series backends.
+	SeriesTable  string
+	KlinesTable  string
+	MetricsTable string
 }
 
 // NewService wires a market persistence service. Returns nil when dependencies missing.
@@ -52,10 +63,15 @@ func NewService(cfg Config) market.Persistence {
 	if cfg.SQLConn == nil {
 		return nil
 	}
+	series, err := NewSeriesStore(cfg)
+	if err != nil {
+		logx.Errorf("marketpersist: series backend %q unavailable, price series persistence disabled: %v", cfg.SeriesBackend, err)
+	}
 	return &Service{
 		sqlConn:         cfg.SQLConn,
 		assetsModel:     cfg.AssetsModel,
 		priceTicksModel: cfg.PriceTicksModel,
+		series:          series,
 		cache:           cfg.Cache,
 		redis:           cfg.Redis,
 		ttl:             cfg.TTL,
@@ -176,7 +192,7 @@ func (s *Service) RecordSnapshot(ctx context.Context, provider string, snapshot
 
 // RecordPriceSeries persists historical ticks (typically OHLCV candles).
 func (s *Service) RecordPriceSeries(ctx context.Context, provider string, symbol string, ticks []market.PriceTick) error {
-	if s == nil || s.priceTicksModel == nil {
+	if s == nil || s.series == nil {
 		return nil
 	}
 	provider = strings.TrimSpace(provider)
@@ -184,30 +200,15 @@ func (s *Service) RecordPriceSeries(ctx context.Context, provider string, symbol
 	if provider == "" || symbol == "" || len(ticks) == 0 {
 		return nil
 	}
-	for _, tick := range ticks {
-		if tick.Timestamp.IsZero() || !(tick.Price > 0) {
-			continue
-		}
-		row := &model.PriceTicks{
-			Provider: provider,
-			Symbol:   symbol,
-			Price:    tick.Price,
-			TsMs:     tick.Timestamp.UTC().UnixMilli(),
-		}
-		if tick.HasVolume {
-			row.Volume = sql.NullFloat64{Float64: tick.Volume, Valid: true}
-		}
-		if raw := buildTickRaw(tick); raw.Valid {
-			row.Raw = raw
-		}
-		if _, err := s.priceTicksModel.Insert(ctx, row); err != nil {
-			if isUniqueViolation(err) {
-				continue
-			}
-			return err
-		}
+	return s.series.WriteTicks(ctx, provider, symbol, ticks)
+}
+
+// QueryPriceSeries loads historical ticks from the configured series store.
+func (s *Service) QueryPriceSeries(ctx context.Context, provider, symbol string, since time.Time, limit int) ([]market.PriceTick, error) {
+	if s == nil || s.series == nil {
+		return nil, nil
 	}
-	return nil
+	return s.series.QueryTicks(ctx, strings.TrimSpace(provider), strings.ToUpper(strings.TrimSpace(symbol)), since, limit)
 }
 
 func (s *Service) cacheAssets(ctx context.Context, provider string, assets []market.Asset) error {
@@ -315,6 +316,24 @@ func (s *Service) cacheMarketCtx(ctx context.Context, provider string, snapshot
 	}
 }
 
+// StoreWindow mirrors a rolling market window to the cache so other processes
+// can assemble prompts without recomputing indicator series.
+func (s *Service) StoreWindow(ctx context.Context, provider string, snapshot *market.Snapshot) error {
+	if s == nil || s.cache == nil || snapshot == nil || strings.TrimSpace(snapshot.Symbol) == "" {
+		return nil
+	}
+	ttl := cachekeys.MarketWindowTTL(s.ttl)
+	if ttl <= 0 {
+		return nil
+	}
+	key := cachekeys.MarketWindowKey(provider, snapshot.Symbol)
+	if err := s.cache.SetWithExpireCtx(ctx, key, snapshot, ttl); err != nil {
+		logx.WithContext(ctx).Errorf("marketpersist: cache window key=%s err=%v", key, err)
+		return err
+	}
+	return nil
+}
+
 func (s *Service) updateCryptoPrices(ctx context.Context, provider, symbol string, price float64) {
 	if s.cache == nil {
 		return
@@ -427,11 +446,3 @@ func toFloat64(v any) (float64, bool) {
 		return 0, false
 	}
 }
-
-func isUniqueViolation(err error) bool {
-	if err == nil {
-		return false
-	}
-	pgErr, ok := err.(*pq.Error)
-	return ok && pgErr.Code == "23505"
-}
@@ -0,0 +1,481 @@
+package marketpersist
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+
+	"nof0-api/internal/model"
+	"nof0-api/pkg/market"
+)
+
+const (
+	// SeriesBackendSQL stores series through the generated price_ticks,
+	// klines and market_metrics models.
+	SeriesBackendSQL = "sql"
+	// SeriesBackendTimescale stores series in TimescaleDB hypertables using batched inserts.
+	SeriesBackendTimescale = "timescale"
+
+	seriesInsertBatchSize = 500
+	seriesDefaultLimit    = 1000
+)
+
+// SeriesStore abstracts storage of historical market series (price ticks,
+// klines and market metrics) so that high-volume deployments can move
+// minute-level history off the primary relational tables without touching
+// callers. The kline ingestor, the metrics collector and the klines API all
+// go through it.
+//
+// The sql and timescale backends are built in. A ClickHouse backend is not;
+// deployments that need one register it with RegisterSeriesStore.
+type SeriesStore interface {
+	// WriteTicks persists ticks for provider/symbol. Duplicate timestamps are ignored.
+	WriteTicks(ctx context.Context, provider, symbol string, ticks []market.PriceTick) error
+	// QueryTicks returns ticks at or after since in ascending time order, capped at limit.
+	QueryTicks(ctx context.Context, provider, symbol string, since time.Time, limit int) ([]market.PriceTick, error)
+
+	// UpsertKlines writes candles, replacing the prices and volume of those
+	// already stored for the same symbol, interval and open time.
+	UpsertKlines(ctx context.Context, rows []*model.Klines) error
+	// LatestKlineOpenTime returns the open time of the newest stored candle.
+	LatestKlineOpenTime(ctx context.Context, provider, symbol, interval string) (time.Time, bool, error)
+	// QueryKlines returns the candles selected by q in ascending open time.
+	QueryKlines(ctx context.Context, q model.KlineRangeQuery) ([]model.Klines, error)
+
+	// WriteMetrics persists metric readings. A reading already stored for the
+	// same symbol and event time is skipped.
+	WriteMetrics(ctx context.Context, rows []*model.MarketMetrics) error
+	// QueryMetrics returns the readings of a symbol at or after since, oldest first.
+	QueryMetrics(ctx context.Context, provider, symbol string, since time.Time) ([]*model.MarketMetrics, error)
+}
+
+// SeriesStoreBuilder constructs a SeriesStore from the persistence config.
+type SeriesStoreBuilder func(cfg Config) (SeriesStore, error)
+
+var (
+	seriesMu       sync.RWMutex
+	seriesBuilders = map[string]SeriesStoreBuilder{
+		SeriesBackendSQL:       newSQLSeriesStore,
+		SeriesBackendTimescale: newTimescaleSeriesStore,
+	}
+)
+
+// RegisterSeriesStore registers a series storage backend under name, allowing
+// out-of-tree implementations (e.g. ClickHouse behind a build tag).
+func RegisterSeriesStore(name string, builder SeriesStoreBuilder) {
+	seriesMu.Lock()
+	defer seriesMu.Unlock()
+	seriesBuilders[strings.ToLower(strings.TrimSpace(name))] = builder
+}
+
+// NewSeriesStore resolves the configured backend, defaulting to SQL.
+func NewSeriesStore(cfg Config) (SeriesStore, error) {
+	name := strings.ToLower(strings.TrimSpace(cfg.SeriesBackend))
+	if name == "" {
+		name = SeriesBackendSQL
+	}
+	seriesMu.RLock()
+	builder, ok := seriesBuilders[name]
+	seriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("marketpersist: unknown series backend %q", cfg.SeriesBackend)
+	}
+	return builder(cfg)
+}
+
+var errNoSeriesModel = errors.New("marketpersist: sql series backend has no model for this series")
+
+// sqlSeriesStore is the default backend writing through the goctl models.
+// The klines and metrics models are optional; without one its methods fail.
+type sqlSeriesStore struct {
+	sqlConn sqlx.SqlConn
+	model   model.PriceTicksModel
+	klines  model.KlinesModel
+	metrics model.MarketMetricsModel
+}
+
+func newSQLSeriesStore(cfg Config) (SeriesStore, error) {
+	if cfg.PriceTicksModel == nil {
+		return nil, fmt.Errorf("marketpersist: sql series backend requires PriceTicksModel")
+	}
+	return &sqlSeriesStore{
+		sqlConn: cfg.SQLConn,
+		model:   cfg.PriceTicksModel,
+		klines:  cfg.KlinesModel,
+		metrics: cfg.MarketMetricsModel,
+	}, nil
+}
+
+// WriteTicks inserts ticks in batches so the row cache is cleared once per
+// batch rather than once per tick.
+func (s *sqlSeriesStore) WriteTicks(ctx context.Context, provider, symbol string, ticks []market.PriceTick) error {
+	rows := make([]*model.PriceTicks, 0, len(ticks))
+	for _, tick := range ticks {
+		if !validTick(tick) {
+			continue
+		}
+		row := &model.PriceTicks{
+			Provider: provider,
+			Symbol:   symbol,
+			Price:    tick.Price,
+			TsMs:     tick.Timestamp.UTC().UnixMilli(),
+		}
+		if tick.HasVolume {
+			row.Volume = sql.NullFloat64{Float64: tick.Volume, Valid: true}
+		}
+		if raw := buildTickRaw(tick); raw.Valid {
+			row.Raw = raw
+		}
+		rows = append(rows, row)
+	}
+	for start := 0; start < len(rows); start += seriesInsertBatchSize {
+		end := min(start+seriesInsertBatchSize, len(rows))
+		if err := s.model.InsertBatch(ctx, rows[start:end]); err != nil {
+			return fmt.Errorf("marketpersist: insert ticks provider=%s symbol=%s count=%d: %w", provider, symbol, end-start, err)
+		}
+	}
+	return nil
+}
+
+func (s *sqlSeriesStore) QueryTicks(ctx context.Context, provider, symbol string, since time.Time, limit int) ([]market.PriceTick, error) {
+	return queryTicks(ctx, s.sqlConn, "public.price_ticks", provider, symbol, since, limit)
+}
+
+func (s *sqlSeriesStore) UpsertKlines(ctx context.Context, rows []*model.Klines) error {
+	if s.klines == nil {
+		return errNoSeriesModel
+	}
+	return s.klines.UpsertBatch(ctx, rows)
+}
+
+func (s *sqlSeriesStore) LatestKlineOpenTime(ctx context.Context, provider, symbol, interval string) (time.Time, bool, error) {
+	if s.klines == nil {
+		return time.Time{}, false, errNoSeriesModel
+	}
+	return s.klines.LatestOpenTime(ctx, provider, symbol, interval)
+}
+
+func (s *sqlSeriesStore) QueryKlines(ctx context.Context, q model.KlineRangeQuery) ([]model.Klines, error) {
+	if s.klines == nil {
+		return nil, errNoSeriesModel
+	}
+	return s.klines.Range(ctx, q)
+}
+
+func (s *sqlSeriesStore) WriteMetrics(ctx context.Context, rows []*model.MarketMetrics) error {
+	if s.metrics == nil {
+		return errNoSeriesModel
+	}
+	return s.metrics.InsertBatch(ctx, rows)
+}
+
+func (s *sqlSeriesStore) QueryMetrics(ctx context.Context, provider, symbol string, since time.Time) ([]*model.MarketMetrics, error) {
+	if s.metrics == nil {
+		return nil, errNoSeriesModel
+	}
+	return s.metrics.ListSince(ctx, provider, symbol, since)
+}
+
+// timescaleSeriesStore writes to TimescaleDB hypertables. TimescaleDB speaks
+// the Postgres wire protocol, so it reuses the shared connection. The tables
+// are expected to exist already:
+//
+//	CREATE TABLE market_price_series (LIKE price_ticks INCLUDING DEFAULTS);
+//	SELECT create_hypertable('market_price_series', by_range('ts_ms', 86400000));
+//	CREATE UNIQUE INDEX ON market_price_series (provider, symbol, ts_ms);
+//
+//	CREATE TABLE market_kline_series (LIKE klines INCLUDING DEFAULTS);
+//	SELECT create_hypertable('market_kline_series', by_range('open_time'));
+//	CREATE UNIQUE INDEX ON market_kline_series (symbol_id, interval, open_time);
+//
+//	CREATE TABLE market_metrics_series (LIKE market_metrics INCLUDING DEFAULTS);
+//	SELECT create_hypertable('market_metrics_series', by_range('event_at'));
+//	CREATE UNIQUE INDEX ON market_metrics_series (symbol_id, event_at);
+type timescaleSeriesStore struct {
+	sqlConn      sqlx.SqlConn
+	table        string
+	klinesTable  string
+	metricsTable string
+}
+
+func newTimescaleSeriesStore(cfg Config) (SeriesStore, error) {
+	if cfg.SQLConn == nil {
+		return nil, fmt.Errorf("marketpersist: timescale series backend requires SQLConn")
+	}
+	return &timescaleSeriesStore{
+		sqlConn:      cfg.SQLConn,
+		table:        tableOrDefault(cfg.SeriesTable, "public.market_price_series"),
+		klinesTable:  tableOrDefault(cfg.KlinesTable, "public.market_kline_series"),
+		metricsTable: tableOrDefault(cfg.MetricsTable, "public.market_metrics_series"),
+	}, nil
+}
+
+func tableOrDefault(table, fallback string) string {
+	if table = strings.TrimSpace(table); table != "" {
+		return table
+	}
+	return fallback
+}
+
+func (s *timescaleSeriesStore) WriteTicks(ctx context.Context, provider, symbol string, ticks []market.PriceTick) error {
+	valid := make([]market.PriceTick, 0, len(ticks))
+	for _, tick := range ticks {
+		if validTick(tick) {
+			valid = append(valid, tick)
+		}
+	}
+	for start := 0; start < len(valid); start += seriesInsertBatchSize {
+		end := start + seriesInsertBatchSize
+		if end > len(valid) {
+			end = len(valid)
+		}
+		if err := s.insertBatch(ctx, provider, symbol, valid[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *timescaleSeriesStore) insertBatch(ctx context.Context, provider, symbol string, ticks []market.PriceTick) error {
+	const cols = 6
+	values := make([]string, 0, len(ticks))
+	args := make([]any, 0, len(ticks)*cols)
+	for i, tick := range ticks {
+		base := i * cols
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)", base+1, base+2, base+3, base+4, base+5, base+6))
+		volume := sql.NullFloat64{}
+		if tick.HasVolume {
+			volume = sql.NullFloat64{Float64: tick.Volume, Valid: true}
+		}
+		args = append(args, provider, symbol, tick.Price, volume, tick.Timestamp.UTC().UnixMilli(), buildTickRaw(tick))
+	}
+	stmt := fmt.Sprintf(`INSERT INTO %s (provider, symbol, price, volume, ts_ms, raw) VALUES %s
+ON CONFLICT (provider, symbol, ts_ms) DO NOTHING`, s.table, strings.Join(values, ", "))
+	if _, err := s.sqlConn.ExecCtx(ctx, stmt, args...); err != nil {
+		return fmt.Errorf("marketpersist: timescale insert provider=%s symbol=%s count=%d: %w", provider, symbol, len(ticks), err)
+	}
+	return nil
+}
+
+func (s *timescaleSeriesStore) QueryTicks(ctx context.Context, provider, symbol string, since time.Time, limit int) ([]market.PriceTick, error) {
+	return queryTicks(ctx, s.sqlConn, s.table, provider, symbol, since, limit)
+}
+
+const (
+	klineColumns   = 12
+	metricsColumns = 15
+)
+
+// UpsertKlines writes candles in batches, replacing candles already stored
+// for the same symbol, interval and open time.
+func (s *timescaleSeriesStore) UpsertKlines(ctx context.Context, rows []*model.Klines) error {
+	for start := 0; start < len(rows); start += seriesInsertBatchSize {
+		end := min(start+seriesInsertBatchSize, len(rows))
+		batch := rows[start:end]
+		args := make([]any, 0, len(batch)*klineColumns)
+		for i, r := range batch {
+			if r == nil {
+				return fmt.Errorf("marketpersist: nil kline at %d", start+i)
+			}
+			args = append(args,
+				r.SymbolId, r.ExchangeProvider, r.Symbol, r.Interval, r.OpenTime, r.CloseTime,
+				r.OpenPrice, r.HighPrice, r.LowPrice, r.ClosePrice, r.Volume, jsonOrEmpty(r.Detail))
+		}
+		stmt := fmt.Sprintf(`INSERT INTO %s (
+    symbol_id, exchange_provider, symbol, interval, open_time, close_time,
+    open_price, high_price, low_price, close_price, volume, detail
+) VALUES %s
+ON CONFLICT (symbol_id, interval, open_time) DO UPDATE
+SET close_time = EXCLUDED.close_time,
+    open_price = EXCLUDED.open_price,
+    high_price = EXCLUDED.high_price,
+    low_price = EXCLUDED.low_price,
+    close_price = EXCLUDED.close_price,
+    volume = EXCLUDED.volume,
+    detail = EXCLUDED.detail,
+    updated_at = NOW()`, s.klinesTable, valuesClause(len(batch), klineColumns, klineColumns-1))
+		if _, err := s.sqlConn.ExecCtx(ctx, stmt, args...); err != nil {
+			return fmt.Errorf("marketpersist: timescale upsert klines count=%d: %w", len(batch), err)
+		}
+	}
+	return nil
+}
+
+func (s *timescaleSeriesStore) LatestKlineOpenTime(ctx context.Context, provider, symbol, interval string) (time.Time, bool, error) {
+	query := fmt.Sprintf(`SELECT MAX(open_time) FROM %s WHERE exchange_provider = $1 AND symbol = $2 AND interval = $3`, s.klinesTable)
+	var latest sql.NullTime
+	if err := s.sqlConn.QueryRowCtx(ctx, &latest, query, provider, symbol, interval); err != nil {
+		return time.Time{}, false, fmt.Errorf("marketpersist: timescale latest kline provider=%s symbol=%s: %w", provider, symbol, err)
+	}
+	if !latest.Valid {
+		return time.Time{}, false, nil
+	}
+	return latest.Time.UTC(), true, nil
+}
+
+// QueryKlines mirrors KlinesModel.Range: the newest Limit candles in range,
+// returned oldest first, Limit defaulting to 1500.
+func (s *timescaleSeriesStore) QueryKlines(ctx context.Context, q model.KlineRangeQuery) ([]model.Klines, error) {
+	if q.Limit <= 0 {
+		q.Limit = 1500
+	}
+	clauses := []string{"exchange_provider = $1", "symbol = $2", "interval = $3"}
+	args := []any{q.Provider, q.Symbol, q.Interval}
+	if !q.From.IsZero() {
+		args = append(args, q.From.UTC())
+		clauses = append(clauses, fmt.Sprintf("open_time >= $%d", len(args)))
+	}
+	if !q.To.IsZero() {
+		args = append(args, q.To.UTC())
+		clauses = append(clauses, fmt.Sprintf("open_time < $%d", len(args)))
+	}
+	args = append(args, q.Limit)
+	query := fmt.Sprintf(`SELECT symbol_id, exchange_provider, symbol, interval, open_time, close_time,
+    open_price, high_price, low_price, close_price, volume, detail
+FROM %s WHERE %s ORDER BY open_time DESC LIMIT $%d`, s.klinesTable, strings.Join(clauses, " AND "), len(args))
+	var rows []model.Klines
+	if err := s.sqlConn.QueryRowsPartialCtx(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("marketpersist: timescale query klines provider=%s symbol=%s: %w", q.Provider, q.Symbol, err)
+	}
+	for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+		rows[i], rows[j] = rows[j], rows[i]
+	}
+	return rows, nil
+}
+
+// WriteMetrics inserts readings in batches, skipping those already stored
+// for the same symbol and event time.
+func (s *timescaleSeriesStore) WriteMetrics(ctx context.Context, rows []*model.MarketMetrics) error {
+	for start := 0; start < len(rows); start += seriesInsertBatchSize {
+		end := min(start+seriesInsertBatchSize, len(rows))
+		batch := rows[start:end]
+		args := make([]any, 0, len(batch)*metricsColumns)
+		for i, r := range batch {
+			if r == nil {
+				return fmt.Errorf("marketpersist: nil metrics row at %d", start+i)
+			}
+			args = append(args,
+				r.SymbolId, r.ExchangeProvider, r.Symbol,
+				r.MarkPrice, r.MidPrice, r.OraclePrice, r.FundingRate, r.OpenInterest,
+				r.DayVolume, r.DayNotionalVolume, r.Change24h, r.Premium, r.PrevDayPrice,
+				jsonOrEmpty(r.Detail), r.EventAt)
+		}
+		stmt := fmt.Sprintf(`INSERT INTO %s (
+    symbol_id, exchange_provider, symbol,
+    mark_price, mid_price, oracle_price, funding_rate, open_interest,
+    day_volume, day_notional_volume, change_24h, premium, prev_day_price,
+    detail, event_at
+) VALUES %s
+ON CONFLICT (symbol_id, event_at) DO NOTHING`, s.metricsTable, valuesClause(len(batch), metricsColumns, metricsColumns-2))
+		if _, err := s.sqlConn.ExecCtx(ctx, stmt, args...); err != nil {
+			return fmt.Errorf("marketpersist: timescale insert metrics count=%d: %w", len(batch), err)
+		}
+	}
+	return nil
+}
+
+func (s *timescaleSeriesStore) QueryMetrics(ctx context.Context, provider, symbol string, since time.Time) ([]*model.MarketMetrics, error) {
+	query := fmt.Sprintf(`SELECT symbol_id, exchange_provider, symbol,
+    mark_price, mid_price, oracle_price, funding_rate, open_interest,
+    day_volume, day_notional_volume, change_24h, premium, prev_day_price,
+    detail, event_at
+FROM %s WHERE exchange_provider = $1 AND symbol = $2 AND event_at >= $3 ORDER BY event_at`, s.metricsTable)
+	var rows []*model.MarketMetrics
+	if err := s.sqlConn.QueryRowsPartialCtx(ctx, &rows, query, provider, symbol, since); err != nil {
+		return nil, fmt.Errorf("marketpersist: timescale query metrics provider=%s symbol=%s: %w", provider, symbol, err)
+	}
+	return rows, nil
+}
+
+// valuesClause returns n parenthesised groups of cols numbered placeholders,
+// casting the one at jsonCol to jsonb.
+func valuesClause(n, cols, jsonCol int) string {
+	groups := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		ph := make([]string, cols)
+		for j := range ph {
+			ph[j] = fmt.Sprintf("$%d", i*cols+j+1)
+		}
+		ph[jsonCol] += "::jsonb"
+		groups = append(groups, "("+strings.Join(ph, ", ")+")")
+	}
+	return strings.Join(groups, ", ")
+}
+
+func jsonOrEmpty(raw string) string {
+	if raw == "" {
+		return "{}"
+	}
+	return raw
+}
+
+type tickRow struct {
+	Price  float64         `db:"price"`
+	Volume sql.NullFloat64 `db:"volume"`
+	TsMs   int64           `db:"ts_ms"`
+	Raw    sql.NullString  `db:"raw"`
+}
+
+// queryTicks reads the newest ticks after since and returns them oldest first.
+func queryTicks(ctx context.Context, conn sqlx.SqlConn, table, provider, symbol string, since time.Time, limit int) ([]market.PriceTick, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("marketpersist: series query requires SQLConn")
+	}
+	if limit <= 0 {
+		limit = seriesDefaultLimit
+	}
+	query := fmt.Sprintf(`SELECT price, volume, ts_ms, raw FROM %s
+WHERE provider = $1 AND symbol = $2 AND ts_ms >= $3
+ORDER BY ts_ms DESC LIMIT $4`, table)
+	var rows []tickRow
+	if err := conn.QueryRowsPartialCtx(ctx, &rows, query, provider, symbol, since.UTC().UnixMilli(), limit); err != nil {
+		return nil, fmt.Errorf("marketpersist: query series provider=%s symbol=%s: %w", provider, symbol, err)
+	}
+	out := make([]market.PriceTick, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, tickFromRow(row))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Timestamp.Before(out[j].Timestamp) })
+	return out, nil
+}
+
+func tickFromRow(row tickRow) market.PriceTick {
+	tick := market.PriceTick{
+		Timestamp: time.UnixMilli(row.TsMs).UTC(),
+		Price:     row.Price,
+		Close:     row.Price,
+	}
+	if row.Volume.Valid {
+		tick.Volume = row.Volume.Float64
+		tick.HasVolume = true
+	}
+	if row.Raw.Valid {
+		var raw struct {
+			Interval string  `json:"interval"`
+			Open     float64 `json:"open"`
+			High     float64 `json:"high"`
+			Low      float64 `json:"low"`
+			Close    float64 `json:"close"`
+		}
+		if err := json.Unmarshal([]byte(row.Raw.String), &raw); err == nil {
+			tick.Interval = raw.Interval
+			tick.Open, tick.High, tick.Low = raw.Open, raw.High, raw.Low
+			if raw.Close > 0 {
+				tick.Close = raw.Close
+			}
+		}
+	}
+	return tick
+}
+
+func validTick(tick market.PriceTick) bool {
+	return !tick.Timestamp.IsZero() && tick.Price > 0
+}
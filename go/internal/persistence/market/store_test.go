@@ -0,0 +1,291 @@
+package marketpersist
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+
+	"nof0-api/internal/model"
+	"nof0-api/pkg/market"
+)
+
+type sqlCall struct {
+	query string
+	args  []any
+}
+
+// recordingConn records statements and answers queries with canned rows.
+type recordingConn struct {
+	sqlx.SqlConn
+	execs   []sqlCall
+	queries []sqlCall
+	// rows is copied into the destination of every query.
+	rows any
+}
+
+func (c *recordingConn) ExecCtx(_ context.Context, query string, args ...any) (sql.Result, error) {
+	c.execs = append(c.execs, sqlCall{query: query, args: args})
+	return nil, nil
+}
+
+func (c *recordingConn) QueryRowCtx(_ context.Context, v any, query string, args ...any) error {
+	return c.answer(v, query, args)
+}
+
+func (c *recordingConn) QueryRowsPartialCtx(_ context.Context, v any, query string, args ...any) error {
+	return c.answer(v, query, args)
+}
+
+func (c *recordingConn) answer(v any, query string, args []any) error {
+	c.queries = append(c.queries, sqlCall{query: query, args: args})
+	if c.rows != nil {
+		reflect.ValueOf(v).Elem().Set(reflect.ValueOf(c.rows))
+	}
+	return nil
+}
+
+type fakeTicksModel struct {
+	model.PriceTicksModel
+	batches [][]*model.PriceTicks
+}
+
+func (m *fakeTicksModel) InsertBatch(_ context.Context, rows []*model.PriceTicks) error {
+	m.batches = append(m.batches, rows)
+	return nil
+}
+
+type fakeKlinesModel struct {
+	model.KlinesModel
+	upserts [][]*model.Klines
+	ranges  []model.KlineRangeQuery
+}
+
+func (m *fakeKlinesModel) UpsertBatch(_ context.Context, rows []*model.Klines) error {
+	m.upserts = append(m.upserts, rows)
+	return nil
+}
+
+func (m *fakeKlinesModel) LatestOpenTime(context.Context, string, string, string) (time.Time, bool, error) {
+	return time.Unix(60, 0).UTC(), true, nil
+}
+
+func (m *fakeKlinesModel) Range(_ context.Context, q model.KlineRangeQuery) ([]model.Klines, error) {
+	m.ranges = append(m.ranges, q)
+	return []model.Klines{{Symbol: q.Symbol}}, nil
+}
+
+type fakeMetricsModel struct {
+	model.MarketMetricsModel
+	inserts [][]*model.MarketMetrics
+	since   []time.Time
+}
+
+func (m *fakeMetricsModel) InsertBatch(_ context.Context, rows []*model.MarketMetrics) error {
+	m.inserts = append(m.inserts, rows)
+	return nil
+}
+
+func (m *fakeMetricsModel) ListSince(_ context.Context, _, symbol string, since time.Time) ([]*model.MarketMetrics, error) {
+	m.since = append(m.since, since)
+	return []*model.MarketMetrics{{Symbol: symbol}}, nil
+}
+
+func klineRows(n int) []*model.Klines {
+	open := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := make([]*model.Klines, n)
+	for i := range rows {
+		rows[i] = &model.Klines{
+			SymbolId:         "hl/BTC",
+			ExchangeProvider: "hl",
+			Symbol:           "BTC",
+			Interval:         "1m",
+			OpenTime:         open.Add(time.Duration(i) * time.Minute),
+			CloseTime:        open.Add(time.Duration(i+1)*time.Minute - time.Millisecond),
+			ClosePrice:       100,
+		}
+	}
+	return rows
+}
+
+func TestNewSeriesStore(t *testing.T) {
+	conn := &recordingConn{}
+	tests := []struct {
+		name    string
+		cfg     Config
+		want    any
+		wantErr string
+	}{
+		{name: "default is sql", cfg: Config{PriceTicksModel: &fakeTicksModel{}}, want: &sqlSeriesStore{}},
+		{name: "sql needs the ticks model", cfg: Config{}, wantErr: "requires PriceTicksModel"},
+		{name: "timescale", cfg: Config{SQLConn: conn, SeriesBackend: " Timescale "}, want: &timescaleSeriesStore{}},
+		{name: "timescale needs a connection", cfg: Config{SeriesBackend: "timescale"}, wantErr: "requires SQLConn"},
+		{name: "clickhouse is not built in", cfg: Config{SeriesBackend: "clickhouse"}, wantErr: `unknown series backend "clickhouse"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewSeriesStore(tt.cfg)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.want, store)
+		})
+	}
+}
+
+func TestSQLSeriesStoreRoutesThroughModels(t *testing.T) {
+	ctx := context.Background()
+	ticks := &fakeTicksModel{}
+	klines := &fakeKlinesModel{}
+	metrics := &fakeMetricsModel{}
+	store, err := NewSeriesStore(Config{PriceTicksModel: ticks, KlinesModel: klines, MarketMetricsModel: metrics})
+	require.NoError(t, err)
+
+	series := make([]market.PriceTick, seriesInsertBatchSize+2)
+	for i := range series {
+		series[i] = market.PriceTick{Timestamp: time.UnixMilli(int64(i + 1)), Price: 100}
+	}
+	series[0].Price = 0 // invalid, skipped
+	require.NoError(t, store.WriteTicks(ctx, "hl", "BTC", series))
+	require.Len(t, ticks.batches, 2)
+	assert.Len(t, ticks.batches[0], seriesInsertBatchSize)
+	assert.Len(t, ticks.batches[1], 1)
+
+	rows := klineRows(2)
+	require.NoError(t, store.UpsertKlines(ctx, rows))
+	assert.Equal(t, [][]*model.Klines{rows}, klines.upserts)
+	latest, ok, err := store.LatestKlineOpenTime(ctx, "hl", "BTC", "1m")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Unix(60, 0).UTC(), latest)
+	q := model.KlineRangeQuery{Provider: "hl", Symbol: "BTC", Interval: "1m", Limit: 10}
+	got, err := store.QueryKlines(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, []model.KlineRangeQuery{q}, klines.ranges)
+	assert.Equal(t, "BTC", got[0].Symbol)
+
+	readings := []*model.MarketMetrics{{Symbol: "BTC"}}
+	require.NoError(t, store.WriteMetrics(ctx, readings))
+	assert.Equal(t, [][]*model.MarketMetrics{readings}, metrics.inserts)
+	since := time.Unix(0, 0)
+	listed, err := store.QueryMetrics(ctx, "hl", "ETH", since)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{since}, metrics.since)
+	assert.Equal(t, "ETH", listed[0].Symbol)
+}
+
+func TestSQLSeriesStoreWithoutSeriesModels(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewSeriesStore(Config{PriceTicksModel: &fakeTicksModel{}})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, store.UpsertKlines(ctx, klineRows(1)), errNoSeriesModel)
+	_, err = store.QueryKlines(ctx, model.KlineRangeQuery{})
+	assert.ErrorIs(t, err, errNoSeriesModel)
+	assert.ErrorIs(t, store.WriteMetrics(ctx, nil), errNoSeriesModel)
+	_, err = store.QueryMetrics(ctx, "hl", "BTC", time.Time{})
+	assert.ErrorIs(t, err, errNoSeriesModel)
+}
+
+func TestTimescaleUpsertKlines(t *testing.T) {
+	conn := &recordingConn{}
+	store, err := NewSeriesStore(Config{SQLConn: conn, SeriesBackend: SeriesBackendTimescale})
+	require.NoError(t, err)
+
+	require.NoError(t, store.UpsertKlines(context.Background(), klineRows(seriesInsertBatchSize+1)))
+
+	require.Len(t, conn.execs, 2, "one statement per batch")
+	first := conn.execs[0]
+	assert.Contains(t, first.query, "INSERT INTO public.market_kline_series")
+	assert.Contains(t, first.query, "($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12::jsonb), ($13,")
+	assert.Contains(t, first.query, "ON CONFLICT (symbol_id, interval, open_time) DO UPDATE")
+	assert.Len(t, first.args, seriesInsertBatchSize*klineColumns)
+	assert.Equal(t, "{}", first.args[klineColumns-1], "empty detail defaults to an empty object")
+	assert.Len(t, conn.execs[1].args, klineColumns)
+}
+
+func TestTimescaleQueryKlines(t *testing.T) {
+	conn := &recordingConn{rows: []model.Klines{{ClosePrice: 2}, {ClosePrice: 1}}}
+	store, err := NewSeriesStore(Config{SQLConn: conn, SeriesBackend: SeriesBackendTimescale, KlinesTable: "ts.klines"})
+	require.NoError(t, err)
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rows, err := store.QueryKlines(context.Background(), model.KlineRangeQuery{Provider: "hl", Symbol: "BTC", Interval: "1m", From: from})
+	require.NoError(t, err)
+
+	require.Len(t, conn.queries, 1)
+	query := conn.queries[0]
+	assert.Contains(t, query.query, "FROM ts.klines WHERE exchange_provider = $1 AND symbol = $2 AND interval = $3 AND open_time >= $4 ORDER BY open_time DESC LIMIT $5")
+	assert.Equal(t, []any{"hl", "BTC", "1m", from, 1500}, query.args)
+	require.Len(t, rows, 2)
+	assert.Equal(t, 1.0, rows[0].ClosePrice, "returned oldest first")
+}
+
+func TestTimescaleLatestKlineOpenTime(t *testing.T) {
+	conn := &recordingConn{rows: sql.NullTime{}}
+	store, err := NewSeriesStore(Config{SQLConn: conn, SeriesBackend: SeriesBackendTimescale})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, ok, err := store.LatestKlineOpenTime(ctx, "hl", "BTC", "1m")
+	require.NoError(t, err)
+	assert.False(t, ok, "empty table")
+
+	open := time.Date(2025, 1, 1, 8, 0, 0, 0, time.FixedZone("UTC+8", 8*3600))
+	conn.rows = sql.NullTime{Time: open, Valid: true}
+	latest, ok, err := store.LatestKlineOpenTime(ctx, "hl", "BTC", "1m")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, open.UTC(), latest)
+	assert.Equal(t, time.UTC, latest.Location())
+}
+
+func TestTimescaleMetrics(t *testing.T) {
+	conn := &recordingConn{}
+	store, err := NewSeriesStore(Config{SQLConn: conn, SeriesBackend: SeriesBackendTimescale, MetricsTable: "ts.metrics"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.WriteMetrics(ctx, []*model.MarketMetrics{{Symbol: "BTC"}, {Symbol: "ETH", Detail: `{"k":1}`}}))
+	require.Len(t, conn.execs, 1)
+	insert := conn.execs[0]
+	assert.Contains(t, insert.query, "INSERT INTO ts.metrics")
+	assert.Contains(t, insert.query, "$14::jsonb, $15), ($16,")
+	assert.Contains(t, insert.query, "ON CONFLICT (symbol_id, event_at) DO NOTHING")
+	require.Len(t, insert.args, 2*metricsColumns)
+	assert.Equal(t, "{}", insert.args[metricsColumns-2])
+	assert.Equal(t, `{"k":1}`, insert.args[2*metricsColumns-2])
+
+	conn.rows = []*model.MarketMetrics{{Symbol: "BTC"}}
+	since := time.Unix(0, 0)
+	rows, err := store.QueryMetrics(ctx, "hl", "BTC", since)
+	require.NoError(t, err)
+	assert.Len(t, rows, 1)
+	require.Len(t, conn.queries, 1)
+	assert.True(t, strings.HasSuffix(conn.queries[0].query, "FROM ts.metrics WHERE exchange_provider = $1 AND symbol = $2 AND event_at >= $3 ORDER BY event_at"))
+	assert.Equal(t, []any{"hl", "BTC", since}, conn.queries[0].args)
+}
+
+func TestTimescaleWriteTicks(t *testing.T) {
+	conn := &recordingConn{}
+	store, err := NewSeriesStore(Config{SQLConn: conn, SeriesBackend: SeriesBackendTimescale})
+	require.NoError(t, err)
+
+	require.NoError(t, store.WriteTicks(context.Background(), "hl", "BTC", []market.PriceTick{
+		{Timestamp: time.UnixMilli(1), Price: 100},
+		{Timestamp: time.UnixMilli(2)}, // no price, skipped
+	}))
+
+	require.Len(t, conn.execs, 1)
+	assert.Contains(t, conn.execs[0].query, "INSERT INTO public.market_price_series")
+	assert.Contains(t, conn.execs[0].query, "ON CONFLICT (provider, symbol, ts_ms) DO NOTHING")
+	assert.Len(t, conn.execs[0].args, 6)
+}
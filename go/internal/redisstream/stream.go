@@ -0,0 +1,76 @@
+// Package redisstream appends to and reads the capped Redis streams the
+// trading engine publishes on. Every entry carries its payload in a single
+// "data" field. go-zero's redis client exposes no XRANGE/XREVRANGE and its
+// XAdd has no MAXLEN option, so the calls go through Lua wrappers.
+package redisstream
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+const (
+	xaddTrimmedScript = `return redis.call('XADD', KEYS[1], 'MAXLEN', '~', ARGV[1], '*', 'data', ARGV[2])`
+	xrangeAfterScript = `return redis.call('XRANGE', KEYS[1], ARGV[1], '+', 'COUNT', ARGV[2])`
+	xrevrangeScript   = `return redis.call('XREVRANGE', KEYS[1], '+', '-', 'COUNT', ARGV[1])`
+)
+
+// Entry is one stream entry: its id and "data" field.
+type Entry struct {
+	ID   string
+	Data string
+}
+
+// Append adds data to the stream at key, trimming it to about maxLen entries.
+func Append(ctx context.Context, rds *redis.Redis, key string, maxLen int, data string) error {
+	_, err := rds.EvalCtx(ctx, xaddTrimmedScript, []string{key}, maxLen, data)
+	return err
+}
+
+// Latest returns the newest count entries of the stream at key, oldest first.
+func Latest(ctx context.Context, rds *redis.Redis, key string, count int) ([]Entry, error) {
+	raw, err := rds.EvalCtx(ctx, xrevrangeScript, []string{key}, count)
+	if err != nil {
+		return nil, err
+	}
+	entries := ParseEntries(raw)
+	// XREVRANGE is newest first.
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// After returns up to count entries of the stream at key following the entry
+// id after, oldest first.
+func After(ctx context.Context, rds *redis.Redis, key, after string, count int) ([]Entry, error) {
+	raw, err := rds.EvalCtx(ctx, xrangeAfterScript, []string{key}, "("+after, count)
+	if err != nil {
+		return nil, err
+	}
+	return ParseEntries(raw), nil
+}
+
+// ParseEntries converts an XRANGE reply ([[id, [field, value, ...]], ...])
+// into entries, skipping those without a "data" field.
+func ParseEntries(raw any) []Entry {
+	items, _ := raw.([]any)
+	out := make([]Entry, 0, len(items))
+	for _, item := range items {
+		pair, ok := item.([]any)
+		if !ok || len(pair) != 2 {
+			continue
+		}
+		id, _ := pair[0].(string)
+		fields, _ := pair[1].([]any)
+		for i := 0; i+1 < len(fields); i += 2 {
+			if name, _ := fields[i].(string); name == "data" {
+				data, _ := fields[i+1].(string)
+				out = append(out, Entry{ID: id, Data: data})
+				break
+			}
+		}
+	}
+	return out
+}
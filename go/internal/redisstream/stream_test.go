@@ -0,0 +1,23 @@
+package redisstream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEntries(t *testing.T) {
+	raw := []any{
+		[]any{"1-0", []any{"data", `{"trader_id":"t1","stage":"thinking"}`}},
+		[]any{"2-0", []any{"other", "x"}},
+		"garbage",
+		[]any{"3-0", []any{"kind", "x", "data", `{"trader_id":"t2"}`}},
+	}
+	entries := ParseEntries(raw)
+	require.Len(t, entries, 2)
+	assert.Equal(t, Entry{ID: "1-0", Data: `{"trader_id":"t1","stage":"thinking"}`}, entries[0])
+	assert.Equal(t, "3-0", entries[1].ID)
+
+	assert.Empty(t, ParseEntries(nil))
+}
@@ -0,0 +1,65 @@
+// Package share signs and verifies public read-only share links.
+//
+// A token is "<payload>.<signature>", both base64url without padding. The
+// payload is "<modelId>|<expiresAt unix seconds>" and the signature is
+// HMAC-SHA256 over the encoded payload, so links cannot be forged or
+// extended without the server secret.
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("share: invalid link")
+	ErrExpired      = errors.New("share: link expired")
+)
+
+// Claims is the content of a verified share token.
+type Claims struct {
+	ModelID   string
+	ExpiresAt time.Time
+}
+
+// Sign returns a token granting read access to modelID until expiresAt.
+func Sign(secret []byte, modelID string, expiresAt time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(modelID + "|" + strconv.FormatInt(expiresAt.Unix(), 10)))
+	return payload + "." + signature(secret, payload)
+}
+
+// Verify checks the signature and expiry of token at now.
+func Verify(secret []byte, token string, now time.Time) (Claims, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(signature(secret, payload))) {
+		return Claims{}, ErrInvalidToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	modelID, expStr, ok := strings.Cut(string(raw), "|")
+	if !ok || modelID == "" {
+		return Claims{}, ErrInvalidToken
+	}
+	exp, err := strconv.ParseInt(expStr, 10, 64)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	claims := Claims{ModelID: modelID, ExpiresAt: time.Unix(exp, 0).UTC()}
+	if !now.Before(claims.ExpiresAt) {
+		return claims, ErrExpired
+	}
+	return claims, nil
+}
+
+func signature(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
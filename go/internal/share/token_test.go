@@ -0,0 +1,35 @@
+package share
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := []byte("k1")
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	token := Sign(secret, "qwen3-max", now.Add(time.Hour))
+
+	claims, err := Verify(secret, token, now)
+	require.NoError(t, err)
+	assert.Equal(t, "qwen3-max", claims.ModelID)
+	assert.True(t, claims.ExpiresAt.Equal(now.Add(time.Hour)))
+
+	_, err = Verify(secret, token, now.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrExpired)
+
+	_, err = Verify([]byte("k2"), token, now)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Re-pointing the payload at another model breaks the signature.
+	other := Sign(secret, "gpt-5", now.Add(time.Hour))
+	forged := other[:len(other)-len(token[len(token)-43:])] + token[len(token)-43:]
+	_, err = Verify(secret, forged, now)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = Verify(secret, "garbage", now)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -13,10 +13,14 @@ import (
 	"github.com/zeromicro/go-zero/core/stores/sqlc"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
 	"github.com/zeromicro/go-zero/core/syncx"
+	"github.com/zeromicro/go-zero/rest"
 
 	"nof0-api/internal/config"
 	"nof0-api/internal/data"
+	"nof0-api/internal/live"
+	"nof0-api/internal/middleware"
 	"nof0-api/internal/model"
+	marketpersist "nof0-api/internal/persistence/market"
 	"nof0-api/pkg/confkit"
 	exchangepkg "nof0-api/pkg/exchange"
 	_ "nof0-api/pkg/exchange/hyperliquid"
@@ -34,6 +38,12 @@ type ServiceContext struct {
 
 	DataLoader *data.DataLoader
 
+	LogStreamAuth          rest.Middleware
+	ShareIssuerAuth        rest.Middleware
+	TemplatesAuth          rest.Middleware
+	WidgetEquityLimit      rest.Middleware
+	WidgetLeaderboardLimit rest.Middleware
+
 	LLMConfig              *llmpkg.Config
 	ExecutorConfig         *executorpkg.Config
 	ManagerConfig          *managerpkg.Config
@@ -48,11 +58,14 @@ type ServiceContext struct {
 	ManagerTraderExchange  map[string]exchangepkg.Provider
 	ManagerTraderMarket    map[string]marketpkg.Provider
 
-	// Optional DB models (injected but unused by handlers/logic for now)
+	// Optional DB models; list endpoints read from them when present and
+	// fall back to DataLoader otherwise.
 	DBConn                      sqlx.SqlConn
+	BulkConn                    sqlx.SqlConn // ingestion writers; DBConn unless Postgres.Bulk is set
 	CachedConn                  *sqlc.CachedConn
 	Cache                       cache.Cache
 	Redis                       *redis.Redis
+	LiveHub                     *live.Hub
 	ModelsModel                 model.ModelsModel
 	SymbolsModel                model.SymbolsModel
 	PriceTicksModel             model.PriceTicksModel
@@ -63,22 +76,42 @@ type ServiceContext struct {
 	ConversationsModel          model.ConversationsModel
 	ConversationMessagesModel   model.ConversationMessagesModel
 	DecisionCyclesModel         model.DecisionCyclesModel
+	CycleEventsModel            model.CycleEventsModel
+	TradeJournalModel           model.TradeJournalModel
 	MarketAssetsModel           model.MarketAssetsModel
 	TraderStateModel            model.TraderStateModel
 	TraderConfigModel           model.TraderConfigModel
 	TraderConfigHistoryModel    model.TraderConfigHistoryModel
 	TraderRuntimeStateModel     model.TraderRuntimeStateModel
 	TraderSymbolCooldownsModel  model.TraderSymbolCooldownsModel
-	TraderConfigRepo            repo.TraderConfigRepository
-	TraderRuntimeRepo           repo.TraderRuntimeRepository
+	KlinesModel                 model.KlinesModel
+	MarketMetricsModel          model.MarketMetricsModel
+	// MarketSeries stores price ticks, klines and market metrics on the
+	// configured MarketStorage backend; nil without Postgres.
+	MarketSeries          marketpersist.SeriesStore
+	AccountSnapshotsModel model.AccountSnapshotsModel
+	TraderConfigRepo      repo.TraderConfigRepository
+	TraderRuntimeRepo     repo.TraderRuntimeRepository
+}
+
+func newRateLimit(w config.WidgetConf, limit config.RateLimitConf) rest.Middleware {
+	return middleware.NewRateLimitMiddleware(limit.Quota, limit.Period, middleware.RateLimitOptions{
+		TrustForwardedFor: w.TrustForwardedFor,
+		MaxClients:        limit.MaxClients,
+	}).Handle
 }
 
 func NewServiceContext(c config.Config, mainConfigPath string) *ServiceContext {
 	configureLogging(c.Logging)
 
 	svc := &ServiceContext{
-		Config:     c,
-		DataLoader: data.NewDataLoader(c.DataPath),
+		Config:                 c,
+		DataLoader:             data.NewDataLoader(c.DataPath),
+		LogStreamAuth:          middleware.NewTokenAuthMiddleware(c.LogStream.Token, "LogStream.Token").Handle,
+		ShareIssuerAuth:        middleware.NewTokenAuthMiddleware(c.Share.IssuerToken, "Share.IssuerToken").Handle,
+		TemplatesAuth:          middleware.NewTokenAuthMiddleware(c.Templates.Token, "Templates.Token").Handle,
+		WidgetEquityLimit:      newRateLimit(c.Widget, c.Widget.Equity),
+		WidgetLeaderboardLimit: newRateLimit(c.Widget, c.Widget.Leaderboard),
 	}
 
 	cacheNodes := filterCacheNodes(c.Cache)
@@ -99,19 +132,33 @@ func NewServiceContext(c config.Config, mainConfigPath string) *ServiceContext {
 			}
 		}
 	}
+	if svc.Redis != nil {
+		svc.LiveHub = live.NewHub(live.NewRedisSource(svc.Redis), c.Live.MaxClients)
+	}
 	var rawDB *sql.DB
 	if strings.TrimSpace(c.Postgres.DataSource) != "" {
 		if !hasCache {
 			log.Fatalf("cache configuration required when postgres is enabled")
 		}
-		conn := sqlx.NewSqlConn("pgx", c.Postgres.DataSource)
-		raw, err := conn.RawDB()
+		conn, raw, err := model.NewPostgresConn(c.Postgres.DataSource, postgresPool("main", c.Postgres))
 		if err != nil {
-			log.Fatalf("failed to init postgres raw db: %v", err)
+			log.Fatalf("failed to init postgres: %v", err)
 		}
-		applyPostgresPool(raw, c.Postgres)
 		rawDB = raw
 		svc.DBConn = conn
+		svc.BulkConn = conn
+		if c.Postgres.Bulk.MaxOpen > 0 {
+			bulk := postgresPool("bulk", c.Postgres)
+			bulk.MaxOpen = c.Postgres.Bulk.MaxOpen
+			bulk.MaxIdle = c.Postgres.Bulk.MaxIdle
+			if c.Postgres.Bulk.StatementTimeout > 0 {
+				bulk.StatementTimeout = c.Postgres.Bulk.StatementTimeout
+			}
+			svc.BulkConn, _, err = model.NewPostgresConn(c.Postgres.DataSource, bulk)
+			if err != nil {
+				log.Fatalf("failed to init postgres bulk pool: %v", err)
+			}
+		}
 		if svc.Cache != nil {
 			cached := sqlc.NewConnWithCache(conn, svc.Cache)
 			svc.CachedConn = &cached
@@ -186,7 +233,7 @@ func NewServiceContext(c config.Config, mainConfigPath string) *ServiceContext {
 		digests := make(map[string]string, len(managerCfg.Traders))
 		for i := range managerCfg.Traders {
 			tr := &managerCfg.Traders[i]
-			renderer, err := managerpkg.NewPromptRenderer(tr.PromptTemplate, managerPromptGuard)
+			renderer, err := managerpkg.NewPromptRendererFS(managerCfg.TemplateFS, tr.PromptTemplate, managerPromptGuard, append(managerCfg.TemplateOptions(), llmpkg.WithPinnedDigest(tr.PromptTemplateDigest))...)
 			if err != nil {
 				log.Fatalf("failed to init manager prompt renderer for trader %s: %v", tr.ID, err)
 			}
@@ -272,12 +319,12 @@ func NewServiceContext(c config.Config, mainConfigPath string) *ServiceContext {
 		}
 	}
 
-	// Only inject DB models when DSN provided; business logic still uses DataLoader.
+	// Only inject DB models when DSN provided; without them logic uses DataLoader.
 	if svc.DBConn != nil {
 		conn := svc.DBConn
 		svc.ModelsModel = model.NewModelsModel(conn, cacheNodes, cacheOpts...)
 		svc.SymbolsModel = model.NewSymbolsModel(conn, cacheNodes, cacheOpts...)
-		svc.PriceTicksModel = model.NewPriceTicksModel(conn, cacheNodes, cacheOpts...)
+		svc.PriceTicksModel = model.NewPriceTicksModel(svc.BulkConn, cacheNodes, cacheOpts...)
 		svc.AccountsModel = model.NewAccountsModel(conn, cacheNodes, cacheOpts...)
 		svc.AccountEquitySnapshotsModel = model.NewAccountEquitySnapshotsModel(conn, cacheNodes, cacheOpts...)
 		svc.PositionsModel = model.NewPositionsModel(conn, cacheNodes, cacheOpts...)
@@ -285,12 +332,31 @@ func NewServiceContext(c config.Config, mainConfigPath string) *ServiceContext {
 		svc.ConversationsModel = model.NewConversationsModel(conn, cacheNodes, cacheOpts...)
 		svc.ConversationMessagesModel = model.NewConversationMessagesModel(conn, cacheNodes, cacheOpts...)
 		svc.DecisionCyclesModel = model.NewDecisionCyclesModel(conn, cacheNodes, cacheOpts...)
+		svc.CycleEventsModel = model.NewCycleEventsModel(conn, cacheNodes, cacheOpts...)
+		svc.TradeJournalModel = model.NewTradeJournalModel(conn, cacheNodes, cacheOpts...)
 		svc.MarketAssetsModel = model.NewMarketAssetsModel(conn, cacheNodes, cacheOpts...)
 		svc.TraderStateModel = model.NewTraderStateModel(conn, cacheNodes, cacheOpts...)
 		svc.TraderConfigModel = model.NewTraderConfigModel(conn, cacheNodes, cacheOpts...)
 		svc.TraderConfigHistoryModel = model.NewTraderConfigHistoryModel(conn, cacheNodes, cacheOpts...)
 		svc.TraderRuntimeStateModel = model.NewTraderRuntimeStateModel(conn, cacheNodes, cacheOpts...)
 		svc.TraderSymbolCooldownsModel = model.NewTraderSymbolCooldownsModel(conn, cacheNodes, cacheOpts...)
+		svc.KlinesModel = model.NewKlinesModel(svc.BulkConn, cacheNodes, cacheOpts...)
+		svc.MarketMetricsModel = model.NewMarketMetricsModel(svc.BulkConn, cacheNodes, cacheOpts...)
+		svc.AccountSnapshotsModel = model.NewAccountSnapshotsModel(conn, cacheNodes, cacheOpts...)
+		series, err := marketpersist.NewSeriesStore(marketpersist.Config{
+			SQLConn:            svc.BulkConn,
+			PriceTicksModel:    svc.PriceTicksModel,
+			KlinesModel:        svc.KlinesModel,
+			MarketMetricsModel: svc.MarketMetricsModel,
+			SeriesBackend:      c.MarketStorage.Backend,
+			SeriesTable:        c.MarketStorage.Table,
+			KlinesTable:        c.MarketStorage.KlinesTable,
+			MetricsTable:       c.MarketStorage.MetricsTable,
+		})
+		if err != nil {
+			log.Fatalf("failed to init market series store: %v", err)
+		}
+		svc.MarketSeries = series
 		if rawDB != nil {
 			svc.TraderConfigRepo = repo.NewTraderConfigRepository(
 				svc.TraderConfigModel,
@@ -307,17 +373,15 @@ func NewServiceContext(c config.Config, mainConfigPath string) *ServiceContext {
 	return svc
 }
 
-func applyPostgresPool(db *sql.DB, cfg config.PostgresConf) {
-	if cfg.MaxIdle > 0 {
-		db.SetMaxIdleConns(cfg.MaxIdle)
-	}
-	if cfg.MaxOpen > 0 {
-		db.SetMaxOpenConns(cfg.MaxOpen)
-	}
-	if cfg.MaxLifetime > 0 {
-		db.SetConnMaxLifetime(cfg.MaxLifetime)
-	} else {
-		db.SetConnMaxLifetime(5 * time.Minute)
+func postgresPool(name string, cfg config.PostgresConf) model.PoolConf {
+	return model.PoolConf{
+		Name:             name,
+		MaxOpen:          cfg.MaxOpen,
+		MaxIdle:          cfg.MaxIdle,
+		MaxLifetime:      cfg.MaxLifetime,
+		MaxIdleTime:      cfg.MaxIdleTime,
+		StatementTimeout: cfg.StatementTimeout,
+		SlowQuery:        cfg.SlowQuery,
 	}
 }
 
@@ -253,13 +253,25 @@ type Trade struct {
 	TotalCommissionDollars float64     `json:"total_commission_dollars"`
 }
 
+type TradesRequest struct {
+	ModelId string `form:"modelId,optional"`
+	From    int64  `form:"from,optional"`
+	To      int64  `form:"to,optional"`
+	Limit   int    `form:"limit,optional"`
+	Cursor  string `form:"cursor,optional"`
+}
+
 type TradesResponse struct {
 	Trades     []Trade `json:"trades"`
+	NextCursor string  `json:"next_cursor,omitempty"`
 	ServerTime int64   `json:"serverTime"`
 }
 
 type PositionsRequest struct {
-	Limit int `form:"limit,optional,default=1000"`
+	Limit   int    `form:"limit,optional,default=1000"`
+	ModelId string `form:"modelId,optional"`
+	From    int64  `form:"from,optional"`
+	To      int64  `form:"to,optional"`
 }
 
 type PositionsByModel struct {
@@ -287,3 +299,474 @@ type ConversationsResponse struct {
 	Conversations []Conversation `json:"conversations"`
 	ServerTime    int64          `json:"serverTime"`
 }
+
+type CyclesLatestRequest struct {
+	ModelId string `form:"modelId,optional"`
+}
+
+type CycleDataStats struct {
+	FetchMs            int64    `json:"fetch_ms"`
+	AgeMs              int64    `json:"age_ms"`
+	Symbols            int      `json:"symbols"`
+	UnavailableSymbols []string `json:"unavailable_symbols,omitempty"`
+}
+
+type CyclePromptStats struct {
+	Digest           string `json:"digest,omitempty"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+}
+
+type CycleLLMStats struct {
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type CycleDecision struct {
+	Symbol          string  `json:"symbol"`
+	Action          string  `json:"action"`
+	Confidence      int     `json:"confidence"`
+	PositionSizeUsd float64 `json:"position_size_usd"`
+}
+
+type CycleRiskVerdict struct {
+	Verdict     string `json:"verdict"`
+	Reason      string `json:"reason,omitempty"`
+	CappedOpens int    `json:"capped_opens,omitempty"`
+}
+
+type CycleExecution struct {
+	Symbol string `json:"symbol"`
+	Action string `json:"action"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+type CycleBreakdown struct {
+	TraderId   string           `json:"trader_id"`
+	TraceId    string           `json:"trace_id"`
+	StartedAt  string           `json:"started_at"`
+	DurationMs int64            `json:"duration_ms"`
+	Outcome    string           `json:"outcome"`
+	Reason     string           `json:"reason,omitempty"`
+	Data       CycleDataStats   `json:"data"`
+	Prompt     CyclePromptStats `json:"prompt"`
+	Llm        CycleLLMStats    `json:"llm"`
+	Decisions  []CycleDecision  `json:"decisions,omitempty"`
+	Risk       CycleRiskVerdict `json:"risk"`
+	Execution  []CycleExecution `json:"execution,omitempty"`
+}
+
+type CyclesLatestResponse struct {
+	Cycles     []CycleBreakdown `json:"cycles"`
+	ServerTime int64            `json:"serverTime"`
+}
+
+type SkippedCycleCount struct {
+	Kind   string `json:"kind"`
+	Count  int64  `json:"count"`
+	LastAt int64  `json:"last_at"`
+}
+
+type RunnerStatus struct {
+	ModelId            string              `json:"model_id"`
+	Status             string              `json:"status"`
+	LastCycleAt        int64               `json:"last_cycle_at"`
+	LastLlmSuccessAt   int64               `json:"last_llm_success_at"`
+	LastOrderAt        int64               `json:"last_order_at"`
+	DecisionIntervalMs int64               `json:"decision_interval_ms"`
+	StallAfterMs       int64               `json:"stall_after_ms"`
+	ReportedAt         int64               `json:"reported_at"`
+	SkippedCycles      []SkippedCycleCount `json:"skipped_cycles"`
+}
+
+type StatusResponse struct {
+	Runners    []RunnerStatus `json:"runners"`
+	ServerTime int64          `json:"serverTime"`
+}
+
+type LogStreamRequest struct {
+	ModelId     string `form:"modelId,optional"`
+	TraceId     string `form:"traceId,optional"`
+	Backfill    int    `form:"backfill,optional"`
+	LastEventId string `header:"Last-Event-ID,optional"`
+}
+
+type PipelineEvent struct {
+	Id       string                 `json:"id"`
+	TraderId string                 `json:"trader_id"`
+	TraceId  string                 `json:"trace_id"`
+	Stage    string                 `json:"stage"`
+	Level    string                 `json:"level"`
+	Message  string                 `json:"message"`
+	Fields   map[string]interface{} `json:"fields,omitempty"`
+	At       string                 `json:"at"`
+}
+
+type LiveCommand struct {
+	Op     string   `json:"op"`
+	Topics []string `json:"topics,optional"`
+}
+
+type LiveMessage struct {
+	Type    string      `json:"type"`
+	Id      string      `json:"id,omitempty"`
+	Topic   string      `json:"topic,omitempty"`
+	ModelId string      `json:"model_id,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+	Topics  []string    `json:"topics,omitempty"`
+	Message string      `json:"message,omitempty"`
+	At      int64       `json:"at"`
+}
+
+type LiveRequest struct {
+	Topics  string `form:"topics,optional"`
+	ModelId string `form:"modelId,optional"`
+}
+
+type TimelineRequest struct {
+	ModelId string `path:"modelId"`
+	Cursor  string `form:"cursor,optional"`
+	Limit   int    `form:"limit,default=50"`
+	Symbol  string `form:"symbol,optional"`
+	TraceId string `form:"traceId,optional"`
+}
+
+type TimelineEntry struct {
+	Id         int64                  `json:"id"`
+	TraceId    string                 `json:"trace_id"`
+	Kind       string                 `json:"kind"`
+	Symbol     string                 `json:"symbol"`
+	Summary    string                 `json:"summary"`
+	Detail     map[string]interface{} `json:"detail"`
+	OccurredAt int64                  `json:"occurred_at"`
+}
+
+type TimelineResponse struct {
+	ModelId    string          `json:"model_id"`
+	Entries    []TimelineEntry `json:"entries"`
+	NextCursor string          `json:"next_cursor"`
+	ServerTime int64           `json:"serverTime"`
+}
+
+type AccountSnapshotRequest struct {
+	ModelId string `form:"modelId,optional"`
+	From    int64  `form:"from,optional"`
+	To      int64  `form:"to,optional"`
+	Limit   int    `form:"limit,optional"`
+	Cursor  string `form:"cursor,optional"`
+	Latest  bool   `form:"latest,optional"`
+}
+
+type AccountSnapshot struct {
+	ModelId            string  `json:"model_id"`
+	Timestamp          int64   `json:"timestamp"`
+	DollarEquity       float64 `json:"dollar_equity"`
+	RealizedPnl        float64 `json:"realized_pnl"`
+	TotalUnrealizedPnl float64 `json:"total_unrealized_pnl"`
+	CumPnlPct          float64 `json:"cum_pnl_pct"`
+	SharpeRatio        float64 `json:"sharpe_ratio"`
+}
+
+type AccountSnapshotResponse struct {
+	Snapshots  []AccountSnapshot `json:"snapshots"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	ServerTime int64             `json:"serverTime"`
+}
+
+type StateAsOfRequest struct {
+	ModelId string `path:"modelId"`
+	At      int64  `form:"at"`
+}
+
+type StateAsOfResponse struct {
+	ModelId      string              `json:"model_id"`
+	At           int64               `json:"at"`
+	Snapshot     *AccountSnapshot    `json:"snapshot,omitempty"`
+	RealizedPnl  float64             `json:"realized_pnl"`
+	Positions    map[string]Position `json:"positions"`
+	ClosedTrades []Trade             `json:"closed_trades"`
+	ServerTime   int64               `json:"serverTime"`
+}
+
+type DecisionsRequest struct {
+	ModelId string `form:"modelId,optional"`
+	From    int64  `form:"from,optional"`
+	To      int64  `form:"to,optional"`
+	Limit   int    `form:"limit,optional"`
+	Cursor  string `form:"cursor,optional"`
+}
+
+type DecisionCycle struct {
+	Id           int64       `json:"id"`
+	ModelId      string      `json:"model_id"`
+	CycleNumber  int64       `json:"cycle_number"`
+	Success      bool        `json:"success"`
+	PromptDigest string      `json:"prompt_digest"`
+	CotTrace     string      `json:"cot_trace"`
+	Decisions    interface{} `json:"decisions"`
+	ErrorMessage string      `json:"error_message,omitempty"`
+	ExecutedAt   int64       `json:"executed_at"`
+}
+
+type DecisionsResponse struct {
+	Decisions  []DecisionCycle `json:"decisions"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	ServerTime int64           `json:"serverTime"`
+}
+
+type TradeJournalEntry struct {
+	Id               int64                  `json:"id"`
+	ModelId          string                 `json:"model_id"`
+	TraceId          string                 `json:"trace_id"`
+	Symbol           string                 `json:"symbol"`
+	Side             string                 `json:"side"`
+	Status           string                 `json:"status"`
+	PromptDigest     string                 `json:"prompt_digest"`
+	PromptVersion    string                 `json:"prompt_version,omitempty"`
+	RawResponse      string                 `json:"raw_response,omitempty"`
+	Decision         map[string]interface{} `json:"decision"`
+	OpenOrderIds     []string               `json:"open_order_ids"`
+	EntryPrice       float64                `json:"entry_price"`
+	EntrySize        float64                `json:"entry_size"`
+	EntrySlippageBps float64                `json:"entry_slippage_bps"`
+	OpenedAt         int64                  `json:"opened_at"`
+	CloseOrderIds    []string               `json:"close_order_ids"`
+	CloseReason      string                 `json:"close_reason"`
+	ExitPrice        float64                `json:"exit_price"`
+	ExitSlippageBps  float64                `json:"exit_slippage_bps"`
+	RealizedPnl      float64                `json:"realized_pnl"`
+	ClosedAt         int64                  `json:"closed_at"`
+}
+
+type TradeJournalEntryRequest struct {
+	ModelId string `path:"modelId"`
+	Id      int64  `path:"id"`
+}
+
+type TradeJournalEntryResponse struct {
+	Entry      TradeJournalEntry `json:"entry"`
+	ServerTime int64             `json:"serverTime"`
+}
+
+type TradeJournalRequest struct {
+	ModelId  string `path:"modelId"`
+	BeforeId int64  `form:"beforeId,optional"`
+	Limit    int    `form:"limit,default=50"`
+	Symbol   string `form:"symbol,optional"`
+}
+
+type TradeJournalResponse struct {
+	ModelId      string              `json:"model_id"`
+	Entries      []TradeJournalEntry `json:"entries"`
+	NextBeforeId int64               `json:"next_before_id"`
+	ServerTime   int64               `json:"serverTime"`
+}
+
+type ShareLinkRequest struct {
+	ModelId    string `json:"model_id"`
+	TtlSeconds int64  `json:"ttl_seconds,optional"`
+}
+
+type ShareLinkResponse struct {
+	Token     string `json:"token"`
+	Url       string `json:"url"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+type SharedPerformanceRequest struct {
+	Token string `path:"token"`
+}
+
+type SharedEquityPoint struct {
+	Timestamp    float64 `json:"timestamp"`
+	DollarEquity float64 `json:"dollar_equity"`
+	CumPnlPct    float64 `json:"cum_pnl_pct"`
+}
+
+type SharedTrade struct {
+	Symbol         string  `json:"symbol"`
+	Side           string  `json:"side"`
+	Quantity       float64 `json:"quantity"`
+	Leverage       float64 `json:"leverage"`
+	EntryPrice     float64 `json:"entry_price"`
+	EntryTime      float64 `json:"entry_time"`
+	ExitPrice      float64 `json:"exit_price"`
+	ExitTime       float64 `json:"exit_time"`
+	RealizedNetPnl float64 `json:"realized_net_pnl"`
+}
+
+type SharedPerformanceResponse struct {
+	ModelId     string              `json:"model_id"`
+	ExpiresAt   int64               `json:"expires_at"`
+	EquityCurve []SharedEquityPoint `json:"equity_curve"`
+	Trades      []SharedTrade       `json:"trades"`
+	ServerTime  int64               `json:"serverTime"`
+}
+
+type TemplateRenderRequest struct {
+	Name    string                 `json:"name"`
+	Version string                 `json:"version,optional"`
+	Data    map[string]interface{} `json:"data,optional"`
+	Spans   bool                   `json:"spans,optional"`
+}
+
+type TemplateRenderResponse struct {
+	Name    string         `json:"name"`
+	Version string         `json:"version"`
+	Digest  string         `json:"digest"`
+	Prompt  string         `json:"prompt"`
+	Tokens  int            `json:"tokens"`
+	Spans   []TemplateSpan `json:"spans,omitempty"`
+}
+
+type TemplateSpan struct {
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+	Field  string `json:"field"`
+	Source string `json:"source"`
+}
+
+type RiskExplainRequest struct {
+	TraderId  string         `json:"trader_id,optional"`
+	Decision  RiskDecision   `json:"decision"`
+	Portfolio *RiskPortfolio `json:"portfolio,optional"`
+}
+
+type RiskDecision struct {
+	Symbol          string  `json:"symbol"`
+	Action          string  `json:"action"`
+	Leverage        int     `json:"leverage,optional"`
+	PositionSizeUsd float64 `json:"position_size_usd,optional"`
+	EntryPrice      float64 `json:"entry_price,optional"`
+	StopLoss        float64 `json:"stop_loss,optional"`
+	TakeProfit      float64 `json:"take_profit,optional"`
+	Confidence      int     `json:"confidence,optional"`
+	RiskUsd         float64 `json:"risk_usd,optional"`
+}
+
+type RiskPortfolio struct {
+	TotalEquity      float64        `json:"total_equity"`
+	AvailableBalance float64        `json:"available_balance,optional"`
+	MarginUsed       float64        `json:"margin_used,optional"`
+	Positions        []RiskPosition `json:"positions,optional"`
+}
+
+type RiskPosition struct {
+	Symbol   string  `json:"symbol"`
+	Side     string  `json:"side"`
+	Quantity float64 `json:"quantity,optional"`
+}
+
+type RiskExplainResponse struct {
+	TraderId string     `json:"trader_id,omitempty"`
+	Symbol   string     `json:"symbol"`
+	Action   string     `json:"action"`
+	Allowed  bool       `json:"allowed"`
+	Rules    []RiskRule `json:"rules"`
+}
+
+type RiskRule struct {
+	Rule      string                 `json:"rule"`
+	Status    string                 `json:"status"`
+	Inputs    map[string]interface{} `json:"inputs,omitempty"`
+	Threshold interface{}            `json:"threshold,omitempty"`
+	Detail    string                 `json:"detail,omitempty"`
+}
+
+type KlinesRequest struct {
+	Symbol   string `form:"symbol"`
+	Interval string `form:"interval,default=3m"`
+	From     int64  `form:"from,optional"`
+	To       int64  `form:"to,optional"`
+	Provider string `form:"provider,optional"`
+	Format   string `form:"format,default=json,options=json|binary"`
+}
+
+type Kline struct {
+	OpenTime int64   `json:"open_time"`
+	Open     float64 `json:"open"`
+	High     float64 `json:"high"`
+	Low      float64 `json:"low"`
+	Close    float64 `json:"close"`
+	Volume   float64 `json:"volume"`
+}
+
+type KlinesResponse struct {
+	Symbol   string  `json:"symbol"`
+	Interval string  `json:"interval"`
+	Provider string  `json:"provider"`
+	Klines   []Kline `json:"klines"`
+}
+
+type KlineMarkersRequest struct {
+	ModelId  string `form:"modelId"`
+	Symbol   string `form:"symbol"`
+	Interval string `form:"interval,default=3m"`
+	From     int64  `form:"from,optional"`
+	To       int64  `form:"to,optional"`
+}
+
+type KlineMarker struct {
+	Time    int64   `json:"time"`
+	At      int64   `json:"at"`
+	Kind    string  `json:"kind"`
+	Side    string  `json:"side,omitempty"`
+	Action  string  `json:"action,omitempty"`
+	Price   float64 `json:"price,omitempty"`
+	Size    float64 `json:"size,omitempty"`
+	Text    string  `json:"text"`
+	TraceId string  `json:"trace_id,omitempty"`
+}
+
+type KlineMarkersResponse struct {
+	ModelId    string        `json:"model_id"`
+	Symbol     string        `json:"symbol"`
+	Interval   string        `json:"interval"`
+	Markers    []KlineMarker `json:"markers"`
+	ServerTime int64         `json:"serverTime"`
+}
+
+type WidgetEquityRequest struct {
+	ModelId string `form:"modelId"`
+	Width   int    `form:"width,default=320"`
+	Height  int    `form:"height,default=120"`
+	Theme   string `form:"theme,default=light,options=light|dark"`
+}
+
+type WidgetLeaderboardRequest struct {
+	Limit int `form:"limit,default=10"`
+}
+
+type WidgetLeaderboardEntry struct {
+	Rank      int     `json:"rank"`
+	ModelId   string  `json:"model_id"`
+	ReturnPct float64 `json:"return_pct"`
+	Equity    float64 `json:"equity"`
+	Sharpe    float64 `json:"sharpe"`
+	NumTrades int     `json:"num_trades"`
+}
+
+type WidgetLeaderboardResponse struct {
+	Entries []WidgetLeaderboardEntry `json:"entries"`
+}
+
+type ModelRating struct {
+	TraderId string  `json:"trader_id"`
+	Rating   float64 `json:"rating"`
+	Matches  int     `json:"matches"`
+	Wins     int     `json:"wins"`
+	Losses   int     `json:"losses"`
+	Draws    int     `json:"draws"`
+}
+
+type RatingsResponse struct {
+	Ratings     []ModelRating `json:"ratings"`
+	Rounds      int           `json:"rounds"`
+	Matches     int           `json:"matches"`
+	Unresolved  int           `json:"unresolved"`
+	Bucket      string        `json:"bucket"`
+	Horizon     string        `json:"horizon"`
+	GeneratedAt int64         `json:"generated_at"`
+	ServerTime  int64         `json:"serverTime"`
+}
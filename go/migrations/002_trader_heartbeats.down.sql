@@ -0,0 +1,3 @@
+-- Rollback trader runner heartbeats
+
+DROP TABLE IF EXISTS trader_heartbeats CASCADE;
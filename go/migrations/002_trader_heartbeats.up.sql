@@ -0,0 +1,17 @@
+-- Trader runner heartbeats
+-- One row per trader, upserted every cycle and monitoring tick.
+
+-- ============================================================================
+-- MODULE: manager
+-- ============================================================================
+
+CREATE TABLE trader_heartbeats (
+    trader_id            TEXT PRIMARY KEY,
+    last_cycle_at        TIMESTAMPTZ,
+    last_llm_success_at  TIMESTAMPTZ,
+    last_order_at        TIMESTAMPTZ,
+    decision_interval_ms BIGINT      NOT NULL DEFAULT 0,
+    stall_after_ms       BIGINT      NOT NULL DEFAULT 0,
+    stalled              BOOLEAN     NOT NULL DEFAULT FALSE,
+    reported_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+-- Rollback decision timeline
+
+DROP TABLE IF EXISTS decision_timeline CASCADE;
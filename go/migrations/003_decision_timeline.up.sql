@@ -0,0 +1,23 @@
+-- Decision timeline
+-- Append-only log of each trader's prompt, decision, risk verdict, order,
+-- fill and exit entries for the trade drill-down view.
+
+-- ============================================================================
+-- MODULE: manager
+-- ============================================================================
+
+CREATE TABLE decision_timeline (
+    id          BIGSERIAL PRIMARY KEY,
+    trader_id   TEXT        NOT NULL,
+    trace_id    TEXT        NOT NULL DEFAULT '',
+    kind        TEXT        NOT NULL CHECK (kind IN ('prompt', 'decision', 'risk', 'order', 'fill', 'exit')),
+    symbol      TEXT        NOT NULL DEFAULT '',
+    summary     TEXT        NOT NULL,
+    detail      JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Keyset pagination walks (occurred_at, id) newest first.
+CREATE INDEX idx_decision_timeline_trader_occurred_desc
+    ON decision_timeline (trader_id, occurred_at DESC, id DESC);
@@ -0,0 +1,3 @@
+-- Rollback cycle events
+
+DROP TABLE IF EXISTS cycle_events CASCADE;
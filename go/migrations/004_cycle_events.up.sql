@@ -0,0 +1,22 @@
+-- Cycle events
+-- Append-only ledger of decision cycles that were skipped or aborted before
+-- trading (stale data, rate limits, budget cap, breakers), so quiet periods
+-- can be explained from /api/status.
+
+-- ============================================================================
+-- MODULE: manager
+-- ============================================================================
+
+CREATE TABLE cycle_events (
+    id          BIGSERIAL PRIMARY KEY,
+    trader_id   TEXT        NOT NULL,
+    trace_id    TEXT        NOT NULL DEFAULT '',
+    kind        TEXT        NOT NULL CHECK (kind IN ('stale_data', 'rate_limited', 'budget_cap', 'breaker', 'llm_error')),
+    reason      TEXT        NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Status counts scan a recent window per trader and kind.
+CREATE INDEX idx_cycle_events_occurred_trader
+    ON cycle_events (occurred_at DESC, trader_id, kind);
@@ -0,0 +1,3 @@
+-- Rollback data quality
+
+DROP TABLE IF EXISTS data_quality CASCADE;
@@ -0,0 +1,25 @@
+-- Data quality
+-- Per-cycle, per-symbol scores for the market data a decision was based on
+-- (candle gaps, staleness, outliers), so unreliable inputs can be traced
+-- back from a bad trade.
+
+-- ============================================================================
+-- MODULE: manager
+-- ============================================================================
+
+CREATE TABLE data_quality (
+    id          BIGSERIAL PRIMARY KEY,
+    trader_id   TEXT             NOT NULL,
+    trace_id    TEXT             NOT NULL DEFAULT '',
+    symbol      TEXT             NOT NULL,
+    score       DOUBLE PRECISION NOT NULL CHECK (score >= 0 AND score <= 1),
+    gaps        INTEGER          NOT NULL DEFAULT 0,
+    stale_ms    BIGINT           NOT NULL DEFAULT 0,
+    outliers    INTEGER          NOT NULL DEFAULT 0,
+    occurred_at TIMESTAMPTZ      NOT NULL,
+    created_at  TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+-- Drill-down reads one symbol's recent history.
+CREATE INDEX idx_data_quality_symbol_occurred
+    ON data_quality (symbol, occurred_at DESC);
@@ -0,0 +1,3 @@
+-- Rollback trade journal
+
+DROP TABLE IF EXISTS trade_journal CASCADE;
@@ -0,0 +1,43 @@
+-- Trade journal
+-- One row per executed open decision with the prompt digest, raw model
+-- answer and parsed decision that caused it, the exchange order ids and
+-- slippage of the entry, and the exit, slippage and realized PnL once the
+-- position closes, so operators can audit why the model traded.
+
+-- ============================================================================
+-- MODULE: manager
+-- ============================================================================
+
+CREATE TABLE trade_journal (
+    id                 BIGSERIAL PRIMARY KEY,
+    trader_id          TEXT             NOT NULL,
+    trace_id           TEXT             NOT NULL DEFAULT '',
+    symbol             TEXT             NOT NULL,
+    side               TEXT             NOT NULL CHECK (side IN ('long', 'short')),
+    status             TEXT             NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    prompt_digest      TEXT             NOT NULL DEFAULT '',
+    raw_response       TEXT             NOT NULL DEFAULT '',
+    decision           JSONB            NOT NULL DEFAULT '{}'::jsonb,
+    open_order_ids     JSONB            NOT NULL DEFAULT '[]'::jsonb,
+    entry_price        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    entry_size         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    entry_slippage_bps DOUBLE PRECISION NOT NULL DEFAULT 0,
+    opened_at          TIMESTAMPTZ      NOT NULL,
+    close_order_ids    JSONB            NOT NULL DEFAULT '[]'::jsonb,
+    close_reason       TEXT             NOT NULL DEFAULT '',
+    exit_price         DOUBLE PRECISION,
+    exit_slippage_bps  DOUBLE PRECISION,
+    realized_pnl       DOUBLE PRECISION,
+    closed_at          TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+-- The journal API pages a trader's entries newest first by id.
+CREATE INDEX idx_trade_journal_trader_id_desc
+    ON trade_journal (trader_id, id DESC);
+
+-- Closes settle the trader's open entries for one symbol and side.
+CREATE INDEX idx_trade_journal_open
+    ON trade_journal (trader_id, symbol, side)
+    WHERE status = 'open';
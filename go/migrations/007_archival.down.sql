@@ -0,0 +1,7 @@
+-- Rollback archival
+
+DROP TABLE IF EXISTS archive_batches CASCADE;
+DROP INDEX IF EXISTS idx_conversation_messages_deleted_at;
+DROP INDEX IF EXISTS idx_decision_cycles_deleted_at;
+ALTER TABLE conversation_messages DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE decision_cycles DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,36 @@
+-- Archival
+-- Decision cycles and LLM conversation messages older than the hot window are
+-- exported to object storage as gzip JSONL (cmd/archive), then soft-deleted
+-- and later purged. archive_batches records every exported object so a
+-- range can be restored.
+
+-- ============================================================================
+-- MODULE: executor/llm
+-- ============================================================================
+
+ALTER TABLE decision_cycles ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE conversation_messages ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Purge scans the soft-deleted rows only.
+CREATE INDEX IF NOT EXISTS idx_decision_cycles_deleted_at
+    ON decision_cycles (deleted_at)
+    WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_conversation_messages_deleted_at
+    ON conversation_messages (deleted_at)
+    WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE archive_batches (
+    id           BIGSERIAL PRIMARY KEY,
+    table_name   TEXT        NOT NULL,
+    object_key   TEXT        NOT NULL UNIQUE,
+    first_id     BIGINT      NOT NULL,
+    last_id      BIGINT      NOT NULL,
+    row_count    INTEGER     NOT NULL,
+    from_at      TIMESTAMPTZ NOT NULL,
+    to_at        TIMESTAMPTZ NOT NULL,
+    archived_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    restored_at  TIMESTAMPTZ
+);
+
+CREATE INDEX idx_archive_batches_table_from
+    ON archive_batches (table_name, from_at);
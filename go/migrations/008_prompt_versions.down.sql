@@ -0,0 +1,4 @@
+-- Rollback prompt versions
+
+DROP INDEX IF EXISTS idx_trade_journal_prompt_version;
+ALTER TABLE trade_journal DROP COLUMN IF EXISTS prompt_version;
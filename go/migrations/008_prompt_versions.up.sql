@@ -0,0 +1,15 @@
+-- Prompt versions
+-- Records which registered prompt version (pkg/prompt) rendered the decision
+-- behind each journaled trade so A/B experiments can be compared on
+-- realized outcomes. Empty when no version registry is configured.
+
+-- ============================================================================
+-- MODULE: manager
+-- ============================================================================
+
+ALTER TABLE trade_journal ADD COLUMN IF NOT EXISTS prompt_version TEXT NOT NULL DEFAULT '';
+
+-- Experiment reports group a trader's entries by version.
+CREATE INDEX IF NOT EXISTS idx_trade_journal_prompt_version
+    ON trade_journal (prompt_version, trader_id)
+    WHERE prompt_version <> '';
@@ -0,0 +1,3 @@
+-- Rollback feature flags
+
+DROP TABLE IF EXISTS feature_flags;
@@ -0,0 +1,17 @@
+-- Feature flags
+-- Backs the postgres source of pkg/flags. A NULL enabled keeps the default
+-- declared in code; rules is a JSON list of
+-- {"envs": [], "models": [], "traders": [], "enabled": bool} evaluated in
+-- order. Running services pick up edits on their next refresh.
+
+-- ============================================================================
+-- MODULE: manager
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS feature_flags (
+    name        TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    enabled     BOOLEAN,
+    rules       JSONB NOT NULL DEFAULT '[]'::jsonb,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -3,11 +3,15 @@ package backtest
 import (
 	"context"
 	"math"
+	"strconv"
 	"testing"
 
+	"nof0-api/pkg/exchange"
 	simex "nof0-api/pkg/exchange/sim"
+	"nof0-api/pkg/market"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBacktest_ThresholdWithSim(t *testing.T) {
@@ -32,3 +36,42 @@ func TestBacktest_ThresholdWithSim(t *testing.T) {
 	assert.False(t, res.MaxDDPct < 0 || math.IsNaN(res.MaxDDPct), "max drawdown should be non-negative and not NaN")
 	assert.False(t, math.IsNaN(res.Sharpe), "sharpe ratio should not be NaN")
 }
+
+// buyStrategy adds a one-unit buy every step, so every order opens or adds.
+type buyStrategy struct {
+	assetID int
+}
+
+func (s *buyStrategy) Decide(ctx context.Context, snap *market.Snapshot) ([]exchange.Order, error) {
+	return []exchange.Order{{Asset: s.assetID, IsBuy: true, LimitPx: strconv.FormatFloat(snap.Price.Last, 'f', -1, 64), Sz: "1"}}, nil
+}
+
+func TestBacktest_VolTargetSizerShrinksInVolatileRegime(t *testing.T) {
+	ctx := context.Background()
+	exch := simex.New()
+	assetID, err := exch.GetAssetIndex(ctx, "BTC")
+	require.NoError(t, err)
+
+	calm := []float64{100, 100.2, 100, 100.2, 100, 100.2, 100, 100.2}
+	volatile := []float64{100, 105, 100, 105, 100, 105, 100, 105}
+	feeder := NewPriceFeeder("BTC", append(calm, volatile...))
+	sizer := &VolTargetSizer{TargetDailyVolPct: 1, Period: 3}
+
+	e := &Engine{Feeder: feeder, Strategy: &buyStrategy{assetID: assetID}, Exch: exch, Symbol: "BTC", Sizer: sizer, InitialEquity: 10000}
+	res, err := e.Run(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, res.Details, len(calm)+len(volatile))
+	assert.Equal(t, 1.0, res.Details[0].Qty, "strategy size is kept until ATR is available")
+
+	var calmQty, volatileQty float64
+	for _, d := range res.Details {
+		if d.Step <= len(calm) {
+			calmQty = d.Qty
+		} else {
+			volatileQty = d.Qty
+		}
+	}
+	assert.NotEqual(t, 1.0, calmQty, "opens should be resized once ATR is available")
+	assert.Greater(t, calmQty, 10*volatileQty, "vol targeting should cut size when volatility rises")
+}
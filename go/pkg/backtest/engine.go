@@ -33,6 +33,7 @@ type Engine struct {
 	InitialEquity float64 // defaults to 100000 if zero
 	FeeBps        float64 // per-trade fee in basis points (e.g., 2.0 for 0.02%)
 	SlippageBps   float64 // execution slippage in bps applied to mid/last
+	Sizer         Sizer   // optional: resizes orders that open or add to a position
 
 	// Optional: write JSON report to this path
 	OutputPath string
@@ -74,6 +75,9 @@ func (e *Engine) Run(ctx context.Context) (*Result, error) {
 			break
 		}
 		res.Steps++
+		if e.Sizer != nil {
+			e.Sizer.Observe(snap)
+		}
 		orders, err := e.Strategy.Decide(ctx, snap)
 		if err != nil {
 			return nil, err
@@ -83,6 +87,12 @@ func (e *Engine) Run(ctx context.Context) (*Result, error) {
 			// Derive numeric size & execution price
 			sz, _ := strconv.ParseFloat(ord.Sz, 64)
 			execPx := applySlippage(px, e.SlippageBps, ord.IsBuy)
+			if e.Sizer != nil && pf.opens(ord.IsBuy) {
+				if notional := e.Sizer.Notional(snap, pf.equity(px)); notional > 0 && execPx > 0 {
+					sz = notional / execPx
+					ord.Sz = strconv.FormatFloat(sz, 'f', -1, 64)
+				}
+			}
 			realized, fee, tradeCompleted := pf.apply(ord.IsBuy, execPx, sz)
 			if tradeCompleted {
 				res.Trades++
@@ -102,6 +102,12 @@ func (p *portfolio) apply(isBuy bool, execPx float64, qty float64) (realized flo
 	return realized, fee, tradeCompleted
 }
 
+// opens reports whether an order on the given side opens or adds to the
+// position rather than reducing it.
+func (p *portfolio) opens(isBuy bool) bool {
+	return p.pos == 0 || (p.pos > 0) == isBuy
+}
+
 func (p *portfolio) equity(lastPx float64) float64 {
 	p.unrealized = 0
 	if p.pos > 0 {
@@ -0,0 +1,213 @@
+package backtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"nof0-api/pkg/decision"
+	"nof0-api/pkg/journal"
+	"nof0-api/pkg/llm"
+)
+
+// ErrNoRecordedDecision is returned by ReplayLLM when no recorded cycle
+// matches a prompt and sequence fallback is off.
+var ErrNoRecordedDecision = errors.New("backtest: no recorded decision for prompt")
+
+var _ llm.LLMClient = (*ReplayLLM)(nil)
+
+// ReplayStats counts how ReplayLLM answered its calls.
+type ReplayStats struct {
+	// Exact calls matched a recorded prompt digest.
+	Exact int
+	// Fallback calls were answered with the next recorded cycle in order.
+	Fallback int
+	// Missed calls had no answer.
+	Missed int
+}
+
+// ReplayLLM is an llm.LLMClient that answers each prompt with the decision
+// recorded in a journal for the same prompt digest, so a backtest reproduces
+// a live run exactly without calling a model. Counterfactual runs that change
+// execution parameters (sizing, risk limits, order style) alter the account
+// and positions in later prompts; WithSequenceFallback answers those with the
+// recorded cycles in order instead of failing.
+//
+// Digests are of the rendered prompt, so traders using split_prompt cannot be
+// matched exactly.
+type ReplayLLM struct {
+	cfg      *llm.Config
+	fallback bool
+
+	mu       sync.Mutex
+	byDigest map[string][]int
+	records  []*journal.CycleRecord
+	used     []bool
+	next     int
+	stats    ReplayStats
+}
+
+// ReplayOption configures a ReplayLLM.
+type ReplayOption func(*ReplayLLM)
+
+// WithReplayConfig sets the config GetConfig reports, which executors read
+// for model settings such as the context window.
+func WithReplayConfig(cfg *llm.Config) ReplayOption {
+	return func(r *ReplayLLM) { r.cfg = cfg }
+}
+
+// WithSequenceFallback answers prompts without a recorded digest with the
+// earliest recorded cycle not yet replayed.
+func WithSequenceFallback() ReplayOption {
+	return func(r *ReplayLLM) { r.fallback = true }
+}
+
+// NewReplayLLM indexes records by prompt digest. Records without a digest or
+// a recorded decision are skipped; repeated digests replay in order.
+func NewReplayLLM(records []*journal.CycleRecord, opts ...ReplayOption) *ReplayLLM {
+	r := &ReplayLLM{byDigest: make(map[string][]int)}
+	for _, rec := range records {
+		if rec == nil || strings.TrimSpace(rec.PromptDigest) == "" {
+			continue
+		}
+		if strings.TrimSpace(rec.Response) == "" && strings.TrimSpace(rec.DecisionsJSON) == "" {
+			continue
+		}
+		r.byDigest[rec.PromptDigest] = append(r.byDigest[rec.PromptDigest], len(r.records))
+		r.records = append(r.records, rec)
+	}
+	r.used = make([]bool, len(r.records))
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Stats reports how calls were answered so far.
+func (r *ReplayLLM) Stats() ReplayStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+// Chat answers req with the recorded response for its prompt.
+func (r *ReplayLLM) Chat(ctx context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	if req == nil || len(req.Messages) == 0 {
+		return nil, errors.New("backtest: replay request requires a message")
+	}
+	digest := llm.DigestString(req.Messages[0].Content)
+	rec, err := r.lookup(digest, len(req.Messages) == 1)
+	if err != nil {
+		return nil, err
+	}
+	content, err := recordedResponse(rec)
+	if err != nil {
+		return nil, err
+	}
+	return &llm.ChatResponse{
+		ID:      "replay-" + digest,
+		Model:   req.Model,
+		Created: rec.Timestamp.Unix(),
+		Choices: []llm.Choice{{Message: llm.Message{Role: "assistant", Content: content}, FinishReason: "stop"}},
+	}, nil
+}
+
+// ChatStructured answers like Chat and decodes the response into target when
+// it is plain JSON; executors parse the content themselves otherwise.
+func (r *ReplayLLM) ChatStructured(ctx context.Context, req *llm.ChatRequest, target interface{}) (*llm.ChatResponse, error) {
+	resp, err := r.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if target != nil {
+		_ = json.Unmarshal([]byte(resp.Choices[0].Message.Content), target)
+	}
+	return resp, nil
+}
+
+// ChatStream is not supported by replays.
+func (r *ReplayLLM) ChatStream(ctx context.Context, req *llm.ChatRequest) (<-chan llm.StreamResponse, error) {
+	return nil, errors.New("backtest: replay does not stream")
+}
+
+// GetConfig returns the config set by WithReplayConfig, or nil.
+func (r *ReplayLLM) GetConfig() *llm.Config { return r.cfg }
+
+// Close is a no-op.
+func (r *ReplayLLM) Close() error { return nil }
+
+func (r *ReplayLLM) lookup(digest string, single bool) (*journal.CycleRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if single {
+		for _, idx := range r.byDigest[digest] {
+			if !r.used[idx] {
+				r.used[idx] = true
+				r.stats.Exact++
+				return r.records[idx], nil
+			}
+		}
+	}
+	if r.fallback {
+		for ; r.next < len(r.records); r.next++ {
+			if !r.used[r.next] {
+				r.used[r.next] = true
+				r.stats.Fallback++
+				return r.records[r.next], nil
+			}
+		}
+	}
+	r.stats.Missed++
+	return nil, fmt.Errorf("%w (digest %s)", ErrNoRecordedDecision, digest)
+}
+
+// recordedResponse returns the model output of rec verbatim, or rebuilds the
+// decision contract from its decisions for journals written before responses
+// were recorded.
+func recordedResponse(rec *journal.CycleRecord) (string, error) {
+	if strings.TrimSpace(rec.Response) != "" {
+		return rec.Response, nil
+	}
+	decisions, err := journal.ParseDecisionsJSON(rec.DecisionsJSON)
+	if err != nil {
+		return "", fmt.Errorf("backtest: replay cycle %d: %w", rec.CycleNumber, err)
+	}
+	contract := decision.Contract{Signal: string(decision.SignalHold)}
+	if len(decisions) > 0 {
+		d := decisions[0]
+		contract = decision.Contract{
+			Signal:                string(signalForAction(d.Action)),
+			Symbol:                d.Symbol,
+			Leverage:              d.Leverage,
+			PositionSizeUSD:       d.PositionSizeUSD,
+			EntryPrice:            d.EntryPrice,
+			StopLoss:              d.StopLoss,
+			TakeProfit:            d.TakeProfit,
+			RiskUSD:               d.RiskUSD,
+			Confidence:            d.Confidence,
+			InvalidationCondition: d.InvalidationCondition,
+			Reasoning:             d.Reasoning,
+		}
+	}
+	data, err := json.Marshal(contract)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// signalForAction maps an executor action back to the signal the model gave.
+func signalForAction(action string) decision.Signal {
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case "open_long":
+		return decision.SignalBuyToEnter
+	case "open_short":
+		return decision.SignalSellToEnter
+	case "close_long", "close_short":
+		return decision.SignalClose
+	}
+	return decision.SignalHold
+}
@@ -0,0 +1,63 @@
+package backtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/decision"
+	"nof0-api/pkg/journal"
+	"nof0-api/pkg/llm"
+)
+
+func replayRequest(prompt string) *llm.ChatRequest {
+	return &llm.ChatRequest{Model: "gpt-5", Messages: []llm.Message{{Role: "system", Content: prompt}}}
+}
+
+func TestReplayLLMAnswersByPromptDigest(t *testing.T) {
+	records := []*journal.CycleRecord{
+		{CycleNumber: 1, PromptDigest: llm.DigestString("prompt one"), Response: `{"signal":"hold","symbol":"","confidence":40}`},
+		{CycleNumber: 2, PromptDigest: llm.DigestString("prompt two"), DecisionsJSON: `[{"Symbol":"ETH","Action":"open_short","Leverage":3,"PositionSizeUSD":300,"EntryPrice":2000,"StopLoss":2100,"TakeProfit":1700,"Confidence":80,"Reasoning":"lower highs"}]`},
+		{CycleNumber: 3},
+	}
+	replay := NewReplayLLM(records)
+
+	var contract decision.Contract
+	resp, err := replay.ChatStructured(context.Background(), replayRequest("prompt two"), &contract)
+	require.NoError(t, err)
+	assert.Equal(t, "sell_to_enter", contract.Signal)
+	parsed, err := decision.Parse(resp.Choices[0].Message.Content)
+	require.NoError(t, err)
+	assert.Equal(t, decision.ExitPlan{StopLoss: 2100, TakeProfit: 1700}, parsed.ExitPlan)
+	assert.Equal(t, "lower highs", parsed.Justification)
+
+	resp, err = replay.Chat(context.Background(), replayRequest("prompt one"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"signal":"hold","symbol":"","confidence":40}`, resp.Choices[0].Message.Content)
+
+	_, err = replay.Chat(context.Background(), replayRequest("prompt one"))
+	assert.ErrorIs(t, err, ErrNoRecordedDecision, "each recorded cycle replays once")
+	assert.Equal(t, ReplayStats{Exact: 2, Missed: 1}, replay.Stats())
+}
+
+func TestReplayLLMSequenceFallback(t *testing.T) {
+	records := []*journal.CycleRecord{
+		{PromptDigest: llm.DigestString("a"), Response: "first"},
+		{PromptDigest: llm.DigestString("b"), Response: "second"},
+		{PromptDigest: llm.DigestString("c"), Response: "third"},
+	}
+	replay := NewReplayLLM(records, WithSequenceFallback())
+	answers := make([]string, 0, 3)
+	for _, prompt := range []string{"b", "changed a", "changed c"} {
+		resp, err := replay.Chat(context.Background(), replayRequest(prompt))
+		require.NoError(t, err)
+		answers = append(answers, resp.Choices[0].Message.Content)
+	}
+	assert.Equal(t, []string{"second", "first", "third"}, answers)
+	assert.Equal(t, ReplayStats{Exact: 1, Fallback: 2}, replay.Stats())
+
+	_, err := replay.Chat(context.Background(), replayRequest("d"))
+	assert.ErrorIs(t, err, ErrNoRecordedDecision)
+}
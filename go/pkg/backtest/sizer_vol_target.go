@@ -0,0 +1,78 @@
+package backtest
+
+import (
+	"math"
+
+	"nof0-api/pkg/market"
+	"nof0-api/pkg/market/indicators"
+)
+
+// Sizer resizes orders that open or add to a position. Observe sees every
+// snapshot so the sizer can keep its own history.
+type Sizer interface {
+	Observe(snap *market.Snapshot)
+	// Notional returns the USD size for a new order, or 0 to keep the
+	// strategy's size.
+	Notional(snap *market.Snapshot, equity float64) float64
+}
+
+// VolTargetSizer sizes opens so each position's expected daily move is
+// TargetDailyVolPct of equity. It uses the snapshot's ATR series when present
+// and otherwise a close-to-close ATR over the observed prices.
+type VolTargetSizer struct {
+	TargetDailyVolPct float64
+	ATRWindow         string  // snapshot ATR series; defaults to ATR14
+	Period            int     // fallback ATR period; defaults to 14
+	BarsPerDay        float64 // bars per day of the fed series; defaults to 6 (4h)
+
+	closes []float64
+}
+
+func (s *VolTargetSizer) Observe(snap *market.Snapshot) {
+	if snap != nil && snap.Price.Last > 0 {
+		s.closes = append(s.closes, snap.Price.Last)
+	}
+}
+
+func (s *VolTargetSizer) Notional(snap *market.Snapshot, equity float64) float64 {
+	if snap == nil {
+		return 0
+	}
+	window := s.ATRWindow
+	if window == "" {
+		window = "ATR14"
+	}
+	bars := s.BarsPerDay
+	if bars <= 0 {
+		bars = 6
+	}
+	atr := snap.LatestATR(window)
+	if atr <= 0 {
+		atr = s.fallbackATR()
+	}
+	return market.VolTargetNotional(equity, s.TargetDailyVolPct, market.ATRDailyVolatility(atr, snap.Price.Last, bars))
+}
+
+// fallbackATR treats each observed close as a zero-range bar, so the true
+// range reduces to the absolute close-to-close change.
+func (s *VolTargetSizer) fallbackATR() float64 {
+	period := s.Period
+	if period <= 0 {
+		period = 14
+	}
+	if len(s.closes) <= period {
+		return 0
+	}
+	klines := make([]indicators.Kline, len(s.closes))
+	for i, c := range s.closes {
+		klines[i] = indicators.Kline{High: c, Low: c, Close: c}
+	}
+	atr := indicators.ATR(klines, period)
+	if len(atr) == 0 {
+		return 0
+	}
+	if v := atr[len(atr)-1]; !math.IsNaN(v) {
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,231 @@
+// Package dataset turns journaled decision cycles into (prompt, decision,
+// outcome) examples for fine-tuning and evaluation.
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"nof0-api/pkg/journal"
+	"nof0-api/pkg/rating"
+)
+
+// Outcome labels. A cycle is labelled by the mean signed return of the
+// positions it opened, measured over the horizon.
+const (
+	OutcomeWin     = "win"
+	OutcomeLoss    = "loss"
+	OutcomeFlat    = "flat"
+	OutcomePending = "pending" // no price seen after the horizon yet
+	OutcomeNone    = "none"    // the cycle opened nothing
+	OutcomeError   = "error"   // the cycle failed
+)
+
+// Outcomes lists every label in the order the CLI documents them.
+var Outcomes = []string{OutcomeWin, OutcomeLoss, OutcomeFlat, OutcomePending, OutcomeNone, OutcomeError}
+
+// Example is one exported cycle. Prompt, Response and Decision are redacted.
+type Example struct {
+	Model     string          `json:"model"`
+	Timestamp time.Time       `json:"timestamp"`
+	Cycle     int             `json:"cycle"`
+	Prompt    string          `json:"prompt"`
+	Response  string          `json:"response,omitempty"`
+	Decision  json.RawMessage `json:"decision"`
+	Outcome   Outcome         `json:"outcome"`
+}
+
+// Outcome scores a cycle's opening decisions against later prices.
+type Outcome struct {
+	Label     string             `json:"label"`
+	ReturnPct float64            `json:"return_pct"`
+	Horizon   string             `json:"horizon"`
+	Symbols   map[string]float64 `json:"symbols,omitempty"`
+}
+
+// Options filters and scores the export. Zero values keep every model, time
+// and outcome and use the rating package's horizon and minimum move.
+type Options struct {
+	Models   []string
+	From     time.Time
+	To       time.Time
+	Outcomes []string
+	Horizon  time.Duration
+	MinMove  float64
+}
+
+func (o Options) withDefaults() Options {
+	if o.Horizon <= 0 {
+		o.Horizon = rating.DefaultHorizon
+	}
+	if o.MinMove <= 0 {
+		o.MinMove = rating.DefaultMinMove
+	}
+	return o
+}
+
+// RenderFunc rebuilds the prompt a cycle was decided on.
+type RenderFunc func(rec *journal.CycleRecord) (string, error)
+
+// Build exports records matching opts in chronological order. Every record,
+// filtered or not, contributes prices for scoring the others, so pass the
+// full journals of all traders rather than a pre-filtered slice.
+func Build(records []*journal.CycleRecord, render RenderFunc, opts Options) ([]Example, error) {
+	opts = opts.withDefaults()
+	prices := newPriceIndex(records)
+	models := make(map[string]bool, len(opts.Models))
+	for _, m := range opts.Models {
+		if m = strings.TrimSpace(m); m != "" {
+			models[m] = true
+		}
+	}
+	outcomes := make(map[string]bool, len(opts.Outcomes))
+	for _, o := range opts.Outcomes {
+		outcomes[strings.ToLower(strings.TrimSpace(o))] = true
+	}
+
+	sorted := make([]*journal.CycleRecord, 0, len(records))
+	for _, rec := range records {
+		if rec != nil && !rec.Timestamp.IsZero() {
+			sorted = append(sorted, rec)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	var out []Example
+	for _, rec := range sorted {
+		if len(models) > 0 && !models[rec.TraderID] {
+			continue
+		}
+		if (!opts.From.IsZero() && rec.Timestamp.Before(opts.From)) || (!opts.To.IsZero() && !rec.Timestamp.Before(opts.To)) {
+			continue
+		}
+		outcome := score(rec, prices, opts)
+		if len(outcomes) > 0 && !outcomes[outcome.Label] {
+			continue
+		}
+		prompt, err := render(rec)
+		if err != nil {
+			return nil, fmt.Errorf("dataset: render %s cycle %d: %w", rec.TraderID, rec.CycleNumber, err)
+		}
+		decision := json.RawMessage("[]")
+		if raw := strings.TrimSpace(rec.DecisionsJSON); raw != "" && json.Valid([]byte(raw)) {
+			decision = json.RawMessage(Redact(raw))
+		}
+		out = append(out, Example{
+			Model:     rec.TraderID,
+			Timestamp: rec.Timestamp.UTC(),
+			Cycle:     rec.CycleNumber,
+			Prompt:    Redact(prompt),
+			Response:  Redact(rec.Response),
+			Decision:  decision,
+			Outcome:   outcome,
+		})
+	}
+	return out, nil
+}
+
+// score labels rec by the mean signed return of the positions it opened
+// between the cycle's price and the first price at or after the horizon.
+func score(rec *journal.CycleRecord, prices priceIndex, opts Options) Outcome {
+	out := Outcome{Horizon: opts.Horizon.String()}
+	if !rec.Success {
+		out.Label = OutcomeError
+		return out
+	}
+	decisions, _ := journal.ParseDecisionsJSON(rec.DecisionsJSON)
+	var sum float64
+	opened := 0
+	for _, d := range decisions {
+		var stance float64
+		switch d.Action {
+		case "open_long":
+			stance = 1
+		case "open_short":
+			stance = -1
+		default:
+			continue
+		}
+		opened++
+		sym := strings.ToUpper(strings.TrimSpace(d.Symbol))
+		entry := digestPrice(rec.MarketDigest, sym)
+		exit, ok := prices.after(sym, rec.Timestamp.Add(opts.Horizon))
+		if entry <= 0 || !ok {
+			out.Label = OutcomePending
+			return out
+		}
+		ret := stance * (exit - entry) / entry
+		if out.Symbols == nil {
+			out.Symbols = make(map[string]float64)
+		}
+		out.Symbols[sym] = ret * 100
+		sum += ret
+	}
+	if opened == 0 {
+		out.Label = OutcomeNone
+		return out
+	}
+	mean := sum / float64(opened)
+	out.ReturnPct = mean * 100
+	switch {
+	case mean >= opts.MinMove:
+		out.Label = OutcomeWin
+	case mean <= -opts.MinMove:
+		out.Label = OutcomeLoss
+	default:
+		out.Label = OutcomeFlat
+	}
+	return out
+}
+
+type pricePoint struct {
+	at    time.Time
+	price float64
+}
+
+// priceIndex holds every symbol's journaled prices oldest first.
+type priceIndex map[string][]pricePoint
+
+func newPriceIndex(records []*journal.CycleRecord) priceIndex {
+	idx := make(priceIndex)
+	for _, rec := range records {
+		if rec == nil || rec.Timestamp.IsZero() {
+			continue
+		}
+		for sym := range rec.MarketDigest {
+			sym = strings.ToUpper(sym)
+			if price := digestPrice(rec.MarketDigest, sym); price > 0 {
+				idx[sym] = append(idx[sym], pricePoint{at: rec.Timestamp, price: price})
+			}
+		}
+	}
+	for _, points := range idx {
+		sort.Slice(points, func(i, j int) bool { return points[i].at.Before(points[j].at) })
+	}
+	return idx
+}
+
+// after returns the first price of sym at or after t.
+func (idx priceIndex) after(sym string, t time.Time) (float64, bool) {
+	points := idx[sym]
+	i := sort.Search(len(points), func(i int) bool { return !points[i].at.Before(t) })
+	if i == len(points) {
+		return 0, false
+	}
+	return points[i].price, true
+}
+
+func digestPrice(digest map[string]any, sym string) float64 {
+	for key, raw := range digest {
+		if !strings.EqualFold(key, sym) {
+			continue
+		}
+		md, _ := raw.(map[string]any)
+		price, _ := md["price"].(float64)
+		return price
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+package dataset
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/journal"
+)
+
+func cycle(trader string, at time.Time, price float64, decisions string) *journal.CycleRecord {
+	return &journal.CycleRecord{
+		Timestamp:     at,
+		TraderID:      trader,
+		Success:       true,
+		Response:      "reasoning for " + trader,
+		DecisionsJSON: decisions,
+		MarketDigest:  map[string]any{"BTC": map[string]any{"price": price}},
+	}
+}
+
+func renderStub(rec *journal.CycleRecord) (string, error) {
+	return "prompt for " + rec.TraderID + " wallet 0x1234567890abcdef1234567890abcdef12345678", nil
+}
+
+func TestBuildScoresAndFilters(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []*journal.CycleRecord{
+		cycle("a", t0, 100, `[{"symbol":"BTC","action":"open_long"}]`),
+		cycle("b", t0, 100, `[{"symbol":"BTC","action":"open_short"}]`),
+		cycle("b", t0.Add(30*time.Minute), 100.5, `[{"symbol":"BTC","action":"hold"}]`),
+		cycle("a", t0.Add(time.Hour), 102, `[{"symbol":"BTC","action":"open_long"}]`),
+	}
+	records = append(records, &journal.CycleRecord{Timestamp: t0.Add(2 * time.Hour), TraderID: "a", ErrorMessage: "timeout"})
+
+	all, err := Build(records, renderStub, Options{})
+	require.NoError(t, err)
+	require.Len(t, all, 5)
+	labels := make([]string, len(all))
+	for i, ex := range all {
+		labels[i] = ex.Outcome.Label
+	}
+	assert.Equal(t, []string{OutcomeWin, OutcomeLoss, OutcomeNone, OutcomePending, OutcomeError}, labels)
+	assert.InDelta(t, 2.0, all[0].Outcome.ReturnPct, 1e-9)
+	assert.InDelta(t, -2.0, all[1].Outcome.Symbols["BTC"], 1e-9)
+	assert.Contains(t, all[0].Prompt, "[REDACTED_ADDRESS]")
+	assert.NotContains(t, all[0].Prompt, "0x1234")
+	assert.JSONEq(t, `[{"symbol":"BTC","action":"open_long"}]`, string(all[0].Decision))
+
+	filtered, err := Build(records, renderStub, Options{
+		Models:   []string{"a"},
+		From:     t0,
+		To:       t0.Add(90 * time.Minute),
+		Outcomes: []string{"win", "pending"},
+	})
+	require.NoError(t, err)
+	require.Len(t, filtered, 2)
+	assert.Equal(t, t0, filtered[0].Timestamp)
+	assert.Equal(t, OutcomePending, filtered[1].Outcome.Label)
+
+	line, err := json.Marshal(filtered[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(line), `"outcome":{"label":"win"`)
+}
+
+func TestBuildRenderError(t *testing.T) {
+	records := []*journal.CycleRecord{cycle("a", time.Now(), 100, "")}
+	_, err := Build(records, func(*journal.CycleRecord) (string, error) { return "", errors.New("bad template") }, Options{})
+	assert.ErrorContains(t, err, "bad template")
+}
+
+func TestRedact(t *testing.T) {
+	in := "key 0x" + strings.Repeat("ab12", 16) + " " +
+		"api sk-abcdefghijklmnop1234 Authorization: Bearer abc.def.ghijkl " +
+		"dsn postgres://nof0:secret@db:5432/nof0 mail ops@example.com BTC 97000.5"
+	out := Redact(in)
+	assert.Equal(t, "key [REDACTED_KEY] api [REDACTED_KEY] Authorization: Bearer [REDACTED_KEY] "+
+		"dsn postgres://[REDACTED]@db:5432/nof0 mail [REDACTED_EMAIL] BTC 97000.5", out)
+}
@@ -0,0 +1,27 @@
+package dataset
+
+import "regexp"
+
+// redactions are applied in order, so private keys are matched before the
+// shorter addresses they would otherwise be mistaken for.
+var redactions = []struct {
+	re   *regexp.Regexp
+	with string
+}{
+	{regexp.MustCompile(`\b0x[0-9a-fA-F]{64}\b`), "[REDACTED_KEY]"},
+	{regexp.MustCompile(`\b0x[0-9a-fA-F]{40}\b`), "[REDACTED_ADDRESS]"},
+	{regexp.MustCompile(`\b(?:sk|pk|rk)-[A-Za-z0-9_-]{16,}`), "[REDACTED_KEY]"},
+	{regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]{8,}`), "Bearer [REDACTED_KEY]"},
+	{regexp.MustCompile(`://[^/\s:@]+:[^/\s@]+@`), "://[REDACTED]@"},
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), "[REDACTED_EMAIL]"},
+}
+
+// Redact masks wallet addresses, private and API keys, credentials embedded
+// in URLs and email addresses in text. Prices, sizes and symbols are left
+// intact since they are what a dataset is for.
+func Redact(text string) string {
+	for _, r := range redactions {
+		text = r.re.ReplaceAllString(text, r.with)
+	}
+	return text
+}
@@ -0,0 +1,206 @@
+// Package decision parses the model's JSON trade decision into typed structs
+// and validates it, so malformed responses fail loudly instead of degrading
+// into holds. It has no dependencies on the executor or manager and can be
+// shared by replay, backtest and evaluation tooling.
+package decision
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Signal is the model's requested action.
+type Signal string
+
+const (
+	SignalBuyToEnter  Signal = "buy_to_enter"
+	SignalSellToEnter Signal = "sell_to_enter"
+	SignalHold        Signal = "hold"
+	SignalClose       Signal = "close"
+)
+
+// ErrMalformed wraps responses that are not a decision object at all.
+var ErrMalformed = errors.New("decision: malformed response")
+
+// IsEntry reports whether the signal opens a position.
+func (s Signal) IsEntry() bool { return s == SignalBuyToEnter || s == SignalSellToEnter }
+
+// Valid reports whether s is one of the known signals.
+func (s Signal) Valid() bool {
+	switch s {
+	case SignalBuyToEnter, SignalSellToEnter, SignalHold, SignalClose:
+		return true
+	}
+	return false
+}
+
+// Contract is the flat JSON object the executor prompts ask the model for. It
+// doubles as the structured-output target, so its fields define the schema
+// sent to providers.
+type Contract struct {
+	Signal                string  `json:"signal"`
+	Symbol                string  `json:"symbol"`
+	Leverage              int     `json:"leverage"`
+	PositionSizeUSD       float64 `json:"position_size_usd"`
+	EntryPrice            float64 `json:"entry_price"`
+	StopLoss              float64 `json:"stop_loss"`
+	TakeProfit            float64 `json:"take_profit"`
+	RiskUSD               float64 `json:"risk_usd"`
+	Confidence            int     `json:"confidence"`
+	InvalidationCondition string  `json:"invalidation_condition"`
+	Reasoning             string  `json:"reasoning"`
+}
+
+// ExitPlan holds the exit levels attached to an entry.
+type ExitPlan struct {
+	StopLoss              float64
+	TakeProfit            float64
+	InvalidationCondition string
+}
+
+// Decision is a parsed, structurally valid model decision.
+type Decision struct {
+	Signal          Signal
+	Symbol          string // uppercased
+	Leverage        int
+	PositionSizeUSD float64
+	EntryPrice      float64
+	RiskUSD         float64
+	Confidence      int
+	ExitPlan        ExitPlan
+	Justification   string
+}
+
+// wire accepts the flat contract plus the nested exit_plan and justification
+// spellings some prompts use.
+type wire struct {
+	Contract
+	ExitPlan *struct {
+		StopLoss              float64 `json:"stop_loss"`
+		TakeProfit            float64 `json:"take_profit"`
+		ProfitTarget          float64 `json:"profit_target"`
+		InvalidationCondition string  `json:"invalidation_condition"`
+	} `json:"exit_plan"`
+	Justification string `json:"justification"`
+}
+
+// Parse decodes raw model output into a Decision. Markdown code fences and
+// prose around the JSON object are tolerated; anything else that is not a
+// well-formed decision is an error.
+func Parse(raw string) (Decision, error) {
+	body, err := extractObject(raw)
+	if err != nil {
+		return Decision{}, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	var w wire
+	if err := dec.Decode(&w); err != nil {
+		return Decision{}, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	d := FromContract(w.Contract)
+	if w.ExitPlan != nil {
+		if d.ExitPlan.StopLoss == 0 {
+			d.ExitPlan.StopLoss = w.ExitPlan.StopLoss
+		}
+		if d.ExitPlan.TakeProfit == 0 {
+			d.ExitPlan.TakeProfit = w.ExitPlan.TakeProfit
+		}
+		if d.ExitPlan.TakeProfit == 0 {
+			d.ExitPlan.TakeProfit = w.ExitPlan.ProfitTarget
+		}
+		if d.ExitPlan.InvalidationCondition == "" {
+			d.ExitPlan.InvalidationCondition = strings.TrimSpace(w.ExitPlan.InvalidationCondition)
+		}
+	}
+	if d.Justification == "" {
+		d.Justification = strings.TrimSpace(w.Justification)
+	}
+	if err := d.Check(); err != nil {
+		return Decision{}, err
+	}
+	return d, nil
+}
+
+// FromContract normalises a decoded contract without validating it.
+func FromContract(c Contract) Decision {
+	return Decision{
+		Signal:          Signal(strings.ToLower(strings.TrimSpace(c.Signal))),
+		Symbol:          strings.ToUpper(strings.TrimSpace(c.Symbol)),
+		Leverage:        c.Leverage,
+		PositionSizeUSD: c.PositionSizeUSD,
+		EntryPrice:      c.EntryPrice,
+		RiskUSD:         c.RiskUSD,
+		Confidence:      c.Confidence,
+		ExitPlan: ExitPlan{
+			StopLoss:              c.StopLoss,
+			TakeProfit:            c.TakeProfit,
+			InvalidationCondition: strings.TrimSpace(c.InvalidationCondition),
+		},
+		Justification: strings.TrimSpace(c.Reasoning),
+	}
+}
+
+// Check enforces the structure every decision must have regardless of risk
+// settings: a known signal, a symbol for anything but hold, sane numbers and
+// a complete exit plan for entries.
+func (d Decision) Check() error {
+	var problems []string
+	if !d.Signal.Valid() {
+		problems = append(problems, fmt.Sprintf("unknown signal %q", d.Signal))
+	}
+	if d.Signal != SignalHold && d.Symbol == "" {
+		problems = append(problems, "symbol is required")
+	}
+	if d.Confidence < 0 || d.Confidence > 100 {
+		problems = append(problems, fmt.Sprintf("confidence %d outside 0-100", d.Confidence))
+	}
+	if d.Leverage < 0 || d.PositionSizeUSD < 0 || d.RiskUSD < 0 || d.EntryPrice < 0 || d.ExitPlan.StopLoss < 0 || d.ExitPlan.TakeProfit < 0 {
+		problems = append(problems, "numeric fields cannot be negative")
+	}
+	if d.Signal.IsEntry() {
+		if d.Leverage == 0 || d.PositionSizeUSD == 0 || d.EntryPrice == 0 {
+			problems = append(problems, "entries need leverage, position_size_usd and entry_price")
+		}
+		if d.ExitPlan.StopLoss == 0 || d.ExitPlan.TakeProfit == 0 {
+			problems = append(problems, "entries need stop_loss and take_profit")
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// RiskReward returns reward over risk for an entry, or 0 when the exit plan
+// is on the wrong side of the entry.
+func (d Decision) RiskReward() float64 {
+	entry, sl, tp := d.EntryPrice, d.ExitPlan.StopLoss, d.ExitPlan.TakeProfit
+	switch d.Signal {
+	case SignalBuyToEnter:
+		if tp > entry && entry > sl {
+			return (tp - entry) / (entry - sl)
+		}
+	case SignalSellToEnter:
+		if sl > entry && entry > tp {
+			return (entry - tp) / (sl - entry)
+		}
+	}
+	return 0
+}
+
+// extractObject returns the outermost JSON object in raw.
+func extractObject(raw string) ([]byte, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty response", ErrMalformed)
+	}
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("%w: no JSON object found", ErrMalformed)
+	}
+	return []byte(s[start : end+1]), nil
+}
@@ -0,0 +1,86 @@
+package decision
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFlatContract(t *testing.T) {
+	d, err := Parse("```json\n" + `{"signal":"Buy_To_Enter","symbol":"btc","leverage":5,"position_size_usd":200,"entry_price":100,"stop_loss":95,"take_profit":115,"risk_usd":10,"confidence":90,"invalidation_condition":"below EMA20","reasoning":"uptrend"}` + "\n```")
+	require.NoError(t, err)
+	assert.Equal(t, SignalBuyToEnter, d.Signal)
+	assert.Equal(t, "BTC", d.Symbol)
+	assert.Equal(t, ExitPlan{StopLoss: 95, TakeProfit: 115, InvalidationCondition: "below EMA20"}, d.ExitPlan)
+	assert.Equal(t, "uptrend", d.Justification)
+	assert.InDelta(t, 3.0, d.RiskReward(), 1e-9)
+}
+
+func TestParseNestedExitPlan(t *testing.T) {
+	d, err := Parse(`Here is my decision: {"signal":"sell_to_enter","symbol":"ETH","leverage":3,"position_size_usd":300,"entry_price":2000,"confidence":70,
+		"exit_plan":{"stop_loss":2100,"profit_target":1700,"invalidation_condition":"reclaims 2100"},"justification":"lower highs"}`)
+	require.NoError(t, err)
+	assert.Equal(t, 2100.0, d.ExitPlan.StopLoss)
+	assert.Equal(t, 1700.0, d.ExitPlan.TakeProfit)
+	assert.Equal(t, "lower highs", d.Justification)
+	assert.InDelta(t, 3.0, d.RiskReward(), 1e-9)
+}
+
+func TestParseRejectsMalformed(t *testing.T) {
+	_, err := Parse("I would hold for now.")
+	assert.True(t, errors.Is(err, ErrMalformed))
+
+	_, err = Parse(`{"signal":"buy_to_enter","symbol":"BTC","leverage":"five"}`)
+	assert.True(t, errors.Is(err, ErrMalformed))
+
+	_, err = Parse(`{"signal":"yolo","symbol":"BTC"}`)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Contains(t, verr.Problems, `unknown signal "yolo"`)
+
+	_, err = Parse(`{"signal":"buy_to_enter","symbol":"BTC","leverage":5,"position_size_usd":100,"entry_price":100,"confidence":120}`)
+	require.ErrorAs(t, err, &verr)
+	assert.Contains(t, verr.Problems, "confidence 120 outside 0-100")
+	assert.Contains(t, verr.Problems, "entries need stop_loss and take_profit")
+
+	d, err := Parse(`{"signal":"hold","symbol":"","confidence":50}`)
+	require.NoError(t, err)
+	assert.Equal(t, SignalHold, d.Signal)
+}
+
+func TestConstraintsValidate(t *testing.T) {
+	c := Constraints{
+		MajorCoinLeverage:  20,
+		AltcoinLeverage:    10,
+		AssetMaxLeverage:   map[string]int{"SOL": 5},
+		MaxPositionSizeUSD: 500,
+		MinConfidence:      75,
+		MinRiskReward:      2,
+		Symbols:            []string{"BTC", "SOL"},
+	}
+	entry := Decision{Signal: SignalBuyToEnter, Symbol: "BTC", Leverage: 10, PositionSizeUSD: 200, EntryPrice: 100, Confidence: 80,
+		ExitPlan: ExitPlan{StopLoss: 95, TakeProfit: 115}}
+	require.NoError(t, c.Validate(entry))
+
+	sol := entry
+	sol.Symbol, sol.Leverage, sol.PositionSizeUSD, sol.Confidence = "SOL", 8, 900, 60
+	sol.ExitPlan.TakeProfit = 105
+	var verr *ValidationError
+	require.ErrorAs(t, c.Validate(sol), &verr)
+	assert.Equal(t, []string{
+		"leverage 8 above cap 5",
+		"position_size_usd 900.00 above cap 500.00",
+		"confidence 60 below 75",
+		"reward/risk 1.00 below 2.00",
+	}, verr.Problems)
+
+	wrongSide := entry
+	wrongSide.Signal = SignalSellToEnter
+	require.ErrorAs(t, c.Validate(wrongSide), &verr)
+	assert.Equal(t, []string{"stop_loss and take_profit must sit on opposite sides of entry_price"}, verr.Problems)
+
+	assert.ErrorContains(t, c.Validate(Decision{Signal: SignalClose, Symbol: "DOGE"}), "symbol DOGE is not tradeable")
+	assert.NoError(t, c.Validate(Decision{Signal: SignalHold}))
+}
@@ -0,0 +1,83 @@
+package decision
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError lists every problem found in a decision.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "decision: invalid: " + strings.Join(e.Problems, "; ")
+}
+
+// Constraints are the risk and market limits an entry must respect. Zero
+// values disable the corresponding check.
+type Constraints struct {
+	// Leverage caps: BTC and ETH use MajorCoinLeverage, everything else
+	// AltcoinLeverage; AssetMaxLeverage (exchange limits by symbol) lowers
+	// either when tighter.
+	MajorCoinLeverage  int
+	AltcoinLeverage    int
+	AssetMaxLeverage   map[string]int
+	MaxPositionSizeUSD float64
+	MinConfidence      int
+	MinRiskReward      float64
+	// Symbols is the tradeable universe; empty allows any symbol.
+	Symbols []string
+}
+
+// Validate checks d's structure and, for entries, the limits in c.
+func (c Constraints) Validate(d Decision) error {
+	if err := d.Check(); err != nil {
+		return err
+	}
+	var problems []string
+	if d.Signal != SignalHold && len(c.Symbols) > 0 && !c.allows(d.Symbol) {
+		problems = append(problems, fmt.Sprintf("symbol %s is not tradeable", d.Symbol))
+	}
+	if d.Signal.IsEntry() {
+		if capLev := c.maxLeverage(d.Symbol); capLev > 0 && d.Leverage > capLev {
+			problems = append(problems, fmt.Sprintf("leverage %d above cap %d", d.Leverage, capLev))
+		}
+		if c.MaxPositionSizeUSD > 0 && d.PositionSizeUSD > c.MaxPositionSizeUSD {
+			problems = append(problems, fmt.Sprintf("position_size_usd %.2f above cap %.2f", d.PositionSizeUSD, c.MaxPositionSizeUSD))
+		}
+		if d.Confidence < c.MinConfidence {
+			problems = append(problems, fmt.Sprintf("confidence %d below %d", d.Confidence, c.MinConfidence))
+		}
+		rr := d.RiskReward()
+		if rr == 0 {
+			problems = append(problems, "stop_loss and take_profit must sit on opposite sides of entry_price")
+		} else if c.MinRiskReward > 0 && rr < c.MinRiskReward {
+			problems = append(problems, fmt.Sprintf("reward/risk %.2f below %.2f", rr, c.MinRiskReward))
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func (c Constraints) allows(symbol string) bool {
+	for _, s := range c.Symbols {
+		if strings.EqualFold(s, symbol) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Constraints) maxLeverage(symbol string) int {
+	capLev := c.AltcoinLeverage
+	if symbol == "BTC" || symbol == "ETH" {
+		capLev = c.MajorCoinLeverage
+	}
+	if asset, ok := c.AssetMaxLeverage[symbol]; ok && asset > 0 && (capLev == 0 || asset < capLev) {
+		capLev = asset
+	}
+	return capLev
+}
@@ -32,6 +32,28 @@ type ProviderConfig struct {
 
 	TimeoutRaw string        `yaml:"timeout"`
 	Timeout    time.Duration `yaml:"-"`
+
+	// ClockSkewThreshold is the local/exchange clock offset beyond which the
+	// provider warns. AdjustClockSkew corrects signed timestamps by the
+	// measured offset instead of only warning.
+	ClockSkewThresholdRaw string        `yaml:"clock_skew_threshold"`
+	ClockSkewThreshold    time.Duration `yaml:"-"`
+	AdjustClockSkew       bool          `yaml:"adjust_clock_skew"`
+
+	// RateLimitPerMinute caps request weight per minute; 0 uses the
+	// provider's default and a negative value disables client-side limiting.
+	RateLimitPerMinute int `yaml:"rate_limit_per_minute"`
+
+	// AllowedTransfers names the provider's native withdrawal-capable
+	// actions it may sign, e.g. "subAccountTransfer". All others are
+	// refused and alerted on; empty blocks every transfer.
+	AllowedTransfers []string `yaml:"allowed_transfers"`
+
+	// InitialEquity seeds paper (sim) accounts in USD; 0 uses the default.
+	InitialEquity float64 `yaml:"initial_equity"`
+	// MaintenanceMarginRate is the sim's maintenance margin as a fraction
+	// of notional, used for liquidation prices; 0 uses the default.
+	MaintenanceMarginRate float64 `yaml:"maintenance_margin_rate"`
 }
 
 // ProviderBuilder constructs a Provider from configuration.
@@ -144,21 +166,32 @@ func (p *ProviderConfig) expandEnv() {
 	p.VaultAddress = strings.TrimSpace(os.ExpandEnv(p.VaultAddress))
 	p.MainAddress = strings.TrimSpace(os.ExpandEnv(p.MainAddress))
 	p.TimeoutRaw = strings.TrimSpace(os.ExpandEnv(p.TimeoutRaw))
+	p.ClockSkewThresholdRaw = strings.TrimSpace(os.ExpandEnv(p.ClockSkewThresholdRaw))
 }
 
 func (p *ProviderConfig) parseDurations(name string) error {
-	if p.TimeoutRaw == "" {
-		p.Timeout = 0
-		return nil
-	}
-	d, err := time.ParseDuration(p.TimeoutRaw)
-	if err != nil {
-		return fmt.Errorf("exchange provider %s: invalid timeout %q: %w", name, p.TimeoutRaw, err)
+	p.Timeout = 0
+	if p.TimeoutRaw != "" {
+		d, err := time.ParseDuration(p.TimeoutRaw)
+		if err != nil {
+			return fmt.Errorf("exchange provider %s: invalid timeout %q: %w", name, p.TimeoutRaw, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("exchange provider %s: timeout must be positive, got %s", name, d)
+		}
+		p.Timeout = d
 	}
-	if d <= 0 {
-		return fmt.Errorf("exchange provider %s: timeout must be positive, got %s", name, d)
+	p.ClockSkewThreshold = 0
+	if p.ClockSkewThresholdRaw != "" {
+		d, err := time.ParseDuration(p.ClockSkewThresholdRaw)
+		if err != nil {
+			return fmt.Errorf("exchange provider %s: invalid clock_skew_threshold %q: %w", name, p.ClockSkewThresholdRaw, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("exchange provider %s: clock_skew_threshold must be positive, got %s", name, d)
+		}
+		p.ClockSkewThreshold = d
 	}
-	p.Timeout = d
 	return nil
 }
 
@@ -199,6 +232,9 @@ func (p *ProviderConfig) validate(name string) error {
 	if strings.ToLower(p.Type) == "hyperliquid" && p.PrivateKey == "" {
 		return fmt.Errorf("exchange config: provider %s requires private_key", name)
 	}
+	if p.MaintenanceMarginRate < 0 || p.MaintenanceMarginRate >= 1 {
+		return fmt.Errorf("exchange config: provider %s maintenance_margin_rate must be in [0, 1)", name)
+	}
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	exchange "nof0-api/pkg/exchange"
 	_ "nof0-api/pkg/exchange/hyperliquid"
@@ -27,6 +28,8 @@ providers:
     type: hyperliquid
     private_key: ${EXCHANGE_PRIVATE_KEY}
     timeout: 45s
+    clock_skew_threshold: 1500ms
+    adjust_clock_skew: true
     testnet: true
     vault_address: 0x0000000000000000000000000000000000000000
 `
@@ -38,6 +41,8 @@ providers:
 	assert.NoError(t, err, "LoadConfig should not error")
 	assert.NotNil(t, cfg, "config should not be nil")
 	assert.Equal(t, "hyperliquid_testnet", cfg.Default, "default should be hyperliquid_testnet")
+	assert.Equal(t, 1500*time.Millisecond, cfg.Providers["hyperliquid_testnet"].ClockSkewThreshold)
+	assert.True(t, cfg.Providers["hyperliquid_testnet"].AdjustClockSkew)
 
 	providers, err := cfg.BuildProviders()
 	assert.NoError(t, err, "BuildProviders should not error")
@@ -0,0 +1,35 @@
+package exchange
+
+import "context"
+
+type fenceKey struct{}
+
+type fence struct {
+	token int64
+	check func(context.Context, int64) error
+}
+
+// WithFence attaches the fencing token of the leader lease an order is
+// submitted under, and check, which confirms with the lease store that the
+// token still leads. Providers run it through CheckFence right before
+// sending an order, so an instance that stalled past its lease cannot trade
+// alongside the new leader.
+func WithFence(ctx context.Context, token int64, check func(context.Context, int64) error) context.Context {
+	return context.WithValue(ctx, fenceKey{}, fence{token: token, check: check})
+}
+
+// FencingToken returns the token attached by WithFence.
+func FencingToken(ctx context.Context) (int64, bool) {
+	f, ok := ctx.Value(fenceKey{}).(fence)
+	return f.token, ok
+}
+
+// CheckFence runs the check attached by WithFence. Contexts without a fence
+// pass.
+func CheckFence(ctx context.Context) error {
+	f, ok := ctx.Value(fenceKey{}).(fence)
+	if !ok || f.check == nil {
+		return nil
+	}
+	return f.check(ctx, f.token)
+}
@@ -0,0 +1,119 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrTransferBlocked is returned when a provider refuses to sign an action
+// that can move funds off the trading account.
+var ErrTransferBlocked = errors.New("exchange: transfer blocked")
+
+// TransferBlocked describes one action refused by a TransferGuard.
+type TransferBlocked struct {
+	Provider string
+	Action   string
+	At       time.Time
+}
+
+// TransferGuarded is implemented by providers that refuse withdrawal-capable
+// actions unless allow-listed. The callback runs on every refusal.
+type TransferGuarded interface {
+	OnTransferBlocked(fn func(TransferBlocked))
+}
+
+// PermissionChecker is implemented by providers that can report what their
+// signing key is allowed to do on the exchange.
+type PermissionChecker interface {
+	CheckPermissions(ctx context.Context) (KeyPermissions, error)
+}
+
+// KeyPermissions describes the signing key of a provider. Trading only needs
+// CanTrade; a key that can also transfer or withdraw turns a leaked secret
+// or a buggy code path into lost funds.
+type KeyPermissions struct {
+	// Role is the exchange's own name for the key, e.g. "agent".
+	Role        string
+	CanTrade    bool
+	CanTransfer bool
+}
+
+// Excessive reports whether the key can do more than trading requires.
+func (p KeyPermissions) Excessive() bool {
+	return p.CanTransfer
+}
+
+// TransferGuard blocks withdrawal-capable actions that are not explicitly
+// allowed. Providers consult it before signing; a nil guard allows everything.
+type TransferGuard struct {
+	provider string
+	capable  map[string]bool
+	allowed  map[string]bool
+	clock    func() time.Time
+
+	mu       sync.RWMutex
+	handlers []func(TransferBlocked)
+}
+
+// NewTransferGuard guards the actions in capable for provider, letting
+// through only those in allowed. Allowed names must be capable actions so a
+// typo cannot silently leave a transfer blocked or open.
+func NewTransferGuard(provider string, capable, allowed []string) (*TransferGuard, error) {
+	g := &TransferGuard{
+		provider: provider,
+		capable:  make(map[string]bool, len(capable)),
+		allowed:  make(map[string]bool, len(allowed)),
+		clock:    time.Now,
+	}
+	for _, name := range capable {
+		g.capable[name] = true
+	}
+	for _, name := range allowed {
+		name = strings.TrimSpace(name)
+		if !g.capable[name] {
+			return nil, fmt.Errorf("allowed_transfers: unknown transfer action %q (known: %s)", name, strings.Join(sortedKeys(g.capable), ", "))
+		}
+		g.allowed[name] = true
+	}
+	return g, nil
+}
+
+// OnTransferBlocked registers fn to run whenever the guard refuses an action.
+func (g *TransferGuard) OnTransferBlocked(fn func(TransferBlocked)) {
+	if g == nil || fn == nil {
+		return
+	}
+	g.mu.Lock()
+	g.handlers = append(g.handlers, fn)
+	g.mu.Unlock()
+}
+
+// Check returns an error wrapping ErrTransferBlocked when action can move
+// funds and is not allowed, after notifying the registered handlers.
+func (g *TransferGuard) Check(action string) error {
+	if g == nil || !g.capable[action] || g.allowed[action] {
+		return nil
+	}
+	event := TransferBlocked{Provider: g.provider, Action: action, At: g.clock()}
+	g.mu.RLock()
+	handlers := append([]func(TransferBlocked){}, g.handlers...)
+	g.mu.RUnlock()
+	for _, fn := range handlers {
+		fn(event)
+	}
+	return fmt.Errorf("%w: %s action %q is not in allowed_transfers", ErrTransferBlocked, g.provider, action)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,30 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferGuard(t *testing.T) {
+	g, err := NewTransferGuard("hl", []string{"withdraw", "transfer"}, []string{" transfer "})
+	require.NoError(t, err)
+	var blocked []TransferBlocked
+	g.OnTransferBlocked(func(ev TransferBlocked) { blocked = append(blocked, ev) })
+
+	assert.NoError(t, g.Check("order"))
+	assert.NoError(t, g.Check("transfer"))
+	err = g.Check("withdraw")
+	assert.ErrorIs(t, err, ErrTransferBlocked)
+	assert.EqualError(t, err, `exchange: transfer blocked: hl action "withdraw" is not in allowed_transfers`)
+	require.Len(t, blocked, 1)
+	assert.Equal(t, "hl", blocked[0].Provider)
+	assert.Equal(t, "withdraw", blocked[0].Action)
+
+	var nilGuard *TransferGuard
+	assert.NoError(t, nilGuard.Check("withdraw"))
+
+	_, err = NewTransferGuard("hl", []string{"withdraw"}, []string{"withdrawl"})
+	assert.EqualError(t, err, `allowed_transfers: unknown transfer action "withdrawl" (known: withdraw)`)
+}
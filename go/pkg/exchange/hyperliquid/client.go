@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -52,6 +53,24 @@ type Client struct {
 	// Asset directory cache
 	assetTTL     time.Duration
 	assetLastRef time.Time
+
+	// Clock skew against the exchange (see clock.go)
+	skewMu        sync.RWMutex
+	skew          exchange.ClockSkew
+	skewSamples   uint64
+	skewThreshold time.Duration
+	adjustSkew    bool
+	nonceResynced bool
+
+	// nonces is shared with sub-account clients, which sign with the same key.
+	nonces *nonceSource
+
+	// Client-side request weight limiter (see ratelimit.go); nil disables.
+	limiter *weightLimiter
+
+	// guard refuses unlisted transfer actions (see guard.go); shared with
+	// sub-account clients.
+	guard *exchange.TransferGuard
 }
 
 // ClientOption customises the Hyperliquid client.
@@ -164,14 +183,17 @@ func NewClient(privateKeyHex string, isTestnet bool, opts ...ClientOption) (*Cli
 		httpClient: &http.Client{
 			Timeout: defaultHTTPTimeout,
 		},
-		signer:       signer,
-		address:      address,
-		isTestnet:    isTestnet,
-		logger:       log.Default(),
-		clock:        time.Now,
-		assetIndex:   make(map[string]int),
-		assetInfo:    make(map[string]AssetInfo),
-		priceSigFigs: 5,
+		signer:        signer,
+		address:       address,
+		isTestnet:     isTestnet,
+		logger:        log.Default(),
+		clock:         time.Now,
+		skewThreshold: defaultClockSkewThreshold,
+		assetIndex:    make(map[string]int),
+		assetInfo:     make(map[string]AssetInfo),
+		priceSigFigs:  5,
+		limiter:       newWeightLimiter(defaultRateLimitPerMinute, time.Now),
+		nonces:        &nonceSource{},
 	}
 	if isTestnet {
 		client.infoURL = testnetInfoURL
@@ -189,6 +211,9 @@ func NewClient(privateKeyHex string, isTestnet bool, opts ...ClientOption) (*Cli
 	if client.clock == nil {
 		client.clock = time.Now
 	}
+	if client.guard == nil {
+		client.guard, _ = NewTransferGuard("hyperliquid", nil)
+	}
 	return client, nil
 }
 
@@ -322,12 +347,17 @@ func (c *Client) doInfoRequest(ctx context.Context, req InfoRequest, result inte
 	backoff := defaultRetryBackoff
 	var lastErr error
 	for attempt := 0; attempt < maxRetryAttempts; attempt++ {
+		if err := c.throttle(ctx, infoRequestWeight); err != nil {
+			return err
+		}
+		wait := backoff
 		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.infoURL, bytes.NewReader(payload))
 		if err != nil {
 			return fmt.Errorf("hyperliquid: build info request: %w", err)
 		}
 		httpReq.Header.Set("Content-Type", "application/json")
 
+		sent := c.localNow()
 		resp, err := c.httpClient.Do(httpReq)
 		if err != nil {
 			if ctx.Err() != nil {
@@ -335,10 +365,14 @@ func (c *Client) doInfoRequest(ctx context.Context, req InfoRequest, result inte
 			}
 			lastErr = err
 		} else {
+			c.observeServerTime(resp, sent, c.localNow())
 			body, readErr := io.ReadAll(resp.Body)
 			resp.Body.Close()
 			if readErr != nil {
 				lastErr = fmt.Errorf("hyperliquid: read info response: %w", readErr)
+			} else if resp.StatusCode == http.StatusTooManyRequests {
+				wait = retryAfter(resp, backoff)
+				lastErr = &RateLimitError{RetryAfter: wait, Body: string(body)}
 			} else if resp.StatusCode < http.StatusOK || resp.StatusCode >= 300 {
 				lastErr = fmt.Errorf("hyperliquid: info http status %d: %s", resp.StatusCode, string(body))
 			} else if result != nil {
@@ -351,12 +385,10 @@ func (c *Client) doInfoRequest(ctx context.Context, req InfoRequest, result inte
 			}
 		}
 
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(backoff):
-			backoff *= 2
+		if err := sleepCtx(ctx, wait); err != nil {
+			return err
 		}
+		backoff *= 2
 	}
 	if lastErr != nil {
 		return lastErr
@@ -396,60 +428,87 @@ func (c *Client) GetVaultDetails(ctx context.Context, vaultAddress string, user
 	return &out, nil
 }
 
-// doExchangeRequest signs and submits an exchange action.
+// doExchangeRequest signs and submits an exchange action. A rejected nonce
+// or a 429 never executes the action, so it is re-signed and retried once:
+// after the nonce sequence is resynced with the exchange clock, or after the
+// wait the exchange asked for.
 func (c *Client) doExchangeRequest(ctx context.Context, action interface{}, result interface{}) error {
-	exchangeReq, err := c.signAction(action)
+	body, err := c.postExchange(ctx, action)
+	var limited *RateLimitError
+	if errors.As(err, &limited) {
+		c.logf("%v; retrying once", err)
+		if err := sleepCtx(ctx, limited.RetryAfter); err != nil {
+			return err
+		}
+		body, err = c.postExchange(ctx, action)
+	} else if errors.Is(err, ErrInvalidNonce) {
+		c.logf("%v; resyncing nonces", err)
+		if syncErr := c.ResyncNonces(ctx); syncErr != nil {
+			c.logf("%v", syncErr)
+		}
+		body, err = c.postExchange(ctx, action)
+	}
 	if err != nil {
 		return err
 	}
+	if result != nil {
+		if err := json.Unmarshal(body, result); err != nil {
+			return fmt.Errorf("hyperliquid: decode exchange response: %w", err)
+		}
+	}
+	return nil
+}
+
+// postExchange signs action with a fresh nonce and returns the response body.
+// The leader fence on ctx, if any, is checked last before signing.
+func (c *Client) postExchange(ctx context.Context, action interface{}) ([]byte, error) {
+	if err := c.throttle(ctx, exchangeRequestWeight); err != nil {
+		return nil, err
+	}
+	if err := exchange.CheckFence(ctx); err != nil {
+		return nil, err
+	}
+	exchangeReq, err := c.SignAction(action)
+	if err != nil {
+		return nil, err
+	}
 	payload, err := json.Marshal(exchangeReq)
 	if err != nil {
-		return fmt.Errorf("hyperliquid: encode exchange request: %w", err)
+		return nil, fmt.Errorf("hyperliquid: encode exchange request: %w", err)
 	}
 	c.logf("hyperliquid: exchange request payload=%s", string(payload))
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.exchangeURL, bytes.NewReader(payload))
 	if err != nil {
-		return fmt.Errorf("hyperliquid: build exchange request: %w", err)
+		return nil, fmt.Errorf("hyperliquid: build exchange request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 
+	sent := c.localNow()
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		if ctx.Err() != nil {
-			return ctx.Err()
+			return nil, ctx.Err()
 		}
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
+	c.observeServerTime(resp, sent, c.localNow())
 
 	body, readErr := io.ReadAll(resp.Body)
 	if readErr != nil {
-		return fmt.Errorf("hyperliquid: read exchange response: %w", readErr)
+		return nil, fmt.Errorf("hyperliquid: read exchange response: %w", readErr)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &RateLimitError{RetryAfter: retryAfter(resp, defaultRetryBackoff), Body: string(body)}
 	}
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= 300 {
 		c.logf("hyperliquid: exchange error status=%d body=%s", resp.StatusCode, string(body))
-		return fmt.Errorf("hyperliquid: exchange http status %d: %s", resp.StatusCode, string(body))
-	}
-	if result != nil {
-		if err := json.Unmarshal(body, result); err != nil {
-			return fmt.Errorf("hyperliquid: decode exchange response: %w", err)
-		}
+		return nil, fmt.Errorf("hyperliquid: exchange http status %d: %s", resp.StatusCode, string(body))
 	}
-	return nil
-}
-
-// signAction builds the EIP-712 payload and signs it.
-func (c *Client) signAction(action interface{}) (*ExchangeRequest, error) {
-	now := c.clock
-	if now == nil {
-		now = time.Now
-	}
-	nonce := now().UnixMilli()
-	exchangeReq, err := signAction(action, c.signer, nonce, c.mainAddress, c.vault, !c.isTestnet)
-	if err != nil {
+	if err := nonceError(body); err != nil {
 		return nil, err
 	}
-	return exchangeReq, nil
+	return body, nil
 }
 
 func (c *Client) logf(format string, args ...interface{}) {
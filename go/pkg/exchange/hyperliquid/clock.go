@@ -0,0 +1,102 @@
+package hyperliquid
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"nof0-api/pkg/exchange"
+)
+
+// defaultClockSkewThreshold leaves room for the one-second resolution of the
+// Date header the offset is measured from.
+const defaultClockSkewThreshold = 2 * time.Second
+
+// WithClockSkewThreshold sets the offset beyond which the client warns.
+func WithClockSkewThreshold(d time.Duration) ClientOption {
+	return func(c *Client) {
+		if d > 0 {
+			c.skewThreshold = d
+		}
+	}
+}
+
+// WithClockSkewAdjustment corrects nonces by the measured offset once it
+// exceeds the threshold, instead of only warning.
+func WithClockSkewAdjustment(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.adjustSkew = enabled
+	}
+}
+
+// SyncClock measures the offset between the local and exchange clocks with a
+// lightweight info request. Hyperliquid exposes no time endpoint, so the
+// exchange time comes from the response Date header.
+func (c *Client) SyncClock(ctx context.Context) (exchange.ClockSkew, error) {
+	c.skewMu.RLock()
+	before := c.skewSamples
+	c.skewMu.RUnlock()
+	if err := c.doInfoRequest(ctx, InfoRequest{Type: "meta"}, nil); err != nil {
+		return exchange.ClockSkew{}, err
+	}
+	c.skewMu.RLock()
+	skew, measured := c.skew, c.skewSamples != before
+	c.skewMu.RUnlock()
+	if !measured {
+		return skew, fmt.Errorf("hyperliquid: info response carried no Date header")
+	}
+	return skew, nil
+}
+
+// ClockSkew returns the most recent measurement; every info and exchange
+// response refreshes it.
+func (c *Client) ClockSkew() exchange.ClockSkew {
+	c.skewMu.RLock()
+	defer c.skewMu.RUnlock()
+	return c.skew
+}
+
+// observeServerTime records the offset implied by resp's Date header for a
+// request sent and answered at the given local times.
+func (c *Client) observeServerTime(resp *http.Response, sent, received time.Time) {
+	server, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+	rtt := received.Sub(sent)
+	// Date is truncated to the second; its midpoint is the best estimate,
+	// compared with the local midpoint of the round trip.
+	offset := server.Add(500 * time.Millisecond).Sub(sent.Add(rtt / 2))
+	skew := exchange.ClockSkew{
+		Offset:     offset.Round(time.Millisecond),
+		RTT:        rtt,
+		Threshold:  c.skewThreshold,
+		MeasuredAt: received,
+	}
+	skew.Adjusted = c.adjustSkew && skew.Exceeded()
+
+	c.skewMu.Lock()
+	wasExceeded := c.skew.Exceeded()
+	c.skew = skew
+	c.skewSamples++
+	c.skewMu.Unlock()
+
+	switch {
+	case skew.Exceeded() && !wasExceeded:
+		action := "signed nonces are not corrected"
+		if skew.Adjusted {
+			action = "correcting signed nonces"
+		}
+		c.logf("hyperliquid: clock skew %s exceeds %s (rtt %s); %s", skew.Offset, skew.Threshold, rtt.Round(time.Millisecond), action)
+	case !skew.Exceeded() && wasExceeded:
+		c.logf("hyperliquid: clock skew back to %s", skew.Offset)
+	}
+}
+
+func (c *Client) localNow() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock()
+}
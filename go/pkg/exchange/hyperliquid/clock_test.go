@@ -0,0 +1,96 @@
+package hyperliquid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const clockTestKey = "0x59c6995e998f97a5a0044966f0945389dc9e86dae88c7a741b52d7c5d5095e2f"
+
+func newSkewServer(t *testing.T, serverTime time.Time) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", serverTime.UTC().Format(http.TimeFormat))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSyncClockMeasuresOffsetFromDateHeader(t *testing.T) {
+	local := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	server := newSkewServer(t, local.Add(5*time.Second))
+
+	client, err := NewClient(clockTestKey, false, WithClock(func() time.Time { return local }))
+	require.NoError(t, err)
+	client.infoURL = server.URL
+
+	skew, err := client.SyncClock(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5500*time.Millisecond, skew.Offset, "Date is truncated to the second, so its midpoint is used")
+	assert.Equal(t, defaultClockSkewThreshold, skew.Threshold)
+	assert.True(t, skew.Exceeded())
+	assert.False(t, skew.Adjusted, "adjustment is opt-in")
+	assert.Equal(t, local.UnixMilli(), client.nonceTime().UnixMilli(), "nonces keep the local clock without adjustment")
+}
+
+func TestClockSkewAdjustmentCorrectsNonces(t *testing.T) {
+	local := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	server := newSkewServer(t, local.Add(-10*time.Second))
+
+	client, err := NewClient(clockTestKey, false,
+		WithClock(func() time.Time { return local }),
+		WithClockSkewThreshold(time.Second),
+		WithClockSkewAdjustment(true),
+	)
+	require.NoError(t, err)
+	client.infoURL = server.URL
+
+	skew, err := client.SyncClock(context.Background())
+	require.NoError(t, err)
+	assert.True(t, skew.Adjusted)
+	assert.Equal(t, local.Add(skew.Offset).UnixMilli(), client.nonceTime().UnixMilli())
+
+	req, err := client.SignAction(map[string]string{"type": "noop"})
+	require.NoError(t, err)
+	assert.Equal(t, local.Add(-9500*time.Millisecond).UnixMilli(), req.Nonce)
+}
+
+func TestClockSkewWithinThresholdIsNotAdjusted(t *testing.T) {
+	local := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	server := newSkewServer(t, local)
+
+	client, err := NewClient(clockTestKey, false,
+		WithClock(func() time.Time { return local }),
+		WithClockSkewAdjustment(true),
+	)
+	require.NoError(t, err)
+	client.infoURL = server.URL
+
+	skew, err := client.SyncClock(context.Background())
+	require.NoError(t, err)
+	assert.False(t, skew.Exceeded())
+	assert.False(t, skew.Adjusted)
+	assert.Equal(t, local, client.nonceTime())
+}
+
+func TestSyncClockWithoutDateHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Date"] = nil
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(clockTestKey, false)
+	require.NoError(t, err)
+	client.infoURL = server.URL
+
+	_, err = client.SyncClock(context.Background())
+	assert.ErrorContains(t, err, "Date header")
+}
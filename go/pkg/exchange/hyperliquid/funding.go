@@ -0,0 +1,92 @@
+package hyperliquid
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FundingRate is one asset's hourly funding rate as a fraction (0.0001 ==
+// 0.01%/h), with the premium it was derived from.
+type FundingRate struct {
+	Coin    string
+	Rate    float64
+	Premium float64
+	Time    time.Time
+}
+
+// fundingHistoryEntry is one row of the fundingHistory info response.
+type fundingHistoryEntry struct {
+	Coin        string `json:"coin"`
+	FundingRate string `json:"fundingRate"`
+	Premium     string `json:"premium"`
+	Time        int64  `json:"time"`
+}
+
+// GetFundingRates returns the current predicted funding rate of every listed
+// perpetual, in universe order. Time is when the rates were fetched.
+func (c *Client) GetFundingRates(ctx context.Context) ([]FundingRate, error) {
+	var resp MetaAndAssetCtxsResponse
+	if err := c.doInfoRequest(ctx, InfoRequest{Type: "metaAndAssetCtxs"}, &resp); err != nil {
+		return nil, err
+	}
+	now := c.localNow()
+	out := make([]FundingRate, 0, len(resp.Universe))
+	for idx, entry := range resp.Universe {
+		if entry.IsDelisted || idx >= len(resp.AssetCtxs) {
+			continue
+		}
+		rate, err := parseOptionalFloat(resp.AssetCtxs[idx].Funding)
+		if err != nil {
+			return nil, fmt.Errorf("hyperliquid: funding for %s: %w", entry.Name, err)
+		}
+		premium, err := parseOptionalFloat(resp.AssetCtxs[idx].Premium)
+		if err != nil {
+			return nil, fmt.Errorf("hyperliquid: premium for %s: %w", entry.Name, err)
+		}
+		out = append(out, FundingRate{Coin: entry.Name, Rate: rate, Premium: premium, Time: now})
+	}
+	return out, nil
+}
+
+// GetFundingHistory returns the settled hourly funding rates of coin between
+// start and end (end zero means now), oldest first.
+func (c *Client) GetFundingHistory(ctx context.Context, coin string, start, end time.Time) ([]FundingRate, error) {
+	name := strings.TrimSpace(coin)
+	if info, ok := c.cachedAssetInfo(canonicalAssetKey(coin)); ok && info.Name != "" {
+		name = info.Name
+	}
+	if name == "" {
+		return nil, fmt.Errorf("hyperliquid: empty coin for fundingHistory")
+	}
+	req := InfoRequest{Type: "fundingHistory", Coin: name, StartTime: start.UnixMilli()}
+	if !end.IsZero() {
+		req.EndTime = end.UnixMilli()
+	}
+	var rows []fundingHistoryEntry
+	if err := c.doInfoRequest(ctx, req, &rows); err != nil {
+		return nil, err
+	}
+	out := make([]FundingRate, 0, len(rows))
+	for _, row := range rows {
+		rate, err := strconv.ParseFloat(row.FundingRate, 64)
+		if err != nil {
+			return nil, fmt.Errorf("hyperliquid: funding history for %s: %w", name, err)
+		}
+		premium, err := parseOptionalFloat(row.Premium)
+		if err != nil {
+			return nil, fmt.Errorf("hyperliquid: funding history premium for %s: %w", name, err)
+		}
+		out = append(out, FundingRate{Coin: row.Coin, Rate: rate, Premium: premium, Time: time.UnixMilli(row.Time).UTC()})
+	}
+	return out, nil
+}
+
+func parseOptionalFloat(s string) (float64, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,93 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"nof0-api/pkg/exchange"
+)
+
+// transferActions lists the exchange actions that move funds off the trading
+// account. The client refuses to sign them unless allow-listed.
+var transferActions = []string{
+	"withdraw3",
+	"usdSend",
+	"spotSend",
+	"sendAsset",
+	"usdClassTransfer",
+	"subAccountTransfer",
+	"subAccountSpotTransfer",
+	"vaultTransfer",
+	"cWithdraw",
+}
+
+// NewTransferGuard guards the Hyperliquid transfer actions for provider,
+// allowing only those named in allowed.
+func NewTransferGuard(provider string, allowed []string) (*exchange.TransferGuard, error) {
+	return exchange.NewTransferGuard(provider, transferActions, allowed)
+}
+
+// WithTransferGuard replaces the default guard, which blocks every transfer.
+func WithTransferGuard(guard *exchange.TransferGuard) ClientOption {
+	return func(c *Client) {
+		if guard != nil {
+			c.guard = guard
+		}
+	}
+}
+
+// OnTransferBlocked implements exchange.TransferGuarded.
+func (c *Client) OnTransferBlocked(fn func(exchange.TransferBlocked)) {
+	c.guard.OnTransferBlocked(fn)
+}
+
+// actionType is the wire "type" of an exchange action.
+func actionType(action interface{}) string {
+	raw, err := json.Marshal(action)
+	if err != nil {
+		return ""
+	}
+	var head struct {
+		Type string `json:"type"`
+	}
+	_ = json.Unmarshal(raw, &head)
+	return head.Type
+}
+
+// userRoleResponse is the info reply for {"type":"userRole"}.
+type userRoleResponse struct {
+	Role string `json:"role"`
+}
+
+// CheckPermissions implements exchange.PermissionChecker. Hyperliquid agent
+// (API) wallets can trade but never withdraw or transfer; any other role
+// means the client signs with an account's own key, which can do both.
+func (c *Client) CheckPermissions(ctx context.Context) (exchange.KeyPermissions, error) {
+	var resp userRoleResponse
+	if err := c.doInfoRequest(ctx, InfoRequest{Type: "userRole", User: c.address}, &resp); err != nil {
+		return exchange.KeyPermissions{}, fmt.Errorf("hyperliquid: user role: %w", err)
+	}
+	return exchange.KeyPermissions{
+		Role:        resp.Role,
+		CanTrade:    true,
+		CanTransfer: resp.Role != "agent",
+	}, nil
+}
+
+// OnTransferBlocked implements exchange.TransferGuarded when the underlying
+// client is guarded.
+func (p *Provider) OnTransferBlocked(fn func(exchange.TransferBlocked)) {
+	if guarded, ok := p.client.(exchange.TransferGuarded); ok {
+		guarded.OnTransferBlocked(fn)
+	}
+}
+
+// CheckPermissions implements exchange.PermissionChecker.
+func (p *Provider) CheckPermissions(ctx context.Context) (exchange.KeyPermissions, error) {
+	checker, ok := p.client.(exchange.PermissionChecker)
+	if !ok {
+		return exchange.KeyPermissions{}, fmt.Errorf("hyperliquid: client does not support permission checks")
+	}
+	return checker.CheckPermissions(ctx)
+}
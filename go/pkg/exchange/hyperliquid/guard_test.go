@@ -0,0 +1,101 @@
+package hyperliquid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+func TestClientBlocksUnlistedTransfers(t *testing.T) {
+	var posted atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted.Add(1)
+		_, _ = w.Write([]byte(`{"status":"ok","response":{"type":"default"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(clockTestKey, true)
+	require.NoError(t, err)
+	client.exchangeURL = server.URL
+	p := &Provider{client: client}
+	var blocked []exchange.TransferBlocked
+	p.OnTransferBlocked(func(ev exchange.TransferBlocked) { blocked = append(blocked, ev) })
+
+	err = p.TransferToSubAccount(context.Background(), "0x1111111111111111111111111111111111111111", 10)
+	require.ErrorIs(t, err, exchange.ErrTransferBlocked)
+	assert.Zero(t, posted.Load(), "blocked actions are never sent")
+	require.Len(t, blocked, 1)
+	assert.Equal(t, "hyperliquid", blocked[0].Provider)
+	assert.Equal(t, "subAccountTransfer", blocked[0].Action)
+
+	_, err = client.SignAction(map[string]any{"type": "withdraw3", "destination": "0x0", "amount": "1"})
+	assert.ErrorIs(t, err, exchange.ErrTransferBlocked)
+	_, err = client.SignAction(createSubAccountAction{Type: "createSubAccount", Name: "x"})
+	assert.NoError(t, err, "non-transfer actions are signed")
+
+	_, err = NewTransferGuard("hl", []string{"withdraw"})
+	assert.ErrorContains(t, err, `unknown transfer action "withdraw"`)
+}
+
+func TestClientChecksFenceBeforeSending(t *testing.T) {
+	var posted atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted.Add(1)
+		_, _ = w.Write([]byte(`{"status":"ok","response":{"type":"default"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(clockTestKey, true)
+	require.NoError(t, err)
+	client.exchangeURL = server.URL
+	action := createSubAccountAction{Type: "createSubAccount", Name: "x"}
+
+	stale := errors.New("lease lost")
+	var checked []int64
+	ctx := exchange.WithFence(context.Background(), 7, func(_ context.Context, token int64) error {
+		checked = append(checked, token)
+		return stale
+	})
+	err = client.doExchangeRequest(ctx, action, nil)
+	require.ErrorIs(t, err, stale)
+	assert.Zero(t, posted.Load(), "fenced-off actions are never sent")
+
+	ctx = exchange.WithFence(context.Background(), 8, func(_ context.Context, token int64) error {
+		checked = append(checked, token)
+		return nil
+	})
+	require.NoError(t, client.doExchangeRequest(ctx, action, nil))
+	assert.EqualValues(t, 1, posted.Load())
+	assert.Equal(t, []int64{7, 8}, checked)
+}
+
+func TestClientCheckPermissions(t *testing.T) {
+	role := "agent"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"role":"` + role + `"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(clockTestKey, true)
+	require.NoError(t, err)
+	client.infoURL = server.URL
+	p := &Provider{client: client}
+
+	perms, err := p.CheckPermissions(context.Background())
+	require.NoError(t, err)
+	assert.False(t, perms.Excessive(), "agent wallets cannot move funds")
+
+	role = "user"
+	perms, err = p.CheckPermissions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "user", perms.Role)
+	assert.True(t, perms.Excessive())
+}
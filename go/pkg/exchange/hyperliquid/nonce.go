@@ -0,0 +1,126 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidNonce reports that the exchange rejected a signed action's nonce,
+// either as a duplicate or as too far from exchange time.
+var ErrInvalidNonce = errors.New("hyperliquid: invalid nonce")
+
+// nonceMaxLead is how far ahead of exchange time a nonce may run; Hyperliquid
+// only accepts nonces within (T - 2 days, T + 1 day).
+const nonceMaxLead = 24 * time.Hour
+
+// nonceSource issues strictly increasing millisecond nonces. Hyperliquid
+// rejects reused nonces, and two actions signed within the same millisecond
+// (or across a backwards clock step) would otherwise collide.
+type nonceSource struct {
+	mu   sync.Mutex
+	last int64
+}
+
+func (n *nonceSource) next(now time.Time) int64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	nonce := now.UnixMilli()
+	if nonce <= n.last {
+		nonce = n.last + 1
+	}
+	n.last = nonce
+	return nonce
+}
+
+// resync rewinds the sequence when it has run past what the exchange accepts
+// relative to now, which happens after trading on a clock that ran fast. It
+// reports whether the sequence was reset.
+func (n *nonceSource) resync(now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.last <= now.Add(nonceMaxLead).UnixMilli() {
+		return false
+	}
+	n.last = now.UnixMilli()
+	return true
+}
+
+// SignAction signs action with the next nonce. REST exchange calls use it, and
+// websocket "post" requests must too, so both transports draw from one
+// monotonic sequence and cannot replay each other's nonces. Transfer actions
+// the guard does not allow are refused before a nonce is drawn.
+func (c *Client) SignAction(action interface{}) (*ExchangeRequest, error) {
+	if err := c.guard.Check(actionType(action)); err != nil {
+		return nil, err
+	}
+	return signAction(action, c.signer, c.nonces.next(c.nonceTime()), c.mainAddress, c.vault, !c.isTestnet)
+}
+
+// ResyncNonces re-measures the exchange clock and realigns the nonce sequence
+// with it. Nonces use the measured offset from then on, even without clock
+// skew adjustment, since the exchange has already rejected local time.
+func (c *Client) ResyncNonces(ctx context.Context) error {
+	if _, err := c.SyncClock(ctx); err != nil {
+		return fmt.Errorf("hyperliquid: resync nonces: %w", err)
+	}
+	c.skewMu.Lock()
+	c.nonceResynced = true
+	c.skewMu.Unlock()
+	if c.nonces.resync(c.nonceTime()) {
+		c.logf("hyperliquid: nonce sequence ran ahead of exchange time; reset")
+	}
+	return nil
+}
+
+// nonceTime is the clock nonces are drawn from: local time, shifted by the
+// measured skew when adjustment applies or after a nonce resync.
+func (c *Client) nonceTime() time.Time {
+	t := c.localNow()
+	c.skewMu.RLock()
+	skew, resynced := c.skew, c.nonceResynced
+	c.skewMu.RUnlock()
+	if skew.Adjusted || resynced {
+		t = t.Add(skew.Offset)
+	}
+	return t
+}
+
+// nonceError extracts a nonce rejection from an exchange response body, which
+// reports it as {"status":"err","response":"Invalid nonce: ..."}.
+func nonceError(body []byte) error {
+	var resp struct {
+		Status   string          `json:"status"`
+		Response json.RawMessage `json:"response"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Status != "err" {
+		return nil
+	}
+	var msg string
+	if err := json.Unmarshal(resp.Response, &msg); err != nil {
+		msg = string(resp.Response)
+	}
+	if !strings.Contains(strings.ToLower(msg), "nonce") {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidNonce, msg)
+}
+
+// ActionPostMessage wraps a signed action in a websocket "post" request.
+func ActionPostMessage(id uint64, req *ExchangeRequest) ([]byte, error) {
+	if req == nil {
+		return nil, fmt.Errorf("hyperliquid: nil exchange request")
+	}
+	return json.Marshal(map[string]interface{}{
+		"method": "post",
+		"id":     id,
+		"request": map[string]interface{}{
+			"type":    "action",
+			"payload": req,
+		},
+	})
+}
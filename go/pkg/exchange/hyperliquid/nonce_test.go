@@ -0,0 +1,159 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonceSourceIsStrictlyIncreasing(t *testing.T) {
+	var n nonceSource
+	at := time.UnixMilli(1_000)
+	assert.Equal(t, int64(1_000), n.next(at))
+	assert.Equal(t, int64(1_001), n.next(at), "same millisecond")
+	assert.Equal(t, int64(1_002), n.next(at.Add(-time.Second)), "clock stepped backwards")
+	assert.Equal(t, int64(5_000), n.next(time.UnixMilli(5_000)))
+}
+
+func TestNonceSourceConcurrentUse(t *testing.T) {
+	var n nonceSource
+	at := time.UnixMilli(1_000)
+	var (
+		mu   sync.Mutex
+		seen = map[int64]bool{}
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce := n.next(at)
+			mu.Lock()
+			seen[nonce] = true
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, 50, "no nonce is issued twice")
+}
+
+func TestNonceSourceResyncOnlyRewindsBeyondLead(t *testing.T) {
+	var n nonceSource
+	now := time.UnixMilli(10_000_000_000)
+	n.next(now.Add(time.Hour))
+	assert.False(t, n.resync(now), "an hour ahead is still accepted")
+	n.next(now.Add(48 * time.Hour))
+	assert.True(t, n.resync(now))
+	assert.Equal(t, now.UnixMilli()+1, n.next(now))
+}
+
+func TestNonceError(t *testing.T) {
+	err := nonceError([]byte(`{"status":"err","response":"Invalid nonce: duplicate nonce"}`))
+	assert.ErrorIs(t, err, ErrInvalidNonce)
+	assert.NoError(t, nonceError([]byte(`{"status":"err","response":"Insufficient margin"}`)))
+	assert.NoError(t, nonceError([]byte(`{"status":"ok","response":{"type":"default"}}`)))
+}
+
+func TestExchangeRequestRetriesAfterNonceError(t *testing.T) {
+	local := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var (
+		mu     sync.Mutex
+		nonces []int64
+		infos  int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The exchange runs 30s ahead of the local clock.
+		w.Header().Set("Date", local.Add(30*time.Second).Format(http.TimeFormat))
+		mu.Lock()
+		defer mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "/info") {
+			infos++
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		var req ExchangeRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		nonces = append(nonces, req.Nonce)
+		if len(nonces) == 1 {
+			_, _ = w.Write([]byte(`{"status":"err","response":"Invalid nonce: duplicate nonce"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok","response":{"type":"default"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(clockTestKey, false, WithClock(func() time.Time { return local }))
+	require.NoError(t, err)
+	client.infoURL = server.URL + "/info"
+	client.exchangeURL = server.URL + "/exchange"
+
+	var resp struct {
+		Status string `json:"status"`
+	}
+	require.NoError(t, client.doExchangeRequest(context.Background(), map[string]string{"type": "noop"}, &resp))
+	assert.Equal(t, "ok", resp.Status)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, nonces, 2)
+	assert.Equal(t, 1, infos, "one clock resync")
+	assert.Equal(t, local.Add(30500*time.Millisecond).UnixMilli(), nonces[1], "retry is signed on exchange time")
+
+	assert.Greater(t, client.nonces.next(local), nonces[1], "later nonces keep increasing")
+}
+
+func TestExchangeRequestGivesUpAfterSecondNonceError(t *testing.T) {
+	var posts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/exchange") {
+			posts++
+			_, _ = w.Write([]byte(`{"status":"err","response":"Invalid nonce: too low"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(clockTestKey, false)
+	require.NoError(t, err)
+	client.infoURL = server.URL + "/info"
+	client.exchangeURL = server.URL + "/exchange"
+
+	err = client.doExchangeRequest(context.Background(), map[string]string{"type": "noop"}, nil)
+	assert.ErrorIs(t, err, ErrInvalidNonce)
+	assert.Equal(t, 2, posts)
+}
+
+func TestActionPostMessage(t *testing.T) {
+	client, err := NewClient(clockTestKey, false, WithClock(func() time.Time { return time.UnixMilli(42) }))
+	require.NoError(t, err)
+	signed, err := client.SignAction(map[string]string{"type": "noop"})
+	require.NoError(t, err)
+
+	msg, err := ActionPostMessage(7, signed)
+	require.NoError(t, err)
+	var out struct {
+		Method  string `json:"method"`
+		ID      uint64 `json:"id"`
+		Request struct {
+			Type    string          `json:"type"`
+			Payload ExchangeRequest `json:"payload"`
+		} `json:"request"`
+	}
+	require.NoError(t, json.Unmarshal(msg, &out))
+	assert.Equal(t, "post", out.Method)
+	assert.Equal(t, uint64(7), out.ID)
+	assert.Equal(t, "action", out.Request.Type)
+	assert.Equal(t, int64(42), out.Request.Payload.Nonce)
+
+	_, err = ActionPostMessage(1, nil)
+	assert.Error(t, err)
+}
@@ -2,8 +2,10 @@ package hyperliquid
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"nof0-api/pkg/exchange"
 )
@@ -58,10 +60,65 @@ func init() {
 		if cfg.MainAddress != "" {
 			opts = append(opts, WithMainAddress(cfg.MainAddress))
 		}
+		if cfg.ClockSkewThreshold > 0 {
+			opts = append(opts, WithClockSkewThreshold(cfg.ClockSkewThreshold))
+		}
+		opts = append(opts, WithClockSkewAdjustment(cfg.AdjustClockSkew))
+		if cfg.RateLimitPerMinute != 0 {
+			opts = append(opts, WithRateLimit(cfg.RateLimitPerMinute))
+		}
+		guard, err := NewTransferGuard(name, cfg.AllowedTransfers)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithTransferGuard(guard))
 		return NewProvider(cfg.PrivateKey, cfg.Testnet, opts...)
 	})
 }
 
+// SyncClock implements exchange.ClockSyncer when the underlying client can
+// measure the exchange clock.
+func (p *Provider) SyncClock(ctx context.Context) (exchange.ClockSkew, error) {
+	syncer, ok := p.client.(exchange.ClockSyncer)
+	if !ok {
+		return exchange.ClockSkew{}, fmt.Errorf("hyperliquid: client does not support clock sync")
+	}
+	return syncer.SyncClock(ctx)
+}
+
+// SimulateOrder implements exchange.OrderSimulator by walking the order book.
+func (p *Provider) SimulateOrder(ctx context.Context, coin string, isBuy bool, qty float64) (*exchange.OrderEstimate, error) {
+	sim, ok := p.client.(exchange.OrderSimulator)
+	if !ok {
+		return nil, fmt.Errorf("hyperliquid: client does not support order simulation")
+	}
+	return sim.SimulateOrder(ctx, coin, isBuy, qty)
+}
+
+// fundingSource is implemented by clients that expose funding rates.
+type fundingSource interface {
+	GetFundingRates(ctx context.Context) ([]FundingRate, error)
+	GetFundingHistory(ctx context.Context, coin string, start, end time.Time) ([]FundingRate, error)
+}
+
+// GetFundingRates returns current funding rates for every listed perpetual.
+func (p *Provider) GetFundingRates(ctx context.Context) ([]FundingRate, error) {
+	src, ok := p.client.(fundingSource)
+	if !ok {
+		return nil, fmt.Errorf("hyperliquid: client does not support funding rates")
+	}
+	return src.GetFundingRates(ctx)
+}
+
+// GetFundingHistory returns settled funding rates for coin in [start, end].
+func (p *Provider) GetFundingHistory(ctx context.Context, coin string, start, end time.Time) ([]FundingRate, error) {
+	src, ok := p.client.(fundingSource)
+	if !ok {
+		return nil, fmt.Errorf("hyperliquid: client does not support funding history")
+	}
+	return src.GetFundingHistory(ctx, coin, start, end)
+}
+
 // PlaceOrder delegates to the underlying client.
 func (p *Provider) PlaceOrder(ctx context.Context, order exchange.Order) (*exchange.OrderResponse, error) {
 	return p.client.PlaceOrder(ctx, order)
@@ -0,0 +1,130 @@
+package hyperliquid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited reports that the exchange answered HTTP 429.
+var ErrRateLimited = errors.New("hyperliquid: rate limited")
+
+// RateLimitError is a 429 response with the wait the exchange asked for.
+type RateLimitError struct {
+	RetryAfter time.Duration
+	Body       string
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%v (retry after %s): %s", ErrRateLimited, e.RetryAfter, e.Body)
+}
+
+// Is lets errors.Is match ErrRateLimited.
+func (e *RateLimitError) Is(target error) bool { return target == ErrRateLimited }
+
+const (
+	// Hyperliquid allows 1200 request weight per minute per IP. Most info
+	// requests weigh 2 and an unbatched exchange action weighs 1.
+	defaultRateLimitPerMinute = 1200
+	infoRequestWeight         = 2
+	exchangeRequestWeight     = 1
+	maxRetryAfter             = 10 * time.Second
+)
+
+// WithRateLimit caps request weight sent per minute so the client stays
+// under the exchange's IP limit when several traders share it. 0 disables
+// client-side limiting; 429 responses are still retried.
+func WithRateLimit(weightPerMinute int) ClientOption {
+	return func(c *Client) {
+		if weightPerMinute <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = newWeightLimiter(weightPerMinute, time.Now)
+	}
+}
+
+// weightLimiter is a token bucket refilled continuously at perMinute/60
+// weight per second, holding at most one minute of weight.
+type weightLimiter struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	perSec   float64
+	last     time.Time
+	now      func() time.Time
+}
+
+func newWeightLimiter(perMinute int, now func() time.Time) *weightLimiter {
+	return &weightLimiter{
+		capacity: float64(perMinute),
+		tokens:   float64(perMinute),
+		perSec:   float64(perMinute) / 60,
+		last:     now(),
+		now:      now,
+	}
+}
+
+// reserve takes weight from the bucket and returns how long the caller must
+// wait before sending.
+func (l *weightLimiter) reserve(weight int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if elapsed := now.Sub(l.last).Seconds(); elapsed > 0 {
+		l.tokens += elapsed * l.perSec
+		if l.tokens > l.capacity {
+			l.tokens = l.capacity
+		}
+	}
+	l.last = now
+	l.tokens -= float64(weight)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.perSec * float64(time.Second))
+}
+
+// throttle blocks until the limiter admits a request of weight.
+func (c *Client) throttle(ctx context.Context, weight int) error {
+	if c.limiter == nil {
+		return nil
+	}
+	wait := c.limiter.reserve(weight)
+	if wait <= 0 {
+		return nil
+	}
+	c.logf("hyperliquid: throttling request for %s", wait)
+	return sleepCtx(ctx, wait)
+}
+
+// retryAfter reads the Retry-After header in seconds, falling back to
+// fallback and capping the wait at maxRetryAfter.
+func retryAfter(resp *http.Response, fallback time.Duration) time.Duration {
+	wait := fallback
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			wait = time.Duration(secs) * time.Second
+		}
+	}
+	if wait > maxRetryAfter {
+		wait = maxRetryAfter
+	}
+	return wait
+}
+
+// sleepCtx waits d or until ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
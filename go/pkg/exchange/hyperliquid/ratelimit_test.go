@@ -0,0 +1,79 @@
+package hyperliquid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeightLimiterReserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newWeightLimiter(60, func() time.Time { return now })
+	assert.Zero(t, l.reserve(60), "a full bucket admits a minute of weight")
+	assert.Equal(t, 2*time.Second, l.reserve(2), "refills at one weight per second")
+
+	now = now.Add(10 * time.Second)
+	assert.Zero(t, l.reserve(8))
+	now = now.Add(time.Hour)
+	assert.Zero(t, l.reserve(60), "refill is capped at capacity")
+	assert.Equal(t, time.Second, l.reserve(1))
+}
+
+func TestRetryAfter(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	assert.Equal(t, time.Second, retryAfter(resp, time.Second))
+	resp.Header.Set("Retry-After", "3")
+	assert.Equal(t, 3*time.Second, retryAfter(resp, time.Second))
+	resp.Header.Set("Retry-After", "600")
+	assert.Equal(t, maxRetryAfter, retryAfter(resp, time.Second))
+}
+
+func TestInfoRequestRetriesAfter429(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`rate limited`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"coin":"BTC","fundingRate":"0.0000125","premium":"-0.0002","time":1700000000000}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(clockTestKey, false)
+	require.NoError(t, err)
+	client.infoURL = server.URL + "/info"
+
+	rates, err := client.GetFundingHistory(context.Background(), "btc", time.UnixMilli(1_600_000_000_000), time.Time{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+	require.Len(t, rates, 1)
+	assert.Equal(t, "BTC", rates[0].Coin)
+	assert.InDelta(t, 0.0000125, rates[0].Rate, 1e-12)
+	assert.InDelta(t, -0.0002, rates[0].Premium, 1e-12)
+	assert.Equal(t, int64(1_700_000_000_000), rates[0].Time.UnixMilli())
+}
+
+func TestExchangeRequestSurfacesRateLimit(t *testing.T) {
+	var posts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&posts, 1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(clockTestKey, false, WithRateLimit(0))
+	require.NoError(t, err)
+	client.exchangeURL = server.URL + "/exchange"
+
+	err = client.doExchangeRequest(context.Background(), map[string]string{"type": "noop"}, nil)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&posts), "one retry after a 429")
+}
@@ -0,0 +1,115 @@
+package hyperliquid
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"nof0-api/pkg/exchange"
+)
+
+// BookLevel is one aggregated price level of the L2 book.
+type BookLevel struct {
+	Px string `json:"px"`
+	Sz string `json:"sz"`
+	N  int    `json:"n"`
+}
+
+// L2Book is the l2Book info response; Levels holds bids then asks, best
+// price first.
+type L2Book struct {
+	Coin   string         `json:"coin"`
+	Time   int64          `json:"time"`
+	Levels [2][]BookLevel `json:"levels"`
+}
+
+// GetL2Book fetches the aggregated order book for coin.
+func (c *Client) GetL2Book(ctx context.Context, coin string) (*L2Book, error) {
+	name := strings.TrimSpace(coin)
+	if info, ok := c.cachedAssetInfo(canonicalAssetKey(coin)); ok && info.Name != "" {
+		name = info.Name
+	}
+	if name == "" {
+		return nil, fmt.Errorf("hyperliquid: empty coin for l2Book")
+	}
+	var book L2Book
+	if err := c.doInfoRequest(ctx, InfoRequest{Type: "l2Book", Coin: name}, &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// SimulateOrder estimates a market order's fill by walking the visible book:
+// asks for buys, bids for sells. Hyperliquid has no estimate endpoint, so
+// hidden liquidity and book changes before the order lands are not captured.
+func (c *Client) SimulateOrder(ctx context.Context, coin string, isBuy bool, qty float64) (*exchange.OrderEstimate, error) {
+	if !(qty > 0) {
+		return nil, fmt.Errorf("hyperliquid: simulate %s: quantity must be positive", coin)
+	}
+	book, err := c.GetL2Book(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+	bids, err := parseBookLevels(book.Levels[0])
+	if err != nil {
+		return nil, fmt.Errorf("hyperliquid: simulate %s: %w", coin, err)
+	}
+	asks, err := parseBookLevels(book.Levels[1])
+	if err != nil {
+		return nil, fmt.Errorf("hyperliquid: simulate %s: %w", coin, err)
+	}
+	if len(bids) == 0 || len(asks) == 0 {
+		return nil, fmt.Errorf("hyperliquid: simulate %s: empty book side", coin)
+	}
+	est := &exchange.OrderEstimate{
+		Coin:     coin,
+		IsBuy:    isBuy,
+		Qty:      qty,
+		MidPrice: (bids[0][0] + asks[0][0]) / 2,
+	}
+	side := bids
+	if isBuy {
+		side = asks
+	}
+	var cost float64
+	for _, level := range side {
+		if est.FilledQty >= qty {
+			break
+		}
+		take := level[1]
+		if remaining := qty - est.FilledQty; take > remaining {
+			take = remaining
+		}
+		cost += take * level[0]
+		est.FilledQty += take
+		est.WorstPrice = level[0]
+		est.Levels++
+	}
+	if est.FilledQty > 0 {
+		est.AvgPrice = cost / est.FilledQty
+		adverse := est.AvgPrice - est.MidPrice
+		if !isBuy {
+			adverse = -adverse
+		}
+		est.SlippageBps = adverse / est.MidPrice * 10000
+	}
+	return est, nil
+}
+
+// parseBookLevels converts levels to [price, size] pairs.
+func parseBookLevels(levels []BookLevel) ([][2]float64, error) {
+	out := make([][2]float64, 0, len(levels))
+	for _, l := range levels {
+		px, err := strconv.ParseFloat(l.Px, 64)
+		if err != nil || !(px > 0) {
+			return nil, fmt.Errorf("invalid book price %q", l.Px)
+		}
+		sz, err := strconv.ParseFloat(l.Sz, 64)
+		if err != nil || sz < 0 {
+			return nil, fmt.Errorf("invalid book size %q", l.Sz)
+		}
+		out = append(out, [2]float64{px, sz})
+	}
+	return out, nil
+}
@@ -0,0 +1,64 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBookServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req InfoRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "l2Book", req.Type)
+		require.Equal(t, "BTC", req.Coin)
+		_, _ = w.Write([]byte(`{"coin":"BTC","time":1,"levels":[
+			[{"px":"99","sz":"1","n":1},{"px":"98","sz":"2","n":3}],
+			[{"px":"101","sz":"1","n":1},{"px":"102","sz":"1","n":2},{"px":"110","sz":"5","n":1}]
+		]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSimulateOrderWalksBook(t *testing.T) {
+	server := newBookServer(t)
+	client, err := NewClient(clockTestKey, false)
+	require.NoError(t, err)
+	client.infoURL = server.URL
+
+	est, err := client.SimulateOrder(context.Background(), "BTC", true, 1.5)
+	require.NoError(t, err)
+	assert.True(t, est.FullyFilled())
+	assert.Equal(t, 2, est.Levels)
+	assert.InDelta(t, (101+0.5*102)/1.5, est.AvgPrice, 1e-9)
+	assert.Equal(t, 102.0, est.WorstPrice)
+	assert.Equal(t, 100.0, est.MidPrice)
+	assert.InDelta(t, (est.AvgPrice-100)/100*10000, est.SlippageBps, 1e-9)
+
+	est, err = client.SimulateOrder(context.Background(), "BTC", false, 2)
+	require.NoError(t, err)
+	assert.InDelta(t, 98.5, est.AvgPrice, 1e-9)
+	assert.InDelta(t, 150, est.SlippageBps, 1e-9, "sells slip below mid")
+}
+
+func TestSimulateOrderReportsShallowBook(t *testing.T) {
+	server := newBookServer(t)
+	client, err := NewClient(clockTestKey, false)
+	require.NoError(t, err)
+	client.infoURL = server.URL
+
+	est, err := client.SimulateOrder(context.Background(), "BTC", false, 10)
+	require.NoError(t, err)
+	assert.False(t, est.FullyFilled())
+	assert.Equal(t, 3.0, est.FilledQty)
+
+	_, err = client.SimulateOrder(context.Background(), "BTC", true, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,207 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"nof0-api/pkg/exchange"
+)
+
+// createSubAccountAction creates a sub-account under the signing master.
+type createSubAccountAction struct {
+	Type string `json:"type" msgpack:"type"`
+	Name string `json:"name" msgpack:"name"`
+}
+
+// subAccountTransferAction moves USDC between the master and a sub-account.
+// Usd is in micro-dollars.
+type subAccountTransferAction struct {
+	Type           string `json:"type" msgpack:"type"`
+	SubAccountUser string `json:"subAccountUser" msgpack:"subAccountUser"`
+	IsDeposit      bool   `json:"isDeposit" msgpack:"isDeposit"`
+	Usd            int64  `json:"usd" msgpack:"usd"`
+}
+
+// subAccountResponse is the exchange reply to sub-account actions; Response
+// is an error string when Status is "err".
+type subAccountResponse struct {
+	Status   string          `json:"status"`
+	Response json.RawMessage `json:"response"`
+}
+
+func (r subAccountResponse) err(action string) error {
+	if r.Status == "ok" {
+		return nil
+	}
+	var msg string
+	if json.Unmarshal(r.Response, &msg) != nil {
+		msg = string(r.Response)
+	}
+	return fmt.Errorf("hyperliquid: %s rejected: %s", action, msg)
+}
+
+// ListSubAccounts returns the sub-accounts of the account this client
+// reads from.
+func (c *Client) ListSubAccounts(ctx context.Context) ([]SubAccount, error) {
+	return c.GetSubAccounts(ctx, c.getInfoAddress())
+}
+
+// CreateSubAccount creates a named sub-account and returns its address.
+func (c *Client) CreateSubAccount(ctx context.Context, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("hyperliquid: sub-account name is required")
+	}
+	var resp subAccountResponse
+	if err := c.doExchangeRequest(ctx, createSubAccountAction{Type: "createSubAccount", Name: name}, &resp); err != nil {
+		return "", err
+	}
+	if err := resp.err("createSubAccount"); err != nil {
+		return "", err
+	}
+	var data struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Response, &data); err != nil || !common.IsHexAddress(data.Data) {
+		return "", fmt.Errorf("hyperliquid: createSubAccount returned no address: %s", string(resp.Response))
+	}
+	return strings.ToLower(common.HexToAddress(data.Data).Hex()), nil
+}
+
+// SubAccountTransfer moves usd of collateral into the sub-account at address
+// when deposit is true, or back to the master otherwise.
+func (c *Client) SubAccountTransfer(ctx context.Context, address string, usd float64, deposit bool) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("hyperliquid: invalid sub-account address %q", address)
+	}
+	micros := int64(math.Round(usd * 1e6))
+	if micros <= 0 {
+		return fmt.Errorf("hyperliquid: transfer amount must be positive, got %.6f", usd)
+	}
+	action := subAccountTransferAction{
+		Type:           "subAccountTransfer",
+		SubAccountUser: strings.ToLower(common.HexToAddress(address).Hex()),
+		IsDeposit:      deposit,
+		Usd:            micros,
+	}
+	var resp subAccountResponse
+	if err := c.doExchangeRequest(ctx, action, &resp); err != nil {
+		return err
+	}
+	return resp.err("subAccountTransfer")
+}
+
+// ForSubAccount returns a client that trades the sub-account at address:
+// actions are signed by the master key on the sub-account's behalf and
+// account reads target the sub-account. It shares the master's nonce
+// sequence, rate limiter and transfer guard.
+func (c *Client) ForSubAccount(address string) (*Client, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("hyperliquid: invalid sub-account address %q", address)
+	}
+	addr := common.HexToAddress(address).Hex()
+	return &Client{
+		infoURL:         c.infoURL,
+		exchangeURL:     c.exchangeURL,
+		httpClient:      c.httpClient,
+		signer:          c.signer,
+		address:         c.address,
+		mainAddress:     strings.ToLower(addr),
+		isTestnet:       c.isTestnet,
+		logger:          c.logger,
+		clock:           c.clock,
+		vault:           addr,
+		assetIndex:      make(map[string]int),
+		assetInfo:       make(map[string]AssetInfo),
+		defaultSlippage: c.defaultSlippage,
+		priceSigFigs:    c.priceSigFigs,
+		assetTTL:        c.assetTTL,
+		skewThreshold:   c.skewThreshold,
+		adjustSkew:      c.adjustSkew,
+		nonces:          c.nonces,
+		limiter:         c.limiter,
+		guard:           c.guard,
+	}, nil
+}
+
+// subAccountSource is implemented by clients that manage sub-accounts.
+type subAccountSource interface {
+	ListSubAccounts(ctx context.Context) ([]SubAccount, error)
+	CreateSubAccount(ctx context.Context, name string) (string, error)
+	SubAccountTransfer(ctx context.Context, address string, usd float64, deposit bool) error
+	ForSubAccount(address string) (*Client, error)
+}
+
+func (p *Provider) subAccounts() (subAccountSource, error) {
+	src, ok := p.client.(subAccountSource)
+	if !ok {
+		return nil, fmt.Errorf("hyperliquid: client does not support sub-accounts")
+	}
+	return src, nil
+}
+
+// SubAccounts implements exchange.SubAccountManager.
+func (p *Provider) SubAccounts(ctx context.Context) ([]exchange.SubAccount, error) {
+	src, err := p.subAccounts()
+	if err != nil {
+		return nil, err
+	}
+	subs, err := src.ListSubAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]exchange.SubAccount, 0, len(subs))
+	for _, s := range subs {
+		value, _ := strconv.ParseFloat(s.ClearinghouseState.MarginSummary.AccountValue, 64)
+		out = append(out, exchange.SubAccount{
+			Name:         s.Name,
+			Address:      strings.ToLower(s.SubAccountUser),
+			AccountValue: value,
+		})
+	}
+	return out, nil
+}
+
+// CreateSubAccount implements exchange.SubAccountManager.
+func (p *Provider) CreateSubAccount(ctx context.Context, name string) (exchange.SubAccount, error) {
+	src, err := p.subAccounts()
+	if err != nil {
+		return exchange.SubAccount{}, err
+	}
+	addr, err := src.CreateSubAccount(ctx, name)
+	if err != nil {
+		return exchange.SubAccount{}, err
+	}
+	return exchange.SubAccount{Name: strings.TrimSpace(name), Address: addr}, nil
+}
+
+// TransferToSubAccount implements exchange.SubAccountManager.
+func (p *Provider) TransferToSubAccount(ctx context.Context, address string, usd float64) error {
+	src, err := p.subAccounts()
+	if err != nil {
+		return err
+	}
+	if usd < 0 {
+		return src.SubAccountTransfer(ctx, address, -usd, false)
+	}
+	return src.SubAccountTransfer(ctx, address, usd, true)
+}
+
+// SubAccountProvider implements exchange.SubAccountManager.
+func (p *Provider) SubAccountProvider(address string) (exchange.Provider, error) {
+	src, err := p.subAccounts()
+	if err != nil {
+		return nil, err
+	}
+	client, err := src.ForSubAccount(address)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{client: client}, nil
+}
@@ -0,0 +1,91 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderSubAccounts(t *testing.T) {
+	const sub = "0x1111111111111111111111111111111111111111"
+	var (
+		mu      sync.Mutex
+		actions []map[string]any
+		vaults  []string
+		infos   []map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "/info") {
+			var req map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			infos = append(infos, req)
+			if req["type"] == "subAccounts" {
+				_, _ = w.Write([]byte(`[{"name":"nof0-gpt-5","subAccountUser":"` + sub + `","master":"0x0","clearinghouseState":{"marginSummary":{"accountValue":"250.5"}}}]`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"marginSummary":{"accountValue":"250.5"},"assetPositions":[]}`))
+			return
+		}
+		var req struct {
+			Action       map[string]any `json:"action"`
+			VaultAddress string         `json:"vaultAddress"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		actions = append(actions, req.Action)
+		vaults = append(vaults, req.VaultAddress)
+		switch req.Action["type"] {
+		case "createSubAccount":
+			_, _ = w.Write([]byte(`{"status":"ok","response":{"type":"createSubAccount","data":"` + sub + `"}}`))
+		case "subAccountTransfer":
+			_, _ = w.Write([]byte(`{"status":"err","response":"Insufficient balance"}`))
+		}
+	}))
+	defer server.Close()
+
+	guard, err := NewTransferGuard("hl", []string{"subAccountTransfer"})
+	require.NoError(t, err)
+	client, err := NewClient(clockTestKey, true, WithTransferGuard(guard))
+	require.NoError(t, err)
+	client.infoURL = server.URL + "/info"
+	client.exchangeURL = server.URL + "/exchange"
+	p := &Provider{client: client}
+	ctx := context.Background()
+
+	subs, err := p.SubAccounts(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, "nof0-gpt-5", subs[0].Name)
+	assert.Equal(t, sub, subs[0].Address)
+	assert.Equal(t, 250.5, subs[0].AccountValue)
+
+	created, err := p.CreateSubAccount(ctx, " nof0-qwen ")
+	require.NoError(t, err)
+	assert.Equal(t, sub, created.Address)
+
+	err = p.TransferToSubAccount(ctx, sub, -12.5)
+	assert.EqualError(t, err, "hyperliquid: subAccountTransfer rejected: Insufficient balance")
+
+	subProvider, err := p.SubAccountProvider(sub)
+	require.NoError(t, err)
+	value, err := subProvider.GetAccountValue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 250.5, value)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, actions, 2)
+	assert.Equal(t, "nof0-qwen", actions[0]["name"])
+	assert.Equal(t, map[string]any{"type": "subAccountTransfer", "subAccountUser": sub, "isDeposit": false, "usd": float64(12_500_000)}, actions[1])
+	assert.Equal(t, []string{"", ""}, vaults, "account management is signed by the master")
+	assert.Equal(t, sub, infos[len(infos)-1]["user"], "sub-account provider reads the sub-account")
+	assert.Same(t, client.nonces, subProvider.(*Provider).client.(*Client).nonces, "nonce sequence is shared")
+}
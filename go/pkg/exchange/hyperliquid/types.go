@@ -143,8 +143,13 @@ type Signature struct {
 type InfoRequest struct {
 	Type string `json:"type"`
 	User string `json:"user,omitempty"`
+	// For l2Book endpoint
+	Coin string `json:"coin,omitempty"`
 	// For vaultDetails endpoint
 	VaultAddress string `json:"vaultAddress,omitempty"`
+	// For fundingHistory endpoint (milliseconds)
+	StartTime int64 `json:"startTime,omitempty"`
+	EndTime   int64 `json:"endTime,omitempty"`
 }
 
 // AccountStateResponse wraps account state returned by Hyperliquid.
@@ -1,6 +1,9 @@
 package exchange
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Provider exposes trading capabilities in an exchange-agnostic fashion.
 type Provider interface {
@@ -21,3 +24,88 @@ type Provider interface {
 	// Utilities.
 	GetAssetIndex(ctx context.Context, coin string) (int, error)
 }
+
+// Exchange is the trading surface the decision loop drives; Provider is its
+// historical name and both refer to the same interface, so live venues and
+// the paper exchange in pkg/exchange/sim are interchangeable.
+type Exchange = Provider
+
+// MarkPriceSource returns the current price of coin, typically the last
+// price of the trader's market provider.
+type MarkPriceSource func(ctx context.Context, coin string) (float64, error)
+
+// MarkPriceFeeder is implemented by paper exchanges that mark open positions
+// to live market data instead of only to their own fills.
+type MarkPriceFeeder interface {
+	SetMarkPriceSource(src MarkPriceSource)
+}
+
+// HedgeCloser is implemented by providers whose accounts can run in hedge
+// mode. ClosePosition flattens a symbol's net position; ClosePositionSide
+// closes only its long or short leg. Opens need no extension: a hedge-mode
+// account books a buy onto the long leg and a sell onto the short leg.
+type HedgeCloser interface {
+	ClosePositionSide(ctx context.Context, coin string, long bool) (*OrderResponse, error)
+}
+
+// SubAccountManager is implemented by providers whose exchange supports
+// sub-accounts: separately margined accounts under one master key. A trader
+// on its own sub-account can lose at most the collateral moved there, so
+// one misbehaving strategy cannot draw down margin shared with others.
+type SubAccountManager interface {
+	SubAccounts(ctx context.Context) ([]SubAccount, error)
+	CreateSubAccount(ctx context.Context, name string) (SubAccount, error)
+	// TransferToSubAccount moves usd of collateral from the master account
+	// to the sub-account at address; a negative amount moves it back.
+	TransferToSubAccount(ctx context.Context, address string, usd float64) error
+	// SubAccountProvider returns a provider trading the sub-account at address.
+	SubAccountProvider(address string) (Provider, error)
+}
+
+// SubAccount is one sub-account of the master account.
+type SubAccount struct {
+	Name    string
+	Address string
+	// AccountValue is the sub-account's equity in USD.
+	AccountValue float64
+}
+
+// ClockSyncer is implemented by providers that can compare the local clock
+// with the exchange's. Signed requests carry local timestamps and candles are
+// bucketed by them, so drift breaks both without an obvious error.
+type ClockSyncer interface {
+	SyncClock(ctx context.Context) (ClockSkew, error)
+}
+
+// ClockSkew is one comparison of the local clock against the exchange clock.
+type ClockSkew struct {
+	// Offset is exchange time minus local time; positive means the local
+	// clock is behind.
+	Offset time.Duration
+	// RTT is the round trip of the measuring request; the true offset is
+	// only known to within half of it plus the server clock's resolution.
+	RTT       time.Duration
+	Threshold time.Duration
+	// Adjusted reports whether signed timestamps are corrected by Offset.
+	Adjusted   bool
+	MeasuredAt time.Time
+}
+
+// Exceeded reports whether the offset is beyond the configured threshold.
+func (s ClockSkew) Exceeded() bool {
+	if s.Threshold <= 0 {
+		return false
+	}
+	offset := s.Offset
+	if offset < 0 {
+		offset = -offset
+	}
+	return offset > s.Threshold
+}
+
+// OrderSimulator is implemented by providers that can estimate a market
+// order's fill before sending it, from an exchange estimate endpoint or by
+// walking the visible order book.
+type OrderSimulator interface {
+	SimulateOrder(ctx context.Context, coin string, isBuy bool, qty float64) (*OrderEstimate, error)
+}
@@ -0,0 +1,58 @@
+package exchange
+
+import (
+	"math"
+	"strings"
+)
+
+// MarginMode selects how a position is collateralised.
+type MarginMode string
+
+const (
+	// MarginCross backs every cross position with the account's shared
+	// collateral, so one position's loss can be absorbed by the rest.
+	MarginCross MarginMode = "cross"
+	// MarginIsolated backs a position only with the margin posted for it;
+	// liquidation loses that margin and no more.
+	MarginIsolated MarginMode = "isolated"
+)
+
+// ParseMarginMode normalises a configured margin mode; empty means cross.
+func ParseMarginMode(s string) (MarginMode, bool) {
+	switch m := MarginMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return MarginCross, true
+	case MarginCross, MarginIsolated:
+		return m, true
+	default:
+		return m, false
+	}
+}
+
+// IsCross reports whether m shares collateral across positions. Anything
+// but isolated is cross, matching the venue default.
+func (m MarginMode) IsCross() bool {
+	return m != MarginIsolated
+}
+
+// LiquidationPrice returns the mark at which a position of signed size qty,
+// currently marked at mark, is liquidated. marginAvailable is the collateral
+// above maintenance at mark: the position's own margin plus its PnL when
+// isolated, or the account's cross collateral when cross. mmr is the
+// maintenance margin rate (maintenance margin / notional). It returns false
+// when no positive price liquidates the position, e.g. a long whose margin
+// covers its whole notional.
+func LiquidationPrice(qty, mark, marginAvailable, mmr float64) (float64, bool) {
+	if qty == 0 || mark <= 0 {
+		return 0, false
+	}
+	side := 1.0
+	if qty < 0 {
+		side = -1
+	}
+	px := mark - side*marginAvailable/math.Abs(qty)/(1-mmr*side)
+	if px <= 0 || math.IsNaN(px) || math.IsInf(px, 0) {
+		return 0, false
+	}
+	return px, true
+}
@@ -0,0 +1,40 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiquidationPrice(t *testing.T) {
+	// Isolated 10x long of 1 @ 100: margin 10, maintenance 1.
+	px, ok := LiquidationPrice(1, 100, 9, 0.01)
+	assert.True(t, ok)
+	assert.InDelta(t, 90.909090, px, 1e-6)
+	// At that price equity (10 - 9.09) equals maintenance (1% of 90.91).
+	assert.InDelta(t, 0.01*px, 10+(px-100), 1e-9)
+
+	px, ok = LiquidationPrice(-2, 100, 18, 0.01)
+	assert.True(t, ok)
+	assert.InDelta(t, 108.910891, px, 1e-6)
+	assert.InDelta(t, 0.01*2*px, 20-2*(px-100), 1e-9)
+
+	_, ok = LiquidationPrice(1, 100, 150, 0.01)
+	assert.False(t, ok, "collateral beyond notional cannot be liquidated")
+	_, ok = LiquidationPrice(0, 100, 10, 0.01)
+	assert.False(t, ok)
+}
+
+func TestParseMarginMode(t *testing.T) {
+	mode, ok := ParseMarginMode(" Isolated ")
+	assert.True(t, ok)
+	assert.Equal(t, MarginIsolated, mode)
+	assert.False(t, mode.IsCross())
+
+	mode, ok = ParseMarginMode("")
+	assert.True(t, ok)
+	assert.True(t, mode.IsCross())
+
+	_, ok = ParseMarginMode("portfolio")
+	assert.False(t, ok)
+}
@@ -0,0 +1,32 @@
+package exchange
+
+import "strings"
+
+// PositionMode is how an account holds positions on a symbol.
+type PositionMode string
+
+const (
+	// PositionModeNet keeps one signed position per symbol: an order against
+	// it reduces or flips it. Hyperliquid accounts always net.
+	PositionModeNet PositionMode = "net"
+	// PositionModeHedge keeps a long and a short leg per symbol that are
+	// opened and closed independently.
+	PositionModeHedge PositionMode = "hedge"
+)
+
+// ParsePositionMode normalises a configured position mode; empty means net.
+func ParsePositionMode(s string) (PositionMode, bool) {
+	switch m := PositionMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return PositionModeNet, true
+	case PositionModeNet, PositionModeHedge:
+		return m, true
+	default:
+		return m, false
+	}
+}
+
+// IsHedge reports whether m holds long and short legs separately.
+func (m PositionMode) IsHedge() bool {
+	return m == PositionModeHedge
+}
@@ -0,0 +1,21 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePositionMode(t *testing.T) {
+	mode, ok := ParsePositionMode("")
+	assert.True(t, ok)
+	assert.Equal(t, PositionModeNet, mode)
+	assert.False(t, mode.IsHedge())
+
+	mode, ok = ParsePositionMode(" HEDGE ")
+	assert.True(t, ok)
+	assert.True(t, mode.IsHedge())
+
+	_, ok = ParsePositionMode("one_way")
+	assert.False(t, ok)
+}
@@ -0,0 +1,95 @@
+package sim
+
+import (
+	"context"
+	"time"
+
+	"nof0-api/pkg/exchange"
+)
+
+// defaultMarkRefresh bounds how often one coin is re-priced from the live
+// source, so account reads within a cycle reuse one market lookup.
+const defaultMarkRefresh = 5 * time.Second
+
+// Option customises the simulator.
+type Option func(*Provider)
+
+// WithInitialEquity seeds the paper account with equity USD of cash.
+func WithInitialEquity(equity float64) Option {
+	return func(p *Provider) {
+		if equity > 0 {
+			p.initialEquity = equity
+			p.cash = equity
+		}
+	}
+}
+
+// WithMaintenanceMarginRate sets the maintenance margin, as a fraction of
+// notional, that liquidation prices are computed against.
+func WithMaintenanceMarginRate(rate float64) Option {
+	return func(p *Provider) {
+		if rate > 0 && rate < 1 {
+			p.mmr = rate
+		}
+	}
+}
+
+// WithMarkPriceSource marks positions and prices market orders from src.
+func WithMarkPriceSource(src exchange.MarkPriceSource) Option {
+	return func(p *Provider) { p.source = src }
+}
+
+// WithMarkRefresh sets the minimum interval between live price lookups for
+// one coin; 0 looks up on every read.
+func WithMarkRefresh(d time.Duration) Option {
+	return func(p *Provider) {
+		if d >= 0 {
+			p.markRefresh = d
+		}
+	}
+}
+
+// SetMarkPriceSource implements exchange.MarkPriceFeeder so the manager can
+// back a paper account with the trader's live market data.
+func (p *Provider) SetMarkPriceSource(src exchange.MarkPriceSource) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.source = src
+}
+
+// refreshMarks re-prices coins (every open position when none are given)
+// from the live source. Lookups run without the lock; a failed lookup keeps
+// the previous mark so a market data hiccup never fails an account read.
+func (p *Provider) refreshMarks(ctx context.Context, coins ...string) {
+	p.mu.Lock()
+	src := p.source
+	if src == nil {
+		p.mu.Unlock()
+		return
+	}
+	if len(coins) == 0 {
+		for coin := range p.positions {
+			coins = append(coins, coin)
+		}
+	}
+	now := p.now()
+	due := coins[:0:0]
+	for _, coin := range coins {
+		if last, ok := p.markedAt[coin]; ok && now.Sub(last) < p.markRefresh {
+			continue
+		}
+		due = append(due, coin)
+	}
+	p.mu.Unlock()
+
+	for _, coin := range due {
+		price, err := src(ctx, coin)
+		if err != nil || !(price > 0) {
+			continue
+		}
+		p.mu.Lock()
+		p.markPx[coin] = price
+		p.markedAt[coin] = now
+		p.mu.Unlock()
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"nof0-api/pkg/exchange"
 )
@@ -15,6 +16,9 @@ import (
 const (
 	defaultInitialEquity = 100000.0
 	defaultFallbackPrice = 100.0
+	// defaultMaintenanceMarginRate is half the initial margin at 50x, the
+	// venue's rule for its most liquid perps.
+	defaultMaintenanceMarginRate = 0.01
 )
 
 // Provider is a paper-trading exchange implementation that keeps positions,
@@ -33,6 +37,14 @@ type Provider struct {
 
 	initialEquity float64
 	cash          float64
+	// mmr is the maintenance margin rate used for liquidation prices.
+	mmr float64
+
+	// Live marking (see marks.go); source nil keeps fill-only marks.
+	source      exchange.MarkPriceSource
+	markedAt    map[string]time.Time
+	markRefresh time.Duration
+	now         func() time.Time
 }
 
 type positionState struct {
@@ -42,8 +54,8 @@ type positionState struct {
 }
 
 // New constructs a new simulator instance with default equity.
-func New() *Provider {
-	return &Provider{
+func New(opts ...Option) *Provider {
+	p := &Provider{
 		nextAssetID:   1,
 		assetIndex:    make(map[string]int),
 		assetSymbol:   make(map[int]string),
@@ -52,7 +64,15 @@ func New() *Provider {
 		positions:     make(map[string]*positionState),
 		initialEquity: defaultInitialEquity,
 		cash:          defaultInitialEquity,
+		mmr:           defaultMaintenanceMarginRate,
+		markedAt:      make(map[string]time.Time),
+		markRefresh:   defaultMarkRefresh,
+		now:           time.Now,
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 func canonical(coin string) string { return strings.ToUpper(strings.TrimSpace(coin)) }
@@ -86,6 +106,9 @@ func (p *Provider) SetMarkPrice(ctx context.Context, coin string, price float64)
 
 // PlaceOrder synchronously fills an IOC-like order at the provided limit price.
 func (p *Provider) PlaceOrder(ctx context.Context, order exchange.Order) (*exchange.OrderResponse, error) {
+	if err := exchange.CheckFence(ctx); err != nil {
+		return nil, err
+	}
 	if order.Sz == "" {
 		return nil, fmt.Errorf("sim: order size is required")
 	}
@@ -232,6 +255,10 @@ func (p *Provider) IOCMarket(ctx context.Context, coin string, isBuy bool, qty f
 	if qty <= 0 {
 		return nil, fmt.Errorf("sim: IOCMarket qty must be positive")
 	}
+	if err := exchange.CheckFence(ctx); err != nil {
+		return nil, err
+	}
+	p.refreshMarks(ctx, canonical(coin))
 	p.mu.Lock()
 	price := p.resolveMarkPriceLocked(canonical(coin))
 	if price <= 0 {
@@ -265,16 +292,20 @@ func (p *Provider) IOCMarket(ctx context.Context, coin string, isBuy bool, qty f
 
 // GetPositions returns the current open positions with mark-to-market values.
 func (p *Provider) GetPositions(ctx context.Context) ([]exchange.Position, error) {
+	p.refreshMarks(ctx)
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	positions, _, _, _ := p.buildAccountSnapshotLocked()
-	return positions, nil
+	return p.buildAccountSnapshotLocked().positions, nil
 }
 
 // ClosePosition fully closes the position for the given coin at the latest mark price.
 func (p *Provider) ClosePosition(ctx context.Context, coin string) (*exchange.OrderResponse, error) {
+	if err := exchange.CheckFence(ctx); err != nil {
+		return nil, err
+	}
 	c := canonical(coin)
+	p.refreshMarks(ctx, c)
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -325,42 +356,46 @@ func (p *Provider) UpdateLeverage(ctx context.Context, asset int, isCross bool,
 	}
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	mode := map[bool]string{true: "cross", false: "isolated"}[isCross]
-	p.leverage[asset] = exchange.Leverage{Type: mode, Value: leverage}
+	mode := exchange.MarginIsolated
+	if isCross {
+		mode = exchange.MarginCross
+	}
+	p.leverage[asset] = exchange.Leverage{Type: string(mode), Value: leverage}
 	return nil
 }
 
 // GetAccountState returns a snapshot with equity, margin usage and open positions.
 func (p *Provider) GetAccountState(ctx context.Context) (*exchange.AccountState, error) {
+	p.refreshMarks(ctx)
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	positions, unrealized, notional, margin := p.buildAccountSnapshotLocked()
-	equity := p.cash + unrealized
+	snap := p.buildAccountSnapshotLocked()
+	equity := p.cash + snap.unrealized
 	state := &exchange.AccountState{
 		MarginSummary: exchange.MarginSummary{
 			AccountValue:    formatDecimal(equity),
-			TotalMarginUsed: formatDecimal(margin),
-			TotalNtlPos:     formatDecimal(notional),
-			TotalRawUSD:     formatDecimal(notional),
+			TotalMarginUsed: formatDecimal(snap.margin),
+			TotalNtlPos:     formatDecimal(snap.notional),
+			TotalRawUSD:     formatDecimal(snap.notional),
 		},
 		CrossMarginSummary: exchange.CrossMarginSummary{
-			AccountValue:    formatDecimal(equity),
-			TotalMarginUsed: formatDecimal(margin),
-			TotalNtlPos:     formatDecimal(notional),
-			TotalRawUSD:     formatDecimal(notional),
+			AccountValue:    formatDecimal(snap.crossValue),
+			TotalMarginUsed: formatDecimal(snap.crossMargin),
+			TotalNtlPos:     formatDecimal(snap.crossNotional),
+			TotalRawUSD:     formatDecimal(snap.crossNotional),
 		},
-		AssetPositions: positions,
+		AssetPositions: snap.positions,
 	}
 	return state, nil
 }
 
 // GetAccountValue returns the current equity (cash + unrealised PnL).
 func (p *Provider) GetAccountValue(ctx context.Context) (float64, error) {
+	p.refreshMarks(ctx)
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	_, unrealized, _, _ := p.buildAccountSnapshotLocked()
-	return p.cash + unrealized, nil
+	return p.cash + p.buildAccountSnapshotLocked().unrealized, nil
 }
 
 // FormatPrice normalises price formatting to 8 decimal places.
@@ -387,7 +422,14 @@ func (p *Provider) CancelAllBySymbol(ctx context.Context, coin string) error {
 // Registry hook for exchange.Config.
 func init() {
 	exchange.RegisterProvider("sim", func(name string, cfg *exchange.ProviderConfig) (exchange.Provider, error) {
-		return New(), nil
+		var opts []Option
+		if cfg.InitialEquity > 0 {
+			opts = append(opts, WithInitialEquity(cfg.InitialEquity))
+		}
+		if cfg.MaintenanceMarginRate > 0 {
+			opts = append(opts, WithMaintenanceMarginRate(cfg.MaintenanceMarginRate))
+		}
+		return New(opts...), nil
 	})
 }
 
@@ -401,27 +443,61 @@ func (p *Provider) resolveMarkPriceLocked(coin string) float64 {
 	return defaultFallbackPrice
 }
 
-func (p *Provider) buildAccountSnapshotLocked() ([]exchange.Position, float64, float64, float64) {
-	positions := make([]exchange.Position, 0, len(p.positions))
-	totalUnreal := 0.0
-	totalNotional := 0.0
-	totalMargin := 0.0
+// accountSnapshot is the marked account. Cross figures exclude isolated
+// positions, whose margin is set aside from the shared collateral.
+type accountSnapshot struct {
+	positions     []exchange.Position
+	unrealized    float64
+	notional      float64
+	margin        float64
+	crossValue    float64
+	crossNotional float64
+	crossMargin   float64
+}
 
+func (p *Provider) buildAccountSnapshotLocked() accountSnapshot {
+	type marked struct {
+		state    *positionState
+		mark     float64
+		unreal   float64
+		notional float64
+		margin   float64
+		lev      exchange.Leverage
+	}
+	var snap accountSnapshot
+	rows := make([]marked, 0, len(p.positions))
+	crossMaintenance := 0.0
+	snap.crossValue = p.cash
 	for coin, state := range p.positions {
-		qty := state.Qty
 		mark := p.resolveMarkPriceLocked(coin)
-		notional := math.Abs(qty * mark)
-		unreal := qty * (mark - state.Entry)
-		lev := p.leverageForCoinLocked(coin)
-		margin := notional
-		if lev.Value > 0 {
-			margin = notional / float64(lev.Value)
+		row := marked{
+			state:    state,
+			mark:     mark,
+			unreal:   state.Qty * (mark - state.Entry),
+			notional: math.Abs(state.Qty * mark),
+			lev:      p.leverageForCoinLocked(coin),
 		}
+		row.margin = row.notional
+		if row.lev.Value > 0 {
+			row.margin = row.notional / float64(row.lev.Value)
+		}
+		snap.unrealized += row.unreal
+		snap.notional += row.notional
+		snap.margin += row.margin
+		if exchange.MarginMode(row.lev.Type).IsCross() {
+			snap.crossValue += row.unreal
+			snap.crossNotional += row.notional
+			snap.crossMargin += row.margin
+			crossMaintenance += p.mmr * row.notional
+		} else {
+			snap.crossValue -= isolatedMargin(state, row.lev.Value)
+		}
+		rows = append(rows, row)
+	}
 
-		totalUnreal += unreal
-		totalNotional += notional
-		totalMargin += margin
-
+	snap.positions = make([]exchange.Position, 0, len(rows))
+	for _, row := range rows {
+		state := row.state
 		var entryPtr *string
 		if state.Entry > 0 {
 			entry := formatDecimal(state.Entry)
@@ -429,25 +505,45 @@ func (p *Provider) buildAccountSnapshotLocked() ([]exchange.Position, float64, f
 			*entryPtr = entry
 		}
 		roe := "0"
-		if margin > 0 {
-			roe = formatDecimal((unreal / margin) * 100)
+		if row.margin > 0 {
+			roe = formatDecimal((row.unreal / row.margin) * 100)
+		}
+		// Collateral above maintenance: the shared cross pool, or the
+		// position's own margin and PnL when isolated.
+		available := snap.crossValue - crossMaintenance
+		if !exchange.MarginMode(row.lev.Type).IsCross() {
+			available = isolatedMargin(state, row.lev.Value) + row.unreal - p.mmr*row.notional
 		}
-		pos := exchange.Position{
-			Coin:           coin,
+		var liqPtr *string
+		if px, ok := exchange.LiquidationPrice(state.Qty, row.mark, available, p.mmr); ok {
+			liq := formatDecimal(px)
+			liqPtr = &liq
+		}
+		snap.positions = append(snap.positions, exchange.Position{
+			Coin:           state.Coin,
 			EntryPx:        entryPtr,
-			PositionValue:  formatDecimal(notional),
-			Szi:            formatDecimal(qty),
-			UnrealizedPnl:  formatDecimal(unreal),
+			PositionValue:  formatDecimal(row.notional),
+			Szi:            formatDecimal(state.Qty),
+			UnrealizedPnl:  formatDecimal(row.unreal),
 			ReturnOnEquity: roe,
-			Leverage:       lev,
-		}
-		positions = append(positions, pos)
+			Leverage:       row.lev,
+			LiquidationPx:  liqPtr,
+		})
 	}
 
-	sort.Slice(positions, func(i, j int) bool {
-		return positions[i].Coin < positions[j].Coin
+	sort.Slice(snap.positions, func(i, j int) bool {
+		return snap.positions[i].Coin < snap.positions[j].Coin
 	})
-	return positions, totalUnreal, totalNotional, totalMargin
+	return snap
+}
+
+// isolatedMargin is the margin posted when an isolated position was opened.
+func isolatedMargin(state *positionState, leverage int) float64 {
+	margin := math.Abs(state.Qty * state.Entry)
+	if leverage > 0 {
+		margin /= float64(leverage)
+	}
+	return margin
 }
 
 func (p *Provider) leverageForCoinLocked(coin string) exchange.Leverage {
@@ -456,7 +552,7 @@ func (p *Provider) leverageForCoinLocked(coin string) exchange.Leverage {
 			return lev
 		}
 	}
-	return exchange.Leverage{Type: "cross", Value: 1}
+	return exchange.Leverage{Type: string(exchange.MarginCross), Value: 1}
 }
 
 func formatDecimal(v float64) string {
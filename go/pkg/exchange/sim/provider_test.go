@@ -2,8 +2,10 @@ package sim
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"nof0-api/pkg/exchange"
@@ -37,6 +39,23 @@ func TestSimProvider_BasicFlow(t *testing.T) {
 	assert.Len(t, pos, 0, "position list should be empty after close")
 }
 
+func TestSimProvider_ChecksFence(t *testing.T) {
+	p := New()
+	stale := errors.New("lease lost")
+	ctx := exchange.WithFence(context.Background(), 3, func(context.Context, int64) error { return stale })
+
+	_, err := p.PlaceOrder(ctx, exchange.Order{Asset: 0, IsBuy: true, LimitPx: "50000", Sz: "0.01"})
+	assert.ErrorIs(t, err, stale, "PlaceOrder should refuse a stale fence")
+	_, err = p.IOCMarket(ctx, "BTC", true, 0.01, 0.01, false)
+	assert.ErrorIs(t, err, stale, "IOCMarket should refuse a stale fence")
+	_, err = p.ClosePosition(ctx, "BTC")
+	assert.ErrorIs(t, err, stale, "ClosePosition should refuse a stale fence")
+
+	pos, err := p.GetPositions(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, pos, "no order should have been applied")
+}
+
 func TestSimProvider_IOCMarket(t *testing.T) {
 	p := New()
 	ctx := context.Background()
@@ -111,3 +130,79 @@ func parseDecimal(t *testing.T, s string) float64 {
 	assert.NoError(t, err, "string should parse as float")
 	return f
 }
+
+func TestSimProvider_LiveMarks(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	prices := map[string]float64{"BTC": 50000}
+	lookups := 0
+	p := New(WithInitialEquity(1000), WithMarkPriceSource(func(_ context.Context, coin string) (float64, error) {
+		lookups++
+		return prices[coin], nil
+	}))
+	p.now = func() time.Time { return now }
+	var _ exchange.MarkPriceFeeder = p
+
+	value, err := p.GetAccountValue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000.0, value, "initial equity is configurable")
+
+	_, err = p.IOCMarket(ctx, "BTC", true, 0.01, 0.001, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, lookups, "market orders price off the live source")
+
+	prices["BTC"] = 51000
+	now = now.Add(time.Second)
+	value, _ = p.GetAccountValue(ctx)
+	assert.Equal(t, 1, lookups, "marks are reused within the refresh interval")
+
+	now = now.Add(defaultMarkRefresh)
+	value, err = p.GetAccountValue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, lookups)
+	assert.InDelta(t, 1000+0.01*(51000-50000*1.001), value, 1e-6, "open position is marked to the live price")
+
+	prices["BTC"] = 0
+	now = now.Add(defaultMarkRefresh)
+	value2, _ := p.GetAccountValue(ctx)
+	assert.Equal(t, value, value2, "a failed lookup keeps the previous mark")
+}
+
+func TestSimProvider_LiquidationPriceByMarginMode(t *testing.T) {
+	p := New(WithInitialEquity(50), WithMaintenanceMarginRate(0.01))
+	ctx := context.Background()
+
+	btc, _ := p.GetAssetIndex(ctx, "BTC")
+	eth, _ := p.GetAssetIndex(ctx, "ETH")
+	assert.NoError(t, p.UpdateLeverage(ctx, btc, true, 10))
+	assert.NoError(t, p.UpdateLeverage(ctx, eth, false, 10))
+	_, err := p.PlaceOrder(ctx, exchange.Order{Asset: btc, IsBuy: true, LimitPx: "100", Sz: "1"})
+	assert.NoError(t, err)
+	_, err = p.PlaceOrder(ctx, exchange.Order{Asset: eth, IsBuy: true, LimitPx: "100", Sz: "1"})
+	assert.NoError(t, err)
+
+	state, err := p.GetAccountState(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "50", state.MarginSummary.AccountValue)
+	assert.Equal(t, "40", state.CrossMarginSummary.AccountValue, "isolated margin leaves the cross pool")
+	assert.Equal(t, "10", state.CrossMarginSummary.TotalMarginUsed)
+
+	liq := func(coin string) float64 {
+		for _, pos := range state.AssetPositions {
+			if pos.Coin == coin {
+				if !assert.NotNil(t, pos.LiquidationPx, coin) {
+					return 0
+				}
+				v, _ := strconv.ParseFloat(*pos.LiquidationPx, 64)
+				return v
+			}
+		}
+		t.Fatalf("no %s position", coin)
+		return 0
+	}
+	// Cross: the pool (40) less BTC's maintenance (1) backs the position.
+	assert.InDelta(t, 100-39/0.99, liq("BTC"), 1e-6)
+	// Isolated: only ETH's own margin (10) less maintenance (1).
+	assert.InDelta(t, 100-9/0.99, liq("ETH"), 1e-6)
+	assert.Equal(t, "isolated", state.AssetPositions[1].Leverage.Type)
+}
@@ -206,3 +206,24 @@ type FilledOrder struct {
 	AvgPx   string `json:"avgPx"`
 	Oid     int64  `json:"oid"`
 }
+
+// OrderEstimate is a pre-trade estimate of how a market order would fill.
+type OrderEstimate struct {
+	Coin  string
+	IsBuy bool
+	// Qty is the requested size; FilledQty is how much the visible book
+	// absorbs and is smaller when depth runs out.
+	Qty        float64
+	FilledQty  float64
+	AvgPrice   float64
+	MidPrice   float64
+	WorstPrice float64
+	// SlippageBps is the adverse distance from MidPrice to AvgPrice.
+	SlippageBps float64
+	Levels      int
+}
+
+// FullyFilled reports whether the visible book covers the requested size.
+func (e *OrderEstimate) FullyFilled() bool {
+	return e != nil && e.Qty > 0 && e.FilledQty >= e.Qty*(1-1e-9)
+}
@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/llm"
+	"nof0-api/pkg/market"
+)
+
+// chartConfig returns the model's chart settings when attachments are enabled.
+func (e *BasicExecutor) chartConfig() *llm.ChartConfig {
+	modelCfg, ok := e.modelConfig()
+	if !ok || !modelCfg.Charts.Active() {
+		return nil
+	}
+	return modelCfg.Charts
+}
+
+// chartImages renders one candlestick PNG per candidate coin, in candidate
+// order. Coins without series data are skipped; the returned symbols label
+// the images for the caption.
+func (e *BasicExecutor) chartImages(input *Context) ([]llm.Image, []string) {
+	charts := e.chartConfig()
+	if charts == nil || input == nil {
+		return nil, nil
+	}
+	width, height := charts.Size()
+	var (
+		images  []llm.Image
+		symbols []string
+	)
+	for _, coin := range input.CandidateCoins {
+		if charts.MaxCoins > 0 && len(images) >= charts.MaxCoins {
+			break
+		}
+		snap := input.MarketDataMap[coin.Symbol]
+		if snap == nil {
+			continue
+		}
+		series := snap.Intraday
+		if charts.SeriesName() == "long_term" {
+			series = snap.LongTerm
+		}
+		candles := series.ChartCandles()
+		if len(candles) == 0 {
+			continue
+		}
+		data, err := market.RenderCandlestickPNG(candles, width, height)
+		if err != nil {
+			logx.Slowf("executor: chart render failed symbol=%s err=%v", coin.Symbol, err)
+			continue
+		}
+		images = append(images, llm.Image{
+			URL:    "data:image/png;base64," + base64.StdEncoding.EncodeToString(data),
+			Detail: charts.DetailLevel(),
+			Width:  width,
+			Height: height,
+		})
+		symbols = append(symbols, coin.Symbol)
+	}
+	return images, symbols
+}
+
+// attachCharts adds images to the last user message, or appends a user
+// message carrying them when the prompt is a single system message.
+func attachCharts(messages []llm.Message, images []llm.Image, symbols []string, series string) []llm.Message {
+	if len(images) == 0 {
+		return messages
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		if strings.EqualFold(messages[i].Role, "user") {
+			messages[i].Images = append(messages[i].Images, images...)
+			return messages
+		}
+	}
+	caption := fmt.Sprintf("Candlestick charts (%s, oldest to newest, volume along the bottom), one per coin in order: %s", series, strings.Join(symbols, ", "))
+	return append(messages, llm.Message{Role: "user", Content: caption, Images: images})
+}
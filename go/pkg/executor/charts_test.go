@@ -0,0 +1,31 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/llm"
+)
+
+func TestAttachCharts(t *testing.T) {
+	images := []llm.Image{{URL: "data:image/png;base64,AA"}, {URL: "data:image/png;base64,BB"}}
+
+	single := attachCharts([]llm.Message{{Role: "system", Content: "prompt"}}, images, []string{"BTC", "ETH"}, "intraday")
+	require.Len(t, single, 2)
+	assert.Equal(t, "user", single[1].Role)
+	assert.Contains(t, single[1].Content, "BTC, ETH")
+	assert.Len(t, single[1].Images, 2)
+
+	split := attachCharts([]llm.Message{
+		{Role: "system", Content: "rules"},
+		{Role: "user", Content: "market"},
+		{Role: "assistant", Content: "ack"},
+	}, images, []string{"BTC", "ETH"}, "intraday")
+	require.Len(t, split, 3)
+	assert.Len(t, split[1].Images, 2)
+
+	none := attachCharts([]llm.Message{{Role: "system"}}, nil, nil, "intraday")
+	assert.Len(t, none, 1)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -31,6 +32,21 @@ type Config struct {
 	PromptValidation       PromptValidation    `yaml:"prompt_validation"`
 	OutputValidation       OutputValidation    `yaml:"output_validation"`
 	TraderID               string              `yaml:"-"` // runtime-only metadata for persistence hooks
+	// TemplateFS, when set, is where prompt template paths are read from
+	// instead of disk (e.g. an embed.FS baked into the binary).
+	TemplateFS fs.FS `yaml:"-"`
+	// TemplateDigest pins the prompt template file to this sha256 digest;
+	// reduced tier layouts next to it are not pinned.
+	TemplateDigest string `yaml:"-"`
+	// AllowedFuncs, when set, are the only functions (text/template builtins
+	// included) the prompt template and its tier layouts may call; a template
+	// calling anything else fails to load. See llm.SafeBuiltinFuncs.
+	AllowedFuncs []string `yaml:"allowed_funcs"`
+	// VolatilityLeverage lowers leverage on symbols whose ATR is high.
+	VolatilityLeverage VolatilityLeverage `yaml:"volatility_leverage"`
+	// AllowHedge lets a hedge-mode account open the opposite side of a
+	// symbol it already holds. Net-mode accounts can never hedge.
+	AllowHedge bool `yaml:"allow_hedge"`
 
 	DecisionIntervalRaw string `yaml:"decision_interval"`
 	DecisionTimeoutRaw  string `yaml:"decision_timeout"`
@@ -160,6 +176,9 @@ func (c *Config) expandFields() {
 	for i, id := range c.AllowedTraderIDs {
 		c.AllowedTraderIDs[i] = strings.TrimSpace(id)
 	}
+	for i, name := range c.AllowedFuncs {
+		c.AllowedFuncs[i] = strings.TrimSpace(name)
+	}
 }
 
 // Validate ensures configuration sanity.
@@ -191,6 +210,9 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("executor config: output_validation.schema_path %q not accessible: %w", path, err)
 		}
 	}
+	if err := c.VolatilityLeverage.Validate(); err != nil {
+		return fmt.Errorf("executor config: volatility_leverage.%w", err)
+	}
 	if len(c.AllowedTraderIDs) > 0 {
 		seen := make(map[string]struct{}, len(c.AllowedTraderIDs))
 		for _, id := range c.AllowedTraderIDs {
@@ -11,8 +11,10 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"nof0-api/pkg/decision"
 	"nof0-api/pkg/llm"
 	"nof0-api/pkg/market"
+	"nof0-api/pkg/prompt"
 )
 
 // Executor defines the decision engine interface.
@@ -35,6 +37,11 @@ type BasicExecutor struct {
 	failures      map[string]int
 	conversations ConversationRecorder
 	schemaChecker *JSONSchemaValidator
+	watcher       *llm.TemplateWatcher
+	// prompts, when set, routes decisions to registered prompt versions.
+	prompts          *prompt.Registry
+	traderID         string
+	versionRenderers map[string]*PromptRenderer
 }
 
 // NewExecutor constructs a BasicExecutor. The templatePath is the executor prompt template provided by caller.
@@ -45,7 +52,7 @@ func NewExecutor(cfg *Config, client llm.LLMClient, templatePath string, modelAl
 	if client == nil {
 		return nil, errors.New("executor: llm client is required")
 	}
-	renderer, err := NewPromptRenderer(cfg, templatePath)
+	renderer, err := NewPromptRenderer(cfg, templatePath, llm.WithTokenCounter(promptTokenCounter(client, modelAlias)))
 	if err != nil {
 		return nil, err
 	}
@@ -75,9 +82,56 @@ func NewExecutor(cfg *Config, client llm.LLMClient, templatePath string, modelAl
 	if exec.conversations == nil {
 		exec.conversations = noopConversationRecorder{}
 	}
+	if err := exec.initPromptVersions(client); err != nil {
+		return nil, err
+	}
+	if exec.watcher != nil {
+		for _, tpl := range exec.watchedTemplates() {
+			if err := exec.watcher.Add(tpl); err != nil {
+				exec.Close()
+				return nil, err
+			}
+		}
+	}
 	return exec, nil
 }
 
+// watchedTemplates lists the templates, tiers and prompt versions included,
+// the executor registers with its watcher.
+func (e *BasicExecutor) watchedTemplates() []*llm.PromptTemplate {
+	renderers := []*PromptRenderer{e.renderer}
+	for _, r := range e.versionRenderers {
+		renderers = append(renderers, r)
+	}
+	var out []*llm.PromptTemplate
+	for _, r := range renderers {
+		out = append(out, r.templates()...)
+	}
+	return out
+}
+
+// Close unregisters the executor's templates from its watcher. Call it when
+// the executor is replaced so the watcher stops reloading templates nothing
+// renders. The executor keeps working, without hot reload.
+func (e *BasicExecutor) Close() error {
+	if e == nil || e.watcher == nil {
+		return nil
+	}
+	for _, tpl := range e.watchedTemplates() {
+		e.watcher.Remove(tpl)
+	}
+	return nil
+}
+
+// WithTemplateWatcher registers the executor's prompt templates, tiers
+// included, with w so edits on disk take effect on the next decision. Close
+// unregisters them.
+func WithTemplateWatcher(w *llm.TemplateWatcher) ExecutorOption {
+	return func(exec *BasicExecutor) {
+		exec.watcher = w
+	}
+}
+
 // GetConfig returns the underlying configuration.
 func (e *BasicExecutor) GetConfig() *Config { return e.cfg }
 
@@ -97,76 +151,201 @@ func (e *BasicExecutor) GetFullDecision(input *Context) (*FullDecision, error) {
 
 	// Render prompt from template with dynamic sections.
 	inputs := buildPromptInputs(e.cfg, &Context{
-		CurrentTime:       input.CurrentTime,
-		RuntimeMinutes:    input.RuntimeMinutes,
-		CallCount:         input.CallCount,
-		Account:           input.Account,
-		Positions:         input.Positions,
-		CandidateCoins:    input.CandidateCoins,
-		MarketDataMap:     input.MarketDataMap,
-		OpenInterestMap:   input.OpenInterestMap,
-		Performance:       e.performance,
-		MajorCoinLeverage: e.cfg.MajorCoinLeverage,
-		AltcoinLeverage:   e.cfg.AltcoinLeverage,
+		CurrentTime:        input.CurrentTime,
+		RuntimeMinutes:     input.RuntimeMinutes,
+		CallCount:          input.CallCount,
+		Account:            input.Account,
+		Positions:          input.Positions,
+		CandidateCoins:     input.CandidateCoins,
+		MarketDataMap:      input.MarketDataMap,
+		OpenInterestMap:    input.OpenInterestMap,
+		Performance:        e.performance,
+		MajorCoinLeverage:  e.cfg.MajorCoinLeverage,
+		AltcoinLeverage:    e.cfg.AltcoinLeverage,
+		OpenAllowance:      input.OpenAllowance,
+		LossStreak:         input.LossStreak,
+		LossStreakLimit:    input.LossStreakLimit,
+		EntriesPausedUntil: input.EntriesPausedUntil,
+		Blacklisted:        input.Blacklisted,
+		ObservedSlippage:   input.ObservedSlippage,
+		MarketRegime:       input.MarketRegime,
+		DecisionInterval:   input.DecisionInterval,
+		TriggerReasons:     input.TriggerReasons,
+		AdvisorNotes:       input.AdvisorNotes,
+		DataQuality:        input.DataQuality,
+		MaxRiskPct:         input.MaxRiskPct,
+		MaxPositionSizeUSD: input.MaxPositionSizeUSD,
+		DrawdownPct:        input.DrawdownPct,
+		RiskScale:          input.RiskScale,
+		SizingMode:         input.SizingMode,
+		VolTargetSizes:     input.VolTargetSizes,
 	})
+	if format := e.seriesFormat(); format != llm.SeriesFormatInline {
+		inputs = inputs.withSeriesFormat(format)
+	}
 
-	promptStr, err := e.renderer.Render(inputs)
+	budget := e.promptTokenBudget()
+	charts, chartSymbols := e.chartImages(input)
+	if _, imageTokens := llm.EstimateMessageImageTokens([]llm.Message{{Images: charts}}); budget > 0 && imageTokens > 0 {
+		if imageTokens >= budget {
+			logx.Slowf("executor: dropping %d charts image_tokens~%d exceed budget=%d model=%s", len(charts), imageTokens, budget, e.modelAlias)
+			charts, chartSymbols = nil, nil
+		} else {
+			budget -= imageTokens
+		}
+	}
+	renderer, assignment := e.rendererFor(input)
+	rendered, tier, err := renderer.renderWithinBudget(inputs, budget)
 	if err != nil {
 		return nil, err
 	}
+	promptStr := rendered.Prompt
+	if trimmed, ok := rendered.Data.(promptPayload); ok {
+		inputs = trimmed.PromptInputs
+	}
 	promptDigest := llm.DigestString(promptStr)
+	if tier != PromptTierFull {
+		logx.Slowf("executor: prompt fell back to tier=%s budget=%d model=%s", tier, budget, e.modelAlias)
+	}
+	if rendered.SeriesLen > 0 {
+		logx.Slowf("executor: prompt series trimmed to %d points tier=%s budget=%d model=%s", rendered.SeriesLen, tier, budget, e.modelAlias)
+	}
 	if e.modelAlias != "" {
-		logx.Infof("executor: prompt rendered digest=%s candidates=%d positions=%d runtime_minutes=%d model=%s", promptDigest, len(input.CandidateCoins), len(input.Positions), input.RuntimeMinutes, e.modelAlias)
+		logx.Infof("executor: prompt rendered digest=%s tier=%s tokens~%d candidates=%d positions=%d runtime_minutes=%d model=%s", promptDigest, tier, rendered.Tokens, len(input.CandidateCoins), len(input.Positions), input.RuntimeMinutes, e.modelAlias)
 	} else {
-		logx.Infof("executor: prompt rendered digest=%s candidates=%d positions=%d runtime_minutes=%d", promptDigest, len(input.CandidateCoins), len(input.Positions), input.RuntimeMinutes)
+		logx.Infof("executor: prompt rendered digest=%s tier=%s tokens~%d candidates=%d positions=%d runtime_minutes=%d", promptDigest, tier, rendered.Tokens, len(input.CandidateCoins), len(input.Positions), input.RuntimeMinutes)
 	}
 
-	// Phase 2: Call LLM with structured output request.
-	req := &llm.ChatRequest{
-		Messages: []llm.Message{
-			{Role: "system", Content: promptStr},
-		},
+	messages := []llm.Message{{Role: "system", Content: promptStr}}
+	if e.splitPrompt() {
+		split, err := renderer.RenderMessages(inputs, tier)
+		if err != nil {
+			return nil, err
+		}
+		if len(split) > 0 {
+			messages = split
+			logx.Infof("executor: prompt split digest=%s messages=%d", promptDigest, len(split))
+		}
+	}
+	if len(charts) > 0 {
+		messages = attachCharts(messages, charts, chartSymbols, e.chartConfig().SeriesName())
+		logx.Infof("executor: attached charts digest=%s symbols=%s", promptDigest, strings.Join(chartSymbols, ","))
 	}
+
+	// Phase 2: Call LLM with structured output request.
+	req := &llm.ChatRequest{Messages: messages}
 	if e.modelAlias != "" {
 		req.Model = e.modelAlias
 	}
 
-	// Use package-level contract type for structured response.
-	var out decisionContract
+	// The decision contract doubles as the structured output schema.
+	var out decision.Contract
 	callCtx, cancel := context.WithTimeout(context.Background(), e.cfg.DecisionTimeout)
 	defer cancel()
 	callStart := time.Now()
 	resp, err := e.llm.ChatStructured(callCtx, req, &out)
+	latency := time.Since(callStart)
+	result := func(decisions []Decision) *FullDecision {
+		full := &FullDecision{UserPrompt: promptStr, CoTTrace: "", Decisions: decisions, Timestamp: time.Now(), LLMLatency: latency,
+			PromptVersion: assignment.Version.Name, PromptExperiment: assignment.Experiment, PromptRollout: assignment.Rollout}
+		if resp != nil {
+			full.PromptTokens = resp.Usage.PromptTokens
+			full.CompletionTokens = resp.Usage.CompletionTokens
+			if len(resp.Choices) > 0 {
+				full.RawResponse = resp.Choices[0].Message.Content
+			}
+		}
+		return full
+	}
 	if err != nil {
-		logx.WithContext(callCtx).Errorf("executor: chat failed digest=%s duration=%s error=%v", promptDigest, time.Since(callStart), err)
-		return &FullDecision{UserPrompt: promptStr, CoTTrace: "", Decisions: nil, Timestamp: time.Now()}, err
+		logx.WithContext(callCtx).Errorf("executor: chat failed digest=%s duration=%s error=%v", promptDigest, latency, err)
+		return result(nil), err
 	}
-	logx.WithContext(callCtx).Infof("executor: chat completed digest=%s duration=%s", promptDigest, time.Since(callStart))
+	logx.WithContext(callCtx).Infof("executor: chat completed digest=%s duration=%s", promptDigest, latency)
 	e.recordConversation(callCtx, promptStr, resp)
 
 	// Phase 3: Schema validation (optional) and logical validation.
 	if err := e.validateSchema(resp, out); err != nil {
 		if e.cfg.OutputValidation.FailOnInvalid {
-			return &FullDecision{UserPrompt: promptStr, CoTTrace: "", Decisions: nil, Timestamp: time.Now()}, err
+			return result(nil), err
 		}
 		logx.Slowf("executor: schema validation warning digest=%s err=%v", promptDigest, err)
 	}
 
-	// Map & validate execution constraints.
-	mapped := mapDecisionContract(out, input.Positions)
+	// Parse strictly so malformed output fails instead of degrading to hold,
+	// then validate execution constraints.
+	parsed, err := parseDecision(resp, out)
+	if err != nil {
+		logx.WithContext(callCtx).Errorf("executor: decision rejected digest=%s err=%v", promptDigest, err)
+		return result(nil), err
+	}
+	mapped := toDecision(parsed, input.Positions)
+	if requested, lowered := applyVolatilityLeverage(e.cfg, input, &mapped); lowered {
+		logx.Infof("executor: volatility haircut symbol=%s leverage=%dx->%dx digest=%s", mapped.Symbol, requested, mapped.Leverage, promptDigest)
+	}
 	if err := ValidateDecisions(e.cfg, input, []Decision{mapped}); err != nil {
 		e.trackFailure(mapped.Symbol, err)
-		return &FullDecision{UserPrompt: promptStr, CoTTrace: "", Decisions: []Decision{mapped}, Timestamp: time.Now()}, err
+		return result([]Decision{mapped}), err
 	}
 	e.resetFailure(mapped.Symbol)
 	logx.Infof("executor: decision validated digest=%s symbol=%s action=%s notional=%.2f confidence=%d", promptDigest, mapped.Symbol, mapped.Action, mapped.PositionSizeUSD, mapped.Confidence)
 
-	return &FullDecision{
-		UserPrompt: promptStr,
-		CoTTrace:   "",
-		Decisions:  []Decision{mapped},
-		Timestamp:  time.Now(),
-	}, nil
+	return result([]Decision{mapped}), nil
+}
+
+// promptTokenBudget is the prompt size allowed by the model's configured
+// context window, or 0 when the window is unknown.
+func (e *BasicExecutor) promptTokenBudget() int {
+	model := e.modelAlias
+	cfg := e.llm.GetConfig()
+	if cfg == nil {
+		return 0
+	}
+	if model == "" {
+		model = cfg.DefaultModel
+	}
+	return cfg.PromptTokenBudget(model)
+}
+
+// promptTokenCounter sizes prompts with the tokenizer of the model the
+// executor calls.
+func promptTokenCounter(client llm.LLMClient, modelAlias string) llm.TokenCounter {
+	cfg := client.GetConfig()
+	if cfg == nil {
+		return llm.ApproxCounter(0)
+	}
+	model := strings.TrimSpace(modelAlias)
+	if model == "" {
+		model = cfg.DefaultModel
+	}
+	modelCfg, _ := cfg.Model(model)
+	return llm.NewTokenCounter(llm.ResolveModelID(model, modelCfg))
+}
+
+// splitPrompt reports whether the model prefers the prompt as a message sequence.
+func (e *BasicExecutor) splitPrompt() bool {
+	modelCfg, ok := e.modelConfig()
+	return ok && modelCfg.SplitPrompt
+}
+
+// seriesFormat is the model's preferred time series layout.
+func (e *BasicExecutor) seriesFormat() string {
+	modelCfg, _ := e.modelConfig()
+	return modelCfg.SeriesLayout()
+}
+
+// modelConfig returns the LLM settings for the executor's model alias,
+// falling back to the client's default model.
+func (e *BasicExecutor) modelConfig() (llm.ModelConfig, bool) {
+	cfg := e.llm.GetConfig()
+	if cfg == nil {
+		return llm.ModelConfig{}, false
+	}
+	model := e.modelAlias
+	if model == "" {
+		model = cfg.DefaultModel
+	}
+	return cfg.Model(model)
 }
 
 func condPerf(p *PerformanceView) *PerformanceView {
@@ -176,7 +355,19 @@ func condPerf(p *PerformanceView) *PerformanceView {
 	return &PerformanceView{}
 }
 
-func (e *BasicExecutor) validateSchema(resp *llm.ChatResponse, contract decisionContract) error {
+// parseDecision parses the raw response text, falling back to the decoded
+// contract when the client returned no text (e.g. replay clients).
+func parseDecision(resp *llm.ChatResponse, contract decision.Contract) (decision.Decision, error) {
+	if resp != nil && len(resp.Choices) > 0 {
+		if content := sanitizeResponse(resp.Choices[0].Message.Content); content != "" {
+			return decision.Parse(content)
+		}
+	}
+	d := decision.FromContract(contract)
+	return d, d.Check()
+}
+
+func (e *BasicExecutor) validateSchema(resp *llm.ChatResponse, contract decision.Contract) error {
 	if e == nil || e.schemaChecker == nil {
 		return nil
 	}
@@ -257,6 +448,7 @@ func (e *BasicExecutor) recordConversation(ctx context.Context, prompt string, r
 		TotalTokens:      resp.Usage.TotalTokens,
 		ModelName:        resp.Model,
 		Timestamp:        ts,
+		Sampling:         resp.Sampling,
 	}
 	if err := e.conversations.RecordConversation(ctx, rec); err != nil {
 		logx.WithContext(ctx).Errorf("executor: record conversation failed trader=%s err=%v", e.cfg.TraderID, err)
@@ -178,3 +178,25 @@ func TestExecutorSchemaValidationWarn(t *testing.T) {
 	require.NotNil(t, out)
 	require.Len(t, out.Decisions, 1)
 }
+
+func TestExecutorRejectsUnknownSignal(t *testing.T) {
+	cfg := &Config{
+		MajorCoinLeverage:      20,
+		AltcoinLeverage:        10,
+		MinConfidence:          70,
+		MinRiskReward:          2.0,
+		MaxPositions:           2,
+		DecisionIntervalRaw:    "3m",
+		DecisionTimeoutRaw:     "60s",
+		MaxConcurrentDecisions: 1,
+	}
+	client := newFakeLLM(`{"signal":"moon","symbol":"BTC","confidence":90}`)
+	templatePath := filepath.Join("..", "..", "etc", "prompts", "executor", "default_prompt.tmpl")
+	exec, err := NewExecutor(cfg, client, templatePath, "")
+	require.NoError(t, err)
+
+	out, err := exec.GetFullDecision(&Context{CurrentTime: "2025-01-01T00:00:00Z"})
+	require.ErrorContains(t, err, `unknown signal "moon"`)
+	require.NotNil(t, out)
+	assert.Empty(t, out.Decisions, "malformed output must not turn into a hold")
+}
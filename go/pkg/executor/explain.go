@@ -0,0 +1,343 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RuleStatus is the outcome of one risk rule for a decision.
+type RuleStatus string
+
+const (
+	RulePass RuleStatus = "pass"
+	RuleFail RuleStatus = "fail"
+	// RuleSkip marks a rule that did not apply: the guard is disabled, the
+	// context lacks the data it needs, or an earlier rule made it moot.
+	RuleSkip RuleStatus = "skip"
+)
+
+// RuleResult records one risk rule evaluated against a decision.
+type RuleResult struct {
+	Rule      string         `json:"rule"`
+	Status    RuleStatus     `json:"status"`
+	Inputs    map[string]any `json:"inputs,omitempty"`
+	Threshold any            `json:"threshold,omitempty"`
+	// Detail is the rejection message for failed rules, the reason for
+	// skipped ones and the adjustment made by passing rules that override
+	// the decision.
+	Detail string `json:"detail,omitempty"`
+}
+
+// Explanation lists every risk rule evaluated for a decision, in the order
+// ValidateDecisions applies them.
+type Explanation struct {
+	Symbol  string       `json:"symbol"`
+	Action  string       `json:"action"`
+	Allowed bool         `json:"allowed"`
+	Rules   []RuleResult `json:"rules"`
+}
+
+// Failure returns the first failed rule, which is the one ValidateDecisions
+// reports, or nil when the decision is allowed.
+func (e Explanation) Failure() *RuleResult {
+	for i := range e.Rules {
+		if e.Rules[i].Status == RuleFail {
+			return &e.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Explain evaluates every risk rule for d against cfg and the portfolio in
+// ctx without stopping at the first failure, so rejected decisions can be
+// understood rule by rule. ctx may be nil for a config-only dry run; rules
+// needing account or market state are then skipped.
+func Explain(cfg *Config, ctx *Context, d Decision) Explanation {
+	action := strings.TrimSpace(d.Action)
+	symbol := strings.TrimSpace(d.Symbol)
+	e := &explainer{}
+	switch {
+	case cfg == nil:
+		e.check("config", false, nil, nil, "executor: missing config for validation")
+	case action == "open_long" || action == "open_short":
+		explainOpen(e, cfg, ctx, d, action, symbol, time.Now())
+	case action == "close_long" || action == "close_short":
+		explainClose(e, ctx, d, action, symbol)
+	case action == "hold" || action == "wait":
+		// no rules apply
+	default:
+		e.check("known_action", false, map[string]any{"action": d.Action}, nil, "unknown action %q", d.Action)
+	}
+	out := Explanation{Symbol: symbol, Action: action, Rules: e.rules}
+	out.Allowed = out.Failure() == nil
+	return out
+}
+
+type explainer struct {
+	rules []RuleResult
+}
+
+func (e *explainer) check(rule string, ok bool, inputs map[string]any, threshold any, format string, args ...any) bool {
+	r := RuleResult{Rule: rule, Status: RulePass, Inputs: inputs, Threshold: threshold}
+	if !ok {
+		r.Status = RuleFail
+		r.Detail = fmt.Sprintf(format, args...)
+	}
+	e.rules = append(e.rules, r)
+	return ok
+}
+
+func (e *explainer) skip(rule, reason string) {
+	e.rules = append(e.rules, RuleResult{Rule: rule, Status: RuleSkip, Detail: reason})
+}
+
+const noPortfolio = "no portfolio context"
+
+func explainOpen(e *explainer, cfg *Config, ctx *Context, d Decision, action, symbol string, now time.Time) {
+	e.check("symbol_required", symbol != "", nil, nil, "symbol is required")
+	e.check("leverage_positive", d.Leverage > 0, map[string]any{"leverage": d.Leverage}, nil, "leverage must be positive")
+	e.check("position_size_positive", d.PositionSizeUSD > 0, map[string]any{"position_size_usd": d.PositionSizeUSD}, nil, "position_size_usd must be positive")
+	prices := map[string]any{"entry_price": d.EntryPrice, "stop_loss": d.StopLoss, "take_profit": d.TakeProfit}
+	e.check("prices_positive", d.StopLoss > 0 && d.TakeProfit > 0 && d.EntryPrice > 0, prices, nil, "entry/stop_loss/take_profit must be positive")
+	e.check("confidence_range", d.Confidence >= 0 && d.Confidence <= 100, map[string]any{"confidence": d.Confidence}, "0-100", "confidence must be 0-100")
+	e.check("min_confidence", d.Confidence >= cfg.MinConfidence, map[string]any{"confidence": d.Confidence}, cfg.MinConfidence, "confidence below threshold")
+
+	// Price relationship & RR check
+	var ordered bool
+	var rr float64
+	if action == "open_long" {
+		ordered = e.check("price_order", d.TakeProfit > d.EntryPrice && d.EntryPrice > d.StopLoss, prices, "TP>entry>SL", "long requires TP>entry>SL")
+		if ordered {
+			rr = (d.TakeProfit - d.EntryPrice) / (d.EntryPrice - d.StopLoss)
+		}
+	} else {
+		ordered = e.check("price_order", d.StopLoss > d.EntryPrice && d.EntryPrice > d.TakeProfit, prices, "SL>entry>TP", "short requires SL>entry>TP")
+		if ordered {
+			rr = (d.EntryPrice - d.TakeProfit) / (d.StopLoss - d.EntryPrice)
+		}
+	}
+	if ordered {
+		e.check("min_risk_reward", rr >= cfg.MinRiskReward, map[string]any{"reward_risk": rr}, cfg.MinRiskReward, "reward/risk %.2f below min %.2f", rr, cfg.MinRiskReward)
+	} else {
+		e.skip("min_risk_reward", "price_order failed")
+	}
+
+	// Volatility haircut: lowers rather than rejects excess leverage, so the
+	// remaining rules see the leverage that would be executed.
+	switch {
+	case !cfg.VolatilityLeverage.Enabled():
+		e.skip("volatility_leverage", "guard disabled")
+	case ctx == nil:
+		e.skip("volatility_leverage", noPortfolio)
+	case cfg.VolatilityLeverage.ATRPct(ctx.MarketDataMap[d.Symbol]) <= 0:
+		e.skip("volatility_leverage", "no ATR reading for "+d.Symbol)
+	default:
+		snap := ctx.MarketDataMap[d.Symbol]
+		atrPct := cfg.VolatilityLeverage.ATRPct(snap)
+		var threshold any
+		if capLev, ok := cfg.VolatilityLeverage.Cap(snap); ok {
+			threshold = capLev
+		}
+		e.check("volatility_leverage", true, map[string]any{"leverage": d.Leverage, "atr_pct": atrPct}, threshold, "")
+		if requested, lowered := applyVolatilityLeverage(cfg, ctx, &d); lowered {
+			e.rules[len(e.rules)-1].Detail = fmt.Sprintf("leverage lowered from %dx to %dx (ATR %.2f%% of price)", requested, d.Leverage, atrPct)
+		}
+	}
+
+	// Leverage caps (config) and asset-level cap if available; take the minimum
+	capLev := cfg.AltcoinLeverage
+	if isBTCETH(d.Symbol) {
+		capLev = cfg.MajorCoinLeverage
+	}
+	if ctx != nil && ctx.AssetMeta != nil {
+		if meta, ok := ctx.AssetMeta[d.Symbol]; ok && meta.MaxLeverage > 0 {
+			if ml := int(meta.MaxLeverage); ml < capLev {
+				capLev = ml
+			}
+		}
+	}
+	e.check("leverage_cap", d.Leverage <= capLev, map[string]any{"leverage": d.Leverage}, capLev, "leverage %dx exceeds cap %dx", d.Leverage, capLev)
+
+	if ctx == nil {
+		for _, rule := range []string{"liquidity", "position_value_min", "position_value_max", "margin_usage", "loss_streak_pause", "blacklist", "indicator_warmup", "open_allowance", "close_cooldown", "max_positions", "no_add_or_hedge", "max_risk", "max_position_size"} {
+			e.skip(rule, noPortfolio)
+		}
+		return
+	}
+	explainGuards(e, ctx, d, now)
+	explainPortfolio(e, cfg, ctx, d)
+}
+
+// explainGuards covers the extended guards, enabled only when the context
+// provides non-zero values.
+func explainGuards(e *explainer, ctx *Context, d Decision, now time.Time) {
+	// Liquidity threshold: OI * Price ≥ threshold (new opens only)
+	snap := ctx.MarketDataMap[d.Symbol]
+	switch {
+	case ctx.LiquidityThresholdUSD <= 0:
+		e.skip("liquidity", "guard disabled")
+	case snap == nil || snap.OpenInterest == nil || snap.Price.Last <= 0:
+		e.skip("liquidity", "no open interest or price for "+d.Symbol)
+	default:
+		oiValueUSD := snap.OpenInterest.Latest * snap.Price.Last
+		e.check("liquidity", oiValueUSD+1e-9 >= ctx.LiquidityThresholdUSD, map[string]any{"oi_value_usd": oiValueUSD}, ctx.LiquidityThresholdUSD,
+			"%s illiquid: oi*price %.2f < threshold %.2f", d.Symbol, oiValueUSD, ctx.LiquidityThresholdUSD)
+	}
+
+	// Position value band by category (equity multiples)
+	equity := ctx.Account.TotalEquity
+	category, minMult, maxMult := "alt", ctx.AltPositionValueMinMultiple, ctx.AltPositionValueMaxMultiple
+	if isBTCETH(d.Symbol) {
+		category, minMult, maxMult = "BTC/ETH", ctx.BTCETHPositionValueMinMultiple, ctx.BTCETHPositionValueMaxMultiple
+	}
+	band := map[string]any{"position_size_usd": d.PositionSizeUSD, "equity": equity, "category": category}
+	switch {
+	case equity <= 0:
+		e.skip("position_value_min", "no account equity")
+	case minMult <= 0:
+		e.skip("position_value_min", "guard disabled")
+	default:
+		minV := equity * minMult
+		e.check("position_value_min", d.PositionSizeUSD+1e-9 >= minV, band, minV,
+			"position_size_usd %.2f below %s min %.2f (%.2fx equity)", d.PositionSizeUSD, category, minV, minMult)
+	}
+	switch {
+	case equity <= 0:
+		e.skip("position_value_max", "no account equity")
+	case maxMult <= 0:
+		e.skip("position_value_max", "guard disabled")
+	default:
+		maxV := equity * maxMult
+		e.check("position_value_max", d.PositionSizeUSD-1e-9 <= maxV, band, maxV,
+			"position_size_usd %.2f exceeds %s max %.2f (%.2fx equity)", d.PositionSizeUSD, category, maxV, maxMult)
+	}
+
+	// Margin usage cap after new position margin
+	switch {
+	case ctx.MaxMarginUsagePct <= 0:
+		e.skip("margin_usage", "guard disabled")
+	case equity <= 0 || d.Leverage <= 0:
+		e.skip("margin_usage", "no account equity or leverage")
+	default:
+		newMargin := d.PositionSizeUSD / float64(d.Leverage)
+		used := ctx.Account.MarginUsed + newMargin
+		usagePct := 100 * (used / equity)
+		e.check("margin_usage", usagePct <= ctx.MaxMarginUsagePct+1e-9,
+			map[string]any{"margin_used": ctx.Account.MarginUsed, "new_margin": newMargin, "equity": equity, "usage_pct": usagePct}, ctx.MaxMarginUsagePct,
+			"margin usage %.2f%% exceeds cap %.2f%% after new position", usagePct, ctx.MaxMarginUsagePct)
+	}
+
+	// Loss-streak cooldown pauses entries; exits stay allowed
+	if ctx.EntriesPausedUntil.IsZero() {
+		e.skip("loss_streak_pause", "entries not paused")
+	} else {
+		until := ctx.EntriesPausedUntil.UTC().Format(time.RFC3339)
+		e.check("loss_streak_pause", !now.Before(ctx.EntriesPausedUntil), map[string]any{"loss_streak": ctx.LossStreak, "paused_until": until}, ctx.LossStreakLimit,
+			"entries paused until %s after loss streak", until)
+	}
+
+	// Symbols blacklisted after repeated data anomalies
+	if until, ok := ctx.Blacklisted[strings.ToUpper(d.Symbol)]; ok {
+		e.check("blacklist", !now.Before(until), map[string]any{"blacklisted_until": until.UTC().Format(time.RFC3339)}, nil,
+			"%s blacklisted after data anomalies until %s", d.Symbol, until.UTC().Format(time.RFC3339))
+	} else {
+		e.check("blacklist", true, nil, nil, "")
+	}
+
+	// Indicators still warming up on a new or short-history symbol
+	warming := snap.WarmingUp()
+	e.check("indicator_warmup", len(warming) == 0, map[string]any{"warming_up": warming}, nil,
+		"%s indicators warming up (%s)", d.Symbol, strings.Join(warming, ", "))
+
+	// Opens-per-hour/day throttle
+	if ctx.OpenAllowance == nil {
+		e.skip("open_allowance", "guard disabled")
+	} else {
+		e.check("open_allowance", ctx.OpenAllowance.Remaining() != 0, map[string]any{"allowance": ctx.OpenAllowance.String()}, nil,
+			"open allowance exhausted (%s)", ctx.OpenAllowance)
+	}
+
+	// Cooldown after close
+	closedAt, closed := ctx.RecentlyClosed[d.Symbol]
+	switch {
+	case ctx.CooldownAfterClose <= 0:
+		e.skip("close_cooldown", "guard disabled")
+	case !closed || closedAt.IsZero():
+		e.check("close_cooldown", true, nil, ctx.CooldownAfterClose.String(), "")
+	default:
+		since := now.Sub(closedAt)
+		e.check("close_cooldown", since >= ctx.CooldownAfterClose, map[string]any{"since_close": since.Truncate(time.Second).String()}, ctx.CooldownAfterClose.String(),
+			"%s in cooldown window (%s remaining)", d.Symbol, (ctx.CooldownAfterClose - since).Truncate(time.Second))
+	}
+}
+
+// explainPortfolio covers position count, exposure and per-trade caps.
+func explainPortfolio(e *explainer, cfg *Config, ctx *Context, d Decision) {
+	e.check("max_positions", len(ctx.Positions) < cfg.MaxPositions, map[string]any{"positions": len(ctx.Positions)}, cfg.MaxPositions,
+		"max_positions reached (%d)", cfg.MaxPositions)
+
+	// No pyramiding. An opposite-side open nets against the position on a
+	// net-mode account and is only allowed as a hedge on a hedge-mode one.
+	wantSide := "long"
+	if d.Action == "open_short" {
+		wantSide = "short"
+	}
+	sameSide, opposite := false, false
+	for _, p := range ctx.Positions {
+		if !strings.EqualFold(p.Symbol, d.Symbol) {
+			continue
+		}
+		if strings.EqualFold(p.Side, wantSide) {
+			sameSide = true
+		} else {
+			opposite = true
+		}
+	}
+	hedge := map[string]any{"hedge_mode": ctx.HedgeMode, "allow_hedge": cfg.AllowHedge}
+	switch {
+	case sameSide || (opposite && !ctx.HedgeMode):
+		e.check("no_add_or_hedge", false, hedge, nil, "position already exists on %s; no add/hedge allowed", d.Symbol)
+	case opposite && !cfg.AllowHedge:
+		e.check("no_add_or_hedge", false, hedge, nil, "hedged exposure on %s not allowed", d.Symbol)
+	default:
+		e.check("no_add_or_hedge", true, hedge, nil, "")
+		if opposite {
+			e.rules[len(e.rules)-1].Detail = fmt.Sprintf("opens a %s leg hedging the existing %s position", wantSide, d.Symbol)
+		}
+	}
+
+	// Risk and size caps from context (if provided by Manager)
+	if ctx.Account.TotalEquity > 0 && ctx.MaxRiskPct > 0 {
+		maxRiskUSD := ctx.Account.TotalEquity * (ctx.MaxRiskPct / 100.0)
+		e.check("max_risk", d.RiskUSD <= maxRiskUSD+1e-9, map[string]any{"risk_usd": d.RiskUSD, "equity": ctx.Account.TotalEquity}, maxRiskUSD,
+			"risk_usd %.2f exceeds max %.2f (%.2f%% of equity)", d.RiskUSD, maxRiskUSD, ctx.MaxRiskPct)
+	} else {
+		e.skip("max_risk", "guard disabled")
+	}
+	if ctx.MaxPositionSizeUSD > 0 {
+		e.check("max_position_size", d.PositionSizeUSD <= ctx.MaxPositionSizeUSD+1e-9, map[string]any{"position_size_usd": d.PositionSizeUSD}, ctx.MaxPositionSizeUSD,
+			"position_size_usd %.2f exceeds cap %.2f", d.PositionSizeUSD, ctx.MaxPositionSizeUSD)
+	} else {
+		e.skip("max_position_size", "guard disabled")
+	}
+}
+
+func explainClose(e *explainer, ctx *Context, d Decision, action, symbol string) {
+	e.check("symbol_required", symbol != "", nil, nil, "symbol is required")
+	if !e.check("context_required", ctx != nil, nil, nil, "context required to validate close action") {
+		return
+	}
+	wantSide := "long"
+	if action == "close_short" {
+		wantSide = "short"
+	}
+	has := false
+	for _, p := range ctx.Positions {
+		if strings.EqualFold(p.Symbol, d.Symbol) && strings.EqualFold(p.Side, wantSide) {
+			has = true
+			break
+		}
+	}
+	e.check("matching_position", has, map[string]any{"side": wantSide}, nil, "no matching %s position to close for %s", wantSide, d.Symbol)
+}
@@ -0,0 +1,85 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ruleByName(t *testing.T, e Explanation, name string) RuleResult {
+	t.Helper()
+	for _, r := range e.Rules {
+		if r.Rule == name {
+			return r
+		}
+	}
+	t.Fatalf("rule %s not evaluated", name)
+	return RuleResult{}
+}
+
+func TestExplain_ReportsEveryRule(t *testing.T) {
+	cfg := baseCfg()
+	ctx := &Context{
+		Account:                     AccountInfo{TotalEquity: 1000, MarginUsed: 800},
+		MaxMarginUsagePct:           85,
+		AltPositionValueMinMultiple: 1.0,
+		RecentlyClosed:              map[string]time.Time{"ALT": time.Now().Add(-5 * time.Minute)},
+		CooldownAfterClose:          15 * time.Minute,
+	}
+	d := Decision{Symbol: "ALT", Action: "open_long", Leverage: 5, PositionSizeUSD: 500, EntryPrice: 10, StopLoss: 9, TakeProfit: 13, Confidence: 90}
+
+	e := Explain(cfg, ctx, d)
+	assert.False(t, e.Allowed)
+	require.NotNil(t, e.Failure())
+	assert.Equal(t, "position_value_min", e.Failure().Rule, "first failure is what ValidateDecisions reports")
+	assert.EqualError(t, ValidateDecisions(cfg, ctx, []Decision{d}), "decision[0]: "+e.Failure().Detail)
+
+	rr := ruleByName(t, e, "min_risk_reward")
+	assert.Equal(t, RulePass, rr.Status)
+	assert.InDelta(t, 3.0, rr.Inputs["reward_risk"], 1e-9)
+	assert.Equal(t, 3.0, rr.Threshold)
+
+	margin := ruleByName(t, e, "margin_usage")
+	assert.Equal(t, RuleFail, margin.Status, "later failures are still reported")
+	assert.InDelta(t, 90.0, margin.Inputs["usage_pct"], 1e-9)
+	assert.Equal(t, RuleFail, ruleByName(t, e, "close_cooldown").Status)
+	assert.Equal(t, RuleSkip, ruleByName(t, e, "liquidity").Status)
+	assert.Equal(t, "guard disabled", ruleByName(t, e, "max_position_size").Detail)
+}
+
+func TestExplain_WithoutPortfolio(t *testing.T) {
+	d := Decision{Symbol: "BTC", Action: "open_short", Leverage: 30, PositionSizeUSD: 100, EntryPrice: 100, StopLoss: 99, TakeProfit: 110, Confidence: 80}
+	e := Explain(baseCfg(), nil, d)
+	assert.Equal(t, RuleFail, ruleByName(t, e, "price_order").Status)
+	assert.Equal(t, RuleSkip, ruleByName(t, e, "min_risk_reward").Status)
+	assert.Equal(t, "leverage 30x exceeds cap 20x", ruleByName(t, e, "leverage_cap").Detail)
+	assert.Equal(t, noPortfolio, ruleByName(t, e, "max_positions").Detail)
+
+	hold := Explain(baseCfg(), nil, Decision{Action: "hold"})
+	assert.True(t, hold.Allowed)
+	assert.Empty(t, hold.Rules)
+}
+
+func TestExplain_HedgeMode(t *testing.T) {
+	cfg := baseCfg()
+	ctx := &Context{Positions: []PositionInfo{{Symbol: "BTC", Side: "long"}}}
+	short := Decision{Symbol: "BTC", Action: "open_short", Leverage: 5, PositionSizeUSD: 100, EntryPrice: 100, StopLoss: 105, TakeProfit: 80, Confidence: 80}
+
+	rule := ruleByName(t, Explain(cfg, ctx, short), "no_add_or_hedge")
+	assert.Equal(t, "position already exists on BTC; no add/hedge allowed", rule.Detail, "net accounts cannot hedge")
+
+	ctx.HedgeMode = true
+	rule = ruleByName(t, Explain(cfg, ctx, short), "no_add_or_hedge")
+	assert.Equal(t, "hedged exposure on BTC not allowed", rule.Detail)
+
+	cfg.AllowHedge = true
+	e := Explain(cfg, ctx, short)
+	assert.True(t, e.Allowed)
+	assert.Equal(t, "opens a short leg hedging the existing BTC position", ruleByName(t, e, "no_add_or_hedge").Detail)
+
+	long := short
+	long.Action, long.StopLoss, long.TakeProfit = "open_long", 95, 120
+	assert.Equal(t, RuleFail, ruleByName(t, Explain(cfg, ctx, long), "no_add_or_hedge").Status, "adding to a leg is still refused")
+}
@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"fmt"
+
+	"nof0-api/pkg/market"
+)
+
+const defaultHaircutATRWindow = "ATR14"
+
+// LeverageHaircut caps leverage on a volatile symbol: once its latest ATR
+// reaches ATRPct percent of price, leverage is at most MaxLeverage.
+type LeverageHaircut struct {
+	ATRPct      float64 `yaml:"atr_pct" json:"atr_pct"`
+	MaxLeverage int     `yaml:"max_leverage" json:"max_leverage"`
+}
+
+// VolatilityLeverage haircuts leverage by volatility. A decision asking for
+// more than its symbol's cap is lowered to the cap rather than rejected.
+type VolatilityLeverage struct {
+	// ATRWindow names the ATR series measured; empty uses ATR14.
+	ATRWindow string `yaml:"atr_window" json:"atr_window"`
+	// Tiers may overlap; the tightest cap among matching tiers applies.
+	Tiers []LeverageHaircut `yaml:"tiers" json:"tiers"`
+}
+
+// Enabled reports whether any haircut tier is configured.
+func (v VolatilityLeverage) Enabled() bool {
+	return len(v.Tiers) > 0
+}
+
+// Validate checks the haircut tiers.
+func (v VolatilityLeverage) Validate() error {
+	for i, tier := range v.Tiers {
+		if tier.ATRPct <= 0 {
+			return fmt.Errorf("tiers[%d].atr_pct must be positive", i)
+		}
+		if tier.MaxLeverage <= 0 {
+			return fmt.Errorf("tiers[%d].max_leverage must be positive", i)
+		}
+	}
+	return nil
+}
+
+// ATRPct returns snap's latest ATR as a percentage of price, or 0 when the
+// snapshot lacks an ATR reading or price.
+func (v VolatilityLeverage) ATRPct(snap *market.Snapshot) float64 {
+	if snap == nil || snap.Price.Last <= 0 {
+		return 0
+	}
+	window := v.ATRWindow
+	if window == "" {
+		window = defaultHaircutATRWindow
+	}
+	return 100 * snap.LatestATR(window) / snap.Price.Last
+}
+
+// Cap returns the leverage cap for snap, or false when no tier applies.
+func (v VolatilityLeverage) Cap(snap *market.Snapshot) (int, bool) {
+	atrPct := v.ATRPct(snap)
+	if atrPct <= 0 {
+		return 0, false
+	}
+	capLev := 0
+	for _, tier := range v.Tiers {
+		if atrPct >= tier.ATRPct && (capLev == 0 || tier.MaxLeverage < capLev) {
+			capLev = tier.MaxLeverage
+		}
+	}
+	return capLev, capLev > 0
+}
+
+// volatilityCaps returns the active leverage cap of every symbol in snaps,
+// for the prompt's market section.
+func volatilityCaps(cfg *Config, snaps map[string]*market.Snapshot) map[string]int {
+	if cfg == nil || !cfg.VolatilityLeverage.Enabled() {
+		return nil
+	}
+	caps := make(map[string]int)
+	for sym, snap := range snaps {
+		if capLev, ok := cfg.VolatilityLeverage.Cap(snap); ok {
+			caps[sym] = capLev
+		}
+	}
+	return caps
+}
+
+// applyVolatilityLeverage lowers an open's leverage to its symbol's
+// volatility cap, returning the requested leverage when it was changed.
+func applyVolatilityLeverage(cfg *Config, ctx *Context, d *Decision) (int, bool) {
+	if cfg == nil || ctx == nil || !cfg.VolatilityLeverage.Enabled() {
+		return 0, false
+	}
+	if d.Action != "open_long" && d.Action != "open_short" {
+		return 0, false
+	}
+	capLev, ok := cfg.VolatilityLeverage.Cap(ctx.MarketDataMap[d.Symbol])
+	if !ok || d.Leverage <= capLev {
+		return 0, false
+	}
+	requested := d.Leverage
+	d.Leverage = capLev
+	return requested, true
+}
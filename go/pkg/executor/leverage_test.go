@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/market"
+)
+
+func atrSnapshot(price, atr float64) *market.Snapshot {
+	return &market.Snapshot{
+		Price:    market.PriceInfo{Last: price},
+		LongTerm: &market.SeriesBundle{ATR: map[string][]float64{"ATR14": {atr * 0.9, atr}}},
+	}
+}
+
+func haircutCfg() *Config {
+	cfg := baseCfg()
+	cfg.VolatilityLeverage = VolatilityLeverage{Tiers: []LeverageHaircut{
+		{ATRPct: 6, MaxLeverage: 2},
+		{ATRPct: 3, MaxLeverage: 5},
+	}}
+	return cfg
+}
+
+func TestVolatilityLeverageCap(t *testing.T) {
+	v := haircutCfg().VolatilityLeverage
+	_, ok := v.Cap(atrSnapshot(100, 2))
+	assert.False(t, ok, "below every tier")
+	capLev, ok := v.Cap(atrSnapshot(100, 4))
+	assert.True(t, ok)
+	assert.Equal(t, 5, capLev)
+	capLev, _ = v.Cap(atrSnapshot(100, 7))
+	assert.Equal(t, 2, capLev, "tightest matching tier wins")
+	_, ok = v.Cap(&market.Snapshot{Price: market.PriceInfo{Last: 100}})
+	assert.False(t, ok, "no ATR reading")
+
+	assert.EqualError(t, VolatilityLeverage{Tiers: []LeverageHaircut{{ATRPct: 3}}}.Validate(), "tiers[0].max_leverage must be positive")
+}
+
+func TestVolatilityLeverageLowersOpenLeverage(t *testing.T) {
+	cfg := haircutCfg()
+	ctx := &Context{MarketDataMap: map[string]*market.Snapshot{"SOL": atrSnapshot(100, 4)}}
+	d := Decision{Symbol: "SOL", Action: "open_long", Leverage: 8, PositionSizeUSD: 100, EntryPrice: 100, StopLoss: 95, TakeProfit: 120, Confidence: 80}
+
+	e := Explain(cfg, ctx, d)
+	assert.True(t, e.Allowed)
+	rule := ruleByName(t, e, "volatility_leverage")
+	assert.Equal(t, RulePass, rule.Status)
+	assert.Equal(t, 5, rule.Threshold)
+	assert.Equal(t, "leverage lowered from 8x to 5x (ATR 4.00% of price)", rule.Detail)
+	assert.Equal(t, 5, ruleByName(t, e, "leverage_cap").Inputs["leverage"], "later rules see the lowered leverage")
+
+	requested, lowered := applyVolatilityLeverage(cfg, ctx, &d)
+	require.True(t, lowered)
+	assert.Equal(t, 8, requested)
+	assert.Equal(t, 5, d.Leverage)
+
+	closing := Decision{Symbol: "SOL", Action: "close_long", Leverage: 8}
+	_, lowered = applyVolatilityLeverage(cfg, ctx, &closing)
+	assert.False(t, lowered, "closes keep their leverage")
+
+	inputs := buildPromptInputs(cfg, ctx)
+	assert.Contains(t, inputs.MarketSnapshots, `"max_leverage":5`)
+	assert.Contains(t, inputs.MarketSummary, "max_leverage=5x")
+	assert.Equal(t, "guard disabled", ruleByName(t, Explain(baseCfg(), ctx, d), "volatility_leverage").Detail)
+}
@@ -3,6 +3,8 @@ package executor
 import (
 	"context"
 	"time"
+
+	"nof0-api/pkg/llm"
 )
 
 // ConversationRecorder captures prompt/response pairs for debugging/cost tracking.
@@ -21,6 +23,8 @@ type ConversationRecord struct {
 	ModelName        string
 	Timestamp        time.Time
 	Topic            string
+	// Sampling is what the call was sent with, for reproducing it.
+	Sampling llm.Sampling
 }
 
 type noopConversationRecorder struct{}
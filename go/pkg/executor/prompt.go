@@ -1,22 +1,84 @@
 package executor
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
 
 	"nof0-api/pkg/llm"
+	market "nof0-api/pkg/market"
 )
 
+// PromptTier names a prompt layout. Tiers trade context for size: when the
+// full prompt does not fit the model's window the renderer falls back to the
+// next tier in PromptTiers order.
+type PromptTier string
+
+const (
+	PromptTierFull       PromptTier = "full"
+	PromptTierCompressed PromptTier = "compressed"
+	PromptTierMinimal    PromptTier = "minimal"
+)
+
+// PromptTiers lists the tiers from most to least detailed. The full tier is
+// the configured template; the others are optional siblings named
+// <template>.compressed.tmpl and <template>.minimal.tmpl.
+var PromptTiers = []PromptTier{PromptTierFull, PromptTierCompressed, PromptTierMinimal}
+
+// TierTemplatePath returns the path of tier's layout for the full template at path.
+func TierTemplatePath(path string, tier PromptTier) string {
+	if tier == PromptTierFull {
+		return path
+	}
+	return strings.TrimSuffix(path, ".tmpl") + "." + string(tier) + ".tmpl"
+}
+
 // PromptInputs contains dynamic data injected into the executor prompt template.
 type PromptInputs struct {
-	CurrentTime     string
-	RuntimeMinutes  int
-	SharpeRatio     float64
-	AccountOverview string
-	OpenPositions   string
-	RiskBudget      string
-	PerformanceView string
-	CandidateCoins  string
-	MarketSnapshots string
+	CurrentTime     string  `doc:"Cycle time, RFC 3339 UTC" example:"2025-01-02T03:04:05Z"`
+	RuntimeMinutes  int     `doc:"Minutes since the trader started" example:"90"`
+	SharpeRatio     float64 `doc:"Sharpe ratio of the trader's returns" example:"1.2"`
+	AccountOverview string  `doc:"Equity, balance and margin summary"`
+	OpenPositions   string  `doc:"One line per open position"`
+	RiskBudget      string  `doc:"Remaining risk and margin headroom"`
+	PerformanceView string  `doc:"Recent trade performance summary"`
+	CandidateCoins  string  `doc:"Symbols the model may trade with their sources" example:"BTC [rank_1h_abs], ETH [rank_1h_abs]"`
+	MarketSnapshots string  `doc:"Per-symbol market snapshots as JSON"`
+	// MarketSummary is a one-line-per-symbol digest of MarketSnapshots for
+	// reduced prompt tiers.
+	MarketSummary string `doc:"One line per symbol digest of MarketSnapshots"`
+	// MarketSeries holds the per-symbol time series, laid out in the model's
+	// series format (inline or CSV blocks).
+	MarketSeries    string `doc:"Per-symbol time series in the model's series format"`
+	DataUnavailable string `doc:"Comma separated symbols lacking market data, empty when complete" example:"DOGE"`
+
+	// The fields below are empty when their feature is off or has nothing
+	// to report, so each template decides whether and where to show them.
+	DecisionCadence    string   `doc:"Interval between scheduled decisions" example:"15m0s"`
+	MarketRegime       string   `doc:"Volatility regime that set the cadence" example:"quiet"`
+	TriggerReasons     []string `doc:"Why this cycle ran ahead of schedule" example:"[\"price_move BTC -2.40% since last decision\"]"`
+	AdvisorNotes       []string `doc:"Non-LLM strategy signals; opens they object to are vetoed" example:"[\"[funding_veto] BTC funding 0.0600% is extreme\"]"`
+	ObservedSlippage   string   `doc:"Recent fill slippage vs arrival price per symbol, bps" example:"BTC=-0.4(p90 +1.5, n=12)"`
+	DataQuality        string   `doc:"Market data quality per symbol, 1 = clean" example:"BTC=1.00, SOL=0.55(gaps=3, stale=4m0s)"`
+	Blacklisted        string   `doc:"Symbols blacklisted after data anomalies, with their end" example:"SOL until 14:30Z"`
+	WarmingUp          string   `doc:"Symbols whose indicators lack history" example:"SOL (long_term:EMA50)"`
+	LossStreak         string   `doc:"Consecutive losses against the pause limit" example:"2/3"`
+	EntriesPausedUntil string   `doc:"End of the loss-streak entry pause, RFC 3339 UTC" example:"2025-01-02T04:00:00Z"`
+
+	// snapshots and seriesFormat rebuild MarketSeries when a prompt budget
+	// forces the series to be shortened.
+	snapshots    map[string]*market.Snapshot
+	seriesFormat string
+}
+
+// withSeriesFormat re-lays MarketSeries out in format.
+func (in PromptInputs) withSeriesFormat(format string) PromptInputs {
+	in.seriesFormat = format
+	in.MarketSeries = formatMarketSeries(in.snapshots, format)
+	return in
 }
 
 // PromptRenderer renders the executor system prompt from a template file.
@@ -24,10 +86,18 @@ type PromptRenderer struct {
 	cfg             *Config
 	tpl             *llm.PromptTemplate
 	templateVersion string
+	// tiers holds the reduced layouts found next to the template, in fallback order.
+	tiers []tierTemplate
+}
+
+type tierTemplate struct {
+	tier PromptTier
+	tpl  *llm.PromptTemplate
 }
 
 // NewPromptRenderer constructs a renderer using the supplied template path.
-func NewPromptRenderer(cfg *Config, templatePath string) (*PromptRenderer, error) {
+// Options apply to the full template and every tier layout.
+func NewPromptRenderer(cfg *Config, templatePath string, tplOpts ...llm.PromptTemplateOption) (*PromptRenderer, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("executor prompt renderer requires config")
 	}
@@ -36,20 +106,63 @@ func NewPromptRenderer(cfg *Config, templatePath string) (*PromptRenderer, error
 		ExpectedVersion:      cfg.PromptSchemaVersion,
 		RequireVersionHeader: cfg.PromptValidation.RequireVersionHeader,
 		StrictMode:           cfg.PromptValidation.StrictMode,
+		FS:                   cfg.TemplateFS,
 	}
 	version, err := guard.Enforce(templatePath)
 	if err != nil {
 		return nil, err
 	}
-	tpl, err := llm.NewPromptTemplate(templatePath, nil)
+	opts := append([]llm.PromptTemplateOption{llm.WithDataType(PromptDataType())}, tplOpts...)
+	if cfg.TemplateFS != nil {
+		opts = append(opts, llm.WithFS(cfg.TemplateFS))
+	}
+	if len(cfg.AllowedFuncs) > 0 {
+		opts = append(opts, llm.WithAllowedFuncs(cfg.AllowedFuncs...))
+	}
+	tpl, err := llm.NewPromptTemplate(templatePath, nil, append(opts, llm.WithPinnedDigest(cfg.TemplateDigest))...)
 	if err != nil {
 		return nil, err
 	}
-	return &PromptRenderer{
+	r := &PromptRenderer{
 		cfg:             cfg,
 		tpl:             tpl,
 		templateVersion: version,
-	}, nil
+	}
+	for _, tier := range PromptTiers[1:] {
+		path := TierTemplatePath(templatePath, tier)
+		if !templateExists(cfg.TemplateFS, path) {
+			continue
+		}
+		if _, err := guard.Enforce(path); err != nil {
+			return nil, err
+		}
+		tierTpl, err := llm.NewPromptTemplate(path, nil, opts...)
+		if err != nil {
+			return nil, err
+		}
+		r.tiers = append(r.tiers, tierTemplate{tier: tier, tpl: tierTpl})
+	}
+	return r, nil
+}
+
+// templates lists the full template followed by its tier layouts.
+func (r *PromptRenderer) templates() []*llm.PromptTemplate {
+	out := []*llm.PromptTemplate{r.tpl}
+	for _, t := range r.tiers {
+		out = append(out, t.tpl)
+	}
+	return out
+}
+
+// templateExists reports whether path exists in fsys, or on disk when fsys is nil.
+func templateExists(fsys fs.FS, path string) bool {
+	var err error
+	if fsys != nil {
+		_, err = fs.Stat(fsys, path)
+	} else {
+		_, err = os.Stat(path)
+	}
+	return err == nil
 }
 
 // Render generates the final prompt string populated with inputs.
@@ -58,15 +171,125 @@ func (r *PromptRenderer) Render(inputs PromptInputs) (string, error) {
 		return "", fmt.Errorf("executor prompt renderer not initialised")
 	}
 
-	payload := struct {
-		Config *Config
-		PromptInputs
-	}{
+	return r.tpl.Render(r.payload(inputs))
+}
+
+func (r *PromptRenderer) payload(inputs PromptInputs) promptPayload {
+	return promptPayload{
 		Config:       r.cfg,
 		PromptInputs: inputs,
 	}
+}
+
+// PromptDataType is the type executor templates render against: the
+// executor Config as .Config plus the PromptInputs fields.
+func PromptDataType() reflect.Type {
+	return reflect.TypeOf(promptPayload{})
+}
+
+func init() {
+	llm.RegisterType("executor.PromptInputs", promptPayload{})
+}
+
+// promptPayload is the template data. It lets llm.RenderWithBudget shorten
+// MarketSeries, oldest points first, to fit the model's window.
+type promptPayload struct {
+	Config *Config
+	PromptInputs
+}
+
+func (p promptPayload) SeriesLen() int {
+	longest := 0
+	for _, s := range p.snapshots {
+		if s == nil {
+			continue
+		}
+		for _, bundle := range []*market.SeriesBundle{s.Intraday, s.LongTerm} {
+			for _, col := range bundleColumns(bundle) {
+				longest = max(longest, len(col.values))
+			}
+		}
+	}
+	return longest
+}
+
+func (p promptPayload) TrimSeries(n int) any {
+	trimmed := make(map[string]*market.Snapshot, len(p.snapshots))
+	for sym, s := range p.snapshots {
+		if s == nil {
+			continue
+		}
+		cp := *s
+		cp.Intraday = tailBundle(s.Intraday, n)
+		cp.LongTerm = tailBundle(s.LongTerm, n)
+		trimmed[sym] = &cp
+	}
+	p.snapshots = trimmed
+	p.MarketSeries = formatMarketSeries(trimmed, p.seriesFormat)
+	return p
+}
 
-	return r.tpl.Render(payload)
+// RenderWithinBudget renders the most detailed tier that fits maxTokens,
+// trimming each tier's time series before falling back to the next. A
+// non-positive maxTokens always renders the full tier. It fails when even
+// the smallest available tier is too large, since the provider would reject
+// the request anyway.
+func (r *PromptRenderer) RenderWithinBudget(inputs PromptInputs, maxTokens int) (string, PromptTier, error) {
+	rendered, tier, err := r.renderWithinBudget(inputs, maxTokens)
+	return rendered.Prompt, tier, err
+}
+
+// renderWithinBudget is RenderWithinBudget returning the rendered data, so
+// message blocks can be rendered from the same trimmed series.
+func (r *PromptRenderer) renderWithinBudget(inputs PromptInputs, maxTokens int) (llm.BudgetedPrompt, PromptTier, error) {
+	if r == nil || r.tpl == nil {
+		return llm.BudgetedPrompt{}, "", fmt.Errorf("executor prompt renderer not initialised")
+	}
+	payload := r.payload(inputs)
+	layouts := append([]tierTemplate{{tier: PromptTierFull, tpl: r.tpl}}, r.tiers...)
+	tokens := 0
+	for _, layout := range layouts {
+		rendered, err := layout.tpl.RenderWithBudget(payload, maxTokens)
+		if err == nil {
+			return rendered, layout.tier, nil
+		}
+		if !errors.Is(err, llm.ErrPromptOverBudget) {
+			return llm.BudgetedPrompt{}, layout.tier, err
+		}
+		tokens = rendered.Tokens
+	}
+	last := layouts[len(layouts)-1].tier
+	return llm.BudgetedPrompt{}, last, fmt.Errorf("executor: prompt needs ~%d tokens at tier %s, budget is %d", tokens, last, maxTokens)
+}
+
+// RenderMessages renders tier's message blocks as a message sequence. It
+// returns nil when that tier's template defines no message blocks.
+func (r *PromptRenderer) RenderMessages(inputs PromptInputs, tier PromptTier) ([]llm.Message, error) {
+	if r == nil || r.tpl == nil {
+		return nil, fmt.Errorf("executor prompt renderer not initialised")
+	}
+	tpl := r.tpl
+	for _, t := range r.tiers {
+		if t.tier == tier {
+			tpl = t.tpl
+		}
+	}
+	if !tpl.HasMessages() {
+		return nil, nil
+	}
+	return tpl.RenderMessages(r.payload(inputs))
+}
+
+// Tiers returns the available prompt tiers in fallback order.
+func (r *PromptRenderer) Tiers() []PromptTier {
+	if r == nil || r.tpl == nil {
+		return nil
+	}
+	out := []PromptTier{PromptTierFull}
+	for _, t := range r.tiers {
+		out = append(out, t.tier)
+	}
+	return out
 }
 
 // Digest returns the underlying template digest for observability.
@@ -3,32 +3,89 @@ package executor
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"nof0-api/pkg/llm"
 	market "nof0-api/pkg/market"
 )
 
-// buildPromptInputs renders dynamic sections used by the executor prompt template.
+// BuildPromptInputs exposes the executor prompt inputs for tooling that renders
+// templates outside a live decision cycle (fixtures, previews, replays).
+func BuildPromptInputs(cfg *Config, ctx *Context) PromptInputs {
+	if ctx == nil {
+		ctx = &Context{}
+	}
+	return buildPromptInputs(cfg, ctx)
+}
+
+// buildPromptInputs renders dynamic sections used by the executor prompt
+// template. It reads the clock only from ctx.CurrentTime, so the same
+// context always renders the same prompt.
 func buildPromptInputs(cfg *Config, ctx *Context) PromptInputs {
-	now := time.Now().UTC().Format(time.RFC3339)
-	current := ctx.CurrentTime
-	if strings.TrimSpace(current) == "" {
-		current = now
+	now := cycleTime(ctx)
+	levCaps := volatilityCaps(cfg, ctx.MarketDataMap)
+
+	inputs := PromptInputs{
+		CurrentTime:      ctx.CurrentTime,
+		RuntimeMinutes:   ctx.RuntimeMinutes,
+		SharpeRatio:      safePerf(ctx.Performance).SharpeRatio,
+		AccountOverview:  formatAccount(ctx.Account),
+		OpenPositions:    formatPositions(ctx.Positions),
+		RiskBudget:       formatRiskBudget(cfg, ctx),
+		PerformanceView:  formatPerformance(ctx.Performance),
+		CandidateCoins:   formatCandidates(ctx.CandidateCoins),
+		MarketSnapshots:  formatMarketJSON(ctx.MarketDataMap, levCaps),
+		MarketSummary:    formatMarketSummary(ctx.MarketDataMap, levCaps),
+		MarketSeries:     formatMarketSeries(ctx.MarketDataMap, llm.SeriesFormatInline),
+		DataUnavailable:  formatUnavailable(ctx.UnavailableSymbols),
+		TriggerReasons:   ctx.TriggerReasons,
+		AdvisorNotes:     ctx.AdvisorNotes,
+		ObservedSlippage: formatObservedSlippage(ctx.ObservedSlippage),
+		DataQuality:      formatDataQuality(ctx.DataQuality),
+		Blacklisted:      formatBlacklisted(ctx.Blacklisted, now),
+		WarmingUp:        formatWarmingUp(ctx.MarketDataMap),
+		snapshots:        ctx.MarketDataMap,
+		seriesFormat:     llm.SeriesFormatInline,
+	}
+	if ctx.DecisionInterval > 0 {
+		inputs.DecisionCadence = ctx.DecisionInterval.String()
+		inputs.MarketRegime = ctx.MarketRegime
+	}
+	if ctx.LossStreakLimit > 0 {
+		inputs.LossStreak = fmt.Sprintf("%d/%d", ctx.LossStreak, ctx.LossStreakLimit)
+	}
+	if now.Before(ctx.EntriesPausedUntil) {
+		inputs.EntriesPausedUntil = ctx.EntriesPausedUntil.UTC().Format(time.RFC3339)
 	}
+	return inputs
+}
 
-	return PromptInputs{
-		CurrentTime:     current,
-		RuntimeMinutes:  ctx.RuntimeMinutes,
-		SharpeRatio:     safePerf(ctx.Performance).SharpeRatio,
-		AccountOverview: formatAccount(ctx.Account),
-		OpenPositions:   formatPositions(ctx.Positions),
-		RiskBudget:      formatRiskBudget(cfg, ctx),
-		PerformanceView: formatPerformance(ctx.Performance),
-		CandidateCoins:  formatCandidates(ctx.CandidateCoins),
-		MarketSnapshots: formatMarketJSON(ctx.MarketDataMap),
+// cycleTime parses ctx.CurrentTime. Without one it is the zero time, so
+// every pause and blacklist entry that is set counts as active.
+func cycleTime(ctx *Context) time.Time {
+	now, err := time.Parse(time.RFC3339, strings.TrimSpace(ctx.CurrentTime))
+	if err != nil {
+		return time.Time{}
 	}
+	return now
+}
+
+func formatUnavailable(symbols []string) string {
+	if len(symbols) == 0 {
+		return ""
+	}
+	items := make([]string, 0, len(symbols))
+	for _, sym := range symbols {
+		if sym = strings.ToUpper(strings.TrimSpace(sym)); sym != "" {
+			items = append(items, sym)
+		}
+	}
+	sort.Strings(items)
+	return strings.Join(items, ", ")
 }
 
 func formatAccount(a AccountInfo) string {
@@ -44,9 +101,14 @@ func formatPositions(positions []PositionInfo) string {
 	// Stable sorting for reproducibility
 	items := make([]string, 0, len(positions))
 	for _, p := range positions {
-		items = append(items, fmt.Sprintf("%s %s qty=%.4f lev=%dx entry=%.4f mark=%.4f upnl=%.2f(%.2f%%) liq=%.4f",
+		item := fmt.Sprintf("%s %s qty=%.4f lev=%dx entry=%.4f mark=%.4f upnl=%.2f(%.2f%%) liq=%.4f",
 			p.Symbol, p.Side, p.Quantity, p.Leverage, p.EntryPrice, p.MarkPrice, p.UnrealizedPnL, p.UnrealizedPnLPct, p.LiquidationPrice,
-		))
+		)
+		// Cross is the default; only call out isolated positions.
+		if p.MarginMode == "isolated" {
+			item += " margin=isolated"
+		}
+		items = append(items, item)
 	}
 	sort.Strings(items)
 	return strings.Join(items, "\n")
@@ -74,17 +136,125 @@ func formatPerformance(p *PerformanceView) string {
 	)
 }
 
+// formatRiskBudget summarises the position and sizing limits on one line.
 func formatRiskBudget(cfg *Config, ctx *Context) string {
 	remaining := cfg.MaxPositions - len(ctx.Positions)
 	if remaining < 0 {
 		remaining = 0
 	}
-	return fmt.Sprintf("max_positions=%d (remaining=%d), min_confidence=%d, min_rr=%.2f",
+	budget := fmt.Sprintf("max_positions=%d (remaining=%d), min_confidence=%d, min_rr=%.2f",
 		cfg.MaxPositions, remaining, cfg.MinConfidence, cfg.MinRiskReward,
 	)
+	if ctx.MaxRiskPct > 0 {
+		budget += fmt.Sprintf(", max_risk_per_trade=%.2f%% equity", ctx.MaxRiskPct)
+	}
+	if ctx.MaxPositionSizeUSD > 0 {
+		budget += fmt.Sprintf(", max_position_size=%.2f USD", ctx.MaxPositionSizeUSD)
+	}
+	if ctx.RiskScale > 0 && ctx.RiskScale < 1 {
+		budget += fmt.Sprintf(", drawdown=%.2f%% (risk scaled x%.2f until equity recovers)", ctx.DrawdownPct, ctx.RiskScale)
+	}
+	if ctx.SizingMode == "vol_target" {
+		budget += ", sizing=vol_target (position_size_usd is replaced by " + formatVolTargetSizes(ctx.VolTargetSizes) + ")"
+	}
+	if ctx.OpenAllowance != nil {
+		budget += ", " + ctx.OpenAllowance.String()
+	}
+	return budget
+}
+
+// formatBlacklisted lists entries still active at now as "SYM until
+// HH:MMZ", sorted by symbol.
+func formatBlacklisted(entries map[string]time.Time, now time.Time) string {
+	items := make([]string, 0, len(entries))
+	for sym, until := range entries {
+		if now.Before(until) {
+			items = append(items, sym+" until "+until.UTC().Format("15:04Z"))
+		}
+	}
+	sort.Strings(items)
+	return strings.Join(items, ", ")
 }
 
-func formatMarketJSON(snaps map[string]*market.Snapshot) string {
+// formatDataQuality lists scores as "BTC=1.00, SOL=0.55(gaps=3, stale=4m0s)",
+// sorted by symbol; details appear only for symbols that lost points.
+func formatDataQuality(scores map[string]market.DataQuality) string {
+	symbols := make([]string, 0, len(scores))
+	for sym := range scores {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+	items := make([]string, 0, len(symbols))
+	for _, sym := range symbols {
+		q := scores[sym]
+		item := fmt.Sprintf("%s=%.2f", sym, q.Score)
+		var details []string
+		if q.Gaps > 0 {
+			details = append(details, fmt.Sprintf("gaps=%d", q.Gaps))
+		}
+		if q.StaleMs > 0 {
+			details = append(details, "stale="+(time.Duration(q.StaleMs)*time.Millisecond).Truncate(time.Second).String())
+		}
+		if q.Outliers > 0 {
+			details = append(details, fmt.Sprintf("outliers=%d", q.Outliers))
+		}
+		if len(details) > 0 {
+			item += "(" + strings.Join(details, ", ") + ")"
+		}
+		items = append(items, item)
+	}
+	return strings.Join(items, ", ")
+}
+
+// formatWarmingUp lists symbols with indicators still warming up as
+// "SYM (long_term:EMA50)", sorted by symbol.
+func formatWarmingUp(snaps map[string]*market.Snapshot) string {
+	items := make([]string, 0)
+	for sym, snap := range snaps {
+		if warming := snap.WarmingUp(); len(warming) > 0 {
+			items = append(items, sym+" ("+strings.Join(warming, ", ")+")")
+		}
+	}
+	sort.Strings(items)
+	return strings.Join(items, "; ")
+}
+
+// formatObservedSlippage lists per-symbol slippage as "SYM=+1.2(p90 3.4, n=8)",
+// sorted by symbol.
+func formatObservedSlippage(stats map[string]SlippageStat) string {
+	symbols := make([]string, 0, len(stats))
+	for sym, st := range stats {
+		if st.Fills > 0 {
+			symbols = append(symbols, sym)
+		}
+	}
+	sort.Strings(symbols)
+	items := make([]string, 0, len(symbols))
+	for _, sym := range symbols {
+		st := stats[sym]
+		items = append(items, fmt.Sprintf("%s=%+.1f(p90 %+.1f, n=%d)", sym, st.MeanBps, st.P90Bps, st.Fills))
+	}
+	return strings.Join(items, " ")
+}
+
+// formatVolTargetSizes lists the per-symbol vol-target sizes, sorted by symbol.
+func formatVolTargetSizes(sizes map[string]float64) string {
+	if len(sizes) == 0 {
+		return "the vol-target size; no ATR data, opens will be rejected"
+	}
+	symbols := make([]string, 0, len(sizes))
+	for sym := range sizes {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+	items := make([]string, 0, len(symbols))
+	for _, sym := range symbols {
+		items = append(items, fmt.Sprintf("%s=%.2f", sym, sizes[sym]))
+	}
+	return "vol-target USD: " + strings.Join(items, " ")
+}
+
+func formatMarketJSON(snaps map[string]*market.Snapshot, levCaps map[string]int) string {
 	if len(snaps) == 0 {
 		return "{}"
 	}
@@ -98,6 +268,8 @@ func formatMarketJSON(snaps map[string]*market.Snapshot) string {
 		MACD     float64            `json:"macd,omitempty"`
 		OILatest *float64           `json:"oi_latest,omitempty"`
 		Funding  *float64           `json:"funding,omitempty"` // funding rate fraction (0.01 == +1%)
+		// MaxLeverage is the volatility haircut in force, when any.
+		MaxLeverage int `json:"max_leverage,omitempty"`
 	}
 	out := make(map[string]Lite, len(snaps))
 	for sym, s := range snaps {
@@ -110,23 +282,211 @@ func formatMarketJSON(snaps map[string]*market.Snapshot) string {
 			funding = &s.Funding.Rate
 		}
 		out[sym] = Lite{
-			Price:    s.Price.Last,
-			Change1h: s.Change.OneHour,
-			Change4h: s.Change.FourHour,
-			EMA:      s.Indicators.EMA,
-			RSI:      s.Indicators.RSI,
-			MACD:     s.Indicators.MACD,
-			OILatest: oi,
-			Funding:  funding,
+			Price:       s.Price.Last,
+			Change1h:    s.Change.OneHour,
+			Change4h:    s.Change.FourHour,
+			EMA:         s.Indicators.EMA,
+			RSI:         s.Indicators.RSI,
+			MACD:        s.Indicators.MACD,
+			OILatest:    oi,
+			Funding:     funding,
+			MaxLeverage: levCaps[sym],
 		}
 	}
 	b, _ := json.Marshal(out)
 	return string(b)
 }
 
+// formatMarketSummary condenses each snapshot to price, momentum, funding and
+// any volatility leverage cap, one symbol per line in symbol order.
+func formatMarketSummary(snaps map[string]*market.Snapshot, levCaps map[string]int) string {
+	if len(snaps) == 0 {
+		return "(none)"
+	}
+	syms := make([]string, 0, len(snaps))
+	for sym, s := range snaps {
+		if s != nil {
+			syms = append(syms, sym)
+		}
+	}
+	sort.Strings(syms)
+	lines := make([]string, 0, len(syms))
+	for _, sym := range syms {
+		s := snaps[sym]
+		line := fmt.Sprintf("%s price=%g 1h=%+.2f%% 4h=%+.2f%%", sym, s.Price.Last, s.Change.OneHour*100, s.Change.FourHour*100)
+		if s.Funding != nil {
+			line += fmt.Sprintf(" funding=%+.4f%%", s.Funding.Rate*100)
+		}
+		if capLev := levCaps[sym]; capLev > 0 {
+			line += fmt.Sprintf(" max_leverage=%dx", capLev)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
 func safePerf(p *PerformanceView) *PerformanceView {
 	if p != nil {
 		return p
 	}
 	return &PerformanceView{}
 }
+
+// seriesColumn is one named time series within a bundle.
+type seriesColumn struct {
+	name   string
+	values []float64
+}
+
+// formatMarketSeries lays out each symbol's intraday and long-term series,
+// either as comma-joined values per line or as CSV code blocks with one row
+// per bar. Both are ordered oldest → newest.
+func formatMarketSeries(snaps map[string]*market.Snapshot, format string) string {
+	if len(snaps) == 0 {
+		return ""
+	}
+	symbols := make([]string, 0, len(snaps))
+	for sym := range snaps {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+
+	var b strings.Builder
+	for _, sym := range symbols {
+		s := snaps[sym]
+		if s == nil {
+			continue
+		}
+		for _, frame := range []struct {
+			label  string
+			bundle *market.SeriesBundle
+		}{{"intraday", s.Intraday}, {"long_term", s.LongTerm}} {
+			cols := bundleColumns(frame.bundle)
+			if len(cols) == 0 {
+				continue
+			}
+			if b.Len() > 0 {
+				b.WriteString("\n\n")
+			}
+			fmt.Fprintf(&b, "%s %s:\n", sym, frame.label)
+			if format == llm.SeriesFormatCSV {
+				writeSeriesCSV(&b, cols)
+			} else {
+				writeSeriesInline(&b, cols)
+			}
+		}
+	}
+	return b.String()
+}
+
+func bundleColumns(bundle *market.SeriesBundle) []seriesColumn {
+	if bundle == nil {
+		return nil
+	}
+	var cols []seriesColumn
+	add := func(name string, values []float64) {
+		if len(values) > 0 {
+			cols = append(cols, seriesColumn{name: name, values: values})
+		}
+	}
+	addMap := func(m map[string][]float64) {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			add(strings.ToLower(k), m[k])
+		}
+	}
+	add("price", bundle.Prices)
+	addMap(bundle.EMA)
+	add("macd", bundle.MACD)
+	addMap(bundle.RSI)
+	addMap(bundle.ATR)
+	add("volume", bundle.Volume)
+	return cols
+}
+
+// tailBundle copies bundle keeping the newest n points of every series.
+func tailBundle(bundle *market.SeriesBundle, n int) *market.SeriesBundle {
+	if bundle == nil {
+		return nil
+	}
+	tailMap := func(m map[string][]float64) map[string][]float64 {
+		if m == nil {
+			return nil
+		}
+		out := make(map[string][]float64, len(m))
+		for k, v := range m {
+			out[k] = tail(v, n)
+		}
+		return out
+	}
+	cp := *bundle
+	cp.Prices = tail(bundle.Prices, n)
+	cp.EMA = tailMap(bundle.EMA)
+	cp.MACD = tail(bundle.MACD, n)
+	cp.RSI = tailMap(bundle.RSI)
+	cp.ATR = tailMap(bundle.ATR)
+	cp.Volume = tail(bundle.Volume, n)
+	cp.Candles = tail(bundle.Candles, n)
+	return &cp
+}
+
+func tail[T any](values []T, n int) []T {
+	if len(values) <= n {
+		return values
+	}
+	return values[len(values)-n:]
+}
+
+func writeSeriesInline(b *strings.Builder, cols []seriesColumn) {
+	for i, col := range cols {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		values := make([]string, len(col.values))
+		for j, v := range col.values {
+			values[j] = formatSeriesValue(v, "NaN")
+		}
+		fmt.Fprintf(b, "  %s: %s", col.name, strings.Join(values, ","))
+	}
+}
+
+// writeSeriesCSV aligns columns on their newest value, so shorter series
+// leave leading cells empty.
+func writeSeriesCSV(b *strings.Builder, cols []seriesColumn) {
+	rows := 0
+	header := make([]string, len(cols))
+	for i, col := range cols {
+		header[i] = col.name
+		if len(col.values) > rows {
+			rows = len(col.values)
+		}
+	}
+	b.WriteString("```csv\n")
+	b.WriteString(strings.Join(header, ","))
+	cells := make([]string, len(cols))
+	for r := 0; r < rows; r++ {
+		for i, col := range cols {
+			idx := r - (rows - len(col.values))
+			cells[i] = ""
+			if idx >= 0 {
+				cells[i] = formatSeriesValue(col.values[idx], "")
+			}
+		}
+		b.WriteByte('\n')
+		b.WriteString(strings.Join(cells, ","))
+	}
+	b.WriteString("\n```")
+}
+
+// formatSeriesValue rounds to 4 decimals and drops trailing zeros; missing
+// indicator warm-up values render as missing.
+func formatSeriesValue(v float64, missing string) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return missing
+	}
+	return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64)
+}
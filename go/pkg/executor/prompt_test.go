@@ -1,11 +1,17 @@
 package executor
 
 import (
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"nof0-api/pkg/llm"
+	"nof0-api/pkg/llm/testutil"
+	"nof0-api/pkg/market"
 )
 
 func TestPromptRenderer(t *testing.T) {
@@ -29,7 +35,7 @@ func TestPromptRenderer(t *testing.T) {
 	assert.NoError(t, err, "NewPromptRenderer should not error")
 	assert.NotNil(t, renderer, "renderer should not be nil")
 
-	out, err := renderer.Render(PromptInputs{
+	inputs := PromptInputs{
 		CurrentTime:     "2025-11-01T08:00:00Z",
 		RuntimeMinutes:  120,
 		SharpeRatio:     1.45,
@@ -39,25 +45,180 @@ func TestPromptRenderer(t *testing.T) {
 		PerformanceView: "WinRate: 60%",
 		CandidateCoins:  "- BTC\n- ETH\n- SOL",
 		MarketSnapshots: `{"BTC":{"price":64000}}`,
+	}
+	engine := testutil.EngineFunc(func(_ string, data any) (string, error) {
+		return renderer.Render(data.(PromptInputs))
 	})
+	testutil.SnapshotTest(t, engine, templatePath, inputs, filepath.Join("testdata", "default_prompt.golden"))
+}
+
+func TestPromptRendererDataUnavailable(t *testing.T) {
+	templatePath := filepath.Join("..", "..", "etc", "prompts", "executor", "default_prompt.tmpl")
+	cfg := &Config{MajorCoinLeverage: 20, AltcoinLeverage: 8, MinConfidence: 75, MinRiskReward: 3.2, MaxPositions: 3}
+	renderer, err := NewPromptRenderer(cfg, templatePath)
+	assert.NoError(t, err, "NewPromptRenderer should not error")
+
+	inputs := buildPromptInputs(cfg, &Context{UnavailableSymbols: []string{"sol", "BTC"}})
+	assert.Equal(t, "BTC, SOL", inputs.DataUnavailable)
+	out, err := renderer.Render(inputs)
+	assert.NoError(t, err, "Render should not error")
+	assert.Contains(t, out, "DATA_UNAVAILABLE: BTC, SOL")
+
+	out, err = renderer.Render(buildPromptInputs(cfg, &Context{}))
 	assert.NoError(t, err, "Render should not error")
-	assert.NotEmpty(t, out, "rendered output should not be empty")
-
-	expectations := []string{
-		"TIMESTAMP: 2025-11-01T08:00:00Z",
-		"UPTIME_MINUTES: 120",
-		"ROLLING_SHARPE: 1.45",
-		"BTC/ETH default 20x",
-		"Minimum reward-to-risk ratio: 3.20",
-		"RISK_BUDGET:",
-		"Respect per-trader limits defined by Manager",
-		"Available risk: $250",
-		`"BTC":{"price":64000}`,
-		"minimum confidence 75",
+	assert.NotContains(t, out, "DATA_UNAVAILABLE:")
+}
+
+func TestPromptRendererFallsBackThroughTiers(t *testing.T) {
+	templatePath := filepath.Join("..", "..", "etc", "prompts", "executor", "default_prompt.tmpl")
+	cfg := &Config{MajorCoinLeverage: 20, AltcoinLeverage: 8, MinConfidence: 75, MinRiskReward: 3.2, MaxPositions: 3}
+	renderer, err := NewPromptRenderer(cfg, templatePath)
+	assert.NoError(t, err, "NewPromptRenderer should not error")
+	assert.Equal(t, PromptTiers, renderer.Tiers())
+
+	inputs := buildPromptInputs(cfg, &Context{MarketDataMap: map[string]*market.Snapshot{
+		"BTC": {Price: market.PriceInfo{Last: 64000}, Change: market.ChangeInfo{OneHour: 0.012}},
+	}})
+	sizes := make(map[PromptTier]int)
+	for _, tier := range PromptTiers {
+		tpl, err := llm.NewPromptTemplate(TierTemplatePath(templatePath, tier), nil)
+		assert.NoError(t, err)
+		out, err := tpl.Render(renderer.payload(inputs))
+		assert.NoError(t, err)
+		sizes[tier] = llm.EstimateTokens(out)
+	}
+	assert.Greater(t, sizes[PromptTierFull], sizes[PromptTierCompressed])
+	assert.Greater(t, sizes[PromptTierCompressed], sizes[PromptTierMinimal])
+
+	out, tier, err := renderer.RenderWithinBudget(inputs, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, PromptTierFull, tier)
+	assert.Contains(t, out, "MARKET_SNAPSHOTS (JSON")
+
+	_, tier, err = renderer.RenderWithinBudget(inputs, sizes[PromptTierFull]-1)
+	assert.NoError(t, err)
+	assert.Equal(t, PromptTierCompressed, tier)
+
+	out, tier, err = renderer.RenderWithinBudget(inputs, sizes[PromptTierMinimal])
+	assert.NoError(t, err)
+	assert.Equal(t, PromptTierMinimal, tier)
+	assert.Contains(t, out, "BTC price=64000 1h=+1.20% 4h=+0.00%")
+
+	_, tier, err = renderer.RenderWithinBudget(inputs, sizes[PromptTierMinimal]-1)
+	assert.ErrorContains(t, err, "tier minimal")
+	assert.Equal(t, PromptTierMinimal, tier)
+}
+
+func TestPromptRendererTrimsSeriesBeforeFallingBack(t *testing.T) {
+	templatePath := filepath.Join("..", "..", "etc", "prompts", "executor", "default_prompt.tmpl")
+	cfg := &Config{MajorCoinLeverage: 20, AltcoinLeverage: 8, MinConfidence: 75, MinRiskReward: 3.2, MaxPositions: 3}
+	renderer, err := NewPromptRenderer(cfg, templatePath)
+	assert.NoError(t, err, "NewPromptRenderer should not error")
+
+	prices := make([]float64, 200)
+	for i := range prices {
+		prices[i] = 1000 + float64(i)
 	}
-	for _, substr := range expectations {
-		assert.Contains(t, out, substr, "rendered prompt should contain %q", substr)
+	inputs := buildPromptInputs(cfg, &Context{MarketDataMap: map[string]*market.Snapshot{
+		"BTC": {Price: market.PriceInfo{Last: 5}, Intraday: &market.SeriesBundle{Prices: prices}},
+	}})
+	full, err := renderer.Render(inputs)
+	assert.NoError(t, err)
+	fullTokens := llm.EstimateTokens(full)
+
+	rendered, tier, err := renderer.renderWithinBudget(inputs, fullTokens-200)
+	assert.NoError(t, err)
+	assert.Equal(t, PromptTierFull, tier, "trimming the series fits the full tier")
+	assert.LessOrEqual(t, rendered.Tokens, fullTokens-200)
+	assert.Greater(t, rendered.SeriesLen, 0)
+	assert.Less(t, rendered.SeriesLen, len(prices))
+	assert.Contains(t, rendered.Prompt, "1199", "newest points are kept")
+	assert.NotContains(t, rendered.Prompt, "1000,1001", "oldest points are dropped")
+	trimmed := rendered.Data.(promptPayload)
+	assert.Equal(t, trimmed.MarketSeries, formatMarketSeries(trimmed.snapshots, llm.SeriesFormatInline))
+	assert.Len(t, inputs.snapshots["BTC"].Intraday.Prices, len(prices), "inputs are not mutated")
+}
+
+func TestFormatMarketSeries(t *testing.T) {
+	snaps := map[string]*market.Snapshot{
+		"ETH": {Intraday: &market.SeriesBundle{
+			Prices: []float64{3000.5, 3010.25, 3005},
+			EMA:    map[string][]float64{"EMA20": {3001.123456, 3002}},
+			RSI:    map[string][]float64{"RSI7": {math.NaN(), 55.5, 60}},
+		}},
+		"BTC": {LongTerm: &market.SeriesBundle{Prices: []float64{64000, 64100}}},
+		"SOL": {},
 	}
+
+	inline := formatMarketSeries(snaps, llm.SeriesFormatInline)
+	assert.Equal(t, "BTC long_term:\n  price: 64000,64100\n\n"+
+		"ETH intraday:\n  price: 3000.5,3010.25,3005\n  ema20: 3001.1235,3002\n  rsi7: NaN,55.5,60", inline)
+
+	csv := formatMarketSeries(snaps, llm.SeriesFormatCSV)
+	assert.Equal(t, "BTC long_term:\n```csv\nprice\n64000\n64100\n```\n\n"+
+		"ETH intraday:\n```csv\nprice,ema20,rsi7\n3000.5,,\n3010.25,3001.1235,55.5\n3005,3002,60\n```", csv)
+
+	assert.Empty(t, formatMarketSeries(nil, llm.SeriesFormatCSV))
+
+	templatePath := filepath.Join("..", "..", "etc", "prompts", "executor", "default_prompt.tmpl")
+	cfg := &Config{MajorCoinLeverage: 20, AltcoinLeverage: 8, MinConfidence: 75, MinRiskReward: 3.2, MaxPositions: 3}
+	renderer, err := NewPromptRenderer(cfg, templatePath)
+	assert.NoError(t, err)
+	out, err := renderer.Render(PromptInputs{MarketSeries: csv})
+	assert.NoError(t, err)
+	assert.Contains(t, out, "MARKET_SERIES (oldest → newest, last value is the most recent):\nBTC long_term:\n```csv")
+	out, err = renderer.Render(PromptInputs{})
+	assert.NoError(t, err)
+	assert.NotContains(t, out, "MARKET_SERIES")
+}
+
+func TestPromptRendererMessages(t *testing.T) {
+	templatePath := filepath.Join("..", "..", "etc", "prompts", "executor", "default_prompt.tmpl")
+	cfg := &Config{MajorCoinLeverage: 20, AltcoinLeverage: 8, MinConfidence: 75, MinRiskReward: 3.2, MaxPositions: 3}
+	renderer, err := NewPromptRenderer(cfg, templatePath)
+	assert.NoError(t, err, "NewPromptRenderer should not error")
+
+	inputs := buildPromptInputs(cfg, &Context{UnavailableSymbols: []string{"SOL"}})
+	single, err := renderer.Render(inputs)
+	assert.NoError(t, err)
+	msgs, err := renderer.RenderMessages(inputs, PromptTierFull)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 4)
+
+	roles := make([]string, len(msgs))
+	for i, m := range msgs {
+		roles[i] = m.Role
+		assert.Contains(t, single, m.Content, "message %d should match the single-message prompt", i)
+	}
+	assert.Equal(t, []string{"system", "user", "user", "user"}, roles)
+	assert.True(t, strings.HasPrefix(msgs[0].Content, "You are an autonomous"))
+	assert.True(t, strings.HasPrefix(msgs[1].Content, "## Current Context"))
+	assert.Contains(t, msgs[2].Content, "DATA_UNAVAILABLE: SOL")
+	assert.True(t, strings.HasSuffix(msgs[3].Content, "no additional commentary."))
+
+	// Tiers without message blocks are sent as a single message.
+	msgs, err = renderer.RenderMessages(inputs, PromptTierMinimal)
+	assert.NoError(t, err)
+	assert.Nil(t, msgs)
+}
+
+func TestPromptRendererWithoutTiers(t *testing.T) {
+	path := writeTempTemplate(t, "{{/* Version: v1.0.0 */}}\nbody {{ .CurrentTime }}")
+	renderer, err := NewPromptRenderer(&Config{}, path)
+	assert.NoError(t, err)
+	assert.Equal(t, []PromptTier{PromptTierFull}, renderer.Tiers())
+	_, _, err = renderer.RenderWithinBudget(PromptInputs{CurrentTime: "now"}, 1)
+	assert.ErrorContains(t, err, "tier full")
+}
+
+func TestPromptRendererAllowedFuncs(t *testing.T) {
+	path := filepath.Join("..", "..", "etc", "prompts", "executor", "default_prompt.tmpl")
+	renderer, err := NewPromptRenderer(&Config{AllowedFuncs: llm.SafeBuiltinFuncs}, path)
+	assert.NoError(t, err, "the shipped layouts only call safe builtins")
+	assert.Len(t, renderer.Tiers(), len(PromptTiers))
+
+	_, err = NewPromptRenderer(&Config{AllowedFuncs: []string{"len"}}, path)
+	assert.ErrorContains(t, err, "is not allowed in this template")
 }
 
 func TestPromptRendererNilConfig(t *testing.T) {
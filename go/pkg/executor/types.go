@@ -1,6 +1,8 @@
 package executor
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	market "nof0-api/pkg/market"
@@ -14,6 +16,7 @@ type PositionInfo struct {
 	MarkPrice        float64
 	Quantity         float64
 	Leverage         int
+	MarginMode       string // "cross" or "isolated"; empty when unknown
 	UnrealizedPnL    float64
 	UnrealizedPnLPct float64
 	LiquidationPrice float64
@@ -77,6 +80,12 @@ type Context struct {
 	// Optional per-trader risk guards injected by Manager.
 	MaxRiskPct         float64 // e.g., 3 means 3% of equity per trade
 	MaxPositionSizeUSD float64 // hard cap per trade
+	DrawdownPct        float64 // current drawdown from peak equity, percent
+	RiskScale          float64 // multiplier already applied to the caps above (0 when unscaled)
+	// SizingMode is the trader's sizing mode; under "vol_target" open sizes are
+	// set by the manager from VolTargetSizes (USD per symbol) at execution.
+	SizingMode     string
+	VolTargetSizes map[string]float64
 	// Optional P0 guards (disabled when zero values):
 	LiquidityThresholdUSD          float64              // require OI*Price ≥ threshold for new opens
 	MaxMarginUsagePct              float64              // after new position margin
@@ -86,6 +95,86 @@ type Context struct {
 	AltPositionValueMaxMultiple    float64              // max equity multiple for alt position value
 	RecentlyClosed                 map[string]time.Time // last close time per symbol (cooldown)
 	CooldownAfterClose             time.Duration        // disallow new opens until this duration passes
+	OpenAllowance                  *OpenAllowance       // remaining opens per hour/day (nil when unthrottled)
+	LossStreak                     int                  // consecutive losing closes so far
+	LossStreakLimit                int                  // losing closes that pause entries (0 disables)
+	EntriesPausedUntil             time.Time            // no new opens before this time (loss-streak cooldown)
+	Blacklisted                    map[string]time.Time // uppercased symbol -> end of data-anomaly blacklist
+	// ObservedSlippage summarises recent fills of this trader's model against
+	// arrival price per uppercased symbol, so size and price assumptions can
+	// follow measured execution costs.
+	ObservedSlippage map[string]SlippageStat
+	// MarketRegime is the volatility regime driving the trader's cadence
+	// ("quiet", "normal", "high_vol"; empty when not regime-scheduled) and
+	// DecisionInterval the time until the next decision.
+	MarketRegime     string
+	DecisionInterval time.Duration
+	// DataQuality scores each symbol's market data this cycle; nil when the
+	// trader keeps quality out of the prompt.
+	DataQuality map[string]market.DataQuality
+	// TriggerReasons explain why this cycle runs ahead of the timer (price
+	// move, funding flip, stop proximity, liquidation cluster); empty for
+	// scheduled cycles.
+	TriggerReasons []string
+	// AdvisorNotes are analysis lines from the trader's strategy advisors,
+	// each prefixed with "[advisor] ".
+	AdvisorNotes []string
+	// UnavailableSymbols lists symbols whose market data failed to load this cycle
+	// when the trader's partial-data policy asks for an annotation.
+	UnavailableSymbols []string
+	// HedgeMode reports that the account keeps separate long and short legs
+	// per symbol, so Positions may list both sides of one symbol.
+	HedgeMode bool
+}
+
+// SlippageStat is adverse slippage in basis points over recent fills;
+// positive means fills were worse than the arrival price.
+type SlippageStat struct {
+	Fills   int
+	MeanBps float64
+	P90Bps  float64
+}
+
+// OpenAllowance is the remaining trade-count budget for new opens over the
+// last hour and day. A zero limit leaves that window unthrottled.
+type OpenAllowance struct {
+	HourLimit     int
+	HourRemaining int
+	DayLimit      int
+	DayRemaining  int
+}
+
+// Remaining is the number of opens the tighter window still allows, or -1
+// when neither window is limited.
+func (a *OpenAllowance) Remaining() int {
+	if a == nil {
+		return -1
+	}
+	left := -1
+	if a.HourLimit > 0 {
+		left = a.HourRemaining
+	}
+	if a.DayLimit > 0 && (left < 0 || a.DayRemaining < left) {
+		left = a.DayRemaining
+	}
+	return left
+}
+
+func (a *OpenAllowance) String() string {
+	if a == nil {
+		return "unlimited"
+	}
+	var parts []string
+	if a.HourLimit > 0 {
+		parts = append(parts, fmt.Sprintf("opens_left_hour=%d/%d", a.HourRemaining, a.HourLimit))
+	}
+	if a.DayLimit > 0 {
+		parts = append(parts, fmt.Sprintf("opens_left_day=%d/%d", a.DayRemaining, a.DayLimit))
+	}
+	if len(parts) == 0 {
+		return "unlimited"
+	}
+	return strings.Join(parts, ", ")
 }
 
 // Decision captures a single trading action suggestion.
@@ -109,4 +198,17 @@ type FullDecision struct {
 	CoTTrace   string
 	Decisions  []Decision
 	Timestamp  time.Time
+
+	// LLM call accounting, populated once the model has been invoked.
+	PromptTokens     int
+	CompletionTokens int
+	LLMLatency       time.Duration
+	// RawResponse is the model's answer verbatim, kept so recorded cycles
+	// can be replayed exactly.
+	RawResponse string
+	// PromptVersion names the registered prompt version that rendered
+	// UserPrompt, and PromptExperiment or PromptRollout what chose it.
+	PromptVersion    string
+	PromptExperiment string
+	PromptRollout    string
 }
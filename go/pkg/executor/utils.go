@@ -2,6 +2,8 @@ package executor
 
 import (
 	"strings"
+
+	"nof0-api/pkg/decision"
 )
 
 // sanitizeResponse performs minimal cleanup prior to parsing.
@@ -12,42 +14,21 @@ func sanitizeResponse(s string) string {
 	return s
 }
 
-// decisionContract mirrors the structured JSON contract expected from the LLM.
-type decisionContract struct {
-	Signal                string  `json:"signal"`
-	Symbol                string  `json:"symbol"`
-	Leverage              int     `json:"leverage"`
-	PositionSizeUSD       float64 `json:"position_size_usd"`
-	EntryPrice            float64 `json:"entry_price"`
-	StopLoss              float64 `json:"stop_loss"`
-	TakeProfit            float64 `json:"take_profit"`
-	RiskUSD               float64 `json:"risk_usd"`
-	Confidence            int     `json:"confidence"`
-	InvalidationCondition string  `json:"invalidation_condition"`
-	Reasoning             string  `json:"reasoning"`
-}
-
-// mapDecisionContract converts the LLM contract into internal Decision format.
-func mapDecisionContract(d decisionContract, positions []PositionInfo) Decision {
-	action := strings.ToLower(strings.TrimSpace(d.Signal))
+// toDecision converts a parsed model decision into the internal Decision
+// format, inferring the side of a close from current positions.
+func toDecision(d decision.Decision, positions []PositionInfo) Decision {
 	mapped := "hold"
-	switch action {
-	case "buy_to_enter":
+	switch d.Signal {
+	case decision.SignalBuyToEnter:
 		mapped = "open_long"
-	case "sell_to_enter":
+	case decision.SignalSellToEnter:
 		mapped = "open_short"
-	case "hold":
-		mapped = "hold"
-	case "close":
-		// Infer side from current positions
-		side := inferSide(positions, d.Symbol)
-		if side == "short" {
+	case decision.SignalClose:
+		if inferSide(positions, d.Symbol) == "short" {
 			mapped = "close_short"
 		} else {
 			mapped = "close_long"
 		}
-	default:
-		// leave as hold
 	}
 	return Decision{
 		Symbol:                d.Symbol,
@@ -55,12 +36,12 @@ func mapDecisionContract(d decisionContract, positions []PositionInfo) Decision
 		Leverage:              d.Leverage,
 		PositionSizeUSD:       d.PositionSizeUSD,
 		EntryPrice:            d.EntryPrice,
-		StopLoss:              d.StopLoss,
-		TakeProfit:            d.TakeProfit,
+		StopLoss:              d.ExitPlan.StopLoss,
+		TakeProfit:            d.ExitPlan.TakeProfit,
 		Confidence:            d.Confidence,
 		RiskUSD:               d.RiskUSD,
-		Reasoning:             d.Reasoning,
-		InvalidationCondition: d.InvalidationCondition,
+		Reasoning:             d.Justification,
+		InvalidationCondition: d.ExitPlan.InvalidationCondition,
 	}
 }
 
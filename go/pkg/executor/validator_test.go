@@ -179,3 +179,196 @@ func TestValidateDecisions_ValueBand_And_Cooldown(t *testing.T) {
 	err := ValidateDecisions(cfg, ctx, []Decision{d})
 	assert.Error(t, err, "should fail due to value band and cooldown")
 }
+
+func TestValidateDecisions_OpenAllowance(t *testing.T) {
+	cfg := baseCfg()
+	d := Decision{Symbol: "ALT", Action: "open_long", Leverage: 5, PositionSizeUSD: 100, EntryPrice: 10, StopLoss: 9, TakeProfit: 13, Confidence: 90}
+
+	ctx := &Context{OpenAllowance: &OpenAllowance{HourLimit: 2, HourRemaining: 1, DayLimit: 10, DayRemaining: 0}}
+	err := ValidateDecisions(cfg, ctx, []Decision{d})
+	assert.ErrorContains(t, err, "open allowance exhausted (opens_left_hour=1/2, opens_left_day=0/10)")
+
+	ctx.OpenAllowance.DayRemaining = 4
+	assert.NoError(t, ValidateDecisions(cfg, ctx, []Decision{d}))
+	assert.Equal(t, 1, ctx.OpenAllowance.Remaining())
+
+	assert.Equal(t, -1, (*OpenAllowance)(nil).Remaining())
+	assert.Equal(t, -1, (&OpenAllowance{}).Remaining())
+}
+
+func TestValidateDecisions_LossStreakPause(t *testing.T) {
+	cfg := baseCfg()
+	ctx := &Context{
+		Positions:          []PositionInfo{{Symbol: "BTC", Side: "long"}},
+		LossStreakLimit:    3,
+		EntriesPausedUntil: time.Now().Add(time.Hour),
+	}
+	open := Decision{Symbol: "ALT", Action: "open_long", Leverage: 5, PositionSizeUSD: 100, EntryPrice: 10, StopLoss: 9, TakeProfit: 13, Confidence: 90}
+	assert.ErrorContains(t, ValidateDecisions(cfg, ctx, []Decision{open}), "entries paused until")
+	assert.NoError(t, ValidateDecisions(cfg, ctx, []Decision{{Symbol: "BTC", Action: "close_long"}}), "exits stay allowed")
+
+	ctx.EntriesPausedUntil = time.Now().Add(-time.Minute)
+	assert.NoError(t, ValidateDecisions(cfg, ctx, []Decision{open}))
+}
+
+func TestValidateDecisions_Blacklisted(t *testing.T) {
+	cfg := baseCfg()
+	d := Decision{Symbol: "sol", Action: "open_long", Leverage: 5, PositionSizeUSD: 100, EntryPrice: 10, StopLoss: 9, TakeProfit: 13, Confidence: 90}
+
+	ctx := &Context{Blacklisted: map[string]time.Time{"SOL": time.Now().Add(time.Hour)}}
+	assert.ErrorContains(t, ValidateDecisions(cfg, ctx, []Decision{d}), "sol blacklisted after data anomalies until")
+
+	ctx.Blacklisted["SOL"] = time.Now().Add(-time.Minute)
+	assert.NoError(t, ValidateDecisions(cfg, ctx, []Decision{d}))
+}
+
+func TestValidateDecisions_WarmingUp(t *testing.T) {
+	cfg := baseCfg()
+	d := Decision{Symbol: "SOL", Action: "open_long", Leverage: 5, PositionSizeUSD: 100, EntryPrice: 10, StopLoss: 9, TakeProfit: 13, Confidence: 90}
+	snap := &market.Snapshot{LongTerm: &market.SeriesBundle{InsufficientHistory: []string{"EMA50", "MACD"}}}
+
+	ctx := &Context{MarketDataMap: map[string]*market.Snapshot{"SOL": snap}}
+	assert.ErrorContains(t, ValidateDecisions(cfg, ctx, []Decision{d}), "SOL indicators warming up (long_term:EMA50, long_term:MACD)")
+
+	snap.LongTerm.InsufficientHistory = nil
+	assert.NoError(t, ValidateDecisions(cfg, ctx, []Decision{d}))
+}
+
+// TestBuildPromptInputs_Sections pins each optional prompt section to the
+// context that produces it, at a fixed cycle time.
+func TestBuildPromptInputs_Sections(t *testing.T) {
+	cfg := &Config{MaxPositions: 3, MinConfidence: 70, MinRiskReward: 2}
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	riskBudget := func(in PromptInputs) any { return in.RiskBudget }
+	const budget = "max_positions=3 (remaining=3), min_confidence=70, min_rr=2.00"
+
+	cases := []struct {
+		name  string
+		ctx   Context
+		field func(PromptInputs) any
+		want  any
+	}{
+		{
+			name:  "risk budget without limits",
+			field: riskBudget,
+			want:  budget,
+		},
+		{
+			name:  "risk budget with open allowance",
+			ctx:   Context{OpenAllowance: &OpenAllowance{HourLimit: 2, HourRemaining: 1, DayLimit: 10, DayRemaining: 4}},
+			field: riskBudget,
+			want:  budget + ", opens_left_hour=1/2, opens_left_day=4/10",
+		},
+		{
+			name:  "risk budget scaled by drawdown",
+			ctx:   Context{MaxRiskPct: 1.5, MaxPositionSizeUSD: 250, DrawdownPct: 10, RiskScale: 0.5},
+			field: riskBudget,
+			want:  budget + ", max_risk_per_trade=1.50% equity, max_position_size=250.00 USD, drawdown=10.00% (risk scaled x0.50 until equity recovers)",
+		},
+		{
+			name:  "risk budget unscaled after recovery",
+			ctx:   Context{DrawdownPct: 10, RiskScale: 1},
+			field: riskBudget,
+			want:  budget,
+		},
+		{
+			name:  "risk budget with vol-target sizes",
+			ctx:   Context{SizingMode: "vol_target", VolTargetSizes: map[string]float64{"ETH": 800, "BTC": 1200.5}},
+			field: riskBudget,
+			want:  budget + ", sizing=vol_target (position_size_usd is replaced by vol-target USD: BTC=1200.50 ETH=800.00)",
+		},
+		{
+			name:  "risk budget with vol-target sizing but no ATR",
+			ctx:   Context{SizingMode: "vol_target"},
+			field: riskBudget,
+			want:  budget + ", sizing=vol_target (position_size_usd is replaced by the vol-target size; no ATR data, opens will be rejected)",
+		},
+		{
+			name:  "loss streak",
+			ctx:   Context{LossStreakLimit: 3, LossStreak: 2},
+			field: func(in PromptInputs) any { return in.LossStreak },
+			want:  "2/3",
+		},
+		{
+			name:  "loss streak off",
+			ctx:   Context{LossStreak: 2},
+			field: func(in PromptInputs) any { return in.LossStreak },
+			want:  "",
+		},
+		{
+			name:  "entries paused",
+			ctx:   Context{LossStreakLimit: 3, EntriesPausedUntil: now.Add(time.Hour)},
+			field: func(in PromptInputs) any { return in.EntriesPausedUntil },
+			want:  "2025-01-02T04:04:05Z",
+		},
+		{
+			name:  "entries pause over",
+			ctx:   Context{LossStreakLimit: 3, EntriesPausedUntil: now.Add(-time.Minute)},
+			field: func(in PromptInputs) any { return in.EntriesPausedUntil },
+			want:  "",
+		},
+		{
+			name:  "decision cadence",
+			ctx:   Context{DecisionInterval: 15 * time.Minute, MarketRegime: "quiet"},
+			field: func(in PromptInputs) any { return in.DecisionCadence + " " + in.MarketRegime },
+			want:  "15m0s quiet",
+		},
+		{
+			name:  "no regime without a cadence",
+			ctx:   Context{MarketRegime: "quiet"},
+			field: func(in PromptInputs) any { return in.DecisionCadence + in.MarketRegime },
+			want:  "",
+		},
+		{
+			name:  "trigger reasons",
+			ctx:   Context{TriggerReasons: []string{"price_move BTC -2.40% since last decision", "funding_flip ETH"}},
+			field: func(in PromptInputs) any { return in.TriggerReasons },
+			want:  []string{"price_move BTC -2.40% since last decision", "funding_flip ETH"},
+		},
+		{
+			name:  "advisor notes",
+			ctx:   Context{AdvisorNotes: []string{"[funding_veto] BTC funding 0.0600% is extreme", "[flow] SOL bid wall"}},
+			field: func(in PromptInputs) any { return in.AdvisorNotes },
+			want:  []string{"[funding_veto] BTC funding 0.0600% is extreme", "[flow] SOL bid wall"},
+		},
+		{
+			name: "observed slippage",
+			ctx: Context{ObservedSlippage: map[string]SlippageStat{
+				"SOL": {Fills: 4, MeanBps: 6.25, P90Bps: 11},
+				"BTC": {Fills: 12, MeanBps: -0.4, P90Bps: 1.5},
+				"ETH": {},
+			}},
+			field: func(in PromptInputs) any { return in.ObservedSlippage },
+			want:  "BTC=-0.4(p90 +1.5, n=12) SOL=+6.2(p90 +11.0, n=4)",
+		},
+		{
+			name: "data quality",
+			ctx: Context{DataQuality: map[string]market.DataQuality{
+				"SOL": {Score: 0.55, Gaps: 3, StaleMs: 240000},
+				"BTC": {Score: 1},
+			}},
+			field: func(in PromptInputs) any { return in.DataQuality },
+			want:  "BTC=1.00, SOL=0.55(gaps=3, stale=4m0s)",
+		},
+		{
+			name:  "blacklist keeps active entries",
+			ctx:   Context{Blacklisted: map[string]time.Time{"SOL": now.Add(time.Hour), "ETH": now.Add(-time.Minute)}},
+			field: func(in PromptInputs) any { return in.Blacklisted },
+			want:  "SOL until 04:04Z",
+		},
+		{
+			name: "warming up",
+			ctx: Context{MarketDataMap: map[string]*market.Snapshot{
+				"SOL": {LongTerm: &market.SeriesBundle{InsufficientHistory: []string{"EMA50", "MACD"}}},
+			}},
+			field: func(in PromptInputs) any { return in.WarmingUp },
+			want:  "SOL (long_term:EMA50, long_term:MACD)",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.ctx.CurrentTime = now.Format(time.RFC3339)
+			assert.Equal(t, tc.want, tc.field(buildPromptInputs(cfg, &tc.ctx)))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package executor
+
+import (
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/llm"
+	"nof0-api/pkg/prompt"
+)
+
+// WithPromptVersions lets rollouts and experiments in registry choose the
+// prompt template for traderID's decisions. Decisions record the version
+// that rendered them; without a matching rollout or experiment the
+// executor's own template is used and attributed by digest.
+func WithPromptVersions(registry *prompt.Registry, traderID string) ExecutorOption {
+	return func(exec *BasicExecutor) {
+		exec.prompts = registry
+		exec.traderID = strings.TrimSpace(traderID)
+	}
+}
+
+// initPromptVersions builds a renderer per registered version, pinned to
+// its digest, so a bad template fails construction rather than a cycle.
+func (e *BasicExecutor) initPromptVersions(client llm.LLMClient) error {
+	if e.prompts == nil {
+		return nil
+	}
+	e.versionRenderers = make(map[string]*PromptRenderer)
+	for _, v := range e.prompts.Versions() {
+		cfg := *e.cfg
+		cfg.TemplateDigest = v.Digest
+		renderer, err := NewPromptRenderer(&cfg, v.Template, llm.WithTokenCounter(promptTokenCounter(client, e.modelAlias)))
+		if err != nil {
+			return err
+		}
+		e.versionRenderers[v.Name] = renderer
+	}
+	return nil
+}
+
+// rendererFor returns the renderer for this decision with the assignment
+// it belongs to, whose version is empty when unknown.
+func (e *BasicExecutor) rendererFor(input *Context) (*PromptRenderer, prompt.Assignment) {
+	if e.prompts == nil {
+		return e.renderer, prompt.Assignment{}
+	}
+	if a, ok := e.prompts.Assign(e.traderID, e.modelAlias, input.CallCount); ok {
+		if renderer := e.versionRenderers[a.Version.Name]; renderer != nil {
+			logx.Infof("executor: prompt version=%s experiment=%s rollout=%s trader=%s cycle=%d", a.Version.Name, a.Experiment, a.Rollout, e.traderID, input.CallCount)
+			return renderer, a
+		}
+	}
+	if v, ok := e.prompts.VersionByDigest(e.renderer.tpl.Digest()); ok {
+		return e.renderer, prompt.Assignment{Version: v}
+	}
+	return e.renderer, prompt.Assignment{}
+}
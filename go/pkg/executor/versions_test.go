@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/prompt"
+)
+
+func TestExecutorPromptVersions(t *testing.T) {
+	dir := filepath.Join("..", "..", "etc", "prompts", "executor")
+	defaultPath := filepath.Join(dir, "default_prompt.tmpl")
+	reg, err := prompt.NewRegistry(&prompt.Config{
+		Versions: []prompt.Version{
+			{Name: "default-v1", Template: defaultPath},
+			{Name: "fast-v1", Template: filepath.Join(dir, "fast_signal_prompt.tmpl")},
+		},
+		Experiments: []prompt.Experiment{{
+			Name:    "trial",
+			Traders: []string{"t1"},
+			Arms:    []prompt.Arm{{Version: "fast-v1", Weight: 1}},
+		}},
+	}, nil)
+	require.NoError(t, err)
+
+	newExec := func(traderID string) *BasicExecutor {
+		cfg := &Config{
+			MajorCoinLeverage:      20,
+			AltcoinLeverage:        10,
+			MinConfidence:          75,
+			MinRiskReward:          3.0,
+			MaxPositions:           4,
+			DecisionIntervalRaw:    "3m",
+			DecisionTimeoutRaw:     "60s",
+			MaxConcurrentDecisions: 1,
+		}
+		exec, err := NewExecutor(cfg, newFakeLLM(""), defaultPath, "", WithPromptVersions(reg, traderID))
+		require.NoError(t, err)
+		return exec
+	}
+
+	out, err := newExec("t1").GetFullDecision(&Context{CurrentTime: "2025-01-01T00:00:00Z", CallCount: 3})
+	require.NoError(t, err)
+	assert.Equal(t, "fast-v1", out.PromptVersion)
+	assert.Equal(t, "trial", out.PromptExperiment)
+	fastPrompt := out.UserPrompt
+
+	// Outside the experiment the trader's own template is attributed by digest.
+	out, err = newExec("t2").GetFullDecision(&Context{CurrentTime: "2025-01-01T00:00:00Z", CallCount: 3})
+	require.NoError(t, err)
+	assert.Equal(t, "default-v1", out.PromptVersion)
+	assert.Empty(t, out.PromptExperiment)
+	assert.NotEqual(t, fastPrompt, out.UserPrompt)
+	assert.Empty(t, out.PromptRollout)
+}
+
+func TestExecutorPromptRollout(t *testing.T) {
+	dir := filepath.Join("..", "..", "etc", "prompts", "executor")
+	defaultPath := filepath.Join(dir, "default_prompt.tmpl")
+	reg, err := prompt.NewRegistry(&prompt.Config{
+		Versions: []prompt.Version{
+			{Name: "default-v1", Template: defaultPath},
+			{Name: "fast-v1", Template: filepath.Join(dir, "fast_signal_prompt.tmpl")},
+		},
+		Rollouts: []prompt.Rollout{{
+			Name:      "fast",
+			Baseline:  "default-v1",
+			Candidate: "fast-v1",
+			Percent:   100,
+			Rollback:  prompt.RollbackPolicy{MaxDrawdownPct: 5},
+		}},
+	}, nil)
+	require.NoError(t, err)
+	cfg := &Config{
+		MajorCoinLeverage:      20,
+		AltcoinLeverage:        10,
+		MinConfidence:          75,
+		MinRiskReward:          3.0,
+		MaxPositions:           4,
+		DecisionIntervalRaw:    "3m",
+		DecisionTimeoutRaw:     "60s",
+		MaxConcurrentDecisions: 1,
+	}
+	exec, err := NewExecutor(cfg, newFakeLLM(""), defaultPath, "", WithPromptVersions(reg, "t1"))
+	require.NoError(t, err)
+
+	out, err := exec.GetFullDecision(&Context{CurrentTime: "2025-01-01T00:00:00Z", CallCount: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "fast-v1", out.PromptVersion)
+	assert.Equal(t, "fast", out.PromptRollout)
+
+	// A rolled back trader is served the baseline.
+	reg.ObserveRollout("fast", "t1", out.PromptVersion, false, 1000, time.Now())
+	_, rolled := reg.ObserveRollout("fast", "t1", out.PromptVersion, false, 900, time.Now())
+	require.True(t, rolled)
+	out, err = exec.GetFullDecision(&Context{CurrentTime: "2025-01-01T00:00:00Z", CallCount: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "default-v1", out.PromptVersion)
+	assert.Equal(t, "fast", out.PromptRollout)
+}
@@ -0,0 +1,177 @@
+// Package flags gates risky behaviours behind feature flags that can be
+// switched per environment, model alias or trader without a deploy.
+//
+// Code declares each flag it checks with Define, which records the default
+// used while the flag is not configured. Configured flags come from a Source
+// (a YAML file or the feature_flags table) and are evaluated against a
+// Scope: the first rule whose lists all match decides, otherwise the flag's
+// own Enabled, otherwise the declared default. A Store reloads its source
+// periodically so flips take effect on the next check.
+package flags
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Definition is a flag declared by the code that checks it.
+type Definition struct {
+	Name        string
+	Description string
+	Default     bool
+}
+
+var (
+	defsMu sync.RWMutex
+	defs   = map[string]Definition{}
+)
+
+// Define declares a flag and returns its name, so packages can keep the name
+// in a variable:
+//
+//	var FlagTWAP = flags.Define("twap_execution", "Split large opens into slices", false)
+//
+// Defining a name twice panics.
+func Define(name, description string, def bool) string {
+	name = strings.TrimSpace(name)
+	defsMu.Lock()
+	defer defsMu.Unlock()
+	if _, dup := defs[name]; dup {
+		panic(fmt.Sprintf("flags: %q defined twice", name))
+	}
+	defs[name] = Definition{Name: name, Description: description, Default: def}
+	return name
+}
+
+// Definitions lists the declared flags ordered by name.
+func Definitions() []Definition {
+	defsMu.RLock()
+	defer defsMu.RUnlock()
+	out := make([]Definition, 0, len(defs))
+	for _, d := range defs {
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Default is the declared default of name; undeclared flags are off.
+func Default(name string) bool {
+	d, _ := definition(name)
+	return d.Default
+}
+
+func definition(name string) (Definition, bool) {
+	defsMu.RLock()
+	defer defsMu.RUnlock()
+	d, ok := defs[name]
+	return d, ok
+}
+
+// Scope is what a flag is checked for. Empty fields only match rules that
+// do not restrict them.
+type Scope struct {
+	Env    string
+	Model  string
+	Trader string
+}
+
+// Rule turns a flag on or off where all of its lists match; an empty list
+// matches anything.
+type Rule struct {
+	Envs    []string `yaml:"envs" json:"envs,omitempty"`
+	Models  []string `yaml:"models" json:"models,omitempty"`
+	Traders []string `yaml:"traders" json:"traders,omitempty"`
+	Enabled bool     `yaml:"enabled" json:"enabled"`
+}
+
+func (r Rule) matches(s Scope) bool {
+	return matches(r.Envs, s.Env) && matches(r.Models, s.Model) && matches(r.Traders, s.Trader)
+}
+
+// Flag is the configured state of one flag.
+type Flag struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	// Enabled applies where no rule matches; nil keeps the declared default.
+	Enabled *bool  `yaml:"enabled" json:"enabled,omitempty"`
+	Rules   []Rule `yaml:"rules" json:"rules,omitempty"`
+}
+
+// Set is a validated, immutable collection of configured flags.
+type Set struct {
+	flags map[string]Flag
+}
+
+// NewSet validates flags: names are required and unique.
+func NewSet(flags []Flag) (*Set, error) {
+	s := &Set{flags: make(map[string]Flag, len(flags))}
+	for i, f := range flags {
+		f.Name = strings.TrimSpace(f.Name)
+		if f.Name == "" {
+			return nil, fmt.Errorf("feature flags[%d]: name is required", i)
+		}
+		if _, dup := s.flags[f.Name]; dup {
+			return nil, fmt.Errorf("feature flag %q: duplicate name", f.Name)
+		}
+		s.flags[f.Name] = f
+	}
+	return s, nil
+}
+
+// Enabled evaluates name for scope. Flags neither configured nor defined are
+// off.
+func (s *Set) Enabled(name string, scope Scope) bool {
+	if s != nil {
+		if f, ok := s.flags[name]; ok {
+			for _, r := range f.Rules {
+				if r.matches(scope) {
+					return r.Enabled
+				}
+			}
+			if f.Enabled != nil {
+				return *f.Enabled
+			}
+		}
+	}
+	return Default(name)
+}
+
+// Flags lists the configured flags ordered by name.
+func (s *Set) Flags() []Flag {
+	if s == nil {
+		return nil
+	}
+	out := make([]Flag, 0, len(s.flags))
+	for _, f := range s.flags {
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Undefined lists configured flags no code has declared, usually typos or
+// flags of removed features.
+func (s *Set) Undefined() []string {
+	var out []string
+	for _, f := range s.Flags() {
+		if _, ok := definition(f.Name); !ok {
+			out = append(out, f.Name)
+		}
+	}
+	return out
+}
+
+func matches(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(strings.TrimSpace(a), value) {
+			return true
+		}
+	}
+	return false
+}
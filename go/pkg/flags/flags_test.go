@@ -0,0 +1,85 @@
+package flags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testFlagOn  = Define("test_default_on", "Defaults on", true)
+	testFlagOff = Define("test_default_off", "Defaults off", false)
+)
+
+func TestSetEnabledRulesAndDefaults(t *testing.T) {
+	off := false
+	set, err := NewSet([]Flag{
+		{
+			Name: testFlagOff,
+			Rules: []Rule{
+				{Envs: []string{"prod"}, Models: []string{"gpt-5"}, Enabled: false},
+				{Envs: []string{"dev"}, Enabled: true},
+				{Models: []string{"GPT-5"}, Enabled: true},
+			},
+		},
+		{Name: testFlagOn, Enabled: &off, Rules: []Rule{{Traders: []string{"t1"}, Enabled: true}}},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, set.Enabled(testFlagOff, Scope{Env: "dev", Model: "qwen"}))
+	assert.True(t, set.Enabled(testFlagOff, Scope{Env: "test", Model: "gpt-5"}))
+	assert.False(t, set.Enabled(testFlagOff, Scope{Env: "prod", Model: "gpt-5"}), "first matching rule wins")
+	assert.False(t, set.Enabled(testFlagOff, Scope{Env: "prod", Model: "qwen"}), "declared default")
+
+	assert.True(t, set.Enabled(testFlagOn, Scope{Trader: "t1"}))
+	assert.False(t, set.Enabled(testFlagOn, Scope{Trader: "t2"}), "configured enabled overrides the default")
+
+	var empty *Set
+	assert.True(t, empty.Enabled(testFlagOn, Scope{}))
+	assert.False(t, empty.Enabled("never_defined", Scope{}))
+
+	_, err = NewSet([]Flag{{Name: "a"}, {Name: " a "}})
+	assert.ErrorContains(t, err, "duplicate name")
+	_, err = NewSet([]Flag{{}})
+	assert.ErrorContains(t, err, "name is required")
+}
+
+func TestDefineTwicePanics(t *testing.T) {
+	assert.Panics(t, func() { Define(testFlagOn, "again", false) })
+	names := map[string]bool{}
+	for _, d := range Definitions() {
+		names[d.Name] = true
+	}
+	assert.True(t, names[testFlagOn] && names[testFlagOff])
+}
+
+func TestStoreReloadsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feature_flags.yaml")
+	store, err := NewStore(context.Background(), FileSource{Path: path}, "prod")
+	require.NoError(t, err, "a missing file configures nothing")
+	assert.False(t, store.Enabled(testFlagOff, "gpt-5", "t1"))
+
+	require.NoError(t, os.WriteFile(path, []byte(`
+flags:
+  - name: test_default_off
+    rules:
+      - {envs: [prod], models: [gpt-5], enabled: true}
+  - name: typo_flag
+    enabled: true
+`), 0o644))
+	require.NoError(t, store.Reload(context.Background()))
+	assert.True(t, store.Enabled(testFlagOff, "gpt-5", "t1"))
+	assert.False(t, store.Enabled(testFlagOff, "qwen", "t1"))
+	assert.Equal(t, []string{"typo_flag"}, store.Snapshot().Undefined())
+
+	require.NoError(t, os.WriteFile(path, []byte("flags: [{name: x}, {name: x}]"), 0o644))
+	assert.Error(t, store.Reload(context.Background()))
+	assert.True(t, store.Enabled(testFlagOff, "gpt-5", "t1"), "a bad reload keeps the last flags")
+
+	var nilStore *Store
+	assert.True(t, nilStore.Enabled(testFlagOn, "gpt-5", "t1"))
+}
@@ -0,0 +1,85 @@
+package flags
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources selectable in configuration.
+const (
+	SourceFile     = "file"
+	SourcePostgres = "postgres"
+)
+
+// FileSource reads flags from a YAML file with a top-level flags list. A
+// missing file configures no flags.
+type FileSource struct {
+	Path string
+}
+
+// Load implements Source.
+func (f FileSource) Load(context.Context) ([]Flag, error) {
+	raw, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("flags: read %s: %w", f.Path, err)
+	}
+	var doc struct {
+		Flags []Flag `yaml:"flags"`
+	}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("flags: decode %s: %w", f.Path, err)
+	}
+	return doc.Flags, nil
+}
+
+// SQLSource reads flags from the feature_flags table (migration
+// 009_feature_flags), whose rules column holds the JSON rule list.
+type SQLSource struct {
+	conn sqlx.SqlConn
+}
+
+// NewSQLSource reads flags through conn.
+func NewSQLSource(conn sqlx.SqlConn) *SQLSource {
+	return &SQLSource{conn: conn}
+}
+
+type flagRow struct {
+	Name        string       `db:"name"`
+	Description string       `db:"description"`
+	Enabled     sql.NullBool `db:"enabled"`
+	Rules       string       `db:"rules"`
+}
+
+// Load implements Source.
+func (s *SQLSource) Load(ctx context.Context) ([]Flag, error) {
+	if s == nil || s.conn == nil {
+		return nil, errors.New("flags: nil sql conn")
+	}
+	var rows []flagRow
+	if err := s.conn.QueryRowsCtx(ctx, &rows, `SELECT name, description, enabled, rules::text AS rules FROM feature_flags ORDER BY name`); err != nil {
+		return nil, fmt.Errorf("flags: query feature_flags: %w", err)
+	}
+	out := make([]Flag, 0, len(rows))
+	for _, row := range rows {
+		f := Flag{Name: row.Name, Description: row.Description}
+		if row.Enabled.Valid {
+			enabled := row.Enabled.Bool
+			f.Enabled = &enabled
+		}
+		if err := json.Unmarshal([]byte(row.Rules), &f.Rules); err != nil {
+			return nil, fmt.Errorf("flags: feature flag %q rules: %w", row.Name, err)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
@@ -0,0 +1,116 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const defaultRefresh = 30 * time.Second
+
+// Source loads the configured flags.
+type Source interface {
+	Load(ctx context.Context) ([]Flag, error)
+}
+
+// Store serves flag checks for one environment from the last good load of
+// its source.
+type Store struct {
+	source Source
+	env    string
+	set    atomic.Pointer[Set]
+}
+
+// NewStore loads source once; env fills the Env of every checked scope.
+func NewStore(ctx context.Context, source Source, env string) (*Store, error) {
+	if source == nil {
+		return nil, errors.New("flags: nil source")
+	}
+	s := &Store{source: source, env: env}
+	if err := s.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Enabled evaluates name for model and trader in the store's environment. A
+// nil store answers with the declared defaults.
+func (s *Store) Enabled(name, model, trader string) bool {
+	if s == nil {
+		return Default(name)
+	}
+	return s.set.Load().Enabled(name, Scope{Env: s.env, Model: model, Trader: trader})
+}
+
+// Snapshot returns the flags currently in effect.
+func (s *Store) Snapshot() *Set {
+	if s == nil {
+		return nil
+	}
+	return s.set.Load()
+}
+
+// Reload loads the source and swaps in the result. On error the previous
+// flags stay in effect.
+func (s *Store) Reload(ctx context.Context) error {
+	loaded, err := s.source.Load(ctx)
+	if err != nil {
+		return err
+	}
+	next, err := NewSet(loaded)
+	if err != nil {
+		return err
+	}
+	prev := s.set.Swap(next)
+	for _, name := range changed(prev, next) {
+		logx.Infof("flags: %s changed", name)
+	}
+	if prev == nil {
+		for _, name := range next.Undefined() {
+			logx.Slowf("flags: %s is configured but not defined by any check", name)
+		}
+	}
+	return nil
+}
+
+// Run reloads every interval (30s when zero) until ctx is done.
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultRefresh
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Reload(ctx); err != nil {
+				logx.Errorf("flags: reload: %v", err)
+			}
+		}
+	}
+}
+
+// changed names the flags added, removed or edited between two sets.
+func changed(prev, next *Set) []string {
+	if prev == nil {
+		return nil
+	}
+	var out []string
+	for _, f := range next.Flags() {
+		if old, ok := prev.flags[f.Name]; !ok || !reflect.DeepEqual(old, f) {
+			out = append(out, f.Name)
+		}
+	}
+	for _, f := range prev.Flags() {
+		if _, ok := next.flags[f.Name]; !ok {
+			out = append(out, f.Name)
+		}
+	}
+	return out
+}
@@ -15,7 +15,9 @@ type CycleRecord struct {
 	ConfigVersion int64                  `json:"config_version,omitempty"`
 	CycleNumber   int                    `json:"cycle_number"`
 	PromptDigest  string                 `json:"prompt_digest,omitempty"`
+	PromptVersion string                 `json:"prompt_version,omitempty"`
 	CoTTrace      string                 `json:"cot_trace,omitempty"`
+	Response      string                 `json:"response,omitempty"`
 	DecisionsJSON string                 `json:"decisions_json,omitempty"`
 	Account       map[string]any         `json:"account_snapshot,omitempty"`
 	Positions     []map[string]any       `json:"positions_snapshot,omitempty"`
@@ -0,0 +1,192 @@
+// Package leader elects, per key, the one process allowed to act on it, so
+// several cmd/llm instances can run for availability while exactly one
+// drives each trader's decision cycles.
+//
+// Leadership is a lease held in a shared Backend and renewed well before it
+// expires; when the holder stops renewing, another instance takes the key
+// over. Every change of holder increments the key's fencing token. Callers
+// carry the token into side effects and check it with Validate right before
+// acting, so a paused former leader whose lease already moved on cannot
+// submit orders with a stale token.
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Backend names.
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+
+	defaultTTL = 15 * time.Second
+)
+
+// ErrNotLeader is returned when the caller does not hold the lease on a key,
+// or holds it under a different fencing token.
+var ErrNotLeader = errors.New("leader: not the leader")
+
+// Backend stores leases shared by all instances.
+type Backend interface {
+	// Acquire takes the lease on key for owner, or renews it when owner
+	// already holds it, for ttl. It returns the lease's fencing token and
+	// whether owner holds the lease.
+	Acquire(ctx context.Context, key, owner string, ttl time.Duration) (int64, bool, error)
+	// Release gives up the lease on key if owner holds it.
+	Release(ctx context.Context, key, owner string) error
+	// Validate returns ErrNotLeader unless owner holds key under token.
+	Validate(ctx context.Context, key, owner string, token int64) error
+}
+
+// Elector campaigns for a set of keys and reports which of them this
+// instance currently leads.
+type Elector struct {
+	backend Backend
+	owner   string
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu     sync.RWMutex
+	leases map[string]lease
+}
+
+type lease struct {
+	token int64
+	// until is when this instance stops trusting the lease locally, a
+	// margin before the backend can hand it to someone else.
+	until time.Time
+}
+
+// NewElector returns an elector holding leases in backend as owner. A
+// non-positive ttl uses 15s and an empty owner uses hostname and pid.
+func NewElector(backend Backend, owner string, ttl time.Duration) (*Elector, error) {
+	if backend == nil {
+		return nil, errors.New("leader: backend is required")
+	}
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	owner = strings.TrimSpace(owner)
+	if owner == "" {
+		owner = DefaultOwner()
+	}
+	return &Elector{
+		backend: backend,
+		owner:   owner,
+		ttl:     ttl,
+		now:     time.Now,
+		leases:  make(map[string]lease),
+	}, nil
+}
+
+// DefaultOwner identifies this process by hostname and pid.
+func DefaultOwner() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Owner returns the identity this elector campaigns under.
+func (e *Elector) Owner() string {
+	return e.owner
+}
+
+// Campaign tries once to acquire or renew the lease on every key. Keys whose
+// attempt fails are dropped locally, so a backend outage stops this
+// instance from acting instead of letting two leaders overlap.
+func (e *Elector) Campaign(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		start := e.now()
+		token, ok, err := e.backend.Acquire(ctx, key, e.owner, e.ttl)
+		if err != nil {
+			logx.WithContext(ctx).Errorf("leader: campaign key=%s owner=%s err=%v", key, e.owner, err)
+			ok = false
+		}
+		e.mu.Lock()
+		prev, held := e.leases[key]
+		if ok {
+			e.leases[key] = lease{token: token, until: start.Add(e.ttl - e.ttl/5)}
+		} else {
+			delete(e.leases, key)
+		}
+		e.mu.Unlock()
+		switch {
+		case ok && (!held || prev.token != token):
+			logx.WithContext(ctx).Infof("leader: %s now leads %s token=%d", e.owner, key, token)
+		case !ok && held:
+			logx.WithContext(ctx).Infof("leader: %s lost %s token=%d", e.owner, key, prev.token)
+		}
+	}
+}
+
+// Run campaigns for keys() every third of the lease TTL until ctx ends, then
+// releases every lease it holds so another instance can take over at once.
+func (e *Elector) Run(ctx context.Context, keys func() []string) {
+	e.Campaign(ctx, keys())
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			e.releaseAll()
+			return
+		case <-ticker.C:
+			e.Campaign(ctx, keys())
+		}
+	}
+}
+
+// Leading reports whether this instance leads key and the fencing token of
+// its lease.
+func (e *Elector) Leading(key string) (int64, bool) {
+	e.mu.RLock()
+	l, ok := e.leases[key]
+	e.mu.RUnlock()
+	if !ok || !e.now().Before(l.until) {
+		return 0, false
+	}
+	return l.token, true
+}
+
+// Validate checks with the backend that this instance still holds key under
+// token, returning ErrNotLeader otherwise.
+func (e *Elector) Validate(ctx context.Context, key string, token int64) error {
+	if err := e.backend.Validate(ctx, key, e.owner, token); err != nil {
+		if errors.Is(err, ErrNotLeader) {
+			e.mu.Lock()
+			if l, ok := e.leases[key]; ok && l.token == token {
+				delete(e.leases, key)
+			}
+			e.mu.Unlock()
+		}
+		return err
+	}
+	return nil
+}
+
+func (e *Elector) releaseAll() {
+	e.mu.Lock()
+	keys := make([]string, 0, len(e.leases))
+	for key := range e.leases {
+		keys = append(keys, key)
+	}
+	e.leases = make(map[string]lease)
+	e.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, key := range keys {
+		if err := e.backend.Release(ctx, key, e.owner); err != nil {
+			logx.WithContext(ctx).Errorf("leader: release key=%s owner=%s err=%v", key, e.owner, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestElectorFailoverIncrementsFence(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{t: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	backend := NewMemoryBackend()
+	backend.now = clock.now
+	newElector := func(owner string) *Elector {
+		e, err := NewElector(backend, owner, 15*time.Second)
+		require.NoError(t, err)
+		e.now = clock.now
+		return e
+	}
+	a, b := newElector("a"), newElector("b")
+	keys := []string{"trader-1"}
+
+	a.Campaign(ctx, keys)
+	b.Campaign(ctx, keys)
+	tokenA, ok := a.Leading("trader-1")
+	require.True(t, ok, "first campaigner leads")
+	_, ok = b.Leading("trader-1")
+	assert.False(t, ok, "exactly one leader per key")
+	require.NoError(t, a.Validate(ctx, "trader-1", tokenA))
+
+	// Renewal keeps the token.
+	clock.advance(5 * time.Second)
+	a.Campaign(ctx, keys)
+	renewed, ok := a.Leading("trader-1")
+	require.True(t, ok)
+	assert.Equal(t, tokenA, renewed)
+
+	// a stops renewing: its local lease lapses before the backend hands the
+	// key to b, and b's token fences a's writes.
+	clock.advance(12 * time.Second)
+	_, ok = a.Leading("trader-1")
+	assert.False(t, ok, "local lease ends before the backend lease")
+	clock.advance(4 * time.Second)
+	b.Campaign(ctx, keys)
+	tokenB, ok := b.Leading("trader-1")
+	require.True(t, ok, "b takes over after the ttl")
+	assert.Greater(t, tokenB, tokenA)
+	assert.ErrorIs(t, a.Validate(ctx, "trader-1", tokenA), ErrNotLeader)
+	require.NoError(t, b.Validate(ctx, "trader-1", tokenB))
+
+	a.Campaign(ctx, keys)
+	_, ok = a.Leading("trader-1")
+	assert.False(t, ok)
+}
+
+func TestElectorRunReleasesOnStop(t *testing.T) {
+	backend := NewMemoryBackend()
+	a, err := NewElector(backend, "a", 30*time.Millisecond)
+	require.NoError(t, err)
+	b, err := NewElector(backend, "b", time.Hour)
+	require.NoError(t, err)
+	keys := func() []string { return []string{"trader-1"} }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.Run(ctx, keys)
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		_, ok := a.Leading("trader-1")
+		return ok
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	_, ok := a.Leading("trader-1")
+	assert.False(t, ok)
+	b.Campaign(context.Background(), keys())
+	_, ok = b.Leading("trader-1")
+	assert.True(t, ok, "released lease is taken over without waiting for expiry")
+}
+
+func TestMemoryBackendReleaseIgnoresOtherOwners(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryBackend()
+	token, ok, err := backend.Acquire(ctx, "k", "a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, backend.Release(ctx, "k", "b"))
+	require.NoError(t, backend.Validate(ctx, "k", "a", token))
+	assert.ErrorIs(t, backend.Validate(ctx, "k", "a", token+1), ErrNotLeader)
+}
+
+func TestRedisKeysShareHashTag(t *testing.T) {
+	lease, fence := redisKeys("trader-1")
+	assert.Equal(t, "nof0:leader:{trader-1}", lease)
+	assert.Equal(t, "nof0:leader:{trader-1}:fence", fence)
+}
@@ -0,0 +1,70 @@
+package leader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBackend keeps leases in process memory. It coordinates electors
+// sharing one instance, which makes it suitable for tests and single-host
+// runs only.
+type MemoryBackend struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	leases map[string]memoryLease
+	fences map[string]int64
+}
+
+type memoryLease struct {
+	owner   string
+	token   int64
+	expires time.Time
+}
+
+// NewMemoryBackend returns an empty in-process lease store.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		now:    time.Now,
+		leases: make(map[string]memoryLease),
+		fences: make(map[string]int64),
+	}
+}
+
+func (b *MemoryBackend) Acquire(_ context.Context, key, owner string, ttl time.Duration) (int64, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	l, ok := b.leases[key]
+	if ok && now.Before(l.expires) {
+		if l.owner != owner {
+			return 0, false, nil
+		}
+		l.expires = now.Add(ttl)
+		b.leases[key] = l
+		return l.token, true, nil
+	}
+	b.fences[key]++
+	l = memoryLease{owner: owner, token: b.fences[key], expires: now.Add(ttl)}
+	b.leases[key] = l
+	return l.token, true, nil
+}
+
+func (b *MemoryBackend) Release(_ context.Context, key, owner string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if l, ok := b.leases[key]; ok && l.owner == owner {
+		delete(b.leases, key)
+	}
+	return nil
+}
+
+func (b *MemoryBackend) Validate(_ context.Context, key, owner string, token int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	l, ok := b.leases[key]
+	if !ok || l.owner != owner || l.token != token || !b.now().Before(l.expires) {
+		return ErrNotLeader
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+const redisKeyPrefix = "nof0:leader:"
+
+// The lease is a hash {owner, token} expiring after the TTL; the fencing
+// counter lives beside it without expiry, so tokens keep increasing across
+// leases. Both keys share a hash tag to stay in one cluster slot.
+var (
+	acquireScript = redis.NewScript(`
+local owner = redis.call('HGET', KEYS[1], 'owner')
+if owner == ARGV[1] then
+  redis.call('PEXPIRE', KEYS[1], ARGV[2])
+  return tonumber(redis.call('HGET', KEYS[1], 'token'))
+end
+if owner then
+  return 0
+end
+local token = redis.call('INCR', KEYS[2])
+redis.call('HSET', KEYS[1], 'owner', ARGV[1], 'token', token)
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return token`)
+
+	releaseScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'owner') == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0`)
+
+	validateScript = redis.NewScript(`
+local lease = redis.call('HMGET', KEYS[1], 'owner', 'token')
+if lease[1] == ARGV[1] and lease[2] == ARGV[2] then
+  return 1
+end
+return 0`)
+)
+
+// RedisBackend keeps leases in Redis, shared by every instance pointed at
+// the same server or cluster.
+type RedisBackend struct {
+	rds *redis.Redis
+}
+
+// NewRedisBackend returns a Backend storing leases in rds.
+func NewRedisBackend(rds *redis.Redis) *RedisBackend {
+	return &RedisBackend{rds: rds}
+}
+
+func (b *RedisBackend) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (int64, bool, error) {
+	lease, fence := redisKeys(key)
+	res, err := b.rds.ScriptRunCtx(ctx, acquireScript, []string{lease, fence}, owner, ttl.Milliseconds())
+	if err != nil {
+		return 0, false, fmt.Errorf("leader: acquire %s: %w", key, err)
+	}
+	token, ok := res.(int64)
+	if !ok {
+		return 0, false, fmt.Errorf("leader: acquire %s: unexpected reply %T", key, res)
+	}
+	return token, token > 0, nil
+}
+
+func (b *RedisBackend) Release(ctx context.Context, key, owner string) error {
+	lease, _ := redisKeys(key)
+	if _, err := b.rds.ScriptRunCtx(ctx, releaseScript, []string{lease}, owner); err != nil {
+		return fmt.Errorf("leader: release %s: %w", key, err)
+	}
+	return nil
+}
+
+func (b *RedisBackend) Validate(ctx context.Context, key, owner string, token int64) error {
+	lease, _ := redisKeys(key)
+	res, err := b.rds.ScriptRunCtx(ctx, validateScript, []string{lease}, owner, strconv.FormatInt(token, 10))
+	if err != nil {
+		return fmt.Errorf("leader: validate %s: %w", key, err)
+	}
+	if n, _ := res.(int64); n != 1 {
+		return ErrNotLeader
+	}
+	return nil
+}
+
+func redisKeys(key string) (lease, fence string) {
+	lease = redisKeyPrefix + "{" + key + "}"
+	return lease, lease + ":fence"
+}
@@ -17,6 +17,11 @@ type BudgetSnapshot struct {
 	UsedCostUSD       float64
 	AlertThresholdPct int
 	AlertTriggered    bool
+	// Image usage is a breakdown of UsedTokens/UsedCostUSD: providers bill
+	// image inputs as prompt tokens, so these are estimates of that share.
+	Images       int64
+	ImageTokens  int64
+	ImageCostUSD float64
 }
 
 type BudgetGuard struct {
@@ -24,6 +29,9 @@ type BudgetGuard struct {
 	mu          sync.Mutex
 	usedTokens  int64
 	usedCostUSD float64
+	images      int64
+	imageTokens int64
+	imageCost   float64
 	periodStart time.Time
 	now         func() time.Time
 }
@@ -76,6 +84,9 @@ func (g *BudgetGuard) RecordUsage(model string, tokens int64) (BudgetSnapshot, e
 		UsedCostUSD:       g.usedCostUSD,
 		AlertThresholdPct: g.cfg.AlertThresholdPct,
 		AlertTriggered:    limit > 0 && g.cfg.AlertThresholdPct > 0 && usagePct >= float64(g.cfg.AlertThresholdPct),
+		Images:            g.images,
+		ImageTokens:       g.imageTokens,
+		ImageCostUSD:      g.imageCost,
 	}
 
 	if limit > 0 && newTotal > limit && g.cfg.StrictEnforcement {
@@ -84,6 +95,31 @@ func (g *BudgetGuard) RecordUsage(model string, tokens int64) (BudgetSnapshot, e
 	return snapshot, nil
 }
 
+// RecordImages attributes an estimated share of recorded usage to image
+// inputs. It does not add to UsedTokens, which already includes them.
+func (g *BudgetGuard) RecordImages(model string, images int, tokens int64) BudgetSnapshot {
+	if g == nil || g.cfg == nil || images <= 0 {
+		return BudgetSnapshot{}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.resetIfNeeded()
+
+	g.images += int64(images)
+	g.imageTokens += tokens
+	g.imageCost += float64(tokens) / 1_000_000.0 * g.costRate(model)
+	return BudgetSnapshot{
+		UsedTokens:        g.usedTokens,
+		Limit:             g.cfg.DailyTokenLimit,
+		UsagePct:          percentage(g.usedTokens, g.cfg.DailyTokenLimit),
+		UsedCostUSD:       g.usedCostUSD,
+		AlertThresholdPct: g.cfg.AlertThresholdPct,
+		Images:            g.images,
+		ImageTokens:       g.imageTokens,
+		ImageCostUSD:      g.imageCost,
+	}
+}
+
 func (g *BudgetGuard) resetIfNeeded() {
 	now := g.nowUTC()
 	currentPeriod := truncateDay(now)
@@ -91,6 +127,9 @@ func (g *BudgetGuard) resetIfNeeded() {
 		g.periodStart = currentPeriod
 		g.usedTokens = 0
 		g.usedCostUSD = 0
+		g.images = 0
+		g.imageTokens = 0
+		g.imageCost = 0
 	}
 }
 
@@ -48,6 +48,33 @@ func TestBudgetGuardRecordUsage(t *testing.T) {
 	require.EqualValues(t, 100, snapshot.UsedTokens)
 }
 
+func TestBudgetGuardRecordImages(t *testing.T) {
+	guard := NewBudgetGuard(&BudgetConfig{
+		DailyTokenLimit:      100000,
+		CostPerMillionTokens: map[string]float64{"gpt-5": 20},
+	})
+	require.NotNil(t, guard)
+	start := time.Date(2025, 11, 8, 0, 0, 0, 0, time.UTC)
+	guard.now = func() time.Time { return start }
+
+	_, err := guard.RecordUsage("gpt-5", 5000)
+	require.NoError(t, err)
+	snapshot := guard.RecordImages("gpt-5", 2, 500)
+	require.EqualValues(t, 5000, snapshot.UsedTokens)
+	require.EqualValues(t, 2, snapshot.Images)
+	require.EqualValues(t, 500, snapshot.ImageTokens)
+	require.InDelta(t, 0.01, snapshot.ImageCostUSD, 1e-9)
+
+	snapshot, err = guard.RecordUsage("gpt-5", 100)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, snapshot.Images)
+
+	guard.now = func() time.Time { return start.Add(24 * time.Hour) }
+	snapshot = guard.RecordImages("gpt-5", 1, 85)
+	require.EqualValues(t, 1, snapshot.Images)
+	require.EqualValues(t, 85, snapshot.ImageTokens)
+}
+
 func TestBudgetGuardDisabled(t *testing.T) {
 	cfg := &BudgetConfig{DailyTokenLimit: 0}
 	guard := NewBudgetGuard(cfg)
@@ -0,0 +1,261 @@
+package llm
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	CacheBackendMemory = "memory"
+	CacheBackendRedis  = "redis"
+
+	defaultCacheTTL        = 10 * time.Minute
+	defaultCacheMaxEntries = 256
+)
+
+// CacheConfig enables response caching, so a request identical to a recent
+// one (same prompt digest, model and temperature) is answered without a
+// billed provider call.
+type CacheConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Backend is memory (per-process LRU, the default) or redis. Redis needs
+	// a store supplied through WithResponseCache.
+	Backend    string        `yaml:"backend,omitempty"`
+	TTL        time.Duration `yaml:"ttl,omitempty"`
+	MaxEntries int           `yaml:"max_entries,omitempty"` // memory backend only
+}
+
+// Active reports whether response caching is enabled.
+func (c *CacheConfig) Active() bool {
+	return c != nil && c.Enabled
+}
+
+// UsesRedis reports whether the cache is enabled with the redis backend.
+func (c *CacheConfig) UsesRedis() bool {
+	return c.Active() && c.Backend == CacheBackendRedis
+}
+
+func (c *CacheConfig) applyDefaults() {
+	if c == nil {
+		return
+	}
+	c.Backend = strings.ToLower(strings.TrimSpace(c.Backend))
+	if c.Backend == "" {
+		c.Backend = CacheBackendMemory
+	}
+	if c.TTL == 0 {
+		c.TTL = defaultCacheTTL
+	}
+	if c.MaxEntries == 0 {
+		c.MaxEntries = defaultCacheMaxEntries
+	}
+}
+
+// Validate ensures cache configuration is sane.
+func (c *CacheConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	switch c.Backend {
+	case "", CacheBackendMemory, CacheBackendRedis:
+	default:
+		return errors.New("llm config: cache.backend must be memory or redis")
+	}
+	if c.TTL < 0 {
+		return errors.New("llm config: cache.ttl cannot be negative")
+	}
+	if c.MaxEntries < 0 {
+		return errors.New("llm config: cache.max_entries cannot be negative")
+	}
+	return nil
+}
+
+// ResponseCache stores completions by request key. Get reports a miss with
+// false; errors are counted and treated as misses by the client.
+type ResponseCache interface {
+	Get(ctx context.Context, key string) (*ChatResponse, bool, error)
+	Set(ctx context.Context, key string, resp *ChatResponse, ttl time.Duration) error
+}
+
+// CacheStats counts response cache lookups since the client was created.
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+	Errors int64
+}
+
+// HitRate returns the share of lookups served from the cache.
+func (s CacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+type cacheCounters struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+func (c *cacheCounters) snapshot() CacheStats {
+	return CacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Errors: c.errors.Load(),
+	}
+}
+
+// ResponseCacheKey derives the cache key for req sent to modelID with the
+// given sampling. The digest covers the messages, response format, top_p and
+// seed, so any change to the rendered prompt, output schema or sampling is a
+// different entry.
+func ResponseCacheKey(req *ChatRequest, modelID string, sampling Sampling) string {
+	digest := sha256.New()
+	_ = json.NewEncoder(digest).Encode(struct {
+		Messages       []Message       `json:"messages"`
+		ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+		TopP           *float64        `json:"top_p,omitempty"`
+		Seed           *int64          `json:"seed,omitempty"`
+	}{req.Messages, req.ResponseFormat, sampling.TopP, sampling.Seed})
+	temp := "default"
+	if sampling.Temperature != nil {
+		temp = strconv.FormatFloat(*sampling.Temperature, 'f', -1, 64)
+	}
+	return modelID + ":" + temp + ":" + hex.EncodeToString(digest.Sum(nil))
+}
+
+// MemoryResponseCache is an in-process LRU response cache with per-entry TTL.
+type MemoryResponseCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front is most recently used
+	entries    map[string]*list.Element
+	now        func() time.Time
+}
+
+type memoryCacheEntry struct {
+	key     string
+	resp    *ChatResponse
+	expires time.Time
+}
+
+// NewMemoryResponseCache returns an LRU holding at most maxEntries responses.
+func NewMemoryResponseCache(maxEntries int) *MemoryResponseCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultCacheMaxEntries
+	}
+	return &MemoryResponseCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+func (m *MemoryResponseCache) Get(_ context.Context, key string) (*ChatResponse, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := el.Value.(*memoryCacheEntry)
+	if !entry.expires.IsZero() && !m.now().Before(entry.expires) {
+		m.order.Remove(el)
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+	m.order.MoveToFront(el)
+	return cloneResponse(entry.resp), true, nil
+}
+
+func (m *MemoryResponseCache) Set(_ context.Context, key string, resp *ChatResponse, ttl time.Duration) error {
+	if resp == nil {
+		return nil
+	}
+	entry := &memoryCacheEntry{key: key, resp: cloneResponse(resp)}
+	if ttl > 0 {
+		entry.expires = m.now().Add(ttl)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.entries[key]; ok {
+		el.Value = entry
+		m.order.MoveToFront(el)
+		return nil
+	}
+	m.entries[key] = m.order.PushFront(entry)
+	for m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of cached responses, including expired ones not
+// yet evicted.
+func (m *MemoryResponseCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+func cloneResponse(resp *ChatResponse) *ChatResponse {
+	cp := *resp
+	cp.Choices = append([]Choice(nil), resp.Choices...)
+	return &cp
+}
+
+// cachedResponse returns the response stored under key, marked as cached
+// and carrying no usage since nothing was billed.
+func (c *Client) cachedResponse(ctx context.Context, key, modelID string) *ChatResponse {
+	if c.cache == nil {
+		return nil
+	}
+	resp, ok, err := c.cache.Get(ctx, key)
+	if err != nil {
+		c.cacheStats.errors.Add(1)
+		c.logger.Warn(ctx, "llm cache lookup failed", Fields{"model": modelID, "error": err.Error()})
+	}
+	if !ok || resp == nil {
+		c.cacheStats.misses.Add(1)
+		return nil
+	}
+	hits := c.cacheStats.hits.Add(1)
+	c.logger.Info(ctx, "llm cache hit", Fields{
+		"model":        modelID,
+		"saved_tokens": resp.Usage.TotalTokens,
+		"hits":         hits,
+	})
+	resp.Cached = true
+	resp.Usage = Usage{}
+	return resp
+}
+
+func (c *Client) storeResponse(ctx context.Context, key string, resp *ChatResponse) {
+	if c.cache == nil || resp == nil {
+		return
+	}
+	if err := c.cache.Set(ctx, key, resp, c.cacheTTL); err != nil {
+		c.cacheStats.errors.Add(1)
+		c.logger.Warn(ctx, "llm cache store failed", Fields{"model": resp.Model, "error": err.Error()})
+	}
+}
+
+// CacheStats returns response cache hit/miss counters; all zero when caching
+// is disabled.
+func (c *Client) CacheStats() CacheStats {
+	return c.cacheStats.snapshot()
+}
@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+const redisCacheKeyPrefix = "nof0:llm:response:"
+
+// RedisResponseCache shares cached responses between processes through
+// Redis, so restarts and replicas reuse each other's completions.
+type RedisResponseCache struct {
+	rds *redis.Redis
+}
+
+// NewRedisResponseCache returns a ResponseCache backed by rds.
+func NewRedisResponseCache(rds *redis.Redis) *RedisResponseCache {
+	return &RedisResponseCache{rds: rds}
+}
+
+func (r *RedisResponseCache) Get(ctx context.Context, key string) (*ChatResponse, bool, error) {
+	raw, err := r.rds.GetCtx(ctx, redisCacheKeyPrefix+key)
+	if err != nil || raw == "" {
+		return nil, false, err
+	}
+	var resp ChatResponse
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, false, err
+	}
+	return &resp, true, nil
+}
+
+func (r *RedisResponseCache) Set(ctx context.Context, key string, resp *ChatResponse, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return r.rds.SetCtx(ctx, redisCacheKeyPrefix+key, string(data))
+	}
+	seconds := int((ttl + time.Second - 1) / time.Second)
+	return r.rds.SetexCtx(ctx, redisCacheKeyPrefix+key, string(data), seconds)
+}
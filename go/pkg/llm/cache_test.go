@@ -0,0 +1,109 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryResponseCacheEvictsAndExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	cache := NewMemoryResponseCache(2)
+	cache.now = func() time.Time { return now }
+
+	require.NoError(t, cache.Set(ctx, "a", &ChatResponse{ID: "a"}, time.Minute))
+	require.NoError(t, cache.Set(ctx, "b", &ChatResponse{ID: "b"}, time.Minute))
+	_, ok, _ := cache.Get(ctx, "a") // a becomes most recently used
+	require.True(t, ok)
+	require.NoError(t, cache.Set(ctx, "c", &ChatResponse{ID: "c"}, time.Minute))
+
+	_, ok, _ = cache.Get(ctx, "b")
+	assert.False(t, ok, "least recently used entry is evicted")
+	assert.Equal(t, 2, cache.Len())
+
+	now = now.Add(time.Minute)
+	_, ok, _ = cache.Get(ctx, "a")
+	assert.False(t, ok, "expired at ttl")
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestResponseCacheKey(t *testing.T) {
+	req := &ChatRequest{Messages: []Message{{Role: "system", Content: "prompt"}}}
+	low, high := 0.2, 0.7
+	seed := int64(7)
+	key := ResponseCacheKey(req, "openai/gpt-5", Sampling{Temperature: &low})
+
+	assert.Equal(t, key, ResponseCacheKey(&ChatRequest{Messages: []Message{{Role: "system", Content: "prompt"}}}, "openai/gpt-5", Sampling{Temperature: &low}))
+	assert.NotEqual(t, key, ResponseCacheKey(req, "openai/gpt-5", Sampling{Temperature: &high}))
+	assert.NotEqual(t, key, ResponseCacheKey(req, "openai/gpt-5", Sampling{Temperature: &low, Seed: &seed}))
+	assert.NotEqual(t, key, ResponseCacheKey(req, "deepseek/deepseek-chat-v3.1", Sampling{Temperature: &low}))
+	assert.NotEqual(t, key, ResponseCacheKey(&ChatRequest{Messages: []Message{{Role: "system", Content: "prompt 2"}}}, "openai/gpt-5", Sampling{Temperature: &low}))
+	assert.True(t, strings.HasPrefix(key, "openai/gpt-5:0.2:"))
+}
+
+func TestClientChatServesRepeatsFromCache(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","created":1730366400,"model":"openai/gpt-5",
+			"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"hold"}}],
+			"usage":{"prompt_tokens":10,"completion_tokens":2,"total_tokens":12}}`))
+	}))
+	defer server.Close()
+
+	cfg := &Config{
+		BaseURL:      server.URL,
+		APIKey:       "test-key",
+		DefaultModel: "gpt-5",
+		Timeout:      5 * time.Second,
+		MaxRetries:   1,
+		LogLevel:     "error",
+		Models:       map[string]ModelConfig{"gpt-5": {Provider: "openai", ModelName: "openai/gpt-5"}},
+		Cache:        &CacheConfig{Enabled: true},
+	}
+	client, err := NewClient(cfg, WithHTTPClient(server.Client()))
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	req := &ChatRequest{Messages: []Message{{Role: "user", Content: "decide"}}}
+	first, err := client.Chat(ctx, req)
+	require.NoError(t, err)
+	assert.False(t, first.Cached)
+	assert.Equal(t, 12, first.Usage.TotalTokens)
+
+	second, err := client.Chat(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, second.Cached)
+	assert.Equal(t, "hold", second.Choices[0].Message.Content)
+	assert.Zero(t, second.Usage.TotalTokens, "cached responses are not billed")
+
+	temp := 0.9
+	_, err = client.Chat(ctx, &ChatRequest{Messages: req.Messages, Temperature: &temp})
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 2, calls.Load())
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 2}, client.CacheStats())
+}
+
+func TestLoadConfigCache(t *testing.T) {
+	t.Setenv(envAPIKey, "key")
+	cfg, err := LoadConfigFromReader(strings.NewReader("default_model: gpt-5\ncache:\n  enabled: true\n  ttl: 90s\n"))
+	require.NoError(t, err)
+	require.True(t, cfg.Cache.Active())
+	assert.Equal(t, CacheBackendMemory, cfg.Cache.Backend)
+	assert.Equal(t, 90*time.Second, cfg.Cache.TTL)
+	assert.Equal(t, defaultCacheMaxEntries, cfg.Cache.MaxEntries)
+
+	_, err = LoadConfigFromReader(strings.NewReader("default_model: gpt-5\ncache:\n  enabled: true\n  backend: memcached\n"))
+	assert.EqualError(t, err, "llm config: cache.backend must be memory or redis")
+}
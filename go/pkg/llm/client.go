@@ -40,6 +40,9 @@ type Client struct {
 	retryHandler *RetryHandler
 	httpClient   *http.Client
 	budget       *BudgetGuard
+	cache        ResponseCache
+	cacheTTL     time.Duration
+	cacheStats   cacheCounters
 	// defaultRouting is applied when using zenmux/auto with no explicit Routing provided
 	defaultRouting *RoutingConfig
 }
@@ -52,6 +55,7 @@ type clientOptions struct {
 	retry        *RetryHandler
 	httpClient   *http.Client
 	openaiClient *openai.Client
+	cache        ResponseCache
 }
 
 // WithLogger injects a custom logger implementation.
@@ -82,6 +86,14 @@ func WithOpenAIClient(client *openai.Client) ClientOption {
 	}
 }
 
+// WithResponseCache supplies the response cache store, e.g. a
+// RedisResponseCache. It is used only when the config enables caching.
+func WithResponseCache(cache ResponseCache) ClientOption {
+	return func(opts *clientOptions) {
+		opts.cache = cache
+	}
+}
+
 // NewClient constructs a new LLM client using the provided configuration.
 func NewClient(cfg *Config, opts ...ClientOption) (*Client, error) {
 	if cfg == nil {
@@ -146,6 +158,17 @@ func NewClient(cfg *Config, opts ...ClientOption) (*Client, error) {
 		httpClient:   optState.httpClient,
 		budget:       budgetGuard,
 	}
+	if clientCfg.Cache.Active() {
+		clientCfg.Cache.applyDefaults()
+		c.cache = optState.cache
+		if c.cache == nil {
+			if clientCfg.Cache.UsesRedis() {
+				logger.Warn(context.Background(), "llm cache backend redis has no store; using memory", nil)
+			}
+			c.cache = NewMemoryResponseCache(clientCfg.Cache.MaxEntries)
+		}
+		c.cacheTTL = clientCfg.Cache.TTL
+	}
 
 	// NOTE: zenmux/auto routing is currently unstable (returns HTTP 500).
 	// This code is retained for future use when the API is fixed.
@@ -186,6 +209,17 @@ func (c *Client) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, err
 	if req == nil {
 		return nil, errors.New("llm: request cannot be nil")
 	}
+	params, modelAlias, modelID, err := c.buildChatParams(req)
+	if err != nil {
+		return nil, err
+	}
+	var cacheKey string
+	if c.cache != nil {
+		cacheKey = ResponseCacheKey(req, modelID, c.effectiveSampling(req, modelAlias))
+		if cached := c.cachedResponse(ctx, cacheKey, modelID); cached != nil {
+			return cached, nil
+		}
+	}
 	if c.budget != nil {
 		if err := c.budget.AllowAttempt(); err != nil {
 			c.logger.Warn(ctx, "llm budget exhausted", Fields{
@@ -194,10 +228,17 @@ func (c *Client) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, err
 			return nil, err
 		}
 	}
-	params, modelAlias, modelID, err := c.buildChatParams(req)
+	resp, err := c.chat(ctx, req, params, modelAlias, modelID)
 	if err != nil {
 		return nil, err
 	}
+	resp.Sampling = c.effectiveSampling(req, modelAlias)
+	c.storeResponse(ctx, cacheKey, resp)
+	return resp, nil
+}
+
+// chat sends a request that missed the response cache.
+func (c *Client) chat(ctx context.Context, req *ChatRequest, params openai.ChatCompletionNewParams, modelAlias, modelID string) (*ChatResponse, error) {
 
 	c.logger.Info(ctx, "llm chat request", Fields{
 		"model":    modelID,
@@ -213,6 +254,8 @@ func (c *Client) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, err
 		if reqCopy.Routing == nil && c.defaultRouting != nil {
 			reqCopy.Routing = c.defaultRouting
 		}
+		sampling := c.effectiveSampling(req, modelAlias)
+		reqCopy.Temperature, reqCopy.TopP, reqCopy.Seed = sampling.Temperature, sampling.TopP, sampling.Seed
 		return c.chatRaw(ctx, &reqCopy, modelID)
 	}
 
@@ -223,7 +266,7 @@ func (c *Client) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, err
 	})
 
 	var completion *openai.ChatCompletion
-	err = c.retryHandler.Do(ctx, func() error {
+	err := c.retryHandler.Do(ctx, func() error {
 		resp, callErr := c.openaiClient.Chat.Completions.New(ctx, params)
 		if callErr != nil {
 			c.logger.Error(ctx, fmt.Errorf("chat completion failed: %w", callErr), Fields{
@@ -267,6 +310,16 @@ func (c *Client) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, err
 				})
 				return nil, err
 			}
+			if images, imageTokens := EstimateMessageImageTokens(req.Messages); images > 0 {
+				imageUsage := c.budget.RecordImages(modelAlias, images, int64(imageTokens))
+				c.logger.Info(ctx, "llm image usage", Fields{
+					"model":          modelAlias,
+					"images":         images,
+					"image_tokens":   imageTokens,
+					"image_cost_usd": imageUsage.ImageCostUSD,
+					"images_today":   imageUsage.Images,
+				})
+			}
 			if snapshot.AlertTriggered {
 				c.logger.Warn(ctx, "llm budget nearing limit", Fields{
 					"model":               modelAlias,
@@ -299,6 +352,9 @@ func (c *Client) chatRaw(ctx context.Context, req *ChatRequest, modelID string)
 		if m.Content != "" {
 			item["content"] = m.Content
 		}
+		if role == "user" && len(m.Images) > 0 {
+			item["content"] = rawContentParts(m)
+		}
 		switch role {
 		case "function":
 			if m.Name != "" {
@@ -329,6 +385,9 @@ func (c *Client) chatRaw(ctx context.Context, req *ChatRequest, modelID string)
 	if req.TopP != nil {
 		body["top_p"] = *req.TopP
 	}
+	if req.Seed != nil {
+		body["seed"] = *req.Seed
+	}
 	if req.Routing != nil {
 		body["model_routing_config"] = req.Routing
 	}
@@ -392,6 +451,22 @@ func (c *Client) chatRaw(ctx context.Context, req *ChatRequest, modelID string)
 	return convertCompletion(completion), nil
 }
 
+// rawContentParts renders a user message with images as OpenAI content parts.
+func rawContentParts(m Message) []map[string]any {
+	parts := make([]map[string]any, 0, len(m.Images)+1)
+	if m.Content != "" {
+		parts = append(parts, map[string]any{"type": "text", "text": m.Content})
+	}
+	for _, img := range m.Images {
+		imageURL := map[string]any{"url": img.URL}
+		if img.Detail != "" {
+			imageURL["detail"] = strings.ToLower(img.Detail)
+		}
+		parts = append(parts, map[string]any{"type": "image_url", "image_url": imageURL})
+	}
+	return parts
+}
+
 func ifEmptyString(s, fallback string) string {
 	if strings.TrimSpace(s) == "" {
 		return fallback
@@ -413,6 +488,9 @@ func summarizeMessages(msgs []Message) string {
 		if len(content) > maxPromptLogLen && !isVerboseLoggingEnabled() {
 			content = content[:maxPromptLogLen] + "..."
 		}
+		if len(m.Images) > 0 {
+			content = fmt.Sprintf("%s [+%d images]", content, len(m.Images))
+		}
 		parts = append(parts, fmt.Sprintf("[%d] role=%s content=%s", i, role, content))
 	}
 	return strings.Join(parts, " | ")
@@ -520,6 +598,23 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// effectiveSampling returns the sampling parameters buildChatParams sends for
+// req: the request's own, falling back to the model's configured defaults.
+func (c *Client) effectiveSampling(req *ChatRequest, modelAlias string) Sampling {
+	modelCfg, _ := c.config.Model(modelAlias)
+	sampling := Sampling{Temperature: modelCfg.Temperature, TopP: modelCfg.TopP, Seed: modelCfg.Seed}
+	if req.Temperature != nil {
+		sampling.Temperature = req.Temperature
+	}
+	if req.TopP != nil {
+		sampling.TopP = req.TopP
+	}
+	if req.Seed != nil {
+		sampling.Seed = req.Seed
+	}
+	return sampling
+}
+
 func (c *Client) buildChatParams(req *ChatRequest) (openai.ChatCompletionNewParams, string, string, error) {
 	if len(req.Messages) == 0 {
 		return openai.ChatCompletionNewParams{}, "", "", errors.New("llm: request requires at least one message")
@@ -571,6 +666,12 @@ func (c *Client) buildChatParams(req *ChatRequest) (openai.ChatCompletionNewPara
 		params.TopP = openai.Float(*modelCfg.TopP)
 	}
 
+	if req.Seed != nil {
+		params.Seed = openai.Int(*req.Seed)
+	} else if modelCfg.Seed != nil {
+		params.Seed = openai.Int(*modelCfg.Seed)
+	}
+
 	return params, modelAlias, modelID, nil
 }
 
@@ -598,6 +699,19 @@ func buildMessageParams(msgs []Message) ([]openai.ChatCompletionMessageParamUnio
 			result = append(result, param)
 		default:
 			param := openai.UserMessage(m.Content)
+			if len(m.Images) > 0 {
+				parts := make([]openai.ChatCompletionContentPartUnionParam, 0, len(m.Images)+1)
+				if m.Content != "" {
+					parts = append(parts, openai.TextContentPart(m.Content))
+				}
+				for _, img := range m.Images {
+					parts = append(parts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+						URL:    img.URL,
+						Detail: strings.ToLower(img.Detail),
+					}))
+				}
+				param = openai.UserMessage(parts)
+			}
 			if m.Name != "" && param.OfUser != nil {
 				param.OfUser.Name = openai.String(m.Name)
 			}
@@ -148,6 +148,46 @@ func TestClientChat(t *testing.T) {
 	require.Equal(t, 1, callCount)
 }
 
+func TestClientChatAppliesModelSampling(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &payload)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","created":1730366400,"model":"deepseek/deepseek-chat-v3.1",
+			"choices":[{"index":0,"finish_reason":"stop","logprobs":null,"message":{"role":"assistant","content":"ok"}}],
+			"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	temp, topP, seed := 0.2, 0.9, int64(42)
+	client, err := NewClient(&Config{
+		BaseURL:      server.URL,
+		APIKey:       "test-key",
+		DefaultModel: "deepseek-chat",
+		Timeout:      5 * time.Second,
+		LogLevel:     "error",
+		Models: map[string]ModelConfig{
+			"deepseek-chat": {ModelName: "deepseek/deepseek-chat-v3.1", Temperature: &temp, TopP: &topP, Seed: &seed},
+		},
+	}, WithHTTPClient(server.Client()))
+	require.NoError(t, err)
+	defer client.Close()
+
+	override := int64(7)
+	resp, err := client.Chat(context.Background(), &ChatRequest{
+		Model:    "deepseek-chat",
+		Messages: []Message{{Role: "user", Content: "hi"}},
+		Seed:     &override,
+	})
+	require.NoError(t, err)
+
+	require.InDelta(t, 0.2, payload["temperature"], 1e-9)
+	require.InDelta(t, 0.9, payload["top_p"], 1e-9)
+	require.EqualValues(t, 7, payload["seed"])
+	require.Equal(t, Sampling{Temperature: &temp, TopP: &topP, Seed: &override}, resp.Sampling)
+}
+
 func TestClientChatStructured(t *testing.T) {
 	var captured map[string]any
 
@@ -462,3 +502,26 @@ func TestConvertToolCalls(t *testing.T) {
 		require.Equal(t, "call_2", result[1].ID)
 	})
 }
+
+func TestBuildMessageParamsImages(t *testing.T) {
+	msgs := []Message{
+		{Role: "system", Content: "rules", Images: []Image{{URL: "data:image/png;base64,AA"}}},
+		{Role: "user", Content: "charts", Images: []Image{{URL: "data:image/png;base64,AA", Detail: "LOW"}}},
+	}
+	params, err := buildMessageParams(msgs)
+	require.NoError(t, err)
+	require.Len(t, params, 2)
+	require.NotNil(t, params[0].OfSystem)
+
+	user := params[1].OfUser
+	require.NotNil(t, user)
+	parts := user.Content.OfArrayOfContentParts
+	require.Len(t, parts, 2)
+	require.Equal(t, "charts", parts[0].OfText.Text)
+	require.Equal(t, "low", parts[1].OfImageURL.ImageURL.Detail)
+
+	raw := rawContentParts(msgs[1])
+	require.Len(t, raw, 2)
+	require.Equal(t, "image_url", raw[1]["type"])
+	require.Equal(t, map[string]any{"url": "data:image/png;base64,AA", "detail": "low"}, raw[1]["image_url"])
+}
@@ -37,8 +37,12 @@ type Config struct {
 	LogLevel     string                 `yaml:"log_level"`
 	Models       map[string]ModelConfig `yaml:"models"`
 	Budget       *BudgetConfig          `yaml:"budget"`
+	Cache        *CacheConfig           `yaml:"cache"`
 	// Optional defaults for Zenmux auto-routing
 	RoutingDefaults *RoutingConfig `yaml:"routing_defaults,omitempty"`
+	// TokenizerDir holds the tiktoken files of the configured OpenAI models,
+	// loaded at startup for exact prompt token counts. Empty approximates.
+	TokenizerDir string `yaml:"tokenizer_dir"`
 
 	timeoutRaw string `yaml:"timeout"`
 }
@@ -50,8 +54,110 @@ type ModelConfig struct {
 	Temperature         *float64 `yaml:"temperature,omitempty"`
 	MaxCompletionTokens *int     `yaml:"max_completion_tokens,omitempty"`
 	TopP                *float64 `yaml:"top_p,omitempty"`
-	Priority            int      `yaml:"priority,omitempty"`
-	CostTier            string   `yaml:"cost_tier,omitempty"`
+	// Seed is sent to providers that support reproducible sampling; others
+	// ignore it.
+	Seed     *int64 `yaml:"seed,omitempty"`
+	Priority int    `yaml:"priority,omitempty"`
+	CostTier string `yaml:"cost_tier,omitempty"`
+	// ContextWindow is the model's total token window; 0 means unknown and
+	// disables prompt budget checks.
+	ContextWindow int `yaml:"context_window,omitempty"`
+	// SplitPrompt sends templates that define message blocks as a message
+	// sequence instead of one system message.
+	SplitPrompt bool `yaml:"split_prompt,omitempty"`
+	// SeriesFormat selects how prompt time series are laid out: inline
+	// (comma-joined values, the default) or csv (one code block per series).
+	SeriesFormat string `yaml:"series_format,omitempty"`
+	// Charts attaches candlestick images per coin for vision-capable models.
+	Charts *ChartConfig `yaml:"charts,omitempty"`
+}
+
+// Prompt series layouts accepted by ModelConfig.SeriesFormat.
+const (
+	SeriesFormatInline = "inline"
+	SeriesFormatCSV    = "csv"
+)
+
+// SeriesLayout returns the model's series format with the default applied.
+func (m ModelConfig) SeriesLayout() string {
+	format := strings.ToLower(strings.TrimSpace(m.SeriesFormat))
+	if format == "" {
+		return SeriesFormatInline
+	}
+	return format
+}
+
+// ChartConfig controls chart image attachments for a model.
+type ChartConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Width    int    `yaml:"width,omitempty"`     // pixels, default 512
+	Height   int    `yaml:"height,omitempty"`    // pixels, default 320
+	Detail   string `yaml:"detail,omitempty"`    // auto | low | high, default low
+	Series   string `yaml:"series,omitempty"`    // intraday | long_term, default intraday
+	MaxCoins int    `yaml:"max_coins,omitempty"` // 0 attaches every candidate coin
+}
+
+const (
+	defaultChartWidth  = 512
+	defaultChartHeight = 320
+	defaultChartDetail = "low"
+	defaultChartSeries = "intraday"
+)
+
+// Active reports whether chart attachments are enabled.
+func (c *ChartConfig) Active() bool {
+	return c != nil && c.Enabled
+}
+
+// Size returns the chart dimensions with defaults applied.
+func (c *ChartConfig) Size() (width, height int) {
+	width, height = defaultChartWidth, defaultChartHeight
+	if c != nil && c.Width > 0 {
+		width = c.Width
+	}
+	if c != nil && c.Height > 0 {
+		height = c.Height
+	}
+	return width, height
+}
+
+// DetailLevel returns the requested vision detail with the default applied.
+func (c *ChartConfig) DetailLevel() string {
+	if c == nil || strings.TrimSpace(c.Detail) == "" {
+		return defaultChartDetail
+	}
+	return strings.ToLower(strings.TrimSpace(c.Detail))
+}
+
+// SeriesName returns which snapshot series to chart with the default applied.
+func (c *ChartConfig) SeriesName() string {
+	if c == nil || strings.TrimSpace(c.Series) == "" {
+		return defaultChartSeries
+	}
+	return strings.ToLower(strings.TrimSpace(c.Series))
+}
+
+func (c *ChartConfig) validate(model string) error {
+	if c == nil {
+		return nil
+	}
+	if c.Width < 0 || c.Height < 0 || c.Width > 2048 || c.Height > 2048 {
+		return fmt.Errorf("llm config: models[%s].charts width and height must be between 0 and 2048", model)
+	}
+	if c.MaxCoins < 0 {
+		return fmt.Errorf("llm config: models[%s].charts.max_coins cannot be negative", model)
+	}
+	switch c.DetailLevel() {
+	case "auto", "low", "high":
+	default:
+		return fmt.Errorf("llm config: models[%s].charts.detail must be auto, low or high", model)
+	}
+	switch c.SeriesName() {
+	case "intraday", "long_term":
+	default:
+		return fmt.Errorf("llm config: models[%s].charts.series must be intraday or long_term", model)
+	}
+	return nil
 }
 
 // BudgetConfig controls token spend for LLM usage.
@@ -137,7 +243,9 @@ func LoadConfigFromReader(r io.Reader) (*Config, error) {
 		LogLevel        string                 `yaml:"log_level"`
 		Models          map[string]ModelConfig `yaml:"models"`
 		Budget          *BudgetConfig          `yaml:"budget"`
+		Cache           *CacheConfig           `yaml:"cache"`
 		RoutingDefaults *RoutingConfig         `yaml:"routing_defaults"`
+		TokenizerDir    string                 `yaml:"tokenizer_dir"`
 	}
 
 	data, err := io.ReadAll(r)
@@ -156,7 +264,9 @@ func LoadConfigFromReader(r io.Reader) (*Config, error) {
 		LogLevel:        raw.LogLevel,
 		Models:          raw.Models,
 		Budget:          raw.Budget,
+		Cache:           raw.Cache,
 		RoutingDefaults: raw.RoutingDefaults,
+		TokenizerDir:    raw.TokenizerDir,
 		timeoutRaw:      raw.Timeout,
 	}
 
@@ -168,9 +278,22 @@ func LoadConfigFromReader(r io.Reader) (*Config, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
+	if err := LoadTokenizers(cfg.TokenizerDir, cfg.modelIDs()...); err != nil {
+		return nil, fmt.Errorf("llm config: tokenizer_dir: %w", err)
+	}
 	return cfg, nil
 }
 
+// modelIDs lists the provider/model IDs of the default model and every
+// configured alias.
+func (c *Config) modelIDs() []string {
+	ids := []string{ResolveModelID(c.DefaultModel, c.Models[c.DefaultModel])}
+	for alias, m := range c.Models {
+		ids = append(ids, ResolveModelID(alias, m))
+	}
+	return ids
+}
+
 // Validate checks that required configuration is present.
 func (c *Config) Validate() error {
 	if strings.TrimSpace(c.APIKey) == "" {
@@ -188,11 +311,33 @@ func (c *Config) Validate() error {
 	if c.MaxRetries < 0 {
 		return errors.New("llm config: max_retries cannot be negative")
 	}
+	for name, m := range c.Models {
+		if m.ContextWindow < 0 {
+			return fmt.Errorf("llm config: models[%s].context_window cannot be negative", name)
+		}
+		if m.Temperature != nil && (*m.Temperature < 0 || *m.Temperature > 2) {
+			return fmt.Errorf("llm config: models[%s].temperature must be between 0 and 2", name)
+		}
+		if m.TopP != nil && (*m.TopP <= 0 || *m.TopP > 1) {
+			return fmt.Errorf("llm config: models[%s].top_p must be in (0, 1]", name)
+		}
+		switch m.SeriesLayout() {
+		case SeriesFormatInline, SeriesFormatCSV:
+		default:
+			return fmt.Errorf("llm config: models[%s].series_format must be inline or csv", name)
+		}
+		if err := m.Charts.validate(name); err != nil {
+			return err
+		}
+	}
 	if c.Budget != nil {
 		if err := c.Budget.Validate(); err != nil {
 			return err
 		}
 	}
+	if err := c.Cache.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -205,6 +350,26 @@ func (c *Config) Model(name string) (ModelConfig, bool) {
 	return modelCfg, ok
 }
 
+// PromptTokenBudget returns how many prompt tokens fit the model's window
+// after reserving room for the completion. Zero means no limit is known.
+func (c *Config) PromptTokenBudget(name string) int {
+	if c == nil {
+		return 0
+	}
+	modelCfg, ok := c.Model(name)
+	if !ok || modelCfg.ContextWindow <= 0 {
+		return 0
+	}
+	budget := modelCfg.ContextWindow
+	if modelCfg.MaxCompletionTokens != nil {
+		budget -= *modelCfg.MaxCompletionTokens
+	}
+	if budget <= 0 {
+		return 0
+	}
+	return budget
+}
+
 // Clone returns a shallow copy of the configuration.
 func (c *Config) Clone() *Config {
 	if c == nil {
@@ -221,6 +386,10 @@ func (c *Config) Clone() *Config {
 	if c.Budget != nil {
 		cp.Budget = c.Budget.Clone()
 	}
+	if c.Cache != nil {
+		cacheCfg := *c.Cache
+		cp.Cache = &cacheCfg
+	}
 	return &cp
 }
 
@@ -237,6 +406,7 @@ func (c *Config) applyDefaults() {
 	if c.Budget != nil {
 		c.Budget.applyDefaults()
 	}
+	c.Cache.applyDefaults()
 }
 
 func (c *Config) applyEnvOverrides() {
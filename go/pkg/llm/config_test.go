@@ -73,6 +73,24 @@ api_key: test-api-key
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "unmarshal llm config")
 	})
+
+	t.Run("missing tokenizer file", func(t *testing.T) {
+		content := `
+base_url: "https://api.example.com"
+api_key: "test-api-key"
+default_model: "gpt-4"
+timeout: "30s"
+tokenizer_dir: "` + t.TempDir() + `"
+models:
+  gpt-4:
+    provider: "openai"
+    model_name: "gpt-4-turbo"
+`
+		_, err := LoadConfigFromReader(strings.NewReader(content))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "tokenizer_dir")
+		require.Contains(t, err.Error(), "cl100k_base.tiktoken")
+	})
 }
 
 func TestConfigValidate(t *testing.T) {
@@ -313,6 +331,88 @@ func TestConfigModel(t *testing.T) {
 	})
 }
 
+func TestConfigPromptTokenBudget(t *testing.T) {
+	completion := 4096
+	cfg := &Config{
+		Models: map[string]ModelConfig{
+			"windowed":  {ContextWindow: 128000, MaxCompletionTokens: &completion},
+			"no-cap":    {ContextWindow: 32000},
+			"unknown":   {},
+			"too-small": {ContextWindow: 2048, MaxCompletionTokens: &completion},
+		},
+	}
+	require.Equal(t, 123904, cfg.PromptTokenBudget("windowed"))
+	require.Equal(t, 32000, cfg.PromptTokenBudget("no-cap"))
+	require.Zero(t, cfg.PromptTokenBudget("unknown"))
+	require.Zero(t, cfg.PromptTokenBudget("too-small"))
+	require.Zero(t, cfg.PromptTokenBudget("missing"))
+	require.Zero(t, (*Config)(nil).PromptTokenBudget("windowed"))
+
+	require.Equal(t, 0, EstimateTokens(""))
+	require.Equal(t, 2, EstimateTokens("abcde"))
+}
+
+func TestChartConfig(t *testing.T) {
+	var disabled *ChartConfig
+	require.False(t, disabled.Active())
+	w, h := disabled.Size()
+	require.Equal(t, 512, w)
+	require.Equal(t, 320, h)
+	require.Equal(t, "low", disabled.DetailLevel())
+	require.Equal(t, "intraday", disabled.SeriesName())
+
+	charts := &ChartConfig{Enabled: true, Width: 1024, Detail: "HIGH", Series: "long_term"}
+	require.True(t, charts.Active())
+	w, h = charts.Size()
+	require.Equal(t, 1024, w)
+	require.Equal(t, 320, h)
+	require.Equal(t, "high", charts.DetailLevel())
+	require.NoError(t, charts.validate("gpt-5"))
+
+	require.ErrorContains(t, (&ChartConfig{Detail: "ultra"}).validate("gpt-5"), "models[gpt-5].charts.detail")
+	require.ErrorContains(t, (&ChartConfig{Series: "weekly"}).validate("gpt-5"), "charts.series")
+	require.ErrorContains(t, (&ChartConfig{Width: 4096}).validate("gpt-5"), "between 0 and 2048")
+	require.ErrorContains(t, (&ChartConfig{MaxCoins: -1}).validate("gpt-5"), "max_coins")
+}
+
+func TestModelConfigSeriesFormat(t *testing.T) {
+	require.Equal(t, SeriesFormatInline, ModelConfig{}.SeriesLayout())
+	require.Equal(t, SeriesFormatCSV, ModelConfig{SeriesFormat: " CSV "}.SeriesLayout())
+
+	cfg := &Config{APIKey: "k", BaseURL: "https://x", DefaultModel: "m", Timeout: time.Second,
+		Models: map[string]ModelConfig{"m": {SeriesFormat: "markdown"}}}
+	require.ErrorContains(t, cfg.Validate(), "models[m].series_format must be inline or csv")
+	cfg.Models["m"] = ModelConfig{SeriesFormat: "csv"}
+	require.NoError(t, cfg.Validate())
+}
+
+func TestModelConfigSamplingBounds(t *testing.T) {
+	hot, wide := 2.5, 0.0
+	cfg := &Config{APIKey: "k", BaseURL: "https://x", DefaultModel: "m", Timeout: time.Second,
+		Models: map[string]ModelConfig{"m": {Temperature: &hot}}}
+	require.ErrorContains(t, cfg.Validate(), "models[m].temperature must be between 0 and 2")
+	cfg.Models["m"] = ModelConfig{TopP: &wide}
+	require.ErrorContains(t, cfg.Validate(), "models[m].top_p must be in (0, 1]")
+	temp, topP, seed := 0.3, 0.9, int64(42)
+	cfg.Models["m"] = ModelConfig{Temperature: &temp, TopP: &topP, Seed: &seed}
+	require.NoError(t, cfg.Validate())
+}
+
+func TestEstimateImageTokens(t *testing.T) {
+	require.Equal(t, 85, EstimateImageTokens(1024, 1024, "low"))
+	require.Equal(t, 85, EstimateImageTokens(0, 0, "high"))
+	require.Equal(t, 255, EstimateImageTokens(512, 320, "high"))
+	require.Equal(t, 765, EstimateImageTokens(1024, 1024, "high"))
+	require.Equal(t, 1105, EstimateImageTokens(2048, 4096, "auto"))
+
+	images, tokens := EstimateMessageImageTokens([]Message{
+		{Role: "system", Content: "rules"},
+		{Role: "user", Images: []Image{{Detail: "low"}, {Width: 512, Height: 320, Detail: "high"}}},
+	})
+	require.Equal(t, 2, images)
+	require.Equal(t, 340, tokens)
+}
+
 func TestConfigClone(t *testing.T) {
 	temp := 0.7
 	maxCompletionTokens := 1024
@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"fmt"
+	"go/token"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TypeRegistry names the Go types templates render against, the names a
+// template's front-matter data_type refers to. Registering a type also
+// registers the named struct types it reaches through fields, slices, maps
+// and pointers from the same module as <package>.<Type>, so tools can
+// describe nested data on its own.
+type TypeRegistry struct {
+	mu    sync.RWMutex
+	types map[string]reflect.Type
+	roots map[string]bool
+}
+
+// NewTypeRegistry returns an empty registry.
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{types: map[string]reflect.Type{}, roots: map[string]bool{}}
+}
+
+// dataTypes is the registry packages add their template data types to.
+var dataTypes = NewTypeRegistry()
+
+// DataTypes returns the shared registry, filled by the init functions of the
+// packages that render templates.
+func DataTypes() *TypeRegistry { return dataTypes }
+
+// RegisterType adds the type of v to the shared registry under name:
+//
+//	func init() { llm.RegisterType("executor.PromptInputs", promptPayload{}) }
+//
+// It panics when name is empty or already registered for another type.
+func RegisterType(name string, v any) {
+	dataTypes.Register(name, reflect.TypeOf(v))
+}
+
+// Register adds typ under name along with its nested types. A nested type
+// never replaces a registered one, while an explicit registration replaces
+// a nested type of the same name. Registering a different type under a name
+// registered explicitly before panics.
+func (r *TypeRegistry) Register(name string, typ reflect.Type) {
+	name = strings.TrimSpace(name)
+	if name == "" || typ == nil {
+		panic("llm: RegisterType needs a name and a type")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if prev, ok := r.types[name]; ok && r.roots[name] && prev != typ {
+		panic(fmt.Sprintf("llm: data type %q registered twice (%s and %s)", name, prev, typ))
+	}
+	r.types[name] = typ
+	r.roots[name] = true
+	r.addNested(typ, modulePrefix(indirect(typ).PkgPath()), map[reflect.Type]bool{})
+}
+
+func (r *TypeRegistry) addNested(typ reflect.Type, module string, seen map[reflect.Type]bool) {
+	typ = indirect(typ)
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		r.addNested(typ.Elem(), module, seen)
+		return
+	case reflect.Map:
+		r.addNested(typ.Key(), module, seen)
+		r.addNested(typ.Elem(), module, seen)
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+	if seen[typ] || module == "" || modulePrefix(typ.PkgPath()) != module {
+		return
+	}
+	seen[typ] = true
+	if n := typ.Name(); token.IsIdentifier(n) && token.IsExported(n) {
+		name := path.Base(typ.PkgPath()) + "." + n
+		if _, ok := r.types[name]; !ok {
+			r.types[name] = typ
+		}
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if f := typ.Field(i); f.IsExported() || f.Anonymous {
+			r.addNested(f.Type, module, seen)
+		}
+	}
+}
+
+// Lookup returns the type registered under name.
+func (r *TypeRegistry) Lookup(name string) (reflect.Type, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	typ, ok := r.types[strings.TrimSpace(name)]
+	return typ, ok
+}
+
+// Names lists the registered names in order.
+func (r *TypeRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.types))
+	for name := range r.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Types returns a copy of the registered names and types.
+func (r *TypeRegistry) Types() map[string]reflect.Type {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make(map[string]reflect.Type, len(r.types))
+	for name, typ := range r.types {
+		out[name] = typ
+	}
+	return out
+}
+
+func indirect(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// modulePrefix is the first element of an import path, the module of the
+// packages in this repository.
+func modulePrefix(pkgPath string) string {
+	first, _, _ := strings.Cut(pkgPath, "/")
+	return first
+}
@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type registryLeg struct {
+	Symbol string
+}
+
+type registryOrder struct {
+	Legs  []registryLeg
+	Meta  map[string]*RegistryNote
+	inner registryLeg
+}
+
+// RegistryNote is exported so it is registered under its own name.
+type RegistryNote struct {
+	Text string
+}
+
+type registryPayload struct {
+	Order *registryOrder
+	RegistryNote
+}
+
+func TestTypeRegistryRegistersNestedTypes(t *testing.T) {
+	r := NewTypeRegistry()
+	r.Register("test.Payload", reflect.TypeOf(registryPayload{}))
+
+	typ, ok := r.Lookup("test.Payload")
+	require.True(t, ok)
+	assert.Equal(t, reflect.TypeOf(registryPayload{}), typ)
+	typ, ok = r.Lookup("llm.RegistryNote")
+	require.True(t, ok, "exported nested types are reachable by name")
+	assert.Equal(t, reflect.TypeOf(RegistryNote{}), typ)
+	assert.Equal(t, []string{"llm.RegistryNote", "test.Payload"}, r.Names(), "unexported types are walked but not named")
+
+	// An explicit registration replaces a nested one; a second explicit one
+	// for another type panics.
+	r.Register("llm.RegistryNote", reflect.TypeOf(registryLeg{}))
+	typ, _ = r.Lookup("llm.RegistryNote")
+	assert.Equal(t, reflect.TypeOf(registryLeg{}), typ)
+	assert.Panics(t, func() { r.Register("llm.RegistryNote", reflect.TypeOf(RegistryNote{})) })
+	assert.NotPanics(t, func() { r.Register("llm.RegistryNote", reflect.TypeOf(registryLeg{})) })
+
+	types := r.Types()
+	delete(types, "test.Payload")
+	_, ok = r.Lookup("test.Payload")
+	assert.True(t, ok, "Types returns a copy")
+}
+
+func TestRegisterTypeFillsSharedRegistry(t *testing.T) {
+	RegisterType("llmtest.Order", registryOrder{})
+	typ, ok := DataTypes().Lookup("llmtest.Order")
+	require.True(t, ok)
+	assert.Equal(t, reflect.TypeOf(registryOrder{}), typ)
+	assert.Panics(t, func() { RegisterType("", registryOrder{}) })
+}
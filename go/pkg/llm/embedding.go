@@ -0,0 +1,176 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/openai/openai-go"
+)
+
+// Embedder maps texts to vectors whose cosine similarity reflects how alike
+// the texts are.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+const defaultHashEmbeddingDims = 256
+
+// HashEmbedder embeds locally by feature-hashing word unigrams and bigrams
+// into Dims buckets (256 when zero). Digits are masked, so prompts that only
+// differ in market numbers embed alike while added, dropped or reformatted
+// sections move the vector. It needs no API access and is deterministic.
+type HashEmbedder struct {
+	Dims int
+}
+
+// Embed implements Embedder.
+func (h HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	dims := h.Dims
+	if dims <= 0 {
+		dims = defaultHashEmbeddingDims
+	}
+	out := make([][]float64, len(texts))
+	for i, text := range texts {
+		vec := make([]float64, dims)
+		prev := ""
+		for _, tok := range embeddingTokens(text) {
+			addFeature(vec, tok)
+			if prev != "" {
+				addFeature(vec, prev+" "+tok)
+			}
+			prev = tok
+		}
+		normalize(vec)
+		out[i] = vec
+	}
+	return out, nil
+}
+
+// embeddingTokens lowercases text, masks digits and splits it into words and
+// punctuation marks.
+func embeddingTokens(text string) []string {
+	var (
+		toks []string
+		b    strings.Builder
+	)
+	flush := func() {
+		if b.Len() > 0 {
+			toks = append(toks, b.String())
+			b.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsDigit(r):
+			b.WriteRune('0')
+		case unicode.IsLetter(r), r == '_', r == '.' && b.Len() > 0:
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			toks = append(toks, string(r))
+		}
+	}
+	flush()
+	return toks
+}
+
+// addFeature adds a signed count for feature to vec; the sign halves the
+// bias collisions add to cosine similarity.
+func addFeature(vec []float64, feature string) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(feature))
+	sum := h.Sum64()
+	sign := 1.0
+	if sum>>63 == 1 {
+		sign = -1
+	}
+	vec[sum%uint64(len(vec))] += sign
+}
+
+func normalize(vec []float64) {
+	var norm float64
+	for _, v := range vec {
+		norm += v * v
+	}
+	if norm == 0 {
+		return
+	}
+	norm = math.Sqrt(norm)
+	for i := range vec {
+		vec[i] /= norm
+	}
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, or 0 when
+// either is zero or their lengths differ.
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+// Embedder returns an Embedder calling the provider's embeddings endpoint
+// with model, e.g. "openai/text-embedding-3-small".
+func (c *Client) Embedder(model string) Embedder {
+	return &clientEmbedder{client: c, model: model}
+}
+
+type clientEmbedder struct {
+	client *Client
+	model  string
+}
+
+func (e *clientEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	if strings.TrimSpace(e.model) == "" {
+		return nil, errors.New("llm: embedding model is required")
+	}
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	params := openai.EmbeddingNewParams{
+		Input: openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: texts},
+		Model: openai.EmbeddingModel(e.model),
+	}
+	var resp *openai.CreateEmbeddingResponse
+	err := e.client.retryHandler.Do(ctx, func() error {
+		r, callErr := e.client.openaiClient.Embeddings.New(ctx, params)
+		if callErr != nil {
+			return callErr
+		}
+		resp = r
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("llm: embed with %s: %w", e.model, err)
+	}
+	out := make([][]float64, len(texts))
+	for _, d := range resp.Data {
+		if d.Index < 0 || int(d.Index) >= len(out) {
+			return nil, fmt.Errorf("llm: embedding index %d out of range", d.Index)
+		}
+		out[d.Index] = d.Embedding
+	}
+	for i, v := range out {
+		if v == nil {
+			return nil, fmt.Errorf("llm: embedding %d missing from response", i)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashEmbedderIgnoresNumbersButNotStructure(t *testing.T) {
+	vecs, err := HashEmbedder{}.Embed(context.Background(), []string{
+		"BTC price 97123.5 EMA20 96800.1 RSI7 61.2 funding 0.0001",
+		"BTC price 64010.0 EMA20 65555.9 RSI7 38.7 funding -0.0003",
+		"BTC price NaN EMA20 unavailable: insufficient history",
+	})
+	require.NoError(t, err)
+	require.Len(t, vecs, 3)
+	assert.Len(t, vecs[0], defaultHashEmbeddingDims)
+	assert.InDelta(t, 1, CosineSimilarity(vecs[0], vecs[0]), 1e-9)
+	assert.Greater(t, CosineSimilarity(vecs[0], vecs[1]), 0.9, "only the numbers differ")
+	assert.Less(t, CosineSimilarity(vecs[0], vecs[2]), 0.6, "the data section changed shape")
+
+	assert.Zero(t, CosineSimilarity([]float64{1, 0}, []float64{1}))
+}
+
+func TestClientEmbedder(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/embeddings", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &payload)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","model":"text-embedding-3-small","data":[
+			{"object":"embedding","index":1,"embedding":[0,1]},
+			{"object":"embedding","index":0,"embedding":[1,0]}],
+			"usage":{"prompt_tokens":2,"total_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{BaseURL: server.URL, APIKey: "test-key", DefaultModel: "m", Timeout: 5 * time.Second, LogLevel: "error"}, WithHTTPClient(server.Client()))
+	require.NoError(t, err)
+	defer client.Close()
+
+	vecs, err := client.Embedder("openai/text-embedding-3-small").Embed(context.Background(), []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{1, 0}, {0, 1}}, vecs, "results are ordered by index")
+	assert.Equal(t, "openai/text-embedding-3-small", payload["model"])
+	assert.Equal(t, []any{"a", "b"}, payload["input"])
+
+	_, err = client.Embedder("").Embed(context.Background(), []string{"a"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,274 @@
+package llm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// WithDataType checks, on every load, that the template only references
+// fields and methods that exist on data (a struct type or pointer to one),
+// so a typo such as {{ .Acount.Status }} fails when the template loads
+// instead of when a trading cycle renders it. See ValidateFields.
+func WithDataType(data reflect.Type) PromptTemplateOption {
+	return func(t *PromptTemplate) { t.dataType = data }
+}
+
+// ValidateFields statically walks tmpl and reports field and method
+// references that cannot resolve against data, the type the template is
+// executed with. The top-level template and message blocks are checked
+// against data; other defined templates are checked with whatever they are
+// invoked with. Dot is tracked through range and with; references through
+// interface values, function results and non-string map keys are not
+// checked since their type is only known at render time.
+func ValidateFields(tmpl *template.Template, data reflect.Type) error {
+	problems := checkFields(tmpl, data)
+	if len(problems) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(problems))
+	for i, p := range problems {
+		loc, _ := p.tree.ErrorContext(p.node)
+		msgs[i] = loc + ": " + p.msg
+	}
+	return fmt.Errorf("template references unknown fields: %s", strings.Join(msgs, "; "))
+}
+
+// fieldProblem is a reference that cannot resolve, at node in tree.
+type fieldProblem struct {
+	tree *parse.Tree
+	node parse.Node
+	msg  string
+}
+
+func checkFields(tmpl *template.Template, data reflect.Type) []fieldProblem {
+	if tmpl == nil || data == nil {
+		return nil
+	}
+	c := &fieldChecker{tmpl: tmpl, seen: make(map[string]bool)}
+	for _, def := range tmpl.Templates() {
+		if def.Tree == nil {
+			continue
+		}
+		if def.Name() == tmpl.Name() || strings.HasPrefix(def.Name(), MessageBlockPrefix) {
+			c.checkTemplate(def.Name(), data)
+		}
+	}
+	return c.problems
+}
+
+type fieldChecker struct {
+	tmpl *template.Template
+	// seen records template/dot pairs already checked, which also stops
+	// recursive {{ template }} calls.
+	seen     map[string]bool
+	problems []fieldProblem
+	tree     *parse.Tree
+}
+
+// fieldScope maps variable names to their types; nil means unknown.
+type fieldScope map[string]reflect.Type
+
+func (s fieldScope) clone() fieldScope {
+	out := make(fieldScope, len(s))
+	for k, v := range s {
+		out[k] = v
+	}
+	return out
+}
+
+func (c *fieldChecker) checkTemplate(name string, dot reflect.Type) {
+	key := name + "\x00" + typeName(dot)
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	def := c.tmpl.Lookup(name)
+	if def == nil || def.Tree == nil {
+		return
+	}
+	outer := c.tree
+	c.tree = def.Tree
+	c.checkList(def.Tree.Root, dot, fieldScope{"$": dot})
+	c.tree = outer
+}
+
+func (c *fieldChecker) checkList(list *parse.ListNode, dot reflect.Type, vars fieldScope) {
+	if list == nil {
+		return
+	}
+	for _, n := range list.Nodes {
+		switch x := n.(type) {
+		case *parse.ActionNode:
+			c.checkPipe(x.Pipe, dot, vars)
+		case *parse.IfNode:
+			c.checkBranch(&x.BranchNode, dot, vars, func(reflect.Type) reflect.Type { return dot })
+		case *parse.WithNode:
+			c.checkBranch(&x.BranchNode, dot, vars, func(t reflect.Type) reflect.Type { return t })
+		case *parse.RangeNode:
+			c.checkRange(x, dot, vars)
+		case *parse.TemplateNode:
+			c.checkTemplate(x.Name, c.checkPipe(x.Pipe, dot, vars))
+		case *parse.ListNode:
+			c.checkList(x, dot, vars)
+		}
+	}
+}
+
+// checkBranch checks an if or with; inner derives the dot of the body from
+// the pipeline's type.
+func (c *fieldChecker) checkBranch(b *parse.BranchNode, dot reflect.Type, vars fieldScope, inner func(reflect.Type) reflect.Type) {
+	scope := vars.clone()
+	typ := c.checkPipe(b.Pipe, dot, scope)
+	c.checkList(b.List, inner(typ), scope)
+	c.checkList(b.ElseList, dot, vars.clone())
+}
+
+func (c *fieldChecker) checkRange(r *parse.RangeNode, dot reflect.Type, vars fieldScope) {
+	scope := vars.clone()
+	typ := indirectType(c.checkPipe(r.Pipe, dot, scope))
+	var key, elem reflect.Type
+	if typ != nil {
+		switch typ.Kind() {
+		case reflect.Slice, reflect.Array:
+			key, elem = reflect.TypeOf(0), typ.Elem()
+		case reflect.Map:
+			key, elem = typ.Key(), typ.Elem()
+		case reflect.Chan:
+			elem = typ.Elem()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			elem = typ
+		}
+	}
+	if r.Pipe != nil {
+		switch len(r.Pipe.Decl) {
+		case 1:
+			scope[r.Pipe.Decl[0].Ident[0]] = elem
+		case 2:
+			scope[r.Pipe.Decl[0].Ident[0]] = key
+			scope[r.Pipe.Decl[1].Ident[0]] = elem
+		}
+	}
+	c.checkList(r.List, elem, scope)
+	c.checkList(r.ElseList, dot, vars.clone())
+}
+
+// checkPipe checks every command of pipe and returns the type it yields,
+// nil when unknown. Declared variables are added to vars.
+func (c *fieldChecker) checkPipe(pipe *parse.PipeNode, dot reflect.Type, vars fieldScope) reflect.Type {
+	if pipe == nil {
+		return nil
+	}
+	var typ reflect.Type
+	for _, cmd := range pipe.Cmds {
+		typ = c.checkCommand(cmd, dot, vars)
+	}
+	if vars != nil && len(pipe.Decl) == 1 && !pipe.IsAssign {
+		vars[pipe.Decl[0].Ident[0]] = typ
+	}
+	return typ
+}
+
+func (c *fieldChecker) checkCommand(cmd *parse.CommandNode, dot reflect.Type, vars fieldScope) reflect.Type {
+	var typ reflect.Type
+	for i, arg := range cmd.Args {
+		t := c.checkArg(arg, dot, vars)
+		if i == 0 {
+			typ = t
+		}
+	}
+	return typ
+}
+
+func (c *fieldChecker) checkArg(n parse.Node, dot reflect.Type, vars fieldScope) reflect.Type {
+	switch x := n.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return c.resolve(x, dot, x.Ident)
+	case *parse.VariableNode:
+		typ, ok := vars[x.Ident[0]]
+		if !ok {
+			return nil
+		}
+		return c.resolve(x, typ, x.Ident[1:])
+	case *parse.ChainNode:
+		return c.resolve(x, c.checkArg(x.Node, dot, vars), x.Field)
+	case *parse.PipeNode:
+		return c.checkPipe(x, dot, vars.clone())
+	}
+	return nil
+}
+
+// resolve follows fields from typ and records the first that does not exist.
+func (c *fieldChecker) resolve(n parse.Node, typ reflect.Type, fields []string) reflect.Type {
+	for _, name := range fields {
+		if typ == nil {
+			return nil
+		}
+		next, ok := lookupField(typ, name)
+		if !ok {
+			c.problems = append(c.problems, fieldProblem{
+				tree: c.tree,
+				node: n,
+				msg:  fmt.Sprintf("%s has no field or method %q", typeName(typ), name),
+			})
+			return nil
+		}
+		typ = next
+	}
+	return typ
+}
+
+// lookupField resolves name on typ the way text/template does at execution:
+// methods first, then struct fields or string map keys. The result is nil
+// when the type is only known at render time.
+func lookupField(typ reflect.Type, name string) (reflect.Type, bool) {
+	if typ.Kind() == reflect.Interface {
+		return nil, true
+	}
+	ptr := typ
+	if typ.Kind() != reflect.Pointer {
+		ptr = reflect.PointerTo(typ)
+	}
+	if m, ok := ptr.MethodByName(name); ok {
+		if m.Type.NumOut() == 0 {
+			return nil, true
+		}
+		return m.Type.Out(0), true
+	}
+	typ = indirectType(typ)
+	switch typ.Kind() {
+	case reflect.Struct:
+		f, ok := typ.FieldByName(name)
+		if !ok || !f.IsExported() {
+			return nil, false
+		}
+		return f.Type, true
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String {
+			return nil, true
+		}
+		return typ.Elem(), true
+	case reflect.Interface:
+		return nil, true
+	}
+	return nil, false
+}
+
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+func typeName(typ reflect.Type) string {
+	if typ == nil {
+		return "<unknown>"
+	}
+	return typ.String()
+}
@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fieldCheckAccount struct {
+	Status string
+	Equity float64
+}
+
+type fieldCheckPosition struct {
+	Symbol string
+	Size   float64
+}
+
+type fieldCheckData struct {
+	Account   *fieldCheckAccount
+	Positions []fieldCheckPosition
+	Labels    map[string]string
+	Extra     any
+}
+
+func (fieldCheckData) Summary() fieldCheckAccount { return fieldCheckAccount{} }
+
+func TestValidateFields(t *testing.T) {
+	typ := reflect.TypeOf(fieldCheckData{})
+	check := func(body string) error {
+		tmpl, err := template.New("t").Parse(body)
+		require.NoError(t, err)
+		return ValidateFields(tmpl, typ)
+	}
+
+	assert.NoError(t, check(`{{ .Account.Status }} {{ .Summary.Equity }} {{ .Labels.anything }} {{ .Extra.Whatever }}
+{{ range $i, $p := .Positions }}{{ $p.Symbol }} {{ .Size }} {{ $.Account.Equity }}{{ end }}
+{{ with .Account }}{{ .Status }}{{ else }}{{ .Positions }}{{ end }}
+{{ $acct := .Account }}{{ $acct.Equity }} {{ (.Summary).Status }}`))
+
+	err := check(`{{ .Acount.Status }}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `t:1:10: llm.fieldCheckData has no field or method "Acount"`)
+
+	err = check("{{ range .Positions }}\n{{ .Symbl }}{{ end }}")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `t:2:3: llm.fieldCheckPosition has no field or method "Symbl"`)
+
+	err = check(`{{ define "message:user:x" }}{{ .Account.State }}{{ end }}{{ define "part" }}{{ .Sise }}{{ end }}{{ template "part" index .Positions 0 }}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `*llm.fieldCheckAccount has no field or method "State"`)
+	assert.NotContains(t, err.Error(), "Sise", "defined templates are checked with the dot they receive")
+}
+
+func TestPromptTemplateWithDataType(t *testing.T) {
+	fsys := fstest.MapFS{"p.tmpl": {Data: []byte("{{ .Account.Stauts }}")}}
+	_, err := NewPromptTemplate("p.tmpl", nil, WithFS(fsys), WithDataType(reflect.TypeOf(fieldCheckData{})))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `parse prompt template "p.tmpl": template references unknown fields`)
+
+	_, err = NewPromptTemplate("p.tmpl", nil, WithFS(fsys))
+	assert.NoError(t, err, "field references are unchecked without a data type")
+}
@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatterFence opens and closes a template's YAML front-matter.
+var frontMatterFence = []byte("---")
+
+// builtinTemplateFuncs are the functions text/template always provides.
+var builtinTemplateFuncs = append([]string{"call"}, SafeBuiltinFuncs...)
+
+// TemplateMeta is the YAML front-matter a template may open with. It is
+// stripped before parsing:
+//
+//	---
+//	data_type: executor.PromptInputs
+//	models: [deepseek-chat]
+//	funcs: [printf, len]
+//	author: quant-desk
+//	version: v1.1.0
+//	delims: ["[[", "]]"]
+//	---
+//
+// A template that lists funcs may call only those, and fails to load when
+// one of them is not provided. Delims replaces the {{ }} action delimiters,
+// so prompts can show the model literal {{ }} text.
+type TemplateMeta struct {
+	DataType string   `yaml:"data_type" json:"data_type,omitempty"`
+	Models   []string `yaml:"models" json:"models,omitempty"`
+	Funcs    []string `yaml:"funcs" json:"funcs,omitempty"`
+	Author   string   `yaml:"author" json:"author,omitempty"`
+	Version  string   `yaml:"version" json:"version,omitempty"`
+	Delims   []string `yaml:"delims" json:"delims,omitempty"`
+}
+
+// ActionDelims returns the left and right action delimiters, empty for
+// text/template's {{ and }}.
+func (m TemplateMeta) ActionDelims() (string, string) {
+	if len(m.Delims) != 2 {
+		return "", ""
+	}
+	return m.Delims[0], m.Delims[1]
+}
+
+// checkDelims fails unless delims is unset or names two distinct,
+// non-blank delimiters.
+func checkDelims(meta TemplateMeta) error {
+	if len(meta.Delims) == 0 {
+		return nil
+	}
+	if len(meta.Delims) != 2 {
+		return fmt.Errorf("front-matter delims needs a left and a right delimiter, got %d", len(meta.Delims))
+	}
+	left, right := meta.Delims[0], meta.Delims[1]
+	if strings.TrimSpace(left) == "" || strings.TrimSpace(right) == "" || left == right {
+		return fmt.Errorf("front-matter delims %q must be two distinct non-blank strings", meta.Delims)
+	}
+	return nil
+}
+
+// LoadTemplateMeta reads only the front-matter of the template at path. It
+// returns a zero TemplateMeta when the template has none.
+func LoadTemplateMeta(path string) (TemplateMeta, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return TemplateMeta{}, fmt.Errorf("read prompt template %q: %w", path, err)
+	}
+	meta, _, err := splitFrontMatter(data)
+	if err != nil {
+		return TemplateMeta{}, fmt.Errorf("prompt template %q: %w", path, err)
+	}
+	return meta, nil
+}
+
+// splitFrontMatter separates a leading front-matter block from the template
+// body. Data without one is returned unchanged.
+func splitFrontMatter(data []byte) (TemplateMeta, []byte, error) {
+	var meta TemplateMeta
+	first, rest, found := bytes.Cut(data, []byte("\n"))
+	if !found || !bytes.Equal(bytes.TrimRight(first, " \t\r"), frontMatterFence) {
+		return meta, data, nil
+	}
+	for offset := 0; offset < len(rest); {
+		line, _, _ := bytes.Cut(rest[offset:], []byte("\n"))
+		if bytes.Equal(bytes.TrimRight(line, " \t\r"), frontMatterFence) {
+			if err := yaml.Unmarshal(rest[:offset], &meta); err != nil {
+				return meta, nil, fmt.Errorf("front-matter: %w", err)
+			}
+			if err := checkDelims(meta); err != nil {
+				return meta, nil, err
+			}
+			end := offset + len(line) + 1
+			if end > len(rest) {
+				end = len(rest)
+			}
+			return meta, rest[end:], nil
+		}
+		offset += len(line) + 1
+	}
+	return meta, nil, fmt.Errorf("front-matter: missing closing %q", frontMatterFence)
+}
+
+// checkRequiredFuncs fails when front-matter lists a function that neither
+// funcs nor text/template provides.
+func checkRequiredFuncs(meta TemplateMeta, funcs template.FuncMap) error {
+	for _, name := range meta.Funcs {
+		if _, ok := funcs[name]; ok {
+			continue
+		}
+		builtin := false
+		for _, b := range builtinTemplateFuncs {
+			builtin = builtin || b == name
+		}
+		if !builtin {
+			return fmt.Errorf("front-matter requires function %q, which is not provided", name)
+		}
+	}
+	return nil
+}
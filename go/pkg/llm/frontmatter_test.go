@@ -0,0 +1,87 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptTemplateFrontMatter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "meta.tmpl")
+	body := "---\ndata_type: executor.PromptInputs\nmodels: [deepseek-chat, gpt-5]\nfuncs: [printf]\nauthor: quant-desk\nversion: v1.1.0\n---\n{{ printf \"%s\" .Name }}"
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+
+	tpl, err := NewPromptTemplate(path, nil)
+	require.NoError(t, err)
+	want := TemplateMeta{
+		DataType: "executor.PromptInputs",
+		Models:   []string{"deepseek-chat", "gpt-5"},
+		Funcs:    []string{"printf"},
+		Author:   "quant-desk",
+		Version:  "v1.1.0",
+	}
+	assert.Equal(t, want, tpl.Meta())
+	out, err := tpl.Render(map[string]any{"Name": "BTC"})
+	require.NoError(t, err)
+	assert.Equal(t, "BTC", out, "front-matter is stripped before parsing")
+
+	meta, err := LoadTemplateMeta(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, meta)
+	version, err := ExtractTemplateVersion(path, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0", version)
+}
+
+func TestPromptTemplateFrontMatterFuncs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+		return path
+	}
+
+	_, err := NewPromptTemplate(write("undeclared.tmpl", "---\nfuncs: [printf]\n---\n{{ len .Items }}"), nil)
+	assert.ErrorContains(t, err, `function "len" is not allowed`)
+
+	_, err = NewPromptTemplate(write("missing.tmpl", "---\nfuncs: [toUpper]\n---\nplain"), nil)
+	assert.ErrorContains(t, err, `front-matter requires function "toUpper"`)
+
+	_, err = NewPromptTemplate(write("open.tmpl", "---\nauthor: x\n"), nil)
+	assert.ErrorContains(t, err, "missing closing")
+
+	tpl, err := NewPromptTemplate(write("plain.tmpl", "--- not front-matter\n{{ len .Items }}"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, TemplateMeta{}, tpl.Meta())
+}
+
+func TestPromptTemplateFrontMatterDelims(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+		return path
+	}
+
+	tpl, err := NewPromptTemplate(write("delims.tmpl", "---\ndelims: [\"[[\", \"]]\"]\n---\nReply as {{ \"symbol\": \"[[ .Name ]]\" }}"), nil)
+	require.NoError(t, err)
+	left, right := tpl.Meta().ActionDelims()
+	assert.Equal(t, "[[", left)
+	assert.Equal(t, "]]", right)
+	out, err := tpl.Render(map[string]any{"Name": "BTC"})
+	require.NoError(t, err)
+	assert.Equal(t, `Reply as {{ "symbol": "BTC" }}`, out, "default delimiters are literal text")
+
+	_, err = NewPromptTemplate(write("one.tmpl", "---\ndelims: [\"[[\"]\n---\nplain"), nil)
+	assert.ErrorContains(t, err, "needs a left and a right delimiter")
+	_, err = NewPromptTemplate(write("same.tmpl", "---\ndelims: [\"%%\", \"%%\"]\n---\nplain"), nil)
+	assert.ErrorContains(t, err, "two distinct non-blank strings")
+
+	issues := LintTemplate("t.tmpl", []byte("---\ndelims: [\"<<\", \">>\"]\n---\n{{ literal }} << nope .X >>"), nil, nil)
+	require.Len(t, issues, 1)
+	assert.Equal(t, TemplateIssue{Line: 4, Col: 18, Kind: IssueFunc, Message: `function "nope" not defined`}, issues[0])
+}
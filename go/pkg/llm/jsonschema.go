@@ -0,0 +1,177 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"path"
+	"reflect"
+	"time"
+)
+
+// JSONSchemaDraft is the dialect ExportJSONSchema emits.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ExportJSONSchema describes the prompt data type t as a draft 2020-12 JSON
+// Schema, so fixture files and non-Go producers of template data can be
+// validated. Properties use Go field names, the names templates reference;
+// json tags are ignored except for "-" and omitempty. Named structs are
+// emitted once under $defs and referenced, which also covers recursive types.
+// Fields of t itself are required unless they are pointers or omitempty;
+// nested objects require nothing since templates usually read a few of their
+// fields and fixtures carry just those. A field's doc or description tag,
+// or else its Go doc comment (see WithSourceDocs), becomes its description
+// and an example tag, parsed as JSON when valid, its examples. Fields a data
+// file cannot supply (funcs, channels and non-empty interfaces) are left out.
+func ExportJSONSchema(t reflect.Type, opts ...SchemaOption) (map[string]any, error) {
+	if t == nil {
+		return nil, errors.New("schema type cannot be nil")
+	}
+	t = indirectType(t)
+	if t.Kind() != reflect.Struct {
+		return nil, errors.New("schema must be a struct, got " + t.Kind().String())
+	}
+	g := &jsonSchemaGen{defs: make(map[string]map[string]any)}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(g)
+		}
+	}
+	root := g.object(t, true)
+	root["$schema"] = JSONSchemaDraft
+	root["title"] = schemaDefName(t)
+	if len(g.defs) > 0 {
+		defs := make(map[string]any, len(g.defs))
+		for name, def := range g.defs {
+			defs[name] = def
+		}
+		root["$defs"] = defs
+	}
+	return root, nil
+}
+
+// SchemaOption customises ExportJSONSchema.
+type SchemaOption func(*jsonSchemaGen)
+
+// WithSourceDocs describes types and fields without doc tags by their Go doc
+// comments, as loaded by LoadSourceDocs.
+func WithSourceDocs(docs SourceDocs) SchemaOption {
+	return func(g *jsonSchemaGen) {
+		g.docs = docs
+	}
+}
+
+type jsonSchemaGen struct {
+	defs map[string]map[string]any
+	docs SourceDocs
+}
+
+func (g *jsonSchemaGen) schema(t reflect.Type) map[string]any {
+	t = indirectType(t)
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t, false)
+		}
+		name := schemaDefName(t)
+		if _, ok := g.defs[name]; !ok {
+			g.defs[name] = nil // placeholder so recursive references stop here
+			g.defs[name] = g.object(t, false)
+		}
+		return map[string]any{"$ref": "#/$defs/" + name}
+	}
+	return map[string]any{}
+}
+
+// object is the inline schema of struct t, with embedded structs' fields
+// promoted as text/template sees them.
+func (g *jsonSchemaGen) object(t reflect.Type, requireFields bool) map[string]any {
+	props := make(map[string]any)
+	var required []string
+	g.addFields(t, props, &required)
+	if !requireFields {
+		required = nil
+	}
+	schema := map[string]any{"type": "object", "properties": props}
+	if doc := g.docs.Lookup(t).Doc; doc != "" {
+		schema["description"] = doc
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (g *jsonSchemaGen) addFields(t reflect.Type, props map[string]any, required *[]string) {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && indirectType(f.Type).Kind() == reflect.Struct && indirectType(f.Type) != timeType {
+			embedded = append(embedded, indirectType(f.Type))
+			continue
+		}
+		if !f.IsExported() || f.Tag.Get("json") == "-" || !schemaRepresentable(f.Type) {
+			continue
+		}
+		if _, dup := props[f.Name]; dup {
+			continue
+		}
+		prop := g.schema(f.Type)
+		if desc := g.docs.FieldDescription(t, f); desc != "" {
+			prop["description"] = desc
+		}
+		if example, ok := f.Tag.Lookup("example"); ok {
+			var v any
+			if err := json.Unmarshal([]byte(example), &v); err != nil {
+				v = example
+			}
+			prop["examples"] = []any{v}
+		}
+		props[f.Name] = prop
+		if _, omitEmpty := parseJSONTag(f); !omitEmpty && f.Type.Kind() != reflect.Pointer {
+			*required = append(*required, f.Name)
+		}
+	}
+	// Promoted fields come after direct ones, which shadow them.
+	for _, e := range embedded {
+		g.addFields(e, props, required)
+	}
+}
+
+// schemaRepresentable reports whether a data file can supply a value of t.
+func schemaRepresentable(t reflect.Type) bool {
+	t = indirectType(t)
+	switch t.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return false
+	case reflect.Interface:
+		return t.NumMethod() == 0
+	}
+	return true
+}
+
+// schemaDefName names t's $defs entry after its package and type, e.g.
+// "executor.Config".
+func schemaDefName(t reflect.Type) string {
+	if t.PkgPath() == "" {
+		return t.Name()
+	}
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+type schemaNode struct {
+	Name     string `doc:"Node name" example:"root"`
+	Children []*schemaNode
+}
+
+type schemaBase struct {
+	Equity float64 `example:"1000.5"`
+}
+
+type schemaData struct {
+	schemaBase
+	At     time.Time
+	Tree   *schemaNode
+	Tags   map[string]int
+	Note   string `json:",omitempty"`
+	Hidden string `json:"-"`
+	hidden string
+	Hook   func()
+}
+
+func TestExportJSONSchema(t *testing.T) {
+	schema, err := ExportJSONSchema(reflect.TypeOf(&schemaData{}))
+	require.NoError(t, err)
+	raw, err := json.Marshal(schema)
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(raw, &doc))
+	assert.Equal(t, JSONSchemaDraft, doc["$schema"])
+	assert.Equal(t, "llm.schemaData", doc["title"])
+	assert.ElementsMatch(t, []any{"At", "Tags", "Equity"}, doc["required"])
+
+	props := doc["properties"].(map[string]any)
+	assert.Len(t, props, 5, "promoted fields are included, hidden and func fields are not")
+	assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, props["At"])
+	assert.Equal(t, []any{1000.5}, props["Equity"].(map[string]any)["examples"])
+	assert.Equal(t, map[string]any{"$ref": "#/$defs/llm.schemaNode"}, props["Tree"])
+
+	node := doc["$defs"].(map[string]any)["llm.schemaNode"].(map[string]any)
+	nodeProps := node["properties"].(map[string]any)
+	assert.Equal(t, "Node name", nodeProps["Name"].(map[string]any)["description"])
+	assert.Equal(t, []any{"root"}, nodeProps["Name"].(map[string]any)["examples"])
+	assert.Equal(t, "#/$defs/llm.schemaNode", nodeProps["Children"].(map[string]any)["items"].(map[string]any)["$ref"])
+
+	validate := func(data string) *gojsonschema.Result {
+		res, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(raw), gojsonschema.NewStringLoader(data))
+		require.NoError(t, err)
+		return res
+	}
+	assert.True(t, validate(`{"At":"2025-01-02T03:04:05Z","Tags":{"a":1},"Equity":1,"Tree":{"Name":"x","Children":[{"Name":"y","Children":[]}]}}`).Valid())
+	assert.False(t, validate(`{"At":"2025-01-02T03:04:05Z","Tags":{"a":1},"Equity":1,"Tree":{"Name":3,"Children":[]}}`).Valid())
+	assert.False(t, validate(`{"Tags":{}}`).Valid())
+
+	_, err = ExportJSONSchema(reflect.TypeOf(1))
+	assert.Error(t, err)
+}
@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Kinds of TemplateIssue.
+const (
+	IssueSyntax      = "syntax"
+	IssueFunc        = "func"
+	IssueField       = "field"
+	IssueTemplate    = "template"
+	IssueFrontMatter = "front-matter"
+)
+
+// TemplateIssue is one problem found by LintTemplate. Line and Col are
+// 1-based positions in the template file, front-matter included; Col is 0
+// when the parser only reports the line.
+type TemplateIssue struct {
+	Line    int    `json:"line"`
+	Col     int    `json:"col,omitempty"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+func (i TemplateIssue) String() string {
+	if i.Col > 0 {
+		return fmt.Sprintf("%d:%d: %s", i.Line, i.Col, i.Message)
+	}
+	return fmt.Sprintf("%d: %s", i.Line, i.Message)
+}
+
+// LintTemplate statically checks the source of a template file as
+// NewPromptTemplate would load it with funcs, reporting every problem
+// instead of the first: syntax errors, calls to functions that are neither
+// provided nor builtins (or, when front-matter lists funcs, not listed),
+// {{ template }} calls to undefined templates and, when dataType is set,
+// field references that cannot resolve against it (see ValidateFields).
+// Parsing stops at a syntax error, so it is reported alone.
+func LintTemplate(name string, src []byte, funcs template.FuncMap, dataType reflect.Type) []TemplateIssue {
+	meta, body, err := splitFrontMatter(src)
+	if err != nil {
+		return []TemplateIssue{{Line: 1, Kind: IssueFrontMatter, Message: err.Error()}}
+	}
+	offset := bytes.Count(src[:len(src)-len(body)], []byte("\n"))
+	text := string(body)
+
+	// Unknown functions are collected below with their positions rather
+	// than failing the parse.
+	root := parse.New(name)
+	root.Mode = parse.SkipFuncCheck
+	trees := make(map[string]*parse.Tree)
+	left, right := meta.ActionDelims()
+	if _, err := root.Parse(text, left, right, trees); err != nil {
+		line, msg := syntaxErrorLine(name, err)
+		if line > 0 {
+			line += offset
+		}
+		return []TemplateIssue{{Line: line, Kind: IssueSyntax, Message: msg}}
+	}
+
+	var issues []TemplateIssue
+	at := func(n parse.Node, kind, msg string) {
+		line, col := sourcePosition(text, nodeStart(n))
+		issues = append(issues, TemplateIssue{Line: line + offset, Col: col, Kind: kind, Message: msg})
+	}
+	declared := declaredFuncs(meta)
+	tmpl := template.New(name).Option("missingkey=error")
+	if len(funcs) > 0 {
+		tmpl = tmpl.Funcs(funcs)
+	}
+	names := make([]string, 0, len(trees))
+	for treeName := range trees {
+		names = append(names, treeName)
+	}
+	sort.Strings(names)
+	for _, treeName := range names {
+		tree := trees[treeName]
+		walkTemplateNodes(tree.Root, func(n parse.Node) {
+			switch x := n.(type) {
+			case *parse.IdentifierNode:
+				if _, ok := funcs[x.Ident]; !ok && !isBuiltinFunc(x.Ident) {
+					at(x, IssueFunc, fmt.Sprintf("function %q not defined", x.Ident))
+				} else if _, ok := declared[x.Ident]; declared != nil && !ok {
+					at(x, IssueFunc, fmt.Sprintf("function %q is not listed in front-matter funcs", x.Ident))
+				}
+			case *parse.TemplateNode:
+				if _, ok := trees[x.Name]; !ok {
+					at(x, IssueTemplate, fmt.Sprintf("template %q not defined", x.Name))
+				}
+			}
+		})
+		if _, err := tmpl.AddParseTree(treeName, tree); err != nil {
+			issues = append(issues, TemplateIssue{Line: 1, Kind: IssueSyntax, Message: err.Error()})
+		}
+	}
+	for _, p := range checkFields(tmpl, dataType) {
+		at(p.node, IssueField, p.msg)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Col < issues[j].Col
+	})
+	return issues
+}
+
+func isBuiltinFunc(name string) bool {
+	for _, b := range builtinTemplateFuncs {
+		if b == name {
+			return true
+		}
+	}
+	return false
+}
+
+// syntaxErrorLine splits a parse error, formatted "template: NAME:LINE:
+// MSG", into its line and message. The line is 0 when absent.
+func syntaxErrorLine(name string, err error) (int, string) {
+	msg := err.Error()
+	rest, ok := strings.CutPrefix(msg, "template: "+name+":")
+	if !ok {
+		return 0, msg
+	}
+	lineStr, detail, ok := strings.Cut(rest, ": ")
+	if !ok {
+		return 0, msg
+	}
+	line, convErr := strconv.Atoi(lineStr)
+	if convErr != nil {
+		return 0, msg
+	}
+	return line, detail
+}
+
+// nodeStart is the offset n starts at. The parser positions a chained field
+// reference such as .Account.Equity at its last segment.
+func nodeStart(n parse.Node) int {
+	pos := int(n.Position())
+	if f, ok := n.(*parse.FieldNode); ok && len(f.Ident) > 1 {
+		pos -= len(f.String()) - len(f.Ident[len(f.Ident)-1]) - 1
+	}
+	return pos
+}
+
+// sourcePosition converts a byte offset in text to a 1-based line and column.
+func sourcePosition(text string, pos int) (int, int) {
+	if pos > len(text) {
+		pos = len(text)
+	}
+	before := text[:pos]
+	line := 1 + strings.Count(before, "\n")
+	col := pos - (strings.LastIndex(before, "\n") + 1) + 1
+	return line, col
+}
@@ -0,0 +1,55 @@
+package llm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lintData struct {
+	Account struct{ Equity float64 }
+	Symbols []string
+}
+
+func TestLintTemplateReportsEveryProblem(t *testing.T) {
+	src := strings.Join([]string{
+		"---",
+		"version: v1",
+		"---",
+		"Equity {{ .Acount.Equity }}",
+		"{{ range .Symbols }}{{ upper . }}{{ end }}",
+		"{{ template \"footer\" . }}",
+		"{{ printf \"%.2f\" .Account.Equity }}",
+	}, "\n")
+	issues := LintTemplate("t.tmpl", []byte(src), nil, reflect.TypeOf(lintData{}))
+	require.Len(t, issues, 3)
+	assert.Equal(t, TemplateIssue{Line: 4, Col: 11, Kind: IssueField, Message: `llm.lintData has no field or method "Acount"`}, issues[0])
+	assert.Equal(t, TemplateIssue{Line: 5, Col: 24, Kind: IssueFunc, Message: `function "upper" not defined`}, issues[1])
+	assert.Equal(t, IssueTemplate, issues[2].Kind)
+	assert.Equal(t, 6, issues[2].Line)
+	assert.Equal(t, `5:24: function "upper" not defined`, issues[1].String())
+
+	issues = LintTemplate("t.tmpl", []byte(src), template.FuncMap{"upper": strings.ToUpper}, nil)
+	require.Len(t, issues, 1, "fields are not checked without a data type")
+	assert.Equal(t, IssueTemplate, issues[0].Kind)
+}
+
+func TestLintTemplateSyntaxError(t *testing.T) {
+	src := "---\nversion: v1\n---\nline one\n{{ if .X }}unterminated\n"
+	issues := LintTemplate("t.tmpl", []byte(src), nil, nil)
+	require.Len(t, issues, 1)
+	assert.Equal(t, IssueSyntax, issues[0].Kind)
+	assert.Equal(t, 6, issues[0].Line, "line counts the front-matter")
+	assert.Contains(t, issues[0].Message, "unexpected EOF")
+}
+
+func TestLintTemplateFrontMatterFuncs(t *testing.T) {
+	src := "---\nfuncs: [printf]\n---\n{{ len .Symbols }} {{ printf \"%d\" 1 }}"
+	issues := LintTemplate("t.tmpl", []byte(src), nil, nil)
+	require.Len(t, issues, 1)
+	assert.Equal(t, TemplateIssue{Line: 4, Col: 4, Kind: IssueFunc, Message: `function "len" is not listed in front-matter funcs`}, issues[0])
+}
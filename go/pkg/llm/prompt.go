@@ -5,25 +5,88 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"os"
+	"io/fs"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"text/template"
+	"text/template/parse"
 )
 
+// MessageBlockPrefix marks template blocks that split a prompt into a message
+// sequence: {{ define "message:<role>:<label>" }}...{{ end }}. Blocks are sent
+// in the order they appear in the file.
+const MessageBlockPrefix = "message:"
+
+// messageBlock is a parsed message block and its offset in the source.
+type messageBlock struct {
+	name string
+	role string
+	pos  int
+}
+
+// SafeBuiltinFuncs are the text/template builtins minus call, which invokes
+// arbitrary function values found in the data. Pass them, plus any formatting
+// helpers, to WithAllowedFuncs for templates edited by untrusted users.
+var SafeBuiltinFuncs = []string{
+	"and", "or", "not", "len", "index", "slice", "print", "printf", "println",
+	"eq", "ne", "lt", "le", "gt", "ge", "html", "js", "urlquery",
+}
+
 // PromptTemplate wraps a text/template loaded from disk with optional function map.
 type PromptTemplate struct {
 	path  string
 	funcs template.FuncMap
+	// fsys, when set, is read instead of the OS filesystem.
+	fsys fs.FS
+	// allowed restricts callable functions, builtins included; nil allows all.
+	allowed map[string]struct{}
+	// pinned, when set, is the only digest the file may have.
+	pinned string
+	// counter sizes rendered prompts for RenderWithBudget; nil estimates.
+	counter TokenCounter
+	// dataType, when set, is the render data type field references are
+	// checked against on load.
+	dataType reflect.Type
 
-	mu   sync.RWMutex
-	tmpl *template.Template
-	hash string
+	mu     sync.RWMutex
+	tmpl   *template.Template
+	hash   string
+	meta   TemplateMeta
+	blocks []messageBlock
+}
+
+// PromptTemplateOption customises a PromptTemplate.
+type PromptTemplateOption func(*PromptTemplate)
+
+// WithAllowedFuncs restricts the functions the template may call, builtins
+// included, to names. A template calling anything else fails to load.
+func WithAllowedFuncs(names ...string) PromptTemplateOption {
+	return func(t *PromptTemplate) {
+		t.allowed = make(map[string]struct{}, len(names))
+		for _, name := range names {
+			t.allowed[name] = struct{}{}
+		}
+	}
+}
+
+// WithFS loads the template from fsys, e.g. an embed.FS, so binaries can
+// ship their prompts. The template path is then an fs.FS path.
+func WithFS(fsys fs.FS) PromptTemplateOption {
+	return func(t *PromptTemplate) { t.fsys = fsys }
+}
+
+// WithPinnedDigest refuses to load or reload the template unless its file
+// has the given sha256 digest (hex, optionally prefixed "sha256:"), so edits
+// to a shared file cannot silently change behaviour. Empty disables the pin.
+func WithPinnedDigest(digest string) PromptTemplateOption {
+	return func(t *PromptTemplate) { t.pinned = NormalizeDigest(digest) }
 }
 
 // NewPromptTemplate parses the template at path using the provided template functions.
-func NewPromptTemplate(path string, funcs template.FuncMap) (*PromptTemplate, error) {
+func NewPromptTemplate(path string, funcs template.FuncMap, opts ...PromptTemplateOption) (*PromptTemplate, error) {
 	if strings.TrimSpace(path) == "" {
 		return nil, fmt.Errorf("prompt template path is empty")
 	}
@@ -31,6 +94,9 @@ func NewPromptTemplate(path string, funcs template.FuncMap) (*PromptTemplate, er
 		path:  path,
 		funcs: funcs,
 	}
+	for _, opt := range opts {
+		opt(t)
+	}
 	if err := t.reload(); err != nil {
 		return nil, err
 	}
@@ -50,9 +116,56 @@ func (t *PromptTemplate) Render(data any) (string, error) {
 	if err := t.tmpl.Execute(&buf, data); err != nil {
 		return "", fmt.Errorf("execute prompt template %q: %w", t.path, err)
 	}
+	// A template made only of message blocks renders as their concatenation.
+	if len(t.blocks) > 0 && strings.TrimSpace(buf.String()) == "" {
+		msgs, err := t.renderMessages(data)
+		if err != nil {
+			return "", err
+		}
+		parts := make([]string, len(msgs))
+		for i, m := range msgs {
+			parts[i] = m.Content
+		}
+		return strings.Join(parts, "\n\n"), nil
+	}
 	return buf.String(), nil
 }
 
+// HasMessages reports whether the template defines message blocks.
+func (t *PromptTemplate) HasMessages() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.blocks) > 0
+}
+
+// RenderMessages renders each message block in source order. Blocks that
+// render to whitespace are dropped. It returns nil when the template defines
+// no message blocks; callers then fall back to Render.
+func (t *PromptTemplate) RenderMessages(data any) ([]Message, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.tmpl == nil {
+		return nil, fmt.Errorf("prompt template %q not parsed", t.path)
+	}
+	return t.renderMessages(data)
+}
+
+func (t *PromptTemplate) renderMessages(data any) ([]Message, error) {
+	var msgs []Message
+	for _, b := range t.blocks {
+		var buf bytes.Buffer
+		if err := t.tmpl.ExecuteTemplate(&buf, b.name, data); err != nil {
+			return nil, fmt.Errorf("execute prompt template %q block %q: %w", t.path, b.name, err)
+		}
+		content := strings.TrimSpace(buf.String())
+		if content == "" {
+			continue
+		}
+		msgs = append(msgs, Message{Role: b.role, Content: content})
+	}
+	return msgs, nil
+}
+
 // Reload reparses the underlying template from disk. This can be used when files change.
 func (t *PromptTemplate) Reload() error {
 	t.mu.Lock()
@@ -61,24 +174,160 @@ func (t *PromptTemplate) Reload() error {
 }
 
 func (t *PromptTemplate) reload() error {
-	data, err := os.ReadFile(t.path)
+	data, err := readTemplateFile(t.fsys, t.path)
 	if err != nil {
 		return fmt.Errorf("read prompt template %q: %w", t.path, err)
 	}
-	t.hash = computeDigest(data)
+	digest := computeDigest(data)
+	if t.pinned != "" && digest != t.pinned {
+		return fmt.Errorf("prompt template %q digest %s does not match pinned %s", t.path, digest, t.pinned)
+	}
+
+	meta, body, err := splitFrontMatter(data)
+	if err != nil {
+		return fmt.Errorf("parse prompt template %q: %w", t.path, err)
+	}
+	if err := checkRequiredFuncs(meta, t.funcs); err != nil {
+		return fmt.Errorf("parse prompt template %q: %w", t.path, err)
+	}
 
 	name := filepath.Base(t.path)
-	tmpl := template.New(name).Option("missingkey=error")
+	tmpl := template.New(name).Option("missingkey=error").Delims(meta.ActionDelims())
 	if len(t.funcs) > 0 {
 		tmpl = tmpl.Funcs(t.funcs)
 	}
-	if _, err := tmpl.Parse(string(data)); err != nil {
+	if _, err := tmpl.Parse(string(body)); err != nil {
+		return fmt.Errorf("parse prompt template %q: %w", t.path, err)
+	}
+	for _, allowed := range []map[string]struct{}{t.allowed, declaredFuncs(meta)} {
+		if allowed == nil {
+			continue
+		}
+		if err := checkAllowedFuncs(tmpl, allowed); err != nil {
+			return fmt.Errorf("parse prompt template %q: %w", t.path, err)
+		}
+	}
+	if err := ValidateFields(tmpl, t.dataType); err != nil {
+		return fmt.Errorf("parse prompt template %q: %w", t.path, err)
+	}
+	blocks, err := parseMessageBlocks(tmpl)
+	if err != nil {
 		return fmt.Errorf("parse prompt template %q: %w", t.path, err)
 	}
 	t.tmpl = tmpl
+	t.hash = digest
+	t.meta = meta
+	t.blocks = blocks
 	return nil
 }
 
+// declaredFuncs is the allow-list front-matter declares, nil when none.
+func declaredFuncs(meta TemplateMeta) map[string]struct{} {
+	if len(meta.Funcs) == 0 {
+		return nil
+	}
+	out := make(map[string]struct{}, len(meta.Funcs))
+	for _, name := range meta.Funcs {
+		out[name] = struct{}{}
+	}
+	return out
+}
+
+// checkAllowedFuncs rejects templates that call a function outside allowed.
+func checkAllowedFuncs(tmpl *template.Template, allowed map[string]struct{}) error {
+	for _, def := range tmpl.Templates() {
+		if def.Tree == nil {
+			continue
+		}
+		var denied string
+		walkTemplateNodes(def.Tree.Root, func(n parse.Node) {
+			if id, ok := n.(*parse.IdentifierNode); ok && denied == "" {
+				if _, ok := allowed[id.Ident]; !ok {
+					denied = id.Ident
+				}
+			}
+		})
+		if denied != "" {
+			return fmt.Errorf("function %q is not allowed in this template", denied)
+		}
+	}
+	return nil
+}
+
+// walkTemplateNodes visits n and every node below it.
+func walkTemplateNodes(n parse.Node, visit func(parse.Node)) {
+	if n == nil {
+		return
+	}
+	visit(n)
+	switch x := n.(type) {
+	case *parse.ListNode:
+		if x == nil {
+			return
+		}
+		for _, c := range x.Nodes {
+			walkTemplateNodes(c, visit)
+		}
+	case *parse.ActionNode:
+		walkTemplateNodes(x.Pipe, visit)
+	case *parse.PipeNode:
+		if x == nil {
+			return
+		}
+		for _, c := range x.Cmds {
+			walkTemplateNodes(c, visit)
+		}
+	case *parse.CommandNode:
+		for _, arg := range x.Args {
+			walkTemplateNodes(arg, visit)
+		}
+	case *parse.ChainNode:
+		walkTemplateNodes(x.Node, visit)
+	case *parse.IfNode:
+		walkBranch(&x.BranchNode, visit)
+	case *parse.RangeNode:
+		walkBranch(&x.BranchNode, visit)
+	case *parse.WithNode:
+		walkBranch(&x.BranchNode, visit)
+	case *parse.TemplateNode:
+		walkTemplateNodes(x.Pipe, visit)
+	}
+}
+
+func walkBranch(b *parse.BranchNode, visit func(parse.Node)) {
+	walkTemplateNodes(b.Pipe, visit)
+	walkTemplateNodes(b.List, visit)
+	walkTemplateNodes(b.ElseList, visit)
+}
+
+// parseMessageBlocks collects message blocks ordered by their position in
+// the template source.
+func parseMessageBlocks(tmpl *template.Template) ([]messageBlock, error) {
+	var blocks []messageBlock
+	for _, def := range tmpl.Templates() {
+		name := def.Name()
+		if !strings.HasPrefix(name, MessageBlockPrefix) || def.Tree == nil {
+			continue
+		}
+		role, _, _ := strings.Cut(strings.TrimPrefix(name, MessageBlockPrefix), ":")
+		switch role {
+		case "system", "developer", "user", "assistant":
+		default:
+			return nil, fmt.Errorf("message block %q: unsupported role %q", name, role)
+		}
+		blocks = append(blocks, messageBlock{name: name, role: role, pos: int(def.Tree.Root.Pos)})
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].pos < blocks[j].pos })
+	return blocks, nil
+}
+
+// Meta returns the template's front-matter; zero when it has none.
+func (t *PromptTemplate) Meta() TemplateMeta {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.meta
+}
+
 // Digest returns the sha256 hash of the template content.
 func (t *PromptTemplate) Digest() string {
 	t.mu.RLock()
@@ -91,6 +340,22 @@ func DigestString(s string) string {
 	return computeDigest([]byte(s))
 }
 
+// TemplateDigest returns the digest of the template file at path, read from
+// fsys or from disk when fsys is nil. It matches PromptTemplate.Digest.
+func TemplateDigest(fsys fs.FS, path string) (string, error) {
+	data, err := readTemplateFile(fsys, path)
+	if err != nil {
+		return "", err
+	}
+	return computeDigest(data), nil
+}
+
+// NormalizeDigest lowercases digest and strips an optional "sha256:" prefix.
+func NormalizeDigest(digest string) string {
+	digest = strings.ToLower(strings.TrimSpace(digest))
+	return strings.TrimPrefix(digest, "sha256:")
+}
+
 func computeDigest(data []byte) string {
 	sum := sha256.Sum256(data)
 	return hex.EncodeToString(sum[:])
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"text/template"
 
 	"github.com/stretchr/testify/assert"
@@ -58,3 +59,117 @@ func TestPromptTemplateReload(t *testing.T) {
 	digestV2 := tpl.Digest()
 	assert.NotEqual(t, digestV1, digestV2, "digest should change after reload")
 }
+
+func TestPromptTemplateRenderMessages(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "split.tmpl")
+	body := `{{ define "message:user:market" }}
+price={{ .Price }}
+{{ end }}{{ define "message:system:rules" }}Be careful.{{ end }}{{ define "message:user:empty" }}  {{ end }}{{ define "helper" }}x{{ end }}`
+	err := os.WriteFile(templatePath, []byte(body), 0o600)
+	assert.NoError(t, err, "write template should succeed")
+
+	tpl, err := NewPromptTemplate(templatePath, nil)
+	assert.NoError(t, err, "NewPromptTemplate should not error")
+	assert.True(t, tpl.HasMessages())
+
+	msgs, err := tpl.RenderMessages(map[string]any{"Price": 42})
+	assert.NoError(t, err, "RenderMessages should not error")
+	assert.Equal(t, []Message{
+		{Role: "user", Content: "price=42"},
+		{Role: "system", Content: "Be careful."},
+	}, msgs, "blocks follow source order and empty blocks are dropped")
+
+	// With no body outside the blocks, Render joins the messages.
+	out, err := tpl.Render(map[string]any{"Price": 42})
+	assert.NoError(t, err, "Render should not error")
+	assert.Equal(t, "price=42\n\nBe careful.", out)
+}
+
+func TestPromptTemplateMessageBlockRole(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "bad.tmpl")
+	err := os.WriteFile(templatePath, []byte(`{{ define "message:robot:x" }}hi{{ end }}`), 0o600)
+	assert.NoError(t, err, "write template should succeed")
+
+	_, err = NewPromptTemplate(templatePath, nil)
+	assert.ErrorContains(t, err, `unsupported role "robot"`)
+
+	plain := filepath.Join(dir, "plain.tmpl")
+	assert.NoError(t, os.WriteFile(plain, []byte("hello"), 0o600))
+	tpl, err := NewPromptTemplate(plain, nil)
+	assert.NoError(t, err)
+	assert.False(t, tpl.HasMessages())
+	msgs, err := tpl.RenderMessages(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, msgs)
+}
+
+func TestPromptTemplateAllowedFuncs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+		return path
+	}
+	funcs := template.FuncMap{"toUpper": strings.ToUpper, "readFile": os.ReadFile}
+	allowed := WithAllowedFuncs(append([]string{"toUpper"}, SafeBuiltinFuncs...)...)
+
+	ok := write("ok.tmpl", `{{ range .Items }}{{ if gt (len .) 1 }}{{ toUpper . | printf "%s;" }}{{ end }}{{ end }}`)
+	tpl, err := NewPromptTemplate(ok, funcs, allowed)
+	assert.NoError(t, err)
+	out, err := tpl.Render(map[string]any{"Items": []string{"a", "bc"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "BC;", out)
+
+	include := write("include.tmpl", `{{ define "x" }}{{ with .Path }}{{ readFile . }}{{ end }}{{ end }}ok`)
+	_, err = NewPromptTemplate(include, funcs, allowed)
+	assert.ErrorContains(t, err, `function "readFile" is not allowed in this template`)
+	_, err = NewPromptTemplate(include, funcs)
+	assert.NoError(t, err, "trusted templates keep every function")
+
+	call := write("call.tmpl", `{{ call .Fn }}`)
+	_, err = NewPromptTemplate(call, nil, allowed)
+	assert.ErrorContains(t, err, `function "call" is not allowed`)
+}
+
+func TestPromptTemplateFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"prompts/example.tmpl": {Data: []byte("{{/* Version: v2 */}}hello {{ .Name }}")},
+	}
+	tpl, err := NewPromptTemplate("prompts/example.tmpl", nil, WithFS(fsys))
+	assert.NoError(t, err)
+	out, err := tpl.Render(map[string]any{"Name": "Alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, "hello Alice", out)
+
+	guard := TemplateVersionGuard{Component: "test", ExpectedVersion: "v2", RequireVersionHeader: true, StrictMode: true, FS: fsys}
+	version, err := guard.Enforce("prompts/example.tmpl")
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", version)
+
+	_, err = NewPromptTemplate("prompts/missing.tmpl", nil, WithFS(fsys))
+	assert.Error(t, err, "paths resolve inside the FS, not on disk")
+}
+
+func TestPromptTemplatePinnedDigest(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "pinned.tmpl")
+	assert.NoError(t, os.WriteFile(templatePath, []byte("v1"), 0o600))
+	digest, err := TemplateDigest(nil, templatePath)
+	assert.NoError(t, err)
+
+	tpl, err := NewPromptTemplate(templatePath, nil, WithPinnedDigest("sha256:"+strings.ToUpper(digest)))
+	assert.NoError(t, err, "matching pin should load")
+	assert.Equal(t, digest, tpl.Digest())
+
+	assert.NoError(t, os.WriteFile(templatePath, []byte("v2"), 0o600))
+	err = tpl.Reload()
+	assert.ErrorContains(t, err, "does not match pinned")
+	out, err := tpl.Render(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", out, "failed reload keeps the pinned version")
+
+	_, err = NewPromptTemplate(templatePath, nil, WithPinnedDigest(digest))
+	assert.Error(t, err, "edited file should not load against the old pin")
+}
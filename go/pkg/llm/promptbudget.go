@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrPromptOverBudget reports that a prompt does not fit its token budget
+// even with its time series trimmed as far as they go.
+var ErrPromptOverBudget = errors.New("llm: prompt exceeds token budget")
+
+// SeriesTrimmer is implemented by template data carrying time series that
+// RenderWithBudget may shorten to fit a token budget.
+type SeriesTrimmer interface {
+	// SeriesLen is the number of points in the longest series.
+	SeriesLen() int
+	// TrimSeries returns a copy of the data keeping at most the newest n
+	// points of every series.
+	TrimSeries(n int) any
+}
+
+// WithTokenCounter sizes rendered prompts with c, typically the model's
+// NewTokenCounter, instead of the model-agnostic EstimateTokens.
+func WithTokenCounter(c TokenCounter) PromptTemplateOption {
+	return func(t *PromptTemplate) { t.counter = c }
+}
+
+// CountTokens counts text with the template's token counter.
+func (t *PromptTemplate) CountTokens(text string) int {
+	if t.counter == nil {
+		return EstimateTokens(text)
+	}
+	return t.counter.CountTokens(text)
+}
+
+// BudgetedPrompt is a prompt rendered within a token budget.
+type BudgetedPrompt struct {
+	Prompt string
+	Tokens int
+	// Data is what was rendered: the caller's data, or its trimmed copy.
+	Data any
+	// SeriesLen is the series window kept, zero when nothing was trimmed.
+	SeriesLen int
+}
+
+// RenderWithBudget renders data and, when the prompt exceeds maxTokens and
+// data is a SeriesTrimmer, drops the oldest series points until it fits,
+// keeping at least the newest point. A non-positive maxTokens renders in
+// full. When nothing fits the error wraps ErrPromptOverBudget and Tokens is
+// that of the smallest rendering tried.
+func (t *PromptTemplate) RenderWithBudget(data any, maxTokens int) (BudgetedPrompt, error) {
+	out, err := t.Render(data)
+	if err != nil {
+		return BudgetedPrompt{}, err
+	}
+	full := BudgetedPrompt{Prompt: out, Tokens: t.CountTokens(out), Data: data}
+	if maxTokens <= 0 || full.Tokens <= maxTokens {
+		return full, nil
+	}
+	trimmer, ok := data.(SeriesTrimmer)
+	if !ok || trimmer.SeriesLen() <= 1 {
+		return BudgetedPrompt{Tokens: full.Tokens}, fmt.Errorf("%w: ~%d tokens, budget is %d", ErrPromptOverBudget, full.Tokens, maxTokens)
+	}
+
+	// Rendered size grows with the series length, so binary search for the
+	// longest window that fits. Renders are cached so the winning window is
+	// not rendered twice.
+	renders := map[int]BudgetedPrompt{}
+	var renderErr error
+	render := func(n int) BudgetedPrompt {
+		if r, ok := renders[n]; ok {
+			return r
+		}
+		trimmed := trimmer.TrimSeries(n)
+		out, err := t.Render(trimmed)
+		if err != nil && renderErr == nil {
+			renderErr = err
+		}
+		r := BudgetedPrompt{Prompt: out, Tokens: t.CountTokens(out), Data: trimmed, SeriesLen: n}
+		renders[n] = r
+		return r
+	}
+	longest := trimmer.SeriesLen()
+	// Index i of the search is window longest-1-i, so the first fitting
+	// index is the longest fitting window.
+	i := sort.Search(longest-1, func(i int) bool {
+		return render(longest-1-i).Tokens <= maxTokens
+	})
+	if renderErr != nil {
+		return BudgetedPrompt{}, renderErr
+	}
+	if i == longest-1 {
+		smallest := render(1).Tokens
+		return BudgetedPrompt{Tokens: smallest}, fmt.Errorf("%w: ~%d tokens with one series point, budget is %d", ErrPromptOverBudget, smallest, maxTokens)
+	}
+	return render(longest - 1 - i), nil
+}
@@ -0,0 +1,71 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seriesData renders one value per point and trims the oldest points.
+type seriesData struct {
+	Points []string
+}
+
+func (d seriesData) SeriesLen() int { return len(d.Points) }
+
+func (d seriesData) TrimSeries(n int) any {
+	if n < len(d.Points) {
+		d.Points = d.Points[len(d.Points)-n:]
+	}
+	return d
+}
+
+func newBudgetTemplate(t *testing.T) *PromptTemplate {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "budget.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("series:{{ range .Points }} {{ . }}{{ end }}"), 0o600))
+	// One token per byte keeps the arithmetic readable.
+	tpl, err := NewPromptTemplate(path, nil, WithTokenCounter(ApproxCounter(1)))
+	require.NoError(t, err)
+	return tpl
+}
+
+func TestRenderWithBudgetTrimsOldestPoints(t *testing.T) {
+	tpl := newBudgetTemplate(t)
+	data := seriesData{Points: []string{"p1", "p2", "p3", "p4", "p5"}}
+
+	out, err := tpl.RenderWithBudget(data, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "series: p1 p2 p3 p4 p5", out.Prompt)
+	assert.Zero(t, out.SeriesLen)
+
+	// "series:" is 7 tokens and each point 3 more.
+	out, err = tpl.RenderWithBudget(data, 7+3*3+2)
+	require.NoError(t, err)
+	assert.Equal(t, "series: p3 p4 p5", out.Prompt)
+	assert.Equal(t, 16, out.Tokens)
+	assert.Equal(t, 3, out.SeriesLen)
+	assert.Equal(t, []string{"p3", "p4", "p5"}, out.Data.(seriesData).Points)
+
+	out, err = tpl.RenderWithBudget(data, 9)
+	assert.ErrorIs(t, err, ErrPromptOverBudget)
+	assert.Equal(t, 10, out.Tokens, "reports the one-point rendering")
+	assert.Empty(t, out.Prompt)
+}
+
+func TestRenderWithBudgetWithoutSeries(t *testing.T) {
+	tpl := newBudgetTemplate(t)
+	data := map[string]any{"Points": strings.Fields("a b c")}
+
+	out, err := tpl.RenderWithBudget(data, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "series: a b c", out.Prompt)
+
+	out, err = tpl.RenderWithBudget(data, 5)
+	assert.ErrorIs(t, err, ErrPromptOverBudget)
+	assert.Equal(t, 13, out.Tokens)
+}
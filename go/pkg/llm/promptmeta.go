@@ -2,6 +2,7 @@ package llm
 
 import (
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"regexp"
@@ -20,6 +21,8 @@ type TemplateVersionGuard struct {
 	StrictMode           bool
 	ScanLimit            int
 	Logger               func(format string, args ...any)
+	// FS, when set, is where template paths are read from instead of disk.
+	FS fs.FS
 }
 
 // Enforce validates the template at templatePath meets the guard expectations and returns the parsed version.
@@ -28,7 +31,7 @@ func (g TemplateVersionGuard) Enforce(templatePath string) (string, error) {
 	if templatePath == "" {
 		return "", fmt.Errorf("prompt template path is empty")
 	}
-	version, err := ExtractTemplateVersion(templatePath, g.scanLimit())
+	version, err := ExtractTemplateVersionFS(g.FS, templatePath, g.scanLimit())
 	if err != nil {
 		if g.RequireVersionHeader {
 			return "", err
@@ -50,7 +53,13 @@ func (g TemplateVersionGuard) Enforce(templatePath string) (string, error) {
 // ExtractTemplateVersion scans the file for a {{/* Version: ... */}} style header.
 
 func ExtractTemplateVersion(templatePath string, scanLimit int) (string, error) {
-	data, err := os.ReadFile(templatePath)
+	return ExtractTemplateVersionFS(nil, templatePath, scanLimit)
+}
+
+// ExtractTemplateVersionFS is ExtractTemplateVersion reading from fsys, or
+// from disk when fsys is nil.
+func ExtractTemplateVersionFS(fsys fs.FS, templatePath string, scanLimit int) (string, error) {
+	data, err := readTemplateFile(fsys, templatePath)
 	if err != nil {
 		return "", fmt.Errorf("read prompt template %q: %w", templatePath, err)
 	}
@@ -68,6 +77,14 @@ func ExtractTemplateVersion(templatePath string, scanLimit int) (string, error)
 	return strings.TrimSpace(matches[1]), nil
 }
 
+// readTemplateFile reads path from fsys, or from disk when fsys is nil.
+func readTemplateFile(fsys fs.FS, path string) ([]byte, error) {
+	if fsys == nil {
+		return os.ReadFile(path)
+	}
+	return fs.ReadFile(fsys, path)
+}
+
 func (g TemplateVersionGuard) scanLimit() int {
 	if g.ScanLimit > 0 {
 		return g.ScanLimit
@@ -118,3 +118,10 @@ func shouldRetry(err error) bool {
 
 	return false
 }
+
+// IsRateLimited reports whether err is the provider rejecting the request
+// with HTTP 429, after any retries were spent.
+func IsRateLimited(err error) bool {
+	var apiErr *openai.Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests
+}
@@ -3,6 +3,7 @@ package llm
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"testing"
@@ -239,6 +240,13 @@ func TestShouldRetry(t *testing.T) {
 	})
 }
 
+func TestIsRateLimited(t *testing.T) {
+	wrapped := fmt.Errorf("chat: %w", &openai.Error{StatusCode: http.StatusTooManyRequests})
+	require.True(t, IsRateLimited(wrapped))
+	require.False(t, IsRateLimited(&openai.Error{StatusCode: http.StatusBadGateway}))
+	require.False(t, IsRateLimited(ErrBudgetExceeded))
+}
+
 // Mock types for testing net.Error interface
 type temporaryError struct {
 	msg string
@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// TypeDoc is the Go doc comment of a named struct type and of its fields.
+type TypeDoc struct {
+	Doc    string
+	Fields map[string]string
+}
+
+// SourceDocs indexes TypeDocs by package name and type name, e.g.
+// "executor.PromptInputs", so doc comments written once in Go source
+// describe template data without being repeated in doc tags.
+type SourceDocs map[string]TypeDoc
+
+// LoadSourceDocs reads the doc comments of the struct types declared in the
+// Go packages at dirs. Test files are skipped.
+func LoadSourceDocs(dirs ...string) (SourceDocs, error) {
+	docs := make(SourceDocs)
+	fset := token.NewFileSet()
+	for _, dir := range dirs {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("source docs: no Go files in %s", dir)
+		}
+		for _, path := range paths {
+			if strings.HasSuffix(path, "_test.go") {
+				continue
+			}
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("source docs: %w", err)
+			}
+			file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+			if err != nil {
+				return nil, fmt.Errorf("source docs: %w", err)
+			}
+			docs.addFile(file)
+		}
+	}
+	return docs, nil
+}
+
+func (d SourceDocs) addFile(file *ast.File) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			td := TypeDoc{Doc: extractTypeDoc(gen, ts), Fields: make(map[string]string)}
+			for _, field := range st.Fields.List {
+				doc := extractFieldDoc(field)
+				if doc == "" {
+					continue
+				}
+				for _, name := range field.Names {
+					td.Fields[name.Name] = doc
+				}
+				if len(field.Names) == 0 {
+					td.Fields[embeddedName(field.Type)] = doc
+				}
+			}
+			d[file.Name.Name+"."+ts.Name.Name] = td
+		}
+	}
+}
+
+// extractTypeDoc returns the doc comment of ts: its own inside a grouped
+// type (...) declaration, otherwise the declaration's.
+func extractTypeDoc(gen *ast.GenDecl, ts *ast.TypeSpec) string {
+	if ts.Doc != nil {
+		return docText(ts.Doc)
+	}
+	if len(gen.Specs) == 1 {
+		return docText(gen.Doc)
+	}
+	return ""
+}
+
+// extractFieldDoc returns the comment above field, or failing that the one
+// trailing it on the same line.
+func extractFieldDoc(field *ast.Field) string {
+	if field.Doc != nil {
+		return docText(field.Doc)
+	}
+	return docText(field.Comment)
+}
+
+// docText flattens a comment group into one line for schema descriptions.
+func docText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(group.Text()), " ")
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+// Lookup returns the documentation of the named type t, if loaded.
+func (d SourceDocs) Lookup(t reflect.Type) TypeDoc {
+	t = indirectType(t)
+	if d == nil || t == nil || t.Name() == "" {
+		return TypeDoc{}
+	}
+	return d[schemaDefName(t)]
+}
+
+// FieldDescription describes field f of struct owner: its doc tag, else its
+// description tag, else its Go doc comment from d.
+func (d SourceDocs) FieldDescription(owner reflect.Type, f reflect.StructField) string {
+	if doc := f.Tag.Get("doc"); doc != "" {
+		return doc
+	}
+	if desc := f.Tag.Get("description"); desc != "" {
+		return desc
+	}
+	return d.Lookup(owner).Fields[f.Name]
+}
@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type docBase struct {
+	Equity float64
+}
+
+type docData struct {
+	docBase
+	Symbol string
+	Side   string `doc:"Tag wins"`
+	Lev    int
+}
+
+const docSource = `package llm
+
+type (
+	// docBase is embedded.
+	docBase struct {
+		// Equity is the account value
+		// in USD.
+		Equity float64
+	}
+)
+
+// docData is the data a
+// test template renders.
+type docData struct {
+	docBase
+	Symbol string // Coin ticker, e.g. BTC
+	Side   string // overridden by the doc tag
+	Lev    int
+}
+`
+
+func TestLoadSourceDocs(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data.go"), []byte(docSource), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data_test.go"), []byte("package llm\n\n// ignored\ntype docData struct{}\n"), 0o644))
+
+	docs, err := LoadSourceDocs(dir)
+	require.NoError(t, err)
+	data := docs.Lookup(reflect.TypeOf(&docData{}))
+	assert.Equal(t, "docData is the data a test template renders.", data.Doc)
+	assert.Equal(t, "Coin ticker, e.g. BTC", data.Fields["Symbol"])
+	assert.Equal(t, "docBase is embedded.", docs.Lookup(reflect.TypeOf(docBase{})).Doc)
+
+	schema, err := ExportJSONSchema(reflect.TypeOf(docData{}), WithSourceDocs(docs))
+	require.NoError(t, err)
+	assert.Equal(t, "docData is the data a test template renders.", schema["description"])
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, "Coin ticker, e.g. BTC", props["Symbol"].(map[string]any)["description"])
+	assert.Equal(t, "Tag wins", props["Side"].(map[string]any)["description"])
+	assert.Equal(t, "Equity is the account value in USD.", props["Equity"].(map[string]any)["description"], "promoted fields use the embedded type's docs")
+	assert.NotContains(t, props["Lev"], "description")
+
+	_, err = LoadSourceDocs(t.TempDir())
+	assert.ErrorContains(t, err, "no Go files")
+}
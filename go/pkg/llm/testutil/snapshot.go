@@ -0,0 +1,90 @@
+// Package testutil provides golden-file snapshot tests for prompt templates.
+//
+// SnapshotTest renders a template and compares the output with a golden file
+// checked in next to the test, printing a unified diff on mismatch. Run the
+// tests with -update to rewrite the golden files after an intended change:
+//
+//	go test ./pkg/executor -run TestPromptRenderer -update
+package testutil
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"nof0-api/pkg/llm"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current template output")
+
+// Engine renders the template tmpl with data.
+type Engine interface {
+	Render(tmpl string, data any) (string, error)
+}
+
+// EngineFunc adapts a function to Engine, e.g. to call a typed renderer:
+//
+//	testutil.EngineFunc(func(_ string, data any) (string, error) {
+//		return renderer.Render(data.(PromptInputs))
+//	})
+type EngineFunc func(tmpl string, data any) (string, error)
+
+// Render implements Engine.
+func (f EngineFunc) Render(tmpl string, data any) (string, error) { return f(tmpl, data) }
+
+// Files returns an Engine that loads tmpl as a template file path, front
+// matter included, the way llm.NewPromptTemplate does with funcs.
+func Files(funcs template.FuncMap) Engine {
+	return EngineFunc(func(tmpl string, data any) (string, error) {
+		t, err := llm.NewPromptTemplate(tmpl, funcs)
+		if err != nil {
+			return "", err
+		}
+		return t.Render(data)
+	})
+}
+
+// SnapshotTest renders tmpl with data through engine and compares the output
+// with the golden file at goldenPath, or rewrites the file under -update.
+func SnapshotTest(t testing.TB, engine Engine, tmpl string, data any, goldenPath string) {
+	t.Helper()
+	got, err := engine.Render(tmpl, data)
+	if err != nil {
+		t.Fatalf("render %s: %v", tmpl, err)
+	}
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			t.Fatalf("update golden %s: %v", goldenPath, err)
+		}
+		if err := os.WriteFile(goldenPath, []byte(got), 0o644); err != nil {
+			t.Fatalf("update golden %s: %v", goldenPath, err)
+		}
+		t.Logf("updated golden %s", goldenPath)
+		return
+	}
+	raw, err := os.ReadFile(goldenPath)
+	if os.IsNotExist(err) {
+		t.Fatalf("golden %s does not exist; run the test with -update to create it", goldenPath)
+	}
+	if err != nil {
+		t.Fatalf("read golden %s: %v", goldenPath, err)
+	}
+	// Checkouts with autocrlf must not fail every snapshot.
+	want := string(bytes.ReplaceAll(raw, []byte("\r\n"), []byte("\n")))
+	if got == want {
+		return
+	}
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(want),
+		B:        difflib.SplitLines(got),
+		FromFile: goldenPath,
+		ToFile:   "rendered " + tmpl,
+		Context:  3,
+	})
+	t.Errorf("%s does not match its golden file (run with -update if the change is intended):\n%s", tmpl, diff)
+}
@@ -0,0 +1,74 @@
+package testutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder captures failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  string
+}
+
+func (r *recorder) Helper()             {}
+func (r *recorder) Logf(string, ...any) {}
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.fatal = fmt.Sprintf(format, args...)
+	panic(r)
+}
+
+func run(tb testing.TB, fn func(testing.TB)) (r *recorder) {
+	r = &recorder{TB: tb}
+	defer func() {
+		if v := recover(); v != nil && v != r {
+			panic(v)
+		}
+	}()
+	fn(r)
+	return r
+}
+
+func TestSnapshotTestComparesAndUpdates(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "greet.tmpl")
+	golden := filepath.Join(dir, "testdata", "greet.golden")
+	require.NoError(t, os.WriteFile(tmpl, []byte("hello {{ .Name }}\nbye\n"), 0o644))
+	data := map[string]any{"Name": "nof0"}
+
+	r := run(t, func(tb testing.TB) { SnapshotTest(tb, Files(nil), tmpl, data, golden) })
+	assert.Contains(t, r.fatal, "run the test with -update")
+
+	*update = true
+	r = run(t, func(tb testing.TB) { SnapshotTest(tb, Files(nil), tmpl, data, golden) })
+	*update = false
+	require.Empty(t, r.fatal)
+	raw, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, "hello nof0\nbye\n", string(raw))
+
+	r = run(t, func(tb testing.TB) { SnapshotTest(tb, Files(nil), tmpl, data, golden) })
+	assert.Empty(t, r.errors)
+	assert.Empty(t, r.fatal)
+
+	r = run(t, func(tb testing.TB) { SnapshotTest(tb, Files(nil), tmpl, map[string]any{"Name": "x"}, golden) })
+	require.Len(t, r.errors, 1)
+	assert.Contains(t, r.errors[0], "-hello nof0\n+hello x\n bye\n")
+
+	upper := EngineFunc(func(_ string, data any) (string, error) {
+		return strings.ToUpper(data.(string)), nil
+	})
+	require.NoError(t, os.WriteFile(golden, []byte("HI\r\nTHERE"), 0o644))
+	r = run(t, func(tb testing.TB) { SnapshotTest(tb, upper, "inline", "hi\nthere", golden) })
+	assert.Empty(t, r.errors, "CRLF golden files compare equal")
+}
@@ -0,0 +1,242 @@
+package llm
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+// charsPerToken is a coarse, model-agnostic heuristic. It overestimates for
+// English prose and underestimates for dense JSON, which is good enough for
+// deciding when a prompt is close to a model's window.
+const charsPerToken = 4
+
+// EstimateTokens approximates the token count of text.
+func EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return (len([]rune(text)) + charsPerToken - 1) / charsPerToken
+}
+
+// Vision pricing follows the OpenAI tile model: low detail is a flat charge,
+// high detail scales the image to fit 2048x2048 with the short side at most
+// 768px and bills each 512px tile on top of the base charge.
+const (
+	imageBaseTokens = 85
+	imageTileTokens = 170
+	imageTileSize   = 512
+)
+
+// EstimateImageTokens approximates the prompt tokens billed for an image.
+// Unknown dimensions are billed at the low detail rate.
+func EstimateImageTokens(width, height int, detail string) int {
+	if strings.EqualFold(detail, "low") || width <= 0 || height <= 0 {
+		return imageBaseTokens
+	}
+	w, h := float64(width), float64(height)
+	if scale := 2048 / math.Max(w, h); scale < 1 {
+		w, h = w*scale, h*scale
+	}
+	if scale := 768 / math.Min(w, h); scale < 1 {
+		w, h = w*scale, h*scale
+	}
+	tiles := int(math.Ceil(w/imageTileSize)) * int(math.Ceil(h/imageTileSize))
+	return imageBaseTokens + tiles*imageTileTokens
+}
+
+// EstimateMessageImageTokens sums the image token estimate across messages.
+func EstimateMessageImageTokens(msgs []Message) (images int, tokens int) {
+	for _, m := range msgs {
+		for _, img := range m.Images {
+			images++
+			tokens += EstimateImageTokens(img.Width, img.Height, img.Detail)
+		}
+	}
+	return images, tokens
+}
+
+// TokenCounter counts the prompt tokens a model bills for text.
+type TokenCounter interface {
+	CountTokens(text string) int
+}
+
+// ApproxCounter estimates tokens as runes divided by a characters-per-token
+// ratio; zero uses the model-agnostic charsPerToken.
+type ApproxCounter float64
+
+func (c ApproxCounter) CountTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	ratio := float64(c)
+	if ratio <= 0 {
+		ratio = charsPerToken
+	}
+	return int(math.Ceil(float64(utf8.RuneCountInString(text)) / ratio))
+}
+
+// providerCharsPerToken holds rough characters-per-token ratios for
+// providers without a public tokenizer, measured on prompt-like text (prose
+// mixed with numbers and JSON). Unlisted providers use charsPerToken.
+var providerCharsPerToken = map[string]float64{
+	"anthropic": 3.5,
+	"deepseek":  3.3,
+	"google":    4,
+	"qwen":      3.3,
+}
+
+// NewTokenCounter returns the counter for a model in provider/model form.
+// OpenAI models are counted exactly with their tiktoken BPE encoding once
+// LoadTokenizers has loaded it, and approximated otherwise; other providers
+// use a per-provider approximation.
+func NewTokenCounter(model string) TokenCounter {
+	if name, ok := openAIModelName(model); ok {
+		if enc, ok := bpeEncodings.Load(openAIEncoding(name)); ok {
+			return bpeCounter{enc: enc.(tokenEncoder)}
+		}
+		return ApproxCounter(charsPerToken)
+	}
+	provider, _ := ParseModelID(strings.TrimSpace(model))
+	return ApproxCounter(providerCharsPerToken[strings.ToLower(provider)])
+}
+
+// openAIModelName returns the model name of an OpenAI model ID.
+func openAIModelName(model string) (string, bool) {
+	provider, name := ParseModelID(strings.TrimSpace(model))
+	provider = strings.ToLower(provider)
+	return name, provider == "openai" || (provider == "" && isOpenAIModel(name))
+}
+
+func isOpenAIModel(name string) bool {
+	name = strings.ToLower(name)
+	for _, prefix := range []string{"gpt-", "o1", "o3", "o4", "text-embedding-"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// openAIEncoding picks the tiktoken encoding for an OpenAI model. Models
+// newer than tiktoken's table use o200k_base, the encoding of every model
+// since gpt-4o.
+func openAIEncoding(name string) string {
+	name = strings.ToLower(name)
+	if enc, ok := tiktoken.MODEL_TO_ENCODING[name]; ok {
+		return enc
+	}
+	for prefix, enc := range tiktoken.MODEL_PREFIX_TO_ENCODING {
+		if strings.HasPrefix(name, prefix) {
+			return enc
+		}
+	}
+	return tiktoken.MODEL_O200K_BASE
+}
+
+// tokenEncoder is the part of a tiktoken encoding bpeCounter uses.
+type tokenEncoder interface {
+	EncodeOrdinary(text string) []int
+}
+
+// bpeEncodings holds the encodings LoadTokenizers loaded, by name.
+var bpeEncodings sync.Map // encoding name -> tokenEncoder
+
+// loadMu serializes loads, which swap tiktoken's package-level BPE loader.
+var loadMu sync.Mutex
+
+// loadEncoding is replaced in tests.
+var loadEncoding = func(dir, name string) (tokenEncoder, error) {
+	tiktoken.SetBpeLoader(localBpeLoader(dir))
+	return tiktoken.GetEncoding(name)
+}
+
+func init() {
+	// tiktoken downloads missing encodings by default; only read local files.
+	tiktoken.SetBpeLoader(localBpeLoader(""))
+}
+
+// LoadTokenizers loads the tiktoken encodings of the OpenAI models among
+// models (provider/model IDs) from dir, which holds the .tiktoken files
+// OpenAI publishes (o200k_base.tiktoken, cl100k_base.tiktoken). Nothing is
+// downloaded: a missing or unreadable file is an error, so a deployment
+// without its tokenizer files fails at startup instead of counting
+// approximately. An empty dir loads nothing.
+func LoadTokenizers(dir string, models ...string) error {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return nil
+	}
+	loadMu.Lock()
+	defer loadMu.Unlock()
+	for _, model := range models {
+		name, ok := openAIModelName(model)
+		if !ok {
+			continue
+		}
+		encoding := openAIEncoding(name)
+		if _, ok := bpeEncodings.Load(encoding); ok {
+			continue
+		}
+		enc, err := loadEncoding(dir, encoding)
+		if err != nil {
+			return fmt.Errorf("load %s tokenizer for %s: %w", encoding, model, err)
+		}
+		bpeEncodings.Store(encoding, enc)
+	}
+	return nil
+}
+
+// localBpeLoader reads tiktoken BPE files from a directory, by the base name
+// of the URL tiktoken asks for.
+type localBpeLoader string
+
+func (dir localBpeLoader) LoadTiktokenBpe(url string) (map[string]int, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("no tokenizer directory configured for %s", path.Base(url))
+	}
+	data, err := os.ReadFile(filepath.Join(string(dir), path.Base(url)))
+	if err != nil {
+		return nil, err
+	}
+	ranks := make(map[string]int)
+	for n, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		token, rank, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("%s line %d: want \"<base64 token> <rank>\"", path.Base(url), n+1)
+		}
+		raw, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path.Base(url), n+1, err)
+		}
+		r, err := strconv.Atoi(rank)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path.Base(url), n+1, err)
+		}
+		ranks[string(raw)] = r
+	}
+	return ranks, nil
+}
+
+type bpeCounter struct {
+	enc tokenEncoder
+}
+
+func (c bpeCounter) CountTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return len(c.enc.EncodeOrdinary(text))
+}
@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEncoder encodes every byte as one token.
+type fakeEncoder struct{}
+
+func (fakeEncoder) EncodeOrdinary(text string) []int { return make([]int, len(text)) }
+
+func stubEncodings(t *testing.T, load func(dir, name string) (tokenEncoder, error)) {
+	t.Helper()
+	reset := func() {
+		bpeEncodings.Range(func(k, _ any) bool {
+			bpeEncodings.Delete(k)
+			return true
+		})
+	}
+	orig := loadEncoding
+	reset()
+	loadEncoding = load
+	t.Cleanup(func() {
+		loadEncoding = orig
+		reset()
+	})
+}
+
+func TestApproxCounter(t *testing.T) {
+	assert.Equal(t, 0, ApproxCounter(3.5).CountTokens(""))
+	assert.Equal(t, 3, ApproxCounter(3.5).CountTokens(strings.Repeat("a", 10)))
+	assert.Equal(t, EstimateTokens("héllo wörld"), ApproxCounter(0).CountTokens("héllo wörld"))
+}
+
+func TestNewTokenCounterRoutesByProvider(t *testing.T) {
+	var loaded []string
+	stubEncodings(t, func(dir, name string) (tokenEncoder, error) {
+		loaded = append(loaded, dir+"/"+name)
+		return fakeEncoder{}, nil
+	})
+
+	text := strings.Repeat("x", 35)
+	assert.Equal(t, 10, NewTokenCounter("anthropic/claude-sonnet-4").CountTokens(text))
+	assert.Equal(t, 9, NewTokenCounter("mystery/model").CountTokens(text))
+	assert.Equal(t, 9, NewTokenCounter("openai/gpt-4o-mini").CountTokens(text), "approximate until loaded")
+
+	require.NoError(t, LoadTokenizers("", "openai/gpt-4o-mini"))
+	assert.Empty(t, loaded, "no directory loads nothing")
+	require.NoError(t, LoadTokenizers("/tok", "openai/gpt-4o-mini", "anthropic/claude-sonnet-4", "gpt-4o"))
+	assert.Equal(t, []string{"/tok/o200k_base"}, loaded, "each encoding is loaded once")
+	assert.Equal(t, 35, NewTokenCounter("openai/gpt-4o-mini").CountTokens(text))
+	assert.Equal(t, 35, NewTokenCounter("gpt-4o").CountTokens(text))
+}
+
+func TestLoadTokenizersFailsWithoutFiles(t *testing.T) {
+	stubEncodings(t, loadEncoding)
+	err := LoadTokenizers(t.TempDir(), "openai/gpt-4-turbo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cl100k_base.tiktoken")
+	assert.Equal(t, EstimateTokens("approximate me"), NewTokenCounter("openai/gpt-4-turbo").CountTokens("approximate me"))
+
+	_, err = localBpeLoader("").LoadTiktokenBpe("https://example.com/o200k_base.tiktoken")
+	assert.ErrorContains(t, err, "no tokenizer directory")
+}
+
+func TestLoadTokenizersReadsLocalFiles(t *testing.T) {
+	stubEncodings(t, loadEncoding)
+	dir := t.TempDir()
+	ranks := "YQ== 0\nYg== 1\nYWI= 2\nIA== 3\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "p50k_base.tiktoken"), []byte(ranks), 0o600))
+
+	require.NoError(t, LoadTokenizers(dir, "openai/text-davinci-003"))
+	counter := NewTokenCounter("openai/text-davinci-003")
+	assert.Equal(t, 3, counter.CountTokens("ab ab"), `"ab", " ", "ab" with the merge`)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "r50k_base.tiktoken"), []byte("YQ==\n"), 0o600))
+	_, err := localBpeLoader(dir).LoadTiktokenBpe("https://example.com/r50k_base.tiktoken")
+	assert.ErrorContains(t, err, "r50k_base.tiktoken line 1")
+}
@@ -0,0 +1,233 @@
+package llm
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"unicode"
+)
+
+// Span attributes a byte range of a rendered prompt to the template action
+// that produced it, so editors can highlight substituted values.
+type Span struct {
+	// Start and End are byte offsets into the rendered prompt; End is
+	// exclusive.
+	Start int `json:"start"`
+	End   int `json:"end"`
+	// Field is the data reference the action prints, e.g.
+	// ".Account.TotalEquity" or "$pos.Symbol"; computed values report the
+	// whole pipeline, e.g. `printf "%.2f" .Price`.
+	Field string `json:"field"`
+	// Source locates the action in the template as "name:line:col".
+	Source string `json:"source"`
+}
+
+// Span sentinels never appear in prompt text: spanOpen <index> spanSep opens
+// a span and spanClose closes the innermost one.
+const (
+	spanOpen  = '\x00'
+	spanSep   = '\x01'
+	spanClose = '\x02'
+)
+
+// RenderSpans renders like Render and also returns the span of every value
+// printed by a template action, in output order.
+func (t *PromptTemplate) RenderSpans(data any) (string, []Span, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.tmpl == nil {
+		return "", nil, fmt.Errorf("prompt template %q not parsed", t.path)
+	}
+	traced, actions, err := t.tracedTemplate()
+	if err != nil {
+		return "", nil, err
+	}
+	var buf bytes.Buffer
+	if err := traced.Execute(&buf, data); err != nil {
+		return "", nil, fmt.Errorf("execute prompt template %q: %w", t.path, err)
+	}
+	out, spans := extractSpans(buf.String(), actions)
+	if len(t.blocks) == 0 || strings.TrimSpace(out) != "" {
+		return out, spans, nil
+	}
+
+	// A template made only of message blocks renders as their concatenation,
+	// matching Render.
+	var parts []string
+	var all []Span
+	offset := 0
+	for _, b := range t.blocks {
+		buf.Reset()
+		if err := traced.ExecuteTemplate(&buf, b.name, data); err != nil {
+			return "", nil, fmt.Errorf("execute prompt template %q block %q: %w", t.path, b.name, err)
+		}
+		text, blockSpans := trimSpans(extractSpans(buf.String(), actions))
+		if text == "" {
+			continue
+		}
+		if len(parts) > 0 {
+			offset += 2
+		}
+		for _, s := range blockSpans {
+			s.Start += offset
+			s.End += offset
+			all = append(all, s)
+		}
+		parts = append(parts, text)
+		offset += len(text)
+	}
+	return strings.Join(parts, "\n\n"), all, nil
+}
+
+// MarkSpans wraps each span of text in open(span) and close, e.g. to emit
+// "[[.Price|" ... "]]" markers for a plain-text preview. Spans must be sorted
+// and non-overlapping, as RenderSpans returns them.
+func MarkSpans(text string, spans []Span, open func(Span) string, close string) string {
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		if s.Start < last || s.End > len(text) || s.Start > s.End {
+			continue
+		}
+		b.WriteString(text[last:s.Start])
+		b.WriteString(open(s))
+		b.WriteString(text[s.Start:s.End])
+		b.WriteString(close)
+		last = s.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// tracedTemplate copies the parsed template with sentinels around every
+// printing action. actions[i] describes the action tagged with index i.
+func (t *PromptTemplate) tracedTemplate() (*template.Template, []Span, error) {
+	traced := template.New(t.tmpl.Name()).Option("missingkey=error")
+	if len(t.funcs) > 0 {
+		traced = traced.Funcs(t.funcs)
+	}
+	var actions []Span
+	for _, def := range t.tmpl.Templates() {
+		if def.Tree == nil {
+			continue
+		}
+		tree := def.Tree.Copy()
+		tagActions(tree, tree.Root, &actions)
+		if _, err := traced.AddParseTree(def.Name(), tree); err != nil {
+			return nil, nil, fmt.Errorf("trace prompt template %q: %w", t.path, err)
+		}
+	}
+	return traced, actions, nil
+}
+
+// tagActions wraps every printing action below list in sentinel text nodes.
+// Actions that only declare or assign variables print nothing and are left
+// alone.
+func tagActions(tree *parse.Tree, list *parse.ListNode, actions *[]Span) {
+	if list == nil {
+		return
+	}
+	nodes := make([]parse.Node, 0, len(list.Nodes))
+	for _, n := range list.Nodes {
+		switch x := n.(type) {
+		case *parse.ActionNode:
+			if x.Pipe == nil || len(x.Pipe.Decl) > 0 {
+				nodes = append(nodes, n)
+				continue
+			}
+			loc, _ := tree.ErrorContext(x)
+			*actions = append(*actions, Span{Field: actionField(x.Pipe), Source: loc})
+			open := string(spanOpen) + strconv.Itoa(len(*actions)-1) + string(spanSep)
+			nodes = append(nodes,
+				&parse.TextNode{NodeType: parse.NodeText, Pos: x.Pos, Text: []byte(open)},
+				n,
+				&parse.TextNode{NodeType: parse.NodeText, Pos: x.Pos, Text: []byte{spanClose}},
+			)
+			continue
+		case *parse.IfNode:
+			tagActions(tree, x.List, actions)
+			tagActions(tree, x.ElseList, actions)
+		case *parse.RangeNode:
+			tagActions(tree, x.List, actions)
+			tagActions(tree, x.ElseList, actions)
+		case *parse.WithNode:
+			tagActions(tree, x.List, actions)
+			tagActions(tree, x.ElseList, actions)
+		}
+		nodes = append(nodes, n)
+	}
+	list.Nodes = nodes
+}
+
+// actionField names what an action prints: the bare field, variable or
+// chain reference when the pipeline is just that, otherwise the pipeline.
+func actionField(pipe *parse.PipeNode) string {
+	if len(pipe.Cmds) == 1 && len(pipe.Cmds[0].Args) == 1 {
+		switch arg := pipe.Cmds[0].Args[0].(type) {
+		case *parse.FieldNode, *parse.VariableNode, *parse.ChainNode, *parse.DotNode:
+			return arg.String()
+		}
+	}
+	return pipe.String()
+}
+
+// extractSpans strips sentinels from raw and returns the clean text with the
+// span of each tagged action. Actions print values, never other actions, so
+// spans close in output order and come out sorted.
+func extractSpans(raw string, actions []Span) (string, []Span) {
+	var (
+		b     strings.Builder
+		spans []Span
+		open  []Span
+	)
+	for i := 0; i < len(raw); i++ {
+		switch raw[i] {
+		case spanOpen:
+			end := strings.IndexByte(raw[i:], spanSep)
+			if end < 0 {
+				continue
+			}
+			idx, err := strconv.Atoi(raw[i+1 : i+end])
+			if err != nil || idx < 0 || idx >= len(actions) {
+				continue
+			}
+			s := actions[idx]
+			s.Start = b.Len()
+			open = append(open, s)
+			i += end
+		case spanClose:
+			if len(open) == 0 {
+				continue
+			}
+			s := open[len(open)-1]
+			open = open[:len(open)-1]
+			s.End = b.Len()
+			if s.End > s.Start {
+				spans = append(spans, s)
+			}
+		default:
+			b.WriteByte(raw[i])
+		}
+	}
+	return b.String(), spans
+}
+
+// trimSpans trims surrounding whitespace from text, shifting and clipping
+// spans to match.
+func trimSpans(text string, spans []Span) (string, []Span) {
+	lead := len(text) - len(strings.TrimLeftFunc(text, unicode.IsSpace))
+	trimmed := strings.TrimSpace(text)
+	out := spans[:0]
+	for _, s := range spans {
+		s.Start = max(s.Start-lead, 0)
+		s.End = min(s.End-lead, len(trimmed))
+		if s.End > s.Start {
+			out = append(out, s)
+		}
+	}
+	return trimmed, out
+}
@@ -0,0 +1,53 @@
+package llm
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderSpans(t *testing.T) {
+	src := "Equity {{ .Equity }}\n{{ $n := len .Coins }}{{ range .Coins }}- {{ . }}\n{{ end }}Count {{ printf \"%d\" $n }}"
+	fsys := fstest.MapFS{"p.tmpl": {Data: []byte(src)}}
+	tpl, err := NewPromptTemplate("p.tmpl", nil, WithFS(fsys))
+	require.NoError(t, err)
+	data := map[string]any{"Equity": 1000, "Coins": []string{"BTC", "ETH"}}
+
+	out, spans, err := tpl.RenderSpans(data)
+	require.NoError(t, err)
+	plain, err := tpl.Render(data)
+	require.NoError(t, err)
+	assert.Equal(t, plain, out, "tracing does not change the prompt")
+
+	require.Len(t, spans, 4)
+	assert.Equal(t, "1000", out[spans[0].Start:spans[0].End])
+	assert.Equal(t, ".Equity", spans[0].Field)
+	assert.Equal(t, "p.tmpl:1:10", spans[0].Source)
+	assert.Equal(t, "BTC", out[spans[1].Start:spans[1].End])
+	assert.Equal(t, ".", spans[1].Field)
+	assert.Equal(t, "ETH", out[spans[2].Start:spans[2].End])
+	assert.Equal(t, "2", out[spans[3].Start:spans[3].End])
+	assert.Equal(t, `printf "%d" $n`, spans[3].Field)
+
+	marked := MarkSpans(out, spans[:1], func(s Span) string { return fmt.Sprintf("[[%s|", s.Field) }, "]]")
+	assert.Contains(t, marked, "Equity [[.Equity|1000]]\n")
+}
+
+func TestRenderSpansMessageBlocks(t *testing.T) {
+	src := `{{ define "message:system:rules" }}
+  Max leverage {{ .Lev }}
+{{ end }}{{ define "message:user:ctx" }}Price {{ .Px }}{{ end }}`
+	fsys := fstest.MapFS{"m.tmpl": {Data: []byte(src)}}
+	tpl, err := NewPromptTemplate("m.tmpl", nil, WithFS(fsys))
+	require.NoError(t, err)
+
+	out, spans, err := tpl.RenderSpans(map[string]any{"Lev": 5, "Px": 42.5})
+	require.NoError(t, err)
+	assert.Equal(t, "Max leverage 5\n\nPrice 42.5", out)
+	require.Len(t, spans, 2)
+	assert.Equal(t, "5", out[spans[0].Start:spans[0].End])
+	assert.Equal(t, "42.5", out[spans[1].Start:spans[1].End])
+}
@@ -2,13 +2,15 @@ package llm
 
 // ChatRequest describes a single LLM chat invocation.
 type ChatRequest struct {
-	Model               string          `json:"model,omitempty"`
-	Messages            []Message       `json:"messages"`
-	Temperature         *float64        `json:"temperature,omitempty"`
-	MaxCompletionTokens *int            `json:"max_completion_tokens,omitempty"`
-	TopP                *float64        `json:"top_p,omitempty"`
-	Stream              bool            `json:"stream,omitempty"`
-	ResponseFormat      *ResponseFormat `json:"response_format,omitempty"`
+	Model               string    `json:"model,omitempty"`
+	Messages            []Message `json:"messages"`
+	Temperature         *float64  `json:"temperature,omitempty"`
+	MaxCompletionTokens *int      `json:"max_completion_tokens,omitempty"`
+	TopP                *float64  `json:"top_p,omitempty"`
+	// Seed asks providers that support it for deterministic sampling.
+	Seed           *int64          `json:"seed,omitempty"`
+	Stream         bool            `json:"stream,omitempty"`
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
 	// Optional: Zenmux multi-model routing config; used when Model == "zenmux/auto"
 	Routing *RoutingConfig `json:"model_routing_config,omitempty"`
 }
@@ -19,6 +21,18 @@ type Message struct {
 	Content    string `json:"content"`
 	Name       string `json:"name,omitempty"`
 	ToolCallID string `json:"tool_call_id,omitempty"`
+	// Images are attached after Content on user messages for vision-capable
+	// models; other roles ignore them.
+	Images []Image `json:"images,omitempty"`
+}
+
+// Image is an image attachment referenced by URL (https or data URL).
+// Width and Height are used for token estimation only.
+type Image struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"` // auto | low | high
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
 }
 
 // ResponseFormat controls the structure of the assistant response.
@@ -46,6 +60,20 @@ type ChatResponse struct {
 	RawJSON     string   `json:"raw_json,omitempty"`
 	Tier        string   `json:"tier,omitempty"`
 	Fingerprint string   `json:"fingerprint,omitempty"`
+	// Cached marks a response served from the response cache; its Usage is
+	// zero because the provider was not called.
+	Cached bool `json:"cached,omitempty"`
+	// Sampling holds the parameters the request was sent with, after model
+	// defaults were applied, so a decision can be reproduced.
+	Sampling Sampling `json:"sampling"`
+}
+
+// Sampling lists the sampling parameters of one chat call. Nil fields were
+// left to the provider default.
+type Sampling struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	Seed        *int64   `json:"seed,omitempty"`
 }
 
 // Choice represents a single completion choice.
@@ -0,0 +1,192 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// defaultWatchDebounce coalesces the burst of events an editor save produces.
+const defaultWatchDebounce = 200 * time.Millisecond
+
+// ReloadFunc is called after a watched template reloads with new content.
+// name is the template path it was loaded from.
+type ReloadFunc func(name, digest string)
+
+// TemplateWatcher reloads registered PromptTemplates when their files change
+// on disk, so running services pick up prompt edits without a restart.
+// A reload that fails (parse error, pinned digest mismatch) is logged and the
+// previous template stays in use.
+type TemplateWatcher struct {
+	watcher  *fsnotify.Watcher
+	debounce time.Duration
+
+	mu        sync.Mutex
+	templates map[string][]*PromptTemplate // keyed by cleaned path
+	dirs      map[string]struct{}
+	hooks     []ReloadFunc
+}
+
+// NewTemplateWatcher starts an fsnotify watcher. Call Run to process events
+// and Close to release it.
+func NewTemplateWatcher() (*TemplateWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("template watcher: %w", err)
+	}
+	return &TemplateWatcher{
+		watcher:   w,
+		debounce:  defaultWatchDebounce,
+		templates: make(map[string][]*PromptTemplate),
+		dirs:      make(map[string]struct{}),
+	}, nil
+}
+
+// Add watches t's file. Its directory is watched rather than the file so
+// editors that save by renaming a new file into place are still seen.
+// Templates loaded through WithFS cannot be watched.
+func (w *TemplateWatcher) Add(t *PromptTemplate) error {
+	if w == nil || t == nil {
+		return nil
+	}
+	if t.fsys != nil {
+		return fmt.Errorf("template watcher: %q is loaded from an fs.FS and cannot be watched", t.path)
+	}
+	path := filepath.Clean(t.path)
+	dir := filepath.Dir(path)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.dirs[dir]; !ok {
+		if err := w.watcher.Add(dir); err != nil {
+			return fmt.Errorf("template watcher: watch %s: %w", dir, err)
+		}
+		w.dirs[dir] = struct{}{}
+	}
+	w.templates[path] = append(w.templates[path], t)
+	return nil
+}
+
+// Remove stops watching t, such as when the executor holding it is
+// replaced. A directory is unwatched once no template in it is left.
+func (w *TemplateWatcher) Remove(t *PromptTemplate) {
+	if w == nil || t == nil {
+		return
+	}
+	path := filepath.Clean(t.path)
+	dir := filepath.Dir(path)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	kept := w.templates[path][:0]
+	for _, other := range w.templates[path] {
+		if other != t {
+			kept = append(kept, other)
+		}
+	}
+	if len(kept) > 0 {
+		w.templates[path] = kept
+		return
+	}
+	delete(w.templates, path)
+	for other := range w.templates {
+		if filepath.Dir(other) == dir {
+			return
+		}
+	}
+	if _, ok := w.dirs[dir]; ok {
+		delete(w.dirs, dir)
+		if err := w.watcher.Remove(dir); err != nil {
+			logx.Errorf("template watcher: unwatch %s: %v", dir, err)
+		}
+	}
+}
+
+// OnReload registers fn to run after every successful reload that changed a
+// template's digest.
+func (w *TemplateWatcher) OnReload(fn ReloadFunc) {
+	if w == nil || fn == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.hooks = append(w.hooks, fn)
+}
+
+// Run processes file events until ctx is done or the watcher is closed.
+func (w *TemplateWatcher) Run(ctx context.Context) {
+	pending := make(map[string]struct{})
+	var flush <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if !ev.Has(fsnotify.Write) && !ev.Has(fsnotify.Create) && !ev.Has(fsnotify.Rename) {
+				continue
+			}
+			path := filepath.Clean(ev.Name)
+			if !w.watching(path) {
+				continue
+			}
+			pending[path] = struct{}{}
+			flush = time.After(w.debounce)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			logx.Errorf("template watcher: %v", err)
+		case <-flush:
+			for path := range pending {
+				w.reload(path)
+			}
+			pending = make(map[string]struct{})
+			flush = nil
+		}
+	}
+}
+
+// Close stops watching.
+func (w *TemplateWatcher) Close() error {
+	if w == nil {
+		return nil
+	}
+	return w.watcher.Close()
+}
+
+func (w *TemplateWatcher) watching(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.templates[path]
+	return ok
+}
+
+func (w *TemplateWatcher) reload(path string) {
+	w.mu.Lock()
+	templates := append([]*PromptTemplate(nil), w.templates[path]...)
+	hooks := append([]ReloadFunc(nil), w.hooks...)
+	w.mu.Unlock()
+
+	for _, t := range templates {
+		before := t.Digest()
+		if err := t.Reload(); err != nil {
+			logx.Errorf("template watcher: keeping previous %s: %v", t.path, err)
+			continue
+		}
+		digest := t.Digest()
+		if digest == before {
+			continue
+		}
+		for _, fn := range hooks {
+			fn(t.path, digest)
+		}
+	}
+}
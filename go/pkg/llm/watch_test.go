@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateWatcherReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "watched.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("v1 {{ .Name }}"), 0o600))
+	tpl, err := NewPromptTemplate(path, nil)
+	require.NoError(t, err)
+
+	w, err := NewTemplateWatcher()
+	require.NoError(t, err)
+	defer w.Close()
+	w.debounce = 10 * time.Millisecond
+	require.NoError(t, w.Add(tpl))
+
+	type reload struct{ name, digest string }
+	reloads := make(chan reload, 4)
+	w.OnReload(func(name, digest string) { reloads <- reload{name, digest} })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	// A broken edit is rejected and the previous template keeps serving.
+	require.NoError(t, os.WriteFile(path, []byte("v2 {{ .Name "), 0o600))
+	require.NoError(t, os.WriteFile(path, []byte("v2 {{ .Name }}"), 0o600))
+
+	select {
+	case got := <-reloads:
+		assert.Equal(t, path, got.name)
+		assert.Equal(t, tpl.Digest(), got.digest)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload after the template changed")
+	}
+	out, err := tpl.Render(map[string]any{"Name": "x"})
+	require.NoError(t, err)
+	assert.Equal(t, "v2 x", out)
+}
+
+func TestTemplateWatcherRejectsFSTemplates(t *testing.T) {
+	fsys := fstest.MapFS{"a.tmpl": {Data: []byte("a")}}
+	tpl, err := NewPromptTemplate("a.tmpl", nil, WithFS(fsys))
+	require.NoError(t, err)
+
+	w, err := NewTemplateWatcher()
+	require.NoError(t, err)
+	defer w.Close()
+	assert.Error(t, w.Add(tpl))
+}
+
+func TestTemplateWatcherRemove(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.tmpl")
+	b := filepath.Join(dir, "b.tmpl")
+	require.NoError(t, os.WriteFile(a, []byte("a"), 0o600))
+	require.NoError(t, os.WriteFile(b, []byte("b"), 0o600))
+	load := func(path string) *PromptTemplate {
+		tpl, err := NewPromptTemplate(path, nil)
+		require.NoError(t, err)
+		return tpl
+	}
+	a1, a2, b1 := load(a), load(a), load(b)
+
+	w, err := NewTemplateWatcher()
+	require.NoError(t, err)
+	defer w.Close()
+	for _, tpl := range []*PromptTemplate{a1, a2, b1} {
+		require.NoError(t, w.Add(tpl))
+	}
+
+	w.Remove(a1)
+	assert.Equal(t, []*PromptTemplate{a2}, w.templates[a], "other templates of the same file stay registered")
+	w.Remove(a2)
+	assert.False(t, w.watching(a))
+	assert.Contains(t, w.dirs, dir, "b.tmpl still needs the directory")
+	w.Remove(b1)
+	assert.Empty(t, w.templates)
+	assert.Empty(t, w.dirs)
+	assert.Empty(t, w.watcher.WatchList())
+	w.Remove(b1) // already removed
+}
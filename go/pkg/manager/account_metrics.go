@@ -0,0 +1,142 @@
+package manager
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// TraderAccount is one read of a trader's exchange account, restricted to
+// the positions the trader owns.
+type TraderAccount struct {
+	TraderID         string
+	EquityUSD        float64
+	CashUSD          float64 // equity not committed as margin
+	MarginUsedUSD    float64
+	UnrealizedPnLUSD float64
+	// InitialEquityUSD is the trader's allocation, the base returns are
+	// measured against; 0 when unallocated.
+	InitialEquityUSD float64
+	Positions        []AccountPosition
+	At               time.Time
+}
+
+// AccountPosition is an open position within a TraderAccount.
+type AccountPosition struct {
+	Symbol           string  `json:"symbol"`
+	Side             string  `json:"side"`
+	Quantity         float64 `json:"quantity"`
+	EntryPrice       float64 `json:"entry_price"`
+	UnrealizedPnLUSD float64 `json:"unrealized_pnl_usd"`
+	Leverage         int     `json:"leverage"`
+}
+
+// AccountMetrics is a trader's performance derived from its account
+// snapshot history.
+type AccountMetrics struct {
+	TotalPnLUSD    float64
+	ReturnPct      float64
+	SharpeRatio    float64
+	MaxDrawdownPct float64
+	AsOf           time.Time
+}
+
+// AccountMetricsSource supplies the latest AccountMetrics of a trader; nil
+// without error means none are known yet.
+type AccountMetricsSource interface {
+	LatestAccountMetrics(ctx context.Context, traderID string) (*AccountMetrics, error)
+}
+
+// WithAccountMetrics makes decision cycles take the trader's PnL, Sharpe
+// ratio and max drawdown from src instead of leaving them at their last
+// persisted values.
+func WithAccountMetrics(src AccountMetricsSource) Option {
+	return func(m *Manager) {
+		m.accountMetrics = src
+	}
+}
+
+// TraderAccounts reads every trader's account. Traders whose exchange read
+// fails are logged and left out.
+func (m *Manager) TraderAccounts(ctx context.Context) []TraderAccount {
+	m.mu.RLock()
+	traders := make([]*VirtualTrader, 0, len(m.traders))
+	for _, t := range m.traders {
+		traders = append(traders, t)
+	}
+	m.mu.RUnlock()
+	sort.Slice(traders, func(i, j int) bool { return traders[i].ID < traders[j].ID })
+
+	out := make([]TraderAccount, 0, len(traders))
+	for _, t := range traders {
+		if t.ExchangeProvider == nil {
+			continue
+		}
+		state, err := t.ExchangeProvider.GetAccountState(ctx)
+		if err != nil {
+			logx.WithContext(ctx).Errorf("manager: account snapshot trader=%s err=%v", t.ID, err)
+			continue
+		}
+		acct := TraderAccount{
+			TraderID:      t.ID,
+			EquityUSD:     parseFloat(state.MarginSummary.AccountValue),
+			MarginUsedUSD: parseFloat(state.MarginSummary.TotalMarginUsed),
+			At:            time.Now().UTC(),
+		}
+		acct.CashUSD = math.Max(0, acct.EquityUSD-acct.MarginUsedUSD)
+		for _, p := range m.filterPositionsForTrader(t.ID, state.AssetPositions) {
+			qty := parseFloat(p.Szi)
+			side := "long"
+			if qty < 0 {
+				side = "short"
+				qty = -qty
+			}
+			pnl := parseFloat(p.UnrealizedPnl)
+			acct.UnrealizedPnLUSD += pnl
+			acct.Positions = append(acct.Positions, AccountPosition{
+				Symbol:           p.Coin,
+				Side:             side,
+				Quantity:         qty,
+				EntryPrice:       parsePtrFloat(p.EntryPx),
+				UnrealizedPnLUSD: pnl,
+				Leverage:         p.Leverage.Value,
+			})
+		}
+		t.mu.RLock()
+		acct.InitialEquityUSD = t.ResourceAlloc.AllocatedEquityUSD
+		t.mu.RUnlock()
+		out = append(out, acct)
+	}
+	return out
+}
+
+// applyAccountMetrics copies the trader's latest snapshot metrics into its
+// performance so the prompt and Sharpe gating see them.
+func (m *Manager) applyAccountMetrics(ctx context.Context, t *VirtualTrader) {
+	if m.accountMetrics == nil {
+		return
+	}
+	metrics, err := m.accountMetrics.LatestAccountMetrics(ctx, t.ID)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("manager: load account metrics trader=%s err=%v", t.ID, err)
+		return
+	}
+	if metrics == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.Performance == nil {
+		t.Performance = &PerformanceMetrics{}
+	}
+	t.Performance.TotalPnLUSD = metrics.TotalPnLUSD
+	t.Performance.TotalPnLPct = metrics.ReturnPct
+	t.Performance.SharpeRatio = metrics.SharpeRatio
+	if metrics.MaxDrawdownPct > t.Performance.MaxDrawdownPct {
+		t.Performance.MaxDrawdownPct = metrics.MaxDrawdownPct
+	}
+	t.Performance.UpdatedAt = metrics.AsOf
+}
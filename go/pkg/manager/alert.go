@@ -0,0 +1,174 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+const (
+	// alertQueueSize bounds alerts waiting for delivery while the trading
+	// loop runs; more are dropped and logged.
+	alertQueueSize    = 256
+	alertDeliveryWait = 5 * time.Second
+)
+
+// Alert kinds emitted by the manager.
+const (
+	AlertRunnerStalled   = "runner_stalled"
+	AlertRunnerRecovered = "runner_recovered"
+)
+
+// Alert is an operational notification about a trader.
+type Alert struct {
+	Kind     string         `json:"kind"`
+	TraderID string         `json:"trader_id"`
+	Message  string         `json:"message"`
+	Details  map[string]any `json:"details,omitempty"`
+	At       time.Time      `json:"at"`
+	// Data is the kind's registered template data (see AlertDataTypes).
+	Data any `json:"data,omitempty"`
+}
+
+// Alerter delivers alerts to operators.
+type Alerter interface {
+	Alert(ctx context.Context, alert Alert) error
+}
+
+// ChannelAlerter is implemented by alerters that want channel-specific
+// message templates. Other alerters use the default templates.
+type ChannelAlerter interface {
+	Channel() string
+}
+
+func alertChannel(a Alerter) string {
+	if c, ok := a.(ChannelAlerter); ok {
+		return c.Channel()
+	}
+	return defaultAlertChannel
+}
+
+// WebhookAlerter posts alerts as JSON to an HTTP endpoint.
+type WebhookAlerter struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookAlerter returns an alerter posting to url.
+func NewWebhookAlerter(url string) *WebhookAlerter {
+	return &WebhookAlerter{url: url, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// Channel implements ChannelAlerter.
+func (w *WebhookAlerter) Channel() string { return "webhook" }
+
+// Alert implements Alerter.
+func (w *WebhookAlerter) Alert(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("manager: alert webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("manager: alert webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// WithAlerter adds an alert sink. The manager also posts to
+// monitoring.alert_webhook when configured; with no sinks alerts are only logged.
+func WithAlerter(a Alerter) Option {
+	return func(m *Manager) {
+		if a != nil {
+			m.alerters = append(m.alerters, a)
+		}
+	}
+}
+
+// runAlertWorker delivers queued alerts so slow sinks never hold up a
+// decision cycle. Until it runs, as outside the trading loop, sendAlert
+// delivers inline. On stop it delivers what is already queued.
+func (m *Manager) runAlertWorker(ctx context.Context) {
+	m.alertWorker.Store(true)
+	for {
+		select {
+		case <-ctx.Done():
+			m.drainAlerts(ctx)
+			return
+		case <-m.stopChan:
+			m.drainAlerts(ctx)
+			return
+		case alert := <-m.alertQueue:
+			m.deliverAlert(ctx, alert)
+		}
+	}
+}
+
+func (m *Manager) drainAlerts(ctx context.Context) {
+	m.alertWorker.Store(false)
+	for {
+		select {
+		case alert := <-m.alertQueue:
+			m.deliverAlert(ctx, alert)
+		default:
+			return
+		}
+	}
+}
+
+// deliverAlert renders alert per channel and sends it to every sink. Sends
+// outlive ctx's cancellation, so alerts raised on shutdown still go out.
+func (m *Manager) deliverAlert(ctx context.Context, alert Alert) {
+	for _, alerter := range m.alerters {
+		out := alert
+		if m.alertTemplates != nil {
+			msg, ok, err := m.alertTemplates.Render(alertChannel(alerter), alert)
+			if err != nil {
+				logx.WithContext(ctx).Slowf("manager: %v; using built-in message", err)
+			} else if ok {
+				out.Message = msg
+			}
+		}
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), alertDeliveryWait)
+		if err := alerter.Alert(sendCtx, out); err != nil {
+			logx.WithContext(ctx).Errorf("manager: deliver alert %s trader=%s channel=%s: %v", alert.Kind, alert.TraderID, alertChannel(alerter), err)
+		}
+		cancel()
+	}
+}
+
+func tradeExecutedAlert(traderID string, d executorpkg.Decision) Alert {
+	now := time.Now()
+	return Alert{
+		Kind:     AlertTradeExecuted,
+		TraderID: traderID,
+		Message:  fmt.Sprintf("trader %s %s %s $%.2f", traderID, d.Action, d.Symbol, d.PositionSizeUSD),
+		At:       now,
+		Data: TradeExecutedAlert{
+			TraderID:   traderID,
+			Symbol:     d.Symbol,
+			Action:     d.Action,
+			SizeUSD:    d.PositionSizeUSD,
+			Price:      d.EntryPrice,
+			Leverage:   d.Leverage,
+			Confidence: d.Confidence,
+			At:         now,
+		},
+	}
+}
@@ -0,0 +1,239 @@
+package manager
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"nof0-api/pkg/llm"
+)
+
+// Additional alert kinds with operator-editable templates.
+const (
+	AlertTradeExecuted  = "trade_executed"
+	AlertStopHit        = "stop_hit"
+	AlertBreakerTripped = "breaker_tripped"
+	// AlertSymbolBlacklisted fires when repeated data anomalies blacklist a symbol.
+	AlertSymbolBlacklisted = "symbol_blacklisted"
+	// AlertClockSkew fires when an exchange clock drifts past its threshold.
+	AlertClockSkew = "clock_skew"
+)
+
+// defaultAlertChannel names the template directory used when a channel has
+// no template of its own.
+const defaultAlertChannel = "default"
+
+// TradeExecutedAlert is the template data for AlertTradeExecuted.
+type TradeExecutedAlert struct {
+	TraderID   string    `doc:"Trader that placed the order"`
+	Symbol     string    `doc:"Traded symbol, e.g. BTC"`
+	Action     string    `doc:"open_long, open_short, close_long or close_short"`
+	SizeUSD    float64   `doc:"Requested position size in USD"`
+	Price      float64   `doc:"Decision entry price (0 for market orders)"`
+	Leverage   int       `doc:"Leverage applied to the order"`
+	Confidence int       `doc:"Model confidence 0-100"`
+	At         time.Time `doc:"Execution time"`
+}
+
+// StopHitAlert is the template data for AlertStopHit. It fires when a tracked
+// position disappears from the exchange without a manager decision, which is
+// how stop-loss and take-profit triggers surface.
+type StopHitAlert struct {
+	TraderID   string    `doc:"Trader that owned the position"`
+	Symbol     string    `doc:"Position symbol"`
+	Side       string    `doc:"long or short"`
+	Quantity   float64   `doc:"Position size in base units"`
+	EntryPrice float64   `doc:"Average entry price"`
+	At         time.Time `doc:"Time the closure was detected"`
+}
+
+// BreakerTrippedAlert is the template data for AlertBreakerTripped.
+type BreakerTrippedAlert struct {
+	TraderID   string    `doc:"Trader that was paused"`
+	Breaker    string    `doc:"Breaker name, e.g. sharpe_pause"`
+	Reason     string    `doc:"Human-readable trip condition"`
+	PauseUntil time.Time `doc:"When trading resumes"`
+	At         time.Time `doc:"Trip time"`
+}
+
+// SymbolBlacklistedAlert is the template data for AlertSymbolBlacklisted.
+type SymbolBlacklistedAlert struct {
+	TraderID string    `doc:"Trader whose cycle observed the last anomaly"`
+	Symbol   string    `doc:"Blacklisted symbol"`
+	Reason   string    `doc:"Anomaly count and the last anomaly"`
+	Until    time.Time `doc:"When the symbol becomes eligible again"`
+	At       time.Time `doc:"Blacklist time"`
+}
+
+// ClockSkewAlert is the template data for AlertClockSkew.
+type ClockSkewAlert struct {
+	Provider  string        `doc:"Exchange provider name from exchange.yaml"`
+	Offset    time.Duration `doc:"Exchange time minus local time"`
+	Threshold time.Duration `doc:"Configured clock_skew_threshold"`
+	Adjusted  bool          `doc:"Whether signed timestamps are corrected by Offset"`
+	At        time.Time     `doc:"Measurement time"`
+}
+
+// RunnerStallAlert is the template data for AlertRunnerStalled and AlertRunnerRecovered.
+type RunnerStallAlert struct {
+	TraderID         string        `doc:"Trader whose runner changed state"`
+	StallAfter       time.Duration `doc:"Threshold without a completed cycle"`
+	LastCycleAt      time.Time     `doc:"Last completed cycle (zero if none)"`
+	LastLLMSuccessAt time.Time     `doc:"Last usable model response (zero if none)"`
+	LastOrderAt      time.Time     `doc:"Last accepted order (zero if none)"`
+	At               time.Time     `doc:"Detection time"`
+}
+
+// AlertDataType documents the data struct an alert template renders against.
+type AlertDataType struct {
+	Kind        string
+	Description string
+	Type        reflect.Type
+}
+
+// AlertField documents one field available to an alert template.
+type AlertField struct {
+	Name string
+	Type string
+	Doc  string
+}
+
+var alertDataTypes = map[string]AlertDataType{
+	AlertTradeExecuted:     {Kind: AlertTradeExecuted, Description: "An order was accepted by the exchange.", Type: reflect.TypeOf(TradeExecutedAlert{})},
+	AlertStopHit:           {Kind: AlertStopHit, Description: "A position was closed by an exchange-side stop or take-profit.", Type: reflect.TypeOf(StopHitAlert{})},
+	AlertBreakerTripped:    {Kind: AlertBreakerTripped, Description: "A risk breaker paused a trader.", Type: reflect.TypeOf(BreakerTrippedAlert{})},
+	AlertSymbolBlacklisted: {Kind: AlertSymbolBlacklisted, Description: "Repeated data anomalies blacklisted a symbol.", Type: reflect.TypeOf(SymbolBlacklistedAlert{})},
+	AlertClockSkew:         {Kind: AlertClockSkew, Description: "An exchange clock drifted past its skew threshold.", Type: reflect.TypeOf(ClockSkewAlert{})},
+	AlertRunnerStalled:     {Kind: AlertRunnerStalled, Description: "A runner stopped completing cycles.", Type: reflect.TypeOf(RunnerStallAlert{})},
+	AlertRunnerRecovered:   {Kind: AlertRunnerRecovered, Description: "A stalled runner completed a cycle again.", Type: reflect.TypeOf(RunnerStallAlert{})},
+	AlertShadowReport:      {Kind: AlertShadowReport, Description: "A shadow model evaluation finished with a verdict.", Type: reflect.TypeOf(ShadowReport{})},
+	AlertOutputAnomaly:     {Kind: AlertOutputAnomaly, Description: "A model produced degenerate output.", Type: reflect.TypeOf(OutputAnomalyAlert{})},
+	AlertPromptDrift:       {Kind: AlertPromptDrift, Description: "A trader's prompts drifted from, or returned to, their baseline.", Type: reflect.TypeOf(PromptDriftAlert{})},
+	AlertTransferBlocked:   {Kind: AlertTransferBlocked, Description: "An exchange provider refused a withdrawal-capable action.", Type: reflect.TypeOf(TransferBlockedAlert{})},
+	AlertRolloutRolledBack: {Kind: AlertRolloutRolledBack, Description: "A staged prompt rollout fell back to its baseline for a trader.", Type: reflect.TypeOf(RolloutRolledBackAlert{})},
+}
+
+// init registers each alert data type with the template tooling as
+// alert.<kind>.
+func init() {
+	for kind, dt := range alertDataTypes {
+		llm.DataTypes().Register("alert."+kind, dt.Type)
+	}
+}
+
+// AlertDataTypes lists the registered alert kinds and their template data
+// types, ordered by kind.
+func AlertDataTypes() []AlertDataType {
+	out := make([]AlertDataType, 0, len(alertDataTypes))
+	for _, dt := range alertDataTypes {
+		out = append(out, dt)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Kind < out[j].Kind })
+	return out
+}
+
+// Fields returns the exported fields of the data type with their doc tags.
+func (d AlertDataType) Fields() []AlertField {
+	fields := make([]AlertField, 0, d.Type.NumField())
+	for i := 0; i < d.Type.NumField(); i++ {
+		f := d.Type.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		fields = append(fields, AlertField{Name: f.Name, Type: f.Type.String(), Doc: f.Tag.Get("doc")})
+	}
+	return fields
+}
+
+// AlertTemplates renders alert messages from operator-editable templates laid
+// out as <dir>/<channel>/<kind>.tmpl, falling back to <dir>/default/<kind>.tmpl.
+// Templates execute against the kind's registered data struct.
+type AlertTemplates struct {
+	dir string
+
+	mu    sync.RWMutex
+	tmpls map[string]*template.Template // "<channel>/<kind>"
+}
+
+// LoadAlertTemplates parses every template under dir and dry-runs it against
+// the zero value of its data type, so a typo in a field name fails at startup
+// instead of when the alert fires.
+func LoadAlertTemplates(dir string) (*AlertTemplates, error) {
+	a := &AlertTemplates{dir: dir, tmpls: make(map[string]*template.Template)}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".tmpl" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		channel, file := filepath.Split(filepath.ToSlash(rel))
+		channel = strings.Trim(channel, "/")
+		kind := strings.TrimSuffix(file, ".tmpl")
+		if channel == "" || strings.Contains(channel, "/") {
+			return fmt.Errorf("manager: alert template %s must be at <channel>/<kind>.tmpl", path)
+		}
+		dt, ok := alertDataTypes[kind]
+		if !ok {
+			return fmt.Errorf("manager: alert template %s: unknown alert kind %q", path, kind)
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		tmpl, err := template.New(channel + "/" + file).Option("missingkey=error").Parse(string(raw))
+		if err != nil {
+			return fmt.Errorf("manager: parse alert template %s: %w", path, err)
+		}
+		if err := tmpl.Execute(io.Discard, reflect.Zero(dt.Type).Interface()); err != nil {
+			return fmt.Errorf("manager: alert template %s does not match %s: %w", path, dt.Type.Name(), err)
+		}
+		a.tmpls[channel+"/"+kind] = tmpl
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Render returns the message for alert on channel. ok is false when neither
+// the channel nor the default directory defines a template for the kind.
+func (a *AlertTemplates) Render(channel string, alert Alert) (msg string, ok bool, err error) {
+	if a == nil || alert.Data == nil {
+		return "", false, nil
+	}
+	a.mu.RLock()
+	tmpl, found := a.tmpls[channel+"/"+alert.Kind]
+	if !found {
+		tmpl, found = a.tmpls[defaultAlertChannel+"/"+alert.Kind]
+	}
+	a.mu.RUnlock()
+	if !found {
+		return "", false, nil
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, alert.Data); err != nil {
+		return "", false, fmt.Errorf("manager: render alert %s for %s: %w", alert.Kind, channel, err)
+	}
+	return strings.TrimSpace(sb.String()), true, nil
+}
+
+// WithAlertTemplates renders alert messages from operator templates.
+func WithAlertTemplates(t *AlertTemplates) Option {
+	return func(m *Manager) {
+		m.alertTemplates = t
+	}
+}
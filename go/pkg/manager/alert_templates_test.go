@@ -0,0 +1,101 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultAlertTemplatesRender(t *testing.T) {
+	tmpls, err := LoadAlertTemplates(filepath.Join("..", "..", "etc", "prompts", "alerts"))
+	require.NoError(t, err)
+
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	samples := map[string]any{
+		AlertTradeExecuted:     TradeExecutedAlert{TraderID: "t1", Symbol: "BTC", Action: "open_long", SizeUSD: 250, Leverage: 5, Confidence: 82, At: at},
+		AlertStopHit:           StopHitAlert{TraderID: "t1", Symbol: "ETH", Side: "short", Quantity: 0.5, EntryPrice: 3200, At: at},
+		AlertBreakerTripped:    BreakerTrippedAlert{TraderID: "t1", Breaker: "sharpe_pause", Reason: "sharpe -1.20 below -1.00", PauseUntil: at, At: at},
+		AlertSymbolBlacklisted: SymbolBlacklistedAlert{TraderID: "t1", Symbol: "SOL", Reason: "3 anomalies within 15m0s, last: feed error: timeout", Until: at, At: at},
+		AlertClockSkew:         ClockSkewAlert{Provider: "hyperliquid", Offset: 3500 * time.Millisecond, Threshold: 2 * time.Second, At: at},
+		AlertRunnerStalled:     RunnerStallAlert{TraderID: "t1", StallAfter: 3 * time.Minute, LastCycleAt: at, At: at},
+		AlertRunnerRecovered:   RunnerStallAlert{TraderID: "t1", At: at},
+		AlertShadowReport:      ShadowReport{TraderID: "t1", IncumbentModel: "gpt-5", CandidateModel: "claude", StartedAt: at, EndedAt: at, Promote: true, Reason: "edge"},
+		AlertOutputAnomaly:     OutputAnomalyAlert{TraderID: "t1", Model: "gpt-5", Kind: OutputRefusal, Detail: `refusal text "as an AI"`, PauseUntil: at, At: at},
+		AlertPromptDrift:       PromptDriftAlert{TraderID: "t1", Score: 6.2, Threshold: 4, RecentMean: 0.31, Baseline: 0.08, At: at},
+		AlertTransferBlocked:   TransferBlockedAlert{Provider: "hyperliquid", Action: "withdraw3", At: at},
+		AlertRolloutRolledBack: RolloutRolledBackAlert{TraderID: "t1", Rollout: "fast", Candidate: "fast-v2", Baseline: "fast-v1", Reason: "drawdown 6.00% exceeds 5.00%", CandidateCycles: 12, At: at},
+	}
+	for _, dt := range AlertDataTypes() {
+		data, ok := samples[dt.Kind]
+		require.True(t, ok, "missing sample for %s", dt.Kind)
+		msg, ok, err := tmpls.Render("telegram", Alert{Kind: dt.Kind, Data: data})
+		require.NoError(t, err, dt.Kind)
+		assert.True(t, ok, dt.Kind)
+		tag := "[t1]"
+		if dt.Kind == AlertClockSkew || dt.Kind == AlertTransferBlocked {
+			tag = "[hyperliquid]"
+		}
+		assert.Contains(t, msg, tag, dt.Kind)
+	}
+
+	msg, _, err := tmpls.Render("webhook", Alert{Kind: AlertTradeExecuted, Data: samples[AlertTradeExecuted]})
+	require.NoError(t, err)
+	assert.Equal(t, "[t1] open_long BTC $250.00 @ market (5x, confidence 82)", msg)
+}
+
+func TestAlertTemplatesChannelOverride(t *testing.T) {
+	dir := t.TempDir()
+	writeAlertTemplate(t, dir, "default", AlertStopHit, "default {{ .Symbol }}")
+	writeAlertTemplate(t, dir, "telegram", AlertStopHit, "tg {{ .Symbol }}")
+
+	tmpls, err := LoadAlertTemplates(dir)
+	require.NoError(t, err)
+
+	alert := Alert{Kind: AlertStopHit, Data: StopHitAlert{Symbol: "SOL"}}
+	msg, ok, err := tmpls.Render("telegram", alert)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "tg SOL", msg)
+
+	msg, ok, err = tmpls.Render("webhook", alert)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "default SOL", msg)
+
+	_, ok, err = tmpls.Render("webhook", Alert{Kind: AlertTradeExecuted, Data: TradeExecutedAlert{}})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestLoadAlertTemplatesRejectsUnknownFieldsAndKinds(t *testing.T) {
+	dir := t.TempDir()
+	writeAlertTemplate(t, dir, "default", AlertTradeExecuted, "{{ .Ticker }}")
+	_, err := LoadAlertTemplates(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TradeExecutedAlert")
+
+	dir = t.TempDir()
+	writeAlertTemplate(t, dir, "default", "margin_call", "hi")
+	_, err = LoadAlertTemplates(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown alert kind")
+}
+
+func TestAlertDataTypeFieldsDocumented(t *testing.T) {
+	for _, dt := range AlertDataTypes() {
+		for _, f := range dt.Fields() {
+			assert.NotEmpty(t, f.Doc, "%s.%s missing doc tag", dt.Type.Name(), f.Name)
+		}
+	}
+}
+
+func writeAlertTemplate(t *testing.T, dir, channel, kind, body string) {
+	t.Helper()
+	path := filepath.Join(dir, channel, kind+".tmpl")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o644))
+}
@@ -0,0 +1,240 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/market"
+)
+
+const (
+	defaultBlacklistMaxAnomalies = 3
+	defaultBlacklistWindow       = 15 * time.Minute
+	defaultBlacklistCooldown     = time.Hour
+)
+
+// SymbolBlacklistConfig quarantines symbols whose data feed keeps failing or
+// whose snapshots breach sanity bounds. Snapshots carry only the last price,
+// so the bounds are volatility based.
+type SymbolBlacklistConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// MaxAnomalies within Window blacklist the symbol for Cooldown.
+	MaxAnomalies int           `yaml:"max_anomalies" json:"max_anomalies"`
+	Window       time.Duration `yaml:"-" json:"window_duration"`
+	Cooldown     time.Duration `yaml:"-" json:"cooldown_duration"`
+	// Sanity bounds; 0 disables each check.
+	MaxMove1hPct float64 `yaml:"max_move_1h_pct" json:"max_move_1h_pct"`
+	MaxATRPct    float64 `yaml:"max_atr_pct" json:"max_atr_pct"`
+
+	WindowRaw   string `yaml:"window" json:"window"`
+	CooldownRaw string `yaml:"cooldown" json:"cooldown"`
+}
+
+func (c *SymbolBlacklistConfig) parseDurations() error {
+	var err error
+	if c.MaxAnomalies == 0 {
+		c.MaxAnomalies = defaultBlacklistMaxAnomalies
+	}
+	c.Window, c.Cooldown = defaultBlacklistWindow, defaultBlacklistCooldown
+	if strings.TrimSpace(c.WindowRaw) != "" {
+		if c.Window, err = parsePositiveDuration("manager.symbol_blacklist.window", c.WindowRaw); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(c.CooldownRaw) != "" {
+		if c.Cooldown, err = parsePositiveDuration("manager.symbol_blacklist.cooldown", c.CooldownRaw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks the anomaly threshold and sanity bounds.
+func (c SymbolBlacklistConfig) Validate() error {
+	if c.MaxAnomalies < 0 {
+		return fmt.Errorf("manager config: manager.symbol_blacklist.max_anomalies cannot be negative")
+	}
+	if c.MaxMove1hPct < 0 || c.MaxATRPct < 0 {
+		return fmt.Errorf("manager config: manager.symbol_blacklist sanity bounds cannot be negative")
+	}
+	return nil
+}
+
+// sanityViolation describes why snap is implausible, or returns "".
+func (c SymbolBlacklistConfig) sanityViolation(snap *market.Snapshot) string {
+	px := snap.Price.Last
+	if !(px > 0) || math.IsInf(px, 0) {
+		return fmt.Sprintf("invalid price %v", px)
+	}
+	if move := math.Abs(snap.Change.OneHour) * 100; c.MaxMove1hPct > 0 && move > c.MaxMove1hPct {
+		return fmt.Sprintf("1h move %.2f%% exceeds %.2f%%", move, c.MaxMove1hPct)
+	}
+	if atr := snap.LatestATR("ATR14"); c.MaxATRPct > 0 && atr > 0 && 100*atr/px > c.MaxATRPct {
+		return fmt.Sprintf("ATR14 %.2f%% of price exceeds %.2f%%", 100*atr/px, c.MaxATRPct)
+	}
+	return ""
+}
+
+// BlacklistEntry is a symbol excluded from prompts and new entries.
+type BlacklistEntry struct {
+	Symbol string
+	Reason string
+	Since  time.Time
+	Until  time.Time
+}
+
+// symbolBlacklist tracks recent anomalies and active entries per symbol.
+type symbolBlacklist struct {
+	mu        sync.Mutex
+	cfg       SymbolBlacklistConfig
+	anomalies map[string][]time.Time
+	entries   map[string]BlacklistEntry
+}
+
+func newSymbolBlacklist(cfg SymbolBlacklistConfig) *symbolBlacklist {
+	return &symbolBlacklist{cfg: cfg, anomalies: map[string][]time.Time{}, entries: map[string]BlacklistEntry{}}
+}
+
+// record counts one anomaly and blacklists the symbol once the threshold is
+// reached within the window. tripped reports a new entry.
+func (b *symbolBlacklist) record(symbol, reason string, now time.Time) (entry BlacklistEntry, tripped bool) {
+	if !b.cfg.Enabled {
+		return BlacklistEntry{}, false
+	}
+	symbol = normalizeSymbol(symbol)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if e, ok := b.entries[symbol]; ok && now.Before(e.Until) {
+		return e, false
+	}
+	cutoff := now.Add(-b.cfg.Window)
+	recent := b.anomalies[symbol][:0]
+	for _, at := range b.anomalies[symbol] {
+		if at.After(cutoff) {
+			recent = append(recent, at)
+		}
+	}
+	recent = append(recent, now)
+	if len(recent) < b.cfg.MaxAnomalies {
+		b.anomalies[symbol] = recent
+		return BlacklistEntry{}, false
+	}
+	delete(b.anomalies, symbol)
+	entry = BlacklistEntry{
+		Symbol: symbol,
+		Reason: fmt.Sprintf("%d anomalies within %s, last: %s", len(recent), b.cfg.Window, reason),
+		Since:  now,
+		Until:  now.Add(b.cfg.Cooldown),
+	}
+	b.entries[symbol] = entry
+	return entry, true
+}
+
+// active returns the symbol's entry while its cooldown runs.
+func (b *symbolBlacklist) active(symbol string, now time.Time) (BlacklistEntry, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.entries[normalizeSymbol(symbol)]
+	if !ok || !now.Before(e.Until) {
+		return BlacklistEntry{}, false
+	}
+	return e, true
+}
+
+// list returns the active entries ordered by symbol, dropping expired ones.
+func (b *symbolBlacklist) list(now time.Time) []BlacklistEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]BlacklistEntry, 0, len(b.entries))
+	for sym, e := range b.entries {
+		if !now.Before(e.Until) {
+			delete(b.entries, sym)
+			continue
+		}
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Symbol < out[j].Symbol })
+	return out
+}
+
+// clear lifts an entry and forgets its anomalies.
+func (b *symbolBlacklist) clear(symbol string) bool {
+	symbol = normalizeSymbol(symbol)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.entries[symbol]
+	delete(b.entries, symbol)
+	delete(b.anomalies, symbol)
+	return ok
+}
+
+// BlacklistedSymbols returns the symbols currently excluded from prompts and
+// new entries, ordered by symbol.
+func (m *Manager) BlacklistedSymbols() []BlacklistEntry {
+	return m.blacklist.list(time.Now())
+}
+
+// UnblacklistSymbol lifts a blacklist entry before its cooldown ends.
+func (m *Manager) UnblacklistSymbol(ctx context.Context, symbol string) error {
+	if !m.blacklist.clear(symbol) {
+		return fmt.Errorf("manager: symbol %s is not blacklisted", normalizeSymbol(symbol))
+	}
+	logx.WithContext(ctx).Infof("manager: symbol %s removed from blacklist by operator", normalizeSymbol(symbol))
+	return nil
+}
+
+// noteSymbolAnomaly records a feed failure or sanity breach seen during
+// trader t's cycle, alerting when it blacklists the symbol.
+func (m *Manager) noteSymbolAnomaly(ctx context.Context, t *VirtualTrader, symbol, reason string) {
+	entry, tripped := m.blacklist.record(symbol, reason, time.Now())
+	if !tripped {
+		return
+	}
+	msg := fmt.Sprintf("symbol %s blacklisted until %s: %s", entry.Symbol, entry.Until.Format(time.RFC3339), entry.Reason)
+	m.sendAlert(ctx, Alert{
+		Kind:     AlertSymbolBlacklisted,
+		TraderID: t.ID,
+		Message:  msg,
+		Data: SymbolBlacklistedAlert{
+			TraderID: t.ID,
+			Symbol:   entry.Symbol,
+			Reason:   entry.Reason,
+			Until:    entry.Until,
+			At:       entry.Since,
+		},
+	})
+}
+
+// screenSnapshot records anomalies for one fetch result and reports whether
+// the snapshot is usable.
+func (m *Manager) screenSnapshot(ctx context.Context, t *VirtualTrader, res snapshotResult) bool {
+	if res.Err != nil {
+		m.noteSymbolAnomaly(ctx, t, res.Symbol, "feed error: "+res.Err.Error())
+		return false
+	}
+	if reason := m.blacklist.cfg.sanityViolation(res.Snapshot); m.blacklist.cfg.Enabled && reason != "" {
+		m.noteSymbolAnomaly(ctx, t, res.Symbol, reason)
+		return false
+	}
+	return true
+}
+
+// blacklistedUntil maps active entries to their expiry for the executor.
+func (m *Manager) blacklistedUntil() map[string]time.Time {
+	entries := m.blacklist.list(time.Now())
+	if len(entries) == 0 {
+		return nil
+	}
+	out := make(map[string]time.Time, len(entries))
+	for _, e := range entries {
+		out[e.Symbol] = e.Until
+	}
+	return out
+}
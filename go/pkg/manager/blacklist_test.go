@@ -0,0 +1,78 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/market"
+)
+
+func TestSymbolBlacklistTripsAndExpires(t *testing.T) {
+	cfg := SymbolBlacklistConfig{Enabled: true}
+	require.NoError(t, cfg.parseDurations())
+	b := newSymbolBlacklist(cfg)
+	now := time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	_, tripped := b.record("sol", "feed error: timeout", now)
+	assert.False(t, tripped)
+	// An anomaly outside the window no longer counts.
+	_, tripped = b.record("SOL", "feed error: timeout", now.Add(20*time.Minute))
+	assert.False(t, tripped)
+	_, tripped = b.record("SOL", "feed error: timeout", now.Add(25*time.Minute))
+	assert.False(t, tripped)
+
+	entry, tripped := b.record("SOL", "1h move 40.00% exceeds 25.00%", now.Add(30*time.Minute))
+	require.True(t, tripped)
+	assert.Equal(t, "SOL", entry.Symbol)
+	assert.Equal(t, "3 anomalies within 15m0s, last: 1h move 40.00% exceeds 25.00%", entry.Reason)
+	assert.Equal(t, now.Add(90*time.Minute), entry.Until)
+
+	_, tripped = b.record("SOL", "feed error", now.Add(31*time.Minute))
+	assert.False(t, tripped, "an active entry is not re-tripped")
+	_, ok := b.active("sol", now.Add(89*time.Minute))
+	assert.True(t, ok)
+	_, ok = b.active("SOL", now.Add(90*time.Minute))
+	assert.False(t, ok)
+	assert.Empty(t, b.list(now.Add(2*time.Hour)))
+}
+
+func TestSymbolBlacklistOperatorClear(t *testing.T) {
+	cfg := SymbolBlacklistConfig{Enabled: true, MaxAnomalies: 1}
+	require.NoError(t, cfg.parseDurations())
+	b := newSymbolBlacklist(cfg)
+	now := time.Now()
+
+	_, tripped := b.record("ETH", "feed error", now)
+	require.True(t, tripped)
+	require.Len(t, b.list(now), 1)
+
+	assert.True(t, b.clear("eth"))
+	assert.False(t, b.clear("ETH"))
+	_, ok := b.active("ETH", now)
+	assert.False(t, ok)
+}
+
+func TestSymbolBlacklistDisabled(t *testing.T) {
+	b := newSymbolBlacklist(SymbolBlacklistConfig{MaxAnomalies: 1})
+	_, tripped := b.record("BTC", "feed error", time.Now())
+	assert.False(t, tripped)
+}
+
+func TestSymbolBlacklistSanityViolation(t *testing.T) {
+	cfg := SymbolBlacklistConfig{MaxMove1hPct: 25, MaxATRPct: 10}
+	snap := &market.Snapshot{Price: market.PriceInfo{Last: 100}, Change: market.ChangeInfo{OneHour: 0.05}}
+	assert.Empty(t, cfg.sanityViolation(snap))
+
+	snap.Change.OneHour = -0.4
+	assert.Equal(t, "1h move 40.00% exceeds 25.00%", cfg.sanityViolation(snap))
+
+	snap.Change.OneHour = 0
+	snap.LongTerm = &market.SeriesBundle{ATR: map[string][]float64{"ATR14": {12}}}
+	assert.Equal(t, "ATR14 12.00% of price exceeds 10.00%", cfg.sanityViolation(snap))
+
+	snap.Price.Last = 0
+	assert.Equal(t, "invalid price 0", cfg.sanityViolation(snap))
+}
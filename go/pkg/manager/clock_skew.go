@@ -0,0 +1,90 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+)
+
+const clockSyncTimeout = 10 * time.Second
+
+// runClockSkewMonitor compares exchange clocks with the local clock on the
+// clock sync interval until the manager stops.
+func (m *Manager) runClockSkewMonitor(ctx context.Context) {
+	interval := 5 * time.Minute
+	if m.config != nil && m.config.Monitoring.ClockSyncInterval > 0 {
+		interval = m.config.Monitoring.ClockSyncInterval
+	}
+	m.checkClockSkew(ctx, time.Now())
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopChan:
+			return
+		case now := <-ticker.C:
+			m.checkClockSkew(ctx, now)
+		}
+	}
+}
+
+// checkClockSkew measures every provider that supports it and alerts when a
+// provider's skew first exceeds its threshold.
+func (m *Manager) checkClockSkew(ctx context.Context, now time.Time) {
+	names := make([]string, 0, len(m.exchangeProviders))
+	for name := range m.exchangeProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		syncer, ok := m.exchangeProviders[name].(exchange.ClockSyncer)
+		if !ok {
+			continue
+		}
+		syncCtx, cancel := context.WithTimeout(ctx, clockSyncTimeout)
+		skew, err := syncer.SyncClock(syncCtx)
+		cancel()
+		if err != nil {
+			logx.WithContext(ctx).Slowf("manager: clock sync %s: %v", name, err)
+			continue
+		}
+		exceeded := skew.Exceeded()
+		m.skewMu.Lock()
+		was := m.skewed[name]
+		m.skewed[name] = exceeded
+		m.skewMu.Unlock()
+		if exceeded == was {
+			continue
+		}
+		if !exceeded {
+			logx.WithContext(ctx).Infof("manager: clock skew for %s back within %s (offset %s)", name, skew.Threshold, skew.Offset)
+			continue
+		}
+		m.sendAlert(ctx, Alert{
+			Kind:    AlertClockSkew,
+			Message: fmt.Sprintf("exchange %s clock skew %s exceeds %s", name, skew.Offset, skew.Threshold),
+			Details: map[string]any{
+				"provider":     name,
+				"offset_ms":    skew.Offset.Milliseconds(),
+				"threshold_ms": skew.Threshold.Milliseconds(),
+				"rtt_ms":       skew.RTT.Milliseconds(),
+				"adjusted":     skew.Adjusted,
+			},
+			At: now,
+			Data: ClockSkewAlert{
+				Provider:  name,
+				Offset:    skew.Offset,
+				Threshold: skew.Threshold,
+				Adjusted:  skew.Adjusted,
+				At:        now,
+			},
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+type skewProvider struct {
+	exchange.Provider
+	skew exchange.ClockSkew
+}
+
+func (p *skewProvider) SyncClock(context.Context) (exchange.ClockSkew, error) {
+	return p.skew, nil
+}
+
+func TestClockSkewAlertsOncePerExcursion(t *testing.T) {
+	alerter := &recordingAlerter{}
+	provider := &skewProvider{skew: exchange.ClockSkew{Offset: 3 * time.Second, Threshold: 2 * time.Second, Adjusted: true}}
+	m := NewManager(&Config{}, nil, map[string]exchange.Provider{"hl": provider, "plain": &skewlessProvider{}}, nil, nil, WithAlerter(alerter))
+	now := time.Now()
+
+	m.checkClockSkew(context.Background(), now)
+	m.checkClockSkew(context.Background(), now.Add(time.Minute))
+	require.Len(t, alerter.alerts, 1, "a persistent skew alerts once")
+	alert := alerter.alerts[0]
+	assert.Equal(t, AlertClockSkew, alert.Kind)
+	data, ok := alert.Data.(ClockSkewAlert)
+	require.True(t, ok)
+	assert.Equal(t, "hl", data.Provider)
+	assert.Equal(t, 3*time.Second, data.Offset)
+	assert.True(t, data.Adjusted)
+
+	provider.skew.Offset = 100 * time.Millisecond
+	m.checkClockSkew(context.Background(), now.Add(2*time.Minute))
+	provider.skew.Offset = -5 * time.Second
+	m.checkClockSkew(context.Background(), now.Add(3*time.Minute))
+	assert.Len(t, alerter.alerts, 2, "a new excursion alerts again")
+}
+
+// skewlessProvider does not implement exchange.ClockSyncer.
+type skewlessProvider struct {
+	exchange.Provider
+}
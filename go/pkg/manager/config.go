@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
@@ -13,6 +14,11 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"nof0-api/pkg/confkit"
+	"nof0-api/pkg/exchange"
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/llm"
+	"nof0-api/pkg/prompt"
+	"nof0-api/pkg/strategy"
 )
 
 // OrderStyle defines how the manager submits opening orders.
@@ -23,6 +29,19 @@ const (
 	OrderStyleMarketIOC OrderStyle = "market_ioc"
 
 	defaultMarketIOCSlippageBps = 50.0 // 0.50% slippage
+	defaultSnapshotConcurrency  = 4
+)
+
+// PartialDataPolicy defines how a cycle proceeds when some symbols fail to load market data.
+type PartialDataPolicy string
+
+const (
+	// PartialDataAnnotate renders the prompt and lists the missing symbols as unavailable.
+	PartialDataAnnotate PartialDataPolicy = "annotate"
+	// PartialDataDrop silently omits symbols without data from the prompt.
+	PartialDataDrop PartialDataPolicy = "drop"
+	// PartialDataAbort skips the decision cycle entirely.
+	PartialDataAbort PartialDataPolicy = "abort"
 )
 
 // Config defines the overall manager configuration schema.
@@ -31,6 +50,14 @@ type Config struct {
 	Traders    []TraderConfig   `yaml:"traders" json:"traders"`
 	Monitoring MonitoringConfig `yaml:"monitoring" json:"monitoring"`
 
+	// TemplateFS, when set, holds the prompt templates: prompt_template and
+	// executor_prompt_template are paths within it rather than on disk.
+	TemplateFS fs.FS `yaml:"-" json:"-"`
+
+	// PromptVersions registers named executor prompt versions, the A/B
+	// experiments splitting decisions between them and staged rollouts.
+	PromptVersions *prompt.Config `yaml:"prompt_versions" json:"prompt_versions,omitempty"`
+
 	baseDir string `json:"-"`
 }
 
@@ -41,28 +68,65 @@ type ManagerConfig struct {
 	RebalanceInterval   time.Duration `yaml:"-" json:"rebalance_interval_duration"`
 	StateStorageBackend string        `yaml:"state_storage_backend" json:"state_storage_backend"`
 	StateStoragePath    string        `yaml:"state_storage_path" json:"state_storage_path"`
+	// SnapshotConcurrency bounds parallel per-symbol market snapshot fetches per cycle.
+	SnapshotConcurrency int           `yaml:"snapshot_concurrency" json:"snapshot_concurrency"`
+	SnapshotTimeout     time.Duration `yaml:"-" json:"snapshot_timeout_duration"`
+	// SymbolBlacklist quarantines symbols with repeated data anomalies.
+	SymbolBlacklist SymbolBlacklistConfig `yaml:"symbol_blacklist" json:"symbol_blacklist"`
+	// AllowedFuncs, when set, are the only functions (text/template builtins
+	// included) trader prompt templates may call; others fail to load.
+	AllowedFuncs []string `yaml:"allowed_funcs" json:"allowed_funcs,omitempty"`
 
 	RebalanceIntervalRaw string `yaml:"rebalance_interval" json:"rebalance_interval"`
+	SnapshotTimeoutRaw   string `yaml:"snapshot_timeout" json:"snapshot_timeout"`
 }
 
 type TraderConfig struct {
-	ID                   string         `yaml:"id" json:"id"`
-	Name                 string         `yaml:"name" json:"name"`
-	ExchangeProvider     string         `yaml:"exchange_provider" json:"exchange_provider"`
-	MarketProvider       string         `yaml:"market_provider" json:"market_provider"`
-	OrderStyle           OrderStyle     `yaml:"order_style" json:"order_style"`
-	MarketIOCSlippageBps float64        `yaml:"market_ioc_slippage_bps" json:"market_ioc_slippage_bps"`
-	PromptTemplate       string         `yaml:"prompt_template" json:"prompt_template"`
-	ExecutorTemplate     string         `yaml:"executor_prompt_template" json:"executor_prompt_template"`
-	Model                string         `yaml:"model" json:"model"`
-	DecisionInterval     time.Duration  `yaml:"-" json:"decision_interval_duration"`
-	RiskParams           RiskParameters `yaml:"risk_params" json:"risk_params"`
-	ExecGuards           ExecGuards     `yaml:"exec_guards" json:"exec_guards"`
-	AllocationPct        float64        `yaml:"allocation_pct" json:"allocation_pct"`
-	AutoStart            bool           `yaml:"auto_start" json:"auto_start"`
-	JournalEnabled       bool           `yaml:"journal_enabled" json:"journal_enabled"`
-	JournalDir           string         `yaml:"journal_dir" json:"journal_dir"`
-	Version              int64          `yaml:"-" json:"-"`
+	ID                   string            `yaml:"id" json:"id"`
+	Name                 string            `yaml:"name" json:"name"`
+	ExchangeProvider     string            `yaml:"exchange_provider" json:"exchange_provider"`
+	MarketProvider       string            `yaml:"market_provider" json:"market_provider"`
+	OrderStyle           OrderStyle        `yaml:"order_style" json:"order_style"`
+	PartialDataPolicy    PartialDataPolicy `yaml:"partial_data_policy" json:"partial_data_policy"`
+	MarketIOCSlippageBps float64           `yaml:"market_ioc_slippage_bps" json:"market_ioc_slippage_bps"`
+	PromptTemplate       string            `yaml:"prompt_template" json:"prompt_template"`
+	ExecutorTemplate     string            `yaml:"executor_prompt_template" json:"executor_prompt_template"`
+	// Optional sha256 digests pinning the templates above; a file whose
+	// content no longer matches fails to load.
+	PromptTemplateDigest   string         `yaml:"prompt_template_digest" json:"prompt_template_digest"`
+	ExecutorTemplateDigest string         `yaml:"executor_prompt_template_digest" json:"executor_prompt_template_digest"`
+	Model                  string         `yaml:"model" json:"model"`
+	DecisionInterval       time.Duration  `yaml:"-" json:"decision_interval_duration"`
+	RiskParams             RiskParameters `yaml:"risk_params" json:"risk_params"`
+	ExecGuards             ExecGuards     `yaml:"exec_guards" json:"exec_guards"`
+	AllocationPct          float64        `yaml:"allocation_pct" json:"allocation_pct"`
+	AutoStart              bool           `yaml:"auto_start" json:"auto_start"`
+	JournalEnabled         bool           `yaml:"journal_enabled" json:"journal_enabled"`
+	JournalDir             string         `yaml:"journal_dir" json:"journal_dir"`
+	Shadow                 ShadowConfig   `yaml:"shadow" json:"shadow"`
+	Version                int64          `yaml:"-" json:"-"`
+
+	// RegimeSchedule varies the decision interval with market volatility.
+	RegimeSchedule RegimeScheduleConfig `yaml:"regime_schedule" json:"regime_schedule"`
+	// Triggers force a decision ahead of the timer on market events.
+	Triggers TriggerConfig `yaml:"triggers" json:"triggers"`
+	// MarginMode is how opens are collateralised on the exchange: cross
+	// (default) or isolated.
+	MarginMode exchange.MarginMode `yaml:"margin_mode" json:"margin_mode"`
+	// PositionMode is how the exchange account holds positions: net (default)
+	// or hedge, where a long and a short can be open on one symbol.
+	PositionMode exchange.PositionMode `yaml:"position_mode" json:"position_mode"`
+	// SubAccount runs the trader on an exchange sub-account with its own
+	// collateral instead of the provider's shared account.
+	SubAccount SubAccountConfig `yaml:"sub_account" json:"sub_account"`
+	// DataQualityInPrompt shows per-symbol data quality scores to the model.
+	DataQualityInPrompt bool `yaml:"data_quality_in_prompt" json:"data_quality_in_prompt"`
+	// Advisors add non-LLM analysis to the prompt and may veto opens.
+	Advisors []strategy.Config `yaml:"advisors" json:"advisors"`
+	// OutputAnomaly flags, and optionally pauses, degenerate model output.
+	OutputAnomaly OutputAnomalyConfig `yaml:"output_anomaly" json:"output_anomaly"`
+	// PromptDrift alerts when rendered prompts drift from their baseline.
+	PromptDrift PromptDriftConfig `yaml:"prompt_drift" json:"prompt_drift"`
 
 	DecisionIntervalRaw string `yaml:"decision_interval" json:"decision_interval"`
 }
@@ -73,6 +137,16 @@ type ExecGuards struct {
 	LiquidityThresholdUSD   float64 `yaml:"liquidity_threshold_usd" json:"liquidity_threshold_usd"`
 	MaxMarginUsagePct       float64 `yaml:"max_margin_usage_pct" json:"max_margin_usage_pct"`
 
+	// Trade-count throttle over rolling windows (new opens only); 0 disables.
+	MaxOpensPerHour int `yaml:"max_opens_per_hour" json:"max_opens_per_hour"`
+	MaxOpensPerDay  int `yaml:"max_opens_per_day" json:"max_opens_per_day"`
+
+	// Loss-streak cooldown: after LossStreakLimit consecutive losing closes,
+	// new entries pause for LossStreakCooldown while exits stay managed.
+	LossStreakLimit       int           `yaml:"loss_streak_limit" json:"loss_streak_limit"`
+	LossStreakCooldown    time.Duration `yaml:"-" json:"loss_streak_cooldown_duration"`
+	LossStreakCooldownRaw string        `yaml:"loss_streak_cooldown" json:"loss_streak_cooldown"`
+
 	BTCETHMinEquityMultiple float64 `yaml:"btceth_position_value_min_equity_multiple" json:"btceth_position_value_min_equity_multiple"`
 	BTCETHMaxEquityMultiple float64 `yaml:"btceth_position_value_max_equity_multiple" json:"btceth_position_value_max_equity_multiple"`
 	AltMinEquityMultiple    float64 `yaml:"alt_position_value_min_equity_multiple" json:"alt_position_value_min_equity_multiple"`
@@ -80,6 +154,13 @@ type ExecGuards struct {
 
 	CooldownAfterClose    time.Duration `yaml:"-" json:"cooldown_after_close_duration"`
 	CooldownAfterCloseRaw string        `yaml:"cooldown_after_close" json:"cooldown_after_close"`
+
+	// Simulate-then-confirm: opens of at least SimulateAboveUSD are first
+	// estimated against the order book and aborted when the estimated
+	// slippage exceeds MaxSimulatedSlippageBps. 0 disables.
+	SimulateAboveUSD        float64 `yaml:"simulate_above_usd" json:"simulate_above_usd"`
+	MaxSimulatedSlippageBps float64 `yaml:"max_simulated_slippage_bps" json:"max_simulated_slippage_bps"`
+
 	// Feature toggles (default true if omitted)
 	EnableLiquidityGuard   *bool `yaml:"enable_liquidity_guard" json:"enable_liquidity_guard"`
 	EnableMarginUsageGuard *bool `yaml:"enable_margin_usage_guard" json:"enable_margin_usage_guard"`
@@ -95,6 +176,39 @@ type ExecGuards struct {
 	PauseDurationOnBreachRaw string        `yaml:"pause_duration_on_breach" json:"pause_duration_on_breach"`
 }
 
+// ShadowConfig evaluates a candidate model against the trader's live model.
+// The candidate receives the same prompts every cycle but its decisions are
+// only paper-traded; after EvaluationDays a report compares both books and
+// decides whether the candidate should be promoted.
+type ShadowConfig struct {
+	Enabled        bool   `yaml:"enabled" json:"enabled"`
+	Model          string `yaml:"model" json:"model"`
+	EvaluationDays int    `yaml:"evaluation_days" json:"evaluation_days"`
+	// MinEdgePct is how many percentage points of return the candidate must
+	// gain over the incumbent to be promoted.
+	MinEdgePct float64 `yaml:"min_edge_pct" json:"min_edge_pct"`
+	// MaxDrawdownPct rejects a candidate whose paper drawdown exceeds it; 0 disables.
+	MaxDrawdownPct float64 `yaml:"max_drawdown_pct" json:"max_drawdown_pct"`
+	// MinTrades is the number of closed candidate trades needed for a verdict.
+	MinTrades int `yaml:"min_trades" json:"min_trades"`
+	// AutoPromote swaps the candidate in as the live model when it wins.
+	AutoPromote bool `yaml:"auto_promote" json:"auto_promote"`
+}
+
+// SubAccountConfig maps a trader to an exchange sub-account, created on
+// registration when missing, so a misbehaving strategy can lose at most the
+// collateral moved there rather than margin shared with other traders.
+// Sub-accounts are per model by default: traders running the same model on
+// one exchange provider share theirs.
+type SubAccountConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Name identifies the sub-account; it defaults to "nof0-<model>".
+	Name string `yaml:"name" json:"name"`
+	// FundUSD tops the sub-account up to this equity from the master
+	// account on registration; 0 leaves funding to the operator.
+	FundUSD float64 `yaml:"fund_usd" json:"fund_usd"`
+}
+
 type RiskParameters struct {
 	MaxPositions       int     `yaml:"max_positions" json:"max_positions"`
 	MaxPositionSizeUSD float64 `yaml:"max_position_size_usd" json:"max_position_size_usd"`
@@ -105,14 +219,57 @@ type RiskParameters struct {
 	MinConfidence      int     `yaml:"min_confidence" json:"min_confidence"`
 	StopLossEnabled    bool    `yaml:"stop_loss_enabled" json:"stop_loss_enabled"`
 	TakeProfitEnabled  bool    `yaml:"take_profit_enabled" json:"take_profit_enabled"`
+	// MaxRiskPerTradePct caps risk_usd as a percentage of equity; 0 disables.
+	MaxRiskPerTradePct float64         `yaml:"max_risk_per_trade_pct" json:"max_risk_per_trade_pct"`
+	DrawdownScaling    DrawdownScaling `yaml:"drawdown_scaling" json:"drawdown_scaling"`
+	Sizing             PositionSizing  `yaml:"sizing" json:"sizing"`
+	// VolatilityLeverage lowers the model's leverage on high-ATR symbols.
+	VolatilityLeverage executorpkg.VolatilityLeverage `yaml:"volatility_leverage" json:"volatility_leverage"`
+	// AllowHedge permits opening the opposite side of a held symbol when the
+	// trader's position_mode is hedge; false keeps exposure one-sided.
+	AllowHedge bool `yaml:"allow_hedge" json:"allow_hedge"`
+	// Execution-time limits enforced by pkg/risk on every entry just before
+	// it is sent to the exchange; 0 disables each.
+	MaxLossPerTradeUSD          float64 `yaml:"max_loss_per_trade_usd" json:"max_loss_per_trade_usd"`
+	MinLiquidationDistancePct   float64 `yaml:"min_liquidation_distance_pct" json:"min_liquidation_distance_pct"`
+	MaxPositionConcentrationPct float64 `yaml:"max_position_concentration_pct" json:"max_position_concentration_pct"`
+	MinPositionSizeUSD          float64 `yaml:"min_position_size_usd" json:"min_position_size_usd"`
+	// ResizeOrders shrinks entries breaking those limits to fit instead of
+	// rejecting them.
+	ResizeOrders bool `yaml:"resize_orders" json:"resize_orders"`
 }
 
 type MonitoringConfig struct {
 	UpdateInterval  time.Duration `yaml:"-" json:"update_interval_duration"`
 	AlertWebhook    string        `yaml:"alert_webhook" json:"alert_webhook"`
 	MetricsExporter string        `yaml:"metrics_exporter" json:"metrics_exporter"`
+	// AlertTemplatesDir holds <channel>/<kind>.tmpl alert message templates.
+	AlertTemplatesDir string `yaml:"alert_templates_dir" json:"alert_templates_dir"`
+	// StallAfter flags a runner as stalled when no cycle completed within it.
+	// Zero derives the threshold from each trader's decision interval.
+	StallAfter time.Duration `yaml:"-" json:"stall_after_duration"`
+	// Telegram configures the operator bot (see pkg/telegram).
+	Telegram TelegramConfig `yaml:"telegram" json:"telegram"`
+	// ClockSyncInterval is how often exchange clocks are compared with the
+	// local clock (providers implementing exchange.ClockSyncer only).
+	ClockSyncInterval time.Duration `yaml:"-" json:"clock_sync_interval_duration"`
+
+	UpdateIntervalRaw    string `yaml:"update_interval" json:"update_interval"`
+	StallAfterRaw        string `yaml:"stall_after" json:"stall_after"`
+	ClockSyncIntervalRaw string `yaml:"clock_sync_interval" json:"clock_sync_interval"`
+}
 
-	UpdateIntervalRaw string `yaml:"update_interval" json:"update_interval"`
+// TelegramConfig configures the Telegram operator bot. Access is granted per
+// chat ID: viewers may query status and positions, operators may also pause,
+// resume and flatten traders.
+type TelegramConfig struct {
+	Enabled   bool    `yaml:"enabled" json:"enabled"`
+	BotToken  string  `yaml:"bot_token" json:"-"`
+	Operators []int64 `yaml:"operators" json:"operators"`
+	Viewers   []int64 `yaml:"viewers" json:"viewers"`
+	// DailySummaryAt is the UTC HH:MM at which a summary is sent to every
+	// allowed chat. Empty disables the summary.
+	DailySummaryAt string `yaml:"daily_summary_at" json:"daily_summary_at"`
 }
 
 // LoadConfig reads configuration from disk.
@@ -136,8 +293,24 @@ func MustLoad() *Config {
 	return cfg
 }
 
+// LoadConfigWithTemplateFS reads configuration from disk and resolves prompt
+// templates against templates, typically an embed.FS compiled into the binary.
+func LoadConfigWithTemplateFS(path string, templates fs.FS) (*Config, error) {
+	confkit.LoadDotenvOnce()
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open manager config: %w", err)
+	}
+	defer file.Close()
+	return loadConfig(file, filepath.Dir(path), templates)
+}
+
 // LoadConfigFromReader constructs a Config from a reader with the provided base directory.
 func LoadConfigFromReader(r io.Reader, baseDir string) (*Config, error) {
+	return loadConfig(r, baseDir, nil)
+}
+
+func loadConfig(r io.Reader, baseDir string, templates fs.FS) (*Config, error) {
 	confkit.LoadDotenvOnce()
 	data, err := io.ReadAll(r)
 	if err != nil {
@@ -149,6 +322,7 @@ func LoadConfigFromReader(r io.Reader, baseDir string) (*Config, error) {
 		return nil, fmt.Errorf("unmarshal manager config: %w", err)
 	}
 	cfg.baseDir = baseDir
+	cfg.TemplateFS = templates
 
 	cfg.applyDefaults()
 	if err := cfg.parseDurations(); err != nil {
@@ -166,6 +340,12 @@ func (c *Config) applyDefaults() {
 	if strings.TrimSpace(c.Manager.RebalanceIntervalRaw) == "" {
 		c.Manager.RebalanceIntervalRaw = "1h"
 	}
+	if c.Manager.SnapshotConcurrency == 0 {
+		c.Manager.SnapshotConcurrency = defaultSnapshotConcurrency
+	}
+	if strings.TrimSpace(c.Manager.SnapshotTimeoutRaw) == "" {
+		c.Manager.SnapshotTimeoutRaw = "4s"
+	}
 	for i := range c.Traders {
 		if strings.TrimSpace(c.Traders[i].DecisionIntervalRaw) == "" {
 			c.Traders[i].DecisionIntervalRaw = "3m"
@@ -177,13 +357,38 @@ func (c *Config) applyDefaults() {
 		if strings.TrimSpace(string(c.Traders[i].OrderStyle)) == "" {
 			c.Traders[i].OrderStyle = OrderStyleLimitIOC
 		}
+		if strings.TrimSpace(string(c.Traders[i].PartialDataPolicy)) == "" {
+			c.Traders[i].PartialDataPolicy = PartialDataDrop
+		}
 		if c.Traders[i].MarketIOCSlippageBps <= 0 {
 			c.Traders[i].MarketIOCSlippageBps = defaultMarketIOCSlippageBps
 		}
+		if strings.TrimSpace(string(c.Traders[i].MarginMode)) == "" {
+			c.Traders[i].MarginMode = exchange.MarginCross
+		}
+		if strings.TrimSpace(string(c.Traders[i].PositionMode)) == "" {
+			c.Traders[i].PositionMode = exchange.PositionModeNet
+		}
+		c.Traders[i].RiskParams.Sizing.applyDefaults()
+		c.Traders[i].RegimeSchedule.applyDefaults()
+		c.Traders[i].Triggers.applyDefaults()
+		c.Traders[i].OutputAnomaly.applyDefaults()
+		c.Traders[i].PromptDrift.applyDefaults()
+		c.Traders[i].SubAccount.Name = strings.TrimSpace(c.Traders[i].SubAccount.Name)
+		if c.Traders[i].SubAccount.Enabled {
+			c.Traders[i].SubAccount.Name = c.Traders[i].SubAccount.accountName(c.Traders[i].Model)
+		}
+		c.Traders[i].Shadow.Model = strings.TrimSpace(c.Traders[i].Shadow.Model)
+		if c.Traders[i].Shadow.EvaluationDays == 0 {
+			c.Traders[i].Shadow.EvaluationDays = defaultShadowEvaluationDays
+		}
 	}
 	if strings.TrimSpace(c.Monitoring.UpdateIntervalRaw) == "" {
 		c.Monitoring.UpdateIntervalRaw = "30s"
 	}
+	if strings.TrimSpace(c.Monitoring.ClockSyncIntervalRaw) == "" {
+		c.Monitoring.ClockSyncIntervalRaw = "5m"
+	}
 }
 
 func (c *Config) parseDurations() error {
@@ -192,12 +397,33 @@ func (c *Config) parseDurations() error {
 	if err != nil {
 		return err
 	}
+	c.Manager.SnapshotTimeout, err = parsePositiveDuration("manager.snapshot_timeout", c.Manager.SnapshotTimeoutRaw)
+	if err != nil {
+		return err
+	}
+	if err := c.Manager.SymbolBlacklist.parseDurations(); err != nil {
+		return err
+	}
 	for i := range c.Traders {
 		d, err := parsePositiveDuration(fmt.Sprintf("traders[%d].decision_interval", i), c.Traders[i].DecisionIntervalRaw)
 		if err != nil {
 			return err
 		}
 		c.Traders[i].DecisionInterval = d
+		if err := c.Traders[i].RegimeSchedule.parseDurations(i); err != nil {
+			return err
+		}
+		if err := c.Traders[i].Triggers.parseDurations(i); err != nil {
+			return err
+		}
+		if err := c.Traders[i].OutputAnomaly.parseDurations(i); err != nil {
+			return err
+		}
+		for j := range c.Traders[i].Advisors {
+			if err := c.Traders[i].Advisors[j].Normalize(); err != nil {
+				return fmt.Errorf("manager config: traders[%d].advisors[%d]: %w", i, j, err)
+			}
+		}
 		// ExecGuards cooldown is optional; parse if provided and non-empty.
 		raw := strings.TrimSpace(c.Traders[i].ExecGuards.CooldownAfterCloseRaw)
 		if raw != "" {
@@ -215,11 +441,29 @@ func (c *Config) parseDurations() error {
 			}
 			c.Traders[i].ExecGuards.PauseDurationOnBreach = pd
 		}
+		rawStreak := strings.TrimSpace(c.Traders[i].ExecGuards.LossStreakCooldownRaw)
+		if rawStreak != "" {
+			sd, err := time.ParseDuration(rawStreak)
+			if err != nil || sd < 0 {
+				return fmt.Errorf("manager config: traders[%d].exec_guards.loss_streak_cooldown invalid: %v", i, err)
+			}
+			c.Traders[i].ExecGuards.LossStreakCooldown = sd
+		}
 	}
 	c.Monitoring.UpdateInterval, err = parsePositiveDuration("monitoring.update_interval", c.Monitoring.UpdateIntervalRaw)
 	if err != nil {
 		return err
 	}
+	c.Monitoring.ClockSyncInterval, err = parsePositiveDuration("monitoring.clock_sync_interval", c.Monitoring.ClockSyncIntervalRaw)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(c.Monitoring.StallAfterRaw) != "" {
+		c.Monitoring.StallAfter, err = parsePositiveDuration("monitoring.stall_after", c.Monitoring.StallAfterRaw)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -227,18 +471,32 @@ func (c *Config) expandFields() {
 	c.Manager.StateStoragePath = c.resolvePath(c.Manager.StateStoragePath)
 	c.Manager.AllocationStrategy = strings.TrimSpace(c.Manager.AllocationStrategy)
 	c.Manager.StateStorageBackend = strings.TrimSpace(c.Manager.StateStorageBackend)
+	for i, name := range c.Manager.AllowedFuncs {
+		c.Manager.AllowedFuncs[i] = strings.TrimSpace(name)
+	}
 	for i := range c.Traders {
 		c.Traders[i].ID = strings.TrimSpace(c.Traders[i].ID)
 		c.Traders[i].Name = strings.TrimSpace(c.Traders[i].Name)
 		c.Traders[i].ExchangeProvider = strings.TrimSpace(c.Traders[i].ExchangeProvider)
 		c.Traders[i].MarketProvider = strings.TrimSpace(c.Traders[i].MarketProvider)
 		c.Traders[i].OrderStyle = OrderStyle(strings.ToLower(strings.TrimSpace(string(c.Traders[i].OrderStyle))))
-		c.Traders[i].PromptTemplate = c.resolvePath(c.Traders[i].PromptTemplate)
-		c.Traders[i].ExecutorTemplate = c.resolvePath(c.Traders[i].ExecutorTemplate)
+		c.Traders[i].PartialDataPolicy = PartialDataPolicy(strings.ToLower(strings.TrimSpace(string(c.Traders[i].PartialDataPolicy))))
+		c.Traders[i].MarginMode, _ = exchange.ParseMarginMode(string(c.Traders[i].MarginMode))
+		c.Traders[i].PositionMode, _ = exchange.ParsePositionMode(string(c.Traders[i].PositionMode))
+		c.Traders[i].PromptTemplate = c.resolveTemplatePath(c.Traders[i].PromptTemplate)
+		c.Traders[i].ExecutorTemplate = c.resolveTemplatePath(c.Traders[i].ExecutorTemplate)
 		c.Traders[i].JournalDir = c.resolvePath(c.Traders[i].JournalDir)
 	}
+	if c.PromptVersions != nil {
+		for i := range c.PromptVersions.Versions {
+			c.PromptVersions.Versions[i].Template = c.resolveTemplatePath(c.PromptVersions.Versions[i].Template)
+		}
+	}
 	c.Monitoring.AlertWebhook = strings.TrimSpace(os.ExpandEnv(c.Monitoring.AlertWebhook))
 	c.Monitoring.MetricsExporter = strings.TrimSpace(c.Monitoring.MetricsExporter)
+	c.Monitoring.AlertTemplatesDir = c.resolvePath(c.Monitoring.AlertTemplatesDir)
+	c.Monitoring.Telegram.BotToken = strings.TrimSpace(os.ExpandEnv(c.Monitoring.Telegram.BotToken))
+	c.Monitoring.Telegram.DailySummaryAt = strings.TrimSpace(c.Monitoring.Telegram.DailySummaryAt)
 }
 
 func (c *Config) resolvePath(path string) string {
@@ -249,6 +507,40 @@ func (c *Config) resolvePath(path string) string {
 	return filepath.Join(c.baseDir, path)
 }
 
+// resolveTemplatePath leaves template paths as fs.FS paths when TemplateFS is set.
+func (c *Config) resolveTemplatePath(path string) string {
+	if c.TemplateFS == nil {
+		return c.resolvePath(path)
+	}
+	return strings.TrimSpace(os.ExpandEnv(path))
+}
+
+// statTemplate checks path in TemplateFS, or on disk when it is unset.
+func (c *Config) statTemplate(path string) error {
+	if c.TemplateFS != nil {
+		_, err := fs.Stat(c.TemplateFS, path)
+		return err
+	}
+	_, err := os.Stat(path)
+	return err
+}
+
+// checkTemplateDigest verifies a pinned template digest; empty pins pass.
+func (c *Config) checkTemplateDigest(path, pinned string) error {
+	pinned = llm.NormalizeDigest(pinned)
+	if pinned == "" {
+		return nil
+	}
+	digest, err := llm.TemplateDigest(c.TemplateFS, path)
+	if err != nil {
+		return err
+	}
+	if digest != pinned {
+		return fmt.Errorf("%q has digest %s, pinned %s", path, digest, pinned)
+	}
+	return nil
+}
+
 // Validate ensures configuration sanity.
 func (c *Config) Validate() error {
 	if c.Manager.TotalEquityUSD < 0 {
@@ -257,6 +549,12 @@ func (c *Config) Validate() error {
 	if c.Manager.ReserveEquityPct < 0 || c.Manager.ReserveEquityPct > 100 {
 		return errors.New("manager config: manager.reserve_equity_pct must be between 0 and 100")
 	}
+	if c.Manager.SnapshotConcurrency < 0 {
+		return errors.New("manager config: manager.snapshot_concurrency cannot be negative")
+	}
+	if err := c.Manager.SymbolBlacklist.Validate(); err != nil {
+		return err
+	}
 	if strings.TrimSpace(c.Manager.StateStorageBackend) == "" {
 		return errors.New("manager config: manager.state_storage_backend is required")
 	}
@@ -289,15 +587,21 @@ func (c *Config) Validate() error {
 		if trader.PromptTemplate == "" {
 			return fmt.Errorf("manager config: traders[%d].prompt_template is required", i)
 		}
-		if _, err := os.Stat(trader.PromptTemplate); err != nil {
+		if err := c.statTemplate(trader.PromptTemplate); err != nil {
 			return fmt.Errorf("manager config: traders[%d].prompt_template %q not accessible: %w", i, trader.PromptTemplate, err)
 		}
 		if strings.TrimSpace(trader.ExecutorTemplate) == "" {
 			return fmt.Errorf("manager config: traders[%d].executor_prompt_template is required", i)
 		}
-		if _, err := os.Stat(trader.ExecutorTemplate); err != nil {
+		if err := c.statTemplate(trader.ExecutorTemplate); err != nil {
 			return fmt.Errorf("manager config: traders[%d].executor_prompt_template %q not accessible: %w", i, trader.ExecutorTemplate, err)
 		}
+		if err := c.checkTemplateDigest(trader.PromptTemplate, trader.PromptTemplateDigest); err != nil {
+			return fmt.Errorf("manager config: traders[%d].prompt_template_digest: %w", i, err)
+		}
+		if err := c.checkTemplateDigest(trader.ExecutorTemplate, trader.ExecutorTemplateDigest); err != nil {
+			return fmt.Errorf("manager config: traders[%d].executor_prompt_template_digest: %w", i, err)
+		}
 		trader.Model = strings.TrimSpace(trader.Model)
 		if trader.AllocationPct < 0 {
 			return fmt.Errorf("manager config: traders[%d].allocation_pct cannot be negative", i)
@@ -309,16 +613,65 @@ func (c *Config) Validate() error {
 		if err := trader.validateOrderStyle(i); err != nil {
 			return err
 		}
+		switch trader.PartialDataPolicy {
+		case "", PartialDataAnnotate, PartialDataDrop, PartialDataAbort:
+		default:
+			return fmt.Errorf("manager config: traders[%d].partial_data_policy %q unsupported (annotate|drop|abort)", i, trader.PartialDataPolicy)
+		}
+		if _, ok := exchange.ParseMarginMode(string(trader.MarginMode)); !ok {
+			return fmt.Errorf("manager config: traders[%d].margin_mode %q unsupported (cross|isolated)", i, trader.MarginMode)
+		}
+		if _, ok := exchange.ParsePositionMode(string(trader.PositionMode)); !ok {
+			return fmt.Errorf("manager config: traders[%d].position_mode %q unsupported (net|hedge)", i, trader.PositionMode)
+		}
 		// ExecGuards validation (optional; non-negative checks)
 		if trader.ExecGuards.MaxNewPositionsPerCycle < 0 {
 			return fmt.Errorf("manager config: traders[%d].exec_guards.max_new_positions_per_cycle cannot be negative", i)
 		}
+		if trader.ExecGuards.MaxOpensPerHour < 0 || trader.ExecGuards.MaxOpensPerDay < 0 {
+			return fmt.Errorf("manager config: traders[%d].exec_guards.max_opens_per_hour/day cannot be negative", i)
+		}
+		if trader.ExecGuards.LossStreakLimit < 0 {
+			return fmt.Errorf("manager config: traders[%d].exec_guards.loss_streak_limit cannot be negative", i)
+		}
+		if trader.ExecGuards.LossStreakLimit > 0 && trader.ExecGuards.LossStreakCooldown <= 0 {
+			return fmt.Errorf("manager config: traders[%d].exec_guards.loss_streak_cooldown is required when loss_streak_limit is set", i)
+		}
+		if trader.ExecGuards.SimulateAboveUSD < 0 || trader.ExecGuards.MaxSimulatedSlippageBps < 0 {
+			return fmt.Errorf("manager config: traders[%d].exec_guards.simulate_above_usd/max_simulated_slippage_bps cannot be negative", i)
+		}
+		if trader.ExecGuards.SimulateAboveUSD > 0 && trader.ExecGuards.MaxSimulatedSlippageBps == 0 {
+			return fmt.Errorf("manager config: traders[%d].exec_guards.max_simulated_slippage_bps is required when simulate_above_usd is set", i)
+		}
 		if trader.ExecGuards.LiquidityThresholdUSD < 0 {
 			return fmt.Errorf("manager config: traders[%d].exec_guards.liquidity_threshold_usd cannot be negative", i)
 		}
 		if trader.ExecGuards.MaxMarginUsagePct < 0 || trader.ExecGuards.MaxMarginUsagePct > 100 {
 			return fmt.Errorf("manager config: traders[%d].exec_guards.max_margin_usage_pct must be 0..100", i)
 		}
+		if err := trader.Shadow.Validate(i, trader.Model); err != nil {
+			return err
+		}
+		if err := trader.SubAccount.Validate(i); err != nil {
+			return err
+		}
+		if err := trader.RegimeSchedule.Validate(i); err != nil {
+			return err
+		}
+		if err := trader.Triggers.Validate(i); err != nil {
+			return err
+		}
+		if err := trader.OutputAnomaly.Validate(i); err != nil {
+			return err
+		}
+		if err := trader.PromptDrift.Validate(i); err != nil {
+			return err
+		}
+		for j, adv := range trader.Advisors {
+			if err := adv.Validate(); err != nil {
+				return fmt.Errorf("manager config: traders[%d].advisors[%d]: %w", i, j, err)
+			}
+		}
 	}
 	if err := c.validateAllocationBudget(totalAllocation); err != nil {
 		return err
@@ -327,9 +680,36 @@ func (c *Config) Validate() error {
 	if c.Monitoring.MetricsExporter == "" {
 		return errors.New("manager config: monitoring.metrics_exporter is required")
 	}
+	if c.Monitoring.AlertTemplatesDir != "" {
+		if _, err := LoadAlertTemplates(c.Monitoring.AlertTemplatesDir); err != nil {
+			return fmt.Errorf("manager config: monitoring.alert_templates_dir: %w", err)
+		}
+	}
+	if tg := c.Monitoring.Telegram; tg.Enabled {
+		if tg.BotToken == "" {
+			return errors.New("manager config: monitoring.telegram.bot_token is required when enabled")
+		}
+		if len(tg.Operators)+len(tg.Viewers) == 0 {
+			return errors.New("manager config: monitoring.telegram needs at least one operator or viewer chat")
+		}
+		if tg.DailySummaryAt != "" {
+			if _, err := time.Parse("15:04", tg.DailySummaryAt); err != nil {
+				return fmt.Errorf("manager config: monitoring.telegram.daily_summary_at must be HH:MM: %w", err)
+			}
+		}
+	}
+	if _, err := c.PromptRegistry(); err != nil {
+		return fmt.Errorf("manager config: prompt_versions: %w", err)
+	}
 	return nil
 }
 
+// PromptRegistry builds the prompt version registry from PromptVersions,
+// reading templates from TemplateFS when set.
+func (c *Config) PromptRegistry() (*prompt.Registry, error) {
+	return prompt.NewRegistry(c.PromptVersions, c.TemplateFS)
+}
+
 func (c *Config) validateAllocationBudget(totalAllocation float64) error {
 	if totalAllocation > 100+1e-6 {
 		return fmt.Errorf("manager config: trader allocation sum %.2f exceeds 100", totalAllocation)
@@ -353,6 +733,40 @@ func (t TraderConfig) validateOrderStyle(index int) error {
 	return nil
 }
 
+// Validate checks the shadow settings of the trader at index.
+func (s ShadowConfig) Validate(index int, liveModel string) error {
+	if !s.Enabled {
+		return nil
+	}
+	if strings.TrimSpace(s.Model) == "" {
+		return fmt.Errorf("manager config: traders[%d].shadow.model is required when enabled", index)
+	}
+	if strings.EqualFold(strings.TrimSpace(s.Model), strings.TrimSpace(liveModel)) {
+		return fmt.Errorf("manager config: traders[%d].shadow.model must differ from the live model", index)
+	}
+	if s.EvaluationDays < 0 {
+		return fmt.Errorf("manager config: traders[%d].shadow.evaluation_days cannot be negative", index)
+	}
+	if s.MaxDrawdownPct < 0 || s.MaxDrawdownPct > 100 {
+		return fmt.Errorf("manager config: traders[%d].shadow.max_drawdown_pct must be between 0 and 100", index)
+	}
+	if s.MinTrades < 0 {
+		return fmt.Errorf("manager config: traders[%d].shadow.min_trades cannot be negative", index)
+	}
+	return nil
+}
+
+// Validate checks the sub-account settings of the trader at index.
+func (s SubAccountConfig) Validate(index int) error {
+	if !s.Enabled {
+		return nil
+	}
+	if s.FundUSD < 0 {
+		return fmt.Errorf("manager config: traders[%d].sub_account.fund_usd cannot be negative", index)
+	}
+	return nil
+}
+
 // Validate ensures risk parameters are within expected ranges.
 func (r RiskParameters) Validate(index int) error {
 	if r.MaxPositions <= 0 {
@@ -376,7 +790,19 @@ func (r RiskParameters) Validate(index int) error {
 	if r.MinConfidence < 0 || r.MinConfidence > 100 {
 		return fmt.Errorf("manager config: traders[%d].risk_params.min_confidence must be between 0 and 100", index)
 	}
-	return nil
+	if r.MaxRiskPerTradePct < 0 || r.MaxRiskPerTradePct > 100 {
+		return fmt.Errorf("manager config: traders[%d].risk_params.max_risk_per_trade_pct must be between 0 and 100", index)
+	}
+	if err := r.DrawdownScaling.Validate(index); err != nil {
+		return err
+	}
+	if err := r.VolatilityLeverage.Validate(); err != nil {
+		return fmt.Errorf("manager config: traders[%d].risk_params.volatility_leverage.%w", index, err)
+	}
+	if err := r.guardConfig().Validate(); err != nil {
+		return fmt.Errorf("manager config: traders[%d].risk_params.%w", index, err)
+	}
+	return r.Sizing.Validate(index)
 }
 
 func parsePositiveDuration(field, value string) (time.Duration, error) {
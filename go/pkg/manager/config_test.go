@@ -1,11 +1,16 @@
 package manager
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
+
+	"nof0-api/pkg/llm"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -97,6 +102,9 @@ monitoring:
 	assert.Equal(t, "hl_market", cfg.Traders[0].MarketProvider, "MarketProvider should be trimmed")
 	assert.Equal(t, OrderStyleLimitIOC, cfg.Traders[0].OrderStyle, "OrderStyle should default to limit_ioc")
 	assert.Equal(t, defaultMarketIOCSlippageBps, cfg.Traders[0].MarketIOCSlippageBps, "MarketIOCSlippageBps should default")
+	assert.Equal(t, PartialDataDrop, cfg.Traders[0].PartialDataPolicy, "PartialDataPolicy should default to drop")
+	assert.Equal(t, defaultSnapshotConcurrency, cfg.Manager.SnapshotConcurrency, "SnapshotConcurrency should default")
+	assert.Equal(t, "4s", cfg.Manager.SnapshotTimeout.String(), "SnapshotTimeout should default")
 
 	wantStatePath := filepath.Join(dir, "state/manager.json")
 	assert.Equal(t, wantStatePath, cfg.Manager.StateStoragePath, "StateStoragePath should match expected path")
@@ -273,3 +281,158 @@ monitoring:
 	assert.Error(t, err, "LoadConfig should error for missing market provider")
 	assert.Contains(t, err.Error(), "market_provider", "error should mention market_provider")
 }
+
+func TestPartialDataPolicyValidation(t *testing.T) {
+	dir := t.TempDir()
+	promptPath := filepath.Join(dir, "prompt.tmpl")
+	err := os.WriteFile(promptPath, []byte("generic prompt"), 0o600)
+	assert.NoError(t, err, "write prompt should succeed")
+
+	build := func(policy string) string {
+		return `
+manager:
+  total_equity_usd: 1000
+  reserve_equity_pct: 0
+  allocation_strategy: equal
+  rebalance_interval: 1h
+  state_storage_backend: file
+  state_storage_path: state.json
+
+traders:
+  - id: t1
+    name: Trader1
+    exchange_provider: ex
+    market_provider: market_a
+    partial_data_policy: ` + policy + `
+    prompt_template: prompt.tmpl
+    executor_prompt_template: prompt.tmpl
+    decision_interval: 3m
+    allocation_pct: 50
+    risk_params:
+      max_positions: 1
+      max_position_size_usd: 100
+      max_margin_usage_pct: 50
+      major_coin_leverage: 10
+      altcoin_leverage: 5
+      min_risk_reward_ratio: 2
+      min_confidence: 70
+
+monitoring:
+  update_interval: 10s
+  metrics_exporter: prometheus
+`
+	}
+
+	path := filepath.Join(dir, "manager.yaml")
+	err = os.WriteFile(path, []byte(build(" Annotate ")), 0o600)
+	assert.NoError(t, err, "write config should succeed")
+	cfg, err := LoadConfig(path)
+	assert.NoError(t, err, "LoadConfig should accept annotate policy")
+	assert.Equal(t, PartialDataAnnotate, cfg.Traders[0].PartialDataPolicy, "policy should be normalised")
+
+	err = os.WriteFile(path, []byte(build("retry")), 0o600)
+	assert.NoError(t, err, "write config should succeed")
+	_, err = LoadConfig(path)
+	assert.Error(t, err, "LoadConfig should reject unknown policy")
+	assert.Contains(t, err.Error(), "partial_data_policy", "error should mention partial_data_policy")
+}
+
+func TestLoadConfigWithTemplateFS(t *testing.T) {
+	configYAML := `
+manager:
+  total_equity_usd: 1000
+  reserve_equity_pct: 0
+  allocation_strategy: equal
+  rebalance_interval: 1h
+  state_storage_backend: file
+  state_storage_path: state.json
+
+traders:
+  - id: t1
+    name: Trader1
+    exchange_provider: ex
+    market_provider: market_a
+    prompt_template: prompts/manager/t1.tmpl
+    executor_prompt_template: prompts/executor/default_prompt.tmpl
+    decision_interval: 3m
+    allocation_pct: 50
+    auto_start: true
+    risk_params:
+      max_positions: 1
+      max_position_size_usd: 100
+      max_margin_usage_pct: 50
+      major_coin_leverage: 10
+      altcoin_leverage: 5
+      min_risk_reward_ratio: 2
+      min_confidence: 70
+      stop_loss_enabled: true
+      take_profit_enabled: true
+
+monitoring:
+  update_interval: 10s
+  metrics_exporter: prometheus
+`
+	templates := fstest.MapFS{
+		"prompts/manager/t1.tmpl":              {Data: []byte("manager prompt")},
+		"prompts/executor/default_prompt.tmpl": {Data: []byte("executor prompt")},
+	}
+	// The base directory holds no templates; they resolve only inside the FS.
+	cfg, err := loadConfig(strings.NewReader(configYAML), t.TempDir(), templates)
+	assert.NoError(t, err, "templates present in the FS should validate")
+	assert.Equal(t, "prompts/manager/t1.tmpl", cfg.Traders[0].PromptTemplate, "template paths stay FS-relative")
+	assert.Equal(t, "prompts/executor/default_prompt.tmpl", cfg.Traders[0].ExecutorTemplate)
+
+	delete(templates, "prompts/manager/t1.tmpl")
+	_, err = loadConfig(strings.NewReader(configYAML), t.TempDir(), templates)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "prompt_template")
+}
+
+func TestLoadConfigPinnedTemplateDigest(t *testing.T) {
+	configYAML := `
+manager:
+  total_equity_usd: 1000
+  allocation_strategy: equal
+  state_storage_backend: file
+  state_storage_path: state.json
+
+traders:
+  - id: t1
+    name: Trader1
+    exchange_provider: ex
+    market_provider: market_a
+    prompt_template: prompts/manager/t1.tmpl
+    prompt_template_digest: %s
+    executor_prompt_template: prompts/executor/default_prompt.tmpl
+    decision_interval: 3m
+    allocation_pct: 50
+    risk_params:
+      max_positions: 1
+      max_position_size_usd: 100
+      max_margin_usage_pct: 50
+      major_coin_leverage: 10
+      altcoin_leverage: 5
+      min_risk_reward_ratio: 2
+      min_confidence: 70
+
+monitoring:
+  update_interval: 10s
+  metrics_exporter: prometheus
+`
+	templates := fstest.MapFS{
+		"prompts/manager/t1.tmpl":              {Data: []byte("manager prompt")},
+		"prompts/executor/default_prompt.tmpl": {Data: []byte("executor prompt")},
+	}
+	digest, err := llm.TemplateDigest(templates, "prompts/manager/t1.tmpl")
+	assert.NoError(t, err)
+
+	_, err = loadConfig(strings.NewReader(fmt.Sprintf(configYAML, "sha256:"+digest)), t.TempDir(), templates)
+	assert.NoError(t, err, "matching pin should validate")
+
+	templates["prompts/manager/t1.tmpl"] = &fstest.MapFile{Data: []byte("edited manager prompt")}
+	edited, err := llm.TemplateDigest(templates, "prompts/manager/t1.tmpl")
+	assert.NoError(t, err)
+	_, err = loadConfig(strings.NewReader(fmt.Sprintf(configYAML, digest)), t.TempDir(), templates)
+	assert.ErrorContains(t, err, "traders[0].prompt_template_digest")
+	assert.ErrorContains(t, err, fmt.Sprintf("has digest %s, pinned %s", edited, digest), "mismatch names both digests")
+}
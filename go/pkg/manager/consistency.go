@@ -0,0 +1,39 @@
+package manager
+
+import (
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+)
+
+// markToSnapshots re-marks positions at the snapshot prices the prompt shows
+// as current and shifts the account's PnL and equity by the difference, so a
+// position's mark, its PnL and the quoted price all come from one read.
+// Positions without a snapshot price keep the exchange's values.
+func markToSnapshots(account *executorpkg.AccountInfo, positions []executorpkg.PositionInfo, snaps map[string]*market.Snapshot) {
+	var delta float64
+	for i := range positions {
+		p := &positions[i]
+		s, ok := snaps[p.Symbol]
+		if !ok || s == nil || !(s.Price.Last > 0) || !(p.EntryPrice > 0) {
+			continue
+		}
+		p.MarkPrice = s.Price.Last
+		p.UnrealizedPnLPct = 100 * (p.MarkPrice - p.EntryPrice) / p.EntryPrice
+		pnl := p.Quantity * (p.MarkPrice - p.EntryPrice)
+		if p.Side == "short" {
+			pnl = -pnl
+		}
+		delta += pnl - p.UnrealizedPnL
+		p.UnrealizedPnL = pnl
+	}
+	if delta == 0 || account == nil {
+		return
+	}
+	account.TotalPnL += delta
+	account.TotalEquity += delta
+	account.AvailableBalance = account.TotalEquity - account.MarginUsed
+	if account.TotalEquity != 0 {
+		account.MarginUsedPct = 100 * (account.MarginUsed / account.TotalEquity)
+		account.TotalPnLPct = 100 * (account.TotalPnL / account.TotalEquity)
+	}
+}
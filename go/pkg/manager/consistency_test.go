@@ -0,0 +1,38 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+)
+
+func TestMarkToSnapshots(t *testing.T) {
+	// The exchange marked BTC at 101 and ETH at 2000; the snapshots read a
+	// moment later say 102 and 1990.
+	account := executorpkg.AccountInfo{TotalEquity: 1000, MarginUsed: 100, TotalPnL: 2}
+	positions := []executorpkg.PositionInfo{
+		{Symbol: "BTC", Side: "long", EntryPrice: 100, Quantity: 1, UnrealizedPnL: 1},
+		{Symbol: "ETH", Side: "short", EntryPrice: 2000, Quantity: 0.1, UnrealizedPnL: 0},
+		{Symbol: "SOL", Side: "long", EntryPrice: 10, Quantity: 5, UnrealizedPnL: 1},
+	}
+	snaps := map[string]*market.Snapshot{
+		"BTC": {Price: market.PriceInfo{Last: 102}},
+		"ETH": {Price: market.PriceInfo{Last: 1990}},
+	}
+
+	markToSnapshots(&account, positions, snaps)
+
+	assert.Equal(t, 102.0, positions[0].MarkPrice)
+	assert.InDelta(t, 2, positions[0].UnrealizedPnL, 1e-9)
+	assert.InDelta(t, 1, positions[1].UnrealizedPnL, 1e-9)
+	assert.Equal(t, 0.0, positions[2].MarkPrice, "no snapshot keeps exchange values")
+	assert.Equal(t, 1.0, positions[2].UnrealizedPnL)
+
+	assert.InDelta(t, 4, account.TotalPnL, 1e-9)
+	assert.InDelta(t, 1002, account.TotalEquity, 1e-9)
+	assert.InDelta(t, 902, account.AvailableBalance, 1e-9)
+	assert.InDelta(t, 100*100/1002.0, account.MarginUsedPct, 1e-9)
+}
@@ -0,0 +1,156 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	executorpkg "nof0-api/pkg/executor"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// TraderStatus is an operator-facing summary of one registered trader.
+type TraderStatus struct {
+	ID               string
+	Name             string
+	State            TraderState
+	PauseUntil       time.Time
+	EquityUSD        float64
+	MarginUsedUSD    float64
+	UnrealizedPnLUSD float64
+	TotalPnLUSD      float64
+	TotalTrades      int
+	Positions        int
+	LastDecisionAt   time.Time
+	Stalled          bool
+}
+
+// TraderStatuses summarises every registered trader, ordered by ID.
+func (m *Manager) TraderStatuses() []TraderStatus {
+	m.mu.RLock()
+	traders := make([]*VirtualTrader, 0, len(m.traders))
+	for _, t := range m.traders {
+		traders = append(traders, t)
+	}
+	m.mu.RUnlock()
+	sort.Slice(traders, func(i, j int) bool { return traders[i].ID < traders[j].ID })
+
+	m.stallMu.Lock()
+	stalled := make(map[string]bool, len(m.stalled))
+	for id, v := range m.stalled {
+		stalled[id] = v
+	}
+	m.stallMu.Unlock()
+
+	out := make([]TraderStatus, 0, len(traders))
+	for _, t := range traders {
+		t.mu.RLock()
+		st := TraderStatus{
+			ID:               t.ID,
+			Name:             t.Name,
+			State:            t.State,
+			PauseUntil:       t.PauseUntil,
+			EquityUSD:        t.ResourceAlloc.CurrentEquityUSD,
+			MarginUsedUSD:    t.ResourceAlloc.MarginUsedUSD,
+			UnrealizedPnLUSD: t.ResourceAlloc.UnrealizedPnLUSD,
+			Positions:        len(t.VirtualPositions),
+			LastDecisionAt:   t.LastDecisionAt,
+			Stalled:          stalled[t.ID],
+		}
+		if t.Performance != nil {
+			st.TotalPnLUSD = t.Performance.TotalPnLUSD
+			st.TotalTrades = t.Performance.TotalTrades
+		}
+		t.mu.RUnlock()
+		out = append(out, st)
+	}
+	return out
+}
+
+// TraderPositions returns the virtual positions held by traderID, ordered by symbol.
+func (m *Manager) TraderPositions(traderID string) ([]VirtualPosition, error) {
+	t, err := m.lookupTrader(traderID)
+	if err != nil {
+		return nil, err
+	}
+	snap := m.snapshotVirtualPositions(t)
+	out := make([]VirtualPosition, 0, len(snap))
+	for _, p := range snap {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Symbol < out[j].Symbol })
+	return out, nil
+}
+
+// PauseTrader stops scheduling new cycles for traderID until ResumeTrader.
+// Open positions are left untouched.
+func (m *Manager) PauseTrader(ctx context.Context, traderID string) error {
+	t, err := m.lookupTrader(traderID)
+	if err != nil {
+		return err
+	}
+	if err := t.Pause(); err != nil {
+		return err
+	}
+	m.persistRuntimeState(ctx, t)
+	return nil
+}
+
+// ResumeTrader puts a paused trader back into the scheduling loop.
+func (m *Manager) ResumeTrader(ctx context.Context, traderID string) error {
+	t, err := m.lookupTrader(traderID)
+	if err != nil {
+		return err
+	}
+	if err := t.Resume(); err != nil {
+		return err
+	}
+	m.persistRuntimeState(ctx, t)
+	return nil
+}
+
+// FlattenTrader pauses traderID and closes every position it holds, so the
+// next cycle cannot immediately reopen them. It returns the closed symbols;
+// on error the positions closed so far are still reported.
+func (m *Manager) FlattenTrader(ctx context.Context, traderID string) ([]string, error) {
+	if err := m.PauseTrader(ctx, traderID); err != nil {
+		return nil, err
+	}
+	positions, err := m.TraderPositions(traderID)
+	if err != nil {
+		return nil, err
+	}
+	t, err := m.lookupTrader(traderID)
+	if err != nil {
+		return nil, err
+	}
+	closed := make([]string, 0, len(positions))
+	for _, p := range positions {
+		if err := ctx.Err(); err != nil {
+			return closed, err
+		}
+		action := "close_long"
+		if p.Side == "short" {
+			action = "close_short"
+		}
+		d := &executorpkg.Decision{Symbol: p.Symbol, Action: action, Reasoning: "operator flatten"}
+		if err := m.ExecuteDecision(t, d); err != nil {
+			return closed, fmt.Errorf("manager: flatten %s %s: %w", traderID, p.Symbol, err)
+		}
+		closed = append(closed, p.Symbol)
+	}
+	logx.WithContext(ctx).Infof("manager: flattened trader %s closed=%v", traderID, closed)
+	return closed, nil
+}
+
+func (m *Manager) lookupTrader(traderID string) (*VirtualTrader, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t, ok := m.traders[traderID]
+	if !ok {
+		return nil, fmt.Errorf("manager: trader %s not found", traderID)
+	}
+	return t, nil
+}
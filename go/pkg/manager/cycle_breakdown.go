@@ -0,0 +1,275 @@
+package manager
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+)
+
+// Cycle outcomes reported by CycleBreakdown.Outcome.
+const (
+	CycleOutcomeTraded  = "traded"
+	CycleOutcomeNoTrade = "no_trade"
+	CycleOutcomeSkipped = "skipped"
+	CycleOutcomeError   = "error"
+)
+
+// Risk verdicts reported by CycleRiskVerdict.Verdict.
+const (
+	RiskVerdictPass         = "pass"
+	RiskVerdictReject       = "reject"
+	RiskVerdictNotEvaluated = "not_evaluated"
+)
+
+// CycleBreakdown is a machine-readable account of one decision cycle, from
+// market data fetch to order execution. The latest breakdown per trader is
+// published so operators can answer "why no trade?" without reading logs.
+type CycleBreakdown struct {
+	TraderID   string           `json:"trader_id"`
+	TraceID    string           `json:"trace_id"`
+	StartedAt  time.Time        `json:"started_at"`
+	DurationMs int64            `json:"duration_ms"`
+	Outcome    string           `json:"outcome"`
+	Reason     string           `json:"reason,omitempty"`
+	Data       CycleDataStats   `json:"data"`
+	Prompt     CyclePromptStats `json:"prompt"`
+	LLM        CycleLLMStats    `json:"llm"`
+	Decisions  []CycleDecision  `json:"decisions,omitempty"`
+	Risk       CycleRiskVerdict `json:"risk"`
+	Execution  []CycleExecution `json:"execution,omitempty"`
+
+	// skipKind is the cycle event kind of a skipped cycle and llmErr the
+	// model call failure, both feeding the skip ledger.
+	skipKind string
+	llmErr   error
+}
+
+// CycleDataStats describes the market data the decision was based on.
+type CycleDataStats struct {
+	FetchMs int64 `json:"fetch_ms"`
+	// AgeMs is how old the fetched data was when the model's answer arrived.
+	AgeMs              int64    `json:"age_ms"`
+	Symbols            int      `json:"symbols"`
+	UnavailableSymbols []string `json:"unavailable_symbols,omitempty"`
+	// Quality scores each fetched symbol's data; see market.AssessQuality.
+	Quality map[string]market.DataQuality `json:"quality,omitempty"`
+}
+
+// CyclePromptStats describes the rendered prompt.
+type CyclePromptStats struct {
+	Digest           string `json:"digest,omitempty"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+}
+
+// CycleLLMStats describes the model call.
+type CycleLLMStats struct {
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// CycleDecision summarises one decision returned by the executor.
+type CycleDecision struct {
+	Symbol          string  `json:"symbol"`
+	Action          string  `json:"action"`
+	Confidence      int     `json:"confidence"`
+	PositionSizeUSD float64 `json:"position_size_usd"`
+}
+
+// CycleRiskVerdict records whether decisions cleared validation and caps.
+type CycleRiskVerdict struct {
+	Verdict string `json:"verdict"`
+	Reason  string `json:"reason,omitempty"`
+	// CappedOpens counts open decisions dropped by position slot limits.
+	CappedOpens int `json:"capped_opens,omitempty"`
+}
+
+// CycleExecution records the exchange outcome for one executed decision.
+type CycleExecution struct {
+	Symbol string `json:"symbol"`
+	Action string `json:"action"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// CycleBreakdownRecorder is implemented by persistence backends that publish
+// the latest cycle breakdown per trader. It is optional so existing
+// PersistenceService implementations keep compiling.
+type CycleBreakdownRecorder interface {
+	RecordCycleBreakdown(ctx context.Context, breakdown CycleBreakdown) error
+}
+
+func newCycleBreakdown(traderID string, startedAt time.Time) *CycleBreakdown {
+	return &CycleBreakdown{
+		TraderID:  traderID,
+		TraceID:   newTraceID(traderID, startedAt),
+		StartedAt: startedAt,
+		Risk:      CycleRiskVerdict{Verdict: RiskVerdictNotEvaluated},
+	}
+}
+
+// skip marks the cycle as skipped before the model was consulted; kind is
+// the CycleEvent kind recorded in the skip ledger.
+func (b *CycleBreakdown) skip(kind, reason string) {
+	b.Outcome = CycleOutcomeSkipped
+	b.Reason = reason
+	b.skipKind = kind
+}
+
+// recordData captures market data freshness once the executor context is built.
+func (b *CycleBreakdown) recordData(ectx *executorpkg.Context, fetch time.Duration) {
+	b.Data.FetchMs = fetch.Milliseconds()
+	if ectx == nil {
+		return
+	}
+	b.Data.Symbols = len(ectx.MarketDataMap)
+	if len(ectx.UnavailableSymbols) > 0 {
+		b.Data.UnavailableSymbols = append([]string(nil), ectx.UnavailableSymbols...)
+		sort.Strings(b.Data.UnavailableSymbols)
+	}
+	b.Data.Quality = ectx.DataQuality
+}
+
+// recordDecision captures prompt, model and validation results. A decision
+// error with no decisions is a model failure; with decisions it is a
+// validation rejection.
+func (b *CycleBreakdown) recordDecision(out *executorpkg.FullDecision, decisionErr error, dataReadyAt time.Time) {
+	if !dataReadyAt.IsZero() {
+		b.Data.AgeMs = time.Since(dataReadyAt).Milliseconds()
+	}
+	if out == nil {
+		if decisionErr != nil {
+			b.LLM.Error = decisionErr.Error()
+			b.llmErr = decisionErr
+		}
+		return
+	}
+	b.Prompt.Digest = outPromptDigest(out)
+	b.Prompt.PromptTokens = out.PromptTokens
+	b.Prompt.CompletionTokens = out.CompletionTokens
+	b.LLM.LatencyMs = out.LLMLatency.Milliseconds()
+	for _, d := range out.Decisions {
+		b.Decisions = append(b.Decisions, CycleDecision{
+			Symbol:          d.Symbol,
+			Action:          d.Action,
+			Confidence:      d.Confidence,
+			PositionSizeUSD: d.PositionSizeUSD,
+		})
+	}
+	switch {
+	case decisionErr == nil:
+		b.Risk.Verdict = RiskVerdictPass
+	case len(out.Decisions) == 0:
+		b.LLM.Error = decisionErr.Error()
+		b.llmErr = decisionErr
+	default:
+		b.Risk.Verdict = RiskVerdictReject
+		b.Risk.Reason = decisionErr.Error()
+	}
+}
+
+// recordExecution appends the exchange outcome for one decision.
+func (b *CycleBreakdown) recordExecution(d executorpkg.Decision, execErr error) {
+	exec := CycleExecution{Symbol: d.Symbol, Action: d.Action, Result: "ok"}
+	if execErr != nil {
+		exec.Result = "error"
+		exec.Error = execErr.Error()
+	}
+	b.Execution = append(b.Execution, exec)
+}
+
+// finish stamps the duration and derives the outcome when not already set.
+func (b *CycleBreakdown) finish(now time.Time) {
+	b.DurationMs = now.Sub(b.StartedAt).Milliseconds()
+	if b.Outcome != "" {
+		return
+	}
+	if b.LLM.Error != "" {
+		b.Outcome = CycleOutcomeError
+		b.Reason = "llm: " + b.LLM.Error
+		return
+	}
+	traded, failed := false, ""
+	for _, exec := range b.Execution {
+		if !isTradeAction(exec.Action) {
+			continue
+		}
+		if exec.Result == "ok" {
+			traded = true
+		} else if failed == "" {
+			failed = exec.Symbol + " " + exec.Action + ": " + exec.Error
+		}
+	}
+	switch {
+	case traded:
+		b.Outcome = CycleOutcomeTraded
+	case failed != "":
+		b.Outcome = CycleOutcomeError
+		b.Reason = "execution: " + failed
+	default:
+		b.Outcome = CycleOutcomeNoTrade
+		b.Reason = b.noTradeReason()
+	}
+}
+
+func (b *CycleBreakdown) noTradeReason() string {
+	switch {
+	case b.Risk.Verdict == RiskVerdictReject:
+		return "risk: " + b.Risk.Reason
+	case b.Risk.CappedOpens > 0 && len(b.Execution) == 0:
+		return "risk: open decisions capped by position slots"
+	case len(b.Decisions) == 0:
+		return "model returned no decisions"
+	default:
+		return "model chose " + b.Decisions[0].Action
+	}
+}
+
+func isOpenAction(action string) bool {
+	return action == "open_long" || action == "open_short"
+}
+
+func isTradeAction(action string) bool {
+	switch action {
+	case "open_long", "open_short", "close_long", "close_short":
+		return true
+	default:
+		return false
+	}
+}
+
+func (m *Manager) recordCycleBreakdown(breakdown *CycleBreakdown) {
+	if m == nil || breakdown == nil {
+		return
+	}
+	breakdown.finish(time.Now())
+	if t, err := m.lookupTrader(breakdown.TraderID); err == nil {
+		t.setCycleTrace("")
+	}
+	level := PipelineLevelInfo
+	if breakdown.Outcome == CycleOutcomeError {
+		level = PipelineLevelError
+	}
+	m.emitPipeline(breakdown, PipelineStageDone, level, "cycle "+breakdown.Outcome, map[string]any{
+		"outcome":     breakdown.Outcome,
+		"reason":      breakdown.Reason,
+		"duration_ms": breakdown.DurationMs,
+	})
+	if event, ok := cycleEventFor(breakdown); ok {
+		m.recordCycleEvent(event)
+	}
+	recorder, ok := m.persistence.(CycleBreakdownRecorder)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	err := recorder.RecordCycleBreakdown(ctx, *breakdown)
+	logPersistenceError(err, "cycle breakdown persistence failed", map[string]any{
+		"trader_id": breakdown.TraderID,
+		"outcome":   breakdown.Outcome,
+	})
+}
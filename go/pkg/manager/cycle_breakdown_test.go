@@ -0,0 +1,107 @@
+package manager
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+)
+
+func TestCycleBreakdownOutcome(t *testing.T) {
+	start := time.Now().Add(-2 * time.Second)
+	openLong := executorpkg.Decision{Symbol: "BTC", Action: "open_long", Confidence: 80, PositionSizeUSD: 100}
+
+	tests := []struct {
+		name       string
+		build      func(b *CycleBreakdown)
+		wantResult string
+		wantReason string
+	}{
+		{
+			name: "traded",
+			build: func(b *CycleBreakdown) {
+				b.recordDecision(&executorpkg.FullDecision{Decisions: []executorpkg.Decision{openLong}}, nil, start)
+				b.recordExecution(openLong, nil)
+			},
+			wantResult: CycleOutcomeTraded,
+		},
+		{
+			name: "llm failure",
+			build: func(b *CycleBreakdown) {
+				b.recordDecision(&executorpkg.FullDecision{UserPrompt: "p"}, errors.New("timeout"), start)
+			},
+			wantResult: CycleOutcomeError,
+			wantReason: "llm: timeout",
+		},
+		{
+			name: "risk rejection",
+			build: func(b *CycleBreakdown) {
+				b.recordDecision(&executorpkg.FullDecision{Decisions: []executorpkg.Decision{openLong}}, errors.New("confidence below minimum"), start)
+			},
+			wantResult: CycleOutcomeNoTrade,
+			wantReason: "risk: confidence below minimum",
+		},
+		{
+			name: "hold",
+			build: func(b *CycleBreakdown) {
+				hold := executorpkg.Decision{Symbol: "ETH", Action: "hold"}
+				b.recordDecision(&executorpkg.FullDecision{Decisions: []executorpkg.Decision{hold}}, nil, start)
+				b.recordExecution(hold, nil)
+			},
+			wantResult: CycleOutcomeNoTrade,
+			wantReason: "model chose hold",
+		},
+		{
+			name: "execution failure",
+			build: func(b *CycleBreakdown) {
+				b.recordDecision(&executorpkg.FullDecision{Decisions: []executorpkg.Decision{openLong}}, nil, start)
+				b.recordExecution(openLong, errors.New("insufficient margin"))
+			},
+			wantResult: CycleOutcomeError,
+			wantReason: "execution: BTC open_long: insufficient margin",
+		},
+		{
+			name:       "skipped",
+			build:      func(b *CycleBreakdown) { b.skip(CycleEventStaleData, "market data unavailable") },
+			wantResult: CycleOutcomeSkipped,
+			wantReason: "market data unavailable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newCycleBreakdown("trader_1", start)
+			tt.build(b)
+			b.finish(time.Now())
+			assert.Equal(t, tt.wantResult, b.Outcome)
+			assert.Equal(t, tt.wantReason, b.Reason)
+			assert.GreaterOrEqual(t, b.DurationMs, int64(2000))
+		})
+	}
+}
+
+func TestCycleBreakdownRecordsAccounting(t *testing.T) {
+	b := newCycleBreakdown("trader_1", time.Now())
+	b.recordData(&executorpkg.Context{
+		MarketDataMap:      map[string]*market.Snapshot{"BTC": {}},
+		UnavailableSymbols: []string{"SOL", "DOGE"},
+	}, 150*time.Millisecond)
+	b.recordDecision(&executorpkg.FullDecision{
+		UserPrompt:       "prompt",
+		PromptTokens:     1200,
+		CompletionTokens: 80,
+		LLMLatency:       900 * time.Millisecond,
+	}, nil, time.Now())
+
+	assert.Equal(t, int64(150), b.Data.FetchMs)
+	assert.Equal(t, 1, b.Data.Symbols)
+	assert.Equal(t, []string{"DOGE", "SOL"}, b.Data.UnavailableSymbols)
+	assert.NotEmpty(t, b.Prompt.Digest)
+	assert.Equal(t, 1200, b.Prompt.PromptTokens)
+	assert.Equal(t, 80, b.Prompt.CompletionTokens)
+	assert.Equal(t, int64(900), b.LLM.LatencyMs)
+	assert.Equal(t, RiskVerdictPass, b.Risk.Verdict)
+}
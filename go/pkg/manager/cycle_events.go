@@ -0,0 +1,80 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"nof0-api/pkg/llm"
+)
+
+// Cycle event kinds recorded in the skip ledger.
+const (
+	CycleEventStaleData   = "stale_data"
+	CycleEventRateLimited = "rate_limited"
+	CycleEventBudgetCap   = "budget_cap"
+	CycleEventBreaker     = "breaker"
+	CycleEventLLMError    = "llm_error"
+)
+
+// CycleEvent records one decision cycle that was skipped or aborted before
+// the model's decisions could be acted on, so quiet periods are explainable.
+type CycleEvent struct {
+	TraderID   string
+	TraceID    string
+	Kind       string
+	Reason     string
+	OccurredAt time.Time
+}
+
+// CycleEventRecorder is implemented by persistence backends that keep the
+// skip ledger. It is optional so existing PersistenceService implementations
+// keep compiling.
+type CycleEventRecorder interface {
+	RecordCycleEvent(ctx context.Context, event CycleEvent) error
+}
+
+// cycleEventFor returns the ledger entry for a finished breakdown, or false
+// when the cycle ran to completion.
+func cycleEventFor(b *CycleBreakdown) (CycleEvent, bool) {
+	kind := b.skipKind
+	if kind == "" && b.Outcome == CycleOutcomeError && b.LLM.Error != "" {
+		kind = llmErrorKind(b.llmErr)
+	}
+	if kind == "" {
+		return CycleEvent{}, false
+	}
+	return CycleEvent{
+		TraderID:   b.TraderID,
+		TraceID:    b.TraceID,
+		Kind:       kind,
+		Reason:     b.Reason,
+		OccurredAt: b.StartedAt,
+	}, true
+}
+
+// llmErrorKind classifies a failed model call.
+func llmErrorKind(err error) string {
+	switch {
+	case errors.Is(err, llm.ErrBudgetExceeded):
+		return CycleEventBudgetCap
+	case llm.IsRateLimited(err):
+		return CycleEventRateLimited
+	default:
+		return CycleEventLLMError
+	}
+}
+
+func (m *Manager) recordCycleEvent(event CycleEvent) {
+	recorder, ok := m.persistence.(CycleEventRecorder)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	err := recorder.RecordCycleEvent(ctx, event)
+	logPersistenceError(err, "cycle event persistence failed", map[string]any{
+		"trader_id": event.TraderID,
+		"kind":      event.Kind,
+	})
+}
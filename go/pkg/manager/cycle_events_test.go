@@ -0,0 +1,51 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/llm"
+)
+
+func TestCycleEventFor(t *testing.T) {
+	start := time.Now()
+	finished := func(build func(b *CycleBreakdown)) *CycleBreakdown {
+		b := newCycleBreakdown("trader_1", start)
+		build(b)
+		b.finish(time.Now())
+		return b
+	}
+
+	b := finished(func(b *CycleBreakdown) { b.skip(CycleEventBreaker, "sharpe gating pause") })
+	event, ok := cycleEventFor(b)
+	require.True(t, ok)
+	assert.Equal(t, CycleEvent{TraderID: "trader_1", TraceID: b.TraceID, Kind: CycleEventBreaker, Reason: "sharpe gating pause", OccurredAt: start}, event)
+
+	cases := map[string]error{
+		CycleEventBudgetCap:   fmt.Errorf("executor: %w", llm.ErrBudgetExceeded),
+		CycleEventRateLimited: fmt.Errorf("executor: %w", &openai.Error{StatusCode: http.StatusTooManyRequests}),
+		CycleEventLLMError:    errors.New("timeout"),
+	}
+	for kind, err := range cases {
+		b = finished(func(b *CycleBreakdown) { b.recordDecision(nil, err, start) })
+		event, ok = cycleEventFor(b)
+		require.True(t, ok, kind)
+		assert.Equal(t, kind, event.Kind)
+		assert.Equal(t, "llm: "+err.Error(), event.Reason)
+	}
+
+	hold := executorpkg.Decision{Symbol: "ETH", Action: "hold"}
+	b = finished(func(b *CycleBreakdown) {
+		b.recordDecision(&executorpkg.FullDecision{Decisions: []executorpkg.Decision{hold}}, nil, start)
+	})
+	_, ok = cycleEventFor(b)
+	assert.False(t, ok, "completed cycles are not ledger entries")
+}
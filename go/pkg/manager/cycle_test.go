@@ -0,0 +1,197 @@
+package manager
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/etc"
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/sim"
+	"nof0-api/pkg/llm"
+	"nof0-api/pkg/market"
+)
+
+// cycleLLM answers every prompt with one fixed decision.
+type cycleLLM struct {
+	payload string
+	prompts int
+}
+
+func (f *cycleLLM) Chat(context.Context, *llm.ChatRequest) (*llm.ChatResponse, error) {
+	return nil, nil
+}
+
+func (f *cycleLLM) ChatStream(context.Context, *llm.ChatRequest) (<-chan llm.StreamResponse, error) {
+	return nil, nil
+}
+
+func (f *cycleLLM) ChatStructured(_ context.Context, _ *llm.ChatRequest, target interface{}) (*llm.ChatResponse, error) {
+	f.prompts++
+	if err := llm.ParseStructured(f.payload, target); err != nil {
+		return nil, err
+	}
+	return &llm.ChatResponse{
+		Model:   "test-model",
+		Choices: []llm.Choice{{Message: llm.Message{Role: "assistant", Content: f.payload}}},
+		Usage:   llm.Usage{PromptTokens: 100, CompletionTokens: 50, TotalTokens: 150},
+	}, nil
+}
+
+func (f *cycleLLM) GetConfig() *llm.Config { return &llm.Config{} }
+func (f *cycleLLM) Close() error           { return nil }
+
+// cycleMarket serves fixed prices for a few active symbols.
+type cycleMarket map[string]float64
+
+func (m cycleMarket) Snapshot(_ context.Context, symbol string) (*market.Snapshot, error) {
+	return &market.Snapshot{
+		Symbol: symbol,
+		Price:  market.PriceInfo{Last: m[symbol]},
+		Change: market.ChangeInfo{OneHour: 0.01, FourHour: 0.02},
+	}, nil
+}
+
+func (m cycleMarket) ListAssets(context.Context) ([]market.Asset, error) {
+	assets := make([]market.Asset, 0, len(m))
+	for sym := range m {
+		assets = append(assets, market.Asset{Symbol: sym, IsActive: true, RawMetadata: map[string]any{"maxLeverage": 50.0}})
+	}
+	return assets, nil
+}
+
+const buyBTCDecision = `{
+  "signal":"buy_to_enter",
+  "symbol":"BTC",
+  "leverage":5,
+  "position_size_usd":200,
+  "entry_price":100,
+  "stop_loss":95,
+  "take_profit":115,
+  "risk_usd":10,
+  "confidence":90,
+  "invalidation_condition":"below EMA20",
+  "reasoning":"clear uptrend"
+}`
+
+// newCycleManager registers one trader on a simulated exchange with BTC
+// at 100, answered by model.
+func newCycleManager(t *testing.T, model *cycleLLM) (*Manager, *VirtualTrader, *sim.Provider, *recordingPipeline) {
+	t.Helper()
+	factory := NewBasicExecutorFactory(model, nil)
+	factory.SetTemplateFS(etc.Prompts)
+	ex := sim.New()
+	require.NoError(t, ex.SetMarkPrice(context.Background(), "BTC", 100))
+	// A 200 USD entry stopped at 95 loses 10 USD; the risk guard halves it.
+	cfg, err := loadConfig(strings.NewReader(`
+manager:
+  total_equity_usd: 1000
+  allocation_strategy: equal
+  state_storage_backend: file
+  state_storage_path: state.json
+
+traders:
+  - id: t1
+    name: Trader1
+    exchange_provider: sim
+    market_provider: mkt
+    prompt_template: prompts/manager/conservative_long.tmpl
+    executor_prompt_template: prompts/executor/default_prompt.tmpl
+    decision_interval: 3m
+    allocation_pct: 100
+    risk_params:
+      max_positions: 2
+      max_position_size_usd: 1000
+      max_margin_usage_pct: 80
+      major_coin_leverage: 10
+      altcoin_leverage: 5
+      min_risk_reward_ratio: 2
+      min_confidence: 70
+      max_loss_per_trade_usd: 5
+      resize_orders: true
+
+monitoring:
+  update_interval: 10s
+  metrics_exporter: prometheus
+`), t.TempDir(), etc.Prompts)
+	require.NoError(t, err)
+	rec := &recordingPipeline{}
+	m := NewManager(cfg, factory,
+		map[string]exchange.Provider{"sim": ex},
+		map[string]market.Provider{"mkt": cycleMarket{"BTC": 100, "ETH": 10}},
+		rec)
+	trader, err := m.RegisterTrader(context.Background(), cfg.Traders[0])
+	require.NoError(t, err)
+	return m, trader, ex, rec
+}
+
+// TestRunDecisionCycleEndToEnd drives one cycle through every stage: market
+// data from the market provider, the prompt rendered from the embedded
+// templates and answered by a fake model, the decision parsed and
+// validated by the executor, resized by the risk guard and filled by the
+// simulated exchange.
+func TestRunDecisionCycleEndToEnd(t *testing.T) {
+	ctx := context.Background()
+	model := &cycleLLM{payload: buyBTCDecision}
+	m, trader, ex, rec := newCycleManager(t, model)
+
+	m.runDecisionCycle(ctx, trader)
+
+	assert.Equal(t, 1, model.prompts)
+	positions, err := ex.GetPositions(ctx)
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	assert.Equal(t, "BTC", positions[0].Coin)
+	qty, err := strconv.ParseFloat(positions[0].Szi, 64)
+	require.NoError(t, err)
+	assert.InDelta(t, 1, qty, 1e-6, "100 USD at 100 after the risk guard resize")
+
+	require.NotEmpty(t, rec.events)
+	stages := map[string]bool{}
+	for _, ev := range rec.events {
+		stages[ev.Stage] = true
+	}
+	for _, stage := range []string{PipelineStageData, PipelineStageThinking, PipelineStageDeciding, PipelineStageExecuting, PipelineStageDone} {
+		assert.True(t, stages[stage], "pipeline stage %s", stage)
+	}
+	done := rec.events[len(rec.events)-1]
+	assert.Equal(t, PipelineStageDone, done.Stage)
+	assert.Equal(t, CycleOutcomeTraded, done.Fields["outcome"])
+	assert.False(t, trader.LastDecisionAt.IsZero(), "the cycle is recorded as a decision")
+}
+
+// positionCheckingEmbedder notes, per embedded prompt, whether the exchange
+// already held a position.
+type positionCheckingEmbedder struct {
+	ex     *sim.Provider
+	filled []bool
+}
+
+func (e *positionCheckingEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	positions, err := e.ex.GetPositions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e.filled = append(e.filled, len(positions) > 0)
+	return llm.HashEmbedder{}.Embed(ctx, texts)
+}
+
+func TestRunDecisionCycleChecksPromptDriftAfterExecution(t *testing.T) {
+	m, trader, ex, _ := newCycleManager(t, &cycleLLM{payload: buyBTCDecision})
+	embedder := &positionCheckingEmbedder{ex: ex}
+	cfg := PromptDriftConfig{Enabled: true, BaselineSize: 2, Window: 1, Threshold: 4}
+	detector, err := newPromptDriftDetector("t1", cfg, embedder)
+	require.NoError(t, err)
+	trader.promptDrift = detector
+
+	m.runDecisionCycle(context.Background(), trader)
+
+	assert.Equal(t, []bool{true}, embedder.filled, "the order is placed before the prompt is embedded")
+	status, ok := m.PromptDrift("t1")
+	require.True(t, ok)
+	assert.Equal(t, 1, status.Collected)
+}
@@ -0,0 +1,60 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"nof0-api/pkg/market"
+)
+
+// DataQualitySample is one symbol's data quality score for one cycle.
+type DataQualitySample struct {
+	TraderID string
+	TraceID  string
+	Symbol   string
+	Quality  market.DataQuality
+	At       time.Time
+}
+
+// DataQualityRecorder is implemented by persistence backends that keep the
+// per-cycle data quality history. It is optional so existing
+// PersistenceService implementations keep compiling.
+type DataQualityRecorder interface {
+	RecordDataQuality(ctx context.Context, samples []DataQualitySample) error
+}
+
+// assessDataQuality scores every fetched snapshot at now.
+func assessDataQuality(snaps map[string]*market.Snapshot, now time.Time) map[string]market.DataQuality {
+	if len(snaps) == 0 {
+		return nil
+	}
+	out := make(map[string]market.DataQuality, len(snaps))
+	for sym, snap := range snaps {
+		out[sym] = market.AssessQuality(snap, now)
+	}
+	return out
+}
+
+func (m *Manager) recordDataQuality(b *CycleBreakdown) {
+	recorder, ok := m.persistence.(DataQualityRecorder)
+	if !ok || len(b.Data.Quality) == 0 {
+		return
+	}
+	samples := make([]DataQualitySample, 0, len(b.Data.Quality))
+	for sym, q := range b.Data.Quality {
+		samples = append(samples, DataQualitySample{
+			TraderID: b.TraderID,
+			TraceID:  b.TraceID,
+			Symbol:   sym,
+			Quality:  q,
+			At:       b.StartedAt,
+		})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	err := recorder.RecordDataQuality(ctx, samples)
+	logPersistenceError(err, "data quality persistence failed", map[string]any{
+		"trader_id": b.TraderID,
+		"symbols":   len(samples),
+	})
+}
@@ -0,0 +1,52 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+)
+
+type qualityRecorder struct {
+	noopPersistenceService
+	samples []DataQualitySample
+}
+
+func (r *qualityRecorder) RecordDataQuality(_ context.Context, samples []DataQualitySample) error {
+	r.samples = append(r.samples, samples...)
+	return nil
+}
+
+func TestRecordDataQuality(t *testing.T) {
+	now := time.Now()
+	candles := []market.Candle{
+		{OpenTime: now.Add(-3 * time.Minute).UnixMilli(), High: 101, Low: 99, Close: 100},
+		{OpenTime: now.Add(-2 * time.Minute).UnixMilli(), High: 101, Low: 99, Close: 100},
+		{OpenTime: now.Add(-time.Minute).UnixMilli(), High: 101, Low: 99, Close: 100},
+	}
+	snaps := map[string]*market.Snapshot{
+		"BTC": {Intraday: &market.SeriesBundle{Candles: candles}},
+		"SOL": {Intraday: &market.SeriesBundle{Candles: candles[:1]}},
+	}
+	quality := assessDataQuality(snaps, now)
+	require.Len(t, quality, 2)
+	assert.Equal(t, 1.0, quality["BTC"].Score)
+
+	rec := &qualityRecorder{}
+	m := NewManager(&Config{}, nil, nil, nil, rec)
+	b := newCycleBreakdown("t1", now)
+	b.recordData(&executorpkg.Context{MarketDataMap: snaps, DataQuality: quality}, time.Millisecond)
+	m.recordDataQuality(b)
+
+	require.Len(t, rec.samples, 2)
+	for _, s := range rec.samples {
+		assert.Equal(t, "t1", s.TraderID)
+		assert.Equal(t, b.TraceID, s.TraceID)
+		assert.Equal(t, quality[s.Symbol], s.Quality)
+	}
+}
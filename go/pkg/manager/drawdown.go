@@ -0,0 +1,105 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DrawdownScaling shrinks the per-trade risk budget as equity falls from its
+// peak and restores it as equity recovers. The curve maps drawdown (%) to a
+// risk multiplier; values between points are interpolated linearly and the
+// last point holds for deeper drawdowns.
+type DrawdownScaling struct {
+	Enabled bool           `yaml:"enabled" json:"enabled"`
+	Curve   []DrawdownStep `yaml:"curve" json:"curve"`
+}
+
+// DrawdownStep is one point on the drawdown scaling curve.
+type DrawdownStep struct {
+	DrawdownPct    float64 `yaml:"drawdown_pct" json:"drawdown_pct"`
+	RiskMultiplier float64 `yaml:"risk_multiplier" json:"risk_multiplier"`
+}
+
+// defaultDrawdownCurve halves risk at a 10% drawdown and quarters it at 20%.
+var defaultDrawdownCurve = []DrawdownStep{
+	{DrawdownPct: 0, RiskMultiplier: 1},
+	{DrawdownPct: 5, RiskMultiplier: 0.75},
+	{DrawdownPct: 10, RiskMultiplier: 0.5},
+	{DrawdownPct: 20, RiskMultiplier: 0.25},
+}
+
+// Validate checks the curve is ordered by drawdown with multipliers in 0..1.
+func (d DrawdownScaling) Validate(index int) error {
+	prev := -1.0
+	for i, step := range d.Curve {
+		if step.DrawdownPct < 0 || step.DrawdownPct > 100 {
+			return fmt.Errorf("manager config: traders[%d].risk_params.drawdown_scaling.curve[%d].drawdown_pct must be between 0 and 100", index, i)
+		}
+		if step.DrawdownPct <= prev {
+			return fmt.Errorf("manager config: traders[%d].risk_params.drawdown_scaling.curve must be sorted by increasing drawdown_pct", index)
+		}
+		if step.RiskMultiplier < 0 || step.RiskMultiplier > 1 {
+			return fmt.Errorf("manager config: traders[%d].risk_params.drawdown_scaling.curve[%d].risk_multiplier must be between 0 and 1", index, i)
+		}
+		prev = step.DrawdownPct
+	}
+	return nil
+}
+
+// Multiplier returns the risk multiplier for the given drawdown from peak.
+func (d DrawdownScaling) Multiplier(drawdownPct float64) float64 {
+	if !d.Enabled {
+		return 1
+	}
+	curve := d.Curve
+	if len(curve) == 0 {
+		curve = defaultDrawdownCurve
+	}
+	if drawdownPct <= curve[0].DrawdownPct {
+		if curve[0].DrawdownPct == 0 {
+			return curve[0].RiskMultiplier
+		}
+		// Interpolate from full risk at zero drawdown to the first point.
+		curve = append([]DrawdownStep{{DrawdownPct: 0, RiskMultiplier: 1}}, curve...)
+	}
+	i := sort.Search(len(curve), func(i int) bool { return curve[i].DrawdownPct >= drawdownPct })
+	if i >= len(curve) {
+		return curve[len(curve)-1].RiskMultiplier
+	}
+	if i == 0 || curve[i].DrawdownPct == drawdownPct {
+		return curve[i].RiskMultiplier
+	}
+	lo, hi := curve[i-1], curve[i]
+	frac := (drawdownPct - lo.DrawdownPct) / (hi.DrawdownPct - lo.DrawdownPct)
+	return lo.RiskMultiplier + frac*(hi.RiskMultiplier-lo.RiskMultiplier)
+}
+
+// trackEquityPeak records equity from an account sync, updating the peak and
+// drawdown metrics used for risk scaling. Callers hold t.mu.
+func (t *VirtualTrader) trackEquityPeak(equity float64) {
+	if equity <= 0 {
+		return
+	}
+	if equity > t.PeakEquityUSD {
+		t.PeakEquityUSD = equity
+	}
+	if t.Performance == nil {
+		t.Performance = &PerformanceMetrics{}
+	}
+	dd := 100 * (t.PeakEquityUSD - equity) / t.PeakEquityUSD
+	t.Performance.CurrentDrawdownPct = dd
+	if dd > t.Performance.MaxDrawdownPct {
+		t.Performance.MaxDrawdownPct = dd
+	}
+}
+
+// riskScale returns the current drawdown from peak and the multiplier applied
+// to the trader's per-trade risk and size caps.
+func (t *VirtualTrader) riskScale() (drawdownPct, scale float64) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.Performance != nil {
+		drawdownPct = t.Performance.CurrentDrawdownPct
+	}
+	return drawdownPct, t.RiskParams.DrawdownScaling.Multiplier(drawdownPct)
+}
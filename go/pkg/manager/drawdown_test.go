@@ -0,0 +1,55 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrawdownScalingMultiplier(t *testing.T) {
+	assert.Equal(t, 1.0, DrawdownScaling{}.Multiplier(50), "disabled scaling keeps full risk")
+
+	d := DrawdownScaling{Enabled: true}
+	assert.InDelta(t, 1, d.Multiplier(0), 1e-9)
+	assert.InDelta(t, 0.875, d.Multiplier(2.5), 1e-9)
+	assert.InDelta(t, 0.625, d.Multiplier(7.5), 1e-9)
+	assert.InDelta(t, 0.5, d.Multiplier(10), 1e-9)
+	assert.InDelta(t, 0.25, d.Multiplier(30), 1e-9)
+
+	d.Curve = []DrawdownStep{{DrawdownPct: 10, RiskMultiplier: 0.5}, {DrawdownPct: 20, RiskMultiplier: 0}}
+	assert.InDelta(t, 0.75, d.Multiplier(5), 1e-9)
+	assert.InDelta(t, 0.25, d.Multiplier(15), 1e-9)
+	assert.InDelta(t, 0, d.Multiplier(25), 1e-9)
+}
+
+func TestDrawdownScalingValidate(t *testing.T) {
+	require.NoError(t, DrawdownScaling{Enabled: true, Curve: defaultDrawdownCurve}.Validate(0))
+
+	err := DrawdownScaling{Curve: []DrawdownStep{{DrawdownPct: 10, RiskMultiplier: 0.5}, {DrawdownPct: 5, RiskMultiplier: 0.25}}}.Validate(1)
+	assert.ErrorContains(t, err, "traders[1].risk_params.drawdown_scaling.curve must be sorted")
+
+	err = DrawdownScaling{Curve: []DrawdownStep{{DrawdownPct: 5, RiskMultiplier: 1.5}}}.Validate(0)
+	assert.ErrorContains(t, err, "curve[0].risk_multiplier must be between 0 and 1")
+}
+
+func TestVirtualTraderRiskScaleRecovers(t *testing.T) {
+	trader := &VirtualTrader{RiskParams: RiskParameters{DrawdownScaling: DrawdownScaling{Enabled: true}}}
+	trader.trackEquityPeak(1000)
+	trader.trackEquityPeak(900)
+
+	dd, scale := trader.riskScale()
+	assert.InDelta(t, 10, dd, 1e-9)
+	assert.InDelta(t, 0.5, scale, 1e-9)
+
+	trader.trackEquityPeak(975)
+	dd, scale = trader.riskScale()
+	assert.InDelta(t, 2.5, dd, 1e-9)
+	assert.InDelta(t, 0.875, scale, 1e-9)
+	assert.InDelta(t, 10, trader.Performance.MaxDrawdownPct, 1e-9)
+
+	trader.trackEquityPeak(1100)
+	_, scale = trader.riskScale()
+	assert.Equal(t, 1.0, scale)
+	assert.Equal(t, 1100.0, trader.PeakEquityUSD)
+}
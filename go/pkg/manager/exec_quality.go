@@ -0,0 +1,232 @@
+package manager
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+// Execution-quality report groupings.
+const (
+	ExecGroupByModel  = "model"
+	ExecGroupBySymbol = "symbol"
+	ExecGroupByTrader = "trader"
+)
+
+const (
+	// execQualityCapacity bounds the in-memory fill history.
+	execQualityCapacity = 2000
+	// promptSlippageFills is how many recent fills per symbol feed the prompt.
+	promptSlippageFills = 20
+)
+
+// ExecutionSample compares one order's fill with the price observed when the
+// order was prepared.
+type ExecutionSample struct {
+	TraderID     string
+	Model        string
+	Symbol       string
+	Action       string
+	ArrivalPrice float64
+	FillPrice    float64
+	FillQty      float64
+	// SlippageBps is adverse slippage: positive when the fill was worse than
+	// the arrival price for the order's direction.
+	SlippageBps float64
+	At          time.Time
+}
+
+// Notional is the filled value in USD.
+func (s ExecutionSample) Notional() float64 {
+	return s.FillPrice * s.FillQty
+}
+
+// ExecutionQualityRow aggregates samples for one model, symbol or trader.
+type ExecutionQualityRow struct {
+	Key         string
+	Fills       int
+	NotionalUSD float64
+	MeanBps     float64
+	// WeightedBps weights each fill's slippage by its notional.
+	WeightedBps float64
+	MedianBps   float64
+	P90Bps      float64
+	WorstBps    float64
+	// CostUSD is the total paid (or saved, when negative) versus arrival.
+	CostUSD float64
+}
+
+// adverseSlippageBps measures fill against arrival; buys (open_long,
+// close_short) lose when filling higher, sells when filling lower.
+func adverseSlippageBps(action string, arrival, fill float64) float64 {
+	if !(arrival > 0) || !(fill > 0) {
+		return 0
+	}
+	bps := (fill - arrival) / arrival * 10000
+	if action == "open_short" || action == "close_long" {
+		bps = -bps
+	}
+	return bps
+}
+
+// execQualityLog keeps the most recent fills in a ring buffer. Like the
+// shadow book it is in memory: a restart starts a fresh sample.
+type execQualityLog struct {
+	mu      sync.RWMutex
+	samples []ExecutionSample
+	next    int
+}
+
+func (l *execQualityLog) add(s ExecutionSample) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.samples) < execQualityCapacity {
+		l.samples = append(l.samples, s)
+		return
+	}
+	l.samples[l.next] = s
+	l.next = (l.next + 1) % execQualityCapacity
+}
+
+// snapshot returns samples oldest first.
+func (l *execQualityLog) snapshot() []ExecutionSample {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make([]ExecutionSample, 0, len(l.samples))
+	out = append(out, l.samples[l.next:]...)
+	out = append(out, l.samples[:l.next]...)
+	return out
+}
+
+// recordExecution logs a fill against its arrival price. Orders whose fill
+// price could not be parsed from the exchange response are skipped, since
+// their "fill" is the arrival price itself.
+func (m *Manager) recordExecution(trader *VirtualTrader, decision *executorpkg.Decision, arrival, fill, qty float64, at time.Time) {
+	if m == nil || trader == nil || decision == nil || !(arrival > 0) || !(fill > 0) {
+		return
+	}
+	trader.mu.RLock()
+	model := trader.Model
+	trader.mu.RUnlock()
+	m.execQuality.add(ExecutionSample{
+		TraderID:     trader.ID,
+		Model:        model,
+		Symbol:       strings.ToUpper(decision.Symbol),
+		Action:       decision.Action,
+		ArrivalPrice: arrival,
+		FillPrice:    fill,
+		FillQty:      qty,
+		SlippageBps:  adverseSlippageBps(decision.Action, arrival, fill),
+		At:           at,
+	})
+}
+
+// ExecutionQuality reports slippage versus arrival price since the given
+// time, grouped by model, symbol or trader and ordered by key.
+func (m *Manager) ExecutionQuality(groupBy string, since time.Time) ([]ExecutionQualityRow, error) {
+	key, err := execGroupKey(groupBy)
+	if err != nil {
+		return nil, err
+	}
+	groups := make(map[string][]ExecutionSample)
+	for _, s := range m.execQuality.snapshot() {
+		if s.At.Before(since) {
+			continue
+		}
+		k := key(s)
+		groups[k] = append(groups[k], s)
+	}
+	rows := make([]ExecutionQualityRow, 0, len(groups))
+	for k, samples := range groups {
+		rows = append(rows, summarizeExecutions(k, samples))
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Key < rows[j].Key })
+	return rows, nil
+}
+
+func execGroupKey(groupBy string) (func(ExecutionSample) string, error) {
+	switch strings.ToLower(strings.TrimSpace(groupBy)) {
+	case ExecGroupByModel, "":
+		return func(s ExecutionSample) string {
+			if s.Model == "" {
+				return "(unknown)"
+			}
+			return s.Model
+		}, nil
+	case ExecGroupBySymbol:
+		return func(s ExecutionSample) string { return s.Symbol }, nil
+	case ExecGroupByTrader:
+		return func(s ExecutionSample) string { return s.TraderID }, nil
+	default:
+		return nil, fmt.Errorf("manager: unknown execution quality grouping %q (want model, symbol or trader)", groupBy)
+	}
+}
+
+func summarizeExecutions(key string, samples []ExecutionSample) ExecutionQualityRow {
+	row := ExecutionQualityRow{Key: key, Fills: len(samples), WorstBps: math.Inf(-1)}
+	bps := make([]float64, 0, len(samples))
+	var sum, weighted float64
+	for _, s := range samples {
+		n := s.Notional()
+		sum += s.SlippageBps
+		weighted += s.SlippageBps * n
+		row.NotionalUSD += n
+		row.CostUSD += s.SlippageBps / 10000 * n
+		row.WorstBps = math.Max(row.WorstBps, s.SlippageBps)
+		bps = append(bps, s.SlippageBps)
+	}
+	row.MeanBps = sum / float64(len(samples))
+	if row.NotionalUSD > 0 {
+		row.WeightedBps = weighted / row.NotionalUSD
+	}
+	sort.Float64s(bps)
+	row.MedianBps = percentile(bps, 50)
+	row.P90Bps = percentile(bps, 90)
+	return row
+}
+
+// percentile interpolates linearly over sorted values.
+func percentile(sorted []float64, pct float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := pct / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
+
+// observedSlippage summarises the last promptSlippageFills fills per symbol
+// for the trader's model; it feeds the executor prompt.
+func (m *Manager) observedSlippage(model string) map[string]executorpkg.SlippageStat {
+	if m == nil || model == "" {
+		return nil
+	}
+	bySymbol := make(map[string][]float64)
+	samples := m.execQuality.snapshot()
+	for i := len(samples) - 1; i >= 0; i-- {
+		s := samples[i]
+		if s.Model != model || len(bySymbol[s.Symbol]) >= promptSlippageFills {
+			continue
+		}
+		bySymbol[s.Symbol] = append(bySymbol[s.Symbol], s.SlippageBps)
+	}
+	if len(bySymbol) == 0 {
+		return nil
+	}
+	out := make(map[string]executorpkg.SlippageStat, len(bySymbol))
+	for sym, bps := range bySymbol {
+		var sum float64
+		for _, b := range bps {
+			sum += b
+		}
+		sort.Float64s(bps)
+		out[sym] = executorpkg.SlippageStat{Fills: len(bps), MeanBps: sum / float64(len(bps)), P90Bps: percentile(bps, 90)}
+	}
+	return out
+}
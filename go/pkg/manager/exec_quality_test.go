@@ -0,0 +1,80 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+func TestAdverseSlippageBps(t *testing.T) {
+	assert.InDelta(t, 10, adverseSlippageBps("open_long", 100, 100.1), 1e-9)
+	assert.InDelta(t, -10, adverseSlippageBps("open_short", 100, 100.1), 1e-9, "selling higher is favourable")
+	assert.InDelta(t, 10, adverseSlippageBps("close_long", 100, 99.9), 1e-9)
+	assert.InDelta(t, 10, adverseSlippageBps("close_short", 100, 100.1), 1e-9)
+	assert.Zero(t, adverseSlippageBps("open_long", 0, 100))
+}
+
+func TestExecutionQualityReport(t *testing.T) {
+	m := NewManager(&Config{}, nil, nil, nil, nil)
+	gpt := &VirtualTrader{ID: "t1", Model: "gpt-5"}
+	ds := &VirtualTrader{ID: "t2", Model: "deepseek-chat"}
+	at := time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	m.recordExecution(gpt, &executorpkg.Decision{Symbol: "btc", Action: "open_long"}, 100, 100.2, 10, at)
+	m.recordExecution(gpt, &executorpkg.Decision{Symbol: "ETH", Action: "open_short"}, 100, 100.1, 30, at)
+	m.recordExecution(ds, &executorpkg.Decision{Symbol: "BTC", Action: "close_long"}, 100, 99.95, 20, at.Add(time.Hour))
+	m.recordExecution(ds, &executorpkg.Decision{Symbol: "BTC", Action: "open_long"}, 0, 100, 20, at)
+
+	rows, err := m.ExecutionQuality(ExecGroupBySymbol, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, rows, 2, "samples without an arrival price are skipped")
+	btc := rows[0]
+	assert.Equal(t, "BTC", btc.Key)
+	assert.Equal(t, 2, btc.Fills)
+	assert.InDelta(t, 12.5, btc.MeanBps, 1e-9)
+	assert.InDelta(t, 20, btc.WorstBps, 1e-9)
+	assert.InDelta(t, (20*1002+5*1999)/(1002.0+1999), btc.WeightedBps, 1e-9)
+	assert.InDelta(t, 2.004+0.9995, btc.CostUSD, 1e-9)
+
+	rows, err = m.ExecutionQuality(ExecGroupByModel, at.Add(30*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "deepseek-chat", rows[0].Key)
+
+	_, err = m.ExecutionQuality("venue", time.Time{})
+	assert.Error(t, err)
+}
+
+func TestObservedSlippageUsesRecentFillsOfModel(t *testing.T) {
+	m := NewManager(&Config{}, nil, nil, nil, nil)
+	trader := &VirtualTrader{ID: "t1", Model: "gpt-5"}
+	other := &VirtualTrader{ID: "t2", Model: "qwen-max"}
+	at := time.Now()
+	// An old outlier falls out of the per-symbol window.
+	m.recordExecution(trader, &executorpkg.Decision{Symbol: "SOL", Action: "open_long"}, 100, 101, 1, at)
+	for i := 0; i < promptSlippageFills; i++ {
+		m.recordExecution(trader, &executorpkg.Decision{Symbol: "SOL", Action: "open_long"}, 100, 100.02, 1, at)
+	}
+	m.recordExecution(other, &executorpkg.Decision{Symbol: "SOL", Action: "open_long"}, 100, 105, 1, at)
+
+	stats := m.observedSlippage("gpt-5")
+	require.Contains(t, stats, "SOL")
+	assert.Equal(t, promptSlippageFills, stats["SOL"].Fills)
+	assert.InDelta(t, 2, stats["SOL"].MeanBps, 1e-9)
+	assert.Nil(t, m.observedSlippage("claude"))
+}
+
+func TestExecQualityLogWrapsOldestFirst(t *testing.T) {
+	var l execQualityLog
+	for i := 0; i < execQualityCapacity+5; i++ {
+		l.add(ExecutionSample{FillQty: float64(i)})
+	}
+	samples := l.snapshot()
+	require.Len(t, samples, execQualityCapacity)
+	assert.Equal(t, 5.0, samples[0].FillQty)
+	assert.Equal(t, float64(execQualityCapacity+4), samples[len(samples)-1].FillQty)
+}
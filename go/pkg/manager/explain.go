@@ -0,0 +1,82 @@
+package manager
+
+import (
+	"time"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/risk"
+)
+
+// executorLimits maps the trader's risk params onto the executor config
+// fields ValidateDecisions reads.
+func (r RiskParameters) executorLimits() *executorpkg.Config {
+	return &executorpkg.Config{
+		MajorCoinLeverage:  r.MajorCoinLeverage,
+		AltcoinLeverage:    r.AltcoinLeverage,
+		MinConfidence:      r.MinConfidence,
+		MinRiskReward:      r.MinRiskRewardRatio,
+		MaxPositions:       r.MaxPositions,
+		VolatilityLeverage: r.VolatilityLeverage,
+		AllowHedge:         r.AllowHedge,
+	}
+}
+
+// guardConfig maps the trader's risk params onto the limits the execution
+// guard enforces.
+func (r RiskParameters) guardConfig() risk.Config {
+	return risk.Config{
+		MaxLossPerTradeUSD:          r.MaxLossPerTradeUSD,
+		MinRiskRewardRatio:          r.MinRiskRewardRatio,
+		MinLiquidationDistancePct:   r.MinLiquidationDistancePct,
+		MaxPositionConcentrationPct: r.MaxPositionConcentrationPct,
+		MinPositionSizeUSD:          r.MinPositionSizeUSD,
+		Resize:                      r.ResizeOrders,
+	}
+}
+
+// applyTo copies the enabled exec guards onto ctx. Toggles default to on
+// when omitted.
+func (g ExecGuards) applyTo(ctx *executorpkg.Context, rp RiskParameters) {
+	guard := func(toggle *bool, v float64) float64 {
+		if toggle == nil || *toggle {
+			return v
+		}
+		return 0
+	}
+	ctx.MaxMarginUsagePct = guard(g.EnableMarginUsageGuard, rp.MaxMarginUsagePct)
+	ctx.LiquidityThresholdUSD = guard(g.EnableLiquidityGuard, g.LiquidityThresholdUSD)
+	ctx.BTCETHPositionValueMinMultiple = guard(g.EnableValueBandGuard, g.BTCETHMinEquityMultiple)
+	ctx.BTCETHPositionValueMaxMultiple = guard(g.EnableValueBandGuard, g.BTCETHMaxEquityMultiple)
+	ctx.AltPositionValueMinMultiple = guard(g.EnableValueBandGuard, g.AltMinEquityMultiple)
+	ctx.AltPositionValueMaxMultiple = guard(g.EnableValueBandGuard, g.AltMaxEquityMultiple)
+	ctx.CooldownAfterClose = 0
+	if g.EnableCooldownGuard == nil || *g.EnableCooldownGuard {
+		ctx.CooldownAfterClose = g.CooldownAfterClose
+	}
+}
+
+// ExplainDecision dry-runs the risk engine for trader cfg against the
+// portfolio in base (account, positions and, optionally, market data),
+// reporting every rule with its inputs and threshold. The trader's risk
+// params and exec guards are applied as in a live cycle, but at full scale:
+// drawdown scaling, open throttles and loss-streak pauses depend on runtime
+// state and are only evaluated when base carries them.
+func ExplainDecision(cfg TraderConfig, base executorpkg.Context, d executorpkg.Decision) executorpkg.Explanation {
+	ctx := base
+	ctx.MajorCoinLeverage = cfg.RiskParams.MajorCoinLeverage
+	ctx.AltcoinLeverage = cfg.RiskParams.AltcoinLeverage
+	if ctx.RiskScale <= 0 {
+		ctx.MaxRiskPct = cfg.RiskParams.MaxRiskPerTradePct
+		ctx.MaxPositionSizeUSD = cfg.RiskParams.MaxPositionSizeUSD
+	} else {
+		ctx.MaxRiskPct = cfg.RiskParams.MaxRiskPerTradePct * ctx.RiskScale
+		ctx.MaxPositionSizeUSD = cfg.RiskParams.MaxPositionSizeUSD * ctx.RiskScale
+	}
+	ctx.LossStreakLimit = cfg.ExecGuards.LossStreakLimit
+	ctx.HedgeMode = cfg.PositionMode.IsHedge()
+	if ctx.CurrentTime == "" {
+		ctx.CurrentTime = time.Now().UTC().Format(time.RFC3339)
+	}
+	cfg.ExecGuards.applyTo(&ctx, cfg.RiskParams)
+	return executorpkg.Explain(cfg.RiskParams.executorLimits(), &ctx, d)
+}
@@ -0,0 +1,44 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+func TestExplainDecision_AppliesTraderRisk(t *testing.T) {
+	off := false
+	cfg := TraderConfig{
+		ID: "t1",
+		RiskParams: RiskParameters{
+			MaxPositions:       3,
+			MaxPositionSizeUSD: 400,
+			MaxMarginUsagePct:  50,
+			MajorCoinLeverage:  10,
+			AltcoinLeverage:    5,
+			MinRiskRewardRatio: 2,
+			MinConfidence:      60,
+			MaxRiskPerTradePct: 1,
+		},
+		ExecGuards: ExecGuards{LiquidityThresholdUSD: 1e6, EnableLiquidityGuard: &off},
+	}
+	base := executorpkg.Context{Account: executorpkg.AccountInfo{TotalEquity: 1000, MarginUsed: 480}}
+	d := executorpkg.Decision{Symbol: "BTC", Action: "open_long", Leverage: 10, PositionSizeUSD: 500, EntryPrice: 100, StopLoss: 95, TakeProfit: 115, Confidence: 70, RiskUSD: 25}
+
+	e := ExplainDecision(cfg, base, d)
+	assert.False(t, e.Allowed)
+	statuses := map[string]executorpkg.RuleStatus{}
+	for _, r := range e.Rules {
+		statuses[r.Rule] = r.Status
+	}
+	assert.Equal(t, executorpkg.RulePass, statuses["leverage_cap"])
+	assert.Equal(t, executorpkg.RuleFail, statuses["margin_usage"], "480 used + 50 new margin is 53% of equity")
+	assert.Equal(t, executorpkg.RuleSkip, statuses["liquidity"], "disabled toggle turns the guard off")
+	assert.Equal(t, executorpkg.RuleFail, statuses["max_risk"])
+	assert.Equal(t, executorpkg.RuleFail, statuses["max_position_size"])
+	require.NotNil(t, e.Failure())
+	assert.Equal(t, "margin_usage", e.Failure().Rule)
+}
@@ -0,0 +1,239 @@
+package manager
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+const (
+	// factCheckCapacity bounds the in-memory fact-check history.
+	factCheckCapacity = 2000
+	// factTolerance is the relative difference within which a cited number
+	// matches the prompt, allowing for rounding ("RSI 72" for 72.34).
+	factTolerance = 0.005
+	// chronicMinCited is how many cited numbers a model needs before it can
+	// be called a chronic hallucinator.
+	chronicMinCited = 20
+	// chronicAccuracy is the accuracy below which it is one.
+	chronicAccuracy = 0.8
+)
+
+// factNumberRe matches decimal numbers with optional thousands separators and
+// the character after them, so durations like 4h and series names like
+// EMA20 can be skipped.
+var factNumberRe = regexp.MustCompile(`(?:^|[^\w.])(-?\$?\d{1,3}(?:,\d{3})+(?:\.\d+)?|-?\$?\d+(?:\.\d+)?)(%|[A-Za-z]?)`)
+
+// FactCheckSample scores how many of the numbers one decision's
+// justification cites appear in the prompt it was given. It is a proxy for
+// whether the model read the data rather than inventing it.
+type FactCheckSample struct {
+	TraderID string
+	Model    string
+	Symbol   string
+	Action   string
+	// Cited counts the numbers checked; Matched those found in the prompt
+	// or the decision itself.
+	Cited     int
+	Matched   int
+	Unmatched []string
+	At        time.Time
+}
+
+// Accuracy is the matched share of cited numbers; 1 when none were cited.
+func (s FactCheckSample) Accuracy() float64 {
+	if s.Cited == 0 {
+		return 1
+	}
+	return float64(s.Matched) / float64(s.Cited)
+}
+
+// FactualAccuracyRow aggregates fact checks for one model or trader.
+type FactualAccuracyRow struct {
+	Key       string
+	Decisions int
+	Cited     int
+	Matched   int
+	// Accuracy is Matched/Cited over all decisions (1 when nothing was cited).
+	Accuracy float64
+	// Chronic marks a model that keeps citing numbers absent from its data.
+	Chronic bool
+}
+
+// factCheckLog keeps the most recent fact checks in a ring buffer, in memory
+// like the execution-quality log.
+type factCheckLog struct {
+	mu      sync.RWMutex
+	samples []FactCheckSample
+	next    int
+}
+
+func (l *factCheckLog) add(s FactCheckSample) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.samples) < factCheckCapacity {
+		l.samples = append(l.samples, s)
+		return
+	}
+	l.samples[l.next] = s
+	l.next = (l.next + 1) % factCheckCapacity
+}
+
+func (l *factCheckLog) snapshot() []FactCheckSample {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make([]FactCheckSample, 0, len(l.samples))
+	out = append(out, l.samples[l.next:]...)
+	out = append(out, l.samples[:l.next]...)
+	return out
+}
+
+// citedNumber is one number found in text; pct marks a trailing percent sign.
+type citedNumber struct {
+	raw   string
+	value float64
+	pct   bool
+}
+
+// extractNumbers returns the numbers in text worth checking. Small integers
+// (counts, timeframes, indicator periods) and numbers glued to a unit letter
+// ("4h", "3x") are skipped since they rarely come from the data.
+func extractNumbers(text string) []citedNumber {
+	var out []citedNumber
+	for _, m := range factNumberRe.FindAllStringSubmatch(text, -1) {
+		raw, suffix := m[1], m[2]
+		if suffix != "" && suffix != "%" {
+			continue
+		}
+		clean := strings.NewReplacer("$", "", ",", "").Replace(raw)
+		v, err := strconv.ParseFloat(clean, 64)
+		if err != nil || math.IsInf(v, 0) {
+			continue
+		}
+		if !strings.Contains(clean, ".") && math.Abs(v) < 10 {
+			continue
+		}
+		out = append(out, citedNumber{raw: raw + suffix, value: v, pct: suffix == "%"})
+	}
+	return out
+}
+
+// numberMatches reports whether cited agrees with any known value, allowing
+// rounding and, for percentages, the fraction the data may hold instead.
+func numberMatches(cited citedNumber, known []float64) bool {
+	candidates := []float64{cited.value}
+	if cited.pct {
+		candidates = append(candidates, cited.value/100)
+	}
+	for _, c := range candidates {
+		for _, k := range known {
+			if c == k || (k != 0 && math.Abs(c-k)/math.Abs(k) <= factTolerance) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// factCheckDecision checks the numbers in d's justification against the
+// prompt numbers and the decision's own parameters.
+func factCheckDecision(d executorpkg.Decision, promptNumbers []float64) (cited, matched int, unmatched []string) {
+	known := append([]float64{
+		float64(d.Leverage), d.PositionSizeUSD, d.EntryPrice, d.StopLoss,
+		d.TakeProfit, float64(d.Confidence), d.RiskUSD,
+	}, promptNumbers...)
+	for _, n := range extractNumbers(d.Reasoning + "\n" + d.InvalidationCondition) {
+		cited++
+		if numberMatches(n, known) {
+			matched++
+			continue
+		}
+		unmatched = append(unmatched, n.raw)
+	}
+	return cited, matched, unmatched
+}
+
+// recordFactChecks scores every decision of a cycle against its prompt.
+func (m *Manager) recordFactChecks(trader *VirtualTrader, out *executorpkg.FullDecision, at time.Time) {
+	if m == nil || trader == nil || out == nil || out.UserPrompt == "" {
+		return
+	}
+	// Read the prompt twice: as prose, where commas separate thousands, and
+	// with commas as separators, as in JSON arrays.
+	var promptNumbers []float64
+	for _, text := range []string{out.UserPrompt, strings.ReplaceAll(out.UserPrompt, ",", " ")} {
+		for _, n := range extractNumbers(text) {
+			promptNumbers = append(promptNumbers, n.value)
+		}
+	}
+	trader.mu.RLock()
+	model := trader.Model
+	trader.mu.RUnlock()
+	for _, d := range out.Decisions {
+		cited, matched, unmatched := factCheckDecision(d, promptNumbers)
+		m.factChecks.add(FactCheckSample{
+			TraderID:  trader.ID,
+			Model:     model,
+			Symbol:    strings.ToUpper(d.Symbol),
+			Action:    d.Action,
+			Cited:     cited,
+			Matched:   matched,
+			Unmatched: unmatched,
+			At:        at,
+		})
+	}
+}
+
+// FactualAccuracy reports how often justifications cite numbers present in
+// the prompt since the given time, grouped by model or trader and ordered by
+// key. Chronic rows have cited at least chronicMinCited numbers with under
+// 80% found in the data.
+func (m *Manager) FactualAccuracy(groupBy string, since time.Time) ([]FactualAccuracyRow, error) {
+	var key func(FactCheckSample) string
+	switch strings.ToLower(strings.TrimSpace(groupBy)) {
+	case ExecGroupByModel, "":
+		key = func(s FactCheckSample) string {
+			if s.Model == "" {
+				return "(unknown)"
+			}
+			return s.Model
+		}
+	case ExecGroupByTrader:
+		key = func(s FactCheckSample) string { return s.TraderID }
+	default:
+		return nil, fmt.Errorf("manager: unknown factual accuracy grouping %q (want model or trader)", groupBy)
+	}
+	groups := make(map[string]*FactualAccuracyRow)
+	for _, s := range m.factChecks.snapshot() {
+		if s.At.Before(since) {
+			continue
+		}
+		k := key(s)
+		row, ok := groups[k]
+		if !ok {
+			row = &FactualAccuracyRow{Key: k}
+			groups[k] = row
+		}
+		row.Decisions++
+		row.Cited += s.Cited
+		row.Matched += s.Matched
+	}
+	rows := make([]FactualAccuracyRow, 0, len(groups))
+	for _, row := range groups {
+		row.Accuracy = 1
+		if row.Cited > 0 {
+			row.Accuracy = float64(row.Matched) / float64(row.Cited)
+		}
+		row.Chronic = row.Cited >= chronicMinCited && row.Accuracy < chronicAccuracy
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Key < rows[j].Key })
+	return rows, nil
+}
@@ -0,0 +1,62 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+func TestExtractNumbers(t *testing.T) {
+	var got []string
+	for _, n := range extractNumbers("BTC at $3,200.50 with RSI14 = 72.3, EMA20 below, 4h trend, funding 0.01% and 3 signals; target 3300.") {
+		got = append(got, n.raw)
+	}
+	assert.Equal(t, []string{"$3,200.50", "72.3", "0.01%", "3300"}, got)
+}
+
+func TestFactCheckDecision(t *testing.T) {
+	prompt := []float64{3198.4, 72.34, 0.0001, 150}
+	d := executorpkg.Decision{
+		Symbol:     "ETH",
+		Action:     "open_long",
+		StopLoss:   3100,
+		Reasoning:  "Price 3,200 above EMA, RSI 72 and funding 0.01%; stop at 3100, RSI will hit 85.5 soon.",
+		Confidence: 80,
+	}
+	cited, matched, unmatched := factCheckDecision(d, prompt)
+	assert.Equal(t, 5, cited)
+	assert.Equal(t, 4, matched)
+	assert.Equal(t, []string{"85.5"}, unmatched)
+}
+
+func TestFactualAccuracyFlagsChronicHallucinators(t *testing.T) {
+	m := NewManager(&Config{}, nil, nil, nil, nil)
+	honest := &VirtualTrader{ID: "t1", Model: "gpt-5"}
+	liar := &VirtualTrader{ID: "t2", Model: "qwen-max"}
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		m.recordFactChecks(honest, &executorpkg.FullDecision{
+			UserPrompt: `{"BTC":{"price":64123.5,"rsi":[55.2,61.8]}}`,
+			Decisions:  []executorpkg.Decision{{Symbol: "btc", Action: "hold", Reasoning: "Price 64,120 with RSI 61.8, waiting."}},
+		}, now)
+		m.recordFactChecks(liar, &executorpkg.FullDecision{
+			UserPrompt: `{"BTC":{"price":64123.5}}`,
+			Decisions:  []executorpkg.Decision{{Symbol: "btc", Action: "hold", Reasoning: "Price 64,120, RSI 28.1 oversold, volume 1.8B."}},
+		}, now)
+	}
+
+	rows, err := m.FactualAccuracy("model", time.Time{})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, FactualAccuracyRow{Key: "gpt-5", Decisions: 10, Cited: 20, Matched: 20, Accuracy: 1}, rows[0])
+	assert.Equal(t, "qwen-max", rows[1].Key)
+	assert.InDelta(t, 0.5, rows[1].Accuracy, 1e-9)
+	assert.True(t, rows[1].Chronic)
+
+	_, err = m.FactualAccuracy("symbol", time.Time{})
+	assert.Error(t, err)
+}
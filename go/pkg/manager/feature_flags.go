@@ -0,0 +1,38 @@
+package manager
+
+import (
+	"nof0-api/pkg/flags"
+)
+
+// Feature flags gating manager behaviours; see etc/feature_flags.yaml.
+var (
+	FlagMarketIOCOrders  = flags.Define("market_ioc_orders", "Submit opens of traders with order_style market_ioc as market IOC orders; off falls back to limit IOC", true)
+	FlagRegimeSchedule   = flags.Define("regime_schedule", "Classify the market regime, adapt the decision interval to it and show it in the prompt", true)
+	FlagObservedSlippage = flags.Define("observed_slippage", "Feed the model's observed fill slippage per symbol to the executor prompt", true)
+	FlagAdvisorNotes     = flags.Define("advisor_notes", "Ask the trader's advisors for notes added to the executor prompt", true)
+)
+
+// FeatureFlags answers whether a flag is on for a model and trader, e.g.
+// *flags.Store.
+type FeatureFlags interface {
+	Enabled(name, model, trader string) bool
+}
+
+// WithFeatureFlags gates manager behaviours on f. Without it every flag
+// keeps its declared default.
+func WithFeatureFlags(f FeatureFlags) Option {
+	return func(m *Manager) {
+		m.flags = f
+	}
+}
+
+// featureEnabled evaluates flag for trader t and its current model.
+func (m *Manager) featureEnabled(t *VirtualTrader, flag string) bool {
+	if m.flags == nil {
+		return flags.Default(flag)
+	}
+	t.mu.RLock()
+	model := t.Model
+	t.mu.RUnlock()
+	return m.flags.Enabled(flag, model, t.ID)
+}
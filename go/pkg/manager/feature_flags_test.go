@@ -0,0 +1,53 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/flags"
+	"nof0-api/pkg/market"
+)
+
+type staticFlags []flags.Flag
+
+func (s staticFlags) Load(context.Context) ([]flags.Flag, error) { return s, nil }
+
+func TestFeatureFlagsGateRegimeSchedule(t *testing.T) {
+	store, err := flags.NewStore(context.Background(), staticFlags{{
+		Name:  FlagRegimeSchedule,
+		Rules: []flags.Rule{{Envs: []string{"prod"}, Models: []string{"gpt-5"}, Enabled: false}},
+	}}, "prod")
+	require.NoError(t, err)
+	m := NewManager(&Config{}, nil, nil, nil, nil, WithFeatureFlags(store))
+	trader := &VirtualTrader{
+		ID:                   "t1",
+		Model:                "qwen",
+		MarketProvider:       &regimeMarket{snap: atrSnapshot(6)},
+		DecisionInterval:     5 * time.Minute,
+		BaseDecisionInterval: 5 * time.Minute,
+		RegimeSchedule:       testRegimeSchedule(),
+	}
+	ctx := context.Background()
+
+	assert.Equal(t, RegimeHighVol, m.updateRegime(ctx, trader, map[string]*market.Snapshot{"BTC": atrSnapshot(6)}))
+	assert.Equal(t, 3*time.Minute, trader.DecisionInterval)
+
+	// Promoting a model the flag is off for restores the configured interval.
+	trader.Model = "gpt-5"
+	assert.Empty(t, m.updateRegime(ctx, trader, map[string]*market.Snapshot{"BTC": atrSnapshot(6)}))
+	assert.Equal(t, 5*time.Minute, trader.DecisionInterval)
+	assert.Empty(t, trader.Regime)
+}
+
+func TestFeatureFlagsDefaultsWithoutStore(t *testing.T) {
+	m := NewManager(&Config{}, nil, nil, nil, nil)
+	trader := &VirtualTrader{ID: "t1"}
+	for _, name := range []string{FlagMarketIOCOrders, FlagRegimeSchedule, FlagObservedSlippage, FlagAdvisorNotes} {
+		assert.True(t, m.featureEnabled(trader, name), name)
+	}
+	assert.False(t, m.featureEnabled(trader, "undeclared"))
+}
@@ -0,0 +1,184 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// stallIntervalMultiplier derives the stall threshold from the decision
+	// interval when monitoring.stall_after is unset.
+	stallIntervalMultiplier = 3
+	minStallAfter           = 2 * time.Minute
+)
+
+// Heartbeat reports the liveness of one trader's runner.
+type Heartbeat struct {
+	TraderID         string
+	LastCycleAt      time.Time
+	LastLLMSuccessAt time.Time
+	LastOrderAt      time.Time
+	DecisionInterval time.Duration
+	StallAfter       time.Duration
+	Stalled          bool
+	ReportedAt       time.Time
+}
+
+// HeartbeatRecorder is implemented by persistence backends that store
+// heartbeats for the status API.
+type HeartbeatRecorder interface {
+	RecordHeartbeat(ctx context.Context, hb Heartbeat) error
+}
+
+// stallAfter resolves the stall threshold for a trader.
+func (m *Manager) stallAfter(interval time.Duration) time.Duration {
+	if m.config != nil && m.config.Monitoring.StallAfter > 0 {
+		return m.config.Monitoring.StallAfter
+	}
+	d := stallIntervalMultiplier * interval
+	if d < minStallAfter {
+		d = minStallAfter
+	}
+	return d
+}
+
+// heartbeat snapshots a trader's liveness at now. A trader that has not yet
+// completed a cycle is measured from manager start; paused traders never stall.
+func (m *Manager) heartbeat(t *VirtualTrader, now time.Time) Heartbeat {
+	t.mu.RLock()
+	hb := Heartbeat{
+		TraderID:         t.ID,
+		LastCycleAt:      t.LastDecisionAt,
+		LastLLMSuccessAt: t.LastLLMSuccessAt,
+		LastOrderAt:      t.LastOrderAt,
+		DecisionInterval: t.DecisionInterval,
+		ReportedAt:       now,
+	}
+	paused := now.Before(t.PauseUntil)
+	t.mu.RUnlock()
+
+	hb.StallAfter = m.stallAfter(hb.DecisionInterval)
+	ref := hb.LastCycleAt
+	if ref.IsZero() {
+		ref = m.startedAt
+	}
+	hb.Stalled = !paused && !ref.IsZero() && now.Sub(ref) > hb.StallAfter
+	return hb
+}
+
+// runHeartbeatMonitor publishes heartbeats on the monitoring interval and
+// alerts when a runner stops completing cycles. It runs beside the trading
+// loop so a cycle hung on I/O is still reported.
+func (m *Manager) runHeartbeatMonitor(ctx context.Context) {
+	interval := 30 * time.Second
+	if m.config != nil && m.config.Monitoring.UpdateInterval > 0 {
+		interval = m.config.Monitoring.UpdateInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopChan:
+			return
+		case now := <-ticker.C:
+			m.checkHeartbeats(ctx, now)
+		}
+	}
+}
+
+func (m *Manager) checkHeartbeats(ctx context.Context, now time.Time) {
+	for _, t := range m.ledTraders() {
+		hb := m.heartbeat(t, now)
+		m.recordHeartbeat(hb)
+		m.stallMu.Lock()
+		was := m.stalled[hb.TraderID]
+		m.stalled[hb.TraderID] = hb.Stalled
+		m.stallMu.Unlock()
+		if hb.Stalled == was {
+			continue
+		}
+		data := RunnerStallAlert{
+			TraderID:         hb.TraderID,
+			StallAfter:       hb.StallAfter,
+			LastCycleAt:      hb.LastCycleAt,
+			LastLLMSuccessAt: hb.LastLLMSuccessAt,
+			LastOrderAt:      hb.LastOrderAt,
+			At:               now,
+		}
+		if hb.Stalled {
+			m.sendAlert(ctx, Alert{
+				Kind:     AlertRunnerStalled,
+				TraderID: hb.TraderID,
+				Message:  fmt.Sprintf("trader %s has not completed a cycle in %s", hb.TraderID, hb.StallAfter),
+				Details:  heartbeatDetails(hb),
+				At:       now,
+				Data:     data,
+			})
+		} else {
+			m.sendAlert(ctx, Alert{
+				Kind:     AlertRunnerRecovered,
+				TraderID: hb.TraderID,
+				Message:  fmt.Sprintf("trader %s resumed completing cycles", hb.TraderID),
+				Details:  heartbeatDetails(hb),
+				At:       now,
+				Data:     data,
+			})
+		}
+	}
+}
+
+func heartbeatDetails(hb Heartbeat) map[string]any {
+	details := map[string]any{"stall_after": hb.StallAfter.String()}
+	if !hb.LastCycleAt.IsZero() {
+		details["last_cycle_at"] = hb.LastCycleAt.UTC().Format(time.RFC3339)
+	}
+	if !hb.LastLLMSuccessAt.IsZero() {
+		details["last_llm_success_at"] = hb.LastLLMSuccessAt.UTC().Format(time.RFC3339)
+	}
+	if !hb.LastOrderAt.IsZero() {
+		details["last_order_at"] = hb.LastOrderAt.UTC().Format(time.RFC3339)
+	}
+	return details
+}
+
+func (m *Manager) recordHeartbeat(hb Heartbeat) {
+	recorder, ok := m.persistence.(HeartbeatRecorder)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	err := recorder.RecordHeartbeat(ctx, hb)
+	logPersistenceError(err, "heartbeat persistence failed", map[string]any{
+		"trader_id": hb.TraderID,
+		"stalled":   hb.Stalled,
+	})
+}
+
+func (m *Manager) sendAlert(ctx context.Context, alert Alert) {
+	if alert.At.IsZero() {
+		alert.At = time.Now()
+	}
+	if alert.Kind == AlertRunnerStalled {
+		logx.WithContext(ctx).Errorf("manager: alert %s: %s", alert.Kind, alert.Message)
+	} else {
+		logx.WithContext(ctx).Infof("manager: alert %s: %s", alert.Kind, alert.Message)
+	}
+	if len(m.alerters) == 0 {
+		return
+	}
+	if !m.alertWorker.Load() {
+		m.deliverAlert(ctx, alert)
+		return
+	}
+	select {
+	case m.alertQueue <- alert:
+	default:
+		logx.WithContext(ctx).Errorf("manager: alert queue full (%d); dropping alert %s trader=%s", cap(m.alertQueue), alert.Kind, alert.TraderID)
+	}
+}
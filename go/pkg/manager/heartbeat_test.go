@@ -0,0 +1,123 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingAlerter struct {
+	alerts []Alert
+}
+
+func (r *recordingAlerter) Alert(_ context.Context, alert Alert) error {
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func TestHeartbeatStallThreshold(t *testing.T) {
+	m := NewManager(&Config{}, nil, nil, nil, nil)
+	assert.Equal(t, minStallAfter, m.stallAfter(10*time.Second))
+	assert.Equal(t, 15*time.Minute, m.stallAfter(5*time.Minute))
+
+	m.config.Monitoring.StallAfter = time.Minute
+	assert.Equal(t, time.Minute, m.stallAfter(5*time.Minute))
+}
+
+func TestHeartbeatMonitorAlertsOnStallAndRecovery(t *testing.T) {
+	alerter := &recordingAlerter{}
+	m := NewManager(&Config{}, nil, nil, nil, nil, WithAlerter(alerter))
+	now := time.Now()
+	m.startedAt = now.Add(-time.Hour)
+	trader := &VirtualTrader{
+		ID:               "t1",
+		State:            TraderStateRunning,
+		DecisionInterval: time.Minute,
+		LastDecisionAt:   now.Add(-10 * time.Minute),
+	}
+	m.traders[trader.ID] = trader
+
+	m.checkHeartbeats(context.Background(), now)
+	require.Len(t, alerter.alerts, 1)
+	assert.Equal(t, AlertRunnerStalled, alerter.alerts[0].Kind)
+	assert.Equal(t, "t1", alerter.alerts[0].TraderID)
+
+	// Still stalled: no duplicate alert.
+	m.checkHeartbeats(context.Background(), now.Add(time.Second))
+	assert.Len(t, alerter.alerts, 1)
+
+	trader.RecordDecision(now.Add(2 * time.Second))
+	m.checkHeartbeats(context.Background(), now.Add(3*time.Second))
+	require.Len(t, alerter.alerts, 2)
+	assert.Equal(t, AlertRunnerRecovered, alerter.alerts[1].Kind)
+}
+
+func TestHeartbeatPausedTraderNeverStalls(t *testing.T) {
+	m := NewManager(&Config{}, nil, nil, nil, nil)
+	now := time.Now()
+	trader := &VirtualTrader{
+		ID:               "t1",
+		State:            TraderStateRunning,
+		DecisionInterval: time.Minute,
+		LastDecisionAt:   now.Add(-time.Hour),
+		PauseUntil:       now.Add(time.Hour),
+	}
+	hb := m.heartbeat(trader, now)
+	assert.False(t, hb.Stalled)
+	assert.Equal(t, trader.LastDecisionAt, hb.LastCycleAt)
+}
+
+// blockingAlerter holds every delivery until release is closed.
+type blockingAlerter struct {
+	release   chan struct{}
+	delivered chan Alert
+}
+
+func (b *blockingAlerter) Alert(_ context.Context, alert Alert) error {
+	<-b.release
+	b.delivered <- alert
+	return nil
+}
+
+func TestSendAlertQueuesWhileTheWorkerRuns(t *testing.T) {
+	alerter := &blockingAlerter{release: make(chan struct{}), delivered: make(chan Alert, 8)}
+	m := NewManager(&Config{}, nil, nil, nil, nil, WithAlerter(alerter))
+	m.alertQueue = make(chan Alert, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		m.runAlertWorker(ctx)
+		close(done)
+	}()
+	require.Eventually(t, m.alertWorker.Load, time.Second, time.Millisecond)
+
+	m.sendAlert(ctx, Alert{Kind: AlertRunnerStalled, TraderID: "t1"})
+	require.Eventually(t, func() bool { return len(m.alertQueue) == 0 }, time.Second, time.Millisecond, "the worker holds the first alert")
+	sent := make(chan struct{})
+	go func() {
+		for _, id := range []string{"t2", "t3", "t4"} {
+			m.sendAlert(ctx, Alert{Kind: AlertRunnerStalled, TraderID: id})
+		}
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("sendAlert blocked on a stuck sink")
+	}
+
+	m.Stop()
+	close(alerter.release)
+	<-done
+	close(alerter.delivered)
+	var ids []string
+	for alert := range alerter.delivered {
+		ids = append(ids, alert.TraderID)
+	}
+	assert.Equal(t, []string{"t1", "t2", "t3"}, ids, "t4 overflowed the queue; queued alerts are delivered on stop")
+	assert.False(t, m.alertWorker.Load())
+}
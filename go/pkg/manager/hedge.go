@@ -0,0 +1,65 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"nof0-api/pkg/exchange"
+)
+
+// positionKey keys a virtual position: by symbol on a net-mode account, by
+// symbol and side on a hedge-mode one, where both legs can be open at once.
+func (t *VirtualTrader) positionKey(symbol, side string) string {
+	key := normalizeSymbol(symbol)
+	if key == "" || !t.PositionMode.IsHedge() {
+		return key
+	}
+	return key + ":" + strings.ToUpper(side)
+}
+
+// keySymbol returns the symbol part of a position key.
+func keySymbol(key string) string {
+	symbol, _, _ := strings.Cut(normalizeSymbol(key), ":")
+	return symbol
+}
+
+// heldSides reports which legs the trader holds on symbol. Callers must not
+// hold t.mu.
+func (t *VirtualTrader) heldSides(symbol string) (long, short bool) {
+	symbol = normalizeSymbol(symbol)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, p := range t.VirtualPositions {
+		if normalizeSymbol(p.Symbol) != symbol {
+			continue
+		}
+		if strings.EqualFold(p.Side, "short") {
+			short = true
+		} else {
+			long = true
+		}
+	}
+	return long, short
+}
+
+// closeSide is the side a close action exits.
+func closeSide(action string) string {
+	if action == "close_short" {
+		return "short"
+	}
+	return "long"
+}
+
+// closeLeg closes the position a close decision targets: the whole symbol
+// on a net-mode account, only the named leg on a hedge-mode one.
+func (t *VirtualTrader) closeLeg(ctx context.Context, symbol, action string) (*exchange.OrderResponse, error) {
+	if !t.PositionMode.IsHedge() {
+		return t.ExchangeProvider.ClosePosition(ctx, symbol)
+	}
+	closer, ok := t.ExchangeProvider.(exchange.HedgeCloser)
+	if !ok {
+		return nil, fmt.Errorf("manager: trader %s exchange provider cannot close one side of a hedged position", t.ID)
+	}
+	return closer.ClosePositionSide(ctx, symbol, closeSide(action) == "long")
+}
@@ -0,0 +1,87 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/leader"
+)
+
+// LeaderElector decides which of several manager instances drives each
+// trader. Keys are trader IDs; *leader.Elector implements it.
+type LeaderElector interface {
+	// Run campaigns for the keys returned by keys until ctx ends.
+	Run(ctx context.Context, keys func() []string)
+	// Leading reports whether this instance leads key and its fencing token.
+	Leading(key string) (int64, bool)
+	// Validate confirms with the shared store that token still leads key.
+	Validate(ctx context.Context, key string, token int64) error
+}
+
+// WithLeaderElection lets several instances share the trader set: each runs
+// decision cycles, heartbeats and triggers only for the traders it leads,
+// and the exchange provider checks the lease's fencing token against the
+// shared store right before sending each order.
+func WithLeaderElection(e LeaderElector) Option {
+	return func(m *Manager) {
+		m.leader = e
+	}
+}
+
+// traderIDs lists every registered trader, led or not, for the elector to
+// campaign for.
+func (m *Manager) traderIDs() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := make([]string, 0, len(m.traders))
+	for id := range m.traders {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// leadsTrader reports whether this instance drives traderID, which is
+// always the case without leader election.
+func (m *Manager) leadsTrader(traderID string) bool {
+	if m.leader == nil {
+		return true
+	}
+	_, ok := m.leader.Leading(traderID)
+	return ok
+}
+
+// ledTraders returns the active traders this instance leads.
+func (m *Manager) ledTraders() []*VirtualTrader {
+	traders := m.GetActiveTraders()
+	if m.leader == nil {
+		return traders
+	}
+	out := traders[:0]
+	for _, t := range traders {
+		if m.leadsTrader(t.ID) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// fenceOrder confirms that this instance leads trader and returns ctx
+// carrying the lease's fencing token and a check against the shared store,
+// which the exchange provider runs right before sending the order.
+func (m *Manager) fenceOrder(ctx context.Context, trader *VirtualTrader) (context.Context, error) {
+	if m.leader == nil {
+		return ctx, nil
+	}
+	token, ok := m.leader.Leading(trader.ID)
+	if !ok {
+		return ctx, fmt.Errorf("manager: trader %s: %w", trader.ID, leader.ErrNotLeader)
+	}
+	elector := m.leader
+	return exchange.WithFence(ctx, token, func(ctx context.Context, token int64) error {
+		if err := elector.Validate(ctx, trader.ID, token); err != nil {
+			return fmt.Errorf("manager: trader %s fencing token %d: %w", trader.ID, token, err)
+		}
+		return nil
+	}), nil
+}
@@ -0,0 +1,60 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/leader"
+)
+
+func TestManagerLeaderElectionSplitsTraders(t *testing.T) {
+	ctx := context.Background()
+	backend := leader.NewMemoryBackend()
+	other, err := leader.NewElector(backend, "other", time.Minute)
+	require.NoError(t, err)
+	other.Campaign(ctx, []string{"t2"})
+
+	elector, err := leader.NewElector(backend, "self", time.Minute)
+	require.NoError(t, err)
+	m := &Manager{traders: make(map[string]*VirtualTrader)}
+	WithLeaderElection(elector)(m)
+	for _, id := range []string{"t1", "t2"} {
+		m.traders[id] = &VirtualTrader{ID: id, State: TraderStateRunning}
+	}
+	elector.Campaign(ctx, m.traderIDs())
+
+	led := m.ledTraders()
+	require.Len(t, led, 1)
+	assert.Equal(t, "t1", led[0].ID)
+
+	fenced, err := m.fenceOrder(ctx, m.traders["t1"])
+	require.NoError(t, err)
+	token, ok := exchange.FencingToken(fenced)
+	require.True(t, ok)
+	assert.EqualValues(t, 1, token)
+	assert.NoError(t, exchange.CheckFence(fenced))
+
+	_, err = m.fenceOrder(ctx, m.traders["t2"])
+	assert.ErrorIs(t, err, leader.ErrNotLeader)
+
+	// Another instance takes t1 over after the fence was attached: the
+	// check providers run before sending now fails.
+	require.NoError(t, backend.Release(ctx, "t1", elector.Owner()))
+	other.Campaign(ctx, []string{"t1"})
+	assert.ErrorIs(t, exchange.CheckFence(fenced), leader.ErrNotLeader)
+}
+
+func TestManagerWithoutLeaderElectionLeadsAll(t *testing.T) {
+	m := &Manager{traders: map[string]*VirtualTrader{"t1": {ID: "t1", State: TraderStateRunning}}}
+	assert.Len(t, m.ledTraders(), 1)
+	ctx, err := m.fenceOrder(context.Background(), m.traders["t1"])
+	require.NoError(t, err)
+	_, ok := exchange.FencingToken(ctx)
+	assert.False(t, ok, "no token without election")
+	assert.NoError(t, exchange.CheckFence(ctx))
+}
@@ -0,0 +1,118 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/repo"
+)
+
+// closedPnL estimates the realised PnL of closing the trader's virtual
+// position under key (see positionKey) at fillPrice. ok is false when the
+// position or price is unknown.
+func (t *VirtualTrader) closedPnL(key string, fillPrice float64) (pnl float64, ok bool) {
+	if t == nil || fillPrice <= 0 {
+		return 0, false
+	}
+	t.mu.RLock()
+	pos, exists := t.VirtualPositions[normalizeSymbol(key)]
+	t.mu.RUnlock()
+	if !exists || pos.EntryPrice <= 0 || pos.Quantity <= 0 {
+		return 0, false
+	}
+	pnl = (fillPrice - pos.EntryPrice) * pos.Quantity
+	if strings.EqualFold(pos.Side, "short") {
+		pnl = -pnl
+	}
+	return pnl, true
+}
+
+// recordCloseOutcome updates the consecutive-loss counter and starts an
+// entry pause once the configured streak is reached. It reports whether the
+// pause was triggered by this close.
+func (t *VirtualTrader) recordCloseOutcome(pnl float64, at time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if pnl >= 0 {
+		t.LossStreak = 0
+		return false
+	}
+	t.LossStreak++
+	t.LastLossPnL = pnl
+	limit := t.ExecGuards.LossStreakLimit
+	if limit <= 0 || t.LossStreak < limit || t.ExecGuards.LossStreakCooldown <= 0 {
+		return false
+	}
+	t.EntriesPausedUntil = at.Add(t.ExecGuards.LossStreakCooldown)
+	t.LossStreak = 0
+	return true
+}
+
+// entriesPaused reports whether the loss-streak cooldown blocks new opens.
+func (t *VirtualTrader) entriesPaused(now time.Time) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return now.Before(t.EntriesPausedUntil)
+}
+
+// trackLossStreak feeds a successful close into the loss-streak rule,
+// alerting and persisting the runtime state when it changes.
+func (m *Manager) trackLossStreak(ctx context.Context, trader *VirtualTrader, symbol string, pnl float64, at time.Time) {
+	if trader.ExecGuards.LossStreakLimit <= 0 {
+		return
+	}
+	if trader.recordCloseOutcome(pnl, at) {
+		trader.mu.RLock()
+		until := trader.EntriesPausedUntil
+		trader.mu.RUnlock()
+		reason := fmt.Sprintf("%d consecutive losing closes (last %s %.2f USD)", trader.ExecGuards.LossStreakLimit, strings.ToUpper(symbol), pnl)
+		logx.WithContext(ctx).Infof("manager: trader %s entries paused until %s: %s", trader.ID, until.Format(time.RFC3339), reason)
+		m.sendAlert(ctx, Alert{
+			Kind:     AlertBreakerTripped,
+			TraderID: trader.ID,
+			Message:  fmt.Sprintf("trader %s entries paused until %s: %s", trader.ID, until.Format(time.RFC3339), reason),
+			Data: BreakerTrippedAlert{
+				TraderID:   trader.ID,
+				Breaker:    "loss_streak",
+				Reason:     reason,
+				PauseUntil: until,
+				At:         at,
+			},
+		})
+	}
+	m.persistRuntimeState(ctx, trader)
+}
+
+// trackExchangeClose counts a position closed by an exchange-side stop or
+// take-profit, valuing it at the current mark since the fill is not known.
+func (m *Manager) trackExchangeClose(ctx context.Context, trader *VirtualTrader, symbol, key string) {
+	if trader.ExecGuards.LossStreakLimit <= 0 || trader.MarketProvider == nil {
+		return
+	}
+	snap, err := trader.MarketProvider.Snapshot(ctx, symbol)
+	if err != nil || snap == nil {
+		return
+	}
+	if pnl, ok := trader.closedPnL(key, snap.Price.Last); ok {
+		m.trackLossStreak(ctx, trader, symbol, pnl, time.Now())
+	}
+}
+
+func buildLossStreakDetail(trader *VirtualTrader) *repo.RuntimeLossStreakDetail {
+	if trader.LossStreak == 0 && !trader.EntriesPausedUntil.After(time.Now()) {
+		return nil
+	}
+	detail := &repo.RuntimeLossStreakDetail{
+		Count:       trader.LossStreak,
+		LastLossPnL: trader.LastLossPnL,
+	}
+	if trader.EntriesPausedUntil.After(time.Now()) {
+		until := trader.EntriesPausedUntil.UTC()
+		detail.PausedUntil = &until
+	}
+	return detail
+}
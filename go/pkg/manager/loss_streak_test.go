@@ -0,0 +1,74 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVirtualTraderClosedPnL(t *testing.T) {
+	trader := &VirtualTrader{VirtualPositions: map[string]VirtualPosition{
+		"BTC": {Symbol: "BTC", Side: "long", Quantity: 0.5, EntryPrice: 100},
+		"ETH": {Symbol: "ETH", Side: "short", Quantity: 2, EntryPrice: 50},
+	}}
+	pnl, ok := trader.closedPnL("btc", 90)
+	require.True(t, ok)
+	assert.InDelta(t, -5, pnl, 1e-9)
+
+	pnl, ok = trader.closedPnL("ETH", 45)
+	require.True(t, ok)
+	assert.InDelta(t, 10, pnl, 1e-9)
+
+	_, ok = trader.closedPnL("SOL", 10)
+	assert.False(t, ok)
+	_, ok = trader.closedPnL("BTC", 0)
+	assert.False(t, ok)
+}
+
+func TestLossStreakPausesEntries(t *testing.T) {
+	alerter := &recordingAlerter{}
+	m := NewManager(&Config{}, nil, nil, nil, nil, WithAlerter(alerter))
+	trader := &VirtualTrader{ID: "t1", ExecGuards: ExecGuards{LossStreakLimit: 3, LossStreakCooldown: 2 * time.Hour}}
+	now := time.Now()
+	ctx := context.Background()
+
+	m.trackLossStreak(ctx, trader, "BTC", -10, now)
+	m.trackLossStreak(ctx, trader, "ETH", 5, now) // a winner resets the streak
+	assert.Equal(t, 0, trader.LossStreak)
+
+	m.trackLossStreak(ctx, trader, "BTC", -10, now)
+	m.trackLossStreak(ctx, trader, "BTC", -4, now)
+	assert.Equal(t, 2, trader.LossStreak)
+	assert.False(t, trader.entriesPaused(now))
+	detail := buildLossStreakDetail(trader)
+	require.NotNil(t, detail)
+	assert.Equal(t, 2, detail.Count)
+	assert.Nil(t, detail.PausedUntil)
+
+	m.trackLossStreak(ctx, trader, "sol", -1, now)
+	assert.True(t, trader.entriesPaused(now.Add(time.Hour)))
+	assert.False(t, trader.entriesPaused(now.Add(2*time.Hour)))
+	assert.Equal(t, 0, trader.LossStreak)
+
+	require.Len(t, alerter.alerts, 1)
+	data, ok := alerter.alerts[0].Data.(BreakerTrippedAlert)
+	require.True(t, ok)
+	assert.Equal(t, "loss_streak", data.Breaker)
+	assert.Contains(t, data.Reason, "3 consecutive losing closes (last SOL -1.00 USD)")
+
+	detail = buildLossStreakDetail(trader)
+	require.NotNil(t, detail)
+	require.NotNil(t, detail.PausedUntil)
+	assert.True(t, detail.PausedUntil.Equal(now.Add(2*time.Hour)))
+}
+
+func TestLossStreakDisabled(t *testing.T) {
+	m := NewManager(&Config{}, nil, nil, nil, nil)
+	trader := &VirtualTrader{ID: "t1"}
+	m.trackLossStreak(context.Background(), trader, "BTC", -10, time.Now())
+	assert.Equal(t, 0, trader.LossStreak)
+	assert.Nil(t, buildLossStreakDetail(trader))
+}
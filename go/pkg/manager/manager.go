@@ -7,21 +7,27 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"math"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"nof0-api/internal/engine"
 	"nof0-api/pkg/exchange"
 	executorpkg "nof0-api/pkg/executor"
 	"nof0-api/pkg/journal"
 	"nof0-api/pkg/llm"
 	"nof0-api/pkg/market"
+	"nof0-api/pkg/prompt"
 	"nof0-api/pkg/repo"
+	"nof0-api/pkg/strategy"
 )
 
 const (
@@ -39,6 +45,9 @@ type ExecutorFactory interface {
 type BasicExecutorFactory struct {
 	llmClient          llm.LLMClient
 	conversationLogger executorpkg.ConversationRecorder
+	templateFS         fs.FS
+	templateWatcher    *llm.TemplateWatcher
+	prompts            *prompt.Registry
 }
 
 // NewBasicExecutorFactory returns a factory that builds local executors using
@@ -47,6 +56,33 @@ func NewBasicExecutorFactory(client llm.LLMClient, recorder executorpkg.Conversa
 	return &BasicExecutorFactory{llmClient: client, conversationLogger: recorder}
 }
 
+// SetTemplateFS makes executors read ExecutorTemplate from fsys instead of disk.
+func (f *BasicExecutorFactory) SetTemplateFS(fsys fs.FS) {
+	if f == nil {
+		return
+	}
+	f.templateFS = fsys
+}
+
+// SetTemplateWatcher registers every executor's prompt templates with w so
+// template edits are picked up without a restart. Executors the manager
+// replaces or drops are closed, which unregisters them.
+func (f *BasicExecutorFactory) SetTemplateWatcher(w *llm.TemplateWatcher) {
+	if f == nil {
+		return
+	}
+	f.templateWatcher = w
+}
+
+// SetPromptRegistry lets prompt experiments choose each executor's template
+// per decision and attribute decisions to prompt versions.
+func (f *BasicExecutorFactory) SetPromptRegistry(r *prompt.Registry) {
+	if f == nil {
+		return
+	}
+	f.prompts = r
+}
+
 // NewExecutor implements ExecutorFactory.
 func (f *BasicExecutorFactory) NewExecutor(traderCfg TraderConfig) (executorpkg.Executor, error) {
 	if f == nil || f.llmClient == nil {
@@ -67,25 +103,27 @@ func (f *BasicExecutorFactory) NewExecutor(traderCfg TraderConfig) (executorpkg.
 	if intervalRaw == "" {
 		intervalRaw = "3m"
 	}
-	ec := &executorpkg.Config{
-		MajorCoinLeverage:      traderCfg.RiskParams.MajorCoinLeverage,
-		AltcoinLeverage:        traderCfg.RiskParams.AltcoinLeverage,
-		MinConfidence:          traderCfg.RiskParams.MinConfidence,
-		MinRiskReward:          traderCfg.RiskParams.MinRiskRewardRatio,
-		MaxPositions:           traderCfg.RiskParams.MaxPositions,
-		DecisionIntervalRaw:    intervalRaw,
-		DecisionInterval:       interval,
-		DecisionTimeoutRaw:     "60s",
-		DecisionTimeout:        60 * time.Second,
-		MaxConcurrentDecisions: 1,
-		AllowedTraderIDs:       []string{traderCfg.ID},
-	}
+	ec := traderCfg.RiskParams.executorLimits()
+	ec.DecisionIntervalRaw = intervalRaw
+	ec.DecisionInterval = interval
+	ec.DecisionTimeoutRaw = "60s"
+	ec.DecisionTimeout = 60 * time.Second
+	ec.MaxConcurrentDecisions = 1
+	ec.AllowedTraderIDs = []string{traderCfg.ID}
 	// executor.NewExecutor validates config.
 	ec.TraderID = traderCfg.ID
+	ec.TemplateFS = f.templateFS
+	ec.TemplateDigest = traderCfg.ExecutorTemplateDigest
 	var opts []executorpkg.ExecutorOption
 	if f.conversationLogger != nil {
 		opts = append(opts, executorpkg.WithConversationRecorder(f.conversationLogger))
 	}
+	if f.templateWatcher != nil {
+		opts = append(opts, executorpkg.WithTemplateWatcher(f.templateWatcher))
+	}
+	if f.prompts != nil {
+		opts = append(opts, executorpkg.WithPromptVersions(f.prompts, traderCfg.ID))
+	}
 	exec, err := executorpkg.NewExecutor(ec, f.llmClient, traderCfg.ExecutorTemplate, traderCfg.Model, opts...)
 	if err != nil {
 		return nil, err
@@ -93,6 +131,18 @@ func (f *BasicExecutorFactory) NewExecutor(traderCfg TraderConfig) (executorpkg.
 	return exec, nil
 }
 
+// releaseExecutor closes an executor the manager no longer uses, releasing
+// its template watcher registrations. Executors without Close are left as is.
+func releaseExecutor(exec executorpkg.Executor) {
+	c, ok := exec.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := c.Close(); err != nil {
+		logx.Errorf("manager: close executor: %v", err)
+	}
+}
+
 // Manager is the orchestration layer that coordinates virtual traders,
 // executors and providers.
 type Manager struct {
@@ -107,11 +157,33 @@ type Manager struct {
 	// Provider registries resolved at startup (see internal/svc for wiring).
 	exchangeProviders map[string]exchange.Provider
 	marketProviders   map[string]market.Provider
+	// subAccounts caches sub-account providers by "<provider>/<name>".
+	subAccounts map[string]subAccountBinding
 
 	executorFactory ExecutorFactory
 	persistence     PersistenceService
 	configRepo      repo.TraderConfigRepository
 	runtimeRepo     repo.TraderRuntimeRepository
+	alerters        []Alerter
+	alertTemplates  *AlertTemplates
+	alertQueue      chan Alert
+	alertWorker     atomic.Bool
+	blacklist       *symbolBlacklist
+	execQuality     execQualityLog
+	factChecks      factCheckLog
+	embedders       EmbedderSource
+	accountMetrics  AccountMetricsSource
+	leader          LeaderElector
+	prompts         *prompt.Registry
+	flags           FeatureFlags
+
+	// Heartbeat monitor state: loop start and last known stall per trader.
+	startedAt time.Time
+	stallMu   sync.Mutex
+	stalled   map[string]bool
+	// Exchange providers whose clock skew last exceeded their threshold.
+	skewMu sync.Mutex
+	skewed map[string]bool
 
 	stopChan chan struct{}
 	stopOnce sync.Once
@@ -157,8 +229,13 @@ func NewManager(
 		positionOwners:    make(map[string]string),
 		exchangeProviders: make(map[string]exchange.Provider),
 		marketProviders:   make(map[string]market.Provider),
+		subAccounts:       make(map[string]subAccountBinding),
 		executorFactory:   execFactory,
 		persistence:       persist,
+		stalled:           make(map[string]bool),
+		skewed:            make(map[string]bool),
+		blacklist:         newSymbolBlacklist(cfg.Manager.SymbolBlacklist),
+		alertQueue:        make(chan Alert, alertQueueSize),
 		stopChan:          make(chan struct{}),
 	}
 	for k, v := range exch {
@@ -167,11 +244,23 @@ func NewManager(
 	for k, v := range mkts {
 		m.marketProviders[k] = v
 	}
+	m.watchTransferGuards()
 	for _, opt := range opts {
 		if opt != nil {
 			opt(m)
 		}
 	}
+	if cfg.Monitoring.AlertWebhook != "" {
+		m.alerters = append(m.alerters, NewWebhookAlerter(cfg.Monitoring.AlertWebhook))
+	}
+	if m.alertTemplates == nil && cfg.Monitoring.AlertTemplatesDir != "" {
+		tmpls, err := LoadAlertTemplates(cfg.Monitoring.AlertTemplatesDir)
+		if err != nil {
+			logx.Errorf("manager: alert templates disabled: %v", err)
+		} else {
+			m.alertTemplates = tmpls
+		}
+	}
 	return m
 }
 
@@ -203,6 +292,7 @@ func (m *Manager) RegisterTrader(ctx context.Context, cfg TraderConfig) (*Virtua
 	if m.config != nil {
 		tempCfg.Manager = m.config.Manager
 		tempCfg.Monitoring = m.config.Monitoring
+		tempCfg.TemplateFS = m.config.TemplateFS
 	}
 	if err := tempCfg.Validate(); err != nil {
 		// Reuse config validation on a temporary wrapper to validate the trader.
@@ -221,10 +311,32 @@ func (m *Manager) RegisterTrader(ctx context.Context, cfg TraderConfig) (*Virtua
 	if !ok {
 		return nil, fmt.Errorf("manager: unknown exchange provider %q for trader %s", cfg.ExchangeProvider, cfg.ID)
 	}
+	var subAccount string
+	if cfg.SubAccount.Enabled {
+		var err error
+		ex, subAccount, err = m.resolveSubAccount(ctx, cfg, ex)
+		if err != nil {
+			return nil, err
+		}
+	}
 	mk, ok := m.marketProviders[cfg.MarketProvider]
 	if !ok {
 		return nil, fmt.Errorf("manager: unknown market provider %q for trader %s", cfg.MarketProvider, cfg.ID)
 	}
+	if feeder, ok := ex.(exchange.MarkPriceFeeder); ok && mk != nil {
+		// Paper exchanges mark positions to the trader's live market data, so
+		// dry runs see real PnL without sending orders anywhere.
+		feeder.SetMarkPriceSource(func(ctx context.Context, coin string) (float64, error) {
+			snap, err := mk.Snapshot(ctx, coin)
+			if err != nil {
+				return 0, err
+			}
+			return snap.Price.Last, nil
+		})
+	}
+	if _, ok := ex.(exchange.HedgeCloser); cfg.PositionMode.IsHedge() && !ok {
+		return nil, fmt.Errorf("manager: trader %s position_mode=hedge unsupported by exchange provider %s", cfg.ID, cfg.ExchangeProvider)
+	}
 	if m.executorFactory == nil {
 		return nil, errors.New("manager: executorFactory is not set")
 	}
@@ -232,6 +344,32 @@ func (m *Manager) RegisterTrader(ctx context.Context, cfg TraderConfig) (*Virtua
 	if err != nil {
 		return nil, fmt.Errorf("manager: create executor for trader %s: %w", cfg.ID, err)
 	}
+	shadow, err := m.newShadow(cfg, tempCfg.Manager.TotalEquityUSD*cfg.AllocationPct/100)
+	if err != nil {
+		releaseExecutor(exec)
+		return nil, err
+	}
+	discard := func() {
+		releaseExecutor(exec)
+		if shadow != nil {
+			releaseExecutor(shadow.executor)
+		}
+	}
+	advisors, err := strategy.NewChain(cfg.Advisors)
+	if err != nil {
+		discard()
+		return nil, fmt.Errorf("manager: advisors for trader %s: %w", cfg.ID, err)
+	}
+	outputAnomaly, err := newOutputAnomalyDetector(cfg.OutputAnomaly)
+	if err != nil {
+		discard()
+		return nil, fmt.Errorf("manager: output_anomaly for trader %s: %w", cfg.ID, err)
+	}
+	promptDrift, err := newPromptDriftDetector(cfg.ID, cfg.PromptDrift, m.promptEmbedder(cfg.PromptDrift))
+	if err != nil {
+		discard()
+		return nil, fmt.Errorf("manager: prompt_drift for trader %s: %w", cfg.ID, err)
+	}
 
 	version := cfg.Version
 	if version <= 0 {
@@ -240,26 +378,42 @@ func (m *Manager) RegisterTrader(ctx context.Context, cfg TraderConfig) (*Virtua
 	vt := &VirtualTrader{
 		ID:                   cfg.ID,
 		Name:                 cfg.Name,
+		Model:                cfg.Model,
 		Exchange:             cfg.ExchangeProvider,
 		ExchangeProvider:     ex,
+		SubAccount:           subAccount,
 		MarketProvider:       mk,
 		Executor:             exec,
 		PromptTemplate:       cfg.PromptTemplate,
 		OrderStyle:           cfg.OrderStyle,
 		MarketIOCSlippageBps: cfg.MarketIOCSlippageBps,
+		PartialDataPolicy:    cfg.PartialDataPolicy,
+		MarginMode:           cfg.MarginMode,
+		PositionMode:         cfg.PositionMode,
 		RiskParams:           cfg.RiskParams,
 		ExecGuards:           cfg.ExecGuards,
 		ResourceAlloc: ResourceAllocation{
 			AllocationPct: cfg.AllocationPct,
 		},
-		State:            TraderStateStopped,
-		DecisionInterval: cfg.DecisionInterval,
-		CreatedAt:        time.Now(),
-		UpdatedAt:        time.Now(),
-		VirtualPositions: make(map[string]VirtualPosition),
-		Cooldown:         make(map[string]time.Time),
-		JournalEnabled:   cfg.JournalEnabled,
-		ConfigVersion:    version,
+		State:                TraderStateStopped,
+		DecisionInterval:     cfg.DecisionInterval,
+		BaseDecisionInterval: cfg.DecisionInterval,
+		RegimeSchedule:       cfg.RegimeSchedule,
+		Triggers:             cfg.Triggers,
+		DataQualityInPrompt:  cfg.DataQualityInPrompt,
+		Advisors:             advisors,
+		CreatedAt:            time.Now(),
+		UpdatedAt:            time.Now(),
+		VirtualPositions:     make(map[string]VirtualPosition),
+		Cooldown:             make(map[string]time.Time),
+		JournalEnabled:       cfg.JournalEnabled,
+		ConfigVersion:        version,
+		shadow:               shadow,
+		outputAnomaly:        outputAnomaly,
+		promptDrift:          promptDrift,
+	}
+	if promptDrift != nil {
+		vt.promptDriftStatus = promptDrift.status
 	}
 	if cfg.JournalEnabled {
 		dir := cfg.JournalDir
@@ -331,11 +485,12 @@ func buildRuntimeStateDetail(trader *VirtualTrader) repo.RuntimeStateDetail {
 		}
 	}
 	alloc := trader.ResourceAlloc
-	if alloc.AllocatedEquityUSD > 0 || alloc.MarginUsedUSD > 0 || alloc.AvailableBalanceUSD > 0 || alloc.CurrentEquityUSD > 0 {
+	if alloc.AllocatedEquityUSD > 0 || alloc.MarginUsedUSD > 0 || alloc.AvailableBalanceUSD > 0 || alloc.CurrentEquityUSD > 0 || trader.PeakEquityUSD > 0 {
 		detail.Allocation = &repo.RuntimeAllocationDetail{
 			EquityUSD:          alloc.CurrentEquityUSD,
 			UsedMarginUSD:      alloc.MarginUsedUSD,
 			AvailableMarginUSD: alloc.AvailableBalanceUSD,
+			PeakEquityUSD:      trader.PeakEquityUSD,
 		}
 	}
 	if trader.Performance != nil {
@@ -344,6 +499,7 @@ func buildRuntimeStateDetail(trader *VirtualTrader) repo.RuntimeStateDetail {
 			TotalPnLUSD: trader.Performance.TotalPnLUSD,
 		}
 	}
+	detail.LossStreak = buildLossStreakDetail(trader)
 	return detail
 }
 
@@ -374,6 +530,7 @@ func (m *Manager) hydrateTraderFromState(ctx context.Context, trader *VirtualTra
 		trader.ResourceAlloc.CurrentEquityUSD = alloc.EquityUSD
 		trader.ResourceAlloc.AvailableBalanceUSD = alloc.AvailableMarginUSD
 		trader.ResourceAlloc.MarginUsedUSD = alloc.UsedMarginUSD
+		trader.PeakEquityUSD = alloc.PeakEquityUSD
 	}
 	if perf := snapshot.Detail.Performance; perf != nil {
 		if trader.Performance == nil {
@@ -382,6 +539,13 @@ func (m *Manager) hydrateTraderFromState(ctx context.Context, trader *VirtualTra
 		trader.Performance.SharpeRatio = perf.SharpeRatio
 		trader.Performance.TotalPnLUSD = perf.TotalPnLUSD
 	}
+	if streak := snapshot.Detail.LossStreak; streak != nil {
+		trader.LossStreak = streak.Count
+		trader.LastLossPnL = streak.LastLossPnL
+		if streak.PausedUntil != nil {
+			trader.EntriesPausedUntil = streak.PausedUntil.UTC()
+		}
+	}
 	if snapshot.IsRunning {
 		trader.State = TraderStateRunning
 	} else {
@@ -424,6 +588,14 @@ func (m *Manager) UnregisterTrader(traderID string) error {
 	m.mu.Unlock()
 	_ = t.Stop() // Best-effort stop; ignore error for MVP.
 	m.releaseAllVirtualPositions(t)
+	t.mu.Lock()
+	exec, shadow := t.Executor, t.shadow
+	t.shadow = nil
+	t.mu.Unlock()
+	releaseExecutor(exec)
+	if shadow != nil {
+		releaseExecutor(shadow.executor)
+	}
 	logx.Infof("manager: unregistered trader id=%s", traderID)
 	return nil
 }
@@ -442,7 +614,10 @@ func (m *Manager) GetActiveTraders() []*VirtualTrader {
 	return out
 }
 
-// RunTradingLoop executes the main orchestration loop (minimal skeleton).
+var errPartialMarketData = errors.New("manager: market data unavailable")
+
+// RunTradingLoop ticks every second and runs a decision cycle for each
+// active trader that is due, until ctx ends or Stop is called.
 func (m *Manager) RunTradingLoop(ctx context.Context) error {
 	if m == nil {
 		return errors.New("manager: nil manager")
@@ -450,6 +625,18 @@ func (m *Manager) RunTradingLoop(ctx context.Context) error {
 	logx.WithContext(ctx).Infof("manager: trading loop starting tick=1s active_traders=%d", len(m.GetActiveTraders()))
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
+	m.startedAt = time.Now()
+	if m.leader != nil {
+		// Cancelled on return, including Stop, to release the leases.
+		electCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go m.leader.Run(electCtx, m.traderIDs)
+	}
+	go m.runAlertWorker(ctx)
+	go m.runHeartbeatMonitor(ctx)
+	go m.runClockSkewMonitor(ctx)
+	go m.checkKeyPermissions(ctx)
+	go m.runTriggerMonitor(ctx)
 
 	for {
 		select {
@@ -460,130 +647,232 @@ func (m *Manager) RunTradingLoop(ctx context.Context) error {
 			logx.WithContext(ctx).Infof("manager: trading loop stopping (stop signal)")
 			return nil
 		case <-ticker.C:
-			traders := m.GetActiveTraders()
+			traders := m.ledTraders()
 			for _, t := range traders {
 				if !t.ShouldMakeDecision() {
 					continue
 				}
-				cycleStart := time.Now()
-				// Sharpe gating
-				if t.ExecGuards.SharpePauseThreshold != 0 && t.ExecGuards.PauseDurationOnBreach > 0 && t.Performance != nil {
-					if t.Performance.SharpeRatio < t.ExecGuards.SharpePauseThreshold {
-						t.mu.Lock()
-						if t.PauseUntil.Before(time.Now()) {
-							t.PauseUntil = time.Now().Add(t.ExecGuards.PauseDurationOnBreach)
-						}
-						t.mu.Unlock()
-						logx.WithContext(ctx).Infof("manager: trader %s paused for Sharpe gating until %s", t.ID, t.PauseUntil.Format(time.RFC3339))
-						continue
-					}
+				m.runDecisionCycle(ctx, t)
+			}
+		}
+	}
+}
+
+// runDecisionCycle runs one decision cycle for t: Sharpe gating, then the
+// engine's market data, model, risk and execution stages with the manager's
+// context builder and order path plugged in, with the cycle recorded,
+// journaled and persisted.
+func (m *Manager) runDecisionCycle(ctx context.Context, t *VirtualTrader) {
+	cycleStart := time.Now()
+	breakdown := newCycleBreakdown(t.ID, cycleStart)
+	t.setCycleTrace(breakdown.TraceID)
+	triggers := t.takeTriggers()
+	// Sharpe gating
+	if t.ExecGuards.SharpePauseThreshold != 0 && t.ExecGuards.PauseDurationOnBreach > 0 && t.Performance != nil {
+		if t.Performance.SharpeRatio < t.ExecGuards.SharpePauseThreshold {
+			t.mu.Lock()
+			if t.PauseUntil.Before(time.Now()) {
+				t.PauseUntil = time.Now().Add(t.ExecGuards.PauseDurationOnBreach)
+			}
+			t.mu.Unlock()
+			logx.WithContext(ctx).Infof("manager: trader %s paused for Sharpe gating until %s", t.ID, t.PauseUntil.Format(time.RFC3339))
+			m.sendAlert(ctx, Alert{
+				Kind:     AlertBreakerTripped,
+				TraderID: t.ID,
+				Message:  fmt.Sprintf("trader %s paused until %s: sharpe %.2f below %.2f", t.ID, t.PauseUntil.Format(time.RFC3339), t.Performance.SharpeRatio, t.ExecGuards.SharpePauseThreshold),
+				Data: BreakerTrippedAlert{
+					TraderID:   t.ID,
+					Breaker:    "sharpe_pause",
+					Reason:     fmt.Sprintf("sharpe %.2f below %.2f", t.Performance.SharpeRatio, t.ExecGuards.SharpePauseThreshold),
+					PauseUntil: t.PauseUntil,
+					At:         cycleStart,
+				},
+			})
+			breakdown.skip(CycleEventBreaker, "sharpe gating pause until "+t.PauseUntil.Format(time.RFC3339))
+			m.recordCycleBreakdown(breakdown)
+			return
+		}
+	}
+	// Build richer executor context and refresh performance view.
+	perfView := t.Performance.ToExecutorView()
+	t.Executor.UpdatePerformance(perfView)
+
+	var (
+		dataReadyAt   time.Time
+		submittedAt   time.Time
+		anomalyPaused bool
+		actions       []map[string]any
+	)
+	eng := &engine.Engine{
+		Gatherer: engine.GatherFunc(func(context.Context) (executorpkg.Context, error) {
+			ectx, err := m.buildExecutorContext(t)
+			ectx.TriggerReasons = triggers
+			return ectx, err
+		}),
+		Decider: t.Executor,
+		Planner: engine.PlanFunc(func(_ context.Context, ectx *executorpkg.Context, out *executorpkg.FullDecision) []executorpkg.Decision {
+			return m.planDecisions(breakdown, t, ectx, out, anomalyPaused)
+		}),
+		Reviewer: engine.ReviewFunc(func(ctx context.Context, ectx *executorpkg.Context, d *executorpkg.Decision) error {
+			// Review is the first stage each decision passes, so the order
+			// timeline counts from here.
+			submittedAt = time.Now()
+			if veto := m.reviewOpen(ctx, t, ectx, *d); veto != "" {
+				return errors.New(veto)
+			}
+			return nil
+		}),
+		Submitter: engine.SubmitFunc(func(_ context.Context, d *executorpkg.Decision) error {
+			return m.ExecuteDecision(t, d)
+		}),
+		Hooks: engine.Hooks{
+			Gathered: func(ctx context.Context, ectx *executorpkg.Context, err error) {
+				dataReadyAt = time.Now()
+				breakdown.recordData(ectx, dataReadyAt.Sub(cycleStart))
+				m.recordDataQuality(breakdown)
+				if !t.DataQualityInPrompt {
+					ectx.DataQuality = nil
 				}
-				// Build richer executor context and refresh performance view.
-				perfView := t.Performance.ToExecutorView()
-				t.Executor.UpdatePerformance(perfView)
-
-				ectx := m.buildExecutorContext(t)
-				out, decisionErr := t.Executor.GetFullDecision(&ectx)
-				// NOTE: BasicExecutor will still return a FullDecision even when validation fails (decisionErr != nil),
-				// so call sites must treat decisionErr as authoritative and avoid executing the payload until it passes.
-
-				// Prepare journaling containers
-				var decisionsJSON string
-				var actions []map[string]any
-				allOK := true
-				decisionCount := 0
+				m.emitPipeline(breakdown, PipelineStageData, PipelineLevelInfo, fmt.Sprintf("market data ready for %d symbols", breakdown.Data.Symbols), map[string]any{
+					"fetch_ms":            breakdown.Data.FetchMs,
+					"unavailable_symbols": breakdown.Data.UnavailableSymbols,
+				})
+				if err != nil {
+					return
+				}
+				if m.featureEnabled(t, FlagAdvisorNotes) {
+					ectx.AdvisorNotes = t.Advisors.Advise(ctx, ectx)
+				}
+				m.emitPipeline(breakdown, PipelineStageThinking, PipelineLevelInfo, "prompt sent to model", nil)
+			},
+			Decided: func(ctx context.Context, ectx *executorpkg.Context, out *executorpkg.FullDecision, err error) {
+				breakdown.recordDecision(out, err, dataReadyAt)
 				if out != nil {
-					// decisionErr can be non-nil here; when wiring retries/guards make sure we don't execute decisions that failed validation.
-					decisionCount = len(out.Decisions)
-					if b, e := json.Marshal(out.Decisions); e == nil {
-						decisionsJSON = string(b)
-					}
-					// Close actions first, then open actions; cap new opens by remaining slots.
-					decisions := sortDecisionsCloseFirst(out.Decisions)
-					// remaining slots by max positions
-					remaining := t.RiskParams.MaxPositions - len(ectx.Positions)
-					if remaining < 0 {
-						remaining = 0
-					}
-					// also enforce per-cycle cap if configured (>0)
-					cycleCap := t.ExecGuards.MaxNewPositionsPerCycle
-					if cycleCap > 0 && cycleCap < remaining {
-						remaining = cycleCap
-					}
-					decisions = capNewOpenDecisions(decisions, remaining)
-					for i := range decisions {
-						d := decisions[i]
-						execErr := m.ExecuteDecision(t, &d)
-						act := map[string]any{
-							"symbol":            d.Symbol,
-							"action":            d.Action,
-							"leverage":          d.Leverage,
-							"position_size_usd": d.PositionSizeUSD,
-							"entry_price":       d.EntryPrice,
-							"stop_loss":         d.StopLoss,
-							"take_profit":       d.TakeProfit,
-							"confidence":        d.Confidence,
-							"result":            "ok",
-						}
-						if execErr != nil {
-							act["result"] = "error"
-							act["error"] = execErr.Error()
-							allOK = false
-							logx.WithContext(ctx).Errorf("manager: trader %s decision action=%s symbol=%s error=%v", t.ID, d.Action, d.Symbol, execErr)
-						}
-						actions = append(actions, act)
-					}
-				} else {
-					allOK = false
-					if decisionErr != nil {
-						logx.WithContext(ctx).Errorf("manager: trader %s decision generation failed: %v", t.ID, decisionErr)
-					}
+					t.setCycleOutput(breakdown.Prompt.Digest, out.PromptVersion, out.RawResponse)
 				}
-
-				// Update lightweight performance snapshot (success ratio proxy)
-				if t.Performance == nil {
-					t.Performance = &PerformanceMetrics{}
+				m.emitDecisionEvents(breakdown, out, err)
+				m.recordTimeline(decisionTimeline(breakdown, time.Now())...)
+				if out != nil && breakdown.LLM.Error == "" {
+					t.RecordLLMSuccess(time.Now())
 				}
-				succ := 0
-				for _, a := range actions {
-					if a["result"] == "ok" {
-						succ++
-					}
+				m.recordFactChecks(t, out, time.Now())
+				if out != nil {
+					m.observeRollout(ctx, t, out, err, ectx.Account.TotalEquity, time.Now())
 				}
-				total := len(actions)
-				t.Performance.TotalTrades += total
-				if total > 0 {
-					t.Performance.WinRate = float64(succ) / float64(total)
+				anomalyPaused = m.checkOutputAnomalies(ctx, t, out, time.Now())
+			},
+			Executed: func(ctx context.Context, d executorpkg.Decision, execErr error) {
+				breakdown.recordExecution(d, execErr)
+				m.emitExecutionEvent(breakdown, d, execErr)
+				if isTradeAction(d.Action) {
+					m.recordTimeline(orderTimelineEntry(breakdown, d, execErr, submittedAt))
 				}
-				t.Performance.UpdatedAt = time.Now()
-				m.recordAnalytics(AnalyticsSnapshot{
-					TraderID:       t.ID,
-					TotalPnLUSD:    t.Performance.TotalPnLUSD,
-					TotalPnLPct:    t.Performance.TotalPnLPct,
-					SharpeRatio:    t.Performance.SharpeRatio,
-					WinRate:        t.Performance.WinRate,
-					TotalTrades:    t.Performance.TotalTrades,
-					MaxDrawdownPct: t.Performance.MaxDrawdownPct,
-					UpdatedAt:      t.Performance.UpdatedAt,
-				})
-
-				// Journal the cycle if configured
-				if t.Journal != nil && t.JournalEnabled {
-					if jErr := m.writeJournalRecord(t, &ectx, out, decisionsJSON, actions, decisionErr, allOK); jErr != nil {
-						logx.WithContext(ctx).Errorf("manager: trader %s journal write failed: %v", t.ID, jErr)
-					} else {
-						logx.WithContext(ctx).Infof("manager: trader %s journal written prompt_digest=%s", t.ID, outPromptDigest(out))
-					}
+				if execErr == nil && isOpenAction(d.Action) {
+					t.RecordOpen(time.Now())
 				}
-				t.RecordDecision(time.Now())
-				m.persistRuntimeState(ctx, t)
-				if syncErr := m.SyncTraderPositions(t.ID); syncErr != nil {
-					logx.WithContext(ctx).Errorf("manager: trader %s sync positions error: %v", t.ID, syncErr)
+				if execErr == nil && isTradeAction(d.Action) {
+					t.RecordOrder(time.Now())
+					m.sendAlert(ctx, tradeExecutedAlert(t.ID, d))
 				}
-				logx.WithContext(ctx).Infof("manager: cycle trader=%s decisions=%d actions=%d ok=%t duration=%s", t.ID, decisionCount, len(actions), allOK && decisionErr == nil, time.Since(cycleStart).String())
-			}
+				act := map[string]any{
+					"symbol":            d.Symbol,
+					"action":            d.Action,
+					"leverage":          d.Leverage,
+					"position_size_usd": d.PositionSizeUSD,
+					"entry_price":       d.EntryPrice,
+					"stop_loss":         d.StopLoss,
+					"take_profit":       d.TakeProfit,
+					"confidence":        d.Confidence,
+					"result":            "ok",
+				}
+				if execErr != nil {
+					act["result"] = "error"
+					act["error"] = execErr.Error()
+					logx.WithContext(ctx).Errorf("manager: trader %s decision action=%s symbol=%s error=%v", t.ID, d.Action, d.Symbol, execErr)
+				}
+				actions = append(actions, act)
+			},
+		},
+	}
+	res, err := eng.Step(ctx)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("manager: trader %s cycle not run: %v", t.ID, err)
+		return
+	}
+	if res.GatherErr != nil {
+		// Partial-data abort: skip this cycle and retry on the next interval.
+		logx.WithContext(ctx).Slowf("manager: trader %s cycle skipped: %v", t.ID, res.GatherErr)
+		breakdown.skip(CycleEventStaleData, res.GatherErr.Error())
+		m.recordCycleBreakdown(breakdown)
+		t.RecordDecision(time.Now())
+		return
+	}
+	ectx, out, decisionErr := res.Context, res.Output, res.DecisionErr
+
+	// Prepare journaling containers
+	var decisionsJSON string
+	decisionCount := 0
+	allOK := res.OK()
+	if out != nil {
+		decisionCount = len(out.Decisions)
+		if b, e := json.Marshal(out.Decisions); e == nil {
+			decisionsJSON = string(b)
+		}
+	}
+	if decisionErr != nil {
+		// The engine does not execute decisions that failed validation.
+		logx.WithContext(ctx).Errorf("manager: trader %s decision generation failed: %v", t.ID, decisionErr)
+	}
+	if out != nil {
+		// Drift scoring may call a remote embeddings API, so it waits until
+		// the cycle's orders are out rather than delaying them.
+		m.checkPromptDrift(ctx, t, out.UserPrompt, time.Now())
+	}
+	m.runShadow(ctx, t, &ectx, perfView, out)
+
+	// Update lightweight performance snapshot (success ratio proxy)
+	if t.Performance == nil {
+		t.Performance = &PerformanceMetrics{}
+	}
+	succ := 0
+	for _, a := range actions {
+		if a["result"] == "ok" {
+			succ++
+		}
+	}
+	total := len(actions)
+	t.Performance.TotalTrades += total
+	if total > 0 {
+		t.Performance.WinRate = float64(succ) / float64(total)
+	}
+	t.Performance.UpdatedAt = time.Now()
+	m.recordAnalytics(AnalyticsSnapshot{
+		TraderID:       t.ID,
+		TotalPnLUSD:    t.Performance.TotalPnLUSD,
+		TotalPnLPct:    t.Performance.TotalPnLPct,
+		SharpeRatio:    t.Performance.SharpeRatio,
+		WinRate:        t.Performance.WinRate,
+		TotalTrades:    t.Performance.TotalTrades,
+		MaxDrawdownPct: t.Performance.MaxDrawdownPct,
+		UpdatedAt:      t.Performance.UpdatedAt,
+	})
+
+	// Journal the cycle if configured
+	if t.Journal != nil && t.JournalEnabled {
+		if jErr := m.writeJournalRecord(t, &ectx, out, decisionsJSON, actions, decisionErr, allOK); jErr != nil {
+			logx.WithContext(ctx).Errorf("manager: trader %s journal write failed: %v", t.ID, jErr)
+		} else {
+			logx.WithContext(ctx).Infof("manager: trader %s journal written prompt_digest=%s", t.ID, outPromptDigest(out))
 		}
 	}
+	t.RecordDecision(time.Now())
+	m.recordCycleBreakdown(breakdown)
+	m.recordHeartbeat(m.heartbeat(t, time.Now()))
+	m.persistRuntimeState(ctx, t)
+	if syncErr := m.SyncTraderPositions(t.ID); syncErr != nil {
+		logx.WithContext(ctx).Errorf("manager: trader %s sync positions error: %v", t.ID, syncErr)
+	}
+	logx.WithContext(ctx).Infof("manager: cycle trader=%s decisions=%d actions=%d ok=%t duration=%s", t.ID, decisionCount, len(actions), allOK && decisionErr == nil, time.Since(cycleStart).String())
 }
 
 // Stop signals the main loop to exit.
@@ -613,7 +902,11 @@ func (m *Manager) ExecuteDecision(trader *VirtualTrader, decision *executorpkg.D
 		return errors.New("manager: symbol required for trade action")
 	}
 	if isOpen {
-		if err := m.ensureSymbolAvailable(trader, decision.Symbol); err != nil {
+		side := "long"
+		if decision.Action == "open_short" {
+			side = "short"
+		}
+		if err := m.ensureSymbolAvailable(trader, decision.Symbol, side); err != nil {
 			return err
 		}
 		if err := m.SyncTraderPositions(trader.ID); err != nil {
@@ -630,25 +923,35 @@ func (m *Manager) ExecuteDecision(trader *VirtualTrader, decision *executorpkg.D
 	if isClose {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
+		// The pre-close price doubles as the arrival price for execution quality.
 		var closeSnapPrice float64
-		if setter, ok := trader.ExchangeProvider.(interface {
-			SetMarkPrice(context.Context, string, float64) error
-		}); ok {
+		if trader.MarketProvider != nil {
 			if snap, err := trader.MarketProvider.Snapshot(ctx, decision.Symbol); err == nil && snap != nil && snap.Price.Last > 0 {
 				closeSnapPrice = snap.Price.Last
-				_ = setter.SetMarkPrice(ctx, decision.Symbol, snap.Price.Last)
+				if setter, ok := trader.ExchangeProvider.(interface {
+					SetMarkPrice(context.Context, string, float64) error
+				}); ok {
+					_ = setter.SetMarkPrice(ctx, decision.Symbol, closeSnapPrice)
+				}
 			}
 		}
-		// Attempt to cancel resting orders via optional extension
+		// Attempt to cancel resting orders via optional extension, unless
+		// they may protect the other leg of a hedged symbol.
+		long, short := trader.heldSides(decision.Symbol)
 		if p, ok := trader.ExchangeProvider.(interface {
 			CancelAllBySymbol(context.Context, string) error
-		}); ok {
+		}); ok && !(long && short) {
 			_ = p.CancelAllBySymbol(ctx, decision.Symbol)
 		}
-		orderResp, err := trader.ExchangeProvider.ClosePosition(ctx, decision.Symbol)
+		ctx, err := m.fenceOrder(ctx, trader)
+		if err != nil {
+			return err
+		}
+		orderResp, err := trader.closeLeg(ctx, decision.Symbol, decision.Action)
 		if err != nil {
 			return err
 		}
+		posKey := trader.positionKey(decision.Symbol, closeSide(decision.Action))
 		logx.Infof("manager: trader %s closed position symbol=%s action=%s", trader.ID, decision.Symbol, decision.Action)
 		// Mark cooldown timestamp on successful close
 		closeTime := time.Now()
@@ -680,17 +983,27 @@ func (m *Manager) ExecuteDecision(trader *VirtualTrader, decision *executorpkg.D
 		if fillQty <= 0 && fillPrice > 0 && decision.PositionSizeUSD > 0 {
 			fillQty = decision.PositionSizeUSD / fillPrice
 		}
+		var closeSlippageBps float64
+		if ok {
+			m.recordExecution(trader, decision, closeSnapPrice, fillPrice, fillQty, closeTime)
+			closeSlippageBps = adverseSlippageBps(decision.Action, closeSnapPrice, fillPrice)
+		}
+		if pnl, ok := trader.closedPnL(posKey, fillPrice); ok {
+			m.trackLossStreak(ctx, trader, decision.Symbol, pnl, closeTime)
+		}
 		m.recordPositionEvent(PositionEvent{
 			TraderID:         trader.ID,
 			Trader:           trader,
 			Decision:         *decision,
 			Event:            PositionEventClose,
 			ExchangeResponse: orderResp,
+			ArrivalPrice:     closeSnapPrice,
 			FillPrice:        fillPrice,
 			FillSize:         fillQty,
+			SlippageBps:      closeSlippageBps,
 			OccurredAt:       time.Now(),
 		})
-		m.releaseVirtualPosition(trader.ID, decision.Symbol)
+		m.releaseVirtualPosition(trader.ID, posKey)
 		return nil
 	}
 
@@ -709,14 +1022,18 @@ func (m *Manager) ExecuteDecision(trader *VirtualTrader, decision *executorpkg.D
 		}
 	}
 	decision.Leverage = lev
-	if err := m.enforceSecondaryRisk(trader, decision, lev); err != nil {
-		return err
+	if entry, blocked := m.blacklist.active(decision.Symbol, time.Now()); blocked {
+		return fmt.Errorf("manager: %s blacklisted until %s: %s", entry.Symbol, entry.Until.Format(time.RFC3339), entry.Reason)
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	assetIdx, err := trader.ExchangeProvider.GetAssetIndex(ctx, decision.Symbol)
-	if err == nil && lev > 0 {
-		_ = trader.ExchangeProvider.UpdateLeverage(ctx, assetIdx, true, lev)
+	if trader.RiskParams.Sizing.VolTarget() {
+		if err := m.applyVolTargetSize(ctx, trader, decision); err != nil {
+			return err
+		}
+	}
+	if err := m.enforceSecondaryRisk(trader, decision, lev); err != nil {
+		return err
 	}
 
 	// Determine price: use decision price or query market snapshot.
@@ -731,6 +1048,14 @@ func (m *Manager) ExecuteDecision(trader *VirtualTrader, decision *executorpkg.D
 	if !(price > 0) {
 		return fmt.Errorf("manager: invalid price resolved for %s", decision.Symbol)
 	}
+	if err := m.enforceRiskGuard(ctx, trader, decision, price); err != nil {
+		return err
+	}
+	lev = decision.Leverage
+	assetIdx, err := trader.ExchangeProvider.GetAssetIndex(ctx, decision.Symbol)
+	if err == nil && lev > 0 {
+		_ = trader.ExchangeProvider.UpdateLeverage(ctx, assetIdx, trader.MarginMode.IsCross(), lev)
+	}
 
 	// Compute size and direction.
 	if setter, ok := trader.ExchangeProvider.(interface {
@@ -745,12 +1070,24 @@ func (m *Manager) ExecuteDecision(trader *VirtualTrader, decision *executorpkg.D
 	if qty <= 0 || math.IsNaN(qty) || math.IsInf(qty, 0) {
 		return fmt.Errorf("manager: invalid position size for %s: qty=%.6f", decision.Symbol, qty)
 	}
+	if err := m.simulateOpen(ctx, trader, decision, qty, lev); err != nil {
+		return err
+	}
 	isBuy := decision.Action == "open_long"
 	priceStr := fmt.Sprintf("%.8f", price)
 	sizeStr := fmt.Sprintf("%.8f", qty)
 	var orderResp *exchange.OrderResponse
+	ctx, err = m.fenceOrder(ctx, trader)
+	if err != nil {
+		return err
+	}
 
-	switch trader.OrderStyle {
+	style := trader.OrderStyle
+	if style == OrderStyleMarketIOC && !m.featureEnabled(trader, FlagMarketIOCOrders) {
+		logx.WithContext(ctx).Infof("manager: trader %s market_ioc orders disabled by flag %s, using limit_ioc", trader.ID, FlagMarketIOCOrders)
+		style = OrderStyleLimitIOC
+	}
+	switch style {
 	case OrderStyleMarketIOC:
 		slippage := trader.MarketIOCSlippageBps / 10000.0
 		if slippage <= 0 {
@@ -832,12 +1169,14 @@ func (m *Manager) ExecuteDecision(trader *VirtualTrader, decision *executorpkg.D
 	}
 	fillPrice := price
 	fillQty := qty
+	filled := false
 	if fp, fq, ok := parseOrderFill(orderResp); ok {
 		if fq > 0 {
 			fillQty = fq
 		}
 		if fp > 0 {
 			fillPrice = fp
+			filled = true
 		}
 	}
 	if fillQty <= 0 {
@@ -846,14 +1185,23 @@ func (m *Manager) ExecuteDecision(trader *VirtualTrader, decision *executorpkg.D
 	if fillPrice <= 0 {
 		fillPrice = price
 	}
+	// price is the arrival price: the decision or snapshot price the order
+	// was prepared against.
+	var openSlippageBps float64
+	if filled {
+		m.recordExecution(trader, decision, price, fillPrice, fillQty, time.Now())
+		openSlippageBps = adverseSlippageBps(decision.Action, price, fillPrice)
+	}
 	m.recordPositionEvent(PositionEvent{
 		TraderID:         trader.ID,
 		Trader:           trader,
 		Decision:         *decision,
 		Event:            PositionEventOpen,
 		ExchangeResponse: orderResp,
+		ArrivalPrice:     price,
 		FillPrice:        fillPrice,
 		FillSize:         fillQty,
+		SlippageBps:      openSlippageBps,
 		OccurredAt:       time.Now(),
 	})
 	virtualSide := "long"
@@ -866,6 +1214,7 @@ func (m *Manager) ExecuteDecision(trader *VirtualTrader, decision *executorpkg.D
 			Side:        virtualSide,
 			Quantity:    fillQty,
 			EntryPrice:  fillPrice,
+			StopLoss:    decision.StopLoss,
 			NotionalUSD: fillQty * fillPrice,
 			Leverage:    lev,
 		}
@@ -917,6 +1266,7 @@ func (m *Manager) SyncTraderPositions(traderID string) error {
 	t.ResourceAlloc.MarginUsedUSD = marginUsed
 	t.ResourceAlloc.UnrealizedPnLUSD = unreal
 	t.ResourceAlloc.AvailableBalanceUSD = math.Max(0, acctVal-marginUsed)
+	t.trackEquityPeak(acctVal)
 	t.UpdatedAt = time.Now()
 	t.mu.Unlock()
 	logx.Infof("manager: trader %s equity=%.2f usd margin_used=%.2f usd avail=%.2f usd unreal_pnl=%.2f usd", traderID, acctVal, marginUsed, t.ResourceAlloc.AvailableBalanceUSD, unreal)
@@ -983,7 +1333,7 @@ func normalizeSymbol(symbol string) string {
 	return strings.ToUpper(strings.TrimSpace(symbol))
 }
 
-func (m *Manager) ensureSymbolAvailable(trader *VirtualTrader, symbol string) error {
+func (m *Manager) ensureSymbolAvailable(trader *VirtualTrader, symbol, side string) error {
 	if trader == nil {
 		return errors.New("manager: trader is nil")
 	}
@@ -996,7 +1346,18 @@ func (m *Manager) ensureSymbolAvailable(trader *VirtualTrader, symbol string) er
 		return nil
 	}
 	if owner == trader.ID {
-		return fmt.Errorf("manager: trader %s already controls %s", trader.ID, symbol)
+		long, short := trader.heldSides(symbol)
+		held := long
+		if side == "short" {
+			held = short
+		}
+		if !trader.PositionMode.IsHedge() || held {
+			return fmt.Errorf("manager: trader %s already controls %s", trader.ID, symbol)
+		}
+		if !trader.RiskParams.AllowHedge {
+			return fmt.Errorf("manager: trader %s hedging %s not allowed", trader.ID, symbol)
+		}
+		return nil
 	}
 	return fmt.Errorf("manager: symbol %s currently assigned to trader %s", symbol, owner)
 }
@@ -1038,10 +1399,11 @@ func (m *Manager) assignVirtualPosition(trader *VirtualTrader, pos VirtualPositi
 	if m == nil || trader == nil {
 		return errors.New("manager: assign virtual position missing trader")
 	}
-	key := normalizeSymbol(pos.Symbol)
-	if key == "" {
+	symbol := normalizeSymbol(pos.Symbol)
+	if symbol == "" {
 		return errors.New("manager: assign virtual position requires symbol")
 	}
+	key := trader.positionKey(pos.Symbol, pos.Side)
 	now := time.Now()
 	if pos.OpenedAt.IsZero() {
 		pos.OpenedAt = now
@@ -1049,7 +1411,7 @@ func (m *Manager) assignVirtualPosition(trader *VirtualTrader, pos VirtualPositi
 	pos.UpdatedAt = now
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	if owner, ok := m.positionOwners[key]; ok && owner != trader.ID {
+	if owner, ok := m.positionOwners[symbol]; ok && owner != trader.ID {
 		return fmt.Errorf("manager: symbol %s already assigned to trader %s", pos.Symbol, owner)
 	}
 	trader.mu.Lock()
@@ -1058,33 +1420,42 @@ func (m *Manager) assignVirtualPosition(trader *VirtualTrader, pos VirtualPositi
 	}
 	trader.VirtualPositions[key] = pos
 	trader.mu.Unlock()
-	m.positionOwners[key] = trader.ID
+	m.positionOwners[symbol] = trader.ID
 	return nil
 }
 
-func (m *Manager) releaseVirtualPosition(traderID, symbol string) {
+// releaseVirtualPosition drops the trader's position under key (see
+// positionKey) and gives up the symbol once no leg of it remains.
+func (m *Manager) releaseVirtualPosition(traderID, key string) {
 	if m == nil {
 		return
 	}
-	key := normalizeSymbol(symbol)
+	key = normalizeSymbol(key)
 	if key == "" {
 		return
 	}
+	symbol := keySymbol(key)
 	m.mu.Lock()
 	trader := m.traders[traderID]
-	owner := m.positionOwners[key]
+	owner := m.positionOwners[symbol]
 	if owner != "" && owner != traderID {
 		m.mu.Unlock()
 		return
 	}
-	delete(m.positionOwners, key)
 	m.mu.Unlock()
-	if trader == nil {
-		return
+	if trader != nil {
+		trader.mu.Lock()
+		delete(trader.VirtualPositions, key)
+		trader.mu.Unlock()
+		if long, short := trader.heldSides(symbol); long || short {
+			return
+		}
 	}
-	trader.mu.Lock()
-	delete(trader.VirtualPositions, key)
-	trader.mu.Unlock()
+	m.mu.Lock()
+	if m.positionOwners[symbol] == traderID || m.positionOwners[symbol] == "" {
+		delete(m.positionOwners, symbol)
+	}
+	m.mu.Unlock()
 }
 
 func (m *Manager) releaseAllVirtualPositions(trader *VirtualTrader) {
@@ -1207,18 +1578,50 @@ func (m *Manager) ReconcileTraderPositions(ctx context.Context, traderID string)
 	if err != nil {
 		return fmt.Errorf("manager: fetch positions for trader %s: %w", traderID, err)
 	}
+	// Keyed as virtual positions are, so each leg of a hedged symbol is
+	// reconciled on its own.
 	actual := make(map[string]exchange.Position, len(positionsRaw))
 	for _, p := range positionsRaw {
-		if qty := parseFloat(p.Szi); qty != 0 {
-			actual[normalizeSymbol(p.Coin)] = p
+		if qty := parseFloat(p.Szi); qty > 0 {
+			actual[trader.positionKey(p.Coin, "long")] = p
+		} else if qty < 0 {
+			actual[trader.positionKey(p.Coin, "short")] = p
 		}
 	}
 	virtual := m.snapshotVirtualPositions(trader)
-	for sym, v := range virtual {
-		p, ok := actual[sym]
+	for key, v := range virtual {
+		sym := normalizeSymbol(v.Symbol)
+		p, ok := actual[key]
 		if !ok {
-			logx.Slowf("manager: trader %s virtual position %s missing on exchange; releasing", trader.ID, sym)
-			m.releaseVirtualPosition(trader.ID, sym)
+			logx.Slowf("manager: trader %s virtual position %s missing on exchange; releasing", trader.ID, key)
+			m.trackExchangeClose(ctx, trader, sym, key)
+			m.releaseVirtualPosition(trader.ID, key)
+			m.recordTimeline(TimelineEntry{
+				TraderID: trader.ID,
+				Kind:     TimelineExit,
+				Symbol:   sym,
+				Summary:  fmt.Sprintf("%s %s closed on exchange (stop or take-profit)", sym, v.Side),
+				Detail: map[string]any{
+					"side":        v.Side,
+					"quantity":    v.Quantity,
+					"entry_price": v.EntryPrice,
+					"reason":      "exchange_close",
+				},
+				At: time.Now(),
+			})
+			m.sendAlert(ctx, Alert{
+				Kind:     AlertStopHit,
+				TraderID: trader.ID,
+				Message:  fmt.Sprintf("trader %s %s %s position closed on exchange (stop or take-profit)", trader.ID, sym, v.Side),
+				Data: StopHitAlert{
+					TraderID:   trader.ID,
+					Symbol:     sym,
+					Side:       v.Side,
+					Quantity:   v.Quantity,
+					EntryPrice: v.EntryPrice,
+					At:         time.Now(),
+				},
+			})
 			continue
 		}
 		vp, _ := exchangePositionToVirtual(p)
@@ -1229,7 +1632,8 @@ func (m *Manager) ReconcileTraderPositions(ctx context.Context, traderID string)
 			return fmt.Errorf("manager: position mismatch %s (virtual %.6f %s vs exchange %.6f %s)", sym, v.Quantity, v.Side, vp.Quantity, vp.Side)
 		}
 	}
-	for sym, p := range actual {
+	for key, p := range actual {
+		sym := normalizeSymbol(p.Coin)
 		owner := m.getPositionOwner(sym)
 		vp, ok := exchangePositionToVirtual(p)
 		if !ok {
@@ -1246,7 +1650,7 @@ func (m *Manager) ReconcileTraderPositions(ctx context.Context, traderID string)
 			}
 			continue
 		}
-		if _, ok := virtual[sym]; !ok {
+		if _, ok := virtual[key]; !ok {
 			if err := m.assignVirtualPosition(trader, vp); err != nil {
 				return err
 			}
@@ -1291,7 +1695,10 @@ func (m *Manager) enforceSecondaryRisk(trader *VirtualTrader, decision *executor
 		return errors.New("manager: missing inputs for risk check")
 	}
 	rp := trader.RiskParams
-	if rp.MaxPositionSizeUSD > 0 && decision.PositionSizeUSD > rp.MaxPositionSizeUSD+1e-6 {
+	if _, scale := trader.riskScale(); rp.MaxPositionSizeUSD > 0 && decision.PositionSizeUSD > rp.MaxPositionSizeUSD*scale+1e-6 {
+		if scale < 1 {
+			return fmt.Errorf("manager: decision size %.2f exceeds drawdown-scaled max_position_size_usd %.2f (x%.2f)", decision.PositionSizeUSD, rp.MaxPositionSizeUSD*scale, scale)
+		}
 		return fmt.Errorf("manager: decision size %.2f exceeds max_position_size_usd %.2f", decision.PositionSizeUSD, rp.MaxPositionSizeUSD)
 	}
 	if rp.MaxMarginUsagePct > 0 && leverage > 0 {
@@ -1320,6 +1727,10 @@ func (m *Manager) recordPositionEvent(event PositionEvent) {
 	if event.OccurredAt.IsZero() {
 		event.OccurredAt = time.Now()
 	}
+	if event.TraceID == "" {
+		event.TraceID = event.Trader.cycleTrace()
+		event.PromptDigest, event.PromptVersion, event.RawResponse = event.Trader.cycleOutput()
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 	err := m.persistence.RecordPositionEvent(ctx, event)
@@ -1328,6 +1739,7 @@ func (m *Manager) recordPositionEvent(event PositionEvent) {
 		"symbol":    event.Decision.Symbol,
 		"event":     event.Event,
 	})
+	m.recordTimeline(positionTimelineEntry(event, event.TraceID))
 }
 
 func (m *Manager) recordDecisionCycle(record DecisionCycleRecord) {
@@ -1432,8 +1844,12 @@ func (m *Manager) writeJournalRecord(t *VirtualTrader, ectx *executorpkg.Context
 
 	cot := ""
 	promptDigest := ""
+	promptVersion := ""
+	response := ""
 	if out != nil {
 		cot = out.CoTTrace
+		promptVersion = out.PromptVersion
+		response = out.RawResponse
 		if s := strings.TrimSpace(out.UserPrompt); s != "" {
 			promptDigest = llm.DigestString(s)
 		}
@@ -1447,7 +1863,9 @@ func (m *Manager) writeJournalRecord(t *VirtualTrader, ectx *executorpkg.Context
 		TraderID:      t.ID,
 		ConfigVersion: t.ConfigVersion,
 		PromptDigest:  promptDigest,
+		PromptVersion: promptVersion,
 		CoTTrace:      cot,
+		Response:      response,
 		DecisionsJSON: decisionsJSON,
 		Account:       acc,
 		Positions:     pos,
@@ -1459,6 +1877,15 @@ func (m *Manager) writeJournalRecord(t *VirtualTrader, ectx *executorpkg.Context
 	if callErr != nil {
 		rec.ErrorMessage = callErr.Error()
 	}
+	if out != nil && out.PromptExperiment != "" {
+		rec.Extra = map[string]interface{}{"prompt_experiment": out.PromptExperiment}
+	}
+	if out != nil && out.PromptRollout != "" {
+		if rec.Extra == nil {
+			rec.Extra = map[string]interface{}{}
+		}
+		rec.Extra["prompt_rollout"] = out.PromptRollout
+	}
 	var err error
 	if t.Journal != nil {
 		_, err = t.Journal.WriteCycle(rec)
@@ -1483,14 +1910,18 @@ func buildCloid(traderID, symbol, action string, qty float64, now time.Time) str
 }
 
 // buildExecutorContext collects a richer snapshot for the executor prompt and validation.
-func (m *Manager) buildExecutorContext(t *VirtualTrader) executorpkg.Context {
+// It returns errPartialMarketData when symbols fail to load under the abort policy.
+func (m *Manager) buildExecutorContext(t *VirtualTrader) (executorpkg.Context, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// 1) Account and positions
+	// 1) Account and positions, from one account read so a fill between two
+	// calls cannot leave equity and positions disagreeing.
 	acctState, _ := t.ExchangeProvider.GetAccountState(ctx)
-	positionsRaw, _ := t.ExchangeProvider.GetPositions(ctx)
-	positionsRaw = m.filterPositionsForTrader(t.ID, positionsRaw)
+	var positionsRaw []exchange.Position
+	if acctState != nil {
+		positionsRaw = m.filterPositionsForTrader(t.ID, acctState.AssetPositions)
+	}
 
 	// Normalize account info
 	account := executorpkg.AccountInfo{}
@@ -1526,6 +1957,7 @@ func (m *Manager) buildExecutorContext(t *VirtualTrader) executorpkg.Context {
 			MarkPrice:        0,
 			Quantity:         qty,
 			Leverage:         p.Leverage.Value,
+			MarginMode:       p.Leverage.Type,
 			UnrealizedPnL:    parseFloat(p.UnrealizedPnl),
 			LiquidationPrice: parsePtrFloat(p.LiquidationPx),
 		})
@@ -1535,32 +1967,38 @@ func (m *Manager) buildExecutorContext(t *VirtualTrader) executorpkg.Context {
 
 	// 2) Candidate set (basic Top-N by |1h change|) and market snapshots
 	candidates := m.selectCandidates(ctx, t, 0)
-	snaps := map[string]*market.Snapshot{}
+	// Positions first (sorted), then candidates in rank order, fetched in parallel.
+	wanted := make([]string, 0, len(symbols)+len(candidates))
 	for sym := range symbols {
-		if s, err := t.MarketProvider.Snapshot(ctx, sym); err == nil && s != nil {
-			snaps[sym] = s
-		}
+		wanted = append(wanted, sym)
 	}
-	// Snapshots for candidates
+	sort.Strings(wanted)
 	for _, c := range candidates {
-		if _, ok := snaps[c.Symbol]; ok { // already fetched
-			continue
-		}
-		if s, err := t.MarketProvider.Snapshot(ctx, c.Symbol); err == nil && s != nil {
-			snaps[c.Symbol] = s
+		if _, ok := symbols[c.Symbol]; !ok {
+			wanted = append(wanted, c.Symbol)
 		}
 	}
-
-	// Second pass: enrich mark price and pnl pct from snapshots
-	for i := range positions {
-		pi := &positions[i]
-		if pi.EntryPrice > 0 {
-			if s, ok := snaps[pi.Symbol]; ok && s != nil && s.Price.Last > 0 {
-				pi.MarkPrice = s.Price.Last
-				pi.UnrealizedPnLPct = 100 * (pi.MarkPrice - pi.EntryPrice) / pi.EntryPrice
-			}
+	concurrency, timeout := m.snapshotSettings()
+	snaps := map[string]*market.Snapshot{}
+	var unavailable []string
+	for _, res := range fetchSnapshots(ctx, t.MarketProvider, wanted, concurrency, timeout) {
+		if res.Err != nil {
+			unavailable = append(unavailable, res.Symbol)
+			logx.WithContext(ctx).Slowf("manager: trader %s snapshot unavailable symbol=%s err=%v", t.ID, res.Symbol, res.Err)
+			m.noteSymbolAnomaly(ctx, t, res.Symbol, "feed error: "+res.Err.Error())
+			continue
 		}
+		snaps[res.Symbol] = res.Snapshot
+	}
+	if len(unavailable) > 0 && t.PartialDataPolicy == PartialDataAbort {
+		return executorpkg.Context{}, fmt.Errorf("%w: %s", errPartialMarketData, strings.Join(unavailable, ","))
 	}
+	if t.PartialDataPolicy != PartialDataAnnotate {
+		unavailable = nil
+	}
+
+	// Second pass: mark positions and the account at the snapshot prices
+	markToSnapshots(&account, positions, snaps)
 
 	// 3) Asset meta (max leverage, precision) for present symbols
 	assetMeta := map[string]executorpkg.AssetMeta{}
@@ -1590,8 +2028,17 @@ func (m *Manager) buildExecutorContext(t *VirtualTrader) executorpkg.Context {
 		}
 	}
 
+	regime := m.updateRegime(ctx, t, snaps)
+	t.resetTriggerBaselines(snaps)
+
 	// 4) Compose executor context
-	return executorpkg.Context{
+	m.applyAccountMetrics(ctx, t)
+	drawdownPct, riskScale := t.riskScale()
+	var observedSlippage map[string]executorpkg.SlippageStat
+	if m.featureEnabled(t, FlagObservedSlippage) {
+		observedSlippage = m.observedSlippage(t.Model)
+	}
+	ectx := executorpkg.Context{
 		CurrentTime:       time.Now().UTC().Format(time.RFC3339),
 		RuntimeMinutes:    0,
 		CallCount:         0,
@@ -1604,50 +2051,27 @@ func (m *Manager) buildExecutorContext(t *VirtualTrader) executorpkg.Context {
 		MajorCoinLeverage: t.RiskParams.MajorCoinLeverage,
 		AltcoinLeverage:   t.RiskParams.AltcoinLeverage,
 		AssetMeta:         assetMeta,
-		// Optional guards sourced from trader risk params when enabled
-		MaxMarginUsagePct: func() float64 {
-			if t.ExecGuards.EnableMarginUsageGuard == nil || *t.ExecGuards.EnableMarginUsageGuard {
-				return t.RiskParams.MaxMarginUsagePct
-			}
-			return 0
-		}(),
-		LiquidityThresholdUSD: func() float64 {
-			if t.ExecGuards.EnableLiquidityGuard == nil || *t.ExecGuards.EnableLiquidityGuard {
-				return t.ExecGuards.LiquidityThresholdUSD
-			}
-			return 0
-		}(),
-		BTCETHPositionValueMinMultiple: func() float64 {
-			if t.ExecGuards.EnableValueBandGuard == nil || *t.ExecGuards.EnableValueBandGuard {
-				return t.ExecGuards.BTCETHMinEquityMultiple
-			}
-			return 0
-		}(),
-		BTCETHPositionValueMaxMultiple: func() float64 {
-			if t.ExecGuards.EnableValueBandGuard == nil || *t.ExecGuards.EnableValueBandGuard {
-				return t.ExecGuards.BTCETHMaxEquityMultiple
-			}
-			return 0
-		}(),
-		AltPositionValueMinMultiple: func() float64 {
-			if t.ExecGuards.EnableValueBandGuard == nil || *t.ExecGuards.EnableValueBandGuard {
-				return t.ExecGuards.AltMinEquityMultiple
-			}
-			return 0
-		}(),
-		AltPositionValueMaxMultiple: func() float64 {
-			if t.ExecGuards.EnableValueBandGuard == nil || *t.ExecGuards.EnableValueBandGuard {
-				return t.ExecGuards.AltMaxEquityMultiple
-			}
-			return 0
-		}(),
-		CooldownAfterClose: func() time.Duration {
-			if t.ExecGuards.EnableCooldownGuard == nil || *t.ExecGuards.EnableCooldownGuard {
-				return t.ExecGuards.CooldownAfterClose
-			}
-			return 0
-		}(),
-	}
+		// Per-trade caps shrink with drawdown from peak equity.
+		MaxRiskPct:         t.RiskParams.MaxRiskPerTradePct * riskScale,
+		MaxPositionSizeUSD: t.RiskParams.MaxPositionSizeUSD * riskScale,
+		DrawdownPct:        drawdownPct,
+		RiskScale:          riskScale,
+		SizingMode:         t.RiskParams.Sizing.Mode,
+		Blacklisted:        m.blacklistedUntil(),
+		VolTargetSizes:     t.volTargetSizes(snaps),
+		ObservedSlippage:   observedSlippage,
+		MarketRegime:       regime,
+		DecisionInterval:   t.DecisionInterval,
+		DataQuality:        assessDataQuality(snaps, time.Now()),
+		OpenAllowance:      t.openAllowance(time.Now()),
+		LossStreak:         t.LossStreak,
+		LossStreakLimit:    t.ExecGuards.LossStreakLimit,
+		EntriesPausedUntil: t.EntriesPausedUntil,
+		UnavailableSymbols: unavailable,
+		HedgeMode:          t.PositionMode.IsHedge(),
+	}
+	t.ExecGuards.applyTo(&ectx, t.RiskParams)
+	return ectx, nil
 }
 
 // selectCandidates picks up to limit candidates using a simple heuristic (|1h change| ranking).
@@ -1669,15 +2093,25 @@ func (m *Manager) selectCandidates(ctx context.Context, t *VirtualTrader, limit
 		score float64
 	}
 	ranked := make([]item, 0, limit*3)
-	count := 0
+	active := make([]string, 0, len(assets))
 	for _, a := range assets {
 		if !a.IsActive {
 			continue
 		}
-		s, err := t.MarketProvider.Snapshot(ctx, a.Symbol)
-		if err != nil || s == nil {
+		if _, blocked := m.blacklist.active(a.Symbol, time.Now()); blocked {
 			continue
 		}
+		active = append(active, a.Symbol)
+		if len(active) >= 200 {
+			break
+		}
+	}
+	concurrency, timeout := m.snapshotSettings()
+	for _, res := range fetchSnapshots(ctx, t.MarketProvider, active, concurrency, timeout) {
+		if !m.screenSnapshot(ctx, t, res) {
+			continue
+		}
+		s := res.Snapshot
 		// Liquidity threshold if enabled
 		if (t.ExecGuards.EnableLiquidityGuard == nil || *t.ExecGuards.EnableLiquidityGuard) && t.ExecGuards.LiquidityThresholdUSD > 0 {
 			if s.OpenInterest != nil {
@@ -1690,13 +2124,9 @@ func (m *Manager) selectCandidates(ctx context.Context, t *VirtualTrader, limit
 		if score < 0 {
 			score = -score
 		}
-		ranked = append(ranked, item{sym: a.Symbol, score: score})
-		count++
-		if count >= 200 {
-			break
-		}
+		ranked = append(ranked, item{sym: res.Symbol, score: score})
 	}
-	sort.Slice(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
 	if len(ranked) > limit {
 		ranked = ranked[:limit]
 	}
@@ -1707,30 +2137,34 @@ func (m *Manager) selectCandidates(ctx context.Context, t *VirtualTrader, limit
 	return out
 }
 
-// sortDecisionsCloseFirst returns decisions ordered by priority: close_* first, then open_*.
-func sortDecisionsCloseFirst(ds []executorpkg.Decision) []executorpkg.Decision {
-	out := make([]executorpkg.Decision, len(ds))
-	copy(out, ds)
-	sort.SliceStable(out, func(i, j int) bool {
-		pri := priority(out[i].Action)
-		prj := priority(out[j].Action)
-		if pri != prj {
-			return pri < prj
-		}
-		return out[i].Symbol < out[j].Symbol
-	})
-	return out
-}
-
-func priority(action string) int {
-	switch action {
-	case "close_long", "close_short":
-		return 0
-	case "open_long", "open_short":
-		return 1
-	default:
-		return 2
-	}
+// planDecisions orders out's decisions closes first, applies the prompt
+// rollout and drops opens beyond the trader's free position slots, per-cycle
+// cap and open allowance, or all opens while entries are paused.
+func (m *Manager) planDecisions(breakdown *CycleBreakdown, t *VirtualTrader, ectx *executorpkg.Context, out *executorpkg.FullDecision, anomalyPaused bool) []executorpkg.Decision {
+	decisions := m.rolloutDecisions(breakdown, out, engine.CloseFirst(out.Decisions))
+	// remaining slots by max positions
+	remaining := t.RiskParams.MaxPositions - len(ectx.Positions)
+	if remaining < 0 {
+		remaining = 0
+	}
+	// also enforce per-cycle cap if configured (>0)
+	cycleCap := t.ExecGuards.MaxNewPositionsPerCycle
+	if cycleCap > 0 && cycleCap < remaining {
+		remaining = cycleCap
+	}
+	if left := ectx.OpenAllowance.Remaining(); left >= 0 && left < remaining {
+		remaining = left
+	}
+	if t.entriesPaused(time.Now()) || anomalyPaused {
+		remaining = 0
+	}
+	capped := capNewOpenDecisions(decisions, remaining)
+	breakdown.Risk.CappedOpens = len(decisions) - len(capped)
+	if breakdown.Risk.CappedOpens > 0 {
+		m.emitPipeline(breakdown, PipelineStageDeciding, PipelineLevelInfo, fmt.Sprintf("dropped %d open decisions: no free position slots or open allowance", breakdown.Risk.CappedOpens), nil)
+	}
+	m.recordTimeline(riskTimelineEntry(breakdown, time.Now()))
+	return capped
 }
 
 // capNewOpenDecisions limits the number of new open actions to remainingSlots; non-open actions are kept.
@@ -1758,3 +2192,12 @@ func capNewOpenDecisions(ds []executorpkg.Decision, remainingSlots int) []execut
 	}
 	return out
 }
+
+// reviewOpen asks the trader's advisors whether an open may proceed and
+// returns the veto reason, or "" to execute.
+func (m *Manager) reviewOpen(ctx context.Context, t *VirtualTrader, ectx *executorpkg.Context, d executorpkg.Decision) string {
+	if !isOpenAction(d.Action) {
+		return ""
+	}
+	return t.Advisors.Review(ctx, ectx, d)
+}
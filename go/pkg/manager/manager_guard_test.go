@@ -2,6 +2,7 @@ package manager
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -29,6 +30,33 @@ func TestManagerAssignReleaseVirtualPosition(t *testing.T) {
 	require.False(t, exists)
 }
 
+func TestManagerHedgeModeKeepsLegsApart(t *testing.T) {
+	m := &Manager{
+		traders:        make(map[string]*VirtualTrader),
+		positionOwners: make(map[string]string),
+	}
+	trader := &VirtualTrader{ID: "t1", PositionMode: exchange.PositionModeHedge, VirtualPositions: make(map[string]VirtualPosition)}
+	m.traders[trader.ID] = trader
+
+	require.NoError(t, m.assignVirtualPosition(trader, VirtualPosition{Symbol: "BTC", Side: "long", Quantity: 1, EntryPrice: 100}))
+	require.EqualError(t, m.ensureSymbolAvailable(trader, "BTC", "short"), "manager: trader t1 hedging BTC not allowed")
+	trader.RiskParams.AllowHedge = true
+	require.NoError(t, m.ensureSymbolAvailable(trader, "BTC", "short"))
+	require.Error(t, m.ensureSymbolAvailable(trader, "BTC", "long"), "no adding to a held leg")
+
+	require.NoError(t, m.assignVirtualPosition(trader, VirtualPosition{Symbol: "BTC", Side: "short", Quantity: 2, EntryPrice: 100}))
+	require.Len(t, m.snapshotVirtualPositions(trader), 2)
+
+	m.releaseVirtualPosition(trader.ID, trader.positionKey("BTC", "long"))
+	require.Equal(t, "t1", m.getPositionOwner("BTC"), "short leg still held")
+	pnl, ok := trader.closedPnL(trader.positionKey("btc", "short"), 90)
+	require.True(t, ok)
+	require.InDelta(t, 20, pnl, 1e-9)
+
+	m.releaseVirtualPosition(trader.ID, trader.positionKey("BTC", "short"))
+	require.Equal(t, "", m.getPositionOwner("BTC"))
+}
+
 func TestManagerEnforceSecondaryRisk(t *testing.T) {
 	m := &Manager{}
 	trader := &VirtualTrader{
@@ -73,3 +101,25 @@ func TestFilterPositionsForTrader(t *testing.T) {
 	require.Len(t, other, 1, "other trader should not see BTC")
 	require.Equal(t, "ETH", other[0].Coin)
 }
+
+func TestVirtualTraderOpenAllowance(t *testing.T) {
+	trader := &VirtualTrader{ID: "t-throttle"}
+	now := time.Now()
+	require.Nil(t, trader.openAllowance(now), "no throttle configured")
+
+	trader.ExecGuards = ExecGuards{MaxOpensPerHour: 2, MaxOpensPerDay: 3}
+	trader.RecordOpen(now.Add(-25 * time.Hour)) // outside both windows
+	trader.RecordOpen(now.Add(-3 * time.Hour))
+	trader.RecordOpen(now.Add(-10 * time.Minute))
+
+	allowance := trader.openAllowance(now)
+	require.Equal(t, 1, allowance.HourRemaining)
+	require.Equal(t, 1, allowance.DayRemaining)
+	require.Equal(t, 1, allowance.Remaining())
+	require.Len(t, trader.openTimes, 2)
+
+	trader.RecordOpen(now)
+	allowance = trader.openAllowance(now)
+	require.Equal(t, 0, allowance.HourRemaining)
+	require.Equal(t, 0, allowance.DayRemaining)
+}
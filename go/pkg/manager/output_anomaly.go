@@ -0,0 +1,435 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+// AlertOutputAnomaly fires when a model's output looks degenerate.
+const AlertOutputAnomaly = "output_anomaly"
+
+// Output anomaly kinds.
+const (
+	// OutputRepeatedDecision: the same decisions repeat_limit cycles in a row.
+	OutputRepeatedDecision = "repeated_decision"
+	// OutputCopiedJustification: reasoning copied across cycles or symbols.
+	OutputCopiedJustification = "copied_justification"
+	// OutputLanguageSwitch: the reasoning changed writing system.
+	OutputLanguageSwitch = "language_switch"
+	// OutputRefusal: the model refused or disclaimed instead of deciding.
+	OutputRefusal = "refusal"
+)
+
+const (
+	defaultOutputRepeatLimit = 10
+	defaultOutputCopyLimit   = 3
+	outputSampleLimit        = 20
+	outputSampleChars        = 2000
+	// minScriptLetters is the letter count below which text is too short to
+	// tell its writing system.
+	minScriptLetters = 40
+	// minCopiedReasoning keeps short stock phrases ("no setup") from
+	// counting as copied justifications.
+	minCopiedReasoning = 40
+)
+
+var defaultRefusalPatterns = []string{
+	`(?i)\bI(?:'m| am) (?:sorry|unable to)\b`,
+	`(?i)\bI (?:can(?:'|no)t|cannot|won't) (?:help|assist|provide|comply)`,
+	`(?i)\bas an AI\b`,
+	`(?i)\b(?:not|never) (?:able|allowed|permitted) to (?:give|provide|offer) (?:financial|investment|trading) advice`,
+	`抱歉|我无法|作为(?:一个)?(?:AI|人工智能)`,
+}
+
+// outputScripts are the writing systems language switches are judged by.
+var outputScripts = map[string]*unicode.RangeTable{
+	"latin":    unicode.Latin,
+	"han":      unicode.Han,
+	"cyrillic": unicode.Cyrillic,
+	"hangul":   unicode.Hangul,
+	"hiragana": unicode.Hiragana,
+	"katakana": unicode.Katakana,
+	"arabic":   unicode.Arabic,
+}
+
+// OutputAnomalyConfig flags degenerate model output: the same decisions
+// over and over, reasoning pasted between cycles or symbols, a switch of
+// language mid-run, or refusal text.
+type OutputAnomalyConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// RepeatLimit is how many identical decision sets in a row are flagged.
+	RepeatLimit int `yaml:"repeat_limit" json:"repeat_limit"`
+	// CopyLimit is how many cycles in a row with identical reasoning are flagged.
+	CopyLimit int `yaml:"copy_limit" json:"copy_limit"`
+	// Script is the expected writing system of the reasoning (latin, han,
+	// ...); empty takes the first cycle's.
+	Script string `yaml:"script" json:"script"`
+	// RefusalPatterns are regular expressions replacing the built-in
+	// refusal phrases.
+	RefusalPatterns []string `yaml:"refusal_patterns" json:"refusal_patterns"`
+	// PauseFor pauses the trader this long on an anomaly; 0 only flags it.
+	PauseFor time.Duration `yaml:"-" json:"pause_for_duration"`
+	// SampleDir receives flagged outputs as <trader>.jsonl for review.
+	SampleDir string `yaml:"sample_dir" json:"sample_dir"`
+
+	PauseForRaw string `yaml:"pause_for" json:"pause_for"`
+}
+
+func (c *OutputAnomalyConfig) applyDefaults() {
+	if c.RepeatLimit == 0 {
+		c.RepeatLimit = defaultOutputRepeatLimit
+	}
+	if c.CopyLimit == 0 {
+		c.CopyLimit = defaultOutputCopyLimit
+	}
+	c.Script = strings.ToLower(strings.TrimSpace(c.Script))
+	c.SampleDir = strings.TrimSpace(c.SampleDir)
+}
+
+func (c *OutputAnomalyConfig) parseDurations(index int) error {
+	c.PauseFor = 0
+	if strings.TrimSpace(c.PauseForRaw) == "" {
+		return nil
+	}
+	d, err := parsePositiveDuration(fmt.Sprintf("traders[%d].output_anomaly.pause_for", index), c.PauseForRaw)
+	if err != nil {
+		return err
+	}
+	c.PauseFor = d
+	return nil
+}
+
+// Validate checks the limits, script and refusal patterns.
+func (c OutputAnomalyConfig) Validate(index int) error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.RepeatLimit < 2 || c.CopyLimit < 2 {
+		return fmt.Errorf("manager config: traders[%d].output_anomaly repeat_limit and copy_limit must be at least 2", index)
+	}
+	if _, ok := outputScripts[c.Script]; c.Script != "" && !ok {
+		return fmt.Errorf("manager config: traders[%d].output_anomaly.script %q unknown", index, c.Script)
+	}
+	if _, err := c.refusals(); err != nil {
+		return fmt.Errorf("manager config: traders[%d].output_anomaly.refusal_patterns: %w", index, err)
+	}
+	return nil
+}
+
+func (c OutputAnomalyConfig) refusals() ([]*regexp.Regexp, error) {
+	patterns := c.RefusalPatterns
+	if len(patterns) == 0 {
+		patterns = defaultRefusalPatterns
+	}
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// OutputAnomaly is one degenerate trait of a model output.
+type OutputAnomaly struct {
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// OutputAnomalySample is a flagged output kept for review.
+type OutputAnomalySample struct {
+	TraderID  string                 `json:"trader_id"`
+	Model     string                 `json:"model"`
+	Anomalies []OutputAnomaly        `json:"anomalies"`
+	CoTTrace  string                 `json:"cot_trace"`
+	Decisions []executorpkg.Decision `json:"decisions"`
+	At        time.Time              `json:"at"`
+}
+
+// OutputAnomalyAlert is the template data for AlertOutputAnomaly.
+type OutputAnomalyAlert struct {
+	TraderID   string    `doc:"Trader whose model produced the output"`
+	Model      string    `doc:"LLM model that produced the output"`
+	Kind       string    `doc:"repeated_decision, copied_justification, language_switch or refusal"`
+	Detail     string    `doc:"What was detected"`
+	PauseUntil time.Time `doc:"When trading resumes (zero when the trader was only flagged)"`
+	At         time.Time `doc:"Cycle time"`
+}
+
+// outputAnomalyDetector carries the cross-cycle state of one trader's
+// checks. Only the trading loop observes; samples are guarded by the
+// trader's mutex.
+type outputAnomalyDetector struct {
+	cfg      OutputAnomalyConfig
+	refusals []*regexp.Regexp
+
+	lastDecisions string
+	repeats       int
+	lastReasoning string
+	copies        int
+	script        string
+	switched      bool
+
+	samples []OutputAnomalySample
+}
+
+func newOutputAnomalyDetector(cfg OutputAnomalyConfig) (*outputAnomalyDetector, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	refusals, err := cfg.refusals()
+	if err != nil {
+		return nil, err
+	}
+	return &outputAnomalyDetector{cfg: cfg, refusals: refusals, script: cfg.Script}, nil
+}
+
+// observe checks one cycle's output against the previous ones.
+func (d *outputAnomalyDetector) observe(out *executorpkg.FullDecision) []OutputAnomaly {
+	var found []OutputAnomaly
+
+	if sig := decisionSignature(out.Decisions); sig == d.lastDecisions {
+		d.repeats++
+	} else {
+		d.lastDecisions, d.repeats = sig, 1
+	}
+	if d.repeats%d.cfg.RepeatLimit == 0 {
+		found = append(found, OutputAnomaly{Kind: OutputRepeatedDecision, Detail: fmt.Sprintf("identical decisions %d cycles in a row", d.repeats)})
+	}
+
+	reasoning := normalizeReasoning(out.CoTTrace)
+	if reasoning != "" && reasoning == d.lastReasoning {
+		d.copies++
+	} else {
+		d.lastReasoning, d.copies = reasoning, 1
+	}
+	if reasoning != "" && d.copies%d.cfg.CopyLimit == 0 {
+		found = append(found, OutputAnomaly{Kind: OutputCopiedJustification, Detail: fmt.Sprintf("identical reasoning %d cycles in a row", d.copies)})
+	}
+	if symbols := copiedAcrossSymbols(out.Decisions); len(symbols) > 0 {
+		found = append(found, OutputAnomaly{Kind: OutputCopiedJustification, Detail: "same justification for " + strings.Join(symbols, ", ")})
+	}
+
+	text := outputText(out)
+	if script := dominantScript(text); script != "" {
+		switch {
+		case d.script == "":
+			d.script = script
+		case script == d.script:
+			d.switched = false
+		case !d.switched:
+			// Flag the switch once, not every cycle it persists.
+			d.switched = true
+			found = append(found, OutputAnomaly{Kind: OutputLanguageSwitch, Detail: fmt.Sprintf("reasoning switched from %s to %s script", d.script, script)})
+		}
+	}
+
+	for _, re := range d.refusals {
+		if match := re.FindString(text); match != "" {
+			found = append(found, OutputAnomaly{Kind: OutputRefusal, Detail: fmt.Sprintf("refusal text %q", match)})
+			break
+		}
+	}
+	return found
+}
+
+func (d *outputAnomalyDetector) keep(sample OutputAnomalySample) {
+	d.samples = append(d.samples, sample)
+	if len(d.samples) > outputSampleLimit {
+		d.samples = d.samples[len(d.samples)-outputSampleLimit:]
+	}
+}
+
+// decisionSignature identifies a decision set by everything but its wording.
+func decisionSignature(decisions []executorpkg.Decision) string {
+	parts := make([]string, 0, len(decisions))
+	for _, d := range decisions {
+		parts = append(parts, fmt.Sprintf("%s|%s|%d|%.2f|%g|%g|%g|%d", strings.ToUpper(d.Symbol), d.Action, d.Leverage, d.PositionSizeUSD, d.EntryPrice, d.StopLoss, d.TakeProfit, d.Confidence))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ";")
+}
+
+func normalizeReasoning(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// copiedAcrossSymbols lists the symbols sharing a word-for-word justification
+// within one output.
+func copiedAcrossSymbols(decisions []executorpkg.Decision) []string {
+	bySymbol := make(map[string]map[string]bool)
+	for _, d := range decisions {
+		r := normalizeReasoning(d.Reasoning)
+		if len(r) < minCopiedReasoning {
+			continue
+		}
+		if bySymbol[r] == nil {
+			bySymbol[r] = make(map[string]bool)
+		}
+		bySymbol[r][strings.ToUpper(d.Symbol)] = true
+	}
+	var out []string
+	for _, symbols := range bySymbol {
+		if len(symbols) < 2 {
+			continue
+		}
+		for sym := range symbols {
+			out = append(out, sym)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func outputText(out *executorpkg.FullDecision) string {
+	var b strings.Builder
+	b.WriteString(out.CoTTrace)
+	for _, d := range out.Decisions {
+		b.WriteString("\n")
+		b.WriteString(d.Reasoning)
+	}
+	return b.String()
+}
+
+// dominantScript names the writing system most letters of text belong to,
+// or "" when text has too few letters to tell.
+func dominantScript(text string) string {
+	counts := make(map[string]int)
+	total := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		for name, table := range outputScripts {
+			if unicode.Is(table, r) {
+				counts[name]++
+				total++
+				break
+			}
+		}
+	}
+	if total < minScriptLetters {
+		return ""
+	}
+	best := ""
+	for name, n := range counts {
+		if n > counts[best] || (n == counts[best] && name < best) {
+			best = name
+		}
+	}
+	return best
+}
+
+// checkOutputAnomalies flags degenerate output from t's model, recording a
+// sample, alerting and pausing the trader when configured. It reports
+// whether the trader was paused.
+func (m *Manager) checkOutputAnomalies(ctx context.Context, t *VirtualTrader, out *executorpkg.FullDecision, at time.Time) bool {
+	if t.outputAnomaly == nil || out == nil {
+		return false
+	}
+	anomalies := t.outputAnomaly.observe(out)
+	if len(anomalies) == 0 {
+		return false
+	}
+	t.mu.RLock()
+	model := t.Model
+	t.mu.RUnlock()
+	cot := out.CoTTrace
+	if r := []rune(cot); len(r) > outputSampleChars {
+		cot = string(r[:outputSampleChars]) + "…"
+	}
+	sample := OutputAnomalySample{
+		TraderID:  t.ID,
+		Model:     model,
+		Anomalies: anomalies,
+		CoTTrace:  cot,
+		Decisions: out.Decisions,
+		At:        at,
+	}
+	t.mu.Lock()
+	t.outputAnomaly.keep(sample)
+	t.mu.Unlock()
+	if dir := t.outputAnomaly.cfg.SampleDir; dir != "" {
+		if err := appendOutputSample(dir, sample); err != nil {
+			logx.WithContext(ctx).Errorf("manager: trader %s output sample write failed: %v", t.ID, err)
+		}
+	}
+
+	var pauseUntil time.Time
+	if pause := t.outputAnomaly.cfg.PauseFor; pause > 0 {
+		t.mu.Lock()
+		if t.PauseUntil.Before(at.Add(pause)) {
+			t.PauseUntil = at.Add(pause)
+		}
+		pauseUntil = t.PauseUntil
+		t.mu.Unlock()
+	}
+	for _, a := range anomalies {
+		msg := fmt.Sprintf("trader %s model %s output anomaly %s: %s", t.ID, model, a.Kind, a.Detail)
+		if !pauseUntil.IsZero() {
+			msg += "; paused until " + pauseUntil.Format(time.RFC3339)
+		}
+		logx.WithContext(ctx).Slowf("manager: %s", msg)
+		m.sendAlert(ctx, Alert{
+			Kind:     AlertOutputAnomaly,
+			TraderID: t.ID,
+			Message:  msg,
+			Details:  map[string]any{"model": model, "kind": a.Kind},
+			At:       at,
+			Data: OutputAnomalyAlert{
+				TraderID:   t.ID,
+				Model:      model,
+				Kind:       a.Kind,
+				Detail:     a.Detail,
+				PauseUntil: pauseUntil,
+				At:         at,
+			},
+		})
+	}
+	return !pauseUntil.IsZero()
+}
+
+// OutputAnomalySamples returns the most recent flagged outputs of a trader,
+// oldest first.
+func (m *Manager) OutputAnomalySamples(traderID string) []OutputAnomalySample {
+	m.mu.RLock()
+	t, ok := m.traders[traderID]
+	m.mu.RUnlock()
+	if !ok || t.outputAnomaly == nil {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]OutputAnomalySample(nil), t.outputAnomaly.samples...)
+}
+
+func appendOutputSample(dir string, sample OutputAnomalySample) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	line, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, sample.TraderID+".jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,102 @@
+package manager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+func anomalyKinds(anomalies []OutputAnomaly) []string {
+	kinds := make([]string, 0, len(anomalies))
+	for _, a := range anomalies {
+		kinds = append(kinds, a.Kind)
+	}
+	return kinds
+}
+
+func TestOutputAnomalyConfigValidate(t *testing.T) {
+	cfg := OutputAnomalyConfig{Enabled: true}
+	cfg.applyDefaults()
+	require.NoError(t, cfg.Validate(0))
+	assert.Equal(t, defaultOutputRepeatLimit, cfg.RepeatLimit)
+
+	bad := cfg
+	bad.Script = "klingon"
+	assert.ErrorContains(t, bad.Validate(1), `traders[1].output_anomaly.script "klingon" unknown`)
+	bad = cfg
+	bad.RefusalPatterns = []string{"("}
+	assert.ErrorContains(t, bad.Validate(0), "output_anomaly.refusal_patterns")
+	bad = cfg
+	bad.RepeatLimit = 1
+	assert.ErrorContains(t, bad.Validate(0), "must be at least 2")
+}
+
+func TestOutputAnomalyDetector(t *testing.T) {
+	cfg := OutputAnomalyConfig{Enabled: true, RepeatLimit: 3, CopyLimit: 2}
+	d, err := newOutputAnomalyDetector(cfg)
+	require.NoError(t, err)
+
+	hold := []executorpkg.Decision{{Symbol: "BTC", Action: "hold", Confidence: 60}}
+	english := "BTC is ranging under resistance with funding flat; no edge to open a position this cycle."
+	out := func(cot string, decisions []executorpkg.Decision) *executorpkg.FullDecision {
+		return &executorpkg.FullDecision{CoTTrace: cot, Decisions: decisions}
+	}
+
+	assert.Empty(t, d.observe(out(english, hold)))
+	assert.Equal(t, []string{OutputCopiedJustification}, anomalyKinds(d.observe(out(english+" ", hold))), "whitespace does not disguise a copy")
+	assert.Equal(t, []string{OutputRepeatedDecision}, anomalyKinds(d.observe(out(english+" Still waiting.", hold))))
+
+	chinese := "比特币在阻力位下方震荡，资金费率持平，本周期没有开仓的优势，继续观望等待更明确的突破信号出现再考虑入场交易机会。"
+	assert.Equal(t, []string{OutputLanguageSwitch}, anomalyKinds(d.observe(out(chinese, nil))))
+	assert.Empty(t, d.observe(out(chinese+"。", nil)), "a persisting switch is flagged once")
+
+	same := "Momentum rolled over on the 4h with open interest dropping sharply, fade the bounce."
+	copied := []executorpkg.Decision{
+		{Symbol: "ETH", Action: "open_short", Reasoning: same},
+		{Symbol: "SOL", Action: "open_short", Reasoning: same},
+	}
+	found := d.observe(out(english+" I'm sorry, I cannot provide trading decisions.", copied))
+	assert.Equal(t, []string{OutputCopiedJustification, OutputRefusal}, anomalyKinds(found))
+	assert.Equal(t, "same justification for ETH, SOL", found[0].Detail)
+
+	disabled, err := newOutputAnomalyDetector(OutputAnomalyConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, disabled)
+}
+
+func TestCheckOutputAnomaliesPausesAndRecords(t *testing.T) {
+	dir := t.TempDir()
+	alerter := &recordingAlerter{}
+	m := NewManager(&Config{}, nil, nil, nil, nil, WithAlerter(alerter))
+	cfg := OutputAnomalyConfig{Enabled: true, RepeatLimit: 10, CopyLimit: 3, PauseFor: time.Hour, SampleDir: dir}
+	detector, err := newOutputAnomalyDetector(cfg)
+	require.NoError(t, err)
+	trader := &VirtualTrader{ID: "t1", Model: "gpt-5", outputAnomaly: detector}
+	m.traders["t1"] = trader
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assert.False(t, m.checkOutputAnomalies(context.Background(), trader, &executorpkg.FullDecision{CoTTrace: "fine"}, now))
+	assert.True(t, m.checkOutputAnomalies(context.Background(), trader, &executorpkg.FullDecision{CoTTrace: "As an AI I cannot help with trading."}, now))
+	assert.Equal(t, now.Add(time.Hour), trader.PauseUntil)
+
+	require.Len(t, alerter.alerts, 1)
+	data, ok := alerter.alerts[0].Data.(OutputAnomalyAlert)
+	require.True(t, ok)
+	assert.Equal(t, OutputAnomalyAlert{TraderID: "t1", Model: "gpt-5", Kind: OutputRefusal, Detail: `refusal text "I cannot help"`, PauseUntil: now.Add(time.Hour), At: now}, data)
+
+	samples := m.OutputAnomalySamples("t1")
+	require.Len(t, samples, 1)
+	assert.Equal(t, "As an AI I cannot help with trading.", samples[0].CoTTrace)
+	raw, err := os.ReadFile(filepath.Join(dir, "t1.jsonl"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(raw), "\n"))
+	assert.Contains(t, string(raw), `"kind":"refusal"`)
+}
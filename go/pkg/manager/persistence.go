@@ -32,6 +32,16 @@ type PositionEvent struct {
 	OccurredAt       time.Time
 	FillPrice        float64
 	FillSize         float64
+	// ArrivalPrice is the price the order was prepared against; SlippageBps
+	// is the fill's adverse distance from it (0 when the fill is unknown).
+	ArrivalPrice float64
+	SlippageBps  float64
+	// TraceID, PromptDigest, PromptVersion and RawResponse tie the event to
+	// the decision cycle that caused it; they are empty outside a cycle.
+	TraceID       string
+	PromptDigest  string
+	PromptVersion string
+	RawResponse   string
 }
 
 // DecisionCycleRecord is emitted after each decision loop for DB/cache mirroring.
@@ -0,0 +1,122 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+// Pipeline stages reported in the live log stream, in cycle order.
+const (
+	PipelineStageData      = "data"
+	PipelineStageThinking  = "thinking"
+	PipelineStageDeciding  = "deciding"
+	PipelineStageExecuting = "executing"
+	PipelineStageDone      = "done"
+)
+
+// Pipeline event levels.
+const (
+	PipelineLevelInfo  = "info"
+	PipelineLevelError = "error"
+)
+
+// maxPipelineReasoning bounds the model reasoning copied into a stream event.
+const maxPipelineReasoning = 500
+
+// PipelineEvent is one structured step of a decision cycle, streamed to the
+// web UI. Events of one cycle share TraceID, which is also the cycle's
+// CycleBreakdown.TraceID.
+type PipelineEvent struct {
+	TraderID string         `json:"trader_id"`
+	TraceID  string         `json:"trace_id"`
+	Stage    string         `json:"stage"`
+	Level    string         `json:"level"`
+	Message  string         `json:"message"`
+	Fields   map[string]any `json:"fields,omitempty"`
+	At       time.Time      `json:"at"`
+}
+
+// PipelineLogRecorder is implemented by persistence backends that publish
+// pipeline events for the log stream API.
+type PipelineLogRecorder interface {
+	RecordPipelineEvent(ctx context.Context, event PipelineEvent) error
+}
+
+func newTraceID(traderID string, startedAt time.Time) string {
+	return fmt.Sprintf("%s-%d", traderID, startedAt.UnixMilli())
+}
+
+// emitPipeline publishes a pipeline event for the cycle described by b.
+func (m *Manager) emitPipeline(b *CycleBreakdown, stage, level, msg string, fields map[string]any) {
+	recorder, ok := m.persistence.(PipelineLogRecorder)
+	if !ok || b == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	err := recorder.RecordPipelineEvent(ctx, PipelineEvent{
+		TraderID: b.TraderID,
+		TraceID:  b.TraceID,
+		Stage:    stage,
+		Level:    level,
+		Message:  msg,
+		Fields:   fields,
+		At:       time.Now(),
+	})
+	logPersistenceError(err, "pipeline event persistence failed", map[string]any{
+		"trader_id": b.TraderID,
+		"stage":     stage,
+	})
+}
+
+func truncateReasoning(s string) string {
+	r := []rune(s)
+	if len(r) <= maxPipelineReasoning {
+		return s
+	}
+	return string(r[:maxPipelineReasoning]) + "…"
+}
+
+// emitDecisionEvents reports the model answer: a failure, or one event per
+// decision with its reasoning.
+func (m *Manager) emitDecisionEvents(b *CycleBreakdown, out *executorpkg.FullDecision, decisionErr error) {
+	if b.LLM.Error != "" {
+		m.emitPipeline(b, PipelineStageDeciding, PipelineLevelError, "model call failed: "+b.LLM.Error, map[string]any{
+			"latency_ms": b.LLM.LatencyMs,
+		})
+		return
+	}
+	if out == nil {
+		return
+	}
+	m.emitPipeline(b, PipelineStageDeciding, PipelineLevelInfo, fmt.Sprintf("model returned %d decisions", len(b.Decisions)), map[string]any{
+		"latency_ms":        b.LLM.LatencyMs,
+		"prompt_tokens":     b.Prompt.PromptTokens,
+		"completion_tokens": b.Prompt.CompletionTokens,
+	})
+	for _, d := range out.Decisions {
+		m.emitPipeline(b, PipelineStageDeciding, PipelineLevelInfo, d.Action+" "+d.Symbol, map[string]any{
+			"symbol":            d.Symbol,
+			"action":            d.Action,
+			"confidence":        d.Confidence,
+			"position_size_usd": d.PositionSizeUSD,
+			"reasoning":         truncateReasoning(d.Reasoning),
+		})
+	}
+	if decisionErr != nil {
+		m.emitPipeline(b, PipelineStageDeciding, PipelineLevelError, "decisions rejected: "+decisionErr.Error(), nil)
+	}
+}
+
+func (m *Manager) emitExecutionEvent(b *CycleBreakdown, d executorpkg.Decision, execErr error) {
+	fields := map[string]any{"symbol": d.Symbol, "action": d.Action}
+	if execErr != nil {
+		fields["error"] = execErr.Error()
+		m.emitPipeline(b, PipelineStageExecuting, PipelineLevelError, d.Action+" "+d.Symbol+" failed", fields)
+		return
+	}
+	m.emitPipeline(b, PipelineStageExecuting, PipelineLevelInfo, d.Action+" "+d.Symbol+" executed", fields)
+}
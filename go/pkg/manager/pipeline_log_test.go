@@ -0,0 +1,64 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+type recordingPipeline struct {
+	noopPersistenceService
+	events []PipelineEvent
+}
+
+func (r *recordingPipeline) RecordPipelineEvent(_ context.Context, ev PipelineEvent) error {
+	r.events = append(r.events, ev)
+	return nil
+}
+
+func TestPipelineEventsShareTraceID(t *testing.T) {
+	rec := &recordingPipeline{}
+	m := NewManager(&Config{}, nil, nil, nil, rec)
+	start := time.UnixMilli(1700000000000)
+	b := newCycleBreakdown("t1", start)
+	assert.Equal(t, "t1-1700000000000", b.TraceID)
+
+	out := &executorpkg.FullDecision{Decisions: []executorpkg.Decision{
+		{Symbol: "BTC", Action: "open_long", Confidence: 80, Reasoning: "breakout"},
+	}}
+	b.recordDecision(out, nil, time.Time{})
+	m.emitDecisionEvents(b, out, nil)
+	rejected := errors.New("rejected")
+	b.recordExecution(out.Decisions[0], rejected)
+	m.emitExecutionEvent(b, out.Decisions[0], rejected)
+	m.recordCycleBreakdown(b)
+
+	require.Len(t, rec.events, 4)
+	stages := make([]string, 0, len(rec.events))
+	for _, ev := range rec.events {
+		assert.Equal(t, b.TraceID, ev.TraceID)
+		assert.Equal(t, "t1", ev.TraderID)
+		stages = append(stages, ev.Stage)
+	}
+	assert.Equal(t, []string{PipelineStageDeciding, PipelineStageDeciding, PipelineStageExecuting, PipelineStageDone}, stages)
+	assert.Equal(t, "breakout", rec.events[1].Fields["reasoning"])
+	assert.Equal(t, PipelineLevelError, rec.events[2].Level)
+	assert.Equal(t, PipelineLevelError, rec.events[3].Level)
+	assert.Equal(t, CycleOutcomeError, rec.events[3].Fields["outcome"])
+}
+
+func TestPipelineModelFailureEvent(t *testing.T) {
+	rec := &recordingPipeline{}
+	m := NewManager(&Config{}, nil, nil, nil, rec)
+	b := newCycleBreakdown("t1", time.Now())
+	b.recordDecision(nil, errors.New("timeout"), time.Time{})
+	m.emitDecisionEvents(b, nil, errors.New("timeout"))
+	require.Len(t, rec.events, 1)
+	assert.Equal(t, "model call failed: timeout", rec.events[0].Message)
+}
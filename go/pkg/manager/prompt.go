@@ -2,6 +2,8 @@ package manager
 
 import (
 	"fmt"
+	"io/fs"
+	"reflect"
 	"strings"
 
 	"nof0-api/pkg/llm"
@@ -13,6 +15,10 @@ type ManagerPromptInputs struct {
 	ContextJSON string
 }
 
+func init() {
+	llm.RegisterType("manager.ManagerPromptInputs", ManagerPromptInputs{})
+}
+
 // PromptRenderer renders manager prompt templates for a specific trader.
 type PromptRenderer struct {
 	template        *llm.PromptTemplate
@@ -21,9 +27,26 @@ type PromptRenderer struct {
 
 // NewPromptRenderer parses the template at the provided path applying the optional version guard.
 func NewPromptRenderer(path string, guard *llm.TemplateVersionGuard) (*PromptRenderer, error) {
+	return NewPromptRendererFS(nil, path, guard)
+}
+
+// TemplateOptions are the template options cfg applies to every trader
+// prompt template, such as its function allow-list.
+func (c *Config) TemplateOptions() []llm.PromptTemplateOption {
+	if c == nil || len(c.Manager.AllowedFuncs) == 0 {
+		return nil
+	}
+	return []llm.PromptTemplateOption{llm.WithAllowedFuncs(c.Manager.AllowedFuncs...)}
+}
+
+// NewPromptRendererFS is NewPromptRenderer reading path from fsys; a nil
+// fsys reads from disk. opts are passed to the underlying template, e.g.
+// llm.WithPinnedDigest or TemplateOptions.
+func NewPromptRendererFS(fsys fs.FS, path string, guard *llm.TemplateVersionGuard, opts ...llm.PromptTemplateOption) (*PromptRenderer, error) {
 	var version string
 	if guard != nil {
 		g := *guard
+		g.FS = fsys
 		if strings.TrimSpace(g.Component) == "" {
 			g.Component = "manager.prompt"
 		}
@@ -33,7 +56,11 @@ func NewPromptRenderer(path string, guard *llm.TemplateVersionGuard) (*PromptRen
 			return nil, err
 		}
 	}
-	tpl, err := llm.NewPromptTemplate(path, nil)
+	if fsys != nil {
+		opts = append(opts, llm.WithFS(fsys))
+	}
+	opts = append(opts, llm.WithDataType(reflect.TypeOf(ManagerPromptInputs{})))
+	tpl, err := llm.NewPromptTemplate(path, nil, opts...)
 	if err != nil {
 		return nil, err
 	}
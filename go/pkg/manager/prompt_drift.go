@@ -0,0 +1,370 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/llm"
+)
+
+// AlertPromptDrift fires when a trader's rendered prompts move away from the
+// distribution its baseline was built from.
+const AlertPromptDrift = "prompt_drift"
+
+const (
+	defaultDriftBaselineSize = 200
+	defaultDriftWindow       = 20
+	defaultDriftThreshold    = 4.0
+	driftEmbedTimeout        = 10 * time.Second
+	// minDriftStd keeps a near-constant baseline from turning noise into
+	// huge scores.
+	minDriftStd = 1e-3
+)
+
+// PromptDriftConfig monitors the distribution of a trader's prompts. The
+// first BaselineSize prompts, the ones the model was tuned against, are
+// embedded and averaged into a baseline; afterwards the mean distance of the
+// last Window prompts from it is compared with the baseline's own spread, so
+// a data pipeline change that reshapes prompts is caught even when every
+// cycle still succeeds.
+type PromptDriftConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// EmbeddingModel embeds through the LLM provider, e.g.
+	// "openai/text-embedding-3-small"; empty uses the local hash embedder.
+	EmbeddingModel string `yaml:"embedding_model" json:"embedding_model"`
+	// BaselineSize is how many prompts form the baseline.
+	BaselineSize int `yaml:"baseline_size" json:"baseline_size"`
+	// Window is how many recent prompts are averaged per check.
+	Window int `yaml:"window" json:"window"`
+	// Threshold is the drift score, in baseline standard deviations above the
+	// baseline mean distance, that alerts.
+	Threshold float64 `yaml:"threshold" json:"threshold"`
+	// BaselinePath keeps the baseline across restarts; without it a new
+	// baseline is collected on every start.
+	BaselinePath string `yaml:"baseline_path" json:"baseline_path"`
+}
+
+func (c *PromptDriftConfig) applyDefaults() {
+	if c.BaselineSize == 0 {
+		c.BaselineSize = defaultDriftBaselineSize
+	}
+	if c.Window == 0 {
+		c.Window = defaultDriftWindow
+	}
+	if c.Threshold == 0 {
+		c.Threshold = defaultDriftThreshold
+	}
+	c.EmbeddingModel = strings.TrimSpace(c.EmbeddingModel)
+	c.BaselinePath = strings.TrimSpace(c.BaselinePath)
+}
+
+// Validate checks the sizes and threshold.
+func (c PromptDriftConfig) Validate(index int) error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.BaselineSize < 2 || c.Window < 1 {
+		return fmt.Errorf("manager config: traders[%d].prompt_drift baseline_size must be at least 2 and window at least 1", index)
+	}
+	if c.Threshold <= 0 {
+		return fmt.Errorf("manager config: traders[%d].prompt_drift.threshold must be positive", index)
+	}
+	return nil
+}
+
+// PromptDriftStatus is a trader's current drift reading.
+type PromptDriftStatus struct {
+	TraderID string `json:"trader_id"`
+	// Collected counts baseline prompts so far; the baseline is ready when it
+	// reaches BaselineSize.
+	Collected    int     `json:"collected"`
+	BaselineSize int     `json:"baseline_size"`
+	BaselineMean float64 `json:"baseline_mean"`
+	BaselineStd  float64 `json:"baseline_std"`
+	// Distance is the last prompt's cosine distance from the baseline
+	// centroid; RecentMean averages the window.
+	Distance   float64   `json:"distance"`
+	RecentMean float64   `json:"recent_mean"`
+	Score      float64   `json:"score"`
+	Drifting   bool      `json:"drifting"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Ready reports whether the baseline is complete.
+func (s PromptDriftStatus) Ready() bool { return s.Collected >= s.BaselineSize }
+
+// PromptDriftAlert is the template data for AlertPromptDrift.
+type PromptDriftAlert struct {
+	TraderID   string    `doc:"Trader whose prompts drifted"`
+	Score      float64   `doc:"Recent mean distance in baseline standard deviations above the baseline mean"`
+	Threshold  float64   `doc:"Configured prompt_drift.threshold"`
+	RecentMean float64   `doc:"Mean cosine distance of the recent window from the baseline centroid"`
+	Baseline   float64   `doc:"Mean cosine distance of baseline prompts from their centroid"`
+	Recovered  bool      `doc:"True when prompts returned under the threshold"`
+	At         time.Time `doc:"Cycle time"`
+}
+
+// promptBaseline is the persisted form of a completed baseline.
+type promptBaseline struct {
+	EmbeddingModel string    `json:"embedding_model"`
+	Size           int       `json:"size"`
+	Centroid       []float64 `json:"centroid"`
+	Mean           float64   `json:"mean"`
+	Std            float64   `json:"std"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// promptDriftDetector carries one trader's baseline and recent distances.
+// Only the trading loop observes; status is guarded by the trader's mutex.
+type promptDriftDetector struct {
+	cfg      PromptDriftConfig
+	embedder llm.Embedder
+
+	pending  [][]float64
+	baseline *promptBaseline
+	recent   []float64
+
+	status PromptDriftStatus
+}
+
+func newPromptDriftDetector(traderID string, cfg PromptDriftConfig, embedder llm.Embedder) (*promptDriftDetector, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if embedder == nil {
+		return nil, fmt.Errorf("embedding_model %q needs an embedding client", cfg.EmbeddingModel)
+	}
+	d := &promptDriftDetector{
+		cfg:      cfg,
+		embedder: embedder,
+		status:   PromptDriftStatus{TraderID: traderID, BaselineSize: cfg.BaselineSize},
+	}
+	if cfg.BaselinePath != "" {
+		b, err := loadPromptBaseline(cfg.BaselinePath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, err
+		case b.EmbeddingModel != cfg.EmbeddingModel:
+			logx.Infof("manager: trader %s prompt baseline %s was built with %q, collecting a new one", traderID, cfg.BaselinePath, b.EmbeddingModel)
+		default:
+			d.setBaseline(b)
+		}
+	}
+	return d, nil
+}
+
+func (d *promptDriftDetector) setBaseline(b *promptBaseline) {
+	d.baseline = b
+	d.pending = nil
+	d.status.Collected = d.cfg.BaselineSize
+	d.status.BaselineMean, d.status.BaselineStd = b.Mean, b.Std
+}
+
+// observe embeds prompt and returns the updated status and whether the
+// drifting state changed.
+func (d *promptDriftDetector) observe(ctx context.Context, prompt string, at time.Time) (PromptDriftStatus, bool, error) {
+	vecs, err := d.embedder.Embed(ctx, []string{prompt})
+	if err != nil {
+		return d.status, false, err
+	}
+	if len(vecs) != 1 || len(vecs[0]) == 0 {
+		return d.status, false, errors.New("empty embedding")
+	}
+	vec := vecs[0]
+	s := d.status
+	s.UpdatedAt = at
+
+	if d.baseline == nil {
+		d.pending = append(d.pending, vec)
+		s.Collected = len(d.pending)
+		if len(d.pending) >= d.cfg.BaselineSize {
+			b := buildPromptBaseline(d.pending, d.cfg.EmbeddingModel, at)
+			d.setBaseline(b)
+			s.Collected, s.BaselineMean, s.BaselineStd = d.status.Collected, b.Mean, b.Std
+			if d.cfg.BaselinePath != "" {
+				if err := savePromptBaseline(d.cfg.BaselinePath, b); err != nil {
+					logx.WithContext(ctx).Errorf("manager: trader %s save prompt baseline: %v", s.TraderID, err)
+				}
+			}
+		}
+		d.status = s
+		return s, false, nil
+	}
+	if len(vec) != len(d.baseline.Centroid) {
+		return d.status, false, fmt.Errorf("embedding has %d dimensions, baseline %d", len(vec), len(d.baseline.Centroid))
+	}
+
+	s.Distance = 1 - llm.CosineSimilarity(vec, d.baseline.Centroid)
+	d.recent = append(d.recent, s.Distance)
+	if len(d.recent) > d.cfg.Window {
+		d.recent = d.recent[len(d.recent)-d.cfg.Window:]
+	}
+	s.RecentMean = mean(d.recent)
+	if len(d.recent) < d.cfg.Window {
+		d.status = s
+		return s, false, nil
+	}
+	s.Score = (s.RecentMean - d.baseline.Mean) / math.Max(d.baseline.Std, minDriftStd)
+	s.Drifting = s.Score > d.cfg.Threshold
+	changed := s.Drifting != d.status.Drifting
+	d.status = s
+	return s, changed, nil
+}
+
+// buildPromptBaseline averages vecs into a centroid and measures how far the
+// baseline prompts themselves sit from it.
+func buildPromptBaseline(vecs [][]float64, model string, at time.Time) *promptBaseline {
+	centroid := make([]float64, len(vecs[0]))
+	for _, v := range vecs {
+		for i := range centroid {
+			if i < len(v) {
+				centroid[i] += v[i]
+			}
+		}
+	}
+	for i := range centroid {
+		centroid[i] /= float64(len(vecs))
+	}
+	dists := make([]float64, len(vecs))
+	for i, v := range vecs {
+		dists[i] = 1 - llm.CosineSimilarity(v, centroid)
+	}
+	m := mean(dists)
+	var variance float64
+	for _, x := range dists {
+		variance += (x - m) * (x - m)
+	}
+	variance /= float64(len(dists))
+	return &promptBaseline{EmbeddingModel: model, Size: len(vecs), Centroid: centroid, Mean: m, Std: math.Sqrt(variance), CreatedAt: at}
+}
+
+func mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+func loadPromptBaseline(path string) (*promptBaseline, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b promptBaseline
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return nil, fmt.Errorf("prompt baseline %s: %w", path, err)
+	}
+	if len(b.Centroid) == 0 {
+		return nil, fmt.Errorf("prompt baseline %s has no centroid", path)
+	}
+	return &b, nil
+}
+
+func savePromptBaseline(path string, b *promptBaseline) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+// EmbedderSource resolves embedding models, e.g. *llm.Client.
+type EmbedderSource interface {
+	Embedder(model string) llm.Embedder
+}
+
+// WithEmbedders lets prompt_drift.embedding_model embed through src.
+func WithEmbedders(src EmbedderSource) Option {
+	return func(m *Manager) {
+		m.embedders = src
+	}
+}
+
+// promptEmbedder returns the embedder for cfg, or nil when its model cannot
+// be resolved.
+func (m *Manager) promptEmbedder(cfg PromptDriftConfig) llm.Embedder {
+	if cfg.EmbeddingModel == "" {
+		return llm.HashEmbedder{}
+	}
+	if m.embedders == nil {
+		return nil
+	}
+	return m.embedders.Embedder(cfg.EmbeddingModel)
+}
+
+// checkPromptDrift feeds the cycle's prompt to t's drift monitor and alerts
+// when prompts start or stop deviating from the baseline.
+func (m *Manager) checkPromptDrift(ctx context.Context, t *VirtualTrader, prompt string, at time.Time) {
+	if t.promptDrift == nil || strings.TrimSpace(prompt) == "" {
+		return
+	}
+	embedCtx, cancel := context.WithTimeout(ctx, driftEmbedTimeout)
+	defer cancel()
+	t.mu.RLock()
+	detector := t.promptDrift
+	t.mu.RUnlock()
+	status, changed, err := detector.observe(embedCtx, prompt, at)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("manager: trader %s prompt drift: %v", t.ID, err)
+		return
+	}
+	t.mu.Lock()
+	t.promptDriftStatus = status
+	t.mu.Unlock()
+	if !changed {
+		return
+	}
+	threshold := detector.cfg.Threshold
+	msg := fmt.Sprintf("trader %s prompts drifted: score %.1f above %.1f (recent distance %.4f, baseline %.4f)", t.ID, status.Score, threshold, status.RecentMean, status.BaselineMean)
+	if !status.Drifting {
+		msg = fmt.Sprintf("trader %s prompts back within baseline: score %.1f", t.ID, status.Score)
+	}
+	logx.WithContext(ctx).Slowf("manager: %s", msg)
+	m.sendAlert(ctx, Alert{
+		Kind:     AlertPromptDrift,
+		TraderID: t.ID,
+		Message:  msg,
+		Details:  map[string]any{"score": status.Score, "recent_mean": status.RecentMean, "baseline_mean": status.BaselineMean},
+		At:       at,
+		Data: PromptDriftAlert{
+			TraderID:   t.ID,
+			Score:      status.Score,
+			Threshold:  threshold,
+			RecentMean: status.RecentMean,
+			Baseline:   status.BaselineMean,
+			Recovered:  !status.Drifting,
+			At:         at,
+		},
+	})
+}
+
+// PromptDrift returns a trader's latest drift reading; ok is false when the
+// trader is unknown or not monitored.
+func (m *Manager) PromptDrift(traderID string) (PromptDriftStatus, bool) {
+	m.mu.RLock()
+	t, ok := m.traders[traderID]
+	m.mu.RUnlock()
+	if !ok || t.promptDrift == nil {
+		return PromptDriftStatus{}, false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.promptDriftStatus, true
+}
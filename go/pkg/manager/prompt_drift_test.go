@@ -0,0 +1,95 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/llm"
+)
+
+// driftPrompt renders a prompt shaped like the executor's, with the symbol
+// set and numbers varying per cycle. broken mimics a data pipeline change
+// that drops indicator values.
+func driftPrompt(rng *rand.Rand, broken bool) string {
+	var b strings.Builder
+	b.WriteString("You are a crypto perpetuals trader. Account equity 10000 USD, 1 open position.\n")
+	for _, sym := range []string{"BTC", "ETH", "SOL", "DOGE", "XRP"} {
+		if rng.Intn(3) == 0 {
+			continue
+		}
+		if broken {
+			fmt.Fprintf(&b, "%s current_price=null ema20=null macd=null rsi7=null (series unavailable: upstream error)\n", sym)
+			continue
+		}
+		fmt.Fprintf(&b, "%s current_price=%.2f ema20=%.2f macd=%.3f rsi7=%.1f\n", sym, rng.Float64()*1e5, rng.Float64()*1e5, rng.NormFloat64(), rng.Float64()*100)
+	}
+	b.WriteString("Respond with a single JSON decision.")
+	return b.String()
+}
+
+func TestPromptDriftConfigValidate(t *testing.T) {
+	cfg := PromptDriftConfig{Enabled: true}
+	cfg.applyDefaults()
+	require.NoError(t, cfg.Validate(0))
+	assert.Equal(t, defaultDriftBaselineSize, cfg.BaselineSize)
+
+	cfg.BaselineSize = 1
+	assert.ErrorContains(t, cfg.Validate(2), "traders[2].prompt_drift baseline_size must be at least 2")
+}
+
+func TestCheckPromptDriftAlertsAndRecovers(t *testing.T) {
+	alerter := &recordingAlerter{}
+	m := NewManager(&Config{}, nil, nil, nil, nil, WithAlerter(alerter))
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	cfg := PromptDriftConfig{Enabled: true, BaselineSize: 30, Window: 5, Threshold: 4, BaselinePath: path}
+	detector, err := newPromptDriftDetector("t1", cfg, m.promptEmbedder(cfg))
+	require.NoError(t, err)
+	trader := &VirtualTrader{ID: "t1", promptDrift: detector}
+	m.traders["t1"] = trader
+	rng := rand.New(rand.NewSource(1))
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for i := 0; i < 40; i++ {
+		m.checkPromptDrift(context.Background(), trader, driftPrompt(rng, false), now)
+	}
+	status, ok := m.PromptDrift("t1")
+	require.True(t, ok)
+	assert.True(t, status.Ready())
+	assert.False(t, status.Drifting)
+	assert.Empty(t, alerter.alerts, "prompts like the baseline do not alert")
+
+	for i := 0; i < 5; i++ {
+		m.checkPromptDrift(context.Background(), trader, driftPrompt(rng, true), now)
+	}
+	status, _ = m.PromptDrift("t1")
+	assert.True(t, status.Drifting)
+	assert.Greater(t, status.Score, 4.0)
+	require.Len(t, alerter.alerts, 1)
+	data, ok := alerter.alerts[0].Data.(PromptDriftAlert)
+	require.True(t, ok)
+	assert.False(t, data.Recovered)
+	assert.Equal(t, AlertPromptDrift, alerter.alerts[0].Kind)
+
+	for i := 0; i < 5; i++ {
+		m.checkPromptDrift(context.Background(), trader, driftPrompt(rng, false), now)
+	}
+	require.Len(t, alerter.alerts, 2)
+	assert.True(t, alerter.alerts[1].Data.(PromptDriftAlert).Recovered)
+
+	// A restart reuses the saved baseline instead of collecting a new one.
+	reloaded, err := newPromptDriftDetector("t1", cfg, llm.HashEmbedder{})
+	require.NoError(t, err)
+	assert.True(t, reloaded.status.Ready())
+	assert.Equal(t, status.BaselineMean, reloaded.status.BaselineMean)
+
+	_, err = newPromptDriftDetector("t1", PromptDriftConfig{Enabled: true, EmbeddingModel: "openai/text-embedding-3-small"}, m.promptEmbedder(PromptDriftConfig{EmbeddingModel: "x"}))
+	assert.ErrorContains(t, err, "needs an embedding client")
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"nof0-api/pkg/llm"
+	"nof0-api/pkg/llm/testutil"
 )
 
 func TestManagerPromptRenderer(t *testing.T) {
@@ -39,24 +40,11 @@ func TestManagerPromptRenderer(t *testing.T) {
 		},
 	}
 
-	out, err := renderer.Render(ManagerPromptInputs{
-		Trader:      trader,
-		ContextJSON: `{"market":"bearish"}`,
+	engine := testutil.EngineFunc(func(_ string, data any) (string, error) {
+		return renderer.Render(data.(ManagerPromptInputs))
 	})
-	assert.NoError(t, err, "Render should not error")
-	assert.NotEmpty(t, out, "rendered output should not be empty")
-
-	expectations := []string{
-		"Trader ID: trader_aggressive_short",
-		"Decision Interval: 3m0s",
-		"Allocation %: 40.00",
-		"max_positions=3",
-		"Min Confidence: 75",
-		`{"market":"bearish"}`,
-	}
-	for _, substr := range expectations {
-		assert.Contains(t, out, substr, "rendered prompt should contain %q", substr)
-	}
+	inputs := ManagerPromptInputs{Trader: trader, ContextJSON: `{"market":"bearish"}`}
+	testutil.SnapshotTest(t, engine, templatePath, inputs, filepath.Join("testdata", "aggressive_short.golden"))
 }
 
 func TestManagerPromptRendererMissingTrader(t *testing.T) {
@@ -0,0 +1,170 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/market"
+)
+
+// Market regimes reported by the volatility classifier.
+const (
+	RegimeQuiet   = "quiet"
+	RegimeNormal  = "normal"
+	RegimeHighVol = "high_vol"
+)
+
+const defaultRegimeReferenceSymbol = "BTC"
+
+// RegimeScheduleConfig adapts the decision interval to the market regime. The
+// regime is classified from the reference symbol's ATR-based daily
+// volatility: at or above HighVolDailyPct the trader decides every
+// HighVolInterval, below QuietDailyPct every QuietInterval, and otherwise at
+// its decision_interval. The regime is re-evaluated each cycle, so a change
+// applies from the next scheduled decision.
+type RegimeScheduleConfig struct {
+	Enabled         bool   `yaml:"enabled" json:"enabled"`
+	ReferenceSymbol string `yaml:"reference_symbol" json:"reference_symbol"`
+	// ATRWindow and BarsPerDay follow risk_params.sizing (ATR14 on 4h bars).
+	ATRWindow       string        `yaml:"atr_window" json:"atr_window"`
+	BarsPerDay      float64       `yaml:"bars_per_day" json:"bars_per_day"`
+	HighVolDailyPct float64       `yaml:"high_vol_daily_pct" json:"high_vol_daily_pct"`
+	QuietDailyPct   float64       `yaml:"quiet_daily_pct" json:"quiet_daily_pct"`
+	HighVolInterval time.Duration `yaml:"-" json:"high_vol_interval_duration"`
+	QuietInterval   time.Duration `yaml:"-" json:"quiet_interval_duration"`
+
+	HighVolIntervalRaw string `yaml:"high_vol_interval" json:"high_vol_interval"`
+	QuietIntervalRaw   string `yaml:"quiet_interval" json:"quiet_interval"`
+}
+
+func (c *RegimeScheduleConfig) applyDefaults() {
+	c.ReferenceSymbol = strings.ToUpper(strings.TrimSpace(c.ReferenceSymbol))
+	if c.ReferenceSymbol == "" {
+		c.ReferenceSymbol = defaultRegimeReferenceSymbol
+	}
+	if c.ATRWindow == "" {
+		c.ATRWindow = defaultVolTargetATRWindow
+	}
+	if c.BarsPerDay == 0 {
+		c.BarsPerDay = defaultVolTargetBarsPerDay
+	}
+}
+
+func (c *RegimeScheduleConfig) parseDurations(index int) error {
+	var err error
+	if strings.TrimSpace(c.HighVolIntervalRaw) != "" {
+		if c.HighVolInterval, err = parsePositiveDuration(fmt.Sprintf("traders[%d].regime_schedule.high_vol_interval", index), c.HighVolIntervalRaw); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(c.QuietIntervalRaw) != "" {
+		if c.QuietInterval, err = parsePositiveDuration(fmt.Sprintf("traders[%d].regime_schedule.quiet_interval", index), c.QuietIntervalRaw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks the volatility thresholds and regime intervals.
+func (c RegimeScheduleConfig) Validate(index int) error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.HighVolDailyPct < 0 || c.QuietDailyPct < 0 || c.BarsPerDay < 0 {
+		return fmt.Errorf("manager config: traders[%d].regime_schedule thresholds cannot be negative", index)
+	}
+	if c.HighVolDailyPct > 0 && c.QuietDailyPct >= c.HighVolDailyPct {
+		return fmt.Errorf("manager config: traders[%d].regime_schedule.quiet_daily_pct must be below high_vol_daily_pct", index)
+	}
+	if (c.HighVolDailyPct > 0) != (c.HighVolInterval > 0) {
+		return fmt.Errorf("manager config: traders[%d].regime_schedule.high_vol_daily_pct and high_vol_interval must be set together", index)
+	}
+	if (c.QuietDailyPct > 0) != (c.QuietInterval > 0) {
+		return fmt.Errorf("manager config: traders[%d].regime_schedule.quiet_daily_pct and quiet_interval must be set together", index)
+	}
+	if c.HighVolDailyPct == 0 && c.QuietDailyPct == 0 {
+		return fmt.Errorf("manager config: traders[%d].regime_schedule needs a high_vol or quiet regime when enabled", index)
+	}
+	return nil
+}
+
+// classify returns the regime for snap and the daily volatility in percent.
+// Without an ATR reading the regime is normal.
+func (c RegimeScheduleConfig) classify(snap *market.Snapshot) (string, float64) {
+	window, bars := c.ATRWindow, c.BarsPerDay
+	if window == "" {
+		window = defaultVolTargetATRWindow
+	}
+	if bars <= 0 {
+		bars = defaultVolTargetBarsPerDay
+	}
+	var price float64
+	if snap != nil {
+		price = snap.Price.Last
+	}
+	volPct := 100 * market.ATRDailyVolatility(snap.LatestATR(window), price, bars)
+	switch {
+	case volPct <= 0:
+		return RegimeNormal, 0
+	case c.HighVolDailyPct > 0 && volPct >= c.HighVolDailyPct:
+		return RegimeHighVol, volPct
+	case c.QuietDailyPct > 0 && volPct < c.QuietDailyPct:
+		return RegimeQuiet, volPct
+	default:
+		return RegimeNormal, volPct
+	}
+}
+
+// interval returns the decision interval for regime, falling back to base.
+func (c RegimeScheduleConfig) interval(regime string, base time.Duration) time.Duration {
+	switch {
+	case regime == RegimeHighVol && c.HighVolInterval > 0:
+		return c.HighVolInterval
+	case regime == RegimeQuiet && c.QuietInterval > 0:
+		return c.QuietInterval
+	default:
+		return base
+	}
+}
+
+// updateRegime classifies the market for trader t from snaps, fetching the
+// reference symbol when it is not among them, and sets the decision interval
+// for the regime. It returns the regime; "" when scheduling is disabled,
+// including by FlagRegimeSchedule, which also restores the configured
+// interval.
+func (m *Manager) updateRegime(ctx context.Context, t *VirtualTrader, snaps map[string]*market.Snapshot) string {
+	sched := t.RegimeSchedule
+	if !sched.Enabled {
+		return ""
+	}
+	if !m.featureEnabled(t, FlagRegimeSchedule) {
+		t.mu.Lock()
+		t.Regime, t.DecisionInterval = "", t.BaseDecisionInterval
+		t.mu.Unlock()
+		return ""
+	}
+	snap := snaps[sched.ReferenceSymbol]
+	if snap == nil && t.MarketProvider != nil {
+		var err error
+		if snap, err = t.MarketProvider.Snapshot(ctx, sched.ReferenceSymbol); err != nil {
+			logx.WithContext(ctx).Slowf("manager: trader %s regime snapshot %s unavailable: %v", t.ID, sched.ReferenceSymbol, err)
+		}
+	}
+	regime, volPct := sched.classify(snap)
+
+	t.mu.Lock()
+	prev, prevInterval := t.Regime, t.DecisionInterval
+	t.Regime = regime
+	t.DecisionInterval = sched.interval(regime, t.BaseDecisionInterval)
+	interval := t.DecisionInterval
+	t.mu.Unlock()
+	if prev != regime || prevInterval != interval {
+		logx.WithContext(ctx).Infof("manager: trader %s regime %s -> %s (%s daily vol %.2f%%), decision interval %s -> %s",
+			t.ID, prev, regime, sched.ReferenceSymbol, volPct, prevInterval, interval)
+	}
+	return regime
+}
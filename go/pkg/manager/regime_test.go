@@ -0,0 +1,110 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/market"
+)
+
+// atrSnapshot returns a snapshot whose ATR14 on 4h bars implies dailyVolPct.
+func atrSnapshot(dailyVolPct float64) *market.Snapshot {
+	atr := dailyVolPct / 100 * 100 / market.ATRDailyVolatility(1, 1, 6)
+	return &market.Snapshot{
+		Price:    market.PriceInfo{Last: 100},
+		LongTerm: &market.SeriesBundle{ATR: map[string][]float64{"ATR14": {atr}}},
+	}
+}
+
+func testRegimeSchedule() RegimeScheduleConfig {
+	c := RegimeScheduleConfig{
+		Enabled:         true,
+		HighVolDailyPct: 4,
+		HighVolInterval: 3 * time.Minute,
+		QuietDailyPct:   1.5,
+		QuietInterval:   15 * time.Minute,
+	}
+	c.applyDefaults()
+	return c
+}
+
+func TestRegimeScheduleValidate(t *testing.T) {
+	require.NoError(t, RegimeScheduleConfig{}.Validate(0))
+	require.NoError(t, testRegimeSchedule().Validate(0))
+	require.NoError(t, RegimeScheduleConfig{Enabled: true, QuietDailyPct: 1, QuietInterval: time.Hour}.Validate(0))
+
+	c := testRegimeSchedule()
+	c.QuietDailyPct = 5
+	assert.ErrorContains(t, c.Validate(1), "traders[1].regime_schedule.quiet_daily_pct must be below high_vol_daily_pct")
+
+	c = testRegimeSchedule()
+	c.HighVolInterval = 0
+	assert.ErrorContains(t, c.Validate(0), "high_vol_daily_pct and high_vol_interval must be set together")
+
+	assert.ErrorContains(t, RegimeScheduleConfig{Enabled: true}.Validate(0), "needs a high_vol or quiet regime")
+}
+
+func TestRegimeScheduleClassify(t *testing.T) {
+	c := testRegimeSchedule()
+	assert.Equal(t, "BTC", c.ReferenceSymbol)
+
+	regime, vol := c.classify(atrSnapshot(5))
+	assert.Equal(t, RegimeHighVol, regime)
+	assert.InDelta(t, 5, vol, 1e-9)
+	regime, _ = c.classify(atrSnapshot(2))
+	assert.Equal(t, RegimeNormal, regime)
+	regime, _ = c.classify(atrSnapshot(1))
+	assert.Equal(t, RegimeQuiet, regime)
+	regime, _ = c.classify(nil)
+	assert.Equal(t, RegimeNormal, regime, "no ATR reading keeps the base cadence")
+
+	assert.Equal(t, 3*time.Minute, c.interval(RegimeHighVol, 5*time.Minute))
+	assert.Equal(t, 15*time.Minute, c.interval(RegimeQuiet, 5*time.Minute))
+	assert.Equal(t, 5*time.Minute, c.interval(RegimeNormal, 5*time.Minute))
+}
+
+type regimeMarket struct {
+	snap  *market.Snapshot
+	calls []string
+}
+
+func (r *regimeMarket) Snapshot(_ context.Context, symbol string) (*market.Snapshot, error) {
+	r.calls = append(r.calls, symbol)
+	return r.snap, nil
+}
+
+func (r *regimeMarket) ListAssets(context.Context) ([]market.Asset, error) { return nil, nil }
+
+func TestUpdateRegimeAdjustsDecisionInterval(t *testing.T) {
+	m := NewManager(&Config{}, nil, nil, nil, nil)
+	mk := &regimeMarket{snap: atrSnapshot(6)}
+	trader := &VirtualTrader{
+		ID:                   "t1",
+		MarketProvider:       mk,
+		DecisionInterval:     5 * time.Minute,
+		BaseDecisionInterval: 5 * time.Minute,
+		RegimeSchedule:       testRegimeSchedule(),
+	}
+	ctx := context.Background()
+
+	assert.Equal(t, RegimeQuiet, m.updateRegime(ctx, trader, map[string]*market.Snapshot{"BTC": atrSnapshot(1)}))
+	assert.Equal(t, 15*time.Minute, trader.DecisionInterval)
+	assert.Empty(t, mk.calls, "reference snapshot already fetched")
+
+	assert.Equal(t, RegimeHighVol, m.updateRegime(ctx, trader, nil))
+	assert.Equal(t, []string{"BTC"}, mk.calls)
+	assert.Equal(t, 3*time.Minute, trader.DecisionInterval)
+	assert.Equal(t, RegimeHighVol, trader.Regime)
+
+	mk.snap = atrSnapshot(2)
+	assert.Equal(t, RegimeNormal, m.updateRegime(ctx, trader, nil))
+	assert.Equal(t, 5*time.Minute, trader.DecisionInterval)
+
+	trader.RegimeSchedule.Enabled = false
+	assert.Empty(t, m.updateRegime(ctx, trader, nil))
+	assert.Equal(t, 5*time.Minute, trader.DecisionInterval)
+}
@@ -0,0 +1,54 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/risk"
+)
+
+// enforceRiskGuard runs an entry through the trader's execution-time risk
+// limits at the resolved entry price. Resized orders are written back to
+// decision so the order, journal and timeline show what was actually sent;
+// orders that cannot be made to fit are rejected.
+func (m *Manager) enforceRiskGuard(ctx context.Context, trader *VirtualTrader, decision *executorpkg.Decision, price float64) error {
+	cfg := trader.RiskParams.guardConfig()
+	order := risk.Order{
+		Symbol:     decision.Symbol,
+		Long:       decision.Action == "open_long",
+		SizeUSD:    decision.PositionSizeUSD,
+		EntryPrice: price,
+		StopLoss:   decision.StopLoss,
+		TakeProfit: decision.TakeProfit,
+		Leverage:   decision.Leverage,
+	}
+	verdict, err := risk.NewGuard(cfg).Check(order, trader.riskPortfolio())
+	if err != nil {
+		return fmt.Errorf("manager: %w", err)
+	}
+	if verdict.Resized() {
+		logx.WithContext(ctx).Infof("manager: trader %s risk guard resized %s %s: %s", trader.ID, decision.Action, decision.Symbol, strings.Join(verdict.Adjustments, "; "))
+		decision.PositionSizeUSD = verdict.Order.SizeUSD
+		decision.Leverage = verdict.Order.Leverage
+	}
+	return nil
+}
+
+// riskPortfolio returns the trader's equity and per-symbol notional for the
+// risk guard.
+func (t *VirtualTrader) riskPortfolio() risk.Portfolio {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	p := risk.Portfolio{
+		EquityUSD:   t.ResourceAlloc.CurrentEquityUSD,
+		ExposureUSD: make(map[string]float64, len(t.VirtualPositions)),
+	}
+	for _, pos := range t.VirtualPositions {
+		p.ExposureUSD[strings.ToUpper(pos.Symbol)] += pos.NotionalUSD
+	}
+	return p
+}
@@ -0,0 +1,45 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+func TestEnforceRiskGuard(t *testing.T) {
+	m := NewManager(&Config{}, nil, nil, nil, nil)
+	trader := &VirtualTrader{
+		ID:            "t1",
+		RiskParams:    RiskParameters{MaxLossPerTradeUSD: 10, MaxPositionConcentrationPct: 50, MinPositionSizeUSD: 50},
+		ResourceAlloc: ResourceAllocation{CurrentEquityUSD: 1000},
+		VirtualPositions: map[string]VirtualPosition{
+			"BTC": {Symbol: "BTC", Side: "long", NotionalUSD: 300},
+		},
+	}
+	decision := func() *executorpkg.Decision {
+		return &executorpkg.Decision{Symbol: "BTC", Action: "open_long", Leverage: 5, PositionSizeUSD: 400, StopLoss: 95, TakeProfit: 115}
+	}
+
+	d := decision()
+	err := m.enforceRiskGuard(context.Background(), trader, d, 100)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "manager: risk: BTC rejected: loss at stop 20.00 USD above 10.00; BTC exposure 70.00% of equity above 50.00%")
+	assert.Equal(t, 400.0, d.PositionSizeUSD, "rejected decisions are left untouched")
+
+	trader.RiskParams.ResizeOrders = true
+	d = decision()
+	require.NoError(t, m.enforceRiskGuard(context.Background(), trader, d, 100))
+	assert.InDelta(t, 200, d.PositionSizeUSD, 1e-9)
+	assert.Equal(t, 5, d.Leverage)
+}
+
+func TestRiskParametersValidateGuardLimits(t *testing.T) {
+	rp := RiskParameters{MaxPositions: 1, MaxPositionSizeUSD: 100, MajorCoinLeverage: 5, AltcoinLeverage: 5, MinRiskRewardRatio: 2, MinLiquidationDistancePct: 150}
+	assert.EqualError(t, rp.Validate(2), "manager config: traders[2].risk_params.min_liquidation_distance_pct must be between 0 and 100")
+	rp.MinLiquidationDistancePct = 10
+	assert.NoError(t, rp.Validate(2))
+}
@@ -0,0 +1,106 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/prompt"
+)
+
+// AlertRolloutRolledBack fires when a staged prompt rollout falls back to
+// its baseline version for a trader.
+const AlertRolloutRolledBack = "rollout_rolled_back"
+
+// RolloutRolledBackAlert is the template data for AlertRolloutRolledBack.
+type RolloutRolledBackAlert struct {
+	TraderID               string    `doc:"Trader moved back to the baseline"`
+	Rollout                string    `doc:"Rollout name from prompt_versions.rollouts"`
+	Candidate              string    `doc:"Prompt version that was rolled back"`
+	Baseline               string    `doc:"Prompt version now serving every cycle"`
+	Reason                 string    `doc:"Breached rollback threshold"`
+	CandidateCycles        int       `doc:"Cycles served by the candidate before the rollback"`
+	CandidateRejectionRate float64   `doc:"Share of candidate cycles whose decisions failed validation, 0-1"`
+	BaselineRejectionRate  float64   `doc:"Share of baseline cycles whose decisions failed validation, 0-1"`
+	DrawdownPct            float64   `doc:"Equity drawdown from the peak since the rollout started, in percent"`
+	At                     time.Time `doc:"Rollback time"`
+}
+
+// WithPromptRegistry lets staged rollouts in r see each cycle's outcome so
+// they can roll back, and restrict candidate cycles to their symbols. Pass
+// the registry the executors assign versions from.
+func WithPromptRegistry(r *prompt.Registry) Option {
+	return func(m *Manager) {
+		m.prompts = r
+	}
+}
+
+// observeRollout records the outcome of a cycle served by a rollout and
+// alerts when it rolls the trader back to the baseline.
+func (m *Manager) observeRollout(ctx context.Context, t *VirtualTrader, out *executorpkg.FullDecision, decisionErr error, equity float64, at time.Time) {
+	if m.prompts == nil || out.PromptRollout == "" {
+		return
+	}
+	status, rolledBack := m.prompts.ObserveRollout(out.PromptRollout, t.ID, out.PromptVersion, decisionErr != nil, equity, at)
+	if !rolledBack {
+		return
+	}
+	msg := fmt.Sprintf("trader %s rolled back from prompt %s to %s (rollout %s): %s", t.ID, status.Candidate, status.Baseline, status.Rollout, status.Reason)
+	logx.WithContext(ctx).Slowf("manager: %s", msg)
+	m.sendAlert(ctx, Alert{
+		Kind:     AlertRolloutRolledBack,
+		TraderID: t.ID,
+		Message:  msg,
+		Details: map[string]any{
+			"rollout":                  status.Rollout,
+			"candidate":                status.Candidate,
+			"baseline":                 status.Baseline,
+			"candidate_cycles":         status.CandidateCycles,
+			"candidate_rejection_rate": status.CandidateRejectionRate(),
+			"drawdown_pct":             status.DrawdownPct,
+		},
+		At: at,
+		Data: RolloutRolledBackAlert{
+			TraderID:               t.ID,
+			Rollout:                status.Rollout,
+			Candidate:              status.Candidate,
+			Baseline:               status.Baseline,
+			Reason:                 status.Reason,
+			CandidateCycles:        status.CandidateCycles,
+			CandidateRejectionRate: status.CandidateRejectionRate(),
+			BaselineRejectionRate:  status.BaselineRejectionRate(),
+			DrawdownPct:            status.DrawdownPct,
+			At:                     at,
+		},
+	})
+}
+
+// rolloutDecisions drops opens on symbols outside the rollout when the
+// cycle was served by a symbol-scoped candidate. Closes always pass so the
+// candidate can unwind positions it inherited.
+func (m *Manager) rolloutDecisions(b *CycleBreakdown, out *executorpkg.FullDecision, ds []executorpkg.Decision) []executorpkg.Decision {
+	if m.prompts == nil || out.PromptRollout == "" {
+		return ds
+	}
+	ro, ok := m.prompts.Rollout(out.PromptRollout)
+	if !ok || len(ro.Symbols) == 0 || out.PromptVersion != ro.Candidate {
+		return ds
+	}
+	kept := ds[:0:0]
+	var dropped []string
+	for _, d := range ds {
+		if isOpenAction(d.Action) && !ro.AllowsSymbol(d.Symbol) {
+			dropped = append(dropped, d.Symbol)
+			continue
+		}
+		kept = append(kept, d)
+	}
+	if len(dropped) > 0 {
+		m.emitPipeline(b, PipelineStageDeciding, PipelineLevelInfo, fmt.Sprintf("dropped %d open decisions outside rollout %s symbols: %s", len(dropped), ro.Name, strings.Join(dropped, ",")), nil)
+	}
+	return kept
+}
@@ -0,0 +1,75 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/prompt"
+)
+
+func rolloutTestRegistry(t *testing.T) *prompt.Registry {
+	t.Helper()
+	fsys := fstest.MapFS{"a.tmpl": {Data: []byte("A")}, "b.tmpl": {Data: []byte("B")}}
+	reg, err := prompt.NewRegistry(&prompt.Config{
+		Versions: []prompt.Version{{Name: "a", Template: "a.tmpl"}, {Name: "b", Template: "b.tmpl"}},
+		Rollouts: []prompt.Rollout{{
+			Name:      "b-canary",
+			Baseline:  "a",
+			Candidate: "b",
+			Percent:   50,
+			Symbols:   []string{"BTC"},
+			Rollback:  prompt.RollbackPolicy{MaxRejectionRate: 0.5, MinCycles: 2},
+		}},
+	}, fsys)
+	require.NoError(t, err)
+	return reg
+}
+
+func TestObserveRolloutAlertsOnRollback(t *testing.T) {
+	alerter := &recordingAlerter{}
+	m := NewManager(&Config{}, nil, nil, nil, nil, WithAlerter(alerter), WithPromptRegistry(rolloutTestRegistry(t)))
+	trader := &VirtualTrader{ID: "t1"}
+	out := &executorpkg.FullDecision{PromptVersion: "b", PromptRollout: "b-canary"}
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	m.observeRollout(context.Background(), trader, out, errors.New("invalid"), 1000, now)
+	assert.Empty(t, alerter.alerts)
+	m.observeRollout(context.Background(), trader, out, errors.New("invalid"), 1000, now)
+	require.Len(t, alerter.alerts, 1)
+	assert.Equal(t, AlertRolloutRolledBack, alerter.alerts[0].Kind)
+	data, ok := alerter.alerts[0].Data.(RolloutRolledBackAlert)
+	require.True(t, ok)
+	assert.Equal(t, "a", data.Baseline)
+	assert.Equal(t, 1.0, data.CandidateRejectionRate)
+
+	m.observeRollout(context.Background(), trader, out, errors.New("invalid"), 1000, now)
+	assert.Len(t, alerter.alerts, 1, "rollback alerts once")
+}
+
+func TestRolloutDecisionsKeepsCandidateToSymbols(t *testing.T) {
+	rec := &recordingPipeline{}
+	m := NewManager(&Config{}, nil, nil, nil, rec, WithPromptRegistry(rolloutTestRegistry(t)))
+	b := newCycleBreakdown("t1", time.Now())
+	ds := []executorpkg.Decision{
+		{Symbol: "ETH", Action: "close_long"},
+		{Symbol: "BTC", Action: "open_long"},
+		{Symbol: "SOL", Action: "open_short"},
+	}
+
+	kept := m.rolloutDecisions(b, &executorpkg.FullDecision{PromptVersion: "b", PromptRollout: "b-canary"}, ds)
+	require.Len(t, kept, 2)
+	assert.Equal(t, "ETH", kept[0].Symbol, "closes pass")
+	assert.Equal(t, "BTC", kept[1].Symbol)
+	require.Len(t, rec.events, 1)
+	assert.Contains(t, rec.events[0].Message, "outside rollout b-canary symbols: SOL")
+
+	kept = m.rolloutDecisions(b, &executorpkg.FullDecision{PromptVersion: "a", PromptRollout: "b-canary"}, ds)
+	assert.Len(t, kept, 3, "baseline cycles trade every symbol")
+}
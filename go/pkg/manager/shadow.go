@@ -0,0 +1,386 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+const (
+	defaultShadowEvaluationDays = 7
+	// shadowPaperEquityUSD seeds both paper books when the trader has no
+	// allocated equity yet; results are compared in percent, so the seed
+	// only matters relative to decision sizes.
+	shadowPaperEquityUSD = 10000.0
+)
+
+// AlertShadowReport fires when a shadow evaluation window ends.
+const AlertShadowReport = "shadow_report"
+
+// ShadowReport compares the incumbent and candidate paper books at the end
+// of a shadow evaluation. It is also the template data for AlertShadowReport.
+type ShadowReport struct {
+	TraderID                string    `doc:"Trader being evaluated"`
+	IncumbentModel          string    `doc:"Live model"`
+	CandidateModel          string    `doc:"Shadow model"`
+	StartedAt               time.Time `doc:"Start of the evaluation window"`
+	EndedAt                 time.Time `doc:"End of the evaluation window"`
+	Cycles                  int       `doc:"Cycles both models were prompted in"`
+	CandidateErrors         int       `doc:"Cycles the candidate failed to return decisions"`
+	AgreementPct            float64   `doc:"Share of cycles with identical trade actions, 0-100"`
+	IncumbentReturnPct      float64   `doc:"Incumbent paper return in percent"`
+	CandidateReturnPct      float64   `doc:"Candidate paper return in percent"`
+	IncumbentMaxDrawdownPct float64   `doc:"Incumbent paper max drawdown in percent"`
+	CandidateMaxDrawdownPct float64   `doc:"Candidate paper max drawdown in percent"`
+	IncumbentTrades         int       `doc:"Incumbent closed paper trades"`
+	CandidateTrades         int       `doc:"Candidate closed paper trades"`
+	IncumbentWinRatePct     float64   `doc:"Incumbent winning closed trades in percent"`
+	CandidateWinRatePct     float64   `doc:"Candidate winning closed trades in percent"`
+	Promote                 bool      `doc:"Whether the candidate should replace the incumbent"`
+	Reason                  string    `doc:"Why the verdict was reached"`
+	Promoted                bool      `doc:"Whether the candidate was swapped in automatically"`
+}
+
+// ShadowReportRecorder is implemented by persistence backends that keep
+// shadow evaluation reports.
+type ShadowReportRecorder interface {
+	RecordShadowReport(ctx context.Context, report ShadowReport) error
+}
+
+// shadowRun holds one trader's in-flight shadow evaluation. Both books are
+// paper-traded at the cycle's snapshot prices so the comparison is not
+// skewed by live slippage or rejected orders. State is in memory: restarting
+// the manager restarts the evaluation window.
+type shadowRun struct {
+	mu sync.Mutex
+
+	cfg            ShadowConfig
+	executor       executorpkg.Executor
+	incumbentModel string
+	startedAt      time.Time
+	incumbent      *paperBook
+	candidate      *paperBook
+	cycles         int
+	agreed         int
+	candidateErrs  int
+}
+
+func newShadowRun(cfg ShadowConfig, exec executorpkg.Executor, incumbentModel string, equity float64, now time.Time) *shadowRun {
+	if equity <= 0 {
+		equity = shadowPaperEquityUSD
+	}
+	return &shadowRun{
+		cfg:            cfg,
+		executor:       exec,
+		incumbentModel: incumbentModel,
+		startedAt:      now,
+		incumbent:      newPaperBook(equity),
+		candidate:      newPaperBook(equity),
+	}
+}
+
+// observe applies one cycle's decisions from both models. candidate is nil
+// when the shadow model failed to answer.
+func (s *shadowRun) observe(incumbent, candidate []executorpkg.Decision, candidateErr error, prices map[string]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cycles++
+	for _, d := range incumbent {
+		s.incumbent.apply(d, prices[normalizeSymbol(d.Symbol)])
+	}
+	s.incumbent.mark(prices)
+	if candidateErr != nil {
+		s.candidateErrs++
+		s.candidate.mark(prices)
+		return
+	}
+	for _, d := range candidate {
+		s.candidate.apply(d, prices[normalizeSymbol(d.Symbol)])
+	}
+	s.candidate.mark(prices)
+	if tradeActionKey(incumbent) == tradeActionKey(candidate) {
+		s.agreed++
+	}
+}
+
+// due reports whether the evaluation window has elapsed.
+func (s *shadowRun) due(now time.Time) bool {
+	return !now.Before(s.startedAt.AddDate(0, 0, s.cfg.EvaluationDays))
+}
+
+// report summarises both books and reaches a verdict.
+func (s *shadowRun) report(traderID string, now time.Time) ShadowReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := ShadowReport{
+		TraderID:                traderID,
+		IncumbentModel:          s.incumbentModel,
+		CandidateModel:          s.cfg.Model,
+		StartedAt:               s.startedAt,
+		EndedAt:                 now,
+		Cycles:                  s.cycles,
+		CandidateErrors:         s.candidateErrs,
+		IncumbentReturnPct:      s.incumbent.returnPct(),
+		CandidateReturnPct:      s.candidate.returnPct(),
+		IncumbentMaxDrawdownPct: s.incumbent.maxDrawdownPct,
+		CandidateMaxDrawdownPct: s.candidate.maxDrawdownPct,
+		IncumbentTrades:         s.incumbent.trades,
+		CandidateTrades:         s.candidate.trades,
+		IncumbentWinRatePct:     s.incumbent.winRatePct(),
+		CandidateWinRatePct:     s.candidate.winRatePct(),
+	}
+	if s.cycles > 0 {
+		r.AgreementPct = float64(s.agreed) / float64(s.cycles) * 100
+	}
+	r.Promote, r.Reason = shadowVerdict(s.cfg, r)
+	return r
+}
+
+// shadowVerdict promotes the candidate only when it traded enough, answered
+// reliably, stayed within the drawdown limit and beat the incumbent by the
+// required edge.
+func shadowVerdict(cfg ShadowConfig, r ShadowReport) (bool, string) {
+	if r.Cycles == 0 {
+		return false, "no cycles observed"
+	}
+	if r.CandidateErrors*2 > r.Cycles {
+		return false, fmt.Sprintf("candidate failed %d of %d cycles", r.CandidateErrors, r.Cycles)
+	}
+	if r.CandidateTrades < cfg.MinTrades {
+		return false, fmt.Sprintf("candidate closed %d trades, need %d", r.CandidateTrades, cfg.MinTrades)
+	}
+	if cfg.MaxDrawdownPct > 0 && r.CandidateMaxDrawdownPct > cfg.MaxDrawdownPct {
+		return false, fmt.Sprintf("candidate drawdown %.2f%% exceeds %.2f%%", r.CandidateMaxDrawdownPct, cfg.MaxDrawdownPct)
+	}
+	edge := r.CandidateReturnPct - r.IncumbentReturnPct
+	if edge <= cfg.MinEdgePct {
+		return false, fmt.Sprintf("candidate edge %+.2f pts does not exceed %.2f", edge, cfg.MinEdgePct)
+	}
+	return true, fmt.Sprintf("candidate returned %+.2f pts over the incumbent", edge)
+}
+
+// tradeActionKey canonicalises the trade actions of a decision set so two
+// models can be compared regardless of ordering, sizing or commentary.
+func tradeActionKey(ds []executorpkg.Decision) string {
+	keys := make([]string, 0, len(ds))
+	for _, d := range ds {
+		if isTradeAction(d.Action) {
+			keys = append(keys, normalizeSymbol(d.Symbol)+":"+d.Action)
+		}
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+type paperPosition struct {
+	side     string
+	quantity float64
+	entry    float64
+}
+
+// paperBook is a minimal simulated account: opens and closes fill at the
+// given price with no fees, and equity is marked at the latest prices seen.
+type paperBook struct {
+	startEquity    float64
+	realized       float64
+	positions      map[string]paperPosition
+	lastPrice      map[string]float64
+	peak           float64
+	maxDrawdownPct float64
+	trades         int
+	wins           int
+}
+
+func newPaperBook(equity float64) *paperBook {
+	return &paperBook{
+		startEquity: equity,
+		positions:   make(map[string]paperPosition),
+		lastPrice:   make(map[string]float64),
+		peak:        equity,
+	}
+}
+
+func (b *paperBook) apply(d executorpkg.Decision, price float64) {
+	if price <= 0 {
+		return
+	}
+	sym := normalizeSymbol(d.Symbol)
+	pos, held := b.positions[sym]
+	switch d.Action {
+	case "open_long", "open_short":
+		side := strings.TrimPrefix(d.Action, "open_")
+		if d.PositionSizeUSD <= 0 || (held && pos.side != side) {
+			return
+		}
+		qty := d.PositionSizeUSD / price
+		if held {
+			pos.entry = (pos.entry*pos.quantity + price*qty) / (pos.quantity + qty)
+			pos.quantity += qty
+		} else {
+			pos = paperPosition{side: side, quantity: qty, entry: price}
+		}
+		b.positions[sym] = pos
+	case "close_long", "close_short":
+		if !held || pos.side != strings.TrimPrefix(d.Action, "close_") {
+			return
+		}
+		pnl := pos.pnl(price)
+		b.realized += pnl
+		b.trades++
+		if pnl > 0 {
+			b.wins++
+		}
+		delete(b.positions, sym)
+	}
+}
+
+func (p paperPosition) pnl(price float64) float64 {
+	if p.side == "short" {
+		return (p.entry - price) * p.quantity
+	}
+	return (price - p.entry) * p.quantity
+}
+
+// mark revalues open positions and tracks the equity peak and drawdown.
+func (b *paperBook) mark(prices map[string]float64) {
+	for sym, px := range prices {
+		if px > 0 {
+			b.lastPrice[sym] = px
+		}
+	}
+	eq := b.equity()
+	if eq > b.peak {
+		b.peak = eq
+	}
+	if b.peak > 0 {
+		if dd := (b.peak - eq) / b.peak * 100; dd > b.maxDrawdownPct {
+			b.maxDrawdownPct = dd
+		}
+	}
+}
+
+func (b *paperBook) equity() float64 {
+	eq := b.startEquity + b.realized
+	for sym, pos := range b.positions {
+		if px, ok := b.lastPrice[sym]; ok {
+			eq += pos.pnl(px)
+		}
+	}
+	return eq
+}
+
+func (b *paperBook) returnPct() float64 {
+	if b.startEquity <= 0 {
+		return 0
+	}
+	return (b.equity() - b.startEquity) / b.startEquity * 100
+}
+
+func (b *paperBook) winRatePct() float64 {
+	if b.trades == 0 {
+		return 0
+	}
+	return float64(b.wins) / float64(b.trades) * 100
+}
+
+// snapshotPrices extracts the last price per symbol from the cycle context.
+func snapshotPrices(ectx *executorpkg.Context) map[string]float64 {
+	prices := make(map[string]float64, len(ectx.MarketDataMap))
+	for sym, snap := range ectx.MarketDataMap {
+		if snap != nil && snap.Price.Last > 0 {
+			prices[normalizeSymbol(sym)] = snap.Price.Last
+		}
+	}
+	return prices
+}
+
+// newShadow builds the candidate executor for cfg, or returns nil when
+// shadow mode is off.
+func (m *Manager) newShadow(cfg TraderConfig, equity float64) (*shadowRun, error) {
+	if !cfg.Shadow.Enabled {
+		return nil, nil
+	}
+	candidateCfg := cfg
+	candidateCfg.Model = cfg.Shadow.Model
+	candidateCfg.Shadow = ShadowConfig{}
+	exec, err := m.executorFactory.NewExecutor(candidateCfg)
+	if err != nil {
+		return nil, fmt.Errorf("manager: create shadow executor for trader %s: %w", cfg.ID, err)
+	}
+	return newShadowRun(cfg.Shadow, exec, cfg.Model, equity, time.Now()), nil
+}
+
+// runShadow prompts the candidate with the cycle's context, paper-trades
+// both decision sets and, once the evaluation window has elapsed, reports
+// the verdict and optionally promotes the candidate.
+func (m *Manager) runShadow(ctx context.Context, t *VirtualTrader, ectx *executorpkg.Context, perf *executorpkg.PerformanceView, live *executorpkg.FullDecision) {
+	t.mu.RLock()
+	run := t.shadow
+	t.mu.RUnlock()
+	if run == nil || live == nil {
+		return
+	}
+	run.executor.UpdatePerformance(perf)
+	var candidate []executorpkg.Decision
+	out, err := run.executor.GetFullDecision(ectx)
+	if err == nil && out != nil {
+		candidate = out.Decisions
+	} else if err == nil {
+		err = fmt.Errorf("empty decision")
+	}
+	if err != nil {
+		logx.WithContext(ctx).Slowf("manager: trader %s shadow model %s failed: %v", t.ID, run.cfg.Model, err)
+	}
+	run.observe(live.Decisions, candidate, err, snapshotPrices(ectx))
+
+	now := time.Now()
+	if !run.due(now) {
+		return
+	}
+	report := run.report(t.ID, now)
+	retired := run.executor
+	t.mu.Lock()
+	t.shadow = nil
+	if report.Promote && run.cfg.AutoPromote {
+		retired = t.Executor
+		t.Executor = run.executor
+		t.Model = run.cfg.Model
+		report.Promoted = true
+	}
+	t.mu.Unlock()
+	releaseExecutor(retired)
+
+	verdict := "keep " + report.IncumbentModel
+	if report.Promote {
+		verdict = "promote " + report.CandidateModel
+	}
+	m.sendAlert(ctx, Alert{
+		Kind:     AlertShadowReport,
+		TraderID: t.ID,
+		Message:  fmt.Sprintf("trader %s shadow evaluation of %s ended: %s (%s)", t.ID, report.CandidateModel, verdict, report.Reason),
+		At:       now,
+		Data:     report,
+	})
+	m.recordShadowReport(report)
+}
+
+func (m *Manager) recordShadowReport(report ShadowReport) {
+	recorder, ok := m.persistence.(ShadowReportRecorder)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	err := recorder.RecordShadowReport(ctx, report)
+	logPersistenceError(err, "shadow report persistence failed", map[string]any{
+		"trader_id": report.TraderID,
+		"candidate": report.CandidateModel,
+	})
+}
@@ -0,0 +1,135 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+)
+
+type stubExecutor struct {
+	out    *executorpkg.FullDecision
+	err    error
+	closed int
+}
+
+func (s *stubExecutor) GetFullDecision(*executorpkg.Context) (*executorpkg.FullDecision, error) {
+	return s.out, s.err
+}
+func (s *stubExecutor) UpdatePerformance(*executorpkg.PerformanceView) {}
+func (s *stubExecutor) GetConfig() *executorpkg.Config                 { return &executorpkg.Config{} }
+func (s *stubExecutor) Close() error                                   { s.closed++; return nil }
+
+func TestPaperBookRoundTrips(t *testing.T) {
+	b := newPaperBook(1000)
+	b.apply(executorpkg.Decision{Symbol: "btc", Action: "open_long", PositionSizeUSD: 500}, 100)
+	b.mark(map[string]float64{"BTC": 90})
+	assert.InDelta(t, -5, b.returnPct(), 1e-9)
+	assert.InDelta(t, 5, b.maxDrawdownPct, 1e-9)
+
+	// Opposite-side opens are ignored while a position is held.
+	b.apply(executorpkg.Decision{Symbol: "BTC", Action: "open_short", PositionSizeUSD: 500}, 90)
+	b.apply(executorpkg.Decision{Symbol: "BTC", Action: "close_long"}, 110)
+	b.mark(map[string]float64{"BTC": 110})
+	assert.Equal(t, 1, b.trades)
+	assert.InDelta(t, 100, b.winRatePct(), 1e-9)
+	assert.InDelta(t, 5, b.returnPct(), 1e-9)
+
+	b.apply(executorpkg.Decision{Symbol: "ETH", Action: "open_short", PositionSizeUSD: 200}, 50)
+	b.apply(executorpkg.Decision{Symbol: "ETH", Action: "close_short"}, 55)
+	assert.Equal(t, 2, b.trades)
+	assert.InDelta(t, 50, b.winRatePct(), 1e-9)
+	assert.InDelta(t, 3, b.returnPct(), 1e-9)
+}
+
+func TestShadowVerdict(t *testing.T) {
+	cfg := ShadowConfig{MinEdgePct: 1, MaxDrawdownPct: 10, MinTrades: 2}
+	base := ShadowReport{Cycles: 10, CandidateTrades: 3, IncumbentReturnPct: 1, CandidateReturnPct: 3, CandidateMaxDrawdownPct: 4}
+
+	promote, _ := shadowVerdict(cfg, base)
+	assert.True(t, promote)
+
+	cases := map[string]func(r *ShadowReport){
+		"no cycles":   func(r *ShadowReport) { r.Cycles = 0 },
+		"unreliable":  func(r *ShadowReport) { r.CandidateErrors = 6 },
+		"few trades":  func(r *ShadowReport) { r.CandidateTrades = 1 },
+		"drawdown":    func(r *ShadowReport) { r.CandidateMaxDrawdownPct = 12 },
+		"small edge":  func(r *ShadowReport) { r.CandidateReturnPct = 2 },
+		"worse model": func(r *ShadowReport) { r.CandidateReturnPct = -1 },
+	}
+	for name, mutate := range cases {
+		r := base
+		mutate(&r)
+		promote, reason := shadowVerdict(cfg, r)
+		assert.False(t, promote, name)
+		assert.NotEmpty(t, reason, name)
+	}
+}
+
+func TestShadowRunReportsAndPromotes(t *testing.T) {
+	alerter := &recordingAlerter{}
+	m := NewManager(&Config{}, nil, nil, nil, nil, WithAlerter(alerter))
+	live := &stubExecutor{}
+	candidate := &stubExecutor{}
+	run := newShadowRun(ShadowConfig{Model: "candidate", EvaluationDays: 1, AutoPromote: true}, candidate, "incumbent", 0, time.Now())
+	trader := &VirtualTrader{ID: "t1", Executor: live, shadow: run}
+
+	cycle := func(price float64, liveDs, candDs []executorpkg.Decision) {
+		candidate.out = &executorpkg.FullDecision{Decisions: candDs}
+		ectx := &executorpkg.Context{MarketDataMap: map[string]*market.Snapshot{
+			"BTC": {Price: market.PriceInfo{Last: price}},
+		}}
+		m.runShadow(context.Background(), trader, ectx, nil, &executorpkg.FullDecision{Decisions: liveDs})
+	}
+	openShort := []executorpkg.Decision{{Symbol: "BTC", Action: "open_short", PositionSizeUSD: 1000}}
+	openLong := []executorpkg.Decision{{Symbol: "BTC", Action: "open_long", PositionSizeUSD: 1000}}
+	cycle(100, openShort, openLong)
+	cycle(100, nil, nil)
+	require.Empty(t, alerter.alerts)
+
+	run.startedAt = time.Now().Add(-25 * time.Hour)
+	cycle(120, []executorpkg.Decision{{Symbol: "BTC", Action: "close_short"}}, []executorpkg.Decision{{Symbol: "BTC", Action: "close_long"}})
+
+	require.Len(t, alerter.alerts, 1)
+	alert := alerter.alerts[0]
+	assert.Equal(t, AlertShadowReport, alert.Kind)
+	report, ok := alert.Data.(ShadowReport)
+	require.True(t, ok)
+	assert.Equal(t, 3, report.Cycles)
+	assert.InDelta(t, 100.0/3, report.AgreementPct, 1e-9)
+	assert.InDelta(t, -2, report.IncumbentReturnPct, 1e-9)
+	assert.InDelta(t, 2, report.CandidateReturnPct, 1e-9)
+	assert.True(t, report.Promote)
+	assert.True(t, report.Promoted)
+	assert.Same(t, candidate, trader.Executor)
+	assert.Nil(t, trader.shadow)
+	assert.Equal(t, 1, live.closed, "the replaced executor releases its templates")
+	assert.Zero(t, candidate.closed)
+}
+
+func TestShadowRunCountsCandidateErrors(t *testing.T) {
+	m := NewManager(&Config{}, nil, nil, nil, nil)
+	candidate := &stubExecutor{err: errors.New("timeout")}
+	run := newShadowRun(ShadowConfig{Model: "candidate", EvaluationDays: 7}, candidate, "incumbent", 0, time.Now())
+	trader := &VirtualTrader{ID: "t1", shadow: run}
+
+	m.runShadow(context.Background(), trader, &executorpkg.Context{}, nil, &executorpkg.FullDecision{})
+	report := run.report("t1", time.Now())
+	assert.Equal(t, 1, report.CandidateErrors)
+	assert.False(t, report.Promote)
+	assert.NotNil(t, trader.shadow)
+}
+
+func TestShadowConfigValidate(t *testing.T) {
+	assert.NoError(t, ShadowConfig{}.Validate(0, "gpt-5"))
+	assert.ErrorContains(t, ShadowConfig{Enabled: true}.Validate(0, "gpt-5"), "shadow.model is required")
+	assert.ErrorContains(t, ShadowConfig{Enabled: true, Model: "GPT-5"}.Validate(0, "gpt-5"), "must differ")
+	assert.ErrorContains(t, ShadowConfig{Enabled: true, Model: "qwen", MaxDrawdownPct: 120}.Validate(1, "gpt-5"), "traders[1].shadow.max_drawdown_pct")
+	assert.NoError(t, ShadowConfig{Enabled: true, Model: "qwen", EvaluationDays: 7}.Validate(0, "gpt-5"))
+}
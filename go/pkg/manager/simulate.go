@@ -0,0 +1,60 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+	executorpkg "nof0-api/pkg/executor"
+)
+
+// simulateOpen estimates a large open against the book before it is sent and
+// rejects it when the estimated slippage exceeds the trader's cap, the
+// visible book cannot fill it, or the estimated fill pushes margin usage past
+// max_margin_usage_pct. Orders below exec_guards.simulate_above_usd and
+// providers without an exchange.OrderSimulator are not simulated.
+func (m *Manager) simulateOpen(ctx context.Context, trader *VirtualTrader, decision *executorpkg.Decision, qty float64, leverage int) error {
+	guards := trader.ExecGuards
+	if guards.SimulateAboveUSD <= 0 || decision.PositionSizeUSD < guards.SimulateAboveUSD {
+		return nil
+	}
+	sim, ok := trader.ExchangeProvider.(exchange.OrderSimulator)
+	if !ok {
+		provider := trader.Exchange
+		if provider == "" {
+			provider = fmt.Sprintf("%T", trader.ExchangeProvider)
+		}
+		logx.WithContext(ctx).Infof("manager: trader %s pre-trade simulation skipped: provider %s cannot simulate", trader.ID, provider)
+		return nil
+	}
+	isBuy := decision.Action == "open_long"
+	est, err := sim.SimulateOrder(ctx, decision.Symbol, isBuy, qty)
+	if err != nil {
+		return fmt.Errorf("manager: simulate %s %s: %w", decision.Action, decision.Symbol, err)
+	}
+	if !est.FullyFilled() {
+		return fmt.Errorf("manager: simulated %s %s fills %.6f of %.6f within visible depth", decision.Action, decision.Symbol, est.FilledQty, qty)
+	}
+	notional := est.AvgPrice * qty
+	logx.WithContext(ctx).Infof(
+		"manager: trader %s simulated %s %s qty=%.6f avg=%.8f mid=%.8f worst=%.8f slippage_bps=%.2f levels=%d notional=%.2f",
+		trader.ID, decision.Action, decision.Symbol, qty, est.AvgPrice, est.MidPrice, est.WorstPrice, est.SlippageBps, est.Levels, notional,
+	)
+	if guards.MaxSimulatedSlippageBps > 0 && est.SlippageBps > guards.MaxSimulatedSlippageBps+1e-9 {
+		return fmt.Errorf("manager: simulated slippage %.2f bps for %s %s exceeds cap %.2f bps", est.SlippageBps, decision.Action, decision.Symbol, guards.MaxSimulatedSlippageBps)
+	}
+	if rp := trader.RiskParams; rp.MaxMarginUsagePct > 0 && leverage > 0 {
+		trader.mu.RLock()
+		alloc := trader.ResourceAlloc
+		trader.mu.RUnlock()
+		if alloc.CurrentEquityUSD > 0 {
+			usagePct := 100 * (alloc.MarginUsedUSD + notional/float64(leverage)) / alloc.CurrentEquityUSD
+			if usagePct > rp.MaxMarginUsagePct+1e-6 {
+				return fmt.Errorf("manager: simulated fill raises margin usage to %.2f%%, above cap %.2f%%", usagePct, rp.MaxMarginUsagePct)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+	executorpkg "nof0-api/pkg/executor"
+)
+
+type simProvider struct {
+	exchange.Provider
+	est   exchange.OrderEstimate
+	calls int
+}
+
+func (p *simProvider) SimulateOrder(_ context.Context, coin string, isBuy bool, qty float64) (*exchange.OrderEstimate, error) {
+	p.calls++
+	est := p.est
+	est.Coin, est.IsBuy, est.Qty = coin, isBuy, qty
+	if est.FilledQty == 0 {
+		est.FilledQty = qty
+	}
+	return &est, nil
+}
+
+func TestSimulateOpen(t *testing.T) {
+	newTrader := func(p exchange.Provider) *VirtualTrader {
+		return &VirtualTrader{
+			ID:               "t1",
+			ExchangeProvider: p,
+			ExecGuards:       ExecGuards{SimulateAboveUSD: 1000, MaxSimulatedSlippageBps: 20},
+			RiskParams:       RiskParameters{MaxMarginUsagePct: 50},
+			ResourceAlloc:    ResourceAllocation{CurrentEquityUSD: 1000},
+		}
+	}
+	m := NewManager(&Config{}, nil, nil, nil, nil)
+	ctx := context.Background()
+	decision := func(size float64) *executorpkg.Decision {
+		return &executorpkg.Decision{Symbol: "BTC", Action: "open_long", PositionSizeUSD: size}
+	}
+
+	t.Run("small orders skip simulation", func(t *testing.T) {
+		p := &simProvider{}
+		require.NoError(t, m.simulateOpen(ctx, newTrader(p), decision(500), 0.005, 5))
+		assert.Zero(t, p.calls)
+	})
+
+	t.Run("slippage within cap confirms", func(t *testing.T) {
+		p := &simProvider{est: exchange.OrderEstimate{AvgPrice: 100_100, MidPrice: 100_000, SlippageBps: 10}}
+		require.NoError(t, m.simulateOpen(ctx, newTrader(p), decision(2000), 0.02, 5))
+		assert.Equal(t, 1, p.calls)
+	})
+
+	t.Run("slippage beyond cap aborts", func(t *testing.T) {
+		p := &simProvider{est: exchange.OrderEstimate{AvgPrice: 100_300, MidPrice: 100_000, SlippageBps: 30}}
+		err := m.simulateOpen(ctx, newTrader(p), decision(2000), 0.02, 5)
+		assert.ErrorContains(t, err, "simulated slippage 30.00 bps")
+	})
+
+	t.Run("shallow book aborts", func(t *testing.T) {
+		p := &simProvider{est: exchange.OrderEstimate{AvgPrice: 100_000, MidPrice: 100_000, FilledQty: 0.01}}
+		err := m.simulateOpen(ctx, newTrader(p), decision(2000), 0.02, 5)
+		assert.ErrorContains(t, err, "within visible depth")
+	})
+
+	t.Run("estimated margin impact aborts", func(t *testing.T) {
+		p := &simProvider{est: exchange.OrderEstimate{AvgPrice: 100_000, MidPrice: 100_000}}
+		err := m.simulateOpen(ctx, newTrader(p), decision(2000), 0.03, 5)
+		assert.ErrorContains(t, err, "margin usage to 60.00%")
+	})
+
+	t.Run("providers without simulation are not blocked", func(t *testing.T) {
+		require.NoError(t, m.simulateOpen(ctx, newTrader(&skewlessProvider{}), decision(2000), 0.02, 5))
+	})
+}
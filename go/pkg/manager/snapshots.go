@@ -0,0 +1,74 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"nof0-api/pkg/market"
+)
+
+const defaultSnapshotTimeout = 4 * time.Second
+
+// snapshotResult is the outcome of loading one symbol's market snapshot.
+type snapshotResult struct {
+	Symbol   string
+	Snapshot *market.Snapshot
+	Err      error
+}
+
+// fetchSnapshots loads snapshots for symbols using at most concurrency
+// in-flight requests, each bounded by timeout. Results are returned in the
+// same order as symbols regardless of completion order so that downstream
+// prompt assembly stays deterministic.
+func fetchSnapshots(ctx context.Context, provider market.Provider, symbols []string, concurrency int, timeout time.Duration) []snapshotResult {
+	results := make([]snapshotResult, len(symbols))
+	if provider == nil || len(symbols) == 0 {
+		for i, sym := range symbols {
+			results[i] = snapshotResult{Symbol: sym, Err: fmt.Errorf("market provider unavailable")}
+		}
+		return results
+	}
+	if concurrency <= 0 {
+		concurrency = defaultSnapshotConcurrency
+	}
+	if timeout <= 0 {
+		timeout = defaultSnapshotTimeout
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, sym := range symbols {
+		results[i].Symbol = sym
+		select {
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(i int, sym string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			symCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			snap, err := provider.Snapshot(symCtx, sym)
+			if err == nil && snap == nil {
+				err = fmt.Errorf("empty snapshot")
+			}
+			results[i].Snapshot = snap
+			results[i].Err = err
+		}(i, sym)
+	}
+	wg.Wait()
+	return results
+}
+
+// snapshotSettings resolves the configured fetch bounds with defaults.
+func (m *Manager) snapshotSettings() (int, time.Duration) {
+	if m == nil || m.config == nil {
+		return defaultSnapshotConcurrency, defaultSnapshotTimeout
+	}
+	return m.config.Manager.SnapshotConcurrency, m.config.Manager.SnapshotTimeout
+}
@@ -0,0 +1,62 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/market"
+)
+
+type slowMarket struct {
+	delay    map[string]time.Duration
+	fail     map[string]bool
+	inflight int32
+	peak     int32
+}
+
+func (s *slowMarket) Snapshot(ctx context.Context, symbol string) (*market.Snapshot, error) {
+	cur := atomic.AddInt32(&s.inflight, 1)
+	defer atomic.AddInt32(&s.inflight, -1)
+	for {
+		peak := atomic.LoadInt32(&s.peak)
+		if cur <= peak || atomic.CompareAndSwapInt32(&s.peak, peak, cur) {
+			break
+		}
+	}
+	select {
+	case <-time.After(s.delay[symbol]):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if s.fail[symbol] {
+		return nil, errors.New("boom")
+	}
+	return &market.Snapshot{Symbol: symbol}, nil
+}
+
+func (s *slowMarket) ListAssets(ctx context.Context) ([]market.Asset, error) { return nil, nil }
+
+func TestFetchSnapshotsPreservesOrderAndBoundsConcurrency(t *testing.T) {
+	provider := &slowMarket{
+		delay: map[string]time.Duration{"BTC": 30 * time.Millisecond, "ETH": 5 * time.Millisecond, "SOL": 10 * time.Millisecond, "DOGE": time.Second},
+		fail:  map[string]bool{"SOL": true},
+	}
+	symbols := []string{"BTC", "ETH", "SOL", "DOGE", "XRP"}
+
+	results := fetchSnapshots(context.Background(), provider, symbols, 2, 100*time.Millisecond)
+	require.Len(t, results, len(symbols))
+	for i, res := range results {
+		require.Equal(t, symbols[i], res.Symbol)
+	}
+	require.NoError(t, results[0].Err)
+	require.NoError(t, results[1].Err)
+	require.Error(t, results[2].Err)
+	require.ErrorIs(t, results[3].Err, context.DeadlineExceeded)
+	require.NoError(t, results[4].Err)
+	require.LessOrEqual(t, int(atomic.LoadInt32(&provider.peak)), 2)
+}
@@ -0,0 +1,75 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+)
+
+// defaultSubAccountPrefix names per-model sub-accounts: "nof0-<model>".
+const defaultSubAccountPrefix = "nof0-"
+
+// accountName is the configured sub-account name, or the per-model default.
+func (s SubAccountConfig) accountName(model string) string {
+	if name := strings.TrimSpace(s.Name); name != "" {
+		return name
+	}
+	return defaultSubAccountPrefix + strings.TrimSpace(model)
+}
+
+// resolveSubAccount returns a provider trading cfg's sub-account of ex and
+// the sub-account's address. The sub-account is created when missing and
+// topped up to sub_account.fund_usd from the master account. Traders mapped
+// to the same sub-account share one provider. Callers hold m.mu.
+func (m *Manager) resolveSubAccount(ctx context.Context, cfg TraderConfig, ex exchange.Provider) (exchange.Provider, string, error) {
+	name := cfg.SubAccount.accountName(cfg.Model)
+	key := cfg.ExchangeProvider + "/" + name
+	if sub, ok := m.subAccounts[key]; ok {
+		return sub.provider, sub.address, nil
+	}
+	mgr, ok := ex.(exchange.SubAccountManager)
+	if !ok {
+		return nil, "", fmt.Errorf("manager: trader %s sub_account unsupported by exchange provider %s", cfg.ID, cfg.ExchangeProvider)
+	}
+	existing, err := mgr.SubAccounts(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("manager: list sub-accounts for trader %s: %w", cfg.ID, err)
+	}
+	var account *exchange.SubAccount
+	for i := range existing {
+		if existing[i].Name == name {
+			account = &existing[i]
+			break
+		}
+	}
+	if account == nil {
+		created, err := mgr.CreateSubAccount(ctx, name)
+		if err != nil {
+			return nil, "", fmt.Errorf("manager: create sub-account %s for trader %s: %w", name, cfg.ID, err)
+		}
+		account = &created
+		logx.Infof("manager: created sub-account %s (%s) on %s for trader %s", name, created.Address, cfg.ExchangeProvider, cfg.ID)
+	}
+	if shortfall := cfg.SubAccount.FundUSD - account.AccountValue; shortfall > 0 {
+		if err := mgr.TransferToSubAccount(ctx, account.Address, shortfall); err != nil {
+			return nil, "", fmt.Errorf("manager: fund sub-account %s for trader %s: %w", name, cfg.ID, err)
+		}
+		logx.Infof("manager: moved %.2f USD to sub-account %s for trader %s", shortfall, name, cfg.ID)
+	}
+	provider, err := mgr.SubAccountProvider(account.Address)
+	if err != nil {
+		return nil, "", fmt.Errorf("manager: sub-account %s for trader %s: %w", name, cfg.ID, err)
+	}
+	m.subAccounts[key] = subAccountBinding{provider: provider, address: account.Address}
+	return provider, account.Address, nil
+}
+
+// subAccountBinding is a resolved sub-account and the provider trading it.
+type subAccountBinding struct {
+	provider exchange.Provider
+	address  string
+}
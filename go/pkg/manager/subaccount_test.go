@@ -0,0 +1,70 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+// subAccountExchange is a master account that records sub-account calls.
+type subAccountExchange struct {
+	exchange.Provider
+	subs      []exchange.SubAccount
+	created   []string
+	transfers map[string]float64
+}
+
+func (e *subAccountExchange) SubAccounts(context.Context) ([]exchange.SubAccount, error) {
+	return e.subs, nil
+}
+
+func (e *subAccountExchange) CreateSubAccount(_ context.Context, name string) (exchange.SubAccount, error) {
+	e.created = append(e.created, name)
+	sub := exchange.SubAccount{Name: name, Address: "0xsub-" + name}
+	e.subs = append(e.subs, sub)
+	return sub, nil
+}
+
+func (e *subAccountExchange) TransferToSubAccount(_ context.Context, address string, usd float64) error {
+	e.transfers[address] += usd
+	return nil
+}
+
+func (e *subAccountExchange) SubAccountProvider(string) (exchange.Provider, error) {
+	return &skewProvider{}, nil
+}
+
+func TestResolveSubAccountCreatesFundsAndShares(t *testing.T) {
+	master := &subAccountExchange{
+		subs:      []exchange.SubAccount{{Name: "nof0-qwen-max", Address: "0xq", AccountValue: 80}},
+		transfers: map[string]float64{},
+	}
+	m := NewManager(&Config{}, nil, map[string]exchange.Provider{"hl": master}, nil, nil)
+	ctx := context.Background()
+	cfg := TraderConfig{ID: "a", Model: "deepseek-chat", ExchangeProvider: "hl", SubAccount: SubAccountConfig{Enabled: true, FundUSD: 100}}
+
+	p1, addr, err := m.resolveSubAccount(ctx, cfg, master)
+	require.NoError(t, err)
+	assert.Equal(t, "0xsub-nof0-deepseek-chat", addr)
+	assert.Equal(t, []string{"nof0-deepseek-chat"}, master.created)
+	assert.Equal(t, 100.0, master.transfers[addr])
+
+	cfg.ID = "b"
+	p2, _, err := m.resolveSubAccount(ctx, cfg, master)
+	require.NoError(t, err)
+	assert.Same(t, p1, p2, "traders on one model share the sub-account")
+	assert.Len(t, master.created, 1)
+
+	qwen := TraderConfig{ID: "c", Model: "qwen-max", ExchangeProvider: "hl", SubAccount: SubAccountConfig{Enabled: true, FundUSD: 100}}
+	_, addr, err = m.resolveSubAccount(ctx, qwen, master)
+	require.NoError(t, err)
+	assert.Equal(t, "0xq", addr, "existing sub-accounts are reused")
+	assert.Equal(t, 20.0, master.transfers["0xq"], "topped up to fund_usd")
+
+	_, _, err = m.resolveSubAccount(ctx, TraderConfig{ID: "d", Model: "x", ExchangeProvider: "plain", SubAccount: SubAccountConfig{Enabled: true}}, &skewlessProvider{})
+	assert.EqualError(t, err, "manager: trader d sub_account unsupported by exchange provider plain")
+}
@@ -0,0 +1,172 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+// Timeline entry kinds, in the order they occur for one trade.
+const (
+	TimelinePrompt   = "prompt"
+	TimelineDecision = "decision"
+	TimelineRisk     = "risk"
+	TimelineOrder    = "order"
+	TimelineFill     = "fill"
+	TimelineExit     = "exit"
+)
+
+// TimelineEntry is one step in a trader's decision timeline. Entries of one
+// cycle share TraceID; fills and exits carry it when they happen inside a
+// cycle and are empty otherwise (operator flattens, exchange-side stops).
+type TimelineEntry struct {
+	TraderID string
+	TraceID  string
+	Kind     string
+	Symbol   string
+	Summary  string
+	Detail   map[string]any
+	At       time.Time
+}
+
+// TimelineRecorder is implemented by persistence backends that store the
+// decision timeline for the drill-down API.
+type TimelineRecorder interface {
+	RecordTimeline(ctx context.Context, entries []TimelineEntry) error
+}
+
+// decisionTimeline builds the prompt entry and one entry per decision once
+// the model has answered.
+func decisionTimeline(b *CycleBreakdown, at time.Time) []TimelineEntry {
+	prompt := TimelineEntry{
+		TraderID: b.TraderID,
+		TraceID:  b.TraceID,
+		Kind:     TimelinePrompt,
+		Summary:  fmt.Sprintf("prompt %s sent to model", shortDigest(b.Prompt.Digest)),
+		Detail: map[string]any{
+			"prompt_digest":     b.Prompt.Digest,
+			"prompt_tokens":     b.Prompt.PromptTokens,
+			"completion_tokens": b.Prompt.CompletionTokens,
+			"latency_ms":        b.LLM.LatencyMs,
+		},
+		At: at,
+	}
+	if b.LLM.Error != "" {
+		prompt.Summary = "model call failed: " + b.LLM.Error
+		prompt.Detail["error"] = b.LLM.Error
+		return []TimelineEntry{prompt}
+	}
+	entries := []TimelineEntry{prompt}
+	for _, d := range b.Decisions {
+		entries = append(entries, TimelineEntry{
+			TraderID: b.TraderID,
+			TraceID:  b.TraceID,
+			Kind:     TimelineDecision,
+			Symbol:   d.Symbol,
+			Summary:  fmt.Sprintf("%s %s (confidence %d)", d.Action, d.Symbol, d.Confidence),
+			Detail: map[string]any{
+				"action":            d.Action,
+				"confidence":        d.Confidence,
+				"position_size_usd": d.PositionSizeUSD,
+			},
+			At: at,
+		})
+	}
+	return entries
+}
+
+// riskTimelineEntry records the verdict after validation and slot caps.
+func riskTimelineEntry(b *CycleBreakdown, at time.Time) TimelineEntry {
+	summary := "risk " + b.Risk.Verdict
+	if b.Risk.Reason != "" {
+		summary += ": " + b.Risk.Reason
+	}
+	if b.Risk.CappedOpens > 0 {
+		summary += fmt.Sprintf(" (%d opens capped)", b.Risk.CappedOpens)
+	}
+	return TimelineEntry{
+		TraderID: b.TraderID,
+		TraceID:  b.TraceID,
+		Kind:     TimelineRisk,
+		Summary:  summary,
+		Detail: map[string]any{
+			"verdict":      b.Risk.Verdict,
+			"reason":       b.Risk.Reason,
+			"capped_opens": b.Risk.CappedOpens,
+		},
+		At: at,
+	}
+}
+
+// orderTimelineEntry records an order submission. at is the submission time
+// so the order sorts before the fill recorded while it executed.
+func orderTimelineEntry(b *CycleBreakdown, d executorpkg.Decision, execErr error, at time.Time) TimelineEntry {
+	e := TimelineEntry{
+		TraderID: b.TraderID,
+		TraceID:  b.TraceID,
+		Kind:     TimelineOrder,
+		Symbol:   d.Symbol,
+		Summary:  fmt.Sprintf("%s %s submitted", d.Action, d.Symbol),
+		Detail: map[string]any{
+			"action":            d.Action,
+			"position_size_usd": d.PositionSizeUSD,
+			"leverage":          d.Leverage,
+			"entry_price":       d.EntryPrice,
+			"stop_loss":         d.StopLoss,
+			"take_profit":       d.TakeProfit,
+			"result":            "ok",
+		},
+		At: at,
+	}
+	if execErr != nil {
+		e.Summary = fmt.Sprintf("%s %s failed: %v", d.Action, d.Symbol, execErr)
+		e.Detail["result"] = "error"
+		e.Detail["error"] = execErr.Error()
+	}
+	return e
+}
+
+// positionTimelineEntry maps a position event to a fill (open) or exit (close).
+func positionTimelineEntry(event PositionEvent, traceID string) TimelineEntry {
+	kind, verb := TimelineFill, "filled"
+	if event.Event == PositionEventClose {
+		kind, verb = TimelineExit, "closed"
+	}
+	return TimelineEntry{
+		TraderID: event.TraderID,
+		TraceID:  traceID,
+		Kind:     kind,
+		Symbol:   event.Decision.Symbol,
+		Summary:  fmt.Sprintf("%s %s %s %.6f @ %.4f", event.Decision.Action, event.Decision.Symbol, verb, event.FillSize, event.FillPrice),
+		Detail: map[string]any{
+			"action":     event.Decision.Action,
+			"fill_price": event.FillPrice,
+			"fill_size":  event.FillSize,
+			"reason":     event.Decision.Reasoning,
+		},
+		At: event.OccurredAt,
+	}
+}
+
+func shortDigest(digest string) string {
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
+
+func (m *Manager) recordTimeline(entries ...TimelineEntry) {
+	recorder, ok := m.persistence.(TimelineRecorder)
+	if !ok || len(entries) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	err := recorder.RecordTimeline(ctx, entries)
+	logPersistenceError(err, "timeline persistence failed", map[string]any{
+		"trader_id": entries[0].TraderID,
+		"kind":      entries[0].Kind,
+	})
+}
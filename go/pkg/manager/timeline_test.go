@@ -0,0 +1,76 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+type recordingTimeline struct {
+	noopPersistenceService
+	entries []TimelineEntry
+}
+
+func (r *recordingTimeline) RecordTimeline(_ context.Context, entries []TimelineEntry) error {
+	r.entries = append(r.entries, entries...)
+	return nil
+}
+
+func TestDecisionTimelineEntries(t *testing.T) {
+	b := newCycleBreakdown("t1", time.Now())
+	out := &executorpkg.FullDecision{
+		UserPrompt: "prompt",
+		Decisions:  []executorpkg.Decision{{Symbol: "BTC", Action: "open_long", Confidence: 70}},
+	}
+	b.recordDecision(out, nil, time.Time{})
+
+	at := time.Now()
+	entries := decisionTimeline(b, at)
+	require.Len(t, entries, 2)
+	assert.Equal(t, TimelinePrompt, entries[0].Kind)
+	assert.Equal(t, TimelineDecision, entries[1].Kind)
+	assert.Equal(t, "BTC", entries[1].Symbol)
+	for _, e := range entries {
+		assert.Equal(t, b.TraceID, e.TraceID)
+	}
+
+	risk := riskTimelineEntry(b, at)
+	assert.Equal(t, "risk pass", risk.Summary)
+
+	order := orderTimelineEntry(b, out.Decisions[0], errors.New("margin"), at)
+	assert.Equal(t, "error", order.Detail["result"])
+	assert.Equal(t, "open_long BTC failed: margin", order.Summary)
+}
+
+func TestDecisionTimelineModelFailure(t *testing.T) {
+	b := newCycleBreakdown("t1", time.Now())
+	b.recordDecision(nil, errors.New("timeout"), time.Time{})
+	entries := decisionTimeline(b, time.Now())
+	require.Len(t, entries, 1)
+	assert.Equal(t, "model call failed: timeout", entries[0].Summary)
+}
+
+func TestPositionEventsLinkToCycleTrace(t *testing.T) {
+	rec := &recordingTimeline{}
+	m := NewManager(&Config{}, nil, nil, nil, rec)
+	trader := &VirtualTrader{ID: "t1"}
+	m.traders[trader.ID] = trader
+	trader.setCycleTrace("t1-1")
+
+	m.recordPositionEvent(PositionEvent{Trader: trader, Event: PositionEventOpen, Decision: executorpkg.Decision{Symbol: "ETH", Action: "open_short"}, FillPrice: 3000, FillSize: 0.5})
+	m.recordCycleBreakdown(&CycleBreakdown{TraderID: "t1", StartedAt: time.Now()})
+	m.recordPositionEvent(PositionEvent{Trader: trader, Event: PositionEventClose, Decision: executorpkg.Decision{Symbol: "ETH", Action: "close_short"}})
+
+	require.Len(t, rec.entries, 2)
+	assert.Equal(t, TimelineFill, rec.entries[0].Kind)
+	assert.Equal(t, "t1-1", rec.entries[0].TraceID)
+	assert.Equal(t, "t1", rec.entries[0].TraderID)
+	assert.Equal(t, TimelineExit, rec.entries[1].Kind)
+	assert.Empty(t, rec.entries[1].TraceID, "trace is cleared when the cycle ends")
+}
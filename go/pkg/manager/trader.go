@@ -10,6 +10,7 @@ import (
 	executorpkg "nof0-api/pkg/executor"
 	"nof0-api/pkg/journal"
 	"nof0-api/pkg/market"
+	"nof0-api/pkg/strategy"
 )
 
 // TraderState captures a trader's lifecycle state.
@@ -65,6 +66,7 @@ type VirtualPosition struct {
 	Quantity    float64
 	NotionalUSD float64
 	EntryPrice  float64
+	StopLoss    float64 // from the opening decision; 0 when unknown
 	Leverage    int
 	OpenedAt    time.Time
 	UpdatedAt   time.Time
@@ -90,13 +92,18 @@ type VirtualTrader struct {
 
 	ID                   string
 	Name                 string
+	Model                string // live LLM model; changes when a shadow model is promoted
 	Exchange             string
 	ExchangeProvider     exchange.Provider
+	SubAccount           string // exchange sub-account address; empty on the shared account
 	MarketProvider       market.Provider
 	Executor             executorpkg.Executor
 	PromptTemplate       string
 	OrderStyle           OrderStyle
 	MarketIOCSlippageBps float64
+	PartialDataPolicy    PartialDataPolicy
+	MarginMode           exchange.MarginMode
+	PositionMode         exchange.PositionMode
 	RiskParams           RiskParameters
 	ExecGuards           ExecGuards
 	ResourceAlloc        ResourceAllocation
@@ -105,6 +112,14 @@ type VirtualTrader struct {
 	Performance          *PerformanceMetrics
 	LastDecisionAt       time.Time
 	DecisionInterval     time.Duration
+	// BaseDecisionInterval is the configured interval; DecisionInterval
+	// departs from it while RegimeSchedule reports a high-vol or quiet regime.
+	BaseDecisionInterval time.Duration
+	RegimeSchedule       RegimeScheduleConfig
+	Regime               string
+	Triggers             TriggerConfig
+	DataQualityInPrompt  bool
+	Advisors             *strategy.Chain // nil when no advisors are configured
 	CreatedAt            time.Time
 	UpdatedAt            time.Time
 	VirtualPositions     map[string]VirtualPosition
@@ -116,6 +131,35 @@ type VirtualTrader struct {
 	JournalEnabled bool
 	// Pause window for Sharpe gating
 	PauseUntil time.Time
+	// Loss-streak cooldown state: consecutive losing closes and the time new
+	// entries resume once the streak limit was hit.
+	LossStreak         int
+	LastLossPnL        float64
+	EntriesPausedUntil time.Time
+	// PeakEquityUSD is the highest synced equity, the reference for drawdown scaling.
+	PeakEquityUSD float64
+	// Liveness timestamps published as heartbeats
+	LastLLMSuccessAt time.Time
+	LastOrderAt      time.Time
+	// traceID identifies the cycle in progress so fills can be linked to it.
+	traceID string
+	// promptDigest, promptVersion and rawResponse are the model input
+	// digest, prompt version and answer of the cycle in progress, journaled
+	// with the trades it causes.
+	promptDigest  string
+	promptVersion string
+	rawResponse   string
+	// shadow is the candidate model under evaluation, nil when none.
+	shadow *shadowRun
+	// openTimes are successful opens within the last day, oldest first.
+	openTimes []time.Time
+	// triggers tracks event-trigger baselines and queued reasons.
+	triggers triggerState
+	// outputAnomaly checks model output for degenerate patterns; nil when disabled.
+	outputAnomaly *outputAnomalyDetector
+	// promptDrift compares rendered prompts with a baseline; nil when disabled.
+	promptDrift       *promptDriftDetector
+	promptDriftStatus PromptDriftStatus
 }
 
 // Start transitions the trader into running state.
@@ -174,6 +218,9 @@ func (t *VirtualTrader) ShouldMakeDecision() bool {
 	if !t.PauseUntil.IsZero() && time.Now().Before(t.PauseUntil) {
 		return false
 	}
+	if t.triggerDue(time.Now()) {
+		return true
+	}
 	if t.DecisionInterval <= 0 {
 		return true
 	}
@@ -183,6 +230,95 @@ func (t *VirtualTrader) ShouldMakeDecision() bool {
 	return time.Since(t.LastDecisionAt) >= t.DecisionInterval
 }
 
+// RecordLLMSuccess notes a model call that returned a usable response.
+func (t *VirtualTrader) RecordLLMSuccess(ts time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.LastLLMSuccessAt = ts
+}
+
+// RecordOrder notes an order accepted by the exchange.
+func (t *VirtualTrader) RecordOrder(ts time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.LastOrderAt = ts
+}
+
+// RecordOpen counts a successful new position toward the opens throttle.
+func (t *VirtualTrader) RecordOpen(ts time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.openTimes = append(pruneOpenTimes(t.openTimes, ts), ts)
+}
+
+// openAllowance reports the opens left in the rolling hour and day windows,
+// or nil when the trader has no throttle configured.
+func (t *VirtualTrader) openAllowance(now time.Time) *executorpkg.OpenAllowance {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	hourLimit, dayLimit := t.ExecGuards.MaxOpensPerHour, t.ExecGuards.MaxOpensPerDay
+	if hourLimit <= 0 && dayLimit <= 0 {
+		return nil
+	}
+	t.openTimes = pruneOpenTimes(t.openTimes, now)
+	lastHour := 0
+	for _, ts := range t.openTimes {
+		if now.Sub(ts) < time.Hour {
+			lastHour++
+		}
+	}
+	return &executorpkg.OpenAllowance{
+		HourLimit:     hourLimit,
+		HourRemaining: max(hourLimit-lastHour, 0),
+		DayLimit:      dayLimit,
+		DayRemaining:  max(dayLimit-len(t.openTimes), 0),
+	}
+}
+
+// pruneOpenTimes drops opens older than a day.
+func pruneOpenTimes(times []time.Time, now time.Time) []time.Time {
+	cut := 0
+	for cut < len(times) && now.Sub(times[cut]) >= 24*time.Hour {
+		cut++
+	}
+	return times[cut:]
+}
+
+func (t *VirtualTrader) setCycleTrace(id string) {
+	t.mu.Lock()
+	t.traceID = id
+	t.promptDigest = ""
+	t.promptVersion = ""
+	t.rawResponse = ""
+	t.mu.Unlock()
+}
+
+func (t *VirtualTrader) setCycleOutput(promptDigest, promptVersion, rawResponse string) {
+	t.mu.Lock()
+	t.promptDigest = promptDigest
+	t.promptVersion = promptVersion
+	t.rawResponse = rawResponse
+	t.mu.Unlock()
+}
+
+func (t *VirtualTrader) cycleOutput() (promptDigest, promptVersion, rawResponse string) {
+	if t == nil {
+		return "", "", ""
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.promptDigest, t.promptVersion, t.rawResponse
+}
+
+func (t *VirtualTrader) cycleTrace() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.traceID
+}
+
 // RecordDecision updates timestamps after a decision round completes.
 func (t *VirtualTrader) RecordDecision(ts time.Time) {
 	t.mu.Lock()
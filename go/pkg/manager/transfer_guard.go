@@ -0,0 +1,73 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+)
+
+// AlertTransferBlocked fires when an exchange provider refuses to sign a
+// withdrawal-capable action that is not in its allowed_transfers.
+const AlertTransferBlocked = "transfer_blocked"
+
+// TransferBlockedAlert is the template data for AlertTransferBlocked.
+type TransferBlockedAlert struct {
+	Provider string    `doc:"Exchange provider name from exchange.yaml"`
+	Action   string    `doc:"Native exchange action that was refused, e.g. withdraw3"`
+	At       time.Time `doc:"Time the action was refused"`
+}
+
+// watchTransferGuards alerts whenever a guarded provider refuses a transfer.
+// Nothing in the trading path withdraws, so a refusal means a bug or a
+// compromised process and always warrants attention.
+func (m *Manager) watchTransferGuards() {
+	for _, provider := range m.exchangeProviders {
+		guarded, ok := provider.(exchange.TransferGuarded)
+		if !ok {
+			continue
+		}
+		guarded.OnTransferBlocked(func(ev exchange.TransferBlocked) {
+			m.sendAlert(context.Background(), Alert{
+				Kind:    AlertTransferBlocked,
+				Message: fmt.Sprintf("exchange %s refused transfer action %s", ev.Provider, ev.Action),
+				Details: map[string]any{
+					"provider": ev.Provider,
+					"action":   ev.Action,
+				},
+				At:   ev.At,
+				Data: TransferBlockedAlert{Provider: ev.Provider, Action: ev.Action, At: ev.At},
+			})
+		})
+	}
+}
+
+// checkKeyPermissions warns about providers whose signing key can do more
+// than trade, e.g. a master key used where an agent wallet would do.
+func (m *Manager) checkKeyPermissions(ctx context.Context) {
+	names := make([]string, 0, len(m.exchangeProviders))
+	for name := range m.exchangeProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		checker, ok := m.exchangeProviders[name].(exchange.PermissionChecker)
+		if !ok {
+			continue
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, clockSyncTimeout)
+		perms, err := checker.CheckPermissions(checkCtx)
+		cancel()
+		if err != nil {
+			logx.WithContext(ctx).Slowf("manager: permission check %s: %v", name, err)
+			continue
+		}
+		if perms.Excessive() {
+			logx.WithContext(ctx).Errorf("manager: exchange %s key (role %q) can transfer or withdraw funds; trading only needs an agent/API key without those rights", name, perms.Role)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+type guardedProvider struct {
+	exchange.Provider
+	guard *exchange.TransferGuard
+}
+
+func (p *guardedProvider) OnTransferBlocked(fn func(exchange.TransferBlocked)) {
+	p.guard.OnTransferBlocked(fn)
+}
+
+func TestTransferBlockedAlerts(t *testing.T) {
+	guard, err := exchange.NewTransferGuard("hl", []string{"withdraw3"}, nil)
+	require.NoError(t, err)
+	alerter := &recordingAlerter{}
+	NewManager(&Config{}, nil, map[string]exchange.Provider{"hl": &guardedProvider{guard: guard}}, nil, nil, WithAlerter(alerter))
+
+	require.ErrorIs(t, guard.Check("withdraw3"), exchange.ErrTransferBlocked)
+	require.Len(t, alerter.alerts, 1)
+	alert := alerter.alerts[0]
+	assert.Equal(t, AlertTransferBlocked, alert.Kind)
+	assert.Equal(t, TransferBlockedAlert{Provider: "hl", Action: "withdraw3", At: alert.At}, alert.Data)
+}
@@ -0,0 +1,299 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/market"
+)
+
+// Decision trigger kinds, used as the prefix of trigger reasons.
+const (
+	TriggerPriceMove          = "price_move"
+	TriggerFundingFlip        = "funding_flip"
+	TriggerStopProximity      = "stop_proximity"
+	TriggerLiquidationCluster = "liquidation_cluster"
+)
+
+const (
+	defaultTriggerCheckInterval = 30 * time.Second
+	defaultTriggerMinGap        = time.Minute
+	// triggerMonitorTick is how often the monitor looks for traders due a check.
+	triggerMonitorTick = 5 * time.Second
+)
+
+// TriggerConfig forces a decision cycle ahead of the timer when watched
+// markets move. Watched symbols are the trader's open positions plus Symbols.
+// Each threshold is optional; 0 (or false) disables that trigger.
+type TriggerConfig struct {
+	Enabled bool     `yaml:"enabled" json:"enabled"`
+	Symbols []string `yaml:"symbols" json:"symbols"`
+	// PriceMovePct fires on a move of at least this % since the last decision.
+	PriceMovePct float64 `yaml:"price_move_pct" json:"price_move_pct"`
+	// FundingFlip fires when a funding rate changes sign.
+	FundingFlip bool `yaml:"funding_flip" json:"funding_flip"`
+	// StopProximityPct fires when the mark comes within this % of a position's
+	// stop loss.
+	StopProximityPct float64 `yaml:"stop_proximity_pct" json:"stop_proximity_pct"`
+	// LiquidationOIDropPct fires when open interest falls at least this %
+	// between checks. The market feed carries no liquidations, so a cluster is
+	// inferred from positions being forcibly removed from open interest.
+	LiquidationOIDropPct float64       `yaml:"liquidation_oi_drop_pct" json:"liquidation_oi_drop_pct"`
+	CheckInterval        time.Duration `yaml:"-" json:"check_interval_duration"`
+	// MinGap is the least time between decisions, so bursts of triggers
+	// cannot drive the model faster than this.
+	MinGap time.Duration `yaml:"-" json:"min_gap_duration"`
+
+	CheckIntervalRaw string `yaml:"check_interval" json:"check_interval"`
+	MinGapRaw        string `yaml:"min_gap" json:"min_gap"`
+}
+
+func (c *TriggerConfig) applyDefaults() {
+	symbols := c.Symbols[:0]
+	for _, sym := range c.Symbols {
+		if sym = strings.TrimSpace(sym); sym != "" {
+			symbols = append(symbols, sym)
+		}
+	}
+	c.Symbols = symbols
+}
+
+func (c *TriggerConfig) parseDurations(index int) error {
+	var err error
+	c.CheckInterval, c.MinGap = defaultTriggerCheckInterval, defaultTriggerMinGap
+	if strings.TrimSpace(c.CheckIntervalRaw) != "" {
+		if c.CheckInterval, err = parsePositiveDuration(fmt.Sprintf("traders[%d].triggers.check_interval", index), c.CheckIntervalRaw); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(c.MinGapRaw) != "" {
+		if c.MinGap, err = parsePositiveDuration(fmt.Sprintf("traders[%d].triggers.min_gap", index), c.MinGapRaw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks the trigger thresholds.
+func (c TriggerConfig) Validate(index int) error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.PriceMovePct < 0 || c.StopProximityPct < 0 || c.LiquidationOIDropPct < 0 {
+		return fmt.Errorf("manager config: traders[%d].triggers thresholds cannot be negative", index)
+	}
+	if c.PriceMovePct == 0 && !c.FundingFlip && c.StopProximityPct == 0 && c.LiquidationOIDropPct == 0 {
+		return fmt.Errorf("manager config: traders[%d].triggers needs at least one trigger when enabled", index)
+	}
+	return nil
+}
+
+// triggerState holds per-symbol baselines between checks and the reasons
+// waiting for the next cycle. Guarded by the trader's mutex.
+type triggerState struct {
+	checkedAt time.Time
+	basePrice map[string]float64 // price at the last decision
+	funding   map[string]float64 // last non-zero funding rate
+	oi        map[string]float64 // open interest at the last check
+	nearStop  map[string]bool    // stop proximity already reported
+	pending   []string
+}
+
+func (s *triggerState) init() {
+	if s.basePrice == nil {
+		s.basePrice = make(map[string]float64)
+		s.funding = make(map[string]float64)
+		s.oi = make(map[string]float64)
+		s.nearStop = make(map[string]bool)
+	}
+}
+
+// evaluate compares snap with the baselines, updates them, and returns the
+// reasons the trigger fired.
+func (c TriggerConfig) evaluate(s *triggerState, sym string, snap *market.Snapshot, pos *VirtualPosition) []string {
+	s.init()
+	var reasons []string
+	px := snap.Price.Last
+	if c.PriceMovePct > 0 && px > 0 {
+		if base := s.basePrice[sym]; base > 0 {
+			if move := 100 * (px - base) / base; math.Abs(move) >= c.PriceMovePct {
+				reasons = append(reasons, fmt.Sprintf("%s %s %+.2f%% since last decision", TriggerPriceMove, sym, move))
+				s.basePrice[sym] = px
+			}
+		} else {
+			s.basePrice[sym] = px
+		}
+	}
+	if c.FundingFlip && snap.Funding != nil && snap.Funding.Rate != 0 {
+		rate := snap.Funding.Rate
+		if prev := s.funding[sym]; prev*rate < 0 {
+			dir := "positive"
+			if rate < 0 {
+				dir = "negative"
+			}
+			reasons = append(reasons, fmt.Sprintf("%s %s funding turned %s (%.4f%% -> %.4f%%)", TriggerFundingFlip, sym, dir, prev*100, rate*100))
+		}
+		s.funding[sym] = rate
+	}
+	if c.StopProximityPct > 0 && pos != nil && pos.StopLoss > 0 && px > 0 {
+		dist := 100 * (px - pos.StopLoss) / px
+		if pos.Side == "short" {
+			dist = -dist
+		}
+		near := dist <= c.StopProximityPct
+		if near && !s.nearStop[sym] {
+			reasons = append(reasons, fmt.Sprintf("%s %s %s mark %.4f within %.2f%% of stop %.4f", TriggerStopProximity, sym, pos.Side, px, math.Max(dist, 0), pos.StopLoss))
+		}
+		s.nearStop[sym] = near
+	}
+	if c.LiquidationOIDropPct > 0 && snap.OpenInterest != nil && snap.OpenInterest.Latest > 0 {
+		cur := snap.OpenInterest.Latest
+		if prev := s.oi[sym]; prev > 0 {
+			if drop := 100 * (prev - cur) / prev; drop >= c.LiquidationOIDropPct {
+				reasons = append(reasons, fmt.Sprintf("%s %s open interest -%.2f%% since last check", TriggerLiquidationCluster, sym, drop))
+			}
+		}
+		s.oi[sym] = cur
+	}
+	return reasons
+}
+
+// nearestStopLeg picks the position leg closest to its stop at px; a symbol
+// has two legs only on a hedge-mode account.
+func nearestStopLeg(legs []VirtualPosition, px float64) *VirtualPosition {
+	var best *VirtualPosition
+	bestDist := math.Inf(1)
+	for i := range legs {
+		dist := math.Inf(1)
+		if legs[i].StopLoss > 0 && px > 0 {
+			dist = (px - legs[i].StopLoss) / px
+			if legs[i].Side == "short" {
+				dist = -dist
+			}
+		}
+		if best == nil || dist < bestDist {
+			best, bestDist = &legs[i], dist
+		}
+	}
+	return best
+}
+
+// runTriggerMonitor checks each trader's event triggers on its check interval
+// until the manager stops.
+func (m *Manager) runTriggerMonitor(ctx context.Context) {
+	ticker := time.NewTicker(triggerMonitorTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopChan:
+			return
+		case now := <-ticker.C:
+			for _, t := range m.ledTraders() {
+				m.checkTriggers(ctx, t, now)
+			}
+		}
+	}
+}
+
+// checkTriggers evaluates t's triggers against fresh snapshots when its check
+// interval has elapsed, queueing fired reasons for the next cycle.
+func (m *Manager) checkTriggers(ctx context.Context, t *VirtualTrader, now time.Time) {
+	cfg := t.Triggers
+	if !cfg.Enabled || t.MarketProvider == nil {
+		return
+	}
+	interval := cfg.CheckInterval
+	if interval <= 0 {
+		interval = defaultTriggerCheckInterval
+	}
+	t.mu.Lock()
+	due := now.Sub(t.triggers.checkedAt) >= interval
+	if due {
+		t.triggers.checkedAt = now
+	}
+	t.mu.Unlock()
+	if !due {
+		return
+	}
+
+	positions := m.snapshotVirtualPositions(t)
+	legs := make(map[string][]VirtualPosition, len(positions))
+	seen := make(map[string]struct{}, len(positions)+len(cfg.Symbols))
+	symbols := make([]string, 0, len(positions)+len(cfg.Symbols))
+	for _, pos := range positions {
+		key := normalizeSymbol(pos.Symbol)
+		legs[key] = append(legs[key], pos)
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			symbols = append(symbols, pos.Symbol)
+		}
+	}
+	sort.Strings(symbols)
+	for _, sym := range cfg.Symbols {
+		if _, ok := seen[normalizeSymbol(sym)]; !ok {
+			seen[normalizeSymbol(sym)] = struct{}{}
+			symbols = append(symbols, sym)
+		}
+	}
+	concurrency, timeout := m.snapshotSettings()
+	var fired []string
+	for _, res := range fetchSnapshots(ctx, t.MarketProvider, symbols, concurrency, timeout) {
+		if res.Err != nil || res.Snapshot == nil {
+			continue
+		}
+		key := normalizeSymbol(res.Symbol)
+		pos := nearestStopLeg(legs[key], res.Snapshot.Price.Last)
+		t.mu.Lock()
+		fired = append(fired, cfg.evaluate(&t.triggers, key, res.Snapshot, pos)...)
+		t.mu.Unlock()
+	}
+	if len(fired) == 0 {
+		return
+	}
+	t.mu.Lock()
+	t.triggers.pending = append(t.triggers.pending, fired...)
+	t.mu.Unlock()
+	logx.WithContext(ctx).Infof("manager: trader %s decision triggered: %s", t.ID, strings.Join(fired, "; "))
+}
+
+// resetTriggerBaselines records this cycle's prices as the reference for
+// price-move triggers.
+func (t *VirtualTrader) resetTriggerBaselines(snaps map[string]*market.Snapshot) {
+	if !t.Triggers.Enabled || t.Triggers.PriceMovePct <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.triggers.init()
+	for sym, snap := range snaps {
+		if snap != nil && snap.Price.Last > 0 {
+			t.triggers.basePrice[normalizeSymbol(sym)] = snap.Price.Last
+		}
+	}
+}
+
+// triggerDue reports whether queued triggers may start a cycle now.
+// Callers hold t.mu.
+func (t *VirtualTrader) triggerDue(now time.Time) bool {
+	if len(t.triggers.pending) == 0 {
+		return false
+	}
+	return t.LastDecisionAt.IsZero() || now.Sub(t.LastDecisionAt) >= t.Triggers.MinGap
+}
+
+// takeTriggers returns and clears the queued trigger reasons.
+func (t *VirtualTrader) takeTriggers() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	reasons := t.triggers.pending
+	t.triggers.pending = nil
+	return reasons
+}
@@ -0,0 +1,83 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/market"
+)
+
+func TestTriggerConfigValidate(t *testing.T) {
+	require.NoError(t, TriggerConfig{}.Validate(0))
+	require.NoError(t, TriggerConfig{Enabled: true, FundingFlip: true}.Validate(0))
+	assert.ErrorContains(t, TriggerConfig{Enabled: true}.Validate(2), "traders[2].triggers needs at least one trigger")
+	assert.ErrorContains(t, TriggerConfig{Enabled: true, PriceMovePct: -1}.Validate(0), "cannot be negative")
+}
+
+func TestTriggerEvaluate(t *testing.T) {
+	cfg := TriggerConfig{Enabled: true, PriceMovePct: 2, FundingFlip: true, StopProximityPct: 0.5, LiquidationOIDropPct: 3}
+	var st triggerState
+	snap := func(px, funding, oi float64) *market.Snapshot {
+		return &market.Snapshot{
+			Price:        market.PriceInfo{Last: px},
+			Funding:      &market.FundingInfo{Rate: funding},
+			OpenInterest: &market.OpenInterestInfo{Latest: oi},
+		}
+	}
+	long := &VirtualPosition{Symbol: "BTC", Side: "long", StopLoss: 97}
+
+	assert.Empty(t, cfg.evaluate(&st, "BTC", snap(100, 0.0001, 1000), long), "first check only sets baselines")
+	assert.Empty(t, cfg.evaluate(&st, "BTC", snap(101, 0.0001, 990), long))
+
+	reasons := cfg.evaluate(&st, "BTC", snap(97.4, -0.00005, 950), long)
+	require.Len(t, reasons, 4)
+	assert.Equal(t, "price_move BTC -2.60% since last decision", reasons[0])
+	assert.Equal(t, "funding_flip BTC funding turned negative (0.0100% -> -0.0050%)", reasons[1])
+	assert.Contains(t, reasons[2], "stop_proximity BTC long mark 97.4000 within 0.41% of stop 97.0000")
+	assert.Contains(t, reasons[3], "liquidation_cluster BTC open interest -4.04%")
+
+	assert.Empty(t, cfg.evaluate(&st, "BTC", snap(97.3, -0.00005, 950), long), "each event is reported once")
+
+	short := &VirtualPosition{Symbol: "ETH", Side: "short", StopLoss: 105}
+	assert.Empty(t, cfg.evaluate(&st, "ETH", snap(100, 0, 0), short))
+	reasons = cfg.evaluate(&st, "ETH", snap(104.6, 0, 0), short)
+	require.Len(t, reasons, 2)
+	assert.Contains(t, reasons[1], "stop_proximity ETH short")
+}
+
+func TestCheckTriggersQueuesCycle(t *testing.T) {
+	m := NewManager(&Config{}, nil, nil, nil, nil)
+	mk := &regimeMarket{snap: &market.Snapshot{Price: market.PriceInfo{Last: 100}}}
+	trader := &VirtualTrader{
+		ID:               "t1",
+		State:            TraderStateRunning,
+		MarketProvider:   mk,
+		DecisionInterval: time.Hour,
+		LastDecisionAt:   time.Now(),
+		Triggers:         TriggerConfig{Enabled: true, Symbols: []string{"BTC"}, PriceMovePct: 1, CheckInterval: time.Second, MinGap: time.Nanosecond},
+	}
+	ctx := context.Background()
+	now := time.Now()
+
+	trader.resetTriggerBaselines(map[string]*market.Snapshot{"BTC": mk.snap})
+	m.checkTriggers(ctx, trader, now)
+	assert.False(t, trader.ShouldMakeDecision())
+
+	mk.snap = &market.Snapshot{Price: market.PriceInfo{Last: 98.5}}
+	m.checkTriggers(ctx, trader, now.Add(500*time.Millisecond))
+	assert.False(t, trader.ShouldMakeDecision(), "check interval not elapsed")
+
+	m.checkTriggers(ctx, trader, now.Add(2*time.Second))
+	assert.True(t, trader.ShouldMakeDecision())
+	assert.Equal(t, []string{"price_move BTC -1.50% since last decision"}, trader.takeTriggers())
+	assert.False(t, trader.ShouldMakeDecision())
+
+	trader.Triggers.MinGap = time.Hour
+	mk.snap = &market.Snapshot{Price: market.PriceInfo{Last: 97}}
+	m.checkTriggers(ctx, trader, now.Add(4*time.Second))
+	assert.False(t, trader.ShouldMakeDecision(), "min gap holds triggered cycles back")
+}
@@ -0,0 +1,146 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+)
+
+// Position sizing modes.
+const (
+	// SizingFixedRisk leaves sizing to the model within the fixed caps.
+	SizingFixedRisk = "fixed_risk"
+	// SizingVolTarget sizes each open so its expected daily move is a
+	// constant share of equity, using ATR as the volatility estimate.
+	SizingVolTarget = "vol_target"
+)
+
+const (
+	defaultVolTargetATRWindow = "ATR14"
+	// defaultVolTargetBarsPerDay matches the 4h long-term series.
+	defaultVolTargetBarsPerDay = 6
+)
+
+// PositionSizing selects how open sizes are derived.
+type PositionSizing struct {
+	Mode string `yaml:"mode" json:"mode"`
+	// TargetDailyVolPct is the expected daily move per position, in % of equity.
+	TargetDailyVolPct float64 `yaml:"target_daily_vol_pct" json:"target_daily_vol_pct"`
+	// ATRWindow names the ATR series used as the volatility estimate.
+	ATRWindow string `yaml:"atr_window" json:"atr_window"`
+	// BarsPerDay is the number of ATR bars in a day (6 for 4h bars).
+	BarsPerDay float64 `yaml:"bars_per_day" json:"bars_per_day"`
+}
+
+func (p *PositionSizing) applyDefaults() {
+	p.Mode = strings.ToLower(strings.TrimSpace(p.Mode))
+	if p.Mode == "" {
+		p.Mode = SizingFixedRisk
+	}
+	if p.Mode != SizingVolTarget {
+		return
+	}
+	if p.ATRWindow == "" {
+		p.ATRWindow = defaultVolTargetATRWindow
+	}
+	if p.BarsPerDay == 0 {
+		p.BarsPerDay = defaultVolTargetBarsPerDay
+	}
+}
+
+// Validate checks the sizing mode and its vol-target parameters.
+func (p PositionSizing) Validate(index int) error {
+	switch p.Mode {
+	case "", SizingFixedRisk:
+		return nil
+	case SizingVolTarget:
+	default:
+		return fmt.Errorf("manager config: traders[%d].risk_params.sizing.mode must be %s or %s", index, SizingFixedRisk, SizingVolTarget)
+	}
+	if p.TargetDailyVolPct <= 0 || p.TargetDailyVolPct > 100 {
+		return fmt.Errorf("manager config: traders[%d].risk_params.sizing.target_daily_vol_pct must be between 0 and 100", index)
+	}
+	if p.BarsPerDay < 0 {
+		return fmt.Errorf("manager config: traders[%d].risk_params.sizing.bars_per_day must be positive", index)
+	}
+	return nil
+}
+
+// VolTarget reports whether volatility-targeted sizing is active.
+func (p PositionSizing) VolTarget() bool {
+	return p.Mode == SizingVolTarget
+}
+
+// targetNotional returns the vol-target position size for snap, or false when
+// the snapshot lacks an ATR reading.
+func (p PositionSizing) targetNotional(equity float64, snap *market.Snapshot) (float64, bool) {
+	if !p.VolTarget() || snap == nil {
+		return 0, false
+	}
+	window, bars := p.ATRWindow, p.BarsPerDay
+	if window == "" {
+		window = defaultVolTargetATRWindow
+	}
+	if bars <= 0 {
+		bars = defaultVolTargetBarsPerDay
+	}
+	vol := market.ATRDailyVolatility(snap.LatestATR(window), snap.Price.Last, bars)
+	notional := market.VolTargetNotional(equity, p.TargetDailyVolPct, vol)
+	return notional, notional > 0
+}
+
+// volTargetSize returns the open size for symbol under vol-target sizing,
+// scaled with drawdown and capped by max_position_size_usd.
+func (t *VirtualTrader) volTargetSize(snap *market.Snapshot) (float64, bool) {
+	t.mu.RLock()
+	equity := t.ResourceAlloc.CurrentEquityUSD
+	sizing, maxSize := t.RiskParams.Sizing, t.RiskParams.MaxPositionSizeUSD
+	t.mu.RUnlock()
+	notional, ok := sizing.targetNotional(equity, snap)
+	if !ok {
+		return 0, false
+	}
+	_, scale := t.riskScale()
+	notional *= scale
+	if maxSize > 0 {
+		notional = math.Min(notional, maxSize*scale)
+	}
+	return notional, notional > 0
+}
+
+// volTargetSizes computes vol-target open sizes for every snapshot that has
+// an ATR reading, for the prompt's risk section.
+func (t *VirtualTrader) volTargetSizes(snaps map[string]*market.Snapshot) map[string]float64 {
+	if !t.RiskParams.Sizing.VolTarget() {
+		return nil
+	}
+	out := make(map[string]float64, len(snaps))
+	for sym, snap := range snaps {
+		if size, ok := t.volTargetSize(snap); ok {
+			out[sym] = size
+		}
+	}
+	return out
+}
+
+// applyVolTargetSize replaces the model's open size with the vol-target size
+// for the decision's symbol.
+func (m *Manager) applyVolTargetSize(ctx context.Context, trader *VirtualTrader, decision *executorpkg.Decision) error {
+	snap, err := trader.MarketProvider.Snapshot(ctx, decision.Symbol)
+	if err != nil {
+		return fmt.Errorf("manager: vol-target sizing snapshot for %s: %w", decision.Symbol, err)
+	}
+	size, ok := trader.volTargetSize(snap)
+	if !ok {
+		return fmt.Errorf("manager: vol-target sizing for %s: no %s reading", decision.Symbol, trader.RiskParams.Sizing.ATRWindow)
+	}
+	logx.WithContext(ctx).Infof("manager: trader %s vol-target size symbol=%s model_usd=%.2f sized_usd=%.2f", trader.ID, decision.Symbol, decision.PositionSizeUSD, size)
+	decision.PositionSizeUSD = size
+	return nil
+}
@@ -0,0 +1,48 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/market"
+)
+
+func TestPositionSizingValidate(t *testing.T) {
+	require.NoError(t, PositionSizing{}.Validate(0))
+	require.NoError(t, PositionSizing{Mode: SizingVolTarget, TargetDailyVolPct: 1}.Validate(0))
+
+	assert.ErrorContains(t, PositionSizing{Mode: "kelly"}.Validate(2), "traders[2].risk_params.sizing.mode must be fixed_risk or vol_target")
+	assert.ErrorContains(t, PositionSizing{Mode: SizingVolTarget}.Validate(0), "target_daily_vol_pct must be between 0 and 100")
+}
+
+func TestVirtualTraderVolTargetSize(t *testing.T) {
+	sizing := PositionSizing{Mode: " Vol_Target ", TargetDailyVolPct: 1}
+	sizing.applyDefaults()
+	assert.Equal(t, "ATR14", sizing.ATRWindow)
+	assert.Equal(t, 6.0, sizing.BarsPerDay)
+
+	trader := &VirtualTrader{
+		RiskParams:    RiskParameters{MaxPositionSizeUSD: 50000, Sizing: sizing},
+		ResourceAlloc: ResourceAllocation{CurrentEquityUSD: 10000},
+	}
+	snap := &market.Snapshot{
+		Price:    market.PriceInfo{Last: 100},
+		LongTerm: &market.SeriesBundle{ATR: map[string][]float64{"ATR14": {2}}},
+	}
+	size, ok := trader.volTargetSize(snap)
+	require.True(t, ok)
+	want := market.VolTargetNotional(10000, 1, market.ATRDailyVolatility(2, 100, 6))
+	assert.InDelta(t, want, size, 1e-9)
+
+	trader.RiskParams.MaxPositionSizeUSD = 1000
+	size, _ = trader.volTargetSize(snap)
+	assert.InDelta(t, 1000, size, 1e-9, "max_position_size_usd still caps vol-target sizes")
+
+	_, ok = trader.volTargetSize(&market.Snapshot{Price: market.PriceInfo{Last: 100}})
+	assert.False(t, ok)
+
+	sizes := trader.volTargetSizes(map[string]*market.Snapshot{"BTC": snap, "ETH": {Price: market.PriceInfo{Last: 10}}})
+	assert.Equal(t, map[string]float64{"BTC": 1000}, sizes)
+}
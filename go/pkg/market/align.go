@@ -0,0 +1,28 @@
+package market
+
+import "time"
+
+// LastClosedOpen returns the open time of the newest bar of interval that has
+// fully closed at asOf. Bars are aligned to the Unix epoch, as exchange
+// candles are, so a bar closing exactly at asOf counts as closed.
+func LastClosedOpen(interval time.Duration, asOf time.Time) time.Time {
+	step := interval.Milliseconds()
+	if step <= 0 {
+		return asOf
+	}
+	ms := asOf.UnixMilli()
+	boundary := ms - ms%step
+	return time.UnixMilli(boundary - step).UTC()
+}
+
+// ClosedCandles returns the oldest-first prefix of candles that had closed at
+// asOf, dropping the bar still forming so indicators never read a partial
+// close. Frames trimmed with the same asOf end at aligned timestamps.
+func ClosedCandles(candles []Candle, interval time.Duration, asOf time.Time) []Candle {
+	last := LastClosedOpen(interval, asOf).UnixMilli()
+	n := len(candles)
+	for n > 0 && candles[n-1].OpenTime > last {
+		n--
+	}
+	return candles[:n]
+}
@@ -0,0 +1,55 @@
+package market
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastClosedOpenAtRollover(t *testing.T) {
+	at := func(hh, mm, ss, ms int) time.Time {
+		return time.Date(2026, 3, 1, hh, mm, ss, ms*int(time.Millisecond), time.UTC)
+	}
+	tests := []struct {
+		name     string
+		interval time.Duration
+		asOf     time.Time
+		want     time.Time
+	}{
+		{"3m just before rollover", 3 * time.Minute, at(11, 59, 59, 999), at(11, 54, 0, 0)},
+		{"3m exactly at rollover", 3 * time.Minute, at(12, 0, 0, 0), at(11, 57, 0, 0)},
+		{"3m just after rollover", 3 * time.Minute, at(12, 0, 0, 1), at(11, 57, 0, 0)},
+		{"4h just before rollover", 4 * time.Hour, at(11, 59, 59, 999), at(4, 0, 0, 0)},
+		{"4h exactly at rollover", 4 * time.Hour, at(12, 0, 0, 0), at(8, 0, 0, 0)},
+		{"4h across midnight", 4 * time.Hour, at(0, 30, 0, 0), at(0, 0, 0, 0).Add(-4 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, LastClosedOpen(tt.interval, tt.asOf))
+		})
+	}
+}
+
+func TestClosedCandlesAlignsFrames(t *testing.T) {
+	asOf := time.Date(2026, 3, 1, 12, 1, 30, 0, time.UTC)
+	bars := func(interval time.Duration, last time.Time, n int) []Candle {
+		out := make([]Candle, n)
+		for i := range out {
+			out[i] = Candle{OpenTime: last.Add(-time.Duration(n-1-i) * interval).UnixMilli()}
+		}
+		return out
+	}
+
+	short := ClosedCandles(bars(3*time.Minute, asOf.Truncate(3*time.Minute), 5), 3*time.Minute, asOf)
+	long := ClosedCandles(bars(4*time.Hour, asOf.Truncate(4*time.Hour), 5), 4*time.Hour, asOf)
+	assert.Len(t, short, 4, "the forming 12:00 bar is dropped")
+	assert.Len(t, long, 4, "the forming 12:00 bar is dropped")
+
+	shortClose := time.UnixMilli(short[len(short)-1].OpenTime).Add(3 * time.Minute)
+	longClose := time.UnixMilli(long[len(long)-1].OpenTime).Add(4 * time.Hour)
+	assert.True(t, shortClose.Equal(longClose), "both frames end at the 12:00 rollover")
+
+	assert.Len(t, ClosedCandles(bars(3*time.Minute, asOf.Add(-time.Hour), 3), 3*time.Minute, asOf), 3, "closed history is untouched")
+	assert.Empty(t, ClosedCandles(nil, time.Minute, asOf))
+}
@@ -0,0 +1,131 @@
+package market
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+)
+
+// Chart colours follow the usual dark exchange palette.
+var (
+	chartBackground = color.RGBA{R: 0x13, G: 0x17, B: 0x22, A: 0xff}
+	chartGrid       = color.RGBA{R: 0x2a, G: 0x2e, B: 0x39, A: 0xff}
+	chartUp         = color.RGBA{R: 0x26, G: 0xa6, B: 0x9a, A: 0xff}
+	chartDown       = color.RGBA{R: 0xef, G: 0x53, B: 0x50, A: 0xff}
+	chartVolume     = color.RGBA{R: 0x43, G: 0x4a, B: 0x5a, A: 0xff}
+)
+
+const (
+	chartPadding     = 8
+	chartGridLines   = 4
+	chartVolumeShare = 0.2 // fraction of the plot height reserved for volume bars
+)
+
+// ChartCandles returns the bundle's OHLC bars, synthesising bodies from
+// consecutive closes when the provider only exposes close prices.
+func (b *SeriesBundle) ChartCandles() []Candle {
+	if b == nil {
+		return nil
+	}
+	if len(b.Candles) > 0 {
+		return b.Candles
+	}
+	out := make([]Candle, 0, len(b.Prices))
+	for i, closePx := range b.Prices {
+		openPx := closePx
+		if i > 0 {
+			openPx = b.Prices[i-1]
+		}
+		c := Candle{Open: openPx, Close: closePx, High: math.Max(openPx, closePx), Low: math.Min(openPx, closePx)}
+		if i < len(b.Volume) {
+			c.Volume = b.Volume[i]
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// RenderCandlestickPNG draws candles as a width x height PNG with volume bars
+// along the bottom. It has no text so the image stays cheap to tokenise; the
+// prompt carries the numbers.
+func RenderCandlestickPNG(candles []Candle, width, height int) ([]byte, error) {
+	if len(candles) == 0 {
+		return nil, errors.New("market: no candles to chart")
+	}
+	if width <= 2*chartPadding || height <= 2*chartPadding {
+		return nil, errors.New("market: chart dimensions too small")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, 0, 0, width, height, chartBackground)
+
+	lo, hi, maxVol := math.Inf(1), math.Inf(-1), 0.0
+	for _, c := range candles {
+		lo = math.Min(lo, c.Low)
+		hi = math.Max(hi, c.High)
+		maxVol = math.Max(maxVol, c.Volume)
+	}
+	if math.IsInf(lo, 0) || math.IsInf(hi, 0) || math.IsNaN(lo) || math.IsNaN(hi) {
+		return nil, errors.New("market: candles have no finite prices")
+	}
+	if hi == lo {
+		hi, lo = hi*1.001+1e-9, lo*0.999-1e-9
+	}
+
+	plotW := width - 2*chartPadding
+	plotH := height - 2*chartPadding
+	volH := 0
+	if maxVol > 0 {
+		volH = int(float64(plotH) * chartVolumeShare)
+	}
+	priceH := plotH - volH
+	top := chartPadding
+	volBase := chartPadding + plotH
+
+	for i := 0; i <= chartGridLines; i++ {
+		y := top + i*(priceH-1)/chartGridLines
+		fillRect(img, chartPadding, y, chartPadding+plotW, y+1, chartGrid)
+	}
+
+	yOf := func(price float64) int {
+		return top + int(math.Round((hi-price)/(hi-lo)*float64(priceH-1)))
+	}
+	slot := float64(plotW) / float64(len(candles))
+	bodyW := int(slot * 0.7)
+	if bodyW < 1 {
+		bodyW = 1
+	}
+	for i, c := range candles {
+		x0 := chartPadding + int(float64(i)*slot+(slot-float64(bodyW))/2)
+		mid := x0 + bodyW/2
+		col := chartUp
+		if c.Close < c.Open {
+			col = chartDown
+		}
+		fillRect(img, mid, yOf(c.High), mid+1, yOf(c.Low)+1, col)
+		bodyTop, bodyBottom := yOf(math.Max(c.Open, c.Close)), yOf(math.Min(c.Open, c.Close))
+		fillRect(img, x0, bodyTop, x0+bodyW, bodyBottom+1, col)
+		if volH > 0 && c.Volume > 0 {
+			barH := int(math.Round(c.Volume / maxVol * float64(volH)))
+			fillRect(img, x0, volBase-barH, x0+bodyW, volBase, chartVolume)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func fillRect(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	r := image.Rect(x0, y0, x1, y1).Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package market
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderCandlestickPNG(t *testing.T) {
+	candles := []Candle{
+		{Open: 100, High: 105, Low: 98, Close: 104, Volume: 10},
+		{Open: 104, High: 106, Low: 99, Close: 100, Volume: 25},
+		{Open: 100, High: 101, Low: 95, Close: 96},
+	}
+	data, err := RenderCandlestickPNG(candles, 200, 120)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 200, img.Bounds().Dx())
+	assert.Equal(t, 120, img.Bounds().Dy())
+
+	_, err = RenderCandlestickPNG(nil, 200, 120)
+	assert.Error(t, err)
+	_, err = RenderCandlestickPNG(candles, 10, 10)
+	assert.Error(t, err)
+
+	// Flat series must not divide by zero.
+	_, err = RenderCandlestickPNG([]Candle{{Open: 1, High: 1, Low: 1, Close: 1}}, 64, 64)
+	assert.NoError(t, err)
+}
+
+func TestSeriesBundleChartCandles(t *testing.T) {
+	assert.Nil(t, (*SeriesBundle)(nil).ChartCandles())
+
+	ohlc := &SeriesBundle{Prices: []float64{1}, Candles: []Candle{{Open: 1, High: 2, Low: 0.5, Close: 1}}}
+	assert.Equal(t, ohlc.Candles, ohlc.ChartCandles())
+
+	closes := &SeriesBundle{Prices: []float64{10, 12, 11}, Volume: []float64{5, 6}}
+	got := closes.ChartCandles()
+	require.Len(t, got, 3)
+	assert.Equal(t, Candle{Open: 10, High: 10, Low: 10, Close: 10, Volume: 5}, got[0])
+	assert.Equal(t, Candle{Open: 12, High: 12, Low: 11, Close: 11}, got[2])
+}
@@ -0,0 +1,418 @@
+// Package binance streams USDⓈ-M futures klines and trades from Binance. It
+// provides a market.TradeFeed only; trading and snapshots go through other
+// providers.
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"golang.org/x/net/websocket"
+
+	"nof0-api/pkg/market"
+)
+
+const (
+	defaultRESTURL     = "https://fapi.binance.com"
+	defaultWSURL       = "wss://fstream.binance.com"
+	testnetRESTURL     = "https://testnet.binancefuture.com"
+	testnetWSURL       = "wss://stream.binancefuture.com"
+	defaultBackoffMin  = 500 * time.Millisecond
+	defaultBackoffMax  = 30 * time.Second
+	defaultReadTimeout = 60 * time.Second
+	defaultHTTPTimeout = 10 * time.Second
+	historyPageBars    = 1000
+	defaultQuoteAsset  = "USDT"
+	streamOrigin       = "https://www.binance.com"
+)
+
+var intervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
+}
+
+var _ market.TradeFeed = (*Feed)(nil)
+
+func init() {
+	market.RegisterKlineFeed("binance", func(cfg market.KlineFeedConfig) (market.KlineFeed, error) {
+		opts := []FeedOption{}
+		if cfg.Testnet {
+			opts = append(opts, WithEndpoints(testnetRESTURL, testnetWSURL))
+		}
+		if cfg.URL != "" {
+			opts = append(opts, WithEndpoints("", cfg.URL))
+		}
+		return NewFeed(opts...), nil
+	})
+}
+
+type subscription struct {
+	symbol   string // caller symbol, e.g. "BTC"
+	interval string
+}
+
+// Feed streams klines and aggregate trades over the combined-stream websocket
+// and reads history from the REST klines endpoint. Symbols are quoted in
+// USDT: "BTC" trades as BTCUSDT. Binance marks each kline final, so updates
+// carry Closed.
+type Feed struct {
+	restURL    string
+	wsURL      string
+	httpClient *http.Client
+	backoffMin time.Duration
+	backoffMax time.Duration
+	dial       func(ctx context.Context, url string) (frameConn, error)
+
+	mu        sync.Mutex
+	subs      []subscription
+	tradeSubs []string // caller symbols
+	onTrade   func(market.TradeUpdate)
+}
+
+// frameConn is the subset of a websocket connection the feed reads from.
+type frameConn interface {
+	Receive() ([]byte, error)
+	SetReadDeadline(t time.Time) error
+	Close() error
+}
+
+// FeedOption configures a Feed.
+type FeedOption func(*Feed)
+
+// WithEndpoints overrides the REST and websocket base URLs; empty values
+// keep the current ones.
+func WithEndpoints(restURL, wsURL string) FeedOption {
+	return func(f *Feed) {
+		if restURL != "" {
+			f.restURL = strings.TrimRight(restURL, "/")
+		}
+		if wsURL != "" {
+			f.wsURL = strings.TrimRight(wsURL, "/")
+		}
+	}
+}
+
+// WithHTTPClient injects the client used for history requests.
+func WithHTTPClient(hc *http.Client) FeedOption {
+	return func(f *Feed) {
+		if hc != nil {
+			f.httpClient = hc
+		}
+	}
+}
+
+// NewFeed builds a mainnet feed.
+func NewFeed(opts ...FeedOption) *Feed {
+	f := &Feed{
+		restURL:    defaultRESTURL,
+		wsURL:      defaultWSURL,
+		httpClient: &http.Client{Timeout: defaultHTTPTimeout},
+		backoffMin: defaultBackoffMin,
+		backoffMax: defaultBackoffMax,
+		dial:       dialWebsocket,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Subscribe implements market.KlineFeed.
+func (f *Feed) Subscribe(symbol, interval string) error {
+	if _, ok := intervals[interval]; !ok {
+		return fmt.Errorf("binance: unsupported interval %q", interval)
+	}
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if symbol == "" {
+		return fmt.Errorf("binance: symbol is required")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subs = append(f.subs, subscription{symbol: symbol, interval: interval})
+	return nil
+}
+
+// SubscribeTrades implements market.TradeFeed with the aggTrade stream.
+func (f *Feed) SubscribeTrades(symbol string) error {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if symbol == "" {
+		return fmt.Errorf("binance: symbol is required")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tradeSubs = append(f.tradeSubs, symbol)
+	return nil
+}
+
+// OnTrade implements market.TradeFeed.
+func (f *Feed) OnTrade(handle func(market.TradeUpdate)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onTrade = handle
+}
+
+// Run implements market.KlineFeed, reconnecting with jittered exponential
+// backoff. Candles missed while disconnected are left to the caller, which
+// sees the gap in open times; missed trades are not recovered.
+func (f *Feed) Run(ctx context.Context, handle func(market.KlineUpdate)) error {
+	f.mu.Lock()
+	subs := append([]subscription(nil), f.subs...)
+	tradeSubs := append([]string(nil), f.tradeSubs...)
+	onTrade := f.onTrade
+	f.mu.Unlock()
+	if len(subs) == 0 && len(tradeSubs) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	symbols := make(map[string]string, len(subs)) // BTCUSDT -> BTC
+	streams := make([]string, 0, len(subs)+len(tradeSubs))
+	for _, sub := range subs {
+		pair := pairFor(sub.symbol)
+		symbols[pair] = sub.symbol
+		streams = append(streams, strings.ToLower(pair)+"@kline_"+sub.interval)
+	}
+	for _, symbol := range tradeSubs {
+		pair := pairFor(symbol)
+		symbols[pair] = symbol
+		streams = append(streams, strings.ToLower(pair)+"@aggTrade")
+	}
+	streamURL := f.wsURL + "/stream?streams=" + strings.Join(streams, "/")
+	dispatch := func(data []byte) {
+		var msg streamMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return
+		}
+		symbol, ok := symbols[msg.Data.Pair]
+		if !ok {
+			return
+		}
+		switch msg.Data.Event {
+		case "kline":
+			handle(msg.klineUpdate(symbol))
+		case "aggTrade":
+			if onTrade != nil {
+				onTrade(msg.tradeUpdate(symbol))
+			}
+		}
+	}
+
+	backoff := f.backoffMin
+	for {
+		connected, err := f.serve(ctx, streamURL, dispatch)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if connected {
+			backoff = f.backoffMin
+		}
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		logx.WithContext(ctx).Infof("binance: websocket disconnected: %v; reconnecting in %s", err, delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		backoff *= 2
+		if backoff > f.backoffMax {
+			backoff = f.backoffMax
+		}
+	}
+}
+
+func (f *Feed) serve(ctx context.Context, streamURL string, dispatch func([]byte)) (bool, error) {
+	conn, err := f.dial(ctx, streamURL)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(defaultReadTimeout)); err != nil {
+			return true, err
+		}
+		data, err := conn.Receive()
+		if err != nil {
+			return true, err
+		}
+		dispatch(data)
+	}
+}
+
+// streamMessage decodes kline and aggTrade events. encoding/json falls back
+// to case-insensitive key matching, so every upper-case key Binance sends
+// next to a lower-case one ("E", "T", "L", "V") needs its own field or it
+// would overwrite its lower-case twin.
+type streamMessage struct {
+	Data struct {
+		Event     string `json:"e"`
+		EventTime int64  `json:"E"`
+		Pair      string `json:"s"`
+		Price     string `json:"p"`
+		Quantity  string `json:"q"`
+		TradeTime int64  `json:"T"`
+		Kline     struct {
+			OpenTime       int64  `json:"t"`
+			CloseTime      int64  `json:"T"`
+			Interval       string `json:"i"`
+			Open           string `json:"o"`
+			Close          string `json:"c"`
+			High           string `json:"h"`
+			Low            string `json:"l"`
+			LastTradeID    int64  `json:"L"`
+			Volume         string `json:"v"`
+			TakerBuyVolume string `json:"V"`
+			Closed         bool   `json:"x"`
+		} `json:"k"`
+	} `json:"data"`
+}
+
+func (msg *streamMessage) tradeUpdate(symbol string) market.TradeUpdate {
+	return market.TradeUpdate{
+		Symbol: symbol,
+		Price:  parseFloat(msg.Data.Price),
+		Size:   parseFloat(msg.Data.Quantity),
+		Time:   msg.Data.TradeTime,
+	}
+}
+
+func (msg *streamMessage) klineUpdate(symbol string) market.KlineUpdate {
+	k := msg.Data.Kline
+	return market.KlineUpdate{
+		Symbol:   symbol,
+		Interval: k.Interval,
+		Candle: market.Candle{
+			OpenTime: k.OpenTime,
+			Open:     parseFloat(k.Open),
+			High:     parseFloat(k.High),
+			Low:      parseFloat(k.Low),
+			Close:    parseFloat(k.Close),
+			Volume:   parseFloat(k.Volume),
+		},
+		Closed: k.Closed,
+	}
+}
+
+// History implements market.KlineFeed, paging through /fapi/v1/klines.
+func (f *Feed) History(ctx context.Context, symbol, interval string, from, to time.Time) ([]market.Candle, error) {
+	step, ok := intervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("binance: unsupported interval %q", interval)
+	}
+	pair := pairFor(strings.ToUpper(strings.TrimSpace(symbol)))
+	var out []market.Candle
+	for start := from; start.Before(to); start = start.Add(historyPageBars * step) {
+		end := start.Add(historyPageBars * step)
+		if end.After(to) {
+			end = to
+		}
+		q := url.Values{}
+		q.Set("symbol", pair)
+		q.Set("interval", interval)
+		q.Set("startTime", strconv.FormatInt(start.UnixMilli(), 10))
+		q.Set("endTime", strconv.FormatInt(end.UnixMilli()-1, 10))
+		q.Set("limit", strconv.Itoa(historyPageBars))
+		rows, err := f.getKlines(ctx, q)
+		if err != nil {
+			return nil, fmt.Errorf("binance: klines %s %s: %w", pair, interval, err)
+		}
+		out = append(out, rows...)
+	}
+	return out, nil
+}
+
+func (f *Feed) getKlines(ctx context.Context, q url.Values) ([]market.Candle, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.restURL+"/fapi/v1/klines?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	// Each row is [openTime, open, high, low, close, volume, closeTime, ...].
+	var rows [][]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		return nil, err
+	}
+	out := make([]market.Candle, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 6 {
+			continue
+		}
+		var (
+			openTime int64
+			fields   [5]string
+		)
+		if err := json.Unmarshal(row[0], &openTime); err != nil {
+			return nil, err
+		}
+		for i := range fields {
+			if err := json.Unmarshal(row[i+1], &fields[i]); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, market.Candle{
+			OpenTime: openTime,
+			Open:     parseFloat(fields[0]),
+			High:     parseFloat(fields[1]),
+			Low:      parseFloat(fields[2]),
+			Close:    parseFloat(fields[3]),
+			Volume:   parseFloat(fields[4]),
+		})
+	}
+	return out, nil
+}
+
+func pairFor(symbol string) string {
+	if strings.HasSuffix(symbol, defaultQuoteAsset) {
+		return symbol
+	}
+	return symbol + defaultQuoteAsset
+}
+
+func parseFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+// netConn adapts golang.org/x/net/websocket to frameConn. The package
+// answers server pings itself.
+type netConn struct {
+	*websocket.Conn
+}
+
+func dialWebsocket(ctx context.Context, url string) (frameConn, error) {
+	cfg, err := websocket.NewConfig(url, streamOrigin)
+	if err != nil {
+		return nil, fmt.Errorf("binance: websocket config: %w", err)
+	}
+	conn, err := cfg.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("binance: websocket dial: %w", err)
+	}
+	return &netConn{Conn: conn}, nil
+}
+
+func (c *netConn) Receive() ([]byte, error) {
+	var data []byte
+	err := websocket.Message.Receive(c.Conn, &data)
+	return data, err
+}
@@ -0,0 +1,117 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/market"
+)
+
+type fakeConn struct {
+	frames chan []byte
+}
+
+func (c *fakeConn) Receive() ([]byte, error) {
+	if f, ok := <-c.frames; ok {
+		return f, nil
+	}
+	return nil, errors.New("connection reset")
+}
+
+func (c *fakeConn) SetReadDeadline(time.Time) error { return nil }
+func (c *fakeConn) Close() error                    { return nil }
+
+func TestFeedStreamsSubscribedKlines(t *testing.T) {
+	f := NewFeed()
+	require.NoError(t, f.Subscribe("btc", "1m"))
+	require.Error(t, f.Subscribe("ETH", "2m"))
+
+	frames := make(chan []byte, 3)
+	// Full payloads: the upper-case keys must not clobber their lower-case twins.
+	frames <- []byte(`{"stream":"btcusdt@kline_1m","data":{"e":"kline","E":120001,"s":"BTCUSDT","k":{"t":60000,"T":119999,"s":"BTCUSDT","i":"1m","f":100,"L":200,"o":"1","c":"2","h":"3","l":"0.5","v":"10","n":101,"x":true,"q":"20","V":"4","Q":"8","B":"0"}}}`)
+	frames <- []byte(`{"stream":"ethusdt@kline_1m","data":{"e":"kline","E":120001,"s":"ETHUSDT","k":{"t":60000,"T":119999,"i":"1m","o":"1","c":"2","h":"3","l":"0.5","v":"10","x":false}}}`)
+	frames <- []byte(`{"result":null,"id":1}`)
+	close(frames)
+	var dialed string
+	f.dial = func(ctx context.Context, url string) (frameConn, error) {
+		dialed = url
+		return &fakeConn{frames: frames}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var got []market.KlineUpdate
+	err := f.Run(ctx, func(u market.KlineUpdate) {
+		got = append(got, u)
+		cancel()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "wss://fstream.binance.com/stream?streams=btcusdt@kline_1m", dialed)
+	require.Len(t, got, 1)
+	assert.Equal(t, market.KlineUpdate{
+		Symbol:   "BTC",
+		Interval: "1m",
+		Candle:   market.Candle{OpenTime: 60000, Open: 1, High: 3, Low: 0.5, Close: 2, Volume: 10},
+		Closed:   true,
+	}, got[0])
+}
+
+func TestFeedStreamsTrades(t *testing.T) {
+	f := NewFeed()
+	require.NoError(t, f.Subscribe("BTC", "1m"))
+	require.NoError(t, f.SubscribeTrades("btc"))
+	var trades []market.TradeUpdate
+	f.OnTrade(func(u market.TradeUpdate) { trades = append(trades, u) })
+
+	frames := make(chan []byte, 2)
+	frames <- []byte(`{"stream":"btcusdt@aggTrade","data":{"e":"aggTrade","E":61005,"s":"BTCUSDT","a":5,"p":"100.5","q":"0.2","f":100,"l":105,"T":61000,"m":true}}`)
+	frames <- []byte(`{"stream":"btcusdt@kline_1m","data":{"e":"kline","E":120001,"s":"BTCUSDT","k":{"t":60000,"T":119999,"i":"1m","o":"1","c":"2","h":"3","l":"0.5","L":200,"v":"10","V":"4","x":true}}}`)
+	close(frames)
+	var dialed string
+	f.dial = func(ctx context.Context, url string) (frameConn, error) {
+		dialed = url
+		return &fakeConn{frames: frames}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := f.Run(ctx, func(market.KlineUpdate) { cancel() })
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "wss://fstream.binance.com/stream?streams=btcusdt@kline_1m/btcusdt@aggTrade", dialed)
+	assert.Equal(t, []market.TradeUpdate{{Symbol: "BTC", Price: 100.5, Size: 0.2, Time: 61000}}, trades)
+}
+
+func TestFeedHistoryPages(t *testing.T) {
+	var pages int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/fapi/v1/klines", r.URL.Path)
+		assert.Equal(t, "ETHUSDT", r.URL.Query().Get("symbol"))
+		start, _ := strconv.ParseInt(r.URL.Query().Get("startTime"), 10, 64)
+		end, _ := strconv.ParseInt(r.URL.Query().Get("endTime"), 10, 64)
+		pages++
+		var rows [][]any
+		for open := start; open <= end; open += int64(time.Hour / time.Millisecond) {
+			rows = append(rows, []any{open, "1", "2", "0.5", fmt.Sprint(open), "7", open + 3_599_999})
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(rows))
+	}))
+	defer server.Close()
+
+	f := NewFeed(WithEndpoints(server.URL, ""))
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles, err := f.History(context.Background(), "eth", "1h", from, from.Add(1500*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 2, pages)
+	require.Len(t, candles, 1500)
+	assert.Equal(t, from.UnixMilli(), candles[0].OpenTime)
+	assert.Equal(t, float64(candles[1499].OpenTime), candles[1499].Close)
+	assert.Equal(t, 7.0, candles[0].Volume)
+}
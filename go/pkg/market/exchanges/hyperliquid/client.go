@@ -12,6 +12,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"nof0-api/pkg/market"
 )
 
 const (
@@ -36,6 +38,9 @@ type Client struct {
 	symbolIndex      map[string]string
 	assetCtxBySymbol map[string]AssetCtx
 	universeMeta     map[string]UniverseEntry
+
+	// longTerm holds the 4h frame per symbol until its next bar closes.
+	longTerm *market.SummaryCache[longTermFrame]
 }
 
 // Option configures a new Client.
@@ -85,6 +90,7 @@ func NewClient(opts ...Option) *Client {
 		httpClient: httpClient,
 		maxRetries: defaultMaxRetries,
 		logger:     log.Default(),
+		longTerm:   market.NewSummaryCache[longTermFrame](),
 	}
 	for _, opt := range opts {
 		opt(client)
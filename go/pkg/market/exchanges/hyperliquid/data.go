@@ -7,17 +7,19 @@ import (
 	"strings"
 	"time"
 
+	"github.com/zeromicro/go-zero/core/logx"
+
 	"nof0-api/pkg/market"
 	"nof0-api/pkg/market/indicators"
 )
 
 const (
 	intradayInterval       = "3m"
-	intradayLookback       = 40
+	intradayLookback       = 80 // MACD warm-up plus the shown series
 	intradaySeriesLength   = 10
 	intradayChangeLookback = 20
 	longerInterval         = "4h"
-	longerLookback         = 60
+	longerLookback         = 120 // EMA50 warm-up plus the shown series
 	priceChange4hLookback  = 1
 )
 
@@ -31,11 +33,19 @@ func (c *Client) buildSnapshot(ctx context.Context, symbol string) (*market.Snap
 		return nil, nil, err
 	}
 
-	intradayKlines, err := c.GetKlines(ctx, info.Symbol, intradayInterval, intradayLookback)
+	// One extra bar each covers the forming candle dropped below, so both
+	// frames end on their last closed bar as of the same instant.
+	asOf := time.Now()
+	intradayKlines, err := c.GetKlines(ctx, info.Symbol, intradayInterval, intradayLookback+1)
 	if err != nil {
 		return nil, nil, err
 	}
-	longerKlines, err := c.GetKlines(ctx, info.Symbol, longerInterval, longerLookback)
+	intradayKlines = closedKlines(intradayKlines, intradayInterval, asOf)
+	// The 4h frame only changes when a 4h bar closes, so reuse it across
+	// decision cycles instead of refetching and recomputing its indicators.
+	longer, cached, err := c.longTerm.Get(ctx, info.Symbol, intervalDurations[longerInterval], asOf, func(ctx context.Context) (longTermFrame, time.Time, error) {
+		return c.buildLongTermFrame(ctx, info.Symbol, asOf)
+	})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -45,44 +55,16 @@ func (c *Client) buildSnapshot(ctx context.Context, symbol string) (*market.Snap
 		return nil, nil, err
 	}
 
+	intradayFixes := sanitizeKlines(ctx, info.Symbol, intradayInterval, intradayKlines)
 	intradaySeries, intradaySignals := buildIntradaySeries(intradayKlines)
-	longerSeries, longerSignals := buildLongerSeries(longerKlines)
+	if intradaySeries != nil {
+		intradaySeries.Corrections = intradayFixes
+	}
 
 	change1h := calculatePriceChange(lastPrice, priceAt(intradayKlines, intradayChangeLookback))
-	change4h := calculatePriceChange(lastPrice, priceAt(longerKlines, priceChange4hLookback))
-
-	indicatorEMA := make(map[string]float64)
-	indicatorRSI := make(map[string]float64)
-
-	if intradaySignals != nil {
-		if !math.IsNaN(intradaySignals.ema20) {
-			indicatorEMA["EMA20"] = intradaySignals.ema20
-		}
-		if !math.IsNaN(intradaySignals.rsi7) {
-			indicatorRSI["RSI7"] = intradaySignals.rsi7
-		}
-	}
-	if longerSignals != nil {
-		if !math.IsNaN(longerSignals.ema20) {
-			indicatorEMA["EMA20_Long"] = longerSignals.ema20
-		}
-		if !math.IsNaN(longerSignals.ema50) {
-			indicatorEMA["EMA50"] = longerSignals.ema50
-		}
-		if !math.IsNaN(longerSignals.rsi14) {
-			indicatorRSI["RSI14"] = longerSignals.rsi14
-		}
-	}
+	change4h := calculatePriceChange(lastPrice, priceAt(longer.klines, priceChange4hLookback))
 
-	indicator := market.IndicatorInfo{
-		EMA: indicatorEMA,
-		RSI: indicatorRSI,
-	}
-	if intradaySignals != nil && !math.IsNaN(intradaySignals.macd) {
-		indicator.MACD = intradaySignals.macd
-	} else if longerSignals != nil && !math.IsNaN(longerSignals.macd) {
-		indicator.MACD = longerSignals.macd
-	}
+	indicator := indicators.Summary(intradaySignals, longer.signals)
 
 	var funding *market.FundingInfo
 	if !math.IsNaN(info.FundingRate) && info.FundingRate != 0 {
@@ -95,10 +77,15 @@ func (c *Client) buildSnapshot(ctx context.Context, symbol string) (*market.Snap
 	if info.OpenInterest != 0 {
 		openInterest = &market.OpenInterestInfo{
 			Latest:  info.OpenInterest,
-			Average: info.OpenInterest, // TODO: replace with historical averaging when Hyperliquid exposes the series.
+			Average: info.OpenInterest, // replaced by the rolling average when a MetricsAverager is set
 		}
 	}
 
+	var volume *market.VolumeInfo
+	if info.DayVolume != 0 {
+		volume = &market.VolumeInfo{Latest: info.DayVolume, Average: info.DayVolume}
+	}
+
 	snapshot := &market.Snapshot{
 		Symbol: info.Symbol,
 		Price: market.PriceInfo{
@@ -111,14 +98,48 @@ func (c *Client) buildSnapshot(ctx context.Context, symbol string) (*market.Snap
 		Indicators:   indicator,
 		OpenInterest: openInterest,
 		Funding:      funding,
+		Volume:       volume,
 		Intraday:     intradaySeries,
-		LongTerm:     longerSeries,
+		LongTerm:     longer.series,
 	}
 
-	ticks := append(buildPriceTicks(intradayInterval, intradayKlines), buildPriceTicks(longerInterval, longerKlines)...)
+	// Ticks of a reused frame were already emitted when it was built.
+	ticks := buildPriceTicks(intradayInterval, intradayKlines)
+	if !cached {
+		ticks = append(ticks, buildPriceTicks(longerInterval, longer.klines)...)
+	}
 	return snapshot, ticks, nil
 }
 
+// longTermFrame is the closed 4h klines of a symbol with the series and
+// signals derived from them. Cached frames are shared between snapshots and
+// must not be mutated.
+type longTermFrame struct {
+	klines  []Kline
+	series  *market.SeriesBundle
+	signals indicators.Current
+}
+
+// buildLongTermFrame fetches and derives the 4h frame as of asOf, returning
+// the open time of its newest bar for the summary cache.
+func (c *Client) buildLongTermFrame(ctx context.Context, symbol string, asOf time.Time) (longTermFrame, time.Time, error) {
+	klines, err := c.GetKlines(ctx, symbol, longerInterval, longerLookback+1)
+	if err != nil {
+		return longTermFrame{}, time.Time{}, err
+	}
+	klines = closedKlines(klines, longerInterval, asOf)
+	fixes := sanitizeKlines(ctx, symbol, longerInterval, klines)
+	series, signals := buildLongerSeries(klines)
+	if series != nil {
+		series.Corrections = fixes
+	}
+	var newest time.Time
+	if n := len(klines); n > 0 {
+		newest = time.UnixMilli(klines[n-1].OpenTime).UTC()
+	}
+	return longTermFrame{klines: klines, series: series, signals: signals}, newest, nil
+}
+
 func (c *Client) getCurrentPriceForCanonical(ctx context.Context, symbol string) (float64, error) {
 	var response AllMidsResponse
 	if err := c.doRequest(ctx, InfoRequest{Type: "allMids"}, &response); err != nil {
@@ -133,86 +154,36 @@ func (c *Client) getCurrentPriceForCanonical(ctx context.Context, symbol string)
 	return 0, fmt.Errorf("hyperliquid: price for %s not available", symbol)
 }
 
-type indicatorSnapshot struct {
-	ema20 float64
-	ema50 float64
-	macd  float64
-	rsi7  float64
-	rsi14 float64
+func buildIntradaySeries(klines []Kline) (*market.SeriesBundle, indicators.Current) {
+	return indicators.BuildSeries(toCandles(klines), indicators.FrameIntraday, intradaySeriesLength)
 }
 
-func buildIntradaySeries(klines []Kline) (*market.SeriesBundle, *indicatorSnapshot) {
-	if len(klines) == 0 {
-		return nil, nil
-	}
-	closes := extractCloses(klines)
-	volumes := extractVolumes(klines)
-
-	ema20 := indicators.EMA(closes, 20)
-	macd, _, _ := indicators.MACD(closes)
-	rsi7 := indicators.RSI(closes, 7)
-	rsi14 := indicators.RSI(closes, 14)
-
-	series := &market.SeriesBundle{
-		Prices: lastN(closes, intradaySeriesLength),
-		EMA:    map[string][]float64{"EMA20": lastN(ema20, intradaySeriesLength)},
-		MACD:   lastN(macd, intradaySeriesLength),
-		RSI: map[string][]float64{
-			"RSI7":  lastN(rsi7, intradaySeriesLength),
-			"RSI14": lastN(rsi14, intradaySeriesLength),
-		},
-		Volume: lastN(volumes, intradaySeriesLength),
-	}
-
-	snapshot := &indicatorSnapshot{
-		ema20: latestNonNaN(ema20),
-		macd:  latestNonNaN(macd),
-		rsi7:  latestNonNaN(rsi7),
-		rsi14: latestNonNaN(rsi14),
-	}
-	return series, snapshot
+func buildLongerSeries(klines []Kline) (*market.SeriesBundle, indicators.Current) {
+	return indicators.BuildSeries(toCandles(klines), indicators.FrameLongTerm, intradaySeriesLength)
 }
 
-func buildLongerSeries(klines []Kline) (*market.SeriesBundle, *indicatorSnapshot) {
-	if len(klines) == 0 {
-		return nil, nil
+// closedKlines drops klines still forming at asOf; see market.ClosedCandles.
+func closedKlines(klines []Kline, interval string, asOf time.Time) []Kline {
+	last := market.LastClosedOpen(intervalDurations[interval], asOf).UnixMilli()
+	n := len(klines)
+	for n > 0 && klines[n-1].OpenTime > last {
+		n--
 	}
-	closes := extractCloses(klines)
-	volumes := extractVolumes(klines)
-
-	ema20 := indicators.EMA(closes, 20)
-	ema50 := indicators.EMA(closes, 50)
-	macd, _, _ := indicators.MACD(closes)
-	rsi14 := indicators.RSI(closes, 14)
-
-	atrInput := convertForATR(klines)
-	atr3 := indicators.ATR(atrInput, 3)
-	atr14 := indicators.ATR(atrInput, 14)
+	return klines[:n]
+}
 
-	series := &market.SeriesBundle{
-		Prices: lastN(closes, intradaySeriesLength),
-		EMA: map[string][]float64{
-			"EMA20": lastN(ema20, intradaySeriesLength),
-			"EMA50": lastN(ema50, intradaySeriesLength),
-		},
-		MACD: lastN(macd, intradaySeriesLength),
-		RSI: map[string][]float64{
-			"RSI14": lastN(rsi14, intradaySeriesLength),
-		},
-		ATR: map[string][]float64{
-			"ATR3":  lastN(atr3, intradaySeriesLength),
-			"ATR14": lastN(atr14, intradaySeriesLength),
-		},
-		Volume: lastN(volumes, intradaySeriesLength),
+// sanitizeKlines repairs impossible prints in klines before indicators are
+// computed, logging each correction, and returns how many were found.
+func sanitizeKlines(ctx context.Context, symbol, interval string, klines []Kline) int {
+	candles := toCandles(klines)
+	fixes := market.SanitizeCandles(candles)
+	for _, fix := range fixes {
+		logx.WithContext(ctx).Infof("hyperliquid: bad tick symbol=%s interval=%s %s", symbol, interval, fix)
 	}
-
-	snapshot := &indicatorSnapshot{
-		ema20: latestNonNaN(ema20),
-		ema50: latestNonNaN(ema50),
-		macd:  latestNonNaN(macd),
-		rsi14: latestNonNaN(rsi14),
+	for i := range klines {
+		klines[i].High, klines[i].Low, klines[i].Close = candles[i].High, candles[i].Low, candles[i].Close
 	}
-	return series, snapshot
+	return len(fixes)
 }
 
 func buildPriceTicks(interval string, klines []Kline) []market.PriceTick {
@@ -258,49 +229,17 @@ func priceAt(klines []Kline, stepsBack int) float64 {
 	return klines[len(klines)-1-stepsBack].Close
 }
 
-func extractCloses(klines []Kline) []float64 {
-	out := make([]float64, len(klines))
-	for i, k := range klines {
-		out[i] = k.Close
-	}
-	return out
-}
-
-func extractVolumes(klines []Kline) []float64 {
-	out := make([]float64, len(klines))
+func toCandles(klines []Kline) []market.Candle {
+	out := make([]market.Candle, len(klines))
 	for i, k := range klines {
-		out[i] = k.Volume
-	}
-	return out
-}
-
-func convertForATR(klines []Kline) []indicators.Kline {
-	out := make([]indicators.Kline, len(klines))
-	for i, k := range klines {
-		out[i] = indicators.Kline{
-			High:  k.High,
-			Low:   k.Low,
-			Close: k.Close,
+		out[i] = market.Candle{
+			OpenTime: k.OpenTime,
+			Open:     k.Open,
+			High:     k.High,
+			Low:      k.Low,
+			Close:    k.Close,
+			Volume:   k.Volume,
 		}
 	}
 	return out
 }
-
-func lastN(values []float64, count int) []float64 {
-	if len(values) == 0 {
-		return []float64{}
-	}
-	if len(values) <= count {
-		return append([]float64(nil), values...)
-	}
-	return append([]float64(nil), values[len(values)-count:]...)
-}
-
-func latestNonNaN(values []float64) float64 {
-	for i := len(values) - 1; i >= 0; i-- {
-		if !math.IsNaN(values[i]) {
-			return values[i]
-		}
-	}
-	return math.NaN()
-}
@@ -1,8 +1,10 @@
 package hyperliquid
 
 import (
+	"context"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -85,77 +87,52 @@ func TestPriceAt(t *testing.T) {
 	}
 }
 
-// TestLastN tests the lastN helper.
-func TestLastN(t *testing.T) {
-	tests := []struct {
-		name   string
-		values []float64
-		count  int
-		want   []float64
-	}{
-		{
-			name:   "normal case",
-			values: []float64{1, 2, 3, 4, 5},
-			count:  3,
-			want:   []float64{3, 4, 5},
-		},
-		{
-			name:   "count exceeds length",
-			values: []float64{1, 2},
-			count:  5,
-			want:   []float64{1, 2},
-		},
-		{
-			name:   "empty values",
-			values: []float64{},
-			count:  3,
-			want:   []float64{},
-		},
+func risingKlines(n int) []Kline {
+	klines := make([]Kline, n)
+	for i := range klines {
+		px := 100 + float64(i)
+		klines[i] = Kline{Open: px, High: px + 1, Low: px - 1, Close: px}
 	}
+	return klines
+}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := lastN(tt.values, tt.count)
-			assert.Equal(t, tt.want, result)
-		})
-	}
+// TestBuildSeriesWarmup checks that indicators lacking warm-up history are
+// omitted and reported rather than emitted from a partial window.
+func TestBuildSeriesWarmup(t *testing.T) {
+	series, signals := buildLongerSeries(risingKlines(60))
+	assert.Equal(t, []string{"EMA50", "MACD"}, series.InsufficientHistory)
+	assert.NotContains(t, series.EMA, "EMA50")
+	assert.Len(t, series.EMA["EMA20"], intradaySeriesLength)
+	assert.Nil(t, series.MACD)
+	assert.True(t, math.IsNaN(signals.EMA50))
+	assert.False(t, math.IsNaN(signals.EMA20))
+	assert.Len(t, series.Prices, intradaySeriesLength)
+
+	series, _ = buildIntradaySeries(risingKlines(20))
+	assert.Equal(t, []string{"EMA20", "MACD", "RSI7", "RSI14"}, series.InsufficientHistory)
+	assert.Empty(t, series.EMA)
+
+	series, _ = buildLongerSeries(risingKlines(longerLookback))
+	assert.Empty(t, series.InsufficientHistory)
+	series, _ = buildIntradaySeries(risingKlines(intradayLookback))
+	assert.Empty(t, series.InsufficientHistory)
 }
 
-// TestLatestNonNaN tests the latestNonNaN helper.
-func TestLatestNonNaN(t *testing.T) {
-	tests := []struct {
-		name   string
-		values []float64
-		want   float64
-		isNaN  bool
-	}{
-		{
-			name:   "last value is valid",
-			values: []float64{1.0, 2.0, 3.0},
-			want:   3.0,
-			isNaN:  false,
-		},
-		{
-			name:   "last values are NaN",
-			values: []float64{1.0, 2.0, math.NaN(), math.NaN()},
-			want:   2.0,
-			isNaN:  false,
-		},
-		{
-			name:   "all values are NaN",
-			values: []float64{math.NaN(), math.NaN()},
-			isNaN:  true,
-		},
-	}
+func TestSanitizeKlines(t *testing.T) {
+	klines := risingKlines(10)
+	klines[7].High = 1000
+	assert.Equal(t, 1, sanitizeKlines(context.Background(), "BTC", "3m", klines))
+	assert.Equal(t, klines[7].Close, klines[7].High)
+}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := latestNonNaN(tt.values)
-			if tt.isNaN {
-				assert.True(t, math.IsNaN(result))
-			} else {
-				assert.Equal(t, tt.want, result)
-			}
-		})
+func TestClosedKlines(t *testing.T) {
+	asOf := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	klines := []Kline{
+		{OpenTime: asOf.Add(-6 * time.Minute).UnixMilli()},
+		{OpenTime: asOf.Add(-3 * time.Minute).UnixMilli()},
+		{OpenTime: asOf.UnixMilli()}, // opened at the rollover, still forming
 	}
+	assert.Len(t, closedKlines(klines, "3m", asOf), 2)
+	assert.Len(t, closedKlines(klines, "3m", asOf.Add(-time.Millisecond)), 1)
+	assert.Len(t, closedKlines(klines, "3m", asOf.Add(3*time.Minute)), 3)
 }
@@ -0,0 +1,178 @@
+package hyperliquid
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"nof0-api/pkg/market"
+)
+
+// historyPageBars bounds one candleSnapshot request; the endpoint returns at
+// most 5000 candles.
+const historyPageBars = 2000
+
+var _ market.TradeFeed = (*KlineFeed)(nil)
+
+func init() {
+	market.RegisterKlineFeed("hyperliquid", func(cfg market.KlineFeedConfig) (market.KlineFeed, error) {
+		var clientOptions []Option
+		if cfg.Testnet {
+			clientOptions = append(clientOptions, WithBaseURL(testnetBaseURL))
+		}
+		return NewKlineFeed(NewClient(clientOptions...), WithStreamURL(cfg.URL)), nil
+	})
+}
+
+// KlineFeed adapts CandleStream to market.TradeFeed, mapping caller symbols
+// to canonical coins.
+type KlineFeed struct {
+	client *Client
+	stream *CandleStream
+
+	mu        sync.Mutex
+	subs      []streamKey       // caller symbol and interval
+	tradeSubs []string          // caller symbols
+	symbols   map[string]string // canonical coin -> caller symbol
+	handle    func(market.KlineUpdate)
+	onTrade   func(market.TradeUpdate)
+}
+
+// NewKlineFeed builds a feed reading candles and trades through client.
+func NewKlineFeed(client *Client, opts ...StreamOption) *KlineFeed {
+	f := &KlineFeed{client: client, symbols: make(map[string]string)}
+	f.stream = NewCandleStream(client, f.dispatch, append(opts, WithTradeHandler(f.dispatchTrade))...)
+	return f
+}
+
+// Subscribe implements market.KlineFeed.
+func (f *KlineFeed) Subscribe(symbol, interval string) error {
+	if _, ok := intervalDurations[interval]; !ok {
+		return fmt.Errorf("hyperliquid: unsupported interval %q", interval)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subs = append(f.subs, streamKey{coin: symbol, interval: interval})
+	return nil
+}
+
+// SubscribeTrades implements market.TradeFeed.
+func (f *KlineFeed) SubscribeTrades(symbol string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tradeSubs = append(f.tradeSubs, symbol)
+	return nil
+}
+
+// OnTrade implements market.TradeFeed.
+func (f *KlineFeed) OnTrade(handle func(market.TradeUpdate)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onTrade = handle
+}
+
+// Run implements market.KlineFeed. Candles recovered over REST after a gap
+// are marked closed once their close time has passed; live updates never
+// are, as the websocket does not say when a candle is final.
+func (f *KlineFeed) Run(ctx context.Context, handle func(market.KlineUpdate)) error {
+	f.mu.Lock()
+	subs := append([]streamKey(nil), f.subs...)
+	tradeSubs := append([]string(nil), f.tradeSubs...)
+	f.handle = handle
+	f.mu.Unlock()
+	for _, sub := range subs {
+		coin, err := f.resolve(ctx, sub.coin)
+		if err != nil {
+			return err
+		}
+		if err := f.stream.Subscribe(coin, sub.interval); err != nil {
+			return err
+		}
+	}
+	for _, symbol := range tradeSubs {
+		coin, err := f.resolve(ctx, symbol)
+		if err != nil {
+			return err
+		}
+		if err := f.stream.SubscribeTrades(coin); err != nil {
+			return err
+		}
+	}
+	return f.stream.Run(ctx)
+}
+
+// resolve maps a caller symbol to its canonical coin and remembers the
+// reverse mapping for dispatch.
+func (f *KlineFeed) resolve(ctx context.Context, symbol string) (string, error) {
+	coin, err := f.client.canonicalSymbolFor(ctx, symbol)
+	if err != nil {
+		return "", fmt.Errorf("hyperliquid: resolve %s: %w", symbol, err)
+	}
+	f.mu.Lock()
+	f.symbols[coin] = symbol
+	f.mu.Unlock()
+	return coin, nil
+}
+
+// History implements market.KlineFeed, paging through candleSnapshot.
+func (f *KlineFeed) History(ctx context.Context, symbol, interval string, from, to time.Time) ([]market.Candle, error) {
+	step, ok := intervalDurations[interval]
+	if !ok {
+		return nil, fmt.Errorf("hyperliquid: unsupported interval %q", interval)
+	}
+	coin, err := f.client.canonicalSymbolFor(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	var out []market.Candle
+	for start := from; start.Before(to); start = start.Add(historyPageBars * step) {
+		end := start.Add(historyPageBars * step)
+		if end.After(to) {
+			end = to
+		}
+		klines, err := f.client.candleRange(ctx, coin, interval, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range toCandles(klines) {
+			if c.OpenTime >= start.UnixMilli() && c.OpenTime < end.UnixMilli() {
+				out = append(out, c)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (f *KlineFeed) dispatch(ev CandleEvent) {
+	f.mu.Lock()
+	handle := f.handle
+	symbol, ok := f.symbols[ev.Coin]
+	f.mu.Unlock()
+	if handle == nil {
+		return
+	}
+	if !ok {
+		symbol = ev.Coin
+	}
+	handle(market.KlineUpdate{
+		Symbol:   symbol,
+		Interval: ev.Interval,
+		Candle:   toCandles([]Kline{ev.Kline})[0],
+		Closed:   ev.Recovered && ev.Kline.CloseTime < time.Now().UnixMilli(),
+	})
+}
+
+func (f *KlineFeed) dispatchTrade(ev TradeEvent) {
+	f.mu.Lock()
+	handle := f.onTrade
+	symbol, ok := f.symbols[ev.Coin]
+	f.mu.Unlock()
+	if handle == nil {
+		return
+	}
+	if !ok {
+		symbol = ev.Coin
+	}
+	handle(market.TradeUpdate{Symbol: symbol, Price: ev.Price, Size: ev.Size, Time: ev.Time})
+}
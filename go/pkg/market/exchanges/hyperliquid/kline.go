@@ -8,8 +8,12 @@ import (
 )
 
 var intervalDurations = map[string]time.Duration{
-	"3m": 3 * time.Minute,
-	"4h": 4 * time.Hour,
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
 }
 
 // GetKlines fetches OHLCV data for the given interval.
@@ -29,23 +33,37 @@ func (c *Client) GetKlines(ctx context.Context, symbol string, interval string,
 	endTime := time.Now().UTC()
 	startTime := endTime.Add(-duration * time.Duration(limit+10))
 
+	klines, err := c.candleRange(ctx, canonical, interval, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("hyperliquid: empty kline response for %s %s", canonical, interval)
+	}
+	if len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+
+	return klines, nil
+}
+
+// candleRange fetches candles for a canonical coin opened between start and
+// end, ordered by open time.
+func (c *Client) candleRange(ctx context.Context, coin, interval string, start, end time.Time) ([]Kline, error) {
 	var response CandleResponse
 	request := InfoRequest{
 		Type: "candleSnapshot",
 		Req: CandleSnapshotRequest{
-			Coin:      canonical,
+			Coin:      coin,
 			Interval:  interval,
-			StartTime: startTime.UnixMilli(),
-			EndTime:   endTime.UnixMilli(),
+			StartTime: start.UnixMilli(),
+			EndTime:   end.UnixMilli(),
 		},
 	}
 
 	if err := c.doRequest(ctx, request, &response); err != nil {
 		return nil, err
 	}
-	if len(response) == 0 {
-		return nil, fmt.Errorf("hyperliquid: empty kline response for %s %s", canonical, interval)
-	}
 
 	klines := make([]Kline, 0, len(response))
 	for _, item := range response {
@@ -63,10 +81,5 @@ func (c *Client) GetKlines(ctx context.Context, symbol string, interval string,
 	sort.Slice(klines, func(i, j int) bool {
 		return klines[i].OpenTime < klines[j].OpenTime
 	})
-
-	if len(klines) > limit {
-		klines = klines[len(klines)-limit:]
-	}
-
 	return klines, nil
 }
@@ -10,12 +10,16 @@ import (
 
 // MarketInfo aggregates key market metrics returned by metaAndAssetCtxs.
 type MarketInfo struct {
-	Symbol       string  // Canonical Hyperliquid symbol
-	MarkPrice    float64 // Mark price
-	MidPrice     float64 // Mid price
-	FundingRate  float64 // Funding rate (decimal, not percentage)
-	OpenInterest float64 // Current open interest
-	DayVolume    float64 // 24h base volume
+	Symbol            string  // Canonical Hyperliquid symbol
+	MarkPrice         float64 // Mark price
+	MidPrice          float64 // Mid price
+	OraclePrice       float64 // Oracle price
+	FundingRate       float64 // Funding rate (decimal, not percentage)
+	OpenInterest      float64 // Current open interest
+	DayVolume         float64 // 24h base volume
+	DayNotionalVolume float64 // 24h notional volume
+	Premium           float64 // Mark premium over the oracle (decimal)
+	PrevDayPrice      float64 // Price 24h ago
 }
 
 // GetCurrentPrice returns the current mid price for the given symbol.
@@ -54,6 +58,31 @@ func (c *Client) GetMarketInfo(ctx context.Context, symbol string) (*MarketInfo,
 	if !ok {
 		return nil, ErrSymbolNotFound
 	}
+	return parseMarketInfo(canonical, ctxData)
+}
+
+// GetMarketInfos retrieves MarketInfo for several symbols with a single
+// metaAndAssetCtxs request. Unlisted symbols are omitted.
+func (c *Client) GetMarketInfos(ctx context.Context, symbols []string) ([]*MarketInfo, error) {
+	if err := c.refreshSymbolDirectory(ctx); err != nil {
+		return nil, err
+	}
+	out := make([]*MarketInfo, 0, len(symbols))
+	for _, symbol := range symbols {
+		canonical, ctxData, ok := c.assetCtxFromCache(symbol)
+		if !ok {
+			continue
+		}
+		info, err := parseMarketInfo(canonical, ctxData)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, info)
+	}
+	return out, nil
+}
+
+func parseMarketInfo(canonical string, ctxData AssetCtx) (*MarketInfo, error) {
 	mark, err := parseFloat(ctxData.MarkPx)
 	if err != nil {
 		return nil, fmt.Errorf("hyperliquid: parse mark price: %w", err)
@@ -79,27 +108,36 @@ func (c *Client) GetMarketInfo(ctx context.Context, symbol string) (*MarketInfo,
 	if math.IsNaN(oi) {
 		oi = 0
 	}
+	notional, err := parseFloat(ctxData.DayNtlVlm)
+	if err != nil {
+		return nil, fmt.Errorf("hyperliquid: parse dayNotional volume: %w", err)
+	}
+	if math.IsNaN(notional) {
+		notional = 0
+	}
 	dayVolume, err := parseFloat(ctxData.DayBaseVlm)
 	if err != nil {
 		return nil, fmt.Errorf("hyperliquid: parse dayBase volume: %w", err)
 	}
 	if math.IsNaN(dayVolume) {
-		dayVolume, err = parseFloat(ctxData.DayNtlVlm)
-		if err != nil {
-			return nil, fmt.Errorf("hyperliquid: parse dayNotional volume: %w", err)
-		}
-		if math.IsNaN(dayVolume) {
-			dayVolume = 0
-		}
+		dayVolume = notional
 	}
+	// The remaining fields are informational; unparsable values read as zero.
+	oracle, _ := parseFloat(ctxData.OraclePx)
+	premium, _ := parseFloat(ctxData.Premium)
+	prevDay, _ := parseFloat(ctxData.PrevDayPx)
 
 	return &MarketInfo{
-		Symbol:       canonical,
-		MarkPrice:    mark,
-		MidPrice:     mid,
-		FundingRate:  funding,
-		OpenInterest: oi,
-		DayVolume:    dayVolume,
+		Symbol:            canonical,
+		MarkPrice:         mark,
+		MidPrice:          mid,
+		OraclePrice:       zeroIfNaN(oracle),
+		FundingRate:       funding,
+		OpenInterest:      oi,
+		DayVolume:         dayVolume,
+		DayNotionalVolume: notional,
+		Premium:           zeroIfNaN(premium),
+		PrevDayPrice:      zeroIfNaN(prevDay),
 	}, nil
 }
 
@@ -113,3 +151,10 @@ func parseFloat(val string) (float64, error) {
 	}
 	return strconv.ParseFloat(val, 64)
 }
+
+func zeroIfNaN(v float64) float64 {
+	if math.IsNaN(v) {
+		return 0
+	}
+	return v
+}
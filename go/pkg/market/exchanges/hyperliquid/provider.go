@@ -20,6 +20,7 @@ type Provider struct {
 	client      *Client
 	timeout     time.Duration
 	persistence market.Persistence
+	averages    market.MetricsAverager
 	providerID  string
 	cacheMu     sync.RWMutex
 	snapshots   map[string]cachedSnapshot
@@ -103,6 +104,11 @@ func (p *Provider) Snapshot(ctx context.Context, symbol string) (*market.Snapsho
 	if err != nil {
 		return nil, err
 	}
+	if p.averages != nil {
+		if avg, ok := p.averages.MetricAverages(snap.Symbol); ok {
+			market.ApplyMetricAverages(snap, avg)
+		}
+	}
 	p.persistSnapshot(ctx, symbol, snap)
 	if len(ticks) > 0 && p.persistence != nil {
 		if err := p.persistence.RecordPriceSeries(ctx, p.providerName(), symbol, ticks); err != nil {
@@ -180,6 +186,40 @@ func (p *Provider) SetPersistence(persist market.Persistence) {
 	p.persistence = persist
 }
 
+// SetMetricsAverager supplies rolling open interest and volume averages for
+// snapshots, which otherwise repeat the latest readings.
+func (p *Provider) SetMetricsAverager(a market.MetricsAverager) {
+	p.averages = a
+}
+
+// Metrics implements market.MetricsProvider with one metaAndAssetCtxs call.
+func (p *Provider) Metrics(ctx context.Context, symbols ...string) ([]market.Metrics, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	infos, err := p.client.GetMarketInfos(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	out := make([]market.Metrics, 0, len(infos))
+	for _, info := range infos {
+		out = append(out, market.Metrics{
+			Symbol:            info.Symbol,
+			MarkPrice:         info.MarkPrice,
+			MidPrice:          info.MidPrice,
+			OraclePrice:       info.OraclePrice,
+			FundingRate:       info.FundingRate,
+			OpenInterest:      info.OpenInterest,
+			DayVolume:         info.DayVolume,
+			DayNotionalVolume: info.DayNotionalVolume,
+			Premium:           info.Premium,
+			PrevDayPrice:      info.PrevDayPrice,
+			EventAt:           now,
+		})
+	}
+	return out, nil
+}
+
 const (
 	snapshotCacheTTL = 15 * time.Second
 	assetCacheTTL    = 5 * time.Minute
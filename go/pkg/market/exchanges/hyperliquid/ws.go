@@ -0,0 +1,459 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	defaultWSURL          = "wss://api.hyperliquid.xyz/ws"
+	defaultWSBackoffMin   = 500 * time.Millisecond
+	defaultWSBackoffMax   = 30 * time.Second
+	defaultWSPingInterval = 20 * time.Second
+	wsCatchUpTimeout      = 10 * time.Second
+)
+
+// CandleEvent is one candle update delivered by CandleStream. Recovered is
+// true for candles fetched over REST to fill a gap or catch up after a
+// reconnect rather than received on the socket.
+type CandleEvent struct {
+	Coin      string
+	Interval  string
+	Kline     Kline
+	Recovered bool
+}
+
+// TradeEvent is one trade delivered by CandleStream. Trades missed while
+// disconnected are not recovered.
+type TradeEvent struct {
+	Coin  string
+	Price float64
+	Size  float64
+	Time  int64 // ms
+}
+
+// wsConn is the subset of a websocket connection CandleStream uses.
+type wsConn interface {
+	Receive() ([]byte, error)
+	Send(msg []byte) error
+	SetReadDeadline(t time.Time) error
+	Close() error
+}
+
+type streamKey struct {
+	coin     string
+	interval string
+}
+
+// CandleStream keeps candle and trade subscriptions alive over the
+// Hyperliquid websocket. It reconnects with exponential backoff, replays
+// subscriptions, and fills missing candles from the REST candleSnapshot
+// endpoint. The feed carries no sequence numbers, so gaps are detected from
+// candle open times.
+type CandleStream struct {
+	url          string
+	rest         *Client
+	handler      func(CandleEvent)
+	onTrade      func(TradeEvent)
+	backoffMin   time.Duration
+	backoffMax   time.Duration
+	pingInterval time.Duration
+	dial         func(ctx context.Context, url string) (wsConn, error)
+
+	mu     sync.Mutex
+	conn   wsConn
+	last   map[streamKey]int64 // last seen open time (ms) per subscription
+	trades map[string]bool     // coins with a trades subscription
+}
+
+// StreamOption configures a CandleStream.
+type StreamOption func(*CandleStream)
+
+// WithStreamURL overrides the websocket endpoint.
+func WithStreamURL(url string) StreamOption {
+	return func(s *CandleStream) {
+		if url != "" {
+			s.url = url
+		}
+	}
+}
+
+// WithStreamBackoff bounds the reconnect delay.
+func WithStreamBackoff(min, max time.Duration) StreamOption {
+	return func(s *CandleStream) {
+		if min > 0 && max >= min {
+			s.backoffMin, s.backoffMax = min, max
+		}
+	}
+}
+
+// WithTradeHandler delivers the trades of coins subscribed with
+// SubscribeTrades to handler.
+func WithTradeHandler(handler func(TradeEvent)) StreamOption {
+	return func(s *CandleStream) { s.onTrade = handler }
+}
+
+// WithStreamPingInterval sets how often the stream pings; a connection silent
+// for two intervals is treated as dead.
+func WithStreamPingInterval(d time.Duration) StreamOption {
+	return func(s *CandleStream) {
+		if d > 0 {
+			s.pingInterval = d
+		}
+	}
+}
+
+// NewCandleStream builds a stream that delivers candles to handler and uses
+// rest for catch-up fetches. The websocket URL defaults to the endpoint that
+// matches rest's info URL.
+func NewCandleStream(rest *Client, handler func(CandleEvent), opts ...StreamOption) *CandleStream {
+	s := &CandleStream{
+		url:          defaultWSURL,
+		rest:         rest,
+		handler:      handler,
+		backoffMin:   defaultWSBackoffMin,
+		backoffMax:   defaultWSBackoffMax,
+		pingInterval: defaultWSPingInterval,
+		dial:         dialWebsocket,
+		last:         make(map[streamKey]int64),
+		trades:       make(map[string]bool),
+	}
+	if rest != nil {
+		s.url = wsURLFor(rest.baseURL)
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// wsURLFor maps an info endpoint such as https://api.hyperliquid.xyz/info to
+// its websocket endpoint.
+func wsURLFor(infoURL string) string {
+	url := strings.TrimSuffix(infoURL, "/info")
+	switch {
+	case strings.HasPrefix(url, "https://"):
+		url = "wss://" + strings.TrimPrefix(url, "https://")
+	case strings.HasPrefix(url, "http://"):
+		url = "ws://" + strings.TrimPrefix(url, "http://")
+	default:
+		return defaultWSURL
+	}
+	return url + "/ws"
+}
+
+// Subscribe adds a candle subscription. It is sent immediately when connected
+// and replayed on every reconnect.
+func (s *CandleStream) Subscribe(coin, interval string) error {
+	if _, ok := intervalDurations[interval]; !ok {
+		return fmt.Errorf("hyperliquid: unsupported interval %q", interval)
+	}
+	key := streamKey{coin: coin, interval: interval}
+	s.mu.Lock()
+	if _, ok := s.last[key]; !ok {
+		s.last[key] = 0
+	}
+	conn := s.conn
+	s.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+	return conn.Send(subscribeMessage(key))
+}
+
+// SubscribeTrades adds a trades subscription for coin, sent immediately when
+// connected and replayed on every reconnect.
+func (s *CandleStream) SubscribeTrades(coin string) error {
+	s.mu.Lock()
+	s.trades[coin] = true
+	conn := s.conn
+	s.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+	return conn.Send(tradesSubscribeMessage(coin))
+}
+
+// Run connects and serves subscriptions until ctx is cancelled, reconnecting
+// with jittered exponential backoff whenever the connection drops.
+func (s *CandleStream) Run(ctx context.Context) error {
+	backoff := s.backoffMin
+	for {
+		connected, err := s.serve(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if connected {
+			backoff = s.backoffMin
+		}
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		s.logf("hyperliquid: websocket disconnected: %v; reconnecting in %s", err, delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		backoff *= 2
+		if backoff > s.backoffMax {
+			backoff = s.backoffMax
+		}
+	}
+}
+
+// serve runs one connection: subscribe, catch up, then read until failure.
+// connected reports whether the subscriptions were replayed successfully.
+func (s *CandleStream) serve(ctx context.Context) (connected bool, err error) {
+	conn, err := s.dial(ctx, s.url)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	s.mu.Lock()
+	keys := make([]streamKey, 0, len(s.last))
+	for key := range s.last {
+		keys = append(keys, key)
+	}
+	coins := make([]string, 0, len(s.trades))
+	for coin := range s.trades {
+		coins = append(coins, coin)
+	}
+	s.conn = conn
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.conn = nil
+		s.mu.Unlock()
+	}()
+
+	for _, key := range keys {
+		if err := conn.Send(subscribeMessage(key)); err != nil {
+			return false, err
+		}
+	}
+	for _, coin := range coins {
+		if err := conn.Send(tradesSubscribeMessage(coin)); err != nil {
+			return false, err
+		}
+	}
+	for _, key := range keys {
+		s.catchUp(ctx, key, time.Now())
+	}
+
+	pingCtx, stopPing := context.WithCancel(ctx)
+	defer stopPing()
+	go s.ping(pingCtx, conn)
+
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(2 * s.pingInterval)); err != nil {
+			return true, err
+		}
+		data, err := conn.Receive()
+		if err != nil {
+			return true, err
+		}
+		s.handleMessage(ctx, data)
+	}
+}
+
+func (s *CandleStream) ping(ctx context.Context, conn wsConn) {
+	ticker := time.NewTicker(s.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := conn.Send([]byte(`{"method":"ping"}`)); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+type wsCandle struct {
+	T      int64  `json:"t"`
+	TClose int64  `json:"T"`
+	S      string `json:"s"`
+	I      string `json:"i"`
+	O      string `json:"o"`
+	C      string `json:"c"`
+	H      string `json:"h"`
+	L      string `json:"l"`
+	V      string `json:"v"`
+}
+
+func (c wsCandle) kline() Kline {
+	parse := func(v string) float64 {
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+	return Kline{OpenTime: c.T, Open: parse(c.O), High: parse(c.H), Low: parse(c.L), Close: parse(c.C), Volume: parse(c.V), CloseTime: c.TClose}
+}
+
+type wsTrade struct {
+	Coin string `json:"coin"`
+	Px   string `json:"px"`
+	Sz   string `json:"sz"`
+	Time int64  `json:"time"`
+}
+
+func (s *CandleStream) handleMessage(ctx context.Context, data []byte) {
+	var msg struct {
+		Channel string          `json:"channel"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return
+	}
+	switch msg.Channel {
+	case "candle":
+		s.handleCandle(ctx, msg.Data)
+	case "trades":
+		s.handleTrades(msg.Data)
+	}
+}
+
+func (s *CandleStream) handleTrades(data json.RawMessage) {
+	var trades []wsTrade
+	if err := json.Unmarshal(data, &trades); err != nil {
+		s.logf("hyperliquid: websocket trades decode: %v", err)
+		return
+	}
+	for _, t := range trades {
+		s.mu.Lock()
+		subscribed := s.trades[t.Coin]
+		s.mu.Unlock()
+		if !subscribed || s.onTrade == nil {
+			continue
+		}
+		px, _ := strconv.ParseFloat(t.Px, 64)
+		sz, _ := strconv.ParseFloat(t.Sz, 64)
+		s.onTrade(TradeEvent{Coin: t.Coin, Price: px, Size: sz, Time: t.Time})
+	}
+}
+
+func (s *CandleStream) handleCandle(ctx context.Context, data json.RawMessage) {
+	var candle wsCandle
+	if err := json.Unmarshal(data, &candle); err != nil {
+		s.logf("hyperliquid: websocket candle decode: %v", err)
+		return
+	}
+	key := streamKey{coin: candle.S, interval: candle.I}
+	step := intervalDurations[candle.I].Milliseconds()
+
+	s.mu.Lock()
+	last, subscribed := s.last[key]
+	s.mu.Unlock()
+	if !subscribed || candle.T < last {
+		return
+	}
+	if last > 0 && step > 0 && candle.T > last+step {
+		s.catchUp(ctx, key, time.UnixMilli(candle.T))
+	}
+	s.emit(key, candle.kline(), false)
+}
+
+// catchUp fetches candles opened from the last seen candle up to before
+// until over REST, re-emitting the last seen candle with its final values.
+func (s *CandleStream) catchUp(ctx context.Context, key streamKey, until time.Time) {
+	s.mu.Lock()
+	last := s.last[key]
+	s.mu.Unlock()
+	if last == 0 || s.rest == nil {
+		return
+	}
+	fetchCtx, cancel := context.WithTimeout(ctx, wsCatchUpTimeout)
+	defer cancel()
+	klines, err := s.rest.candleRange(fetchCtx, key.coin, key.interval, time.UnixMilli(last), until)
+	if err != nil {
+		s.logf("hyperliquid: websocket catch-up %s %s: %v", key.coin, key.interval, err)
+		return
+	}
+	for _, k := range klines {
+		if k.OpenTime >= last && k.OpenTime < until.UnixMilli() {
+			s.emit(key, k, true)
+		}
+	}
+}
+
+func (s *CandleStream) emit(key streamKey, k Kline, recovered bool) {
+	s.mu.Lock()
+	if k.OpenTime < s.last[key] {
+		s.mu.Unlock()
+		return
+	}
+	s.last[key] = k.OpenTime
+	s.mu.Unlock()
+	if s.handler != nil {
+		s.handler(CandleEvent{Coin: key.coin, Interval: key.interval, Kline: k, Recovered: recovered})
+	}
+}
+
+func (s *CandleStream) logf(format string, args ...interface{}) {
+	if s.rest != nil {
+		s.rest.logf(format, args...)
+	}
+}
+
+func subscribeMessage(key streamKey) []byte {
+	msg, _ := json.Marshal(map[string]any{
+		"method": "subscribe",
+		"subscription": map[string]string{
+			"type":     "candle",
+			"coin":     key.coin,
+			"interval": key.interval,
+		},
+	})
+	return msg
+}
+
+func tradesSubscribeMessage(coin string) []byte {
+	msg, _ := json.Marshal(map[string]any{
+		"method": "subscribe",
+		"subscription": map[string]string{
+			"type": "trades",
+			"coin": coin,
+		},
+	})
+	return msg
+}
+
+// netConn adapts golang.org/x/net/websocket to wsConn.
+type netConn struct {
+	*websocket.Conn
+	sendMu sync.Mutex
+}
+
+func dialWebsocket(ctx context.Context, url string) (wsConn, error) {
+	cfg, err := websocket.NewConfig(url, "https://app.hyperliquid.xyz")
+	if err != nil {
+		return nil, fmt.Errorf("hyperliquid: websocket config: %w", err)
+	}
+	conn, err := cfg.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("hyperliquid: websocket dial: %w", err)
+	}
+	return &netConn{Conn: conn}, nil
+}
+
+func (c *netConn) Receive() ([]byte, error) {
+	var data []byte
+	err := websocket.Message.Receive(c.Conn, &data)
+	return data, err
+}
+
+func (c *netConn) Send(msg []byte) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	return websocket.Message.Send(c.Conn, string(msg))
+}
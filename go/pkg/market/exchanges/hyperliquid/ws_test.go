@@ -0,0 +1,183 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWSConn replays scripted frames, then fails as if the network dropped.
+type fakeWSConn struct {
+	frames chan []byte
+	mu     sync.Mutex
+	sent   []string
+}
+
+func newFakeWSConn(frames ...string) *fakeWSConn {
+	c := &fakeWSConn{frames: make(chan []byte, len(frames))}
+	for _, f := range frames {
+		c.frames <- []byte(f)
+	}
+	close(c.frames)
+	return c
+}
+
+func (c *fakeWSConn) Receive() ([]byte, error) {
+	if f, ok := <-c.frames; ok {
+		return f, nil
+	}
+	return nil, errors.New("connection reset")
+}
+
+func (c *fakeWSConn) Send(msg []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, string(msg))
+	return nil
+}
+
+func (c *fakeWSConn) SetReadDeadline(time.Time) error { return nil }
+func (c *fakeWSConn) Close() error                    { return nil }
+
+func candleFrame(openMs int64, close float64) string {
+	return fmt.Sprintf(`{"channel":"candle","data":{"t":%d,"T":%d,"s":"BTC","i":"1m","o":"1","c":"%g","h":"1","l":"1","v":"1"}}`, openMs, openMs+59_999, close)
+}
+
+func TestCandleStreamReconnectsAndFillsGaps(t *testing.T) {
+	const minute = int64(60_000)
+	// REST history grows like the exchange's: minutes 0..4 exist when the
+	// stream reconnects, 0..7 by the time the gap is noticed.
+	var restCalls [][2]int64
+	var restMu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Type string                `json:"type"`
+			Req  CandleSnapshotRequest `json:"req"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		restMu.Lock()
+		restCalls = append(restCalls, [2]int64{req.Req.StartTime, req.Req.EndTime})
+		horizon := int64(7)
+		if len(restCalls) == 1 {
+			horizon = 4
+		}
+		restMu.Unlock()
+		var out []map[string]any
+		for i := int64(0); i <= horizon; i++ {
+			open := i * minute
+			if open < req.Req.StartTime || open > req.Req.EndTime {
+				continue
+			}
+			out = append(out, map[string]any{"t": open, "T": open + minute - 1, "s": "BTC", "i": "1m", "o": "1", "c": fmt.Sprint(100 + i), "h": "1", "l": "1", "v": "1"})
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	}))
+	defer server.Close()
+
+	conns := []*fakeWSConn{
+		// Minutes 0 and 1, then the connection drops.
+		newFakeWSConn(`{"channel":"subscriptionResponse","data":{}}`, candleFrame(0, 100), candleFrame(minute, 101)),
+		// After reconnect: minute 4 arrives, then 7 (skipping 5 and 6).
+		newFakeWSConn(candleFrame(4*minute, 104), candleFrame(7*minute, 107)),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		events []CandleEvent
+	)
+	stream := NewCandleStream(NewClient(WithBaseURL(server.URL), WithMaxRetries(0)), func(ev CandleEvent) {
+		mu.Lock()
+		events = append(events, ev)
+		mu.Unlock()
+	}, WithStreamBackoff(time.Millisecond, 2*time.Millisecond))
+	var dials int
+	stream.dial = func(context.Context, string) (wsConn, error) {
+		if dials >= len(conns) {
+			cancel()
+			return nil, context.Canceled
+		}
+		c := conns[dials]
+		dials++
+		return c, nil
+	}
+	require.NoError(t, stream.Subscribe("BTC", "1m"))
+	assert.ErrorIs(t, stream.Run(ctx), context.Canceled)
+
+	for _, c := range conns {
+		require.NotEmpty(t, c.sent)
+		assert.JSONEq(t, `{"method":"subscribe","subscription":{"type":"candle","coin":"BTC","interval":"1m"}}`, c.sent[0], "subscription is replayed on each connection")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var opens []int64
+	seen := map[int64]bool{}
+	for _, ev := range events {
+		if !seen[ev.Kline.OpenTime] {
+			opens = append(opens, ev.Kline.OpenTime/minute)
+			seen[ev.Kline.OpenTime] = true
+		}
+	}
+	assert.Equal(t, []int64{0, 1, 2, 3, 4, 5, 6, 7}, opens, "no candle goes missing across the drop and the gap")
+	for _, ev := range events {
+		switch ev.Kline.OpenTime / minute {
+		case 2, 3, 5, 6:
+			assert.True(t, ev.Recovered, "minute %d comes from REST", ev.Kline.OpenTime/minute)
+		case 0, 7:
+			assert.False(t, ev.Recovered)
+		}
+	}
+	restMu.Lock()
+	defer restMu.Unlock()
+	require.Len(t, restCalls, 2, "one catch-up on reconnect and one for the gap")
+	assert.Equal(t, minute, restCalls[0][0], "reconnect catch-up starts at the last seen candle")
+	assert.Equal(t, [2]int64{4 * minute, 7 * minute}, restCalls[1])
+}
+
+func TestCandleStreamTrades(t *testing.T) {
+	conns := []*fakeWSConn{
+		newFakeWSConn(`{"channel":"trades","data":[{"coin":"BTC","side":"B","px":"100.5","sz":"0.2","time":61000},{"coin":"ETH","px":"10","sz":"1","time":61000}]}`),
+		newFakeWSConn(),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var trades []TradeEvent
+	stream := NewCandleStream(NewClient(WithMaxRetries(0)), func(CandleEvent) {},
+		WithStreamBackoff(time.Millisecond, 2*time.Millisecond),
+		WithTradeHandler(func(ev TradeEvent) { trades = append(trades, ev) }))
+	var dials int
+	stream.dial = func(context.Context, string) (wsConn, error) {
+		if dials >= len(conns) {
+			cancel()
+			return nil, context.Canceled
+		}
+		c := conns[dials]
+		dials++
+		return c, nil
+	}
+	require.NoError(t, stream.SubscribeTrades("BTC"))
+	assert.ErrorIs(t, stream.Run(ctx), context.Canceled)
+
+	for _, c := range conns {
+		require.Len(t, c.sent, 1)
+		assert.JSONEq(t, `{"method":"subscribe","subscription":{"type":"trades","coin":"BTC"}}`, c.sent[0], "trades subscription is replayed on each connection")
+	}
+	assert.Equal(t, []TradeEvent{{Coin: "BTC", Price: 100.5, Size: 0.2, Time: 61000}}, trades, "unsubscribed coins are ignored")
+}
+
+func TestWSURLFor(t *testing.T) {
+	assert.Equal(t, "wss://api.hyperliquid.xyz/ws", wsURLFor(defaultBaseURL))
+	assert.Equal(t, "wss://api.hyperliquid-testnet.xyz/ws", wsURLFor(testnetBaseURL))
+	assert.Equal(t, "ws://127.0.0.1:8080/ws", wsURLFor("http://127.0.0.1:8080"))
+}
@@ -142,6 +142,22 @@ func ATR(klines []Kline, period int) []float64 {
 	return EMA(tr, period)
 }
 
+// EMAWarmup is the number of prices an EMA of period needs before the SMA
+// seed has decayed enough for the value to be trusted: the seed window plus
+// as many bars again.
+func EMAWarmup(period int) int { return 2 * period }
+
+// MACDWarmup is the number of prices MACD needs for its slow EMA to settle
+// and its signal line to cover settled values.
+func MACDWarmup() int { return EMAWarmup(26) + 9 }
+
+// RSIWarmup is the number of prices RSI needs for Wilder smoothing to move
+// past its initial simple average.
+func RSIWarmup(period int) int { return 2*period + 1 }
+
+// ATRWarmup is the number of klines ATR needs; it smooths true range with an EMA.
+func ATRWarmup(period int) int { return EMAWarmup(period) }
+
 // Kline represents OHLCV input for ATR calculations.
 type Kline struct {
 	High  float64
@@ -54,3 +54,10 @@ func TestATR(t *testing.T) {
 	require.Len(t, atr, len(klines))
 	require.InDelta(t, 3.326525, atr[len(atr)-1], 1e-6)
 }
+
+func TestWarmup(t *testing.T) {
+	require.Equal(t, 40, EMAWarmup(20))
+	require.Equal(t, 61, MACDWarmup())
+	require.Equal(t, 29, RSIWarmup(14))
+	require.Equal(t, 28, ATRWarmup(14))
+}
@@ -0,0 +1,170 @@
+package indicators
+
+import (
+	"math"
+
+	"nof0-api/pkg/market"
+)
+
+// Frame selects the indicator set computed for one timeframe. The intraday
+// frame carries the fast set shown for short-term structure; the long-term
+// frame adds EMA50 and ATR for trend and risk sizing.
+type Frame string
+
+const (
+	FrameIntraday Frame = "intraday"
+	FrameLongTerm Frame = "long_term"
+)
+
+// Current holds the latest value of each indicator computed for a frame.
+// Indicators outside the frame or still warming up are NaN.
+type Current struct {
+	EMA20 float64
+	EMA50 float64
+	MACD  float64
+	RSI7  float64
+	RSI14 float64
+	ATR3  float64
+	ATR14 float64
+}
+
+func newCurrent() Current {
+	nan := math.NaN()
+	return Current{EMA20: nan, EMA50: nan, MACD: nan, RSI7: nan, RSI14: nan, ATR3: nan, ATR14: nan}
+}
+
+// BuildSeries computes frame's indicators from candles (oldest first, closed
+// bars only) and returns the last length bars as a SeriesBundle together
+// with the latest values. Indicators without enough bars to warm up are left
+// out of the bundle and named in InsufficientHistory. The bundle is nil when
+// there are no candles.
+func BuildSeries(candles []market.Candle, frame Frame, length int) (*market.SeriesBundle, Current) {
+	cur := newCurrent()
+	if len(candles) == 0 {
+		return nil, cur
+	}
+	closes := make([]float64, len(candles))
+	volumes := make([]float64, len(candles))
+	atrInput := make([]Kline, len(candles))
+	for i, c := range candles {
+		closes[i] = c.Close
+		volumes[i] = c.Volume
+		atrInput[i] = Kline{High: c.High, Low: c.Low, Close: c.Close}
+	}
+
+	w := warmupTracker{length: length}
+	ema := map[string][]float64{}
+	rsi := map[string][]float64{}
+	atr := map[string][]float64{}
+
+	ema["EMA20"] = w.series("EMA20", EMA(closes, 20), EMAWarmup(20))
+	cur.EMA20 = latestNonNaN(ema["EMA20"])
+	if frame == FrameLongTerm {
+		ema["EMA50"] = w.series("EMA50", EMA(closes, 50), EMAWarmup(50))
+		cur.EMA50 = latestNonNaN(ema["EMA50"])
+	}
+	macdLine, _, _ := MACD(closes)
+	macd := w.series("MACD", macdLine, MACDWarmup())
+	cur.MACD = latestNonNaN(macd)
+	if frame == FrameIntraday {
+		rsi["RSI7"] = w.series("RSI7", RSI(closes, 7), RSIWarmup(7))
+		cur.RSI7 = latestNonNaN(rsi["RSI7"])
+	}
+	rsi["RSI14"] = w.series("RSI14", RSI(closes, 14), RSIWarmup(14))
+	cur.RSI14 = latestNonNaN(rsi["RSI14"])
+	if frame == FrameLongTerm {
+		atr["ATR3"] = w.series("ATR3", ATR(atrInput, 3), ATRWarmup(3))
+		atr["ATR14"] = w.series("ATR14", ATR(atrInput, 14), ATRWarmup(14))
+		cur.ATR3 = latestNonNaN(atr["ATR3"])
+		cur.ATR14 = latestNonNaN(atr["ATR14"])
+	}
+
+	shown := candles
+	if len(shown) > length {
+		shown = shown[len(shown)-length:]
+	}
+	series := &market.SeriesBundle{
+		Prices:              lastN(closes, length),
+		EMA:                 seriesMap(ema),
+		MACD:                macd,
+		RSI:                 seriesMap(rsi),
+		Volume:              lastN(volumes, length),
+		Candles:             append([]market.Candle(nil), shown...),
+		InsufficientHistory: w.missing,
+	}
+	if frame == FrameLongTerm {
+		series.ATR = seriesMap(atr)
+	}
+	return series, cur
+}
+
+// Summary merges the latest values of both frames into a snapshot's
+// IndicatorInfo: EMA20 and RSI7 come from the intraday frame, EMA20_Long,
+// EMA50 and RSI14 from the long-term frame, and MACD from intraday falling
+// back to long-term.
+func Summary(intraday, longTerm Current) market.IndicatorInfo {
+	info := market.IndicatorInfo{EMA: map[string]float64{}, RSI: map[string]float64{}}
+	setIfValid(info.EMA, "EMA20", intraday.EMA20)
+	setIfValid(info.RSI, "RSI7", intraday.RSI7)
+	setIfValid(info.EMA, "EMA20_Long", longTerm.EMA20)
+	setIfValid(info.EMA, "EMA50", longTerm.EMA50)
+	setIfValid(info.RSI, "RSI14", longTerm.RSI14)
+	if !math.IsNaN(intraday.MACD) {
+		info.MACD = intraday.MACD
+	} else if !math.IsNaN(longTerm.MACD) {
+		info.MACD = longTerm.MACD
+	}
+	return info
+}
+
+func setIfValid(m map[string]float64, key string, v float64) {
+	if !math.IsNaN(v) {
+		m[key] = v
+	}
+}
+
+// warmupTracker trims indicator series to the shown window and records the
+// ones whose oldest shown value was computed from fewer bars than warm-up
+// requires, instead of emitting seed-dominated values.
+type warmupTracker struct {
+	length  int
+	missing []string
+}
+
+func (w *warmupTracker) series(name string, values []float64, warmup int) []float64 {
+	shown := min(w.length, len(values))
+	if len(values)-shown+1 < warmup {
+		w.missing = append(w.missing, name)
+		return nil
+	}
+	return lastN(values, w.length)
+}
+
+// seriesMap drops series left out by warmupTracker.
+func seriesMap(in map[string][]float64) map[string][]float64 {
+	for name, values := range in {
+		if values == nil {
+			delete(in, name)
+		}
+	}
+	return in
+}
+
+func lastN(values []float64, count int) []float64 {
+	if len(values) == 0 {
+		return []float64{}
+	}
+	if len(values) <= count {
+		return append([]float64(nil), values...)
+	}
+	return append([]float64(nil), values[len(values)-count:]...)
+}
+
+func latestNonNaN(values []float64) float64 {
+	for i := len(values) - 1; i >= 0; i-- {
+		if !math.IsNaN(values[i]) {
+			return values[i]
+		}
+	}
+	return math.NaN()
+}
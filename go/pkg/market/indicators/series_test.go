@@ -0,0 +1,141 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/market"
+)
+
+func risingCandles(n int) []market.Candle {
+	candles := make([]market.Candle, n)
+	for i := range candles {
+		px := 100 + float64(i)
+		candles[i] = market.Candle{OpenTime: int64(i), Open: px, High: px + 1, Low: px - 1, Close: px, Volume: 10}
+	}
+	return candles
+}
+
+func TestBuildSeriesFrames(t *testing.T) {
+	series, cur := BuildSeries(risingCandles(120), FrameLongTerm, 10)
+	require.NotNil(t, series)
+	assert.Empty(t, series.InsufficientHistory)
+	assert.Len(t, series.Prices, 10)
+	assert.Len(t, series.Candles, 10)
+	assert.Equal(t, int64(119), series.Candles[9].OpenTime)
+	assert.ElementsMatch(t, []string{"EMA20", "EMA50"}, keys(series.EMA))
+	assert.ElementsMatch(t, []string{"ATR3", "ATR14"}, keys(series.ATR))
+	assert.ElementsMatch(t, []string{"RSI14"}, keys(series.RSI))
+	assert.InDelta(t, 2.0, cur.ATR14, 1e-6, "constant 2-point bars")
+	assert.Greater(t, cur.EMA20, cur.EMA50, "rising prices")
+	assert.True(t, math.IsNaN(cur.RSI7), "RSI7 is intraday only")
+
+	series, cur = BuildSeries(risingCandles(80), FrameIntraday, 10)
+	assert.ElementsMatch(t, []string{"RSI7", "RSI14"}, keys(series.RSI))
+	assert.Nil(t, series.ATR)
+	assert.False(t, math.IsNaN(cur.RSI7))
+
+	series, cur = BuildSeries(nil, FrameIntraday, 10)
+	assert.Nil(t, series)
+	assert.True(t, math.IsNaN(cur.EMA20))
+}
+
+func TestSummary(t *testing.T) {
+	intraday, long := newCurrent(), newCurrent()
+	intraday.EMA20, intraday.RSI7 = 101, 65
+	long.EMA20, long.EMA50, long.RSI14, long.MACD = 99, 95, 58, 1.5
+
+	info := Summary(intraday, long)
+	assert.Equal(t, map[string]float64{"EMA20": 101, "EMA20_Long": 99, "EMA50": 95}, info.EMA)
+	assert.Equal(t, map[string]float64{"RSI7": 65, "RSI14": 58}, info.RSI)
+	assert.Equal(t, 1.5, info.MACD, "falls back to the long-term MACD")
+
+	intraday.MACD = -0.5
+	assert.Equal(t, -0.5, Summary(intraday, long).MACD)
+}
+
+func keys(m map[string][]float64) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
+
+// TestLastN tests the lastN helper.
+func TestLastN(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		count  int
+		want   []float64
+	}{
+		{
+			name:   "normal case",
+			values: []float64{1, 2, 3, 4, 5},
+			count:  3,
+			want:   []float64{3, 4, 5},
+		},
+		{
+			name:   "count exceeds length",
+			values: []float64{1, 2},
+			count:  5,
+			want:   []float64{1, 2},
+		},
+		{
+			name:   "empty values",
+			values: []float64{},
+			count:  3,
+			want:   []float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := lastN(tt.values, tt.count)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+}
+
+// TestLatestNonNaN tests the latestNonNaN helper.
+func TestLatestNonNaN(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+		isNaN  bool
+	}{
+		{
+			name:   "last value is valid",
+			values: []float64{1.0, 2.0, 3.0},
+			want:   3.0,
+			isNaN:  false,
+		},
+		{
+			name:   "last values are NaN",
+			values: []float64{1.0, 2.0, math.NaN(), math.NaN()},
+			want:   2.0,
+			isNaN:  false,
+		},
+		{
+			name:   "all values are NaN",
+			values: []float64{math.NaN(), math.NaN()},
+			isNaN:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := latestNonNaN(tt.values)
+			if tt.isNaN {
+				assert.True(t, math.IsNaN(result))
+			} else {
+				assert.Equal(t, tt.want, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package indicators
+
+import (
+	"math"
+
+	"nof0-api/pkg/market"
+)
+
+// Stream is BuildSeries computed incrementally. It keeps the frame's
+// indicator state and the newest length points of every series, so a closed
+// candle is folded in with Push instead of recomputing the whole history.
+// After the same candles, Series returns what BuildSeries would.
+type Stream struct {
+	frame  Frame
+	length int
+	pushed int
+
+	candles []market.Candle
+	closes  []float64
+	volumes []float64
+	values  map[string][]float64
+
+	ema20, ema50, ema12, ema26 emaState
+	atr3, atr14                emaState
+	rsi7, rsi14                rsiState
+}
+
+// NewStream starts an empty stream keeping length points of frame's series.
+func NewStream(frame Frame, length int) *Stream {
+	return &Stream{
+		frame:  frame,
+		length: max(length, 1),
+		values: map[string][]float64{},
+		ema20:  newEMAState(20),
+		ema50:  newEMAState(50),
+		ema12:  newEMAState(12),
+		ema26:  newEMAState(26),
+		atr3:   newEMAState(3),
+		atr14:  newEMAState(14),
+		rsi7:   rsiState{period: 7},
+		rsi14:  rsiState{period: 14},
+	}
+}
+
+// Seed is the number of candles the stream needs before every shown point
+// of every indicator in its frame is past warm-up.
+func (s *Stream) Seed() int {
+	warmup := max(EMAWarmup(20), MACDWarmup(), RSIWarmup(14))
+	if s.frame == FrameLongTerm {
+		warmup = max(warmup, EMAWarmup(50), ATRWarmup(14))
+	}
+	return s.length + warmup - 1
+}
+
+// LastOpen is the open time of the newest candle pushed.
+func (s *Stream) LastOpen() (int64, bool) {
+	if len(s.candles) == 0 {
+		return 0, false
+	}
+	return s.candles[len(s.candles)-1].OpenTime, true
+}
+
+// Push folds in the next closed candle and drops the oldest point once the
+// stream holds length points. Candles not newer than the last one are
+// ignored.
+func (s *Stream) Push(c market.Candle) {
+	if last, ok := s.LastOpen(); ok && c.OpenTime <= last {
+		return
+	}
+	tr := c.High - c.Low
+	if n := len(s.closes); n > 0 {
+		prev := s.closes[n-1]
+		tr = math.Max(tr, math.Max(math.Abs(c.High-prev), math.Abs(c.Low-prev)))
+	}
+	s.rsi7.push(c.Close)
+	s.rsi14.push(c.Close)
+	s.pushed++
+	s.candles = roll(s.candles, c, s.length)
+	s.closes = roll(s.closes, c.Close, s.length)
+	s.volumes = roll(s.volumes, c.Volume, s.length)
+
+	macd := math.NaN()
+	if fast, slow := s.ema12.push(c.Close), s.ema26.push(c.Close); !math.IsNaN(fast) && !math.IsNaN(slow) {
+		macd = fast - slow
+	}
+	s.record("EMA20", s.ema20.push(c.Close))
+	s.record("MACD", macd)
+	s.record("RSI14", s.rsi14.value)
+	switch s.frame {
+	case FrameIntraday:
+		s.record("RSI7", s.rsi7.value)
+	case FrameLongTerm:
+		s.record("EMA50", s.ema50.push(c.Close))
+		s.record("ATR3", s.atr3.push(tr))
+		s.record("ATR14", s.atr14.push(tr))
+	}
+}
+
+func (s *Stream) record(name string, v float64) {
+	s.values[name] = roll(s.values[name], v, s.length)
+}
+
+// Series returns the stream's window as BuildSeries does. The bundle is nil
+// before the first candle.
+func (s *Stream) Series() (*market.SeriesBundle, Current) {
+	cur := newCurrent()
+	if s.pushed == 0 {
+		return nil, cur
+	}
+	w := streamWarmup{pushed: s.pushed, shown: len(s.closes)}
+	ema := map[string][]float64{}
+	rsi := map[string][]float64{}
+	atr := map[string][]float64{}
+
+	ema["EMA20"] = w.series("EMA20", s.values["EMA20"], EMAWarmup(20))
+	cur.EMA20 = latestNonNaN(ema["EMA20"])
+	if s.frame == FrameLongTerm {
+		ema["EMA50"] = w.series("EMA50", s.values["EMA50"], EMAWarmup(50))
+		cur.EMA50 = latestNonNaN(ema["EMA50"])
+	}
+	macd := w.series("MACD", s.values["MACD"], MACDWarmup())
+	cur.MACD = latestNonNaN(macd)
+	if s.frame == FrameIntraday {
+		rsi["RSI7"] = w.series("RSI7", s.values["RSI7"], RSIWarmup(7))
+		cur.RSI7 = latestNonNaN(rsi["RSI7"])
+	}
+	rsi["RSI14"] = w.series("RSI14", s.values["RSI14"], RSIWarmup(14))
+	cur.RSI14 = latestNonNaN(rsi["RSI14"])
+	if s.frame == FrameLongTerm {
+		atr["ATR3"] = w.series("ATR3", s.values["ATR3"], ATRWarmup(3))
+		atr["ATR14"] = w.series("ATR14", s.values["ATR14"], ATRWarmup(14))
+		cur.ATR3 = latestNonNaN(atr["ATR3"])
+		cur.ATR14 = latestNonNaN(atr["ATR14"])
+	}
+
+	series := &market.SeriesBundle{
+		Prices:              lastN(s.closes, s.length),
+		EMA:                 seriesMap(ema),
+		MACD:                macd,
+		RSI:                 seriesMap(rsi),
+		Volume:              lastN(s.volumes, s.length),
+		Candles:             append([]market.Candle(nil), s.candles...),
+		InsufficientHistory: w.missing,
+	}
+	if s.frame == FrameLongTerm {
+		series.ATR = seriesMap(atr)
+	}
+	return series, cur
+}
+
+// streamWarmup applies warmupTracker's rule to a stream that has seen
+// pushed candles and shows the newest shown of them.
+type streamWarmup struct {
+	pushed  int
+	shown   int
+	missing []string
+}
+
+func (w *streamWarmup) series(name string, values []float64, warmup int) []float64 {
+	if w.pushed-w.shown+1 < warmup {
+		w.missing = append(w.missing, name)
+		return nil
+	}
+	return append([]float64(nil), values...)
+}
+
+// emaState is EMA one price at a time: NaN until period consecutive valid
+// prices seed it with their average.
+type emaState struct {
+	period int
+	mult   float64
+	seeded bool
+	n      int
+	sum    float64
+	value  float64
+}
+
+func newEMAState(period int) emaState {
+	return emaState{period: period, mult: 2.0 / float64(period+1), value: math.NaN()}
+}
+
+func (e *emaState) push(price float64) float64 {
+	switch {
+	case e.seeded:
+		if !math.IsNaN(price) {
+			e.value = (price-e.value)*e.mult + e.value
+		}
+	case math.IsNaN(price):
+		e.n, e.sum = 0, 0
+	default:
+		e.n++
+		e.sum += price
+		if e.n == e.period {
+			e.seeded = true
+			e.value = e.sum / float64(e.period)
+		}
+	}
+	return e.value
+}
+
+// rsiState is RSI one price at a time with Wilder smoothing.
+type rsiState struct {
+	period     int
+	prev       float64
+	changes    int
+	avgGain    float64
+	avgLoss    float64
+	value      float64
+	hasPrev    bool
+	hasAverage bool
+}
+
+func (r *rsiState) push(price float64) {
+	r.value = math.NaN()
+	if !r.hasPrev {
+		r.prev, r.hasPrev = price, true
+		return
+	}
+	change := price - r.prev
+	r.prev = price
+	r.changes++
+	gain, loss := math.Max(change, 0), math.Max(-change, 0)
+	if !r.hasAverage {
+		if change > 0 {
+			r.avgGain += change
+		} else {
+			r.avgLoss -= change
+		}
+		if r.changes < r.period {
+			return
+		}
+		r.avgGain /= float64(r.period)
+		r.avgLoss /= float64(r.period)
+		r.hasAverage = true
+	} else {
+		r.avgGain = (r.avgGain*float64(r.period-1) + gain) / float64(r.period)
+		r.avgLoss = (r.avgLoss*float64(r.period-1) + loss) / float64(r.period)
+	}
+	r.value = computeRSI(r.avgGain, r.avgLoss)
+}
+
+// roll appends v to window, dropping the oldest value once it holds n.
+func roll[T any](window []T, v T, n int) []T {
+	if len(window) < n {
+		return append(window, v)
+	}
+	copy(window, window[1:])
+	window[len(window)-1] = v
+	return window
+}
+
+// Windows are a symbol's rolling intraday and long-term streams. They
+// implement market.SymbolWindows.
+type Windows struct {
+	intradayInterval string
+	longTermInterval string
+	intraday         *Stream
+	longTerm         *Stream
+}
+
+// NewWindows keeps intradayLen points of intradayInterval candles and
+// longTermLen points of longTermInterval candles; non-positive lengths use
+// market.DefaultIntradayWindow and market.DefaultLongTermWindow.
+func NewWindows(intradayInterval, longTermInterval string, intradayLen, longTermLen int) *Windows {
+	if intradayLen <= 0 {
+		intradayLen = market.DefaultIntradayWindow
+	}
+	if longTermLen <= 0 {
+		longTermLen = market.DefaultLongTermWindow
+	}
+	return &Windows{
+		intradayInterval: intradayInterval,
+		longTermInterval: longTermInterval,
+		intraday:         NewStream(FrameIntraday, intradayLen),
+		longTerm:         NewStream(FrameLongTerm, longTermLen),
+	}
+}
+
+// Intervals implements market.SymbolWindows.
+func (w *Windows) Intervals() map[string]int {
+	return map[string]int{
+		w.intradayInterval: w.intraday.Seed(),
+		w.longTermInterval: w.longTerm.Seed(),
+	}
+}
+
+// Push implements market.SymbolWindows.
+func (w *Windows) Push(interval string, c market.Candle) {
+	switch interval {
+	case w.intradayInterval:
+		w.intraday.Push(c)
+	case w.longTermInterval:
+		w.longTerm.Push(c)
+	}
+}
+
+// Apply implements market.SymbolWindows.
+func (w *Windows) Apply(snap *market.Snapshot) {
+	intraday, intradayCur := w.intraday.Series()
+	longTerm, longTermCur := w.longTerm.Series()
+	if snap == nil || intraday == nil || longTerm == nil {
+		return
+	}
+	snap.Intraday = intraday
+	snap.LongTerm = longTerm
+	snap.Indicators = Summary(intradayCur, longTermCur)
+}
@@ -0,0 +1,96 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/market"
+)
+
+// wavyCandles moves prices up and down so every indicator changes.
+func wavyCandles(n int) []market.Candle {
+	candles := make([]market.Candle, n)
+	for i := range candles {
+		px := 100 + 10*math.Sin(float64(i)/5) + float64(i%7)
+		candles[i] = market.Candle{OpenTime: int64(i), Open: px - 1, High: px + 2, Low: px - 3, Close: px, Volume: float64(i % 11)}
+	}
+	return candles
+}
+
+func TestStreamMatchesBuildSeries(t *testing.T) {
+	candles := wavyCandles(200)
+	for _, frame := range []Frame{FrameIntraday, FrameLongTerm} {
+		stream := NewStream(frame, 30)
+		for n, c := range candles {
+			stream.Push(c)
+			if n%17 != 0 && n != len(candles)-1 {
+				continue
+			}
+			want, wantCur := BuildSeries(candles[:n+1], frame, 30)
+			got, gotCur := stream.Series()
+			require.Equal(t, want, got, "frame=%s candles=%d", frame, n+1)
+			assert.Equal(t, fmt.Sprint(wantCur), fmt.Sprint(gotCur), "frame=%s candles=%d", frame, n+1)
+		}
+	}
+}
+
+func TestStreamRollsOneCandleAtATime(t *testing.T) {
+	stream := NewStream(FrameIntraday, 3)
+	series, _ := stream.Series()
+	assert.Nil(t, series)
+
+	for _, c := range risingCandles(5) {
+		stream.Push(c)
+	}
+	stream.Push(market.Candle{OpenTime: 2, Close: 1}) // not newer, ignored
+
+	series, _ = stream.Series()
+	assert.Equal(t, []float64{102, 103, 104}, series.Prices)
+	open, ok := stream.LastOpen()
+	assert.True(t, ok)
+	assert.Equal(t, int64(4), open)
+	assert.Contains(t, series.InsufficientHistory, "MACD")
+}
+
+func TestStreamSeedWarmsEveryPoint(t *testing.T) {
+	for _, frame := range []Frame{FrameIntraday, FrameLongTerm} {
+		stream := NewStream(frame, 50)
+		candles := risingCandles(stream.Seed())
+		for _, c := range candles[:len(candles)-1] {
+			stream.Push(c)
+		}
+		series, _ := stream.Series()
+		assert.NotEmpty(t, series.InsufficientHistory, "frame=%s one candle short", frame)
+
+		stream.Push(candles[len(candles)-1])
+		series, _ = stream.Series()
+		assert.Empty(t, series.InsufficientHistory, "frame=%s", frame)
+	}
+}
+
+func TestWindowsApply(t *testing.T) {
+	w := NewWindows("3m", "4h", 10, 10)
+	assert.Equal(t, map[string]int{"3m": 10 + MACDWarmup() - 1, "4h": 10 + EMAWarmup(50) - 1}, w.Intervals())
+
+	snap := &market.Snapshot{Intraday: &market.SeriesBundle{Prices: []float64{1}}}
+	for _, c := range risingCandles(120) {
+		w.Push("3m", c)
+	}
+	w.Apply(snap)
+	assert.Equal(t, []float64{1}, snap.Intraday.Prices, "untouched until every window has candles")
+
+	for _, c := range risingCandles(120) {
+		w.Push("4h", c)
+		w.Push("1m", market.Candle{OpenTime: c.OpenTime, Close: -1})
+	}
+	w.Apply(snap)
+	want, intradayCur := BuildSeries(risingCandles(120), FrameIntraday, 10)
+	assert.Equal(t, want, snap.Intraday)
+	wantLong, longCur := BuildSeries(risingCandles(120), FrameLongTerm, 10)
+	assert.Equal(t, wantLong, snap.LongTerm)
+	assert.Equal(t, Summary(intradayCur, longCur), snap.Indicators)
+}
@@ -0,0 +1,87 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KlineFeed streams live candles from an exchange websocket and fetches
+// candle history over REST to fill gaps.
+type KlineFeed interface {
+	// Subscribe adds a symbol and interval, e.g. "BTC" and "1m". All
+	// subscriptions are made before Run.
+	Subscribe(symbol, interval string) error
+	// Run streams updates of the subscribed candles to handle until ctx is
+	// cancelled, reconnecting as needed. handle is called from one goroutine.
+	Run(ctx context.Context, handle func(KlineUpdate)) error
+	// History returns candles opened in [from, to), oldest first.
+	History(ctx context.Context, symbol, interval string, from, to time.Time) ([]Candle, error)
+}
+
+// KlineUpdate is the latest state of one candle. The same candle is usually
+// delivered several times while it is open; Closed is set when the feed
+// knows the values are final.
+type KlineUpdate struct {
+	Symbol   string
+	Interval string
+	Candle   Candle
+	Closed   bool
+}
+
+// TradeFeed is a KlineFeed that can also stream the public trade tape.
+// Trades arrive between candle updates, so they keep the open candle current
+// and show that a bar has closed as soon as the next one trades.
+type TradeFeed interface {
+	KlineFeed
+	// SubscribeTrades adds a symbol's trades. Like Subscribe, all
+	// subscriptions are made before Run.
+	SubscribeTrades(symbol string) error
+	// OnTrade sets where Run delivers trades, from the goroutine that calls
+	// its candle handler. Set it before Run.
+	OnTrade(handle func(TradeUpdate))
+}
+
+// TradeUpdate is one trade on the public tape.
+type TradeUpdate struct {
+	Symbol string
+	Price  float64
+	Size   float64
+	// Time is the trade time in Unix milliseconds.
+	Time int64
+}
+
+// KlineFeedConfig configures a feed built from the registry.
+type KlineFeedConfig struct {
+	Testnet bool
+	// URL overrides the websocket endpoint.
+	URL string
+}
+
+// KlineFeedBuilder constructs a KlineFeed from configuration.
+type KlineFeedBuilder func(cfg KlineFeedConfig) (KlineFeed, error)
+
+var (
+	klineFeedRegistry   = make(map[string]KlineFeedBuilder)
+	klineFeedRegistryMu sync.RWMutex
+)
+
+// RegisterKlineFeed registers a kline feed constructor for an exchange type.
+func RegisterKlineFeed(typeName string, builder KlineFeedBuilder) {
+	klineFeedRegistryMu.Lock()
+	defer klineFeedRegistryMu.Unlock()
+	klineFeedRegistry[strings.ToLower(strings.TrimSpace(typeName))] = builder
+}
+
+// NewKlineFeed builds the kline feed registered for typeName.
+func NewKlineFeed(typeName string, cfg KlineFeedConfig) (KlineFeed, error) {
+	klineFeedRegistryMu.RLock()
+	builder, ok := klineFeedRegistry[strings.ToLower(strings.TrimSpace(typeName))]
+	klineFeedRegistryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("market: no kline feed registered for %q", typeName)
+	}
+	return builder(cfg)
+}
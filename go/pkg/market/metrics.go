@@ -0,0 +1,62 @@
+package market
+
+import (
+	"context"
+	"time"
+)
+
+// Metrics is a point-in-time reading of a perpetual's derivatives and volume
+// figures, as stored in market_metrics.
+type Metrics struct {
+	Symbol            string
+	MarkPrice         float64
+	MidPrice          float64
+	OraclePrice       float64
+	FundingRate       float64 // fractional funding rate (0.01 == 1%)
+	OpenInterest      float64 // open interest in base units
+	DayVolume         float64 // 24h base volume
+	DayNotionalVolume float64 // 24h notional volume
+	Premium           float64 // mark premium over the oracle, fractional
+	PrevDayPrice      float64
+	EventAt           time.Time
+}
+
+// MetricsProvider is implemented by providers that report Metrics. Symbols
+// the exchange does not list are left out of the result.
+type MetricsProvider interface {
+	Metrics(ctx context.Context, symbols ...string) ([]Metrics, error)
+}
+
+// MetricAverages are rolling means over recent Metrics samples of a symbol.
+type MetricAverages struct {
+	OpenInterest float64
+	DayVolume    float64
+	FundingRate  float64
+	Samples      int
+}
+
+// MetricsAverager supplies rolling metric averages; ok is false while no
+// samples are held for the symbol.
+type MetricsAverager interface {
+	MetricAverages(symbol string) (avg MetricAverages, ok bool)
+}
+
+// MetricsAverageAware indicates the provider fills Snapshot averages from a
+// MetricsAverager.
+type MetricsAverageAware interface {
+	SetMetricsAverager(a MetricsAverager)
+}
+
+// ApplyMetricAverages replaces the open interest and volume averages of snap
+// with avg. Snapshots without those sections are left alone.
+func ApplyMetricAverages(snap *Snapshot, avg MetricAverages) {
+	if snap == nil || avg.Samples == 0 {
+		return
+	}
+	if snap.OpenInterest != nil {
+		snap.OpenInterest.Average = avg.OpenInterest
+	}
+	if snap.Volume != nil {
+		snap.Volume.Average = avg.DayVolume
+	}
+}
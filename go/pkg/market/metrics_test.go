@@ -0,0 +1,22 @@
+package market
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyMetricAverages(t *testing.T) {
+	snap := &Snapshot{
+		OpenInterest: &OpenInterestInfo{Latest: 10, Average: 10},
+		Volume:       &VolumeInfo{Latest: 5, Average: 5},
+	}
+	ApplyMetricAverages(snap, MetricAverages{})
+	assert.Equal(t, 10.0, snap.OpenInterest.Average, "no samples keeps the latest reading")
+
+	ApplyMetricAverages(snap, MetricAverages{OpenInterest: 8, DayVolume: 4, Samples: 2})
+	assert.Equal(t, 8.0, snap.OpenInterest.Average)
+	assert.Equal(t, 4.0, snap.Volume.Average)
+
+	ApplyMetricAverages(&Snapshot{}, MetricAverages{OpenInterest: 8, Samples: 1})
+}
@@ -23,6 +23,7 @@ type Snapshot struct {
 	Indicators   IndicatorInfo     // Calculated technical indicators
 	OpenInterest *OpenInterestInfo // Derivatives interest data, if available
 	Funding      *FundingInfo      // Perpetual funding information, if available
+	Volume       *VolumeInfo       // 24h volume data, if available
 	Intraday     *SeriesBundle     // Short-term time series context
 	LongTerm     *SeriesBundle     // Longer-term time series context
 }
@@ -61,6 +62,12 @@ type OpenInterestInfo struct {
 	Average float64
 }
 
+// VolumeInfo reports 24h base volume.
+type VolumeInfo struct {
+	Latest  float64
+	Average float64
+}
+
 // FundingInfo captures perpetual funding rate data.
 type FundingInfo struct {
 	Rate float64 // fractional funding rate (0.01 == 1%)
@@ -68,10 +75,49 @@ type FundingInfo struct {
 
 // SeriesBundle provides supporting time series data for analysis layers.
 type SeriesBundle struct {
-	Prices []float64            // Ordered oldest → newest close prices
-	EMA    map[string][]float64 // EMA series keyed by window
-	MACD   []float64            // MACD values
-	RSI    map[string][]float64 // RSI series keyed by window
-	ATR    map[string][]float64 // ATR series keyed by window
-	Volume []float64            // Volume series when available
+	Prices  []float64            // Ordered oldest → newest close prices
+	EMA     map[string][]float64 // EMA series keyed by window
+	MACD    []float64            // MACD values
+	RSI     map[string][]float64 // RSI series keyed by window
+	ATR     map[string][]float64 // ATR series keyed by window
+	Volume  []float64            // Volume series when available
+	Candles []Candle             // OHLC bars aligned with Prices when the provider exposes them
+	// InsufficientHistory names indicators left out of the bundle because
+	// too few bars exist to warm them up, e.g. right after a listing.
+	InsufficientHistory []string
+	// Corrections counts impossible prints repaired or flagged by
+	// SanitizeCandles before indicators were computed.
+	Corrections int
+}
+
+// WarmingUp lists indicators still warming up as "frame:NAME" (frames are
+// "intraday" and "long_term"). Decisions should not open positions on a
+// symbol until it is empty.
+func (s *Snapshot) WarmingUp() []string {
+	if s == nil {
+		return nil
+	}
+	var out []string
+	for _, frame := range []struct {
+		label  string
+		bundle *SeriesBundle
+	}{{"intraday", s.Intraday}, {"long_term", s.LongTerm}} {
+		if frame.bundle == nil {
+			continue
+		}
+		for _, name := range frame.bundle.InsufficientHistory {
+			out = append(out, frame.label+":"+name)
+		}
+	}
+	return out
+}
+
+// Candle is a single OHLC bar.
+type Candle struct {
+	OpenTime int64 // Open time in milliseconds
+	Open     float64
+	High     float64
+	Low      float64
+	Close    float64
+	Volume   float64
 }
@@ -0,0 +1,99 @@
+package market
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// outlierRangeMultiple flags a candle whose high-low range exceeds this
+	// multiple of the bundle's median range.
+	outlierRangeMultiple = 8
+
+	gapPenalty        = 0.05
+	maxGapPenalty     = 0.3
+	outlierPenalty    = 0.1
+	maxOutlierPenalty = 0.3
+	stalePenalty      = 0.1 // per interval of lag
+	maxStalePenalty   = 0.4
+)
+
+// DataQuality scores how far one snapshot's inputs can be trusted.
+type DataQuality struct {
+	Score    float64 `json:"score"`    // 1 = clean, 0 = unusable
+	Gaps     int     `json:"gaps"`     // bars missing between consecutive candles
+	StaleMs  int64   `json:"stale_ms"` // lag of the newest candle beyond the expected one
+	Outliers int     `json:"outliers"` // implausible candles, including sanitized ones
+}
+
+// AssessQuality scores the candles behind s at now. Gaps and outliers lower
+// the score per bar; staleness per interval of lag. Bundles without candles
+// cannot be assessed and count as clean.
+func AssessQuality(s *Snapshot, now time.Time) DataQuality {
+	q := DataQuality{Score: 1}
+	if s == nil {
+		return q
+	}
+	var staleLoss float64
+	for _, b := range []*SeriesBundle{s.Intraday, s.LongTerm} {
+		if b == nil || len(b.Candles) < 2 {
+			continue
+		}
+		interval := candleInterval(b.Candles)
+		if interval <= 0 {
+			continue
+		}
+		for i := 1; i < len(b.Candles); i++ {
+			if step := b.Candles[i].OpenTime - b.Candles[i-1].OpenTime; step > interval {
+				q.Gaps += int((step+interval/2)/interval) - 1
+			}
+		}
+		// The newest candle may still be forming, so one interval of lag
+		// beyond it is expected.
+		newest := time.UnixMilli(b.Candles[len(b.Candles)-1].OpenTime)
+		if lag := now.Sub(newest).Milliseconds() - 2*interval; lag > 0 {
+			q.StaleMs = max(q.StaleMs, lag)
+			staleLoss = math.Max(staleLoss, stalePenalty*float64(lag)/float64(interval))
+		}
+		q.Outliers += countOutliers(b.Candles) + b.Corrections
+	}
+	q.Score -= math.Min(gapPenalty*float64(q.Gaps), maxGapPenalty)
+	q.Score -= math.Min(staleLoss, maxStalePenalty)
+	q.Score -= math.Min(outlierPenalty*float64(q.Outliers), maxOutlierPenalty)
+	q.Score = math.Round(math.Max(q.Score, 0)*100) / 100
+	return q
+}
+
+// candleInterval is the shortest step between consecutive open times, in
+// milliseconds, so gaps do not inflate it.
+func candleInterval(candles []Candle) int64 {
+	var interval int64
+	for i := 1; i < len(candles); i++ {
+		if step := candles[i].OpenTime - candles[i-1].OpenTime; step > 0 && (interval == 0 || step < interval) {
+			interval = step
+		}
+	}
+	return interval
+}
+
+// countOutliers counts candles whose close lies outside their own range or
+// whose range dwarfs the median range.
+func countOutliers(candles []Candle) int {
+	ranges := make([]float64, 0, len(candles))
+	for _, c := range candles {
+		ranges = append(ranges, c.High-c.Low)
+	}
+	sort.Float64s(ranges)
+	median := ranges[len(ranges)/2]
+	n := 0
+	for _, c := range candles {
+		switch {
+		case c.Close > c.High || c.Close < c.Low:
+			n++
+		case median > 0 && c.High-c.Low > outlierRangeMultiple*median:
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,45 @@
+package market
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func minuteCandles(start time.Time, n int) []Candle {
+	out := make([]Candle, n)
+	for i := range out {
+		out[i] = Candle{OpenTime: start.Add(time.Duration(i) * time.Minute).UnixMilli(), Open: 100, High: 101, Low: 99, Close: 100}
+	}
+	return out
+}
+
+func TestAssessQuality(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	start := now.Add(-10 * time.Minute)
+
+	clean := &Snapshot{Intraday: &SeriesBundle{Candles: minuteCandles(start, 10)}}
+	assert.Equal(t, DataQuality{Score: 1}, AssessQuality(clean, now))
+	assert.Equal(t, DataQuality{Score: 1}, AssessQuality(&Snapshot{}, now), "no candles cannot be assessed")
+
+	gappy := minuteCandles(start, 10)
+	gappy = append(gappy[:3], gappy[6:]...)
+	q := AssessQuality(&Snapshot{Intraday: &SeriesBundle{Candles: gappy}}, now)
+	assert.Equal(t, 3, q.Gaps)
+	assert.InDelta(t, 0.85, q.Score, 1e-9)
+
+	q = AssessQuality(clean, now.Add(5*time.Minute))
+	assert.Equal(t, int64(4*time.Minute/time.Millisecond), q.StaleMs, "newest candle opened 6m ago; 2m of lag is expected")
+	assert.InDelta(t, 0.6, q.Score, 1e-9)
+
+	spiky := minuteCandles(start, 10)
+	spiky[4].High, spiky[4].Low = 150, 99
+	spiky[7].Close = 120
+	q = AssessQuality(&Snapshot{LongTerm: &SeriesBundle{Candles: spiky}}, now)
+	assert.Equal(t, 2, q.Outliers)
+	assert.InDelta(t, 0.8, q.Score, 1e-9)
+
+	q = AssessQuality(&Snapshot{Intraday: &SeriesBundle{Candles: minuteCandles(start, 10), Corrections: 1}}, now)
+	assert.Equal(t, 1, q.Outliers, "sanitized prints count as outliers")
+}
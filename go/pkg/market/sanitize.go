@@ -0,0 +1,95 @@
+package market
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// badTickRangeMultiple marks a wick or close jump as impossible when it
+	// exceeds this multiple of the recent median bar range.
+	badTickRangeMultiple = 8
+	// badTickLookback is the number of preceding bars the median range uses.
+	badTickLookback = 20
+	// badTickMinHistory is the fewest preceding bars needed to judge a bar.
+	badTickMinHistory = 5
+)
+
+// CandleCorrection records one impossible print found by SanitizeCandles.
+// Corrected is false when the print was only flagged.
+type CandleCorrection struct {
+	OpenTime  int64
+	Field     string // "high", "low" or "close"
+	From      float64
+	To        float64
+	Corrected bool
+	Reason    string
+}
+
+func (c CandleCorrection) String() string {
+	action := "flagged"
+	if c.Corrected {
+		action = fmt.Sprintf("corrected to %g", c.To)
+	}
+	return fmt.Sprintf("candle %d %s %g %s: %s", c.OpenTime, c.Field, c.From, action, c.Reason)
+}
+
+// SanitizeCandles repairs impossible prints in place, judging each bar
+// against the median range of the bars before it:
+//   - a wick beyond the body by more than badTickRangeMultiple median ranges
+//     is clipped to the body;
+//   - a close that jumps that far from the previous close and is reverted by
+//     the next open is replaced by that open; without a next bar to confirm
+//     it, the close is only flagged.
+//
+// Candles must be ordered oldest first. Bars with too little history before
+// them are left alone.
+func SanitizeCandles(candles []Candle) []CandleCorrection {
+	var out []CandleCorrection
+	for i := badTickMinHistory; i < len(candles); i++ {
+		ref := medianRange(candles[max(0, i-badTickLookback):i])
+		if !(ref > 0) {
+			continue
+		}
+		limit := badTickRangeMultiple * ref
+		c := &candles[i]
+		prev := candles[i-1].Close
+
+		if jump := math.Abs(c.Close - prev); jump > limit {
+			reason := fmt.Sprintf("close moved %g from previous close, limit %g", jump, limit)
+			fix := CandleCorrection{OpenTime: c.OpenTime, Field: "close", From: c.Close, Reason: reason}
+			if i+1 < len(candles) && math.Abs(candles[i+1].Open-prev) <= limit {
+				fix.To, fix.Corrected = candles[i+1].Open, true
+				c.Close = fix.To
+			}
+			out = append(out, fix)
+		}
+		top, bottom := math.Max(c.Open, c.Close), math.Min(c.Open, c.Close)
+		if wick := c.High - top; wick > limit {
+			out = append(out, CandleCorrection{OpenTime: c.OpenTime, Field: "high", From: c.High, To: top, Corrected: true,
+				Reason: fmt.Sprintf("upper wick %g, limit %g", wick, limit)})
+			c.High = top
+		}
+		if wick := bottom - c.Low; wick > limit {
+			out = append(out, CandleCorrection{OpenTime: c.OpenTime, Field: "low", From: c.Low, To: bottom, Corrected: true,
+				Reason: fmt.Sprintf("lower wick %g, limit %g", wick, limit)})
+			c.Low = bottom
+		}
+	}
+	return out
+}
+
+func medianRange(candles []Candle) float64 {
+	ranges := make([]float64, 0, len(candles))
+	for _, c := range candles {
+		if r := c.High - c.Low; r >= 0 && !math.IsNaN(r) {
+			ranges = append(ranges, r)
+		}
+	}
+	if len(ranges) == 0 {
+		return 0
+	}
+	sort.Float64s(ranges)
+	return ranges[len(ranges)/2]
+}
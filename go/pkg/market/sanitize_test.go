@@ -0,0 +1,52 @@
+package market
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func flatCandles(n int) []Candle {
+	out := make([]Candle, n)
+	for i := range out {
+		out[i] = Candle{OpenTime: int64(i), Open: 100, High: 101, Low: 99, Close: 100}
+	}
+	return out
+}
+
+func TestSanitizeCandles(t *testing.T) {
+	candles := flatCandles(12)
+	candles[6].High = 150 // 50% wick from a malformed print
+	candles[8].Close = 160
+	candles[8].High = 160 // bad close, reverted by the next open
+	candles[11].Low = 40  // newest bar: wick still clipped
+
+	fixes := SanitizeCandles(candles)
+	require.Len(t, fixes, 4)
+
+	assert.Equal(t, CandleCorrection{OpenTime: 6, Field: "high", From: 150, To: 100, Corrected: true, Reason: "upper wick 50, limit 16"}, fixes[0])
+	assert.Equal(t, 100.0, candles[6].High)
+
+	assert.Equal(t, "close", fixes[1].Field)
+	assert.True(t, fixes[1].Corrected)
+	assert.Equal(t, 100.0, candles[8].Close)
+	assert.Equal(t, "high", fixes[2].Field)
+	assert.Equal(t, 100.0, candles[8].High)
+
+	assert.Equal(t, "low", fixes[3].Field)
+	assert.Equal(t, 100.0, candles[11].Low)
+	assert.Equal(t, "candle 11 low 40 corrected to 100: lower wick 60, limit 16", fixes[3].String())
+}
+
+func TestSanitizeCandlesFlagsUnconfirmedClose(t *testing.T) {
+	candles := flatCandles(8)
+	candles[7] = Candle{OpenTime: 7, Open: 130, High: 131, Low: 129, Close: 130}
+	fixes := SanitizeCandles(candles)
+	require.Len(t, fixes, 1)
+	assert.False(t, fixes[0].Corrected)
+	assert.Equal(t, 130.0, candles[7].Close, "no next bar to confirm the print")
+	assert.Equal(t, "candle 7 close 130 flagged: close moved 30 from previous close, limit 16", fixes[0].String())
+
+	assert.Empty(t, SanitizeCandles(flatCandles(3)), "too little history to judge")
+}
@@ -0,0 +1,84 @@
+package market
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SummaryCacheStats counts lookups served from and missing a SummaryCache.
+type SummaryCacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// SummaryCache memoises slow-moving summaries, such as long-interval series
+// or an LLM digest of them, for as long as the bar they were derived from is
+// the newest closed one. High-frequency decision loops then reuse the summary
+// across cycles and only pay for recomputation once per bar of interval.
+//
+// Concurrent misses on the same key may each compute; the last one stored
+// wins. Errors are never cached.
+type SummaryCache[T any] struct {
+	mu      sync.Mutex
+	entries map[string]summaryEntry[T]
+	stats   SummaryCacheStats
+}
+
+type summaryEntry[T any] struct {
+	value   T
+	barOpen time.Time
+}
+
+// NewSummaryCache returns an empty cache.
+func NewSummaryCache[T any]() *SummaryCache[T] {
+	return &SummaryCache[T]{entries: make(map[string]summaryEntry[T])}
+}
+
+// Get returns the summary cached under key while its bar is still the last
+// closed bar of interval at asOf, and otherwise calls compute. compute
+// returns the summary with the open time of the newest bar it covers; the
+// result is stored only when that is the expected bar, so a summary built
+// from data that lags the exchange's bar close is recomputed next cycle
+// rather than served for a whole bar. hit reports whether compute was skipped.
+func (c *SummaryCache[T]) Get(ctx context.Context, key string, interval time.Duration, asOf time.Time, compute func(ctx context.Context) (T, time.Time, error)) (value T, hit bool, err error) {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	want := LastClosedOpen(interval, asOf)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && entry.barOpen.Equal(want) {
+		c.stats.Hits++
+		c.mu.Unlock()
+		return entry.value, true, nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	value, barOpen, err := compute(ctx)
+	if err != nil {
+		var zero T
+		return zero, false, err
+	}
+	if barOpen.Equal(want) {
+		c.mu.Lock()
+		c.entries[key] = summaryEntry[T]{value: value, barOpen: barOpen}
+		c.mu.Unlock()
+	}
+	return value, false, nil
+}
+
+// Invalidate drops the summary cached under key.
+func (c *SummaryCache[T]) Invalidate(key string) {
+	c.mu.Lock()
+	delete(c.entries, strings.ToUpper(strings.TrimSpace(key)))
+	c.mu.Unlock()
+}
+
+// Stats returns the hit and miss counters since the cache was built.
+func (c *SummaryCache[T]) Stats() SummaryCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
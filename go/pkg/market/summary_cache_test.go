@@ -0,0 +1,74 @@
+package market_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	market "nof0-api/pkg/market"
+)
+
+func TestSummaryCacheReusesWithinBar(t *testing.T) {
+	cache := market.NewSummaryCache[string]()
+	interval := 4 * time.Hour
+	calls := 0
+	compute := func(asOf time.Time) func(context.Context) (string, time.Time, error) {
+		return func(context.Context) (string, time.Time, error) {
+			calls++
+			return "summary", market.LastClosedOpen(interval, asOf), nil
+		}
+	}
+	ctx := context.Background()
+	start := time.Date(2025, 1, 1, 4, 5, 0, 0, time.UTC)
+
+	v, hit, err := cache.Get(ctx, "btc", interval, start, compute(start))
+	require.NoError(t, err)
+	assert.False(t, hit)
+	assert.Equal(t, "summary", v)
+
+	later := start.Add(3 * time.Hour)
+	_, hit, err = cache.Get(ctx, "BTC", interval, later, compute(later))
+	require.NoError(t, err)
+	assert.True(t, hit, "same closed 4h bar")
+	assert.Equal(t, 1, calls)
+
+	next := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	_, hit, err = cache.Get(ctx, "BTC", interval, next, compute(next))
+	require.NoError(t, err)
+	assert.False(t, hit, "a new bar closed")
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, market.SummaryCacheStats{Hits: 1, Misses: 2}, cache.Stats())
+
+	cache.Invalidate("btc")
+	_, hit, _ = cache.Get(ctx, "BTC", interval, next, compute(next))
+	assert.False(t, hit)
+}
+
+func TestSummaryCacheSkipsLaggingAndFailedComputes(t *testing.T) {
+	cache := market.NewSummaryCache[int]()
+	interval := time.Hour
+	asOf := time.Date(2025, 1, 1, 10, 1, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	// The exchange has not published the 09:00 bar yet.
+	lagging := func(context.Context) (int, time.Time, error) {
+		return 1, asOf.Truncate(interval).Add(-2 * interval), nil
+	}
+	_, _, err := cache.Get(ctx, "ETH", interval, asOf, lagging)
+	require.NoError(t, err)
+	_, hit, _ := cache.Get(ctx, "ETH", interval, asOf, lagging)
+	assert.False(t, hit)
+
+	failing := func(context.Context) (int, time.Time, error) {
+		return 0, time.Time{}, errors.New("boom")
+	}
+	_, _, err = cache.Get(ctx, "SOL", interval, asOf, failing)
+	require.Error(t, err)
+	_, hit, err = cache.Get(ctx, "SOL", interval, asOf, failing)
+	require.Error(t, err)
+	assert.False(t, hit)
+}
@@ -0,0 +1,42 @@
+package market
+
+import "math"
+
+// LatestATR returns the most recent finite value of the named ATR series,
+// preferring the long-term bundle. It returns 0 when no reading exists.
+func (s *Snapshot) LatestATR(window string) float64 {
+	if s == nil {
+		return 0
+	}
+	for _, b := range []*SeriesBundle{s.LongTerm, s.Intraday} {
+		if b == nil {
+			continue
+		}
+		series := b.ATR[window]
+		for i := len(series) - 1; i >= 0; i-- {
+			if v := series[i]; !math.IsNaN(v) && !math.IsInf(v, 0) && v > 0 {
+				return v
+			}
+		}
+	}
+	return 0
+}
+
+// ATRDailyVolatility estimates daily volatility as a fraction of price from
+// an ATR over bars of which barsPerDay fit in a day, scaling the per-bar
+// range by sqrt(barsPerDay).
+func ATRDailyVolatility(atr, price, barsPerDay float64) float64 {
+	if atr <= 0 || price <= 0 || barsPerDay <= 0 {
+		return 0
+	}
+	return atr / price * math.Sqrt(barsPerDay)
+}
+
+// VolTargetNotional sizes a position so its expected daily move equals
+// targetDailyVolPct of equity. It returns 0 when volatility is unknown.
+func VolTargetNotional(equity, targetDailyVolPct, dailyVol float64) float64 {
+	if equity <= 0 || targetDailyVolPct <= 0 || dailyVol <= 0 {
+		return 0
+	}
+	return equity * (targetDailyVolPct / 100) / dailyVol
+}
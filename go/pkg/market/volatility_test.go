@@ -0,0 +1,31 @@
+package market
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotLatestATR(t *testing.T) {
+	snap := &Snapshot{
+		Intraday: &SeriesBundle{ATR: map[string][]float64{"ATR14": {1, 2}}},
+		LongTerm: &SeriesBundle{ATR: map[string][]float64{"ATR14": {3, 4, math.NaN()}}},
+	}
+	assert.Equal(t, 4.0, snap.LatestATR("ATR14"))
+	assert.Equal(t, 0.0, snap.LatestATR("ATR3"))
+
+	snap.LongTerm = nil
+	assert.Equal(t, 2.0, snap.LatestATR("ATR14"))
+	assert.Equal(t, 0.0, (*Snapshot)(nil).LatestATR("ATR14"))
+}
+
+func TestVolTargetNotional(t *testing.T) {
+	// 4h ATR of 1% -> ~2.45% daily; targeting 1% of equity per day.
+	vol := ATRDailyVolatility(1, 100, 6)
+	assert.InDelta(t, 0.024495, vol, 1e-6)
+	assert.InDelta(t, 10000*0.01/vol, VolTargetNotional(10000, 1, vol), 1e-9)
+
+	assert.Zero(t, ATRDailyVolatility(0, 100, 6))
+	assert.Zero(t, VolTargetNotional(10000, 1, 0))
+}
@@ -0,0 +1,260 @@
+package market
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultIntradayWindow is the number of short-interval points retained per symbol.
+	DefaultIntradayWindow = 50
+	// DefaultLongTermWindow is the number of long-interval points retained per symbol.
+	DefaultLongTermWindow = 30
+	// DefaultWindowMaxAge bounds how stale a cached snapshot may be before callers refetch.
+	DefaultWindowMaxAge = 2 * time.Minute
+)
+
+// Refresher is implemented by providers that can bypass their read cache. The
+// market ingestor prefers Refresh over Snapshot so that it keeps caches warm
+// instead of reading from them.
+type Refresher interface {
+	Refresh(ctx context.Context, symbol string) (*Snapshot, error)
+}
+
+// WindowSink mirrors cached windows to an external store (e.g. Redis) so that
+// other processes can assemble prompts without recomputing series.
+type WindowSink interface {
+	StoreWindow(ctx context.Context, provider string, snapshot *Snapshot) error
+}
+
+// SymbolWindows are the rolling candle windows of one symbol, one per
+// timeframe shown in the prompt. indicators.NewWindows builds them.
+type SymbolWindows interface {
+	// Intervals maps each windowed candle interval to the number of closed
+	// candles needed before every point in its window is warmed up.
+	Intervals() map[string]int
+	// Push appends a closed candle to the window of interval, dropping the
+	// oldest point once the window is full. Candles of other intervals, and
+	// candles not newer than the window's last, are ignored.
+	Push(interval string, c Candle)
+	// Apply replaces snap's series and indicator summary with the windows'
+	// once every window holds candles, and leaves snap alone before that.
+	Apply(snap *Snapshot)
+}
+
+// WindowCache serves prompt inputs from rolling per-symbol windows. The
+// kline ingestor appends each closed candle with AppendKline, so a window
+// moves one candle at a time instead of being rebuilt from a full fetch.
+// Point-in-time fields (price, funding, open interest) come from the latest
+// provider snapshot, kept for maxAge and refreshed by the market ingestor;
+// until the windows are fed the snapshot's own series are served.
+type WindowCache struct {
+	provider   string
+	maxAge     time.Duration
+	newWindows func() SymbolWindows
+	sink       WindowSink
+
+	mu      sync.RWMutex
+	entries map[string]windowEntry
+	windows map[string]SymbolWindows
+	nowFn   func() time.Time
+}
+
+type windowEntry struct {
+	snapshot  *Snapshot
+	updatedAt time.Time
+}
+
+// NewWindowCache builds a cache for the named provider. newWindows creates
+// the rolling windows of a symbol; when nil only provider snapshots are
+// served. A non-positive maxAge falls back to DefaultWindowMaxAge.
+func NewWindowCache(provider string, maxAge time.Duration, newWindows func() SymbolWindows) *WindowCache {
+	if maxAge <= 0 {
+		maxAge = DefaultWindowMaxAge
+	}
+	return &WindowCache{
+		provider:   provider,
+		maxAge:     maxAge,
+		newWindows: newWindows,
+		entries:    make(map[string]windowEntry),
+		windows:    make(map[string]SymbolWindows),
+		nowFn:      time.Now,
+	}
+}
+
+// SetSink wires an optional external mirror for cached windows.
+func (c *WindowCache) SetSink(sink WindowSink) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sink = sink
+}
+
+// WindowIntervals reports the candle intervals the windows take and how
+// many closed candles each needs to warm up; nil without windows.
+func (c *WindowCache) WindowIntervals() map[string]int {
+	if c.newWindows == nil {
+		return nil
+	}
+	return c.newWindows().Intervals()
+}
+
+// AppendKline rolls a closed candle into symbol's window for interval.
+func (c *WindowCache) AppendKline(symbol, interval string, candle Candle) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if c.newWindows == nil || symbol == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w, ok := c.windows[symbol]
+	if !ok {
+		w = c.newWindows()
+		c.windows[symbol] = w
+	}
+	w.Push(interval, candle)
+}
+
+// Update stores a copy of snapshot and mirrors it, with the windows
+// applied, to the sink when set.
+func (c *WindowCache) Update(ctx context.Context, snapshot *Snapshot) error {
+	if snapshot == nil || strings.TrimSpace(snapshot.Symbol) == "" {
+		return nil
+	}
+	symbol := strings.ToUpper(snapshot.Symbol)
+	c.mu.Lock()
+	c.entries[symbol] = windowEntry{snapshot: cloneSnapshot(snapshot), updatedAt: c.nowFn()}
+	sink := c.sink
+	c.mu.Unlock()
+	if sink == nil {
+		return nil
+	}
+	mirrored, _ := c.Get(symbol)
+	return sink.StoreWindow(ctx, c.provider, mirrored)
+}
+
+// Get returns the cached snapshot for symbol, with its series read from the
+// symbol's windows, when the snapshot is fresh enough.
+func (c *WindowCache) Get(symbol string) (*Snapshot, bool) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[symbol]
+	if !ok || entry.snapshot == nil || c.nowFn().Sub(entry.updatedAt) > c.maxAge {
+		return nil, false
+	}
+	out := cloneSnapshot(entry.snapshot)
+	if w, ok := c.windows[symbol]; ok {
+		w.Apply(out)
+	}
+	return out, true
+}
+
+// Wrap returns a Provider that serves snapshots from the cache and falls back
+// to base on a miss. The returned provider implements Refresher.
+func (c *WindowCache) Wrap(base Provider) Provider {
+	return &windowProvider{Provider: base, cache: c}
+}
+
+type windowProvider struct {
+	Provider
+	cache *WindowCache
+}
+
+func (w *windowProvider) Snapshot(ctx context.Context, symbol string) (*Snapshot, error) {
+	if snap, ok := w.cache.Get(symbol); ok {
+		return snap, nil
+	}
+	return w.Refresh(ctx, symbol)
+}
+
+func (w *windowProvider) Refresh(ctx context.Context, symbol string) (*Snapshot, error) {
+	snap, err := w.Provider.Snapshot(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	// A failed mirror write must not fail the read path; the in-memory copy is authoritative.
+	_ = w.cache.Update(ctx, snap)
+	if cached, ok := w.cache.Get(symbol); ok {
+		return cached, nil
+	}
+	return snap, nil
+}
+
+func (w *windowProvider) SetPersistence(persist Persistence) {
+	if aware, ok := w.Provider.(PersistenceAware); ok {
+		aware.SetPersistence(persist)
+	}
+}
+
+func cloneSnapshot(src *Snapshot) *Snapshot {
+	out := *src
+	out.Indicators = IndicatorInfo{
+		EMA:  cloneFloatMap(src.Indicators.EMA),
+		MACD: src.Indicators.MACD,
+		RSI:  cloneFloatMap(src.Indicators.RSI),
+	}
+	if src.OpenInterest != nil {
+		oi := *src.OpenInterest
+		out.OpenInterest = &oi
+	}
+	if src.Funding != nil {
+		funding := *src.Funding
+		out.Funding = &funding
+	}
+	if src.Volume != nil {
+		volume := *src.Volume
+		out.Volume = &volume
+	}
+	out.Intraday = cloneBundle(src.Intraday)
+	out.LongTerm = cloneBundle(src.LongTerm)
+	return &out
+}
+
+func cloneBundle(src *SeriesBundle) *SeriesBundle {
+	if src == nil {
+		return nil
+	}
+	return &SeriesBundle{
+		Prices:  cloneFloats(src.Prices),
+		EMA:     cloneSeriesMap(src.EMA),
+		MACD:    cloneFloats(src.MACD),
+		RSI:     cloneSeriesMap(src.RSI),
+		ATR:     cloneSeriesMap(src.ATR),
+		Volume:  cloneFloats(src.Volume),
+		Candles: append([]Candle(nil), src.Candles...),
+
+		InsufficientHistory: append([]string(nil), src.InsufficientHistory...),
+		Corrections:         src.Corrections,
+	}
+}
+
+func cloneSeriesMap(src map[string][]float64) map[string][]float64 {
+	if src == nil {
+		return nil
+	}
+	out := make(map[string][]float64, len(src))
+	for k, v := range src {
+		out[k] = cloneFloats(v)
+	}
+	return out
+}
+
+func cloneFloats(src []float64) []float64 {
+	if src == nil {
+		return nil
+	}
+	return append([]float64{}, src...)
+}
+
+func cloneFloatMap(src map[string]float64) map[string]float64 {
+	if src == nil {
+		return nil
+	}
+	out := make(map[string]float64, len(src))
+	for k, v := range src {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+package market_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	market "nof0-api/pkg/market"
+)
+
+type countingProvider struct {
+	calls int
+	snap  *market.Snapshot
+}
+
+func (p *countingProvider) Snapshot(ctx context.Context, symbol string) (*market.Snapshot, error) {
+	p.calls++
+	return p.snap, nil
+}
+
+func (p *countingProvider) ListAssets(ctx context.Context) ([]market.Asset, error) {
+	return nil, nil
+}
+
+func series(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = float64(i)
+	}
+	return out
+}
+
+// fakeWindows counts pushed candles and stamps them into applied snapshots.
+type fakeWindows struct {
+	pushed []market.Candle
+}
+
+func (w *fakeWindows) Intervals() map[string]int { return map[string]int{"3m": 5} }
+
+func (w *fakeWindows) Push(interval string, c market.Candle) {
+	if interval == "3m" {
+		w.pushed = append(w.pushed, c)
+	}
+}
+
+func (w *fakeWindows) Apply(snap *market.Snapshot) {
+	if len(w.pushed) == 0 {
+		return
+	}
+	snap.Intraday = &market.SeriesBundle{Candles: append([]market.Candle(nil), w.pushed...)}
+}
+
+func TestWindowCacheServesFromCache(t *testing.T) {
+	base := &countingProvider{snap: &market.Snapshot{
+		Symbol:   "BTC",
+		Intraday: &market.SeriesBundle{Prices: series(10), EMA: map[string][]float64{"EMA20": series(10)}},
+		LongTerm: &market.SeriesBundle{Prices: series(10)},
+	}}
+	cache := market.NewWindowCache("hyperliquid", 0, nil)
+	provider := cache.Wrap(base)
+
+	snap, err := provider.Snapshot(context.Background(), "btc")
+	require.NoError(t, err)
+	assert.Equal(t, series(10), snap.Intraday.Prices, "without windows the provider's series are served")
+	assert.Nil(t, cache.WindowIntervals())
+
+	// Mutating the returned copy must not leak into the cache.
+	snap.Intraday.Prices[0] = -1
+	snap.Intraday.EMA["EMA20"][0] = -1
+
+	again, err := provider.Snapshot(context.Background(), "BTC")
+	require.NoError(t, err)
+	assert.Equal(t, 1, base.calls)
+	assert.Equal(t, 0.0, again.Intraday.Prices[0])
+	assert.Equal(t, 0.0, again.Intraday.EMA["EMA20"][0])
+
+	refresher, ok := provider.(market.Refresher)
+	require.True(t, ok)
+	_, err = refresher.Refresh(context.Background(), "BTC")
+	require.NoError(t, err)
+	assert.Equal(t, 2, base.calls)
+}
+
+func TestWindowCacheReadsSeriesFromWindows(t *testing.T) {
+	base := &countingProvider{snap: &market.Snapshot{
+		Symbol:   "BTC",
+		Price:    market.PriceInfo{Last: 101},
+		Intraday: &market.SeriesBundle{Prices: series(10)},
+	}}
+	cache := market.NewWindowCache("hyperliquid", 0, func() market.SymbolWindows { return &fakeWindows{} })
+	provider := cache.Wrap(base)
+	assert.Equal(t, map[string]int{"3m": 5}, cache.WindowIntervals())
+
+	cache.AppendKline("btc", "3m", market.Candle{OpenTime: 1, Close: 100})
+	cache.AppendKline("BTC", "3m", market.Candle{OpenTime: 2, Close: 101})
+	cache.AppendKline("BTC", "4h", market.Candle{OpenTime: 3, Close: 99})
+
+	snap, err := provider.Snapshot(context.Background(), "BTC")
+	require.NoError(t, err)
+	assert.Equal(t, 101.0, snap.Price.Last, "point-in-time fields come from the provider")
+	require.Len(t, snap.Intraday.Candles, 2, "series come from the symbol's window")
+	assert.Equal(t, int64(2), snap.Intraday.Candles[1].OpenTime)
+
+	cache.AppendKline("BTC", "3m", market.Candle{OpenTime: 3, Close: 102})
+	again, err := provider.Snapshot(context.Background(), "BTC")
+	require.NoError(t, err)
+	assert.Equal(t, 1, base.calls, "a new candle does not refetch")
+	assert.Len(t, again.Intraday.Candles, 3)
+}
@@ -0,0 +1,250 @@
+// Package prompt keeps named versions of the executor prompt template and
+// splits decision traffic between them for A/B experiments and staged
+// rollouts.
+//
+// A version pins a template path to the digest of its content; an
+// experiment assigns weighted arms of versions to matching traders and
+// models. Assignment is a deterministic hash of the experiment and the
+// decision cycle, so replays see the same arm and the share of decisions
+// per arm converges on its weight. A rollout serves a candidate version to
+// a share of cycles and rolls a trader back to the baseline when the
+// candidate's rejection rate or the trader's drawdown breaches its policy.
+package prompt
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"sync"
+
+	"nof0-api/pkg/llm"
+)
+
+// Assignment units.
+const (
+	// UnitCycle draws an arm for every decision cycle.
+	UnitCycle = "cycle"
+	// UnitTrader keeps each trader on one arm for the whole experiment.
+	UnitTrader = "trader"
+)
+
+// Version is a named prompt template at a fixed content digest.
+type Version struct {
+	Name     string `yaml:"name" json:"name"`
+	Template string `yaml:"template" json:"template"`
+	// Digest pins the template content; empty records the digest found at
+	// load instead.
+	Digest   string            `yaml:"digest" json:"digest"`
+	Metadata map[string]string `yaml:"metadata" json:"metadata,omitempty"`
+}
+
+// Arm routes Weight parts of an experiment's traffic to Version.
+type Arm struct {
+	Version string  `yaml:"version" json:"version"`
+	Weight  float64 `yaml:"weight" json:"weight"`
+}
+
+// Experiment splits the decisions of matching traders between arms.
+type Experiment struct {
+	Name string `yaml:"name" json:"name"`
+	// Models and Traders restrict the experiment to these model aliases and
+	// trader ids (runs); empty matches all.
+	Models  []string `yaml:"models" json:"models,omitempty"`
+	Traders []string `yaml:"traders" json:"traders,omitempty"`
+	// Unit is cycle (default) or trader.
+	Unit string `yaml:"unit" json:"unit,omitempty"`
+	Arms []Arm  `yaml:"arms" json:"arms"`
+}
+
+// Config is the prompt_versions section of manager.yaml.
+type Config struct {
+	Versions    []Version    `yaml:"versions" json:"versions"`
+	Experiments []Experiment `yaml:"experiments" json:"experiments,omitempty"`
+	Rollouts    []Rollout    `yaml:"rollouts" json:"rollouts,omitempty"`
+}
+
+// Assignment is the version chosen for one decision; Experiment and
+// Rollout name what it was drawn from, if anything.
+type Assignment struct {
+	Version    Version
+	Experiment string
+	Rollout    string
+}
+
+// Registry resolves versions, experiment and rollout assignments, and
+// tracks each rollout's health per trader in memory.
+type Registry struct {
+	versions    map[string]Version
+	order       []string
+	byDigest    map[string]string
+	experiments []Experiment
+	rollouts    []Rollout
+
+	mu           sync.Mutex
+	rolloutState map[string]*RolloutStatus
+}
+
+// NewRegistry validates cfg and records each version's digest, reading
+// templates from fsys or from disk when fsys is nil. Template paths are
+// used as given; callers resolve them first.
+func NewRegistry(cfg *Config, fsys fs.FS) (*Registry, error) {
+	r := &Registry{versions: map[string]Version{}, byDigest: map[string]string{}, rolloutState: map[string]*RolloutStatus{}}
+	if cfg == nil {
+		return r, nil
+	}
+	for i, v := range cfg.Versions {
+		v.Name = strings.TrimSpace(v.Name)
+		if v.Name == "" {
+			return nil, fmt.Errorf("prompt versions[%d]: name is required", i)
+		}
+		if _, dup := r.versions[v.Name]; dup {
+			return nil, fmt.Errorf("prompt version %q: duplicate name", v.Name)
+		}
+		if strings.TrimSpace(v.Template) == "" {
+			return nil, fmt.Errorf("prompt version %q: template is required", v.Name)
+		}
+		digest, err := llm.TemplateDigest(fsys, v.Template)
+		if err != nil {
+			return nil, fmt.Errorf("prompt version %q: %w", v.Name, err)
+		}
+		if pinned := llm.NormalizeDigest(v.Digest); pinned != "" && pinned != digest {
+			return nil, fmt.Errorf("prompt version %q: template %s has digest %s, pinned %s", v.Name, v.Template, digest, pinned)
+		}
+		v.Digest = digest
+		r.versions[v.Name] = v
+		r.order = append(r.order, v.Name)
+		if _, taken := r.byDigest[digest]; !taken {
+			r.byDigest[digest] = v.Name
+		}
+	}
+	seen := map[string]bool{}
+	for i, e := range cfg.Experiments {
+		e.Name = strings.TrimSpace(e.Name)
+		if e.Name == "" {
+			return nil, fmt.Errorf("prompt experiments[%d]: name is required", i)
+		}
+		if seen[e.Name] {
+			return nil, fmt.Errorf("prompt experiment %q: duplicate name", e.Name)
+		}
+		seen[e.Name] = true
+		e.Unit = strings.ToLower(strings.TrimSpace(e.Unit))
+		switch e.Unit {
+		case "":
+			e.Unit = UnitCycle
+		case UnitCycle, UnitTrader:
+		default:
+			return nil, fmt.Errorf("prompt experiment %q: unit %q must be %s or %s", e.Name, e.Unit, UnitCycle, UnitTrader)
+		}
+		if len(e.Arms) == 0 {
+			return nil, fmt.Errorf("prompt experiment %q: no arms", e.Name)
+		}
+		for _, a := range e.Arms {
+			if _, ok := r.versions[a.Version]; !ok {
+				return nil, fmt.Errorf("prompt experiment %q: unknown version %q", e.Name, a.Version)
+			}
+			if a.Weight <= 0 {
+				return nil, fmt.Errorf("prompt experiment %q: arm %q weight must be positive", e.Name, a.Version)
+			}
+		}
+		r.experiments = append(r.experiments, e)
+	}
+	if err := r.addRollouts(cfg.Rollouts); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Version returns the version called name.
+func (r *Registry) Version(name string) (Version, bool) {
+	if r == nil {
+		return Version{}, false
+	}
+	v, ok := r.versions[name]
+	return v, ok
+}
+
+// Versions lists the versions in configuration order.
+func (r *Registry) Versions() []Version {
+	if r == nil {
+		return nil
+	}
+	out := make([]Version, 0, len(r.order))
+	for _, name := range r.order {
+		out = append(out, r.versions[name])
+	}
+	return out
+}
+
+// VersionByDigest returns the first version whose template has digest, so
+// decisions rendered from a trader's own template are still attributed.
+func (r *Registry) VersionByDigest(digest string) (Version, bool) {
+	if r == nil {
+		return Version{}, false
+	}
+	name, ok := r.byDigest[llm.NormalizeDigest(digest)]
+	if !ok {
+		return Version{}, false
+	}
+	return r.versions[name], true
+}
+
+// Assign draws the version for a trader's decision cycle from the first
+// rollout, then the first experiment, matching the trader and model. It
+// reports false when neither applies.
+func (r *Registry) Assign(traderID, model string, cycle int) (Assignment, bool) {
+	if r == nil {
+		return Assignment{}, false
+	}
+	if a, ok := r.assignRollout(traderID, model, cycle); ok {
+		return a, true
+	}
+	for _, e := range r.experiments {
+		if !matches(e.Traders, traderID) || !matches(e.Models, model) {
+			continue
+		}
+		unit := traderID
+		if e.Unit == UnitCycle {
+			unit += "#" + strconv.Itoa(cycle)
+		}
+		arm := e.pick(bucket(e.Name, unit))
+		return Assignment{Version: r.versions[arm.Version], Experiment: e.Name}, true
+	}
+	return Assignment{}, false
+}
+
+// pick returns the arm covering u, a uniform draw in [0, 1).
+func (e Experiment) pick(u float64) Arm {
+	total := 0.0
+	for _, a := range e.Arms {
+		total += a.Weight
+	}
+	acc := 0.0
+	for _, a := range e.Arms {
+		acc += a.Weight / total
+		if u < acc {
+			return a
+		}
+	}
+	return e.Arms[len(e.Arms)-1]
+}
+
+// bucket hashes experiment and unit to a uniform value in [0, 1).
+func bucket(experiment, unit string) float64 {
+	sum := sha256.Sum256([]byte(experiment + "\x00" + unit))
+	return float64(binary.BigEndian.Uint64(sum[:8])>>11) / float64(uint64(1)<<53)
+}
+
+func matches(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(strings.TrimSpace(a), value) {
+			return true
+		}
+	}
+	return false
+}
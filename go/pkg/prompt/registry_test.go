@@ -0,0 +1,114 @@
+package prompt
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/llm"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"a.tmpl": {Data: []byte("prompt A")},
+		"b.tmpl": {Data: []byte("prompt B")},
+	}
+}
+
+func TestNewRegistryDigests(t *testing.T) {
+	fsys := testFS()
+	reg, err := NewRegistry(&Config{Versions: []Version{
+		{Name: "a", Template: "a.tmpl", Metadata: map[string]string{"author": "ops"}},
+		{Name: "b", Template: "b.tmpl", Digest: "sha256:" + llm.DigestString("prompt B")},
+	}}, fsys)
+	require.NoError(t, err)
+
+	a, ok := reg.Version("a")
+	require.True(t, ok)
+	assert.Equal(t, llm.DigestString("prompt A"), a.Digest)
+	assert.Equal(t, "ops", a.Metadata["author"])
+
+	byDigest, ok := reg.VersionByDigest("SHA256:" + llm.DigestString("prompt B"))
+	require.True(t, ok)
+	assert.Equal(t, "b", byDigest.Name)
+	assert.Len(t, reg.Versions(), 2)
+
+	_, err = NewRegistry(&Config{Versions: []Version{{Name: "a", Template: "a.tmpl", Digest: "deadbeef"}}}, fsys)
+	assert.ErrorContains(t, err, "pinned deadbeef")
+}
+
+func TestNewRegistryValidation(t *testing.T) {
+	versions := []Version{{Name: "a", Template: "a.tmpl"}}
+	cases := map[string]*Config{
+		"duplicate name":    {Versions: append(versions, Version{Name: "a", Template: "b.tmpl"})},
+		"template is":       {Versions: []Version{{Name: "a"}}},
+		"file does not":     {Versions: []Version{{Name: "a", Template: "missing.tmpl"}}},
+		"unknown version":   {Versions: versions, Experiments: []Experiment{{Name: "x", Arms: []Arm{{Version: "z", Weight: 1}}}}},
+		"must be positive":  {Versions: versions, Experiments: []Experiment{{Name: "x", Arms: []Arm{{Version: "a"}}}}},
+		"no arms":           {Versions: versions, Experiments: []Experiment{{Name: "x"}}},
+		"unit \"run\" must": {Versions: versions, Experiments: []Experiment{{Name: "x", Unit: "run", Arms: []Arm{{Version: "a", Weight: 1}}}}},
+	}
+	for want, cfg := range cases {
+		_, err := NewRegistry(cfg, testFS())
+		assert.ErrorContains(t, err, want)
+	}
+}
+
+func TestAssignSplitsTraffic(t *testing.T) {
+	reg, err := NewRegistry(&Config{
+		Versions: []Version{{Name: "a", Template: "a.tmpl"}, {Name: "b", Template: "b.tmpl"}},
+		Experiments: []Experiment{{
+			Name:   "trial",
+			Models: []string{"deepseek-chat"},
+			Arms:   []Arm{{Version: "a", Weight: 3}, {Version: "b", Weight: 1}},
+		}},
+	}, testFS())
+	require.NoError(t, err)
+
+	_, ok := reg.Assign("t1", "qwen-max", 1)
+	assert.False(t, ok, "experiment is scoped to deepseek-chat")
+
+	counts := map[string]int{}
+	for cycle := 0; cycle < 4000; cycle++ {
+		a, ok := reg.Assign("t1", "deepseek-chat", cycle)
+		require.True(t, ok)
+		assert.Equal(t, "trial", a.Experiment)
+		counts[a.Version.Name]++
+	}
+	assert.InDelta(t, 3000, counts["a"], 150)
+	assert.InDelta(t, 1000, counts["b"], 150)
+
+	first, _ := reg.Assign("t1", "deepseek-chat", 7)
+	again, _ := reg.Assign("t1", "deepseek-chat", 7)
+	assert.Equal(t, first, again, "assignment is deterministic")
+}
+
+func TestAssignTraderUnit(t *testing.T) {
+	reg, err := NewRegistry(&Config{
+		Versions: []Version{{Name: "a", Template: "a.tmpl"}, {Name: "b", Template: "b.tmpl"}},
+		Experiments: []Experiment{{
+			Name:    "sticky",
+			Traders: []string{"t1", "t2"},
+			Unit:    "Trader",
+			Arms:    []Arm{{Version: "a", Weight: 1}, {Version: "b", Weight: 1}},
+		}},
+	}, testFS())
+	require.NoError(t, err)
+
+	for _, trader := range []string{"t1", "t2"} {
+		first, ok := reg.Assign(trader, "any", 0)
+		require.True(t, ok)
+		for cycle := 1; cycle < 50; cycle++ {
+			a, _ := reg.Assign(trader, "any", cycle)
+			assert.Equal(t, first.Version.Name, a.Version.Name)
+		}
+	}
+	_, ok := reg.Assign("t3", "any", 0)
+	assert.False(t, ok)
+
+	var nilReg *Registry
+	_, ok = nilReg.Assign("t1", "any", 0)
+	assert.False(t, ok)
+}
@@ -0,0 +1,210 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const defaultRollbackMinCycles = 10
+
+// Rollout stages a candidate version for matching traders: Percent of their
+// decision cycles render with Candidate and the rest with Baseline. A trader
+// whose cycles breach Rollback is moved back to Baseline for good, while the
+// others carry on.
+type Rollout struct {
+	Name string `yaml:"name" json:"name"`
+	// Models and Traders restrict the rollout to these model aliases and
+	// trader ids; empty matches all.
+	Models    []string `yaml:"models" json:"models,omitempty"`
+	Traders   []string `yaml:"traders" json:"traders,omitempty"`
+	Baseline  string   `yaml:"baseline" json:"baseline"`
+	Candidate string   `yaml:"candidate" json:"candidate"`
+	// Percent of cycles, above 0 and up to 100, served by Candidate.
+	Percent float64 `yaml:"percent" json:"percent"`
+	// Symbols, when set, are the only symbols candidate cycles may open;
+	// their opens on other symbols are dropped.
+	Symbols  []string       `yaml:"symbols" json:"symbols,omitempty"`
+	Rollback RollbackPolicy `yaml:"rollback" json:"rollback"`
+}
+
+// RollbackPolicy is when a rollout falls back to its baseline for a trader.
+// Zero thresholds are not checked.
+type RollbackPolicy struct {
+	// MaxRejectionRate is the share of candidate cycles, 0-1, whose
+	// decisions may fail validation, judged once MinCycles candidate cycles
+	// were observed (default 10).
+	MaxRejectionRate float64 `yaml:"max_rejection_rate" json:"max_rejection_rate,omitempty"`
+	MinCycles        int     `yaml:"min_cycles" json:"min_cycles,omitempty"`
+	// MaxDrawdownPct is the largest drop of the trader's equity, in percent
+	// of its peak since the rollout began serving it.
+	MaxDrawdownPct float64 `yaml:"max_drawdown_pct" json:"max_drawdown_pct,omitempty"`
+}
+
+// AllowsSymbol reports whether candidate cycles may open symbol.
+func (r Rollout) AllowsSymbol(symbol string) bool {
+	return matches(r.Symbols, symbol)
+}
+
+// RolloutStatus is a rollout's health for one trader.
+type RolloutStatus struct {
+	Rollout           string
+	TraderID          string
+	Baseline          string
+	Candidate         string
+	StartedAt         time.Time
+	CandidateCycles   int
+	CandidateRejected int
+	BaselineCycles    int
+	BaselineRejected  int
+	PeakEquity        float64
+	DrawdownPct       float64
+	RolledBack        bool
+	RolledBackAt      time.Time
+	Reason            string
+}
+
+// CandidateRejectionRate is the share of candidate cycles that were rejected.
+func (s RolloutStatus) CandidateRejectionRate() float64 {
+	if s.CandidateCycles == 0 {
+		return 0
+	}
+	return float64(s.CandidateRejected) / float64(s.CandidateCycles)
+}
+
+// BaselineRejectionRate is the share of baseline cycles that were rejected.
+func (s RolloutStatus) BaselineRejectionRate() float64 {
+	if s.BaselineCycles == 0 {
+		return 0
+	}
+	return float64(s.BaselineRejected) / float64(s.BaselineCycles)
+}
+
+func (r *Registry) addRollouts(rollouts []Rollout) error {
+	seen := map[string]bool{}
+	for i, ro := range rollouts {
+		ro.Name = strings.TrimSpace(ro.Name)
+		if ro.Name == "" {
+			return fmt.Errorf("prompt rollouts[%d]: name is required", i)
+		}
+		if seen[ro.Name] {
+			return fmt.Errorf("prompt rollout %q: duplicate name", ro.Name)
+		}
+		seen[ro.Name] = true
+		for _, v := range []string{ro.Baseline, ro.Candidate} {
+			if _, ok := r.versions[v]; !ok {
+				return fmt.Errorf("prompt rollout %q: unknown version %q", ro.Name, v)
+			}
+		}
+		if ro.Baseline == ro.Candidate {
+			return fmt.Errorf("prompt rollout %q: candidate must differ from baseline", ro.Name)
+		}
+		if ro.Percent <= 0 || ro.Percent > 100 {
+			return fmt.Errorf("prompt rollout %q: percent %.2f must be above 0 and at most 100", ro.Name, ro.Percent)
+		}
+		p := &ro.Rollback
+		if p.MaxRejectionRate < 0 || p.MaxRejectionRate > 1 {
+			return fmt.Errorf("prompt rollout %q: max_rejection_rate must be between 0 and 1", ro.Name)
+		}
+		if p.MaxDrawdownPct < 0 || p.MinCycles < 0 {
+			return fmt.Errorf("prompt rollout %q: rollback thresholds must be non-negative", ro.Name)
+		}
+		if p.MinCycles == 0 {
+			p.MinCycles = defaultRollbackMinCycles
+		}
+		r.rollouts = append(r.rollouts, ro)
+	}
+	return nil
+}
+
+// Rollout returns the rollout called name.
+func (r *Registry) Rollout(name string) (Rollout, bool) {
+	if r == nil {
+		return Rollout{}, false
+	}
+	for _, ro := range r.rollouts {
+		if ro.Name == name {
+			return ro, true
+		}
+	}
+	return Rollout{}, false
+}
+
+// assignRollout draws the version of a trader's cycle from the first
+// matching rollout. A rolled back trader stays on the baseline.
+func (r *Registry) assignRollout(traderID, model string, cycle int) (Assignment, bool) {
+	for _, ro := range r.rollouts {
+		if !matches(ro.Traders, traderID) || !matches(ro.Models, model) {
+			continue
+		}
+		version := ro.Baseline
+		if !r.rolledBack(ro.Name, traderID) && bucket(ro.Name, fmt.Sprintf("%s#%d", traderID, cycle))*100 < ro.Percent {
+			version = ro.Candidate
+		}
+		return Assignment{Version: r.versions[version], Rollout: ro.Name}, true
+	}
+	return Assignment{}, false
+}
+
+func (r *Registry) rolledBack(rollout, traderID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.rolloutState[rolloutKey(rollout, traderID)]
+	return s != nil && s.RolledBack
+}
+
+// ObserveRollout records the outcome of a trader's cycle served by rollout
+// with version: whether its decisions were rejected and the trader's equity
+// when the cycle began (0 when unknown). It returns the updated status and
+// true when this cycle triggered the rollback.
+func (r *Registry) ObserveRollout(rollout, traderID, version string, rejected bool, equity float64, now time.Time) (RolloutStatus, bool) {
+	ro, ok := r.Rollout(rollout)
+	if !ok {
+		return RolloutStatus{}, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := rolloutKey(rollout, traderID)
+	s := r.rolloutState[key]
+	if s == nil {
+		s = &RolloutStatus{Rollout: ro.Name, TraderID: traderID, Baseline: ro.Baseline, Candidate: ro.Candidate, StartedAt: now}
+		r.rolloutState[key] = s
+	}
+	if s.RolledBack {
+		return *s, false
+	}
+	if version == ro.Candidate {
+		s.CandidateCycles++
+		if rejected {
+			s.CandidateRejected++
+		}
+	} else {
+		s.BaselineCycles++
+		if rejected {
+			s.BaselineRejected++
+		}
+	}
+	if equity > 0 {
+		if equity > s.PeakEquity {
+			s.PeakEquity = equity
+		}
+		s.DrawdownPct = (s.PeakEquity - equity) / s.PeakEquity * 100
+	}
+	p := ro.Rollback
+	switch {
+	case p.MaxDrawdownPct > 0 && s.DrawdownPct > p.MaxDrawdownPct:
+		s.Reason = fmt.Sprintf("drawdown %.2f%% exceeds %.2f%%", s.DrawdownPct, p.MaxDrawdownPct)
+	case p.MaxRejectionRate > 0 && s.CandidateCycles >= p.MinCycles && s.CandidateRejectionRate() > p.MaxRejectionRate:
+		s.Reason = fmt.Sprintf("candidate rejection rate %.0f%% (%d/%d cycles) exceeds %.0f%%, baseline %.0f%%",
+			s.CandidateRejectionRate()*100, s.CandidateRejected, s.CandidateCycles, p.MaxRejectionRate*100, s.BaselineRejectionRate()*100)
+	default:
+		return *s, false
+	}
+	s.RolledBack = true
+	s.RolledBackAt = now
+	return *s, true
+}
+
+func rolloutKey(rollout, traderID string) string {
+	return rollout + "\x00" + traderID
+}
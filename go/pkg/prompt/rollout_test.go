@@ -0,0 +1,121 @@
+package prompt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rolloutRegistry(t *testing.T, policy RollbackPolicy) *Registry {
+	t.Helper()
+	reg, err := NewRegistry(&Config{
+		Versions: []Version{{Name: "a", Template: "a.tmpl"}, {Name: "b", Template: "b.tmpl"}},
+		Experiments: []Experiment{{
+			Name: "trial",
+			Arms: []Arm{{Version: "a", Weight: 1}},
+		}},
+		Rollouts: []Rollout{{
+			Name:      "b-canary",
+			Traders:   []string{"t1", "t2"},
+			Baseline:  "a",
+			Candidate: "b",
+			Percent:   25,
+			Symbols:   []string{"BTC"},
+			Rollback:  policy,
+		}},
+	}, testFS())
+	require.NoError(t, err)
+	return reg
+}
+
+func TestAssignRolloutPercent(t *testing.T) {
+	reg := rolloutRegistry(t, RollbackPolicy{})
+	counts := map[string]int{}
+	for cycle := 0; cycle < 4000; cycle++ {
+		a, ok := reg.Assign("t1", "any", cycle)
+		require.True(t, ok)
+		assert.Equal(t, "b-canary", a.Rollout)
+		assert.Empty(t, a.Experiment)
+		counts[a.Version.Name]++
+	}
+	assert.InDelta(t, 1000, counts["b"], 100)
+
+	a, ok := reg.Assign("t3", "any", 0)
+	require.True(t, ok)
+	assert.Equal(t, "trial", a.Experiment, "unmatched traders fall through to experiments")
+
+	ro, ok := reg.Rollout("b-canary")
+	require.True(t, ok)
+	assert.Equal(t, defaultRollbackMinCycles, ro.Rollback.MinCycles)
+	assert.True(t, ro.AllowsSymbol("btc"))
+	assert.False(t, ro.AllowsSymbol("ETH"))
+}
+
+func TestObserveRolloutRejectionRollback(t *testing.T) {
+	reg := rolloutRegistry(t, RollbackPolicy{MaxRejectionRate: 0.5, MinCycles: 4})
+	now := time.Unix(1700000000, 0)
+	for i := 0; i < 3; i++ {
+		_, rolled := reg.ObserveRollout("b-canary", "t1", "b", true, 0, now)
+		require.False(t, rolled, "below min cycles")
+	}
+	status, _ := reg.ObserveRollout("b-canary", "t1", "a", true, 0, now)
+	assert.Equal(t, 1, status.BaselineCycles)
+
+	status, rolled := reg.ObserveRollout("b-canary", "t1", "b", false, 0, now)
+	require.True(t, rolled)
+	assert.True(t, status.RolledBack)
+	assert.Equal(t, 0.75, status.CandidateRejectionRate())
+	assert.Contains(t, status.Reason, "rejection rate 75% (3/4 cycles) exceeds 50%")
+
+	_, rolled = reg.ObserveRollout("b-canary", "t1", "b", true, 0, now)
+	assert.False(t, rolled, "rollback is reported once")
+	for cycle := 0; cycle < 200; cycle++ {
+		a, _ := reg.Assign("t1", "any", cycle)
+		require.Equal(t, "a", a.Version.Name)
+	}
+
+	seen := false
+	for cycle := 0; cycle < 200; cycle++ {
+		a, _ := reg.Assign("t2", "any", cycle)
+		seen = seen || a.Version.Name == "b"
+	}
+	assert.True(t, seen, "other traders keep the candidate")
+}
+
+func TestObserveRolloutDrawdownRollback(t *testing.T) {
+	reg := rolloutRegistry(t, RollbackPolicy{MaxDrawdownPct: 10})
+	now := time.Unix(1700000000, 0)
+	for _, equity := range []float64{1000, 1200, 1100} {
+		_, rolled := reg.ObserveRollout("b-canary", "t1", "b", false, equity, now)
+		require.False(t, rolled)
+	}
+	status, rolled := reg.ObserveRollout("b-canary", "t1", "a", false, 1070, now)
+	require.True(t, rolled)
+	assert.Equal(t, 1200.0, status.PeakEquity)
+	assert.InDelta(t, 10.83, status.DrawdownPct, 0.01)
+	assert.Equal(t, now, status.RolledBackAt)
+
+	_, rolled = reg.ObserveRollout("missing", "t1", "b", true, 0, now)
+	assert.False(t, rolled)
+}
+
+func TestNewRegistryRolloutValidation(t *testing.T) {
+	versions := []Version{{Name: "a", Template: "a.tmpl"}, {Name: "b", Template: "b.tmpl"}}
+	cases := map[string]Rollout{
+		"name is required":      {Baseline: "a", Candidate: "b", Percent: 10},
+		"unknown version \"z\"": {Name: "x", Baseline: "a", Candidate: "z", Percent: 10},
+		"must differ":           {Name: "x", Baseline: "a", Candidate: "a", Percent: 10},
+		"at most 100":           {Name: "x", Baseline: "a", Candidate: "b", Percent: 120},
+		"max_rejection_rate":    {Name: "x", Baseline: "a", Candidate: "b", Percent: 10, Rollback: RollbackPolicy{MaxRejectionRate: 2}},
+		"must be non-negative":  {Name: "x", Baseline: "a", Candidate: "b", Percent: 10, Rollback: RollbackPolicy{MaxDrawdownPct: -1}},
+	}
+	for want, ro := range cases {
+		_, err := NewRegistry(&Config{Versions: versions, Rollouts: []Rollout{ro}}, testFS())
+		assert.ErrorContains(t, err, want)
+	}
+	dup := Rollout{Name: "x", Baseline: "a", Candidate: "b", Percent: 10}
+	_, err := NewRegistry(&Config{Versions: versions, Rollouts: []Rollout{dup, dup}}, testFS())
+	assert.ErrorContains(t, err, "duplicate name")
+}
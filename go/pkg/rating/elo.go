@@ -0,0 +1,112 @@
+package rating
+
+import (
+	"math"
+	"sort"
+)
+
+// Default Elo parameters, following chess conventions.
+const (
+	DefaultInitial = 1500.0
+	DefaultK       = 24.0
+)
+
+// Result of a match from the first player's point of view.
+const (
+	Loss = 0.0
+	Draw = 0.5
+	Win  = 1.0
+)
+
+// Rating is one model's standing in the table.
+type Rating struct {
+	TraderID string  `json:"trader_id"`
+	Rating   float64 `json:"rating"`
+	Matches  int     `json:"matches"`
+	Wins     int     `json:"wins"`
+	Losses   int     `json:"losses"`
+	Draws    int     `json:"draws"`
+}
+
+// Pairing is one head-to-head result to apply.
+type Pairing struct {
+	A, B   string
+	Result float64 // for A: Win, Draw or Loss
+}
+
+// Table holds Elo ratings keyed by trader id.
+type Table struct {
+	k       float64
+	initial float64
+	ratings map[string]*Rating
+}
+
+// NewTable creates an empty table; non-positive arguments fall back to the
+// defaults.
+func NewTable(k, initial float64) *Table {
+	if k <= 0 {
+		k = DefaultK
+	}
+	if initial <= 0 {
+		initial = DefaultInitial
+	}
+	return &Table{k: k, initial: initial, ratings: make(map[string]*Rating)}
+}
+
+// Expected is the probability that a player rated ra beats one rated rb.
+func Expected(ra, rb float64) float64 {
+	return 1 / (1 + math.Pow(10, (rb-ra)/400))
+}
+
+func (t *Table) get(id string) *Rating {
+	r, ok := t.ratings[id]
+	if !ok {
+		r = &Rating{TraderID: id, Rating: t.initial}
+		t.ratings[id] = r
+	}
+	return r
+}
+
+// ApplyRound scores pairings played simultaneously: every expectation uses
+// the ratings from before the round, so the order of pairings within a
+// round does not matter.
+func (t *Table) ApplyRound(pairings []Pairing) {
+	deltas := make(map[string]float64)
+	for _, p := range pairings {
+		a, b := t.get(p.A), t.get(p.B)
+		ea := Expected(a.Rating, b.Rating)
+		deltas[p.A] += t.k * (p.Result - ea)
+		deltas[p.B] += t.k * ((1 - p.Result) - (1 - ea))
+		a.Matches++
+		b.Matches++
+		switch p.Result {
+		case Win:
+			a.Wins++
+			b.Losses++
+		case Loss:
+			a.Losses++
+			b.Wins++
+		default:
+			a.Draws++
+			b.Draws++
+		}
+	}
+	for id, d := range deltas {
+		t.ratings[id].Rating += d
+	}
+}
+
+// Ranking returns ratings best first, ties broken by trader id.
+func (t *Table) Ranking() []Rating {
+	out := make([]Rating, 0, len(t.ratings))
+	for _, r := range t.ratings {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Rating != out[j].Rating {
+			return out[i].Rating > out[j].Rating
+		}
+		return out[i].TraderID < out[j].TraderID
+	})
+	return out
+}
@@ -0,0 +1,216 @@
+package rating
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"nof0-api/pkg/journal"
+)
+
+// Default head-to-head settings.
+const (
+	DefaultBucket  = 5 * time.Minute
+	DefaultHorizon = time.Hour
+	DefaultMinMove = 0.001
+)
+
+// Config controls how cycles are matched and judged.
+type Config struct {
+	K       float64
+	Initial float64
+	// Bucket groups cycles that started within the same window; models in
+	// one bucket saw the same market data for a symbol.
+	Bucket time.Duration
+	// Horizon is how far ahead the outcome price is read.
+	Horizon time.Duration
+	// MinMove is the absolute return below which a disagreement is a draw:
+	// the market did not move enough to prove either side right.
+	MinMove float64
+}
+
+func (c Config) withDefaults() Config {
+	if c.Bucket <= 0 {
+		c.Bucket = DefaultBucket
+	}
+	if c.Horizon <= 0 {
+		c.Horizon = DefaultHorizon
+	}
+	if c.MinMove <= 0 {
+		c.MinMove = DefaultMinMove
+	}
+	return c
+}
+
+// Observation is a model's stance on a symbol at one cycle: +1 long, -1
+// short, 0 flat.
+type Observation struct {
+	TraderID string
+	Symbol   string
+	At       time.Time
+	Price    float64
+	Stance   float64
+}
+
+// Summary reports how many matches were scored.
+type Summary struct {
+	Ratings    []Rating `json:"ratings"`
+	Rounds     int      `json:"rounds"`
+	Matches    int      `json:"matches"`
+	Unresolved int      `json:"unresolved"`
+}
+
+// ObservationsFromJournal derives each cycle's stance per symbol of its
+// market snapshot. An explicit open or close decision sets the stance; a hold,
+// or no decision at all, keeps the position held when the cycle started.
+func ObservationsFromJournal(records []*journal.CycleRecord) []Observation {
+	var out []Observation
+	for _, rec := range records {
+		if rec == nil || rec.TraderID == "" || rec.Timestamp.IsZero() {
+			continue
+		}
+		stance := make(map[string]float64)
+		for _, p := range rec.Positions {
+			sym, _ := p["symbol"].(string)
+			side, _ := p["side"].(string)
+			switch strings.ToLower(side) {
+			case "long":
+				stance[strings.ToUpper(sym)] = 1
+			case "short":
+				stance[strings.ToUpper(sym)] = -1
+			}
+		}
+		decisions, _ := journal.ParseDecisionsJSON(rec.DecisionsJSON)
+		for _, d := range decisions {
+			sym := strings.ToUpper(strings.TrimSpace(d.Symbol))
+			switch d.Action {
+			case "open_long":
+				stance[sym] = 1
+			case "open_short":
+				stance[sym] = -1
+			case "close_long", "close_short":
+				stance[sym] = 0
+			}
+		}
+		for sym, raw := range rec.MarketDigest {
+			md, _ := raw.(map[string]any)
+			price, _ := md["price"].(float64)
+			if price <= 0 {
+				continue
+			}
+			sym = strings.ToUpper(sym)
+			out = append(out, Observation{
+				TraderID: rec.TraderID,
+				Symbol:   sym,
+				At:       rec.Timestamp,
+				Price:    price,
+				Stance:   stance[sym],
+			})
+		}
+	}
+	return out
+}
+
+type roundKey struct {
+	start  time.Time
+	symbol string
+}
+
+// Score replays observations chronologically. Each bucket and symbol is one
+// round: models that took different stances are paired, and the one whose
+// stance earned more over the horizon wins. Identical stances carry no
+// information about skill and are not paired. All models share the round's
+// first price and the first price seen after the horizon, so timing inside a
+// bucket cannot decide a match.
+func Score(obs []Observation, cfg Config) Summary {
+	cfg = cfg.withDefaults()
+	sorted := append([]Observation(nil), obs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if !a.At.Equal(b.At) {
+			return a.At.Before(b.At)
+		}
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		return a.TraderID < b.TraderID
+	})
+
+	rounds := make(map[roundKey][]Observation)
+	var order []roundKey
+	prices := make(map[string][]Observation)
+	for _, o := range sorted {
+		key := roundKey{start: o.At.Truncate(cfg.Bucket), symbol: o.Symbol}
+		if _, ok := rounds[key]; !ok {
+			order = append(order, key)
+		}
+		rounds[key] = append(rounds[key], o)
+		prices[o.Symbol] = append(prices[o.Symbol], o)
+	}
+
+	table := NewTable(cfg.K, cfg.Initial)
+	summary := Summary{}
+	for _, key := range order {
+		round := rounds[key]
+		entrants := firstPerTrader(round)
+		if len(entrants) < 2 {
+			continue
+		}
+		exit, ok := priceAfter(prices[key.symbol], round[0].At.Add(cfg.Horizon))
+		if !ok {
+			summary.Unresolved++
+			continue
+		}
+		move := exit/round[0].Price - 1
+		var pairings []Pairing
+		for i := 0; i < len(entrants); i++ {
+			for j := i + 1; j < len(entrants); j++ {
+				a, b := entrants[i], entrants[j]
+				if a.Stance == b.Stance {
+					continue
+				}
+				result := Draw
+				if move >= cfg.MinMove || move <= -cfg.MinMove {
+					result = Loss
+					if a.Stance*move > b.Stance*move {
+						result = Win
+					}
+				}
+				pairings = append(pairings, Pairing{A: a.TraderID, B: b.TraderID, Result: result})
+			}
+		}
+		if len(pairings) == 0 {
+			continue
+		}
+		table.ApplyRound(pairings)
+		summary.Rounds++
+		summary.Matches += len(pairings)
+	}
+	summary.Ratings = table.Ranking()
+	return summary
+}
+
+// firstPerTrader keeps each trader's earliest observation, ordered by
+// trader id for deterministic pairing.
+func firstPerTrader(obs []Observation) []Observation {
+	seen := make(map[string]struct{}, len(obs))
+	out := make([]Observation, 0, len(obs))
+	for _, o := range obs {
+		if _, ok := seen[o.TraderID]; ok {
+			continue
+		}
+		seen[o.TraderID] = struct{}{}
+		out = append(out, o)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TraderID < out[j].TraderID })
+	return out
+}
+
+// priceAfter returns the first observed price at or after t.
+func priceAfter(series []Observation, t time.Time) (float64, bool) {
+	i := sort.Search(len(series), func(i int) bool { return !series[i].At.Before(t) })
+	if i == len(series) {
+		return 0, false
+	}
+	return series[i].Price, true
+}
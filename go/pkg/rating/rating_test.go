@@ -0,0 +1,99 @@
+package rating
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/journal"
+)
+
+func TestApplyRoundIsOrderIndependent(t *testing.T) {
+	round := []Pairing{
+		{A: "a", B: "b", Result: Win},
+		{A: "b", B: "c", Result: Draw},
+		{A: "a", B: "c", Result: Loss},
+	}
+	t1 := NewTable(0, 0)
+	t1.ApplyRound(round)
+	t2 := NewTable(0, 0)
+	t2.ApplyRound([]Pairing{round[2], round[0], round[1]})
+	assert.Equal(t, t1.Ranking(), t2.Ranking())
+
+	var total float64
+	for _, r := range t1.Ranking() {
+		total += r.Rating
+		assert.Equal(t, 2, r.Matches)
+	}
+	assert.InDelta(t, 3*DefaultInitial, total, 1e-9, "rating is conserved")
+	assert.InDelta(t, 0.5, Expected(1500, 1500), 1e-12)
+	assert.InDelta(t, 0.909, Expected(1800, 1400), 1e-3)
+}
+
+func at(min int) time.Time {
+	return time.Date(2025, 10, 24, 12, 0, 0, 0, time.UTC).Add(time.Duration(min) * time.Minute)
+}
+
+func TestScoreHeadToHead(t *testing.T) {
+	obs := []Observation{
+		// Round 1: bull goes long, bear short, flat stays out; BTC rises 2%.
+		{TraderID: "bull", Symbol: "BTC", At: at(0), Price: 100, Stance: 1},
+		{TraderID: "bear", Symbol: "BTC", At: at(1), Price: 100.5, Stance: -1},
+		{TraderID: "flat", Symbol: "BTC", At: at(2), Price: 100.2, Stance: 0},
+		// A second observation in the same bucket does not enter twice.
+		{TraderID: "bull", Symbol: "BTC", At: at(3), Price: 100.1, Stance: -1},
+		// Round 2: only identical stances, nothing to learn.
+		{TraderID: "bull", Symbol: "BTC", At: at(60), Price: 102, Stance: 1},
+		{TraderID: "bear", Symbol: "BTC", At: at(61), Price: 102, Stance: 1},
+		// Round 3: disagreement but the market barely moves -> draw.
+		{TraderID: "bull", Symbol: "BTC", At: at(120), Price: 102, Stance: 1},
+		{TraderID: "bear", Symbol: "BTC", At: at(121), Price: 102, Stance: -1},
+		{TraderID: "bull", Symbol: "BTC", At: at(180), Price: 102.05, Stance: 1},
+		// Round 4: no price after the horizon yet.
+		{TraderID: "bull", Symbol: "ETH", At: at(180), Price: 4000, Stance: 1},
+		{TraderID: "bear", Symbol: "ETH", At: at(181), Price: 4000, Stance: -1},
+	}
+	sum := Score(obs, Config{})
+	assert.Equal(t, 2, sum.Rounds)
+	assert.Equal(t, 4, sum.Matches)
+	assert.Equal(t, 1, sum.Unresolved)
+
+	require.Len(t, sum.Ratings, 3)
+	byID := map[string]Rating{}
+	for _, r := range sum.Ratings {
+		byID[r.TraderID] = r
+	}
+	assert.Equal(t, "bull", sum.Ratings[0].TraderID)
+	assert.Equal(t, "bear", sum.Ratings[2].TraderID)
+	assert.Equal(t, Rating{TraderID: "bull", Rating: byID["bull"].Rating, Matches: 3, Wins: 2, Draws: 1}, byID["bull"])
+	assert.Equal(t, 1, byID["flat"].Wins)
+	assert.Equal(t, 1, byID["flat"].Losses)
+	assert.Equal(t, 2, byID["bear"].Losses)
+}
+
+func TestObservationsFromJournal(t *testing.T) {
+	rec := &journal.CycleRecord{
+		TraderID:      "gpt-5",
+		Timestamp:     at(0),
+		DecisionsJSON: `[{"symbol":"eth","action":"open_short"},{"symbol":"SOL","action":"close_long"}]`,
+		Positions: []map[string]any{
+			{"symbol": "BTC", "side": "long"},
+			{"symbol": "SOL", "side": "long"},
+		},
+		MarketDigest: map[string]any{
+			"BTC":  map[string]any{"price": 100000.0},
+			"ETH":  map[string]any{"price": 4000.0},
+			"SOL":  map[string]any{"price": 200.0},
+			"DOGE": map[string]any{"price": 0.2},
+			"XRP":  map[string]any{},
+		},
+	}
+	stances := map[string]float64{}
+	for _, o := range ObservationsFromJournal([]*journal.CycleRecord{rec, nil, {TraderID: "x"}}) {
+		assert.Equal(t, "gpt-5", o.TraderID)
+		stances[o.Symbol] = o.Stance
+	}
+	assert.Equal(t, map[string]float64{"BTC": 1, "ETH": -1, "SOL": 0, "DOGE": 0}, stances)
+}
@@ -19,6 +19,7 @@ type RuntimeStateDetail struct {
 	Pause       *RuntimePauseDetail       `json:"pause,omitempty"`
 	Allocation  *RuntimeAllocationDetail  `json:"allocation,omitempty"`
 	Performance *RuntimePerformanceDetail `json:"performance,omitempty"`
+	LossStreak  *RuntimeLossStreakDetail  `json:"loss_streak,omitempty"`
 }
 
 type RuntimeDecisionDetail struct {
@@ -35,6 +36,7 @@ type RuntimeAllocationDetail struct {
 	EquityUSD          float64 `json:"equity_usd,omitempty"`
 	UsedMarginUSD      float64 `json:"used_margin_usd,omitempty"`
 	AvailableMarginUSD float64 `json:"available_margin_usd,omitempty"`
+	PeakEquityUSD      float64 `json:"peak_equity_usd,omitempty"`
 }
 
 type RuntimePerformanceDetail struct {
@@ -42,6 +44,14 @@ type RuntimePerformanceDetail struct {
 	TotalPnLUSD float64 `json:"total_pnl_usd,omitempty"`
 }
 
+// RuntimeLossStreakDetail tracks consecutive losing closes and the entry
+// pause they triggered.
+type RuntimeLossStreakDetail struct {
+	Count       int        `json:"count,omitempty"`
+	LastLossPnL float64    `json:"last_loss_pnl,omitempty"`
+	PausedUntil *time.Time `json:"paused_until,omitempty"`
+}
+
 // RuntimeStateRecord encapsulates an upsert payload for trader_runtime_state.
 type RuntimeStateRecord struct {
 	TraderID            string
@@ -0,0 +1,214 @@
+// Package risk enforces a trader's hard limits on orders at execution time.
+// The system prompt states the same limits, but the model cannot be trusted
+// to respect them, so every entry passes a Guard right before it is sent to
+// the exchange. It has no dependencies on the executor or manager.
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Config holds the limits a Guard enforces. Zero values disable the
+// corresponding check.
+type Config struct {
+	// MaxLossPerTradeUSD caps the loss if the stop is hit; without a stop the
+	// whole margin counts as at risk.
+	MaxLossPerTradeUSD float64
+	// MinRiskRewardRatio is the least reward/risk between stop and target.
+	MinRiskRewardRatio float64
+	// MinLiquidationDistancePct is how far, in percent of entry, the
+	// estimated liquidation price must sit.
+	MinLiquidationDistancePct float64
+	// MaxPositionConcentrationPct caps one symbol's notional, including what
+	// is already held, as a percentage of equity.
+	MaxPositionConcentrationPct float64
+	// MinPositionSizeUSD rejects entries too small to be worth the fees,
+	// including those shrunk below it by resizing.
+	MinPositionSizeUSD float64
+	// Resize shrinks size or leverage to fit the loss, liquidation and
+	// concentration limits instead of rejecting the order.
+	Resize bool
+}
+
+// Validate checks the limits are within range.
+func (c Config) Validate() error {
+	switch {
+	case c.MaxLossPerTradeUSD < 0:
+		return errors.New("max_loss_per_trade_usd cannot be negative")
+	case c.MinRiskRewardRatio < 0:
+		return errors.New("min_risk_reward_ratio cannot be negative")
+	case c.MinLiquidationDistancePct < 0 || c.MinLiquidationDistancePct >= 100:
+		return errors.New("min_liquidation_distance_pct must be between 0 and 100")
+	case c.MaxPositionConcentrationPct < 0:
+		return errors.New("max_position_concentration_pct cannot be negative")
+	case c.MinPositionSizeUSD < 0:
+		return errors.New("min_position_size_usd cannot be negative")
+	}
+	return nil
+}
+
+// Order is an entry about to be placed.
+type Order struct {
+	Symbol     string
+	Long       bool
+	SizeUSD    float64 // notional
+	EntryPrice float64
+	StopLoss   float64 // 0 when the entry has no stop
+	TakeProfit float64 // 0 when the entry has no target
+	Leverage   int
+}
+
+// Portfolio is the account state an order is checked against.
+type Portfolio struct {
+	EquityUSD float64
+	// ExposureUSD is the notional already held per symbol.
+	ExposureUSD map[string]float64
+}
+
+// Rejection lists every limit an order breaks that resizing could not fix.
+type Rejection struct {
+	Symbol   string
+	Problems []string
+}
+
+func (e *Rejection) Error() string {
+	return fmt.Sprintf("risk: %s rejected: %s", e.Symbol, strings.Join(e.Problems, "; "))
+}
+
+// Verdict is an order that passed the guard, possibly resized.
+type Verdict struct {
+	Order Order
+	// Adjustments describes each resize, empty when the order is unchanged.
+	Adjustments []string
+}
+
+// Resized reports whether the guard changed the order.
+func (v Verdict) Resized() bool { return len(v.Adjustments) > 0 }
+
+// Guard checks orders against a Config.
+type Guard struct {
+	cfg Config
+}
+
+// NewGuard returns a guard enforcing cfg.
+func NewGuard(cfg Config) *Guard {
+	return &Guard{cfg: cfg}
+}
+
+// Check returns o, resized when the config allows it, or a *Rejection naming
+// the limits it breaks. A nil guard passes every order.
+func (g *Guard) Check(o Order, p Portfolio) (Verdict, error) {
+	v := Verdict{Order: o}
+	if g == nil {
+		return v, nil
+	}
+	c := g.cfg
+	var problems []string
+	reject := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	if o.EntryPrice <= 0 || o.SizeUSD <= 0 {
+		return v, &Rejection{Symbol: o.Symbol, Problems: []string{"entry price and size must be positive"}}
+	}
+
+	if c.MinRiskRewardRatio > 0 {
+		if rr := riskReward(o); rr == 0 {
+			reject("stop_loss and take_profit must sit on opposite sides of entry")
+		} else if rr < c.MinRiskRewardRatio {
+			reject("reward/risk %.2f below %.2f", rr, c.MinRiskRewardRatio)
+		}
+	}
+
+	if c.MinLiquidationDistancePct > 0 && o.Leverage > 0 {
+		if dist := liquidationDistancePct(o.Leverage); dist < c.MinLiquidationDistancePct {
+			fit := int(math.Floor(100 / c.MinLiquidationDistancePct))
+			if c.Resize && fit >= 1 {
+				v.Adjustments = append(v.Adjustments, fmt.Sprintf("leverage %d -> %d for liquidation distance %.2f%%", o.Leverage, fit, c.MinLiquidationDistancePct))
+				v.Order.Leverage = fit
+			} else {
+				reject("liquidation %.2f%% from entry, below %.2f%%", dist, c.MinLiquidationDistancePct)
+			}
+		}
+	}
+
+	if c.MaxLossPerTradeUSD > 0 {
+		if loss := lossAtStop(v.Order); loss > c.MaxLossPerTradeUSD+1e-9 {
+			if c.Resize {
+				fit := v.Order.SizeUSD * c.MaxLossPerTradeUSD / loss
+				v.Adjustments = append(v.Adjustments, fmt.Sprintf("size %.2f -> %.2f for max loss %.2f USD", v.Order.SizeUSD, fit, c.MaxLossPerTradeUSD))
+				v.Order.SizeUSD = fit
+			} else {
+				reject("loss at stop %.2f USD above %.2f", loss, c.MaxLossPerTradeUSD)
+			}
+		}
+	}
+
+	if c.MaxPositionConcentrationPct > 0 && p.EquityUSD > 0 {
+		held := p.ExposureUSD[strings.ToUpper(o.Symbol)]
+		limit := p.EquityUSD * c.MaxPositionConcentrationPct / 100
+		if held+v.Order.SizeUSD > limit+1e-9 {
+			if fit := limit - held; c.Resize && fit > 0 {
+				v.Adjustments = append(v.Adjustments, fmt.Sprintf("size %.2f -> %.2f for concentration %.2f%%", v.Order.SizeUSD, fit, c.MaxPositionConcentrationPct))
+				v.Order.SizeUSD = fit
+			} else {
+				reject("%s exposure %.2f%% of equity above %.2f%%", o.Symbol, 100*(held+v.Order.SizeUSD)/p.EquityUSD, c.MaxPositionConcentrationPct)
+			}
+		}
+	}
+
+	if c.MinPositionSizeUSD > 0 && v.Order.SizeUSD < c.MinPositionSizeUSD-1e-9 {
+		reject("size %.2f USD below %.2f", v.Order.SizeUSD, c.MinPositionSizeUSD)
+	}
+
+	if len(problems) > 0 {
+		return Verdict{Order: o}, &Rejection{Symbol: o.Symbol, Problems: problems}
+	}
+	return v, nil
+}
+
+// riskReward returns reward/risk between entry, stop and target, or 0 when
+// they are missing or on the wrong sides of entry.
+func riskReward(o Order) float64 {
+	if o.StopLoss <= 0 || o.TakeProfit <= 0 {
+		return 0
+	}
+	risk, reward := o.EntryPrice-o.StopLoss, o.TakeProfit-o.EntryPrice
+	if !o.Long {
+		risk, reward = -risk, -reward
+	}
+	if risk <= 0 || reward <= 0 {
+		return 0
+	}
+	return reward / risk
+}
+
+// lossAtStop estimates the USD lost if the stop fills at its price. Without
+// a stop, or with one beyond liquidation, the margin is lost instead.
+func lossAtStop(o Order) float64 {
+	margin := o.SizeUSD
+	if o.Leverage > 0 {
+		margin = o.SizeUSD / float64(o.Leverage)
+	}
+	if o.StopLoss <= 0 {
+		return margin
+	}
+	move := (o.EntryPrice - o.StopLoss) / o.EntryPrice
+	if !o.Long {
+		move = -move
+	}
+	if move <= 0 {
+		// A stop on the profit side of entry caps no loss.
+		return margin
+	}
+	return math.Min(o.SizeUSD*move, margin)
+}
+
+// liquidationDistancePct estimates how far price can move against an
+// isolated position before liquidation. It ignores maintenance margin, so
+// the real distance is somewhat smaller.
+func liquidationDistancePct(leverage int) float64 {
+	return 100 / float64(leverage)
+}
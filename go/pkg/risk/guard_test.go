@@ -0,0 +1,74 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func longBTC() Order {
+	return Order{Symbol: "BTC", Long: true, SizeUSD: 1000, EntryPrice: 100, StopLoss: 95, TakeProfit: 115, Leverage: 5}
+}
+
+func TestGuardPassesOrderWithinLimits(t *testing.T) {
+	g := NewGuard(Config{MaxLossPerTradeUSD: 60, MinRiskRewardRatio: 2, MinLiquidationDistancePct: 10, MaxPositionConcentrationPct: 50, MinPositionSizeUSD: 10})
+	v, err := g.Check(longBTC(), Portfolio{EquityUSD: 5000})
+	require.NoError(t, err)
+	assert.False(t, v.Resized())
+	assert.Equal(t, longBTC(), v.Order)
+}
+
+func TestGuardRejectsEveryViolation(t *testing.T) {
+	g := NewGuard(Config{MaxLossPerTradeUSD: 20, MinRiskRewardRatio: 4, MinLiquidationDistancePct: 25, MaxPositionConcentrationPct: 20, MinPositionSizeUSD: 2000})
+	_, err := g.Check(longBTC(), Portfolio{EquityUSD: 5000, ExposureUSD: map[string]float64{"BTC": 500}})
+	var rej *Rejection
+	require.ErrorAs(t, err, &rej)
+	assert.Equal(t, []string{
+		"reward/risk 3.00 below 4.00",
+		"liquidation 20.00% from entry, below 25.00%",
+		"loss at stop 50.00 USD above 20.00",
+		"BTC exposure 30.00% of equity above 20.00%",
+		"size 1000.00 USD below 2000.00",
+	}, rej.Problems)
+	assert.EqualError(t, err, "risk: BTC rejected: "+rej.Problems[0]+"; "+rej.Problems[1]+"; "+rej.Problems[2]+"; "+rej.Problems[3]+"; "+rej.Problems[4])
+}
+
+func TestGuardResizesWhenAllowed(t *testing.T) {
+	g := NewGuard(Config{MaxLossPerTradeUSD: 25, MinLiquidationDistancePct: 25, MaxPositionConcentrationPct: 10, MinPositionSizeUSD: 100, Resize: true})
+	v, err := g.Check(longBTC(), Portfolio{EquityUSD: 5000, ExposureUSD: map[string]float64{"BTC": 100}})
+	require.NoError(t, err)
+	assert.Equal(t, 4, v.Order.Leverage)
+	assert.InDelta(t, 400, v.Order.SizeUSD, 1e-9)
+	assert.Equal(t, []string{
+		"leverage 5 -> 4 for liquidation distance 25.00%",
+		"size 1000.00 -> 500.00 for max loss 25.00 USD",
+		"size 500.00 -> 400.00 for concentration 10.00%",
+	}, v.Adjustments)
+
+	// Resizing below the minimum size still rejects.
+	_, err = g.Check(longBTC(), Portfolio{EquityUSD: 5000, ExposureUSD: map[string]float64{"BTC": 450}})
+	assert.ErrorContains(t, err, "size 50.00 USD below 100.00")
+}
+
+func TestGuardRiskRewardAndLossEdgeCases(t *testing.T) {
+	short := Order{Symbol: "ETH", SizeUSD: 600, EntryPrice: 2000, StopLoss: 2100, TakeProfit: 1700, Leverage: 3}
+	assert.InDelta(t, 3.0, riskReward(short), 1e-9)
+	assert.InDelta(t, 30, lossAtStop(short), 1e-9)
+
+	short.StopLoss = 0
+	assert.InDelta(t, 200, lossAtStop(short), 1e-9, "no stop risks the margin")
+	_, err := NewGuard(Config{MinRiskRewardRatio: 1}).Check(short, Portfolio{})
+	assert.ErrorContains(t, err, "stop_loss and take_profit must sit on opposite sides of entry")
+
+	var nilGuard *Guard
+	v, err := nilGuard.Check(short, Portfolio{})
+	require.NoError(t, err)
+	assert.Equal(t, short, v.Order)
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{MaxLossPerTradeUSD: 50, MinLiquidationDistancePct: 10}.Validate())
+	assert.EqualError(t, Config{MinLiquidationDistancePct: 100}.Validate(), "min_liquidation_distance_pct must be between 0 and 100")
+	assert.EqualError(t, Config{MinPositionSizeUSD: -1}.Validate(), "min_position_size_usd cannot be negative")
+}
@@ -0,0 +1,111 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"nof0-api/pkg/exchange"
+)
+
+// ErrStopBeyondLiquidation is returned when the position would be liquidated
+// before its stop loss could fill.
+var ErrStopBeyondLiquidation = errors.New("risk: stop loss beyond liquidation price")
+
+// Sizer turns a loss budget into a position for Hyperliquid-style margin:
+// size so a stop fill loses MaxLossPct of the account, then the lowest
+// leverage in range whose margin the account can post.
+type Sizer struct {
+	// MinLeverage and MaxLeverage bound the leverage chosen; 0 means 1 and
+	// MinLeverage respectively.
+	MinLeverage int
+	MaxLeverage int
+	// MaxLossPct is the share of account value lost if the stop fills.
+	MaxLossPct float64
+	// Mode is the margin mode the position is opened in; empty means cross.
+	Mode exchange.MarginMode
+	// MaintenanceMarginRate is maintenance margin over notional; 0 uses half
+	// the initial margin at MaxLeverage, as Hyperliquid does.
+	MaintenanceMarginRate float64
+}
+
+// Position is a sized entry.
+type Position struct {
+	Long        bool
+	Quantity    float64
+	NotionalUSD float64
+	// MarginUSD is the initial margin at Leverage.
+	MarginUSD float64
+	Leverage  int
+	// LiquidationPrice is the estimated liquidation mark at entry; 0 when no
+	// positive price liquidates the position.
+	LiquidationPrice float64
+	LossAtStopUSD    float64
+	// Capped reports that MaxLeverage, not the loss budget, bounds the size.
+	Capped bool
+}
+
+// Size sizes an entry at entryPrice with its stop at stopLoss; a stop below
+// entry is a long. It returns ErrStopBeyondLiquidation, with the position,
+// when the stop sits past the estimated liquidation price.
+func (s Sizer) Size(accountValueUSD, entryPrice, stopLoss float64) (Position, error) {
+	minLev, maxLev := s.leverageRange()
+	switch {
+	case accountValueUSD <= 0:
+		return Position{}, errors.New("risk: account value must be positive")
+	case entryPrice <= 0 || stopLoss <= 0 || stopLoss == entryPrice:
+		return Position{}, errors.New("risk: entry and stop loss must be positive and differ")
+	case s.MaxLossPct <= 0 || s.MaxLossPct > 100:
+		return Position{}, errors.New("risk: max loss must be between 0 and 100 percent")
+	case minLev > maxLev:
+		return Position{}, fmt.Errorf("risk: leverage range %d-%d is empty", minLev, maxLev)
+	}
+
+	p := Position{Long: stopLoss < entryPrice}
+	stopDistance := math.Abs(entryPrice - stopLoss)
+	p.Quantity = accountValueUSD * s.MaxLossPct / 100 / stopDistance
+	p.NotionalUSD = p.Quantity * entryPrice
+	if limit := accountValueUSD * float64(maxLev); p.NotionalUSD > limit {
+		p.NotionalUSD, p.Quantity, p.Capped = limit, limit/entryPrice, true
+	}
+	p.Leverage = int(math.Ceil(p.NotionalUSD/accountValueUSD - 1e-9))
+	if p.Leverage < minLev {
+		p.Leverage = minLev
+	}
+	if p.Leverage > maxLev {
+		p.Leverage = maxLev
+	}
+	p.MarginUSD = p.NotionalUSD / float64(p.Leverage)
+	p.LossAtStopUSD = p.Quantity * stopDistance
+
+	mmr := s.MaintenanceMarginRate
+	if mmr <= 0 {
+		mmr = 1 / (2 * float64(maxLev))
+	}
+	available := accountValueUSD - mmr*p.NotionalUSD
+	if !s.Mode.IsCross() {
+		available = p.MarginUSD - mmr*p.NotionalUSD
+	}
+	signed := p.Quantity
+	if !p.Long {
+		signed = -signed
+	}
+	if px, ok := exchange.LiquidationPrice(signed, entryPrice, available, mmr); ok {
+		p.LiquidationPrice = px
+		if (p.Long && stopLoss <= px) || (!p.Long && stopLoss >= px) {
+			return p, fmt.Errorf("%w: stop %.8g, liquidation %.8g", ErrStopBeyondLiquidation, stopLoss, px)
+		}
+	}
+	return p, nil
+}
+
+func (s Sizer) leverageRange() (int, int) {
+	minLev, maxLev := s.MinLeverage, s.MaxLeverage
+	if minLev <= 0 {
+		minLev = 1
+	}
+	if maxLev <= 0 {
+		maxLev = minLev
+	}
+	return minLev, maxLev
+}
@@ -0,0 +1,66 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+func TestSizerCrossLong(t *testing.T) {
+	s := Sizer{MinLeverage: 1, MaxLeverage: 20, MaxLossPct: 1}
+	p, err := s.Size(10_000, 100, 95)
+	require.NoError(t, err)
+	assert.True(t, p.Long)
+	assert.InDelta(t, 20, p.Quantity, 1e-9)
+	assert.InDelta(t, 2000, p.NotionalUSD, 1e-9)
+	assert.Equal(t, 1, p.Leverage)
+	assert.InDelta(t, 2000, p.MarginUSD, 1e-9)
+	assert.InDelta(t, 100, p.LossAtStopUSD, 1e-9)
+	assert.Zero(t, p.LiquidationPrice, "cross collateral covers the whole notional")
+	assert.False(t, p.Capped)
+}
+
+func TestSizerIsolatedPicksLowestLeverage(t *testing.T) {
+	s := Sizer{MaxLeverage: 10, MaxLossPct: 5, Mode: exchange.MarginIsolated}
+	p, err := s.Size(1000, 100, 98)
+	require.NoError(t, err)
+	assert.InDelta(t, 2500, p.NotionalUSD, 1e-9)
+	assert.Equal(t, 3, p.Leverage)
+	assert.InDelta(t, 2500.0/3, p.MarginUSD, 1e-9)
+	// maintenance rate 1/(2*10); liquidation where the margin above it is gone.
+	assert.InDelta(t, 100-(2500.0/3-125)/25/0.95, p.LiquidationPrice, 1e-9)
+}
+
+func TestSizerCapsAtMaxLeverage(t *testing.T) {
+	s := Sizer{MaxLeverage: 5, MaxLossPct: 2, Mode: exchange.MarginIsolated}
+	p, err := s.Size(1000, 100, 99.9)
+	require.NoError(t, err)
+	assert.True(t, p.Capped)
+	assert.InDelta(t, 5000, p.NotionalUSD, 1e-9)
+	assert.Equal(t, 5, p.Leverage)
+	assert.InDelta(t, 5, p.LossAtStopUSD, 1e-6)
+}
+
+func TestSizerStopBeyondLiquidation(t *testing.T) {
+	s := Sizer{MinLeverage: 20, MaxLeverage: 40, MaxLossPct: 1, Mode: exchange.MarginIsolated}
+	p, err := s.Size(1000, 100, 110)
+	require.ErrorIs(t, err, ErrStopBeyondLiquidation)
+	assert.False(t, p.Long)
+	assert.Equal(t, 20, p.Leverage)
+	assert.InDelta(t, 100+3.75/1.0125, p.LiquidationPrice, 1e-9)
+}
+
+func TestSizerRejectsBadInputs(t *testing.T) {
+	s := Sizer{MaxLeverage: 5, MaxLossPct: 1}
+	_, err := s.Size(0, 100, 95)
+	assert.EqualError(t, err, "risk: account value must be positive")
+	_, err = s.Size(1000, 100, 100)
+	assert.EqualError(t, err, "risk: entry and stop loss must be positive and differ")
+	_, err = Sizer{MaxLeverage: 5}.Size(1000, 100, 95)
+	assert.EqualError(t, err, "risk: max loss must be between 0 and 100 percent")
+	_, err = Sizer{MinLeverage: 10, MaxLeverage: 5, MaxLossPct: 1}.Size(1000, 100, 95)
+	assert.EqualError(t, err, "risk: leverage range 10-5 is empty")
+}
@@ -0,0 +1,131 @@
+// Package strategy lets non-LLM strategies take part in a trader's decision
+// cycle. An Advisor adds analysis text to the prompt before the model is
+// called and may veto the model's decisions before they reach the exchange.
+// Advisors are registered by type (in-tree, from a Go plugin, or as a
+// sandboxed WASM module) and attached to traders through the manager config.
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+const defaultAdvisorTimeout = 2 * time.Second
+
+// Advisor contributes signals to a decision cycle. Implementations must be
+// safe for use by one trader loop at a time; the manager never calls the same
+// Advisor concurrently.
+type Advisor interface {
+	// Name identifies the advisor in prompts, logs and veto reasons.
+	Name() string
+	// Advise returns notes rendered into the prompt for this cycle. Returning
+	// no notes is fine.
+	Advise(ctx context.Context, in *executorpkg.Context) ([]string, error)
+	// Review inspects one open decision before execution and returns a
+	// non-empty reason to veto it. Closes are never reviewed so advisors cannot
+	// keep a trader from reducing risk.
+	Review(ctx context.Context, in *executorpkg.Context, d executorpkg.Decision) (string, error)
+}
+
+// Config attaches one advisor to a trader.
+type Config struct {
+	// Type selects a registered builder; Plugin, when set, names a Go plugin
+	// (.so) that registers Type when opened.
+	Type   string         `yaml:"type" json:"type"`
+	Name   string         `yaml:"name" json:"name"`
+	Plugin string         `yaml:"plugin" json:"plugin"`
+	Params map[string]any `yaml:"params" json:"params"`
+	// FailClosed vetoes decisions when Review errors; by default advisor
+	// failures are logged and ignored.
+	FailClosed bool          `yaml:"fail_closed" json:"fail_closed"`
+	Timeout    time.Duration `yaml:"-" json:"timeout_duration"`
+
+	TimeoutRaw string `yaml:"timeout" json:"timeout"`
+}
+
+// Normalize trims fields and parses the timeout, defaulting to 2s.
+func (c *Config) Normalize() error {
+	c.Type = strings.ToLower(strings.TrimSpace(c.Type))
+	c.Name = strings.TrimSpace(c.Name)
+	c.Plugin = strings.TrimSpace(c.Plugin)
+	c.Timeout = defaultAdvisorTimeout
+	if raw := strings.TrimSpace(c.TimeoutRaw); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("strategy: advisor %q timeout %q invalid", c.Type, raw)
+		}
+		c.Timeout = d
+	}
+	return nil
+}
+
+// Validate checks that the advisor type is set.
+func (c Config) Validate() error {
+	if c.Type == "" {
+		return fmt.Errorf("strategy: advisor type is required")
+	}
+	return nil
+}
+
+// Builder constructs an Advisor from its config.
+type Builder func(cfg Config) (Advisor, error)
+
+var (
+	registry   = make(map[string]Builder)
+	registryMu sync.RWMutex
+)
+
+// Register associates a builder with an advisor type. Plugins call it from
+// their init functions.
+func Register(typeName string, builder Builder) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[strings.ToLower(strings.TrimSpace(typeName))] = builder
+}
+
+func lookupBuilder(typeName string) (Builder, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	builder, ok := registry[strings.ToLower(strings.TrimSpace(typeName))]
+	return builder, ok
+}
+
+// Build opens cfg.Plugin if set, then constructs the advisor of cfg.Type.
+func Build(cfg Config) (Advisor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Plugin != "" {
+		if err := openPlugin(cfg.Plugin); err != nil {
+			return nil, err
+		}
+	}
+	builder, ok := lookupBuilder(cfg.Type)
+	if !ok {
+		return nil, fmt.Errorf("strategy: unsupported advisor type %q", cfg.Type)
+	}
+	adv, err := builder(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("strategy: build advisor %q: %w", cfg.Type, err)
+	}
+	return adv, nil
+}
+
+// DecodeParams copies free-form advisor params into out, a pointer to a
+// struct with json tags.
+func DecodeParams(params map[string]any, out any) error {
+	if len(params) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
@@ -0,0 +1,123 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+)
+
+type stubAdvisor struct {
+	notes     []string
+	veto      string
+	reviewErr error
+}
+
+func (s *stubAdvisor) Name() string { return "stub" }
+
+func (s *stubAdvisor) Advise(context.Context, *executorpkg.Context) ([]string, error) {
+	return s.notes, nil
+}
+
+func (s *stubAdvisor) Review(context.Context, *executorpkg.Context, executorpkg.Decision) (string, error) {
+	return s.veto, s.reviewErr
+}
+
+func TestConfigNormalize(t *testing.T) {
+	cfg := Config{Type: " Funding_Veto ", TimeoutRaw: "500ms"}
+	require.NoError(t, cfg.Normalize())
+	assert.Equal(t, "funding_veto", cfg.Type)
+	assert.Equal(t, "500ms", cfg.Timeout.String())
+
+	cfg = Config{Type: "x", TimeoutRaw: "-1s"}
+	assert.Error(t, cfg.Normalize())
+	assert.ErrorContains(t, Config{}.Validate(), "type is required")
+}
+
+func TestBuildUnknownType(t *testing.T) {
+	_, err := Build(Config{Type: "nope"})
+	assert.ErrorContains(t, err, `unsupported advisor type "nope"`)
+}
+
+func TestChainAdviseAndReview(t *testing.T) {
+	c := &Chain{}
+	c.add(Config{Name: "quant"}, &stubAdvisor{notes: []string{"BTC breakout", ""}})
+	c.add(Config{}, &stubAdvisor{reviewErr: errors.New("down")})
+	c.add(Config{Name: "flow"}, &stubAdvisor{veto: "spoofed book"})
+
+	notes := c.Advise(context.Background(), &executorpkg.Context{})
+	assert.Equal(t, []string{"[quant] BTC breakout"}, notes)
+
+	reason := c.Review(context.Background(), &executorpkg.Context{}, executorpkg.Decision{Symbol: "BTC", Action: "open_long"})
+	assert.Equal(t, "vetoed by advisor flow: spoofed book", reason, "fail-open errors fall through to later advisors")
+
+	closed := &Chain{}
+	closed.add(Config{FailClosed: true}, &stubAdvisor{reviewErr: errors.New("down")})
+	assert.Equal(t, "advisor stub unavailable: down", closed.Review(context.Background(), nil, executorpkg.Decision{}))
+
+	var none *Chain
+	assert.Empty(t, none.Advise(context.Background(), nil))
+	assert.Empty(t, none.Review(context.Background(), nil, executorpkg.Decision{}))
+}
+
+func TestFundingVeto(t *testing.T) {
+	adv, err := Build(Config{Type: "funding_veto", Params: map[string]any{"max_rate_pct": 0.03}})
+	require.NoError(t, err)
+	in := &executorpkg.Context{MarketDataMap: map[string]*market.Snapshot{
+		"BTC": {Funding: &market.FundingInfo{Rate: 0.0005}},
+		"ETH": {Funding: &market.FundingInfo{Rate: 0.0001}},
+	}}
+
+	notes, err := adv.Advise(context.Background(), in)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Contains(t, notes[0], "BTC funding 0.0500% is extreme")
+
+	veto, err := adv.Review(context.Background(), in, executorpkg.Decision{Symbol: "BTC", Action: "open_long"})
+	require.NoError(t, err)
+	assert.Contains(t, veto, "BTC funding 0.0500% beyond 0.0300%")
+	veto, _ = adv.Review(context.Background(), in, executorpkg.Decision{Symbol: "BTC", Action: "open_short"})
+	assert.Empty(t, veto)
+	veto, _ = adv.Review(context.Background(), in, executorpkg.Decision{Symbol: "ETH", Action: "open_long"})
+	assert.Empty(t, veto)
+
+	_, err = Build(Config{Type: "funding_veto", Params: map[string]any{"max_rate_pct": 0}})
+	assert.ErrorContains(t, err, "max_rate_pct must be positive")
+}
+
+func TestWASMAdvisorParams(t *testing.T) {
+	_, err := Build(Config{Type: "wasm"})
+	assert.ErrorContains(t, err, "module path is required")
+	_, err = Build(Config{Type: "wasm", Params: map[string]any{"module": "x.wasm", "memory_limit_mb": 1024}})
+	assert.ErrorContains(t, err, "memory_limit_mb must be in 1-256")
+	_, err = Build(Config{Type: "wasm", Params: map[string]any{"module": t.TempDir() + "/missing.wasm"}})
+	assert.ErrorContains(t, err, "read module")
+}
+
+func TestWASMInputView(t *testing.T) {
+	in := &executorpkg.Context{
+		CurrentTime: "2026-01-02T03:04:05Z",
+		Account:     executorpkg.AccountInfo{TotalEquity: 1000, AvailableBalance: 600},
+		Positions:   []executorpkg.PositionInfo{{Symbol: "ETH", Side: "long", Quantity: 0.5}},
+		MarketDataMap: map[string]*market.Snapshot{
+			"SOL": {Price: market.PriceInfo{Last: 150}},
+			"BTC": {Price: market.PriceInfo{Last: 60000}, Funding: &market.FundingInfo{Rate: 0.0001}},
+		},
+	}
+	view := newWASMInput(in, &executorpkg.Decision{Symbol: "BTC", Action: "open_long", Leverage: 3})
+	require.Len(t, view.Markets, 2)
+	assert.Equal(t, "BTC", view.Markets[0].Symbol, "markets are sorted for deterministic input")
+	assert.InDelta(t, 0.0001, view.Markets[0].FundingRate, 1e-12)
+	assert.Equal(t, 600.0, view.Available)
+	require.NotNil(t, view.Decision)
+	assert.Equal(t, 3, view.Decision.Leverage)
+
+	empty := newWASMInput(nil, nil)
+	assert.NotNil(t, empty.Positions, "modules always see arrays, never null")
+	assert.Nil(t, empty.Decision)
+}
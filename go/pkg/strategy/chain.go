@@ -0,0 +1,109 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+// Chain runs a trader's advisors in config order, bounding each call by the
+// advisor's timeout.
+type Chain struct {
+	entries []chainEntry
+}
+
+type chainEntry struct {
+	name       string
+	advisor    Advisor
+	timeout    time.Duration
+	failClosed bool
+}
+
+// NewChain builds every configured advisor. An empty config yields a nil
+// Chain, which advises nothing and vetoes nothing.
+func NewChain(cfgs []Config) (*Chain, error) {
+	if len(cfgs) == 0 {
+		return nil, nil
+	}
+	c := &Chain{entries: make([]chainEntry, 0, len(cfgs))}
+	for _, cfg := range cfgs {
+		adv, err := Build(cfg)
+		if err != nil {
+			return nil, err
+		}
+		c.add(cfg, adv)
+	}
+	return c, nil
+}
+
+func (c *Chain) add(cfg Config, adv Advisor) {
+	name := cfg.Name
+	if name == "" {
+		name = adv.Name()
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultAdvisorTimeout
+	}
+	c.entries = append(c.entries, chainEntry{name: name, advisor: adv, timeout: timeout, failClosed: cfg.FailClosed})
+}
+
+// Len reports the number of advisors in the chain.
+func (c *Chain) Len() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.entries)
+}
+
+// Advise collects every advisor's notes, each prefixed with the advisor name.
+// Failing advisors are logged and skipped.
+func (c *Chain) Advise(ctx context.Context, in *executorpkg.Context) []string {
+	if c == nil {
+		return nil
+	}
+	var notes []string
+	for _, e := range c.entries {
+		callCtx, cancel := context.WithTimeout(ctx, e.timeout)
+		got, err := e.advisor.Advise(callCtx, in)
+		cancel()
+		if err != nil {
+			logx.WithContext(ctx).Errorf("strategy: advisor %s advise failed: %v", e.name, err)
+			continue
+		}
+		for _, note := range got {
+			if note != "" {
+				notes = append(notes, "["+e.name+"] "+note)
+			}
+		}
+	}
+	return notes
+}
+
+// Review returns the first veto raised against d, naming the advisor. An
+// advisor error vetoes only when that advisor is fail-closed.
+func (c *Chain) Review(ctx context.Context, in *executorpkg.Context, d executorpkg.Decision) string {
+	if c == nil {
+		return ""
+	}
+	for _, e := range c.entries {
+		callCtx, cancel := context.WithTimeout(ctx, e.timeout)
+		reason, err := e.advisor.Review(callCtx, in, d)
+		cancel()
+		if err != nil {
+			if e.failClosed {
+				return fmt.Sprintf("advisor %s unavailable: %v", e.name, err)
+			}
+			logx.WithContext(ctx).Errorf("strategy: advisor %s review %s %s failed: %v", e.name, d.Action, d.Symbol, err)
+			continue
+		}
+		if reason != "" {
+			return fmt.Sprintf("vetoed by advisor %s: %s", e.name, reason)
+		}
+	}
+	return ""
+}
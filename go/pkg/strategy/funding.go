@@ -0,0 +1,79 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+func init() {
+	Register("funding_veto", newFundingVeto)
+}
+
+// fundingVeto is the reference advisor: it reports extreme funding rates and
+// vetoes opens on the side that pays them.
+type fundingVeto struct {
+	MaxRatePct float64 `json:"max_rate_pct"`
+}
+
+func newFundingVeto(cfg Config) (Advisor, error) {
+	a := &fundingVeto{MaxRatePct: 0.05}
+	if err := DecodeParams(cfg.Params, a); err != nil {
+		return nil, err
+	}
+	if a.MaxRatePct <= 0 {
+		return nil, fmt.Errorf("max_rate_pct must be positive")
+	}
+	return a, nil
+}
+
+func (a *fundingVeto) Name() string { return "funding_veto" }
+
+func (a *fundingVeto) Advise(_ context.Context, in *executorpkg.Context) ([]string, error) {
+	symbols := make([]string, 0, len(in.MarketDataMap))
+	for sym := range in.MarketDataMap {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+	var notes []string
+	for _, sym := range symbols {
+		if pct, ok := a.extreme(in, sym); ok {
+			side := "longs"
+			if pct < 0 {
+				side = "shorts"
+			}
+			notes = append(notes, fmt.Sprintf("%s funding %.4f%% is extreme; %s pay, new %s will be vetoed", sym, pct, side, side))
+		}
+	}
+	return notes, nil
+}
+
+func (a *fundingVeto) Review(_ context.Context, in *executorpkg.Context, d executorpkg.Decision) (string, error) {
+	pct, ok := a.extreme(in, d.Symbol)
+	if !ok {
+		return "", nil
+	}
+	if (d.Action == "open_long" && pct > 0) || (d.Action == "open_short" && pct < 0) {
+		return fmt.Sprintf("%s funding %.4f%% beyond %.4f%%", d.Symbol, pct, a.MaxRatePct), nil
+	}
+	return "", nil
+}
+
+// extreme returns the symbol's funding rate in percent when its magnitude
+// reaches the threshold.
+func (a *fundingVeto) extreme(in *executorpkg.Context, symbol string) (float64, bool) {
+	if in == nil {
+		return 0, false
+	}
+	snap := in.MarketDataMap[symbol]
+	if snap == nil || snap.Funding == nil {
+		return 0, false
+	}
+	pct := snap.Funding.Rate * 100
+	if pct >= a.MaxRatePct || -pct >= a.MaxRatePct {
+		return pct, true
+	}
+	return 0, false
+}
@@ -0,0 +1,29 @@
+package strategy
+
+import (
+	"fmt"
+	"plugin"
+	"sync"
+)
+
+var (
+	openedPlugins   = make(map[string]error)
+	openedPluginsMu sync.Mutex
+)
+
+// openPlugin loads a Go plugin once per path. The plugin registers its
+// advisor types with Register from an init function; opening it again is a
+// no-op that returns the first result.
+func openPlugin(path string) error {
+	openedPluginsMu.Lock()
+	defer openedPluginsMu.Unlock()
+	if err, ok := openedPlugins[path]; ok {
+		return err
+	}
+	_, err := plugin.Open(path)
+	if err != nil {
+		err = fmt.Errorf("strategy: open plugin %s: %w", path, err)
+	}
+	openedPlugins[path] = err
+	return err
+}
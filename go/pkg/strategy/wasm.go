@@ -0,0 +1,257 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+
+	executorpkg "nof0-api/pkg/executor"
+)
+
+// WASM advisors run user-supplied code in a sandbox so hosted deployments can
+// accept strategies from tenants without loading native plugins. A module is
+// freestanding (no WASI, no host imports: it cannot touch files, the network
+// or the clock) and exports:
+//
+//	memory
+//	alloc(size i32) i32                 // buffer for the JSON input
+//	advise(ptr i32, len i32) i64        // -> {"notes": ["..."]}
+//	review(ptr i32, len i32) i64        // optional -> {"veto": "..."}
+//
+// advise and review return the output location packed as ptr<<32 | len. Each
+// call runs in a fresh instance, so nothing leaks between cycles; CPU time is
+// bounded by the advisor timeout and memory by memory_limit_mb.
+const (
+	defaultWASMMemoryMB = 16
+	maxWASMMemoryMB     = 256
+	maxWASMOutputBytes  = 64 << 10
+	wasmPageBytes       = 64 << 10
+)
+
+func init() {
+	Register("wasm", newWASMAdvisor)
+}
+
+type wasmParams struct {
+	Module        string `json:"module"`
+	MemoryLimitMB int    `json:"memory_limit_mb"`
+}
+
+type wasmAdvisor struct {
+	name      string
+	runtime   wazero.Runtime
+	compiled  wazero.CompiledModule
+	hasReview bool
+}
+
+func newWASMAdvisor(cfg Config) (Advisor, error) {
+	p := wasmParams{MemoryLimitMB: defaultWASMMemoryMB}
+	if err := DecodeParams(cfg.Params, &p); err != nil {
+		return nil, err
+	}
+	p.Module = strings.TrimSpace(p.Module)
+	if p.Module == "" {
+		return nil, fmt.Errorf("module path is required")
+	}
+	if p.MemoryLimitMB <= 0 || p.MemoryLimitMB > maxWASMMemoryMB {
+		return nil, fmt.Errorf("memory_limit_mb must be in 1-%d, got %d", maxWASMMemoryMB, p.MemoryLimitMB)
+	}
+	code, err := os.ReadFile(p.Module)
+	if err != nil {
+		return nil, fmt.Errorf("read module: %w", err)
+	}
+
+	ctx := context.Background()
+	rtCfg := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(p.MemoryLimitMB * (1 << 20) / wasmPageBytes)).
+		WithCloseOnContextDone(true)
+	rt := wazero.NewRuntimeWithConfig(ctx, rtCfg)
+	compiled, err := rt.CompileModule(ctx, code)
+	if err != nil {
+		_ = rt.Close(ctx)
+		return nil, fmt.Errorf("compile module: %w", err)
+	}
+	if n := len(compiled.ImportedFunctions()); n > 0 {
+		_ = rt.Close(ctx)
+		return nil, fmt.Errorf("module imports %d host functions; sandboxed modules may not import any", n)
+	}
+	exports := compiled.ExportedFunctions()
+	for _, fn := range []string{"alloc", "advise"} {
+		if _, ok := exports[fn]; !ok {
+			_ = rt.Close(ctx)
+			return nil, fmt.Errorf("module does not export %s", fn)
+		}
+	}
+	_, hasReview := exports["review"]
+
+	name := cfg.Name
+	if name == "" {
+		name = strings.TrimSuffix(p.Module[strings.LastIndex(p.Module, "/")+1:], ".wasm")
+	}
+	return &wasmAdvisor{name: name, runtime: rt, compiled: compiled, hasReview: hasReview}, nil
+}
+
+func (a *wasmAdvisor) Name() string { return a.name }
+
+func (a *wasmAdvisor) Advise(ctx context.Context, in *executorpkg.Context) ([]string, error) {
+	var out struct {
+		Notes []string `json:"notes"`
+	}
+	if err := a.call(ctx, "advise", newWASMInput(in, nil), &out); err != nil {
+		return nil, err
+	}
+	return out.Notes, nil
+}
+
+func (a *wasmAdvisor) Review(ctx context.Context, in *executorpkg.Context, d executorpkg.Decision) (string, error) {
+	if !a.hasReview {
+		return "", nil
+	}
+	var out struct {
+		Veto string `json:"veto"`
+	}
+	if err := a.call(ctx, "review", newWASMInput(in, &d), &out); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.Veto), nil
+}
+
+// call instantiates the module, writes input into its memory, invokes fn and
+// decodes the JSON it returns into out.
+func (a *wasmAdvisor) call(ctx context.Context, fn string, input wasmInput, out any) error {
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	mod, err := a.runtime.InstantiateModule(ctx, a.compiled, wazero.NewModuleConfig().WithName("").WithStartFunctions())
+	if err != nil {
+		return fmt.Errorf("instantiate: %w", err)
+	}
+	defer mod.Close(context.Background())
+
+	mem := mod.Memory()
+	if mem == nil {
+		return fmt.Errorf("module exports no memory")
+	}
+	res, err := mod.ExportedFunction("alloc").Call(ctx, uint64(len(payload)))
+	if err != nil {
+		return fmt.Errorf("alloc: %w", err)
+	}
+	ptr := api.DecodeU32(res[0])
+	if !mem.Write(ptr, payload) {
+		return fmt.Errorf("alloc returned out-of-range buffer %d", ptr)
+	}
+	res, err = mod.ExportedFunction(fn).Call(ctx, uint64(ptr), uint64(len(payload)))
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	outPtr, outLen := uint32(res[0]>>32), uint32(res[0])
+	if outLen == 0 {
+		return nil
+	}
+	if outLen > maxWASMOutputBytes {
+		return fmt.Errorf("%s output %d bytes exceeds %d", fn, outLen, maxWASMOutputBytes)
+	}
+	raw, ok := mem.Read(outPtr, outLen)
+	if !ok {
+		return fmt.Errorf("%s returned out-of-range output", fn)
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("%s output: %w", fn, err)
+	}
+	return nil
+}
+
+// wasmInput is the stable JSON view of a decision cycle handed to modules.
+// It carries only what a signal needs, not the executor's internal state.
+type wasmInput struct {
+	Time      string             `json:"time"`
+	Equity    float64            `json:"equity"`
+	Available float64            `json:"available"`
+	Positions []wasmPosition     `json:"positions"`
+	Markets   []wasmMarket       `json:"markets"`
+	Decision  *wasmDecisionInput `json:"decision,omitempty"`
+}
+
+type wasmPosition struct {
+	Symbol        string  `json:"symbol"`
+	Side          string  `json:"side"`
+	Quantity      float64 `json:"quantity"`
+	EntryPrice    float64 `json:"entry_price"`
+	MarkPrice     float64 `json:"mark_price"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+}
+
+type wasmMarket struct {
+	Symbol      string  `json:"symbol"`
+	Price       float64 `json:"price"`
+	Change1h    float64 `json:"change_1h"`
+	Change4h    float64 `json:"change_4h"`
+	FundingRate float64 `json:"funding_rate"`
+}
+
+type wasmDecisionInput struct {
+	Symbol          string  `json:"symbol"`
+	Action          string  `json:"action"`
+	Leverage        int     `json:"leverage"`
+	PositionSizeUSD float64 `json:"position_size_usd"`
+	EntryPrice      float64 `json:"entry_price"`
+	StopLoss        float64 `json:"stop_loss"`
+	TakeProfit      float64 `json:"take_profit"`
+	Confidence      int     `json:"confidence"`
+}
+
+func newWASMInput(in *executorpkg.Context, d *executorpkg.Decision) wasmInput {
+	out := wasmInput{Positions: []wasmPosition{}, Markets: []wasmMarket{}}
+	if d != nil {
+		out.Decision = &wasmDecisionInput{
+			Symbol:          d.Symbol,
+			Action:          d.Action,
+			Leverage:        d.Leverage,
+			PositionSizeUSD: d.PositionSizeUSD,
+			EntryPrice:      d.EntryPrice,
+			StopLoss:        d.StopLoss,
+			TakeProfit:      d.TakeProfit,
+			Confidence:      d.Confidence,
+		}
+	}
+	if in == nil {
+		return out
+	}
+	out.Time = in.CurrentTime
+	out.Equity = in.Account.TotalEquity
+	out.Available = in.Account.AvailableBalance
+	for _, p := range in.Positions {
+		out.Positions = append(out.Positions, wasmPosition{
+			Symbol:        p.Symbol,
+			Side:          p.Side,
+			Quantity:      p.Quantity,
+			EntryPrice:    p.EntryPrice,
+			MarkPrice:     p.MarkPrice,
+			UnrealizedPnL: p.UnrealizedPnL,
+		})
+	}
+	symbols := make([]string, 0, len(in.MarketDataMap))
+	for sym := range in.MarketDataMap {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+	for _, sym := range symbols {
+		snap := in.MarketDataMap[sym]
+		if snap == nil {
+			continue
+		}
+		m := wasmMarket{Symbol: sym, Price: snap.Price.Last, Change1h: snap.Change.OneHour, Change4h: snap.Change.FourHour}
+		if snap.Funding != nil {
+			m.FundingRate = snap.Funding.Rate
+		}
+		out.Markets = append(out.Markets, m)
+	}
+	return out
+}
@@ -0,0 +1,411 @@
+// Package telegram implements an operator bot for the manager runtime.
+//
+// The bot runs inside the manager process and talks to it directly through
+// Controller; there is no separate admin API. Access control is a per-chat
+// allowlist from manager config with two roles: viewers may run read-only
+// commands, operators may also change trader state.
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	managerpkg "nof0-api/pkg/manager"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	pollTimeout  = 30 * time.Second
+	retryBackoff = 5 * time.Second
+	// maxMessageLen is Telegram's limit for one text message.
+	maxMessageLen = 4096
+)
+
+// Role is the access level granted to a chat.
+type Role int
+
+const (
+	RoleNone Role = iota
+	RoleViewer
+	RoleOperator
+)
+
+// Controller is the manager surface the bot drives. *manager.Manager
+// implements it.
+type Controller interface {
+	TraderStatuses() []managerpkg.TraderStatus
+	TraderPositions(traderID string) ([]managerpkg.VirtualPosition, error)
+	PauseTrader(ctx context.Context, traderID string) error
+	ResumeTrader(ctx context.Context, traderID string) error
+	FlattenTrader(ctx context.Context, traderID string) ([]string, error)
+	BlacklistedSymbols() []managerpkg.BlacklistEntry
+	UnblacklistSymbol(ctx context.Context, symbol string) error
+	ExecutionQuality(groupBy string, since time.Time) ([]managerpkg.ExecutionQualityRow, error)
+	FactualAccuracy(groupBy string, since time.Time) ([]managerpkg.FactualAccuracyRow, error)
+}
+
+type command struct {
+	role    Role
+	usage   string
+	needsID bool
+	run     func(b *Bot, ctx context.Context, arg string) (string, error)
+}
+
+// commands is populated in init because /help lists it.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"/help":      {role: RoleViewer, usage: "/help", run: (*Bot).cmdHelp},
+		"/status":    {role: RoleViewer, usage: "/status", run: (*Bot).cmdStatus},
+		"/positions": {role: RoleViewer, usage: "/positions [model]", run: (*Bot).cmdPositions},
+		"/pause":     {role: RoleOperator, usage: "/pause <model>", needsID: true, run: (*Bot).cmdPause},
+		"/resume":    {role: RoleOperator, usage: "/resume <model>", needsID: true, run: (*Bot).cmdResume},
+		"/flatten":   {role: RoleOperator, usage: "/flatten <model>", needsID: true, run: (*Bot).cmdFlatten},
+		"/blacklist": {role: RoleViewer, usage: "/blacklist", run: (*Bot).cmdBlacklist},
+		"/unblock":   {role: RoleOperator, usage: "/unblock <symbol>", needsID: true, run: (*Bot).cmdUnblock},
+		"/execution": {role: RoleViewer, usage: "/execution [model|symbol|trader]", run: (*Bot).cmdExecution},
+		"/accuracy":  {role: RoleViewer, usage: "/accuracy [model|trader]", run: (*Bot).cmdAccuracy},
+	}
+}
+
+// Bot answers operator commands and forwards manager alerts to every
+// allowed chat.
+type Bot struct {
+	cfg    managerpkg.TelegramConfig
+	client *Client
+	roles  map[int64]Role
+	ctrl   Controller
+	now    func() time.Time
+}
+
+// NewBot builds a bot from config. Run must be called to serve commands;
+// alerts are delivered as soon as the bot is constructed.
+func NewBot(cfg managerpkg.TelegramConfig, client *Client) *Bot {
+	if client == nil {
+		client = NewClient(cfg.BotToken, "")
+	}
+	roles := make(map[int64]Role, len(cfg.Operators)+len(cfg.Viewers))
+	for _, id := range cfg.Viewers {
+		roles[id] = RoleViewer
+	}
+	for _, id := range cfg.Operators {
+		roles[id] = RoleOperator
+	}
+	return &Bot{cfg: cfg, client: client, roles: roles, now: time.Now}
+}
+
+// Channel implements manager.ChannelAlerter so alerts use the telegram
+// templates when present.
+func (b *Bot) Channel() string { return "telegram" }
+
+// Alert implements manager.Alerter.
+func (b *Bot) Alert(ctx context.Context, alert managerpkg.Alert) error {
+	return b.broadcast(ctx, alert.Message)
+}
+
+// Run serves commands and the daily summary until ctx is cancelled.
+func (b *Bot) Run(ctx context.Context, ctrl Controller) {
+	b.ctrl = ctrl
+	if b.cfg.DailySummaryAt != "" {
+		go b.runDailySummary(ctx)
+	}
+	var offset int64
+	for {
+		updates, err := b.client.GetUpdates(ctx, offset, pollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logx.WithContext(ctx).Errorf("%v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryBackoff):
+			}
+			continue
+		}
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message == nil || u.Message.Text == "" {
+				continue
+			}
+			chatID := u.Message.Chat.ID
+			reply, ok := b.handle(ctx, chatID, u.Message.Text)
+			if !ok {
+				continue
+			}
+			if err := b.client.SendMessage(ctx, chatID, truncate(reply)); err != nil {
+				logx.WithContext(ctx).Errorf("%v", err)
+			}
+		}
+	}
+}
+
+// handle executes one message and returns the reply. ok is false for
+// messages from chats outside the allowlist, which are ignored.
+func (b *Bot) handle(ctx context.Context, chatID int64, text string) (reply string, ok bool) {
+	role := b.roles[chatID]
+	if role == RoleNone {
+		logx.WithContext(ctx).Infof("telegram: ignoring message from unauthorised chat %d", chatID)
+		return "", false
+	}
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", false
+	}
+	// Commands sent in groups arrive as /cmd@BotName.
+	name, _, _ := strings.Cut(strings.ToLower(fields[0]), "@")
+	arg := ""
+	if len(fields) > 1 {
+		arg = fields[1]
+	}
+	cmd, found := commands[name]
+	if !found {
+		return "Unknown command. Try /help.", true
+	}
+	if role < cmd.role {
+		logx.WithContext(ctx).Infof("telegram: chat %d denied %s", chatID, name)
+		return fmt.Sprintf("%s requires operator access.", name), true
+	}
+	if cmd.needsID && arg == "" {
+		return "Usage: " + cmd.usage, true
+	}
+	if b.ctrl == nil && name != "/help" {
+		return "Manager is not running yet.", true
+	}
+	out, err := cmd.run(b, ctx, arg)
+	if err != nil {
+		return "Error: " + err.Error(), true
+	}
+	if cmd.role == RoleOperator {
+		logx.WithContext(ctx).Infof("telegram: chat %d ran %s %s", chatID, name, arg)
+	}
+	return out, true
+}
+
+func (b *Bot) cmdHelp(_ context.Context, _ string) (string, error) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	sb.WriteString("Commands:")
+	for _, name := range names {
+		cmd := commands[name]
+		sb.WriteString("\n" + cmd.usage)
+		if cmd.role == RoleOperator {
+			sb.WriteString(" (operator)")
+		}
+	}
+	return sb.String(), nil
+}
+
+func (b *Bot) cmdStatus(_ context.Context, _ string) (string, error) {
+	return formatStatus(b.ctrl.TraderStatuses(), b.now()), nil
+}
+
+func (b *Bot) cmdPositions(_ context.Context, traderID string) (string, error) {
+	ids := []string{traderID}
+	if traderID == "" {
+		ids = ids[:0]
+		for _, st := range b.ctrl.TraderStatuses() {
+			ids = append(ids, st.ID)
+		}
+	}
+	var sb strings.Builder
+	for _, id := range ids {
+		positions, err := b.ctrl.TraderPositions(id)
+		if err != nil {
+			return "", err
+		}
+		if len(positions) == 0 {
+			if traderID != "" {
+				sb.WriteString(id + ": no open positions\n")
+			}
+			continue
+		}
+		sb.WriteString(id + ":\n")
+		for _, p := range positions {
+			fmt.Fprintf(&sb, "  %s %s qty=%.4f entry=%.4f notional=$%.2f %dx\n",
+				p.Symbol, p.Side, p.Quantity, p.EntryPrice, p.NotionalUSD, p.Leverage)
+		}
+	}
+	if sb.Len() == 0 {
+		return "No open positions.", nil
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+func (b *Bot) cmdPause(ctx context.Context, traderID string) (string, error) {
+	if err := b.ctrl.PauseTrader(ctx, traderID); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s paused. Positions left open; /resume %s to continue.", traderID, traderID), nil
+}
+
+func (b *Bot) cmdResume(ctx context.Context, traderID string) (string, error) {
+	if err := b.ctrl.ResumeTrader(ctx, traderID); err != nil {
+		return "", err
+	}
+	return traderID + " resumed.", nil
+}
+
+func (b *Bot) cmdFlatten(ctx context.Context, traderID string) (string, error) {
+	closed, err := b.ctrl.FlattenTrader(ctx, traderID)
+	if err != nil {
+		if len(closed) > 0 {
+			return "", fmt.Errorf("%w (closed before failure: %s)", err, strings.Join(closed, ", "))
+		}
+		return "", err
+	}
+	if len(closed) == 0 {
+		return traderID + " paused; no open positions.", nil
+	}
+	return fmt.Sprintf("%s paused and closed: %s", traderID, strings.Join(closed, ", ")), nil
+}
+
+func (b *Bot) cmdBlacklist(_ context.Context, _ string) (string, error) {
+	entries := b.ctrl.BlacklistedSymbols()
+	if len(entries) == 0 {
+		return "No blacklisted symbols.", nil
+	}
+	var sb strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&sb, "%s until %s: %s\n", e.Symbol, e.Until.UTC().Format("15:04"), e.Reason)
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+func (b *Bot) cmdUnblock(ctx context.Context, symbol string) (string, error) {
+	if err := b.ctrl.UnblacklistSymbol(ctx, symbol); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(symbol) + " removed from blacklist; new entries allowed.", nil
+}
+
+func (b *Bot) cmdExecution(_ context.Context, groupBy string) (string, error) {
+	rows, err := b.ctrl.ExecutionQuality(groupBy, time.Time{})
+	if err != nil {
+		return "", err
+	}
+	if len(rows) == 0 {
+		return "No fills recorded yet.", nil
+	}
+	var sb strings.Builder
+	sb.WriteString("Slippage vs arrival (bps, + = worse):\n")
+	for _, r := range rows {
+		fmt.Fprintf(&sb, "%s: %d fills $%.0f, mean %+.1f, p90 %+.1f, worst %+.1f, cost $%.2f\n",
+			r.Key, r.Fills, r.NotionalUSD, r.MeanBps, r.P90Bps, r.WorstBps, r.CostUSD)
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+func (b *Bot) cmdAccuracy(_ context.Context, groupBy string) (string, error) {
+	rows, err := b.ctrl.FactualAccuracy(groupBy, time.Time{})
+	if err != nil {
+		return "", err
+	}
+	if len(rows) == 0 {
+		return "No decisions checked yet.", nil
+	}
+	var sb strings.Builder
+	sb.WriteString("Cited numbers found in prompt data:\n")
+	for _, r := range rows {
+		fmt.Fprintf(&sb, "%s: %.0f%% of %d over %d decisions", r.Key, r.Accuracy*100, r.Cited, r.Decisions)
+		if r.Chronic {
+			sb.WriteString(" (chronic hallucinator)")
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+func formatStatus(statuses []managerpkg.TraderStatus, now time.Time) string {
+	if len(statuses) == 0 {
+		return "No traders registered."
+	}
+	var sb strings.Builder
+	for _, st := range statuses {
+		state := string(st.State)
+		if st.Stalled {
+			state += ", STALLED"
+		}
+		if now.Before(st.PauseUntil) {
+			state += ", gated until " + st.PauseUntil.UTC().Format("15:04")
+		}
+		fmt.Fprintf(&sb, "%s [%s] equity=$%.2f uPnL=$%.2f pnl=$%.2f trades=%d positions=%d",
+			st.ID, state, st.EquityUSD, st.UnrealizedPnLUSD, st.TotalPnLUSD, st.TotalTrades, st.Positions)
+		if !st.LastDecisionAt.IsZero() {
+			fmt.Fprintf(&sb, " last=%s ago", now.Sub(st.LastDecisionAt).Truncate(time.Second))
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// runDailySummary sends /status output to every allowed chat at
+// DailySummaryAt (UTC) each day.
+func (b *Bot) runDailySummary(ctx context.Context) {
+	at, err := time.Parse("15:04", b.cfg.DailySummaryAt)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("telegram: daily summary disabled: %v", err)
+		return
+	}
+	for {
+		next := nextDaily(b.now(), at)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		msg := "Daily summary " + next.Format("2006-01-02") + "\n" + formatStatus(b.ctrl.TraderStatuses(), b.now())
+		if err := b.broadcast(ctx, msg); err != nil {
+			logx.WithContext(ctx).Errorf("telegram: daily summary: %v", err)
+		}
+	}
+}
+
+// nextDaily returns the first time after now whose UTC clock reads at.
+func nextDaily(now time.Time, at time.Time) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func (b *Bot) broadcast(ctx context.Context, text string) error {
+	ids := make([]int64, 0, len(b.roles))
+	for id := range b.roles {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var firstErr error
+	for _, id := range ids {
+		if err := b.client.SendMessage(ctx, id, truncate(text)); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func truncate(s string) string {
+	if len(s) <= maxMessageLen {
+		return s
+	}
+	cut := maxMessageLen - len("\n…")
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "\n…"
+}
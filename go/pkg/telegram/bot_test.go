@@ -0,0 +1,187 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	managerpkg "nof0-api/pkg/manager"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeController struct {
+	paused    []string
+	flattened []string
+	unblocked []string
+}
+
+func (f *fakeController) TraderStatuses() []managerpkg.TraderStatus {
+	return []managerpkg.TraderStatus{
+		{ID: "t1", State: managerpkg.TraderStateRunning, EquityUSD: 1000, Positions: 1},
+		{ID: "t2", State: managerpkg.TraderStatePaused, EquityUSD: 500},
+	}
+}
+
+func (f *fakeController) TraderPositions(traderID string) ([]managerpkg.VirtualPosition, error) {
+	if traderID == "t1" {
+		return []managerpkg.VirtualPosition{{Symbol: "BTC", Side: "long", Quantity: 0.01, EntryPrice: 60000, NotionalUSD: 600, Leverage: 3}}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeController) PauseTrader(_ context.Context, traderID string) error {
+	f.paused = append(f.paused, traderID)
+	return nil
+}
+
+func (f *fakeController) ResumeTrader(context.Context, string) error { return nil }
+
+func (f *fakeController) FlattenTrader(_ context.Context, traderID string) ([]string, error) {
+	f.flattened = append(f.flattened, traderID)
+	return []string{"BTC"}, nil
+}
+
+func (f *fakeController) BlacklistedSymbols() []managerpkg.BlacklistEntry {
+	return []managerpkg.BlacklistEntry{{Symbol: "SOL", Reason: "3 anomalies within 15m0s, last: feed error: timeout", Until: time.Date(2025, 1, 2, 4, 0, 0, 0, time.UTC)}}
+}
+
+func (f *fakeController) UnblacklistSymbol(_ context.Context, symbol string) error {
+	f.unblocked = append(f.unblocked, symbol)
+	return nil
+}
+
+func (f *fakeController) ExecutionQuality(groupBy string, _ time.Time) ([]managerpkg.ExecutionQualityRow, error) {
+	if groupBy == "venue" {
+		return nil, errors.New("unknown grouping")
+	}
+	return []managerpkg.ExecutionQualityRow{{Key: "deepseek-chat", Fills: 4, NotionalUSD: 2000, MeanBps: 2.5, P90Bps: 6, WorstBps: 8, CostUSD: 0.5}}, nil
+}
+
+func (f *fakeController) FactualAccuracy(string, time.Time) ([]managerpkg.FactualAccuracyRow, error) {
+	return []managerpkg.FactualAccuracyRow{
+		{Key: "deepseek-chat", Decisions: 12, Cited: 40, Matched: 38, Accuracy: 0.95},
+		{Key: "qwen-max", Decisions: 9, Cited: 25, Matched: 15, Accuracy: 0.6, Chronic: true},
+	}, nil
+}
+
+func newTestBot(ctrl Controller) *Bot {
+	b := NewBot(managerpkg.TelegramConfig{Operators: []int64{1}, Viewers: []int64{2}}, NewClient("token", "http://unused"))
+	b.ctrl = ctrl
+	return b
+}
+
+func TestBotRoles(t *testing.T) {
+	ctrl := &fakeController{}
+	b := newTestBot(ctrl)
+	ctx := context.Background()
+
+	_, ok := b.handle(ctx, 99, "/status")
+	assert.False(t, ok, "unknown chats are ignored")
+
+	reply, ok := b.handle(ctx, 2, "/status")
+	require.True(t, ok)
+	assert.Contains(t, reply, "t1 [running]")
+	assert.Contains(t, reply, "t2 [paused]")
+
+	reply, _ = b.handle(ctx, 2, "/pause t1")
+	assert.Equal(t, "/pause requires operator access.", reply)
+	assert.Empty(t, ctrl.paused)
+
+	reply, _ = b.handle(ctx, 1, "/pause@nof0_bot t1")
+	assert.Contains(t, reply, "t1 paused")
+	assert.Equal(t, []string{"t1"}, ctrl.paused)
+
+	reply, _ = b.handle(ctx, 1, "/flatten t1")
+	assert.Equal(t, "t1 paused and closed: BTC", reply)
+	assert.Equal(t, []string{"t1"}, ctrl.flattened)
+}
+
+func TestBotCommandParsing(t *testing.T) {
+	b := newTestBot(&fakeController{})
+	ctx := context.Background()
+
+	reply, _ := b.handle(ctx, 1, "/flatten")
+	assert.Equal(t, "Usage: /flatten <model>", reply)
+
+	reply, _ = b.handle(ctx, 1, "/launch")
+	assert.Equal(t, "Unknown command. Try /help.", reply)
+
+	reply, _ = b.handle(ctx, 2, "/positions")
+	assert.Contains(t, reply, "BTC long qty=0.0100")
+	assert.NotContains(t, reply, "t2")
+
+	reply, _ = b.handle(ctx, 2, "/positions t2")
+	assert.Equal(t, "t2: no open positions", reply)
+
+	reply, _ = b.handle(ctx, 2, "/help")
+	assert.Contains(t, reply, "/flatten <model> (operator)")
+}
+
+func TestNextDaily(t *testing.T) {
+	at, err := time.Parse("15:04", "08:30")
+	require.NoError(t, err)
+	now := time.Date(2025, 3, 1, 7, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 1, 8, 30, 0, 0, time.UTC), nextDaily(now, at))
+	now = time.Date(2025, 3, 1, 8, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 2, 8, 30, 0, 0, time.UTC), nextDaily(now, at))
+}
+
+func TestAlertBroadcastsToAllowedChats(t *testing.T) {
+	var chats []int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.True(t, strings.HasSuffix(r.URL.Path, "/bottoken/sendMessage"))
+		var body struct {
+			ChatID int64  `json:"chat_id"`
+			Text   string `json:"text"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "stalled", body.Text)
+		chats = append(chats, body.ChatID)
+		_, _ = w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	defer srv.Close()
+
+	b := NewBot(managerpkg.TelegramConfig{Operators: []int64{1}, Viewers: []int64{2}}, NewClient("token", srv.URL))
+	require.NoError(t, b.Alert(context.Background(), managerpkg.Alert{Message: "stalled"}))
+	assert.Equal(t, []int64{1, 2}, chats)
+}
+
+func TestBotBlacklist(t *testing.T) {
+	ctrl := &fakeController{}
+	b := newTestBot(ctrl)
+	ctx := context.Background()
+
+	reply, _ := b.handle(ctx, 2, "/blacklist")
+	assert.Equal(t, "SOL until 04:00: 3 anomalies within 15m0s, last: feed error: timeout", reply)
+
+	reply, _ = b.handle(ctx, 2, "/unblock sol")
+	assert.Equal(t, "/unblock requires operator access.", reply)
+
+	reply, _ = b.handle(ctx, 1, "/unblock sol")
+	assert.Equal(t, "SOL removed from blacklist; new entries allowed.", reply)
+	assert.Equal(t, []string{"sol"}, ctrl.unblocked)
+}
+
+func TestBotExecution(t *testing.T) {
+	b := newTestBot(&fakeController{})
+	ctx := context.Background()
+
+	reply, _ := b.handle(ctx, 2, "/execution model")
+	assert.Equal(t, "Slippage vs arrival (bps, + = worse):\ndeepseek-chat: 4 fills $2000, mean +2.5, p90 +6.0, worst +8.0, cost $0.50", reply)
+
+	reply, _ = b.handle(ctx, 2, "/execution venue")
+	assert.Contains(t, reply, "unknown grouping")
+}
+
+func TestBotAccuracy(t *testing.T) {
+	b := newTestBot(&fakeController{})
+	reply, _ := b.handle(context.Background(), 2, "/accuracy")
+	assert.Equal(t, "Cited numbers found in prompt data:\ndeepseek-chat: 95% of 40 over 12 decisions\nqwen-max: 60% of 25 over 9 decisions (chronic hallucinator)", reply)
+}
@@ -0,0 +1,116 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const defaultAPIBase = "https://api.telegram.org"
+
+// Update is the subset of a Bot API update the bot consumes.
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message"`
+}
+
+// Message is an incoming chat message.
+type Message struct {
+	Chat struct {
+		ID int64 `json:"id"`
+	} `json:"chat"`
+	Text string `json:"text"`
+}
+
+// Client is a minimal Telegram Bot API client.
+type Client struct {
+	base string
+	http *http.Client
+}
+
+// NewClient returns a client for the bot identified by token. apiBase
+// overrides the Bot API endpoint; empty uses api.telegram.org.
+func NewClient(token, apiBase string) *Client {
+	if apiBase == "" {
+		apiBase = defaultAPIBase
+	}
+	return &Client{
+		base: apiBase + "/bot" + token,
+		// Long polls hold the connection for up to pollTimeout.
+		http: &http.Client{Timeout: pollTimeout + 10*time.Second},
+	}
+}
+
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+}
+
+// GetUpdates long-polls for updates after offset.
+func (c *Client) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	q := url.Values{}
+	q.Set("offset", strconv.FormatInt(offset, 10))
+	q.Set("timeout", strconv.Itoa(int(timeout/time.Second)))
+	q.Set("allowed_updates", `["message"]`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+"/getUpdates?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var updates []Update
+	if err := c.do(req, &updates); err != nil {
+		return nil, fmt.Errorf("telegram: getUpdates: %w", err)
+	}
+	return updates, nil
+}
+
+// SendMessage posts plain text to chatID.
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string) error {
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.do(req, nil); err != nil {
+		return fmt.Errorf("telegram: sendMessage chat=%d: %w", chatID, err)
+	}
+	return nil
+}
+
+func (c *Client) do(req *http.Request, out any) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// url.Error embeds the request URL, which carries the bot token.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			return uerr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	var r apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("decode %s response: %w", resp.Status, err)
+	}
+	if !r.OK {
+		return fmt.Errorf("%s: %s", resp.Status, r.Description)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(r.Result, out)
+}